@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alert
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+)
+
+type (
+	// Alert is the state of an alert rule on a service.
+	Alert struct {
+		Rule      string `json:"rule"`
+		Service   string `json:"service"`
+		Tenant    string `json:"tenant,omitempty"`
+		Metric    string `json:"metric"`
+		Condition string `json:"condition"`
+		Severity  string `json:"severity"`
+		// Value is the current value of the metric, which is the latency
+		// in milliseconds, the percentage of failed requests, or the count
+		// of instances in service.
+		Value float64 `json:"value"`
+		// NoData means sidecars of the service report no statistics.
+		NoData bool `json:"noData,omitempty"`
+		// Firing means the condition holds now, regardless of how long.
+		Firing bool `json:"firing"`
+
+		forDuration time.Duration
+		webhook     string
+	}
+
+	// mesh is what rules are evaluated against.
+	mesh struct {
+		// tenants are tenants of services keyed by service name.
+		tenants map[string]string
+		// instances are counts of instances in service keyed by service name.
+		instances map[string]int
+		// objects are statuses of objects reported by sidecars.
+		objects map[string]map[string]interface{}
+	}
+)
+
+// listRules returns alert rules of the names, empty names means all.
+func listRules(meshClient meshclient.MeshClient, timeout time.Duration, names []string) ([]*resource.AlertRule, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	if len(names) == 0 {
+		rules, err := meshClient.V1Alpha1().AlertRule().List(ctx)
+		if meshclient.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return rules, err
+	}
+
+	rules := []*resource.AlertRule{}
+	for _, name := range names {
+		rule, err := meshClient.V1Alpha1().AlertRule().Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// collectMesh returns services, instances and statistics of sidecars.
+func collectMesh(meshClient meshclient.MeshClient, tc telemetry.Client, timeout time.Duration) (*mesh, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	services, err := meshClient.V1Alpha1().Service().List(ctx)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return nil, errors.Wrap(err, "list services")
+	}
+	m := &mesh{tenants: map[string]string{}, instances: map[string]int{}}
+	for _, s := range services {
+		if s.Spec != nil {
+			m.tenants[s.Name()] = s.Spec.RegisterTenant
+		}
+	}
+
+	instances, err := meshClient.V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return nil, errors.Wrap(err, "list service instances")
+	}
+	for _, instance := range instances {
+		if instance.Spec != nil && instance.Spec.Status == "UP" {
+			m.instances[instance.Spec.ServiceName]++
+		}
+	}
+
+	m.objects, err = tc.Objects()
+	if err != nil {
+		return nil, errors.Wrap(err, "get telemetry of sidecars")
+	}
+
+	return m, nil
+}
+
+// evaluate returns alerts of the rules on every service in their scopes.
+func evaluate(rules []*resource.AlertRule, m *mesh) []*Alert {
+	alerts := []*Alert{}
+	for _, rule := range rules {
+		if rule.Spec == nil {
+			continue
+		}
+		for _, service := range m.servicesOf(rule.Spec) {
+			alerts = append(alerts, evaluateService(rule, service, m))
+		}
+	}
+	return alerts
+}
+
+// servicesOf returns services in the scope of the rule, a service rule
+// applies even if the service is not registered.
+func (m *mesh) servicesOf(spec *resource.AlertRuleSpec) []string {
+	if spec.Tenant == "" {
+		return []string{spec.Service}
+	}
+
+	services := []string{}
+	for service, tenant := range m.tenants {
+		if tenant == spec.Tenant {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+func evaluateService(rule *resource.AlertRule, service string, m *mesh) *Alert {
+	spec := rule.Spec
+	alert := &Alert{
+		Rule:        rule.Name(),
+		Service:     service,
+		Tenant:      m.tenants[service],
+		Metric:      spec.Metric(),
+		Condition:   spec.Condition(),
+		Severity:    spec.SeverityOrDefault(),
+		forDuration: spec.ForDuration(),
+		webhook:     spec.Webhook,
+	}
+
+	switch {
+	case spec.Latency != nil:
+		alert.NoData = true
+		percentile := spec.Latency.PercentileOrDefault()
+		for _, s := range telemetry.IngressStats(m.objects, service, "") {
+			// NOTE: Percentiles of sidecars could not be merged, so the
+			// worst one stands for the service.
+			if latency, ok := s.Latency(percentile); ok {
+				alert.NoData = false
+				if latency > alert.Value {
+					alert.Value = latency
+				}
+			}
+		}
+		threshold, _ := spec.Latency.ThresholdDuration()
+		alert.Firing = !alert.NoData && alert.Value > float64(threshold)/float64(time.Millisecond)
+	case spec.ErrorRate != nil:
+		total := &telemetry.Stat{}
+		for _, s := range telemetry.IngressStats(m.objects, service, "") {
+			total.Add(s)
+		}
+		alert.NoData = total.M1 == 0
+		if !alert.NoData {
+			alert.Value = 100 * total.M1Err / total.M1
+		}
+		alert.Firing = alert.Value > spec.ErrorRate.Threshold
+	case spec.InstanceCount != nil:
+		alert.Value = float64(m.instances[service])
+		alert.Firing = alert.Value < float64(spec.InstanceCount.Min)
+	}
+
+	return alert
+}
+
+// FormattedValue returns the value of the metric with its unit.
+func (a *Alert) FormattedValue() string {
+	if a.NoData {
+		return "-"
+	}
+	switch a.Metric {
+	case resource.AlertMetricLatency:
+		return fmt.Sprintf("%gms", a.Value)
+	case resource.AlertMetricErrorRate:
+		return fmt.Sprintf("%.2f%%", a.Value)
+	default:
+		return fmt.Sprintf("%g", a.Value)
+	}
+}
+
+func (a *Alert) state() string {
+	if a.Firing {
+		return "firing"
+	}
+	return "ok"
+}
+
+func printAlerts(w io.Writer, alerts []*Alert) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Rule", "Service", "Tenant", "Condition", "Value", "Severity", "State"})
+	table.SetBorder(false)
+	for _, a := range alerts {
+		table.Append([]string{a.Rule, a.Service, a.Tenant, a.Condition, a.FormattedValue(), a.Severity, a.state()})
+	}
+	table.Render()
+}
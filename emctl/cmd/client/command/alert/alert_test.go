@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alert
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const testObjects = `
+easemesh-controller:
+  order-service-6b7d9:
+    sidecar-ingress-order-service:
+      m1: 10
+      m1Err: 0.5
+      p99: 400
+  order-service-8c4f2:
+    sidecar-ingress-order-service:
+      m1: 10
+      m1Err: 0.1
+      p99: 700
+`
+
+func newRule(name string, spec *resource.AlertRuleSpec) *resource.AlertRule {
+	return &resource.AlertRule{
+		MeshResource: resource.NewAlertRuleResource(resource.DefaultAPIVersion, name),
+		Spec:         spec,
+	}
+}
+
+func testMesh(t *testing.T) *mesh {
+	m := &mesh{
+		tenants:   map[string]string{"order-service": "shop", "pet-service": "shop", "vets-service": "pet"},
+		instances: map[string]int{"order-service": 2, "pet-service": 1},
+	}
+	err := yaml.Unmarshal([]byte(testObjects), &m.objects)
+	if err != nil {
+		t.Fatalf("unmarshal objects failed: %v", err)
+	}
+	return m
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := []*resource.AlertRule{
+		newRule("order-latency", &resource.AlertRuleSpec{Service: "order-service", Latency: &resource.AlertLatency{Threshold: "500ms"}}),
+		newRule("order-errors", &resource.AlertRuleSpec{Service: "order-service", ErrorRate: &resource.AlertErrorRate{Threshold: 5}}),
+		newRule("shop-instances", &resource.AlertRuleSpec{Tenant: "shop", InstanceCount: &resource.AlertInstanceCount{Min: 2}}),
+		newRule("vets-errors", &resource.AlertRuleSpec{Service: "vets-service", ErrorRate: &resource.AlertErrorRate{Threshold: 5}}),
+	}
+
+	alerts := evaluate(rules, testMesh(t))
+	if len(alerts) != 5 {
+		t.Fatalf("expected 5 alerts, got %d", len(alerts))
+	}
+	for i, c := range []struct {
+		service string
+		value   float64
+		firing  bool
+	}{
+		{"order-service", 700, true},
+		{"order-service", 3, false},
+		{"order-service", 2, false},
+		{"pet-service", 1, true},
+		{"vets-service", 0, false},
+	} {
+		a := alerts[i]
+		if a.Service != c.service || a.Value != c.value || a.Firing != c.firing {
+			t.Fatalf("alert %d: expected %+v, got %+v", i, c, a)
+		}
+	}
+	if !alerts[4].NoData || alerts[4].FormattedValue() != "-" {
+		t.Fatalf("expected no data of vets-service, got %+v", alerts[4])
+	}
+}
+
+func TestExport(t *testing.T) {
+	rules := []*resource.AlertRule{
+		newRule("order-latency", &resource.AlertRuleSpec{Service: "order-service", Latency: &resource.AlertLatency{Threshold: "500ms"}, For: "90s"}),
+		newRule("shop-errors", &resource.AlertRuleSpec{Tenant: "shop", ErrorRate: &resource.AlertErrorRate{Threshold: 5}, Severity: "critical"}),
+	}
+	flag := &flags.AlertExport{Name: "easemesh-alerts", Namespace: "easemesh", MetricPrefix: "easemesh", RateWindow: 5 * time.Minute}
+
+	r := toPrometheusRule(rules, flag)
+	alerts := r.Spec.Groups[0].Rules
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alert rules, got %d", len(alerts))
+	}
+	if alerts[0].Alert != "OrderLatency" || alerts[0].For != "90s" || alerts[0].Labels["severity"] != "warning" {
+		t.Fatalf("unexpected alert rule %+v", alerts[0])
+	}
+	expected := `histogram_quantile(0.99, sum by (service, le) (rate(easemesh_http_request_duration_seconds_bucket{service="order-service"}[5m]))) > 0.5`
+	if alerts[0].Expr != expected {
+		t.Fatalf("expected expression %s, got %s", expected, alerts[0].Expr)
+	}
+	if !strings.Contains(alerts[1].Expr, `easemesh_http_requests_total{tenant="shop"}[5m]`) || alerts[1].For != "" {
+		t.Fatalf("unexpected alert rule %+v", alerts[1])
+	}
+
+	for name, expected := range map[string]string{"order-latency": "OrderLatency", "5xx_errors": "_5xxErrors"} {
+		if got := alertName(name); got != expected {
+			t.Fatalf("expected alert name %s of %s, got %s", expected, name, got)
+		}
+	}
+	if d := promDuration(1500 * time.Millisecond); d != "1500ms" {
+		t.Fatalf("expected 1500ms, got %s", d)
+	}
+}
+
+func TestPush(t *testing.T) {
+	sent := map[string]*webhookMessage{}
+	var sendErr error
+	p := newPusher("http://default", true, time.Second)
+	p.send = func(webhook string, message *webhookMessage) error {
+		sent[webhook] = message
+		return sendErr
+	}
+
+	alert := func(rule string, firing bool, webhook string) *Alert {
+		return &Alert{Rule: rule, Service: "order-service", Firing: firing, forDuration: time.Minute, webhook: webhook}
+	}
+	now := time.Now()
+
+	// Pending for a minute before firing.
+	err := p.push([]*Alert{alert("latency", true, ""), alert("errors", false, "http://errors")}, now)
+	if err != nil || len(sent) != 0 {
+		t.Fatalf("expected nothing pushed, got %+v, %v", sent, err)
+	}
+	sendErr = errors.New("unavailable")
+	err = p.push([]*Alert{alert("latency", true, ""), alert("errors", true, "http://errors")}, now.Add(time.Minute))
+	if err == nil || sent["http://default"].Status != statusFiring || len(p.firing) != 0 {
+		t.Fatalf("expected firing alerts rolled back after failed push, got %+v, %v", sent, err)
+	}
+
+	sendErr, sent = nil, map[string]*webhookMessage{}
+	err = p.push([]*Alert{alert("latency", true, ""), alert("errors", true, "http://errors")}, now.Add(2*time.Minute))
+	if err != nil || len(sent) != 2 || len(sent["http://default"].Alerts) != 1 {
+		t.Fatalf("expected alerts pushed to both webhooks, got %+v, %v", sent, err)
+	}
+	if startsAt := sent["http://default"].Alerts[0].StartsAt; !startsAt.Equal(now) {
+		t.Fatalf("expected alert started at %s, got %s", now, startsAt)
+	}
+
+	// Firing alerts are pushed once, and pushed again when resolved.
+	sent = map[string]*webhookMessage{}
+	err = p.push([]*Alert{alert("latency", true, ""), alert("errors", true, "http://errors")}, now.Add(3*time.Minute))
+	if err != nil || len(sent) != 0 {
+		t.Fatalf("expected nothing pushed again, got %+v, %v", sent, err)
+	}
+	sent = map[string]*webhookMessage{}
+	err = p.push([]*Alert{alert("latency", false, "")}, now.Add(4*time.Minute))
+	if err != nil || sent["http://default"].Status != statusResolved || sent["http://errors"].Status != statusResolved {
+		t.Fatalf("expected both alerts resolved, got %+v, %v", sent, err)
+	}
+	if len(p.firing) != 0 || len(p.since) != 0 {
+		t.Fatalf("expected no state left, got %+v, %+v", p.firing, p.since)
+	}
+}
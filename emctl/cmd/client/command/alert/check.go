@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// RunCheck is the entrypoint of the emctl alert check sub command
+func RunCheck(cmd *cobra.Command, flag *flags.AlertCheck) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	meshClient := meshclient.New(flag.Server)
+	rules, err := listRules(meshClient, flag.Timeout, cmd.Flags().Args())
+	if err != nil {
+		if meshclient.IsNotFoundError(err) {
+			common.ExitWithError(common.WithCode(err, common.ExitCodeNotFound))
+		}
+		common.ExitWithErrorf("list alert rules failed: %w", err)
+	}
+	if len(rules) == 0 {
+		common.Infof("no alert rule found, create alert rules by emctl apply")
+		return
+	}
+
+	m, err := collectMesh(meshClient, telemetry.New(flag.Server, flag.Timeout), flag.Timeout)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	alerts := evaluate(rules, m)
+
+	switch flag.OutputFormat {
+	case "table":
+		printAlerts(os.Stdout, alerts)
+	case "yaml":
+		buff, err := yaml.Marshal(alerts)
+		if err != nil {
+			common.ExitWithErrorf("marshal alerts failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(alerts, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal alerts failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
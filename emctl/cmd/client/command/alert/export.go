@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+type (
+	// prometheusRule is the PrometheusRule object of the Prometheus Operator.
+	prometheusRule struct {
+		APIVersion string                 `json:"apiVersion"`
+		Kind       string                 `json:"kind"`
+		Metadata   prometheusRuleMetadata `json:"metadata"`
+		Spec       prometheusRuleSpec     `json:"spec"`
+	}
+
+	prometheusRuleMetadata struct {
+		Name      string            `json:"name"`
+		Namespace string            `json:"namespace,omitempty"`
+		Labels    map[string]string `json:"labels,omitempty"`
+	}
+
+	prometheusRuleSpec struct {
+		Groups []prometheusRuleGroup `json:"groups"`
+	}
+
+	prometheusRuleGroup struct {
+		Name  string                `json:"name"`
+		Rules []prometheusAlertRule `json:"rules"`
+	}
+
+	prometheusAlertRule struct {
+		Alert       string            `json:"alert"`
+		Expr        string            `json:"expr"`
+		For         string            `json:"for,omitempty"`
+		Labels      map[string]string `json:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}
+)
+
+// RunExport is the entrypoint of the emctl alert export sub command
+func RunExport(cmd *cobra.Command, flag *flags.AlertExport) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	if flag.RateWindow <= 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "--rate-window must be positive, got %s", flag.RateWindow)
+	}
+
+	rules, err := listRules(meshclient.New(flag.Server), flag.Timeout, cmd.Flags().Args())
+	if err != nil {
+		if meshclient.IsNotFoundError(err) {
+			common.ExitWithError(common.WithCode(err, common.ExitCodeNotFound))
+		}
+		common.ExitWithErrorf("list alert rules failed: %w", err)
+	}
+	if len(rules) == 0 {
+		common.Warnf("no alert rule found, the PrometheusRule has no rules")
+	}
+
+	buff, err := yaml.Marshal(toPrometheusRule(rules, flag))
+	if err != nil {
+		common.ExitWithErrorf("marshal PrometheusRule failed: %w", err)
+	}
+	fmt.Print(string(buff))
+}
+
+// toPrometheusRule converts alert rules to a PrometheusRule, whose
+// expressions query metrics of sidecars with labels service and tenant.
+func toPrometheusRule(rules []*resource.AlertRule, flag *flags.AlertExport) *prometheusRule {
+	group := prometheusRuleGroup{Name: "easemesh", Rules: []prometheusAlertRule{}}
+	for _, rule := range rules {
+		if rule.Spec == nil {
+			continue
+		}
+		spec := rule.Spec
+		alert := prometheusAlertRule{
+			Alert: alertName(rule.Name()),
+			Expr:  expression(spec, flag.MetricPrefix, flag.RateWindow),
+			Labels: map[string]string{
+				"rule":     rule.Name(),
+				"severity": spec.SeverityOrDefault(),
+			},
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("%s of {{ $labels.service }} is {{ $value }}", spec.Condition()),
+			},
+		}
+		if d := spec.ForDuration(); d > 0 {
+			alert.For = promDuration(d)
+		}
+		group.Rules = append(group.Rules, alert)
+	}
+
+	return &prometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata: prometheusRuleMetadata{
+			Name:      flag.Name,
+			Namespace: flag.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "emctl"},
+		},
+		Spec: prometheusRuleSpec{Groups: []prometheusRuleGroup{group}},
+	}
+}
+
+// expression returns the PromQL expression of the rule, which has a
+// series for every service in the scope of the rule.
+func expression(spec *resource.AlertRuleSpec, prefix string, rateWindow time.Duration) string {
+	window := promDuration(rateWindow)
+	selector := fmt.Sprintf(`service="%s"`, spec.Service)
+	if spec.Tenant != "" {
+		selector = fmt.Sprintf(`tenant="%s"`, spec.Tenant)
+	}
+
+	switch {
+	case spec.Latency != nil:
+		threshold, _ := spec.Latency.ThresholdDuration()
+		return fmt.Sprintf("histogram_quantile(%s, sum by (service, le) (rate(%s_http_request_duration_seconds_bucket{%s}[%s]))) > %s",
+			formatFloat(spec.Latency.PercentileOrDefault()/100), prefix, selector, window, formatFloat(threshold.Seconds()))
+	case spec.ErrorRate != nil:
+		return fmt.Sprintf("100 * sum by (service) (rate(%s_http_request_errors_total{%s}[%s])) / sum by (service) (rate(%s_http_requests_total{%s}[%s])) > %s",
+			prefix, selector, window, prefix, selector, window, formatFloat(spec.ErrorRate.Threshold))
+	case spec.InstanceCount != nil:
+		return fmt.Sprintf(`sum by (service) (%s_service_instances{%s,status="UP"}) < %d`,
+			prefix, selector, spec.InstanceCount.Min)
+	default:
+		return ""
+	}
+}
+
+// alertName converts the rule name like order-latency to a valid alert
+// name like OrderLatency.
+func alertName(name string) string {
+	b := strings.Builder{}
+	upper := true
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if b.Len() == 0 && unicode.IsDigit(r) {
+				b.WriteRune('_')
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// promDuration formats the duration in the Prometheus way, which doesn't
+// support fractions like 1.5m of Go.
+func promDuration(d time.Duration) string {
+	for _, u := range []struct {
+		unit   time.Duration
+		suffix string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}} {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d%s", d/u.unit, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
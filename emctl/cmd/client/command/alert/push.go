@@ -0,0 +1,281 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alert
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	statusFiring   = "firing"
+	statusResolved = "resolved"
+)
+
+type (
+	// webhookMessage is the message of the Alertmanager webhook format,
+	// so receivers of Alertmanager accept it as well.
+	webhookMessage struct {
+		Version  string          `json:"version"`
+		Status   string          `json:"status"`
+		Receiver string          `json:"receiver"`
+		Alerts   []*webhookAlert `json:"alerts"`
+	}
+
+	webhookAlert struct {
+		Status      string            `json:"status"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+		StartsAt    time.Time         `json:"startsAt"`
+		EndsAt      *time.Time        `json:"endsAt,omitempty"`
+	}
+
+	firingAlert struct {
+		alert   *webhookAlert
+		webhook string
+	}
+
+	// pusher pushes alerts to webhooks when they start firing or resolve.
+	pusher struct {
+		webhook string
+		// waitFor waits for the for duration of rules before firing,
+		// which needs evaluating repeatedly.
+		waitFor bool
+		send    func(webhook string, message *webhookMessage) error
+
+		// since are the times conditions started holding, and firing
+		// are alerts pushed as firing, both keyed by rule and service.
+		since  map[string]time.Time
+		firing map[string]*firingAlert
+	}
+)
+
+// RunPush is the entrypoint of the emctl alert push sub command
+func RunPush(cmd *cobra.Command, flag *flags.AlertPush) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+	if flag.Interval < 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "--interval must not be negative, got %s", flag.Interval)
+	}
+
+	meshClient := meshclient.New(flag.Server)
+	names := cmd.Flags().Args()
+	p := newPusher(flag.Webhook, flag.Interval > 0, flag.Timeout)
+
+	round := func() error {
+		rules, err := listRules(meshClient, flag.Timeout, names)
+		if err != nil {
+			return errors.Wrap(err, "list alert rules")
+		}
+		if missing := rulesWithoutWebhook(rules, flag.Webhook); len(missing) != 0 {
+			return errors.Errorf("alert rules %s have no webhook, specify the default one by --webhook",
+				strings.Join(missing, ", "))
+		}
+		m, err := collectMesh(meshClient, telemetry.New(flag.Server, flag.Timeout), flag.Timeout)
+		if err != nil {
+			return err
+		}
+		return p.push(evaluate(rules, m), time.Now())
+	}
+
+	if flag.Interval == 0 {
+		if err := round(); err != nil {
+			common.ExitWithErrorf("push alerts failed: %w", err)
+		}
+		return
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	common.Infof("evaluating alert rules every %s", flag.Interval)
+	ticker := time.NewTicker(flag.Interval)
+	defer ticker.Stop()
+	for {
+		if err := round(); err != nil {
+			common.Warnf("push alerts failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func rulesWithoutWebhook(rules []*resource.AlertRule, webhook string) []string {
+	missing := []string{}
+	if webhook != "" {
+		return missing
+	}
+	for _, rule := range rules {
+		if rule.Spec != nil && rule.Spec.Webhook == "" {
+			missing = append(missing, rule.Name())
+		}
+	}
+	return missing
+}
+
+func newPusher(webhook string, waitFor bool, timeout time.Duration) *pusher {
+	return &pusher{
+		webhook: webhook,
+		waitFor: waitFor,
+		send: func(webhook string, message *webhookMessage) error {
+			_, err := client.NewHTTPJSON().
+				Post(webhook, message, timeout, nil).
+				HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+					if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+						return nil, errors.Errorf("push alerts to %s failed, status code: %d, body: %s", webhook, statusCode, body)
+					}
+					return nil, nil
+				})
+			return err
+		},
+		since:  map[string]time.Time{},
+		firing: map[string]*firingAlert{},
+	}
+}
+
+// push pushes alerts starting firing, and alerts resolved since the last
+// push, to their webhooks. Alerts of rules or services gone are resolved.
+func (p *pusher) push(alerts []*Alert, now time.Time) error {
+	messages := map[string]*webhookMessage{}
+	add := func(webhook string, alert *webhookAlert) {
+		message, ok := messages[webhook]
+		if !ok {
+			message = &webhookMessage{Version: "4", Status: statusResolved, Receiver: "emctl"}
+			messages[webhook] = message
+		}
+		if alert.Status == statusFiring {
+			message.Status = statusFiring
+		}
+		message.Alerts = append(message.Alerts, alert)
+	}
+	resolve := func(key string) {
+		fired := p.firing[key]
+		fired.alert.Status, fired.alert.EndsAt = statusResolved, &now
+		add(fired.webhook, fired.alert)
+		delete(p.firing, key)
+	}
+
+	seen := map[string]bool{}
+	for _, a := range alerts {
+		key := a.Rule + "/" + a.Service
+		seen[key] = true
+		if !a.Firing {
+			delete(p.since, key)
+			if _, ok := p.firing[key]; ok {
+				resolve(key)
+			}
+			continue
+		}
+
+		since, ok := p.since[key]
+		if !ok {
+			since = now
+			p.since[key] = now
+		}
+		if _, ok := p.firing[key]; ok || (p.waitFor && now.Sub(since) < a.forDuration) {
+			continue
+		}
+
+		webhook := a.webhook
+		if webhook == "" {
+			webhook = p.webhook
+		}
+		fired := &firingAlert{alert: a.toWebhookAlert(since), webhook: webhook}
+		p.firing[key] = fired
+		add(webhook, fired.alert)
+	}
+	for key := range p.firing {
+		if !seen[key] {
+			delete(p.since, key)
+			resolve(key)
+		}
+	}
+
+	webhooks := []string{}
+	for webhook := range messages {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Strings(webhooks)
+	var lastErr error
+	for _, webhook := range webhooks {
+		message := messages[webhook]
+		err := p.send(webhook, message)
+		if err != nil {
+			p.rollback(webhook, message)
+			lastErr = err
+			continue
+		}
+		common.Infof("pushed %d alerts to %s", len(message.Alerts), webhook)
+	}
+	return lastErr
+}
+
+// rollback restores states of alerts failed to push, so they're pushed
+// again next time.
+func (p *pusher) rollback(webhook string, message *webhookMessage) {
+	for _, alert := range message.Alerts {
+		key := alert.Labels["alertname"] + "/" + alert.Labels["service"]
+		if alert.Status == statusFiring {
+			delete(p.firing, key)
+			continue
+		}
+		alert.Status, alert.EndsAt = statusFiring, nil
+		p.firing[key] = &firingAlert{alert: alert, webhook: webhook}
+	}
+}
+
+func (a *Alert) toWebhookAlert(since time.Time) *webhookAlert {
+	labels := map[string]string{
+		"alertname": a.Rule,
+		"service":   a.Service,
+		"metric":    a.Metric,
+		"severity":  a.Severity,
+	}
+	if a.Tenant != "" {
+		labels["tenant"] = a.Tenant
+	}
+
+	return &webhookAlert{
+		Status: statusFiring,
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary": a.Condition + " of " + a.Service + ", current value is " + a.FormattedValue(),
+		},
+		StartsAt: since,
+	}
+}
@@ -19,6 +19,7 @@ package apply
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
@@ -38,46 +39,102 @@ var _ Applier = &serviceApplier{}
 type baseApplier struct {
 	client  meshclient.MeshClient
 	timeout time.Duration
+	// resourceVersion is the precondition of updating the object if it's not empty.
+	resourceVersion string
 }
 
-// WrapApplierByMeshObject returns a Applier from a MeshObject
+// context returns the context of requests to the control plane,
+// updates with it fail if the object has been modified since its resource version.
+func (b *baseApplier) context() context.Context {
+	return meshclient.WithResourceVersion(context.Background(), b.resourceVersion)
+}
+
+// checkHTTPService makes sure HTTP-only policies are not applied to tcp services.
+// The check is skipped if the service can't be got, e.g. it's applied later.
+func (b *baseApplier) checkHTTPService(ctx context.Context, service string, policies ...string) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	s, err := b.client.V1Alpha1().Service().Get(ctx, service)
+	if err != nil || s.Spec == nil || !s.Spec.IsTCP() {
+		return nil
+	}
+
+	return errors.Errorf("tcp service %s can't have HTTP-only policies: %s", service, strings.Join(policies, ", "))
+}
+
+// WrapApplierByMeshObject returns a Applier from a MeshObject, which keeps
+// labels and annotations of the object as well.
 func WrapApplierByMeshObject(object meta.MeshObject,
 	client meshclient.MeshClient, timeout time.Duration) Applier {
+	applier := wrapApplierByKind(object, client, timeout)
+	if !resource.HasResourceMeta(object.Kind()) {
+		return applier
+	}
+	return &resourceMetaApplier{
+		baseApplier: baseApplier{client: client, timeout: timeout},
+		applier:     applier,
+		object:      object,
+	}
+}
+
+func wrapApplierByKind(object meta.MeshObject,
+	client meshclient.MeshClient, timeout time.Duration) Applier {
+	base := baseApplier{client: client, timeout: timeout, resourceVersion: object.ResourceVersion()}
 	switch object.Kind() {
 	case resource.KindMeshController:
-		return &meshControllerApplier{object: object.(*resource.MeshController), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &meshControllerApplier{object: object.(*resource.MeshController), baseApplier: base}
 	case resource.KindService:
-		return &serviceApplier{object: object.(*resource.Service), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &serviceApplier{object: object.(*resource.Service), baseApplier: base}
 	case resource.KindServiceInstance:
-		return &serviceInstanceApplier{object: object.(*resource.ServiceInstance), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &serviceInstanceApplier{object: object.(*resource.ServiceInstance), baseApplier: base}
 	case resource.KindCanary:
-		return &canaryApplier{object: object.(*resource.Canary), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &canaryApplier{object: object.(*resource.Canary), baseApplier: base}
 	case resource.KindLoadBalance:
-		return &loadBalanceApplier{object: object.(*resource.LoadBalance), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &loadBalanceApplier{object: object.(*resource.LoadBalance), baseApplier: base}
 	case resource.KindTenant:
-		return &tenantApplier{object: object.(*resource.Tenant), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &tenantApplier{object: object.(*resource.Tenant), baseApplier: base}
 	case resource.KindResilience:
-		return &resilienceApplier{object: object.(*resource.Resilience), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &resilienceApplier{object: object.(*resource.Resilience), baseApplier: base}
 	case resource.KindMock:
-		return &mockApplier{object: object.(*resource.Mock), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &mockApplier{object: object.(*resource.Mock), baseApplier: base}
 	case resource.KindObservabilityMetrics:
-		return &observabilityMetricsApplier{object: object.(*resource.ObservabilityMetrics), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &observabilityMetricsApplier{object: object.(*resource.ObservabilityMetrics), baseApplier: base}
 	case resource.KindObservabilityOutputServer:
-		return &observabilityOutputServerApplier{object: object.(*resource.ObservabilityOutputServer), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &observabilityOutputServerApplier{object: object.(*resource.ObservabilityOutputServer), baseApplier: base}
 	case resource.KindObservabilityTracings:
-		return &observabilityTracingsApplier{object: object.(*resource.ObservabilityTracings), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &observabilityTracingsApplier{object: object.(*resource.ObservabilityTracings), baseApplier: base}
 	case resource.KindIngress:
-		return &ingressApplier{object: object.(*resource.Ingress), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &ingressApplier{object: object.(*resource.Ingress), baseApplier: base}
 	case resource.KindHTTPRouteGroup:
-		return &httpRouteGroupApplier{object: object.(*resource.HTTPRouteGroup), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &httpRouteGroupApplier{object: object.(*resource.HTTPRouteGroup), baseApplier: base}
 	case resource.KindTrafficTarget:
-		return &trafficTargetApplier{object: object.(*resource.TrafficTarget), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &trafficTargetApplier{object: object.(*resource.TrafficTarget), baseApplier: base}
 	case resource.KindServiceCanary:
-		return &serviceCanaryApplier{object: object.(*resource.ServiceCanary), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &serviceCanaryApplier{object: object.(*resource.ServiceCanary), baseApplier: base}
+	case resource.KindExternalService:
+		return &externalServiceApplier{object: object.(*resource.ExternalService), baseApplier: base}
+	case resource.KindTenantPolicy:
+		return &tenantPolicyApplier{object: object.(*resource.TenantPolicy), baseApplier: base}
+	case resource.KindSLO:
+		return &sloApplier{object: object.(*resource.SLO), baseApplier: base}
+	case resource.KindAlertRule:
+		return &alertRuleApplier{object: object.(*resource.AlertRule), baseApplier: base}
+	case resource.KindMessagingPolicy:
+		return &messagingPolicyApplier{object: object.(*resource.MessagingPolicy), baseApplier: base}
+	case resource.KindIngressPort:
+		return &ingressPortApplier{object: object.(*resource.IngressPort), baseApplier: base}
+	case resource.KindWAFPolicy:
+		return &wafPolicyApplier{object: object.(*resource.WAFPolicy), baseApplier: base}
+	case resource.KindMaintenanceMode:
+		return &maintenanceModeApplier{object: object.(*resource.MaintenanceMode), baseApplier: base}
+	case resource.KindPolicyRollout:
+		return &policyRolloutApplier{object: object.(*resource.PolicyRollout), baseApplier: base}
 	case resource.KindCustomResourceKind:
-		return &customResourceKindApplier{object: object.(*resource.CustomResourceKind), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &customResourceKindApplier{object: object.(*resource.CustomResourceKind), baseApplier: base}
 	default:
-		return &customResourceApplier{object: object.(*resource.CustomResource), baseApplier: baseApplier{client: client, timeout: timeout}}
+		return &customResourceApplier{object: object.(*resource.CustomResource), baseApplier: base}
 	}
 }
 
@@ -87,7 +144,7 @@ type meshControllerApplier struct {
 }
 
 func (mc *meshControllerApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), mc.timeout)
+	ctx, cancelFunc := context.WithTimeout(mc.context(), mc.timeout)
 	defer cancelFunc()
 	err := mc.client.V1Alpha1().MeshController().Create(ctx, mc.object)
 	for {
@@ -116,9 +173,14 @@ type serviceApplier struct {
 }
 
 func (s *serviceApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	err := s.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate service %s", s.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(s.context(), s.timeout)
 	defer cancelFunc()
-	err := s.client.V1Alpha1().Service().Create(ctx, s.object)
+	err = s.client.V1Alpha1().Service().Create(ctx, s.object)
 	for {
 		switch {
 		case err == nil:
@@ -154,8 +216,15 @@ type canaryApplier struct {
 }
 
 func (c *canaryApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancelFunc := context.WithTimeout(c.context(), c.timeout)
 	defer cancelFunc()
+	if c.object.Spec != nil {
+		err := c.checkHTTPService(ctx, c.object.Name(), "canary")
+		if err != nil {
+			return errors.Wrapf(err, "validate canary %s", c.object.Name())
+		}
+	}
+
 	err := c.client.V1Alpha1().Canary().Create(ctx, c.object)
 	for {
 		switch {
@@ -183,7 +252,7 @@ type observabilityTracingsApplier struct {
 }
 
 func (o *observabilityTracingsApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), o.timeout)
+	ctx, cancelFunc := context.WithTimeout(o.context(), o.timeout)
 	defer cancelFunc()
 	err := o.client.V1Alpha1().ObservabilityTracings().Create(ctx, o.object)
 	for {
@@ -212,7 +281,7 @@ type observabilityMetricsApplier struct {
 }
 
 func (o *observabilityMetricsApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), o.timeout)
+	ctx, cancelFunc := context.WithTimeout(o.context(), o.timeout)
 	defer cancelFunc()
 	err := o.client.V1Alpha1().ObservabilityMetrics().Create(ctx, o.object)
 	for {
@@ -241,7 +310,7 @@ type observabilityOutputServerApplier struct {
 }
 
 func (o *observabilityOutputServerApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), o.timeout)
+	ctx, cancelFunc := context.WithTimeout(o.context(), o.timeout)
 	defer cancelFunc()
 	err := o.client.V1Alpha1().ObservabilityOutputServer().Create(ctx, o.object)
 	for {
@@ -270,7 +339,7 @@ type loadBalanceApplier struct {
 }
 
 func (l *loadBalanceApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), l.timeout)
+	ctx, cancelFunc := context.WithTimeout(l.context(), l.timeout)
 	defer cancelFunc()
 	err := l.client.V1Alpha1().LoadBalance().Create(ctx, l.object)
 	for {
@@ -299,7 +368,7 @@ type tenantApplier struct {
 }
 
 func (t *tenantApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), t.timeout)
+	ctx, cancelFunc := context.WithTimeout(t.context(), t.timeout)
 	defer cancelFunc()
 	err := t.client.V1Alpha1().Tenant().Create(ctx, t.object)
 	for {
@@ -328,9 +397,19 @@ type resilienceApplier struct {
 }
 
 func (r *resilienceApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), r.timeout)
+	err := r.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate resilience %s", r.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(r.context(), r.timeout)
 	defer cancelFunc()
-	err := r.client.V1Alpha1().Resilience().Create(ctx, r.object)
+	err = r.checkHTTPService(ctx, r.object.Name(), r.object.HTTPOnlyPolicies()...)
+	if err != nil {
+		return errors.Wrapf(err, "validate resilience %s", r.object.Name())
+	}
+
+	err = r.client.V1Alpha1().Resilience().Create(ctx, r.object)
 	for {
 		switch {
 		case err == nil:
@@ -357,8 +436,15 @@ type mockApplier struct {
 }
 
 func (m *mockApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	ctx, cancelFunc := context.WithTimeout(m.context(), m.timeout)
 	defer cancelFunc()
+	if m.object.Spec != nil {
+		err := m.checkHTTPService(ctx, m.object.Name(), "mock")
+		if err != nil {
+			return errors.Wrapf(err, "validate mock %s", m.object.Name())
+		}
+	}
+
 	err := m.client.V1Alpha1().Mock().Create(ctx, m.object)
 	for {
 		switch {
@@ -386,9 +472,25 @@ type ingressApplier struct {
 }
 
 func (i *ingressApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), i.timeout)
+	err := i.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate ingress %s", i.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(i.context(), i.timeout)
 	defer cancelFunc()
-	err := i.client.V1Alpha1().Ingress().Create(ctx, i.object)
+	if i.object.Spec != nil {
+		for _, rule := range i.object.Spec.Rules {
+			for _, path := range rule.Paths {
+				err := i.checkHTTPService(ctx, path.Backend, "ingress path "+path.Path)
+				if err != nil {
+					return errors.Wrapf(err, "validate ingress %s", i.object.Name())
+				}
+			}
+		}
+	}
+
+	err = i.client.V1Alpha1().Ingress().Create(ctx, i.object)
 	for {
 		switch {
 		case err == nil:
@@ -415,7 +517,7 @@ type httpRouteGroupApplier struct {
 }
 
 func (g *httpRouteGroupApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), g.timeout)
+	ctx, cancelFunc := context.WithTimeout(g.context(), g.timeout)
 	defer cancelFunc()
 	err := g.client.V1Alpha1().HTTPRouteGroup().Create(ctx, g.object)
 	for {
@@ -444,7 +546,7 @@ type trafficTargetApplier struct {
 }
 
 func (tt *trafficTargetApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), tt.timeout)
+	ctx, cancelFunc := context.WithTimeout(tt.context(), tt.timeout)
 	defer cancelFunc()
 	err := tt.client.V1Alpha1().TrafficTarget().Create(ctx, tt.object)
 	for {
@@ -473,9 +575,23 @@ type serviceCanaryApplier struct {
 }
 
 func (sc *serviceCanaryApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), sc.timeout)
+	err := sc.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate serviceCanary %s", sc.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(sc.context(), sc.timeout)
 	defer cancelFunc()
-	err := sc.client.V1Alpha1().ServiceCanary().Create(ctx, sc.object)
+	if sc.object.Spec != nil && sc.object.Spec.Selector != nil {
+		for _, service := range sc.object.Spec.Selector.MatchServices {
+			err := sc.checkHTTPService(ctx, service, "serviceCanary")
+			if err != nil {
+				return errors.Wrapf(err, "validate serviceCanary %s", sc.object.Name())
+			}
+		}
+	}
+
+	err = sc.client.V1Alpha1().ServiceCanary().Create(ctx, sc.object)
 	for {
 		switch {
 		case err == nil:
@@ -496,13 +612,319 @@ func (sc *serviceCanaryApplier) Apply() error {
 	}
 }
 
+type externalServiceApplier struct {
+	baseApplier
+	object *resource.ExternalService
+}
+
+func (e *externalServiceApplier) Apply() error {
+	err := e.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate external service %s", e.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(e.context(), e.timeout)
+	defer cancelFunc()
+	err = e.client.V1Alpha1().ExternalService().Create(ctx, e.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = e.client.V1Alpha1().ExternalService().Patch(ctx, e.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update external service %s", e.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = e.client.V1Alpha1().ExternalService().Create(ctx, e.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create external service %s", e.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply external service %s", e.object.Name())
+		}
+	}
+}
+
+type tenantPolicyApplier struct {
+	baseApplier
+	object *resource.TenantPolicy
+}
+
+func (t *tenantPolicyApplier) Apply() error {
+	err := t.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate tenant policy %s", t.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(t.context(), t.timeout)
+	defer cancelFunc()
+	err = t.client.V1Alpha1().TenantPolicy().Create(ctx, t.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = t.client.V1Alpha1().TenantPolicy().Patch(ctx, t.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update tenant policy %s", t.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = t.client.V1Alpha1().TenantPolicy().Create(ctx, t.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create tenant policy %s", t.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply tenant policy %s", t.object.Name())
+		}
+	}
+}
+
+type sloApplier struct {
+	baseApplier
+	object *resource.SLO
+}
+
+func (s *sloApplier) Apply() error {
+	err := s.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate SLO %s", s.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(s.context(), s.timeout)
+	defer cancelFunc()
+	err = s.client.V1Alpha1().SLO().Create(ctx, s.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = s.client.V1Alpha1().SLO().Patch(ctx, s.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update SLO %s", s.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = s.client.V1Alpha1().SLO().Create(ctx, s.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create SLO %s", s.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply SLO %s", s.object.Name())
+		}
+	}
+}
+
+type alertRuleApplier struct {
+	baseApplier
+	object *resource.AlertRule
+}
+
+func (a *alertRuleApplier) Apply() error {
+	err := a.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate alert rule %s", a.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(a.context(), a.timeout)
+	defer cancelFunc()
+	err = a.client.V1Alpha1().AlertRule().Create(ctx, a.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = a.client.V1Alpha1().AlertRule().Patch(ctx, a.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update alert rule %s", a.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = a.client.V1Alpha1().AlertRule().Create(ctx, a.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create alert rule %s", a.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply alert rule %s", a.object.Name())
+		}
+	}
+}
+
+type messagingPolicyApplier struct {
+	baseApplier
+	object *resource.MessagingPolicy
+}
+
+func (m *messagingPolicyApplier) Apply() error {
+	err := m.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate messaging policy %s", m.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(m.context(), m.timeout)
+	defer cancelFunc()
+	err = m.client.V1Alpha1().MessagingPolicy().Create(ctx, m.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = m.client.V1Alpha1().MessagingPolicy().Patch(ctx, m.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update messaging policy %s", m.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = m.client.V1Alpha1().MessagingPolicy().Create(ctx, m.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create messaging policy %s", m.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply messaging policy %s", m.object.Name())
+		}
+	}
+}
+
+type ingressPortApplier struct {
+	baseApplier
+	object *resource.IngressPort
+}
+
+func (i *ingressPortApplier) Apply() error {
+	err := i.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate ingress port %s", i.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(i.context(), i.timeout)
+	defer cancelFunc()
+	err = i.client.V1Alpha1().IngressPort().Create(ctx, i.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = i.client.V1Alpha1().IngressPort().Patch(ctx, i.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update ingress port %s", i.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = i.client.V1Alpha1().IngressPort().Create(ctx, i.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create ingress port %s", i.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply ingress port %s", i.object.Name())
+		}
+	}
+}
+
+type wafPolicyApplier struct {
+	baseApplier
+	object *resource.WAFPolicy
+}
+
+func (i *wafPolicyApplier) Apply() error {
+	err := i.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate WAF policy %s", i.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(i.context(), i.timeout)
+	defer cancelFunc()
+	err = i.client.V1Alpha1().WAFPolicy().Create(ctx, i.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = i.client.V1Alpha1().WAFPolicy().Patch(ctx, i.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update WAF policy %s", i.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = i.client.V1Alpha1().WAFPolicy().Create(ctx, i.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create WAF policy %s", i.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply WAF policy %s", i.object.Name())
+		}
+	}
+}
+
+type maintenanceModeApplier struct {
+	baseApplier
+	object *resource.MaintenanceMode
+}
+
+func (m *maintenanceModeApplier) Apply() error {
+	err := m.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate maintenance mode %s", m.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(m.context(), m.timeout)
+	defer cancelFunc()
+	err = m.client.V1Alpha1().MaintenanceMode().Create(ctx, m.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = m.client.V1Alpha1().MaintenanceMode().Patch(ctx, m.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update maintenance mode %s", m.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = m.client.V1Alpha1().MaintenanceMode().Create(ctx, m.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create maintenance mode %s", m.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply maintenance mode %s", m.object.Name())
+		}
+	}
+}
+
+type policyRolloutApplier struct {
+	baseApplier
+	object *resource.PolicyRollout
+}
+
+func (m *policyRolloutApplier) Apply() error {
+	err := m.object.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "validate policy rollout %s", m.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(m.context(), m.timeout)
+	defer cancelFunc()
+	err = m.client.V1Alpha1().PolicyRollout().Create(ctx, m.object)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = m.client.V1Alpha1().PolicyRollout().Patch(ctx, m.object)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update policy rollout %s", m.object.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = m.client.V1Alpha1().PolicyRollout().Create(ctx, m.object)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create policy rollout %s", m.object.Name())
+			}
+		default:
+			return errors.Wrapf(err, "apply policy rollout %s", m.object.Name())
+		}
+	}
+}
+
 type customResourceKindApplier struct {
 	baseApplier
 	object *resource.CustomResourceKind
 }
 
 func (k *customResourceKindApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), k.timeout)
+	ctx, cancelFunc := context.WithTimeout(k.context(), k.timeout)
 	defer cancelFunc()
 	err := k.client.V1Alpha1().CustomResourceKind().Create(ctx, k.object)
 	for {
@@ -531,9 +953,14 @@ type customResourceApplier struct {
 }
 
 func (cra *customResourceApplier) Apply() error {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), cra.timeout)
+	ctx, cancelFunc := context.WithTimeout(cra.context(), cra.timeout)
 	defer cancelFunc()
-	err := cra.client.V1Alpha1().CustomResource().Create(ctx, cra.object)
+	err := cra.validate(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "validate custom resource %s", cra.object.Name())
+	}
+
+	err = cra.client.V1Alpha1().CustomResource().Create(ctx, cra.object)
 	for {
 		switch {
 		case err == nil:
@@ -553,3 +980,8 @@ func (cra *customResourceApplier) Apply() error {
 		}
 	}
 }
+
+// validate validates the custom resource by the JSON schema of its kind.
+func (cra *customResourceApplier) validate(ctx context.Context) error {
+	return validateCustomResource(ctx, cra.client, cra.object)
+}
@@ -40,7 +40,7 @@ func TestApplierCreateSuccessful(t *testing.T) {
 	types := meshtesting.GetAllResourceKinds()
 	client := meshclient.NewFakeClient(reactorType)
 	for _, tp := range types {
-		resource := meshtesting.CreateMeshObjectFromType(tp.Type, tp.Kind, "new")
+		resource := newResource(tp, "new")
 		err := WrapApplierByMeshObject(resource, client, time.Second*1).Apply()
 		if err != nil {
 			t.Fatalf("apply %+v, error:%s", resource, err)
@@ -71,7 +71,7 @@ func TestApplierLoopOver(t *testing.T) {
 	types := meshtesting.GetAllResourceKinds()
 	client := meshclient.NewFakeClient(reactorType)
 	for _, tp := range types {
-		resource := meshtesting.CreateMeshObjectFromType(tp.Type, tp.Kind, "new")
+		resource := newResource(tp, "new")
 		err := WrapApplierByMeshObject(resource, client, time.Second*1).Apply()
 		if err != nil {
 			t.Fatalf("apply %+v, error:%s", resource, err)
@@ -89,7 +89,7 @@ func TestApplierFastFail(t *testing.T) {
 	types := meshtesting.GetAllResourceKinds()
 	client := meshclient.NewFakeClient(reactorType)
 	for _, tp := range types {
-		resource := meshtesting.CreateMeshObjectFromType(tp.Type, tp.Kind, "new")
+		resource := newResource(tp, "new")
 		err := WrapApplierByMeshObject(resource, client, time.Second*1).Apply()
 		if err == nil {
 			t.Fatalf("apply %+v, error:%s", resource, err)
@@ -126,7 +126,7 @@ func TestApplierCreateFail(t *testing.T) {
 	types := meshtesting.GetAllResourceKinds()
 	client := meshclient.NewFakeClient(reactorType)
 	for _, tp := range types {
-		resource := meshtesting.CreateMeshObjectFromType(tp.Type, tp.Kind, "new")
+		resource := newResource(tp, "new")
 		err := WrapApplierByMeshObject(resource, client, time.Second*1).Apply()
 		if err == nil {
 			t.Fatalf("apply %+v, should raise an error", resource)
@@ -154,10 +154,83 @@ func TestApplierPatchFail(t *testing.T) {
 	types := meshtesting.GetAllResourceKinds()
 	client := meshclient.NewFakeClient(reactorType)
 	for _, tp := range types {
-		resource := meshtesting.CreateMeshObjectFromType(tp.Type, tp.Kind, "new")
+		resource := newResource(tp, "new")
 		err := WrapApplierByMeshObject(resource, client, time.Second*1).Apply()
 		if err == nil {
 			t.Fatalf("apply %+v, should raise an error", resource)
 		}
 	}
 }
+
+func TestCustomResourceApplierValidate(t *testing.T) {
+	reactorType := "__custom_resource_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("get", resource.KindCustomResourceKind, "ShadowService", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{&resource.CustomResourceKind{
+				MeshResource: resource.NewCustomResourceKindResource(resource.DefaultAPIVersion, "ShadowService"),
+				Spec: &resource.CustomResourceKindSpec{
+					JSONSchema: resource.DynamicObject{
+						"type":     "object",
+						"required": []interface{}{"serviceName"},
+						"properties": map[string]interface{}{
+							"serviceName": map[string]interface{}{"type": "string"},
+						},
+					},
+				},
+			}}, nil
+		}).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+	client := meshclient.NewFakeClient(reactorType)
+
+	newShadowService := func(spec map[string]interface{}) *resource.CustomResource {
+		return &resource.CustomResource{
+			MeshResource: resource.NewMeshResource(resource.DefaultAPIVersion, "ShadowService", "vets-shadow"),
+			Spec:         spec,
+		}
+	}
+
+	err := WrapApplierByMeshObject(newShadowService(map[string]interface{}{"serviceName": "vets-service"}), client, time.Second).Apply()
+	if err != nil {
+		t.Fatalf("apply valid custom resource failed: %v", err)
+	}
+
+	for _, spec := range []map[string]interface{}{
+		{},
+		{"serviceName": 1},
+	} {
+		err = WrapApplierByMeshObject(newShadowService(spec), client, time.Second).Apply()
+		if err == nil {
+			t.Fatalf("apply custom resource %+v violating the schema of its kind should fail", spec)
+		}
+	}
+}
+
+// newResource creates the resource of the kind, maintenance modes get valid specs
+// since applying them without specs fails.
+func newResource(tp meshtesting.ResourceTypeKind, name string) meta.MeshObject {
+	if tp.Kind == resource.KindMaintenanceMode {
+		return &resource.MaintenanceMode{
+			MeshResource: resource.NewMaintenanceModeResource(resource.DefaultAPIVersion, name),
+			Spec:         &resource.MaintenanceModeSpec{Service: name},
+		}
+	}
+	return meshtesting.CreateMeshObjectFromType(tp.Type, tp.Kind, name)
+}
+
+func TestApplierMaintenanceModeWithoutSpec(t *testing.T) {
+	reactorType := "__reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	mode := meshtesting.CreateMeshObjectFromType(reflect.TypeOf(resource.MaintenanceMode{}), resource.KindMaintenanceMode, "new")
+	err := WrapApplierByMeshObject(mode, meshclient.NewFakeClient(reactorType), time.Second*1).Apply()
+	if err == nil {
+		t.Fatalf("apply maintenance mode without spec should fail")
+	}
+}
@@ -18,10 +18,11 @@
 package apply
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 	"github.com/megaease/easemeshctl/cmd/client/util"
 	"github.com/megaease/easemeshctl/cmd/common"
@@ -37,32 +38,67 @@ func Run(cmd *cobra.Command, flag *flags.Apply) {
 	}
 
 	if flag.YamlFile == "" {
-		common.ExitWithErrorf("no resource specified")
+		common.ExitWithCodef(common.ExitCodeValidation, "no resource specified")
+	}
+
+	client := meshclient.New(flag.Server)
+
+	var staged *StagedApplier
+	if flag.Staged != "" {
+		if flag.Prune {
+			common.ExitWithCodef(common.ExitCodeValidation, "--staged could not be used with --prune")
+		}
+		stages, err := ParseStages(flag.Staged)
+		if err != nil {
+			common.ExitWithError(common.WithCode(errors.Wrap(err, "parse stages"), common.ExitCodeValidation))
+		}
+		staged = NewStagedApplier(client, telemetry.New(flag.Server, flag.Timeout),
+			stages, flag.BakeTime, flag.MaxErrorRate, flag.Timeout)
+	}
+
+	var pruner *Pruner
+	if flag.Selector != "" || flag.Prune {
+		var err error
+		pruner, err = NewPruner(client, flag.Selector, flag.Timeout)
+		if err != nil {
+			common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+		}
+	}
+
+	template, err := util.NewTemplate(flag.Values, flag.Set, flag.Env)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
 	}
 
 	vss, err := util.NewVisitorBuilder().
+		Strict(flag.Validate).
+		Template(template).
 		FilenameParam(&util.FilenameOptions{
 			Recursive: flag.Recursive,
 			Filenames: []string{flag.YamlFile},
 		}).
 		Do()
 	if err != nil {
-		common.ExitWithErrorf("build visitor failed: %v", err)
+		common.ExitWithErrorf("build visitor failed: %w", err)
 	}
 
 	var errs []error
+	var objects []meta.MeshObject
 	for _, vs := range vss {
 		err := vs.Visit(func(mo meta.MeshObject, e error) error {
 			if e != nil {
 				return errors.Wrap(e, "visit failed")
 			}
 
-			err := WrapApplierByMeshObject(mo, meshclient.New(flag.Server), flag.Timeout).Apply()
-			if err != nil {
-				return fmt.Errorf("%s/%s applied failed: %s", mo.Kind(), mo.Name(), err)
+			if pruner != nil && !pruner.Matches(mo) {
+				return nil
+			}
+
+			if flag.Force {
+				mo.(meta.MetaDataSetter).SetResourceVersion("")
 			}
 
-			fmt.Printf("%s/%s applied successfully\n", mo.Kind(), mo.Name())
+			objects = append(objects, mo)
 			return nil
 		})
 
@@ -73,7 +109,78 @@ func Run(cmd *cobra.Command, flag *flags.Apply) {
 		}
 	}
 
+	if len(errs) > 0 && !flag.ContinueOnError {
+		common.ExitWithCodef(common.ExitCodeOf(errs...), "reading resources has errors occurred, nothing applied")
+	}
+
+	missing, err := MissingReferences(client, objects, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("check references failed: %w", err)
+	}
+	for _, m := range missing {
+		if flag.AllowMissingRefs {
+			common.Warnf("%s", m)
+		} else {
+			common.OutputErrorf("%s", m)
+		}
+	}
+	if len(missing) > 0 && !flag.AllowMissingRefs {
+		common.ExitWithCodef(common.ExitCodeValidation,
+			"%d references to missing resources, nothing applied, apply them together or pass --allow-missing-refs", len(missing))
+	}
+
+	concurrency := flag.Concurrency
+	if staged != nil {
+		// NOTE: Stages of rollouts are baked one by one.
+		concurrency = 1
+	}
+
+	results := BulkApply(objects, concurrency, flag.ContinueOnError, func(mo meta.MeshObject) error {
+		var err error
+		if staged != nil {
+			err = staged.Apply(mo)
+		} else {
+			err = WrapApplierByMeshObject(mo, client, flag.Timeout).Apply()
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "%s/%s applied failed", mo.Kind(), mo.Name())
+			common.OutputError(err)
+			return err
+		}
+
+		common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+			Infof("%s/%s applied successfully", mo.Kind(), mo.Name())
+		return nil
+	})
+
+	if len(results) > 1 {
+		printResults(os.Stdout, results)
+	}
+
+	var applied []meta.MeshObject
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			errs = append(errs, result.Err)
+		case result.Result == resultApplied:
+			applied = append(applied, result.Object)
+		}
+	}
+
 	if len(errs) > 0 {
-		common.ExitWithErrorf("applying resources has errors occurred")
+		common.ExitWithCodef(common.ExitCodeOf(errs...), "applying resources has errors occurred")
+	}
+
+	// NOTE: Never prune after a partial failure, since the missing resources
+	// may be caused by the failure instead of removal from the input.
+	if flag.Prune {
+		pruned, err := pruner.Prune(applied)
+		for _, mo := range pruned {
+			common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+				Infof("%s/%s pruned", mo.Kind(), mo.Name())
+		}
+		if err != nil {
+			common.ExitWithErrorf("pruning resources failed: %w", err)
+		}
 	}
 }
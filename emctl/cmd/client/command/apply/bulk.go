@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	resultApplied = "applied"
+	resultFailed  = "failed"
+	resultSkipped = "skipped"
+)
+
+type (
+	// Result is the result of applying a resource shown in the summary.
+	Result struct {
+		Object   meta.MeshObject
+		Result   string
+		Duration time.Duration
+		Err      error
+	}
+
+	// ApplyFunc applies a resource.
+	ApplyFunc func(meta.MeshObject) error
+)
+
+// waveOf ranks kinds of resources, resources referred by others are applied
+// in earlier waves, e.g. tenants before services before policies of services.
+func waveOf(kind string) int {
+	switch kind {
+	case resource.KindCustomResourceKind, resource.KindMeshController, resource.KindTenant:
+		return 0
+	case resource.KindService, resource.KindExternalService:
+		return 1
+	}
+	return 2
+}
+
+// BulkApply applies resources by concurrent workers wave by wave, see waveOf,
+// and returns their results in the order of the input. Once a resource fails,
+// resources not started yet are skipped, unless continueOnError.
+func BulkApply(objects []meta.MeshObject, concurrency int, continueOnError bool, apply ApplyFunc) []*Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	waves := [3][]int{}
+	for i, mo := range objects {
+		wave := waveOf(mo.Kind())
+		waves[wave] = append(waves[wave], i)
+	}
+
+	results := make([]*Result, len(objects))
+	var failed int32
+	for _, wave := range waves {
+		jobs := make(chan int)
+		wg := &sync.WaitGroup{}
+		for w := 0; w < concurrency && w < len(wave); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					result := &Result{Object: objects[i]}
+					results[i] = result
+
+					if !continueOnError && atomic.LoadInt32(&failed) != 0 {
+						result.Result = resultSkipped
+						continue
+					}
+
+					startTime := time.Now()
+					result.Err = apply(objects[i])
+					result.Duration = time.Since(startTime).Round(time.Millisecond)
+					if result.Err != nil {
+						atomic.StoreInt32(&failed, 1)
+						result.Result = resultFailed
+						continue
+					}
+					result.Result = resultApplied
+				}
+			}()
+		}
+
+		for _, i := range wave {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	return results
+}
+
+func printResults(w io.Writer, results []*Result) {
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Kind", "Name", "Result", "Duration", "Message"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Result]++
+
+		duration, message := "", ""
+		if result.Result != resultSkipped {
+			duration = result.Duration.String()
+		}
+		if result.Err != nil {
+			message = result.Err.Error()
+		}
+		table.Append([]string{result.Object.Kind(), result.Object.Name(), result.Result, duration, message})
+	}
+
+	table.Render()
+
+	fmt.Fprintf(w, "\n%d applied, %d failed, %d skipped\n",
+		counts[resultApplied], counts[resultFailed], counts[resultSkipped])
+}
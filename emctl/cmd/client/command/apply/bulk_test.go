@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+func bulkObjects() []meta.MeshObject {
+	return []meta.MeshObject{
+		&resource.LoadBalance{MeshResource: resource.NewLoadBalanceResource(resource.DefaultAPIVersion, "orders")},
+		&resource.Service{MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "orders")},
+		&resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "shop")},
+		&resource.Service{MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "payments")},
+	}
+}
+
+func TestBulkApply(t *testing.T) {
+	objects := bulkObjects()
+
+	mutex := &sync.Mutex{}
+	waves := map[string]int{}
+	applied := 0
+	results := BulkApply(objects, 4, false, func(mo meta.MeshObject) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		waves[mo.Kind()+"/"+mo.Name()] = applied
+		applied++
+		return nil
+	})
+
+	for i, result := range results {
+		if result.Object != objects[i] || result.Result != resultApplied {
+			t.Fatalf("expected %s/%s applied in order of the input, got %+v", objects[i].Kind(), objects[i].Name(), result)
+		}
+	}
+	if waves["Tenant/shop"] != 0 || waves["LoadBalance/orders"] != 3 {
+		t.Fatalf("expected tenants applied first and policies last, got %v", waves)
+	}
+}
+
+func TestBulkApplyFailed(t *testing.T) {
+	failing := func(mo meta.MeshObject) error {
+		if mo.Kind() == resource.KindService && mo.Name() == "orders" {
+			return errors.New("mock an error")
+		}
+		return nil
+	}
+
+	results := BulkApply(bulkObjects(), 1, false, failing)
+	expected := []string{resultSkipped, resultFailed, resultApplied, resultSkipped}
+	for i, result := range results {
+		if result.Result != expected[i] {
+			t.Fatalf("expected result %s of %s/%s, got %s", expected[i], result.Object.Kind(), result.Object.Name(), result.Result)
+		}
+	}
+
+	results = BulkApply(bulkObjects(), 2, true, failing)
+	expected = []string{resultApplied, resultFailed, resultApplied, resultApplied}
+	for i, result := range results {
+		if result.Result != expected[i] {
+			t.Fatalf("expected result %s of %s/%s with continue-on-error, got %s", expected[i], result.Object.Kind(), result.Object.Name(), result.Result)
+		}
+	}
+
+	buff := &bytes.Buffer{}
+	printResults(buff, results)
+	if !strings.Contains(buff.String(), "mock an error") || !strings.Contains(buff.String(), "3 applied, 1 failed, 0 skipped") {
+		t.Fatalf("unexpected summary:\n%s", buff.String())
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/delete"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Pruner deletes resources which were applied with the same label selector
+// last time but no longer appear in the input.
+type Pruner struct {
+	client   meshclient.MeshClient
+	timeout  time.Duration
+	selector labels.Selector
+}
+
+// NewPruner creates a Pruner for the label selector, e.g. app=orders.
+func NewPruner(client meshclient.MeshClient, selector string, timeout time.Duration) (*Pruner, error) {
+	if selector == "" {
+		return nil, errors.Errorf("prune requires a label selector")
+	}
+
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse label selector %s", selector)
+	}
+
+	return &Pruner{
+		client:   client,
+		timeout:  timeout,
+		selector: s,
+	}, nil
+}
+
+// Matches reports whether the object is selected by the label selector.
+func (p *Pruner) Matches(object meta.MeshObject) bool {
+	return p.selector.Matches(labels.Set(object.Labels()))
+}
+
+// applySetName returns the name of the apply set of the selector,
+// the string of a parsed selector is canonical.
+func (p *Pruner) applySetName() string {
+	return p.selector.String()
+}
+
+// Prune deletes the resources recorded last time but not applied this time,
+// then records the applied ones. It returns the pruned resources.
+func (p *Pruner) Prune(applied []meta.MeshObject) ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), p.timeout)
+	defer cancelFunc()
+
+	previous := []*resource.ApplySetEntry{}
+	applySet, err := p.client.V1Alpha1().ApplySet().Get(ctx, p.applySetName())
+	switch {
+	case err == nil:
+		previous = applySet.Spec.Resources
+	case meshclient.IsNotFoundError(err):
+	default:
+		return nil, errors.Wrapf(err, "get apply set %s", p.applySetName())
+	}
+
+	current := map[string]bool{}
+	entries := []*resource.ApplySetEntry{}
+	for _, object := range applied {
+		current[entryKey(object.Kind(), object.Name())] = true
+		entries = append(entries, &resource.ApplySetEntry{Kind: object.Kind(), Name: object.Name()})
+	}
+
+	pruned := []meta.MeshObject{}
+	for _, entry := range previous {
+		if current[entryKey(entry.Kind, entry.Name)] {
+			continue
+		}
+
+		object, err := resource.NewObjectCreator().NewFromResource(
+			resource.NewMeshResource(resource.DefaultAPIVersion, entry.Kind, entry.Name))
+		if err != nil {
+			return pruned, errors.Wrapf(err, "create %s/%s", entry.Kind, entry.Name)
+		}
+
+		err = delete.WrapDeleterByMeshObject(object, p.client, p.timeout).Delete()
+		if err != nil && !meshclient.IsNotFoundError(err) {
+			return pruned, errors.Wrapf(err, "prune %s/%s", entry.Kind, entry.Name)
+		}
+
+		pruned = append(pruned, object)
+	}
+
+	applySet = &resource.ApplySet{
+		MeshResource: resource.NewMeshResource(resource.DefaultAPIVersion, resource.KindApplySet, p.applySetName()),
+		Spec: &resource.ApplySetSpec{
+			Selector:  p.selector.String(),
+			Resources: entries,
+		},
+	}
+
+	err = p.client.V1Alpha1().ApplySet().Create(ctx, applySet)
+	if meshclient.IsConflictError(err) {
+		err = p.client.V1Alpha1().ApplySet().Patch(ctx, applySet)
+	}
+	if err != nil {
+		return pruned, errors.Wrapf(err, "record apply set %s", p.applySetName())
+	}
+
+	return pruned, nil
+}
+
+func entryKey(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+func TestPrune(t *testing.T) {
+	reactorType := "__prune_reactor"
+	tenantRequests := 0
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("get", resource.KindApplySet, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{&resource.ApplySet{
+				MeshResource: resource.NewMeshResource(resource.DefaultAPIVersion, resource.KindApplySet, "app=orders"),
+				Spec: &resource.ApplySetSpec{
+					Selector: "app=orders",
+					Resources: []*resource.ApplySetEntry{
+						{Kind: resource.KindTenant, Name: "orders"},
+						{Kind: resource.KindTenant, Name: "orders-legacy"},
+					},
+				},
+			}}, nil
+		}).
+		AddReactor("*", resource.KindTenant, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			tenantRequests++
+			return true, nil, nil
+		}).
+		AddReactor("*", resource.KindResourceMeta, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	client := meshclient.NewFakeClient(reactorType)
+	pruner, err := NewPruner(client, "app=orders", time.Second)
+	if err != nil {
+		t.Fatalf("new pruner failed: %v", err)
+	}
+
+	tenant := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "orders")}
+	tenant.MetaData.Labels = map[string]string{"app": "orders"}
+	if !pruner.Matches(tenant) {
+		t.Fatalf("tenant with label app=orders should match the selector")
+	}
+
+	other := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "payments")}
+	if pruner.Matches(other) {
+		t.Fatalf("tenant without labels should not match the selector")
+	}
+
+	pruned, err := pruner.Prune([]meta.MeshObject{tenant})
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].Name() != "orders-legacy" {
+		t.Fatalf("only orders-legacy should be pruned, but got %+v", pruned)
+	}
+	if tenantRequests != 1 {
+		t.Fatalf("one tenant should be deleted, but got %d requests", tenantRequests)
+	}
+
+	_, err = NewPruner(client, "", time.Second)
+	if err == nil {
+		t.Fatalf("prune without selector should fail")
+	}
+}
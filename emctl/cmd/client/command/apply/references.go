@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+// MissingReferences returns references of the objects to resources neither
+// in the objects nor in the control plane, e.g. a service registered to a
+// missing tenant, or an ingress routing to an unknown service.
+func MissingReferences(client meshclient.MeshClient, objects []meta.MeshObject, timeout time.Duration) ([]string, error) {
+	applied := map[string]bool{}
+	for _, mo := range objects {
+		applied[mo.Kind()+"/"+mo.Name()] = true
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	existing := map[string]map[string]bool{}
+	var missing []string
+	for _, mo := range objects {
+		for _, ref := range resource.References(mo) {
+			if applied[ref.String()] {
+				continue
+			}
+
+			names, ok := existing[ref.Kind]
+			if !ok {
+				var err error
+				names, err = listNames(ctx, client, ref.Kind)
+				if err != nil {
+					return nil, errors.Wrapf(err, "list %s", ref.Kind)
+				}
+				existing[ref.Kind] = names
+			}
+			if !names[ref.Name] {
+				missing = append(missing, fmt.Sprintf("%s/%s references the missing %s %s",
+					mo.Kind(), mo.Name(), ref.Kind, ref.Name))
+			}
+		}
+	}
+	return missing, nil
+}
+
+// listNames lists names of resources of the kind in the control plane.
+func listNames(ctx context.Context, client meshclient.MeshClient, kind string) (map[string]bool, error) {
+	var objects []meta.MeshObject
+	var err error
+	switch kind {
+	case resource.KindService:
+		var services []*resource.Service
+		services, err = client.V1Alpha1().Service().List(ctx)
+		for _, s := range services {
+			objects = append(objects, s)
+		}
+	case resource.KindTenant:
+		var tenants []*resource.Tenant
+		tenants, err = client.V1Alpha1().Tenant().List(ctx)
+		for _, t := range tenants {
+			objects = append(objects, t)
+		}
+	case resource.KindIngress:
+		var ingresses []*resource.Ingress
+		ingresses, err = client.V1Alpha1().Ingress().List(ctx)
+		for _, i := range ingresses {
+			objects = append(objects, i)
+		}
+	default:
+		return nil, errors.Errorf("references to %s are unsupported", kind)
+	}
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, mo := range objects {
+		names[mo.Name()] = true
+	}
+	return names, nil
+}
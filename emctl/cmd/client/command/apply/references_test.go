@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+)
+
+func TestMissingReferences(t *testing.T) {
+	reactorType := "__test_references_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindTenant, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{
+				&resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "shop")},
+			}, nil
+		}).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	objects := []meta.MeshObject{
+		&resource.Service{
+			MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "order"),
+			Spec:         &resource.ServiceSpec{RegisterTenant: "shop"},
+		},
+		&resource.Service{
+			MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "pay"),
+			Spec:         &resource.ServiceSpec{RegisterTenant: "bank"},
+		},
+		&resource.Canary{MeshResource: resource.NewCanaryResource(resource.DefaultAPIVersion, "cart")},
+		&resource.Ingress{
+			MeshResource: resource.NewIngressResource(resource.DefaultAPIVersion, "gateway"),
+			Spec: &resource.IngressSpec{Rules: []*v1alpha1.IngressRule{{
+				Paths: []*v1alpha1.IngressPath{{Path: "/order", Backend: "order"}},
+			}}},
+		},
+	}
+
+	missing, err := MissingReferences(meshclient.NewFakeClient(reactorType), objects, time.Second)
+	if err != nil {
+		t.Fatalf("check references failed: %v", err)
+	}
+	expected := []string{
+		"Service/pay references the missing Tenant bank",
+		"Canary/cart references the missing Service cart",
+	}
+	if len(missing) != len(expected) || missing[0] != expected[0] || missing[1] != expected[1] {
+		t.Fatalf("expect missing references %v, but got %v", expected, missing)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package apply
+
+import (
+	"context"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+// resourceMetaApplier keeps labels and annotations of the object
+// after the object itself is applied.
+type resourceMetaApplier struct {
+	baseApplier
+	applier Applier
+	object  meta.MeshObject
+}
+
+func (r *resourceMetaApplier) Apply() error {
+	err := r.applier.Apply()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), r.timeout)
+	defer cancelFunc()
+	resourceMeta := resource.NewResourceMeta(r.object)
+	err = r.client.V1Alpha1().ResourceMeta().Create(ctx, resourceMeta)
+	for {
+		switch {
+		case err == nil:
+			return nil
+		case meshclient.IsConflictError(err):
+			err = r.client.V1Alpha1().ResourceMeta().Patch(ctx, resourceMeta)
+			if err != nil && meshclient.IsConflictError(err) {
+				return errors.Wrapf(err, "update resource meta %s", resourceMeta.Name())
+			}
+		case meshclient.IsNotFoundError(err):
+			err = r.client.V1Alpha1().ResourceMeta().Create(ctx, resourceMeta)
+			if err != nil && meshclient.IsNotFoundError(err) {
+				return errors.Wrapf(err, "create resource meta %s", resourceMeta.Name())
+			}
+		case meshclient.IsUnsupportedError(err):
+			// NOTE: Control planes without resource metas can't keep labels
+			// and annotations, objects without them are fine.
+			if len(r.object.Labels()) == 0 && len(r.object.Annotations()) == 0 {
+				return nil
+			}
+			return errors.Wrapf(err, "keep labels and annotations of %s/%s", r.object.Kind(), r.object.Name())
+		default:
+			return errors.Wrapf(err, "apply resource meta %s", resourceMeta.Name())
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+// stagedPollInterval is the interval of checking error rates during baking.
+const stagedPollInterval = 30 * time.Second
+
+// StagedApplier applies policies of services to a percentage of their
+// sidecars stage by stage, it waits for the bake time after every stage
+// and aborts the rollout if the error rate of the service exceeds the limit.
+type StagedApplier struct {
+	client       meshclient.MeshClient
+	telemetry    telemetry.Client
+	timeout      time.Duration
+	stages       []int
+	bakeTime     time.Duration
+	maxErrorRate float64
+
+	sleep func(time.Duration)
+}
+
+// ParseStages parses the percentages of stages like 10%,50%,100%.
+func ParseStages(s string) ([]int, error) {
+	stages := []int{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSuffix(strings.TrimSpace(item), "%")
+		stage, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, errors.Errorf("invalid stage %q", item)
+		}
+		stages = append(stages, stage)
+	}
+
+	err := resource.ValidateRolloutStages(stages)
+	if err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// NewStagedApplier creates a StagedApplier, the max error rate is in percent.
+func NewStagedApplier(client meshclient.MeshClient, tc telemetry.Client, stages []int,
+	bakeTime time.Duration, maxErrorRate float64, timeout time.Duration) *StagedApplier {
+	return &StagedApplier{
+		client:       client,
+		telemetry:    tc,
+		timeout:      timeout,
+		stages:       stages,
+		bakeTime:     bakeTime,
+		maxErrorRate: maxErrorRate,
+		sleep:        time.Sleep,
+	}
+}
+
+// Apply rolls out the policy stage by stage, the policy is applied as usual
+// at the stage of 100%, and the rollout is removed after it's done or aborted.
+func (s *StagedApplier) Apply(object meta.MeshObject) error {
+	service, policy, err := stagedPolicyOf(object)
+	if err != nil {
+		return err
+	}
+
+	if v, ok := object.(interface{ Validate() error }); ok {
+		err = v.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "validate %s %s", object.Kind(), object.Name())
+		}
+	}
+
+	rollout := &resource.PolicyRollout{
+		MeshResource: resource.NewPolicyRolloutResource(resource.DefaultAPIVersion,
+			resource.PolicyRolloutName(object.Kind(), object.Name())),
+		Spec: &resource.PolicyRolloutSpec{
+			Service:    service,
+			TargetKind: object.Kind(),
+			TargetName: object.Name(),
+			Policy:     policy,
+			Stages:     s.stages,
+		},
+	}
+
+	for _, stage := range s.stages {
+		if stage == 100 {
+			break
+		}
+
+		rollout.Spec.Percentage = stage
+		err = WrapApplierByMeshObject(rollout, s.client, s.timeout).Apply()
+		if err != nil {
+			return s.abort(rollout, errors.Wrapf(err, "roll out to %d%% of sidecars", stage))
+		}
+		common.Logger().With("kind", object.Kind(), "name", object.Name()).
+			Infof("%s/%s rolled out to %d%% of sidecars, baking for %s", object.Kind(), object.Name(), stage, s.bakeTime)
+
+		err = s.bake(service)
+		if err != nil {
+			return s.abort(rollout, errors.Wrapf(err, "bake at %d%% of sidecars", stage))
+		}
+	}
+
+	err = WrapApplierByMeshObject(object, s.client, s.timeout).Apply()
+	if err != nil {
+		return s.abort(rollout, err)
+	}
+	return s.remove(rollout)
+}
+
+// bake waits for the bake time, it fails as soon as the error rate of
+// requests to the service since the stage began exceeds the limit.
+func (s *StagedApplier) bake(service string) error {
+	base, err := s.ingressStat(service)
+	if err != nil {
+		return err
+	}
+
+	for elapsed := time.Duration(0); elapsed < s.bakeTime; {
+		wait := stagedPollInterval
+		if remaining := s.bakeTime - elapsed; remaining < wait {
+			wait = remaining
+		}
+		s.sleep(wait)
+		elapsed += wait
+
+		current, err := s.ingressStat(service)
+		if err != nil {
+			return err
+		}
+		// NOTE: Counters start over if sidecars restarted.
+		if current.Count < base.Count || current.ErrCount < base.ErrCount {
+			base = &telemetry.Stat{}
+		}
+
+		count := current.Count - base.Count
+		if count == 0 {
+			continue
+		}
+		rate := (current.ErrCount - base.ErrCount) / count * 100
+		if rate > s.maxErrorRate {
+			return errors.Errorf("error rate %.2f%% of %s exceeds %.2f%%", rate, service, s.maxErrorRate)
+		}
+	}
+
+	return nil
+}
+
+func (s *StagedApplier) ingressStat(service string) (*telemetry.Stat, error) {
+	objects, err := s.telemetry.Objects()
+	if err != nil {
+		return nil, errors.Wrap(err, "get telemetry of sidecars")
+	}
+
+	total := &telemetry.Stat{}
+	for _, stat := range telemetry.IngressStats(objects, service, "") {
+		total.Add(stat)
+	}
+	return total, nil
+}
+
+// abort removes the rollout, so that all sidecars go back to the current policy.
+func (s *StagedApplier) abort(rollout *resource.PolicyRollout, cause error) error {
+	err := s.remove(rollout)
+	if err != nil {
+		return errors.Wrapf(cause, "rollout aborted but removing it failed: %v", err)
+	}
+	return errors.Wrap(cause, "rollout aborted")
+}
+
+func (s *StagedApplier) remove(rollout *resource.PolicyRollout) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFunc()
+
+	err := s.client.V1Alpha1().PolicyRollout().Delete(ctx, rollout.Name())
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete policy rollout %s", rollout.Name())
+	}
+	return nil
+}
+
+// stagedPolicyOf returns the service and the policy in the form stored in
+// the control plane, only policies of a single service could be staged,
+// since sidecars of the service are the ones to select.
+func stagedPolicyOf(object meta.MeshObject) (string, map[string]interface{}, error) {
+	var policy interface{}
+	switch o := object.(type) {
+	case *resource.Service:
+		service, err := o.Translate()
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "service %s", o.Name())
+		}
+		policy = service
+	case *resource.Resilience:
+		policy = o.Spec
+	case *resource.LoadBalance:
+		policy = o.ToV1Alpha1()
+	case *resource.Canary:
+		policy = o.ToV1Alpha1()
+	case *resource.Mock:
+		policy = o.ToV1Alpha1()
+	case *resource.ObservabilityMetrics:
+		policy = o.ToV1Alpha1()
+	case *resource.ObservabilityTracings:
+		policy = o.ToV1Alpha1()
+	case *resource.ObservabilityOutputServer:
+		policy = o.ToV1Alpha1()
+	default:
+		return "", nil, errors.Errorf("%s/%s could not be staged, only policies of a service are supported",
+			object.Kind(), object.Name())
+	}
+
+	buff, err := json.Marshal(policy)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "marshal %s/%s", object.Kind(), object.Name())
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal(buff, &result)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "unmarshal %s/%s", object.Kind(), object.Name())
+	}
+	return object.Name(), result, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// fakeIngressClient reports requests to vets-service growing by 100 with
+// the errors per 100 requests at every call.
+type fakeIngressClient struct {
+	calls  int
+	errors int
+}
+
+func (c *fakeIngressClient) Objects() (map[string]map[string]interface{}, error) {
+	c.calls++
+	return map[string]map[string]interface{}{
+		"easemesh-controller": {
+			"vets-service-6b7d9": map[string]interface{}{
+				"sidecar-ingress-vets-service": map[string]interface{}{
+					"count":    float64(c.calls * 100),
+					"errCount": float64(c.calls * c.errors),
+				},
+			},
+		},
+	}, nil
+}
+
+func TestParseStages(t *testing.T) {
+	stages, err := ParseStages("10%,50%, 100%")
+	if err != nil || !reflect.DeepEqual(stages, []int{10, 50, 100}) {
+		t.Fatalf("parse stages failed: %v %v", stages, err)
+	}
+	stages, err = ParseStages("100")
+	if err != nil || !reflect.DeepEqual(stages, []int{100}) {
+		t.Fatalf("parse stages failed: %v %v", stages, err)
+	}
+
+	for _, s := range []string{"", "ten%", "10%,50%", "50%,10%,100%", "0%,100%", "10%,10%,100%", "10%,150%"} {
+		if _, err := ParseStages(s); err == nil {
+			t.Fatalf("stages %q should be invalid", s)
+		}
+	}
+}
+
+func TestStagedApplier(t *testing.T) {
+	reactorType := "__staged_reactor"
+	events := []string{}
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("*", resource.KindPolicyRollout, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			events = append(events, "rollout")
+			return true, nil, nil
+		}).
+		AddReactor("*", resource.KindResilience, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			events = append(events, "resilience")
+			return true, nil, nil
+		}).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	newResilience := func() meta.MeshObject {
+		return &resource.Resilience{
+			MeshResource: resource.NewResilienceResource(resource.DefaultAPIVersion, "vets-service"),
+			Spec:         &resource.ResilienceSpec{},
+		}
+	}
+
+	applier := NewStagedApplier(meshclient.NewFakeClient(reactorType), &fakeIngressClient{errors: 1},
+		[]int{10, 50, 100}, time.Minute, 5, time.Second)
+	applier.sleep = func(d time.Duration) {
+		if d != stagedPollInterval {
+			t.Fatalf("bake should poll every %s, but slept %s", stagedPollInterval, d)
+		}
+		events = append(events, "sleep")
+	}
+
+	err := applier.Apply(newResilience())
+	if err != nil {
+		t.Fatalf("staged apply failed: %v", err)
+	}
+	expected := []string{"rollout", "sleep", "sleep", "rollout", "sleep", "sleep", "resilience", "rollout"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected %v, but got %v", expected, events)
+	}
+
+	events = []string{}
+	applier.telemetry = &fakeIngressClient{errors: 10}
+	err = applier.Apply(newResilience())
+	if err == nil {
+		t.Fatalf("rollout exceeding the max error rate should be aborted")
+	}
+	expected = []string{"rollout", "sleep", "rollout"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("aborted rollout should be removed without applying, expected %v, but got %v", expected, events)
+	}
+
+	tenant := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "pet")}
+	if err := applier.Apply(tenant); err == nil {
+		t.Fatalf("tenant should not be staged")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/valid"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+// Validate validates the object as it's validated before applied, by its own
+// rules, and by the JSON schema of its kind for a custom resource.
+func Validate(client meshclient.MeshClient, object meta.MeshObject, timeout time.Duration) error {
+	if v, ok := object.(interface{ Validate() error }); ok {
+		err := v.Validate()
+		if err != nil {
+			return common.WithCode(errors.Wrapf(err, "validate %s %s", object.Kind(), object.Name()),
+				common.ExitCodeValidation)
+		}
+	}
+
+	cr, ok := object.(*resource.CustomResource)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+	err := validateCustomResource(ctx, client, cr)
+	if err != nil {
+		return errors.Wrapf(err, "validate custom resource %s", cr.Name())
+	}
+	return nil
+}
+
+// validateCustomResource validates the custom resource by the JSON schema of its kind.
+// The check is skipped if the kind can't be got, e.g. it's applied later.
+func validateCustomResource(ctx context.Context, client meshclient.MeshClient, cr *resource.CustomResource) error {
+	kind, err := client.V1Alpha1().CustomResourceKind().Get(ctx, cr.Kind())
+	if err != nil || kind.Spec == nil || len(kind.Spec.JSONSchema) == 0 {
+		return nil
+	}
+
+	vr := valid.ValidateBySchema(kind.Spec.JSONSchema, cr.ToV1Alpha1())
+	if !vr.Valid() {
+		return vr
+	}
+	return nil
+}
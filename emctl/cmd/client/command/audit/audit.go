@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+// RunList is the entrypoint of the emctl audit list sub command
+func RunList(cmd *cobra.Command, flag *flags.AuditList) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	options := listOptions(flag, time.Now())
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	records, err := meshclient.New(flag.Server).V1Alpha1().Audit().List(ctx, options)
+	if err != nil {
+		common.ExitWithErrorf("list audit records failed: %w", err)
+	}
+
+	objects := make([]meta.MeshObject, len(records))
+	for i := range records {
+		objects[i] = records[i]
+	}
+
+	printer.New(flag.OutputFormat).PrintObjects(objects)
+}
+
+func listOptions(flag *flags.AuditList, now time.Time) *meshclient.AuditListOptions {
+	options := &meshclient.AuditListOptions{
+		Name: flag.Name,
+		User: flag.User,
+	}
+
+	if flag.Kind != "" {
+		options.Kind = util.AdaptCommandKind(flag.Kind)
+	}
+
+	if flag.Since > 0 {
+		options.Since = now.Add(-flag.Since)
+	}
+
+	return options
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestListOptions(t *testing.T) {
+	now := time.Now()
+	options := listOptions(&flags.AuditList{
+		Since: 24 * time.Hour,
+		Kind:  "loadbalance",
+		User:  "alice",
+	}, now)
+
+	if !options.Since.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("since should be 24 hours ago, but got %s", options.Since)
+	}
+	if options.Kind != resource.KindLoadBalance {
+		t.Fatalf("kind should be %s, but got %s", resource.KindLoadBalance, options.Kind)
+	}
+	if options.User != "alice" {
+		t.Fatalf("user should be alice, but got %s", options.User)
+	}
+
+	options = listOptions(&flags.AuditList{}, now)
+	if !options.Since.IsZero() {
+		t.Fatalf("zero since should list all records, but got %s", options.Since)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// RunCreateToken is the entrypoint of the emctl auth create-token sub command
+func RunCreateToken(cmd *cobra.Command, flag *flags.AuthCreateToken) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	request := tokenRequest(flag)
+	err := request.Validate()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+	switch flag.OutputFormat {
+	case "token", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support token, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	token, err := meshclient.New(flag.Server).V1Alpha1().AccessToken().Create(ctx, request)
+	if err != nil {
+		common.ExitWithErrorf("create access token failed: %w", err)
+	}
+
+	output, err := formatToken(token, flag.OutputFormat)
+	if err != nil {
+		common.ExitWithErrorf("format access token failed: %w", err)
+	}
+	fmt.Print(output)
+	common.Infof("access token %s created, it can't be shown again", token.ID)
+}
+
+// RunRevokeToken is the entrypoint of the emctl auth revoke-token sub command
+func RunRevokeToken(cmd *cobra.Command, flag *flags.AuthRevokeToken) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	id := tokenID(cmd.Flags().Arg(0))
+	err := meshclient.New(flag.Server).V1Alpha1().AccessToken().Delete(ctx, id)
+	if err != nil {
+		common.ExitWithErrorf("revoke access token %s failed: %w", id, err)
+	}
+	common.Infof("access token %s revoked", id)
+}
+
+func tokenRequest(flag *flags.AuthCreateToken) *resource.AccessTokenRequest {
+	request := &resource.AccessTokenRequest{
+		Tenant:      flag.Tenant,
+		Role:        flag.Role,
+		Description: flag.Description,
+	}
+	if flag.TTL != 0 {
+		request.TTL = flag.TTL.String()
+	}
+	return request
+}
+
+func formatToken(token *resource.AccessToken, format string) (string, error) {
+	switch format {
+	case "yaml":
+		buff, err := yaml.Marshal(token)
+		return string(buff), err
+	case "json":
+		buff, err := json.MarshalIndent(token, "", "  ")
+		return string(buff) + "\n", err
+	default:
+		return token.Token + "\n", nil
+	}
+}
+
+// tokenID returns the id of the token in the form of id.secret,
+// so both the token and its id could be revoked.
+func tokenID(token string) string {
+	for i, c := range token {
+		if c == '.' {
+			return token[:i]
+		}
+	}
+	return token
+}
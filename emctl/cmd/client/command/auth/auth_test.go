@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/testing/meshserver"
+	"github.com/megaease/easemeshctl/cmd/common"
+	commonclient "github.com/megaease/easemeshctl/cmd/common/client"
+)
+
+func TestCreateAndRevokeToken(t *testing.T) {
+	server := meshserver.New()
+	defer server.Close()
+
+	flag := &flags.AuthCreateToken{Role: resource.TokenRoleEditor, Tenant: "shop", TTL: 24 * time.Hour}
+	request := tokenRequest(flag)
+	if err := request.Validate(); err != nil || request.TTL != "24h0m0s" {
+		t.Fatalf("unexpected request %+v: %v", request, err)
+	}
+
+	admin, err := server.Client().V1Alpha1().AccessToken().Create(context.Background(),
+		&resource.AccessTokenRequest{Role: resource.TokenRoleAdmin})
+	if err != nil {
+		t.Fatalf("create admin token failed: %v", err)
+	}
+	ctx := commonclient.WithHeader(context.Background(), meshclient.AuthorizationHeader, "Bearer "+admin.Token)
+
+	token, err := server.Client().V1Alpha1().AccessToken().Create(ctx, request)
+	if err != nil {
+		t.Fatalf("create access token failed: %v", err)
+	}
+	if token.Tenant != "shop" || token.Role != resource.TokenRoleEditor || token.ExpiresAt == "" {
+		t.Fatalf("unexpected access token %+v", token)
+	}
+	if tokenID(token.Token) != token.ID || tokenID(token.ID) != token.ID {
+		t.Fatalf("unexpected id of token %s", token.Token)
+	}
+
+	output, err := formatToken(token, "yaml")
+	if err != nil || !strings.Contains(output, "tenant: shop") {
+		t.Fatalf("unexpected yaml output %s: %v", output, err)
+	}
+
+	err = server.Client().V1Alpha1().AccessToken().Delete(ctx, token.ID)
+	if err != nil {
+		t.Fatalf("revoke access token failed: %v", err)
+	}
+}
+
+func TestCreateTokenRedacted(t *testing.T) {
+	server := meshserver.New()
+	defer server.Close()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	common.SetLogOutput(stdout, stderr)
+	common.SetVerbosity(2)
+	defer func() {
+		common.SetLogOutput(os.Stdout, os.Stderr)
+		common.SetVerbosity(0)
+		common.SetLogLevel("info")
+	}()
+
+	token, err := server.Client().V1Alpha1().AccessToken().Create(context.Background(),
+		&resource.AccessTokenRequest{Role: resource.TokenRoleAdmin})
+	if err != nil {
+		t.Fatalf("create access token failed: %v", err)
+	}
+
+	logs := stderr.String()
+	if !strings.Contains(logs, "REDACTED") || strings.Contains(logs, token.Token) {
+		t.Fatalf("expected the secret of token %s redacted in the response body, got %q", token.ID, logs)
+	}
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+type (
+	// MatchResult is the result of evaluating a sample request.
+	MatchResult struct {
+		ServiceCanary string `json:"serviceCanary,omitempty"`
+		Matched       bool   `json:"matched"`
+
+		// TrafficRules are results of headers of the traffic rules,
+		// all of them must be matched.
+		TrafficRules []*resource.CanaryPredicateResult `json:"trafficRules,omitempty"`
+		// Match are results of predicates of the match expression.
+		Match []*resource.CanaryPredicateResult `json:"match,omitempty"`
+		// Sticky are results of the sticky of the service canary.
+		Sticky []*resource.CanaryPredicateResult `json:"sticky,omitempty"`
+	}
+
+	trafficRules struct {
+		Headers map[string]*stringMatch `json:"headers"`
+	}
+
+	stringMatch struct {
+		Exact  string `json:"exact"`
+		Prefix string `json:"prefix"`
+		Regex  string `json:"regex"`
+	}
+)
+
+// RunTestMatch is the entrypoint of the emctl canary test-match sub command
+func RunTestMatch(cmd *cobra.Command, flag *flags.CanaryTestMatch) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	args := cmd.Flags().Args()
+	if len(args) > 1 {
+		common.ExitWithCodef(common.ExitCodeValidation, "only one service canary name is allowed")
+	}
+	if len(args) == 0 && flag.Expression == "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "a service canary name or --expression is required")
+	}
+
+	r, err := newSampleRequest(flag)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "%v", err)
+	}
+
+	var serviceCanary *resource.ServiceCanary
+	if len(args) == 1 {
+		ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+		defer cancelFunc()
+		serviceCanary, err = meshclient.New(flag.Server).V1Alpha1().ServiceCanary().Get(ctx, args[0])
+		if err != nil {
+			if meshclient.IsNotFoundError(err) {
+				common.ExitWithError(common.WithCode(err, common.ExitCodeNotFound))
+			}
+			common.ExitWithErrorf("get service canary %s failed: %w", args[0], err)
+		}
+	}
+
+	result, err := testMatch(serviceCanary, flag.Expression, r)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "%v", err)
+	}
+
+	switch flag.OutputFormat {
+	case "table":
+		printResult(os.Stdout, result)
+	case "yaml":
+		buff, err := yaml.Marshal(result)
+		if err != nil {
+			common.ExitWithErrorf("marshal result failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal result failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
+
+// newSampleRequest builds the sample request from the flags.
+func newSampleRequest(flag *flags.CanaryTestMatch) (*http.Request, error) {
+	query := url.Values{}
+	for _, kv := range flag.Query {
+		k, v, err := splitKeyValue("query", kv)
+		if err != nil {
+			return nil, err
+		}
+		query.Add(k, v)
+	}
+
+	r, err := http.NewRequest(http.MethodGet, "http://sample"+flag.Path, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid path %s", flag.Path)
+	}
+	if len(query) != 0 {
+		r.URL.RawQuery = query.Encode()
+	}
+
+	for _, kv := range flag.Headers {
+		k, v, err := splitKeyValue("header", kv)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Add(k, v)
+	}
+	for _, kv := range flag.Cookies {
+		k, v, err := splitKeyValue("cookie", kv)
+		if err != nil {
+			return nil, err
+		}
+		r.AddCookie(&http.Cookie{Name: k, Value: v})
+	}
+
+	return r, nil
+}
+
+func splitKeyValue(kind, kv string) (string, string, error) {
+	i := strings.Index(kv, "=")
+	if i <= 0 {
+		return "", "", errors.Errorf("invalid %s %q, want name=value", kind, kv)
+	}
+	return kv[:i], kv[i+1:], nil
+}
+
+// testMatch evaluates the request against the service canary and the expression,
+// the expression overrides the match expression of the service canary. A sticky
+// cookie routes the request to the canary regardless of the others, while a
+// sticky hash must hold besides them.
+func testMatch(serviceCanary *resource.ServiceCanary, expression string, r *http.Request) (*MatchResult, error) {
+	result := &MatchResult{Matched: true}
+
+	if serviceCanary != nil {
+		result.ServiceCanary = serviceCanary.Name()
+		if serviceCanary.Spec != nil {
+			if expression == "" {
+				expression = serviceCanary.Spec.Match
+			}
+
+			results, err := matchTrafficRules(serviceCanary.Spec, r)
+			if err != nil {
+				return nil, err
+			}
+			result.TrafficRules = results
+			for _, rr := range results {
+				result.Matched = result.Matched && rr.Matched
+			}
+		}
+	}
+
+	if expression != "" {
+		m, err := resource.ParseCanaryMatch(expression)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid match %q", expression)
+		}
+		result.Match = m.Explain(r)
+		result.Matched = result.Matched && m.Match(r)
+	}
+
+	if serviceCanary != nil && serviceCanary.Spec != nil && serviceCanary.Spec.Sticky != nil {
+		sticky := serviceCanary.Spec.Sticky
+		m, err := resource.ParseCanaryMatch(sticky.Expression(serviceCanary.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid sticky")
+		}
+		result.Sticky = m.Explain(r)
+		if sticky.Mode == resource.ServiceCanaryStickyHash {
+			result.Matched = result.Matched && m.Match(r)
+		} else {
+			result.Matched = result.Matched || m.Match(r)
+		}
+	}
+
+	return result, nil
+}
+
+// matchTrafficRules evaluates headers of the traffic rules, which are
+// decoded at the JSON level to keep in line with the control plane.
+func matchTrafficRules(spec *resource.ServiceCanarySpec, r *http.Request) ([]*resource.CanaryPredicateResult, error) {
+	if spec.TrafficRules == nil {
+		return nil, nil
+	}
+
+	buff, err := json.Marshal(spec.TrafficRules)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal traffic rules")
+	}
+	rules := &trafficRules{}
+	err = json.Unmarshal(buff, rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal traffic rules")
+	}
+
+	names := []string{}
+	for name := range rules.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := []*resource.CanaryPredicateResult{}
+	for _, name := range names {
+		sm := rules.Headers[name]
+		if sm == nil {
+			continue
+		}
+		value := r.Header.Get(name)
+		result := &resource.CanaryPredicateResult{Value: value}
+		switch {
+		case sm.Exact != "":
+			result.Predicate = fmt.Sprintf("header(%q) exact %q", name, sm.Exact)
+			result.Matched = value == sm.Exact
+		case sm.Prefix != "":
+			result.Predicate = fmt.Sprintf("header(%q) prefix %q", name, sm.Prefix)
+			result.Matched = strings.HasPrefix(value, sm.Prefix)
+		case sm.Regex != "":
+			re, err := regexp.Compile(sm.Regex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regex of header %s", name)
+			}
+			result.Predicate = fmt.Sprintf("header(%q) regex %q", name, sm.Regex)
+			result.Matched = re.MatchString(value)
+		default:
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func printResult(w io.Writer, result *MatchResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Source", "Predicate", "Value", "Matched"})
+	table.SetBorder(false)
+	for _, r := range result.TrafficRules {
+		table.Append([]string{"trafficRules", r.Predicate, r.Value, fmt.Sprint(r.Matched)})
+	}
+	for _, r := range result.Match {
+		table.Append([]string{"match", r.Predicate, r.Value, fmt.Sprint(r.Matched)})
+	}
+	for _, r := range result.Sticky {
+		table.Append([]string{"sticky", r.Predicate, r.Value, fmt.Sprint(r.Matched)})
+	}
+	table.Render()
+
+	target := "the expression"
+	if result.ServiceCanary != "" {
+		target = "service canary " + result.ServiceCanary
+	}
+	if result.Matched {
+		fmt.Fprintf(w, "\nrequest matches %s\n", target)
+	} else {
+		fmt.Fprintf(w, "\nrequest does not match %s\n", target)
+	}
+}
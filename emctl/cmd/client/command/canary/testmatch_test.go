@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package canary
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestTestMatch(t *testing.T) {
+	r, err := newSampleRequest(&flags.CanaryTestMatch{
+		Path:    "/pets",
+		Headers: []string{"X-Location=Beijing"},
+		Cookies: []string{"uid=1024"},
+		Query:   []string{"debug=a=b"},
+	})
+	if err != nil {
+		t.Fatalf("new sample request failed: %v", err)
+	}
+	if r.URL.Query().Get("debug") != "a=b" {
+		t.Fatalf("query debug should be a=b, got %s", r.URL.RawQuery)
+	}
+	if _, err := newSampleRequest(&flags.CanaryTestMatch{Path: "/", Headers: []string{"=Beijing"}}); err == nil {
+		t.Fatalf("header without name should fail")
+	}
+
+	trafficRules := &v1alpha1.TrafficRules{}
+	err = json.Unmarshal([]byte(`{"headers":{"X-Location":{"exact":"Beijing"},"X-Os":{"prefix":"And"}}}`), trafficRules)
+	if err != nil {
+		t.Fatalf("unmarshal traffic rules failed: %v", err)
+	}
+	sc := &resource.ServiceCanary{
+		MeshResource: resource.NewServiceCanaryResource(resource.DefaultAPIVersion, "pet-beijing"),
+		Spec: &resource.ServiceCanarySpec{
+			TrafficRules: trafficRules,
+			Match:        `cookie("uid") == "1024"`,
+		},
+	}
+
+	result, err := testMatch(sc, "", r)
+	if err != nil {
+		t.Fatalf("test match failed: %v", err)
+	}
+	if result.Matched || len(result.TrafficRules) != 2 || len(result.Match) != 1 {
+		t.Fatalf("request without X-Os should not match: %+v", result)
+	}
+
+	r.Header.Set("X-Os", "Android")
+	result, err = testMatch(sc, "", r)
+	if err != nil {
+		t.Fatalf("test match failed: %v", err)
+	}
+	if !result.Matched {
+		t.Fatalf("request should match: %+v", result)
+	}
+
+	result, err = testMatch(sc, `exists(query("trace"))`, r)
+	if err != nil {
+		t.Fatalf("test match failed: %v", err)
+	}
+	if result.Matched {
+		t.Fatalf("expression should override the match: %+v", result)
+	}
+
+	if _, err := testMatch(nil, `header("X-Os") ==`, r); err == nil {
+		t.Fatalf("invalid expression should fail")
+	}
+
+	sc.Spec.Sticky = &resource.ServiceCanarySticky{Mode: resource.ServiceCanaryStickyCookie}
+	r.Header.Del("X-Os")
+	r.AddCookie(&http.Cookie{Name: resource.DefaultServiceCanaryStickyCookie, Value: "pet-beijing"})
+	result, err = testMatch(sc, "", r)
+	if err != nil {
+		t.Fatalf("test match failed: %v", err)
+	}
+	if !result.Matched || len(result.Sticky) != 1 {
+		t.Fatalf("request with the sticky cookie should match: %+v", result)
+	}
+
+	r.Header.Set("X-Os", "Android")
+	sc.Spec.Sticky = &resource.ServiceCanarySticky{Mode: resource.ServiceCanaryStickyHash, Key: `cookie("uid")`}
+	result, err = testMatch(sc, "", r)
+	if err != nil {
+		t.Fatalf("test match failed: %v", err)
+	}
+	if result.Matched {
+		t.Fatalf("request should not match the sticky hash of 0 percentage: %+v", result)
+	}
+	sc.Spec.Sticky.Percentage = 100
+	result, err = testMatch(sc, "", r)
+	if err != nil {
+		t.Fatalf("test match failed: %v", err)
+	}
+	if !result.Matched {
+		t.Fatalf("request should match the sticky hash of 100 percentage: %+v", result)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/migrate"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const categoryReference = "Reference"
+
+// RunRefs is the entrypoint of the emctl check refs sub command
+func RunRefs(cmd *cobra.Command, flag *flags.CheckRefs) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	var objects []meta.MeshObject
+	for _, kind := range migrate.Kinds() {
+		raws, err := migrate.Fetch(flag.Server, kind, flag.Timeout)
+		if err != nil {
+			common.ExitWithError(common.WithCode(errors.Wrapf(err, "fetch %s", kind), common.ExitCodeUnreachable))
+		}
+
+		for _, raw := range raws {
+			object, _, err := migrate.Decode(kind, raw)
+			if err != nil {
+				name, _ := raw["name"].(string)
+				common.Warnf("%s/%s skipped: %v", kind, name, err)
+				continue
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	findings := checkReferences(objects)
+	printFindings(os.Stdout, findings, "No broken reference is found")
+	if count(findings, severityError) != 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "resources reference missing ones")
+	}
+}
+
+// checkReferences reports references of the objects to missing resources,
+// e.g. a service registered to a deleted tenant, or an ingress routing to
+// a deleted service.
+func checkReferences(objects []meta.MeshObject) []*finding {
+	names := map[string]bool{}
+	for _, object := range objects {
+		names[object.Kind()+"/"+object.Name()] = true
+	}
+
+	var findings []*finding
+	for _, object := range objects {
+		for _, ref := range resource.References(object) {
+			if names[ref.String()] {
+				continue
+			}
+			findings = append(findings, &finding{
+				Category: categoryReference,
+				Object:   object.Kind() + "/" + object.Name(),
+				Severity: severityError,
+				Message:  fmt.Sprintf("references the missing %s %s", ref.Kind, ref.Name),
+			})
+		}
+	}
+	return findings
+}
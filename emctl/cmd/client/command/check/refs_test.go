@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+)
+
+func TestCheckReferences(t *testing.T) {
+	objects := []meta.MeshObject{
+		&resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "shop")},
+		&resource.Service{
+			MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "order"),
+			Spec:         &resource.ServiceSpec{RegisterTenant: "shop"},
+		},
+		&resource.Service{
+			MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "pay"),
+			Spec:         &resource.ServiceSpec{RegisterTenant: "bank"},
+		},
+		&resource.Ingress{
+			MeshResource: resource.NewIngressResource(resource.DefaultAPIVersion, "gateway"),
+			Spec: &resource.IngressSpec{Rules: []*v1alpha1.IngressRule{{
+				Paths: []*v1alpha1.IngressPath{
+					{Path: "/order", Backend: "order"},
+					{Path: "/cart", Backend: "cart"},
+				},
+			}}},
+		},
+	}
+
+	findings := checkReferences(objects)
+	expected := map[string]string{
+		"Service/pay":     "references the missing Tenant bank",
+		"Ingress/gateway": "references the missing Service cart",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for _, f := range findings {
+		if expected[f.Object] != f.Message || f.Severity != severityError {
+			t.Fatalf("unexpected finding %+v", f)
+		}
+	}
+}
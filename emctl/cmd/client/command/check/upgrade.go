@@ -0,0 +1,319 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/command/migrate"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	severityError   = "ERROR"
+	severityWarning = "WARNING"
+
+	categoryKubernetesAPI = "KubernetesAPI"
+	categoryMeshResource  = "MeshResource"
+	categoryWorkload      = "Workload"
+	categorySidecar       = "Sidecar"
+
+	sidecarContainerName = "easemesh-sidecar"
+)
+
+type (
+	// finding is a change required before or after upgrading.
+	finding struct {
+		Category string
+		Object   string
+		Severity string
+		Message  string
+	}
+
+	// requiredAPI is an API of Kubernetes the components of EaseMesh rely on.
+	requiredAPI struct {
+		groupVersion string
+		resource     string
+		usedBy       string
+	}
+
+	upgradeChecker struct {
+		flag          *flags.CheckUpgradeCompat
+		client        kubernetes.Interface
+		dynamicClient dynamic.Interface
+		meshClient    meshclient.MeshClient
+	}
+)
+
+var (
+	requiredAPIs = []requiredAPI{
+		{"apps/v1", "deployments", "the operator and the ingress controller"},
+		{"apps/v1", "statefulsets", "the control plane"},
+		{"admissionregistration.k8s.io/v1", "mutatingwebhookconfigurations", "sidecar injection of the operator"},
+		{"apiextensions.k8s.io/v1", "customresourcedefinitions", "the MeshDeployment CRD"},
+		{"certificates.k8s.io/v1", "certificatesigningrequests", "certificates of the operator webhook"},
+		{"coordination.k8s.io/v1", "leases", "leader election of the operator"},
+		{"rbac.authorization.k8s.io/v1", "clusterroles", "permissions of the components"},
+	}
+
+	meshDeploymentGVR = schema.GroupVersionResource{Group: "mesh.megaease.com", Version: "v1", Resource: "meshdeployments"}
+)
+
+// RunUpgradeCompat is the entrypoint of the emctl check upgrade-compat sub command
+func RunUpgradeCompat(cmd *cobra.Command, flag *flags.CheckUpgradeCompat) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	dynamicClient, err := installbase.NewKubernetesDynamicClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	c := &upgradeChecker{
+		flag:          flag,
+		client:        client,
+		dynamicClient: dynamicClient,
+		meshClient:    meshclient.New(flag.Server),
+	}
+	findings, err := c.check()
+	if err != nil {
+		common.ExitWithError(err)
+	}
+
+	printFindings(os.Stdout, findings, "No change is required before upgrading")
+	if count(findings, severityError) != 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "EaseMesh can't be upgraded until errors are fixed")
+	}
+}
+
+func (c *upgradeChecker) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.flag.Timeout)
+}
+
+// check runs all checks, the error means the inventory can't be fetched.
+func (c *upgradeChecker) check() ([]*finding, error) {
+	var findings []*finding
+	for _, fn := range []func() ([]*finding, error){
+		c.checkKubernetesAPIs,
+		c.checkMeshResources,
+		c.checkMeshDeployments,
+		c.checkSidecars,
+	} {
+		f, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, f...)
+	}
+	return findings, nil
+}
+
+// checkKubernetesAPIs reports APIs removed from the cluster which the components rely on.
+func (c *upgradeChecker) checkKubernetesAPIs() ([]*finding, error) {
+	var findings []*finding
+	served := map[string]map[string]bool{}
+	for _, api := range requiredAPIs {
+		resources, ok := served[api.groupVersion]
+		if !ok {
+			list, err := c.client.Discovery().ServerResourcesForGroupVersion(api.groupVersion)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return nil, common.WithCode(errors.Wrapf(err, "discover %s", api.groupVersion), common.ExitCodeUnreachable)
+			}
+			resources = map[string]bool{}
+			if list != nil {
+				for _, r := range list.APIResources {
+					resources[r.Name] = true
+				}
+			}
+			served[api.groupVersion] = resources
+		}
+
+		if !resources[api.resource] {
+			findings = append(findings, &finding{
+				Category: categoryKubernetesAPI,
+				Object:   api.groupVersion + "/" + api.resource,
+				Severity: severityError,
+				Message:  fmt.Sprintf("not served by the cluster, which is required by %s", api.usedBy),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// checkMeshResources reports resources with fields removed from the schema or invalid in it.
+func (c *upgradeChecker) checkMeshResources() ([]*finding, error) {
+	var findings []*finding
+	for _, kind := range migrate.Kinds() {
+		objects, err := migrate.Fetch(c.flag.Server, kind, c.flag.Timeout)
+		if err != nil {
+			return nil, common.WithCode(errors.Wrapf(err, "fetch %s", kind), common.ExitCodeUnreachable)
+		}
+
+		for _, object := range objects {
+			name, _ := object["name"].(string)
+			f := &finding{
+				Category: categoryMeshResource,
+				Object:   kind + "/" + name,
+				Severity: severityError,
+			}
+
+			obj, unknown, err := migrate.Decode(kind, object)
+			switch {
+			case len(unknown) != 0:
+				f.Message = fmt.Sprintf("fields %s are removed or renamed, convert them by emctl migrate resources",
+					strings.Join(unknown, ","))
+			case err != nil:
+				f.Message = err.Error()
+			default:
+				err = apply.Validate(c.meshClient, obj, c.flag.Timeout)
+				if err == nil {
+					continue
+				}
+				f.Message = err.Error()
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// checkMeshDeployments reports MeshDeployments, which are deprecated in favor of
+// native deployments with annotations.
+func (c *upgradeChecker) checkMeshDeployments() ([]*finding, error) {
+	ctx, cancelFunc := c.requestContext()
+	defer cancelFunc()
+
+	list, err := c.dynamicClient.Resource(meshDeploymentGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, common.WithCode(errors.Wrap(err, "list MeshDeployments"), common.ExitCodeUnreachable)
+	}
+
+	var findings []*finding
+	for _, item := range list.Items {
+		findings = append(findings, &finding{
+			Category: categoryWorkload,
+			Object:   "MeshDeployment/" + item.GetNamespace() + "/" + item.GetName(),
+			Severity: severityWarning,
+			Message:  "MeshDeployment is deprecated, replace it by a Deployment annotated with mesh.megaease.com/service-name",
+		})
+	}
+	return findings, nil
+}
+
+// checkSidecars reports injected pods running sidecar images other than the
+// one injected after upgrading, they keep running until restarted.
+func (c *upgradeChecker) checkSidecars() ([]*finding, error) {
+	ctx, cancelFunc := c.requestContext()
+	defer cancelFunc()
+
+	operatorConfig, err := installbase.GetMeshOperatorConfig(c.client, c.flag.MeshNamespace)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, common.CodeErrorf(common.ExitCodeNotFound, "EaseMesh isn't installed in namespace %s", c.flag.MeshNamespace)
+		}
+		return nil, common.WithCode(errors.Wrap(err, "get config of the operator"), common.ExitCodeUnreachable)
+	}
+	image := c.flag.SidecarImage
+	if operatorConfig.ImageRegistryURL != "" {
+		image = operatorConfig.ImageRegistryURL + "/" + image
+	}
+
+	pods, err := c.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: installbase.SidecarInjectedLabelKey + "=true",
+	})
+	if err != nil {
+		return nil, common.WithCode(errors.Wrap(err, "list injected pods"), common.ExitCodeUnreachable)
+	}
+
+	var findings []*finding
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			if container.Name != sidecarContainerName || container.Image == image {
+				continue
+			}
+			findings = append(findings, &finding{
+				Category: categorySidecar,
+				Object:   "Pod/" + pod.Namespace + "/" + pod.Name,
+				Severity: severityWarning,
+				Message: fmt.Sprintf("sidecar image %s is incompatible with %s injected after upgrading, restart the pod",
+					container.Image, image),
+			})
+		}
+	}
+	return findings, nil
+}
+
+func count(findings []*finding, severity string) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func printFindings(w io.Writer, findings []*finding, none string) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, none)
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Category", "Object", "Severity", "Message"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, f := range findings {
+		table.Append([]string{f.Category, f.Object, f.Severity, f.Message})
+	}
+
+	table.Render()
+
+	fmt.Fprintf(w, "\n%d errors, %d warnings\n", count(findings, severityError), count(findings, severityWarning))
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/testing/meshserver"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func injectedPod(name, image string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "shop",
+			Labels:    map[string]string{installbase.SidecarInjectedLabelKey: "true"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "app", Image: "shop/order:1.0"},
+				{Name: sidecarContainerName, Image: image},
+			},
+		},
+	}
+}
+
+func TestCheckUpgradeCompat(t *testing.T) {
+	server := meshserver.New()
+	defer server.Close()
+
+	// NOTE: The field zone is unknown to the schema of emctl.
+	resp, err := http.Post(server.URL+meshclient.MeshTenantsURL, "application/json",
+		strings.NewReader(`{"name": "pet", "zone": "beijing"}`))
+	if err != nil {
+		t.Fatalf("create tenant failed: %v", err)
+	}
+	resp.Body.Close()
+	resp, err = http.Post(server.URL+meshclient.MeshTenantsURL, "application/json",
+		strings.NewReader(`{"name": "shop", "description": "shop"}`))
+	if err != nil {
+		t.Fatalf("create tenant failed: %v", err)
+	}
+	resp.Body.Close()
+
+	client := fake.NewSimpleClientset(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: installbase.OperatorConfigMapName, Namespace: flags.DefaultMeshNamespace},
+			Data:       map[string]string{installbase.OperatorConfigMapKey: "image-registry-url: docker.io\n"},
+		},
+		injectedPod("order-0", "docker.io/"+flags.DefaultEasegressImage),
+		injectedPod("order-1", "docker.io/megaease/easegress:v1.3.0"),
+	)
+	discovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	lists := map[string]*metav1.APIResourceList{}
+	for _, api := range requiredAPIs {
+		list, ok := lists[api.groupVersion]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: api.groupVersion}
+			lists[api.groupVersion] = list
+			discovery.Resources = append(discovery.Resources, list)
+		}
+		// NOTE: The group version is kept without resources, since the fake discovery
+		// fails unknown group versions without the NotFound errors of API servers.
+		if api.groupVersion != "certificates.k8s.io/v1" {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Name: api.resource})
+		}
+	}
+
+	meshDeployment := &unstructured.Unstructured{}
+	meshDeployment.SetAPIVersion("mesh.megaease.com/v1")
+	meshDeployment.SetKind("MeshDeployment")
+	meshDeployment.SetNamespace("shop")
+	meshDeployment.SetName("delivery")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{meshDeploymentGVR: "MeshDeploymentList"}, meshDeployment)
+
+	c := &upgradeChecker{
+		flag: &flags.CheckUpgradeCompat{
+			AdminGlobal:     &flags.AdminGlobal{Server: server.Address(), Timeout: 5 * time.Second},
+			OperationGlobal: &flags.OperationGlobal{MeshNamespace: flags.DefaultMeshNamespace},
+			SidecarImage:    flags.DefaultEasegressImage,
+		},
+		client:        client,
+		dynamicClient: dynamicClient,
+		meshClient:    server.Client(),
+	}
+	findings, err := c.check()
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"certificates.k8s.io/v1/certificatesigningrequests": severityError,
+		"Tenant/pet":                   severityError,
+		"MeshDeployment/shop/delivery": severityWarning,
+		"Pod/shop/order-1":             severityWarning,
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for _, f := range findings {
+		if expected[f.Object] != f.Severity {
+			t.Fatalf("unexpected finding %+v", f)
+		}
+	}
+
+	buff := &bytes.Buffer{}
+	printFindings(buff, findings, "")
+	if !strings.Contains(buff.String(), "2 errors, 2 warnings") {
+		t.Fatalf("unexpected report:\n%s", buff.String())
+	}
+}
+
+func TestCheckUpgradeCompatNotInstalled(t *testing.T) {
+	c := &upgradeChecker{
+		flag: &flags.CheckUpgradeCompat{
+			AdminGlobal:     &flags.AdminGlobal{Timeout: time.Second},
+			OperationGlobal: &flags.OperationGlobal{MeshNamespace: flags.DefaultMeshNamespace},
+		},
+		client: fake.NewSimpleClientset(),
+	}
+	if _, err := c.checkSidecars(); err == nil {
+		t.Fatalf("expected error without EaseMesh installed")
+	}
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlplane
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var pollInterval = 2 * time.Second
+
+type storageResizer struct {
+	flag     *flags.ResizeStorage
+	client   kubernetes.Interface
+	capacity resource.Quantity
+	out      io.Writer
+}
+
+// RunResizeStorage is the entrypoint of the emctl control-plane resize-storage sub command
+func RunResizeStorage(cmd *cobra.Command, flag *flags.ResizeStorage) {
+	r := &storageResizer{flag: flag, out: os.Stdout}
+
+	if flag.Capacity == "" && !flag.DryRun {
+		common.ExitWithCodef(common.ExitCodeValidation, "--capacity is required")
+	}
+	if flag.Capacity != "" {
+		capacity, err := resource.ParseQuantity(flag.Capacity)
+		if err != nil {
+			common.ExitWithCodef(common.ExitCodeValidation, "invalid --capacity %s: %v", flag.Capacity, err)
+		}
+		r.capacity = capacity
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	r.client = client
+
+	err = r.resize()
+	if err != nil {
+		common.ExitWithErrorf("resize storage of control plane failed: %w", err)
+	}
+}
+
+// resize expands the claims of existing members in place, then updates the
+// VolumeClaimTemplates of the StatefulSet for future members. As the
+// templates are immutable, the StatefulSet is deleted with its pods orphaned
+// and created again, which adopts the running pods without restarting them.
+func (r *storageResizer) resize() error {
+	sts, err := r.getStatefulSet()
+	if err != nil {
+		return err
+	}
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return common.CodeErrorf(common.ExitCodeValidation,
+			"statefulset %s has no volume claim template, the control plane is installed with --ephemeral-storage", sts.Name)
+	}
+	claims, err := r.listClaims(sts)
+	if err != nil {
+		return err
+	}
+	printClaims(r.out, claims)
+
+	if r.flag.Capacity == "" {
+		return nil
+	}
+
+	expanding, err := r.preflight(claims)
+	if err != nil {
+		return err
+	}
+	if r.flag.DryRun {
+		common.Infof("preflight checks passed, %d claims will be expanded to %s", len(expanding), r.flag.Capacity)
+		return nil
+	}
+
+	for _, claim := range expanding {
+		common.Infof("expand persistent volume claim %s to %s", claim.Name, r.flag.Capacity)
+		if claim.Spec.Resources.Requests == nil {
+			claim.Spec.Resources.Requests = v1.ResourceList{}
+		}
+		claim.Spec.Resources.Requests[v1.ResourceStorage] = r.capacity
+		_, err = r.client.CoreV1().PersistentVolumeClaims(r.flag.MeshNamespace).Update(context.TODO(), claim, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "update persistent volume claim %s", claim.Name)
+		}
+	}
+
+	err = r.updateClaimTemplate(sts)
+	if err != nil {
+		return err
+	}
+
+	if r.flag.WaitTimeout > 0 && len(expanding) > 0 {
+		err = r.waitExpanded(expanding)
+		if err != nil {
+			return err
+		}
+		claims, err = r.listClaims(sts)
+		if err != nil {
+			return err
+		}
+		printClaims(r.out, claims)
+	}
+
+	common.Infof("resize storage of control plane to %s successfully", r.flag.Capacity)
+	return nil
+}
+
+// preflight checks every claim can be expanded to the capacity, and returns
+// the claims smaller than it.
+func (r *storageResizer) preflight(claims []*v1.PersistentVolumeClaim) ([]*v1.PersistentVolumeClaim, error) {
+	expanding := []*v1.PersistentVolumeClaim{}
+	for _, claim := range claims {
+		current := claim.Spec.Resources.Requests[v1.ResourceStorage]
+		switch r.capacity.Cmp(current) {
+		case -1:
+			return nil, common.CodeErrorf(common.ExitCodeValidation,
+				"persistent volume claim %s requests %s, shrinking to %s is not supported", claim.Name, current.String(), r.flag.Capacity)
+		case 0:
+			continue
+		}
+
+		if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+			return nil, common.CodeErrorf(common.ExitCodeValidation,
+				"persistent volume claim %s has no storage class, which can't be expanded", claim.Name)
+		}
+		className := *claim.Spec.StorageClassName
+		class, err := r.client.StorageV1().StorageClasses().Get(context.TODO(), className, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, common.CodeErrorf(common.ExitCodeNotFound, "storage class %s of persistent volume claim %s not found",
+					className, claim.Name)
+			}
+			return nil, errors.Wrapf(err, "get storage class %s", className)
+		}
+		if class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion {
+			return nil, common.CodeErrorf(common.ExitCodeValidation,
+				"storage class %s doesn't allow volume expansion, set allowVolumeExpansion if its provisioner supports it", className)
+		}
+
+		expanding = append(expanding, claim)
+	}
+	return expanding, nil
+}
+
+func (r *storageResizer) updateClaimTemplate(sts *appsV1.StatefulSet) error {
+	recreated := sts.DeepCopy()
+	found := false
+	for i := range recreated.Spec.VolumeClaimTemplates {
+		template := &recreated.Spec.VolumeClaimTemplates[i]
+		if template.Name != installbase.ControlPlanePVCName {
+			continue
+		}
+		found = true
+		current := template.Spec.Resources.Requests[v1.ResourceStorage]
+		if current.Cmp(r.capacity) == 0 {
+			return nil
+		}
+		if template.Spec.Resources.Requests == nil {
+			template.Spec.Resources.Requests = v1.ResourceList{}
+		}
+		template.Spec.Resources.Requests[v1.ResourceStorage] = r.capacity
+	}
+	if !found {
+		return errors.Errorf("volume claim template %s not found in statefulset %s", installbase.ControlPlanePVCName, sts.Name)
+	}
+
+	common.Infof("recreate statefulset %s with pods orphaned to update volume claim template", sts.Name)
+
+	orphan := metav1.DeletePropagationOrphan
+	err := r.client.AppsV1().StatefulSets(r.flag.MeshNamespace).Delete(context.TODO(), sts.Name,
+		metav1.DeleteOptions{PropagationPolicy: &orphan})
+	if err != nil {
+		return errors.Wrapf(err, "delete statefulset %s", sts.Name)
+	}
+	err = r.poll(func() error {
+		_, err := r.client.AppsV1().StatefulSets(r.flag.MeshNamespace).Get(context.TODO(), sts.Name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return errors.Errorf("statefulset %s is being deleted", sts.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	recreated.ObjectMeta = metav1.ObjectMeta{
+		Name:        sts.Name,
+		Namespace:   sts.Namespace,
+		Labels:      sts.Labels,
+		Annotations: sts.Annotations,
+	}
+	recreated.Status = appsV1.StatefulSetStatus{}
+	_, err = r.client.AppsV1().StatefulSets(r.flag.MeshNamespace).Create(context.TODO(), recreated, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "create statefulset %s, recreate it by emctl install with --mesh-control-plane-pv-capacity %s",
+			sts.Name, r.flag.Capacity)
+	}
+	return nil
+}
+
+// waitExpanded waits for the volumes of claims reaching the capacity. Some
+// volume plugins resize the file system only when the volume is mounted
+// again, those claims are reported to restart their pods.
+func (r *storageResizer) waitExpanded(claims []*v1.PersistentVolumeClaim) error {
+	for _, claim := range claims {
+		name := claim.Name
+		pending := false
+		err := r.poll(func() error {
+			claim, err := r.client.CoreV1().PersistentVolumeClaims(r.flag.MeshNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			for _, c := range claim.Status.Conditions {
+				if c.Type == v1.PersistentVolumeClaimFileSystemResizePending && c.Status == v1.ConditionTrue {
+					pending = true
+					return nil
+				}
+			}
+			actual := claim.Status.Capacity[v1.ResourceStorage]
+			if actual.Cmp(r.capacity) < 0 {
+				return errors.Errorf("persistent volume claim %s has capacity %s, expanding to %s", name, actual.String(), r.flag.Capacity)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if pending {
+			common.Warnf("file system of persistent volume claim %s is resized when its pod restarts", name)
+		}
+	}
+	return nil
+}
+
+func (r *storageResizer) listClaims(sts *appsV1.StatefulSet) ([]*v1.PersistentVolumeClaim, error) {
+	replicas := 0
+	if sts.Spec.Replicas != nil {
+		replicas = int(*sts.Spec.Replicas)
+	}
+
+	claims := []*v1.PersistentVolumeClaim{}
+	for i := 0; i < replicas; i++ {
+		name := installbase.ControlPlanePVCName + "-" + installbase.ControlPlanePodName(i)
+		claim, err := r.client.CoreV1().PersistentVolumeClaims(r.flag.MeshNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, common.CodeErrorf(common.ExitCodeNotFound, "persistent volume claim %s not found in namespace %s",
+					name, r.flag.MeshNamespace)
+			}
+			return nil, errors.Wrapf(err, "get persistent volume claim %s", name)
+		}
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+func (r *storageResizer) getStatefulSet() (*appsV1.StatefulSet, error) {
+	sts, err := r.client.AppsV1().StatefulSets(r.flag.MeshNamespace).
+		Get(context.TODO(), installbase.ControlPlaneStatefulSetName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, common.CodeErrorf(common.ExitCodeNotFound, "statefulset %s not found in namespace %s",
+				installbase.ControlPlaneStatefulSetName, r.flag.MeshNamespace)
+		}
+		return nil, errors.Wrapf(err, "get statefulset %s", installbase.ControlPlaneStatefulSetName)
+	}
+	return sts, nil
+}
+
+func (r *storageResizer) poll(fn func() error) error {
+	deadline := time.Now().Add(r.flag.WaitTimeout)
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "timeout after %s", r.flag.WaitTimeout)
+		}
+		common.Debugf("%v, retry in %s", err, pollInterval)
+		time.Sleep(pollInterval)
+	}
+}
+
+func printClaims(w io.Writer, claims []*v1.PersistentVolumeClaim) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Claim", "Storage Class", "Requested", "Capacity", "Conditions"})
+	table.SetBorder(false)
+	for _, claim := range claims {
+		className := ""
+		if claim.Spec.StorageClassName != nil {
+			className = *claim.Spec.StorageClassName
+		}
+		requested := claim.Spec.Resources.Requests[v1.ResourceStorage]
+		capacity := claim.Status.Capacity[v1.ResourceStorage]
+		conditions := ""
+		for _, c := range claim.Status.Conditions {
+			if c.Status != v1.ConditionTrue {
+				continue
+			}
+			if conditions != "" {
+				conditions += ","
+			}
+			conditions += string(c.Type)
+		}
+		table.Append([]string{
+			claim.Name,
+			className,
+			requested.String(),
+			capacity.String(),
+			conditions,
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlplane
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace    = "easemesh"
+	testStorageClass = "easemesh-storage"
+)
+
+func testStatefulSet(replicas int) *appsV1.StatefulSet {
+	r := int32(replicas)
+	className := testStorageClass
+	return &appsV1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            installbase.ControlPlaneStatefulSetName,
+			Namespace:       testNamespace,
+			ResourceVersion: "7",
+		},
+		Spec: appsV1.StatefulSetSpec{
+			Replicas: &r,
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: installbase.ControlPlanePVCName},
+					Spec: v1.PersistentVolumeClaimSpec{
+						StorageClassName: &className,
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("3Gi")},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testClaim(index int, capacity string) *v1.PersistentVolumeClaim {
+	className := testStorageClass
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.ControlPlanePVCName + "-" + installbase.ControlPlanePodName(index),
+			Namespace: testNamespace,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &className,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)},
+			},
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+}
+
+func testStorageClassObject(allowExpansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: testStorageClass},
+		AllowVolumeExpansion: &allowExpansion,
+	}
+}
+
+func newTestResizer(capacity string, dryRun bool, objects ...runtime.Object) *storageResizer {
+	pollInterval = time.Millisecond
+	return &storageResizer{
+		flag: &flags.ResizeStorage{
+			OperationGlobal: &flags.OperationGlobal{MeshNamespace: testNamespace},
+			Capacity:        capacity,
+			DryRun:          dryRun,
+		},
+		client:   fake.NewSimpleClientset(objects...),
+		capacity: resource.MustParse(capacity),
+		out:      &bytes.Buffer{},
+	}
+}
+
+func claimRequest(t *testing.T, r *storageResizer, index int) string {
+	claim, err := r.client.CoreV1().PersistentVolumeClaims(testNamespace).Get(context.TODO(),
+		testClaim(index, "0").Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get claim failed: %v", err)
+	}
+	q := claim.Spec.Resources.Requests[v1.ResourceStorage]
+	return q.String()
+}
+
+func TestResizeStorage(t *testing.T) {
+	r := newTestResizer("20Gi", false,
+		testStatefulSet(3), testClaim(0, "3Gi"), testClaim(1, "3Gi"), testClaim(2, "20Gi"),
+		testStorageClassObject(true))
+
+	err := r.resize()
+	if err != nil {
+		t.Fatalf("resize failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := claimRequest(t, r, i); got != "20Gi" {
+			t.Errorf("claim %d requests %s, want 20Gi", i, got)
+		}
+	}
+
+	sts, err := r.client.AppsV1().StatefulSets(testNamespace).Get(context.TODO(),
+		installbase.ControlPlaneStatefulSetName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get statefulset failed: %v", err)
+	}
+	q := sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage]
+	if q.String() != "20Gi" {
+		t.Errorf("volume claim template requests %s, want 20Gi", q.String())
+	}
+	if sts.ResourceVersion == "7" {
+		t.Errorf("statefulset is not recreated")
+	}
+
+	if !strings.Contains(r.out.(*bytes.Buffer).String(), testClaim(0, "0").Name) {
+		t.Errorf("claims are not printed: %s", r.out)
+	}
+}
+
+func TestResizeStoragePreflight(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity string
+		dryRun   bool
+		objects  []runtime.Object
+		wantErr  string
+	}{
+		{
+			name:     "expansion not allowed",
+			capacity: "20Gi",
+			objects:  []runtime.Object{testStorageClassObject(false)},
+			wantErr:  "doesn't allow volume expansion",
+		},
+		{
+			name:     "storage class not found",
+			capacity: "20Gi",
+			wantErr:  "not found",
+		},
+		{
+			name:     "shrinking",
+			capacity: "1Gi",
+			objects:  []runtime.Object{testStorageClassObject(true)},
+			wantErr:  "shrinking",
+		},
+		{
+			name:     "dry run",
+			capacity: "20Gi",
+			dryRun:   true,
+			objects:  []runtime.Object{testStorageClassObject(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := append(tt.objects, testStatefulSet(1), testClaim(0, "3Gi"))
+			r := newTestResizer(tt.capacity, tt.dryRun, objects...)
+
+			err := r.resize()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("resize failed: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("want error containing %q, got %v", tt.wantErr, err)
+			}
+
+			if got := claimRequest(t, r, 0); got != "3Gi" {
+				t.Errorf("claim requests %s, want 3Gi untouched", got)
+			}
+		})
+	}
+}
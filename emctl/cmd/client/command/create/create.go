@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package create
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// specFunc generates the spec of the resource with the name.
+type specFunc func(name string) (map[string]interface{}, error)
+
+// RunService is the entrypoint of the emctl create service sub command
+func RunService(cmd *cobra.Command, flag *flags.CreateService) {
+	run(cmd, flag.Create, resource.KindService, func(string) (map[string]interface{}, error) {
+		return serviceSpec(flag)
+	})
+}
+
+// RunTenant is the entrypoint of the emctl create tenant sub command
+func RunTenant(cmd *cobra.Command, flag *flags.CreateTenant) {
+	run(cmd, flag.Create, resource.KindTenant, func(string) (map[string]interface{}, error) {
+		return tenantSpec(flag), nil
+	})
+}
+
+// RunServiceCanary is the entrypoint of the emctl create servicecanary sub command
+func RunServiceCanary(cmd *cobra.Command, flag *flags.CreateServiceCanary) {
+	run(cmd, flag.Create, resource.KindServiceCanary, func(name string) (map[string]interface{}, error) {
+		return serviceCanarySpec(name, flag)
+	})
+}
+
+// RunIngress is the entrypoint of the emctl create ingress sub command
+func RunIngress(cmd *cobra.Command, flag *flags.CreateIngress) {
+	run(cmd, flag.Create, resource.KindIngress, func(string) (map[string]interface{}, error) {
+		return ingressSpec(flag)
+	})
+}
+
+// RunShadowService is the entrypoint of the emctl create shadowservice sub command
+func RunShadowService(cmd *cobra.Command, flag *flags.CreateShadowService) {
+	run(cmd, flag.Create, KindShadowService, func(name string) (map[string]interface{}, error) {
+		return shadowServiceSpec(name, flag)
+	})
+}
+
+func run(cmd *cobra.Command, flag *flags.Create, kind string, spec specFunc) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+	switch flag.OutputFormat {
+	case "", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support yaml, json)", flag.OutputFormat)
+		return
+	}
+
+	args := cmd.Flags().Args()
+	if len(args) != 1 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <resource name>")
+		return
+	}
+	name := args[0]
+
+	client := meshclient.New(flag.Server)
+	object, err := generate(client, kind, name, spec, flag)
+	if err != nil {
+		common.ExitWithErrorf("generate %s/%s failed: %w", kind, name, err)
+		return
+	}
+
+	if flag.DryRun {
+		outputFormat := flag.OutputFormat
+		if outputFormat == "" {
+			outputFormat = "yaml"
+		}
+		printer.New(outputFormat).PrintObjects([]meta.MeshObject{object})
+		return
+	}
+
+	err = create(client, object, flag)
+	if err != nil {
+		common.ExitWithErrorf("create %s/%s failed: %w", kind, name, err)
+		return
+	}
+	common.Logger().With("kind", kind, "name", name).Infof("%s/%s created", kind, name)
+
+	if flag.OutputFormat != "" {
+		printer.New(flag.OutputFormat).PrintObjects([]meta.MeshObject{object})
+	}
+}
+
+// generate generates the object, it's decoded and validated as the one applied from files.
+func generate(client meshclient.MeshClient, kind, name string, spec specFunc, flag *flags.Create) (meta.MeshObject, error) {
+	s, err := spec(name)
+	if err != nil {
+		return nil, common.WithCode(err, common.ExitCodeValidation)
+	}
+
+	buff, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": resource.DefaultAPIVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       s,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal")
+	}
+
+	var objects []meta.MeshObject
+	err = util.NewReaderVisitor(bytes.NewReader(buff), fmt.Sprintf("%s/%s", kind, name)).
+		Visit(func(object meta.MeshObject, err error) error {
+			if err != nil {
+				return err
+			}
+			objects = append(objects, object)
+			return nil
+		})
+	if err != nil {
+		return nil, common.WithCode(err, common.ExitCodeValidation)
+	}
+	if len(objects) != 1 {
+		return nil, errors.Errorf("expected 1 object, got %d objects", len(objects))
+	}
+
+	err = apply.Validate(client, objects[0], flag.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return objects[0], nil
+}
+
+// create creates the object, it fails if the object exists,
+// which should be updated by emctl apply or emctl edit.
+func create(client meshclient.MeshClient, object meta.MeshObject, flag *flags.Create) error {
+	_, err := get.WrapGetterByMeshObject(object, client, flag.Timeout).Get()
+	switch {
+	case err == nil:
+		return common.CodeErrorf(common.ExitCodeConflict, "%s/%s already exists, update it by emctl apply or emctl edit",
+			object.Kind(), object.Name())
+	case !meshclient.IsNotFoundError(err):
+		return err
+	}
+
+	return apply.WrapApplierByMeshObject(object, client, flag.Timeout).Apply()
+}
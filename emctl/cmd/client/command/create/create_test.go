@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package create
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"gopkg.in/yaml.v2"
+)
+
+func assertContains(t *testing.T, object meta.MeshObject, substrs ...string) {
+	t.Helper()
+
+	buff, err := yaml.Marshal(object)
+	if err != nil {
+		t.Fatalf("marshal %s/%s failed: %v", object.Kind(), object.Name(), err)
+	}
+	// NOTE: Fields of the EaseMesh API without YAML tags are in lower case.
+	for _, substr := range substrs {
+		if !strings.Contains(strings.ToLower(string(buff)), strings.ToLower(substr)) {
+			t.Fatalf("expected %q in %s/%s:\n%s", substr, object.Kind(), object.Name(), buff)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	reactorType := "__create_reactor"
+	// NOTE: No custom resource kinds are defined, so generated objects are validated by their types only.
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+	client := meshclient.NewFakeClient(reactorType)
+	flag := &flags.Create{AdminGlobal: &flags.AdminGlobal{Timeout: time.Second}}
+
+	object, err := generate(client, resource.KindService, "orders", func(string) (map[string]interface{}, error) {
+		return serviceSpec(&flags.CreateService{Tenant: "shop", Port: 8080, Protocol: "tcp"})
+	}, flag)
+	if err != nil {
+		t.Fatalf("generate service failed: %v", err)
+	}
+	assertContains(t, object, "registerTenant: shop", "protocol: tcp", "ingressPort: 8080", "ingressProtocol: tcp")
+
+	object, err = generate(client, resource.KindServiceCanary, "orders-canary", func(name string) (map[string]interface{}, error) {
+		return serviceCanarySpec(name, &flags.CreateServiceCanary{Services: []string{"orders"}, Priority: 5})
+	}, flag)
+	if err != nil {
+		t.Fatalf("generate service canary failed: %v", err)
+	}
+	assertContains(t, object, "- orders\n", "release: orders-canary", "exact: orders-canary")
+
+	object, err = generate(client, resource.KindIngress, "shop-ingress", func(string) (map[string]interface{}, error) {
+		return ingressSpec(&flags.CreateIngress{Service: "orders", Host: "shop.example.com", Path: "/orders"})
+	}, flag)
+	if err != nil {
+		t.Fatalf("generate ingress failed: %v", err)
+	}
+	assertContains(t, object, "host: shop.example.com", "path: /orders", "backend: orders")
+
+	object, err = generate(client, KindShadowService, "orders-shadow", func(name string) (map[string]interface{}, error) {
+		return shadowServiceSpec(name, &flags.CreateShadowService{Service: "orders", Namespace: "shop"})
+	}, flag)
+	if err != nil {
+		t.Fatalf("generate shadow service failed: %v", err)
+	}
+	assertContains(t, object, "kind: ShadowService", "serviceName: orders", "namespace: shop")
+
+	_, err = generate(client, resource.KindService, "orders", func(string) (map[string]interface{}, error) {
+		return serviceSpec(&flags.CreateService{Port: 8080, Protocol: "http"})
+	}, flag)
+	if common.ExitCode(err) != common.ExitCodeValidation {
+		t.Fatalf("expected validation error of missing tenant, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package create
+
+import (
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// KindShadowService is the kind of custom resources of shadow services,
+	// registered by the ShadowService add-on.
+	KindShadowService = "ShadowService"
+
+	// defaultCanaryHeader is the header tagging requests routed to the
+	// service canary by default, its value is the name of the canary.
+	defaultCanaryHeader = "X-Canary"
+
+	// defaultCanaryLabel is the label of canary instances by default,
+	// its value is the name of the canary.
+	defaultCanaryLabel = "release"
+
+	defaultDiscoveryType  = "eureka"
+	defaultSidecarAddress = "127.0.0.1"
+)
+
+func serviceSpec(flag *flags.CreateService) (map[string]interface{}, error) {
+	if flag.Tenant == "" {
+		return nil, errors.Errorf("--tenant is required")
+	}
+	if flag.Port <= 0 || flag.Port > 65535 {
+		return nil, errors.Errorf("--port must be a valid port, got %d", flag.Port)
+	}
+
+	sidecarProtocol := resource.DefaultSideIngressProtocol
+	switch flag.Protocol {
+	case resource.ServiceProtocolHTTP:
+	case resource.ServiceProtocolTCP:
+		sidecarProtocol = resource.ServiceProtocolTCP
+	default:
+		return nil, errors.Errorf("unsupported protocol %q (support %s, %s)",
+			flag.Protocol, resource.ServiceProtocolHTTP, resource.ServiceProtocolTCP)
+	}
+
+	return map[string]interface{}{
+		"registerTenant": flag.Tenant,
+		"protocol":       flag.Protocol,
+		"sidecar": map[string]interface{}{
+			"discoveryType":   defaultDiscoveryType,
+			"address":         defaultSidecarAddress,
+			"ingressPort":     flag.Port,
+			"ingressProtocol": sidecarProtocol,
+			"egressPort":      resource.DefaultSideEgressPort,
+			"egressProtocol":  sidecarProtocol,
+		},
+	}, nil
+}
+
+func tenantSpec(flag *flags.CreateTenant) map[string]interface{} {
+	return map[string]interface{}{
+		"services":    []string{},
+		"description": flag.Description,
+	}
+}
+
+func serviceCanarySpec(name string, flag *flags.CreateServiceCanary) (map[string]interface{}, error) {
+	if len(flag.Services) == 0 {
+		return nil, errors.Errorf("--service is required")
+	}
+	if flag.Priority < 1 || flag.Priority > 9 {
+		return nil, errors.Errorf("--priority must be in [1, 9], got %d", flag.Priority)
+	}
+
+	headers := map[string]interface{}{defaultCanaryHeader: map[string]interface{}{"exact": name}}
+	if len(flag.Headers) != 0 {
+		pairs, err := parsePairs("--header", flag.Headers)
+		if err != nil {
+			return nil, err
+		}
+		headers = map[string]interface{}{}
+		for k, v := range pairs {
+			headers[k] = map[string]interface{}{"exact": v}
+		}
+	}
+
+	labels := map[string]string{defaultCanaryLabel: name}
+	if len(flag.Labels) != 0 {
+		var err error
+		labels, err = parsePairs("--label", flag.Labels)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"priority": flag.Priority,
+		"selector": map[string]interface{}{
+			"matchServices":       flag.Services,
+			"matchInstanceLabels": labels,
+		},
+		"trafficRules": map[string]interface{}{"headers": headers},
+	}, nil
+}
+
+func ingressSpec(flag *flags.CreateIngress) (map[string]interface{}, error) {
+	if flag.Service == "" {
+		return nil, errors.Errorf("--service is required")
+	}
+	if !strings.HasPrefix(flag.Path, "/") {
+		return nil, errors.Errorf("--path must start with /, got %q", flag.Path)
+	}
+
+	rule := map[string]interface{}{
+		"paths": []interface{}{map[string]interface{}{"path": flag.Path, "backend": flag.Service}},
+	}
+	if flag.Host != "" {
+		rule["host"] = flag.Host
+	}
+	return map[string]interface{}{"rules": []interface{}{rule}}, nil
+}
+
+func shadowServiceSpec(name string, flag *flags.CreateShadowService) (map[string]interface{}, error) {
+	if flag.Service == "" {
+		return nil, errors.Errorf("--service is required")
+	}
+
+	return map[string]interface{}{
+		"name":        name,
+		"namespace":   flag.Namespace,
+		"serviceName": flag.Service,
+	}, nil
+}
+
+// parsePairs parses values of the flag in the form key=value.
+func parsePairs(flag string, values []string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, kv := range values {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid %s %q, must be in the form key=value", flag, kv)
+		}
+		pairs[kv[:i]] = kv[i+1:]
+	}
+	return pairs, nil
+}
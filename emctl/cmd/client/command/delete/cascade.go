@@ -0,0 +1,412 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// dependent is a resource referencing resources to delete.
+	dependent struct {
+		object meta.MeshObject
+		// patch is the object without references to the deleted resources,
+		// the object is deleted as well if it's nil.
+		patch meta.MeshObject
+		// references are the deleted resources referenced by the object.
+		references []string
+	}
+
+	// cascade finds dependents of resources to delete, they are services
+	// registered to deleted tenants, and resources referencing deleted
+	// tenants, services or ingresses.
+	cascade struct {
+		client meshclient.MeshClient
+
+		deleted    map[string]bool
+		names      map[string]map[string]bool
+		dependents []*dependent
+	}
+)
+
+// String returns the dependent with the resources it references.
+func (d *dependent) String() string {
+	return fmt.Sprintf("%s/%s references %v", d.object.Kind(), d.object.Name(), d.references)
+}
+
+// apply deletes the dependent, or patches it without the references.
+func (d *dependent) apply(client meshclient.MeshClient, timeout time.Duration) error {
+	if d.patch == nil {
+		return WrapDeleterByMeshObject(d.object, client, timeout).Delete()
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+	switch patch := d.patch.(type) {
+	case *resource.Ingress:
+		return client.V1Alpha1().Ingress().Patch(ctx, patch)
+	case *resource.ServiceCanary:
+		return client.V1Alpha1().ServiceCanary().Patch(ctx, patch)
+	case *resource.WAFPolicy:
+		return client.V1Alpha1().WAFPolicy().Patch(ctx, patch)
+	}
+	return errors.Errorf("patching %s is unsupported", d.patch.Kind())
+}
+
+// dependentsOf returns dependents of the objects, resources come before
+// the ones they reference, so they could be deleted or patched in order.
+func dependentsOf(ctx context.Context, client meshclient.MeshClient, objects []meta.MeshObject) ([]*dependent, error) {
+	c := &cascade{
+		client:  client,
+		deleted: map[string]bool{},
+		names:   map[string]map[string]bool{},
+	}
+	for _, object := range objects {
+		c.markDeleted(object)
+	}
+
+	// NOTE: Services of tenants are found first, as resources referencing
+	// them are dependents as well, so do WAF policies of deleted ingresses.
+	for _, find := range []func(context.Context) error{
+		c.findServices,
+		c.findTenantReferences,
+		c.findServiceReferences,
+		c.findIngressReferences,
+	} {
+		err := find(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dependents := make([]*dependent, 0, len(c.dependents))
+	for i := len(c.dependents) - 1; i >= 0; i-- {
+		dependents = append(dependents, c.dependents[i])
+	}
+	return dependents, nil
+}
+
+func (c *cascade) markDeleted(object meta.MeshObject) {
+	c.deleted[object.Kind()+"/"+object.Name()] = true
+	if c.names[object.Kind()] == nil {
+		c.names[object.Kind()] = map[string]bool{}
+	}
+	c.names[object.Kind()][object.Name()] = true
+}
+
+func (c *cascade) isDeleted(kind, name string) bool {
+	return c.names[kind][name]
+}
+
+// add adds the dependent unless it's deleted already, references are names
+// of resources of the kind.
+func (c *cascade) add(object, patch meta.MeshObject, kind string, references ...string) {
+	if c.deleted[object.Kind()+"/"+object.Name()] {
+		return
+	}
+	d := &dependent{object: object, patch: patch}
+	for _, reference := range references {
+		d.references = append(d.references, kind+"/"+reference)
+	}
+	c.dependents = append(c.dependents, d)
+	if patch == nil {
+		c.markDeleted(object)
+	}
+}
+
+func (c *cascade) findServices(ctx context.Context) error {
+	if len(c.names[resource.KindTenant]) == 0 {
+		return nil
+	}
+
+	services, err := c.client.V1Alpha1().Service().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list services")
+	}
+	for _, service := range services {
+		if service.Spec != nil && c.isDeleted(resource.KindTenant, service.Spec.RegisterTenant) {
+			c.add(service, nil, resource.KindTenant, service.Spec.RegisterTenant)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findTenantReferences(ctx context.Context) error {
+	for tenant := range c.names[resource.KindTenant] {
+		policy, err := c.client.V1Alpha1().TenantPolicy().Get(ctx, tenant)
+		if err = ignoreMissing(err); err != nil {
+			return errors.Wrapf(err, "get tenant policy %s", tenant)
+		}
+		if policy != nil {
+			c.add(policy, nil, resource.KindTenant, tenant)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findServiceReferences(ctx context.Context) error {
+	if len(c.names[resource.KindService]) == 0 && len(c.names[resource.KindTenant]) == 0 {
+		return nil
+	}
+	for _, find := range []func(context.Context) error{
+		c.findAlertRules,
+		c.findServiceCanaries,
+		c.findIngresses,
+		c.findIngressPorts,
+		c.findSLOs,
+		c.findMaintenanceModes,
+		c.findMessagingPolicies,
+		c.findPolicyRollouts,
+	} {
+		err := find(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findAlertRules(ctx context.Context) error {
+	rules, err := c.client.V1Alpha1().AlertRule().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list alert rules")
+	}
+	for _, rule := range rules {
+		switch {
+		case rule.Spec == nil:
+		case c.isDeleted(resource.KindService, rule.Spec.Service):
+			c.add(rule, nil, resource.KindService, rule.Spec.Service)
+		case c.isDeleted(resource.KindTenant, rule.Spec.Tenant):
+			c.add(rule, nil, resource.KindTenant, rule.Spec.Tenant)
+		}
+	}
+	return nil
+}
+
+// findServiceCanaries finds service canaries selecting deleted services,
+// they are patched to select the others, or deleted if none is left.
+func (c *cascade) findServiceCanaries(ctx context.Context) error {
+	canaries, err := c.client.V1Alpha1().ServiceCanary().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list service canaries")
+	}
+	for _, canary := range canaries {
+		if canary.Spec == nil || canary.Spec.Selector == nil {
+			continue
+		}
+
+		kept, deleted := []string{}, []string{}
+		for _, service := range canary.Spec.Selector.MatchServices {
+			if c.isDeleted(resource.KindService, service) {
+				deleted = append(deleted, service)
+			} else {
+				kept = append(kept, service)
+			}
+		}
+		switch {
+		case len(deleted) == 0:
+		case len(kept) == 0:
+			c.add(canary, nil, resource.KindService, deleted...)
+		default:
+			selector := proto.Clone(canary.Spec.Selector).(*v1alpha1.ServiceSelector)
+			selector.MatchServices = kept
+			spec := *canary.Spec
+			spec.Selector = selector
+			patch := *canary
+			patch.Spec = &spec
+			c.add(canary, &patch, resource.KindService, deleted...)
+		}
+	}
+	return nil
+}
+
+// findIngresses finds ingresses routing to deleted services, paths to them
+// are removed, and the ingress is deleted if no rule is left.
+func (c *cascade) findIngresses(ctx context.Context) error {
+	ingresses, err := c.client.V1Alpha1().Ingress().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list ingresses")
+	}
+	for _, ingress := range ingresses {
+		if ingress.Spec == nil {
+			continue
+		}
+
+		rules, deleted := []*v1alpha1.IngressRule{}, []string{}
+		for _, rule := range ingress.Spec.Rules {
+			paths := []*v1alpha1.IngressPath{}
+			for _, path := range rule.Paths {
+				if c.isDeleted(resource.KindService, path.Backend) {
+					deleted = appendUnique(deleted, path.Backend)
+				} else {
+					paths = append(paths, path)
+				}
+			}
+			if len(paths) == 0 && len(rule.Paths) != 0 {
+				continue
+			}
+			r := proto.Clone(rule).(*v1alpha1.IngressRule)
+			r.Paths = paths
+			rules = append(rules, r)
+		}
+		switch {
+		case len(deleted) == 0:
+		case len(rules) == 0:
+			c.add(ingress, nil, resource.KindService, deleted...)
+		default:
+			spec := *ingress.Spec
+			spec.Rules = rules
+			patch := *ingress
+			patch.Spec = &spec
+			c.add(ingress, &patch, resource.KindService, deleted...)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findIngressPorts(ctx context.Context) error {
+	ports, err := c.client.V1Alpha1().IngressPort().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list ingress ports")
+	}
+	for _, port := range ports {
+		if port.Spec != nil && c.isDeleted(resource.KindService, port.Spec.Backend) {
+			c.add(port, nil, resource.KindService, port.Spec.Backend)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findSLOs(ctx context.Context) error {
+	slos, err := c.client.V1Alpha1().SLO().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list SLOs")
+	}
+	for _, slo := range slos {
+		if slo.Spec != nil && c.isDeleted(resource.KindService, slo.Spec.Service) {
+			c.add(slo, nil, resource.KindService, slo.Spec.Service)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findMaintenanceModes(ctx context.Context) error {
+	modes, err := c.client.V1Alpha1().MaintenanceMode().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list maintenance modes")
+	}
+	for _, mode := range modes {
+		if mode.Spec != nil && c.isDeleted(resource.KindService, mode.Spec.Service) {
+			c.add(mode, nil, resource.KindService, mode.Spec.Service)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findMessagingPolicies(ctx context.Context) error {
+	policies, err := c.client.V1Alpha1().MessagingPolicy().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list messaging policies")
+	}
+	for _, policy := range policies {
+		if policy.Spec != nil && c.isDeleted(resource.KindService, policy.Spec.Service) {
+			c.add(policy, nil, resource.KindService, policy.Spec.Service)
+		}
+	}
+	return nil
+}
+
+func (c *cascade) findPolicyRollouts(ctx context.Context) error {
+	rollouts, err := c.client.V1Alpha1().PolicyRollout().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list policy rollouts")
+	}
+	for _, rollout := range rollouts {
+		if rollout.Spec != nil && c.isDeleted(resource.KindService, rollout.Spec.Service) {
+			c.add(rollout, nil, resource.KindService, rollout.Spec.Service)
+		}
+	}
+	return nil
+}
+
+// findIngressReferences finds WAF policies targeting deleted ingresses,
+// the targets are removed, and the policy is deleted if none is left.
+func (c *cascade) findIngressReferences(ctx context.Context) error {
+	if len(c.names[resource.KindIngress]) == 0 {
+		return nil
+	}
+
+	policies, err := c.client.V1Alpha1().WAFPolicy().List(ctx)
+	if err = ignoreMissing(err); err != nil {
+		return errors.Wrap(err, "list WAF policies")
+	}
+	for _, policy := range policies {
+		if policy.Spec == nil {
+			continue
+		}
+
+		targets, deleted := []*resource.WAFTarget{}, []string{}
+		for _, target := range policy.Spec.Targets {
+			if c.isDeleted(resource.KindIngress, target.Ingress) {
+				deleted = appendUnique(deleted, target.Ingress)
+			} else {
+				targets = append(targets, target)
+			}
+		}
+		switch {
+		case len(deleted) == 0:
+		case len(targets) == 0:
+			c.add(policy, nil, resource.KindIngress, deleted...)
+		default:
+			spec := *policy.Spec
+			spec.Targets = targets
+			patch := *policy
+			patch.Spec = &spec
+			c.add(policy, &patch, resource.KindIngress, deleted...)
+		}
+	}
+	return nil
+}
+
+// ignoreMissing ignores errors of missing resources, resources of APIs
+// not served by the control plane are missing as well.
+func ignoreMissing(err error) error {
+	if meshclient.IsNotFoundError(err) || meshclient.IsUnsupportedError(err) {
+		return nil
+	}
+	return err
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+)
+
+func prepareCascadeClient() meshclient.MeshClient {
+	service := func(name, tenant string) meta.MeshObject {
+		return &resource.Service{
+			MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, name),
+			Spec:         &resource.ServiceSpec{RegisterTenant: tenant},
+		}
+	}
+	canary := func(name string, services ...string) meta.MeshObject {
+		return &resource.ServiceCanary{
+			MeshResource: resource.NewServiceCanaryResource(resource.DefaultAPIVersion, name),
+			Spec:         &resource.ServiceCanarySpec{Selector: &v1alpha1.ServiceSelector{MatchServices: services}},
+		}
+	}
+	ingress := func(name string, backends ...string) meta.MeshObject {
+		rule := &v1alpha1.IngressRule{}
+		for _, backend := range backends {
+			rule.Paths = append(rule.Paths, &v1alpha1.IngressPath{Path: "/" + backend, Backend: backend})
+		}
+		return &resource.Ingress{
+			MeshResource: resource.NewIngressResource(resource.DefaultAPIVersion, name),
+			Spec:         &resource.IngressSpec{Rules: []*v1alpha1.IngressRule{rule}},
+		}
+	}
+
+	reactorType := "__test_cascade_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindService, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{service("order", "shop"), service("cart", "shop"), service("pay", "bank")}, nil
+		}).
+		AddReactor("list", resource.KindServiceCanary, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{canary("order-beta", "order"), canary("mixed-beta", "order", "pay"), canary("pay-beta", "pay")}, nil
+		}).
+		AddReactor("list", resource.KindIngress, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{ingress("shop-gateway", "order", "cart"), ingress("gateway", "cart", "pay")}, nil
+		}).
+		AddReactor("list", resource.KindWAFPolicy, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{&resource.WAFPolicy{
+				MeshResource: resource.NewWAFPolicyResource(resource.DefaultAPIVersion, "waf"),
+				Spec: &resource.WAFPolicySpec{Targets: []*resource.WAFTarget{
+					{Ingress: "shop-gateway"}, {Ingress: "gateway"},
+				}},
+			}}, nil
+		}).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	return meshclient.NewFakeClient(reactorType)
+}
+
+func TestDependentsOf(t *testing.T) {
+	client := prepareCascadeClient()
+	tenant := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "shop")}
+
+	dependents, err := dependentsOf(context.Background(), client, []meta.MeshObject{tenant})
+	if err != nil {
+		t.Fatalf("find dependents failed: %v", err)
+	}
+
+	actions := []string{}
+	for _, d := range dependents {
+		action := "delete"
+		if d.patch != nil {
+			action = "patch"
+		}
+		actions = append(actions, fmt.Sprintf("%s %s", action, d))
+	}
+	expected := []string{
+		"patch WAFPolicy/waf references [Ingress/shop-gateway]",
+		"patch Ingress/gateway references [Service/cart]",
+		"delete Ingress/shop-gateway references [Service/order Service/cart]",
+		"patch ServiceCanary/mixed-beta references [Service/order]",
+		"delete ServiceCanary/order-beta references [Service/order]",
+		"delete Service/cart references [Tenant/shop]",
+		"delete Service/order references [Tenant/shop]",
+	}
+	if strings.Join(actions, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expect dependents:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), strings.Join(actions, "\n"))
+	}
+
+	gateway := dependents[1].patch.(*resource.Ingress)
+	if paths := gateway.Spec.Rules[0].Paths; len(paths) != 1 || paths[0].Backend != "pay" {
+		t.Fatalf("expect ingress gateway routing to pay only, but got %+v", paths)
+	}
+	if paths := dependents[1].object.(*resource.Ingress).Spec.Rules[0].Paths; len(paths) != 2 {
+		t.Fatalf("expect listed ingress gateway unchanged, but got %+v", paths)
+	}
+	mixed := dependents[3].patch.(*resource.ServiceCanary)
+	if services := mixed.Spec.Selector.MatchServices; len(services) != 1 || services[0] != "pay" {
+		t.Fatalf("expect service canary mixed-beta selecting pay only, but got %v", services)
+	}
+
+	payCanary := &resource.ServiceCanary{MeshResource: resource.NewServiceCanaryResource(resource.DefaultAPIVersion, "pay-beta")}
+	dependents, err = dependentsOf(context.Background(), client, []meta.MeshObject{payCanary})
+	if err != nil || len(dependents) != 0 {
+		t.Fatalf("expect no dependents of service canary, but got %v, %v", dependents, err)
+	}
+}
+
+func TestResolveDependents(t *testing.T) {
+	client := prepareCascadeClient()
+	objects := []meta.MeshObject{&resource.Service{MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "pay")}}
+	flag := &flags.Delete{AdminGlobal: &flags.AdminGlobal{Timeout: time.Second}}
+
+	out := &bytes.Buffer{}
+	_, err := resolveDependents(strings.NewReader("y\n"), out, client, objects, flag)
+	if common.ExitCode(err) != common.ExitCodeConflict {
+		t.Fatalf("expect deletion blocked by dependents, but got %v", err)
+	}
+	if !strings.Contains(out.String(), "ServiceCanary/pay-beta references [Service/pay]") {
+		t.Fatalf("expect dependents listed, but got %q", out.String())
+	}
+
+	flag.Force = true
+	dependents, err := resolveDependents(strings.NewReader(""), &bytes.Buffer{}, client, objects, flag)
+	if err != nil || len(dependents) != 0 {
+		t.Fatalf("expect references left dangling by --force, but got %v, %v", dependents, err)
+	}
+
+	flag.Cascade, flag.Force = true, false
+	for _, answer := range []string{"n\n", ""} {
+		_, err = resolveDependents(strings.NewReader(answer), &bytes.Buffer{}, client, objects, flag)
+		if err == nil {
+			t.Fatalf("expect deletion aborted by answer %q", answer)
+		}
+	}
+
+	out.Reset()
+	dependents, err = resolveDependents(strings.NewReader("yes\n"), out, client, objects, flag)
+	if err != nil || len(dependents) != 3 {
+		t.Fatalf("expect 3 dependents confirmed, but got %v, %v", dependents, err)
+	}
+	if !strings.Contains(out.String(), "Delete 2 resources and patch 2 resources? [y/N]") {
+		t.Fatalf("expect confirmation asked, but got %q", out.String())
+	}
+
+	flag.Force = true
+	dependents, err = resolveDependents(strings.NewReader(""), &bytes.Buffer{}, client, objects, flag)
+	if err != nil || len(dependents) != 3 {
+		t.Fatalf("expect 3 dependents without confirmation, but got %v, %v", dependents, err)
+	}
+}
@@ -18,7 +18,11 @@
 package delete
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
@@ -28,6 +32,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Run is the entrypoint of the emctl delete sub command
@@ -36,28 +41,45 @@ func Run(cmd *cobra.Command, flag *flags.Delete) {
 		flag.Server = flags.GetServerAddress()
 	}
 
-	visitorBulder := util.NewVisitorBuilder()
-
 	cmdArgs := cmd.Flags().Args()
 
+	if flag.Selector != "" {
+		runBySelector(cmd, cmdArgs, flag)
+		return
+	}
+
+	// NOTE: Resources are deleted by kinds and names, unknown fields don't matter.
+	visitorBulder := util.NewVisitorBuilder().Strict(false)
+
 	if len(cmdArgs) == 0 && flag.YamlFile == "" {
-		common.ExitWithErrorf("no resource specified")
+		common.ExitWithCodef(common.ExitCodeValidation, "no resource specified")
 	}
 
 	if len(cmdArgs) != 0 {
 		if flag.YamlFile != "" {
-			common.ExitWithErrorf("file and command args are both specified")
+			common.ExitWithCodef(common.ExitCodeValidation, "file and command args are both specified")
 		}
 		if len(cmdArgs) != 2 {
-			common.ExitWithErrorf("invalid command args: support <resource kind> <resource name>")
+			common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <resource kind> <resource name>")
+			return
+		}
+		kind, err := util.ResolveCommandKind(meshclient.New(flag.Server), cmdArgs[0], flag.Timeout)
+		if err != nil {
+			common.ExitWithError(err)
+			return
 		}
 		visitorBulder.CommandParam(&util.CommandOptions{
-			Kind: cmdArgs[0],
+			Kind: kind,
 			Name: cmdArgs[1],
 		})
 	}
 
 	if flag.YamlFile != "" {
+		template, err := util.NewTemplate(flag.Values, flag.Set, flag.Env)
+		if err != nil {
+			common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+		}
+		visitorBulder.Template(template)
 		visitorBulder.FilenameParam(&util.FilenameOptions{
 			Recursive: flag.Recursive,
 			Filenames: []string{flag.YamlFile},
@@ -66,22 +88,17 @@ func Run(cmd *cobra.Command, flag *flags.Delete) {
 
 	vss, err := visitorBulder.Do()
 	if err != nil {
-		common.ExitWithErrorf("build visitor failed: %s", err)
+		common.ExitWithErrorf("build visitor failed: %w", err)
 	}
 
+	var objects []meta.MeshObject
 	var errs []error
 	for _, vs := range vss {
 		err := vs.Visit(func(mo meta.MeshObject, e error) error {
 			if e != nil {
 				return errors.Wrap(e, "visit failed")
 			}
-
-			err := WrapDeleterByMeshObject(mo, meshclient.New(flag.Server), flag.Timeout).Delete()
-			if err != nil {
-				return errors.Wrapf(err, "%s/%s deleted failed", mo.Kind(), mo.Name())
-			}
-
-			fmt.Printf("%s/%s deleted successfully\n", mo.Kind(), mo.Name())
+			objects = append(objects, mo)
 			return nil
 		})
 
@@ -92,7 +109,183 @@ func Run(cmd *cobra.Command, flag *flags.Delete) {
 		}
 	}
 
+	client := meshclient.New(flag.Server)
+	deleteDependents(cmd, client, objects, flag)
+
+	for _, mo := range objects {
+		err := WrapDeleterByMeshObject(mo, client, flag.Timeout).Delete()
+		if err != nil {
+			err = errors.Wrapf(err, "%s/%s deleted failed", mo.Kind(), mo.Name())
+			common.OutputError(err)
+			errs = append(errs, err)
+			continue
+		}
+
+		common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+			Infof("%s/%s deleted successfully", mo.Kind(), mo.Name())
+	}
+
+	if len(errs) > 0 {
+		common.ExitWithCodef(common.ExitCodeOf(errs...), "deleting resources has errors occurred")
+	}
+}
+
+// runBySelector deletes resources matching the label selector,
+// a kind could be specified to delete resources of the kind only.
+func runBySelector(cmd *cobra.Command, cmdArgs []string, flag *flags.Delete) {
+	if flag.YamlFile != "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "file and label selector are both specified")
+		return
+	}
+	if len(cmdArgs) > 1 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support [resource kind] with label selector")
+		return
+	}
+
+	selector, err := labels.Parse(flag.Selector)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid label selector %s: %v", flag.Selector, err)
+		return
+	}
+
+	client := meshclient.New(flag.Server)
+	kind := ""
+	if len(cmdArgs) == 1 {
+		kind, err = util.ResolveCommandKind(client, cmdArgs[0], flag.Timeout)
+		if err != nil {
+			common.ExitWithError(err)
+			return
+		}
+	}
+
+	objects, err := SelectResources(client, kind, selector, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("select resources failed: %w", err)
+		return
+	}
+	if len(objects) == 0 {
+		common.Logger().With("selector", flag.Selector).
+			Infof("no resources matching %s found", flag.Selector)
+		return
+	}
+
+	deleteDependents(cmd, client, objects, flag)
+
+	var errs []error
+	for _, mo := range objects {
+		err := WrapDeleterByMeshObject(mo, client, flag.Timeout).Delete()
+		switch {
+		case meshclient.IsNotFoundError(err):
+			common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+				Warnf("%s/%s already deleted", mo.Kind(), mo.Name())
+		case err != nil:
+			err = errors.Wrapf(err, "%s/%s deleted failed", mo.Kind(), mo.Name())
+			common.OutputError(err)
+			errs = append(errs, err)
+		default:
+			common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+				Infof("%s/%s deleted successfully", mo.Kind(), mo.Name())
+		}
+	}
+
 	if len(errs) > 0 {
-		common.ExitWithErrorf("deleting resources has errors occurred")
+		common.ExitWithCodef(common.ExitCodeOf(errs...), "deleting resources has errors occurred")
+	}
+}
+
+// deleteDependents deletes or patches resources referencing the objects,
+// which block the deletion unless they are handled together by --cascade
+// after the confirmation, or left dangling by --force.
+func deleteDependents(cmd *cobra.Command, client meshclient.MeshClient, objects []meta.MeshObject, flag *flags.Delete) {
+	dependents, err := resolveDependents(cmd.InOrStdin(), cmd.ErrOrStderr(), client, objects, flag)
+	if err != nil {
+		common.ExitWithError(err)
+		return
+	}
+
+	for _, d := range dependents {
+		action := "deleted"
+		if d.patch != nil {
+			action = "patched"
+		}
+
+		err := d.apply(client, flag.Timeout)
+		if err != nil {
+			common.ExitWithErrorf("%s/%s %s failed: %w", d.object.Kind(), d.object.Name(), action, err)
+			return
+		}
+
+		common.Logger().With("kind", d.object.Kind(), "name", d.object.Name()).
+			Infof("%s/%s %s successfully", d.object.Kind(), d.object.Name(), action)
+	}
+}
+
+// resolveDependents returns dependents of the objects to delete or patch
+// before the objects.
+func resolveDependents(in io.Reader, out io.Writer, client meshclient.MeshClient,
+	objects []meta.MeshObject, flag *flags.Delete) ([]*dependent, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+
+	dependents, err := dependentsOf(ctx, client, objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "find resources referencing the deleted ones")
+	}
+	if len(dependents) == 0 {
+		return nil, nil
+	}
+
+	if !flag.Cascade {
+		if flag.Force {
+			for _, d := range dependents {
+				common.Warnf("%s, which is left dangling", d)
+			}
+			return nil, nil
+		}
+
+		fmt.Fprintln(out, "Resources referencing the deleted ones:")
+		for _, d := range dependents {
+			fmt.Fprintf(out, "  %s\n", d)
+		}
+		return nil, common.CodeErrorf(common.ExitCodeConflict,
+			"%d resources reference the deleted ones, delete them together by --cascade or leave them dangling by --force",
+			len(dependents))
+	}
+
+	deleted, patched := len(objects), 0
+	fmt.Fprintln(out, "Resources referencing the deleted ones:")
+	for _, d := range dependents {
+		action := "delete"
+		if d.patch != nil {
+			action = "patch"
+			patched++
+		} else {
+			deleted++
+		}
+		fmt.Fprintf(out, "  %s %s\n", action, d)
+	}
+	if flag.Force {
+		return dependents, nil
+	}
+
+	if !confirm(in, out, fmt.Sprintf("Delete %d resources and patch %d resources?", deleted, patched)) {
+		return nil, common.CodeErrorf(common.ExitCodeGeneral, "deletion aborted, confirm it or pass --force")
+	}
+	return dependents, nil
+}
+
+// confirm asks the question, answers other than y and yes mean no,
+// so do no answer at the end of the input.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil {
+		fmt.Fprintln(out)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
 	}
+	return false
 }
@@ -28,8 +28,22 @@ import (
 	"github.com/pkg/errors"
 )
 
-// WrapDeleterByMeshObject returns a new Deleter from a MeshObject
+// WrapDeleterByMeshObject returns a new Deleter from a MeshObject, which
+// deletes labels and annotations of the object as well.
 func WrapDeleterByMeshObject(object meta.MeshObject,
+	client meshclient.MeshClient, timeout time.Duration) Deleter {
+	deleter := wrapDeleterByKind(object, client, timeout)
+	if !resource.HasResourceMeta(object.Kind()) {
+		return deleter
+	}
+	return &resourceMetaDeleter{
+		baseDeleter: baseDeleter{client: client, timeout: timeout},
+		deleter:     deleter,
+		object:      object,
+	}
+}
+
+func wrapDeleterByKind(object meta.MeshObject,
 	client meshclient.MeshClient, timeout time.Duration) Deleter {
 	switch object.Kind() {
 	case resource.KindMeshController:
@@ -62,6 +76,24 @@ func WrapDeleterByMeshObject(object meta.MeshObject,
 		return &trafficTargetDeleter{object: object.(*resource.TrafficTarget), baseDeleter: baseDeleter{client: client, timeout: timeout}}
 	case resource.KindServiceCanary:
 		return &serviceCanaryDeleter{object: object.(*resource.ServiceCanary), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindExternalService:
+		return &externalServiceDeleter{object: object.(*resource.ExternalService), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindTenantPolicy:
+		return &tenantPolicyDeleter{object: object.(*resource.TenantPolicy), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindSLO:
+		return &sloDeleter{object: object.(*resource.SLO), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindAlertRule:
+		return &alertRuleDeleter{object: object.(*resource.AlertRule), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindMessagingPolicy:
+		return &messagingPolicyDeleter{object: object.(*resource.MessagingPolicy), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindIngressPort:
+		return &ingressPortDeleter{object: object.(*resource.IngressPort), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindWAFPolicy:
+		return &wafPolicyDeleter{object: object.(*resource.WAFPolicy), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindMaintenanceMode:
+		return &maintenanceModeDeleter{object: object.(*resource.MaintenanceMode), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindPolicyRollout:
+		return &policyRolloutDeleter{object: object.(*resource.PolicyRollout), baseDeleter: baseDeleter{client: client, timeout: timeout}}
 	case resource.KindCustomResourceKind:
 		return &customResourceKindDeleter{object: object.(*resource.CustomResourceKind), baseDeleter: baseDeleter{client: client, timeout: timeout}}
 	default:
@@ -323,6 +355,159 @@ func (sc *serviceCanaryDeleter) Delete() error {
 	return err
 }
 
+type externalServiceDeleter struct {
+	baseDeleter
+	object *resource.ExternalService
+}
+
+func (e *externalServiceDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), e.timeout)
+	defer cancelFunc()
+
+	err := e.client.V1Alpha1().ExternalService().Delete(ctx, e.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete external service %s", e.object.Name())
+	}
+
+	return err
+}
+
+type tenantPolicyDeleter struct {
+	baseDeleter
+	object *resource.TenantPolicy
+}
+
+func (t *tenantPolicyDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), t.timeout)
+	defer cancelFunc()
+
+	err := t.client.V1Alpha1().TenantPolicy().Delete(ctx, t.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete tenant policy %s", t.object.Name())
+	}
+
+	return err
+}
+
+type sloDeleter struct {
+	baseDeleter
+	object *resource.SLO
+}
+
+func (s *sloDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFunc()
+
+	err := s.client.V1Alpha1().SLO().Delete(ctx, s.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete SLO %s", s.object.Name())
+	}
+
+	return err
+}
+
+type alertRuleDeleter struct {
+	baseDeleter
+	object *resource.AlertRule
+}
+
+func (a *alertRuleDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), a.timeout)
+	defer cancelFunc()
+
+	err := a.client.V1Alpha1().AlertRule().Delete(ctx, a.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete alert rule %s", a.object.Name())
+	}
+
+	return err
+}
+
+type messagingPolicyDeleter struct {
+	baseDeleter
+	object *resource.MessagingPolicy
+}
+
+func (m *messagingPolicyDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	defer cancelFunc()
+
+	err := m.client.V1Alpha1().MessagingPolicy().Delete(ctx, m.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete messaging policy %s", m.object.Name())
+	}
+
+	return err
+}
+
+type ingressPortDeleter struct {
+	baseDeleter
+	object *resource.IngressPort
+}
+
+func (i *ingressPortDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), i.timeout)
+	defer cancelFunc()
+
+	err := i.client.V1Alpha1().IngressPort().Delete(ctx, i.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete ingress port %s", i.object.Name())
+	}
+
+	return err
+}
+
+type wafPolicyDeleter struct {
+	baseDeleter
+	object *resource.WAFPolicy
+}
+
+func (i *wafPolicyDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), i.timeout)
+	defer cancelFunc()
+
+	err := i.client.V1Alpha1().WAFPolicy().Delete(ctx, i.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete WAF policy %s", i.object.Name())
+	}
+
+	return err
+}
+
+type maintenanceModeDeleter struct {
+	baseDeleter
+	object *resource.MaintenanceMode
+}
+
+func (m *maintenanceModeDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	defer cancelFunc()
+
+	err := m.client.V1Alpha1().MaintenanceMode().Delete(ctx, m.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete maintenance mode %s", m.object.Name())
+	}
+
+	return err
+}
+
+type policyRolloutDeleter struct {
+	baseDeleter
+	object *resource.PolicyRollout
+}
+
+func (m *policyRolloutDeleter) Delete() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	defer cancelFunc()
+
+	err := m.client.V1Alpha1().PolicyRollout().Delete(ctx, m.object.Name())
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete policy rollout %s", m.object.Name())
+	}
+
+	return err
+}
+
 type customResourceKindDeleter struct {
 	baseDeleter
 	object *resource.CustomResourceKind
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package delete
+
+import (
+	"context"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+// resourceMetaDeleter deletes labels and annotations of the object
+// after the object itself is deleted.
+type resourceMetaDeleter struct {
+	baseDeleter
+	deleter Deleter
+	object  meta.MeshObject
+}
+
+func (r *resourceMetaDeleter) Delete() error {
+	err := r.deleter.Delete()
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return err
+	}
+
+	// NOTE: Labels of a resource already missing from the control plane
+	// are deleted as well, but the not found error is still returned.
+	ctx, cancelFunc := context.WithTimeout(context.Background(), r.timeout)
+	defer cancelFunc()
+	name := resource.ResourceMetaName(r.object.Kind(), r.object.Name())
+	metaErr := r.client.V1Alpha1().ResourceMeta().Delete(ctx, name)
+	if metaErr != nil && !meshclient.IsNotFoundError(metaErr) && !meshclient.IsUnsupportedError(metaErr) {
+		return errors.Wrapf(metaErr, "delete resource meta %s", name)
+	}
+	return err
+}
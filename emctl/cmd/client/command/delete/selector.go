@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package delete
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SelectResources returns resources whose labels match the selector,
+// resources of all kinds are returned if the kind is empty.
+func SelectResources(client meshclient.MeshClient, kind string,
+	selector labels.Selector, timeout time.Duration) ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	resourceMetas, err := client.V1Alpha1().ResourceMeta().List(ctx)
+	switch {
+	case meshclient.IsNotFoundError(err):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "list resource meta")
+	}
+
+	objects := []meta.MeshObject{}
+	for _, resourceMeta := range resourceMetas {
+		spec := resourceMeta.Spec
+		if spec.Kind == "" || (kind != "" && spec.Kind != kind) {
+			continue
+		}
+		if !selector.Matches(labels.Set(spec.Labels)) {
+			continue
+		}
+
+		object, err := resource.NewObjectCreator().NewFromResource(
+			resource.NewMeshResource(resource.DefaultAPIVersion, spec.Kind, spec.Name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "create %s/%s", spec.Kind, spec.Name)
+		}
+		resourceMeta.ApplyTo(object)
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package delete
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSelectResources(t *testing.T) {
+	newResourceMeta := func(kind, name, env string) meta.MeshObject {
+		object, _ := resource.NewObjectCreator().NewFromResource(
+			resource.NewMeshResource(resource.DefaultAPIVersion, kind, name))
+		object.(meta.MetaDataSetter).SetLabels(map[string]string{"env": env})
+		return resource.NewResourceMeta(object)
+	}
+
+	reactorType := "__test_select_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindResourceMeta, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{
+				newResourceMeta(resource.KindService, "orders", "staging"),
+				newResourceMeta(resource.KindTenant, "orders", "staging"),
+				newResourceMeta(resource.KindService, "payments", "production"),
+				newResourceMeta("ShadowService", "orders-shadow", "staging"),
+			}, nil
+		}).
+		Added()
+
+	client := meshclient.NewFakeClient(reactorType)
+	selector, _ := labels.Parse("env=staging")
+	objects, err := SelectResources(client, "", selector, time.Second)
+	if err != nil {
+		t.Fatalf("select resources failed: %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expect 3 resources selected, but got %d", len(objects))
+	}
+	if _, ok := objects[2].(*resource.CustomResource); !ok {
+		t.Fatalf("expect a custom resource, but got %T", objects[2])
+	}
+
+	objects, err = SelectResources(client, resource.KindService, selector, time.Second)
+	if err != nil {
+		t.Fatalf("select resources failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Name() != "orders" || objects[0].Labels()["env"] != "staging" {
+		t.Fatalf("expect service orders selected, but got %+v", objects)
+	}
+}
@@ -0,0 +1,316 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package demo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/delete"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// pollInterval is the interval of polling the status of demo apps.
+const pollInterval = 2 * time.Second
+
+type (
+	// step is a step of installing or uninstalling the demo.
+	step struct {
+		name string
+		run  func() error
+	}
+
+	demo struct {
+		namespace     string
+		meshNamespace string
+		timeout       time.Duration
+
+		client     kubernetes.Interface
+		meshClient meshclient.MeshClient
+	}
+)
+
+// RunInstall is the entrypoint of the emctl demo install sub command
+func RunInstall(cmd *cobra.Command, flag *flags.DemoInstall) {
+	d := newDemo(flag.AdminGlobal, flag.OperationGlobal, flag.Namespace)
+
+	steps := []step{
+		{name: "create namespace", run: d.createNamespace},
+		{name: "apply mesh resources", run: d.applyMeshResources},
+		{name: "deploy demo apps", run: func() error { return d.deployApps(flag.ImageRegistryURL, flag.ImageTag) }},
+	}
+	if flag.WaitTimeout > 0 {
+		steps = append(steps, step{name: "wait for sidecars injected", run: func() error { return d.waitAppsReady(flag.WaitTimeout) }})
+	}
+
+	err := runSteps(steps)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	printGuide(os.Stdout, d.namespace, d.ingressAddress())
+}
+
+// RunUninstall is the entrypoint of the emctl demo uninstall sub command
+func RunUninstall(cmd *cobra.Command, flag *flags.DemoUninstall) {
+	d := newDemo(flag.AdminGlobal, flag.OperationGlobal, flag.Namespace)
+
+	err := runSteps([]step{
+		{name: "delete mesh resources", run: d.deleteMeshResources},
+		{name: "delete namespace", run: d.deleteNamespace},
+	})
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	common.Infof("demo in namespace %s uninstalled", d.namespace)
+}
+
+func newDemo(admin *flags.AdminGlobal, operation *flags.OperationGlobal, namespace string) *demo {
+	if admin.Server == "" {
+		admin.Server = flags.GetServerAddress()
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	return &demo{
+		namespace:     namespace,
+		meshNamespace: operation.MeshNamespace,
+		timeout:       admin.Timeout,
+		client:        client,
+		meshClient:    meshclient.New(admin.Server),
+	}
+}
+
+func runSteps(steps []step) error {
+	for _, s := range steps {
+		common.Infof("%s", s.name)
+		err := s.run()
+		if err != nil {
+			return errors.Wrap(err, s.name)
+		}
+	}
+	return nil
+}
+
+func (d *demo) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), d.timeout)
+}
+
+// createNamespace creates the namespace of demo apps labeled for the
+// sidecar injection, installing the demo again keeps the namespace.
+func (d *demo) createNamespace() error {
+	return installbase.DeployNamespace(demoNamespace(d.namespace), d.client)
+}
+
+func (d *demo) applyMeshResources() error {
+	objects, err := demoMeshObjects()
+	if err != nil {
+		return errors.Wrap(err, "decode mesh resources of the demo")
+	}
+
+	for _, object := range objects {
+		err := apply.WrapApplierByMeshObject(object, d.meshClient, d.timeout).Apply()
+		if err != nil {
+			return errors.Wrapf(err, "apply %s/%s", object.Kind(), object.Name())
+		}
+	}
+	return nil
+}
+
+func (d *demo) deployApps(registryURL, tag string) error {
+	for _, app := range demoApps {
+		deployment := demoDeployment(app, d.namespace, demoImage(registryURL, app.image, tag))
+		err := installbase.DeployDeployment(deployment, d.client, d.namespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy %s", app.name)
+		}
+	}
+	return nil
+}
+
+// waitAppsReady waits for pods of all demo apps are ready,
+// with sidecars injected by the mesh operator.
+func (d *demo) waitAppsReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := d.checkAppsReady()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "timeout after %s", timeout)
+		}
+		common.Debugf("%v, retry in %s", err, pollInterval)
+		time.Sleep(pollInterval)
+	}
+}
+
+func (d *demo) checkAppsReady() error {
+	ctx, cancelFunc := d.requestContext()
+	defer cancelFunc()
+
+	for _, app := range demoApps {
+		pods, err := d.client.CoreV1().Pods(d.namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", demoAppLabelKey, app.name),
+		})
+		if err != nil {
+			return err
+		}
+		if len(pods.Items) == 0 {
+			return errors.Errorf("no pod of %s", app.name)
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if !hasSidecar(pod) {
+				return errors.Errorf("no sidecar injected into pod %s, is the mesh operator running?", pod.Name)
+			}
+			if !podReady(pod) {
+				return errors.Errorf("pod %s is not ready", pod.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func hasSidecar(pod *v1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == sidecarContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// deleteMeshResources deletes the mesh resources in the reverse order of creating.
+func (d *demo) deleteMeshResources() error {
+	objects, err := demoMeshObjects()
+	if err != nil {
+		return errors.Wrap(err, "decode mesh resources of the demo")
+	}
+
+	var errs []string
+	for i := len(objects) - 1; i >= 0; i-- {
+		object := objects[i]
+		err := delete.WrapDeleterByMeshObject(object, d.meshClient, d.timeout).Delete()
+		if err != nil && !meshclient.IsNotFoundError(err) {
+			errs = append(errs, fmt.Sprintf("delete %s/%s: %v", object.Kind(), object.Name(), err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (d *demo) deleteNamespace() error {
+	ctx, cancelFunc := d.requestContext()
+	defer cancelFunc()
+
+	err := d.client.CoreV1().Namespaces().Delete(ctx, d.namespace, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// ingressAddress returns the address of the NodePort of the mesh ingress
+// service, or a placeholder if it's not discovered.
+func (d *demo) ingressAddress() string {
+	const placeholder = "http://{node_ip}:{ingress_node_port}"
+
+	ctx, cancelFunc := d.requestContext()
+	defer cancelFunc()
+
+	service, err := d.client.CoreV1().Services(d.meshNamespace).Get(ctx,
+		installbase.IngressControllerServiceName, metav1.GetOptions{})
+	if err != nil || len(service.Spec.Ports) == 0 || service.Spec.Ports[0].NodePort == 0 {
+		return placeholder
+	}
+	nodePort := service.Spec.Ports[0].NodePort
+
+	// NOTICE: For situation where the node address is not reachable.
+	if addr := os.Getenv("EMCTL_NODE_ADDRESS"); addr != "" {
+		return fmt.Sprintf("http://%s:%d", addr, nodePort)
+	}
+
+	nodes, err := d.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return placeholder
+	}
+	for _, n := range nodes.Items {
+		for _, address := range n.Status.Addresses {
+			if address.Type == v1.NodeInternalIP {
+				return fmt.Sprintf("http://%s:%d", address.Address, nodePort)
+			}
+		}
+	}
+	return placeholder
+}
+
+func printGuide(w io.Writer, namespace, ingressAddress string) {
+	request := fmt.Sprintf(`curl %s/ -H 'Host: %s' -d '{"order_id": "abc1234", "food": "bread"}'`,
+		ingressAddress, demoIngressHost)
+
+	fmt.Fprintf(w, `The takeaway demo is installed in namespace %s.
+
+Order food through the mesh ingress:
+  %s
+
+Route the delivery to the canary delivery-mesh-beijing:
+  %s -H 'X-Location: Beijing'
+
+See the mesh working:
+  emctl graph
+  emctl describe service order-mesh
+  emctl slo status
+  kubectl get pods -n %s
+
+Uninstall the demo:
+  emctl demo uninstall --namespace %s
+`, namespace, request, request, namespace, namespace)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package demo
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/testing/meshserver"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDemoLifecycle(t *testing.T) {
+	server := meshserver.New()
+	defer server.Close()
+
+	d := &demo{
+		namespace:     flags.DefaultDemoNamespace,
+		meshNamespace: flags.DefaultMeshNamespace,
+		timeout:       5 * time.Second,
+		client:        fake.NewSimpleClientset(),
+		meshClient:    server.Client(),
+	}
+
+	install := func() error { return d.deployApps(flags.DefaultImageRegistryURL, flags.DefaultDemoImageTag) }
+	// NOTE: Installing the demo again updates it.
+	for _, fn := range []func() error{d.createNamespace, d.applyMeshResources, install, d.createNamespace, d.applyMeshResources, install} {
+		if err := fn(); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := d.meshClient.V1Alpha1().ServiceCanary().Get(ctx, "delivery-mesh-beijing"); err != nil {
+		t.Fatalf("get service canary failed: %v", err)
+	}
+	canary, err := d.client.AppsV1().Deployments(d.namespace).Get(ctx, "delivery-mesh-beijing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment failed: %v", err)
+	}
+	if canary.Annotations[annotationServiceNameKey] != "delivery-mesh" ||
+		canary.Annotations[annotationServiceLabelsKey] != "release=delivery-mesh-beijing" {
+		t.Fatalf("unexpected annotations of the canary: %v", canary.Annotations)
+	}
+	if image := canary.Spec.Template.Spec.Containers[0].Image; image != "docker.io/megaease/easemesh-demo-delivery-beijing:latest" {
+		t.Fatalf("unexpected image %s", image)
+	}
+
+	// NOTE: No sidecar is injected without the mesh operator.
+	if err := d.waitAppsReady(time.Millisecond); err == nil {
+		t.Fatalf("expected no pod of demo apps")
+	}
+
+	for _, fn := range []func() error{d.deleteMeshResources, d.deleteNamespace, d.deleteMeshResources, d.deleteNamespace} {
+		if err := fn(); err != nil {
+			t.Fatalf("uninstall failed: %v", err)
+		}
+	}
+	if _, err := d.meshClient.V1Alpha1().Tenant().Get(ctx, "takeaway"); err == nil {
+		t.Fatalf("expected tenant deleted")
+	}
+}
+
+func TestPrintGuide(t *testing.T) {
+	buff := &bytes.Buffer{}
+	printGuide(buff, flags.DefaultDemoNamespace, "http://192.168.0.10:30080")
+	for _, want := range []string{
+		"curl http://192.168.0.10:30080/ -H 'Host: takeaway.megaease.com'",
+		"-H 'X-Location: Beijing'",
+		"emctl demo uninstall --namespace easemesh-demo",
+	} {
+		if !strings.Contains(buff.String(), want) {
+			t.Fatalf("guide should contain %q:\n%s", want, buff.String())
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package demo
+
+import (
+	"bytes"
+	_ "embed" // for embedding the mesh resources of the demo
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// annotationPrefix is the prefix of annotations instructing
+	// the mesh operator to inject sidecars.
+	annotationPrefix = "mesh.megaease.com/"
+
+	namespaceLabelKey = annotationPrefix + "mesh-service"
+
+	annotationServiceNameKey     = annotationPrefix + "service-name"
+	annotationServiceLabelsKey   = annotationPrefix + "service-labels"
+	annotationApplicationPortKey = annotationPrefix + "application-port"
+	annotationAliveProbeURLKey   = annotationPrefix + "alive-probe-url"
+
+	sidecarContainerName = "easemesh-sidecar"
+
+	demoAppLabelKey = "app"
+	demoAppPort     = 80
+
+	// demoIngressHost is the host routed to the order service
+	// by the mesh ingress, see stack/mesh.yaml.
+	demoIngressHost = "takeaway.megaease.com"
+)
+
+type (
+	// demoApp is an app of the takeaway demo, a canary app
+	// registers as instances of its service with the release label.
+	demoApp struct {
+		name    string
+		service string
+		image   string
+		release string
+	}
+)
+
+// demoApps are apps of the takeaway demo, the order service calls the
+// restaurant service, which calls the delivery service.
+var demoApps = []*demoApp{
+	{name: "order-mesh", service: "order-mesh", image: "easemesh-demo-order"},
+	{name: "restaurant-mesh", service: "restaurant-mesh", image: "easemesh-demo-restaurant"},
+	{name: "delivery-mesh", service: "delivery-mesh", image: "easemesh-demo-delivery"},
+	{name: "delivery-mesh-beijing", service: "delivery-mesh", image: "easemesh-demo-delivery-beijing", release: "delivery-mesh-beijing"},
+}
+
+//go:embed stack/mesh.yaml
+var demoMeshResources []byte
+
+// demoMeshObjects returns the mesh resources of the demo in the order
+// of creating, the resources which others depend on come first.
+func demoMeshObjects() ([]meta.MeshObject, error) {
+	var objects []meta.MeshObject
+	err := util.NewReaderVisitor(bytes.NewReader(demoMeshResources), "stack/mesh.yaml").
+		Visit(func(object meta.MeshObject, err error) error {
+			if err != nil {
+				return err
+			}
+			objects = append(objects, object)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// demoImage returns the image of the app in the registry.
+func demoImage(registryURL, image, tag string) string {
+	return fmt.Sprintf("%s/megaease/%s:%s", strings.TrimSuffix(registryURL, "/"), image, tag)
+}
+
+func demoNamespace(name string) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				namespaceLabelKey: "true",
+			},
+		},
+	}
+}
+
+func demoDeployment(app *demoApp, namespace, image string) *appsV1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{demoAppLabelKey: app.name}
+
+	annotations := map[string]string{
+		annotationServiceNameKey:     app.service,
+		annotationApplicationPortKey: strconv.Itoa(demoAppPort),
+		annotationAliveProbeURLKey:   fmt.Sprintf("http://localhost:%d/", demoAppPort),
+	}
+	if app.release != "" {
+		annotations[annotationServiceLabelsKey] = "release=" + app.release
+	}
+
+	return &appsV1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        app.name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: appsV1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  app.name,
+							Image: image,
+							Ports: []v1.ContainerPort{
+								{
+									ContainerPort: demoAppPort,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
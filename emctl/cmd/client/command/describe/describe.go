@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+// Run is the entrypoint of the emctl describe sub command
+func Run(cmd *cobra.Command, flag *flags.Describe) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	args := cmd.Flags().Args()
+	if len(args) != 2 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <resource kind> <resource name>")
+		return
+	}
+
+	client := meshclient.New(flag.Server)
+	kind, err := util.ResolveCommandKind(client, args[0], flag.Timeout)
+	if err != nil {
+		common.ExitWithError(err)
+		return
+	}
+
+	object, err := resource.NewObjectCreator().NewFromResource(
+		resource.NewMeshResource(resource.DefaultAPIVersion, kind, args[1]))
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+		return
+	}
+
+	objects, err := get.WrapGetterByMeshObject(object, client, flag.Timeout).Get()
+	if err != nil {
+		common.ExitWithErrorf("get %s/%s failed: %w", kind, args[1], err)
+		return
+	}
+
+	d := &describer{
+		client:    client,
+		telemetry: telemetry.New(flag.Server, flag.Timeout),
+		timeout:   flag.Timeout,
+	}
+	for _, object := range objects {
+		err = d.describe(os.Stdout, object)
+		if err != nil {
+			common.ExitWithErrorf("describe %s/%s failed: %w", kind, args[1], err)
+			return
+		}
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/pkg/errors"
+)
+
+type fakeStatusClient struct{}
+
+func (c *fakeStatusClient) Objects() (map[string]map[string]interface{}, error) {
+	return map[string]map[string]interface{}{
+		"easemesh-controller": {
+			"vets-service-0": map[string]interface{}{
+				"sidecar-ingress-vets-service": map[string]interface{}{"count": 200.0, "errCount": 3.0, "m1Err": 0.05},
+			},
+		},
+	}, nil
+}
+
+func TestDescribeService(t *testing.T) {
+	reactorType := "__describe_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindServiceInstance, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{
+				resource.ToServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets-service", InstanceID: "vets-service-0", Ip: "10.0.0.10", Port: 13001, Status: "UP"}),
+				resource.ToServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "pet-service", InstanceID: "pet-service-0", Ip: "10.0.0.11", Port: 13001, Status: "UP"}),
+			}, nil
+		}).
+		AddReactor("list", resource.KindServiceCanary, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{&resource.ServiceCanary{
+				MeshResource: resource.NewServiceCanaryResource(resource.DefaultAPIVersion, "vets-canary"),
+				Spec: &resource.ServiceCanarySpec{
+					Priority: 5,
+					Selector: &v1alpha1.ServiceSelector{
+						MatchServices:       []string{"vets-service"},
+						MatchInstanceLabels: map[string]string{"release": "vets-canary"},
+					},
+				},
+			}}, nil
+		}).
+		AddReactor("get", resource.KindResilience, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{&resource.Resilience{
+				MeshResource: resource.NewResilienceResource(resource.DefaultAPIVersion, "vets-service"),
+				Spec: &resource.ResilienceSpec{
+					Resilience: v1alpha1.Resilience{CircuitBreaker: &v1alpha1.CircuitBreaker{}},
+				},
+			}}, nil
+		}).
+		AddReactor("list", resource.KindIngress, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{&resource.Ingress{
+				MeshResource: resource.NewIngressResource(resource.DefaultAPIVersion, "pet-ingress"),
+				Spec: &resource.IngressSpec{Rules: []*v1alpha1.IngressRule{{
+					Host:  "pet.example.com",
+					Paths: []*v1alpha1.IngressPath{{Path: "/vets", Backend: "vets-service"}, {Path: "/", Backend: "pet-service"}},
+				}}},
+			}}, nil
+		}).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	d := &describer{
+		client:    meshclient.NewFakeClient(reactorType),
+		telemetry: &fakeStatusClient{},
+		timeout:   time.Second,
+	}
+	service := &resource.Service{
+		MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "vets-service"),
+		Spec:         &resource.ServiceSpec{RegisterTenant: "pet"},
+	}
+	buff := &bytes.Buffer{}
+	err := d.describe(buff, service)
+	if err != nil {
+		t.Fatalf("describe service failed: %v", err)
+	}
+
+	output := buff.String()
+	for _, expected := range []string{
+		"Name:", "vets-service", "registerTenant: pet",
+		"vets-service-0", "10.0.0.10",
+		"vets-canary", "release=vets-canary",
+		"circuitBreaker",
+		"Maintenance:", "<none>",
+		"200", "1.50%",
+		"pet.example.com", "/vets",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("%q not found in output:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"pet-service-0", "10.0.0.11"} {
+		if strings.Contains(output, unexpected) {
+			t.Fatalf("%q of other services found in output:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestDescribeCustomResource(t *testing.T) {
+	reactorType := "__describe_custom_resource_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("*", "*", "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	d := &describer{client: meshclient.NewFakeClient(reactorType), timeout: time.Second}
+	shadow := &resource.CustomResource{
+		MeshResource: resource.NewMeshResource(resource.DefaultAPIVersion, "ShadowService", "vets-shadow"),
+		Spec:         map[string]interface{}{"serviceName": "vets-service"},
+	}
+	buff := &bytes.Buffer{}
+	err := d.describe(buff, shadow)
+	if err != nil {
+		t.Fatalf("describe custom resource failed: %v", err)
+	}
+	if !strings.Contains(buff.String(), "ShadowService") || !strings.Contains(buff.String(), "serviceName: vets-service") ||
+		!strings.Contains(buff.String(), "Status:") {
+		t.Fatalf("unexpected output:\n%s", buff.String())
+	}
+}
+
+func TestWriteStatus(t *testing.T) {
+	buff := &bytes.Buffer{}
+	writeStatus(buff, &resource.ResourceStatus{
+		ObservedRevision: 3,
+		SyncedSidecars:   4,
+		TotalSidecars:    5,
+		LastError:        "sidecar vets-service-1 rejected the config",
+		LastErrorTime:    "2021-11-01T10:00:00Z",
+		Conditions: []*resource.Condition{
+			{Type: resource.ConditionAccepted, Status: resource.ConditionTrue},
+			{Type: resource.ConditionReady, Status: resource.ConditionFalse, Reason: "PropagationFailed"},
+		},
+	}, nil)
+
+	output := buff.String()
+	for _, expected := range []string{
+		"Failed (observed revision 3)", "4/5 synced",
+		"sidecar vets-service-1 rejected the config (at 2021-11-01T10:00:00Z)",
+		"Accepted", "PropagationFailed",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("%q not found in output:\n%s", expected, output)
+		}
+	}
+
+	buff.Reset()
+	writeStatus(buff, nil, errors.Wrap(meshclient.NotFoundError, "get status"))
+	if buff.String() != "Status:\t<none>\n" {
+		t.Fatalf("expected no status, got %q", buff.String())
+	}
+}
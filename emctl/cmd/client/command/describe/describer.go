@@ -0,0 +1,316 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const none = "<none>"
+
+// describer writes the detail of a resource joined with the live status and
+// related objects in the form of kubectl describe.
+type describer struct {
+	client    meshclient.MeshClient
+	telemetry telemetry.Client
+	timeout   time.Duration
+}
+
+func (d *describer) describe(w io.Writer, object meta.MeshObject) error {
+	spec, err := specOf(object)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", object.Name())
+	fmt.Fprintf(tw, "Kind:\t%s\n", object.Kind())
+	fmt.Fprintf(tw, "API Version:\t%s\n", object.APIVersion())
+	fmt.Fprintf(tw, "Labels:\t%s\n", labelsOf(object.Labels()))
+	fmt.Fprintf(tw, "Annotations:\t%s\n", labelsOf(object.Annotations()))
+	if spec == "" {
+		fmt.Fprintf(tw, "Spec:\t%s\n", none)
+	} else {
+		fmt.Fprintf(tw, "Spec:\n")
+		for _, line := range strings.Split(strings.TrimSuffix(spec, "\n"), "\n") {
+			fmt.Fprintf(tw, "  %s\n", line)
+		}
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), d.timeout)
+	defer cancelFunc()
+
+	status, err := d.client.V1Alpha1().Status().Get(ctx, object.Kind(), object.Name())
+	writeStatus(tw, status, err)
+
+	if service, ok := object.(*resource.Service); ok {
+		d.describeService(ctx, tw, service.Name())
+	}
+
+	return tw.Flush()
+}
+
+// describeService writes instances, active canaries, resilience policies,
+// maintenance, recent errors and ingress rules of the service. Failures of
+// getting related objects are shown in place instead of failing the whole.
+func (d *describer) describeService(ctx context.Context, tw io.Writer, service string) {
+	rows := [][]string{}
+	instances, err := d.client.V1Alpha1().ServiceInstance().List(ctx)
+	for _, instance := range instances {
+		if instance.Spec == nil || instance.Spec.ServiceName != service {
+			continue
+		}
+		rows = append(rows, []string{instance.Spec.InstanceID, instance.Spec.Ip,
+			strconv.Itoa(int(instance.Spec.Port)), instance.Spec.Status})
+	}
+	writeSection(tw, "Instances", []string{"ID", "IP", "Port", "Status"}, rows, err)
+
+	rows = [][]string{}
+	canaries, err := d.client.V1Alpha1().ServiceCanary().List(ctx)
+	for _, canary := range canaries {
+		if canary.Spec == nil || canary.Spec.Selector == nil || !contains(canary.Spec.Selector.MatchServices, service) {
+			continue
+		}
+		sticky := "-"
+		if canary.Spec.Sticky != nil {
+			sticky = canary.Spec.Sticky.Mode
+		}
+		match := canary.Spec.Match
+		if match == "" {
+			match = "-"
+		}
+		rows = append(rows, []string{canary.Name(), strconv.Itoa(int(canary.Spec.Priority)),
+			labelsOf(canary.Spec.Selector.MatchInstanceLabels), match, sticky})
+	}
+	writeSection(tw, "Service Canaries", []string{"Name", "Priority", "Instance Labels", "Match", "Sticky"}, rows, err)
+
+	resilience, err := d.client.V1Alpha1().Resilience().Get(ctx, service)
+	writeValue(tw, "Resilience", resiliencePolicies(resilience), err)
+
+	maintenance, err := d.client.V1Alpha1().MaintenanceMode().Get(ctx, service)
+	writeValue(tw, "Maintenance", maintenanceOf(maintenance), err)
+
+	rows = [][]string{}
+	objects, err := d.telemetry.Objects()
+	if err == nil {
+		stats := telemetry.IngressStats(objects, service, "")
+		total := &telemetry.Stat{}
+		for _, stat := range stats {
+			total.Add(stat)
+		}
+		if len(stats) != 0 {
+			rate := 0.0
+			if total.Count != 0 {
+				rate = total.ErrCount / total.Count * 100
+			}
+			rows = append(rows, []string{strconv.Itoa(len(stats)), formatFloat(total.Count), formatFloat(total.ErrCount),
+				fmt.Sprintf("%.2f%%", rate), fmt.Sprintf("%.3f", total.M1Err), fmt.Sprintf("%.3f", total.M5Err)})
+		}
+	}
+	writeSection(tw, "Requests", []string{"Sidecars", "Total", "Errors", "Error Rate", "Errors/s (1m)", "Errors/s (5m)"}, rows, err)
+
+	rows = [][]string{}
+	ingresses, err := d.client.V1Alpha1().Ingress().List(ctx)
+	for _, ingress := range ingresses {
+		if ingress.Spec == nil {
+			continue
+		}
+		for _, rule := range ingress.Spec.Rules {
+			for _, path := range rule.Paths {
+				if path.Backend != service {
+					continue
+				}
+				host := rule.Host
+				if host == "" {
+					host = "*"
+				}
+				rows = append(rows, []string{ingress.Name(), host, path.Path})
+			}
+		}
+	}
+	writeSection(tw, "Ingresses", []string{"Name", "Host", "Path"}, rows, err)
+}
+
+// writeStatus writes the status populated by the control plane, not found
+// errors mean the control plane reports no status of the resource.
+func writeStatus(tw io.Writer, status *resource.ResourceStatus, err error) {
+	if err != nil || status == nil {
+		writeValue(tw, "Status", "", err)
+		return
+	}
+
+	phase := status.Phase()
+	if status.ObservedRevision != 0 {
+		phase += fmt.Sprintf(" (observed revision %d)", status.ObservedRevision)
+	}
+	writeValue(tw, "Status", phase, nil)
+	writeValue(tw, "Sidecars", status.Synced()+" synced", nil)
+
+	lastError := status.LastError
+	if lastError != "" && status.LastErrorTime != "" {
+		lastError += " (at " + status.LastErrorTime + ")"
+	}
+	writeValue(tw, "Last Error", lastError, nil)
+
+	rows := [][]string{}
+	for _, c := range status.Conditions {
+		rows = append(rows, []string{c.Type, c.Status, orDash(c.Reason), orDash(c.Message), orDash(c.LastTransitionTime)})
+	}
+	writeSection(tw, "Conditions", []string{"Type", "Status", "Reason", "Message", "Last Transition"}, rows, nil)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// writeSection writes a titled table, not found errors mean there is none.
+func writeSection(tw io.Writer, title string, header []string, rows [][]string, err error) {
+	switch {
+	case meshclient.IsUnsupportedError(err):
+		fmt.Fprintf(tw, "%s:\t<unsupported by the control plane>\n", title)
+	case err != nil && !meshclient.IsNotFoundError(err):
+		fmt.Fprintf(tw, "%s:\t<unknown: %v>\n", title, err)
+		return
+	case len(rows) == 0:
+		fmt.Fprintf(tw, "%s:\t%s\n", title, none)
+		return
+	}
+
+	fmt.Fprintf(tw, "%s:\n", title)
+	fmt.Fprintf(tw, "  %s\n", strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintf(tw, "  %s\n", strings.Join(row, "\t"))
+	}
+}
+
+func writeValue(tw io.Writer, title, value string, err error) {
+	switch {
+	case meshclient.IsUnsupportedError(err):
+		fmt.Fprintf(tw, "%s:\t<unsupported by the control plane>\n", title)
+	case err != nil && !meshclient.IsNotFoundError(err):
+		fmt.Fprintf(tw, "%s:\t<unknown: %v>\n", title, err)
+	case err != nil || value == "":
+		fmt.Fprintf(tw, "%s:\t%s\n", title, none)
+	default:
+		fmt.Fprintf(tw, "%s:\t%s\n", title, value)
+	}
+}
+
+// specOf returns the spec of the object in YAML, which is the same as the
+// output of emctl get -o yaml.
+func specOf(object meta.MeshObject) (string, error) {
+	buff, err := yaml.Marshal(object)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal %s/%s", object.Kind(), object.Name())
+	}
+
+	fields := yaml.MapSlice{}
+	err = yaml.Unmarshal(buff, &fields)
+	if err != nil {
+		return "", errors.Wrapf(err, "unmarshal %s/%s", object.Kind(), object.Name())
+	}
+
+	for _, field := range fields {
+		if field.Key != "spec" || field.Value == nil {
+			continue
+		}
+		buff, err = yaml.Marshal(field.Value)
+		if err != nil {
+			return "", errors.Wrapf(err, "marshal spec of %s/%s", object.Kind(), object.Name())
+		}
+		return string(buff), nil
+	}
+	return "", nil
+}
+
+func labelsOf(labels map[string]string) string {
+	if len(labels) == 0 {
+		return none
+	}
+	result := []string{}
+	for k, v := range labels {
+		result = append(result, k+"="+v)
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
+func resiliencePolicies(r *resource.Resilience) string {
+	if r == nil || r.Spec == nil {
+		return ""
+	}
+	policies := []string{}
+	if r.Spec.CircuitBreaker != nil {
+		policies = append(policies, "circuitBreaker")
+	}
+	if r.Spec.RateLimiter != nil {
+		policies = append(policies, "rateLimiter")
+	}
+	if r.Spec.Retryer != nil {
+		policies = append(policies, "retryer")
+	}
+	if r.Spec.TimeLimiter != nil {
+		policies = append(policies, "timeLimiter")
+	}
+	if r.Spec.Hedging != nil {
+		policies = append(policies, "hedging")
+	}
+	return strings.Join(policies, ", ")
+}
+
+func maintenanceOf(m *resource.MaintenanceMode) string {
+	if m == nil || m.Spec == nil {
+		return ""
+	}
+	result := "responding " + strconv.Itoa(m.Spec.StatusCodeOrDefault())
+	if m.Spec.Reason != "" {
+		result += ", " + m.Spec.Reason
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
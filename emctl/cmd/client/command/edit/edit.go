@@ -0,0 +1,336 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package edit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// maxRetries is the max times to apply an edit again on conflicts.
+const maxRetries = 5
+
+// Run is the entrypoint of the emctl edit sub command
+func Run(cmd *cobra.Command, flag *flags.Edit) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	args := cmd.Flags().Args()
+	if len(args) != 2 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <resource kind> <resource name>")
+		return
+	}
+
+	client := meshclient.New(flag.Server)
+	kind, err := util.ResolveCommandKind(client, args[0], flag.Timeout)
+	if err != nil {
+		common.ExitWithError(err)
+		return
+	}
+
+	current, err := getObject(client, kind, args[1], flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("get %s/%s failed: %w", kind, args[1], err)
+		return
+	}
+
+	original, err := yaml.Marshal(current)
+	if err != nil {
+		common.ExitWithErrorf("marshal %s/%s failed: %w", kind, args[1], err)
+		return
+	}
+
+	updated, err := editUntilValid(client, current, original, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("edit %s/%s failed: %w", kind, args[1], err)
+		return
+	}
+	if updated == nil {
+		common.Infof("edit cancelled, no changes made")
+		return
+	}
+
+	err = Update(client, current, updated, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("edit %s/%s failed: %w", kind, args[1], err)
+		return
+	}
+	common.Logger().With("kind", kind, "name", args[1]).
+		Infof("%s/%s edited successfully", kind, args[1])
+}
+
+// editUntilValid opens the original content in the editor, and reopens it
+// with errors in the header until the edited object is valid. It returns nil
+// if the edit is cancelled, by saving an unchanged or empty file, or saving
+// the invalid file without fixing it.
+func editUntilValid(client meshclient.MeshClient, current meta.MeshObject, original []byte, timeout time.Duration) (meta.MeshObject, error) {
+	var previous []byte
+	var invalid error
+	for {
+		content := original
+		if previous != nil {
+			content = previous
+		}
+		edited, err := util.Edit(append(editHeader(current, invalid), content...), "emctl-edit-*.yaml")
+		if err != nil {
+			return nil, err
+		}
+
+		edited = stripHeader(edited)
+		if len(bytes.TrimSpace(edited)) == 0 || bytes.Equal(edited, original) || bytes.Equal(edited, previous) {
+			return nil, nil
+		}
+
+		updated, err := Validate(client, current, edited, timeout)
+		if err == nil {
+			return updated, nil
+		}
+		if common.ExitCode(err) != common.ExitCodeValidation {
+			return nil, err
+		}
+
+		common.Logger().With("kind", current.Kind(), "name", current.Name()).
+			Warnf("%s/%s is invalid, reopen it: %v", current.Kind(), current.Name(), err)
+		previous, invalid = edited, err
+	}
+}
+
+// editHeader returns the comment header of the file to edit, with the
+// error of validating the previous edit if any.
+func editHeader(current meta.MeshObject, err error) []byte {
+	header := "# Please edit the object below. Lines beginning with a '#' will be ignored,\n" +
+		"# and an empty file will abort the edit. If an error occurs while saving this file will be\n" +
+		"# reopened with the relevant failures.\n#\n"
+	if err != nil {
+		header += fmt.Sprintf("# %s/%s is invalid:\n", current.Kind(), current.Name())
+		for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+			header += "# " + line + "\n"
+		}
+		header += "#\n"
+	}
+	return []byte(header)
+}
+
+// stripHeader removes leading comment lines of the edited content.
+func stripHeader(content []byte) []byte {
+	for len(content) != 0 && content[0] == '#' {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			return nil
+		}
+		content = content[i+1:]
+	}
+	return content
+}
+
+// Validate decodes the edited content, and validates it as it's validated
+// before applied. The kind and name of the original object can't be changed.
+func Validate(client meshclient.MeshClient, original meta.MeshObject, edited []byte, timeout time.Duration) (meta.MeshObject, error) {
+	updated, err := decode(edited)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Kind() != original.Kind() || updated.Name() != original.Name() {
+		return nil, common.CodeErrorf(common.ExitCodeValidation, "kind and name of %s/%s can't be changed to %s/%s",
+			original.Kind(), original.Name(), updated.Kind(), updated.Name())
+	}
+
+	err = apply.Validate(client, updated, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Update applies the updated object on the condition of the resource version
+// of the original one. If the resource has been modified meanwhile, changes
+// of the edit are applied to the latest resource again, unless the same
+// fields have been modified.
+func Update(client meshclient.MeshClient, original, updated meta.MeshObject, timeout time.Duration) error {
+	// NOTE: The resource version got is the precondition of the edit,
+	// no matter it's changed or removed in the editor.
+	updated.(meta.MetaDataSetter).SetResourceVersion(original.ResourceVersion())
+
+	for retries := 0; ; retries++ {
+		err := apply.WrapApplierByMeshObject(updated, client, timeout).Apply()
+		if !meshclient.IsStaleError(err) {
+			return err
+		}
+		if retries == maxRetries {
+			return errors.Wrapf(err, "give up after %d retries", maxRetries)
+		}
+
+		latest, err := getObject(client, original.Kind(), original.Name(), timeout)
+		if err != nil {
+			return errors.Wrap(err, "get the latest one")
+		}
+		updated, err = rebase(original, updated, latest)
+		if err != nil {
+			return err
+		}
+		original = latest
+
+		common.Logger().With("kind", original.Kind(), "name", original.Name()).
+			Infof("%s/%s has been modified, retry with resource version %s",
+				original.Kind(), original.Name(), original.ResourceVersion())
+	}
+}
+
+// rebase applies changes from the original object to the updated one
+// to the latest object, it fails if the latest one changed the same fields.
+func rebase(original, updated, latest meta.MeshObject) (meta.MeshObject, error) {
+	originalObject, err := toJSONObject(original)
+	if err != nil {
+		return nil, err
+	}
+	updatedObject, err := toJSONObject(updated)
+	if err != nil {
+		return nil, err
+	}
+	latestObject, err := toJSONObject(latest)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := util.CreateMergePatch(originalObject, updatedObject)
+	conflicts := conflictedFields("", patch, util.CreateMergePatch(originalObject, latestObject))
+	if len(conflicts) != 0 {
+		return nil, common.CodeErrorf(common.ExitCodeConflict, "%s/%s has been modified in the edited fields: %s",
+			original.Kind(), original.Name(), strings.Join(conflicts, ", "))
+	}
+
+	buff, err := json.Marshal(util.MergePatch(latestObject, patch))
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal %s/%s", latest.Kind(), latest.Name())
+	}
+	rebased, err := decode(buff)
+	if err != nil {
+		return nil, err
+	}
+	rebased.(meta.MetaDataSetter).SetResourceVersion(latest.ResourceVersion())
+	return rebased, nil
+}
+
+// conflictedFields returns fields changed differently by both patches,
+// in dotted paths sorted.
+func conflictedFields(prefix string, patch, other map[string]interface{}) []string {
+	var fields []string
+	for k, v := range patch {
+		otherValue, exists := other[k]
+		if !exists {
+			continue
+		}
+
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+		object, ok := v.(map[string]interface{})
+		otherObject, otherOK := otherValue.(map[string]interface{})
+		switch {
+		case ok && otherOK:
+			fields = append(fields, conflictedFields(field, object, otherObject)...)
+		case !reflect.DeepEqual(v, otherValue):
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+// toJSONObject converts the object to the JSON form without its resource version.
+func toJSONObject(object meta.MeshObject) (map[string]interface{}, error) {
+	yamlBuff, err := yaml.Marshal(object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal %s/%s", object.Kind(), object.Name())
+	}
+
+	jsonBuff, err := yamljsontool.YAMLToJSON(yamlBuff)
+	if err != nil {
+		return nil, errors.Wrapf(err, "transform %s/%s to json", object.Kind(), object.Name())
+	}
+
+	result := map[string]interface{}{}
+	err = json.Unmarshal(jsonBuff, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s/%s", object.Kind(), object.Name())
+	}
+
+	if metadata, ok := result["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "resourceVersion")
+	}
+	return result, nil
+}
+
+// decode decodes one object in YAML or JSON, with the validation of its schema.
+func decode(buff []byte) (meta.MeshObject, error) {
+	var objects []meta.MeshObject
+	err := util.NewReaderVisitor(bytes.NewReader(buff), "edited object").Visit(func(object meta.MeshObject, err error) error {
+		if err != nil {
+			return err
+		}
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		return nil, common.WithCode(err, common.ExitCodeValidation)
+	}
+
+	if len(objects) != 1 {
+		return nil, common.CodeErrorf(common.ExitCodeValidation, "expected 1 object, got %d objects", len(objects))
+	}
+	return objects[0], nil
+}
+
+func getObject(client meshclient.MeshClient, kind, name string, timeout time.Duration) (meta.MeshObject, error) {
+	object, err := resource.NewObjectCreator().NewFromResource(
+		resource.NewMeshResource(resource.DefaultAPIVersion, kind, name))
+	if err != nil {
+		return nil, common.WithCode(err, common.ExitCodeValidation)
+	}
+
+	objects, err := get.WrapGetterByMeshObject(object, client, timeout).Get()
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, errors.Wrapf(meshclient.NotFoundError, "get %s/%s", kind, name)
+	}
+	return objects[0], nil
+}
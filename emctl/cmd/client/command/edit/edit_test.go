@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package edit
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+)
+
+func newTenant(version string, services []string, description string) *resource.Tenant {
+	tenant := &resource.Tenant{
+		MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "tenant-001"),
+		Spec:         &resource.TenantSpec{Services: services, Description: description},
+	}
+	tenant.SetResourceVersion(version)
+	return tenant
+}
+
+func TestRebase(t *testing.T) {
+	original := newTenant("1", []string{"service-001"}, "tenant")
+	updated := newTenant("1", []string{"service-001", "service-002"}, "tenant")
+
+	latest := newTenant("2", []string{"service-001"}, "tenant of vets")
+	rebased, err := rebase(original, updated, latest)
+	if err != nil {
+		t.Fatalf("rebase failed: %v", err)
+	}
+	tenant := rebased.(*resource.Tenant)
+	if !reflect.DeepEqual(tenant.Spec, newTenant("", []string{"service-001", "service-002"}, "tenant of vets").Spec) {
+		t.Fatalf("unexpected rebased spec: %+v", tenant.Spec)
+	}
+	if tenant.ResourceVersion() != "2" {
+		t.Fatalf("expected resource version 2, got %s", tenant.ResourceVersion())
+	}
+
+	latest = newTenant("3", []string{"service-003"}, "tenant")
+	_, err = rebase(original, updated, latest)
+	if err == nil {
+		t.Fatalf("expected conflicts of spec.services")
+	}
+	if code := common.ExitCode(err); code != common.ExitCodeConflict {
+		t.Fatalf("expected exit code %d, got %d", common.ExitCodeConflict, code)
+	}
+}
+
+func TestConflictedFields(t *testing.T) {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"a": 1.0, "b": 2.0, "c": map[string]interface{}{"d": 3.0}},
+	}
+	other := map[string]interface{}{
+		"spec":     map[string]interface{}{"a": 1.0, "b": 3.0, "c": "d"},
+		"metadata": map[string]interface{}{"labels": nil},
+	}
+
+	fields := conflictedFields("", patch, other)
+	if !reflect.DeepEqual(fields, []string{"spec.b", "spec.c"}) {
+		t.Fatalf("unexpected conflicted fields: %v", fields)
+	}
+}
+
+func TestEditUntilValid(t *testing.T) {
+	defer func(runEditor func(string) error) { util.RunEditor = runEditor }(util.RunEditor)
+
+	original := []byte(`apiVersion: mesh.megaease.com/v1alpha1
+kind: Tenant
+metadata:
+  name: tenant-001
+spec:
+  services: []
+  description: tenant
+`)
+	edits := []string{
+		strings.Replace(string(original), "name: tenant-001", "name: tenant-002", 1),
+		strings.Replace(string(original), "description: tenant", "description: tenant of vets", 1),
+	}
+	var opened []string
+	util.RunEditor = func(path string) error {
+		buff, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		opened = append(opened, string(buff))
+		return ioutil.WriteFile(path, []byte(edits[len(opened)-1]), 0o600)
+	}
+
+	updated, err := editUntilValid(nil, newTenant("1", nil, "tenant"), original, time.Second)
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if len(opened) != 2 {
+		t.Fatalf("expected to be opened 2 times, got %d", len(opened))
+	}
+	if !strings.Contains(opened[1], "# Tenant/tenant-001 is invalid:") || !strings.HasSuffix(opened[1], edits[0]) {
+		t.Fatalf("expected to be reopened with errors and the invalid edit, got:\n%s", opened[1])
+	}
+	if description := updated.(*resource.Tenant).Spec.Description; description != "tenant of vets" {
+		t.Fatalf("expected description tenant of vets, got %s", description)
+	}
+
+	opened, edits = nil, []string{edits[0], edits[0]}
+	updated, err = editUntilValid(nil, newTenant("1", nil, "tenant"), original, time.Second)
+	if err != nil || updated != nil {
+		t.Fatalf("expected the edit cancelled, got %v, %v", updated, err)
+	}
+}
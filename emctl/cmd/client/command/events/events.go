@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// reconnectInterval is the interval of reconnecting the broken event stream.
+	reconnectInterval = 3 * time.Second
+
+	// eventRowFormat has fixed widths, since rows are printed before the widths
+	// of later ones are known.
+	eventRowFormat = "%-26s %-8s %-14s %-40s %s\n"
+)
+
+// Run is the entrypoint of the emctl events sub command
+func Run(cmd *cobra.Command, flag *flags.Events) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	err := validateTypes(flag.Types)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	options := watchOptions(flag, time.Now())
+	printer := &eventPrinter{out: os.Stdout, outputFormat: flag.OutputFormat}
+	events := meshclient.New(flag.Server).V1Alpha1().Event()
+
+	if !flag.Watch {
+		ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+		defer cancelFunc()
+		err := events.Watch(ctx, options, printer.print)
+		if err != nil {
+			common.ExitWithErrorf("watch events failed: %w", err)
+		}
+		if printer.count == 0 {
+			fmt.Println("No events")
+		}
+		return
+	}
+
+	ctx, cancelFunc := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancelFunc()
+	watch(ctx, events, options, printer.print)
+}
+
+func validateTypes(types []string) error {
+	for _, t := range types {
+		valid := false
+		for _, eventType := range resource.EventTypes {
+			if t == eventType {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.Errorf("unsupported event type %s (support %s)", t, strings.Join(resource.EventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+func watchOptions(flag *flags.Events, now time.Time) *meshclient.EventWatchOptions {
+	options := &meshclient.EventWatchOptions{
+		Types:  flag.Types,
+		Follow: flag.Watch,
+	}
+
+	if flag.Since > 0 {
+		options.Since = now.Add(-flag.Since)
+	}
+
+	return options
+}
+
+// watch follows the event stream until the context is done, the stream is
+// resumed from the last event once it's broken, e.g. the control plane restarts.
+func watch(ctx context.Context, events meshclient.EventInterface, options *meshclient.EventWatchOptions, handler meshclient.EventHandler) {
+	r := &resumer{handler: handler, since: options.Since}
+	for {
+		err := events.Watch(ctx, options, r.handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("closed by the control plane")
+		}
+		common.Warnf("event stream broken, reconnecting: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectInterval):
+		}
+		options.Since = r.since
+	}
+}
+
+// resumer remembers where the stream is, since is precise to seconds in the
+// query, so events in the last second are remembered to be skipped once
+// they're sent again after the stream is resumed.
+type resumer struct {
+	handler meshclient.EventHandler
+	since   time.Time
+	seen    map[string]struct{}
+}
+
+func (r *resumer) handle(event *resource.MeshEvent) error {
+	if _, seen := r.seen[event.Spec.ID]; seen {
+		return nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, event.Spec.Timestamp)
+	if err == nil {
+		second := timestamp.Truncate(time.Second)
+		if r.seen == nil || second.After(r.since) {
+			r.since = second
+			r.seen = map[string]struct{}{}
+		}
+		if second.Equal(r.since) {
+			r.seen[event.Spec.ID] = struct{}{}
+		}
+	}
+
+	return r.handler(event)
+}
+
+// eventPrinter prints events once they arrive, in table rows, YAML documents
+// or JSON lines, instead of collecting them like printing other resources.
+type eventPrinter struct {
+	out          io.Writer
+	outputFormat string
+	count        int
+}
+
+func (p *eventPrinter) print(event *resource.MeshEvent) error {
+	defer func() { p.count++ }()
+
+	switch p.outputFormat {
+	case "json":
+		buff, err := json.Marshal(event.Spec)
+		if err != nil {
+			return errors.Wrap(err, "marshal event to json")
+		}
+		fmt.Fprintf(p.out, "%s\n", buff)
+	case "yaml":
+		buff, err := yaml.Marshal(event.Spec)
+		if err != nil {
+			return errors.Wrap(err, "marshal event to yaml")
+		}
+		fmt.Fprintf(p.out, "---\n%s", buff)
+	default:
+		if p.count == 0 {
+			fmt.Fprintf(p.out, eventRowFormat, "TIMESTAMP", "TYPE", "REASON", "OBJECT", "MESSAGE")
+		}
+		fmt.Fprintf(p.out, eventRowFormat, event.Spec.Timestamp, event.Spec.Type, event.Spec.Reason,
+			event.Spec.Kind+"/"+event.Spec.Name, event.Spec.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func newEvent(id, timestamp string) *resource.MeshEvent {
+	return resource.ToMeshEvent(&resource.MeshEventObject{
+		ID:        id,
+		Timestamp: timestamp,
+		Type:      resource.EventTypeCanary,
+		Reason:    "Activated",
+		Kind:      resource.KindServiceCanary,
+		Name:      "canary-001",
+	})
+}
+
+func TestResumer(t *testing.T) {
+	var ids []string
+	r := &resumer{handler: func(event *resource.MeshEvent) error {
+		ids = append(ids, event.Spec.ID)
+		return nil
+	}}
+
+	r.handle(newEvent("1", "2021-11-01T10:00:00.1Z"))
+	r.handle(newEvent("2", "2021-11-01T10:00:00.5Z"))
+	if !r.since.Equal(time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("since should be the second of the last event, but got %s", r.since)
+	}
+
+	// NOTE: Events in the last second are sent again after the stream is resumed.
+	r.handle(newEvent("1", "2021-11-01T10:00:00.1Z"))
+	r.handle(newEvent("2", "2021-11-01T10:00:00.5Z"))
+	r.handle(newEvent("3", "2021-11-01T10:00:01Z"))
+
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Fatalf("events should be handled once, but got %v", ids)
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	err := validateTypes([]string{resource.EventTypeCanary, resource.EventTypeSidecar})
+	if err != nil {
+		t.Fatalf("canary and sidecar should be valid, but got %v", err)
+	}
+
+	err = validateTypes([]string{"canaries"})
+	if err == nil {
+		t.Fatalf("canaries should be invalid")
+	}
+}
+
+func TestWatchOptions(t *testing.T) {
+	now := time.Now()
+	options := watchOptions(&flags.Events{Since: time.Hour, Types: []string{"canary"}, Watch: true}, now)
+	if !options.Since.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("since should be an hour ago, but got %s", options.Since)
+	}
+	if !options.Follow {
+		t.Fatalf("watch should follow the stream")
+	}
+}
+
+func TestEventPrinter(t *testing.T) {
+	buff := &bytes.Buffer{}
+	p := &eventPrinter{out: buff, outputFormat: "json"}
+	p.print(newEvent("1", "2021-11-01T10:00:00Z"))
+	p.print(newEvent("2", "2021-11-01T10:00:01Z"))
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"id":"2"`) {
+		t.Fatalf("events should be printed in JSON lines, but got %q", buff.String())
+	}
+
+	buff.Reset()
+	p = &eventPrinter{out: buff, outputFormat: "table"}
+	p.print(newEvent("1", "2021-11-01T10:00:00Z"))
+	p.print(newEvent("2", "2021-11-01T10:00:01Z"))
+	if strings.Count(buff.String(), "TIMESTAMP") != 1 || !strings.Contains(buff.String(), "ServiceCanary/canary-001") {
+		t.Fatalf("the header should be printed once, but got %q", buff.String())
+	}
+}
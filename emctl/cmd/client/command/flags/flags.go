@@ -18,6 +18,10 @@
 package flags
 
 import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
 	"time"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
@@ -48,6 +52,9 @@ const (
 	// DefaultMeshAdminPort is the default administrator port of control plane service
 	DefaultMeshAdminPort = 2381
 
+	// DefaultSidecarAdminPort is the default administrator port of sidecars, it's the default one of Easegress
+	DefaultSidecarAdminPort = 2381
+
 	// DefaultMeshControlPlaneHeadfulServiceName is the default headful service name of the EaseMesh control plane
 	DefaultMeshControlPlaneHeadfulServiceName = "easemesh-control-plane-service"
 
@@ -66,12 +73,71 @@ const (
 	// DefaultHeartbeatInterval is default heartbeat
 	DefaultHeartbeatInterval = 5
 
+	// DefaultInstanceExpiry is default seconds without heartbeats after which a service instance is out of service
+	DefaultInstanceExpiry = 15
+
+	// DefaultDeregistrationGracePeriod is default seconds an expired service instance is kept before deregistered
+	DefaultDeregistrationGracePeriod = 60
+
+	// DefaultZookeeperPathPrefix is default path under which Dubbo services register in ZooKeeper
+	DefaultZookeeperPathPrefix = "/dubbo"
+	// DefaultVaultAuthPath is default mount path of the Kubernetes auth method of Vault
+	DefaultVaultAuthPath = "kubernetes"
+
+	// DefaultIngressACMEDirectoryURL is default ACME server of ingress controllers, the production one of Let's Encrypt
+	DefaultIngressACMEDirectoryURL = "https://acme-v02.api.letsencrypt.org/directory"
+
 	// MeshControllerKind is kind of the EaseMesh controller in the Easegress
 	MeshControllerKind = "MeshController"
 
 	// DefaultMeshIngressServicePort is default port listened by the Easegress acted as an ingress role
 	DefaultMeshIngressServicePort = 19527
 
+	// DefaultMeshEgressReplicas is default number of the mesh egress gateway's replicas
+	DefaultMeshEgressReplicas = 1
+
+	// DefaultMeshEgressServicePort is default port listened by the Easegress acted as an egress gateway role
+	DefaultMeshEgressServicePort = 19528
+
+	// DefaultGitOpsBranch is default branch of the Git repository watched by the GitOps controller
+	DefaultGitOpsBranch = "main"
+
+	// DefaultGitOpsInterval is default interval of the GitOps controller polling the Git repository
+	DefaultGitOpsInterval = time.Minute
+
+	// DefaultGitOpsWebhookPort is default port listened by the GitOps controller for webhooks and status
+	DefaultGitOpsWebhookPort = 19529
+
+	// DefaultRevisionHistoryLimit is the default number of revisions kept for every mesh resource
+	DefaultRevisionHistoryLimit = 10
+
+	// DefaultMeshControllerName is default name of the mesh controller created by the installation
+	DefaultMeshControllerName = "easemesh-controller"
+
+	// DefaultVerifyInstallNamespace is default namespace of the sample apps deployed by verify-install
+	DefaultVerifyInstallNamespace = "emctl-verify"
+
+	// DefaultVerifyInstallImage is default image of the sample apps deployed by verify-install
+	DefaultVerifyInstallImage = "ealen/echo-server:0.7.0"
+
+	// DefaultDemoNamespace is default namespace of the apps deployed by demo install
+	DefaultDemoNamespace = "easemesh-demo"
+
+	// DefaultDemoImageTag is default tag of images of the apps deployed by demo install
+	DefaultDemoImageTag = "latest"
+
+	// DefaultCacheTTL is the default duration of trusting cached discovery data of the control plane
+	DefaultCacheTTL = 10 * time.Minute
+
+	// DefaultApplyConcurrency is the default number of resources applied concurrently (apply command)
+	DefaultApplyConcurrency = 8
+
+	// DefaultStageTimeout is the default timeout of every stage of the installation
+	DefaultStageTimeout = 10 * time.Minute
+
+	// DefaultRequestTimeout is the default timeout of every request to Kubernetes in the installation
+	DefaultRequestTimeout = 30 * time.Second
+
 	// DefaultWaitControlPlaneSeconds is the default wait control plane ready elapse, in seconds (intall command)
 	DefaultWaitControlPlaneSeconds = 3
 
@@ -95,7 +161,8 @@ const (
 	`
 	// MeshControlPlanePVNotExistedHelpStr is a text described the persistent volume that doesn't exist
 	MeshControlPlanePVNotExistedHelpStr = `EaseMesh control plane needs PersistentVolume to store data.
-You need to create PersistentVolume in advance and specify its storageClassName as the value of --mesh-storage-class-name.
+You need to create PersistentVolume in advance and specify its storageClassName as the value of --storage-class,
+or use --storage-class="" for the default StorageClass of the cluster, or --ephemeral-storage for throwaway dev installs.
 
 You can create PersistentVolume by the following definition:
 
@@ -121,11 +188,100 @@ spec:
 	DefaultEaseMeshOperatorImage = "megaease/easemesh-operator:latest"
 	// DefaultShadowServiceControllerImage is default name of the shadow service docker image
 	DefaultShadowServiceControllerImage = "megaease/easemesh-shadowservice-controller:latest"
+	// DefaultInterceptionImage is default name of the docker image setting up the traffic interception
+	DefaultInterceptionImage = "registry.k8s.io/build-image/debian-iptables:buster-v1.6.7"
+	// DefaultGitOpsControllerImage is default name of the GitOps controller docker image
+	DefaultGitOpsControllerImage = "megaease/emctl:latest"
+	// DefaultVMSidecarImage is default name of the sidecar docker image running on VMs
+	DefaultVMSidecarImage = "megaease/easegress:easemesh"
+	// DefaultVMAgentInitializerImage is default name of the docker image providing EaseAgent on VMs
+	DefaultVMAgentInitializerImage = "megaease/easeagent-initializer:latest"
+	// VMFormatCompose generates docker-compose files for VM workloads
+	VMFormatCompose = "compose"
+	// VMFormatSystemd generates systemd units for VM workloads
+	VMFormatSystemd = "systemd"
+	// DefaultMaintenanceImage is default name of the docker image running the control plane maintenance
+	DefaultMaintenanceImage = "megaease/emctl:latest"
+	// DefaultMaintenanceSchedule is default cron schedule of the control plane maintenance, weekly at 03:00 on Sunday
+	DefaultMaintenanceSchedule = "0 3 * * 0"
+	// DefaultMaintenanceRetainRevisions is default number of revisions kept by compacting the control plane storage
+	DefaultMaintenanceRetainRevisions = 10000
 	// DefaultImageRegistryURL is default registry url
 	DefaultImageRegistryURL = "docker.io"
 )
 
+// DefaultPluginPlatforms are default platforms the kubectl plugin is released for
+var DefaultPluginPlatforms = []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64"}
+
 type (
+	// Logging holds the logging options for all the emctl commands
+	Logging struct {
+		Level     string
+		Format    string
+		Verbosity int
+	}
+
+	// Cache holds the options of caching discovery data of the control plane for all the emctl commands
+	Cache struct {
+		// Dir is the directory of cached custom resource kinds with their schemas, empty disables the cache
+		Dir string
+		// TTL is the duration cached data is trusted, it's discarded earlier once the control plane restarts
+		TTL time.Duration
+	}
+
+	// Kubernetes holds the options of accessing Kubernetes for all the emctl commands,
+	// which are the same as the ones of kubectl.
+	Kubernetes struct {
+		Kubeconfig string
+		Context    string
+	}
+
+	// PluginKrewManifest holds the option for the emctl plugin krew-manifest sub command
+	PluginKrewManifest struct {
+		Version    string
+		BaseURL    string
+		ArchiveDir string
+		Platforms  []string
+	}
+
+	// Version holds the option for the emctl version command
+	Version struct {
+		*AdminGlobal
+		*OperationGlobal
+
+		// Client only shows the version of emctl without reaching servers.
+		Client       bool
+		OutputFormat string
+	}
+
+	// Wait holds the option for the emctl wait command, Timeout bounds the whole wait.
+	Wait struct {
+		*AdminGlobal
+
+		// For is condition=<type>[=<status>] or delete.
+		For      string
+		Interval time.Duration
+	}
+
+	// Lint holds the option for the emctl lint command
+	Lint struct {
+		*AdminFileInput
+
+		// Rules override severities of rules in the form of <rule>=<severity>.
+		Rules        []string
+		OutputFormat string
+	}
+
+	// VersionCheck holds the global option of checking version skews
+	VersionCheck struct {
+		Skip bool
+	}
+
+	// TelemetryOn holds the option for the emctl telemetry on sub command
+	TelemetryOn struct {
+		Endpoint string
+	}
+
 	// OperationGlobal is global option for emctl
 	OperationGlobal struct {
 		MeshNamespace string
@@ -138,7 +294,16 @@ type (
 
 		ImageRegistryURL string
 
-		CleanWhenFailed bool
+		// RollbackOnFailure deletes the objects created by a failed installation
+		RollbackOnFailure bool
+		// CleanupFailed deletes the objects left by the last failed installation
+		CleanupFailed bool
+		// ProgressFormat is text or json, json reports transitions of stages as NDJSON events
+		ProgressFormat string
+		// StageTimeout bounds every stage, the stage fails once it's exceeded
+		StageTimeout time.Duration
+		// RequestTimeout bounds every request to Kubernetes, e.g. hanging on a broken webhook
+		RequestTimeout time.Duration
 
 		// Easegress Control Plane params
 		EasegressImage                string
@@ -147,25 +312,158 @@ type (
 		EgAdminPort                   int
 		EgPeerPort                    int
 
+		// MeshControlPlaneZones are availability zones the members of the control plane
+		// are spread across one per zone, empty means no zone awareness.
+		MeshControlPlaneZones []string
+
 		EgServicePeerPort  int
 		EgServiceAdminPort int
 
+		// PinDigests resolves tags of the installed images to digests before deploying them
+		PinDigests bool
+		// CosignKey is the public key verifying signatures of the Easegress and operator images by cosign, empty means no verification
+		CosignKey string
+
+		// EasegressConfigTemplate is a config file of Easegress, which the config
+		// computed by the installer is merged into for the control plane.
+		EasegressConfigTemplate string
+
 		MeshControlPlaneStorageClassName      string
 		MeshControlPlanePersistVolumeName     string
 		MeshControlPlanePersistVolumeHostPath string
 		MeshControlPlanePersistVolumeCapacity string
 		MeshControlPlaneCheckHealthzMaxTime   int
+		// MeshControlPlaneEphemeralStorage stores data of the control plane
+		// in emptyDir volumes, which is lost once pods restart.
+		MeshControlPlaneEphemeralStorage bool
+		// MeshControlPlaneAdminNodePort and MeshControlPlaneClientNodePort
+		// are NodePorts of the public control plane service, 0 means allocated by Kubernetes.
+		MeshControlPlaneAdminNodePort  int32
+		MeshControlPlaneClientNodePort int32
 
 		MeshIngressReplicas    int
 		MeshIngressServicePort int32
+		// MeshIngressNodePort is the NodePort of the mesh ingress service, 0 means allocated by Kubernetes.
+		MeshIngressNodePort int32
+		// MeshIngressClasses are extra ingress controller instances, each serves
+		// mesh ingresses annotated with its ingress class only.
+		MeshIngressClasses []string
+		// MeshIngressL4Ports are TCP/UDP ports exposed by ingress controllers, forwarded by IngressPort resources.
+		MeshIngressL4Ports []string
+		// IngressACMEEmail is the account email of the ACME client of ingress controllers, empty disables ACME.
+		IngressACMEEmail string
+		// IngressACMEDirectoryURL is the directory of the ACME server.
+		IngressACMEDirectoryURL string
+		// IngressACMEDNSProvider is the DNS provider solving dns-01 challenges, empty means only http-01.
+		IngressACMEDNSProvider string
+		// IngressACMEDNSProviderSecret is the Secret in the mesh namespace holding options of the DNS provider.
+		IngressACMEDNSProviderSecret string
+
+		// KindPreset applies defaults tuned for local development on kind or minikube
+		KindPreset bool
+		// Interactive walks users through the installation by prompts
+		Interactive bool
+		// LowResourceRequests lowers resource requests of the control plane and the operator
+		LowResourceRequests bool
+		// SecurityProfile hardens pods of the control plane, the operator, the ingress and the egress gateway,
+		// empty means the defaults of the cluster
+		SecurityProfile string
+		// EnableNetworkPolicies restricts traffic to the control plane and the operator with NetworkPolicies
+		EnableNetworkPolicies bool
+
+		MeshEgressReplicas    int
+		MeshEgressServicePort int32
+
+		// EnableGatewayAPI makes the operator translate Gateway API resources into mesh ingresses
+		EnableGatewayAPI bool
+		// EnableK8sIngress makes the operator translate Kubernetes Ingresses of the EaseMesh ingress class into mesh ingresses
+		EnableK8sIngress bool
+
+		// SelfHeal makes the operator revert manual edits and deletions of objects applied by the installation
+		SelfHeal bool
+		// EnableAPIAggregation registers mesh resources served by the operator as an aggregated API
+		EnableAPIAggregation bool
+		// ServiceAutoRegistration makes the operator register Deployments labeled with
+		// mesh.megaease.com/enable=true as mesh services under tenants named after their namespaces
+		ServiceAutoRegistration bool
+		// TrafficPolicyAnnotations makes the operator convert timeout, retries and
+		// canary weight annotations of Deployments into mesh resources
+		TrafficPolicyAnnotations bool
+
+		// SidecarDNSCapture makes sidecars serve DNS for mesh services and external services
+		SidecarDNSCapture bool
+		// SidecarDNSUpstream is the nameserver sidecars forward unknown names to
+		SidecarDNSUpstream string
+		// ClusterDomain is the DNS domain of the Kubernetes cluster
+		ClusterDomain string
+
+		// SpringCloudConfigURI is the Spring Cloud Config server which injected applications fetch config from
+		SpringCloudConfigURI string
+		// SidecarInjectionTemplate is the file of patches applied to injected pods,
+		// empty keeps the template in the cluster.
+		SidecarInjectionTemplate string
+		// SidecarDrainDuration is how long applications keep running to finish
+		// in-flight requests on termination, sidecars outlive them, zero disables the drain.
+		SidecarDrainDuration time.Duration
+		// HoldApplicationUntilSidecarReady makes applications start after sidecars are ready
+		HoldApplicationUntilSidecarReady bool
+		// NativeSidecar injects sidecars as native sidecars of Kubernetes 1.28+
+		NativeSidecar bool
+
+		// TrafficInterception intercepts outbound traffic of injected pods, empty leaves it to EaseAgent and DNS
+		TrafficInterception string
+		// InterceptionPorts are destination ports of outbound traffic redirected to sidecars
+		InterceptionPorts []int
+		// InterceptionImage is the image of init containers setting up iptables rules
+		InterceptionImage string
+
+		// OperatorMetricsScrape exposes the operator metrics to Prometheus scraping
+		OperatorMetricsScrape bool
+		// OperatorEnablePprof serves pprof endpoints of the operator on its metrics port
+		OperatorEnablePprof bool
 
 		OnlyAddOn                    bool
 		AddOns                       []string
 		ShadowServiceControllerImage string
 
+		// GitOps controller params (add-on gitops)
+		GitOpsRepo            string
+		GitOpsBranch          string
+		GitOpsPath            string
+		GitOpsInterval        time.Duration
+		GitOpsWebhookSecret   string
+		GitOpsWebhookPort     int32
+		GitOpsControllerImage string
+
+		// Control plane maintenance params (add-on maintenance)
+		MaintenanceSchedule        string
+		MaintenanceImage           string
+		MaintenanceRetainRevisions int64
+
 		// EaseMesh Controller  params
 		EaseMeshRegistryType string
 		HeartbeatInterval    int
+		RevisionHistoryLimit int
+		// InstanceExpiry is the seconds without heartbeats after which
+		// a service instance is marked OUT_OF_SERVICE.
+		InstanceExpiry int
+		// DeregistrationGracePeriod is the seconds an expired service
+		// instance is kept before it's deregistered.
+		DeregistrationGracePeriod int
+		// ZookeeperConnection is the connection string of the ZooKeeper
+		// ensemble which Dubbo services register to, empty means none.
+		ZookeeperConnection string
+		// ZookeeperPathPrefix is the path under which Dubbo services register.
+		ZookeeperPathPrefix string
+		// VaultAddress is the Vault server resolving secret references of mesh resources, empty means none.
+		VaultAddress string
+		// VaultRole is the role of the Kubernetes auth method the control plane logins Vault with.
+		VaultRole string
+		// VaultAuthPath is the mount path of the Kubernetes auth method of Vault.
+		VaultAuthPath string
+		// SecretNamespaces are the namespaces besides the mesh namespace whose Secrets
+		// could be referenced by mesh resources.
+		SecretNamespaces []string
 
 		// EaseMesh Operator params
 		EaseMeshOperatorImage    string
@@ -201,24 +499,505 @@ type (
 	AdminFileInput struct {
 		YamlFile  string
 		Recursive bool
+
+		// Values are files of values rendering ${name} in resource files.
+		Values []string
+		// Set are values in the form of <name>=<value>, overriding Values.
+		Set []string
+		// Env resolves ${name} by environment variables too.
+		Env bool
 	}
 
 	// Apply holds the option for the apply sub command
 	Apply struct {
 		*AdminGlobal
 		*AdminFileInput
+
+		// Selector only applies resources matching the label selector.
+		Selector string
+		// Prune deletes resources applied with the same selector last time
+		// but no longer in the input.
+		Prune bool
+		// Staged is the percentages of sidecars to roll out policies stage
+		// by stage, e.g. 10%,50%,100%, empty means applying at once.
+		Staged       string
+		BakeTime     time.Duration
+		MaxErrorRate float64
+		// Force updates resources even if their resource versions are stale.
+		Force bool
+		// Validate rejects unknown fields of resources, e.g. typos of fields.
+		Validate bool
+		// Concurrency is the number of resources applied concurrently.
+		Concurrency int
+		// ContinueOnError keeps applying the remaining resources after a failure.
+		ContinueOnError bool
+		// AllowMissingRefs applies resources referencing missing ones with
+		// warnings, instead of rejecting them.
+		AllowMissingRefs bool
 	}
 
 	// Delete holds the option for the emctl delete sub command
 	Delete struct {
 		*AdminGlobal
 		*AdminFileInput
+		// Selector deletes resources matching the label selector.
+		Selector string
+		// Cascade deletes resources referencing the deleted ones as well,
+		// or removes the references from them, after the confirmation.
+		Cascade bool
+		// Force leaves references dangling without --cascade, or skips the
+		// confirmation with --cascade.
+		Force bool
 	}
 
 	// Get holds the option for the emctl get sub command
 	Get struct {
 		*AdminGlobal
 		OutputFormat string
+		// Selector only gets resources matching the label selector.
+		Selector string
+	}
+
+	// Describe holds the option for the emctl describe sub command
+	Describe struct {
+		*AdminGlobal
+	}
+
+	// Edit holds the option for the emctl edit sub command
+	Edit struct {
+		*AdminGlobal
+	}
+
+	// Create holds the common option for the emctl create sub commands
+	Create struct {
+		*AdminGlobal
+		DryRun       bool
+		OutputFormat string
+	}
+
+	// CreateService holds the option for the emctl create service sub command
+	CreateService struct {
+		*Create
+		Tenant   string
+		Port     int
+		Protocol string
+	}
+
+	// CreateTenant holds the option for the emctl create tenant sub command
+	CreateTenant struct {
+		*Create
+		Description string
+	}
+
+	// CreateServiceCanary holds the option for the emctl create servicecanary sub command
+	CreateServiceCanary struct {
+		*Create
+		Services []string
+		Priority int
+		Headers  []string
+		Labels   []string
+	}
+
+	// CreateIngress holds the option for the emctl create ingress sub command
+	CreateIngress struct {
+		*Create
+		Service string
+		Host    string
+		Path    string
+	}
+
+	// CreateShadowService holds the option for the emctl create shadowservice sub command
+	CreateShadowService struct {
+		*Create
+		Service   string
+		Namespace string
+	}
+
+	// History holds the option for the emctl history sub command
+	History struct {
+		*AdminGlobal
+		OutputFormat string
+	}
+
+	// Rollback holds the option for the emctl rollback sub command
+	Rollback struct {
+		*AdminGlobal
+		ToRevision int64
+	}
+
+	// AuditList holds the option for the emctl audit list sub command
+	AuditList struct {
+		*AdminGlobal
+		Since        time.Duration
+		Kind         string
+		Name         string
+		User         string
+		OutputFormat string
+	}
+
+	// ProxyStatus holds the option for the emctl proxy-status command
+	ProxyStatus struct {
+		*AdminGlobal
+		// Service only shows sidecars of the service, empty means all services.
+		Service      string
+		OutputFormat string
+	}
+
+	// IngressCertStatus holds the option for the emctl ingress cert status sub command
+	IngressCertStatus struct {
+		*AdminGlobal
+		// Ingress only shows certificates of hosts of the ingress, empty means all ingresses.
+		Ingress      string
+		OutputFormat string
+	}
+
+	// IngressCachePurge holds the option for the emctl ingress cache purge sub command
+	IngressCachePurge struct {
+		*AdminGlobal
+		Host string
+		// Path is an exact path, or a prefix ending with *.
+		Path string
+	}
+
+	// MigrateResources holds the option for the emctl migrate resources sub command
+	MigrateResources struct {
+		*AdminGlobal
+		From   string
+		To     string
+		DryRun bool
+	}
+
+	// Events holds the option for the emctl events sub command
+	Events struct {
+		*AdminGlobal
+		Since        time.Duration
+		Types        []string
+		Watch        bool
+		OutputFormat string
+	}
+
+	// Proxy holds the option for the emctl proxy sub command
+	Proxy struct {
+		*OperationGlobal
+
+		Address string
+		Port    int
+		// HealthInterval is the interval of checking the health of the control plane.
+		HealthInterval time.Duration
+	}
+
+	// Graph holds the option for the emctl graph sub command
+	Graph struct {
+		*AdminGlobal
+		Tenant       string
+		Window       time.Duration
+		OutputFormat string
+	}
+
+	// SLOStatus holds the option for the emctl slo status sub command
+	SLOStatus struct {
+		*AdminGlobal
+		OutputFormat string
+	}
+
+	// AlertCheck holds the option for the emctl alert check sub command
+	AlertCheck struct {
+		*AdminGlobal
+		OutputFormat string
+	}
+
+	// AlertExport holds the option for the emctl alert export sub command
+	AlertExport struct {
+		*AdminGlobal
+
+		Name         string
+		Namespace    string
+		MetricPrefix string
+		RateWindow   time.Duration
+	}
+
+	// AlertPush holds the option for the emctl alert push sub command
+	AlertPush struct {
+		*AdminGlobal
+
+		// Webhook is the receiver of rules without their own webhooks.
+		Webhook string
+		// Interval evaluates rules repeatedly, zero means once.
+		Interval time.Duration
+	}
+
+	// AuthCreateToken holds the option for the emctl auth create-token sub command
+	AuthCreateToken struct {
+		*AdminGlobal
+
+		Tenant       string
+		Role         string
+		TTL          time.Duration
+		Description  string
+		OutputFormat string
+	}
+
+	// AuthRevokeToken holds the option for the emctl auth revoke-token sub command
+	AuthRevokeToken struct {
+		*AdminGlobal
+	}
+
+	// PolicyExportGatekeeper holds the option for the emctl policy export-gatekeeper sub command
+	PolicyExportGatekeeper struct {
+		// TenantKey is the label or annotation declaring tenants of mesh services.
+		TenantKey          string
+		ApprovedRegistries []string
+		ExcludedNamespaces []string
+		EnforcementAction  string
+	}
+
+	// CanaryTestMatch holds the option for the emctl canary test-match sub command
+	CanaryTestMatch struct {
+		*AdminGlobal
+
+		// Expression is evaluated instead of the match of the ServiceCanary.
+		Expression   string
+		Path         string
+		Headers      []string
+		Cookies      []string
+		Query        []string
+		OutputFormat string
+	}
+
+	// GitOpsServe holds the option for the emctl gitops serve sub command
+	GitOpsServe struct {
+		*AdminGlobal
+
+		Repo     string
+		Branch   string
+		Path     string
+		WorkDir  string
+		Interval time.Duration
+
+		// ListenAddress serves webhooks triggering sync and the status.
+		ListenAddress string
+		// WebhookSecret verifies webhooks, empty means no verification.
+		WebhookSecret string
+
+		// SelfHeal applies resources drifted from Git, otherwise drift
+		// is only reported.
+		SelfHeal bool
+		Selector string
+		Prune    bool
+	}
+
+	// GitOpsArgoCDConfig holds the option for the emctl gitops argocd-config sub command
+	GitOpsArgoCDConfig struct {
+		Namespace    string
+		OutputFormat string
+	}
+
+	// TenantPolicySet holds the option for the emctl tenant policy set sub command
+	TenantPolicySet struct {
+		*AdminGlobal
+		YamlFile string
+	}
+
+	// TenantPolicyGet holds the option for the emctl tenant policy get sub command
+	TenantPolicyGet struct {
+		*AdminGlobal
+		OutputFormat string
+	}
+
+	// MeshConfig holds the option for the emctl mesh-config edit sub command
+	MeshConfig struct {
+		*AdminGlobal
+		Name string
+		// DryRun validates and shows the changes without updating.
+		DryRun bool
+	}
+
+	// MeshConfigPatch holds the option for the emctl mesh-config patch sub command
+	MeshConfigPatch struct {
+		*MeshConfig
+		Patch     string
+		PatchFile string
+	}
+
+	// VerifyInstall holds the option for the emctl verify-install sub command
+	VerifyInstall struct {
+		*AdminGlobal
+		*OperationGlobal
+
+		// Namespace is the temporary namespace of the sample apps.
+		Namespace string
+		// Image is the image of the sample apps, which must echo
+		// the request headers as JSON.
+		Image string
+		// IngressAddress is the address of the mesh ingress, it's
+		// discovered from the NodePort of the ingress service if empty.
+		IngressAddress string
+		WaitTimeout    time.Duration
+		// KeepResources skips the teardown for debugging.
+		KeepResources bool
+	}
+
+	// DemoInstall holds the option for the emctl demo install sub command
+	DemoInstall struct {
+		*AdminGlobal
+		*OperationGlobal
+
+		// Namespace is the namespace of the demo apps.
+		Namespace        string
+		ImageRegistryURL string
+		ImageTag         string
+		// WaitTimeout is the max time to wait for the demo apps, 0 means no waiting.
+		WaitTimeout time.Duration
+	}
+
+	// DemoUninstall holds the option for the emctl demo uninstall sub command
+	DemoUninstall struct {
+		*AdminGlobal
+		*OperationGlobal
+
+		Namespace string
+	}
+
+	// CheckUpgradeCompat holds the option for the emctl check upgrade-compat sub command
+	CheckUpgradeCompat struct {
+		*AdminGlobal
+		*OperationGlobal
+
+		// SidecarImage is the sidecar image injected after upgrading, pods
+		// running other images are reported.
+		SidecarImage string
+	}
+
+	// CheckRefs holds the option for the emctl check refs sub command
+	CheckRefs struct {
+		*AdminGlobal
+	}
+
+	// Maintenance holds the option for the emctl maintenance run sub command
+	Maintenance struct {
+		*OperationGlobal
+
+		// Endpoint is the etcd client URL of a member, others are accessed by
+		// their advertised client URLs listed by it, so it's used inside the
+		// cluster. Members are accessed via the Kubernetes API server if empty.
+		Endpoint        string
+		RetainRevisions int64
+		SkipDefrag      bool
+		Timeout         time.Duration
+	}
+
+	// MaintenanceEnable holds the option for the emctl maintenance enable sub command
+	MaintenanceEnable struct {
+		*AdminGlobal
+
+		// Service is the service in maintenance, All means the whole mesh.
+		Service    string
+		All        bool
+		Response   int
+		Body       string
+		Headers    []string
+		RetryAfter int
+		Reason     string
+	}
+
+	// MaintenanceDisable holds the option for the emctl maintenance disable sub command
+	MaintenanceDisable struct {
+		*AdminGlobal
+
+		Service string
+		All     bool
+	}
+
+	// ScaleControlPlane holds the option for the emctl scale control-plane sub command
+	ScaleControlPlane struct {
+		*OperationGlobal
+
+		// Replicas is the target number of members of the control plane.
+		Replicas    int
+		WaitTimeout time.Duration
+	}
+
+	// SidecarVersions holds the option for the emctl sidecar versions sub command
+	SidecarVersions struct {
+		*OperationGlobal
+
+		// Namespace is the namespace of pods, empty means all namespaces.
+		Namespace    string
+		OutputFormat string
+	}
+
+	// SidecarUpgrade holds the option for the emctl sidecar upgrade sub command
+	SidecarUpgrade struct {
+		*OperationGlobal
+
+		Namespace string
+		// MaxUnavailable is the number or percentage of workloads
+		// restarted in a batch, e.g. 2 or 20%.
+		MaxUnavailable string
+		WaitTimeout    time.Duration
+		DryRun         bool
+	}
+
+	// SidecarConfigDump holds the option for the emctl sidecar config-dump sub command
+	SidecarConfigDump struct {
+		Namespace    string
+		AdminPort    int
+		OutputFormat string
+		Timeout      time.Duration
+	}
+
+	// VMGenerate holds the option for the emctl vm generate sub command
+	VMGenerate struct {
+		*OperationGlobal
+
+		ServiceName string
+		// InstanceName is the unique name of the sidecar in the mesh,
+		// it's generated from the service name and the application IP if empty.
+		InstanceName    string
+		ApplicationIP   string
+		ApplicationPort int
+		AliveProbeURL   string
+		Labels          map[string]string
+		// ControlPlanePeerURLs are discovered from the NodePort of
+		// the public control plane service if empty.
+		ControlPlanePeerURLs  []string
+		Format                string
+		OutputDir             string
+		SidecarImage          string
+		AgentInitializerImage string
+	}
+
+	// VMRegister holds the option for the emctl vm register sub command
+	VMRegister struct {
+		*VMGenerate
+
+		// TokenTTL is the lifetime of the bootstrap token of the VM.
+		TokenTTL time.Duration
+		// SidecarBinary is the path of the sidecar binary packed into the tarball.
+		SidecarBinary string
+	}
+
+	// VMList holds the option for the emctl vm list sub command
+	VMList struct {
+		*OperationGlobal
+		*AdminGlobal
+	}
+
+	// VMDeregister holds the option for the emctl vm deregister sub command
+	VMDeregister struct {
+		*OperationGlobal
+		*AdminGlobal
+	}
+
+	// ResizeStorage holds the option for the emctl control-plane resize-storage sub command
+	ResizeStorage struct {
+		*OperationGlobal
+
+		// Capacity is the new capacity of each persistent volume claim of the control plane.
+		Capacity string
+		// DryRun only runs the preflight checks and shows the current usage.
+		DryRun      bool
+		WaitTimeout time.Duration
 	}
 )
 
@@ -237,6 +1016,40 @@ func GetServerAddress() string {
 	return rc.Server
 }
 
+// GetIdentity returns the identity of who runs emctl, which is recorded in
+// the audit log of the control plane. The user of rc file takes precedence
+// over the user of the operating system.
+func GetIdentity() string {
+	rc, err := rcfile.New()
+	if err == nil && rc.Unmarshal() == nil && rc.User != "" {
+		return rc.User
+	}
+
+	identity := "unknown"
+	if u, err := user.Current(); err == nil {
+		identity = u.Username
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		identity += "@" + hostname
+	}
+
+	return identity
+}
+
+// GetToken returns the access token authorizing requests to the control
+// plane. The env EMCTL_TOKEN takes precedence over the token of rc file.
+func GetToken() string {
+	if token := os.Getenv("EMCTL_TOKEN"); token != "" {
+		return token
+	}
+
+	rc, err := rcfile.New()
+	if err == nil && rc.Unmarshal() == nil {
+		return rc.Token
+	}
+	return ""
+}
+
 // AttachCmd attaches options for installation of coredns.
 func (c *CoreDNS) AttachCmd(cmd *cobra.Command) {
 	c.OperationGlobal = &OperationGlobal{}
@@ -259,31 +1072,112 @@ func (i *Install) AttachCmd(cmd *cobra.Command) {
 		DefaultMeshControlPlaneCheckHealthzMaxTime,
 		"Max timeout in second for checking control panel component whether ready or not")
 
-	cmd.Flags().IntVar(&i.EgServicePeerPort, "mesh-control-plane-service-peer-port", DefaultMeshPeerPort, "Port of Easegress cluster peer")
-	cmd.Flags().IntVar(&i.EgServiceAdminPort, "mesh-control-plane-service-admin-port", DefaultMeshAdminPort, "Port of Easegress admin address")
+	cmd.Flags().IntVar(&i.EgServicePeerPort, "mesh-control-plane-service-peer-port", DefaultMeshPeerPort, "Peer port of the control plane service, which sidecars join the cluster by")
+	cmd.Flags().IntVar(&i.EgServiceAdminPort, "mesh-control-plane-service-admin-port", DefaultMeshAdminPort, "Admin port of the control plane service, which the operator and add-ons call the admin API by")
 
+	cmd.Flags().StringVar(&i.MeshControlPlaneStorageClassName, "storage-class", DefaultMeshControlPlaneStorageClassName,
+		"Storage class of the control plane volumes, empty means the default storage class of the cluster, which is also used if the class has no volume available")
 	cmd.Flags().StringVar(&i.MeshControlPlaneStorageClassName, "mesh-storage-class-name", DefaultMeshControlPlaneStorageClassName, "Mesh storage class name")
+	cmd.Flags().MarkDeprecated("mesh-storage-class-name", "use --storage-class instead")
+	cmd.Flags().Int32Var(&i.MeshControlPlaneAdminNodePort, "mesh-control-plane-admin-node-port", 0, "NodePort of the control plane admin port, 0 means allocated by Kubernetes")
+	cmd.Flags().Int32Var(&i.MeshControlPlaneClientNodePort, "mesh-control-plane-client-node-port", 0, "NodePort of the control plane client port, 0 means allocated by Kubernetes")
+	cmd.Flags().BoolVar(&i.MeshControlPlaneEphemeralStorage, "ephemeral-storage", false, "Store data of the control plane in emptyDir volumes, which is lost once pods restart, only for throwaway dev installs")
 	cmd.Flags().StringVar(&i.MeshControlPlanePersistVolumeCapacity, "mesh-control-plane-pv-capacity", DefaultMeshControlPlanePersistVolumeCapacity,
 		MeshControlPlanePVNotExistedHelpStr)
 
 	cmd.Flags().Int32Var(&i.MeshIngressServicePort, "mesh-ingress-service-port", DefaultMeshIngressServicePort, "Port of mesh ingress controller")
+	cmd.Flags().Int32Var(&i.MeshIngressNodePort, "mesh-ingress-node-port", 0, "NodePort of mesh ingress controller, 0 means allocated by Kubernetes")
+	cmd.Flags().StringArrayVar(&i.MeshIngressClasses, "ingress-class", nil,
+		"Extra ingress controller instance in the form name=<class>,tenant=<tenant>,replicas=<n>,port=<port>,node-port=<port>,cpu=<quantity>,memory=<quantity>, "+
+			"serving mesh ingresses annotated with mesh.megaease.com/ingress-class=<class> only, can be repeated")
+	cmd.Flags().StringSliceVar(&i.MeshIngressL4Ports, "mesh-ingress-l4-ports", []string{},
+		"TCP/UDP ports exposed by ingress controllers in the form <port>[/tcp|/udp] like 5432,53/udp, forwarded to mesh services by IngressPort resources")
+	cmd.Flags().StringVar(&i.IngressACMEEmail, "ingress-acme-email", "", "Account email of the ACME client obtaining certificates of ingress hosts, empty disables ACME")
+	cmd.Flags().StringVar(&i.IngressACMEDirectoryURL, "ingress-acme-directory-url", DefaultIngressACMEDirectoryURL, "Directory URL of the ACME server")
+	cmd.Flags().StringVar(&i.IngressACMEDNSProvider, "ingress-acme-dns-provider", "", "DNS provider solving dns-01 challenges like cloudflare or route53, empty means only http-01 challenges")
+	cmd.Flags().StringVar(&i.IngressACMEDNSProviderSecret, "ingress-acme-dns-provider-secret", "", "Secret in the mesh namespace holding options of the DNS provider, required by --ingress-acme-dns-provider")
+	cmd.Flags().BoolVar(&i.EnableGatewayAPI, "enable-gateway-api", false, "Translate Kubernetes Gateway API resources (Gateway/HTTPRoute) into mesh ingresses")
+	cmd.Flags().BoolVar(&i.EnableK8sIngress, "enable-k8s-ingress", false, "Translate Kubernetes Ingresses with ingressClassName easemesh into mesh ingresses")
+	cmd.Flags().BoolVar(&i.SelfHeal, "self-heal", false, "Make the operator revert manual edits and deletions of objects applied by the installation")
+	cmd.Flags().BoolVar(&i.EnableAPIAggregation, "enable-api-aggregation", false,
+		"Register mesh resources served by the operator as an aggregated API, e.g. kubectl get meshservices")
+	cmd.Flags().BoolVar(&i.ServiceAutoRegistration, "service-auto-registration", false,
+		"Make the operator inject and register Deployments labeled with mesh.megaease.com/enable=true as mesh services under tenants named after their namespaces")
+	cmd.Flags().BoolVar(&i.TrafficPolicyAnnotations, "traffic-policy-annotations", false,
+		"Make the operator convert timeout, retries and canary weight annotations of Deployments into mesh resources")
+	cmd.Flags().BoolVar(&i.SidecarDNSCapture, "sidecar-dns-capture", false, "Make sidecars serve DNS for mesh services and external services")
+	cmd.Flags().StringVar(&i.SidecarDNSUpstream, "sidecar-dns-upstream", "", "The nameserver sidecars forward unknown names to, default is the cluster DNS")
+	cmd.Flags().StringVar(&i.ClusterDomain, "cluster-domain", "cluster.local", "The DNS domain of the Kubernetes cluster")
+	cmd.Flags().StringVar(&i.SpringCloudConfigURI, "spring-cloud-config-uri", "", "The Spring Cloud Config server which injected applications fetch config from, empty means no config server")
+	cmd.Flags().StringVar(&i.SidecarInjectionTemplate, "sidecar-injection-template", "", "File of strategic merge and JSON patches applied to pods injected with sidecars, empty keeps the template in the cluster")
+	cmd.Flags().DurationVar(&i.SidecarDrainDuration, "sidecar-drain-duration", 0, "How long applications keep running to finish in-flight requests on termination before they and then sidecars are terminated, zero disables the drain")
+	cmd.Flags().BoolVar(&i.HoldApplicationUntilSidecarReady, "hold-application-until-sidecar-ready", false, "Start application containers after sidecars are ready, eliminating connection errors of applications starting")
+	cmd.Flags().BoolVar(&i.NativeSidecar, "native-sidecar", false, "Inject sidecars as native sidecars of Kubernetes 1.28+, which start before and stop after applications, falling back to containers on older versions")
 
 	cmd.Flags().StringVar(&i.EaseMeshRegistryType, "registry-type", DefaultMeshRegistryType, MeshRegistryTypeHelpStr)
 	cmd.Flags().IntVar(&i.HeartbeatInterval, "heartbeat-interval", DefaultHeartbeatInterval, "Heartbeat interval for mesh service")
+	cmd.Flags().IntVar(&i.RevisionHistoryLimit, "revision-history-limit", DefaultRevisionHistoryLimit, "The number of revisions kept for every mesh resource")
+	cmd.Flags().IntVar(&i.InstanceExpiry, "instance-expiry", DefaultInstanceExpiry, "Seconds without heartbeats after which a service instance is marked OUT_OF_SERVICE, must be greater than the heartbeat interval")
+	cmd.Flags().IntVar(&i.DeregistrationGracePeriod, "deregistration-grace-period", DefaultDeregistrationGracePeriod, "Seconds an expired service instance is kept before it's deregistered")
+	cmd.Flags().StringVar(&i.ZookeeperConnection, "zookeeper-connection", "", "Connection string of the ZooKeeper registry of Dubbo services like zk-0:2181,zk-1:2181/chroot, syncing them with mesh services")
+	cmd.Flags().StringVar(&i.ZookeeperPathPrefix, "zookeeper-path-prefix", DefaultZookeeperPathPrefix, "Path under the chroot of --zookeeper-connection where Dubbo services register")
+	cmd.Flags().StringVar(&i.VaultAddress, "vault-address", "", "Address of the Vault server like https://vault.vault:8200 resolving vault secret references of mesh resources, empty means none")
+	cmd.Flags().StringVar(&i.VaultRole, "vault-role", "", "Role of the Kubernetes auth method of Vault the control plane logins with, required by --vault-address")
+	cmd.Flags().StringVar(&i.VaultAuthPath, "vault-auth-path", DefaultVaultAuthPath, "Mount path of the Kubernetes auth method of Vault")
+	cmd.Flags().StringSliceVar(&i.SecretNamespaces, "secret-namespaces", []string{}, "Namespaces besides the mesh namespace whose Secrets could be referenced by mesh resources, the control plane is granted to read them")
 
 	cmd.Flags().StringVar(&i.ImageRegistryURL, "image-registry-url", DefaultImageRegistryURL, "Image registry URL")
 	cmd.Flags().StringVar(&i.EasegressImage, "easegress-image", DefaultEasegressImage, "Easegress image name")
+	cmd.Flags().BoolVar(&i.PinDigests, "pin-digests", false, "Resolve tags of the installed images to digests at install time, deploy and record them by digests")
+	cmd.Flags().StringVar(&i.CosignKey, "cosign-key", "", "Public key verifying signatures of the Easegress and operator images by cosign before deploying them, empty means no verification")
+	cmd.Flags().StringVar(&i.EasegressConfigTemplate, "easegress-config-template", "",
+		"A config file of Easegress for the control plane, the cluster name, ports and directories computed by the installer take precedence over it")
 	cmd.Flags().StringVar(&i.EaseMeshOperatorImage, "easemesh-operator-image", DefaultEaseMeshOperatorImage, "Mesh operator image name")
 
 	cmd.Flags().IntVar(&i.EasegressControlPlaneReplicas, "easemesh-control-plane-replicas", DefaultMeshControlPlaneReplicas, "Mesh control plane replicas")
+	cmd.Flags().StringSliceVar(&i.MeshControlPlaneZones, "zones", nil,
+		"Availability zones the control plane members are spread across one per zone, its count must equal --easemesh-control-plane-replicas")
 	cmd.Flags().IntVar(&i.MeshIngressReplicas, "easemesh-ingress-replicas", DefaultMeshIngressReplicas, "Mesh ingress controller replicas")
+	cmd.Flags().IntVar(&i.MeshEgressReplicas, "easemesh-egress-replicas", DefaultMeshEgressReplicas, "Mesh egress gateway replicas (add-on egressgateway)")
+	cmd.Flags().Int32Var(&i.MeshEgressServicePort, "mesh-egress-service-port", DefaultMeshEgressServicePort, "Port of mesh egress gateway (add-on egressgateway)")
 	cmd.Flags().BoolVar(&i.OnlyAddOn, "only-add-on", false, "Only install add-ons")
 	cmd.Flags().StringArrayVar(&i.AddOns, "add-ons", []string{}, "Names of add-ons to be installed")
 	cmd.Flags().StringVar(&i.ShadowServiceControllerImage, "shadowservice-controller-image", DefaultShadowServiceControllerImage, "Shadow service controller image name")
+	cmd.Flags().StringVar(&i.GitOpsRepo, "gitops-repo", "", "URL of the Git repository holding EaseMesh resources (add-on gitops)")
+	cmd.Flags().StringVar(&i.GitOpsBranch, "gitops-branch", DefaultGitOpsBranch, "Branch of the Git repository to sync (add-on gitops)")
+	cmd.Flags().StringVar(&i.GitOpsPath, "gitops-path", ".", "Path of EaseMesh resources inside the Git repository (add-on gitops)")
+	cmd.Flags().DurationVar(&i.GitOpsInterval, "gitops-interval", DefaultGitOpsInterval, "Interval of polling the Git repository (add-on gitops)")
+	cmd.Flags().StringVar(&i.GitOpsWebhookSecret, "gitops-webhook-secret", "", "Secret to verify webhooks triggering sync, empty means no verification (add-on gitops)")
+	cmd.Flags().Int32Var(&i.GitOpsWebhookPort, "gitops-webhook-port", DefaultGitOpsWebhookPort, "Port of the GitOps controller serving webhooks and status (add-on gitops)")
+	cmd.Flags().StringVar(&i.GitOpsControllerImage, "gitops-controller-image", DefaultGitOpsControllerImage, "GitOps controller image name (add-on gitops)")
+	cmd.Flags().StringVar(&i.MaintenanceSchedule, "maintenance-schedule", DefaultMaintenanceSchedule, "Cron schedule of compacting and defragmenting the control plane storage (add-on maintenance)")
+	cmd.Flags().StringVar(&i.MaintenanceImage, "maintenance-image", DefaultMaintenanceImage, "Image name of emctl running the maintenance (add-on maintenance)")
+	cmd.Flags().Int64Var(&i.MaintenanceRetainRevisions, "maintenance-retain-revisions", DefaultMaintenanceRetainRevisions, "Number of latest revisions kept by compacting (add-on maintenance)")
+	cmd.Flags().IntVar(&i.EaseMeshOperatorReplicas, "operator-replicas", DefaultMeshOperatorReplicas, "Mesh operator replicas, only the elected leader reconciles while all of them inject sidecars")
 	cmd.Flags().IntVar(&i.EaseMeshOperatorReplicas, "easemesh-operator-replicas", DefaultMeshOperatorReplicas, "Mesh operator controller replicas")
+	cmd.Flags().MarkDeprecated("easemesh-operator-replicas", "use --operator-replicas instead")
+	cmd.Flags().StringVar(&i.TrafficInterception, "traffic-interception", "", "Experimental, intercept outbound traffic of injected pods to sidecars (support iptables), empty leaves it to EaseAgent and DNS")
+	cmd.Flags().IntSliceVar(&i.InterceptionPorts, "interception-ports", []int{80}, "Destination ports of outbound TCP traffic redirected to sidecars by the traffic interception")
+	cmd.Flags().StringVar(&i.InterceptionImage, "interception-image", DefaultInterceptionImage, "Image of init containers setting up iptables rules of the traffic interception, which needs the shell and iptables")
+	cmd.Flags().BoolVar(&i.OperatorMetricsScrape, "operator-metrics-scrape", false, "Expose the operator metrics on a plain HTTP port annotated for Prometheus scraping")
+	cmd.Flags().BoolVar(&i.OperatorEnablePprof, "operator-enable-pprof", false, "Serve pprof endpoints of the operator under /debug/pprof/ on its metrics port")
 	cmd.Flags().StringVarP(&i.SpecFile, "file", "f", "", "A yaml file specifying the install params")
-	cmd.Flags().BoolVar(&i.CleanWhenFailed, "clean-when-failed", true, "Clean resources when installation failed")
+	cmd.Flags().BoolVar(&i.RollbackOnFailure, "rollback-on-failure", true, "Delete resources created by the installation when it failed")
+	cmd.Flags().BoolVar(&i.RollbackOnFailure, "clean-when-failed", true, "Clean resources when installation failed")
+	cmd.Flags().MarkDeprecated("clean-when-failed", "use --rollback-on-failure instead")
+	cmd.Flags().BoolVar(&i.CleanupFailed, "cleanup-failed", false, "Delete resources left by the last failed installation, then exit")
+	cmd.Flags().StringVar(&i.ProgressFormat, "progress-format", "text",
+		"Format of the install progress (support text, json), json outputs one event per line to stdout and logs to stderr")
+	cmd.Flags().DurationVar(&i.StageTimeout, "stage-timeout", DefaultStageTimeout, "Timeout of every stage of the installation, 0 means no timeout")
+	cmd.Flags().DurationVar(&i.RequestTimeout, "request-timeout", DefaultRequestTimeout, "Timeout of every request to Kubernetes, 0 means no timeout")
 	cmd.Flags().IntVar(&i.WaitControlPlaneTimeoutInSeconds, "wait-control-plane-seconds", DefaultWaitControlPlaneSeconds, "Wait control plane ready timeout in seconds")
+	cmd.Flags().BoolVar(&i.KindPreset, "kind-preset", false, "Apply defaults tuned for local development on kind or minikube, flags specified explicitly take precedence")
+	cmd.Flags().BoolVar(&i.Interactive, "interactive", false, "Walk through the cluster detection, storage, replicas, registry type and add-ons by prompts, then print the equivalent command")
+	cmd.Flags().BoolVar(&i.LowResourceRequests, "low-resource-requests", false, "Lower resource requests of the control plane and the operator for small clusters")
+	cmd.Flags().StringVar(&i.SecurityProfile, "security-profile", "",
+		"Security profile hardening pods of the installed components (support restricted), restricted passes the restricted Pod Security Admission")
+	cmd.Flags().BoolVar(&i.EnableNetworkPolicies, "enable-network-policies", false,
+		"Deploy NetworkPolicies only allowing mesh components and sidecars to connect the control plane and the operator")
 }
 
 // AttachCmd attaches options for reset sub command
@@ -294,6 +1188,92 @@ func (r *Reset) AttachCmd(cmd *cobra.Command) {
 	cmd.Flags().StringArrayVar(&r.AddOns, "add-ons", []string{}, "Names of add-ons to be reset")
 }
 
+// AttachCmd attaches logging options to the command and its sub commands
+func (l *Logging) AttachCmd(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&l.Level, "log-level", "info", "Log level (support debug, info, warn, error)")
+	cmd.PersistentFlags().StringVar(&l.Format, "log-format", common.LogFormatText, "Log format (support text, json), json logs go to stderr")
+	cmd.PersistentFlags().CountVarP(&l.Verbosity, "verbose", "v", "Verbosity, -v logs requests to the control plane, -vv logs their bodies too")
+}
+
+// Setup sets up the logger by the options
+func (l *Logging) Setup() error {
+	err := common.SetLogLevel(l.Level)
+	if err != nil {
+		return err
+	}
+
+	err = common.SetLogFormat(l.Format)
+	if err != nil {
+		return err
+	}
+
+	common.SetVerbosity(l.Verbosity)
+	return nil
+}
+
+// AttachCmd attaches cache options to the command and its sub commands
+func (c *Cache) AttachCmd(cmd *cobra.Command) {
+	dir := ""
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		dir = filepath.Join(cacheDir, "emctl")
+	}
+	cmd.PersistentFlags().StringVar(&c.Dir, "cache-dir", dir, "Directory to cache custom resource kinds with their schemas, empty disables the cache")
+	cmd.PersistentFlags().DurationVar(&c.TTL, "cache-ttl", DefaultCacheTTL, "Duration of trusting the cache, it's discarded earlier once the control plane restarts")
+}
+
+// AttachCmd attaches options of accessing Kubernetes to all the commands
+func (k *Kubernetes) AttachCmd(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&k.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, default is KUBECONFIG or ~/.kube/config")
+	cmd.PersistentFlags().StringVar(&k.Context, "context", "", "The name of the kubeconfig context to use, default is its current context")
+}
+
+// AttachCmd attaches options for plugin krew-manifest command
+func (p *PluginKrewManifest) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.Version, "version", "", "Version of the released plugin, e.g. v1.3.0, default is the version of emctl")
+	cmd.Flags().StringVar(&p.BaseURL, "base-url", "", "URL the archives are downloaded from, default is the GitHub release of the version")
+	cmd.Flags().StringVar(&p.ArchiveDir, "archive-dir", "bin/dist", "Directory of the archives kubectl-mesh_<os>_<arch>.tar.gz, whose checksums are computed")
+	cmd.Flags().StringSliceVar(&p.Platforms, "platforms", DefaultPluginPlatforms, "Platforms of the archives in <os>/<arch>")
+}
+
+// AttachCmd attaches options for version command
+func (v *Version) AttachCmd(cmd *cobra.Command) {
+	v.AdminGlobal = &AdminGlobal{}
+	v.AdminGlobal.AttachCmd(cmd)
+	v.OperationGlobal = &OperationGlobal{}
+	v.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().BoolVar(&v.Client, "client", false, "Only show the version of emctl")
+	cmd.Flags().StringVarP(&v.OutputFormat, "output", "o", "text", "Output format (support text, yaml, json)")
+}
+
+// AttachCmd attaches options for wait command
+func (w *Wait) AttachCmd(cmd *cobra.Command) {
+	w.AdminGlobal = &AdminGlobal{}
+	cmd.Flags().StringVarP(&w.Server, "server", "s", "", "An address to access the EaseMesh control plane")
+	cmd.Flags().DurationVarP(&w.Timeout, "timeout", "t", 30*time.Second, "Max time to wait for all resources")
+	cmd.Flags().StringVar(&w.For, "for", "", "What to wait for: condition=<type>[=<status>] or delete, the status is True by default")
+	cmd.Flags().DurationVar(&w.Interval, "interval", 2*time.Second, "Interval of polling statuses of resources")
+}
+
+// AttachCmd attaches options for lint command
+func (l *Lint) AttachCmd(cmd *cobra.Command) {
+	l.AdminFileInput = &AdminFileInput{}
+	l.AdminFileInput.AttachCmd(cmd)
+
+	cmd.Flags().StringSliceVar(&l.Rules, "rule", nil, "Override the severity of a rule in the form of <rule>=<severity>, severity is one of error, warning, note, off (repeatable)")
+	cmd.Flags().StringVarP(&l.OutputFormat, "output", "o", "text", "Output format (support text, json, sarif)")
+}
+
+// AttachCmd attaches the option of checking version skews globally
+func (v *VersionCheck) AttachCmd(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&v.Skip, "skip-version-check", false, "Run commands even if the control plane or the operator is incompatible with emctl")
+}
+
+// AttachCmd attaches options for telemetry on command
+func (t *TelemetryOn) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&t.Endpoint, "endpoint", "", "HTTP(S) URL of the collector receiving usage reports")
+}
+
 // AttachCmd attaches options globally
 func (o *OperationGlobal) AttachCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.MeshNamespace, "mesh-namespace", DefaultMeshNamespace, "EaseMesh namespace in kubernetes")
@@ -310,6 +1290,9 @@ func (a *AdminGlobal) AttachCmd(cmd *cobra.Command) {
 func (a *AdminFileInput) AttachCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&a.YamlFile, "file", "f", "", "A location contained the EaseMesh resource files (YAML format) to apply, could be a file, directory, or URL")
 	cmd.Flags().BoolVarP(&a.Recursive, "recursive", "r", true, "Whether to recursively iterate all sub-directories and files of the location")
+	cmd.Flags().StringArrayVar(&a.Values, "values", nil, "A YAML file of values rendering ${name} in the resource files, later files override earlier ones (repeatable)")
+	cmd.Flags().StringArrayVar(&a.Set, "set", nil, "A value rendering ${name} in the resource files in the form of <name>=<value>, overriding --values (repeatable)")
+	cmd.Flags().BoolVar(&a.Env, "env", false, "Render ${name} in the resource files by environment variables too, after --set and --values")
 }
 
 // AttachCmd attaches options for apply sub command
@@ -319,6 +1302,17 @@ func (a *Apply) AttachCmd(cmd *cobra.Command) {
 
 	a.AdminFileInput = &AdminFileInput{}
 	a.AdminFileInput.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&a.Selector, "selector", "l", "", "Label selector to filter resources to apply, supports '=', '==', '!=', e.g. -l app=orders")
+	cmd.Flags().BoolVar(&a.Prune, "prune", false, "Delete resources applied with the same selector last time but no longer in the input, requires --selector")
+	cmd.Flags().StringVar(&a.Staged, "staged", "", "Roll out policies of services to percentages of sidecars stage by stage, e.g. 10%,50%,100%")
+	cmd.Flags().DurationVar(&a.BakeTime, "bake-time", 5*time.Minute, "Time to observe error rates after every stage of --staged")
+	cmd.Flags().Float64Var(&a.MaxErrorRate, "max-error-rate", 5, "Max error rate in percent of a service during baking, a rollout exceeding it is aborted")
+	cmd.Flags().BoolVar(&a.Force, "force", false, "Update resources even if they have been modified since metadata.resourceVersion")
+	cmd.Flags().BoolVar(&a.Validate, "validate", true, "Reject unknown fields of resources, --validate=false to ignore them")
+	cmd.Flags().IntVar(&a.Concurrency, "concurrency", DefaultApplyConcurrency, "Number of resources applied concurrently")
+	cmd.Flags().BoolVar(&a.ContinueOnError, "continue-on-error", false, "Keep applying the remaining resources after a failure instead of skipping them")
+	cmd.Flags().BoolVar(&a.AllowMissingRefs, "allow-missing-refs", false, "Apply resources referencing missing tenants, services or ingresses with warnings instead of rejecting them")
 }
 
 // AttachCmd attaches options for delete sub command
@@ -328,6 +1322,10 @@ func (d *Delete) AttachCmd(cmd *cobra.Command) {
 
 	d.AdminFileInput = &AdminFileInput{}
 	d.AdminFileInput.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&d.Selector, "selector", "l", "", "Label selector to delete resources, supports '=', '==', '!=', e.g. -l env=staging")
+	cmd.Flags().BoolVar(&d.Cascade, "cascade", false, "Delete resources referencing the deleted ones as well, or remove the references from them")
+	cmd.Flags().BoolVar(&d.Force, "force", false, "Delete without the confirmation of --cascade, or leave references dangling without --cascade")
 }
 
 // AttachCmd attaches options for get sub command
@@ -335,5 +1333,485 @@ func (g *Get) AttachCmd(cmd *cobra.Command) {
 	g.AdminGlobal = &AdminGlobal{}
 	g.AdminGlobal.AttachCmd(cmd)
 
-	cmd.Flags().StringVarP(&g.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+	cmd.Flags().StringVarP(&g.OutputFormat, "output", "o", "table", "Output format (support table, wide, yaml, json, custom-columns=<header>:<path>,...)")
+	cmd.Flags().StringVarP(&g.Selector, "selector", "l", "", "Label selector to filter resources, supports '=', '==', '!=', e.g. -l team=payments")
+}
+
+// AttachCmd attaches options for describe sub command
+func (d *Describe) AttachCmd(cmd *cobra.Command) {
+	d.AdminGlobal = &AdminGlobal{}
+	d.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for edit sub command
+func (e *Edit) AttachCmd(cmd *cobra.Command) {
+	e.AdminGlobal = &AdminGlobal{}
+	e.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches common options for create sub commands
+func (c *Create) AttachCmd(cmd *cobra.Command) {
+	c.AdminGlobal = &AdminGlobal{}
+	c.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().BoolVar(&c.DryRun, "dry-run", false, "Only print the generated resource without creating it")
+	cmd.Flags().StringVarP(&c.OutputFormat, "output", "o", "", "Output format of the generated resource (support yaml, json), yaml for --dry-run by default")
+}
+
+// AttachCmd attaches options for create service sub command
+func (c *CreateService) AttachCmd(cmd *cobra.Command) {
+	c.Create = &Create{}
+	c.Create.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&c.Tenant, "tenant", "", "Tenant the service is registered in (required)")
+	cmd.Flags().IntVar(&c.Port, "port", 13001, "Port of the sidecar for inbound traffic of the service")
+	cmd.Flags().StringVar(&c.Protocol, "protocol", "http", "Protocol of the service (support http, tcp)")
+}
+
+// AttachCmd attaches options for create tenant sub command
+func (c *CreateTenant) AttachCmd(cmd *cobra.Command) {
+	c.Create = &Create{}
+	c.Create.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&c.Description, "description", "", "Description of the tenant")
+}
+
+// AttachCmd attaches options for create servicecanary sub command
+func (c *CreateServiceCanary) AttachCmd(cmd *cobra.Command) {
+	c.Create = &Create{}
+	c.Create.AttachCmd(cmd)
+
+	cmd.Flags().StringArrayVar(&c.Services, "service", nil, "Service selected by the canary, can be repeated (required)")
+	cmd.Flags().IntVar(&c.Priority, "priority", 5, "Priority of the canary in [1, 9], the lower one is matched first")
+	cmd.Flags().StringArrayVar(&c.Headers, "header", nil, "Header matched exactly in the form name=value, can be repeated, X-Canary=<name> by default")
+	cmd.Flags().StringArrayVar(&c.Labels, "label", nil, "Label of canary instances in the form key=value, can be repeated, release=<name> by default")
+}
+
+// AttachCmd attaches options for create ingress sub command
+func (c *CreateIngress) AttachCmd(cmd *cobra.Command) {
+	c.Create = &Create{}
+	c.Create.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&c.Service, "service", "", "Backend service of the ingress (required)")
+	cmd.Flags().StringVar(&c.Host, "host", "", "Host of the ingress rule, all hosts by default")
+	cmd.Flags().StringVar(&c.Path, "path", "/", "Path of the ingress rule")
+}
+
+// AttachCmd attaches options for create shadowservice sub command
+func (c *CreateShadowService) AttachCmd(cmd *cobra.Command) {
+	c.Create = &Create{}
+	c.Create.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&c.Service, "service", "", "Service to shadow (required)")
+	cmd.Flags().StringVar(&c.Namespace, "namespace", "default", "Kubernetes namespace of the shadow service")
+}
+
+// AttachCmd attaches options for history sub command
+func (h *History) AttachCmd(cmd *cobra.Command) {
+	h.AdminGlobal = &AdminGlobal{}
+	h.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&h.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for rollback sub command
+func (r *Rollback) AttachCmd(cmd *cobra.Command) {
+	r.AdminGlobal = &AdminGlobal{}
+	r.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().Int64Var(&r.ToRevision, "to-revision", 0, "The revision to rollback to, default is the previous revision")
+}
+
+// AttachCmd attaches options for audit list sub command
+func (a *AuditList) AttachCmd(cmd *cobra.Command) {
+	a.AdminGlobal = &AdminGlobal{}
+	a.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().DurationVar(&a.Since, "since", 24*time.Hour, "Only list records newer than a relative duration like 30m, or 24h, zero means all")
+	cmd.Flags().StringVar(&a.Kind, "kind", "", "Only list records of the resource kind")
+	cmd.Flags().StringVar(&a.Name, "name", "", "Only list records of the resource name")
+	cmd.Flags().StringVar(&a.User, "user", "", "Only list records made by the user")
+	cmd.Flags().StringVarP(&a.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for proxy-status command
+func (p *ProxyStatus) AttachCmd(cmd *cobra.Command) {
+	p.AdminGlobal = &AdminGlobal{}
+	p.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&p.Service, "service", "", "Only show sidecars of the service")
+	cmd.Flags().StringVarP(&p.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for ingress cert status sub command
+func (i *IngressCertStatus) AttachCmd(cmd *cobra.Command) {
+	i.AdminGlobal = &AdminGlobal{}
+	i.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&i.Ingress, "ingress", "", "Only show certificates of hosts of the ingress")
+	cmd.Flags().StringVarP(&i.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for ingress cache purge sub command
+func (i *IngressCachePurge) AttachCmd(cmd *cobra.Command) {
+	i.AdminGlobal = &AdminGlobal{}
+	i.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&i.Host, "host", "", "Host of the cached responses to purge")
+	cmd.Flags().StringVar(&i.Path, "path", "/*", "Path of the cached responses to purge, an exact path or a prefix ending with * like /assets/*")
+}
+
+// AttachCmd attaches options for migrate resources sub command
+func (m *MigrateResources) AttachCmd(cmd *cobra.Command) {
+	m.AdminGlobal = &AdminGlobal{}
+	m.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&m.From, "from", "", "Schema version of EaseMesh the resources are stored in")
+	cmd.Flags().StringVar(&m.To, "to", "", "Schema version of EaseMesh to migrate the resources to")
+	cmd.Flags().BoolVar(&m.DryRun, "dry-run", false, "Only convert and validate resources without rewriting them")
+}
+
+// AttachCmd attaches options for events sub command
+func (e *Events) AttachCmd(cmd *cobra.Command) {
+	e.AdminGlobal = &AdminGlobal{}
+	e.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().DurationVar(&e.Since, "since", time.Hour, "Only output events newer than a relative duration like 30m, or 24h, zero means all")
+	cmd.Flags().StringSliceVar(&e.Types, "type", nil, "Only output events of the types (support service, canary, config, sidecar)")
+	cmd.Flags().BoolVarP(&e.Watch, "watch", "w", false, "Keep watching new events after outputting existing ones, until interrupted")
+	cmd.Flags().StringVarP(&e.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for graph sub command
+func (g *Graph) AttachCmd(cmd *cobra.Command) {
+	g.AdminGlobal = &AdminGlobal{}
+	g.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&g.Tenant, "tenant", "", "Only output services of the tenant, with services calling or called by them")
+	cmd.Flags().DurationVar(&g.Window, "window", 0, "Only count calls in a time window like 1m from now on, zero means all calls since sidecars started")
+	cmd.Flags().StringVarP(&g.OutputFormat, "output", "o", "dot", "Output format (support dot, mermaid, json)")
+}
+
+// AttachCmd attaches options for proxy sub command
+func (p *Proxy) AttachCmd(cmd *cobra.Command) {
+	p.OperationGlobal = &OperationGlobal{}
+	p.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&p.Address, "address", "127.0.0.1", "Local address to serve the admin API on, it's unauthenticated so keep it on loopback unless it's intended")
+	cmd.Flags().IntVar(&p.Port, "port", DefaultMeshAdminPort, "Local port to serve the admin API on")
+	cmd.Flags().DurationVar(&p.HealthInterval, "health-interval", 10*time.Second, "Interval of checking the health of the control plane")
+}
+
+// AttachCmd attaches options for slo status sub command
+func (s *SLOStatus) AttachCmd(cmd *cobra.Command) {
+	s.AdminGlobal = &AdminGlobal{}
+	s.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&s.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for alert check sub command
+func (a *AlertCheck) AttachCmd(cmd *cobra.Command) {
+	a.AdminGlobal = &AdminGlobal{}
+	a.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&a.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for alert export sub command
+func (a *AlertExport) AttachCmd(cmd *cobra.Command) {
+	a.AdminGlobal = &AdminGlobal{}
+	a.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&a.Name, "name", "easemesh-alerts", "Name of the PrometheusRule")
+	cmd.Flags().StringVar(&a.Namespace, "namespace", DefaultMeshNamespace, "Namespace of the PrometheusRule")
+	cmd.Flags().StringVar(&a.MetricPrefix, "metric-prefix", "easemesh", "Prefix of metrics of sidecars in Prometheus")
+	cmd.Flags().DurationVar(&a.RateWindow, "rate-window", 5*time.Minute, "Time window of rates of requests and errors in expressions")
+}
+
+// AttachCmd attaches options for alert push sub command
+func (a *AlertPush) AttachCmd(cmd *cobra.Command) {
+	a.AdminGlobal = &AdminGlobal{}
+	a.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&a.Webhook, "webhook", "", "URL of the webhook receiving alerts of rules without their own webhooks")
+	cmd.Flags().DurationVar(&a.Interval, "interval", 0, "Evaluate rules at the interval until interrupted, zero means evaluating once without waiting for the for duration of rules")
+}
+
+// AttachCmd attaches options for auth create-token sub command
+func (a *AuthCreateToken) AttachCmd(cmd *cobra.Command) {
+	a.AdminGlobal = &AdminGlobal{}
+	a.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&a.Tenant, "tenant", "", "Tenant scoping the token, empty means the whole mesh")
+	cmd.Flags().StringVar(&a.Role, "role", "", "Role of the token (support viewer, editor, admin)")
+	cmd.Flags().DurationVar(&a.TTL, "ttl", 0, "Lifetime of the token like 720h, zero means never expiring")
+	cmd.Flags().StringVar(&a.Description, "description", "", "Description of the token, like the team using it")
+	cmd.Flags().StringVarP(&a.OutputFormat, "output", "o", "token", "Output format (support token, yaml, json), token outputs the token only")
+}
+
+// AttachCmd attaches options for auth revoke-token sub command
+func (a *AuthRevokeToken) AttachCmd(cmd *cobra.Command) {
+	a.AdminGlobal = &AdminGlobal{}
+	a.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for policy export-gatekeeper sub command
+func (p *PolicyExportGatekeeper) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.TenantKey, "tenant-key", "mesh.megaease.com/tenant", "Label or annotation key which mesh services must declare their tenants by")
+	cmd.Flags().StringSliceVar(&p.ApprovedRegistries, "approved-registries", []string{DefaultImageRegistryURL + "/megaease/"},
+		"Prefixes of approved images of sidecars and initializers, like registry.example.com/mesh/")
+	cmd.Flags().StringSliceVar(&p.ExcludedNamespaces, "excluded-namespaces", []string{"kube-system", DefaultMeshNamespace}, "Namespaces excluded from the constraints")
+	cmd.Flags().StringVar(&p.EnforcementAction, "enforcement-action", "deny", "Enforcement action of the constraints (support deny, dryrun, warn)")
+}
+
+// AttachCmd attaches options for maintenance enable sub command
+func (m *MaintenanceEnable) AttachCmd(cmd *cobra.Command) {
+	m.AdminGlobal = &AdminGlobal{}
+	m.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&m.Service, "service", "", "Name of the service in maintenance")
+	cmd.Flags().BoolVar(&m.All, "all", false, "Put the whole mesh in maintenance")
+	cmd.Flags().IntVar(&m.Response, "response", 503, "Status code of the static response")
+	cmd.Flags().StringVar(&m.Body, "body", "", "Body of the static response")
+	cmd.Flags().StringArrayVar(&m.Headers, "header", nil, "Header of the static response in the form name=value, can be repeated")
+	cmd.Flags().IntVar(&m.RetryAfter, "retry-after", 0, "Seconds in the Retry-After header of the static response, zero means no header")
+	cmd.Flags().StringVar(&m.Reason, "reason", "", "Reason of the maintenance shown in emctl and logs of sidecars")
+}
+
+// AttachCmd attaches options for maintenance disable sub command
+func (m *MaintenanceDisable) AttachCmd(cmd *cobra.Command) {
+	m.AdminGlobal = &AdminGlobal{}
+	m.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&m.Service, "service", "", "Name of the service out of maintenance")
+	cmd.Flags().BoolVar(&m.All, "all", false, "Take the whole mesh out of maintenance, services in maintenance are kept")
+}
+
+// AttachCmd attaches options for canary test-match sub command
+func (c *CanaryTestMatch) AttachCmd(cmd *cobra.Command) {
+	c.AdminGlobal = &AdminGlobal{}
+	c.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&c.Expression, "expression", "e", "", "Match expression to evaluate instead of the one of the ServiceCanary")
+	cmd.Flags().StringVar(&c.Path, "path", "/", "Path of the sample request")
+	cmd.Flags().StringArrayVar(&c.Headers, "header", nil, "Header of the sample request in the form name=value, can be repeated")
+	cmd.Flags().StringArrayVar(&c.Cookies, "cookie", nil, "Cookie of the sample request in the form name=value, can be repeated")
+	cmd.Flags().StringArrayVar(&c.Query, "query", nil, "Query param of the sample request in the form name=value, can be repeated")
+	cmd.Flags().StringVarP(&c.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for tenant policy set sub command
+func (t *TenantPolicySet) AttachCmd(cmd *cobra.Command) {
+	t.AdminGlobal = &AdminGlobal{}
+	t.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&t.YamlFile, "file", "f", "", "A YAML file contained the policies (resilience, loadBalance, observability) of the tenant")
+}
+
+// AttachCmd attaches options for tenant policy get sub command
+func (t *TenantPolicyGet) AttachCmd(cmd *cobra.Command) {
+	t.AdminGlobal = &AdminGlobal{}
+	t.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&t.OutputFormat, "output", "o", "yaml", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for gitops serve sub command
+func (g *GitOpsServe) AttachCmd(cmd *cobra.Command) {
+	g.AdminGlobal = &AdminGlobal{}
+	g.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&g.Repo, "repo", "", "URL of the Git repository holding EaseMesh resources")
+	cmd.Flags().StringVar(&g.Branch, "branch", DefaultGitOpsBranch, "Branch of the Git repository to sync")
+	cmd.Flags().StringVar(&g.Path, "path", ".", "Path of EaseMesh resources inside the Git repository")
+	cmd.Flags().StringVar(&g.WorkDir, "work-dir", filepath.Join(os.TempDir(), "easemesh-gitops"), "Local directory to clone the Git repository into")
+	cmd.Flags().DurationVar(&g.Interval, "interval", DefaultGitOpsInterval, "Interval of polling the Git repository")
+	cmd.Flags().StringVar(&g.ListenAddress, "listen-address", fmt.Sprintf(":%d", DefaultGitOpsWebhookPort), "Address to serve webhooks triggering sync and the sync status")
+	cmd.Flags().StringVar(&g.WebhookSecret, "webhook-secret", os.Getenv("EASEMESH_GITOPS_WEBHOOK_SECRET"), "Secret to verify webhooks, default is the env EASEMESH_GITOPS_WEBHOOK_SECRET")
+	cmd.Flags().BoolVar(&g.SelfHeal, "self-heal", true, "Apply resources drifted from Git, otherwise drift is only reported")
+	cmd.Flags().StringVarP(&g.Selector, "selector", "l", "", "Label selector to filter resources to sync")
+	cmd.Flags().BoolVar(&g.Prune, "prune", false, "Delete resources removed from Git, requires --selector")
+}
+
+// AttachCmd attaches options for gitops argocd-config sub command
+func (g *GitOpsArgoCDConfig) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&g.Namespace, "namespace", "n", "argocd", "Namespace of ArgoCD")
+	cmd.Flags().StringVarP(&g.OutputFormat, "output", "o", "configmap", "Output format (support configmap, helm)")
+}
+
+// AttachCmd attaches options for mesh-config edit sub command
+func (m *MeshConfig) AttachCmd(cmd *cobra.Command) {
+	m.AdminGlobal = &AdminGlobal{}
+	m.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&m.Name, "name", DefaultMeshControllerName, "Name of the mesh controller")
+	cmd.Flags().BoolVar(&m.DryRun, "dry-run", false, "Only validate and show the changes without updating the mesh controller")
+}
+
+// AttachCmd attaches options for mesh-config patch sub command
+func (m *MeshConfigPatch) AttachCmd(cmd *cobra.Command) {
+	m.MeshConfig = &MeshConfig{}
+	m.MeshConfig.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&m.Patch, "patch", "p", "", "The merge patch (YAML or JSON) to apply, e.g. '{\"heartbeatInterval\": \"10s\"}'")
+	cmd.Flags().StringVar(&m.PatchFile, "patch-file", "", "A file contained the merge patch (YAML or JSON) to apply")
+}
+
+// AttachCmd attaches options for verify-install sub command
+func (v *VerifyInstall) AttachCmd(cmd *cobra.Command) {
+	v.AdminGlobal = &AdminGlobal{}
+	v.AdminGlobal.AttachCmd(cmd)
+
+	v.OperationGlobal = &OperationGlobal{}
+	v.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&v.Namespace, "namespace", "n", DefaultVerifyInstallNamespace, "Temporary namespace to deploy the sample apps, it's deleted after verification")
+	cmd.Flags().StringVar(&v.Image, "image", DefaultVerifyInstallImage, "Image of the sample apps, which must echo request headers in JSON")
+	cmd.Flags().StringVar(&v.IngressAddress, "ingress-address", "", "Address of the mesh ingress like http://192.168.0.10:30080, default is discovered from the NodePort of the ingress service")
+	cmd.Flags().DurationVar(&v.WaitTimeout, "wait-timeout", 5*time.Minute, "Max time to wait for the sample apps, sidecars, metrics and tracing")
+	cmd.Flags().BoolVar(&v.KeepResources, "keep-resources", false, "Keep the sample apps and mesh resources after verification for debugging")
+}
+
+// AttachCmd attaches options for demo install sub command
+func (d *DemoInstall) AttachCmd(cmd *cobra.Command) {
+	d.AdminGlobal = &AdminGlobal{}
+	d.AdminGlobal.AttachCmd(cmd)
+
+	d.OperationGlobal = &OperationGlobal{}
+	d.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&d.Namespace, "namespace", "n", DefaultDemoNamespace, "Namespace to deploy the demo apps")
+	cmd.Flags().StringVar(&d.ImageRegistryURL, "image-registry-url", DefaultImageRegistryURL, "Image registry URL of the demo apps")
+	cmd.Flags().StringVar(&d.ImageTag, "image-tag", DefaultDemoImageTag, "Image tag of the demo apps")
+	cmd.Flags().DurationVar(&d.WaitTimeout, "wait-timeout", 5*time.Minute, "Max time to wait for the demo apps running with sidecars, 0 means no waiting")
+}
+
+// AttachCmd attaches options for demo uninstall sub command
+func (d *DemoUninstall) AttachCmd(cmd *cobra.Command) {
+	d.AdminGlobal = &AdminGlobal{}
+	d.AdminGlobal.AttachCmd(cmd)
+
+	d.OperationGlobal = &OperationGlobal{}
+	d.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&d.Namespace, "namespace", "n", DefaultDemoNamespace, "Namespace of the demo apps, it's deleted")
+}
+
+// AttachCmd attaches options for check upgrade-compat sub command
+func (c *CheckUpgradeCompat) AttachCmd(cmd *cobra.Command) {
+	c.AdminGlobal = &AdminGlobal{}
+	c.AdminGlobal.AttachCmd(cmd)
+
+	c.OperationGlobal = &OperationGlobal{}
+	c.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&c.SidecarImage, "sidecar-image", DefaultEasegressImage, "Sidecar image name injected after upgrading, without the registry")
+}
+
+// AttachCmd attaches options for check refs sub command
+func (c *CheckRefs) AttachCmd(cmd *cobra.Command) {
+	c.AdminGlobal = &AdminGlobal{}
+	c.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for scale control-plane sub command
+func (s *ScaleControlPlane) AttachCmd(cmd *cobra.Command) {
+	s.OperationGlobal = &OperationGlobal{}
+	s.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().IntVar(&s.Replicas, "replicas", 0, "Target number of members of the control plane, an odd number is recommended")
+	cmd.Flags().DurationVar(&s.WaitTimeout, "wait-timeout", 5*time.Minute, "Max time to wait for each member to join or leave the cluster")
+}
+
+// AttachCmd attaches options for sidecar versions sub command
+func (s *SidecarVersions) AttachCmd(cmd *cobra.Command) {
+	s.OperationGlobal = &OperationGlobal{}
+	s.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&s.Namespace, "namespace", "n", "", "Only list pods in the namespace, default is all namespaces")
+	cmd.Flags().StringVarP(&s.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+}
+
+// AttachCmd attaches options for sidecar upgrade sub command
+func (s *SidecarUpgrade) AttachCmd(cmd *cobra.Command) {
+	s.OperationGlobal = &OperationGlobal{}
+	s.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&s.Namespace, "namespace", "n", "", "Namespace of the workloads to upgrade sidecars of")
+	cmd.Flags().StringVar(&s.MaxUnavailable, "max-unavailable", "25%", "Number or percentage of workloads restarted in a batch, e.g. 2 or 20%")
+	cmd.Flags().DurationVar(&s.WaitTimeout, "wait-timeout", 5*time.Minute, "Max time to wait for workloads of a batch to roll out")
+	cmd.Flags().BoolVar(&s.DryRun, "dry-run", false, "Only output the batches of workloads without restarting them")
+}
+
+// AttachCmd attaches options for sidecar config-dump sub command
+func (s *SidecarConfigDump) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.Namespace, "namespace", "n", "default", "Namespace of the pod")
+	cmd.Flags().IntVar(&s.AdminPort, "admin-port", DefaultSidecarAdminPort, "Port of the admin API of the sidecar, which only listens on localhost of the pod")
+	cmd.Flags().StringVarP(&s.OutputFormat, "output", "o", "yaml", "Output format (support yaml, json)")
+	cmd.Flags().DurationVarP(&s.Timeout, "timeout", "t", 30*time.Second, "Max time to forward the port and fetch the configuration")
+}
+
+// AttachCmd attaches options for vm generate sub command
+func (v *VMGenerate) AttachCmd(cmd *cobra.Command) {
+	v.OperationGlobal = &OperationGlobal{}
+	v.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&v.ServiceName, "service-name", "", "Name of the mesh service the VM workload belongs to")
+	cmd.Flags().StringVar(&v.InstanceName, "instance-name", "", "Unique name of the sidecar in the mesh, default is generated from the service name and the application IP")
+	cmd.Flags().StringVar(&v.ApplicationIP, "application-ip", "", "IP of the VM registered as the address of the service instance, it must be reachable from other instances")
+	cmd.Flags().IntVar(&v.ApplicationPort, "application-port", 0, "Port of the application on the VM")
+	cmd.Flags().StringVar(&v.AliveProbeURL, "alive-probe-url", "", "URL probing the liveness of the application, e.g. http://localhost:8080/healthz")
+	cmd.Flags().StringToStringVar(&v.Labels, "labels", map[string]string{}, "Labels of the service instance, e.g. version=v2,zone=dc1")
+	cmd.Flags().StringSliceVar(&v.ControlPlanePeerURLs, "control-plane-peer-urls", []string{}, "Peer URLs of the control plane reachable from the VM, default is discovered from the NodePort of the control plane service")
+	cmd.Flags().StringVar(&v.Format, "format", VMFormatCompose, "Format of the generated files, compose or systemd")
+	cmd.Flags().StringVarP(&v.OutputDir, "output-dir", "o", ".", "Directory the generated files are written to")
+	cmd.Flags().StringVar(&v.SidecarImage, "sidecar-image", DefaultImageRegistryURL+"/"+DefaultVMSidecarImage, "Sidecar image of the compose format")
+	cmd.Flags().StringVar(&v.AgentInitializerImage, "agent-initializer-image", DefaultImageRegistryURL+"/"+DefaultVMAgentInitializerImage, "Image copying EaseAgent into a volume of the compose format")
+}
+
+// AttachCmd attaches options for vm register sub command
+func (v *VMRegister) AttachCmd(cmd *cobra.Command) {
+	v.VMGenerate = &VMGenerate{}
+	v.VMGenerate.AttachCmd(cmd)
+
+	cmd.Flags().DurationVar(&v.TokenTTL, "token-ttl", 24*time.Hour, "Lifetime of the bootstrap token of the VM")
+	cmd.Flags().StringVar(&v.SidecarBinary, "sidecar-binary", "", "Path of the sidecar binary packed into the tarball, e.g. easegress-server copied from the sidecar image")
+}
+
+// AttachCmd attaches options for vm list sub command
+func (v *VMList) AttachCmd(cmd *cobra.Command) {
+	v.OperationGlobal = &OperationGlobal{}
+	v.OperationGlobal.AttachCmd(cmd)
+	v.AdminGlobal = &AdminGlobal{}
+	v.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for vm deregister sub command
+func (v *VMDeregister) AttachCmd(cmd *cobra.Command) {
+	v.OperationGlobal = &OperationGlobal{}
+	v.OperationGlobal.AttachCmd(cmd)
+	v.AdminGlobal = &AdminGlobal{}
+	v.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for control-plane resize-storage sub command
+func (r *ResizeStorage) AttachCmd(cmd *cobra.Command) {
+	r.OperationGlobal = &OperationGlobal{}
+	r.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&r.Capacity, "capacity", "", "New capacity of each persistent volume claim of the control plane, e.g. 20Gi, shrinking is not supported")
+	cmd.Flags().BoolVar(&r.DryRun, "dry-run", false, "Only run the preflight checks and show the current storage of the control plane")
+	cmd.Flags().DurationVar(&r.WaitTimeout, "wait-timeout", 5*time.Minute, "Max time to wait for the volumes to be expanded, 0 means not waiting")
+}
+
+// AttachCmd attaches options for maintenance run sub command
+func (m *Maintenance) AttachCmd(cmd *cobra.Command) {
+	m.OperationGlobal = &OperationGlobal{}
+	m.OperationGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVar(&m.Endpoint, "endpoint", "", "Etcd client URL of a control plane member reachable from emctl, default is accessing members via the Kubernetes API server")
+	cmd.Flags().Int64Var(&m.RetainRevisions, "retain-revisions", DefaultMaintenanceRetainRevisions, "Number of latest revisions kept by compacting, 0 means skipping the compaction")
+	cmd.Flags().BoolVar(&m.SkipDefrag, "skip-defrag", false, "Only compact without defragmenting members")
+	cmd.Flags().DurationVar(&m.Timeout, "timeout", 5*time.Minute, "Max time of each request to members, defragmenting a large storage takes a while")
 }
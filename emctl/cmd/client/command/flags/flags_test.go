@@ -69,3 +69,28 @@ func TestInstallFlag(t *testing.T) {
 	a := Install{}
 	a.AttachCmd(cmd)
 }
+
+func TestApplyKindPreset(t *testing.T) {
+	cmd := &cobra.Command{}
+	i := Install{}
+	i.AttachCmd(cmd)
+
+	err := cmd.Flags().Parse([]string{"--kind-preset", "--easemesh-operator-replicas", "2", "--mesh-ingress-node-port", "31080"})
+	if err != nil {
+		t.Fatalf("parse flags failed: %v", err)
+	}
+	err = i.ApplyPreset(cmd)
+	if err != nil {
+		t.Fatalf("apply preset failed: %v", err)
+	}
+
+	if i.EasegressControlPlaneReplicas != 1 || !i.MeshControlPlaneEphemeralStorage || !i.LowResourceRequests {
+		t.Fatalf("preset not applied: %+v", i)
+	}
+	if i.MeshControlPlaneAdminNodePort != KindPresetControlPlaneAdminNodePort {
+		t.Fatalf("expected admin node port %d, got %d", KindPresetControlPlaneAdminNodePort, i.MeshControlPlaneAdminNodePort)
+	}
+	if i.EaseMeshOperatorReplicas != 2 || i.MeshIngressNodePort != 31080 {
+		t.Fatalf("flags specified explicitly are overridden by preset: %+v", i)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flags
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// KindPresetIngressNodePort is the NodePort of the mesh ingress with --kind-preset
+	KindPresetIngressNodePort = 30080
+	// KindPresetControlPlaneAdminNodePort is the NodePort of the control plane admin port with --kind-preset
+	KindPresetControlPlaneAdminNodePort = 30381
+	// KindPresetControlPlaneClientNodePort is the NodePort of the control plane client port with --kind-preset
+	KindPresetControlPlaneClientNodePort = 30379
+)
+
+// presetValue is the value of flags in a preset, the first name is the
+// flag set by the preset, the others are its deprecated aliases.
+type presetValue struct {
+	names []string
+	value string
+}
+
+// kindPreset stands up the EaseMesh on a single node kind or minikube cluster.
+// NodePorts are fixed, so they could be mapped to the host by extraPortMappings of kind.
+var kindPreset = []presetValue{
+	{names: []string{"easemesh-control-plane-replicas"}, value: "1"},
+	{names: []string{"easemesh-ingress-replicas"}, value: "1"},
+	{names: []string{"operator-replicas", "easemesh-operator-replicas"}, value: "1"},
+	{names: []string{"ephemeral-storage"}, value: "true"},
+	{names: []string{"low-resource-requests"}, value: "true"},
+	{names: []string{"mesh-ingress-node-port"}, value: strconv.Itoa(KindPresetIngressNodePort)},
+	{names: []string{"mesh-control-plane-admin-node-port"}, value: strconv.Itoa(KindPresetControlPlaneAdminNodePort)},
+	{names: []string{"mesh-control-plane-client-node-port"}, value: strconv.Itoa(KindPresetControlPlaneClientNodePort)},
+}
+
+// ApplyPreset sets flags of the preset chosen by the install flags,
+// flags specified explicitly in the command line are kept.
+func (i *Install) ApplyPreset(cmd *cobra.Command) error {
+	if !i.KindPreset {
+		return nil
+	}
+
+	for _, p := range kindPreset {
+		changed := false
+		for _, name := range p.names {
+			if cmd.Flags().Changed(name) {
+				changed = true
+				break
+			}
+		}
+		if changed {
+			continue
+		}
+
+		err := cmd.Flags().Set(p.names[0], p.value)
+		if err != nil {
+			return errors.Wrapf(err, "set flag %s of preset", p.names[0])
+		}
+	}
+	return nil
+}
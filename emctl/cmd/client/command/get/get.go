@@ -18,15 +18,23 @@
 package get
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
 	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 	"github.com/megaease/easemeshctl/cmd/client/util"
 	"github.com/megaease/easemeshctl/cmd/common"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Run is the entrypoint of the get sub command
@@ -34,11 +42,16 @@ func Run(cmd *cobra.Command, flag *flags.Get) {
 	if flag.Server == "" {
 		flag.Server = flags.GetServerAddress()
 	}
-	switch flag.OutputFormat {
-	case "table", "yaml", "json":
-	default:
-		common.ExitWithErrorf("unsupported output format %s (support table, yaml, json)",
-			flag.OutputFormat)
+	err := printer.ValidateOutputFormat(flag.OutputFormat)
+	if err != nil {
+		common.ExitWithError(err)
+		return
+	}
+
+	selector, err := labels.Parse(flag.Selector)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid label selector %s: %v", flag.Selector, err)
+		return
 	}
 
 	visitorBulder := util.NewVisitorBuilder()
@@ -47,26 +60,38 @@ func Run(cmd *cobra.Command, flag *flags.Get) {
 
 	switch len(cmdArgs) {
 	case 0:
-		common.ExitWithErrorf("no resource specified")
-	case 1:
-		visitorBulder.CommandParam(&util.CommandOptions{
-			Kind: cmdArgs[0],
-		})
-	case 2:
-		visitorBulder.CommandParam(&util.CommandOptions{
-			Kind: cmdArgs[0],
-			Name: cmdArgs[1],
-		})
+		common.ExitWithCodef(common.ExitCodeValidation, "no resource specified")
+		return
+	case 1, 2:
 	default:
-		common.ExitWithErrorf("invalid command args: support <resource kind> [resource name]")
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <resource kind> [resource name]")
+		return
 	}
 
+	kind, err := util.ResolveCommandKind(meshclient.New(flag.Server), cmdArgs[0], flag.Timeout)
+	if err != nil {
+		common.ExitWithError(err)
+		return
+	}
+	options := &util.CommandOptions{Kind: kind}
+	if len(cmdArgs) == 2 {
+		options.Name = cmdArgs[1]
+	}
+	visitorBulder.CommandParam(options)
+
 	vss, err := visitorBulder.Do()
 	if err != nil {
-		common.ExitWithErrorf("build visitor failed: %s", err)
+		common.ExitWithErrorf("build visitor failed: %w", err)
 	}
 
-	printer := printer.New(flag.OutputFormat)
+	var printOpts []printer.Option
+	if flag.OutputFormat == "wide" && kind == resource.KindService {
+		printOpts = append(printOpts, printer.WithWideColumns(instancesColumns(meshclient.New(flag.Server), flag.Timeout)))
+	}
+	if flag.OutputFormat == "wide" {
+		printOpts = append(printOpts, printer.WithWideColumns(statusColumns(meshclient.New(flag.Server), kind, flag.Timeout)))
+	}
+	objectPrinter := printer.New(flag.OutputFormat, printOpts...)
 	var errs []error
 	for _, vs := range vss {
 		err := vs.Visit(func(mo meta.MeshObject, e error) error {
@@ -84,7 +109,9 @@ func Run(cmd *cobra.Command, flag *flags.Get) {
 				return errors.Wrapf(err, "%s get failed", resourceID)
 			}
 
-			printer.PrintObjects(objects)
+			objects = filterBySelector(objects, selector)
+			sortObjects(objects)
+			objectPrinter.PrintObjects(objects)
 
 			return nil
 		})
@@ -97,6 +124,106 @@ func Run(cmd *cobra.Command, flag *flags.Get) {
 	}
 
 	if len(errs) > 0 {
-		common.ExitWithErrorf("getting resources has errors occurred")
+		common.ExitWithCodef(common.ExitCodeOf(errs...), "getting resources has errors occurred")
+	}
+}
+
+func filterBySelector(objects []meta.MeshObject, selector labels.Selector) []meta.MeshObject {
+	if selector.Empty() {
+		return objects
+	}
+
+	result := []meta.MeshObject{}
+	for _, object := range objects {
+		if selector.Matches(labels.Set(object.Labels())) {
+			result = append(result, object)
+		}
+	}
+	return result
+}
+
+// sortObjects sorts objects by their kinds and names, so that the output is stable.
+func sortObjects(objects []meta.MeshObject) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].Kind() != objects[j].Kind() {
+			return objects[i].Kind() < objects[j].Kind()
+		}
+		return objects[i].Name() < objects[j].Name()
+	})
+}
+
+// instancesColumns returns the function of the column of instances of services
+// in the wide table, in the form of <up>/<total>. Instances are listed once.
+func instancesColumns(client meshclient.MeshClient, timeout time.Duration) printer.ColumnsFunc {
+	var once sync.Once
+	var up, total map[string]int
+	var err error
+	return func(object meta.MeshObject) []*meta.TableColumn {
+		once.Do(func() {
+			ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+			defer cancelFunc()
+
+			var instances []*resource.ServiceInstance
+			instances, err = client.V1Alpha1().ServiceInstance().List(ctx)
+			if err != nil {
+				common.Warnf("list service instances failed: %v", err)
+				return
+			}
+
+			up, total = map[string]int{}, map[string]int{}
+			for _, instance := range instances {
+				if instance.Spec == nil {
+					continue
+				}
+				total[instance.Spec.ServiceName]++
+				if instance.Spec.Status == "UP" {
+					up[instance.Spec.ServiceName]++
+				}
+			}
+		})
+
+		value := "<unknown>"
+		if err == nil {
+			value = fmt.Sprintf("%d/%d", up[object.Name()], total[object.Name()])
+		}
+		return []*meta.TableColumn{{Name: "Instances", Value: value}}
+	}
+}
+
+// statusColumns returns the function of the columns of statuses of resources
+// in the wide table: the phase and the sidecars synced. Statuses are listed once.
+func statusColumns(client meshclient.MeshClient, kind string, timeout time.Duration) printer.ColumnsFunc {
+	var once sync.Once
+	var statuses map[string]*resource.ResourceStatus
+	var err error
+	return func(object meta.MeshObject) []*meta.TableColumn {
+		once.Do(func() {
+			ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+			defer cancelFunc()
+
+			var list []*resource.ResourceStatus
+			list, err = client.V1Alpha1().Status().List(ctx, kind)
+			if err != nil && !meshclient.IsNotFoundError(err) && !meshclient.IsUnsupportedError(err) {
+				common.Warnf("list statuses of %s failed: %v", kind, err)
+				return
+			}
+			err = nil
+
+			statuses = map[string]*resource.ResourceStatus{}
+			for _, status := range list {
+				statuses[status.Name] = status
+			}
+		})
+
+		phase, synced := "<unknown>", "<unknown>"
+		status := statuses[object.Name()]
+		switch {
+		case err != nil:
+		case status == nil:
+			phase, synced = "<none>", "<none>"
+		default:
+			phase, synced = status.Phase(), status.Synced()
+		}
+		return []*meta.TableColumn{{Name: "Status", Value: phase}, {Name: "Synced", Value: synced}}
 	}
 }
@@ -26,9 +26,25 @@ import (
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 )
 
-// WrapGetterByMeshObject wraps getter for mesh object.
+// WrapGetterByMeshObject wraps getter for mesh object, the got objects
+// carry their labels and annotations, and the resource version if the
+// object is got by its name.
 func WrapGetterByMeshObject(object meta.MeshObject,
 	client meshclient.MeshClient, timeout time.Duration,
+) Getter {
+	base := baseGetter{client: client, timeout: timeout}
+	getter := wrapGetterByKind(object, client, timeout)
+	if object.Name() != "" {
+		getter = &resourceVersionGetter{baseGetter: base, getter: getter}
+	}
+	if !resource.HasResourceMeta(object.Kind()) {
+		return getter
+	}
+	return &resourceMetaGetter{baseGetter: base, getter: getter}
+}
+
+func wrapGetterByKind(object meta.MeshObject,
+	client meshclient.MeshClient, timeout time.Duration,
 ) Getter {
 	base := baseGetter{
 		client:  client,
@@ -64,6 +80,24 @@ func WrapGetterByMeshObject(object meta.MeshObject,
 		return &httpRouteGroupGetter{object: object.(*resource.HTTPRouteGroup), baseGetter: base}
 	case resource.KindTrafficTarget:
 		return &trafficTargetGetter{object: object.(*resource.TrafficTarget), baseGetter: base}
+	case resource.KindExternalService:
+		return &externalServiceGetter{object: object.(*resource.ExternalService), baseGetter: base}
+	case resource.KindTenantPolicy:
+		return &tenantPolicyGetter{object: object.(*resource.TenantPolicy), baseGetter: base}
+	case resource.KindSLO:
+		return &sloGetter{object: object.(*resource.SLO), baseGetter: base}
+	case resource.KindAlertRule:
+		return &alertRuleGetter{object: object.(*resource.AlertRule), baseGetter: base}
+	case resource.KindMessagingPolicy:
+		return &messagingPolicyGetter{object: object.(*resource.MessagingPolicy), baseGetter: base}
+	case resource.KindIngressPort:
+		return &ingressPortGetter{object: object.(*resource.IngressPort), baseGetter: base}
+	case resource.KindWAFPolicy:
+		return &wafPolicyGetter{object: object.(*resource.WAFPolicy), baseGetter: base}
+	case resource.KindMaintenanceMode:
+		return &maintenanceModeGetter{object: object.(*resource.MaintenanceMode), baseGetter: base}
+	case resource.KindPolicyRollout:
+		return &policyRolloutGetter{object: object.(*resource.PolicyRollout), baseGetter: base}
 	case resource.KindCustomResourceKind:
 		return &customResourceKindGetter{object: object.(*resource.CustomResourceKind), baseGetter: base}
 	case resource.KindServiceCanary:
@@ -556,6 +590,285 @@ func (i *serviceCanaryGetter) Get() ([]meta.MeshObject, error) {
 	return objects, nil
 }
 
+type externalServiceGetter struct {
+	baseGetter
+	object *resource.ExternalService
+}
+
+func (e *externalServiceGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), e.timeout)
+	defer cancelFunc()
+
+	if e.object.Name() != "" {
+		externalService, err := e.client.V1Alpha1().ExternalService().Get(ctx, e.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{externalService}, nil
+	}
+
+	externalServices, err := e.client.V1Alpha1().ExternalService().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(externalServices))
+	for i := range externalServices {
+		objects[i] = externalServices[i]
+	}
+
+	return objects, nil
+}
+
+type tenantPolicyGetter struct {
+	baseGetter
+	object *resource.TenantPolicy
+}
+
+func (t *tenantPolicyGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), t.timeout)
+	defer cancelFunc()
+
+	if t.object.Name() != "" {
+		tenantPolicy, err := t.client.V1Alpha1().TenantPolicy().Get(ctx, t.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{tenantPolicy}, nil
+	}
+
+	tenantPolicies, err := t.client.V1Alpha1().TenantPolicy().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(tenantPolicies))
+	for i := range tenantPolicies {
+		objects[i] = tenantPolicies[i]
+	}
+
+	return objects, nil
+}
+
+type sloGetter struct {
+	baseGetter
+	object *resource.SLO
+}
+
+func (s *sloGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFunc()
+
+	if s.object.Name() != "" {
+		slo, err := s.client.V1Alpha1().SLO().Get(ctx, s.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{slo}, nil
+	}
+
+	slos, err := s.client.V1Alpha1().SLO().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(slos))
+	for i := range slos {
+		objects[i] = slos[i]
+	}
+
+	return objects, nil
+}
+
+type alertRuleGetter struct {
+	baseGetter
+	object *resource.AlertRule
+}
+
+func (a *alertRuleGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), a.timeout)
+	defer cancelFunc()
+
+	if a.object.Name() != "" {
+		alertRule, err := a.client.V1Alpha1().AlertRule().Get(ctx, a.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{alertRule}, nil
+	}
+
+	alertRules, err := a.client.V1Alpha1().AlertRule().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(alertRules))
+	for i := range alertRules {
+		objects[i] = alertRules[i]
+	}
+
+	return objects, nil
+}
+
+type messagingPolicyGetter struct {
+	baseGetter
+	object *resource.MessagingPolicy
+}
+
+func (m *messagingPolicyGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	defer cancelFunc()
+
+	if m.object.Name() != "" {
+		messagingPolicy, err := m.client.V1Alpha1().MessagingPolicy().Get(ctx, m.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{messagingPolicy}, nil
+	}
+
+	messagingPolicies, err := m.client.V1Alpha1().MessagingPolicy().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(messagingPolicies))
+	for i := range messagingPolicies {
+		objects[i] = messagingPolicies[i]
+	}
+
+	return objects, nil
+}
+
+type ingressPortGetter struct {
+	baseGetter
+	object *resource.IngressPort
+}
+
+func (i *ingressPortGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), i.timeout)
+	defer cancelFunc()
+
+	if i.object.Name() != "" {
+		ingressPort, err := i.client.V1Alpha1().IngressPort().Get(ctx, i.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{ingressPort}, nil
+	}
+
+	ingressPorts, err := i.client.V1Alpha1().IngressPort().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(ingressPorts))
+	for i := range ingressPorts {
+		objects[i] = ingressPorts[i]
+	}
+
+	return objects, nil
+}
+
+type wafPolicyGetter struct {
+	baseGetter
+	object *resource.WAFPolicy
+}
+
+func (i *wafPolicyGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), i.timeout)
+	defer cancelFunc()
+
+	if i.object.Name() != "" {
+		wafPolicy, err := i.client.V1Alpha1().WAFPolicy().Get(ctx, i.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{wafPolicy}, nil
+	}
+
+	wafPolicies, err := i.client.V1Alpha1().WAFPolicy().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(wafPolicies))
+	for i := range wafPolicies {
+		objects[i] = wafPolicies[i]
+	}
+
+	return objects, nil
+}
+
+type maintenanceModeGetter struct {
+	baseGetter
+	object *resource.MaintenanceMode
+}
+
+func (m *maintenanceModeGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	defer cancelFunc()
+
+	if m.object.Name() != "" {
+		maintenanceMode, err := m.client.V1Alpha1().MaintenanceMode().Get(ctx, m.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{maintenanceMode}, nil
+	}
+
+	maintenanceModes, err := m.client.V1Alpha1().MaintenanceMode().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(maintenanceModes))
+	for i := range maintenanceModes {
+		objects[i] = maintenanceModes[i]
+	}
+
+	return objects, nil
+}
+
+type policyRolloutGetter struct {
+	baseGetter
+	object *resource.PolicyRollout
+}
+
+func (m *policyRolloutGetter) Get() ([]meta.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), m.timeout)
+	defer cancelFunc()
+
+	if m.object.Name() != "" {
+		policyRollout, err := m.client.V1Alpha1().PolicyRollout().Get(ctx, m.object.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		return []meta.MeshObject{policyRollout}, nil
+	}
+
+	policyRollouts, err := m.client.V1Alpha1().PolicyRollout().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]meta.MeshObject, len(policyRollouts))
+	for i := range policyRollouts {
+		objects[i] = policyRollouts[i]
+	}
+
+	return objects, nil
+}
+
 type customResourceKindGetter struct {
 	baseGetter
 	object *resource.CustomResourceKind
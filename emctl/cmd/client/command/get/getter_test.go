@@ -27,6 +27,7 @@ import (
 	"github.com/megaease/easemeshctl/cmd/client/resource"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func TestGetter(t *testing.T) {
@@ -103,3 +104,42 @@ func TestGetterFail(t *testing.T) {
 	WrapGetterByMeshObject(meshtesting.CreateMeshObjectFromType(reflect.TypeOf(resource.ServiceInstance{}),
 		resource.KindServiceInstance, "aaaa"), client, time.Second*1).Get()
 }
+
+func TestGetterWithResourceMeta(t *testing.T) {
+	reactorType := "__test_resource_meta_reactor"
+	tenant := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "payments")}
+	labeled := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "payments")}
+	labeled.MetaData.Labels = map[string]string{"team": "payments"}
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindResourceMeta, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{resource.NewResourceMeta(labeled)}, nil
+		}).
+		AddReactor("get", resource.KindTenant, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{tenant}, nil
+		}).
+		AddReactor("list", resource.KindRevision, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{
+				&resource.Revision{Spec: &resource.RevisionObject{Kind: resource.KindTenant, Name: "payments", Revision: 1}},
+				&resource.Revision{Spec: &resource.RevisionObject{Kind: resource.KindTenant, Name: "payments", Revision: 2}},
+			}, nil
+		}).
+		Added()
+
+	client := meshclient.NewFakeClient(reactorType)
+	query := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, "payments")}
+	objects, err := WrapGetterByMeshObject(query, client, time.Second).Get()
+	if err != nil {
+		t.Fatalf("get tenant failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Labels()["team"] != "payments" {
+		t.Fatalf("labels of tenant should be got, but got %+v", objects)
+	}
+	if objects[0].ResourceVersion() != "2" {
+		t.Fatalf("resource version of tenant should be the latest revision, but got %q", objects[0].ResourceVersion())
+	}
+
+	selector, _ := labels.Parse("team=orders")
+	if len(filterBySelector(objects, selector)) != 0 {
+		t.Fatalf("tenant should not match the selector %s", selector)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package get
+
+import (
+	"context"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+// resourceMetaGetter sets labels and annotations to the got objects.
+type resourceMetaGetter struct {
+	baseGetter
+	getter Getter
+}
+
+func (r *resourceMetaGetter) Get() ([]meta.MeshObject, error) {
+	objects, err := r.getter.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), r.timeout)
+	defer cancelFunc()
+	resourceMetas, err := r.client.V1Alpha1().ResourceMeta().List(ctx)
+	switch {
+	case meshclient.IsNotFoundError(err), meshclient.IsUnsupportedError(err):
+		return objects, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "list resource meta")
+	}
+
+	byName := map[string]*resource.ResourceMeta{}
+	for _, resourceMeta := range resourceMetas {
+		byName[resourceMeta.Name()] = resourceMeta
+	}
+	for _, object := range objects {
+		resourceMeta, ok := byName[resource.ResourceMetaName(object.Kind(), object.Name())]
+		if ok {
+			resourceMeta.ApplyTo(object)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package get
+
+import (
+	"context"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// resourceVersionGetter sets the resource versions to the got objects,
+// so that they could be updated with the precondition of versions.
+type resourceVersionGetter struct {
+	baseGetter
+	getter Getter
+}
+
+func (r *resourceVersionGetter) Get() ([]meta.MeshObject, error) {
+	objects, err := r.getter.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), r.timeout)
+	defer cancelFunc()
+	for _, object := range objects {
+		setter, ok := object.(meta.MetaDataSetter)
+		if !ok {
+			continue
+		}
+		version, err := meshclient.ResourceVersion(ctx, r.client, object.Kind(), object.Name())
+		if err != nil {
+			return nil, err
+		}
+		setter.SetResourceVersion(version)
+	}
+	return objects, nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// argoCDConfigMapName is the ConfigMap holding resource customizations of ArgoCD.
+	argoCDConfigMapName = "argocd-cm"
+
+	meshDeploymentGroupKind = "mesh.megaease.com_MeshDeployment"
+
+	// meshDeploymentHealthScript judges the health by the Ready condition
+	// if the operator reports it, otherwise the resource is healthy once
+	// it's accepted, since the operator reconciles it into a Deployment
+	// whose health ArgoCD already knows.
+	meshDeploymentHealthScript = `hs = {}
+if obj.status ~= nil then
+  if obj.status.observedGeneration ~= nil and obj.metadata.generation ~= nil and obj.status.observedGeneration < obj.metadata.generation then
+    hs.status = "Progressing"
+    hs.message = "Waiting for the EaseMesh operator to observe the latest spec"
+    return hs
+  end
+  if obj.status.conditions ~= nil then
+    for i, condition in ipairs(obj.status.conditions) do
+      if condition.type == "Ready" then
+        if condition.status == "True" then
+          hs.status = "Healthy"
+        elseif condition.status == "False" then
+          hs.status = "Degraded"
+        else
+          hs.status = "Progressing"
+        end
+        hs.message = condition.message
+        return hs
+      end
+    end
+  end
+end
+hs.status = "Healthy"
+hs.message = "MeshDeployment is reconciled by the EaseMesh operator"
+return hs
+`
+)
+
+type (
+	// argoCDIgnoreDifferences is the ignoreDifferences customization of ArgoCD.
+	argoCDIgnoreDifferences struct {
+		JSONPointers      []string `json:"jsonPointers,omitempty"`
+		JQPathExpressions []string `json:"jqPathExpressions,omitempty"`
+	}
+
+	// argoCDKnownTypeField is the knownTypeFields customization of ArgoCD,
+	// which normalizes the field as the built-in type.
+	argoCDKnownTypeField struct {
+		Field string `json:"field"`
+		Type  string `json:"type"`
+	}
+)
+
+// meshDeploymentIgnoreDifferences ignores fields defaulted by the API server
+// or the operator, only if they hold the default values.
+func meshDeploymentIgnoreDifferences() *argoCDIgnoreDifferences {
+	return &argoCDIgnoreDifferences{
+		JQPathExpressions: []string{
+			`.spec.service.appContainerName | select(. == "")`,
+			`.spec.service.aliveProbeURL | select(. == "")`,
+			`.spec.service.applicationPort | select(. == 0)`,
+			`.spec.service.labels | select(. == null or . == {})`,
+			`.spec.deploy.template.spec.containers[]?.ports[]?.protocol | select(. == "TCP")`,
+			`.spec.deploy.template.spec.initContainers[]?.ports[]?.protocol | select(. == "TCP")`,
+		},
+	}
+}
+
+func meshDeploymentKnownTypeFields() []argoCDKnownTypeField {
+	return []argoCDKnownTypeField{
+		{Field: "spec.deploy", Type: "apps/v1/DeploymentSpec"},
+	}
+}
+
+// argoCDCustomizations returns the keys of ArgoCD ConfigMap for resource customizations.
+func argoCDCustomizations() (map[string]string, error) {
+	ignoreDifferences, err := yaml.Marshal(meshDeploymentIgnoreDifferences())
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal ignore differences")
+	}
+
+	knownTypeFields, err := yaml.Marshal(meshDeploymentKnownTypeFields())
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal known type fields")
+	}
+
+	return map[string]string{
+		"resource.customizations.health." + meshDeploymentGroupKind:            meshDeploymentHealthScript,
+		"resource.customizations.ignoreDifferences." + meshDeploymentGroupKind: string(ignoreDifferences),
+		"resource.customizations.knownTypeFields." + meshDeploymentGroupKind:   string(knownTypeFields),
+	}, nil
+}
+
+// argoCDConfig renders the customizations as a ConfigMap, or values of the ArgoCD Helm chart.
+func argoCDConfig(format, namespace string) ([]byte, error) {
+	customizations, err := argoCDCustomizations()
+	if err != nil {
+		return nil, err
+	}
+
+	var config interface{}
+	switch strings.ToLower(format) {
+	case "configmap":
+		config = &v1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      argoCDConfigMapName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    argoCDConfigMapName,
+					"app.kubernetes.io/part-of": "argocd",
+				},
+			},
+			Data: customizations,
+		}
+	case "helm":
+		config = map[string]interface{}{
+			"configs": map[string]interface{}{
+				"cm": customizations,
+			},
+		}
+	default:
+		return nil, errors.Errorf("unsupported output format %s (support configmap, helm)", format)
+	}
+
+	buff, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal ArgoCD config")
+	}
+
+	return buff, nil
+}
+
+// RunArgoCDConfig is the entrypoint of the emctl gitops argocd-config subcommand
+func RunArgoCDConfig(cmd *cobra.Command, flag *flags.GitOpsArgoCDConfig) {
+	buff, err := argoCDConfig(flag.OutputFormat, flag.Namespace)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	fmt.Print(string(buff))
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestArgoCDConfig(t *testing.T) {
+	buff, err := argoCDConfig("configmap", "argocd")
+	if err != nil {
+		t.Fatalf("generate configmap failed: %v", err)
+	}
+
+	cm := &v1.ConfigMap{}
+	err = yaml.Unmarshal(buff, cm)
+	if err != nil {
+		t.Fatalf("unmarshal configmap failed: %v", err)
+	}
+	if cm.Name != argoCDConfigMapName || cm.Namespace != "argocd" {
+		t.Fatalf("expect configmap argocd/%s but got %s/%s", argoCDConfigMapName, cm.Namespace, cm.Name)
+	}
+
+	health := cm.Data["resource.customizations.health."+meshDeploymentGroupKind]
+	if !strings.Contains(health, `condition.type == "Ready"`) {
+		t.Fatalf("health script should check the Ready condition, but got %s", health)
+	}
+
+	ignoreDifferences := &argoCDIgnoreDifferences{}
+	err = yaml.Unmarshal([]byte(cm.Data["resource.customizations.ignoreDifferences."+meshDeploymentGroupKind]), ignoreDifferences)
+	if err != nil {
+		t.Fatalf("unmarshal ignore differences failed: %v", err)
+	}
+	if len(ignoreDifferences.JQPathExpressions) == 0 {
+		t.Fatalf("expect jq path expressions of defaulted fields")
+	}
+
+	buff, err = argoCDConfig("helm", "argocd")
+	if err != nil {
+		t.Fatalf("generate helm values failed: %v", err)
+	}
+	if !strings.HasPrefix(string(buff), "configs:\n  cm:\n") {
+		t.Fatalf("expect helm values under configs.cm but got %s", buff)
+	}
+
+	_, err = argoCDConfig("kustomize", "argocd")
+	if err == nil {
+		t.Fatalf("unsupported format should fail")
+	}
+}
@@ -0,0 +1,400 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxWebhookBodySize is large enough for push events of GitHub and GitLab.
+	maxWebhookBodySize = 10 << 20
+
+	githubSignatureHeader = "X-Hub-Signature-256"
+	gitlabTokenHeader     = "X-Gitlab-Token"
+)
+
+// Controller keeps mesh resources in sync with the ones in a Git repository.
+type Controller struct {
+	flag     *flags.GitOpsServe
+	client   meshclient.MeshClient
+	repo     *repository
+	pruner   *apply.Pruner
+	trigger  chan struct{}
+	recorder *statusRecorder
+}
+
+// NewController creates a Controller.
+func NewController(flag *flags.GitOpsServe, client meshclient.MeshClient) (*Controller, error) {
+	if flag.Repo == "" {
+		return nil, errors.Errorf("no git repository specified")
+	}
+	if flag.Interval <= 0 {
+		return nil, errors.Errorf("invalid interval %s", flag.Interval)
+	}
+
+	c := &Controller{
+		flag:    flag,
+		client:  client,
+		repo:    newRepository(flag.Repo, flag.Branch, flag.WorkDir),
+		trigger: make(chan struct{}, 1),
+		recorder: &statusRecorder{
+			status: Status{
+				Repo:   flag.Repo,
+				Branch: flag.Branch,
+				Path:   flag.Path,
+			},
+		},
+	}
+
+	if flag.Selector != "" || flag.Prune {
+		pruner, err := apply.NewPruner(client, flag.Selector, flag.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		c.pruner = pruner
+	}
+
+	return c, nil
+}
+
+// Run syncs at every interval or webhook until the context is done.
+func (c *Controller) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:    c.flag.ListenAddress,
+		Handler: c.handler(),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	defer server.Close()
+
+	ticker := time.NewTicker(c.flag.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := c.sync(ctx)
+		if err != nil {
+			common.OutputError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-serverErr:
+			return errors.Wrapf(err, "serve %s", c.flag.ListenAddress)
+		case <-ticker.C:
+		case <-c.trigger:
+			common.Infof("sync triggered by webhook")
+		}
+	}
+}
+
+func (c *Controller) sync(ctx context.Context) error {
+	commit, err := c.repo.sync(ctx)
+	if err != nil {
+		c.fail("FetchFailed", err)
+		return errors.Wrapf(err, "sync %s", c.flag.Repo)
+	}
+
+	objects, err := c.load()
+	if err != nil {
+		c.fail("LoadFailed", err)
+		return err
+	}
+
+	drifts, errs := c.reconcile(objects)
+
+	// NOTE: Never prune after a partial failure, since the missing resources
+	// may be caused by the failure instead of removal from Git.
+	if len(errs) == 0 && c.flag.Prune && c.flag.SelfHeal {
+		pruned, err := c.pruner.Prune(objects)
+		for _, mo := range pruned {
+			common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+				Infof("%s/%s pruned", mo.Kind(), mo.Name())
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var unhealthy []string
+	if len(errs) == 0 {
+		unhealthy = c.unhealthy(objects)
+	}
+
+	now := time.Now()
+	c.recorder.update(func(s *Status) {
+		s.Commit = commit
+		s.LastSyncTime = &now
+		s.Drifts = drifts
+		s.Unhealthy = unhealthy
+
+		switch {
+		case len(drifts) == 0:
+			s.setCondition(ConditionDrifted, conditionFalse, "NoDrift", "", now)
+		case c.flag.SelfHeal:
+			s.setCondition(ConditionDrifted, conditionTrue, "DriftCorrected",
+				fmt.Sprintf("applied %s", strings.Join(drifts, ", ")), now)
+		default:
+			s.setCondition(ConditionDrifted, conditionTrue, "DriftDetected",
+				fmt.Sprintf("%s differ from Git", strings.Join(drifts, ", ")), now)
+		}
+
+		if len(errs) != 0 {
+			message := joinErrors(errs)
+			s.setCondition(ConditionSynced, conditionFalse, "ApplyFailed", message, now)
+			s.setCondition(ConditionReady, conditionFalse, "ApplyFailed", message, now)
+			return
+		}
+
+		message := fmt.Sprintf("%d resources synced at commit %s", len(objects), commit)
+		s.setCondition(ConditionSynced, conditionTrue, "Synced", message, now)
+		switch {
+		case len(drifts) != 0 && !c.flag.SelfHeal:
+			s.setCondition(ConditionReady, conditionFalse, "DriftDetected", "self heal is disabled", now)
+		case len(unhealthy) != 0:
+			s.setCondition(ConditionReady, conditionFalse, "ResourcesUnhealthy", strings.Join(unhealthy, "; "), now)
+		default:
+			s.setCondition(ConditionReady, conditionTrue, "Synced", message, now)
+		}
+	})
+
+	if len(errs) != 0 {
+		return errors.Errorf("sync commit %s: %s", commit, joinErrors(errs))
+	}
+
+	return nil
+}
+
+// load reads resources under the path of the work tree.
+func (c *Controller) load() ([]meta.MeshObject, error) {
+	vss, err := util.NewVisitorBuilder().
+		FilenameParam(&util.FilenameOptions{
+			Recursive: true,
+			Filenames: []string{filepath.Join(c.flag.WorkDir, c.flag.Path)},
+		}).
+		Do()
+	if err != nil {
+		return nil, errors.Wrap(err, "build visitor failed")
+	}
+
+	var objects []meta.MeshObject
+	for _, vs := range vss {
+		err := vs.Visit(func(mo meta.MeshObject, e error) error {
+			if e != nil {
+				return errors.Wrap(e, "visit failed")
+			}
+
+			if c.pruner != nil && !c.pruner.Matches(mo) {
+				return nil
+			}
+
+			objects = append(objects, mo)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// reconcile applies the objects drifted from live ones if self heal is enabled,
+// it returns the drifted ones in the form of kind/name.
+func (c *Controller) reconcile(objects []meta.MeshObject) ([]string, []error) {
+	var drifts []string
+	var errs []error
+	for _, mo := range objects {
+		id := fmt.Sprintf("%s/%s", mo.Kind(), mo.Name())
+
+		isDrifted := true
+		live, err := get.WrapGetterByMeshObject(mo, c.client, c.flag.Timeout).Get()
+		switch {
+		case meshclient.IsNotFoundError(err):
+			// NOTE: A resource missing from the control plane drifts too.
+		case err != nil:
+			errs = append(errs, errors.Wrapf(err, "get %s", id))
+			continue
+		case len(live) != 0:
+			isDrifted, err = drifted(mo, live[0])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		if !isDrifted {
+			continue
+		}
+
+		drifts = append(drifts, id)
+		if !c.flag.SelfHeal {
+			common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+				Warnf("%s drifted from Git", id)
+			continue
+		}
+
+		err = apply.WrapApplierByMeshObject(mo, c.client, c.flag.Timeout).Apply()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "apply %s", id))
+			continue
+		}
+		common.Logger().With("kind", mo.Kind(), "name", mo.Name()).
+			Infof("%s applied successfully", id)
+	}
+
+	return drifts, errs
+}
+
+// unhealthy returns the resources rejected or failed to propagate by the control
+// plane in the form of kind/name: reason. Kinds whose statuses can't be listed
+// are skipped, e.g. the control plane doesn't report statuses.
+func (c *Controller) unhealthy(objects []meta.MeshObject) []string {
+	statuses := map[string]map[string]*resource.ResourceStatus{}
+	var unhealthy []string
+	for _, mo := range objects {
+		kindStatuses, ok := statuses[mo.Kind()]
+		if !ok {
+			ctx, cancel := context.WithTimeout(context.Background(), c.flag.Timeout)
+			list, err := c.client.V1Alpha1().Status().List(ctx, mo.Kind())
+			cancel()
+			if err != nil && !meshclient.IsNotFoundError(err) && !meshclient.IsUnsupportedError(err) {
+				common.Warnf("list statuses of %s failed: %v", mo.Kind(), err)
+			}
+
+			kindStatuses = map[string]*resource.ResourceStatus{}
+			for _, status := range list {
+				kindStatuses[status.Name] = status
+			}
+			statuses[mo.Kind()] = kindStatuses
+		}
+
+		status := kindStatuses[mo.Name()]
+		if status == nil {
+			continue
+		}
+		if reason := status.Unhealthy(); reason != "" {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s/%s: %s", mo.Kind(), mo.Name(), reason))
+		}
+	}
+	return unhealthy
+}
+
+func (c *Controller) fail(reason string, err error) {
+	now := time.Now()
+	c.recorder.update(func(s *Status) {
+		s.setCondition(ConditionSynced, conditionFalse, reason, err.Error(), now)
+		s.setCondition(ConditionReady, conditionFalse, reason, err.Error(), now)
+	})
+}
+
+func (c *Controller) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/status", c.handleStatus)
+	mux.HandleFunc("/webhook", c.handleWebhook)
+	return mux
+}
+
+func (c *Controller) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.recorder.get())
+}
+
+func (c *Controller) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !verifyWebhook(c.flag.WebhookSecret, r.Header, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// NOTE: A pending trigger covers this one.
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verifyWebhook verifies the signature of GitHub (and Gitea) webhooks,
+// or the token of GitLab webhooks.
+func verifyWebhook(secret string, header http.Header, body []byte) bool {
+	if secret == "" {
+		return true
+	}
+
+	if token := header.Get(gitlabTokenHeader); token != "" {
+		return hmac.Equal([]byte(token), []byte(secret))
+	}
+
+	signature := header.Get(githubSignatureHeader)
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(strings.TrimPrefix(signature, "sha256=")), []byte(expected))
+}
+
+func joinErrors(errs []error) string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
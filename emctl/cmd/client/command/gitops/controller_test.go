@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+func newTestController(t *testing.T, reactorType string, selfHeal bool) *Controller {
+	c, err := NewController(&flags.GitOpsServe{
+		AdminGlobal: &flags.AdminGlobal{Timeout: time.Second},
+		Repo:        "https://github.com/megaease/easemesh-gitops.git",
+		Branch:      "main",
+		Path:        ".",
+		Interval:    time.Minute,
+		SelfHeal:    selfHeal,
+	}, meshclient.NewFakeClient(reactorType))
+	if err != nil {
+		t.Fatalf("new controller failed: %v", err)
+	}
+	return c
+}
+
+func TestReconcile(t *testing.T) {
+	reactorType := "__gitops_reconcile_reactor"
+	requests := 0
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("*", resource.KindTenant, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			requests++
+			return true, []meta.MeshObject{newTenant(resource.DefaultAPIVersion, "pet", "pet shop", nil)}, nil
+		}).
+		AddReactor("*", resource.KindResourceMeta, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		AddReactor("*", resource.KindRevision, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, nil, nil
+		}).
+		Added()
+
+	objects := []meta.MeshObject{newTenant(resource.DefaultAPIVersion, "pet", "pet store", nil)}
+
+	c := newTestController(t, reactorType, false)
+	drifts, errs := c.reconcile(objects)
+	if len(errs) != 0 {
+		t.Fatalf("reconcile failed: %v", errs)
+	}
+	if len(drifts) != 1 || drifts[0] != "Tenant/pet" {
+		t.Fatalf("expect Tenant/pet drifted, but got %v", drifts)
+	}
+	if requests != 1 {
+		t.Fatalf("drift should only be reported without self heal, but got %d requests", requests)
+	}
+
+	requests = 0
+	c = newTestController(t, reactorType, true)
+	_, errs = c.reconcile(objects)
+	if len(errs) != 0 {
+		t.Fatalf("reconcile failed: %v", errs)
+	}
+	if requests != 2 {
+		t.Fatalf("drift should be applied with self heal, but got %d requests", requests)
+	}
+
+	requests = 0
+	drifts, _ = c.reconcile([]meta.MeshObject{newTenant(resource.DefaultAPIVersion, "pet", "pet shop", nil)})
+	if len(drifts) != 0 || requests != 1 {
+		t.Fatalf("expect nothing drifted and applied, but got %v with %d requests", drifts, requests)
+	}
+
+	_, err := NewController(&flags.GitOpsServe{Interval: time.Minute}, meshclient.NewFakeClient(reactorType))
+	if err == nil {
+		t.Fatalf("controller without repo should fail")
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	s := &Status{}
+	begin := time.Now()
+	s.setCondition(ConditionReady, conditionFalse, "FetchFailed", "timeout", begin)
+	s.setCondition(ConditionReady, conditionFalse, "ApplyFailed", "bad spec", begin.Add(time.Minute))
+
+	c := s.condition(ConditionReady)
+	if len(s.Conditions) != 1 || c.Reason != "ApplyFailed" {
+		t.Fatalf("expect one condition updated, but got %+v", s.Conditions)
+	}
+	if !c.LastTransitionTime.Equal(begin) {
+		t.Fatalf("transition time should not change if the status is the same")
+	}
+
+	s.setCondition(ConditionReady, conditionTrue, "Synced", "", begin.Add(2*time.Minute))
+	if c := s.condition(ConditionReady); !c.LastTransitionTime.Equal(begin.Add(2 * time.Minute)) {
+		t.Fatalf("transition time should change with the status")
+	}
+
+	if s.condition(ConditionDrifted) != nil {
+		t.Fatalf("condition %s should not exist", ConditionDrifted)
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	for _, c := range []struct {
+		secret string
+		header http.Header
+		valid  bool
+	}{
+		{secret: "", header: http.Header{}, valid: true},
+		{secret: "s3cret", header: http.Header{githubSignatureHeader: {signature}}, valid: true},
+		{secret: "other", header: http.Header{githubSignatureHeader: {signature}}, valid: false},
+		{secret: "s3cret", header: http.Header{githubSignatureHeader: {"sha1=abc"}}, valid: false},
+		{secret: "s3cret", header: http.Header{gitlabTokenHeader: {"s3cret"}}, valid: true},
+		{secret: "s3cret", header: http.Header{gitlabTokenHeader: {"guess"}}, valid: false},
+		{secret: "s3cret", header: http.Header{}, valid: false},
+	} {
+		if valid := verifyWebhook(c.secret, c.header, body); valid != c.valid {
+			t.Fatalf("secret %q header %v: expect %v but got %v", c.secret, c.header, c.valid, valid)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := newTestController(t, "__gitops_handler_reactor", true)
+	c.flag.WebhookSecret = "s3cret"
+	c.fail("FetchFailed", http.ErrHandlerTimeout)
+
+	server := httptest.NewServer(c.handler())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/webhook", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("post webhook failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unsigned webhook should be rejected, but got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/webhook", strings.NewReader("{}"))
+	req.Header.Set(gitlabTokenHeader, "s3cret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post webhook failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expect webhook accepted, but got %d", resp.StatusCode)
+	}
+	select {
+	case <-c.trigger:
+	default:
+		t.Fatalf("webhook should trigger a sync")
+	}
+
+	resp, err = http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("get status failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	status := Status{}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	if err != nil {
+		t.Fatalf("decode status failed: %v", err)
+	}
+	if ready := status.condition(ConditionReady); ready == nil || ready.Status != conditionFalse || ready.Reason != "FetchFailed" {
+		t.Fatalf("expect not ready because of fetch failure, but got %+v", status.Conditions)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"reflect"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// drifted reports whether the live object differs from the desired one in Git.
+// Both are compared in the normalized YAML form, in which empty fields are
+// dropped, since the control plane may omit or fill in them.
+func drifted(desired, live meta.MeshObject) (bool, error) {
+	d, err := normalize(desired)
+	if err != nil {
+		return false, err
+	}
+
+	l, err := normalize(live)
+	if err != nil {
+		return false, err
+	}
+
+	return !reflect.DeepEqual(d, l), nil
+}
+
+func normalize(object meta.MeshObject) (interface{}, error) {
+	buff, err := yaml.Marshal(object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal %s/%s", object.Kind(), object.Name())
+	}
+
+	var v interface{}
+	err = yaml.Unmarshal(buff, &v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s/%s", object.Kind(), object.Name())
+	}
+
+	v = dropEmpty(v)
+	if m, ok := v.(map[interface{}]interface{}); ok {
+		// NOTE: The control plane serves objects of its own API version.
+		delete(m, "apiVersion")
+		// NOTE: Resource versions of live objects are never in Git.
+		if metadata, ok := m["metadata"].(map[interface{}]interface{}); ok {
+			delete(metadata, "resourceVersion")
+		}
+	}
+
+	return v, nil
+}
+
+// dropEmpty removes nil, empty strings, empty maps and empty lists recursively.
+func dropEmpty(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		for k, item := range value {
+			item = dropEmpty(item)
+			if isEmpty(item) {
+				delete(value, k)
+				continue
+			}
+			value[k] = item
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = dropEmpty(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+func isEmpty(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case map[interface{}]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
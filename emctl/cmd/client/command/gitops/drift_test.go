@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func newTenant(apiVersion, name, description string, services []string) *resource.Tenant {
+	return &resource.Tenant{
+		MeshResource: resource.NewTenantResource(apiVersion, name),
+		Spec: &resource.TenantSpec{
+			Services:    services,
+			Description: description,
+		},
+	}
+}
+
+func TestDrifted(t *testing.T) {
+	versioned := newTenant(resource.DefaultAPIVersion, "pet", "pet store", nil)
+	versioned.SetResourceVersion("3")
+
+	for _, c := range []struct {
+		name    string
+		desired *resource.Tenant
+		live    *resource.Tenant
+		drifted bool
+	}{
+		{
+			name:    "same",
+			desired: newTenant(resource.DefaultAPIVersion, "pet", "pet store", []string{"order"}),
+			live:    newTenant(resource.DefaultAPIVersion, "pet", "pet store", []string{"order"}),
+		},
+		{
+			name:    "empty fields",
+			desired: newTenant(resource.DefaultAPIVersion, "pet", "", nil),
+			live:    newTenant(resource.DefaultAPIVersion, "pet", "", []string{}),
+		},
+		{
+			name:    "api version",
+			desired: newTenant("mesh.megaease.com/v1alpha1", "pet", "pet store", nil),
+			live:    newTenant("v1", "pet", "pet store", nil),
+		},
+		{
+			name:    "resource version",
+			desired: newTenant(resource.DefaultAPIVersion, "pet", "pet store", nil),
+			live:    versioned,
+		},
+		{
+			name:    "changed field",
+			desired: newTenant(resource.DefaultAPIVersion, "pet", "pet store", nil),
+			live:    newTenant(resource.DefaultAPIVersion, "pet", "pet shop", nil),
+			drifted: true,
+		},
+		{
+			name:    "extra item",
+			desired: newTenant(resource.DefaultAPIVersion, "pet", "", []string{"order"}),
+			live:    newTenant(resource.DefaultAPIVersion, "pet", "", []string{"order", "delivery"}),
+			drifted: true,
+		},
+	} {
+		drifted, err := drifted(c.desired, c.live)
+		if err != nil {
+			t.Fatalf("%s: compare failed: %v", c.name, err)
+		}
+		if drifted != c.drifted {
+			t.Fatalf("%s: expect drifted %v but got %v", c.name, c.drifted, drifted)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// repository is a local shallow clone of a branch of a Git repository,
+// it shells out to git so that every protocol and credential helper
+// supported by git works as well.
+type repository struct {
+	url    string
+	branch string
+	dir    string
+}
+
+func newRepository(url, branch, dir string) *repository {
+	return &repository{
+		url:    url,
+		branch: branch,
+		dir:    dir,
+	}
+}
+
+// sync clones or fetches the branch and resets the work tree to it,
+// it returns the commit of the work tree.
+func (r *repository) sync(ctx context.Context) (string, error) {
+	_, err := os.Stat(filepath.Join(r.dir, ".git"))
+	switch {
+	case os.IsNotExist(err):
+		err = os.MkdirAll(r.dir, 0755)
+		if err != nil {
+			return "", errors.Wrapf(err, "create dir %s", r.dir)
+		}
+		_, err = r.git(ctx, "clone", "--quiet", "--depth", "1", "--single-branch",
+			"--branch", r.branch, r.url, ".")
+		if err != nil {
+			return "", err
+		}
+	case err != nil:
+		return "", errors.Wrapf(err, "stat dir %s", r.dir)
+	default:
+		_, err = r.git(ctx, "fetch", "--quiet", "--depth", "1", "origin", r.branch)
+		if err != nil {
+			return "", err
+		}
+		// NOTE: Reset hard to drop anything not in Git, the work tree
+		// is owned by the controller.
+		_, err = r.git(ctx, "reset", "--quiet", "--hard", "FETCH_HEAD")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return r.git(ctx, "rev-parse", "HEAD")
+}
+
+func (r *repository) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+// RunServe is the entrypoint of the emctl gitops serve subcommand
+func RunServe(cmd *cobra.Command, flag *flags.GitOpsServe) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	controller, err := NewController(flag, meshclient.New(flag.Server))
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	common.Logger().With("repo", flag.Repo, "branch", flag.Branch, "path", flag.Path).
+		Infof("syncing %s (branch %s, path %s) every %s, listening on %s",
+			flag.Repo, flag.Branch, flag.Path, flag.Interval, flag.ListenAddress)
+
+	err = controller.Run(ctx)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+}
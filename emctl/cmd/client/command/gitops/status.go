@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// ConditionReady means the last sync succeeded and nothing drifts.
+	ConditionReady = "Ready"
+	// ConditionSynced means resources in Git have been applied.
+	ConditionSynced = "Synced"
+	// ConditionDrifted means live resources differ from the ones in Git.
+	ConditionDrifted = "Drifted"
+
+	conditionTrue  = "True"
+	conditionFalse = "False"
+)
+
+type (
+	// Condition is a condition of the sync status, in the same
+	// shape of conditions of Kubernetes resources.
+	Condition struct {
+		Type               string    `json:"type"`
+		Status             string    `json:"status"`
+		Reason             string    `json:"reason,omitempty"`
+		Message            string    `json:"message,omitempty"`
+		LastTransitionTime time.Time `json:"lastTransitionTime"`
+	}
+
+	// Status is the sync status of the GitOps controller.
+	Status struct {
+		Repo         string     `json:"repo"`
+		Branch       string     `json:"branch"`
+		Path         string     `json:"path"`
+		Commit       string     `json:"commit,omitempty"`
+		LastSyncTime *time.Time `json:"lastSyncTime,omitempty"`
+		Drifts       []string   `json:"drifts,omitempty"`
+		// Unhealthy are resources rejected or failed to propagate by the control plane.
+		Unhealthy  []string    `json:"unhealthy,omitempty"`
+		Conditions []Condition `json:"conditions"`
+	}
+
+	statusRecorder struct {
+		mutex  sync.RWMutex
+		status Status
+	}
+)
+
+// setCondition updates the condition of the type, the transition time
+// only changes if the status changes.
+func (s *Status) setCondition(conditionType, status, reason, message string, now time.Time) {
+	for i := range s.Conditions {
+		c := &s.Conditions[i]
+		if c.Type != conditionType {
+			continue
+		}
+		if c.Status != status {
+			c.LastTransitionTime = now
+		}
+		c.Status, c.Reason, c.Message = status, reason, message
+		return
+	}
+
+	s.Conditions = append(s.Conditions, Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: now,
+	})
+}
+
+// condition returns the condition of the type, nil if not existed.
+func (s *Status) condition(conditionType string) *Condition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func (r *statusRecorder) update(fn func(s *Status)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fn(&r.status)
+}
+
+func (r *statusRecorder) get() Status {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	status := r.status
+	status.Drifts = append([]string(nil), r.status.Drifts...)
+	status.Conditions = append([]Condition(nil), r.status.Conditions...)
+	return status
+}
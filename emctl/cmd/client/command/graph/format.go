@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// label describes the calls of the edge, e.g. "120 req, 2 err".
+func (e *Edge) label() string {
+	label := strconv.FormatFloat(e.Requests, 'f', -1, 64) + " req"
+	if e.Errors > 0 {
+		label += ", " + strconv.FormatFloat(e.Errors, 'f', -1, 64) + " err"
+	}
+	return label
+}
+
+// tenantGroups groups nodes by tenants in order, nodes are sorted by
+// tenants already.
+func tenantGroups(nodes []*Node) [][]*Node {
+	groups := [][]*Node{}
+	for i, n := range nodes {
+		if i == 0 || n.Tenant != nodes[i-1].Tenant {
+			groups = append(groups, []*Node{})
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], n)
+	}
+	return groups
+}
+
+// printDOT prints the graph in Graphviz DOT, services are clustered by
+// tenants, and edges with errors are red.
+func printDOT(w io.Writer, g *Graph) {
+	fmt.Fprintln(w, "digraph easemesh {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+
+	for i, group := range tenantGroups(g.Nodes) {
+		indent := "  "
+		if group[0].Tenant != "" {
+			fmt.Fprintf(w, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(w, "    label=%s;\n", strconv.Quote(group[0].Tenant))
+			indent = "    "
+		}
+		for _, n := range group {
+			fmt.Fprintf(w, "%s%s;\n", indent, strconv.Quote(n.Name))
+		}
+		if group[0].Tenant != "" {
+			fmt.Fprintln(w, "  }")
+		}
+	}
+
+	for _, e := range g.Edges {
+		attrs := "label=" + strconv.Quote(e.label())
+		if e.Errors > 0 {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(w, "  %s -> %s [%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), attrs)
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+// printMermaid prints the graph in a Mermaid flowchart, services are in
+// subgraphs of tenants, and edges with errors are red.
+func printMermaid(w io.Writer, g *Graph) {
+	fmt.Fprintln(w, "flowchart LR")
+
+	// NOTE: Names of services may contain characters invalid in ids of Mermaid.
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.Name] = fmt.Sprintf("n%d", i)
+	}
+
+	for i, group := range tenantGroups(g.Nodes) {
+		indent := "  "
+		if group[0].Tenant != "" {
+			fmt.Fprintf(w, "  subgraph t%d [%s]\n", i, strconv.Quote(group[0].Tenant))
+			indent = "    "
+		}
+		for _, n := range group {
+			fmt.Fprintf(w, "%s%s[%s]\n", indent, ids[n.Name], strconv.Quote(n.Name))
+		}
+		if group[0].Tenant != "" {
+			fmt.Fprintln(w, "  end")
+		}
+	}
+
+	for i, e := range g.Edges {
+		fmt.Fprintf(w, "  %s -->|%s| %s\n", ids[e.From], strconv.Quote(e.label()), ids[e.To])
+		if e.Errors > 0 {
+			fmt.Fprintf(w, "  linkStyle %d stroke:red\n", i)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// egressPipelinePrefix prefixes the egress pipelines of sidecars,
+	// a sidecar creates one for every service its application calls.
+	egressPipelinePrefix = "sidecar-egress-pipeline-"
+
+	// FormatDOT is the Graphviz DOT format.
+	FormatDOT = "dot"
+	// FormatMermaid is the Mermaid flowchart format.
+	FormatMermaid = "mermaid"
+	// FormatJSON is the JSON format.
+	FormatJSON = "json"
+)
+
+type (
+	// Node is a mesh service in the graph.
+	Node struct {
+		Name   string `json:"name"`
+		Tenant string `json:"tenant,omitempty"`
+	}
+
+	// Edge is the calls from a service to another one.
+	Edge struct {
+		From     string  `json:"from"`
+		To       string  `json:"to"`
+		Requests float64 `json:"requests"`
+		Errors   float64 `json:"errors"`
+	}
+
+	// Graph is the call graph of mesh services.
+	Graph struct {
+		Tenant string  `json:"tenant,omitempty"`
+		Window string  `json:"window,omitempty"`
+		Nodes  []*Node `json:"nodes"`
+		Edges  []*Edge `json:"edges"`
+	}
+
+	edgeKey struct {
+		from string
+		to   string
+	}
+)
+
+// RunGraph is the entrypoint of the emctl graph sub command
+func RunGraph(cmd *cobra.Command, flag *flags.Graph) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case FormatDOT, FormatMermaid, FormatJSON:
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support dot, mermaid, json)",
+			flag.OutputFormat)
+	}
+	if flag.Window < 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "--window must not be negative, got %s", flag.Window)
+	}
+
+	tenants, members, err := listServices(meshclient.New(flag.Server), flag.Timeout)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	if flag.Tenant != "" && !hasTenant(tenants, flag.Tenant) {
+		common.Warnf("no service registered in tenant %s", flag.Tenant)
+	}
+
+	edges, err := collect(telemetry.New(flag.Server, flag.Timeout), members, flag.Window, time.Sleep)
+	if err != nil {
+		common.ExitWithErrorf("collect telemetry of sidecars failed: %w", err)
+	}
+
+	g := build(edges, tenants, flag.Tenant)
+	if flag.Window > 0 {
+		g.Window = flag.Window.String()
+	}
+
+	switch flag.OutputFormat {
+	case FormatDOT:
+		printDOT(os.Stdout, g)
+	case FormatMermaid:
+		printMermaid(os.Stdout, g)
+	case FormatJSON:
+		buff, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal graph failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
+
+// listServices returns tenants of services keyed by service name, and
+// services of sidecars keyed by instance ID which is the member name of
+// the sidecar.
+func listServices(meshClient meshclient.MeshClient, timeout time.Duration) (map[string]string, map[string]string, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	services, err := meshClient.V1Alpha1().Service().List(ctx)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return nil, nil, errors.Wrap(err, "list services")
+	}
+	tenants := map[string]string{}
+	for _, s := range services {
+		if s.Spec != nil {
+			tenants[s.Name()] = s.Spec.RegisterTenant
+		}
+	}
+
+	instances, err := meshClient.V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return nil, nil, errors.Wrap(err, "list service instances")
+	}
+	members := map[string]string{}
+	for _, instance := range instances {
+		if instance.Spec != nil {
+			members[instance.Spec.InstanceID] = instance.Spec.ServiceName
+		}
+	}
+
+	return tenants, members, nil
+}
+
+func hasTenant(tenants map[string]string, tenant string) bool {
+	for _, t := range tenants {
+		if t == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// collect returns calls between services reported by sidecars. The counts
+// of sidecars are accumulated since they started, so calls in the window
+// are the differences of two snapshots, zero window means all calls.
+func collect(tc telemetry.Client, members map[string]string, window time.Duration, sleep func(time.Duration)) (map[edgeKey]*Edge, error) {
+	before, err := snapshot(tc, members)
+	if err != nil || window == 0 {
+		return before, err
+	}
+
+	sleep(window)
+	after, err := snapshot(tc, members)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, e := range after {
+		b := before[key]
+		// NOTE: The counts restart from zero if the sidecar restarted.
+		if b != nil && b.Requests <= e.Requests {
+			e.Requests -= b.Requests
+			e.Errors -= b.Errors
+			if e.Errors < 0 {
+				e.Errors = 0
+			}
+		}
+		if e.Requests == 0 {
+			delete(after, key)
+		}
+	}
+	return after, nil
+}
+
+func snapshot(tc telemetry.Client, members map[string]string) (map[edgeKey]*Edge, error) {
+	objects, err := tc.Objects()
+	if err != nil {
+		return nil, err
+	}
+
+	edges := map[edgeKey]*Edge{}
+	for _, statuses := range objects {
+		for member, status := range statuses {
+			from := members[member]
+			if from == "" {
+				from = member
+			}
+			for to, e := range egressCounts(status) {
+				key := edgeKey{from: from, to: to}
+				if edges[key] == nil {
+					edges[key] = &Edge{From: from, To: to}
+				}
+				edges[key].Requests += e.Requests
+				edges[key].Errors += e.Errors
+			}
+		}
+	}
+	return edges, nil
+}
+
+// egressCounts sums counts of requests and errors in the statistics of
+// egress pipelines, keyed by the called services.
+func egressCounts(status interface{}) map[string]*Edge {
+	counts := map[string]*Edge{}
+
+	var walk func(value interface{}, to string)
+	walk = func(value interface{}, to string) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for k, v := range value {
+				if n, ok := v.(float64); ok && to != "" {
+					switch k {
+					case "count":
+						counts[to].Requests += n
+					case "errCount":
+						counts[to].Errors += n
+					}
+					continue
+				}
+
+				next := to
+				if strings.HasPrefix(k, egressPipelinePrefix) {
+					next = strings.TrimPrefix(k, egressPipelinePrefix)
+					if counts[next] == nil {
+						counts[next] = &Edge{To: next}
+					}
+				}
+				walk(v, next)
+			}
+		case []interface{}:
+			for _, v := range value {
+				walk(v, to)
+			}
+		}
+	}
+	walk(status, "")
+
+	for to, e := range counts {
+		if e.Requests == 0 {
+			delete(counts, to)
+		}
+	}
+	return counts
+}
+
+// build builds the graph of services in the tenant, with services of
+// other tenants calling or called by them, empty tenant means all.
+func build(edges map[edgeKey]*Edge, tenants map[string]string, tenant string) *Graph {
+	g := &Graph{Tenant: tenant, Nodes: []*Node{}, Edges: []*Edge{}}
+
+	names := map[string]struct{}{}
+	for name, t := range tenants {
+		if tenant == "" || t == tenant {
+			names[name] = struct{}{}
+		}
+	}
+	for _, e := range edges {
+		if tenant != "" && tenants[e.From] != tenant && tenants[e.To] != tenant {
+			continue
+		}
+		g.Edges = append(g.Edges, e)
+		names[e.From] = struct{}{}
+		names[e.To] = struct{}{}
+	}
+
+	for name := range names {
+		g.Nodes = append(g.Nodes, &Node{Name: name, Tenant: tenants[name]})
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Tenant != g.Nodes[j].Tenant {
+			return g.Nodes[i].Tenant < g.Nodes[j].Tenant
+		}
+		return g.Nodes[i].Name < g.Nodes[j].Name
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+
+	return g
+}
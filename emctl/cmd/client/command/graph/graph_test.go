@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeStatusClient struct {
+	snapshots []string
+}
+
+func (c *fakeStatusClient) Objects() (map[string]map[string]interface{}, error) {
+	objects := map[string]map[string]interface{}{}
+	err := json.Unmarshal([]byte(c.snapshots[0]), &objects)
+	c.snapshots = c.snapshots[1:]
+	return objects, err
+}
+
+func sidecarStatus(orderToDelivery, orderErrors, orderToPayment, petToOrder int) string {
+	return `{
+  "easemesh-controller": {
+    "order-service-6b7d9": {
+      "sidecar-egress-pipeline-delivery-service": {
+        "filters": {"proxy": {"mainPool": {"count": ` + strconv.Itoa(orderToDelivery) + `, "errCount": ` + strconv.Itoa(orderErrors) + `, "m1": 0.5}}}
+      },
+      "sidecar-egress-pipeline-payment-service": {
+        "filters": {"proxy": {"mainPool": {"count": ` + strconv.Itoa(orderToPayment) + `, "errCount": 0}}}
+      },
+      "sidecar-ingress-pipeline-order-service": {
+        "filters": {"proxy": {"mainPool": {"count": 1000, "errCount": 0}}}
+      }
+    },
+    "pet-service-5f8c4": {
+      "sidecar-egress-pipeline-order-service": {
+        "filters": {"proxy": {"mainPool": {"count": ` + strconv.Itoa(petToOrder) + `, "errCount": 0}}}
+      }
+    },
+    "easemesh-control-plane-0": {}
+  }
+}`
+}
+
+var (
+	testMembers = map[string]string{
+		"order-service-6b7d9": "order-service",
+		"pet-service-5f8c4":   "pet-service",
+	}
+	testTenants = map[string]string{
+		"order-service":    "shop",
+		"delivery-service": "shop",
+		"payment-service":  "finance",
+		"pet-service":      "pet",
+		"idle-service":     "pet",
+	}
+)
+
+func TestCollect(t *testing.T) {
+	sc := &fakeStatusClient{snapshots: []string{sidecarStatus(100, 2, 10, 5)}}
+	edges, err := collect(sc, testMembers, 0, nil)
+	if err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+	if len(edges) != 3 {
+		t.Fatalf("expected 3 edges, got %d", len(edges))
+	}
+	e := edges[edgeKey{from: "order-service", to: "delivery-service"}]
+	if e == nil || e.Requests != 100 || e.Errors != 2 {
+		t.Fatalf("unexpected edge %+v", e)
+	}
+
+	sc = &fakeStatusClient{snapshots: []string{sidecarStatus(100, 2, 10, 5), sidecarStatus(130, 3, 10, 2)}}
+	var slept time.Duration
+	edges, err = collect(sc, testMembers, time.Minute, func(d time.Duration) { slept = d })
+	if err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+	if slept != time.Minute {
+		t.Fatalf("expected sleeping 1m, got %s", slept)
+	}
+	e = edges[edgeKey{from: "order-service", to: "delivery-service"}]
+	if e == nil || e.Requests != 30 || e.Errors != 1 {
+		t.Fatalf("unexpected edge in window %+v", e)
+	}
+	if _, ok := edges[edgeKey{from: "order-service", to: "payment-service"}]; ok {
+		t.Fatalf("expected no calls to payment-service in window")
+	}
+	// NOTE: The sidecar of pet-service restarted.
+	e = edges[edgeKey{from: "pet-service", to: "order-service"}]
+	if e == nil || e.Requests != 2 {
+		t.Fatalf("unexpected edge of restarted sidecar %+v", e)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	sc := &fakeStatusClient{snapshots: []string{sidecarStatus(100, 2, 10, 5)}}
+	edges, _ := collect(sc, testMembers, 0, nil)
+
+	g := build(edges, testTenants, "")
+	if len(g.Nodes) != 5 || len(g.Edges) != 3 {
+		t.Fatalf("expected 5 nodes and 3 edges, got %d nodes and %d edges", len(g.Nodes), len(g.Edges))
+	}
+	if g.Nodes[0].Name != "payment-service" || g.Edges[0].From != "order-service" || g.Edges[0].To != "delivery-service" {
+		t.Fatalf("unexpected order of nodes or edges: %+v %+v", g.Nodes[0], g.Edges[0])
+	}
+
+	g = build(edges, testTenants, "pet")
+	names := []string{}
+	for _, n := range g.Nodes {
+		names = append(names, n.Name)
+	}
+	if strings.Join(names, ",") != "idle-service,pet-service,order-service" {
+		t.Fatalf("unexpected nodes of tenant pet: %v", names)
+	}
+	if len(g.Edges) != 1 || g.Edges[0].From != "pet-service" {
+		t.Fatalf("unexpected edges of tenant pet: %+v", g.Edges)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	sc := &fakeStatusClient{snapshots: []string{sidecarStatus(100, 2, 10, 5)}}
+	edges, _ := collect(sc, testMembers, 0, nil)
+	g := build(edges, testTenants, "")
+
+	buff := &bytes.Buffer{}
+	printDOT(buff, g)
+	dot := buff.String()
+	for _, s := range []string{
+		`digraph easemesh {`,
+		`label="shop";`,
+		`"order-service" -> "delivery-service" [label="100 req, 2 err", color=red];`,
+		`"order-service" -> "payment-service" [label="10 req"];`,
+	} {
+		if !strings.Contains(dot, s) {
+			t.Fatalf("expected %s in DOT:\n%s", s, dot)
+		}
+	}
+
+	buff.Reset()
+	printMermaid(buff, g)
+	mermaid := buff.String()
+	for _, s := range []string{
+		`flowchart LR`,
+		`subgraph t0 ["finance"]`,
+		`n0["payment-service"]`,
+		`-->|"100 req, 2 err"|`,
+		`linkStyle 0 stroke:red`,
+	} {
+		if !strings.Contains(mermaid, s) {
+			t.Fatalf("expected %s in Mermaid:\n%s", s, mermaid)
+		}
+	}
+}
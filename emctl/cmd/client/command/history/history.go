@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RunHistory is the entrypoint of the emctl history sub command
+func RunHistory(cmd *cobra.Command, flag *flags.History) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	kind, name := kindNameFromArgs(cmd)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	revisions, err := meshclient.New(flag.Server).V1Alpha1().Revision().List(ctx, kind, name)
+	if err != nil {
+		common.ExitWithErrorf("get history of %s/%s failed: %w", kind, name, err)
+	}
+
+	objects := make([]meta.MeshObject, len(revisions))
+	for i := range revisions {
+		objects[i] = revisions[i]
+	}
+
+	printer.New(flag.OutputFormat).PrintObjects(objects)
+}
+
+// RunRollback is the entrypoint of the emctl rollback sub command
+func RunRollback(cmd *cobra.Command, flag *flags.Rollback) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	kind, name := kindNameFromArgs(cmd)
+
+	revision, err := Rollback(meshclient.New(flag.Server), kind, name, flag.ToRevision, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("rollback %s/%s failed: %w", kind, name, err)
+	}
+
+	common.Logger().With("kind", kind, "name", name, "revision", revision).
+		Infof("%s/%s rolled back to revision %d successfully", kind, name, revision)
+}
+
+// Rollback rollbacks the resource to the revision, the previous revision is
+// chosen if the revision is zero. It returns the revision rolled back to.
+func Rollback(client meshclient.MeshClient, kind, name string, revision int64, timeout time.Duration) (int64, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	if revision < 0 {
+		return 0, errors.Errorf("invalid revision %d", revision)
+	}
+
+	if revision == 0 {
+		revisions, err := client.V1Alpha1().Revision().List(ctx, kind, name)
+		if err != nil {
+			return 0, errors.Wrap(err, "list revisions")
+		}
+		previous, err := previousRevision(revisions)
+		if err != nil {
+			return 0, err
+		}
+		revision = previous
+	} else {
+		_, err := client.V1Alpha1().Revision().Get(ctx, kind, name, revision)
+		if err != nil {
+			return 0, errors.Wrapf(err, "get revision %d", revision)
+		}
+	}
+
+	err := client.V1Alpha1().Revision().Rollback(ctx, kind, name, revision)
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, nil
+}
+
+// previousRevision returns the revision before the latest one.
+func previousRevision(revisions []*resource.Revision) (int64, error) {
+	var latest, previous int64
+	for _, r := range revisions {
+		if r.Spec == nil {
+			continue
+		}
+		switch {
+		case r.Spec.Revision > latest:
+			previous, latest = latest, r.Spec.Revision
+		case r.Spec.Revision > previous && r.Spec.Revision < latest:
+			previous = r.Spec.Revision
+		}
+	}
+
+	if previous == 0 {
+		return 0, errors.Errorf("no previous revision")
+	}
+
+	return previous, nil
+}
+
+func kindNameFromArgs(cmd *cobra.Command) (string, string) {
+	args := cmd.Flags().Args()
+	if len(args) != 2 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <resource kind> <resource name>")
+	}
+	return util.AdaptCommandKind(args[0]), args[1]
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+func newRevisions(revisions ...int64) []meta.MeshObject {
+	objects := []meta.MeshObject{}
+	for _, r := range revisions {
+		objects = append(objects, resource.ToRevision(&resource.RevisionObject{
+			Kind:     resource.KindLoadBalance,
+			Name:     "service-001",
+			Revision: r,
+		}))
+	}
+	return objects
+}
+
+func TestPreviousRevision(t *testing.T) {
+	revisions := []*resource.Revision{}
+	for _, o := range newRevisions(3, 1, 5, 4) {
+		revisions = append(revisions, o.(*resource.Revision))
+	}
+
+	previous, err := previousRevision(revisions)
+	if err != nil {
+		t.Fatalf("previous revision failed: %v", err)
+	}
+	if previous != 4 {
+		t.Fatalf("previous revision should be 4, but got %d", previous)
+	}
+
+	_, err = previousRevision(revisions[1:2])
+	if err == nil {
+		t.Fatalf("previous revision of single revision should fail")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	reactorType := "__reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindRevision, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, newRevisions(1, 2, 3), nil
+		}).
+		AddReactor("get", resource.KindRevision, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, newRevisions(1), nil
+		}).
+		Added()
+	client := meshclient.NewFakeClient(reactorType)
+
+	revision, err := Rollback(client, resource.KindLoadBalance, "service-001", 0, time.Second)
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if revision != 2 {
+		t.Fatalf("rollback should choose the previous revision 2, but got %d", revision)
+	}
+
+	revision, err = Rollback(client, resource.KindLoadBalance, "service-001", 1, time.Second)
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if revision != 1 {
+		t.Fatalf("rollback should choose revision 1, but got %d", revision)
+	}
+
+	_, err = Rollback(client, resource.KindLoadBalance, "service-001", -1, time.Second)
+	if err == nil {
+		t.Fatalf("rollback to negative revision should fail")
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+// RunCachePurge is the entrypoint of the emctl ingress cache purge sub command
+func RunCachePurge(cmd *cobra.Command, flag *flags.IngressCachePurge) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	purge := &resource.IngressCachePurge{Host: flag.Host, Path: flag.Path}
+	err := purge.Validate()
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "%v", err)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+
+	result, err := meshclient.New(flag.Server).V1Alpha1().IngressCache().Purge(ctx, purge)
+	if err != nil {
+		common.ExitWithErrorf("purge cached responses of %s%s failed: %w", purge.Host, purge.Path, err)
+	}
+
+	if result.Purged == 0 {
+		fmt.Printf("No cached response of %s%s found\n", purge.Host, purge.Path)
+		return
+	}
+	fmt.Printf("Purged %d cached responses of %s%s\n", result.Purged, purge.Host, purge.Path)
+}
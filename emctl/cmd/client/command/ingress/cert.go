@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// CertStatus is the status of the certificate of an ingress host.
+type CertStatus struct {
+	resource.IngressCertificate `yaml:",inline"`
+	// ExpiresIn is the duration until the certificate in use expires,
+	// it's negative if it has expired, empty if there is no certificate.
+	ExpiresIn string `yaml:"expiresIn,omitempty" json:"expiresIn,omitempty"`
+}
+
+// RunCertStatus is the entrypoint of the emctl ingress cert status sub command
+func RunCertStatus(cmd *cobra.Command, flag *flags.IngressCertStatus) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+
+	certificates, err := meshclient.New(flag.Server).V1Alpha1().IngressCertificate().List(ctx)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		common.ExitWithErrorf("list ingress certificates failed: %w", err)
+	}
+
+	statuses := certStatuses(certificates, time.Now(), flag.Ingress)
+
+	switch flag.OutputFormat {
+	case "table":
+		printCertStatuses(os.Stdout, statuses)
+	case "yaml":
+		buff, err := yaml.Marshal(statuses)
+		if err != nil {
+			common.ExitWithErrorf("marshal certificate statuses failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal certificate statuses failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
+
+// certStatuses returns statuses of certificates sorted by hosts, empty
+// ingress means all ingresses.
+func certStatuses(certificates []*resource.IngressCertificate, now time.Time, ingress string) []*CertStatus {
+	statuses := []*CertStatus{}
+	for _, certificate := range certificates {
+		if ingress != "" && certificate.Ingress != ingress {
+			continue
+		}
+
+		status := &CertStatus{IngressCertificate: *certificate}
+		if notAfter, err := time.Parse(time.RFC3339, certificate.NotAfter); err == nil {
+			status.ExpiresIn = notAfter.Sub(now).Truncate(time.Minute).String()
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Host < statuses[j].Host
+	})
+	return statuses
+}
+
+func printCertStatuses(w io.Writer, statuses []*CertStatus) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No certificate managed by the ingress controller found")
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Host", "Ingress", "Challenge", "Status", "Expires In", "Message"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	unready := 0
+	for _, status := range statuses {
+		if status.Status != resource.IngressCertificateReady {
+			unready++
+		}
+		expiresIn := status.ExpiresIn
+		if expiresIn == "" {
+			expiresIn = "-"
+		}
+		table.Append([]string{status.Host, status.Ingress, status.Challenge, status.Status, expiresIn, status.Message})
+	}
+	table.Render()
+
+	if unready != 0 {
+		fmt.Fprintf(w, "\n%d of %d certificates are not ready\n", unready, len(statuses))
+	}
+}
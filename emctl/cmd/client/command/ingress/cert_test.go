@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestCertStatuses(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	certificates := []*resource.IngressCertificate{
+		{Host: "www.megaease.com", Ingress: "web", Challenge: "http-01", Status: resource.IngressCertificateReady,
+			NotAfter: now.Add(60 * 24 * time.Hour).Format(time.RFC3339)},
+		{Host: "api.megaease.com", Ingress: "api", Challenge: "dns-01", Status: resource.IngressCertificateFailed,
+			NotAfter: now.Add(-time.Hour).Format(time.RFC3339), Message: "DNS record not propagated"},
+		{Host: "admin.megaease.com", Ingress: "web", Challenge: "http-01", Status: resource.IngressCertificatePending},
+	}
+
+	statuses := certStatuses(certificates, now, "")
+	want := []struct {
+		host      string
+		expiresIn string
+	}{
+		{"admin.megaease.com", ""},
+		{"api.megaease.com", "-1h0m0s"},
+		{"www.megaease.com", "1440h0m0s"},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("want %d statuses, got %d", len(want), len(statuses))
+	}
+	for i, w := range want {
+		if statuses[i].Host != w.host || statuses[i].ExpiresIn != w.expiresIn {
+			t.Errorf("status %d: want %+v, got %+v", i, w, statuses[i])
+		}
+	}
+
+	statuses = certStatuses(certificates, now, "web")
+	if len(statuses) != 2 {
+		t.Fatalf("want 2 statuses of web, got %d", len(statuses))
+	}
+
+	buff := &bytes.Buffer{}
+	printCertStatuses(buff, statuses)
+	if !strings.Contains(buff.String(), "1 of 2 certificates are not ready") {
+		t.Errorf("unexpected output:\n%s", buff.String())
+	}
+}
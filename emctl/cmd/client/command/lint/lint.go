@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lint checks mesh resources in files without any cluster, for
+// their schemas, references between them, and suspicious values.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+)
+
+const (
+	// maxRetryAttempts is the max attempts of retries not suspicious, since
+	// retries of every hop in a call chain multiply each other.
+	maxRetryAttempts = 3
+	// maxHedgingBudgetPercent is the max percentage of hedged requests not suspicious.
+	maxHedgingBudgetPercent = 50
+)
+
+type (
+	// Finding is a problem of a resource found by a rule.
+	Finding struct {
+		Rule     string `yaml:"rule" json:"rule"`
+		Severity string `yaml:"severity" json:"severity"`
+		Message  string `yaml:"message" json:"message"`
+		Source   string `yaml:"source" json:"source"`
+		// Line is the first line of the YAML document of the resource, 0 if it's unknown.
+		Line int    `yaml:"line,omitempty" json:"line,omitempty"`
+		Kind string `yaml:"kind,omitempty" json:"kind,omitempty"`
+		Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	}
+
+	// Linter collects resources with their locations, and lints them at last.
+	Linter struct {
+		severities map[string]string
+		objects    []*locatedObject
+		findings   []*Finding
+	}
+
+	locatedObject struct {
+		meta.MeshObject
+		location util.Location
+	}
+)
+
+// NewLinter creates a Linter with severities of rules overridden in the form of <rule>=<severity>.
+func NewLinter(overrides []string) (*Linter, error) {
+	s, err := severities(overrides)
+	if err != nil {
+		return nil, err
+	}
+	return &Linter{severities: s}, nil
+}
+
+// Add adds the resource decoded at the location, err is the decoding error of it.
+// It implements util.LocatedVisitorFunc.
+func (l *Linter) Add(mo meta.MeshObject, location util.Location, err error) error {
+	if err != nil {
+		l.report(RuleSchema, &locatedObject{location: location}, "%v", err)
+		return nil
+	}
+
+	object := &locatedObject{MeshObject: mo, location: location}
+	if v, ok := mo.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			l.report(RuleSchema, object, "%v", err)
+		}
+	}
+	l.objects = append(l.objects, object)
+	return nil
+}
+
+// AddError adds the error of reading the source, e.g. broken YAML.
+func (l *Linter) AddError(source string, err error) {
+	l.report(RuleSchema, &locatedObject{location: util.Location{Source: source}}, "%v", err)
+}
+
+// Lint checks all resources added, and returns all findings sorted by their locations.
+func (l *Linter) Lint() []*Finding {
+	l.checkDuplicates()
+	l.checkReferences()
+	l.checkSuspiciousValues()
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Line < b.Line
+	})
+	return l.findings
+}
+
+func (l *Linter) report(rule string, object *locatedObject, format string, a ...interface{}) {
+	severity := l.severities[rule]
+	if severity == SeverityOff {
+		return
+	}
+
+	finding := &Finding{
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+		Source:   object.location.Source,
+		Line:     object.location.Line,
+	}
+	if object.MeshObject != nil {
+		finding.Kind, finding.Name = object.Kind(), object.Name()
+	}
+	l.findings = append(l.findings, finding)
+}
+
+func (l *Linter) checkDuplicates() {
+	defined := map[string]*locatedObject{}
+	for _, object := range l.objects {
+		key := object.Kind() + "/" + object.Name()
+		if first, ok := defined[key]; ok {
+			l.report(RuleDuplicateResource, object, "%s is already defined in %s:%d",
+				key, first.location.Source, first.location.Line)
+			continue
+		}
+		defined[key] = object
+	}
+}
+
+func (l *Linter) checkReferences() {
+	services := map[string]*resource.Service{}
+	names := map[string]map[string]bool{}
+	for _, object := range l.objects {
+		if names[object.Kind()] == nil {
+			names[object.Kind()] = map[string]bool{}
+		}
+		names[object.Kind()][object.Name()] = true
+		if s, ok := object.MeshObject.(*resource.Service); ok {
+			services[s.Name()] = s
+		}
+	}
+
+	for _, object := range l.objects {
+		refs := resource.References(object.MeshObject)
+		for _, ref := range refs {
+			if names[ref.Kind][ref.Name] {
+				continue
+			}
+			rule := RuleMissingReference
+			if ref.Kind == resource.KindTenant {
+				rule = RuleMissingTenant
+			}
+			l.report(rule, object, "%s/%s references the missing %s %s",
+				object.Kind(), object.Name(), ref.Kind, ref.Name)
+		}
+
+		for _, ref := range refs {
+			s := services[ref.Name]
+			if ref.Kind != resource.KindService || ref.HTTPOnly == "" || s == nil || s.Spec == nil || !s.Spec.IsTCP() {
+				continue
+			}
+			l.report(RuleTCPServicePolicy, object, "tcp service %s can't have HTTP-only policies: %s",
+				ref.Name, ref.HTTPOnly)
+		}
+	}
+}
+
+func (l *Linter) checkSuspiciousValues() {
+	for _, object := range l.objects {
+		switch o := object.MeshObject.(type) {
+		case *resource.ServiceCanary:
+			if o.Spec == nil || o.Spec.Sticky == nil {
+				continue
+			}
+			if o.Spec.Sticky.Mode == resource.ServiceCanaryStickyHash && o.Spec.Sticky.Percentage == 0 {
+				l.report(RuleZeroPercentage, object, "sticky percentage is 0%%, no user is routed to the canary")
+			}
+		case *resource.Service:
+			if o.Spec == nil {
+				continue
+			}
+			for _, route := range o.Spec.Routes {
+				if route.Retry == nil {
+					continue
+				}
+				if route.Retry.MaxAttempts > maxRetryAttempts {
+					l.report(RuleRetryStorm, object, "route %s retries up to %d attempts, more than %d multiplies load on failures across hops",
+						route.Name, route.Retry.MaxAttempts, maxRetryAttempts)
+				}
+				if route.Retry.MaxAttempts > 1 && route.Retry.WaitDuration == "" {
+					l.report(RuleRetryStorm, object, "route %s retries without waitDuration, failed requests are retried at once",
+						route.Name)
+				}
+			}
+		case *resource.ExternalService:
+			if o.Spec == nil || o.Spec.Retries == nil {
+				continue
+			}
+			if o.Spec.Retries.MaxAttempts > maxRetryAttempts {
+				l.report(RuleRetryStorm, object, "retries up to %d attempts, more than %d multiplies load on failures",
+					o.Spec.Retries.MaxAttempts, maxRetryAttempts)
+			}
+			if o.Spec.Retries.MaxAttempts > 1 && o.Spec.Retries.PerTryTimeout == "" {
+				l.report(RuleRetryStorm, object, "retries without perTryTimeout, a hanging attempt takes the whole timeout")
+			}
+		case *resource.Resilience:
+			if o.Spec == nil || o.Spec.Hedging == nil {
+				continue
+			}
+			for _, policy := range o.Spec.Hedging.Policies {
+				if policy.BudgetPercent > maxHedgingBudgetPercent {
+					l.report(RuleRetryStorm, object, "hedging policy %s hedges up to %d%% of requests, more than %d%% multiplies load when the service is slow",
+						policy.Name, policy.BudgetPercent, maxHedgingBudgetPercent)
+				}
+			}
+		}
+	}
+}
+
+// Count returns the number of findings of the severity.
+func Count(findings []*Finding, severity string) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
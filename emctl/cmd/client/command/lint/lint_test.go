@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+func TestSeverities(t *testing.T) {
+	s, err := severities([]string{"missing-tenant=off", " retry-storm = error "})
+	if err != nil {
+		t.Fatalf("parse severities failed: %v", err)
+	}
+	if s[RuleMissingTenant] != SeverityOff || s[RuleRetryStorm] != SeverityError || s[RuleSchema] != SeverityError {
+		t.Fatalf("unexpected severities %v", s)
+	}
+
+	for _, override := range []string{"retry-storm", "unknown=error", "retry-storm=fatal"} {
+		if _, err := severities([]string{override}); err == nil {
+			t.Errorf("expected error for %q", override)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	linter, err := NewLinter([]string{"missing-tenant=off"})
+	if err != nil {
+		t.Fatalf("new linter failed: %v", err)
+	}
+
+	service := func() *resource.Service {
+		return &resource.Service{
+			MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, "pet"),
+			Spec:         &resource.ServiceSpec{RegisterTenant: "tenant-001", Protocol: resource.ServiceProtocolTCP},
+		}
+	}
+	add := func(mo meta.MeshObject, source string, line int) {
+		if err := linter.Add(mo, util.Location{Source: source, Line: line}, nil); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+
+	add(service(), "mesh.yaml", 1)
+	add(&resource.Canary{MeshResource: resource.NewCanaryResource(resource.DefaultAPIVersion, "pet")}, "mesh.yaml", 10)
+	add(&resource.ServiceCanary{
+		MeshResource: resource.NewServiceCanaryResource(resource.DefaultAPIVersion, "pet-beta"),
+		Spec: &resource.ServiceCanarySpec{
+			Selector: &v1alpha1.ServiceSelector{MatchServices: []string{"pet", "order"}},
+			Sticky:   &resource.ServiceCanarySticky{Mode: resource.ServiceCanaryStickyHash, Key: `header("X-User-Id")`},
+		},
+	}, "mesh.yaml", 20)
+	add(&resource.Resilience{
+		MeshResource: resource.NewResilienceResource(resource.DefaultAPIVersion, "order"),
+		Spec: &resource.ResilienceSpec{
+			Hedging: &resource.Hedging{
+				Policies: []*resource.HedgingPolicy{{Name: "fast", Delay: "100ms", BudgetPercent: 80}},
+				URLs: []*resource.HedgingURLRule{{
+					Methods: []string{"GET"}, URL: &resource.HedgingURL{Prefix: "/"}, PolicyRef: "fast",
+				}},
+			},
+		},
+	}, "order.yaml", 1)
+	add(service(), "order.yaml", 10)
+	if err := linter.Add(nil, util.Location{Source: "bad.yaml", Line: 3}, errors.New("unknown field")); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	findings := linter.Lint()
+	rules := map[string]int{}
+	for _, finding := range findings {
+		rules[finding.Rule]++
+	}
+	expected := map[string]int{
+		RuleSchema:            1,
+		RuleDuplicateResource: 1,
+		RuleMissingReference:  2,
+		RuleTCPServicePolicy:  2,
+		RuleZeroPercentage:    1,
+		RuleRetryStorm:        1,
+	}
+	for rule, count := range expected {
+		if rules[rule] != count {
+			t.Errorf("expected %d findings of %s, got %d", count, rule, rules[rule])
+		}
+	}
+	if rules[RuleMissingTenant] != 0 {
+		t.Errorf("expected rule %s turned off, got %d findings", RuleMissingTenant, rules[RuleMissingTenant])
+	}
+	if Count(findings, SeverityError) != 6 {
+		t.Errorf("expected 6 errors, got %d", Count(findings, SeverityError))
+	}
+
+	first := findings[0]
+	if first.Source != "bad.yaml" || first.Line != 3 || first.Rule != RuleSchema {
+		t.Errorf("expected findings sorted by locations, got %+v first", first)
+	}
+
+	buff := &bytes.Buffer{}
+	printText(buff, findings)
+	if !strings.Contains(buff.String(), "bad.yaml:3: error: unknown field [schema]\n") ||
+		!strings.HasSuffix(buff.String(), "6 error(s), 2 warning(s), 0 note(s)\n") {
+		t.Errorf("unexpected text output:\n%s", buff.String())
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	log := toSARIF([]*Finding{
+		{Rule: RuleSchema, Severity: SeverityError, Message: "broken YAML", Source: "mesh.yaml"},
+		{Rule: RuleRetryStorm, Severity: SeverityNote, Message: "too many retries", Source: "dir/svc.yaml", Line: 12},
+	})
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules) || len(run.Results) != 2 {
+		t.Fatalf("unexpected run %+v", run)
+	}
+	if run.Results[0].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("expected no region without the line")
+	}
+	result := run.Results[1]
+	if result.Level != SeverityNote || result.RuleID != RuleRetryStorm ||
+		result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "dir/svc.yaml" ||
+		result.Locations[0].PhysicalLocation.Region.StartLine != 12 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
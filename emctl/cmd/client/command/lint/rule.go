@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SeverityError fails the lint.
+	SeverityError = "error"
+	// SeverityWarning is reported without failing the lint.
+	SeverityWarning = "warning"
+	// SeverityNote is reported for information.
+	SeverityNote = "note"
+	// SeverityOff disables the rule.
+	SeverityOff = "off"
+
+	// RuleSchema checks resources by schemas and validation of their kinds.
+	RuleSchema = "schema"
+	// RuleDuplicateResource checks resources defined more than once.
+	RuleDuplicateResource = "duplicate-resource"
+	// RuleMissingReference checks services and ingresses referenced by resources.
+	RuleMissingReference = "missing-reference"
+	// RuleMissingTenant checks tenants referenced by resources.
+	RuleMissingTenant = "missing-tenant"
+	// RuleTCPServicePolicy checks HTTP-only policies of tcp services.
+	RuleTCPServicePolicy = "tcp-service-policy"
+	// RuleZeroPercentage checks canaries selecting no traffic.
+	RuleZeroPercentage = "zero-percentage"
+	// RuleRetryStorm checks retries and hedging multiplying load on failures.
+	RuleRetryStorm = "retry-storm"
+)
+
+// Rule is a lint rule with its default severity.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    string
+}
+
+// Rules are all lint rules.
+var Rules = []*Rule{
+	{RuleSchema, "Resources conform to the schemas and the validation of their kinds", SeverityError},
+	{RuleDuplicateResource, "Every resource is defined once", SeverityError},
+	{RuleMissingReference, "Services and ingresses referenced by resources are defined", SeverityError},
+	{RuleMissingTenant, "Tenants referenced by resources are defined, they are often managed apart", SeverityWarning},
+	{RuleTCPServicePolicy, "HTTP-only policies are not attached to tcp services", SeverityError},
+	{RuleZeroPercentage, "Canaries select some of the traffic", SeverityWarning},
+	{RuleRetryStorm, "Retries and hedging don't multiply load on failures", SeverityWarning},
+}
+
+// severities returns severities of rules with the overrides in the form of <rule>=<severity>.
+func severities(overrides []string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, rule := range Rules {
+		result[rule.ID] = rule.Severity
+	}
+
+	for _, override := range overrides {
+		parts := strings.SplitN(override, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid rule %q, want <rule>=<severity>", override)
+		}
+		id, severity := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if _, ok := result[id]; !ok {
+			return nil, errors.Errorf("unknown rule %q (support %s)", id, ruleIDs())
+		}
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityNote, SeverityOff:
+		default:
+			return nil, errors.Errorf("invalid severity %q of rule %s (support %s, %s, %s, %s)",
+				severity, id, SeverityError, SeverityWarning, SeverityNote, SeverityOff)
+		}
+		result[id] = severity
+	}
+
+	return result, nil
+}
+
+func ruleIDs() string {
+	ids := []string{}
+	for _, rule := range Rules {
+		ids = append(ids, rule.ID)
+	}
+	return strings.Join(ids, ", ")
+}
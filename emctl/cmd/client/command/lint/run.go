@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Run is the entrypoint of the emctl lint command
+func Run(cmd *cobra.Command, flag *flags.Lint) {
+	if flag.YamlFile == "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "no resource specified, please specify it by -f")
+	}
+	switch flag.OutputFormat {
+	case "text", "json", "sarif":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support text, json, sarif)",
+			flag.OutputFormat)
+	}
+
+	linter, err := NewLinter(flag.Rules)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	template, err := util.NewTemplate(flag.Values, flag.Set, flag.Env)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	vss, err := util.NewVisitorBuilder().
+		Template(template).
+		FilenameParam(&util.FilenameOptions{
+			Recursive: flag.Recursive,
+			Filenames: []string{flag.YamlFile},
+		}).
+		Do()
+	if err != nil {
+		common.ExitWithErrorf("build visitor failed: %w", err)
+	}
+
+	for _, vs := range vss {
+		lv, ok := vs.(util.LocatedVisitor)
+		if !ok {
+			common.ExitWithErrorf("BUG: visitor %T doesn't support locations", vs)
+		}
+		if err := lv.VisitLocated(linter.Add); err != nil {
+			linter.AddError(flag.YamlFile, err)
+		}
+	}
+
+	findings := linter.Lint()
+	switch flag.OutputFormat {
+	case "text":
+		printText(os.Stdout, findings)
+	case "json":
+		buff, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal findings failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	case "sarif":
+		buff, err := json.MarshalIndent(toSARIF(findings), "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal findings failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+
+	if errs := Count(findings, SeverityError); errs != 0 {
+		common.ExitWithError(common.WithCode(errors.Errorf("lint failed with %d error(s)", errs), common.ExitCodeValidation))
+	}
+}
+
+func printText(w io.Writer, findings []*Finding) {
+	for _, finding := range findings {
+		location := finding.Source
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, finding.Line)
+		}
+		fmt.Fprintf(w, "%s: %s: %s [%s]\n", location, finding.Severity, finding.Message, finding.Rule)
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s), %d note(s)\n",
+		Count(findings, SeverityError), Count(findings, SeverityWarning), Count(findings, SeverityNote))
+}
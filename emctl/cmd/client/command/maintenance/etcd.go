@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// NOTE: The embedded etcd of the control plane serves its gRPC gateway
+// on the client port, in which int64 fields are encoded as strings.
+const (
+	memberListPath = "/v3/cluster/member/list"
+	statusPath     = "/v3/maintenance/status"
+	compactionPath = "/v3/kv/compaction"
+	defragmentPath = "/v3/maintenance/defragment"
+)
+
+type (
+	// int64String is an int64 encoded as a string or a number in JSON.
+	int64String int64
+
+	responseHeader struct {
+		MemberID int64String `json:"member_id"`
+		Revision int64String `json:"revision"`
+	}
+
+	memberStatus struct {
+		Header      responseHeader `json:"header"`
+		Version     string         `json:"version"`
+		DBSize      int64String    `json:"dbSize"`
+		DBSizeInUse int64String    `json:"dbSizeInUse"`
+		Leader      int64String    `json:"leader"`
+	}
+
+	member struct {
+		ID         int64String `json:"ID"`
+		Name       string      `json:"name"`
+		ClientURLs []string    `json:"clientURLs"`
+	}
+
+	memberList struct {
+		Members []member `json:"members"`
+	}
+
+	// etcdClient requests the gRPC gateway of a member.
+	etcdClient struct {
+		name    string
+		url     string
+		timeout time.Duration
+		options []client.Option
+	}
+)
+
+func (i *int64String) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = int64String(v)
+	return nil
+}
+
+func (c *etcdClient) post(path string, reqBody interface{}, result interface{}) error {
+	_, err := client.NewHTTPJSON(c.options...).
+		Post(c.url+path, reqBody, c.timeout, nil).
+		HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+			if statusCode != http.StatusOK {
+				return nil, errors.Errorf("request %s of member %s failed, status code: %d, body: %s",
+					path, c.name, statusCode, body)
+			}
+			if result == nil {
+				return nil, nil
+			}
+			return nil, json.Unmarshal(body, result)
+		})
+	return err
+}
+
+func (c *etcdClient) members() ([]member, error) {
+	list := &memberList{}
+	err := c.post(memberListPath, map[string]interface{}{}, list)
+	if err != nil {
+		return nil, err
+	}
+	return list.Members, nil
+}
+
+func (c *etcdClient) status() (*memberStatus, error) {
+	status := &memberStatus{}
+	err := c.post(statusPath, map[string]interface{}{}, status)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// compact compacts the history before the revision of the whole cluster,
+// it waits for the compaction is applied to the storage.
+func (c *etcdClient) compact(revision int64) error {
+	return c.post(compactionPath, map[string]interface{}{
+		"revision": strconv.FormatInt(revision, 10),
+		"physical": true,
+	}, nil)
+}
+
+// defragment releases the free space of the storage of the member,
+// the member can't serve during defragmenting.
+func (c *etcdClient) defragment() error {
+	return c.post(defragmentPath, map[string]interface{}{}, nil)
+}
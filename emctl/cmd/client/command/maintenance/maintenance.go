@@ -0,0 +1,267 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+type (
+	// memberResult is the result of maintaining a member shown in the summary.
+	memberResult struct {
+		Name         string
+		Leader       bool
+		SizeBefore   int64
+		SizeAfter    int64
+		SizeInUse    int64
+		Defragmented bool
+	}
+
+	maintainer struct {
+		flag    *flags.Maintenance
+		members []*etcdClient
+	}
+)
+
+// Run is the entrypoint of the emctl maintenance run sub command
+func Run(cmd *cobra.Command, flag *flags.Maintenance) {
+	var members []*etcdClient
+	var err error
+	if flag.Endpoint != "" {
+		members, err = discoverMembers(flag)
+	} else {
+		members, err = proxyMembers(flag)
+	}
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	m := &maintainer{flag: flag, members: members}
+	results, err := m.maintain()
+	if results != nil {
+		printSummary(os.Stdout, results)
+	}
+	if err != nil {
+		common.ExitWithErrorf("maintain control plane failed: %w", err)
+	}
+
+	common.Infof("maintain control plane successfully")
+}
+
+// discoverMembers lists members from the endpoint, and accesses
+// them by their advertised client URLs.
+func discoverMembers(flag *flags.Maintenance) ([]*etcdClient, error) {
+	seed := &etcdClient{name: flag.Endpoint, url: strings.TrimSuffix(flag.Endpoint, "/"), timeout: flag.Timeout}
+	list, err := seed.members()
+	if err != nil {
+		return nil, errors.Wrapf(err, "list members from %s", flag.Endpoint)
+	}
+
+	members := []*etcdClient{}
+	for _, m := range list {
+		if len(m.ClientURLs) == 0 {
+			return nil, errors.Errorf("member %d has not started", m.ID)
+		}
+		members = append(members, &etcdClient{
+			name:    m.Name,
+			url:     strings.TrimSuffix(m.ClientURLs[0], "/"),
+			timeout: flag.Timeout,
+		})
+	}
+	return members, nil
+}
+
+// proxyMembers accesses members via the proxy of pods of the Kubernetes
+// API server, so the control plane needn't be reachable from emctl.
+func proxyMembers(flag *flags.Maintenance) ([]*etcdClient, error) {
+	config, err := installbase.KubernetesConfig()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := installbase.NewKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(flag.MeshNamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "app=" + installbase.ControlPlaneStatefulSetName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list pods of control plane")
+	}
+	if len(pods.Items) == 0 {
+		return nil, errors.Errorf("no pod of control plane found in namespace %s", flag.MeshNamespace)
+	}
+
+	options := []client.Option{
+		func(c *resty.Client) {
+			c.SetTransport(transport)
+		},
+	}
+	host := strings.TrimSuffix(config.Host, "/")
+
+	members := []*etcdClient{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		port := installbase.PodContainerPort(pod, installbase.ControlPlaneStatefulSetClientPortName, flags.DefaultMeshClientPort)
+		members = append(members, &etcdClient{
+			name: pod.Name,
+			url: fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s:%d/proxy",
+				host, flag.MeshNamespace, pod.Name, port),
+			timeout: flag.Timeout,
+			options: options,
+		})
+	}
+	return members, nil
+}
+
+// maintain compacts the history of the cluster, then defragments members one
+// by one, the leader is the last one to avoid unnecessary leader elections.
+func (m *maintainer) maintain() ([]*memberResult, error) {
+	results := []*memberResult{}
+	var leader, revision int64
+	for _, member := range m.members {
+		status, err := member.status()
+		if err != nil {
+			return nil, err
+		}
+		leader = int64(status.Leader)
+		if r := int64(status.Header.Revision); r > revision {
+			revision = r
+		}
+		results = append(results, &memberResult{
+			Name:       member.name,
+			Leader:     status.Header.MemberID == status.Leader,
+			SizeBefore: int64(status.DBSize),
+			SizeAfter:  int64(status.DBSize),
+			SizeInUse:  int64(status.DBSizeInUse),
+		})
+	}
+	common.Debugf("leader %x, revision %d", leader, revision)
+
+	err := m.compact(revision)
+	if err != nil {
+		return results, err
+	}
+
+	if m.flag.SkipDefrag {
+		return results, nil
+	}
+
+	order := make([]int, len(m.members))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return !results[order[i]].Leader && results[order[j]].Leader
+	})
+
+	for _, i := range order {
+		member, result := m.members[i], results[i]
+
+		common.Infof("defragment member %s", member.name)
+		err := member.defragment()
+		if err != nil {
+			return results, errors.Wrapf(err, "defragment member %s", member.name)
+		}
+		result.Defragmented = true
+
+		status, err := member.status()
+		if err != nil {
+			return results, err
+		}
+		result.SizeAfter = int64(status.DBSize)
+		result.SizeInUse = int64(status.DBSizeInUse)
+	}
+
+	return results, nil
+}
+
+func (m *maintainer) compact(revision int64) error {
+	if m.flag.RetainRevisions <= 0 {
+		return nil
+	}
+
+	target := revision - m.flag.RetainRevisions
+	if target <= 0 {
+		common.Infof("skip compacting, only %d revisions", revision)
+		return nil
+	}
+
+	common.Infof("compact revisions before %d", target)
+	err := m.members[0].compact(target)
+	if err != nil && strings.Contains(err.Error(), "required revision has been compacted") {
+		common.Infof("revisions before %d are compacted already", target)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "compact")
+	}
+	return nil
+}
+
+func printSummary(w io.Writer, results []*memberResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Member", "Leader", "DB Size Before", "DB Size After", "DB Size In Use", "Defragmented"})
+	table.SetBorder(false)
+	for _, r := range results {
+		table.Append([]string{
+			r.Name,
+			fmt.Sprintf("%t", r.Leader),
+			formatSize(r.SizeBefore),
+			formatSize(r.SizeAfter),
+			formatSize(r.SizeInUse),
+			fmt.Sprintf("%t", r.Defragmented),
+		})
+	}
+	table.Render()
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+)
+
+type fakeCluster struct {
+	servers   []*httptest.Server
+	leader    int
+	revision  int64
+	dbSize    int64
+	calls     []string
+	compacted string
+}
+
+func newFakeCluster(n, leader int) *fakeCluster {
+	c := &fakeCluster{leader: leader, revision: 25000, dbSize: 64 << 20}
+	for i := 0; i < n; i++ {
+		c.servers = append(c.servers, httptest.NewServer(c.handler(i)))
+	}
+	return c
+}
+
+func (c *fakeCluster) close() {
+	for _, s := range c.servers {
+		s.Close()
+	}
+}
+
+func (c *fakeCluster) handler(id int) http.Handler {
+	name := fmt.Sprintf("member-%d", id)
+	mux := http.NewServeMux()
+	mux.HandleFunc(memberListPath, func(w http.ResponseWriter, r *http.Request) {
+		list := `{"members": [`
+		for i, s := range c.servers {
+			if i > 0 {
+				list += ","
+			}
+			list += fmt.Sprintf(`{"ID": "%d", "name": "member-%d", "clientURLs": ["%s"]}`, i+1, i, s.URL)
+		}
+		w.Write([]byte(list + "]}"))
+	})
+	mux.HandleFunc(statusPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"header": {"member_id": "%d", "revision": "%d"}, "dbSize": "%d", "dbSizeInUse": "%d", "leader": "%d"}`,
+			id+1, c.revision, c.dbSize, 16<<20, c.leader+1)
+	})
+	mux.HandleFunc(compactionPath, func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&req)
+		c.compacted = fmt.Sprint(req["revision"])
+		c.calls = append(c.calls, "compact "+name)
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc(defragmentPath, func(w http.ResponseWriter, r *http.Request) {
+		c.calls = append(c.calls, "defragment "+name)
+		w.Write([]byte(`{}`))
+	})
+	return mux
+}
+
+func TestMaintain(t *testing.T) {
+	cluster := newFakeCluster(3, 0)
+	defer cluster.close()
+
+	flag := &flags.Maintenance{Endpoint: cluster.servers[1].URL, RetainRevisions: 10000, Timeout: time.Second}
+	members, err := discoverMembers(flag)
+	if err != nil {
+		t.Fatalf("discover members failed: %v", err)
+	}
+	if len(members) != 3 {
+		t.Fatalf("want 3 members, got %d", len(members))
+	}
+
+	m := &maintainer{flag: flag, members: members}
+	results, err := m.maintain()
+	if err != nil {
+		t.Fatalf("maintain failed: %v", err)
+	}
+
+	if cluster.compacted != "15000" {
+		t.Errorf("compacted revision: want 15000, got %s", cluster.compacted)
+	}
+	want := []string{"compact member-0", "defragment member-1", "defragment member-2", "defragment member-0"}
+	if !reflect.DeepEqual(cluster.calls, want) {
+		t.Errorf("calls: want %v, got %v", want, cluster.calls)
+	}
+	for _, r := range results {
+		if !r.Defragmented {
+			t.Errorf("member %s is not defragmented", r.Name)
+		}
+	}
+	if !results[0].Leader {
+		t.Errorf("member-0 should be the leader")
+	}
+
+	buff := &bytes.Buffer{}
+	printSummary(buff, results)
+	if !strings.Contains(buff.String(), "64.0 MiB") {
+		t.Errorf("summary should contain db size, got:\n%s", buff.String())
+	}
+}
+
+func TestMaintainSkip(t *testing.T) {
+	cluster := newFakeCluster(1, 0)
+	defer cluster.close()
+	cluster.revision = 500
+
+	flag := &flags.Maintenance{Endpoint: cluster.servers[0].URL, RetainRevisions: 10000, SkipDefrag: true, Timeout: time.Second}
+	members, err := discoverMembers(flag)
+	if err != nil {
+		t.Fatalf("discover members failed: %v", err)
+	}
+
+	m := &maintainer{flag: flag, members: members}
+	_, err = m.maintain()
+	if err != nil {
+		t.Fatalf("maintain failed: %v", err)
+	}
+	if len(cluster.calls) != 0 {
+		t.Errorf("nothing should be done, got %v", cluster.calls)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, want := range map[int64]string{
+		512:       "512 B",
+		2048:      "2.0 KiB",
+		300 << 20: "300.0 MiB",
+	} {
+		if got := formatSize(size); got != want {
+			t.Errorf("format %d: want %s, got %s", size, want, got)
+		}
+	}
+}
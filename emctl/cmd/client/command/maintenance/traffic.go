@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RunEnable is the entrypoint of the emctl maintenance enable sub command
+func RunEnable(cmd *cobra.Command, flag *flags.MaintenanceEnable) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	mode, err := newMaintenanceMode(flag)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "%v", err)
+	}
+
+	client := meshclient.New(flag.Server)
+	err = enable(client, mode, flag.Timeout)
+	if err != nil {
+		if meshclient.IsNotFoundError(err) {
+			common.ExitWithError(common.WithCode(err, common.ExitCodeNotFound))
+		}
+		common.ExitWithErrorf("enable maintenance failed: %w", err)
+	}
+
+	common.Logger().With("kind", resource.KindMaintenanceMode, "name", mode.Name()).
+		Infof("%s is in maintenance, responding %d", scopeOf(mode.Spec.Service), mode.Spec.StatusCodeOrDefault())
+}
+
+// RunDisable is the entrypoint of the emctl maintenance disable sub command
+func RunDisable(cmd *cobra.Command, flag *flags.MaintenanceDisable) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	name, err := maintenanceModeName(flag.Service, flag.All)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "%v", err)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	err = meshclient.New(flag.Server).V1Alpha1().MaintenanceMode().Delete(ctx, name)
+	if err != nil {
+		if meshclient.IsNotFoundError(err) {
+			common.ExitWithError(common.WithCode(errors.Errorf("%s is not in maintenance", scopeOf(flag.Service)),
+				common.ExitCodeNotFound))
+		}
+		common.ExitWithErrorf("disable maintenance failed: %w", err)
+	}
+
+	common.Logger().With("kind", resource.KindMaintenanceMode, "name", name).
+		Infof("%s is out of maintenance", scopeOf(flag.Service))
+}
+
+// maintenanceModeName returns the name of the MaintenanceMode of the service,
+// exactly one of the service and all is required.
+func maintenanceModeName(service string, all bool) (string, error) {
+	switch {
+	case service != "" && all:
+		return "", errors.New("--service and --all are mutually exclusive")
+	case all:
+		return resource.MaintenanceModeMeshWide, nil
+	case service == "":
+		return "", errors.New("--service or --all is required")
+	case service == resource.MaintenanceModeMeshWide:
+		return "", errors.Errorf("service name %s is reserved for the whole mesh", service)
+	default:
+		return service, nil
+	}
+}
+
+func newMaintenanceMode(flag *flags.MaintenanceEnable) (*resource.MaintenanceMode, error) {
+	name, err := maintenanceModeName(flag.Service, flag.All)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &resource.MaintenanceModeSpec{
+		Service:    flag.Service,
+		StatusCode: flag.Response,
+		Body:       flag.Body,
+		RetryAfter: flag.RetryAfter,
+		Reason:     flag.Reason,
+	}
+	for _, kv := range flag.Headers {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid header %q, want name=value", kv)
+		}
+		if spec.Headers == nil {
+			spec.Headers = map[string]string{}
+		}
+		spec.Headers[kv[:i]] = kv[i+1:]
+	}
+
+	mode := &resource.MaintenanceMode{
+		MeshResource: resource.NewMaintenanceModeResource(resource.DefaultAPIVersion, name),
+		Spec:         spec,
+	}
+	err = mode.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return mode, nil
+}
+
+// enable checks the service exists and applies the MaintenanceMode.
+func enable(client meshclient.MeshClient, mode *resource.MaintenanceMode, timeout time.Duration) error {
+	if mode.Spec.Service != "" {
+		ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+		defer cancelFunc()
+		_, err := client.V1Alpha1().Service().Get(ctx, mode.Spec.Service)
+		if err != nil {
+			return errors.Wrapf(err, "get service %s", mode.Spec.Service)
+		}
+	}
+
+	return apply.WrapApplierByMeshObject(mode, client, timeout).Apply()
+}
+
+func scopeOf(service string) string {
+	if service == "" {
+		return "the whole mesh"
+	}
+	return "service " + service
+}
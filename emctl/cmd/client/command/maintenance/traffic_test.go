@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestNewMaintenanceMode(t *testing.T) {
+	mode, err := newMaintenanceMode(&flags.MaintenanceEnable{
+		Service:    "payments",
+		Response:   503,
+		Headers:    []string{"Content-Type=text/plain"},
+		RetryAfter: 120,
+	})
+	if err != nil {
+		t.Fatalf("new maintenance mode failed: %v", err)
+	}
+	if mode.Name() != "payments" || mode.Spec.Headers["Content-Type"] != "text/plain" || mode.Spec.RetryAfter != 120 {
+		t.Fatalf("unexpected maintenance mode %+v", mode.Spec)
+	}
+
+	mode, err = newMaintenanceMode(&flags.MaintenanceEnable{All: true, Response: 200, Body: "ok"})
+	if err != nil {
+		t.Fatalf("new mesh-wide maintenance mode failed: %v", err)
+	}
+	if mode.Name() != resource.MaintenanceModeMeshWide || mode.Spec.Service != "" {
+		t.Fatalf("unexpected mesh-wide maintenance mode %s %+v", mode.Name(), mode.Spec)
+	}
+
+	for _, flag := range []*flags.MaintenanceEnable{
+		{Response: 503},
+		{Service: "payments", All: true, Response: 503},
+		{Service: resource.MaintenanceModeMeshWide, Response: 503},
+		{Service: "payments", Response: 99},
+		{Service: "payments", Response: 503, RetryAfter: -1},
+		{Service: "payments", Response: 503, Headers: []string{"Retry-After=10"}},
+		{Service: "payments", Response: 503, Headers: []string{"no-value"}},
+	} {
+		if _, err := newMaintenanceMode(flag); err == nil {
+			t.Fatalf("new maintenance mode of %+v should fail", flag)
+		}
+	}
+}
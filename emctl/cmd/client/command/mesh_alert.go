@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/alert"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// AlertCmd invokes alert sub command entrypoint
+func AlertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alert",
+		Short: "Check, export and push alerts of alert rules",
+		Long: `Alert rules are AlertRule resources managed by emctl apply, get and delete.
+The alert sub commands evaluate them against metrics reported by sidecars, export them
+as a PrometheusRule, or push firing alerts to webhooks.`,
+	}
+
+	cmd.AddCommand(alertCheckCmd())
+	cmd.AddCommand(alertExportCmd())
+	cmd.AddCommand(alertPushCmd())
+
+	return cmd
+}
+
+func alertCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check [alert rule names]",
+		Short: "Evaluate alert rules and show which are firing",
+		Long: `Evaluate alert rules on every service in their scopes against metrics reported by
+sidecars, and show the current values and whether the conditions hold.`,
+		Example: `emctl alert check
+emctl alert check order-latency -o json`,
+	}
+
+	flags := &flags.AlertCheck{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		alert.RunCheck(cmd, flags)
+	}
+
+	return cmd
+}
+
+func alertExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [alert rule names]",
+		Short: "Export alert rules as a PrometheusRule",
+		Long: `Export alert rules as a PrometheusRule of the Prometheus Operator, whose expressions
+query metrics of sidecars with the prefix and labels service and tenant.`,
+		Example: `emctl alert export | kubectl apply -f -
+emctl alert export --metric-prefix mesh --rate-window 1m`,
+	}
+
+	flags := &flags.AlertExport{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		alert.RunExport(cmd, flags)
+	}
+
+	return cmd
+}
+
+func alertPushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push [alert rule names]",
+		Short: "Push firing alerts to webhooks",
+		Long: `Evaluate alert rules and push alerts starting firing or resolved to webhooks, in the
+Alertmanager webhook format. Rules push to their own webhooks, or to the one of --webhook.`,
+		Example: `emctl alert push --webhook http://receiver:8080/alerts
+emctl alert push --interval 30s`,
+	}
+
+	flags := &flags.AlertPush{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		alert.RunPush(cmd, flags)
+	}
+
+	return cmd
+}
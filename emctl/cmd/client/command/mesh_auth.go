@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/auth"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// AuthCmd invokes auth sub command entrypoint
+func AuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage access tokens of the control plane",
+		Long: `Access tokens are scoped to tenants with roles: viewers read all resources,
+editors also write resources of their tenants, admins also manage tokens of their tenants.
+emctl sends the token of the env EMCTL_TOKEN or the token in ~/.emctlrc with every request,
+the roles are enforced by control planes serving access tokens, not by emctl.`,
+	}
+
+	cmd.AddCommand(authCreateTokenCmd())
+	cmd.AddCommand(authRevokeTokenCmd())
+
+	return cmd
+}
+
+func authCreateTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create-token",
+		Short: "Create an access token scoped to a tenant",
+		Example: `emctl auth create-token --role admin
+emctl auth create-token --tenant shop --role editor --ttl 720h --description "shop team"`,
+		Args: cobra.NoArgs,
+	}
+
+	flags := &flags.AuthCreateToken{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		auth.RunCreateToken(cmd, flags)
+	}
+
+	return cmd
+}
+
+func authRevokeTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "revoke-token id",
+		Short:   "Revoke an access token by its id or the token itself",
+		Example: "emctl auth revoke-token 9f2c4e7a1b8d3f60a5c7e9b1d3f5a7c9",
+		Args:    cobra.ExactArgs(1),
+	}
+
+	flags := &flags.AuthRevokeToken{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		auth.RunRevokeToken(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/canary"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// CanaryCmd invokes canary sub command entrypoint
+func CanaryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "canary",
+		Short: "Debug service canaries",
+		Long: `Service canaries are ServiceCanary resources managed by emctl apply, get and delete.
+The canary sub commands help to debug how requests are tagged by them.`,
+	}
+
+	cmd.AddCommand(canaryTestMatchCmd())
+
+	return cmd
+}
+
+func canaryTestMatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test-match [service canary name]",
+		Short: "Evaluate a sample request against a service canary",
+		Long: `Evaluate a sample request built from the flags against the traffic rules and the match
+expression of a ServiceCanary, or against the expression of --expression, and show the
+result of every predicate. Nothing is sent to services.`,
+		Example: `emctl canary test-match delivery-mesh-beijing --header X-Location=Beijing
+emctl canary test-match -e 'bucket(cookie("uid")) < 20' --cookie uid=1024`,
+	}
+
+	flags := &flags.CanaryTestMatch{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		canary.RunTestMatch(cmd, flags)
+	}
+
+	return cmd
+}
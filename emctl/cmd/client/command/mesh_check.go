@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/check"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// CheckCmd invokes check sub command entrypoint
+func CheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the EaseMesh installed in the cluster and resources in the control plane",
+	}
+
+	cmd.AddCommand(checkUpgradeCompatCmd())
+	cmd.AddCommand(checkRefsCmd())
+
+	return cmd
+}
+
+func checkUpgradeCompatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade-compat",
+		Short: "Report changes required before upgrading the EaseMesh to the version of emctl",
+		Long: `Scan the Kubernetes APIs served by the cluster, resources in the control plane, MeshDeployments
+and injected pods, then report APIs removed from the cluster, fields of resources removed or renamed
+in the schema of emctl, deprecated MeshDeployments and sidecars running incompatible images.
+It exits with a non-zero code if any error must be fixed before upgrading.`,
+		Example: "emctl check upgrade-compat",
+	}
+
+	flags := &flags.CheckUpgradeCompat{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		check.RunUpgradeCompat(cmd, flags)
+	}
+
+	return cmd
+}
+
+func checkRefsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refs",
+		Short: "Report resources in the control plane referencing missing ones",
+		Long: `Scan resources in the control plane, then report references to missing resources,
+e.g. services registered to deleted tenants, service canaries selecting deleted services,
+ingresses routing to unknown backends and WAF policies targeting deleted ingresses.
+It exits with a non-zero code if any reference is broken.`,
+		Example: "emctl check refs",
+	}
+
+	flags := &flags.CheckRefs{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		check.RunRefs(cmd, flags)
+	}
+
+	return cmd
+}
@@ -31,6 +31,32 @@ func TestCmd(t *testing.T) {
 	ApplyCmd()
 	DeleteCmd()
 	GetCmd()
+	DescribeCmd()
+	EditCmd()
+	CreateCmd()
 	InstallCmd()
 	ResetCmd()
+	TenantCmd()
+	HistoryCmd()
+	RollbackCmd()
+	AuditCmd()
+	EventsCmd()
+	MigrateCmd()
+	GitOpsCmd()
+	VerifyInstallCmd()
+	DemoCmd()
+	CheckCmd()
+	SidecarCmd()
+	MeshConfigCmd()
+	ScaleCmd()
+	MaintenanceCmd()
+	ControlPlaneCmd()
+	VMCmd()
+	GraphCmd()
+	SLOCmd()
+	AlertCmd()
+	CanaryCmd()
+	ProxyCmd()
+	ProxyStatusCmd()
+	IngressCmd()
 }
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshconfig"
+
+	"github.com/spf13/cobra"
+)
+
+// MeshConfigCmd invokes mesh-config sub command entrypoint
+func MeshConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mesh-config",
+		Short: "Change the configuration of the mesh controller with live reload",
+		Long: `Change the configuration of the mesh controller such as heartbeatInterval, registryType
+and monitorMTLS through the admin API of the control plane. The changes are validated before
+updating, and the control plane reloads the mesh controller without restarting pods.`,
+	}
+
+	cmd.AddCommand(meshConfigEditCmd(), meshConfigPatchCmd())
+
+	return cmd
+}
+
+func meshConfigEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "edit",
+		Short:   "Edit the configuration of the mesh controller in the editor from EMCTL_EDITOR or EDITOR",
+		Example: "EDITOR=nano emctl mesh-config edit",
+	}
+
+	flags := &flags.MeshConfig{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		meshconfig.RunEdit(cmd, flags)
+	}
+
+	return cmd
+}
+
+func meshConfigPatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "patch",
+		Short: "Patch the configuration of the mesh controller with a merge patch",
+		Example: `emctl mesh-config patch -p '{"heartbeatInterval": "10s"}'
+emctl mesh-config patch --patch-file monitor-mtls.yaml --dry-run`,
+	}
+
+	flags := &flags.MeshConfigPatch{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		meshconfig.RunPatch(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/controlplane"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// ControlPlaneCmd invokes control-plane sub command entrypoint
+func ControlPlaneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "control-plane",
+		Short: "Operate the control plane of the EaseMesh",
+	}
+
+	cmd.AddCommand(resizeStorageCmd())
+
+	return cmd
+}
+
+func resizeStorageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resize-storage",
+		Short: "Expand the persistent volumes of the control plane",
+		Long: `Expand the PersistentVolumeClaims of the control plane members in place, and update
+the VolumeClaimTemplates of the StatefulSet for future members. The storage class of the
+claims must allow volume expansion. The StatefulSet is recreated with its pods orphaned,
+so running members are not restarted.`,
+		Example: "emctl control-plane resize-storage --capacity 20Gi",
+	}
+
+	flags := &flags.ResizeStorage{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		controlplane.RunResizeStorage(cmd, flags)
+	}
+
+	return cmd
+}
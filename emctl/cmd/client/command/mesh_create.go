@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/create"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateCmd invokes create sub command entrypoint
+func CreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a resource of easemesh from a generator with sensible defaults",
+		Long: `Generate a valid resource from flags and create it, the resource is validated as the one
+applied by emctl apply. With --dry-run, the resource is only printed, which scaffolds
+a spec to customize and apply later.`,
+	}
+
+	cmd.AddCommand(createServiceCmd(), createTenantCmd(), createServiceCanaryCmd(),
+		createIngressCmd(), createShadowServiceCmd())
+
+	return cmd
+}
+
+func createServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service <name>",
+		Short: "Create a service registered in a tenant",
+		Example: `emctl create service orders --tenant shop
+emctl create service orders --tenant shop --port 8080 --protocol http --dry-run -o yaml`,
+	}
+
+	flags := &flags.CreateService{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		create.RunService(cmd, flags)
+	}
+
+	return cmd
+}
+
+func createTenantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tenant <name>",
+		Short:   "Create a tenant",
+		Example: `emctl create tenant shop --description "Services of the shop"`,
+	}
+
+	flags := &flags.CreateTenant{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		create.RunTenant(cmd, flags)
+	}
+
+	return cmd
+}
+
+func createServiceCanaryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "servicecanary <name>",
+		Aliases: []string{"canary"},
+		Short:   "Create a service canary routing tagged requests to canary instances",
+		Long: `Create a service canary selecting instances of the services by labels. By default, requests
+with the header X-Canary=<name> are routed to instances with the label release=<name>.`,
+		Example: `emctl create servicecanary orders-canary --service orders
+emctl create servicecanary orders-beijing --service orders --header X-Location=Beijing --label release=beijing`,
+	}
+
+	flags := &flags.CreateServiceCanary{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		create.RunServiceCanary(cmd, flags)
+	}
+
+	return cmd
+}
+
+func createIngressCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ingress <name>",
+		Short:   "Create an ingress routing a path to a service",
+		Example: `emctl create ingress shop-ingress --service orders --host shop.example.com --path /orders`,
+	}
+
+	flags := &flags.CreateIngress{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		create.RunIngress(cmd, flags)
+	}
+
+	return cmd
+}
+
+func createShadowServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "shadowservice <name>",
+		Short:   "Create a shadow service of a service, the ShadowService add-on is required",
+		Example: `emctl create shadowservice orders-shadow --service orders --namespace shop`,
+	}
+
+	flags := &flags.CreateShadowService{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		create.RunShadowService(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/demo"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// DemoCmd invokes demo sub command entrypoint
+func DemoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Install and uninstall the demo apps of the EaseMesh",
+	}
+
+	cmd.AddCommand(demoInstallCmd())
+	cmd.AddCommand(demoUninstallCmd())
+
+	return cmd
+}
+
+func demoInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the takeaway demo with sidecars injected",
+		Long: `Deploy the takeaway demo of the order, restaurant and delivery services with sidecars
+injected, together with its tenant, tracing, metrics, a canary of the delivery service, an SLO
+and an ingress, then output how to request it and see the mesh working.`,
+		Example: "emctl demo install\nemctl demo install --namespace takeaway --wait-timeout 0",
+	}
+
+	flags := &flags.DemoInstall{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		demo.RunInstall(cmd, flags)
+	}
+
+	return cmd
+}
+
+func demoUninstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "uninstall",
+		Short:   "Uninstall the demo with its mesh resources and namespace",
+		Example: "emctl demo uninstall",
+	}
+
+	flags := &flags.DemoUninstall{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		demo.RunUninstall(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/describe"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// DescribeCmd invokes describe sub command entrypoint
+func DescribeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <resource kind> <resource name>",
+		Short: "Show details of a resource of easemesh with its live status and related objects",
+		Long: `Show the spec of a resource in a human-readable form. For a service, it's joined with
+its instances, the service canaries selecting it, its resilience policies, maintenance,
+recent errors reported by sidecars, and the ingress rules routing to it.`,
+		Example: `emctl describe service vets-service
+emctl describe shadowservice vets-shadow`,
+	}
+
+	flags := &flags.Describe{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		describe.Run(cmd, flags)
+	}
+
+	return cmd
+}
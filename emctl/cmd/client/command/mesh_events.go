@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/events"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// EventsCmd invokes events sub command entrypoint
+func EventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Output events of the control plane, e.g. services registered and canaries activated",
+		Long: `Output events of the control plane in the order they happened: services registered and
+deregistered, canaries activated, configurations rejected and sidecars disconnected.
+With --watch, new events are printed once they happen until interrupted, and the stream
+is resumed from the last event if it's broken.`,
+		Example: `emctl events --since 1h --type canary
+emctl events -w --type sidecar,config -o json`,
+	}
+
+	flags := &flags.Events{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		events.Run(cmd, flags)
+	}
+
+	return cmd
+}
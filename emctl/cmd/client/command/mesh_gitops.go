@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/gitops"
+
+	"github.com/spf13/cobra"
+)
+
+// GitOpsCmd invokes gitops sub command entrypoint
+func GitOpsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gitops",
+		Short: "Sync EaseMesh resources from Git",
+	}
+
+	cmd.AddCommand(gitOpsServeCmd(), gitOpsArgoCDConfigCmd())
+
+	return cmd
+}
+
+func gitOpsServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the GitOps controller syncing EaseMesh resources from a Git repository",
+		Long: `Run the GitOps controller, which polls a branch of a Git repository and applies
+EaseMesh resources under the path continuously. Live resources drifted from Git are
+applied again unless --self-heal=false. A POST to /webhook triggers a sync immediately,
+and GET /status returns the commit and the conditions (Ready, Synced, Drifted) of the last sync.`,
+		Example: "emctl gitops serve --repo https://github.com/megaease/easemesh-gitops.git --branch main --path mesh",
+	}
+
+	flags := &flags.GitOpsServe{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		gitops.RunServe(cmd, flags)
+	}
+
+	return cmd
+}
+
+func gitOpsArgoCDConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "argocd-config",
+		Short: "Generate ArgoCD resource customizations for EaseMesh resources",
+		Long: `Generate ArgoCD resource customizations for EaseMesh CRDs: health checks, and diff
+normalizations for fields defaulted by the API server, so EaseMesh resources show correct
+health and sync status in ArgoCD dashboards. Merge the output into the argocd-cm ConfigMap,
+or the values of the ArgoCD Helm chart with -o helm.`,
+		Example: `kubectl -n argocd patch configmap argocd-cm --type merge --patch "$(emctl gitops argocd-config)"
+emctl gitops argocd-config -o helm > easemesh-values.yaml`,
+	}
+
+	flags := &flags.GitOpsArgoCDConfig{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		gitops.RunArgoCDConfig(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/graph"
+
+	"github.com/spf13/cobra"
+)
+
+// GraphCmd invokes graph sub command entrypoint
+func GraphCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Output the call graph of mesh services built from telemetry of sidecars",
+		Long: `Output the call graph of mesh services in DOT, Mermaid or JSON. Edges are built from
+statistics of egress pipelines reported by sidecars to the control plane, labeled with
+counts of requests and errors. Services are grouped by tenants, so calls across tenants
+and other unexpected dependencies stand out.`,
+		Example: `emctl graph | dot -Tsvg > mesh.svg
+emctl graph --tenant pet --window 1m -o mermaid`,
+	}
+
+	flags := &flags.Graph{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		graph.RunGraph(cmd, flags)
+	}
+
+	return cmd
+}
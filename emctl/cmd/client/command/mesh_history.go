@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/history"
+
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd invokes history sub command entrypoint
+func HistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "Show revision history of a resource of easemesh",
+		Example: "emctl history loadbalance service-001",
+	}
+
+	flags := &flags.History{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		history.RunHistory(cmd, flags)
+	}
+
+	return cmd
+}
+
+// RollbackCmd invokes rollback sub command entrypoint
+func RollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rollback",
+		Short:   "Rollback a resource of easemesh to a revision",
+		Example: "emctl rollback loadbalance service-001 --to-revision 3",
+	}
+
+	flags := &flags.Rollback{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		history.RunRollback(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/ingress"
+
+	"github.com/spf13/cobra"
+)
+
+// IngressCmd invokes ingress sub command entrypoint
+func IngressCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ingress",
+		Short: "Operate the ingress controller",
+	}
+
+	cmd.AddCommand(ingressCertCmd())
+	cmd.AddCommand(ingressCacheCmd())
+
+	return cmd
+}
+
+func ingressCertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cert",
+		Short: "Operate certificates of ingress hosts managed by the ingress controller",
+	}
+
+	cmd.AddCommand(ingressCertStatusCmd())
+
+	return cmd
+}
+
+func ingressCertStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show statuses of certificates obtained by ACME for ingress hosts",
+		Long: `Show every ingress host whose certificate is managed by the ACME client of the ingress
+controller, with the challenge type, whether the certificate is PENDING, READY or FAILED, the time
+left until the certificate in use expires, and the error of the last failed attempt.`,
+		Example: `emctl ingress cert status
+emctl ingress cert status --ingress pet-ingress -o yaml`,
+	}
+
+	flags := &flags.IngressCertStatus{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		ingress.RunCertStatus(cmd, flags)
+	}
+
+	return cmd
+}
+
+func ingressCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Operate responses cached by the ingress controller",
+	}
+
+	cmd.AddCommand(ingressCachePurgeCmd())
+
+	return cmd
+}
+
+func ingressCachePurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Purge responses cached by path filters of ingresses",
+		Long: `Purge responses of the host cached by the cache of path filters of ingresses in all
+ingress controller instances, e.g. after deploying new static assets of a frontend. The path is
+an exact path, or a prefix ending with * which purges all responses under it.`,
+		Example: `emctl ingress cache purge --host app.example.com --path '/assets/*'
+emctl ingress cache purge --host app.example.com --path /index.html`,
+	}
+
+	flags := &flags.IngressCachePurge{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		ingress.RunCachePurge(cmd, flags)
+	}
+
+	return cmd
+}
@@ -21,17 +21,32 @@ import (
 	stdcontext "context"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/controlpanel"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/coredns"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/crd"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/dashboards"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/egressgateway"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/gatewayapi"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/gitops"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/images"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/ingresscontroller"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/installation"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/k8singress"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/maintenance"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/meshcontrolplane"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/networkpolicy"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/operator"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/selfheal"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/shadowservice"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/wizard"
 	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
 	"github.com/megaease/easemeshctl/cmd/common"
 
@@ -39,6 +54,7 @@ import (
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/transport"
 )
 
 // InstallCmd is the entrypoint of the emctl installation
@@ -47,7 +63,7 @@ func InstallCmd() *cobra.Command {
 		Use:     "install",
 		Short:   "Deploy infrastructure components of the EaseMesh",
 		Long:    "",
-		Example: "emctl install coredns --clean-when-failed",
+		Example: "emctl install --rollback-on-failure\nemctl install --cleanup-failed\nemctl install --interactive",
 	}
 	cmd.AddCommand(coredns.CoreDNSCmd())
 
@@ -55,17 +71,25 @@ func InstallCmd() *cobra.Command {
 	flags.AttachCmd(cmd)
 
 	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if flags.Interactive && !runWizard(cmd, flags) {
+			return
+		}
+
+		err := flags.ApplyPreset(cmd)
+		if err != nil {
+			common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+		}
+
 		if flags.SpecFile != "" {
 			var buff []byte
-			var err error
 			buff, err = ioutil.ReadFile(flags.SpecFile)
 			if err != nil {
-				common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+				common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 			}
 
 			err = yaml.Unmarshal(buff, flags)
 			if err != nil {
-				common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+				common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 			}
 		}
 		install(cmd, flags)
@@ -74,6 +98,25 @@ func InstallCmd() *cobra.Command {
 	return cmd
 }
 
+// runWizard sets flags by answers of the installation wizard,
+// it returns false if users decline to install.
+func runWizard(cmd *cobra.Command, flags *flags.Install) bool {
+	if flags.SpecFile != "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "--interactive can't be used with --file")
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	proceed, err := wizard.Run(cmd, flags, client, os.Stdin, os.Stdout)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+	return proceed
+}
+
 // uniqueAddOn removes duplicated add-on names and convert all the names to lower case
 func uniqueAddOn(addOns []string) []string {
 	m := make(map[string]bool)
@@ -90,22 +133,63 @@ func uniqueAddOn(addOns []string) []string {
 }
 
 func install(cmd *cobra.Command, flags *flags.Install) {
-	var err error
-	kubeClient, err := installbase.NewKubernetesClient()
+	if flags.CleanupFailed {
+		cleanupFailedInstall(cmd)
+		return
+	}
+
+	var progress *installbase.ProgressReporter
+	switch flags.ProgressFormat {
+	case installbase.ProgressFormatText:
+	case installbase.ProgressFormatJSON:
+		// NOTE: Stdout is kept for the events, so that pipelines can parse it line by line.
+		common.RedirectInfoToStderr()
+		progress = installbase.NewProgressReporter(os.Stdout)
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unknown progress format %s (support %s, %s)",
+			flags.ProgressFormat, installbase.ProgressFormatText, installbase.ProgressFormatJSON)
+	}
+
+	err := installbase.ValidateSecurityProfile(flags)
 	if err != nil {
-		common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
 	}
 
-	apiExtensionClient, err := installbase.NewKubernetesAPIExtensionsClient()
+	record, err := installbase.NewInstallRecord()
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+	record.ReportTo(progress)
+
+	binding := installbase.NewContextBinding()
+	var snapshot *installbase.InstallSnapshot
+	var wrappers []transport.WrapperFunc
+	if flags.SelfHeal {
+		snapshot = installbase.NewInstallSnapshot()
+		wrappers = append(wrappers, snapshot.WrapTransport)
+	}
+	kubeClient, apiExtensionClient, err := installbase.NewRecordedKubernetesClients(record, binding, flags.RequestTimeout, wrappers...)
 	if err != nil {
-		common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+	dynamicClient, err := installbase.NewRecordedKubernetesDynamicClient(record, binding, flags.RequestTimeout)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 	}
 
+	interrupted, stop := signal.NotifyContext(stdcontext.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	context := &installbase.StageContext{
+		Context:             interrupted,
+		Binding:             binding,
 		Flags:               flags,
 		Client:              kubeClient,
 		Cmd:                 cmd,
 		APIExtensionsClient: apiExtensionClient,
+		DynamicClient:       dynamicClient,
+		Snapshot:            snapshot,
+		Progress:            progress,
 	}
 
 	// TODO: currently, we install add-ons in the 'emctl instll' command, but we need to use a seperated
@@ -113,38 +197,122 @@ func install(cmd *cobra.Command, flags *flags.Install) {
 	var stages []installation.InstallStage
 	if !flags.OnlyAddOn {
 		stages = append(stages,
-			installation.Wrap(crd.PreCheck, crd.Deploy, crd.Clear, crd.DescribePhase),
-			installation.Wrap(controlpanel.PreCheck, controlpanel.Deploy, controlpanel.Clear, controlpanel.DescribePhase),
-			installation.Wrap(operator.PreCheck, operator.Deploy, operator.Clear, operator.DescribePhase),
-			installation.Wrap(ingresscontroller.PreCheck, ingresscontroller.Deploy, ingresscontroller.Clear, ingresscontroller.DescribePhase),
+			installation.Wrap("crd", crd.PreCheck, crd.Deploy, crd.Clear, crd.DescribePhase),
+			installation.Wrap("controlplane", controlpanel.PreCheck, controlpanel.Deploy, controlpanel.Clear, controlpanel.DescribePhase),
+			installation.Wrap("operator", operator.PreCheck, operator.Deploy, operator.Clear, operator.DescribePhase),
+			installation.Wrap("ingresscontroller", ingresscontroller.PreCheck, ingresscontroller.Deploy, ingresscontroller.Clear, ingresscontroller.DescribePhase),
 		)
+
+		if flags.EnableGatewayAPI {
+			stages = append(stages, installation.Wrap("gatewayapi", gatewayapi.PreCheck, gatewayapi.Deploy, gatewayapi.Clear, gatewayapi.DescribePhase))
+		}
+
+		if flags.EnableK8sIngress {
+			stages = append(stages, installation.Wrap("k8singress", k8singress.PreCheck, k8singress.Deploy, k8singress.Clear, k8singress.DescribePhase))
+		}
+
+		if flags.EnableNetworkPolicies {
+			stages = append(stages, installation.Wrap("networkpolicy", networkpolicy.PreCheck, networkpolicy.Deploy, networkpolicy.Clear, networkpolicy.DescribePhase))
+		}
 	}
 
 	for _, addon := range uniqueAddOn(flags.AddOns) {
 		switch addon {
 		case "shadowservice":
-			stages = append(stages, installation.Wrap(shadowservice.PreCheck, shadowservice.Deploy, shadowservice.Clear, shadowservice.DescribePhase))
+			stages = append(stages, installation.Wrap("shadowservice", shadowservice.PreCheck, shadowservice.Deploy, shadowservice.Clear, shadowservice.DescribePhase))
+		case "egressgateway":
+			stages = append(stages, installation.Wrap("egressgateway", egressgateway.PreCheck, egressgateway.Deploy, egressgateway.Clear, egressgateway.DescribePhase))
+		case "gitops":
+			stages = append(stages, installation.Wrap("gitops", gitops.PreCheck, gitops.Deploy, gitops.Clear, gitops.DescribePhase))
+		case "maintenance":
+			stages = append(stages, installation.Wrap("maintenance", maintenance.PreCheck, maintenance.Deploy, maintenance.Clear, maintenance.DescribePhase))
+		case "dashboards":
+			stages = append(stages, installation.Wrap("dashboards", dashboards.PreCheck, dashboards.Deploy, dashboards.Clear, dashboards.DescribePhase))
 		default:
-			common.ExitWithErrorf("unknown add-on name: %s", addon)
+			common.ExitWithCodef(common.ExitCodeValidation, "unknown add-on name: %s", addon)
 		}
 	}
 	if flags.OnlyAddOn && len(stages) == 0 {
-		common.ExitWithErrorf("nothing to install")
+		common.ExitWithCodef(common.ExitCodeValidation, "nothing to install")
+	}
+
+	// NOTE: The MeshControlPlane records the installation at last,
+	// the operator applies changes of it to components afterwards.
+	stages = append(stages, installation.Wrap("meshcontrolplane", meshcontrolplane.PreCheck,
+		meshcontrolplane.Deploy, meshcontrolplane.Clear, meshcontrolplane.DescribePhase))
+
+	// NOTE: The snapshot is saved after all objects are applied.
+	if flags.SelfHeal {
+		stages = append(stages, installation.Wrap("selfheal", selfheal.PreCheck, selfheal.Deploy, selfheal.Clear, selfheal.DescribePhase))
+	}
+
+	// NOTE: Images are pinned ahead of all stages, which deploy them by the pinned image flags.
+	if flags.PinDigests || flags.CosignKey != "" {
+		stages = append([]installation.InstallStage{
+			installation.Wrap("images", images.PreCheck, images.Deploy, images.Clear, images.DescribePhase),
+		}, stages...)
 	}
 
 	install := installation.New(stages...)
 
 	err = install.DoInstallStage(context)
+	// NOTE: Signals terminate emctl as usual after stages, e.g. in the rollback.
+	stop()
 	if err != nil {
-		if flags.CleanWhenFailed {
-			install.ClearResource(context)
+		if flags.RollbackOnFailure {
+			rollbackInstall(record)
+		} else {
+			common.Warnf("resources created by the failed installation are kept, run 'emctl install --cleanup-failed' to delete them")
 		}
-		common.ExitWithErrorf("install mesh infrastructure error: %s", err)
+		common.ExitWithErrorf("install mesh infrastructure error: %w", err)
+	}
+
+	err = record.Remove()
+	if err != nil {
+		common.Warnf("ignored: %v", err)
 	}
 
 	postInstall(context)
 
-	fmt.Println("Done.")
+	if flags.KindPreset {
+		common.Infof("mesh ingress listens on NodePort %d, control plane admin API on NodePort %d, "+
+			"map them by extraPortMappings of kind and set EMCTL_NODE_ADDRESS=127.0.0.1 if nodes aren't reachable from the host",
+			flags.MeshIngressNodePort, flags.MeshControlPlaneAdminNodePort)
+	}
+
+	common.Infof("Done.")
+}
+
+func rollbackInstall(record *installbase.InstallRecord) {
+	common.Infof("Rolling back %d resources created by the installation", len(record.Objects))
+	client, err := installbase.NewKubernetesDynamicClient()
+	if err == nil {
+		err = record.Rollback(client)
+	}
+	if err != nil {
+		common.OutputErrorf("rollback failed: %v, run 'emctl install --cleanup-failed' to retry", err)
+	}
+}
+
+func cleanupFailedInstall(cmd *cobra.Command) {
+	record, err := installbase.LoadInstallRecord()
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	client, err := installbase.NewKubernetesDynamicClient()
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	common.Infof("Deleting %d resources left by the installation started at %s",
+		len(record.Objects), record.StartedAt.Format(time.RFC3339))
+	err = record.Rollback(client)
+	if err != nil {
+		common.ExitWithErrorf("clean up failed installation error: %w", err)
+	}
+
+	common.Infof("Done.")
 }
 
 func postInstall(context *installbase.StageContext) {
@@ -161,6 +329,8 @@ func postInstall(context *installbase.StageContext) {
 		common.OutputErrorf("ignored: new rcfile failed: %v", err)
 		return
 	}
+	// NOTE: Keep the user and the token of the existing rc file, which may not exist.
+	_ = rc.Unmarshal()
 
 	nodes, err := context.Client.CoreV1().Nodes().List(stdcontext.TODO(), metav1.ListOptions{})
 	if err != nil {
@@ -198,6 +368,6 @@ func postInstall(context *installbase.StageContext) {
 	if err != nil {
 		common.OutputError(err)
 	} else {
-		fmt.Printf("run commands file: %s\n", rc.Path())
+		common.Infof("run commands file: %s", rc.Path())
 	}
 }
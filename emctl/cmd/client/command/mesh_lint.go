@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/lint"
+
+	"github.com/spf13/cobra"
+)
+
+// LintCmd invokes lint command entrypoint
+func LintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check EaseMesh resources in files without any cluster",
+		Long: `Check EaseMesh resources in files without any cluster: schemas, references between
+resources, e.g. a canary of a missing service, and suspicious values, e.g. 0% canaries and
+retry storms. It exits with 3 if any error is found, so it fits in CI.`,
+		Example: `emctl lint -f ./mesh
+emctl lint -f ./mesh --rule missing-tenant=off --rule retry-storm=error
+emctl lint -f ./mesh -o sarif > emctl-lint.sarif`,
+		Args: cobra.NoArgs,
+	}
+
+	flags := &flags.Lint{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		lint.Run(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/maintenance"
+
+	"github.com/spf13/cobra"
+)
+
+// MaintenanceCmd invokes maintenance sub command entrypoint
+func MaintenanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Maintain the storage of the control plane, or put services in maintenance",
+	}
+
+	cmd.AddCommand(maintenanceRunCmd())
+	cmd.AddCommand(maintenanceEnableCmd())
+	cmd.AddCommand(maintenanceDisableCmd())
+
+	return cmd
+}
+
+func maintenanceRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Compact and defragment the storage of the control plane on demand",
+		Long: `Compact the history of the etcd embedded in the control plane to the latest revisions,
+then defragment members one by one to release the free space, the leader is the last one.
+It's the same as the scheduled maintenance installed by the add-on maintenance.`,
+		Example: `emctl maintenance run
+emctl maintenance run --retain-revisions 1000 --skip-defrag`,
+	}
+
+	flags := &flags.Maintenance{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		maintenance.Run(cmd, flags)
+	}
+
+	return cmd
+}
+
+func maintenanceEnableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Make sidecars and ingresses return a static response for a service or the whole mesh",
+		Long: `Put a service or the whole mesh in maintenance by a MaintenanceMode resource, sidecars and
+ingresses return the static response instead of forwarding requests to the service, until
+it's disabled. It's the kill switch during incident response and migrations.`,
+		Example: `emctl maintenance enable --service payments --response 503 --retry-after 120
+emctl maintenance enable --all --body 'down for migration' --reason 'db migration'`,
+	}
+
+	flags := &flags.MaintenanceEnable{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		maintenance.RunEnable(cmd, flags)
+	}
+
+	return cmd
+}
+
+func maintenanceDisableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable",
+		Short: "Take a service or the whole mesh out of maintenance",
+		Example: `emctl maintenance disable --service payments
+emctl maintenance disable --all`,
+	}
+
+	flags := &flags.MaintenanceDisable{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		maintenance.RunDisable(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/migrate"
+
+	"github.com/spf13/cobra"
+)
+
+// MigrateCmd invokes migrate sub command entrypoint
+func MigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate things of easemesh between versions",
+	}
+
+	cmd.AddCommand(migrateResourcesCmd())
+
+	return cmd
+}
+
+func migrateResourcesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "Migrate resources in the control plane to the schema of another version",
+		Long: `Fetch resources from the control plane, convert them to the schema of the target version
+with converters of their kinds, validate and rewrite them. Fields which can't be converted
+are reported, and nothing is rewritten unless all resources are converted and valid.`,
+		Example: `emctl migrate resources --from v1 --to v2 --dry-run
+emctl migrate resources --from v1 --to v2`,
+	}
+
+	flags := &flags.MigrateResources{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		migrate.RunResources(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/plugin"
+
+	"github.com/spf13/cobra"
+)
+
+// PluginCmd invokes plugin sub command entrypoint
+func PluginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Distribute emctl as the kubectl plugin kubectl mesh",
+		Long: `emctl runs as the kubectl plugin kubectl mesh once its binary is named kubectl-mesh
+and found in PATH, e.g. installed by krew. Commands and flags are the same as emctl, and
+--kubeconfig and --context work like the ones of kubectl.`,
+	}
+
+	cmd.AddCommand(pluginKrewManifestCmd())
+
+	return cmd
+}
+
+func pluginKrewManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "krew-manifest",
+		Short: "Output the krew manifest of the plugin released with archives of platforms",
+		Example: `make krew
+emctl plugin krew-manifest --version v1.3.0 --archive-dir bin/dist > mesh.yaml`,
+	}
+
+	flags := &flags.PluginKrewManifest{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		plugin.RunKrewManifest(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/policy"
+
+	"github.com/spf13/cobra"
+)
+
+// PolicyCmd invokes policy sub command entrypoint
+func PolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Export governance policies of the mesh for admission controllers",
+	}
+
+	cmd.AddCommand(policyExportGatekeeperCmd())
+
+	return cmd
+}
+
+func policyExportGatekeeperCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-gatekeeper",
+		Short: "Export ConstraintTemplates and Constraints of OPA Gatekeeper enforcing mesh governance",
+		Long: `Export ConstraintTemplates and Constraints of OPA Gatekeeper, which require mesh services
+to declare their tenants, and images of sidecars and initializers to come from approved registries.
+Gatekeeper validates Pods after the operator injects sidecars, so the injected images are checked too.`,
+		Example: `emctl policy export-gatekeeper | kubectl apply -f -
+emctl policy export-gatekeeper --approved-registries registry.example.com/mesh/ --enforcement-action dryrun`,
+	}
+
+	flags := &flags.PolicyExportGatekeeper{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		policy.RunExportGatekeeper(cmd, flags)
+	}
+
+	return cmd
+}
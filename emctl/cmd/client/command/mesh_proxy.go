@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/proxy"
+
+	"github.com/spf13/cobra"
+)
+
+// ProxyCmd invokes proxy sub command entrypoint
+func ProxyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Expose the admin API of the control plane locally",
+		Long: `Expose the admin API of the control plane on a local port until interrupted. Requests are
+forwarded through the service proxy of the Kubernetes API server, authenticated by the
+kubeconfig, and connections are re-established once they're broken, so other tools and
+the SDK could access localhost instead of negotiating their own connections.`,
+		Example: `emctl proxy &
+emctl get service --server 127.0.0.1:2381
+emctl proxy --port 12381 --mesh-namespace mesh-demo`,
+	}
+
+	flags := &flags.Proxy{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		proxy.Run(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/proxystatus"
+
+	"github.com/spf13/cobra"
+)
+
+// ProxyStatusCmd invokes proxy-status sub command entrypoint
+func ProxyStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy-status",
+		Short: "Show whether sidecars are in sync with the control plane",
+		Long: `Show every sidecar connected to the control plane with the configuration version it acked,
+the latest version of it, and its last heartbeat. Sidecars lagging behind the latest version are STALE,
+and sidecars without heartbeats for the instance expiry of the mesh controller are DISCONNECTED.`,
+		Example: `emctl proxy-status
+emctl proxy-status --service order-mesh -o yaml`,
+	}
+
+	flags := &flags.ProxyStatus{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		proxystatus.Run(cmd, flags)
+	}
+
+	return cmd
+}
@@ -22,9 +22,18 @@ import (
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/controlpanel"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/crd"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/dashboards"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/egressgateway"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/gatewayapi"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/gitops"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/ingresscontroller"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/installation"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/k8singress"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/maintenance"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/meshcontrolplane"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/networkpolicy"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/operator"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/selfheal"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/shadowservice"
 	"github.com/megaease/easemeshctl/cmd/common"
 
@@ -34,12 +43,17 @@ import (
 func reset(cmd *cobra.Command, resetFlags *flags.Reset) {
 	kubeClient, err := installbase.NewKubernetesClient()
 	if err != nil {
-		common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 	}
 
 	apiExtensionClient, err := installbase.NewKubernetesAPIExtensionsClient()
 	if err != nil {
-		common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	dynamicClient, err := installbase.NewKubernetesDynamicClient()
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 	}
 
 	var clearFuncs []installation.ClearFunc
@@ -48,17 +62,42 @@ func reset(cmd *cobra.Command, resetFlags *flags.Reset) {
 			switch addon {
 			case "shadowservice":
 				clearFuncs = append(clearFuncs, shadowservice.Clear)
+			case "egressgateway":
+				clearFuncs = append(clearFuncs, egressgateway.Clear)
+			case "gitops":
+				clearFuncs = append(clearFuncs, gitops.Clear)
+			case "maintenance":
+				clearFuncs = append(clearFuncs, maintenance.Clear)
+			case "dashboards":
+				clearFuncs = append(clearFuncs, dashboards.Clear)
 			default:
-				common.ExitWithErrorf("unknown add-on name: %s", addon)
+				common.ExitWithCodef(common.ExitCodeValidation, "unknown add-on name: %s", addon)
 			}
 		}
 		if len(clearFuncs) == 0 {
-			common.ExitWithErrorf("nothing to reset")
+			common.ExitWithCodef(common.ExitCodeValidation, "nothing to reset")
 		}
+		clearFuncs = append(clearFuncs, func(ctx *installbase.StageContext) error {
+			return meshcontrolplane.RemoveAddOns(ctx, resetFlags.AddOns)
+		})
+		// NOTE: Remove them from the snapshot ahead, so the operator doesn't recreate them.
+		clearFuncs = append([]installation.ClearFunc{func(ctx *installbase.StageContext) error {
+			return selfheal.RemoveStages(ctx, resetFlags.AddOns)
+		}}, clearFuncs...)
 	} else {
 		// clear everything
 		clearFuncs = []installation.ClearFunc{
+			// NOTE: Delete them ahead, so the operator stops applying them to components.
+			selfheal.Clear,
+			meshcontrolplane.Clear,
+			dashboards.Clear,
+			maintenance.Clear,
+			gitops.Clear,
 			shadowservice.Clear,
+			egressgateway.Clear,
+			networkpolicy.Clear,
+			gatewayapi.Clear,
+			k8singress.Clear,
 			ingresscontroller.Clear,
 			operator.Clear,
 			controlpanel.Clear,
@@ -71,6 +110,7 @@ func reset(cmd *cobra.Command, resetFlags *flags.Reset) {
 		Client:              kubeClient,
 		Flags:               &flags.Install{OperationGlobal: resetFlags.OperationGlobal},
 		APIExtensionsClient: apiExtensionClient,
+		DynamicClient:       dynamicClient,
 		ClearFuncs:          nil,
 	}
 
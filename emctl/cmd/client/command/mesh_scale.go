@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/scale"
+
+	"github.com/spf13/cobra"
+)
+
+// ScaleCmd invokes scale sub command entrypoint
+func ScaleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Scale components of the EaseMesh",
+	}
+
+	cmd.AddCommand(scaleControlPlaneCmd())
+
+	return cmd
+}
+
+func scaleControlPlaneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "control-plane",
+		Short: "Scale members of the control plane with the etcd cluster membership managed",
+		Long: `Scale the control plane StatefulSet one member at a time. A new member is added to
+the etcd cluster before it starts, and a removed member is purged from the cluster after it
+stops, so the cluster keeps the quorum. Scaling with kubectl directly breaks the membership.`,
+		Example: "emctl scale control-plane --replicas 5",
+	}
+
+	flags := &flags.ScaleControlPlane{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		scale.RunControlPlane(cmd, flags)
+	}
+
+	return cmd
+}
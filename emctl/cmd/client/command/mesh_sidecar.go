@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/sidecar"
+
+	"github.com/spf13/cobra"
+)
+
+// SidecarCmd invokes sidecar sub command entrypoint
+func SidecarCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sidecar",
+		Short: "Manage sidecars injected into pods",
+	}
+
+	cmd.AddCommand(sidecarVersionsCmd())
+	cmd.AddCommand(sidecarUpgradeCmd())
+	cmd.AddCommand(sidecarConfigDumpCmd())
+
+	return cmd
+}
+
+func sidecarVersionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "List pods grouped by versions of their sidecar images",
+		Long: `List pods injected with sidecars grouped by sidecar images, and report the pods running
+images other than the one injected by the operator now, which are upgraded by emctl sidecar upgrade.`,
+		Example: `emctl sidecar versions
+emctl sidecar versions --namespace shop -o yaml`,
+	}
+
+	flags := &flags.SidecarVersions{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		sidecar.RunVersions(cmd, flags)
+	}
+
+	return cmd
+}
+
+func sidecarUpgradeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Restart workloads in batches to roll out the sidecar image injected by the operator",
+		Long: `Find Deployments, StatefulSets and DaemonSets in the namespace with pods running sidecars other than
+the one injected by the operator, and restart them in batches of at most --max-unavailable workloads.
+The next batch is restarted after all workloads of the previous one are rolled out, and the upgrade
+stops at the first batch failing to roll out in --wait-timeout.`,
+		Example: `emctl sidecar upgrade --namespace shop --max-unavailable 20% --dry-run
+emctl sidecar upgrade --namespace shop --max-unavailable 20%`,
+	}
+
+	flags := &flags.SidecarUpgrade{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		sidecar.RunUpgrade(cmd, flags)
+	}
+
+	return cmd
+}
+
+func sidecarConfigDumpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config-dump <pod>",
+		Short: "Output the effective configuration of the sidecar in a pod",
+		Long: `Output the Easegress objects of the sidecar in a pod, including the routes, the backends and the
+policies it received from the control plane, and the status of them, to debug traffic not matching.
+The admin API of the sidecar only listens on localhost of the pod, so it's reached by kubectl
+port-forward, which is authenticated by the kubeconfig and bound to localhost.`,
+		Example: `emctl sidecar config-dump vets-service-5d4f7c9b8-x2x7m --namespace spring-petclinic
+emctl sidecar config-dump vets-service-5d4f7c9b8-x2x7m -n spring-petclinic -o json`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	flags := &flags.SidecarConfigDump{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		sidecar.RunConfigDump(cmd, flags, args[0])
+	}
+
+	return cmd
+}
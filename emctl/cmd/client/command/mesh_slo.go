@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/slo"
+
+	"github.com/spf13/cobra"
+)
+
+// SLOCmd invokes slo sub command entrypoint
+func SLOCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "slo",
+		Short: "Track service level objectives and their error budgets",
+	}
+
+	cmd.AddCommand(sloStatusCmd())
+
+	return cmd
+}
+
+func sloStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [SLO names]",
+		Short: "Show current burn rates and remaining error budgets of SLOs",
+		Long: `Show the status of every objective of SLOs, computed against statistics of requests
+reported by sidecars: the percentage of good requests, the burn rates of the error budget,
+the remaining budget, and the time left until the budget is exhausted. A burn rate of 1
+means the budget is exhausted right at the end of the window of the SLO.`,
+		Example: `emctl slo status
+emctl slo status order-availability -o json`,
+	}
+
+	flags := &flags.SLOStatus{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		slo.RunStatus(cmd, flags)
+	}
+
+	return cmd
+}
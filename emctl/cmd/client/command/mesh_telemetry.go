@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/usagereport"
+
+	"github.com/spf13/cobra"
+)
+
+// TelemetryCmd invokes telemetry sub command entrypoint
+func TelemetryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "Manage the opt-in report of anonymous usage of emctl",
+		Long: `Once turned on, emctl reports the command name, names of set flags, the duration,
+success or failure, the exit code, its version, os and arch to the endpoint after every command.
+Arguments, flag values, error messages and anything identifying the user, the host or the cluster
+are never reported. It's off by default, and the env EMCTL_TELEMETRY=off or DO_NOT_TRACK=1
+turns it off whatever ~/.emctlrc says.`,
+	}
+
+	cmd.AddCommand(telemetryOnCmd())
+	cmd.AddCommand(telemetryOffCmd())
+	cmd.AddCommand(telemetryStatusCmd())
+
+	return cmd
+}
+
+func telemetryOnCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "on",
+		Short:   "Report anonymous usage of emctl to the endpoint",
+		Example: "emctl telemetry on --endpoint https://telemetry.example.com/emctl",
+		Args:    cobra.NoArgs,
+	}
+
+	flags := &flags.TelemetryOn{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		usagereport.RunOn(cmd, flags)
+	}
+
+	return cmd
+}
+
+func telemetryOffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "off",
+		Short:   "Stop reporting usage of emctl",
+		Example: "emctl telemetry off",
+		Args:    cobra.NoArgs,
+	}
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		usagereport.RunOff(cmd)
+	}
+
+	return cmd
+}
+
+func telemetryStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "status",
+		Short:   "Show whether usage of emctl is reported and where to",
+		Example: "emctl telemetry status",
+		Args:    cobra.NoArgs,
+	}
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		usagereport.RunStatus(cmd)
+	}
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/tenant"
+
+	"github.com/spf13/cobra"
+)
+
+// TenantCmd invokes tenant sub command entrypoint
+func TenantCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "Manage tenants of easemesh",
+	}
+
+	cmd.AddCommand(tenantPolicyCmd())
+
+	return cmd
+}
+
+func tenantPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage default policies of a tenant inherited by its services",
+		Long: `Manage default policies (resilience, loadBalance, observability) of a tenant.
+Services registered in the tenant inherit these policies unless they have their own,
+the precedence is: service spec > tenant policy > mesh defaults.`,
+	}
+
+	cmd.AddCommand(tenantPolicySetCmd(), tenantPolicyGetCmd())
+
+	return cmd
+}
+
+func tenantPolicySetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "set",
+		Short:   "Set default policies of a tenant",
+		Example: "emctl tenant policy set pet -f pet-policy.yaml",
+	}
+
+	flags := &flags.TenantPolicySet{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		tenant.RunPolicySet(cmd, flags)
+	}
+
+	return cmd
+}
+
+func tenantPolicyGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Get default policies of a tenant",
+		Example: "emctl tenant policy get pet -o yaml",
+	}
+
+	flags := &flags.TenantPolicyGet{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		tenant.RunPolicyGet(cmd, flags)
+	}
+
+	return cmd
+}
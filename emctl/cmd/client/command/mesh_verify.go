@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/verify"
+
+	"github.com/spf13/cobra"
+)
+
+// VerifyInstallCmd invokes verify-install sub command entrypoint
+func VerifyInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-install",
+		Short: "Verify the installation of the EaseMesh end to end with sample apps",
+		Long: `Deploy a pair of sample apps with sidecars injected in a temporary namespace,
+request them through the mesh ingress, check the tracing and metrics of requests,
+then tear everything down and report a pass/fail summary.`,
+		Example: "emctl verify-install --ingress-address http://192.168.0.10:30080",
+	}
+
+	flags := &flags.VerifyInstall{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		verify.Run(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/versioncheck"
+
+	"github.com/spf13/cobra"
+)
+
+// VersionCmd invokes version command entrypoint
+func VersionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show versions of emctl, the control plane and the operator",
+		Long: `Show versions of emctl, the control plane and the operator, with their skews.
+emctl works with components at most one minor version away from it, and blocks commands
+if a component is of another major version or doesn't serve the API version of emctl.`,
+		Example: `emctl version
+emctl version -o json
+emctl version --client`,
+		Args: cobra.NoArgs,
+	}
+
+	flags := &flags.Version{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		versioncheck.RunVersion(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/vm"
+
+	"github.com/spf13/cobra"
+)
+
+// VMCmd invokes vm sub command entrypoint
+func VMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vm",
+		Short: "Run workloads outside Kubernetes in the EaseMesh",
+	}
+
+	cmd.AddCommand(vmGenerateCmd())
+	cmd.AddCommand(vmRegisterCmd())
+	cmd.AddCommand(vmListCmd())
+	cmd.AddCommand(vmDeregisterCmd())
+
+	return cmd
+}
+
+func vmGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the sidecar and EaseAgent configuration of a VM or bare-metal workload",
+		Long: `Generate the sidecar configuration of a workload running on a VM or bare-metal host,
+with a docker-compose file or systemd units running the sidecar. The sidecar joins the
+same control plane as the sidecars in Kubernetes, and registers the workload as an
+instance of the mesh service, so VM and Kubernetes instances form one mesh.`,
+		Example: `emctl vm generate --service-name order-service --application-ip 10.0.0.8 --application-port 8080
+emctl vm generate --service-name order-service --application-ip 10.0.0.8 --application-port 8080 --format systemd -o order`,
+	}
+
+	flags := &flags.VMGenerate{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		vm.RunGenerate(cmd, flags)
+	}
+
+	return cmd
+}
+
+func vmRegisterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register",
+		Short: "Register a VM or bare-metal workload into a mesh service with a bootstrap token",
+		Long: `Register a workload running on a VM or bare-metal host into a mesh service. It creates a
+bootstrap token of the VM in the cluster, and writes a tarball holding the sidecar
+configuration, the CA certificate of the API server, the token and optionally the sidecar
+binary. Copy the tarball to the VM, the token lets the VM follow endpoints of the control
+plane until it expires.`,
+		Example: `emctl vm register --service-name order-service --application-ip 10.0.0.8 --application-port 8080 --format systemd
+emctl vm register --service-name order-service --application-ip 10.0.0.8 --application-port 8080 --token-ttl 2h --sidecar-binary ./easegress-server`,
+	}
+
+	flags := &flags.VMRegister{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		vm.RunRegister(cmd, flags)
+	}
+
+	return cmd
+}
+
+func vmListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List registered VMs with the status of their instances and bootstrap tokens",
+		Example: "emctl vm list",
+	}
+
+	flags := &flags.VMList{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		vm.RunList(cmd, flags)
+	}
+
+	return cmd
+}
+
+func vmDeregisterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deregister <instance name>",
+		Short:   "Deregister a VM, revoking its bootstrap token and deleting its service instance",
+		Example: "emctl vm deregister order-service-10-0-0-8",
+	}
+
+	flags := &flags.VMDeregister{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		vm.RunDeregister(cmd, flags)
+	}
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/wait"
+
+	"github.com/spf13/cobra"
+)
+
+// WaitCmd invokes wait command entrypoint
+func WaitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for conditions of EaseMesh resources",
+		Long: `Wait for conditions of EaseMesh resources reported by the control plane, or their deletion.
+Conditions of a revision older than the latest one of the resource are not counted. It exits
+with 7 if the condition isn't met in --timeout, so deployment pipelines could gate on it.`,
+		Example: `emctl wait servicecanary/pet-beta --for=condition=Active --timeout=5m
+emctl wait service pet --for=condition=Ready
+emctl wait ingress/pet-ingress ingressport/pet-port --for=condition=Ready=True
+emctl wait servicecanary/pet-beta --for=delete`,
+	}
+
+	flags := &flags.Wait{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		wait.Run(cmd, flags)
+	}
+
+	return cmd
+}
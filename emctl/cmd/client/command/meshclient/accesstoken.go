@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// AccessTokenGetter represents an AccessToken accessor
+type AccessTokenGetter interface {
+	AccessToken() AccessTokenInterface
+}
+
+// AccessTokenInterface captures the set of operations for interacting with the EaseMesh REST apis of access tokens.
+type AccessTokenInterface interface {
+	// Create creates an access token, whose secret is only returned here.
+	Create(context.Context, *resource.AccessTokenRequest) (*resource.AccessToken, error)
+	// Delete revokes the access token of the id.
+	Delete(context.Context, string) error
+}
+
+type accessTokenGetter struct {
+	client *meshClient
+}
+
+func (a *accessTokenGetter) AccessToken() AccessTokenInterface {
+	return &accessTokenInterface{client: a.client}
+}
+
+type accessTokenInterface struct {
+	client *meshClient
+}
+
+func (a *accessTokenInterface) Create(ctx context.Context, request *resource.AccessTokenRequest) (*resource.AccessToken, error) {
+	if err := a.client.requireAPI(ctx, APIAccessTokens); err != nil {
+		return nil, err
+	}
+	url := "http://" + a.client.server + MeshAccessTokensURL
+	result, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, request, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call POST %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			token := &resource.AccessToken{}
+			err := json.Unmarshal(b, token)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal access token result")
+			}
+			return token, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*resource.AccessToken), nil
+}
+
+func (a *accessTokenInterface) Delete(ctx context.Context, id string) error {
+	if err := a.client.requireAPI(ctx, APIAccessTokens); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+a.client.server+MeshAccessTokenURL, id)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "revoke access token %s", id)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
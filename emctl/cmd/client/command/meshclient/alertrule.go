@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// AlertRuleGetter represents an alert rule resource accessor
+type AlertRuleGetter interface {
+	AlertRule() AlertRuleInterface
+}
+
+// AlertRuleInterface captures the set of operations for interacting with the EaseMesh REST apis of the alert rule resource.
+type AlertRuleInterface interface {
+	Get(context.Context, string) (*resource.AlertRule, error)
+	Patch(context.Context, *resource.AlertRule) error
+	Create(context.Context, *resource.AlertRule) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.AlertRule, error)
+}
+
+type alertRuleGetter struct {
+	client *meshClient
+}
+
+func (g *alertRuleGetter) AlertRule() AlertRuleInterface {
+	return &alertRuleInterface{client: g.client}
+}
+
+type alertRuleInterface struct {
+	client *meshClient
+}
+
+func (t *alertRuleInterface) Get(ctx context.Context, name string) (*resource.AlertRule, error) {
+	if err := t.client.requireAPI(ctx, APIAlertRules); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshAlertRuleURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get alert rule %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.AlertRuleObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to alert rule")
+			}
+			return resource.ToAlertRule(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.AlertRule), nil
+}
+
+func (t *alertRuleInterface) Patch(ctx context.Context, alertRule *resource.AlertRule) error {
+	if err := t.client.requireAPI(ctx, APIAlertRules); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshAlertRuleURL, alertRule.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, alertRule.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch alert rule %s", alertRule.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch alert rule %s", alertRule.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *alertRuleInterface) Create(ctx context.Context, alertRule *resource.AlertRule) error {
+	if err := t.client.requireAPI(ctx, APIAlertRules); err != nil {
+		return err
+	}
+	url := "http://" + t.client.server + MeshAlertRulesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, alertRule.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create alert rule %s", alertRule.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *alertRuleInterface) Delete(ctx context.Context, name string) error {
+	if err := t.client.requireAPI(ctx, APIAlertRules); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshAlertRuleURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete alert rule %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *alertRuleInterface) List(ctx context.Context) ([]*resource.AlertRule, error) {
+	if err := t.client.requireAPI(ctx, APIAlertRules); err != nil {
+		return nil, err
+	}
+	url := "http://" + t.client.server + MeshAlertRulesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list alert rule")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.AlertRuleObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal alert rule result")
+			}
+
+			results := []*resource.AlertRule{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToAlertRule(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.AlertRule), err
+}
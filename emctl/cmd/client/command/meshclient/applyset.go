@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// ApplySetGetter represents an ApplySet accessor
+type ApplySetGetter interface {
+	ApplySet() ApplySetInterface
+}
+
+// ApplySetInterface captures the set of operations for interacting with the EaseMesh REST apis of the apply set.
+type ApplySetInterface interface {
+	Get(context.Context, string) (*resource.ApplySet, error)
+	Patch(context.Context, *resource.ApplySet) error
+	Create(context.Context, *resource.ApplySet) error
+}
+
+type applySetGetter struct {
+	client *meshClient
+}
+
+func (a *applySetGetter) ApplySet() ApplySetInterface {
+	return &applySetInterface{client: a.client}
+}
+
+type applySetInterface struct {
+	client *meshClient
+}
+
+func (a *applySetInterface) Get(ctx context.Context, name string) (*resource.ApplySet, error) {
+	if err := a.client.requireAPI(ctx, APIApplySets); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+a.client.server+MeshApplySetURL, url.PathEscape(name))
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get apply set %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.ApplySetObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to ApplySet")
+			}
+			return resource.ToApplySet(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.ApplySet), nil
+}
+
+func (a *applySetInterface) Patch(ctx context.Context, applySet *resource.ApplySet) error {
+	if err := a.client.requireAPI(ctx, APIApplySets); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+a.client.server+MeshApplySetURL, url.PathEscape(applySet.Name()))
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, applySet.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch apply set %s", applySet.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch apply set %s", applySet.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (a *applySetInterface) Create(ctx context.Context, applySet *resource.ApplySet) error {
+	if err := a.client.requireAPI(ctx, APIApplySets); err != nil {
+		return err
+	}
+	url := "http://" + a.client.server + MeshApplySetsURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, applySet.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create apply set %s", applySet.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// AuditGetter represents an AuditRecord accessor
+type AuditGetter interface {
+	Audit() AuditInterface
+}
+
+// AuditListOptions filters audit records, empty fields match all.
+type AuditListOptions struct {
+	Since time.Time
+	Kind  string
+	Name  string
+	User  string
+}
+
+// AuditInterface captures the set of operations for interacting with the EaseMesh REST apis of the audit log.
+type AuditInterface interface {
+	List(context.Context, *AuditListOptions) ([]*resource.AuditRecord, error)
+}
+
+type auditGetter struct {
+	client *meshClient
+}
+
+func (a *auditGetter) Audit() AuditInterface {
+	return &auditInterface{client: a.client}
+}
+
+type auditInterface struct {
+	client *meshClient
+}
+
+func (a *auditInterface) List(ctx context.Context, options *AuditListOptions) ([]*resource.AuditRecord, error) {
+	if err := a.client.requireAPI(ctx, APIAudits); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if !options.Since.IsZero() {
+		query.Set("since", options.Since.UTC().Format(time.RFC3339))
+	}
+	if options.Kind != "" {
+		query.Set("kind", options.Kind)
+	}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+	if options.User != "" {
+		query.Set("user", options.User)
+	}
+
+	url := "http://" + a.client.server + MeshAuditsURL
+	if len(query) != 0 {
+		url += "?" + query.Encode()
+	}
+
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list audit records")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.AuditRecordObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal audit records result")
+			}
+
+			results := []*resource.AuditRecord{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToAuditRecord(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.AuditRecord), err
+}
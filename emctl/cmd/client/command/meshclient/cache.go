@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// MeshMembersURL is the path of members of the control plane.
+	MeshMembersURL = apiURL + "/status/members"
+
+	customResourceKindsCacheFile = "customresourcekinds.json"
+)
+
+type (
+	// discoveryCache caches custom resource kinds of control planes
+	// with their schemas in files, see SetDiscoveryCache.
+	discoveryCache struct {
+		mutex sync.Mutex
+		dir   string
+		ttl   time.Duration
+		// loaded are kinds loaded by this process keyed by servers,
+		// which are used without checking the control plane again.
+		loaded map[string][]*resource.CustomResourceKind
+	}
+
+	customResourceKindsCache struct {
+		FetchedAt           time.Time    `json:"fetchedAt"`
+		ControlPlaneVersion string       `json:"controlPlaneVersion"`
+		Kinds               []cachedKind `json:"kinds"`
+	}
+
+	cachedKind struct {
+		Name       string                 `json:"name"`
+		JSONSchema resource.DynamicObject `json:"jsonSchema,omitempty"`
+	}
+
+	cachedCustomResourceKindInterface struct {
+		client   *meshClient
+		delegate CustomResourceKindInterface
+	}
+)
+
+var cache = &discoveryCache{loaded: map[string][]*resource.CustomResourceKind{}}
+
+// SetDiscoveryCache makes mesh clients cache custom resource kinds with
+// their schemas in the directory for the ttl, empty dir disables the cache.
+func SetDiscoveryCache(dir string, ttl time.Duration) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.dir, cache.ttl = dir, ttl
+}
+
+func (c *discoveryCache) enabled() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.dir != "" && c.ttl > 0
+}
+
+func (c *discoveryCache) path(server string) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// NOTE: Every control plane has its own cache, e.g. 127.0.0.1:2381 is cached in 127.0.0.1_2381.
+	return filepath.Join(c.dir, strings.NewReplacer(":", "_", "/", "_").Replace(server), customResourceKindsCacheFile)
+}
+
+func (c *discoveryCache) load(server, version string) []*resource.CustomResourceKind {
+	buff, err := ioutil.ReadFile(c.path(server))
+	if err != nil {
+		return nil
+	}
+
+	cached := &customResourceKindsCache{}
+	err = json.Unmarshal(buff, cached)
+	if err != nil {
+		common.Debugf("ignore broken cache of custom resource kinds: %v", err)
+		return nil
+	}
+
+	c.mutex.Lock()
+	ttl := c.ttl
+	c.mutex.Unlock()
+	if time.Since(cached.FetchedAt) > ttl || cached.ControlPlaneVersion != version {
+		return nil
+	}
+
+	kinds := []*resource.CustomResourceKind{}
+	for _, kind := range cached.Kinds {
+		kinds = append(kinds, &resource.CustomResourceKind{
+			MeshResource: resource.NewCustomResourceKindResource(resource.DefaultAPIVersion, kind.Name),
+			Spec:         &resource.CustomResourceKindSpec{JSONSchema: kind.JSONSchema},
+		})
+	}
+	return kinds
+}
+
+func (c *discoveryCache) store(server, version string, kinds []*resource.CustomResourceKind) {
+	cached := &customResourceKindsCache{FetchedAt: time.Now(), ControlPlaneVersion: version}
+	for _, kind := range kinds {
+		k := cachedKind{Name: kind.Name()}
+		if kind.Spec != nil {
+			k.JSONSchema = kind.Spec.JSONSchema
+		}
+		cached.Kinds = append(cached.Kinds, k)
+	}
+
+	buff, err := json.Marshal(cached)
+	if err == nil {
+		path := c.path(server)
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = ioutil.WriteFile(path, buff, 0o644)
+		}
+	}
+	if err != nil {
+		common.Debugf("cache custom resource kinds failed: %v", err)
+	}
+}
+
+func (c *discoveryCache) memorized(server string) ([]*resource.CustomResourceKind, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	kinds, ok := c.loaded[server]
+	return kinds, ok
+}
+
+func (c *discoveryCache) memorize(server string, kinds []*resource.CustomResourceKind) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.loaded[server] = kinds
+}
+
+func (c *discoveryCache) invalidate(server string) {
+	c.mutex.Lock()
+	delete(c.loaded, server)
+	c.mutex.Unlock()
+
+	err := os.Remove(c.path(server))
+	if err != nil && !os.IsNotExist(err) {
+		common.Warnf("invalidate cache of custom resource kinds failed: %v", err)
+	}
+}
+
+// controlPlaneVersion identifies the running members of the control plane
+// by their names and start times, so it changes once the control plane
+// restarts, e.g. it's upgraded. It's empty if members can't be got.
+func (k *cachedCustomResourceKindInterface) controlPlaneVersion(ctx context.Context) string {
+	url := "http://" + k.client.server + MeshMembersURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			members := []map[string]interface{}{}
+			err := yaml.Unmarshal(b, &members)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal members of the control plane")
+			}
+
+			ids := []string{}
+			for _, member := range members {
+				ids = append(ids, fmt.Sprintf("%v@%v", lookup(member, "options", "name"), lookup(member, "etcd", "startTime")))
+			}
+			sort.Strings(ids)
+			return strings.Join(ids, ","), nil
+		})
+	if err != nil {
+		common.Debugf("get version of the control plane failed: %v", err)
+		return ""
+	}
+	return result.(string)
+}
+
+func lookup(object interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		switch m := object.(type) {
+		case map[string]interface{}:
+			object = m[key]
+		case map[interface{}]interface{}:
+			object = m[key]
+		default:
+			return nil
+		}
+	}
+	return object
+}
+
+func (k *cachedCustomResourceKindInterface) Get(ctx context.Context, customResourceKindID string) (*resource.CustomResourceKind, error) {
+	kinds, err := k.List(ctx)
+	if err == nil {
+		for _, kind := range kinds {
+			if kind.Name() == customResourceKindID {
+				return kind, nil
+			}
+		}
+	}
+
+	// NOTE: The kind may be created after the cache, so it's got directly.
+	return k.delegate.Get(ctx, customResourceKindID)
+}
+
+func (k *cachedCustomResourceKindInterface) List(ctx context.Context) ([]*resource.CustomResourceKind, error) {
+	if kinds, ok := cache.memorized(k.client.server); ok {
+		return kinds, nil
+	}
+
+	version := k.controlPlaneVersion(ctx)
+	kinds := cache.load(k.client.server, version)
+	if kinds == nil {
+		var err error
+		kinds, err = k.delegate.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cache.store(k.client.server, version, kinds)
+	}
+
+	cache.memorize(k.client.server, kinds)
+	return kinds, nil
+}
+
+func (k *cachedCustomResourceKindInterface) Patch(ctx context.Context, customResourceKind *resource.CustomResourceKind) error {
+	defer cache.invalidate(k.client.server)
+	return k.delegate.Patch(ctx, customResourceKind)
+}
+
+func (k *cachedCustomResourceKindInterface) Create(ctx context.Context, customResourceKind *resource.CustomResourceKind) error {
+	defer cache.invalidate(k.client.server)
+	return k.delegate.Create(ctx, customResourceKind)
+}
+
+func (k *cachedCustomResourceKindInterface) Delete(ctx context.Context, customResourceKindID string) error {
+	defer cache.invalidate(k.client.server)
+	return k.delegate.Delete(ctx, customResourceKindID)
+}
+
+var _ CustomResourceKindInterface = &cachedCustomResourceKindInterface{}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestDiscoveryCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emctl-cache")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &discoveryCache{dir: dir, ttl: time.Minute, loaded: map[string][]*resource.CustomResourceKind{}}
+	server := "127.0.0.1:2381"
+	if kinds := c.load(server, "v1"); kinds != nil {
+		t.Fatalf("expected nothing cached, got %v", kinds)
+	}
+
+	c.store(server, "v1", []*resource.CustomResourceKind{{
+		MeshResource: resource.NewCustomResourceKindResource(resource.DefaultAPIVersion, "ShadowService"),
+		Spec:         &resource.CustomResourceKindSpec{JSONSchema: resource.DynamicObject{"type": "object"}},
+	}})
+
+	kinds := c.load(server, "v1")
+	if len(kinds) != 1 || kinds[0].Name() != "ShadowService" || kinds[0].Spec.JSONSchema["type"] != "object" {
+		t.Fatalf("expected the cached kind, got %v", kinds)
+	}
+
+	if kinds := c.load(server, "v2"); kinds != nil {
+		t.Fatalf("expected the cache discarded once the control plane version changed, got %v", kinds)
+	}
+
+	c.ttl = time.Nanosecond
+	if kinds := c.load(server, "v1"); kinds != nil {
+		t.Fatalf("expected the cache expired, got %v", kinds)
+	}
+
+	c.ttl = time.Minute
+	c.invalidate(server)
+	if kinds := c.load(server, "v1"); kinds != nil {
+		t.Fatalf("expected the cache invalidated, got %v", kinds)
+	}
+}
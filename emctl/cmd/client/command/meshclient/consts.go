@@ -68,6 +68,130 @@ const (
 	// MeshIngressURL is the mesh ingress path.
 	MeshIngressURL = apiURL + "/mesh/ingresses/%s"
 
+	// MeshExternalServicesURL is the mesh external service prefix.
+	MeshExternalServicesURL = apiURL + "/mesh/externalservices"
+
+	// MeshExternalServiceURL is the mesh external service path.
+	MeshExternalServiceURL = apiURL + "/mesh/externalservices/%s"
+
+	// MeshTenantPoliciesURL is the mesh tenant policy prefix.
+	MeshTenantPoliciesURL = apiURL + "/mesh/tenantpolicies"
+
+	// MeshTenantPolicyURL is the mesh tenant policy path.
+	MeshTenantPolicyURL = apiURL + "/mesh/tenantpolicies/%s"
+
+	// MeshSLOsURL is the mesh SLO prefix.
+	MeshSLOsURL = apiURL + "/mesh/slos"
+
+	// MeshSLOURL is the mesh SLO path.
+	MeshSLOURL = apiURL + "/mesh/slos/%s"
+
+	// MeshServiceCanariesURL is the mesh service canary prefix.
+	MeshServiceCanariesURL = apiURL + "/mesh/servicecanaries"
+
+	// MeshServiceCanaryObjectURL is the path of a mesh service canary object.
+	MeshServiceCanaryObjectURL = apiURL + "/mesh/servicecanaries/%s"
+
+	// MeshAlertRulesURL is the mesh alert rule prefix.
+	MeshAlertRulesURL = apiURL + "/mesh/alertrules"
+
+	// MeshAlertRuleURL is the mesh alert rule path.
+	MeshAlertRuleURL = apiURL + "/mesh/alertrules/%s"
+
+	// MeshMessagingPoliciesURL is the mesh messaging policy prefix.
+	MeshMessagingPoliciesURL = apiURL + "/mesh/messagingpolicies"
+
+	// MeshMessagingPolicyURL is the mesh messaging policy path.
+	MeshMessagingPolicyURL = apiURL + "/mesh/messagingpolicies/%s"
+
+	// MeshIngressPortsURL is the mesh ingress port prefix.
+	MeshIngressPortsURL = apiURL + "/mesh/ingressports"
+
+	// MeshIngressPortURL is the mesh ingress port path.
+	MeshIngressPortURL = apiURL + "/mesh/ingressports/%s"
+
+	// MeshWAFPoliciesURL is the mesh WAF policy prefix.
+	MeshWAFPoliciesURL = apiURL + "/mesh/wafpolicies"
+
+	// MeshWAFPolicyURL is the mesh WAF policy path.
+	MeshWAFPolicyURL = apiURL + "/mesh/wafpolicies/%s"
+
+	// MeshPolicyRolloutsURL is the mesh policy rollout prefix.
+	MeshPolicyRolloutsURL = apiURL + "/mesh/policyrollouts"
+
+	// MeshPolicyRolloutURL is the mesh policy rollout path.
+	MeshPolicyRolloutURL = apiURL + "/mesh/policyrollouts/%s"
+
+	// MeshMaintenanceModesURL is the mesh maintenance mode prefix.
+	MeshMaintenanceModesURL = apiURL + "/mesh/maintenancemodes"
+
+	// MeshMaintenanceModeURL is the mesh maintenance mode path.
+	MeshMaintenanceModeURL = apiURL + "/mesh/maintenancemodes/%s"
+
+	// MeshRevisionsURL is the path of revisions of a mesh resource.
+	MeshRevisionsURL = apiURL + "/mesh/revisions/%s/%s"
+
+	// MeshRevisionURL is the path of a revision of a mesh resource.
+	MeshRevisionURL = apiURL + "/mesh/revisions/%s/%s/%d"
+
+	// MeshRevisionRollbackURL is the path to rollback a mesh resource to a revision.
+	MeshRevisionRollbackURL = apiURL + "/mesh/revisions/%s/%s/%d/rollback"
+
+	// MeshApplySetsURL is the mesh apply set prefix.
+	MeshApplySetsURL = apiURL + "/mesh/applysets"
+
+	// MeshApplySetURL is the mesh apply set path.
+	MeshApplySetURL = apiURL + "/mesh/applysets/%s"
+
+	// MeshResourceMetasURL is the mesh resource meta prefix.
+	MeshResourceMetasURL = apiURL + "/mesh/resourcemetas"
+
+	// MeshResourceMetaURL is the mesh resource meta path.
+	MeshResourceMetaURL = apiURL + "/mesh/resourcemetas/%s"
+
+	// MeshAuditsURL is the path of the audit log.
+	MeshAuditsURL = apiURL + "/mesh/audits"
+
+	// MeshAccessTokensURL is the access token prefix.
+	MeshAccessTokensURL = apiURL + "/mesh/accesstokens"
+
+	// MeshAccessTokenURL is the access token path.
+	MeshAccessTokenURL = apiURL + "/mesh/accesstokens/%s"
+
+	// MeshEventsURL is the path of the event stream of the control plane.
+	MeshEventsURL = apiURL + "/mesh/events"
+
+	// MeshProxyStatusesURL is the path of the configuration statuses reported by sidecars.
+	MeshProxyStatusesURL = apiURL + "/mesh/proxystatuses"
+
+	// MeshIngressCertificatesURL is the path of the statuses of certificates managed by the ingress controller.
+	MeshIngressCertificatesURL = apiURL + "/mesh/ingresscertificates"
+
+	// MeshIngressCachePurgeURL is the path purging responses cached by the ingress controller.
+	MeshIngressCachePurgeURL = apiURL + "/mesh/ingresscaches/purge"
+
+	// MeshResourceStatusesURL is the path of statuses of mesh resources of a kind.
+	MeshResourceStatusesURL = apiURL + "/mesh/statuses/%s"
+
+	// MeshResourceStatusURL is the path of the status of a mesh resource.
+	MeshResourceStatusURL = apiURL + "/mesh/statuses/%s/%s"
+
+	// MeshVersionURL is the path of the version of the control plane.
+	MeshVersionURL = apiURL + "/mesh/version"
+
+	// AuditIdentityHeader is the header carrying the identity of who runs emctl,
+	// which is recorded in the audit log of the control plane.
+	AuditIdentityHeader = "X-EaseMesh-Identity"
+
+	// AuthorizationHeader carries the access token of emctl in the form of
+	// Bearer {token}. Control planes serving access tokens authorize requests by its
+	// tenant and role, the others ignore it.
+	AuthorizationHeader = "Authorization"
+
+	// ResourceVersionHeader is the header carrying the resource version expected
+	// by an update, the control plane rejects the update with 409 if it's stale.
+	ResourceVersionHeader = "X-EaseMesh-Resource-Version"
+
 	// MeshCustomResourceKindsURL is the mesh custom resource kind prefix.
 	MeshCustomResourceKindsURL = apiURL + "/mesh/customresourcekinds"
 
@@ -35,7 +35,11 @@ type customResourceKindGetter struct {
 }
 
 func (t *customResourceKindGetter) CustomResourceKind() CustomResourceKindInterface {
-	return &customResourceKindInterface{client: t.client}
+	kinds := &customResourceKindInterface{client: t.client}
+	if cache.enabled() {
+		return &cachedCustomResourceKindInterface{client: t.client, delegate: kinds}
+	}
+	return kinds
 }
 
 type customResourceKindInterface struct {
@@ -79,6 +83,10 @@ func (k *customResourceKindInterface) Patch(ctx context.Context, customResourceK
 				return nil, errors.Wrapf(NotFoundError, "patch custom resource kind %s", customResourceKind.Name())
 			}
 
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch custom resource kind %s", customResourceKind.Name())
+			}
+
 			if statusCode < 300 && statusCode >= 200 {
 				return nil, nil
 			}
@@ -205,6 +213,10 @@ func (o *customResourceInterface) Patch(ctx context.Context, customResource *res
 				return nil, errors.Wrapf(NotFoundError, "patch custom resource %s", customResource.Name())
 			}
 
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch custom resource %s", customResource.Name())
+			}
+
 			if statusCode < 300 && statusCode >= 200 {
 				return nil, nil
 			}
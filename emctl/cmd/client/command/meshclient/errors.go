@@ -17,13 +17,21 @@
 
 package meshclient
 
-import "github.com/pkg/errors"
+import (
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
 
 var (
 	// ConflictError indicate that the resource already exists
-	ConflictError = errors.Errorf("resource already exists")
+	ConflictError = common.CodeErrorf(common.ExitCodeConflict, "resource already exists")
 	// NotFoundError indicate that the resource does not existed
-	NotFoundError = errors.Errorf("resource not found")
+	NotFoundError = common.CodeErrorf(common.ExitCodeNotFound, "resource not found")
+	// StaleError indicate that the resource has been modified since the resource version
+	StaleError = common.CodeErrorf(common.ExitCodeConflict, "resource has been modified, the resource version is stale")
+	// UnsupportedError indicate that the control plane doesn't serve the API
+	UnsupportedError = common.CodeErrorf(common.ExitCodeUnsupported, "not served by the control plane, which may be Easegress without EaseMesh extensions")
 )
 
 // IsConflictError judge err is a ConflictError
@@ -36,3 +44,13 @@ func IsConflictError(err error) (result bool) {
 func IsNotFoundError(err error) (result bool) {
 	return errors.Cause(err) == NotFoundError
 }
+
+// IsStaleError judge err is a StaleError
+func IsStaleError(err error) (result bool) {
+	return errors.Cause(err) == StaleError
+}
+
+// IsUnsupportedError judge err is a UnsupportedError
+func IsUnsupportedError(err error) (result bool) {
+	return errors.Cause(err) == UnsupportedError
+}
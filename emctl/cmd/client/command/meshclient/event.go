@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// EventGetter represents a MeshEvent accessor
+type EventGetter interface {
+	Event() EventInterface
+}
+
+// EventWatchOptions filters events, empty fields match all.
+type EventWatchOptions struct {
+	Since time.Time
+	Types []string
+	// Follow keeps the stream open for new events after the existing ones are sent.
+	Follow bool
+}
+
+// EventHandler handles an event of the stream, the stream is closed if it returns an error.
+type EventHandler func(*resource.MeshEvent) error
+
+// EventInterface captures the set of operations for interacting with the EaseMesh REST apis of the events.
+type EventInterface interface {
+	Watch(context.Context, *EventWatchOptions, EventHandler) error
+}
+
+type eventGetter struct {
+	client *meshClient
+}
+
+func (e *eventGetter) Event() EventInterface {
+	return &eventInterface{client: e.client}
+}
+
+type eventInterface struct {
+	client *meshClient
+}
+
+// Watch reads events in newline-delimited JSON from the control plane, it returns
+// nil once the stream ends, which never happens with Follow until the context is done.
+func (e *eventInterface) Watch(ctx context.Context, options *EventWatchOptions, handler EventHandler) error {
+	if err := e.client.requireAPI(ctx, APIEvents); err != nil {
+		return err
+	}
+	query := url.Values{}
+	if !options.Since.IsZero() {
+		query.Set("since", options.Since.UTC().Format(time.RFC3339))
+	}
+	for _, t := range options.Types {
+		query.Add("type", t)
+	}
+	if options.Follow {
+		query.Set("watch", "true")
+	}
+
+	url := "http://" + e.client.server + MeshEventsURL
+	if len(query) != 0 {
+		url += "?" + query.Encode()
+	}
+
+	body, statusCode, err := client.StreamByContext(ctx, url, map[string]string{"Accept": "application/x-ndjson"})
+	if err != nil {
+		return errors.Wrapf(err, "call GET %s failed", url)
+	}
+	defer body.Close()
+
+	if statusCode == http.StatusNotFound {
+		return errors.Wrap(NotFoundError, "watch events")
+	}
+	if statusCode >= 300 || statusCode < 200 {
+		b, _ := ioutil.ReadAll(body)
+		return errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+	}
+
+	decoder := json.NewDecoder(body)
+	for {
+		object := &resource.MeshEventObject{}
+		err := decoder.Decode(object)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.Wrap(err, "decode event")
+		}
+
+		err = handler(resource.ToMeshEvent(object))
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// ExternalServiceGetter represents an ExternalService resource accessor
+type ExternalServiceGetter interface {
+	ExternalService() ExternalServiceInterface
+}
+
+// ExternalServiceInterface captures the set of operations for interacting with the EaseMesh REST apis of the external service resource.
+type ExternalServiceInterface interface {
+	Get(context.Context, string) (*resource.ExternalService, error)
+	Patch(context.Context, *resource.ExternalService) error
+	Create(context.Context, *resource.ExternalService) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.ExternalService, error)
+}
+
+type externalServiceGetter struct {
+	client *meshClient
+}
+
+func (g *externalServiceGetter) ExternalService() ExternalServiceInterface {
+	return &externalServiceInterface{client: g.client}
+}
+
+type externalServiceInterface struct {
+	client *meshClient
+}
+
+func (e *externalServiceInterface) Get(ctx context.Context, name string) (*resource.ExternalService, error) {
+	if err := e.client.requireAPI(ctx, APIExternalServices); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+e.client.server+MeshExternalServiceURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get external service %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.ExternalServiceObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to ExternalService")
+			}
+			return resource.ToExternalService(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.ExternalService), nil
+}
+
+func (e *externalServiceInterface) Patch(ctx context.Context, externalService *resource.ExternalService) error {
+	if err := e.client.requireAPI(ctx, APIExternalServices); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+e.client.server+MeshExternalServiceURL, externalService.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, externalService.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch external service %s", externalService.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch external service %s", externalService.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (e *externalServiceInterface) Create(ctx context.Context, externalService *resource.ExternalService) error {
+	if err := e.client.requireAPI(ctx, APIExternalServices); err != nil {
+		return err
+	}
+	url := "http://" + e.client.server + MeshExternalServicesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, externalService.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create external service %s", externalService.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (e *externalServiceInterface) Delete(ctx context.Context, name string) error {
+	if err := e.client.requireAPI(ctx, APIExternalServices); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+e.client.server+MeshExternalServiceURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete external service %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (e *externalServiceInterface) List(ctx context.Context) ([]*resource.ExternalService, error) {
+	if err := e.client.requireAPI(ctx, APIExternalServices); err != nil {
+		return nil, err
+	}
+	url := "http://" + e.client.server + MeshExternalServicesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list external service")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.ExternalServiceObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal external service result")
+			}
+
+			results := []*resource.ExternalService{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToExternalService(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.ExternalService), err
+}
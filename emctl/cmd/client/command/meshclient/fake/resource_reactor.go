@@ -92,6 +92,7 @@ func (r *resourceReactor) DoRequest(verb, kind, resource string, obj meta.MeshOb
 	var a Action
 	action := &actionImpl{
 		verb: verb,
+		name: resource,
 		vk: meta.VersionKind{
 			APIVersion: "mesh.megaease.com/v1alpha1", Kind: kind,
 		},
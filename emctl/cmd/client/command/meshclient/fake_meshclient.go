@@ -19,6 +19,7 @@ package meshclient
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
 	"github.com/megaease/easemeshctl/cmd/client/resource"
@@ -91,6 +92,42 @@ type (
 		baseGetter
 	}
 
+	fakeExternalServiceGetter struct {
+		baseGetter
+	}
+
+	fakeTenantPolicyGetter struct {
+		baseGetter
+	}
+
+	fakeSLOGetter struct {
+		baseGetter
+	}
+
+	fakeAlertRuleGetter struct {
+		baseGetter
+	}
+
+	fakeMessagingPolicyGetter struct {
+		baseGetter
+	}
+
+	fakeIngressPortGetter struct {
+		baseGetter
+	}
+
+	fakeWAFPolicyGetter struct {
+		baseGetter
+	}
+
+	fakeMaintenanceModeGetter struct {
+		baseGetter
+	}
+
+	fakePolicyRolloutGetter struct {
+		baseGetter
+	}
+
 	fakeCustomResourceKindGetter struct {
 		baseGetter
 	}
@@ -98,6 +135,50 @@ type (
 	fakeCustomResourceGetter struct {
 		baseGetter
 	}
+
+	fakeRevisionGetter struct {
+		baseGetter
+	}
+
+	fakeAuditGetter struct {
+		baseGetter
+	}
+
+	fakeAccessTokenGetter struct {
+		baseGetter
+	}
+
+	fakeEventGetter struct {
+		baseGetter
+	}
+
+	fakeProxyStatusGetter struct {
+		baseGetter
+	}
+
+	fakeIngressCertificateGetter struct {
+		baseGetter
+	}
+
+	fakeIngressCacheGetter struct {
+		baseGetter
+	}
+
+	fakeApplySetGetter struct {
+		baseGetter
+	}
+
+	fakeResourceMetaGetter struct {
+		baseGetter
+	}
+
+	fakeStatusGetter struct {
+		baseGetter
+	}
+
+	fakeVersionGetter struct {
+		baseGetter
+	}
 	fakeV1alpha1 struct {
 		resourceReactor fake.ResourceReactor
 	}
@@ -189,6 +270,51 @@ func (f *fakeV1alpha1) ServiceCanary() ServiceCanaryInterface {
 	}
 }
 
+func (f *fakeV1alpha1) ExternalService() ExternalServiceInterface {
+	return &fakeExternalServiceGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindExternalService}}
+}
+
+func (f *fakeV1alpha1) TenantPolicy() TenantPolicyInterface {
+	return &fakeTenantPolicyGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindTenantPolicy}}
+}
+
+func (f *fakeV1alpha1) SLO() SLOInterface {
+	return &fakeSLOGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindSLO}}
+}
+
+func (f *fakeV1alpha1) AlertRule() AlertRuleInterface {
+	return &fakeAlertRuleGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindAlertRule}}
+}
+
+func (f *fakeV1alpha1) MessagingPolicy() MessagingPolicyInterface {
+	return &fakeMessagingPolicyGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindMessagingPolicy}}
+}
+
+func (f *fakeV1alpha1) IngressPort() IngressPortInterface {
+	return &fakeIngressPortGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindIngressPort}}
+}
+
+func (f *fakeV1alpha1) WAFPolicy() WAFPolicyInterface {
+	return &fakeWAFPolicyGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindWAFPolicy}}
+}
+
+func (f *fakeV1alpha1) MaintenanceMode() MaintenanceModeInterface {
+	return &fakeMaintenanceModeGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindMaintenanceMode}}
+}
+
+func (f *fakeV1alpha1) PolicyRollout() PolicyRolloutInterface {
+	return &fakePolicyRolloutGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindPolicyRollout}}
+}
+
 func (f *fakeV1alpha1) CustomResourceKind() CustomResourceKindInterface {
 	return &fakeCustomResourceKindGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
 		kind: resource.KindCustomResourceKind}}
@@ -199,6 +325,61 @@ func (f *fakeV1alpha1) CustomResource() CustomResourceInterface {
 		kind: "-"}}
 }
 
+func (f *fakeV1alpha1) Revision() RevisionInterface {
+	return &fakeRevisionGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindRevision}}
+}
+
+func (f *fakeV1alpha1) Audit() AuditInterface {
+	return &fakeAuditGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindAuditRecord}}
+}
+
+func (f *fakeV1alpha1) AccessToken() AccessTokenInterface {
+	return &fakeAccessTokenGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: fakeAccessTokenKind}}
+}
+
+func (f *fakeV1alpha1) Event() EventInterface {
+	return &fakeEventGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindMeshEvent}}
+}
+
+func (f *fakeV1alpha1) ProxyStatus() ProxyStatusInterface {
+	return &fakeProxyStatusGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: fakeProxyStatusKind}}
+}
+
+func (f *fakeV1alpha1) IngressCertificate() IngressCertificateInterface {
+	return &fakeIngressCertificateGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: fakeIngressCertificateKind}}
+}
+
+func (f *fakeV1alpha1) IngressCache() IngressCacheInterface {
+	return &fakeIngressCacheGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: fakeIngressCacheKind}}
+}
+
+func (f *fakeV1alpha1) ApplySet() ApplySetInterface {
+	return &fakeApplySetGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindApplySet}}
+}
+
+func (f *fakeV1alpha1) ResourceMeta() ResourceMetaInterface {
+	return &fakeResourceMetaGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: resource.KindResourceMeta}}
+}
+
+func (f *fakeV1alpha1) Status() StatusInterface {
+	return &fakeStatusGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: fakeStatusKind}}
+}
+
+func (f *fakeV1alpha1) Version() VersionInterface {
+	return &fakeVersionGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
+		kind: fakeVersionKind}}
+}
+
 func (f *fakeV1alpha1) MeshController() MeshControllerInterface {
 	return &fakeMeshControllerGetter{baseGetter: baseGetter{resourceReactor: f.resourceReactor,
 		kind: resource.KindMeshController}}
@@ -891,46 +1072,46 @@ func (f *fakeServiceCanaryGetter) List(ctx context.Context) ([]*resource.Service
 	return result, nil
 }
 
-// fakeCustomResourceKindGetter implementation
+// fakeExternalServiceGetter implementation
 
-func (f *fakeCustomResourceKindGetter) Get(ctx context.Context, name string) (*resource.CustomResourceKind, error) {
-	o, err := f.resourceReactor.DoRequest("get", resource.KindCustomResourceKind, name, nil)
+func (f *fakeExternalServiceGetter) Get(ctx context.Context, name string) (*resource.ExternalService, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindExternalService, name, nil)
 	if err != nil {
 		return nil, err
 	}
 	if len(o) == 0 {
 		return nil, NotFoundError
 	}
-	result, ok := o[0].(*resource.CustomResourceKind)
+	result, ok := o[0].(*resource.ExternalService)
 	if !ok {
 		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
 	}
 	return result, nil
 }
 
-func (f *fakeCustomResourceKindGetter) Patch(ctx context.Context, t *resource.CustomResourceKind) error {
-	return f.doModifyRequest(resource.KindCustomResourceKind, t.Name(), t)
+func (f *fakeExternalServiceGetter) Patch(ctx context.Context, t *resource.ExternalService) error {
+	return f.doModifyRequest(resource.KindExternalService, t.Name(), t)
 }
 
-func (f *fakeCustomResourceKindGetter) Create(ctx context.Context, t *resource.CustomResourceKind) error {
-	return f.doModifyRequest(resource.KindCustomResourceKind, t.Name(), t)
+func (f *fakeExternalServiceGetter) Create(ctx context.Context, t *resource.ExternalService) error {
+	return f.doModifyRequest(resource.KindExternalService, t.Name(), t)
 }
 
-func (f *fakeCustomResourceKindGetter) Delete(ctx context.Context, name string) error {
-	return f.doModifyRequest(resource.KindCustomResourceKind, name, nil)
+func (f *fakeExternalServiceGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindExternalService, name, nil)
 }
 
-func (f *fakeCustomResourceKindGetter) List(ctx context.Context) ([]*resource.CustomResourceKind, error) {
-	o, err := f.resourceReactor.DoRequest("list", resource.KindCustomResourceKind, "", nil)
+func (f *fakeExternalServiceGetter) List(ctx context.Context) ([]*resource.ExternalService, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindExternalService, "", nil)
 	if err != nil {
 		return nil, err
 	}
 	if len(o) == 0 {
 		return nil, NotFoundError
 	}
-	result := []*resource.CustomResourceKind{}
+	result := []*resource.ExternalService{}
 	for _, m := range o {
-		c := m.(*resource.CustomResourceKind)
+		c := m.(*resource.ExternalService)
 		if c != nil {
 			result = append(result, c)
 		}
@@ -938,47 +1119,718 @@ func (f *fakeCustomResourceKindGetter) List(ctx context.Context) ([]*resource.Cu
 	return result, nil
 }
 
-// fakeCustomResourceGetter implementation
+// fakeTenantPolicyGetter implementation
 
-func (f *fakeCustomResourceGetter) Get(ctx context.Context, kind, name string) (*resource.CustomResource, error) {
-	// TODO: how to handle customresource ?
-	o, err := f.resourceReactor.DoRequest("get", kind, name, nil)
+func (f *fakeTenantPolicyGetter) Get(ctx context.Context, name string) (*resource.TenantPolicy, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindTenantPolicy, name, nil)
 	if err != nil {
 		return nil, err
 	}
 	if len(o) == 0 {
 		return nil, NotFoundError
 	}
-	result, ok := o[0].(*resource.CustomResource)
+	result, ok := o[0].(*resource.TenantPolicy)
 	if !ok {
 		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
 	}
 	return result, nil
 }
 
-func (f *fakeCustomResourceGetter) Patch(ctx context.Context, t *resource.CustomResource) error {
-	return f.doModifyRequest(t.Kind(), t.Name(), t)
+func (f *fakeTenantPolicyGetter) Patch(ctx context.Context, t *resource.TenantPolicy) error {
+	return f.doModifyRequest(resource.KindTenantPolicy, t.Name(), t)
 }
 
-func (f *fakeCustomResourceGetter) Create(ctx context.Context, t *resource.CustomResource) error {
-	return f.doModifyRequest(t.Kind(), t.Name(), t)
+func (f *fakeTenantPolicyGetter) Create(ctx context.Context, t *resource.TenantPolicy) error {
+	return f.doModifyRequest(resource.KindTenantPolicy, t.Name(), t)
 }
 
-func (f *fakeCustomResourceGetter) Delete(ctx context.Context, kind, name string) error {
-	return f.doModifyRequest(kind, name, nil)
+func (f *fakeTenantPolicyGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindTenantPolicy, name, nil)
 }
 
-func (f *fakeCustomResourceGetter) List(ctx context.Context, kind string) ([]*resource.CustomResource, error) {
-	o, err := f.resourceReactor.DoRequest("list", kind, "", nil)
+func (f *fakeTenantPolicyGetter) List(ctx context.Context) ([]*resource.TenantPolicy, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindTenantPolicy, "", nil)
 	if err != nil {
 		return nil, err
 	}
 	if len(o) == 0 {
 		return nil, NotFoundError
 	}
-	result := []*resource.CustomResource{}
+	result := []*resource.TenantPolicy{}
 	for _, m := range o {
-		c := m.(*resource.CustomResource)
+		c := m.(*resource.TenantPolicy)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeSLOGetter implementation
+
+func (f *fakeSLOGetter) Get(ctx context.Context, name string) (*resource.SLO, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindSLO, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.SLO)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeSLOGetter) Patch(ctx context.Context, t *resource.SLO) error {
+	return f.doModifyRequest(resource.KindSLO, t.Name(), t)
+}
+
+func (f *fakeSLOGetter) Create(ctx context.Context, t *resource.SLO) error {
+	return f.doModifyRequest(resource.KindSLO, t.Name(), t)
+}
+
+func (f *fakeSLOGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindSLO, name, nil)
+}
+
+func (f *fakeSLOGetter) List(ctx context.Context) ([]*resource.SLO, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindSLO, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.SLO{}
+	for _, m := range o {
+		c := m.(*resource.SLO)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeAlertRuleGetter implementation
+
+func (f *fakeAlertRuleGetter) Get(ctx context.Context, name string) (*resource.AlertRule, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindAlertRule, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.AlertRule)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeAlertRuleGetter) Patch(ctx context.Context, t *resource.AlertRule) error {
+	return f.doModifyRequest(resource.KindAlertRule, t.Name(), t)
+}
+
+func (f *fakeAlertRuleGetter) Create(ctx context.Context, t *resource.AlertRule) error {
+	return f.doModifyRequest(resource.KindAlertRule, t.Name(), t)
+}
+
+func (f *fakeAlertRuleGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindAlertRule, name, nil)
+}
+
+func (f *fakeAlertRuleGetter) List(ctx context.Context) ([]*resource.AlertRule, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindAlertRule, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.AlertRule{}
+	for _, m := range o {
+		c := m.(*resource.AlertRule)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeMessagingPolicyGetter implementation
+
+func (f *fakeMessagingPolicyGetter) Get(ctx context.Context, name string) (*resource.MessagingPolicy, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindMessagingPolicy, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.MessagingPolicy)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeMessagingPolicyGetter) Patch(ctx context.Context, t *resource.MessagingPolicy) error {
+	return f.doModifyRequest(resource.KindMessagingPolicy, t.Name(), t)
+}
+
+func (f *fakeMessagingPolicyGetter) Create(ctx context.Context, t *resource.MessagingPolicy) error {
+	return f.doModifyRequest(resource.KindMessagingPolicy, t.Name(), t)
+}
+
+func (f *fakeMessagingPolicyGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindMessagingPolicy, name, nil)
+}
+
+func (f *fakeMessagingPolicyGetter) List(ctx context.Context) ([]*resource.MessagingPolicy, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindMessagingPolicy, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.MessagingPolicy{}
+	for _, m := range o {
+		c := m.(*resource.MessagingPolicy)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeIngressPortGetter implementation
+
+func (f *fakeIngressPortGetter) Get(ctx context.Context, name string) (*resource.IngressPort, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindIngressPort, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.IngressPort)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeIngressPortGetter) Patch(ctx context.Context, t *resource.IngressPort) error {
+	return f.doModifyRequest(resource.KindIngressPort, t.Name(), t)
+}
+
+func (f *fakeIngressPortGetter) Create(ctx context.Context, t *resource.IngressPort) error {
+	return f.doModifyRequest(resource.KindIngressPort, t.Name(), t)
+}
+
+func (f *fakeIngressPortGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindIngressPort, name, nil)
+}
+
+func (f *fakeIngressPortGetter) List(ctx context.Context) ([]*resource.IngressPort, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindIngressPort, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.IngressPort{}
+	for _, m := range o {
+		c := m.(*resource.IngressPort)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeWAFPolicyGetter implementation
+
+func (f *fakeWAFPolicyGetter) Get(ctx context.Context, name string) (*resource.WAFPolicy, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindWAFPolicy, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.WAFPolicy)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeWAFPolicyGetter) Patch(ctx context.Context, t *resource.WAFPolicy) error {
+	return f.doModifyRequest(resource.KindWAFPolicy, t.Name(), t)
+}
+
+func (f *fakeWAFPolicyGetter) Create(ctx context.Context, t *resource.WAFPolicy) error {
+	return f.doModifyRequest(resource.KindWAFPolicy, t.Name(), t)
+}
+
+func (f *fakeWAFPolicyGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindWAFPolicy, name, nil)
+}
+
+func (f *fakeWAFPolicyGetter) List(ctx context.Context) ([]*resource.WAFPolicy, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindWAFPolicy, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.WAFPolicy{}
+	for _, m := range o {
+		c := m.(*resource.WAFPolicy)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeMaintenanceModeGetter implementation
+
+func (f *fakeMaintenanceModeGetter) Get(ctx context.Context, name string) (*resource.MaintenanceMode, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindMaintenanceMode, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.MaintenanceMode)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeMaintenanceModeGetter) Patch(ctx context.Context, t *resource.MaintenanceMode) error {
+	return f.doModifyRequest(resource.KindMaintenanceMode, t.Name(), t)
+}
+
+func (f *fakeMaintenanceModeGetter) Create(ctx context.Context, t *resource.MaintenanceMode) error {
+	return f.doModifyRequest(resource.KindMaintenanceMode, t.Name(), t)
+}
+
+func (f *fakeMaintenanceModeGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindMaintenanceMode, name, nil)
+}
+
+func (f *fakeMaintenanceModeGetter) List(ctx context.Context) ([]*resource.MaintenanceMode, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindMaintenanceMode, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.MaintenanceMode{}
+	for _, m := range o {
+		c := m.(*resource.MaintenanceMode)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakePolicyRolloutGetter implementation
+
+func (f *fakePolicyRolloutGetter) Get(ctx context.Context, name string) (*resource.PolicyRollout, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindPolicyRollout, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.PolicyRollout)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakePolicyRolloutGetter) Patch(ctx context.Context, t *resource.PolicyRollout) error {
+	return f.doModifyRequest(resource.KindPolicyRollout, t.Name(), t)
+}
+
+func (f *fakePolicyRolloutGetter) Create(ctx context.Context, t *resource.PolicyRollout) error {
+	return f.doModifyRequest(resource.KindPolicyRollout, t.Name(), t)
+}
+
+func (f *fakePolicyRolloutGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindPolicyRollout, name, nil)
+}
+
+func (f *fakePolicyRolloutGetter) List(ctx context.Context) ([]*resource.PolicyRollout, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindPolicyRollout, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.PolicyRollout{}
+	for _, m := range o {
+		c := m.(*resource.PolicyRollout)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeCustomResourceKindGetter implementation
+
+func (f *fakeCustomResourceKindGetter) Get(ctx context.Context, name string) (*resource.CustomResourceKind, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindCustomResourceKind, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.CustomResourceKind)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeCustomResourceKindGetter) Patch(ctx context.Context, t *resource.CustomResourceKind) error {
+	return f.doModifyRequest(resource.KindCustomResourceKind, t.Name(), t)
+}
+
+func (f *fakeCustomResourceKindGetter) Create(ctx context.Context, t *resource.CustomResourceKind) error {
+	return f.doModifyRequest(resource.KindCustomResourceKind, t.Name(), t)
+}
+
+func (f *fakeCustomResourceKindGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindCustomResourceKind, name, nil)
+}
+
+func (f *fakeCustomResourceKindGetter) List(ctx context.Context) ([]*resource.CustomResourceKind, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindCustomResourceKind, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.CustomResourceKind{}
+	for _, m := range o {
+		c := m.(*resource.CustomResourceKind)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeCustomResourceGetter implementation
+
+func (f *fakeCustomResourceGetter) Get(ctx context.Context, kind, name string) (*resource.CustomResource, error) {
+	// TODO: how to handle customresource ?
+	o, err := f.resourceReactor.DoRequest("get", kind, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.CustomResource)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeCustomResourceGetter) Patch(ctx context.Context, t *resource.CustomResource) error {
+	return f.doModifyRequest(t.Kind(), t.Name(), t)
+}
+
+func (f *fakeCustomResourceGetter) Create(ctx context.Context, t *resource.CustomResource) error {
+	return f.doModifyRequest(t.Kind(), t.Name(), t)
+}
+
+func (f *fakeCustomResourceGetter) Delete(ctx context.Context, kind, name string) error {
+	return f.doModifyRequest(kind, name, nil)
+}
+
+func (f *fakeCustomResourceGetter) List(ctx context.Context, kind string) ([]*resource.CustomResource, error) {
+	o, err := f.resourceReactor.DoRequest("list", kind, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.CustomResource{}
+	for _, m := range o {
+		c := m.(*resource.CustomResource)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeRevisionGetter implementation
+
+func (f *fakeRevisionGetter) List(ctx context.Context, kind, name string) ([]*resource.Revision, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindRevision, kind+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.Revision{}
+	for _, m := range o {
+		c := m.(*resource.Revision)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRevisionGetter) Get(ctx context.Context, kind, name string, revision int64) (*resource.Revision, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindRevision, fmt.Sprintf("%s/%s/%d", kind, name, revision), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.Revision)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeRevisionGetter) Rollback(ctx context.Context, kind, name string, revision int64) error {
+	return f.doModifyRequest(resource.KindRevision, fmt.Sprintf("%s/%s/%d", kind, name, revision), nil)
+}
+
+// fakeAuditGetter implementation
+
+func (f *fakeAuditGetter) List(ctx context.Context, options *AuditListOptions) ([]*resource.AuditRecord, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindAuditRecord, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.AuditRecord{}
+	for _, m := range o {
+		c := m.(*resource.AuditRecord)
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeAccessTokenGetter implementation
+
+// fakeAccessTokenKind is the kind of access tokens for resource reactors,
+// access tokens aren't mesh resources.
+const fakeAccessTokenKind = "AccessToken"
+
+func (f *fakeAccessTokenGetter) Create(ctx context.Context, request *resource.AccessTokenRequest) (*resource.AccessToken, error) {
+	err := f.doModifyRequest(fakeAccessTokenKind, request.Tenant, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resource.AccessToken{ID: "fake", Token: "fake.token", Tenant: request.Tenant, Role: request.Role}, nil
+}
+
+func (f *fakeAccessTokenGetter) Delete(ctx context.Context, id string) error {
+	return f.doModifyRequest(fakeAccessTokenKind, id, nil)
+}
+
+// fakeEventGetter implementation
+
+func (f *fakeEventGetter) Watch(ctx context.Context, options *EventWatchOptions, handler EventHandler) error {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindMeshEvent, "", nil)
+	if err != nil {
+		return err
+	}
+	for _, m := range o {
+		c := m.(*resource.MeshEvent)
+		if c == nil {
+			continue
+		}
+		err := handler(c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeProxyStatusGetter implementation
+
+// fakeProxyStatusKind is the kind of proxy statuses for resource reactors,
+// proxy statuses aren't mesh resources.
+const fakeProxyStatusKind = "ProxyStatus"
+
+func (f *fakeProxyStatusGetter) List(ctx context.Context) ([]*resource.ProxyStatus, error) {
+	_, err := f.resourceReactor.DoRequest("list", fakeProxyStatusKind, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return []*resource.ProxyStatus{}, nil
+}
+
+// fakeStatusGetter implementation
+
+// fakeStatusKind is the kind of statuses for resource reactors,
+// statuses aren't mesh resources.
+const fakeStatusKind = "Status"
+
+func (f *fakeStatusGetter) Get(ctx context.Context, kind, name string) (*resource.ResourceStatus, error) {
+	_, err := f.resourceReactor.DoRequest("get", fakeStatusKind, kind+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resource.ResourceStatus{Kind: kind, Name: name}, nil
+}
+
+func (f *fakeStatusGetter) List(ctx context.Context, kind string) ([]*resource.ResourceStatus, error) {
+	_, err := f.resourceReactor.DoRequest("list", fakeStatusKind, kind, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []*resource.ResourceStatus{}, nil
+}
+
+// fakeVersionGetter implementation
+
+// fakeVersionKind is the kind of the version for resource reactors,
+// the version isn't a mesh resource.
+const fakeVersionKind = "Version"
+
+func (f *fakeVersionGetter) Get(ctx context.Context) (*resource.ControlPlaneVersion, error) {
+	_, err := f.resourceReactor.DoRequest("get", fakeVersionKind, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resource.ControlPlaneVersion{}, nil
+}
+
+// fakeIngressCertificateGetter implementation
+
+// fakeIngressCertificateKind is the kind of ingress certificates for resource
+// reactors, ingress certificates aren't mesh resources.
+const fakeIngressCertificateKind = "IngressCertificate"
+
+func (f *fakeIngressCertificateGetter) List(ctx context.Context) ([]*resource.IngressCertificate, error) {
+	_, err := f.resourceReactor.DoRequest("list", fakeIngressCertificateKind, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return []*resource.IngressCertificate{}, nil
+}
+
+// fakeIngressCacheGetter implementation
+
+// fakeIngressCacheKind is the kind of ingress caches for resource reactors,
+// ingress caches aren't mesh resources.
+const fakeIngressCacheKind = "IngressCache"
+
+func (f *fakeIngressCacheGetter) Purge(ctx context.Context, purge *resource.IngressCachePurge) (*resource.IngressCachePurge, error) {
+	_, err := f.resourceReactor.DoRequest("create", fakeIngressCacheKind, purge.Host+purge.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := *purge
+	return &result, nil
+}
+
+// fakeApplySetGetter implementation
+
+func (f *fakeApplySetGetter) Get(ctx context.Context, name string) (*resource.ApplySet, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindApplySet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.ApplySet)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeApplySetGetter) Patch(ctx context.Context, t *resource.ApplySet) error {
+	return f.doModifyRequest(resource.KindApplySet, t.Name(), t)
+}
+
+func (f *fakeApplySetGetter) Create(ctx context.Context, t *resource.ApplySet) error {
+	return f.doModifyRequest(resource.KindApplySet, t.Name(), t)
+}
+
+// fakeResourceMetaGetter implementation
+
+func (f *fakeResourceMetaGetter) Get(ctx context.Context, name string) (*resource.ResourceMeta, error) {
+	o, err := f.resourceReactor.DoRequest("get", resource.KindResourceMeta, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result, ok := o[0].(*resource.ResourceMeta)
+	if !ok {
+		return nil, errors.Errorf("get an unknown MeshObject %+v", o)
+	}
+	return result, nil
+}
+
+func (f *fakeResourceMetaGetter) Patch(ctx context.Context, t *resource.ResourceMeta) error {
+	return f.doModifyRequest(resource.KindResourceMeta, t.Name(), t)
+}
+
+func (f *fakeResourceMetaGetter) Create(ctx context.Context, t *resource.ResourceMeta) error {
+	return f.doModifyRequest(resource.KindResourceMeta, t.Name(), t)
+}
+
+func (f *fakeResourceMetaGetter) Delete(ctx context.Context, name string) error {
+	return f.doModifyRequest(resource.KindResourceMeta, name, nil)
+}
+
+func (f *fakeResourceMetaGetter) List(ctx context.Context) ([]*resource.ResourceMeta, error) {
+	o, err := f.resourceReactor.DoRequest("list", resource.KindResourceMeta, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 {
+		return nil, NotFoundError
+	}
+	result := []*resource.ResourceMeta{}
+	for _, m := range o {
+		c := m.(*resource.ResourceMeta)
 		if c != nil {
 			result = append(result, c)
 		}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// MeshFeaturesURL is the path of the APIs served by the control plane
+// besides the ones of Easegress.
+const MeshFeaturesURL = apiURL + "/mesh/features"
+
+// APIs of EaseMesh extensions, which are only served by control planes listing
+// them at MeshFeaturesURL. Easegress serves none of them, so commands using them
+// fail with UnsupportedError instead of sending requests it doesn't understand.
+const (
+	APIAccessTokens        = "accesstokens"
+	APIAlertRules          = "alertrules"
+	APIApplySets           = "applysets"
+	APIAudits              = "audits"
+	APIEvents              = "events"
+	APIExternalServices    = "externalservices"
+	APIIngressCaches       = "ingresscaches"
+	APIIngressCertificates = "ingresscertificates"
+	APIIngressPorts        = "ingressports"
+	APIMaintenanceModes    = "maintenancemodes"
+	APIMessagingPolicies   = "messagingpolicies"
+	APIPolicyRollouts      = "policyrollouts"
+	APIProxyStatuses       = "proxystatuses"
+	APIResourceMetas       = "resourcemetas"
+	APIRevisions           = "revisions"
+	APISLOs                = "slos"
+	APIStatuses            = "statuses"
+	APITenantPolicies      = "tenantpolicies"
+	APIVersion             = "version"
+	APIWAFPolicies         = "wafpolicies"
+)
+
+// Features returns all APIs of EaseMesh extensions.
+func Features() []string {
+	return []string{
+		APIAccessTokens, APIAlertRules, APIApplySets, APIAudits, APIEvents,
+		APIExternalServices, APIIngressCaches, APIIngressCertificates, APIIngressPorts,
+		APIMaintenanceModes, APIMessagingPolicies, APIPolicyRollouts, APIProxyStatuses,
+		APIResourceMetas, APIRevisions, APISLOs, APIStatuses, APITenantPolicies,
+		APIVersion, APIWAFPolicies,
+	}
+}
+
+// featureCache keeps the APIs served by control planes keyed by servers,
+// so they are discovered once by a process.
+type featureCache struct {
+	mutex    sync.Mutex
+	features map[string]map[string]bool
+}
+
+var features = &featureCache{features: map[string]map[string]bool{}}
+
+// requireAPI returns UnsupportedError if the control plane doesn't serve the API.
+func (m *meshClient) requireAPI(ctx context.Context, api string) error {
+	features.mutex.Lock()
+	defer features.mutex.Unlock()
+
+	served, ok := features.features[m.server]
+	if !ok {
+		var err error
+		served, err = m.discoverFeatures(ctx)
+		if err != nil {
+			return err
+		}
+		features.features[m.server] = served
+	}
+
+	if !served[api] {
+		return errors.Wrapf(UnsupportedError, "%s API of %s", api, m.server)
+	}
+	return nil
+}
+
+func (m *meshClient) discoverFeatures(ctx context.Context) (map[string]bool, error) {
+	url := "http://" + m.server + MeshFeaturesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			// NOTE: Easegress doesn't serve the features API,
+			// so it serves no APIs of EaseMesh extensions.
+			if statusCode == http.StatusNotFound {
+				return map[string]bool{}, nil
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			names := []string{}
+			err := json.Unmarshal(b, &names)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal features of the control plane")
+			}
+
+			served := map[string]bool{}
+			for _, name := range names {
+				served[name] = true
+			}
+			return served, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]bool), nil
+}
@@ -15,14 +15,18 @@
  * limitations under the License.
  */
 
-//go:generate go run github.com/megaease/easemeshctl/cmd/transformer Global Ingress=ingresses/%s
-
 package meshclient
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
 )
 
 // IngressGetter represents an Ingress resource accessor
@@ -38,3 +42,128 @@ type IngressInterface interface {
 	Delete(context.Context, string) error
 	List(context.Context) ([]*resource.Ingress, error)
 }
+
+type ingressGetter struct {
+	client *meshClient
+}
+
+func (g *ingressGetter) Ingress() IngressInterface {
+	return &ingressInterface{client: g.client}
+}
+
+type ingressInterface struct {
+	client *meshClient
+}
+
+func (i *ingressInterface) Get(ctx context.Context, name string) (*resource.Ingress, error) {
+	url := fmt.Sprintf("http://"+i.client.server+MeshIngressURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get ingress %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.IngressObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to Ingress")
+			}
+			return resource.IngressFromObject(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.Ingress), nil
+}
+
+func (i *ingressInterface) Patch(ctx context.Context, ingress *resource.Ingress) error {
+	url := fmt.Sprintf("http://"+i.client.server+MeshIngressURL, ingress.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, ingress.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch ingress %s", ingress.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch ingress %s", ingress.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *ingressInterface) Create(ctx context.Context, ingress *resource.Ingress) error {
+	url := "http://" + i.client.server + MeshIngressesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, ingress.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create ingress %s", ingress.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *ingressInterface) Delete(ctx context.Context, name string) error {
+	url := fmt.Sprintf("http://"+i.client.server+MeshIngressURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete ingress %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *ingressInterface) List(ctx context.Context) ([]*resource.Ingress, error) {
+	url := "http://" + i.client.server + MeshIngressesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list ingress")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.IngressObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal ingress result")
+			}
+
+			results := []*resource.Ingress{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.IngressFromObject(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.Ingress), err
+}
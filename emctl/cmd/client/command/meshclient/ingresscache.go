@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// IngressCacheGetter represents an accessor of caches of the ingress controller
+type IngressCacheGetter interface {
+	IngressCache() IngressCacheInterface
+}
+
+// IngressCacheInterface captures the set of operations for interacting with the EaseMesh REST apis of ingress caches.
+type IngressCacheInterface interface {
+	Purge(context.Context, *resource.IngressCachePurge) (*resource.IngressCachePurge, error)
+}
+
+type ingressCacheGetter struct {
+	client *meshClient
+}
+
+func (c *ingressCacheGetter) IngressCache() IngressCacheInterface {
+	return &ingressCacheInterface{client: c.client}
+}
+
+type ingressCacheInterface struct {
+	client *meshClient
+}
+
+func (c *ingressCacheInterface) Purge(ctx context.Context, purge *resource.IngressCachePurge) (*resource.IngressCachePurge, error) {
+	if err := c.client.requireAPI(ctx, APIIngressCaches); err != nil {
+		return nil, err
+	}
+	url := "http://" + c.client.server + MeshIngressCachePurgeURL
+	result, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, purge, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			// NOTE: Control planes before the ingress cache API don't serve it.
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "purge ingress cache")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call POST %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			result := &resource.IngressCachePurge{}
+			err := json.Unmarshal(b, result)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal ingress cache purge result")
+			}
+			return result, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*resource.IngressCachePurge), nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// IngressCertificateGetter represents an IngressCertificate accessor
+type IngressCertificateGetter interface {
+	IngressCertificate() IngressCertificateInterface
+}
+
+// IngressCertificateInterface captures the set of operations for interacting with the EaseMesh REST apis of ingress certificates.
+type IngressCertificateInterface interface {
+	List(context.Context) ([]*resource.IngressCertificate, error)
+}
+
+type ingressCertificateGetter struct {
+	client *meshClient
+}
+
+func (c *ingressCertificateGetter) IngressCertificate() IngressCertificateInterface {
+	return &ingressCertificateInterface{client: c.client}
+}
+
+type ingressCertificateInterface struct {
+	client *meshClient
+}
+
+func (c *ingressCertificateInterface) List(ctx context.Context) ([]*resource.IngressCertificate, error) {
+	if err := c.client.requireAPI(ctx, APIIngressCertificates); err != nil {
+		return nil, err
+	}
+	url := "http://" + c.client.server + MeshIngressCertificatesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list ingress certificates")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			certificates := []*resource.IngressCertificate{}
+			err := json.Unmarshal(b, &certificates)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal ingress certificates result")
+			}
+			return certificates, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.IngressCertificate), err
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// IngressPortGetter represents an ingress port resource accessor
+type IngressPortGetter interface {
+	IngressPort() IngressPortInterface
+}
+
+// IngressPortInterface captures the set of operations for interacting with the EaseMesh REST apis of the ingress port resource.
+type IngressPortInterface interface {
+	Get(context.Context, string) (*resource.IngressPort, error)
+	Patch(context.Context, *resource.IngressPort) error
+	Create(context.Context, *resource.IngressPort) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.IngressPort, error)
+}
+
+type ingressPortGetter struct {
+	client *meshClient
+}
+
+func (g *ingressPortGetter) IngressPort() IngressPortInterface {
+	return &ingressPortInterface{client: g.client}
+}
+
+type ingressPortInterface struct {
+	client *meshClient
+}
+
+func (i *ingressPortInterface) Get(ctx context.Context, name string) (*resource.IngressPort, error) {
+	if err := i.client.requireAPI(ctx, APIIngressPorts); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+i.client.server+MeshIngressPortURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get ingress port %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.IngressPortObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to ingress port")
+			}
+			return resource.ToIngressPort(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.IngressPort), nil
+}
+
+func (i *ingressPortInterface) Patch(ctx context.Context, ingressPort *resource.IngressPort) error {
+	if err := i.client.requireAPI(ctx, APIIngressPorts); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+i.client.server+MeshIngressPortURL, ingressPort.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, ingressPort.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch ingress port %s", ingressPort.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch ingress port %s", ingressPort.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *ingressPortInterface) Create(ctx context.Context, ingressPort *resource.IngressPort) error {
+	if err := i.client.requireAPI(ctx, APIIngressPorts); err != nil {
+		return err
+	}
+	url := "http://" + i.client.server + MeshIngressPortsURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, ingressPort.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create ingress port %s", ingressPort.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *ingressPortInterface) Delete(ctx context.Context, name string) error {
+	if err := i.client.requireAPI(ctx, APIIngressPorts); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+i.client.server+MeshIngressPortURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete ingress port %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *ingressPortInterface) List(ctx context.Context) ([]*resource.IngressPort, error) {
+	if err := i.client.requireAPI(ctx, APIIngressPorts); err != nil {
+		return nil, err
+	}
+	url := "http://" + i.client.server + MeshIngressPortsURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list ingress port")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.IngressPortObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal ingress port result")
+			}
+
+			results := []*resource.IngressPort{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToIngressPort(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.IngressPort), err
+}
@@ -43,8 +43,28 @@ type V1Alpha1Interface interface {
 	HTTPRouteGroupGetter
 	TrafficTargetGetter
 	ServiceCanaryGetter
+	ExternalServiceGetter
+	TenantPolicyGetter
+	SLOGetter
+	AlertRuleGetter
+	MessagingPolicyGetter
+	IngressPortGetter
+	WAFPolicyGetter
+	MaintenanceModeGetter
+	PolicyRolloutGetter
 	CustomResourceKindGetter
 	CustomResourceGetter
+	RevisionGetter
+	AuditGetter
+	AccessTokenGetter
+	EventGetter
+	ProxyStatusGetter
+	IngressCertificateGetter
+	IngressCacheGetter
+	ApplySetGetter
+	ResourceMetaGetter
+	StatusGetter
+	VersionGetter
 }
 
 // MeshControllerGetter represents a mesh controller resource accessor
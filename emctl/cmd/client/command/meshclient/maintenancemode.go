@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// MaintenanceModeGetter represents a maintenance mode resource accessor
+type MaintenanceModeGetter interface {
+	MaintenanceMode() MaintenanceModeInterface
+}
+
+// MaintenanceModeInterface captures the set of operations for interacting with the EaseMesh REST apis of the maintenance mode resource.
+type MaintenanceModeInterface interface {
+	Get(context.Context, string) (*resource.MaintenanceMode, error)
+	Patch(context.Context, *resource.MaintenanceMode) error
+	Create(context.Context, *resource.MaintenanceMode) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.MaintenanceMode, error)
+}
+
+type maintenanceModeGetter struct {
+	client *meshClient
+}
+
+func (g *maintenanceModeGetter) MaintenanceMode() MaintenanceModeInterface {
+	return &maintenanceModeInterface{client: g.client}
+}
+
+type maintenanceModeInterface struct {
+	client *meshClient
+}
+
+func (t *maintenanceModeInterface) Get(ctx context.Context, name string) (*resource.MaintenanceMode, error) {
+	if err := t.client.requireAPI(ctx, APIMaintenanceModes); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshMaintenanceModeURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get maintenance mode %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.MaintenanceModeObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to maintenance mode")
+			}
+			return resource.ToMaintenanceMode(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.MaintenanceMode), nil
+}
+
+func (t *maintenanceModeInterface) Patch(ctx context.Context, maintenanceMode *resource.MaintenanceMode) error {
+	if err := t.client.requireAPI(ctx, APIMaintenanceModes); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshMaintenanceModeURL, maintenanceMode.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, maintenanceMode.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch maintenance mode %s", maintenanceMode.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch maintenance mode %s", maintenanceMode.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *maintenanceModeInterface) Create(ctx context.Context, maintenanceMode *resource.MaintenanceMode) error {
+	if err := t.client.requireAPI(ctx, APIMaintenanceModes); err != nil {
+		return err
+	}
+	url := "http://" + t.client.server + MeshMaintenanceModesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, maintenanceMode.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create maintenance mode %s", maintenanceMode.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *maintenanceModeInterface) Delete(ctx context.Context, name string) error {
+	if err := t.client.requireAPI(ctx, APIMaintenanceModes); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshMaintenanceModeURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete maintenance mode %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *maintenanceModeInterface) List(ctx context.Context) ([]*resource.MaintenanceMode, error) {
+	if err := t.client.requireAPI(ctx, APIMaintenanceModes); err != nil {
+		return nil, err
+	}
+	url := "http://" + t.client.server + MeshMaintenanceModesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list maintenance mode")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.MaintenanceModeObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal maintenance mode result")
+			}
+
+			results := []*resource.MaintenanceMode{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToMaintenanceMode(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.MaintenanceMode), err
+}
@@ -61,8 +61,28 @@ type v1alpha1Interface struct {
 	httpRouteGroupGetter
 	trafficTargetGetter
 	serviceCanaryGetter
+	externalServiceGetter
+	tenantPolicyGetter
+	sloGetter
+	alertRuleGetter
+	messagingPolicyGetter
+	ingressPortGetter
+	wafPolicyGetter
+	maintenanceModeGetter
+	policyRolloutGetter
 	customResourceKindGetter
 	customResourceGetter
+	revisionGetter
+	auditGetter
+	accessTokenGetter
+	eventGetter
+	proxyStatusGetter
+	ingressCertificateGetter
+	ingressCacheGetter
+	applySetGetter
+	resourceMetaGetter
+	statusGetter
+	versionGetter
 }
 
 var _ V1Alpha1Interface = &v1alpha1Interface{}
@@ -93,8 +113,28 @@ func New(server string) MeshClient {
 		httpRouteGroupGetter:     httpRouteGroupGetter{client: client},
 		trafficTargetGetter:      trafficTargetGetter{client: client},
 		serviceCanaryGetter:      serviceCanaryGetter{client: client},
+		externalServiceGetter:    externalServiceGetter{client: client},
+		tenantPolicyGetter:       tenantPolicyGetter{client: client},
+		sloGetter:                sloGetter{client: client},
+		alertRuleGetter:          alertRuleGetter{client: client},
+		messagingPolicyGetter:    messagingPolicyGetter{client: client},
+		ingressPortGetter:        ingressPortGetter{client: client},
+		wafPolicyGetter:          wafPolicyGetter{client: client},
+		maintenanceModeGetter:    maintenanceModeGetter{client: client},
+		policyRolloutGetter:      policyRolloutGetter{client: client},
 		customResourceKindGetter: customResourceKindGetter{client: client},
 		customResourceGetter:     customResourceGetter{client: client},
+		revisionGetter:           revisionGetter{client: client},
+		auditGetter:              auditGetter{client: client},
+		accessTokenGetter:        accessTokenGetter{client: client},
+		eventGetter:              eventGetter{client: client},
+		proxyStatusGetter:        proxyStatusGetter{client: client},
+		ingressCertificateGetter: ingressCertificateGetter{client: client},
+		ingressCacheGetter:       ingressCacheGetter{client: client},
+		applySetGetter:           applySetGetter{client: client},
+		resourceMetaGetter:       resourceMetaGetter{client: client},
+		statusGetter:             statusGetter{client: client},
+		versionGetter:            versionGetter{client: client},
 	}
 	client.v1Alpha1 = &alpha1
 	return client
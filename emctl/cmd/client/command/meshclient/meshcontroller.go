@@ -82,6 +82,10 @@ func (t *meshControllerInterface) Patch(ctx context.Context, meshController *res
 				return nil, errors.Wrapf(NotFoundError, "patch meshController %s", meshController.Name())
 			}
 
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch meshController %s", meshController.Name())
+			}
+
 			if statusCode < 300 && statusCode >= 200 {
 				return nil, nil
 			}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// MessagingPolicyGetter represents a messaging policy resource accessor
+type MessagingPolicyGetter interface {
+	MessagingPolicy() MessagingPolicyInterface
+}
+
+// MessagingPolicyInterface captures the set of operations for interacting with the EaseMesh REST apis of the messaging policy resource.
+type MessagingPolicyInterface interface {
+	Get(context.Context, string) (*resource.MessagingPolicy, error)
+	Patch(context.Context, *resource.MessagingPolicy) error
+	Create(context.Context, *resource.MessagingPolicy) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.MessagingPolicy, error)
+}
+
+type messagingPolicyGetter struct {
+	client *meshClient
+}
+
+func (g *messagingPolicyGetter) MessagingPolicy() MessagingPolicyInterface {
+	return &messagingPolicyInterface{client: g.client}
+}
+
+type messagingPolicyInterface struct {
+	client *meshClient
+}
+
+func (t *messagingPolicyInterface) Get(ctx context.Context, name string) (*resource.MessagingPolicy, error) {
+	if err := t.client.requireAPI(ctx, APIMessagingPolicies); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshMessagingPolicyURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get messaging policy %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.MessagingPolicyObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to messaging policy")
+			}
+			return resource.ToMessagingPolicy(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.MessagingPolicy), nil
+}
+
+func (t *messagingPolicyInterface) Patch(ctx context.Context, messagingPolicy *resource.MessagingPolicy) error {
+	if err := t.client.requireAPI(ctx, APIMessagingPolicies); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshMessagingPolicyURL, messagingPolicy.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, messagingPolicy.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch messaging policy %s", messagingPolicy.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch messaging policy %s", messagingPolicy.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *messagingPolicyInterface) Create(ctx context.Context, messagingPolicy *resource.MessagingPolicy) error {
+	if err := t.client.requireAPI(ctx, APIMessagingPolicies); err != nil {
+		return err
+	}
+	url := "http://" + t.client.server + MeshMessagingPoliciesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, messagingPolicy.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create messaging policy %s", messagingPolicy.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *messagingPolicyInterface) Delete(ctx context.Context, name string) error {
+	if err := t.client.requireAPI(ctx, APIMessagingPolicies); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshMessagingPolicyURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete messaging policy %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *messagingPolicyInterface) List(ctx context.Context) ([]*resource.MessagingPolicy, error) {
+	if err := t.client.requireAPI(ctx, APIMessagingPolicies); err != nil {
+		return nil, err
+	}
+	url := "http://" + t.client.server + MeshMessagingPoliciesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list messaging policy")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.MessagingPolicyObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal messaging policy result")
+			}
+
+			results := []*resource.MessagingPolicy{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToMessagingPolicy(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.MessagingPolicy), err
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// PolicyRolloutGetter represents a policy rollout resource accessor
+type PolicyRolloutGetter interface {
+	PolicyRollout() PolicyRolloutInterface
+}
+
+// PolicyRolloutInterface captures the set of operations for interacting with the EaseMesh REST apis of the policy rollout resource.
+type PolicyRolloutInterface interface {
+	Get(context.Context, string) (*resource.PolicyRollout, error)
+	Patch(context.Context, *resource.PolicyRollout) error
+	Create(context.Context, *resource.PolicyRollout) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.PolicyRollout, error)
+}
+
+type policyRolloutGetter struct {
+	client *meshClient
+}
+
+func (g *policyRolloutGetter) PolicyRollout() PolicyRolloutInterface {
+	return &policyRolloutInterface{client: g.client}
+}
+
+type policyRolloutInterface struct {
+	client *meshClient
+}
+
+func (t *policyRolloutInterface) Get(ctx context.Context, name string) (*resource.PolicyRollout, error) {
+	if err := t.client.requireAPI(ctx, APIPolicyRollouts); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshPolicyRolloutURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get policy rollout %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.PolicyRolloutObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to policy rollout")
+			}
+			return resource.ToPolicyRollout(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.PolicyRollout), nil
+}
+
+func (t *policyRolloutInterface) Patch(ctx context.Context, policyRollout *resource.PolicyRollout) error {
+	if err := t.client.requireAPI(ctx, APIPolicyRollouts); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshPolicyRolloutURL, policyRollout.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, policyRollout.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch policy rollout %s", policyRollout.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch policy rollout %s", policyRollout.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *policyRolloutInterface) Create(ctx context.Context, policyRollout *resource.PolicyRollout) error {
+	if err := t.client.requireAPI(ctx, APIPolicyRollouts); err != nil {
+		return err
+	}
+	url := "http://" + t.client.server + MeshPolicyRolloutsURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, policyRollout.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create policy rollout %s", policyRollout.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *policyRolloutInterface) Delete(ctx context.Context, name string) error {
+	if err := t.client.requireAPI(ctx, APIPolicyRollouts); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshPolicyRolloutURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete policy rollout %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *policyRolloutInterface) List(ctx context.Context) ([]*resource.PolicyRollout, error) {
+	if err := t.client.requireAPI(ctx, APIPolicyRollouts); err != nil {
+		return nil, err
+	}
+	url := "http://" + t.client.server + MeshPolicyRolloutsURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list policy rollout")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.PolicyRolloutObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal policy rollout result")
+			}
+
+			results := []*resource.PolicyRollout{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToPolicyRollout(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.PolicyRollout), err
+}
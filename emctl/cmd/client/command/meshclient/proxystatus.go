@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// ProxyStatusGetter represents a ProxyStatus accessor
+type ProxyStatusGetter interface {
+	ProxyStatus() ProxyStatusInterface
+}
+
+// ProxyStatusInterface captures the set of operations for interacting with the EaseMesh REST apis of proxy statuses.
+type ProxyStatusInterface interface {
+	List(context.Context) ([]*resource.ProxyStatus, error)
+}
+
+type proxyStatusGetter struct {
+	client *meshClient
+}
+
+func (p *proxyStatusGetter) ProxyStatus() ProxyStatusInterface {
+	return &proxyStatusInterface{client: p.client}
+}
+
+type proxyStatusInterface struct {
+	client *meshClient
+}
+
+func (p *proxyStatusInterface) List(ctx context.Context) ([]*resource.ProxyStatus, error) {
+	if err := p.client.requireAPI(ctx, APIProxyStatuses); err != nil {
+		return nil, err
+	}
+	url := "http://" + p.client.server + MeshProxyStatusesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list proxy statuses")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			statuses := []*resource.ProxyStatus{}
+			err := json.Unmarshal(b, &statuses)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal proxy statuses result")
+			}
+			return statuses, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.ProxyStatus), err
+}
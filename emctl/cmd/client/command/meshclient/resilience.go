@@ -15,14 +15,18 @@
  * limitations under the License.
  */
 
-//go:generate go run github.com/megaease/easemeshctl/cmd/transformer Service Resilience=services/%s/resilience
-
 package meshclient
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
 )
 
 // ResilienceGetter represents a Resilience resource accessor
@@ -38,3 +42,135 @@ type ResilienceInterface interface {
 	Delete(context.Context, string) error
 	List(context.Context) ([]*resource.Resilience, error)
 }
+
+type resilienceGetter struct {
+	client *meshClient
+}
+
+func (g *resilienceGetter) Resilience() ResilienceInterface {
+	return &resilienceInterface{client: g.client}
+}
+
+type resilienceInterface struct {
+	client *meshClient
+}
+
+func (r *resilienceInterface) Get(ctx context.Context, name string) (*resource.Resilience, error) {
+	url := fmt.Sprintf("http://"+r.client.server+MeshServiceResilienceURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get resilience %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.ResilienceSpec{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to resilience")
+			}
+			return resource.ToResilienceFromObject(name, object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.Resilience), nil
+}
+
+func (r *resilienceInterface) Patch(ctx context.Context, resilience *resource.Resilience) error {
+	url := fmt.Sprintf("http://"+r.client.server+MeshServiceResilienceURL, resilience.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, resilience.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch resilience %s", resilience.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch resilience %s", resilience.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (r *resilienceInterface) Create(ctx context.Context, resilience *resource.Resilience) error {
+	url := fmt.Sprintf("http://"+r.client.server+MeshServiceResilienceURL, resilience.Name())
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, resilience.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create resilience %s", resilience.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (r *resilienceInterface) Delete(ctx context.Context, name string) error {
+	url := fmt.Sprintf("http://"+r.client.server+MeshServiceResilienceURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete resilience %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+// List lists resiliences of all services, services without resilience are skipped.
+func (r *resilienceInterface) List(ctx context.Context) ([]*resource.Resilience, error) {
+	url := "http://" + r.client.server + MeshServicesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list service")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			// NOTE: Services are decoded partially to keep hedging of resiliences.
+			services := []struct {
+				Name       string                   `json:"name"`
+				Resilience *resource.ResilienceSpec `json:"resilience"`
+			}{}
+			err := json.Unmarshal(b, &services)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal service result")
+			}
+
+			results := []*resource.Resilience{}
+			for _, service := range services {
+				if service.Resilience != nil {
+					results = append(results, resource.ToResilienceFromObject(service.Name, service.Resilience))
+				}
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*resource.Resilience), nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// ResourceMetaGetter represents a ResourceMeta accessor
+type ResourceMetaGetter interface {
+	ResourceMeta() ResourceMetaInterface
+}
+
+// ResourceMetaInterface captures the set of operations for interacting with the EaseMesh REST apis of the resource meta.
+type ResourceMetaInterface interface {
+	Get(context.Context, string) (*resource.ResourceMeta, error)
+	Patch(context.Context, *resource.ResourceMeta) error
+	Create(context.Context, *resource.ResourceMeta) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.ResourceMeta, error)
+}
+
+type resourceMetaGetter struct {
+	client *meshClient
+}
+
+func (r *resourceMetaGetter) ResourceMeta() ResourceMetaInterface {
+	return &resourceMetaInterface{client: r.client}
+}
+
+type resourceMetaInterface struct {
+	client *meshClient
+}
+
+func (r *resourceMetaInterface) Get(ctx context.Context, name string) (*resource.ResourceMeta, error) {
+	if err := r.client.requireAPI(ctx, APIResourceMetas); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+r.client.server+MeshResourceMetaURL, url.PathEscape(name))
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get resource meta %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.ResourceMetaObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to ResourceMeta")
+			}
+			return resource.ToResourceMeta(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.ResourceMeta), nil
+}
+
+func (r *resourceMetaInterface) Patch(ctx context.Context, resourceMeta *resource.ResourceMeta) error {
+	if err := r.client.requireAPI(ctx, APIResourceMetas); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+r.client.server+MeshResourceMetaURL, url.PathEscape(resourceMeta.Name()))
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, resourceMeta.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch resource meta %s", resourceMeta.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch resource meta %s", resourceMeta.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (r *resourceMetaInterface) Create(ctx context.Context, resourceMeta *resource.ResourceMeta) error {
+	if err := r.client.requireAPI(ctx, APIResourceMetas); err != nil {
+		return err
+	}
+	url := "http://" + r.client.server + MeshResourceMetasURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, resourceMeta.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create resource meta %s", resourceMeta.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (r *resourceMetaInterface) Delete(ctx context.Context, name string) error {
+	if err := r.client.requireAPI(ctx, APIResourceMetas); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+r.client.server+MeshResourceMetaURL, url.PathEscape(name))
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete resource meta %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (r *resourceMetaInterface) List(ctx context.Context) ([]*resource.ResourceMeta, error) {
+	if err := r.client.requireAPI(ctx, APIResourceMetas); err != nil {
+		return nil, err
+	}
+	url := "http://" + r.client.server + MeshResourceMetasURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list resource meta")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.ResourceMetaObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal resource meta result")
+			}
+
+			results := []*resource.ResourceMeta{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToResourceMeta(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.ResourceMeta), err
+}
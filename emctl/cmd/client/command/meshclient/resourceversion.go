@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package meshclient
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// WithResourceVersion returns a context whose updates of resources fail
+// with StaleError if the resource has been modified since the version.
+func WithResourceVersion(ctx context.Context, version string) context.Context {
+	if version == "" {
+		return ctx
+	}
+	return client.WithHeader(ctx, ResourceVersionHeader, version)
+}
+
+// ResourceVersion returns the resource version of the resource, which is the
+// number of its latest revision. It's empty if the resource has no revisions,
+// or the control plane keeps none.
+func ResourceVersion(ctx context.Context, mc MeshClient, kind, name string) (string, error) {
+	revisions, err := mc.V1Alpha1().Revision().List(ctx, kind, name)
+	switch {
+	case IsNotFoundError(err), IsUnsupportedError(err):
+		return "", nil
+	case err != nil:
+		return "", errors.Wrapf(err, "get resource version of %s/%s", kind, name)
+	case len(revisions) == 0:
+		return "", nil
+	}
+
+	latest := revisions[len(revisions)-1]
+	if latest.Spec == nil {
+		return "", nil
+	}
+	return strconv.FormatInt(latest.Spec.Revision, 10), nil
+}
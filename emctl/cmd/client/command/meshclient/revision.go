@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// RevisionGetter represents a Revision accessor
+type RevisionGetter interface {
+	Revision() RevisionInterface
+}
+
+// RevisionInterface captures the set of operations for interacting with the EaseMesh REST apis of the resource revisions.
+type RevisionInterface interface {
+	// List lists revisions of the resource, ordered by revision ascending.
+	List(ctx context.Context, kind, name string) ([]*resource.Revision, error)
+	// Get gets one revision of the resource.
+	Get(ctx context.Context, kind, name string, revision int64) (*resource.Revision, error)
+	// Rollback restores the resource to the revision, which creates a new revision.
+	Rollback(ctx context.Context, kind, name string, revision int64) error
+}
+
+type revisionGetter struct {
+	client *meshClient
+}
+
+func (r *revisionGetter) Revision() RevisionInterface {
+	return &revisionInterface{client: r.client}
+}
+
+type revisionInterface struct {
+	client *meshClient
+}
+
+func (r *revisionInterface) List(ctx context.Context, kind, name string) ([]*resource.Revision, error) {
+	if err := r.client.requireAPI(ctx, APIRevisions); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+r.client.server+MeshRevisionsURL, kind, name)
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "list revisions of %s/%s", kind, name)
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.RevisionObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal revisions result")
+			}
+
+			results := []*resource.Revision{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToRevision(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.Revision), err
+}
+
+func (r *revisionInterface) Get(ctx context.Context, kind, name string, revision int64) (*resource.Revision, error) {
+	if err := r.client.requireAPI(ctx, APIRevisions); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+r.client.server+MeshRevisionURL, kind, name, revision)
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get revision %d of %s/%s", revision, kind, name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+
+			object := &resource.RevisionObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to Revision")
+			}
+			return resource.ToRevision(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*resource.Revision), nil
+}
+
+func (r *revisionInterface) Rollback(ctx context.Context, kind, name string, revision int64) error {
+	if err := r.client.requireAPI(ctx, APIRevisions); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+r.client.server+MeshRevisionRollbackURL, kind, name, revision)
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "rollback %s/%s to revision %d", kind, name, revision)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call POST %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
@@ -15,14 +15,18 @@
  * limitations under the License.
  */
 
-//go:generate go run github.com/megaease/easemeshctl/cmd/transformer Global ServiceCanary=servicecanaries/%s
-
 package meshclient
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
 )
 
 // ServiceCanaryGetter represents a ServiceCanary resource accessor.
@@ -39,3 +43,128 @@ type ServiceCanaryInterface interface {
 	Delete(context.Context, string) error
 	List(context.Context) ([]*resource.ServiceCanary, error)
 }
+
+type serviceCanaryGetter struct {
+	client *meshClient
+}
+
+func (g *serviceCanaryGetter) ServiceCanary() ServiceCanaryInterface {
+	return &serviceCanaryInterface{client: g.client}
+}
+
+type serviceCanaryInterface struct {
+	client *meshClient
+}
+
+func (t *serviceCanaryInterface) Get(ctx context.Context, name string) (*resource.ServiceCanary, error) {
+	url := fmt.Sprintf("http://"+t.client.server+MeshServiceCanaryObjectURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get service canary %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.ServiceCanaryObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to service canary")
+			}
+			return resource.ToServiceCanaryFromObject(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.ServiceCanary), nil
+}
+
+func (t *serviceCanaryInterface) Patch(ctx context.Context, serviceCanary *resource.ServiceCanary) error {
+	url := fmt.Sprintf("http://"+t.client.server+MeshServiceCanaryObjectURL, serviceCanary.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, serviceCanary.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch service canary %s", serviceCanary.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch service canary %s", serviceCanary.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *serviceCanaryInterface) Create(ctx context.Context, serviceCanary *resource.ServiceCanary) error {
+	url := "http://" + t.client.server + MeshServiceCanariesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, serviceCanary.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create service canary %s", serviceCanary.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *serviceCanaryInterface) Delete(ctx context.Context, name string) error {
+	url := fmt.Sprintf("http://"+t.client.server+MeshServiceCanaryObjectURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete service canary %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *serviceCanaryInterface) List(ctx context.Context) ([]*resource.ServiceCanary, error) {
+	url := "http://" + t.client.server + MeshServiceCanariesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list service canary")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.ServiceCanaryObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal service canary result")
+			}
+
+			results := []*resource.ServiceCanary{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToServiceCanaryFromObject(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.ServiceCanary), err
+}
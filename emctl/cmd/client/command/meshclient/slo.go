@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// SLOGetter represents an SLO resource accessor
+type SLOGetter interface {
+	SLO() SLOInterface
+}
+
+// SLOInterface captures the set of operations for interacting with the EaseMesh REST apis of the SLO resource.
+type SLOInterface interface {
+	Get(context.Context, string) (*resource.SLO, error)
+	Patch(context.Context, *resource.SLO) error
+	Create(context.Context, *resource.SLO) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.SLO, error)
+}
+
+type sloGetter struct {
+	client *meshClient
+}
+
+func (g *sloGetter) SLO() SLOInterface {
+	return &sloInterface{client: g.client}
+}
+
+type sloInterface struct {
+	client *meshClient
+}
+
+func (t *sloInterface) Get(ctx context.Context, name string) (*resource.SLO, error) {
+	if err := t.client.requireAPI(ctx, APISLOs); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshSLOURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get SLO %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.SLOObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to SLO")
+			}
+			return resource.ToSLO(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.SLO), nil
+}
+
+func (t *sloInterface) Patch(ctx context.Context, slo *resource.SLO) error {
+	if err := t.client.requireAPI(ctx, APISLOs); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshSLOURL, slo.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, slo.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch SLO %s", slo.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch SLO %s", slo.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *sloInterface) Create(ctx context.Context, slo *resource.SLO) error {
+	if err := t.client.requireAPI(ctx, APISLOs); err != nil {
+		return err
+	}
+	url := "http://" + t.client.server + MeshSLOsURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, slo.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create SLO %s", slo.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *sloInterface) Delete(ctx context.Context, name string) error {
+	if err := t.client.requireAPI(ctx, APISLOs); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshSLOURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete SLO %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *sloInterface) List(ctx context.Context) ([]*resource.SLO, error) {
+	if err := t.client.requireAPI(ctx, APISLOs); err != nil {
+		return nil, err
+	}
+	url := "http://" + t.client.server + MeshSLOsURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list SLO")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.SLOObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal SLO result")
+			}
+
+			results := []*resource.SLO{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToSLO(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.SLO), err
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// StatusGetter represents a ResourceStatus accessor
+type StatusGetter interface {
+	Status() StatusInterface
+}
+
+// StatusInterface captures the set of operations for interacting with the EaseMesh REST apis of the resource statuses.
+type StatusInterface interface {
+	// Get gets the status of the resource, it's NotFoundError if the resource doesn't exist.
+	Get(ctx context.Context, kind, name string) (*resource.ResourceStatus, error)
+	// List lists statuses of all resources of the kind.
+	List(ctx context.Context, kind string) ([]*resource.ResourceStatus, error)
+}
+
+type statusGetter struct {
+	client *meshClient
+}
+
+func (s *statusGetter) Status() StatusInterface {
+	return &statusInterface{client: s.client}
+}
+
+type statusInterface struct {
+	client *meshClient
+}
+
+func (s *statusInterface) Get(ctx context.Context, kind, name string) (*resource.ResourceStatus, error) {
+	if err := s.client.requireAPI(ctx, APIStatuses); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+s.client.server+MeshResourceStatusURL, kind, url.PathEscape(name))
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get status of %s/%s", kind, name)
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			status := &resource.ResourceStatus{}
+			err := json.Unmarshal(b, status)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal status of %s/%s", kind, name)
+			}
+			return status, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*resource.ResourceStatus), nil
+}
+
+func (s *statusInterface) List(ctx context.Context, kind string) ([]*resource.ResourceStatus, error) {
+	if err := s.client.requireAPI(ctx, APIStatuses); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+s.client.server+MeshResourceStatusesURL, kind)
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			// NOTE: Control planes before statuses don't serve them.
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "list statuses of %s", kind)
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			statuses := []*resource.ResourceStatus{}
+			err := json.Unmarshal(b, &statuses)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal statuses of %s", kind)
+			}
+			return statuses, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.ResourceStatus), nil
+}
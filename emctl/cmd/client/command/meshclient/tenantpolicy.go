@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// TenantPolicyGetter represents a TenantPolicy resource accessor
+type TenantPolicyGetter interface {
+	TenantPolicy() TenantPolicyInterface
+}
+
+// TenantPolicyInterface captures the set of operations for interacting with the EaseMesh REST apis of the tenant policy resource.
+type TenantPolicyInterface interface {
+	Get(context.Context, string) (*resource.TenantPolicy, error)
+	Patch(context.Context, *resource.TenantPolicy) error
+	Create(context.Context, *resource.TenantPolicy) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.TenantPolicy, error)
+}
+
+type tenantPolicyGetter struct {
+	client *meshClient
+}
+
+func (g *tenantPolicyGetter) TenantPolicy() TenantPolicyInterface {
+	return &tenantPolicyInterface{client: g.client}
+}
+
+type tenantPolicyInterface struct {
+	client *meshClient
+}
+
+func (t *tenantPolicyInterface) Get(ctx context.Context, name string) (*resource.TenantPolicy, error) {
+	if err := t.client.requireAPI(ctx, APITenantPolicies); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshTenantPolicyURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get tenant policy %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.TenantPolicyObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to TenantPolicy")
+			}
+			return resource.ToTenantPolicy(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.TenantPolicy), nil
+}
+
+func (t *tenantPolicyInterface) Patch(ctx context.Context, tenantPolicy *resource.TenantPolicy) error {
+	if err := t.client.requireAPI(ctx, APITenantPolicies); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshTenantPolicyURL, tenantPolicy.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, tenantPolicy.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch tenant policy %s", tenantPolicy.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch tenant policy %s", tenantPolicy.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *tenantPolicyInterface) Create(ctx context.Context, tenantPolicy *resource.TenantPolicy) error {
+	if err := t.client.requireAPI(ctx, APITenantPolicies); err != nil {
+		return err
+	}
+	url := "http://" + t.client.server + MeshTenantPoliciesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, tenantPolicy.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create tenant policy %s", tenantPolicy.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *tenantPolicyInterface) Delete(ctx context.Context, name string) error {
+	if err := t.client.requireAPI(ctx, APITenantPolicies); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+t.client.server+MeshTenantPolicyURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete tenant policy %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (t *tenantPolicyInterface) List(ctx context.Context) ([]*resource.TenantPolicy, error) {
+	if err := t.client.requireAPI(ctx, APITenantPolicies); err != nil {
+		return nil, err
+	}
+	url := "http://" + t.client.server + MeshTenantPoliciesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list tenant policy")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.TenantPolicyObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal tenant policy result")
+			}
+
+			results := []*resource.TenantPolicy{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToTenantPolicy(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.TenantPolicy), err
+}
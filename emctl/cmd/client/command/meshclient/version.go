@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// VersionGetter represents a version accessor of the control plane
+type VersionGetter interface {
+	Version() VersionInterface
+}
+
+// VersionInterface captures the set of operations for interacting with the EaseMesh REST apis of the version.
+type VersionInterface interface {
+	Get(context.Context) (*resource.ControlPlaneVersion, error)
+}
+
+type versionGetter struct {
+	client *meshClient
+}
+
+func (v *versionGetter) Version() VersionInterface {
+	return &versionInterface{client: v.client}
+}
+
+type versionInterface struct {
+	client *meshClient
+}
+
+func (v *versionInterface) Get(ctx context.Context) (*resource.ControlPlaneVersion, error) {
+	if err := v.client.requireAPI(ctx, APIVersion); err != nil {
+		return nil, err
+	}
+	url := "http://" + v.client.server + MeshVersionURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			// NOTE: Control planes before the version API don't serve it.
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "get version of the control plane")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			version := &resource.ControlPlaneVersion{}
+			err := json.Unmarshal(b, version)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal version of the control plane")
+			}
+			return version, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*resource.ControlPlaneVersion), nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// WAFPolicyGetter represents a WAF policy resource accessor
+type WAFPolicyGetter interface {
+	WAFPolicy() WAFPolicyInterface
+}
+
+// WAFPolicyInterface captures the set of operations for interacting with the EaseMesh REST apis of the WAF policy resource.
+type WAFPolicyInterface interface {
+	Get(context.Context, string) (*resource.WAFPolicy, error)
+	Patch(context.Context, *resource.WAFPolicy) error
+	Create(context.Context, *resource.WAFPolicy) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]*resource.WAFPolicy, error)
+}
+
+type wafPolicyGetter struct {
+	client *meshClient
+}
+
+func (g *wafPolicyGetter) WAFPolicy() WAFPolicyInterface {
+	return &wafPolicyInterface{client: g.client}
+}
+
+type wafPolicyInterface struct {
+	client *meshClient
+}
+
+func (i *wafPolicyInterface) Get(ctx context.Context, name string) (*resource.WAFPolicy, error) {
+	if err := i.client.requireAPI(ctx, APIWAFPolicies); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("http://"+i.client.server+MeshWAFPolicyURL, name)
+	re, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "get WAF policy %s", name)
+			}
+
+			if statusCode >= 300 {
+				return nil, errors.Errorf("call %s failed, return status code: %d text:%s", url, statusCode, string(b))
+			}
+			object := &resource.WAFPolicyObject{}
+			err := json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal data to WAF policy")
+			}
+			return resource.ToWAFPolicy(object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return re.(*resource.WAFPolicy), nil
+}
+
+func (i *wafPolicyInterface) Patch(ctx context.Context, wafPolicy *resource.WAFPolicy) error {
+	if err := i.client.requireAPI(ctx, APIWAFPolicies); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+i.client.server+MeshWAFPolicyURL, wafPolicy.Name())
+	_, err := client.NewHTTPJSON().
+		PutByContext(ctx, url, wafPolicy.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "patch WAF policy %s", wafPolicy.Name())
+			}
+
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(StaleError, "patch WAF policy %s", wafPolicy.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call PUT %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *wafPolicyInterface) Create(ctx context.Context, wafPolicy *resource.WAFPolicy) error {
+	if err := i.client.requireAPI(ctx, APIWAFPolicies); err != nil {
+		return err
+	}
+	url := "http://" + i.client.server + MeshWAFPoliciesURL
+	_, err := client.NewHTTPJSON().
+		PostByContext(ctx, url, wafPolicy.ToObject(), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusConflict {
+				return nil, errors.Wrapf(ConflictError, "create WAF policy %s", wafPolicy.Name())
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call Post %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *wafPolicyInterface) Delete(ctx context.Context, name string) error {
+	if err := i.client.requireAPI(ctx, APIWAFPolicies); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://"+i.client.server+MeshWAFPolicyURL, name)
+	_, err := client.NewHTTPJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrapf(NotFoundError, "delete WAF policy %s", name)
+			}
+
+			if statusCode < 300 && statusCode >= 200 {
+				return nil, nil
+			}
+			return nil, errors.Errorf("call DELETE %s failed, return statuscode %d text %s", url, statusCode, string(b))
+		})
+	return err
+}
+
+func (i *wafPolicyInterface) List(ctx context.Context) ([]*resource.WAFPolicy, error) {
+	if err := i.client.requireAPI(ctx, APIWAFPolicies); err != nil {
+		return nil, err
+	}
+	url := "http://" + i.client.server + MeshWAFPoliciesURL
+	result, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return nil, errors.Wrap(NotFoundError, "list WAF policy")
+			}
+
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []resource.WAFPolicyObject{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal WAF policy result")
+			}
+
+			results := []*resource.WAFPolicy{}
+			for _, object := range objects {
+				copy := object
+				results = append(results, resource.ToWAFPolicy(&copy))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*resource.WAFPolicy), err
+}
@@ -66,6 +66,9 @@ func (c *canaryInterface) Patch(args0 context.Context, args1 *resource.Canary) e
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch Canary %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch Canary %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -66,6 +66,9 @@ func (h *hTTPRouteGroupInterface) Patch(args0 context.Context, args1 *resource.H
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch HTTPRouteGroup %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch HTTPRouteGroup %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
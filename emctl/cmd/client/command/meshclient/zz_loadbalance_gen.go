@@ -66,6 +66,9 @@ func (l *loadBalanceInterface) Patch(args0 context.Context, args1 *resource.Load
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch LoadBalance %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch LoadBalance %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -66,6 +66,9 @@ func (m *mockInterface) Patch(args0 context.Context, args1 *resource.Mock) error
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch Mock %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch Mock %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -78,6 +78,9 @@ func (o *observabilityOutputServerInterface) Patch(args0 context.Context, args1
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch ObservabilityOutputServer %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch ObservabilityOutputServer %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -167,6 +170,9 @@ func (o *observabilityMetricsInterface) Patch(args0 context.Context, args1 *reso
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch ObservabilityMetrics %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch ObservabilityMetrics %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -256,6 +262,9 @@ func (o *observabilityTracingsInterface) Patch(args0 context.Context, args1 *res
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch ObservabilityTracings %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch ObservabilityTracings %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -66,6 +66,9 @@ func (t *trafficTargetInterface) Patch(args0 context.Context, args1 *resource.Tr
 		if statusCode == http.StatusNotFound {
 			return nil, errors.Wrapf(NotFoundError, "patch TrafficTarget %s", args1.Name())
 		}
+		if statusCode == http.StatusConflict {
+			return nil, errors.Wrapf(StaleError, "patch TrafficTarget %s", args1.Name())
+		}
 		if statusCode < 300 && statusCode >= 200 {
 			return nil, nil
 		}
@@ -0,0 +1,377 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// registryTypes are registry types supported by the mesh controller.
+var registryTypes = []string{"eureka", "consul", "nacos"}
+
+// RunEdit is the entrypoint of the emctl mesh-config edit sub command
+func RunEdit(cmd *cobra.Command, flag *flags.MeshConfig) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	client := meshclient.New(flag.Server)
+	current, err := get(client, flag.Name, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("get mesh controller %s failed: %w", flag.Name, err)
+	}
+
+	original, err := yaml.Marshal(current)
+	if err != nil {
+		common.ExitWithErrorf("marshal mesh controller %s failed: %w", flag.Name, err)
+	}
+
+	edited, err := util.Edit(original, "emctl-mesh-config-*.yaml")
+	if err != nil {
+		common.ExitWithErrorf("edit mesh controller %s failed: %w", flag.Name, err)
+	}
+	if bytes.Equal(edited, original) {
+		common.Infof("edit cancelled, no changes made")
+		return
+	}
+
+	updated, err := decode(edited)
+	if err != nil {
+		common.ExitWithError(errors.Wrapf(err, "decode edited mesh controller %s", flag.Name))
+	}
+
+	changes, err := update(client, current, updated, flag)
+	if err != nil {
+		common.ExitWithErrorf("edit mesh controller %s failed: %w", flag.Name, err)
+	}
+	report(flag, changes)
+}
+
+// RunPatch is the entrypoint of the emctl mesh-config patch sub command
+func RunPatch(cmd *cobra.Command, flag *flags.MeshConfigPatch) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	patch := []byte(flag.Patch)
+	switch {
+	case flag.Patch != "" && flag.PatchFile != "":
+		common.ExitWithCodef(common.ExitCodeValidation, "--patch and --patch-file can't be specified together")
+	case flag.PatchFile != "":
+		var err error
+		patch, err = ioutil.ReadFile(flag.PatchFile)
+		if err != nil {
+			common.ExitWithErrorf("read patch file %s failed: %w", flag.PatchFile, err)
+		}
+	case flag.Patch == "":
+		common.ExitWithCodef(common.ExitCodeValidation, "no patch specified by --patch or --patch-file")
+	}
+
+	changes, err := Patch(meshclient.New(flag.Server), patch, flag.MeshConfig)
+	if err != nil {
+		common.ExitWithErrorf("patch mesh controller %s failed: %w", flag.Name, err)
+	}
+	report(flag.MeshConfig, changes)
+}
+
+// Patch applies the merge patch to the mesh controller, it returns the
+// changed fields in the form of "field: old -> new".
+func Patch(client meshclient.MeshClient, patch []byte, flag *flags.MeshConfig) ([]string, error) {
+	current, err := get(client, flag.Name, flag.Timeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get mesh controller %s", flag.Name)
+	}
+
+	patchJSON, err := yamljsontool.YAMLToJSON(patch)
+	if err != nil {
+		return nil, common.WithCode(errors.Wrap(err, "parse patch"), common.ExitCodeValidation)
+	}
+	var patchObject interface{}
+	err = json.Unmarshal(patchJSON, &patchObject)
+	if err != nil {
+		return nil, common.WithCode(errors.Wrap(err, "parse patch"), common.ExitCodeValidation)
+	}
+	if _, ok := patchObject.(map[string]interface{}); !ok {
+		return nil, common.CodeErrorf(common.ExitCodeValidation, "patch must be an object")
+	}
+
+	currentObject, err := toJSONObject(current)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := json.Marshal(util.MergePatch(currentObject, patchObject))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal patched mesh controller")
+	}
+
+	updated, err := decode(patched)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode patched mesh controller")
+	}
+
+	return update(client, current, updated, flag)
+}
+
+func get(client meshclient.MeshClient, name string, timeout time.Duration) (*resource.MeshController, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	return client.V1Alpha1().MeshController().Get(ctx, name)
+}
+
+// decode decodes the mesh controller in YAML or JSON, with the
+// validation of its schema.
+func decode(buff []byte) (*resource.MeshController, error) {
+	var objects []meta.MeshObject
+	err := util.NewReaderVisitor(bytes.NewReader(buff), "mesh controller").Visit(func(object meta.MeshObject, err error) error {
+		if err != nil {
+			return err
+		}
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) != 1 {
+		return nil, common.CodeErrorf(common.ExitCodeValidation, "expected 1 mesh controller, got %d objects", len(objects))
+	}
+	meshController, ok := objects[0].(*resource.MeshController)
+	if !ok {
+		return nil, common.CodeErrorf(common.ExitCodeValidation, "expected kind %s, got %s",
+			resource.KindMeshController, objects[0].Kind())
+	}
+
+	return meshController, nil
+}
+
+// update validates the updated mesh controller and puts it to the control plane,
+// which reloads the mesh controller without restarting. It returns the changed fields.
+func update(client meshclient.MeshClient, current, updated *resource.MeshController, flag *flags.MeshConfig) ([]string, error) {
+	if updated.Name() != current.Name() {
+		return nil, common.CodeErrorf(common.ExitCodeValidation, "name of mesh controller can't be changed from %s to %s",
+			current.Name(), updated.Name())
+	}
+
+	err := validate(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := changedFields(current, updated)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 || flag.DryRun {
+		return changes, nil
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	err = client.V1Alpha1().MeshController().Patch(ctx, updated)
+	if err != nil {
+		return nil, errors.Wrapf(err, "update mesh controller %s", updated.Name())
+	}
+
+	reloaded, err := get(client, updated.Name(), flag.Timeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get reloaded mesh controller %s", updated.Name())
+	}
+	unreloaded, err := changedFields(updated, reloaded)
+	if err != nil {
+		return nil, err
+	}
+	if len(unreloaded) != 0 {
+		common.Warnf("mesh controller %s is not reloaded with changes: %s", updated.Name(), strings.Join(unreloaded, ", "))
+	}
+
+	return changes, nil
+}
+
+// validate validates the fields which the schema can't.
+func validate(meshController *resource.MeshController) error {
+	var errs []string
+
+	interval, err := time.ParseDuration(meshController.HeartbeatInterval)
+	if err != nil || interval <= 0 {
+		errs = append(errs, fmt.Sprintf("heartbeatInterval: invalid positive duration %q", meshController.HeartbeatInterval))
+	}
+
+	if meshController.InstanceExpiry != "" {
+		expiry, err := time.ParseDuration(meshController.InstanceExpiry)
+		if err != nil || expiry <= interval {
+			errs = append(errs, fmt.Sprintf("instanceExpiry: %q must be greater than heartbeatInterval", meshController.InstanceExpiry))
+		}
+	}
+
+	if meshController.DeregistrationGracePeriod != "" {
+		gracePeriod, err := time.ParseDuration(meshController.DeregistrationGracePeriod)
+		if err != nil || gracePeriod < 0 {
+			errs = append(errs, fmt.Sprintf("deregistrationGracePeriod: invalid non-negative duration %q", meshController.DeregistrationGracePeriod))
+		}
+	}
+
+	if !supportedRegistryType(meshController.RegistryType) {
+		errs = append(errs, fmt.Sprintf("registryType: unsupported %q (support %s)",
+			meshController.RegistryType, strings.Join(registryTypes, ", ")))
+	}
+
+	for name, port := range map[string]int{"apiPort": meshController.APIPort, "ingressPort": meshController.IngressPort} {
+		if port <= 0 || port > 65535 {
+			errs = append(errs, fmt.Sprintf("%s: invalid port %d", name, port))
+		}
+	}
+
+	if meshController.RevisionHistoryLimit < 0 {
+		errs = append(errs, fmt.Sprintf("revisionHistoryLimit: invalid negative %d", meshController.RevisionHistoryLimit))
+	}
+
+	if len(errs) != 0 {
+		sort.Strings(errs)
+		return common.CodeErrorf(common.ExitCodeValidation, "invalid mesh controller: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func supportedRegistryType(registryType string) bool {
+	for _, t := range registryTypes {
+		if t == registryType {
+			return true
+		}
+	}
+	return false
+}
+
+func toJSONObject(meshController *resource.MeshController) (map[string]interface{}, error) {
+	yamlBuff, err := yaml.Marshal(meshController)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal mesh controller %s", meshController.Name())
+	}
+
+	jsonBuff, err := yamljsontool.YAMLToJSON(yamlBuff)
+	if err != nil {
+		return nil, errors.Wrapf(err, "transform mesh controller %s to json", meshController.Name())
+	}
+
+	object := map[string]interface{}{}
+	err = json.Unmarshal(jsonBuff, &object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal mesh controller %s", meshController.Name())
+	}
+
+	return object, nil
+}
+
+// changedFields returns fields changed from the old one to the new one,
+// in the form of "field: old -> new", sorted by fields.
+func changedFields(oldObject, newObject *resource.MeshController) ([]string, error) {
+	oldJSON, err := toJSONObject(oldObject)
+	if err != nil {
+		return nil, err
+	}
+	newJSON, err := toJSONObject(newObject)
+	if err != nil {
+		return nil, err
+	}
+
+	oldFields, newFields := map[string]string{}, map[string]string{}
+	flatten("", oldJSON, oldFields)
+	flatten("", newJSON, newFields)
+
+	var changes []string
+	for field, newValue := range newFields {
+		if oldValue, exists := oldFields[field]; !exists || oldValue != newValue {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", field, valueOrNone(oldValue, exists), newValue))
+		}
+	}
+	for field, oldValue := range oldFields {
+		if _, exists := newFields[field]; !exists {
+			changes = append(changes, fmt.Sprintf("%s: %s -> <none>", field, oldValue))
+		}
+	}
+
+	sort.Strings(changes)
+	return changes, nil
+}
+
+func valueOrNone(value string, exists bool) string {
+	if !exists {
+		return "<none>"
+	}
+	return value
+}
+
+// flatten flattens the object to fields in dotted paths, lists are
+// compared as a whole.
+func flatten(prefix string, value interface{}, fields map[string]string) {
+	if object, ok := value.(map[string]interface{}); ok {
+		for k, v := range object {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flatten(k, v, fields)
+		}
+		return
+	}
+
+	if value == nil {
+		return
+	}
+
+	buff, _ := json.Marshal(value)
+	fields[prefix] = string(buff)
+}
+
+func report(flag *flags.MeshConfig, changes []string) {
+	if len(changes) == 0 {
+		common.Infof("mesh controller %s unchanged", flag.Name)
+		return
+	}
+
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+
+	if flag.DryRun {
+		common.Infof("mesh controller %s validated (dry run)", flag.Name)
+		return
+	}
+	common.Logger().With("kind", resource.KindMeshController, "name", flag.Name).
+		Infof("mesh controller %s updated and reloaded", flag.Name)
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/testing/meshserver"
+	"github.com/megaease/easemeshctl/cmd/common"
+)
+
+func newTestServer(t *testing.T) *meshserver.Server {
+	server := meshserver.New()
+	err := server.LoadFixture(resource.KindMeshController)
+	if err != nil {
+		server.Close()
+		t.Fatalf("load fixture failed: %v", err)
+	}
+	return server
+}
+
+func newTestFlag() *flags.MeshConfig {
+	return &flags.MeshConfig{
+		AdminGlobal: &flags.AdminGlobal{Timeout: 5 * time.Second},
+		Name:        flags.DefaultMeshControllerName,
+	}
+}
+
+func TestPatch(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := server.Client()
+	changes, err := Patch(client, []byte(`{"heartbeatInterval": "10s", "revisionHistoryLimit": 20}`), newTestFlag())
+	if err != nil {
+		t.Fatalf("patch failed: %v", err)
+	}
+
+	expected := []string{"heartbeatInterval: \"5s\" -> \"10s\"", "revisionHistoryLimit: <none> -> 20"}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected changes %v, got %v", expected, changes)
+	}
+
+	meshController, err := client.V1Alpha1().MeshController().Get(context.Background(), flags.DefaultMeshControllerName)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if meshController.HeartbeatInterval != "10s" || meshController.RevisionHistoryLimit != 20 {
+		t.Fatalf("mesh controller is not patched: %+v", meshController.MeshControllerAdmin)
+	}
+
+	changes, err = Patch(client, []byte("heartbeatInterval: 10s"), newTestFlag())
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v: %v", changes, err)
+	}
+}
+
+func TestPatchDryRun(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	flag := newTestFlag()
+	flag.DryRun = true
+	changes, err := Patch(server.Client(), []byte("registryType: consul"), flag)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %v: %v", changes, err)
+	}
+
+	meshController, err := server.Client().V1Alpha1().MeshController().Get(context.Background(), flag.Name)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if meshController.RegistryType != "eureka" {
+		t.Fatalf("mesh controller should not be updated in dry run")
+	}
+}
+
+func TestPatchInvalid(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	for _, patch := range []string{
+		`registryType: zookeeper`,
+		`heartbeatInterval: -5s`,
+		`apiPort: 70000`,
+		`instanceExpiry: 5s`,
+		`metadata: {name: another}`,
+		`[1, 2]`,
+	} {
+		_, err := Patch(server.Client(), []byte(patch), newTestFlag())
+		if err == nil {
+			t.Fatalf("expected patch %s is invalid", patch)
+		}
+		if code := common.ExitCode(err); code != common.ExitCodeValidation {
+			t.Fatalf("expected exit code %d of patch %s, got %d: %v", common.ExitCodeValidation, patch, code, err)
+		}
+	}
+}
@@ -18,14 +18,18 @@
 package installbase
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"sort"
 	"strings"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/homedir"
 )
@@ -87,6 +91,58 @@ type (
 		HeartbeatInterval string `yaml:"heartbeatInterval" jsonschema:"required"`
 		IngressPort       int32  `yaml:"ingressPort" jsonschema:"omitempty"`
 		APIPort           int    `yaml:"apiPort" jsonschema:"required"`
+
+		RevisionHistoryLimit int `yaml:"revisionHistoryLimit,omitempty" jsonschema:"omitempty"`
+
+		InstanceExpiry            string `yaml:"instanceExpiry,omitempty" jsonschema:"omitempty"`
+		DeregistrationGracePeriod string `yaml:"deregistrationGracePeriod,omitempty" jsonschema:"omitempty"`
+
+		// ExternalServiceRegistry is the name of the registry whose services are synced with mesh services.
+		ExternalServiceRegistry string `yaml:"externalServiceRegistry,omitempty" jsonschema:"omitempty"`
+
+		// SecretStore resolves secret references of mesh resources besides Kubernetes Secrets.
+		SecretStore *SecretStoreConfig `yaml:"secretStore,omitempty" jsonschema:"omitempty"`
+
+		// IngressACME obtains certificates of ingress hosts by ACME, nil means disabled.
+		IngressACME *IngressACMEConfig `yaml:"ingressACME,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressACMEConfig is the config of the ACME client of ingress controllers.
+	IngressACMEConfig struct {
+		Email        string `yaml:"email" jsonschema:"required"`
+		DirectoryURL string `yaml:"directoryURL" jsonschema:"required"`
+		// DNSProvider solves dns-01 challenges, nil means only http-01 challenges.
+		DNSProvider *ACMEDNSProviderConfig `yaml:"dnsProvider,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ACMEDNSProviderConfig is the DNS provider plugin, its options like API
+	// tokens are read from the Secret in the mesh namespace.
+	ACMEDNSProviderConfig struct {
+		Name       string `yaml:"name" jsonschema:"required"`
+		SecretName string `yaml:"secretName" jsonschema:"required"`
+	}
+
+	// SecretStoreConfig is the config of stores resolving secret references.
+	SecretStoreConfig struct {
+		Vault *VaultConfig `yaml:"vault,omitempty" jsonschema:"omitempty"`
+	}
+
+	// VaultConfig is the config of Vault, the control plane logins with
+	// the Kubernetes auth method by its service account.
+	VaultConfig struct {
+		Address  string `yaml:"address" jsonschema:"required"`
+		AuthPath string `yaml:"authPath" jsonschema:"required"`
+		Role     string `yaml:"role" jsonschema:"required"`
+	}
+
+	// ZookeeperServiceRegistryConfig is the config of the ZooKeeper registry of Easegress.
+	ZookeeperServiceRegistryConfig struct {
+		Name         string   `yaml:"name" jsonschema:"required"`
+		Kind         string   `yaml:"kind" jsonschema:"required"`
+		ZKServices   []string `yaml:"zkservices" jsonschema:"required"`
+		Prefix       string   `yaml:"prefix" jsonschema:"required"`
+		ConnTimeout  string   `yaml:"conntimeout" jsonschema:"required"`
+		SyncInterval string   `yaml:"syncInterval" jsonschema:"required"`
 	}
 
 	// MeshOperatorConfig is the config of EaseMesh operator.
@@ -94,6 +150,7 @@ type (
 		ImageRegistryURL     string   `yaml:"image-registry-url" jsonschema:"required"`
 		ClusterName          string   `yaml:"cluster-name" jsonschema:"required"`
 		ClusterJoinURLs      []string `yaml:"cluster-join-urls" jsonschema:"required"`
+		APIAddr              string   `yaml:"api-addr" jsonschema:"omitempty"`
 		MetricsAddr          string   `yaml:"metrics-bind-address" jsonschema:"required"`
 		EnableLeaderElection bool     `yaml:"leader-elect" jsonschema:"required"`
 		ProbeAddr            string   `yaml:"health-probe-bind-address" jsonschema:"required"`
@@ -108,6 +165,51 @@ type (
 		AgentInitializerImageName string `yaml:"agent-initializer-image-name" jsonschema:"required"`
 		// Log4jConfigName default is easeagent-log4j.xml
 		Log4jConfigName string `yaml:"log4j-config-name" jsonschema:"required"`
+
+		// EnableGatewayAPI enables the Gateway API ingress source of the operator
+		EnableGatewayAPI bool `yaml:"enable-gateway-api" jsonschema:"omitempty"`
+		// EnableK8sIngress enables the Kubernetes Ingress source of the operator
+		EnableK8sIngress bool `yaml:"enable-k8s-ingress" jsonschema:"omitempty"`
+		// EnableMeshControlPlane makes the operator apply the MeshControlPlane to components
+		EnableMeshControlPlane bool `yaml:"enable-mesh-control-plane" jsonschema:"omitempty"`
+		// EnableSelfHeal makes the operator revert manual edits and deletions of objects in the install snapshot
+		EnableSelfHeal bool `yaml:"enable-self-heal" jsonschema:"omitempty"`
+		// EnableServiceAutoRegistration makes the operator register Deployments labeled with mesh.megaease.com/enable=true as mesh services
+		EnableServiceAutoRegistration bool `yaml:"enable-service-auto-registration" jsonschema:"omitempty"`
+		// EnableTrafficPolicyAnnotations makes the operator convert traffic policy annotations of Deployments into mesh resources
+		EnableTrafficPolicyAnnotations bool `yaml:"enable-traffic-policy-annotations" jsonschema:"omitempty"`
+		// EnableAPIAggregation makes the operator serve mesh resources as an aggregated API on APIAggregationPort
+		EnableAPIAggregation bool   `yaml:"enable-api-aggregation" jsonschema:"omitempty"`
+		APIAggregationPort   uint16 `yaml:"api-aggregation-port" jsonschema:"omitempty"`
+
+		// SidecarDNSCapture makes injected sidecars serve DNS of their pods
+		SidecarDNSCapture bool `yaml:"sidecar-dns-capture" jsonschema:"omitempty"`
+		// SidecarDNSUpstream is the nameserver sidecars forward unknown names to
+		SidecarDNSUpstream string `yaml:"sidecar-dns-upstream" jsonschema:"omitempty"`
+		// ClusterDomain is the DNS domain of the Kubernetes cluster
+		ClusterDomain string `yaml:"cluster-domain" jsonschema:"omitempty"`
+
+		// SpringCloudConfigURI is the Spring Cloud Config server injected applications fetch config from
+		SpringCloudConfigURI string `yaml:"spring-cloud-config-uri" jsonschema:"omitempty"`
+
+		// EnablePprof serves pprof endpoints on the metrics address
+		EnablePprof bool `yaml:"enable-pprof" jsonschema:"omitempty"`
+
+		// SidecarInjectionTemplate is the file of patches applied to injected pods
+		SidecarInjectionTemplate string `yaml:"sidecar-injection-template" jsonschema:"omitempty"`
+		// SidecarDrainDuration is how long applications keep running on termination, sidecars outlive them
+		SidecarDrainDuration string `yaml:"sidecar-drain-duration" jsonschema:"omitempty"`
+		// HoldApplicationUntilSidecarReady makes applications start after sidecars are ready
+		HoldApplicationUntilSidecarReady bool `yaml:"hold-application-until-sidecar-ready" jsonschema:"omitempty"`
+		// NativeSidecar injects sidecars as native sidecars of Kubernetes 1.28+
+		NativeSidecar bool `yaml:"native-sidecar" jsonschema:"omitempty"`
+
+		// TrafficInterception intercepts outbound traffic of injected pods, empty leaves it to EaseAgent and DNS
+		TrafficInterception string `yaml:"traffic-interception" jsonschema:"omitempty"`
+		// InterceptionImageName is the image of init containers setting up iptables rules
+		InterceptionImageName string `yaml:"interception-image-name" jsonschema:"omitempty"`
+		// InterceptionPorts are destination ports of outbound traffic redirected to sidecars
+		InterceptionPorts []int `yaml:"interception-ports" jsonschema:"omitempty"`
 	}
 
 	// EasegressReaderParams is the parameters of Easegress reader role.
@@ -127,7 +229,18 @@ type (
 		Flags               *flags.Install
 		CoreDNSFlags        *flags.CoreDNS
 		APIExtensionsClient apiextensions.Interface
+		DynamicClient       dynamic.Interface
 		ClearFuncs          []func(*StageContext) error
+		// Snapshot keeps objects applied by stages for self-healing, nil means disabled.
+		Snapshot *InstallSnapshot
+		// Progress reports transitions of stages, nil means no report.
+		Progress *ProgressReporter
+		// Context is cancelled once the installation is interrupted, stages derive their contexts from it.
+		Context context.Context
+		// Binding binds requests of Client and APIExtensionsClient to the context of the running stage.
+		Binding *ContextBinding
+
+		stage context.Context
 	}
 
 	// InstallFunc is the type of function for installation.
@@ -204,3 +317,68 @@ func ControlPlanePeerURLsStr(ctx *StageContext) string {
 	peerURLs := ControlPlanePeerURLs(ctx)
 	return strings.Join(peerURLs, ",")
 }
+
+// ControlPlaneContainerPorts returns the container ports of Easegress,
+// which listens on the ports of the control plane flags.
+func ControlPlaneContainerPorts(ctx *StageContext) []v1.ContainerPort {
+	return []v1.ContainerPort{
+		{
+			Name:          ControlPlaneStatefulSetAdminPortName,
+			ContainerPort: int32(ctx.Flags.EgAdminPort),
+		},
+		{
+			Name:          ControlPlaneStatefulSetClientPortName,
+			ContainerPort: int32(ctx.Flags.EgClientPort),
+		},
+		{
+			Name:          ControlPlaneStatefulSetPeerPortName,
+			ContainerPort: int32(ctx.Flags.EgPeerPort),
+		},
+	}
+}
+
+// portFlag is a port with the flag specifying it.
+type portFlag struct {
+	flag string
+	port int
+}
+
+// ValidateControlPlanePorts checks the ports of the control plane are valid
+// and don't conflict, the ingress and the egress gateway listen on their
+// service ports in the same pods with Easegress, so they're checked too.
+// Ports of the control plane service are checked apart, since they're
+// translated to the ports of the containers.
+func ValidateControlPlanePorts(installFlags *flags.Install) error {
+	err := validateDistinctPorts([]portFlag{
+		{"--mesh-control-plane-admin-port", installFlags.EgAdminPort},
+		{"--mesh-control-plane-client-port", installFlags.EgClientPort},
+		{"--mesh-control-plane-peer-port", installFlags.EgPeerPort},
+		{"--mesh-ingress-service-port", int(installFlags.MeshIngressServicePort)},
+		{"--mesh-egress-service-port", int(installFlags.MeshEgressServicePort)},
+	})
+	if err != nil {
+		return err
+	}
+
+	// NOTE: The service of the control plane serves the client port as it is.
+	return validateDistinctPorts([]portFlag{
+		{"--mesh-control-plane-service-admin-port", installFlags.EgServiceAdminPort},
+		{"--mesh-control-plane-service-peer-port", installFlags.EgServicePeerPort},
+		{"--mesh-control-plane-client-port", installFlags.EgClientPort},
+	})
+}
+
+func validateDistinctPorts(ports []portFlag) error {
+	flagOfPort := map[int]string{}
+	for _, p := range ports {
+		if p.port <= 0 || p.port > 65535 {
+			return errors.Errorf("%s must be in 1-65535, got %d", p.flag, p.port)
+		}
+		if flag, exists := flagOfPort[p.port]; exists {
+			return errors.Errorf("%s conflicts with %s on port %d", p.flag, flag, p.port)
+		}
+		flagOfPort[p.port] = p.flag
+	}
+
+	return nil
+}
@@ -51,6 +51,10 @@ const (
 	// ControlPlaneCmd is the essential command of control plane.
 	ControlPlaneCmd = "/opt/easegress/bin/easegress-server -f /opt/easegress/config/control-plane.yaml"
 
+	// ControlPlaneSecretReaderRoleName is the name of roles letting the control plane read
+	// Kubernetes Secrets referenced by mesh resources, and their bindings.
+	ControlPlaneSecretReaderRoleName = "easemesh-control-plane-secret-reader"
+
 	// --- Control plane StatefuleSet related.
 
 	// ControlPlaneStatefulSetName is the name of control plane statefulset.
@@ -82,6 +86,10 @@ const (
 	MeshControllerName = "easemesh-controller"
 	// MeshControllerAPIPort is the API port of sidecar for handling local Eureka/Conslu/Nacos APIs.
 	MeshControllerAPIPort = 13009
+	// ZookeeperServiceRegistryName is the name of the ZooKeeper registry synced by the MeshController.
+	ZookeeperServiceRegistryName = "easemesh-zookeeper-registry"
+	// ZookeeperServiceRegistryKind is the kind of the ZooKeeper registry in Easegress.
+	ZookeeperServiceRegistryKind = "ZookeeperServiceRegistry"
 
 	// --- Operator Deployment related.
 
@@ -93,6 +101,13 @@ const (
 	OperatorConfigMapVolumeMountPath = "/opt/operator/operator.yaml"
 	// OperatorConfigMapVolumeMountSubPath is the subpath of volume mouth of config map in control plane config map.
 	OperatorConfigMapVolumeMountSubPath = "operator.yaml"
+	// SidecarInjectionTemplateConfigMapName is the name of config map of patches applied to injected pods.
+	SidecarInjectionTemplateConfigMapName = "easemesh-sidecar-injection-template"
+	// SidecarInjectionTemplateConfigMapKey is the key of the template in the config map.
+	SidecarInjectionTemplateConfigMapKey = "template.yaml"
+	// SidecarInjectionTemplateVolumeMountPath is the directory of the template in the operator, it's
+	// mounted without subpath, so changes of the config map are seen by the operator.
+	SidecarInjectionTemplateVolumeMountPath = "/opt/operator/injection-template"
 	// OperatorSecretName is the name of secret of operator deployment.
 	OperatorSecretName = "easemesh-operator-secret"
 	// OperatorSecretVolumeMountPath is the secret directory of adminssion control of operator deployment.
@@ -108,6 +123,12 @@ const (
 
 	// OperatorDeploymentName is the name of operator deployment.
 	OperatorDeploymentName = "easemesh-operator"
+	// MeshDeploymentCRDName is the name of the CustomResourceDefinition of MeshDeployment.
+	MeshDeploymentCRDName = "meshdeployments.mesh.megaease.com"
+	// MeshControlPlaneCRDName is the name of the CustomResourceDefinition of MeshControlPlane.
+	MeshControlPlaneCRDName = "meshcontrolplanes.mesh.megaease.com"
+	// MeshControlPlaneName is the name of the MeshControlPlane describing the installation.
+	MeshControlPlaneName = "easemesh"
 	// OperatorServiceName is the name of service of operator deployment.
 	OperatorServiceName = "easemesh-operator-service"
 	// OperatorCSRName is the name of CertificateSigningRequest of operator deployment.
@@ -116,10 +137,26 @@ const (
 	OperatorMutatingWebhookName = "easemesh-operator-mutating-webhook"
 	// OperatorMutatingWebhookPath is the path of admission control of operator deployment.
 	OperatorMutatingWebhookPath = "/mutate"
+	// OperatorConversionWebhookPath is the path of the conversion webhook of MeshDeployments served by the operator.
+	OperatorConversionWebhookPath = "/convert"
 	// OperatorMutatingWebhookPortName is the name of mutating webhook port of admission control of operator deployment.
 	OperatorMutatingWebhookPortName = "mutate-port"
 	// OperatorMutatingWebhookPort is the port of adminssion control of operator deployment.
 	OperatorMutatingWebhookPort = 9090
+	// OperatorMetricsPortName is the name of metrics port of operator deployment.
+	OperatorMetricsPortName = "metrics"
+	// OperatorMetricsPort is the port of metrics of operator deployment.
+	OperatorMetricsPort = 8080
+	// OperatorRBACProxyPort is the port of kube-rbac-proxy serving metrics of operator deployment.
+	OperatorRBACProxyPort = 8443
+	// OperatorProbePort is the port of health probes of operator deployment.
+	OperatorProbePort = 8081
+	// OperatorAPIAggregationPortName is the name of the aggregated API port of operator deployment.
+	OperatorAPIAggregationPortName = "aggregated-api"
+	// OperatorAPIAggregationPort is the port of the aggregated API of operator deployment.
+	OperatorAPIAggregationPort = 9443
+	// OperatorAPIServiceName is the name of the APIService of mesh resources served by the operator.
+	OperatorAPIServiceName = "v1alpha1.admin.mesh.megaease.com"
 
 	// --- Operator injection related.
 
@@ -149,11 +186,83 @@ const (
 	// IngressControllerHomeDir is home directory of control plane.
 	IngressControllerHomeDir = "/opt/easegress"
 
+	// --- Egress Gateway related.
+
+	// EgressGatewayDeploymentName is the name of deployment of egress gateway.
+	EgressGatewayDeploymentName = "easemesh-egress-gateway"
+	// EgressGatewayDeploymentCmd is the essetial command of deployment of egress gateway.
+	EgressGatewayDeploymentCmd = "/opt/easegress/bin/easegress-server -f /opt/easegress/config/egress-gateway.yaml"
+	// EgressGatewayConfigMapName is the name of config map of egress gateway.
+	EgressGatewayConfigMapName = "easemesh-egress-gateway-config"
+	// EgressGatewayServiceName is the name of service of egress gateway.
+	EgressGatewayServiceName = "easemesh-egress-gateway-service"
+	// EgressGatewayConfigMapVolumeMountPath is the path of volume mouth of config map of egress gateway.
+	EgressGatewayConfigMapVolumeMountPath = "/opt/easegress/config/egress-gateway.yaml"
+	// EgressGatewayConfigMapVolumeMountSubPath is the subpath of volume mouth of config map of egress gateway.
+	EgressGatewayConfigMapVolumeMountSubPath = "control-plane.yaml"
+
 	// --- Shadow Service related.
 
 	// IngressControllerShadowServiceName is the name of shadow service of ingress controller.
 	IngressControllerShadowServiceName = "easemesh-ingress-controller-shadowservice"
 
+	// --- GitOps controller related.
+
+	// GitOpsControllerDeploymentName is the name of deployment of GitOps controller.
+	GitOpsControllerDeploymentName = "easemesh-gitops-controller"
+	// GitOpsControllerServiceName is the name of service of GitOps controller for webhooks.
+	GitOpsControllerServiceName = "easemesh-gitops-controller-service"
+	// GitOpsControllerSecretName is the name of secret holding the webhook secret of GitOps controller.
+	GitOpsControllerSecretName = "easemesh-gitops-controller-secret"
+	// GitOpsControllerSecretKey is the key of the webhook secret in the secret of GitOps controller.
+	GitOpsControllerSecretKey = "webhook-secret"
+
+	// --- Image pinning related.
+
+	// ImageDigestsConfigMapName is the name of config map recording digests of the installed images.
+	ImageDigestsConfigMapName = "easemesh-image-digests"
+	// ImageDigestsConfigMapKey is the key of the digests in the config map.
+	ImageDigestsConfigMapKey = "images.yaml"
+
+	// --- Network policy related.
+
+	// ControlPlaneNetworkPolicyName is the name of network policy of control plane.
+	ControlPlaneNetworkPolicyName = "easemesh-control-plane"
+	// OperatorNetworkPolicyName is the name of network policy of operator deployment.
+	OperatorNetworkPolicyName = "easemesh-operator"
+	// SidecarInjectedLabelKey is the label the operator puts on pods injected with sidecars.
+	SidecarInjectedLabelKey = "mesh.megaease.com/sidecar-injected"
+
+	// --- Control plane maintenance related.
+
+	// MaintenanceCronJobName is the name of cronjob compacting and defragmenting the control plane storage.
+	MaintenanceCronJobName = "easemesh-control-plane-maintenance"
+
+	// --- Traffic interception related.
+
+	// TrafficInterceptionIptables intercepts outbound traffic of injected pods by iptables rules of init containers.
+	TrafficInterceptionIptables = "iptables"
+	// SidecarIngressPort is the port of sidecars receiving inbound traffic.
+	SidecarIngressPort = 13001
+	// SidecarEgressPort is the port of sidecars receiving outbound traffic of applications.
+	SidecarEgressPort = 13002
+
+	// --- Dashboards related.
+
+	// DashboardsConfigMapName is the name of config map of Grafana dashboards.
+	DashboardsConfigMapName = "easemesh-grafana-dashboards"
+	// DashboardsLabelKey is the label key of config maps loaded by the dashboard sidecar of Grafana.
+	DashboardsLabelKey = "grafana_dashboard"
+
+	// --- Ingress source related.
+
+	// GatewayClassName is the GatewayClass name whose Gateways are served by the mesh ingress controller.
+	GatewayClassName = "easemesh"
+	// IngressClassName is the IngressClass name of Kubernetes Ingresses served by the mesh ingress controller.
+	IngressClassName = "easemesh"
+	// IngressClassController is the controller name of the EaseMesh IngressClass.
+	IngressClassController = "megaease.com/easemesh-ingress-controller"
+
 	// --- Kubernetes related.
 
 	// DefaultKubeDir is the directory of Kubernetes config.
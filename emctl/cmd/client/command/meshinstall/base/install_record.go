@@ -0,0 +1,290 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const installRecordFileName = ".emctl-install-record.yaml"
+
+type (
+	// RecordedObject identifies a Kubernetes object created by an installation.
+	RecordedObject struct {
+		Group     string `yaml:"group,omitempty"`
+		Version   string `yaml:"version"`
+		Resource  string `yaml:"resource"`
+		Namespace string `yaml:"namespace,omitempty"`
+		Name      string `yaml:"name"`
+	}
+
+	// InstallRecord tracks the objects created by an installation run,
+	// so that they can be deleted if the installation fails.
+	InstallRecord struct {
+		StartedAt time.Time        `yaml:"startedAt"`
+		Objects   []RecordedObject `yaml:"objects"`
+
+		mutex    sync.Mutex
+		path     string
+		progress *ProgressReporter
+	}
+
+	recordingRoundTripper struct {
+		record   *InstallRecord
+		delegate http.RoundTripper
+	}
+)
+
+func (o RecordedObject) String() string {
+	resource := o.Resource
+	if o.Group != "" {
+		resource += "." + o.Group
+	}
+	if o.Namespace != "" {
+		return resource + " " + o.Namespace + "/" + o.Name
+	}
+	return resource + " " + o.Name
+}
+
+func installRecordPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "get user home dir failed")
+	}
+	return path.Join(homeDir, installRecordFileName), nil
+}
+
+// NewInstallRecord creates an empty record for a new installation run.
+func NewInstallRecord() (*InstallRecord, error) {
+	path, err := installRecordPath()
+	if err != nil {
+		return nil, err
+	}
+	return newInstallRecord(path), nil
+}
+
+func newInstallRecord(path string) *InstallRecord {
+	return &InstallRecord{StartedAt: time.Now(), path: path}
+}
+
+// LoadInstallRecord loads the record left by a failed installation run.
+func LoadInstallRecord() (*InstallRecord, error) {
+	path, err := installRecordPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadInstallRecord(path)
+}
+
+func loadInstallRecord(path string) (*InstallRecord, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.CodeErrorf(common.ExitCodeNotFound, "no failed installation recorded in %s", path)
+		}
+		return nil, errors.Wrapf(err, "read file %s failed", path)
+	}
+
+	record := &InstallRecord{path: path}
+	err = yaml.Unmarshal(buff, record)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal file %s failed", path)
+	}
+	return record, nil
+}
+
+// Path returns the path of the record file.
+func (r *InstallRecord) Path() string {
+	return r.path
+}
+
+// ReportTo makes the record report the created objects to the progress.
+func (r *InstallRecord) ReportTo(progress *ProgressReporter) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.progress = progress
+}
+
+// Record appends a created object and saves the record, so it survives
+// an interrupted installation.
+func (r *InstallRecord) Record(object RecordedObject) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Objects = append(r.Objects, object)
+	common.Debugf("created %s", object)
+	r.progress.ObjectApplied(object)
+
+	err := r.save()
+	if err != nil {
+		common.Warnf("save install record failed: %v", err)
+	}
+}
+
+func (r *InstallRecord) save() error {
+	buff, err := yaml.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "marshal install record to yaml failed")
+	}
+
+	err = ioutil.WriteFile(r.path, buff, 0o644)
+	if err != nil {
+		return errors.Wrapf(err, "write file %s failed", r.path)
+	}
+	return nil
+}
+
+// Remove deletes the record file.
+func (r *InstallRecord) Remove() error {
+	err := os.Remove(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "remove file %s failed", r.path)
+	}
+	return nil
+}
+
+// Rollback deletes the recorded objects in reverse order of creation.
+// Objects which can't be deleted are kept in the record for another try.
+func (r *InstallRecord) Rollback(client dynamic.Interface) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	propagation := metav1.DeletePropagationBackground
+	var failed []RecordedObject
+	for i := len(r.Objects) - 1; i >= 0; i-- {
+		object := r.Objects[i]
+		gvr := schema.GroupVersionResource{Group: object.Group, Version: object.Version, Resource: object.Resource}
+
+		var err error
+		if object.Namespace != "" {
+			err = client.Resource(gvr).Namespace(object.Namespace).Delete(context.Background(),
+				object.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		} else {
+			err = client.Resource(gvr).Delete(context.Background(),
+				object.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		}
+
+		if err != nil && !apierrors.IsNotFound(err) {
+			common.OutputErrorf("delete %s failed: %v", object, err)
+			failed = append([]RecordedObject{object}, failed...)
+			continue
+		}
+		common.Infof("Deleted %s", object)
+	}
+
+	r.Objects = failed
+	if len(failed) != 0 {
+		err := r.save()
+		if err != nil {
+			common.Warnf("save install record failed: %v", err)
+		}
+		return errors.Errorf("%d objects are not deleted, they are kept in %s", len(failed), r.path)
+	}
+
+	return r.Remove()
+}
+
+// WrapTransport makes the record track the objects created through the transport.
+// It fits rest.Config.WrapTransport.
+func (r *InstallRecord) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &recordingRoundTripper{record: r, delegate: rt}
+}
+
+func (t *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.delegate.RoundTrip(req)
+	if err != nil || req.Method != http.MethodPost || resp.StatusCode != http.StatusCreated {
+		return resp, err
+	}
+
+	object, ok := parseCollectionPath(req.URL.Path)
+	if !ok {
+		return resp, err
+	}
+
+	body, readErr := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		return resp, readErr
+	}
+
+	created := struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}{}
+	if json.Unmarshal(body, &created) != nil || created.Metadata.Name == "" {
+		common.Debugf("can't track object created by %s", req.URL.Path)
+		return resp, err
+	}
+
+	object.Name = created.Metadata.Name
+	t.record.Record(object)
+	return resp, err
+}
+
+// parseCollectionPath parses the path of a create request, such as
+// /api/v1/namespaces/{namespace}/{resource} or /apis/{group}/{version}/{resource}.
+// Requests to subresources are not creations of objects, so they are ignored.
+func parseCollectionPath(urlPath string) (RecordedObject, bool) {
+	object := RecordedObject{}
+	parts := strings.Split(strings.Trim(urlPath, "/"), "/")
+
+	var rest []string
+	for i, part := range parts {
+		if part == "api" && len(parts) > i+1 {
+			object.Version = parts[i+1]
+			rest = parts[i+2:]
+			break
+		}
+		if part == "apis" && len(parts) > i+2 {
+			object.Group, object.Version = parts[i+1], parts[i+2]
+			rest = parts[i+3:]
+			break
+		}
+	}
+
+	switch {
+	case len(rest) == 1:
+		object.Resource = rest[0]
+	case len(rest) == 3 && rest[0] == "namespaces":
+		object.Namespace, object.Resource = rest[1], rest[2]
+	default:
+		return object, false
+	}
+
+	return object, true
+}
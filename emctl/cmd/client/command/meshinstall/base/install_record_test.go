@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestParseCollectionPath(t *testing.T) {
+	cases := []struct {
+		path   string
+		ok     bool
+		object RecordedObject
+	}{
+		{"/api/v1/namespaces", true, RecordedObject{Version: "v1", Resource: "namespaces"}},
+		{"/api/v1/namespaces/easemesh/services", true, RecordedObject{Version: "v1", Namespace: "easemesh", Resource: "services"}},
+		{"/apis/apps/v1/namespaces/easemesh/deployments", true, RecordedObject{Group: "apps", Version: "v1", Namespace: "easemesh", Resource: "deployments"}},
+		{"/apis/rbac.authorization.k8s.io/v1/clusterroles", true, RecordedObject{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}},
+		{"/k8s/clusters/c-1/api/v1/namespaces/easemesh/secrets", true, RecordedObject{Version: "v1", Namespace: "easemesh", Resource: "secrets"}},
+		{"/api/v1/namespaces/easemesh/serviceaccounts/default/token", false, RecordedObject{}},
+		{"/healthz", false, RecordedObject{}},
+	}
+
+	for _, c := range cases {
+		object, ok := parseCollectionPath(c.path)
+		if ok != c.ok {
+			t.Fatalf("parse %s: expected %v, got %v", c.path, c.ok, ok)
+		}
+		if ok && object != c.object {
+			t.Fatalf("parse %s: expected %+v, got %+v", c.path, c.object, object)
+		}
+	}
+}
+
+func TestRecordingRoundTripper(t *testing.T) {
+	record := newInstallRecord(path.Join(t.TempDir(), installRecordFileName))
+	body := `{"metadata":{"name":"easemesh-operator"}}`
+	rt := record.WrapTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status := http.StatusOK
+		if req.Method == http.MethodPost {
+			status = http.StatusCreated
+		}
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	}))
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		req, _ := http.NewRequest(method, "https://127.0.0.1/apis/apps/v1/namespaces/easemesh/deployments", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("round trip failed: %v", err)
+		}
+		buff, _ := ioutil.ReadAll(resp.Body)
+		if string(buff) != body {
+			t.Fatalf("response body should be kept, got %s", buff)
+		}
+	}
+
+	if len(record.Objects) != 1 || record.Objects[0].Name != "easemesh-operator" {
+		t.Fatalf("expected only the created deployment recorded, got %+v", record.Objects)
+	}
+
+	loaded, err := loadInstallRecord(record.Path())
+	if err != nil {
+		t.Fatalf("load install record failed: %v", err)
+	}
+	if len(loaded.Objects) != 1 || loaded.Objects[0] != record.Objects[0] {
+		t.Fatalf("expected saved record equals to %+v, got %+v", record.Objects, loaded.Objects)
+	}
+}
+
+func TestInstallRecordRollback(t *testing.T) {
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetNamespace("easemesh")
+	deployment.SetName("easemesh-operator")
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), deployment)
+
+	record := newInstallRecord(path.Join(t.TempDir(), installRecordFileName))
+	record.Record(RecordedObject{Version: "v1", Resource: "namespaces", Name: "easemesh"})
+	record.Record(RecordedObject{Group: "apps", Version: "v1", Resource: "deployments", Namespace: "easemesh", Name: "easemesh-operator"})
+
+	err := record.Rollback(client)
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	_, err = client.Resource(gvr).Namespace("easemesh").Get(requestContext(), "easemesh-operator", metav1.GetOptions{})
+	if err == nil {
+		t.Fatalf("deployment should be deleted")
+	}
+
+	if _, err := os.Stat(record.Path()); !os.IsNotExist(err) {
+		t.Fatalf("install record should be removed after rollback")
+	}
+}
+
+func TestLoadInstallRecordNotFound(t *testing.T) {
+	_, err := loadInstallRecord(path.Join(t.TempDir(), installRecordFileName))
+	if common.ExitCode(err) != common.ExitCodeNotFound {
+		t.Fatalf("expected not found exit code, got %d", common.ExitCode(err))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -479,3 +480,44 @@ spec:
       secretName: easestack-ingress-controller-token-6nng4
 `
 )
+
+func TestDeployResourceRetry(t *testing.T) {
+	origin := RetryBackoff
+	RetryBackoff.Duration = time.Millisecond
+	defer func() { RetryBackoff = origin }()
+
+	calls := 0
+	err := deployResource(func() error {
+		calls++
+		if calls < 3 {
+			return k8serr.NewServiceUnavailable("overloaded")
+		}
+		return nil
+	}, nil)
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got %d calls: %v", calls, err)
+	}
+
+	calls = 0
+	err = deployResource(func() error {
+		calls++
+		return k8serr.NewBadRequest("invalid")
+	}, nil)
+	if err == nil || calls != 1 {
+		t.Fatalf("expected non-transient error without retries, got %d calls: %v", calls, err)
+	}
+}
+
+func TestPodContainerPort(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Ports: []v1.ContainerPort{{Name: ControlPlaneStatefulSetAdminPortName, ContainerPort: 12381}}}},
+		},
+	}
+	if port := PodContainerPort(pod, ControlPlaneStatefulSetAdminPortName, 2381); port != 12381 {
+		t.Fatalf("expected port 12381, got %d", port)
+	}
+	if port := PodContainerPort(pod, ControlPlaneStatefulSetClientPortName, 2379); port != 2379 {
+		t.Fatalf("expected default port 2379, got %d", port)
+	}
+}
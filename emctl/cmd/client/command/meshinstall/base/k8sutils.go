@@ -22,12 +22,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/megaease/easemeshctl/cmd/common"
 
+	"gopkg.in/yaml.v2"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	appsV1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -37,8 +41,14 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
+	"k8s.io/client-go/util/retry"
 )
 
 var (
@@ -49,6 +59,9 @@ var (
 	encoder = unstructured.NewJSONFallbackEncoder(codecs.LegacyCodec(scheme.PrioritizedVersionsAllGroups()...))
 
 	metadataAccessor = meta.NewAccessor()
+
+	// RetryBackoff is the backoff of retrying transient errors in deploying resources.
+	RetryBackoff = wait.Backoff{Steps: 5, Duration: 500 * time.Millisecond, Factor: 2, Jitter: 0.1}
 )
 
 type (
@@ -74,11 +87,28 @@ type (
 	ListPodFunc func(kubernetes.Interface, string) []PodStatus
 )
 
+// kubeconfigPath and kubeContext are set by the global flags --kubeconfig
+// and --context, empty ones follow KUBECONFIG and its current context like kubectl.
+var kubeconfigPath, kubeContext string
+
+// SetKubernetesConfig sets the kubeconfig file and its context to access Kubernetes.
+func SetKubernetesConfig(kubeconfig, context string) {
+	kubeconfigPath, kubeContext = kubeconfig, context
+}
+
+// KubernetesConfig loads the config to access Kubernetes from
+// the kubeconfig, or from the in-cluster config.
+func KubernetesConfig() (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigPath
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext}).
+		ClientConfig()
+}
+
 // NewKubernetesClient creates Kubernetes client set.
 func NewKubernetesClient() (kubernetes.Interface, error) {
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).
-		ClientConfig()
+	config, err := KubernetesConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -92,9 +122,7 @@ func NewKubernetesClient() (kubernetes.Interface, error) {
 
 // NewKubernetesAPIExtensionsClient creates Kubernetes API extensions client.
 func NewKubernetesAPIExtensionsClient() (apiextensions.Interface, error) {
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).
-		ClientConfig()
+	config, err := KubernetesConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +134,69 @@ func NewKubernetesAPIExtensionsClient() (apiextensions.Interface, error) {
 	return clientset, nil
 }
 
+// NewKubernetesDynamicClient creates Kubernetes dynamic client.
+func NewKubernetesDynamicClient() (dynamic.Interface, error) {
+	config, err := KubernetesConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewRecordedKubernetesClients creates Kubernetes client set and API extensions client,
+// the objects created by them are tracked in the record. Their requests are bound to
+// contexts of stages by the binding, and time out after the timeout if it's positive.
+// The wrappers wrap their transport further, e.g. to take the snapshot.
+func NewRecordedKubernetesClients(record *InstallRecord, binding *ContextBinding,
+	timeout time.Duration, wrappers ...transport.WrapperFunc) (kubernetes.Interface, apiextensions.Interface, error) {
+	config, err := recordedKubernetesConfig(record, binding, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, wrapper := range wrappers {
+		config.Wrap(wrapper)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apiExtensionsClient, err := apiextensions.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kubeClient, apiExtensionsClient, nil
+}
+
+// NewRecordedKubernetesDynamicClient creates Kubernetes dynamic client, which
+// is recorded and bound in the same way as NewRecordedKubernetesClients.
+func NewRecordedKubernetesDynamicClient(record *InstallRecord, binding *ContextBinding,
+	timeout time.Duration) (dynamic.Interface, error) {
+	config, err := recordedKubernetesConfig(record, binding, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+func recordedKubernetesConfig(record *InstallRecord, binding *ContextBinding,
+	timeout time.Duration) (*rest.Config, error) {
+	config, err := KubernetesConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Wrap(record.WrapTransport)
+	config.Wrap(binding.WrapTransport)
+	config.Timeout = timeout
+	return config, nil
+}
+
 func requestContext() context.Context     { return context.TODO() }
 func createOptions() metav1.CreateOptions { return metav1.CreateOptions{} }
 func getOptions() metav1.GetOptions       { return metav1.GetOptions{} }
@@ -148,16 +239,31 @@ func adaptReplaceObject(old, new runtime.Object) error {
 }
 
 func deployResource(createFn createResourceFunc, updateFn updateResourceFunc) error {
-	err := createFn()
-	if err == nil {
-		return nil
-	}
+	return retry.OnError(RetryBackoff, isTransientError, func() error {
+		err := createFn()
+		if err == nil {
+			return nil
+		}
 
-	if !errors.IsAlreadyExists(err) {
-		return err
-	}
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
 
-	return updateFn()
+		return updateFn()
+	})
+}
+
+// isTransientError reports whether the error is likely to disappear by retrying,
+// e.g. an overloaded API server or an object modified concurrently.
+func isTransientError(err error) bool {
+	transient := errors.IsServerTimeout(err) || errors.IsTimeout(err) ||
+		errors.IsTooManyRequests(err) || errors.IsServiceUnavailable(err) ||
+		errors.IsInternalError(err) || errors.IsConflict(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+	if transient {
+		common.Debugf("retry on transient error: %v", err)
+	}
+	return transient
 }
 
 // DeployNamespace creates or updates Namespace.
@@ -304,6 +410,34 @@ func DeployConfigMap(configMap *v1.ConfigMap, clientSet kubernetes.Interface, na
 	return deployResource(createFn, updateFn)
 }
 
+// DeployCronJob creates or updates CronJob.
+func DeployCronJob(cronJob *batchv1.CronJob, clientSet kubernetes.Interface, namespace string) error {
+	createFn := func() error {
+		_, err := clientSet.BatchV1().CronJobs(namespace).
+			Create(requestContext(), cronJob, createOptions())
+		return err
+	}
+
+	updateFn := func() error {
+		oldObject, err := clientSet.BatchV1().CronJobs(namespace).
+			Get(requestContext(), cronJob.Name, getOptions())
+		if err != nil {
+			return err
+		}
+
+		err = adaptReplaceObject(oldObject, cronJob)
+		if err != nil {
+			return err
+		}
+
+		_, err = clientSet.BatchV1().CronJobs(namespace).
+			Update(requestContext(), cronJob, updateOptions())
+		return err
+	}
+
+	return deployResource(createFn, updateFn)
+}
+
 // DeploySecret creates or updates Secret.
 func DeploySecret(secret *v1.Secret, clientSet kubernetes.Interface, namespace string) error {
 	createFn := func() error {
@@ -478,6 +612,62 @@ func DeployClusterRoleBinding(clusterRoleBinding *rbacv1.ClusterRoleBinding, cli
 	return deployResource(createFn, updateFn)
 }
 
+// DeployIngressClass creates or updates IngressClass.
+func DeployIngressClass(ingressClass *networkingv1.IngressClass, clientSet kubernetes.Interface) error {
+	createFn := func() error {
+		_, err := clientSet.NetworkingV1().IngressClasses().
+			Create(requestContext(), ingressClass, createOptions())
+		return err
+	}
+
+	updateFn := func() error {
+		oldObject, err := clientSet.NetworkingV1().IngressClasses().
+			Get(requestContext(), ingressClass.Name, getOptions())
+		if err != nil {
+			return err
+		}
+
+		err = adaptReplaceObject(oldObject, ingressClass)
+		if err != nil {
+			return err
+		}
+
+		_, err = clientSet.NetworkingV1().IngressClasses().
+			Update(requestContext(), ingressClass, updateOptions())
+		return err
+	}
+
+	return deployResource(createFn, updateFn)
+}
+
+// DeployNetworkPolicy creates or updates NetworkPolicy.
+func DeployNetworkPolicy(networkPolicy *networkingv1.NetworkPolicy, clientSet kubernetes.Interface, namespace string) error {
+	createFn := func() error {
+		_, err := clientSet.NetworkingV1().NetworkPolicies(namespace).
+			Create(requestContext(), networkPolicy, createOptions())
+		return err
+	}
+
+	updateFn := func() error {
+		oldObject, err := clientSet.NetworkingV1().NetworkPolicies(namespace).
+			Get(requestContext(), networkPolicy.Name, getOptions())
+		if err != nil {
+			return err
+		}
+
+		err = adaptReplaceObject(oldObject, networkPolicy)
+		if err != nil {
+			return err
+		}
+
+		_, err = clientSet.NetworkingV1().NetworkPolicies(namespace).
+			Update(requestContext(), networkPolicy, updateOptions())
+		return err
+	}
+
+	return deployResource(createFn, updateFn)
+}
+
 // DeployCustomResourceDefinition creates or updates CustomResourceDefinition.
 func DeployCustomResourceDefinition(crd *apiextensionsv1.CustomResourceDefinition, clientSet apiextensions.Interface) error {
 	createFn := func() error {
@@ -586,6 +776,42 @@ func GetMeshControlPlaneEndpoints(client kubernetes.Interface, namespace, resour
 	return entrypoints, nil
 }
 
+// GetMeshOperatorConfig gets the config of the operator installed in the namespace.
+func GetMeshOperatorConfig(client kubernetes.Interface, namespace string) (*MeshOperatorConfig, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(requestContext(), OperatorConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	config := &MeshOperatorConfig{}
+	err = yaml.Unmarshal([]byte(configMap.Data[OperatorConfigMapKey]), config)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal config of the operator: %v", err)
+	}
+	return config, nil
+}
+
+// InjectedSidecarImage returns the sidecar image the operator injects into pods.
+func (c *MeshOperatorConfig) InjectedSidecarImage() string {
+	if c.ImageRegistryURL == "" {
+		return c.SidecarImageName
+	}
+	return c.ImageRegistryURL + "/" + c.SidecarImageName
+}
+
+// PodContainerPort returns the container port of the pod with the name,
+// the default port is returned if the pod doesn't name its ports.
+func PodContainerPort(pod *v1.Pod, portName string, defaultPort int) int {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == portName {
+				return int(port.ContainerPort)
+			}
+		}
+	}
+	return defaultPort
+}
+
 // BatchDeployResources deploy resources in batches.
 func BatchDeployResources(ctx *StageContext, installFuncs []InstallFunc) error {
 	for _, fn := range installFuncs {
@@ -606,6 +832,15 @@ func DeleteStatefulsetResource(client kubernetes.Interface, resource, namespace,
 	return nil
 }
 
+// DeleteCronJobResource deletes CronJob.
+func DeleteCronJobResource(client kubernetes.Interface, resource, namespace, name string) error {
+	err := client.BatchV1().CronJobs(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // DeleteAppsV1Resource deletes resources within group AppV1.
 func DeleteAppsV1Resource(client kubernetes.Interface, resource, namespace, name string) error {
 	err := client.AppsV1().RESTClient().Delete().Resource(resource).Namespace(namespace).Name(name).Do(context.Background()).Error()
@@ -653,6 +888,24 @@ func DeleteCertificateV1Beta1Resources(client kubernetes.Interface, resources, n
 	return nil
 }
 
+// DeleteIngressClassResource deletes IngressClass.
+func DeleteIngressClassResource(client kubernetes.Interface, resources, namespace, name string) error {
+	err := client.NetworkingV1().IngressClasses().Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteNetworkPolicyResource deletes NetworkPolicy.
+func DeleteNetworkPolicyResource(client kubernetes.Interface, resources, namespace, name string) error {
+	err := client.NetworkingV1().NetworkPolicies(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // DeleteCRDResource deletes resources within group CustomResourceDefinitions.
 func DeleteCRDResource(client apiextensions.Interface, name string) error {
 	err := client.ApiextensionsV1().CustomResourceDefinitions().Delete(context.Background(), name, metav1.DeleteOptions{})
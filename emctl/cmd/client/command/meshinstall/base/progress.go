@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// ProgressFormatText reports the progress of installation by human-readable logs.
+	ProgressFormatText = "text"
+	// ProgressFormatJSON reports the progress of installation by one JSON event per line.
+	ProgressFormatJSON = "json"
+)
+
+const (
+	// ProgressStarted is the event of a stage starting.
+	ProgressStarted = "started"
+	// ProgressObjectApplied is the event of an object created by a stage.
+	ProgressObjectApplied = "object-applied"
+	// ProgressReady is the event of a stage installed successfully.
+	ProgressReady = "ready"
+	// ProgressFailed is the event of a stage failing, with the reason.
+	ProgressFailed = "failed"
+)
+
+type (
+	// ProgressEvent is a transition of an installation stage.
+	ProgressEvent struct {
+		Time   time.Time `json:"time"`
+		Stage  string    `json:"stage"`
+		Event  string    `json:"event"`
+		Object string    `json:"object,omitempty"`
+		Reason string    `json:"reason,omitempty"`
+	}
+
+	// ProgressReporter writes progress events as newline-delimited JSON,
+	// a nil ProgressReporter reports nothing.
+	ProgressReporter struct {
+		mutex sync.Mutex
+		out   io.Writer
+		stage string
+	}
+)
+
+// NewProgressReporter creates a ProgressReporter writing events to out.
+func NewProgressReporter(out io.Writer) *ProgressReporter {
+	return &ProgressReporter{out: out}
+}
+
+// Started reports the stage starts, objects applied afterwards belong to it.
+func (p *ProgressReporter) Started(stage string) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	p.stage = stage
+	p.mutex.Unlock()
+
+	p.report(ProgressEvent{Stage: stage, Event: ProgressStarted})
+}
+
+// ObjectApplied reports the object is created by the current stage.
+func (p *ProgressReporter) ObjectApplied(object RecordedObject) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	stage := p.stage
+	p.mutex.Unlock()
+
+	p.report(ProgressEvent{Stage: stage, Event: ProgressObjectApplied, Object: object.String()})
+}
+
+// Ready reports the stage is installed successfully.
+func (p *ProgressReporter) Ready(stage string) {
+	if p == nil {
+		return
+	}
+	p.report(ProgressEvent{Stage: stage, Event: ProgressReady})
+}
+
+// Failed reports the stage fails with the reason.
+func (p *ProgressReporter) Failed(stage string, reason error) {
+	if p == nil {
+		return
+	}
+	p.report(ProgressEvent{Stage: stage, Event: ProgressFailed, Reason: reason.Error()})
+}
+
+func (p *ProgressReporter) report(event ProgressEvent) {
+	event.Time = time.Now()
+
+	// NOTE: The marshalling is impossible to fail with the plain fields.
+	buff, _ := json.Marshal(event)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	fmt.Fprintf(p.out, "%s\n", buff)
+}
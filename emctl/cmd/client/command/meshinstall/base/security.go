@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SecurityProfileRestricted hardens pods of the installed components to pass
+	// the "restricted" level of Pod Security Admission.
+	SecurityProfileRestricted = "restricted"
+
+	// RestrictedUserID is the non-root user running components under the restricted security profile.
+	RestrictedUserID int64 = 65532
+	// RestrictedEasegressHomeDir is the writable home directory of Easegress under the restricted security profile,
+	// which is out of the read-only root filesystem.
+	RestrictedEasegressHomeDir = "/opt/easegress/home"
+	// TmpDir is the temporary directory of components, which is writable under the restricted security profile.
+	TmpDir = "/tmp"
+)
+
+// ValidateSecurityProfile checks the security profile is supported and the
+// components are able to run under it.
+func ValidateSecurityProfile(installFlags *flags.Install) error {
+	switch installFlags.SecurityProfile {
+	case "":
+		return nil
+	case SecurityProfileRestricted:
+	default:
+		return errors.Errorf("unsupported security profile %q (support %s)", installFlags.SecurityProfile, SecurityProfileRestricted)
+	}
+
+	// NOTE: Non-root users without capabilities can't bind privileged ports.
+	for _, p := range []struct {
+		flag string
+		port int32
+	}{
+		{"--mesh-ingress-service-port", installFlags.MeshIngressServicePort},
+		{"--mesh-egress-service-port", installFlags.MeshEgressServicePort},
+	} {
+		if p.port < 1024 {
+			return errors.Errorf("%s %d is privileged, which can't be bound under the %s security profile",
+				p.flag, p.port, SecurityProfileRestricted)
+		}
+	}
+	return nil
+}
+
+func isRestricted(installFlags *flags.Install) bool {
+	return installFlags.SecurityProfile == SecurityProfileRestricted
+}
+
+// EasegressHomeDir returns the home directory of Easegress of the control plane,
+// the ingress and the egress gateway.
+func EasegressHomeDir(installFlags *flags.Install) string {
+	if isRestricted(installFlags) {
+		return RestrictedEasegressHomeDir
+	}
+	return ControlPlaneHomeDir
+}
+
+// ContainerSecurityContext returns the security context of containers under
+// the security profile, nil means the default of the cluster.
+func ContainerSecurityContext(installFlags *flags.Install) *v1.SecurityContext {
+	if !isRestricted(installFlags) {
+		return nil
+	}
+
+	allowPrivilegeEscalation, runAsNonRoot, readOnlyRootFilesystem := false, true, true
+	return &v1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		RunAsNonRoot:             &runAsNonRoot,
+		ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+		},
+		SeccompProfile: &v1.SeccompProfile{
+			Type: v1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// ApplySecurityProfile hardens the pod under the security profile. Containers
+// without security contexts get the one of the profile, and writable dirs are
+// mounted from emptyDir volumes into all containers, since the root filesystem
+// is read-only.
+func ApplySecurityProfile(installFlags *flags.Install, spec *v1.PodSpec, writableDirs ...string) {
+	if !isRestricted(installFlags) {
+		return
+	}
+
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &v1.PodSecurityContext{}
+	}
+	runAsNonRoot, userID := true, RestrictedUserID
+	spec.SecurityContext.RunAsNonRoot = &runAsNonRoot
+	if spec.SecurityContext.RunAsUser == nil {
+		spec.SecurityContext.RunAsUser = &userID
+	}
+	if spec.SecurityContext.RunAsGroup == nil {
+		spec.SecurityContext.RunAsGroup = &userID
+	}
+	// NOTE: Volumes like PVCs of the control plane are owned by the group, so they're writable.
+	if spec.SecurityContext.FSGroup == nil {
+		spec.SecurityContext.FSGroup = &userID
+	}
+	spec.SecurityContext.SeccompProfile = &v1.SeccompProfile{
+		Type: v1.SeccompProfileTypeRuntimeDefault,
+	}
+
+	for _, dir := range writableDirs {
+		name := "writable-" + strings.ReplaceAll(strings.Trim(dir, "/"), "/", "-")
+		spec.Volumes = append(spec.Volumes, v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		})
+		for i := range spec.Containers {
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, v1.VolumeMount{
+				Name:      name,
+				MountPath: dir,
+			})
+		}
+	}
+
+	for i := range spec.Containers {
+		if spec.Containers[i].SecurityContext == nil {
+			spec.Containers[i].SecurityContext = ContainerSecurityContext(installFlags)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestValidateSecurityProfile(t *testing.T) {
+	installFlags := &flags.Install{MeshIngressServicePort: 19527, MeshEgressServicePort: 19528}
+	for profile, valid := range map[string]bool{
+		"":                        true,
+		SecurityProfileRestricted: true,
+		"baseline":                false,
+	} {
+		installFlags.SecurityProfile = profile
+		if err := ValidateSecurityProfile(installFlags); (err == nil) != valid {
+			t.Errorf("profile %q: want valid %v, got %v", profile, valid, err)
+		}
+	}
+
+	installFlags.SecurityProfile, installFlags.MeshIngressServicePort = SecurityProfileRestricted, 80
+	if err := ValidateSecurityProfile(installFlags); err == nil {
+		t.Errorf("expected privileged ingress port is invalid under the restricted profile")
+	}
+}
+
+func TestApplySecurityProfile(t *testing.T) {
+	spec := &v1.PodSpec{Containers: []v1.Container{{Name: "a"}, {Name: "b"}}}
+	ApplySecurityProfile(&flags.Install{}, spec, TmpDir)
+	if spec.SecurityContext != nil || len(spec.Volumes) != 0 || spec.Containers[0].SecurityContext != nil {
+		t.Fatalf("expected the pod is kept without a security profile")
+	}
+	if EasegressHomeDir(&flags.Install{}) != ControlPlaneHomeDir {
+		t.Fatalf("expected the default home dir without a security profile")
+	}
+
+	installFlags := &flags.Install{SecurityProfile: SecurityProfileRestricted}
+	spec.Containers[0].SecurityContext = ContainerSecurityContext(installFlags)
+	ApplySecurityProfile(installFlags, spec, RestrictedEasegressHomeDir, TmpDir)
+
+	if !*spec.SecurityContext.RunAsNonRoot || *spec.SecurityContext.RunAsUser != RestrictedUserID ||
+		spec.SecurityContext.SeccompProfile.Type != v1.SeccompProfileTypeRuntimeDefault {
+		t.Fatalf("unexpected pod security context %+v", spec.SecurityContext)
+	}
+	if len(spec.Volumes) != 2 || spec.Volumes[1].Name != "writable-tmp" || spec.Volumes[1].EmptyDir == nil {
+		t.Fatalf("expected emptyDir volumes of writable dirs, got %+v", spec.Volumes)
+	}
+	for _, c := range spec.Containers {
+		sc := c.SecurityContext
+		if sc == nil || !*sc.ReadOnlyRootFilesystem || *sc.AllowPrivilegeEscalation ||
+			len(sc.Capabilities.Drop) != 1 || sc.Capabilities.Drop[0] != "ALL" {
+			t.Fatalf("unexpected security context of container %s: %+v", c.Name, sc)
+		}
+		if len(c.VolumeMounts) != 2 || c.VolumeMounts[0].MountPath != RestrictedEasegressHomeDir {
+			t.Fatalf("expected writable dirs are mounted into container %s, got %+v", c.Name, c.VolumeMounts)
+		}
+	}
+}
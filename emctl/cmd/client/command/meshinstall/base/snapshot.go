@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// InstallSnapshotSecretName is the name of the secret keeping the snapshot of the installation.
+	InstallSnapshotSecretName = "easemesh-install-snapshot"
+	// InstallSnapshotSecretKey is the key of the snapshot in the secret.
+	InstallSnapshotSecretKey = "objects.json"
+)
+
+// snapshotResources are resources whose objects are healed by the operator,
+// others like CustomResourceDefinitions and PersistentVolumes are left out.
+var snapshotResources = map[string]bool{
+	"namespaces":                    true,
+	"configmaps":                    true,
+	"secrets":                       true,
+	"services":                      true,
+	"deployments":                   true,
+	"statefulsets":                  true,
+	"cronjobs":                      true,
+	"roles":                         true,
+	"rolebindings":                  true,
+	"clusterroles":                  true,
+	"clusterrolebindings":           true,
+	"mutatingwebhookconfigurations": true,
+	"ingressclasses":                true,
+	"networkpolicies":               true,
+}
+
+type (
+	// SnapshotObject is the desired state of an object applied by a stage of the installation.
+	SnapshotObject struct {
+		Group     string                 `json:"group,omitempty"`
+		Version   string                 `json:"version"`
+		Resource  string                 `json:"resource"`
+		Namespace string                 `json:"namespace,omitempty"`
+		Name      string                 `json:"name"`
+		Stage     string                 `json:"stage"`
+		Object    map[string]interface{} `json:"object"`
+	}
+
+	// InstallSnapshot keeps the objects applied by an installation run,
+	// so that the operator can revert manual edits and deletions of them.
+	InstallSnapshot struct {
+		mutex   sync.Mutex
+		stage   string
+		objects []*SnapshotObject
+	}
+
+	snapshotRoundTripper struct {
+		snapshot *InstallSnapshot
+		delegate http.RoundTripper
+	}
+)
+
+func (o *SnapshotObject) key() string {
+	return strings.Join([]string{o.Group, o.Resource, o.Namespace, o.Name}, "/")
+}
+
+// NewInstallSnapshot creates an empty snapshot for a new installation run.
+func NewInstallSnapshot() *InstallSnapshot {
+	return &InstallSnapshot{}
+}
+
+// BeginStage makes following objects belong to the stage.
+func (s *InstallSnapshot) BeginStage(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stage = name
+}
+
+// Objects returns the objects in order of application, an object applied
+// more than once is kept by its last state.
+func (s *InstallSnapshot) Objects() []*SnapshotObject {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	objects := make([]*SnapshotObject, len(s.objects))
+	copy(objects, s.objects)
+	return objects
+}
+
+func (s *InstallSnapshot) put(object *SnapshotObject) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	object.Stage = s.stage
+	for i, o := range s.objects {
+		if o.key() == object.key() {
+			s.objects[i] = object
+			return
+		}
+	}
+	s.objects = append(s.objects, object)
+}
+
+// MergeSnapshotObjects appends objects absent in old ones, and replaces
+// the ones present with them.
+func MergeSnapshotObjects(old, objects []*SnapshotObject) []*SnapshotObject {
+	index := map[string]int{}
+	result := make([]*SnapshotObject, 0, len(old)+len(objects))
+	for _, o := range append(old, objects...) {
+		if i, exists := index[o.key()]; exists {
+			result[i] = o
+			continue
+		}
+		index[o.key()] = len(result)
+		result = append(result, o)
+	}
+	return result
+}
+
+// RemoveSnapshotStages removes objects of the stages.
+func RemoveSnapshotStages(objects []*SnapshotObject, stages []string) []*SnapshotObject {
+	removed := map[string]bool{}
+	for _, stage := range stages {
+		removed[strings.ToLower(stage)] = true
+	}
+
+	result := []*SnapshotObject{}
+	for _, o := range objects {
+		if !removed[o.Stage] {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// MarshalSnapshotObjects marshals objects to the value of the snapshot secret.
+func MarshalSnapshotObjects(objects []*SnapshotObject) ([]byte, error) {
+	buff, err := json.Marshal(objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal snapshot objects to json failed")
+	}
+	return buff, nil
+}
+
+// UnmarshalSnapshotObjects unmarshals objects from the value of the snapshot secret.
+func UnmarshalSnapshotObjects(buff []byte) ([]*SnapshotObject, error) {
+	objects := []*SnapshotObject{}
+	err := json.Unmarshal(buff, &objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal snapshot objects from json failed")
+	}
+	return objects, nil
+}
+
+// WrapTransport makes the snapshot keep the objects applied through the transport.
+// It fits rest.Config.WrapTransport.
+func (s *InstallSnapshot) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &snapshotRoundTripper{snapshot: s, delegate: rt}
+}
+
+func (t *snapshotRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost && req.Method != http.MethodPut || req.Body == nil {
+		return t.delegate.RoundTrip(req)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "read body of request %s", req.URL.Path)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	resp, err := t.delegate.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, err
+	}
+
+	object, ok := snapshotObject(req.Method, req.URL.Path, body)
+	if ok {
+		t.snapshot.put(object)
+	}
+	return resp, err
+}
+
+// snapshotObject parses the object applied by the request, which creates
+// it by POST to its collection, or updates it by PUT to itself.
+func snapshotObject(method, urlPath string, body []byte) (*SnapshotObject, bool) {
+	if method == http.MethodPut {
+		urlPath = urlPath[:strings.LastIndex(urlPath, "/")]
+	}
+	recorded, ok := parseCollectionPath(urlPath)
+	if !ok || !snapshotResources[recorded.Resource] {
+		return nil, false
+	}
+
+	object := map[string]interface{}{}
+	if json.Unmarshal(body, &object) != nil {
+		common.Debugf("can't snapshot object applied by %s", urlPath)
+		return nil, false
+	}
+
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" || recorded.Resource == "secrets" && name == InstallSnapshotSecretName {
+		return nil, false
+	}
+
+	// NOTE: Fields maintained by the API server are not desired states.
+	for _, field := range []string{"resourceVersion", "uid", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		delete(metadata, field)
+	}
+	delete(object, "status")
+
+	return &SnapshotObject{
+		Group:     recorded.Group,
+		Version:   recorded.Version,
+		Resource:  recorded.Resource,
+		Namespace: recorded.Namespace,
+		Name:      name,
+		Object:    object,
+	}, true
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTripper(t *testing.T) {
+	snapshot := NewInstallSnapshot()
+	rt := snapshot.WrapTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		buff, _ := ioutil.ReadAll(req.Body)
+		if !strings.Contains(string(buff), `"name"`) {
+			t.Fatalf("request body should be kept, got %s", buff)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	}))
+
+	requests := []struct {
+		stage  string
+		method string
+		path   string
+		body   string
+	}{
+		{"operator", http.MethodPost, "/apis/apps/v1/namespaces/easemesh/deployments",
+			`{"metadata":{"name":"easemesh-operator","resourceVersion":"1"},"spec":{"replicas":1},"status":{}}`},
+		{"operator", http.MethodPut, "/apis/apps/v1/namespaces/easemesh/deployments/easemesh-operator",
+			`{"metadata":{"name":"easemesh-operator"},"spec":{"replicas":2}}`},
+		{"crd", http.MethodPost, "/apis/apiextensions.k8s.io/v1/customresourcedefinitions",
+			`{"metadata":{"name":"meshdeployments.mesh.megaease.com"}}`},
+		{"selfheal", http.MethodPost, "/api/v1/namespaces/easemesh/secrets",
+			`{"metadata":{"name":"` + InstallSnapshotSecretName + `"}}`},
+		{"shadowservice", http.MethodPost, "/api/v1/namespaces/easemesh/services",
+			`{"metadata":{"name":"easemesh-shadowservice-controller"}}`},
+	}
+	for _, r := range requests {
+		snapshot.BeginStage(r.stage)
+		req, _ := http.NewRequest(r.method, "https://127.0.0.1"+r.path, strings.NewReader(r.body))
+		_, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("round trip failed: %v", err)
+		}
+	}
+
+	objects := snapshot.Objects()
+	if len(objects) != 2 {
+		t.Fatalf("expected the deployment and the service in the snapshot, got %+v", objects)
+	}
+	deployment := objects[0]
+	if deployment.Resource != "deployments" || deployment.Name != "easemesh-operator" || deployment.Stage != "operator" {
+		t.Fatalf("unexpected object %+v", deployment)
+	}
+	if replicas := deployment.Object["spec"].(map[string]interface{})["replicas"]; replicas != float64(2) {
+		t.Fatalf("expected the last applied state, got replicas %v", replicas)
+	}
+
+	buff, err := MarshalSnapshotObjects(objects)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	objects, err = UnmarshalSnapshotObjects(buff)
+	if err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	objects = RemoveSnapshotStages(objects, []string{"ShadowService"})
+	if len(objects) != 1 || objects[0].Name != "easemesh-operator" {
+		t.Fatalf("expected objects of the stage removed, got %+v", objects)
+	}
+
+	merged := MergeSnapshotObjects(objects, []*SnapshotObject{
+		{Group: "apps", Resource: "deployments", Namespace: "easemesh", Name: "easemesh-operator", Stage: "operator"},
+		{Resource: "services", Namespace: "easemesh", Name: "easemesh-shadowservice-controller", Stage: "shadowservice"},
+	})
+	if len(merged) != 2 || merged[0].Object != nil || merged[1].Stage != "shadowservice" {
+		t.Fatalf("unexpected merged objects %+v", merged)
+	}
+}
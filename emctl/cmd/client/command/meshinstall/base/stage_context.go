@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// ContextBinding binds requests of Kubernetes clients to the context of
+	// the running stage, so that they are cancelled once the stage is
+	// interrupted or times out, instead of hanging on a slow API server.
+	ContextBinding struct {
+		mutex sync.RWMutex
+		ctx   context.Context
+	}
+
+	bindingRoundTripper struct {
+		binding  *ContextBinding
+		delegate http.RoundTripper
+	}
+)
+
+// NewContextBinding creates a ContextBinding, requests keep their own
+// contexts until a stage binds its context.
+func NewContextBinding() *ContextBinding {
+	return &ContextBinding{}
+}
+
+// Bind binds the context to following requests, nil unbinds it.
+func (b *ContextBinding) Bind(ctx context.Context) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.ctx = ctx
+}
+
+func (b *ContextBinding) context() context.Context {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.ctx
+}
+
+// WrapTransport makes requests through the transport bound to the context.
+// It fits rest.Config.WrapTransport.
+func (b *ContextBinding) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &bindingRoundTripper{binding: b, delegate: rt}
+}
+
+func (t *bindingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ctx := t.binding.context(); ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	return t.delegate.RoundTrip(req)
+}
+
+// BeginStage derives the context of a stage from the context of the
+// installation, which times out after the stage timeout. Requests of the
+// clients are bound to it until the returned function ends the stage.
+func (ctx *StageContext) BeginStage() (end func()) {
+	parent := ctx.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	var timeout time.Duration
+	if ctx.Flags != nil {
+		timeout = ctx.Flags.StageTimeout
+	}
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx.stage, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx.stage, cancel = context.WithCancel(parent)
+	}
+	ctx.Binding.Bind(ctx.stage)
+
+	return func() {
+		ctx.Binding.Bind(nil)
+		cancel()
+	}
+}
+
+// Stage returns the context of the running stage, which is done once
+// the installation is interrupted or the stage times out.
+func (ctx *StageContext) Stage() context.Context {
+	if ctx.stage == nil {
+		return context.Background()
+	}
+	return ctx.stage
+}
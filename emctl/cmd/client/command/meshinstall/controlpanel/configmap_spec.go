@@ -19,15 +19,42 @@ package controlpanel
 
 import (
 	"fmt"
+	"io/ioutil"
 
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		yamlBuff, err := easegressConfig(ctx.Flags)
+		if err != nil {
+			return err
+		}
+
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      installbase.ControlPlaneConfigMapName,
+				Namespace: ctx.Flags.MeshNamespace,
+			},
+			Data: map[string]string{
+				installbase.ControlPlaneConfigMapKey: string(yamlBuff),
+			},
+		}
+
+		return installbase.DeployConfigMap(configMap, ctx.Client, ctx.Flags.MeshNamespace)
+	}
+}
+
+// easegressConfig builds the config of the control plane, which is merged
+// into the template of --easegress-config-template if it's specified.
+// The values computed by the installer take precedence over the template.
+func easegressConfig(installFlags *flags.Install) ([]byte, error) {
 	config := installbase.EasegressConfig{
 		// Injected from env EG_NAME
 		// Name:                    "" ,
@@ -35,8 +62,8 @@ func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		ClusterName: installbase.ControlPlaneStatefulSetName,
 		ClusterRole: installbase.EasegressPrimaryClusterRole,
 		Cluster: installbase.ClusterOptions{
-			ListenPeerURLs:   []string{fmt.Sprintf("http://0.0.0.0:%d", ctx.Flags.EgPeerPort)},
-			ListenClientURLs: []string{fmt.Sprintf("http://0.0.0.0:%d", ctx.Flags.EgClientPort)},
+			ListenPeerURLs:   []string{fmt.Sprintf("http://0.0.0.0:%d", installFlags.EgPeerPort)},
+			ListenClientURLs: []string{fmt.Sprintf("http://0.0.0.0:%d", installFlags.EgClientPort)},
 
 			// Injected from command line.
 			// AdvertiseClientURLs: nil,
@@ -47,29 +74,80 @@ func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 			// Injected from command line.
 			// InitialCluster: nil,
 		},
-		APIAddr: fmt.Sprintf("0.0.0.0:%d", ctx.Flags.EgAdminPort),
-		HomeDir: installbase.ControlPlaneHomeDir,
+		APIAddr: fmt.Sprintf("0.0.0.0:%d", installFlags.EgAdminPort),
+		HomeDir: installbase.EasegressHomeDir(installFlags),
 		DataDir: installbase.ControlPlaneDataDir,
 	}
 
-	yamlBuff, _ := yaml.Marshal(config)
-	data := map[string]string{
-		installbase.ControlPlaneConfigMapKey: string(yamlBuff),
+	yamlBuff, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal easegress config")
+	}
+	if installFlags.EasegressConfigTemplate == "" {
+		return yamlBuff, nil
 	}
 
-	configMap := &v1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      installbase.ControlPlaneConfigMapName,
-			Namespace: ctx.Flags.MeshNamespace,
-		},
-		Data: data,
+	templateBuff, err := ioutil.ReadFile(installFlags.EasegressConfigTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "read easegress config template")
+	}
+	template := map[string]interface{}{}
+	err = yaml.Unmarshal(templateBuff, &template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal easegress config template %s", installFlags.EasegressConfigTemplate)
 	}
 
-	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployConfigMap(configMap, ctx.Client, ctx.Flags.MeshNamespace)
-		if err != nil {
-			return err
+	computed := map[string]interface{}{}
+	err = yaml.Unmarshal(yamlBuff, &computed)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal easegress config")
+	}
+	for key, value := range computed {
+		template[key] = mergeConfigValue(template[key], value)
+	}
+
+	yamlBuff, err = yaml.Marshal(template)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal easegress config")
+	}
+	return yamlBuff, nil
+}
+
+// mergeConfigValue merges the computed value into the value of the template,
+// empty computed values are left to the template, and maps are merged by keys.
+func mergeConfigValue(templateValue, computedValue interface{}) interface{} {
+	switch computed := computedValue.(type) {
+	case nil:
+		return templateValue
+	case string:
+		if computed == "" {
+			return templateValue
 		}
-		return nil
+	case int:
+		if computed == 0 {
+			return templateValue
+		}
+	case bool:
+		if !computed {
+			return templateValue
+		}
+	case []interface{}:
+		if len(computed) == 0 {
+			return templateValue
+		}
+	case map[interface{}]interface{}:
+		if len(computed) == 0 {
+			return templateValue
+		}
+		template, ok := templateValue.(map[interface{}]interface{})
+		if !ok {
+			return computed
+		}
+		for key, value := range computed {
+			template[key] = mergeConfigValue(template[key], value)
+		}
+		return template
 	}
+
+	return computedValue
 }
@@ -30,13 +30,14 @@ import (
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Deploy will deploy resource of control panel
 func Deploy(ctx *installbase.StageContext) error {
 	installFuncs := []installbase.InstallFunc{
 		namespaceSpec(ctx),
+		secretReaderRoleSpec(ctx),
+		secretReaderRoleBindingSpec(ctx),
 		configMapSpec(ctx),
 		serviceSpec(ctx),
 		statefulsetSpec(ctx),
@@ -63,42 +64,70 @@ func Deploy(ctx *installbase.StageContext) error {
 func PreCheck(context *installbase.StageContext) error {
 	var err error
 
-	// 1. check available PersistentVolume
-	pvList, err := installbase.ListPersistentVolume(context.Client)
+	// 1. check tuning of service heartbeats and instance eviction
+	err = checkHeartbeatTuning(context.Flags)
 	if err != nil {
 		return err
 	}
 
-	availablePVCount := 0
-	quantity := resource.MustParse(context.Flags.MeshControlPlanePersistVolumeCapacity)
-	boundedPVCSuffixes := []string{}
-	for i := 0; i < context.Flags.EasegressControlPlaneReplicas; i++ {
-		boundedPVCSuffixes = append(boundedPVCSuffixes, fmt.Sprintf("%s-%d", installbase.ControlPlaneStatefulSetName, i))
-	}
-	for _, pv := range pvList.Items {
-		if pv.Status.Phase == v1.VolumeAvailable &&
-			pv.Spec.StorageClassName == context.Flags.MeshControlPlaneStorageClassName &&
-			pv.Spec.Capacity.Storage().Cmp(quantity) >= 0 &&
-			checkPVAccessModes(v1.ReadWriteOnce, &pv) {
-			availablePVCount++
-		} else if pv.Status.Phase == v1.VolumeBound {
-			// If PV already bound to PVC of EaseMesh controlpanel
-			// we regarded it as availablePVCount
-			for _, pvNameSuffix := range boundedPVCSuffixes {
-				if pv.Spec.ClaimRef.Kind == "PersistentVolumeClaim" &&
-					pv.Spec.ClaimRef.Namespace == context.Flags.MeshNamespace &&
-					strings.HasSuffix(pv.Spec.ClaimRef.Name, pvNameSuffix) {
-					availablePVCount++
-					break
-				}
-			}
-		}
+	// 2. check volumes of the control plane
+	err = checkStorage(context)
+	if err != nil {
+		return err
+	}
+
+	// 3. check the ZooKeeper registry of Dubbo services
+	_, err = zookeeperRegistryConfig(context.Flags)
+	if err != nil {
+		return err
+	}
+
+	// 4. check stores resolving secret references
+	err = checkSecretStore(context.Flags)
+	if err != nil {
+		return err
+	}
+
+	// 5. check the ACME client of ingress controllers
+	err = checkIngressACME(context.Flags)
+	if err != nil {
+		return err
+	}
+
+	// 6. check ports of the control plane
+	err = installbase.ValidateControlPlanePorts(context.Flags)
+	if err != nil {
+		return err
 	}
 
-	if availablePVCount < context.Flags.EasegressControlPlaneReplicas {
-		return errors.Errorf(flags.MeshControlPlanePVNotExistedHelpStr)
+	// 7. check the template of the control plane config
+	_, err = easegressConfig(context.Flags)
+	if err != nil {
+		return err
 	}
 
+	// 8. check zones the control plane is spread across
+	err = checkZones(context)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkHeartbeatTuning checks service instances are expired after
+// missing at least one heartbeat.
+func checkHeartbeatTuning(installFlags *flags.Install) error {
+	if installFlags.HeartbeatInterval <= 0 {
+		return errors.Errorf("--heartbeat-interval must be positive, got %d", installFlags.HeartbeatInterval)
+	}
+	if installFlags.InstanceExpiry <= installFlags.HeartbeatInterval {
+		return errors.Errorf("--instance-expiry (%d) must be greater than --heartbeat-interval (%d)",
+			installFlags.InstanceExpiry, installFlags.HeartbeatInterval)
+	}
+	if installFlags.DeregistrationGracePeriod < 0 {
+		return errors.Errorf("--deregistration-grace-period must not be negative, got %d", installFlags.DeregistrationGracePeriod)
+	}
 	return nil
 }
 
@@ -119,6 +148,14 @@ func Clear(context *installbase.StageContext) error {
 	installbase.DeleteResources(context.Client, statefulsetResource, context.Flags.MeshNamespace, installbase.DeleteStatefulsetResource)
 	installbase.DeleteResources(context.Client, coreV1Resources, context.Flags.MeshNamespace, installbase.DeleteCoreV1Resource)
 
+	rbacV1Resources := [][]string{
+		{"rolebindings", installbase.ControlPlaneSecretReaderRoleName},
+		{"roles", installbase.ControlPlaneSecretReaderRoleName},
+	}
+	for _, namespace := range secretNamespaces(context.Flags) {
+		installbase.DeleteResources(context.Client, rbacV1Resources, namespace, installbase.DeleteRbacV1Resources)
+	}
+
 	return nil
 }
 
@@ -147,7 +184,11 @@ func checkPVAccessModes(accessModel v1.PersistentVolumeAccessMode, volume *v1.Pe
 
 func checkEasegressControlPlaneStatus(ctx *installbase.StageContext) error {
 	// Wait a fix time for the Easegress cluster to start
-	time.Sleep(time.Second * time.Duration(ctx.Flags.WaitControlPlaneTimeoutInSeconds))
+	select {
+	case <-time.After(time.Second * time.Duration(ctx.Flags.WaitControlPlaneTimeoutInSeconds)):
+	case <-ctx.Stage().Done():
+		return ctx.Stage().Err()
+	}
 
 	entrypoints, err := installbase.GetMeshControlPlaneEndpoints(ctx.Client, ctx.Flags.MeshNamespace,
 		installbase.ControlPlanePlubicServiceName,
@@ -156,7 +197,7 @@ func checkEasegressControlPlaneStatus(ctx *installbase.StageContext) error {
 		return errors.Wrap(err, "get mesh control plane entrypoint failed")
 	}
 
-	fmt.Printf("control plane endpoints: %+v\n", entrypoints)
+	common.Infof("control plane endpoints: %+v", entrypoints)
 
 	timeOutPerTry := ctx.Flags.MeshControlPlaneCheckHealthzMaxTime / len(entrypoints)
 
@@ -173,7 +214,7 @@ func checkEasegressControlPlaneStatus(ctx *installbase.StageContext) error {
 					return true
 				}
 
-				return len(members) < (ctx.Flags.EaseMeshOperatorReplicas/2 + 1)
+				return len(members) < (ctx.Flags.EasegressControlPlaneReplicas/2 + 1)
 			})...).
 			Get(entrypoints[i]+installbase.MemberList, nil, time.Second*time.Duration(timeOutPerTry), nil).
 			HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
@@ -18,15 +18,24 @@
 package controlpanel
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	appsV1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
@@ -92,11 +101,329 @@ func TestDeploy(t *testing.T) {
 	clearEaseMeshControlPlaneProvision(ctx.Cmd, ctx.Client, ctx.Flags)
 }
 
+func TestStatefulsetSpecError(t *testing.T) {
+	ctx, _, _ := prepareContext()
+
+	failed := func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		return nil, errors.New("mock error")
+	}
+	_, err := statefulsetPVCSpec(statefulsetContainerSpec(baseStatefulSetSpec(failed)))(ctx)
+	if err == nil {
+		t.Fatalf("statefulset spec should propagate error of inner spec func")
+	}
+
+	statefulSet, err := statefulsetPVCSpec(statefulsetContainerSpec(baseStatefulSetSpec(initialStatefulSetSpec(nil))))(ctx)
+	if err != nil {
+		t.Fatalf("build statefulset spec failed: %s", err)
+	}
+	if len(statefulSet.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("expected 1 container, got %d", len(statefulSet.Spec.Template.Spec.Containers))
+	}
+}
+
+func TestCustomPorts(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	ctx.Flags.EgAdminPort, ctx.Flags.EgClientPort, ctx.Flags.EgPeerPort = 12381, 12379, 12380
+	ctx.Flags.EgServiceAdminPort, ctx.Flags.EgServicePeerPort = 22381, 22380
+
+	statefulSet, err := statefulsetPVCSpec(statefulsetContainerSpec(baseStatefulSetSpec(initialStatefulSetSpec(nil))))(ctx)
+	if err != nil {
+		t.Fatalf("build statefulset spec failed: %s", err)
+	}
+	containerPorts := map[string]int32{}
+	for _, port := range statefulSet.Spec.Template.Spec.Containers[0].Ports {
+		containerPorts[port.Name] = port.ContainerPort
+	}
+	expected := map[string]int32{
+		installbase.ControlPlaneStatefulSetAdminPortName:  12381,
+		installbase.ControlPlaneStatefulSetClientPortName: 12379,
+		installbase.ControlPlaneStatefulSetPeerPortName:   12380,
+	}
+	for name, port := range expected {
+		if containerPorts[name] != port {
+			t.Fatalf("expected container port %s is %d, got %d", name, port, containerPorts[name])
+		}
+	}
+
+	err = serviceSpec(ctx).Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy services failed: %s", err)
+	}
+	expectedServicePorts := map[string]int32{
+		installbase.ControlPlaneStatefulSetAdminPortName:  22381,
+		installbase.ControlPlaneStatefulSetClientPortName: 12379,
+		installbase.ControlPlaneStatefulSetPeerPortName:   22380,
+	}
+	for _, name := range []string{installbase.ControlPlaneHeadlessServiceName, installbase.ControlPlanePlubicServiceName, ctx.Flags.EgServiceName} {
+		service, err := client.CoreV1().Services(ctx.Flags.MeshNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get service %s failed: %s", name, err)
+		}
+		for _, port := range service.Spec.Ports {
+			expectedPort := expected[port.Name]
+			if name == ctx.Flags.EgServiceName {
+				expectedPort = expectedServicePorts[port.Name]
+			}
+			if port.Port != expectedPort || port.TargetPort.IntVal != expected[port.Name] {
+				t.Fatalf("expected port %s of service %s is %d -> %d, got %d -> %s",
+					port.Name, name, expectedPort, expected[port.Name], port.Port, port.TargetPort.String())
+			}
+		}
+	}
+
+	if err = installbase.ValidateControlPlanePorts(ctx.Flags); err != nil {
+		t.Fatalf("custom ports should be valid: %s", err)
+	}
+	ctx.Flags.EgPeerPort = int(ctx.Flags.MeshIngressServicePort)
+	if err = installbase.ValidateControlPlanePorts(ctx.Flags); err == nil {
+		t.Fatalf("expected the peer port conflicting with the ingress port is invalid")
+	}
+	ctx.Flags.EgPeerPort = 70000
+	if err = installbase.ValidateControlPlanePorts(ctx.Flags); err == nil {
+		t.Fatalf("expected the peer port out of range is invalid")
+	}
+	ctx.Flags.EgPeerPort = 12380
+	ctx.Flags.EgServicePeerPort = ctx.Flags.EgClientPort
+	if err = installbase.ValidateControlPlanePorts(ctx.Flags); err == nil {
+		t.Fatalf("expected the service peer port conflicting with the client port is invalid")
+	}
+}
+
+func TestStatefulsetEphemeralStorage(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	ctx.Flags.MeshControlPlaneEphemeralStorage = true
+
+	statefulSet, err := statefulsetPVCSpec(statefulsetContainerSpec(baseStatefulSetSpec(initialStatefulSetSpec(nil))))(ctx)
+	if err != nil {
+		t.Fatalf("build statefulset spec failed: %s", err)
+	}
+	if len(statefulSet.Spec.VolumeClaimTemplates) != 0 {
+		t.Fatalf("expected no volume claim template, got %d", len(statefulSet.Spec.VolumeClaimTemplates))
+	}
+	found := false
+	for _, v := range statefulSet.Spec.Template.Spec.Volumes {
+		if v.Name == installbase.ControlPlanePVCName && v.EmptyDir != nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected emptyDir volume %s", installbase.ControlPlanePVCName)
+	}
+}
+
+func storageClass(name, provisioner string, isDefault bool) *storagev1.StorageClass {
+	class := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		Provisioner: provisioner,
+	}
+	if isDefault {
+		class.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+	}
+	return class
+}
+
+func TestCheckStorage(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		classes   []*storagev1.StorageClass
+		wantErr   bool
+		wantClass string
+	}{
+		{
+			name:    "ephemeral storage",
+			args:    []string{"--ephemeral-storage"},
+			wantErr: false,
+		},
+		{
+			name:      "default storage class",
+			args:      []string{"--storage-class="},
+			classes:   []*storagev1.StorageClass{storageClass("standard", "rancher.io/local-path", true)},
+			wantClass: "",
+		},
+		{
+			name:    "no default storage class",
+			args:    []string{"--storage-class="},
+			classes: []*storagev1.StorageClass{storageClass("standard", "rancher.io/local-path", false)},
+			wantErr: true,
+		},
+		{
+			name:      "dynamic provisioning",
+			classes:   []*storagev1.StorageClass{storageClass(flags.DefaultMeshControlPlaneStorageClassName, "ebs.csi.aws.com", false)},
+			wantClass: flags.DefaultMeshControlPlaneStorageClassName,
+		},
+		{
+			name:      "fall back to default storage class",
+			classes:   []*storagev1.StorageClass{storageClass("standard", "k8s.io/minikube-hostpath", true)},
+			wantClass: "standard",
+		},
+		{
+			name:    "no fallback for explicit storage class",
+			args:    []string{"--storage-class", "local"},
+			classes: []*storagev1.StorageClass{storageClass("standard", "k8s.io/minikube-hostpath", true)},
+			wantErr: true,
+		},
+		{
+			name:    "no volume",
+			classes: []*storagev1.StorageClass{storageClass(flags.DefaultMeshControlPlaneStorageClassName, "kubernetes.io/no-provisioner", false)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, client, _ := prepareContext()
+			err := ctx.Cmd.Flags().Parse(tt.args)
+			if err != nil {
+				t.Fatalf("parse flags failed: %v", err)
+			}
+			for _, class := range tt.classes {
+				client.Tracker().Add(class)
+			}
+
+			err = checkStorage(ctx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("check storage failed: %v", err)
+			}
+			if ctx.Flags.MeshControlPlaneStorageClassName != tt.wantClass && !ctx.Flags.MeshControlPlaneEphemeralStorage {
+				t.Fatalf("expected storage class %q, got %q", tt.wantClass, ctx.Flags.MeshControlPlaneStorageClassName)
+			}
+		})
+	}
+}
+
 func TestCheckPV(T *testing.T) {
 	checkPVAccessModes(v1.ReadWriteOnce, &v1.PersistentVolume{})
 	checkPVAccessModes(v1.ReadWriteOnce, &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}}})
 }
 
+func TestCheckHeartbeatTuning(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	if err := checkHeartbeatTuning(ctx.Flags); err != nil {
+		t.Fatalf("default tuning should be valid: %v", err)
+	}
+
+	ctx.Flags.InstanceExpiry = ctx.Flags.HeartbeatInterval
+	if err := checkHeartbeatTuning(ctx.Flags); err == nil {
+		t.Fatalf("expected instance expiry not greater than heartbeat interval is invalid")
+	}
+
+	ctx.Flags.InstanceExpiry, ctx.Flags.DeregistrationGracePeriod = 30, -1
+	if err := checkHeartbeatTuning(ctx.Flags); err == nil {
+		t.Fatalf("expected negative deregistration grace period is invalid")
+	}
+}
+
+func TestSecretStore(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	if secretStoreConfig(ctx.Flags) != nil {
+		t.Fatalf("secret store should be nil without --vault-address")
+	}
+
+	ctx.Flags.VaultAddress = "https://vault.vault:8200"
+	if err := checkSecretStore(ctx.Flags); err == nil {
+		t.Fatalf("expected --vault-address without --vault-role is invalid")
+	}
+	ctx.Flags.VaultRole = "easemesh"
+	if err := checkSecretStore(ctx.Flags); err != nil {
+		t.Fatalf("check secret store failed: %v", err)
+	}
+	store := secretStoreConfig(ctx.Flags)
+	if store == nil || store.Vault.AuthPath != flags.DefaultVaultAuthPath || store.Vault.Role != "easemesh" {
+		t.Fatalf("unexpected secret store %+v", store)
+	}
+	ctx.Flags.VaultAddress = "vault:8200"
+	if err := checkSecretStore(ctx.Flags); err == nil {
+		t.Fatalf("expected --vault-address without scheme is invalid")
+	}
+
+	ctx.Flags.SecretNamespaces = []string{"payments", ctx.Flags.MeshNamespace}
+	for _, f := range []func(*installbase.StageContext) installbase.InstallFunc{
+		secretReaderRoleSpec, secretReaderRoleBindingSpec,
+	} {
+		if err := f(ctx).Deploy(ctx); err != nil {
+			t.Fatalf("deploy secret reader failed: %v", err)
+		}
+	}
+	for _, namespace := range []string{ctx.Flags.MeshNamespace, "payments"} {
+		binding, err := client.RbacV1().RoleBindings(namespace).Get(context.Background(),
+			installbase.ControlPlaneSecretReaderRoleName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get role binding in %s failed: %v", namespace, err)
+		}
+		if binding.Subjects[0].Namespace != ctx.Flags.MeshNamespace {
+			t.Fatalf("role binding in %s should bind the service account of the mesh namespace", namespace)
+		}
+	}
+	roles, _ := client.RbacV1().Roles("").List(context.Background(), metav1.ListOptions{})
+	if len(roles.Items) != 2 {
+		t.Fatalf("expected 2 roles, got %d", len(roles.Items))
+	}
+}
+
+func TestEasegressConfigTemplate(t *testing.T) {
+	ctx, _, _ := prepareContext()
+
+	dir, err := ioutil.TempDir("", "easegress-config")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx.Flags.EasegressConfigTemplate = filepath.Join(dir, "easegress.yaml")
+	if _, err = easegressConfig(ctx.Flags); err == nil {
+		t.Fatalf("expected the missing template is invalid")
+	}
+
+	template := `
+cluster-name: my-cluster
+log-dir: /opt/easegress/log
+labels:
+  region: us-east-1
+cluster:
+  listen-peer-urls: [http://0.0.0.0:9999]
+  max-call-send-msg-size: 10485760
+`
+	err = ioutil.WriteFile(ctx.Flags.EasegressConfigTemplate, []byte(template), 0644)
+	if err != nil {
+		t.Fatalf("write template failed: %v", err)
+	}
+
+	buff, err := easegressConfig(ctx.Flags)
+	if err != nil {
+		t.Fatalf("build config failed: %v", err)
+	}
+	config := installbase.EasegressConfig{}
+	err = yaml.Unmarshal(buff, &config)
+	if err != nil {
+		t.Fatalf("unmarshal config failed: %v", err)
+	}
+
+	if config.ClusterName != installbase.ControlPlaneStatefulSetName ||
+		config.DataDir != installbase.ControlPlaneDataDir ||
+		config.Cluster.ListenPeerURLs[0] != "http://0.0.0.0:2380" {
+		t.Fatalf("expected computed values take precedence, got %+v", config)
+	}
+	if config.LogDir != "/opt/easegress/log" || config.Labels["region"] != "us-east-1" ||
+		config.Cluster.MaxCallSendMsgSize != 10485760 {
+		t.Fatalf("expected values of the template are kept, got %+v", config)
+	}
+
+	err = ioutil.WriteFile(ctx.Flags.EasegressConfigTemplate, []byte("labels: ["), 0644)
+	if err != nil {
+		t.Fatalf("write template failed: %v", err)
+	}
+	if _, err = easegressConfig(ctx.Flags); err == nil {
+		t.Fatalf("expected the malformed template is invalid")
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	unmarshalMember([]byte{})
 	unmarshalMember([]byte("test"))
@@ -111,3 +438,44 @@ func TestDescribePhase(t *testing.T) {
 }
 
 var helloWorld = "aGVsbG8gd29ybGQK"
+
+func TestIngressACME(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	if err := checkIngressACME(ctx.Flags); err != nil || ingressACMEConfig(ctx.Flags) != nil {
+		t.Fatalf("ACME should be disabled without --ingress-acme-email")
+	}
+
+	ctx.Flags.IngressACMEEmail = "ops@megaease.com"
+	if err := checkIngressACME(ctx.Flags); err != nil {
+		t.Fatalf("check ACME failed: %v", err)
+	}
+	config := ingressACMEConfig(ctx.Flags)
+	if config == nil || config.DirectoryURL != flags.DefaultIngressACMEDirectoryURL || config.DNSProvider != nil {
+		t.Fatalf("unexpected ACME config %+v", config)
+	}
+
+	ctx.Flags.IngressACMEDNSProvider = "cloudflare"
+	if err := checkIngressACME(ctx.Flags); err == nil {
+		t.Fatalf("expected --ingress-acme-dns-provider without secret is invalid")
+	}
+	ctx.Flags.IngressACMEDNSProviderSecret = "cloudflare-token"
+	if err := checkIngressACME(ctx.Flags); err != nil {
+		t.Fatalf("check ACME failed: %v", err)
+	}
+	if config := ingressACMEConfig(ctx.Flags); config.DNSProvider == nil || config.DNSProvider.SecretName != "cloudflare-token" {
+		t.Fatalf("unexpected ACME config %+v", config)
+	}
+
+	for _, modify := range []func(f *flags.Install){
+		func(f *flags.Install) { f.IngressACMEDNSProvider = "godaddy" },
+		func(f *flags.Install) { f.IngressACMEEmail = "ops" },
+		func(f *flags.Install) { f.IngressACMEDirectoryURL = "http://acme.megaease.com/directory" },
+		func(f *flags.Install) { f.IngressACMEEmail = "" },
+	} {
+		invalid := *ctx.Flags
+		modify(&invalid)
+		if err := checkIngressACME(&invalid); err == nil {
+			t.Fatalf("expected flags %+v are invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+)
+
+// acmeDNSProviders are the DNS provider plugins of the ACME client of
+// ingress controllers solving dns-01 challenges.
+var acmeDNSProviders = map[string]bool{
+	"alidns":       true,
+	"azure":        true,
+	"cloudflare":   true,
+	"digitalocean": true,
+	"dnspod":       true,
+	"duckdns":      true,
+	"google":       true,
+	"hetzner":      true,
+	"route53":      true,
+	"vultr":        true,
+}
+
+// ingressACMEConfig returns the config of the ACME client of ingress
+// controllers, nil if no account email is specified.
+func ingressACMEConfig(installFlags *flags.Install) *installbase.IngressACMEConfig {
+	if installFlags.IngressACMEEmail == "" {
+		return nil
+	}
+
+	config := &installbase.IngressACMEConfig{
+		Email:        installFlags.IngressACMEEmail,
+		DirectoryURL: installFlags.IngressACMEDirectoryURL,
+	}
+	if installFlags.IngressACMEDNSProvider != "" {
+		config.DNSProvider = &installbase.ACMEDNSProviderConfig{
+			Name:       installFlags.IngressACMEDNSProvider,
+			SecretName: installFlags.IngressACMEDNSProviderSecret,
+		}
+	}
+	return config
+}
+
+// checkIngressACME checks flags of the ACME client of ingress controllers.
+func checkIngressACME(installFlags *flags.Install) error {
+	if installFlags.IngressACMEEmail == "" {
+		if installFlags.IngressACMEDNSProvider != "" {
+			return errors.New("--ingress-acme-email is required by --ingress-acme-dns-provider")
+		}
+		return nil
+	}
+
+	at := strings.Index(installFlags.IngressACMEEmail, "@")
+	if at <= 0 || at == len(installFlags.IngressACMEEmail)-1 {
+		return errors.Errorf("--ingress-acme-email must be an email address, got %q", installFlags.IngressACMEEmail)
+	}
+	u, err := url.Parse(installFlags.IngressACMEDirectoryURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.Errorf("--ingress-acme-directory-url must be an https URL, got %q", installFlags.IngressACMEDirectoryURL)
+	}
+
+	if installFlags.IngressACMEDNSProvider == "" {
+		return nil
+	}
+	if !acmeDNSProviders[installFlags.IngressACMEDNSProvider] {
+		providers := []string{}
+		for provider := range acmeDNSProviders {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+		return errors.Errorf("unsupported --ingress-acme-dns-provider %s (support %s)",
+			installFlags.IngressACMEDNSProvider, strings.Join(providers, ", "))
+	}
+	if installFlags.IngressACMEDNSProviderSecret == "" {
+		return errors.New("--ingress-acme-dns-provider-secret is required by --ingress-acme-dns-provider")
+	}
+	return nil
+}
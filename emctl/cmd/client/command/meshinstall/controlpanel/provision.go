@@ -43,6 +43,18 @@ func provisionEaseMeshControlPlane(ctx *installbase.StageContext) error {
 		return errors.Wrap(err, "get mesh control panel entrypoint failed")
 	}
 
+	zookeeperConfig, err := zookeeperRegistryConfig(ctx.Flags)
+	if err != nil {
+		return err
+	}
+	if zookeeperConfig != nil {
+		// NOTE: The registry is created before the MeshController referring to it.
+		err = createObject(entrypoints, zookeeperConfig)
+		if err != nil {
+			return errors.Wrap(err, "create ZooKeeper registry")
+		}
+	}
+
 	meshControllerConfig := installbase.MeshControllerConfig{
 		Name:              installbase.MeshControllerName,
 		Kind:              flags.MeshControllerKind,
@@ -50,11 +62,27 @@ func provisionEaseMeshControlPlane(ctx *installbase.StageContext) error {
 		HeartbeatInterval: strconv.Itoa(ctx.Flags.HeartbeatInterval) + "s",
 		IngressPort:       ctx.Flags.MeshIngressServicePort,
 		APIPort:           installbase.MeshControllerAPIPort,
+
+		RevisionHistoryLimit: ctx.Flags.RevisionHistoryLimit,
+
+		InstanceExpiry:            strconv.Itoa(ctx.Flags.InstanceExpiry) + "s",
+		DeregistrationGracePeriod: strconv.Itoa(ctx.Flags.DeregistrationGracePeriod) + "s",
 	}
+	if zookeeperConfig != nil {
+		meshControllerConfig.ExternalServiceRegistry = zookeeperConfig.Name
+	}
+	meshControllerConfig.SecretStore = secretStoreConfig(ctx.Flags)
+	meshControllerConfig.IngressACME = ingressACMEConfig(ctx.Flags)
+
+	return createObject(entrypoints, meshControllerConfig)
+}
 
-	configBody, err := yaml.Marshal(meshControllerConfig)
+// createObject creates the object in the control plane by the first
+// entrypoint available.
+func createObject(entrypoints []string, object interface{}) error {
+	configBody, err := yaml.Marshal(object)
 	if err != nil {
-		return fmt.Errorf("marshal %#v to yaml failed: %v", meshControllerConfig, err)
+		return fmt.Errorf("marshal %#v to yaml failed: %v", object, err)
 	}
 
 	for _, entrypoint := range entrypoints {
@@ -87,9 +115,16 @@ func clearEaseMeshControlPlaneProvision(cmd *cobra.Command, kubeClient kubernete
 		return
 	}
 
+	// NOTE: The MeshController is deleted before the registry it refers to.
+	for _, name := range []string{installbase.MeshControllerName, installbase.ZookeeperServiceRegistryName} {
+		deleteObject(entrypoints, name)
+	}
+}
+
+func deleteObject(entrypoints []string, name string) {
 	for _, entrypoint := range entrypoints {
-		url := fmt.Sprintf(entrypoint+installbase.ObjectURL, installbase.MeshControllerName)
-		_, err = client.NewHTTPJSON().
+		url := fmt.Sprintf(entrypoint+installbase.ObjectURL, name)
+		_, err := client.NewHTTPJSON().
 			Delete(url, nil, time.Second*5, nil).
 			HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
 				if statusCode == http.StatusNotFound {
@@ -101,7 +136,7 @@ func clearEaseMeshControlPlaneProvision(cmd *cobra.Command, kubeClient kubernete
 				return nil, nil
 			})
 		if err != nil {
-			common.OutputErrorf("delete mesh controller configuration from %s failed %s", url, err)
+			common.OutputErrorf("delete object %s from %s failed %s", name, url, err)
 		}
 	}
 }
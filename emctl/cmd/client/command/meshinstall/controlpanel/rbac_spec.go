@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// secretReaderRoleSpec grants the control plane to read Secrets referenced
+// by mesh resources, only in the mesh namespace and --secret-namespaces.
+func secretReaderRoleSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		for _, namespace := range secretNamespaces(ctx.Flags) {
+			role := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      installbase.ControlPlaneSecretReaderRoleName,
+					Namespace: namespace,
+				},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"secrets"},
+						Verbs:     []string{"get", "list", "watch"},
+					},
+				},
+			}
+			err := installbase.DeployRole(role, ctx.Client, namespace)
+			if err != nil {
+				return errors.Wrapf(err, "create role %s in namespace %s", role.Name, namespace)
+			}
+		}
+		return nil
+	}
+}
+
+func secretReaderRoleBindingSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		for _, namespace := range secretNamespaces(ctx.Flags) {
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      installbase.ControlPlaneSecretReaderRoleName,
+					Namespace: namespace,
+				},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "Role",
+					Name:     installbase.ControlPlaneSecretReaderRoleName,
+				},
+				Subjects: []rbacv1.Subject{
+					{
+						Kind:      "ServiceAccount",
+						Name:      "default",
+						Namespace: ctx.Flags.MeshNamespace,
+					},
+				},
+			}
+			err := installbase.DeployRoleBinding(roleBinding, ctx.Client, namespace)
+			if err != nil {
+				return errors.Wrapf(err, "create roleBinding %s in namespace %s", roleBinding.Name, namespace)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+)
+
+// secretStoreConfig returns the config of stores resolving secret references
+// of mesh resources, nil if no Vault is specified. Kubernetes Secrets are
+// always resolved by the control plane with its service account.
+func secretStoreConfig(installFlags *flags.Install) *installbase.SecretStoreConfig {
+	if installFlags.VaultAddress == "" {
+		return nil
+	}
+
+	return &installbase.SecretStoreConfig{
+		Vault: &installbase.VaultConfig{
+			Address:  installFlags.VaultAddress,
+			AuthPath: strings.Trim(installFlags.VaultAuthPath, "/"),
+			Role:     installFlags.VaultRole,
+		},
+	}
+}
+
+// checkSecretStore checks flags of stores resolving secret references.
+func checkSecretStore(installFlags *flags.Install) error {
+	for _, namespace := range installFlags.SecretNamespaces {
+		if namespace == "" {
+			return errors.New("--secret-namespaces must not contain empty namespace")
+		}
+	}
+
+	if installFlags.VaultAddress == "" {
+		return nil
+	}
+	u, err := url.Parse(installFlags.VaultAddress)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("--vault-address must be an http or https URL, got %q", installFlags.VaultAddress)
+	}
+	if installFlags.VaultRole == "" {
+		return errors.New("--vault-role is required by --vault-address")
+	}
+	if strings.Trim(installFlags.VaultAuthPath, "/") == "" {
+		return errors.New("--vault-auth-path must not be empty")
+	}
+	return nil
+}
+
+// secretNamespaces returns the namespaces whose Secrets the control plane
+// reads, which always contain the mesh namespace.
+func secretNamespaces(installFlags *flags.Install) []string {
+	namespaces := []string{installFlags.MeshNamespace}
+	for _, namespace := range installFlags.SecretNamespaces {
+		duplicated := false
+		for _, ns := range namespaces {
+			if ns == namespace {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
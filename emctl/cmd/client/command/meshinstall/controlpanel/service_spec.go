@@ -48,17 +48,17 @@ func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		{
 			Name:       installbase.ControlPlaneStatefulSetAdminPortName,
 			Port:       int32(ctx.Flags.EgAdminPort),
-			TargetPort: intstr.IntOrString{IntVal: 2381},
+			TargetPort: intstr.FromInt(ctx.Flags.EgAdminPort),
 		},
 		{
 			Name:       installbase.ControlPlaneStatefulSetPeerPortName,
 			Port:       int32(ctx.Flags.EgPeerPort),
-			TargetPort: intstr.IntOrString{IntVal: 2380},
+			TargetPort: intstr.FromInt(ctx.Flags.EgPeerPort),
 		},
 		{
 			Name:       installbase.ControlPlaneStatefulSetClientPortName,
 			Port:       int32(ctx.Flags.EgClientPort),
-			TargetPort: intstr.IntOrString{IntVal: 2379},
+			TargetPort: intstr.FromInt(ctx.Flags.EgClientPort),
 		},
 	}
 
@@ -69,22 +69,24 @@ func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		},
 	}
 
+	// NOTE: Components in the cluster reach the control plane by the service,
+	// whose admin and peer ports could differ from the ports of the containers.
 	headfulService.Spec.Selector = labels
 	headfulService.Spec.Ports = []v1.ServicePort{
 		{
 			Name:       installbase.ControlPlaneStatefulSetAdminPortName,
-			Port:       int32(ctx.Flags.EgAdminPort),
-			TargetPort: intstr.IntOrString{IntVal: 2381},
+			Port:       int32(ctx.Flags.EgServiceAdminPort),
+			TargetPort: intstr.FromInt(ctx.Flags.EgAdminPort),
 		},
 		{
 			Name:       installbase.ControlPlaneStatefulSetPeerPortName,
-			Port:       int32(ctx.Flags.EgPeerPort),
-			TargetPort: intstr.IntOrString{IntVal: 2380},
+			Port:       int32(ctx.Flags.EgServicePeerPort),
+			TargetPort: intstr.FromInt(ctx.Flags.EgPeerPort),
 		},
 		{
 			Name:       installbase.ControlPlaneStatefulSetClientPortName,
 			Port:       int32(ctx.Flags.EgClientPort),
-			TargetPort: intstr.IntOrString{IntVal: 2379},
+			TargetPort: intstr.FromInt(ctx.Flags.EgClientPort),
 		},
 	}
 
@@ -98,17 +100,19 @@ func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		{
 			Name:       installbase.ControlPlaneStatefulSetAdminPortName,
 			Port:       int32(ctx.Flags.EgAdminPort),
-			TargetPort: intstr.IntOrString{IntVal: 2381},
+			TargetPort: intstr.FromInt(ctx.Flags.EgAdminPort),
+			NodePort:   ctx.Flags.MeshControlPlaneAdminNodePort,
 		},
 		{
 			Name:       installbase.ControlPlaneStatefulSetPeerPortName,
 			Port:       int32(ctx.Flags.EgPeerPort),
-			TargetPort: intstr.IntOrString{IntVal: 2380},
+			TargetPort: intstr.FromInt(ctx.Flags.EgPeerPort),
 		},
 		{
 			Name:       installbase.ControlPlaneStatefulSetClientPortName,
 			Port:       int32(ctx.Flags.EgClientPort),
-			TargetPort: intstr.IntOrString{IntVal: 2379},
+			TargetPort: intstr.FromInt(ctx.Flags.EgClientPort),
+			NodePort:   ctx.Flags.MeshControlPlaneClientNodePort,
 		},
 	}
 
@@ -20,9 +20,7 @@ package controlpanel
 import (
 	"fmt"
 
-	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
-	"github.com/megaease/easemeshctl/cmd/common"
 	"github.com/pkg/errors"
 
 	appsV1 "k8s.io/api/apps/v1"
@@ -31,16 +29,21 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-type statefulsetSpecFunc func(ctx *installbase.StageContext) *appsV1.StatefulSet
+type statefulsetSpecFunc func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error)
 
 func statefulsetSpec(ctx *installbase.StageContext) installbase.InstallFunc {
-	statefulSet := statefulsetPVCSpec(
-		statefulsetContainerSpec(
-			baseStatefulSetSpec(
-				initialStatefulSetSpec(nil))))(ctx)
-
 	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployStatefulset(statefulSet, ctx.Client, ctx.Flags.MeshNamespace)
+		statefulSet, err := statefulsetSecurityProfileSpec(
+			statefulsetZoneSpec(
+				statefulsetPVCSpec(
+					statefulsetContainerSpec(
+						baseStatefulSetSpec(
+							initialStatefulSetSpec(nil))))))(ctx)
+		if err != nil {
+			return errors.Wrap(err, "build statefulset spec failed")
+		}
+
+		err = installbase.DeployStatefulset(statefulSet, ctx.Client, ctx.Flags.MeshNamespace)
 		if err != nil {
 			return errors.Wrapf(err, "deploy statefulset %s failed", statefulSet.ObjectMeta.Name)
 		}
@@ -49,14 +52,17 @@ func statefulsetSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 }
 
 func initialStatefulSetSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.StatefulSet {
-		return &appsV1.StatefulSet{}
+	return func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		return &appsV1.StatefulSet{}, nil
 	}
 }
 
 func baseStatefulSetSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.StatefulSet {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
 		labels := meshControlPlaneLabel()
 		spec.Name = installbase.ControlPlaneStatefulSetName
 		spec.Spec.ServiceName = installbase.ControlPlaneHeadlessServiceName
@@ -80,40 +86,71 @@ func baseStatefulSetSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
 				},
 			},
 		}
-		return spec
+		return spec, nil
 	}
 }
 
 func statefulsetPVCSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.StatefulSet {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ctx.Flags.MeshControlPlaneEphemeralStorage {
+			spec.Spec.Template.Spec.Volumes = append(spec.Spec.Template.Spec.Volumes, v1.Volume{
+				Name: installbase.ControlPlanePVCName,
+				VolumeSource: v1.VolumeSource{
+					EmptyDir: &v1.EmptyDirVolumeSource{},
+				},
+			})
+			return spec, nil
+		}
+
 		pvc := v1.PersistentVolumeClaim{}
 		pvc.Name = installbase.ControlPlanePVCName
 		pvc.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
-		pvc.Spec.StorageClassName = &ctx.Flags.MeshControlPlaneStorageClassName
+		// NOTE: A nil storage class lets the cluster choose its default one,
+		// while an empty one means no storage class.
+		if ctx.Flags.MeshControlPlaneStorageClassName != "" {
+			pvc.Spec.StorageClassName = &ctx.Flags.MeshControlPlaneStorageClassName
+		}
 
 		pvc.Spec.Resources.Requests = v1.ResourceList{
 			v1.ResourceStorage: resource.MustParse(ctx.Flags.MeshControlPlanePersistVolumeCapacity),
 		}
 		spec.Spec.VolumeClaimTemplates = []v1.PersistentVolumeClaim{pvc}
-		return spec
+		return spec, nil
 	}
 }
 
 func statefulsetContainerSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.StatefulSet {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
 		container, err := installbase.AcceptContainerVisitor("easegress",
 			ctx.Flags.ImageRegistryURL+"/"+ctx.Flags.EasegressImage,
 			v1.PullIfNotPresent,
 			newContainerVisistor(ctx))
 		if err != nil {
-			common.ExitWithErrorf("generate mesh controlpanel container spec failed: %s", err)
-			return nil
+			return nil, errors.Wrap(err, "generate mesh controlpanel container spec failed")
 		}
 
 		spec.Spec.Template.Spec.Containers = []v1.Container{*container}
-		return spec
+		return spec, nil
+	}
+}
+
+func statefulsetSecurityProfileSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		installbase.ApplySecurityProfile(ctx.Flags, &spec.Spec.Template.Spec,
+			installbase.EasegressHomeDir(ctx.Flags), installbase.TmpDir)
+		return spec, nil
 	}
 }
 
@@ -140,20 +177,7 @@ func (m *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []str
 }
 
 func (m *containerVisitor) VisitorContainerPorts(c *v1.Container) ([]v1.ContainerPort, error) {
-	return []v1.ContainerPort{
-		{
-			Name:          installbase.ControlPlaneStatefulSetAdminPortName,
-			ContainerPort: flags.DefaultMeshAdminPort,
-		},
-		{
-			Name:          installbase.ControlPlaneStatefulSetClientPortName,
-			ContainerPort: flags.DefaultMeshClientPort,
-		},
-		{
-			Name:          installbase.ControlPlaneStatefulSetPeerPortName,
-			ContainerPort: flags.DefaultMeshPeerPort,
-		},
-	}, nil
+	return installbase.ControlPlaneContainerPorts(m.ctx), nil
 }
 
 func (m *containerVisitor) VisitorEnvs(c *v1.Container) ([]v1.EnvVar, error) {
@@ -196,7 +220,11 @@ func (m *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.Res
 	if err != nil {
 		return nil, err
 	}
-	memoryRequest, err := resource.ParseQuantity("1Gi")
+	memory := "1Gi"
+	if m.ctx.Flags.LowResourceRequests {
+		memory = "256Mi"
+	}
+	memoryRequest, err := resource.ParseQuantity(memory)
 	if err != nil {
 		return nil, err
 	}
@@ -272,8 +300,7 @@ func (m *containerVisitor) VisitorLifeCycle(c *v1.Container) (*v1.Lifecycle, err
 }
 
 func (m *containerVisitor) VisitorSecurityContext(c *v1.Container) (*v1.SecurityContext, error) {
-	// do nothing
-	return nil, nil
+	return installbase.ContainerSecurityContext(m.ctx.Flags), nil
 }
 
 func newContainerVisistor(ctx *installbase.StageContext) installbase.ContainerVisitor {
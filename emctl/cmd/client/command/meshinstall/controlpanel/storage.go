@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+
+	// noProvisioner marks a storage class whose volumes are created manually.
+	noProvisioner = "kubernetes.io/no-provisioner"
+)
+
+// checkStorage checks the control plane gets its volumes. Volumes of a
+// storage class with a provisioner are created on demand, otherwise enough
+// available PersistentVolumes must be created in advance. The default
+// storage class of the cluster is used instead if the storage class isn't
+// specified explicitly and it has no volume, which is the case of dev
+// clusters like kind and minikube.
+func checkStorage(ctx *installbase.StageContext) error {
+	if ctx.Flags.MeshControlPlaneEphemeralStorage {
+		common.Warnf("data of the control plane is lost once its pods restart with --ephemeral-storage")
+		return nil
+	}
+
+	name := ctx.Flags.MeshControlPlaneStorageClassName
+	if name == "" {
+		class, err := defaultStorageClass(ctx.Client)
+		if err != nil {
+			return err
+		}
+		if class == nil {
+			return errors.Errorf("no default storage class found in the cluster, specify one by --storage-class")
+		}
+		common.Infof("control plane uses the default storage class %s", class.Name)
+		return nil
+	}
+
+	class, err := ctx.Client.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "get storage class %s", name)
+	}
+	if err == nil && class.Provisioner != noProvisioner {
+		return nil
+	}
+
+	enough, err := checkPersistentVolumes(ctx)
+	if err != nil || enough {
+		return err
+	}
+
+	if !ctx.Cmd.Flags().Changed("storage-class") && !ctx.Cmd.Flags().Changed("mesh-storage-class-name") {
+		class, err := defaultStorageClass(ctx.Client)
+		if err != nil {
+			return err
+		}
+		if class != nil {
+			common.Warnf("no PersistentVolume of storage class %s available, fall back to the default storage class %s",
+				name, class.Name)
+			ctx.Flags.MeshControlPlaneStorageClassName = class.Name
+			return nil
+		}
+	}
+
+	return errors.Errorf(flags.MeshControlPlanePVNotExistedHelpStr)
+}
+
+// checkPersistentVolumes checks there are enough PersistentVolumes of the
+// storage class available or bound to the control plane.
+func checkPersistentVolumes(ctx *installbase.StageContext) (bool, error) {
+	pvList, err := installbase.ListPersistentVolume(ctx.Client)
+	if err != nil {
+		return false, err
+	}
+
+	availablePVCount := 0
+	quantity := resource.MustParse(ctx.Flags.MeshControlPlanePersistVolumeCapacity)
+	boundedPVCSuffixes := []string{}
+	for i := 0; i < ctx.Flags.EasegressControlPlaneReplicas; i++ {
+		boundedPVCSuffixes = append(boundedPVCSuffixes, fmt.Sprintf("%s-%d", installbase.ControlPlaneStatefulSetName, i))
+	}
+	for _, pv := range pvList.Items {
+		if pv.Status.Phase == v1.VolumeAvailable &&
+			pv.Spec.StorageClassName == ctx.Flags.MeshControlPlaneStorageClassName &&
+			pv.Spec.Capacity.Storage().Cmp(quantity) >= 0 &&
+			checkPVAccessModes(v1.ReadWriteOnce, &pv) {
+			availablePVCount++
+		} else if pv.Status.Phase == v1.VolumeBound {
+			// If PV already bound to PVC of EaseMesh controlpanel
+			// we regarded it as availablePVCount
+			for _, pvNameSuffix := range boundedPVCSuffixes {
+				if pv.Spec.ClaimRef.Kind == "PersistentVolumeClaim" &&
+					pv.Spec.ClaimRef.Namespace == ctx.Flags.MeshNamespace &&
+					strings.HasSuffix(pv.Spec.ClaimRef.Name, pvNameSuffix) {
+					availablePVCount++
+					break
+				}
+			}
+		}
+	}
+
+	return availablePVCount >= ctx.Flags.EasegressControlPlaneReplicas, nil
+}
+
+// defaultStorageClass returns the default storage class of the cluster,
+// or nil if there isn't one.
+func defaultStorageClass(client kubernetes.Interface) (*storagev1.StorageClass, error) {
+	classes, err := client.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list storage classes")
+	}
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		if class.Annotations[defaultStorageClassAnnotation] == "true" ||
+			class.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			return class, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// checkZones checks the control plane keeps its quorum when a zone of
+// --zones is down. Each zone holds exactly one member, so a zone outage
+// loses one member only, which requires at least 3 zones.
+func checkZones(ctx *installbase.StageContext) error {
+	zones := ctx.Flags.MeshControlPlaneZones
+	if len(zones) == 0 {
+		return nil
+	}
+
+	err := validateZones(ctx.Flags)
+	if err != nil {
+		return err
+	}
+
+	for _, zone := range zones {
+		selector := labels.SelectorFromSet(labels.Set{v1.LabelTopologyZone: zone})
+		nodes, err := ctx.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return errors.Wrapf(err, "list nodes of zone %s", zone)
+		}
+		if len(nodes.Items) == 0 {
+			return errors.Errorf("no node labeled %s=%s found in the cluster", v1.LabelTopologyZone, zone)
+		}
+	}
+
+	if ctx.Flags.MeshControlPlaneEphemeralStorage {
+		return nil
+	}
+
+	return checkZonalStorageClass(ctx)
+}
+
+func validateZones(installFlags *flags.Install) error {
+	zones := installFlags.MeshControlPlaneZones
+	seen := map[string]bool{}
+	for _, zone := range zones {
+		if strings.TrimSpace(zone) == "" {
+			return errors.Errorf("--zones contains an empty zone")
+		}
+		if seen[zone] {
+			return errors.Errorf("--zones contains zone %s more than once", zone)
+		}
+		seen[zone] = true
+	}
+
+	if len(zones) < 3 {
+		return errors.Errorf("--zones needs at least 3 zones to keep the quorum of the control plane in a zone outage, got %d", len(zones))
+	}
+	if installFlags.EasegressControlPlaneReplicas != len(zones) {
+		return errors.Errorf("--easemesh-control-plane-replicas (%d) must equal the count of --zones (%d), "+
+			"otherwise a zone holding more members may lose the quorum of the control plane",
+			installFlags.EasegressControlPlaneReplicas, len(zones))
+	}
+
+	return nil
+}
+
+// checkZonalStorageClass checks volumes of the control plane are provisioned
+// in the zones its pods are scheduled to. A volume provisioned at once
+// lands in an arbitrary zone and pins its pod there.
+func checkZonalStorageClass(ctx *installbase.StageContext) error {
+	var class *storagev1.StorageClass
+	var err error
+	if ctx.Flags.MeshControlPlaneStorageClassName == "" {
+		class, err = defaultStorageClass(ctx.Client)
+	} else {
+		class, err = ctx.Client.StorageV1().StorageClasses().Get(context.TODO(),
+			ctx.Flags.MeshControlPlaneStorageClassName, metav1.GetOptions{})
+	}
+	if err != nil || class == nil {
+		// NOTE: Missing storage classes are reported by checkStorage.
+		return nil
+	}
+
+	// NOTE: Pre-created volumes without a provisioner carry their own node affinity.
+	if class.Provisioner == noProvisioner {
+		return nil
+	}
+	if class.VolumeBindingMode == nil || *class.VolumeBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+		return errors.Errorf("storage class %s binds volumes before pods are scheduled, which may put members "+
+			"of the control plane into the same zone, use a storage class with volumeBindingMode %s for --zones",
+			class.Name, storagev1.VolumeBindingWaitForFirstConsumer)
+	}
+
+	return nil
+}
+
+// statefulsetZoneSpec spreads members of the control plane across --zones,
+// the node affinity limits them to the zones and the topology spread
+// puts one member into each zone since the replicas equal the zones.
+func statefulsetZoneSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.StatefulSet, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(ctx.Flags.MeshControlPlaneZones) == 0 {
+			return spec, nil
+		}
+
+		podSpec := &spec.Spec.Template.Spec
+		podSpec.Affinity = &v1.Affinity{
+			NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{
+							MatchExpressions: []v1.NodeSelectorRequirement{
+								{
+									Key:      v1.LabelTopologyZone,
+									Operator: v1.NodeSelectorOpIn,
+									Values:   ctx.Flags.MeshControlPlaneZones,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		podSpec.TopologySpreadConstraints = []v1.TopologySpreadConstraint{
+			{
+				MaxSkew:           1,
+				TopologyKey:       v1.LabelTopologyZone,
+				WhenUnsatisfiable: v1.DoNotSchedule,
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: meshControlPlaneLabel(),
+				},
+			},
+		}
+		return spec, nil
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckZones(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	if err := checkZones(ctx); err != nil {
+		t.Fatalf("expected no check without zones, got %v", err)
+	}
+
+	ctx.Flags.EasegressControlPlaneReplicas = 3
+	for _, zones := range [][]string{
+		{"zone-a", "zone-b"},
+		{"zone-a", "zone-b", "zone-b"},
+		{"zone-a", "zone-b", ""},
+		{"zone-a", "zone-b", "zone-c", "zone-d"},
+	} {
+		ctx.Flags.MeshControlPlaneZones = zones
+		if err := checkZones(ctx); err == nil {
+			t.Fatalf("check zones %v should fail", zones)
+		}
+	}
+
+	ctx.Flags.MeshControlPlaneZones = []string{"zone-a", "zone-b", "zone-c"}
+	for _, zone := range []string{"zone-a", "zone-b"} {
+		client.CoreV1().Nodes().Create(context.TODO(), &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-" + zone,
+				Labels: map[string]string{v1.LabelTopologyZone: zone},
+			},
+		}, metav1.CreateOptions{})
+	}
+	if err := checkZones(ctx); err == nil {
+		t.Fatalf("check zones should fail when zone-c has no node")
+	}
+
+	client.CoreV1().Nodes().Create(context.TODO(), &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-zone-c",
+			Labels: map[string]string{v1.LabelTopologyZone: "zone-c"},
+		},
+	}, metav1.CreateOptions{})
+
+	immediate := storagev1.VolumeBindingImmediate
+	class := &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: ctx.Flags.MeshControlPlaneStorageClassName},
+		Provisioner:       "ebs.csi.aws.com",
+		VolumeBindingMode: &immediate,
+	}
+	client.StorageV1().StorageClasses().Create(context.TODO(), class, metav1.CreateOptions{})
+	if err := checkZones(ctx); err == nil {
+		t.Fatalf("check zones should fail with storage class binding volumes immediately")
+	}
+
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	class.VolumeBindingMode = &waitForFirstConsumer
+	client.StorageV1().StorageClasses().Update(context.TODO(), class, metav1.UpdateOptions{})
+	if err := checkZones(ctx); err != nil {
+		t.Fatalf("check zones failed: %v", err)
+	}
+}
+
+func TestStatefulsetZoneSpec(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	spec, err := statefulsetZoneSpec(baseStatefulSetSpec(initialStatefulSetSpec(nil)))(ctx)
+	if err != nil {
+		t.Fatalf("build statefulset spec failed: %v", err)
+	}
+	if spec.Spec.Template.Spec.Affinity != nil || len(spec.Spec.Template.Spec.TopologySpreadConstraints) != 0 {
+		t.Fatalf("expected no zone spec without zones")
+	}
+
+	ctx.Flags.MeshControlPlaneZones = []string{"zone-a", "zone-b", "zone-c"}
+	spec, err = statefulsetZoneSpec(baseStatefulSetSpec(initialStatefulSetSpec(nil)))(ctx)
+	if err != nil {
+		t.Fatalf("build statefulset spec failed: %v", err)
+	}
+	terms := spec.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || len(terms[0].MatchExpressions[0].Values) != 3 {
+		t.Fatalf("unexpected node affinity %+v", terms)
+	}
+	constraints := spec.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 1 || constraints[0].MaxSkew != 1 ||
+		constraints[0].TopologyKey != v1.LabelTopologyZone ||
+		constraints[0].WhenUnsatisfiable != v1.DoNotSchedule {
+		t.Fatalf("unexpected topology spread constraints %+v", constraints)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"net"
+	"path"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	zookeeperConnTimeout  = "6s"
+	zookeeperSyncInterval = "10s"
+)
+
+// zookeeperRegistryConfig returns the config of the ZooKeeper registry of
+// Dubbo services, nil if no ZooKeeper connection is specified.
+func zookeeperRegistryConfig(installFlags *flags.Install) (*installbase.ZookeeperServiceRegistryConfig, error) {
+	if installFlags.ZookeeperConnection == "" {
+		return nil, nil
+	}
+
+	servers, prefix, err := parseZookeeperConnection(installFlags.ZookeeperConnection, installFlags.ZookeeperPathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &installbase.ZookeeperServiceRegistryConfig{
+		Name:         installbase.ZookeeperServiceRegistryName,
+		Kind:         installbase.ZookeeperServiceRegistryKind,
+		ZKServices:   servers,
+		Prefix:       prefix,
+		ConnTimeout:  zookeeperConnTimeout,
+		SyncInterval: zookeeperSyncInterval,
+	}, nil
+}
+
+// parseZookeeperConnection parses the connection string like
+// zk-0:2181,zk-1:2181/chroot into servers, and maps the path prefix under
+// the optional chroot, as ZooKeeper clients of Dubbo do.
+func parseZookeeperConnection(connection, pathPrefix string) ([]string, string, error) {
+	hosts, chroot := connection, ""
+	if i := strings.Index(connection, "/"); i >= 0 {
+		hosts, chroot = connection[:i], connection[i:]
+	}
+
+	servers := []string{}
+	for _, server := range strings.Split(hosts, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(server); err != nil || port == "" {
+			return nil, "", errors.Errorf("invalid ZooKeeper server %q in --zookeeper-connection, it must be host:port", server)
+		}
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return nil, "", errors.Errorf("no ZooKeeper server in --zookeeper-connection %q", connection)
+	}
+
+	if !strings.HasPrefix(pathPrefix, "/") {
+		return nil, "", errors.Errorf("--zookeeper-path-prefix %q must start with /", pathPrefix)
+	}
+
+	return servers, path.Join("/", chroot, pathPrefix), nil
+}
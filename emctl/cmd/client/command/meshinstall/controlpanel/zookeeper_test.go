@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseZookeeperConnection(t *testing.T) {
+	for _, c := range []struct {
+		connection string
+		prefix     string
+		servers    []string
+		path       string
+	}{
+		{"zk-0:2181", "/dubbo", []string{"zk-0:2181"}, "/dubbo"},
+		{"zk-0:2181, zk-1:2181/legacy", "/dubbo", []string{"zk-0:2181", "zk-1:2181"}, "/legacy/dubbo"},
+		{"10.0.0.1:2181/", "/services/", []string{"10.0.0.1:2181"}, "/services"},
+	} {
+		servers, path, err := parseZookeeperConnection(c.connection, c.prefix)
+		if err != nil {
+			t.Fatalf("parse %s failed: %v", c.connection, err)
+		}
+		if !reflect.DeepEqual(servers, c.servers) || path != c.path {
+			t.Fatalf("parse %s: expected %v %s, got %v %s", c.connection, c.servers, c.path, servers, path)
+		}
+	}
+
+	for _, c := range [][2]string{{"zk-0", "/dubbo"}, {"/chroot", "/dubbo"}, {"zk-0:2181", "dubbo"}} {
+		if _, _, err := parseZookeeperConnection(c[0], c[1]); err == nil {
+			t.Fatalf("parse %s with prefix %s should fail", c[0], c[1])
+		}
+	}
+}
+
+func TestZookeeperRegistryConfig(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	config, err := zookeeperRegistryConfig(ctx.Flags)
+	if err != nil || config != nil {
+		t.Fatalf("expected no registry by default, got %+v, %v", config, err)
+	}
+
+	ctx.Flags.ZookeeperConnection = "zk-0:2181"
+	config, err = zookeeperRegistryConfig(ctx.Flags)
+	if err != nil || config.Prefix != "/dubbo" || config.Kind != "ZookeeperServiceRegistry" {
+		t.Fatalf("unexpected registry %+v, %v", config, err)
+	}
+}
@@ -35,7 +35,7 @@ import (
 var (
 	coreDNSSpecFile = "coredns-old-spec.yaml"
 
-	warnMessage = fmt.Sprintf(`WARN: The process of installation for coredns can't be reverted in $ emctl reset
+	warnMessage = fmt.Sprintf(`The process of installation for coredns can't be reverted in $ emctl reset
 you could use generated file to revert coredns spec by: $ kubectl apply -f %s
 `, coreDNSSpecFile)
 )
@@ -51,17 +51,17 @@ func CoreDNSCmd() *cobra.Command {
 	flags.AttachCmd(cmd)
 
 	cmd.Run = func(cmd *cobra.Command, args []string) {
-		fmt.Println(warnMessage)
+		common.Warnf("%s", warnMessage)
 
 		var err error
 		kubeClient, err := installbase.NewKubernetesClient()
 		if err != nil {
-			common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+			common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 		}
 
 		apiExtensionClient, err := installbase.NewKubernetesAPIExtensionsClient()
 		if err != nil {
-			common.ExitWithErrorf("%s failed: %v", cmd.Short, err)
+			common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
 		}
 
 		ctx := &installbase.StageContext{
@@ -73,11 +73,11 @@ func CoreDNSCmd() *cobra.Command {
 
 		err = storeOldCoreDNS(ctx)
 		if err != nil {
-			fmt.Printf("WARN: store old coredns spec failed: %v\n\n", err)
+			common.Warnf("store old coredns spec failed: %v", err)
 		}
 
 		stages := []installation.InstallStage{
-			installation.Wrap(PreCheck, Deploy, Clear, DescribePhase),
+			installation.Wrap("coredns", PreCheck, Deploy, Clear, DescribePhase),
 		}
 
 		install := installation.New(stages...)
@@ -87,7 +87,7 @@ func CoreDNSCmd() *cobra.Command {
 			if flags.CleanWhenFailed {
 				install.ClearResource(ctx)
 			}
-			common.ExitWithErrorf("install coredns failed: %s", err)
+			common.ExitWithErrorf("install coredns failed: %w", err)
 		}
 	}
 
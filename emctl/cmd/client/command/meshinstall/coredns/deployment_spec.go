@@ -28,14 +28,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-type deploymentSpecFunc func(ctx *installbase.StageContext) *appsV1.Deployment
+type deploymentSpecFunc func(ctx *installbase.StageContext) (*appsV1.Deployment, error)
 
 func coreDNSDeploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
-	deployment := deploymentConfigVolumeSpec(
-		deploymentBaseSpec(deploymentInitialize(nil)))(ctx)
-
 	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployDeployment(deployment, ctx.Client, coreDNSNamespace)
+		deployment, err := deploymentConfigVolumeSpec(
+			deploymentBaseSpec(deploymentInitialize(nil)))(ctx)
+		if err != nil {
+			return errors.Wrap(err, "build deployment spec failed")
+		}
+
+		err = installbase.DeployDeployment(deployment, ctx.Client, coreDNSNamespace)
 		if err != nil {
 			return errors.Wrapf(err, "deployment operation %s failed", deployment.Name)
 		}
@@ -50,14 +53,17 @@ func coreDNSLabels() map[string]string {
 }
 
 func deploymentInitialize(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		return &appsV1.Deployment{}
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		return &appsV1.Deployment{}, nil
 	}
 }
 
 func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
 
 		labels := coreDNSLabels()
 		spec.Name = "coredns"
@@ -99,20 +105,26 @@ func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 			RunAsUser: &v,
 		}
 
-		container, _ := installbase.AcceptContainerVisitor("coredns",
+		container, err := installbase.AcceptContainerVisitor("coredns",
 			ctx.CoreDNSFlags.Image,
 			v1.PullIfNotPresent,
 			newVisitor(ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate container spec failed")
+		}
 
 		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, *container)
-		return spec
+		return spec, nil
 	}
 }
 
 func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 	var defaultMode int32 = 420
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
 		spec.Spec.Template.Spec.Volumes = []v1.Volume{
 			{
 				Name: "config-volume",
@@ -132,7 +144,7 @@ func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 				},
 			},
 		}
-		return spec
+		return spec, nil
 	}
 }
 
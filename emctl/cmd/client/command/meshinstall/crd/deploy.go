@@ -24,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 	apiExtensionsV1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	clientGoScheme "k8s.io/client-go/kubernetes/scheme"
@@ -32,6 +33,9 @@ import (
 //go:embed  crd.yaml
 var easemeshDeploymentCRD []byte
 
+//go:embed meshcontrolplane_crd.yaml
+var easemeshControlPlaneCRD []byte
+
 // Deploy deploy resources of crd
 func Deploy(context *installbase.StageContext) error {
 	crd, err := getCRDSpec(easemeshDeploymentCRD)
@@ -39,6 +43,23 @@ func Deploy(context *installbase.StageContext) error {
 		return err
 	}
 
+	// NOTE: Keep the conversion webhook set by the operator stage, otherwise
+	// MeshDeployments of other versions can't be served until it's set again.
+	old, err := context.APIExtensionsClient.ApiextensionsV1().CustomResourceDefinitions().
+		Get(context.Stage(), crd.Name, metav1.GetOptions{})
+	if err == nil && old.Spec.Conversion != nil && old.Spec.Conversion.Strategy == apiExtensionsV1.WebhookConverter {
+		crd.Spec.Conversion = old.Spec.Conversion
+	}
+
+	err = installbase.DeployCustomResourceDefinition(crd, context.APIExtensionsClient)
+	if err != nil {
+		return errors.Wrapf(err, "can't deploy CRD %s", crd.Name)
+	}
+
+	crd, err = getCRDSpec(easemeshControlPlaneCRD)
+	if err != nil {
+		return err
+	}
 	err = installbase.DeployCustomResourceDefinition(crd, context.APIExtensionsClient)
 	if err != nil {
 		return errors.Wrapf(err, "can't deploy CRD %s", crd.Name)
@@ -53,11 +74,17 @@ func PreCheck(context *installbase.StageContext) error {
 
 // Clear will clear all installed resource about control panel
 func Clear(context *installbase.StageContext) error {
-	crd, err := getCRDSpec(easemeshDeploymentCRD)
-	if err != nil {
-		return err
+	for _, spec := range [][]byte{easemeshControlPlaneCRD, easemeshDeploymentCRD} {
+		crd, err := getCRDSpec(spec)
+		if err != nil {
+			return err
+		}
+		err = installbase.DeleteCRDResource(context.APIExtensionsClient, crd.Name)
+		if err != nil {
+			return err
+		}
 	}
-	return installbase.DeleteCRDResource(context.APIExtensionsClient, crd.Name)
+	return nil
 }
 
 // DescribePhase leverage human-readable text to describe different phase
@@ -65,9 +92,9 @@ func Clear(context *installbase.StageContext) error {
 func DescribePhase(context *installbase.StageContext, phase installbase.InstallPhase) string {
 	switch phase {
 	case installbase.BeginPhase:
-		return "Begin to deploy CRD meshdeployment\n"
+		return "Begin to deploy CRD meshdeployment and meshcontrolplane\n"
 	case installbase.EndPhase:
-		return "CustomeResourceDefine meshdeployment deployed successfully\n"
+		return "CustomeResourceDefine meshdeployment and meshcontrolplane deployed successfully\n"
 	}
 	return ""
 }
@@ -25,7 +25,9 @@ import (
 	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
 
 	"github.com/spf13/cobra"
+	apiExtensionsV1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -43,6 +45,43 @@ func TestDeploy(t *testing.T) {
 	Deploy(prepareContext())
 }
 
+func TestDeployKeepsConversionWebhook(t *testing.T) {
+	ctx := prepareContext()
+	err := Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy CRD failed: %v", err)
+	}
+
+	crds := ctx.APIExtensionsClient.ApiextensionsV1().CustomResourceDefinitions()
+	crd, err := crds.Get(ctx.Stage(), installbase.MeshDeploymentCRDName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get CRD failed: %v", err)
+	}
+	if len(crd.Spec.Versions) != 2 || crd.Spec.Versions[0].Name != "v1" || !crd.Spec.Versions[0].Storage {
+		t.Fatalf("v1 should be the storage version besides v1beta1, but got %+v", crd.Spec.Versions)
+	}
+
+	_, err = crds.Get(ctx.Stage(), installbase.MeshControlPlaneCRDName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get CRD of MeshControlPlane failed: %v", err)
+	}
+
+	crd.Spec.Conversion = &apiExtensionsV1.CustomResourceConversion{Strategy: apiExtensionsV1.WebhookConverter}
+	_, err = crds.Update(ctx.Stage(), crd, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("update CRD failed: %v", err)
+	}
+
+	err = Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy CRD again failed: %v", err)
+	}
+	crd, _ = crds.Get(ctx.Stage(), installbase.MeshDeploymentCRDName, metav1.GetOptions{})
+	if crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy != apiExtensionsV1.WebhookConverter {
+		t.Fatalf("the conversion webhook should be kept, but got %+v", crd.Spec.Conversion)
+	}
+}
+
 func TestDescribePhase(t *testing.T) {
 	ctx := prepareContext()
 	DescribePhase(ctx, installbase.BeginPhase)
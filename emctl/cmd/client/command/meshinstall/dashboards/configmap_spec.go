@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dashboards
+
+import (
+	_ "embed"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/pkg/errors"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: Panels of the operator dashboard query metrics of pkg/metrics of the operator.
+//
+//go:embed operator.json
+var operatorDashboard string
+
+func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.DashboardsConfigMapName,
+			Namespace: ctx.Flags.MeshNamespace,
+			Labels: map[string]string{
+				installbase.DashboardsLabelKey: "1",
+			},
+		},
+		Data: map[string]string{
+			"easemesh-operator.json": operatorDashboard,
+		},
+	}
+
+	return func(ctx *installbase.StageContext) error {
+		err := installbase.DeployConfigMap(configMap, ctx.Client, ctx.Flags.MeshNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy ConfigMap %s failed", configMap.Name)
+		}
+		return nil
+	}
+}
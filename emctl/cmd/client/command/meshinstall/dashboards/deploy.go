@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dashboards
+
+import (
+	"fmt"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+)
+
+// Deploy deploy the config map of Grafana dashboards
+func Deploy(ctx *installbase.StageContext) error {
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		configMapSpec(ctx),
+	})
+}
+
+// PreCheck check prerequisite for installing Grafana dashboards
+func PreCheck(context *installbase.StageContext) error {
+	return nil
+}
+
+// Clear will clear all installed resource about Grafana dashboards
+func Clear(context *installbase.StageContext) error {
+	coreV1Resources := [][]string{
+		{"configmaps", installbase.DashboardsConfigMapName},
+	}
+
+	installbase.DeleteResources(context.Client, coreV1Resources, context.Flags.MeshNamespace, installbase.DeleteCoreV1Resource)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of Grafana dashboards
+func DescribePhase(context *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to install Grafana dashboards in the namespace:%s", context.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nGrafana dashboards deployed successfully, configmap:%s\n"+
+			"Grafana loads them by its dashboard sidecar watching config maps labeled %s=1 in the namespace %s",
+			installbase.DashboardsConfigMapName, installbase.DashboardsLabelKey, context.Flags.MeshNamespace)
+	}
+	return ""
+}
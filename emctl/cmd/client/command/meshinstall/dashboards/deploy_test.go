@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset, *extensionfake.Clientset) {
+	client := meshtesting.NewFakeClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client, extensionClient
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client, _ := prepareContext()
+
+	err := Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(ctx.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.DashboardsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get configmap failed: %v", err)
+	}
+	if configMap.Labels[installbase.DashboardsLabelKey] != "1" {
+		t.Errorf("configmap should be labeled for the dashboard sidecar, got %v", configMap.Labels)
+	}
+
+	metrics := map[string][]string{
+		"easemesh-operator.json": {
+			"easemesh_operator_sidecar_injections_total",
+			"easemesh_operator_sidecar_injection_duration_seconds_bucket",
+			"easemesh_operator_errors_total",
+		},
+	}
+	for key, names := range metrics {
+		dashboard := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(configMap.Data[key]), &dashboard); err != nil {
+			t.Fatalf("dashboard %s is invalid: %v", key, err)
+		}
+		if uid, _ := dashboard["uid"].(string); uid == "" {
+			t.Errorf("dashboard %s has no uid", key)
+		}
+		for _, name := range names {
+			if !strings.Contains(configMap.Data[key], name) {
+				t.Errorf("dashboard %s doesn't query %s", key, name)
+			}
+		}
+	}
+
+	Clear(ctx)
+	_, err = client.CoreV1().ConfigMaps(ctx.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.DashboardsConfigMapName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the configmap is cleared, got %v", err)
+	}
+}
+
+func TestDescribePhase(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	DescribePhase(ctx, installbase.ErrorPhase)
+}
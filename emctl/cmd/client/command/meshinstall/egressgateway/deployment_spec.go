@@ -0,0 +1,230 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package egressgateway
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type deploymentSpecFunc func(*installbase.StageContext) (*appsV1.Deployment, error)
+
+func egressGatewayLabel() map[string]string {
+	selector := map[string]string{}
+	selector["app"] = installbase.EgressGatewayDeploymentName
+	return selector
+}
+
+func deploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		deployment, err := deploymentSecurityProfileSpec(
+			deploymentConfigVolumeSpec(
+				deploymentContainerSpec(
+					deploymentBaseSpec(
+						deploymentInitialize(nil)))))(ctx)
+		if err != nil {
+			return errors.Wrap(err, "build deployment spec failed")
+		}
+
+		err = installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy %s failed", deployment.Name)
+		}
+		return err
+	}
+}
+
+func deploymentInitialize(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		return &appsV1.Deployment{}, nil
+	}
+}
+
+func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		spec.Name = installbase.EgressGatewayDeploymentName
+		spec.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: egressGatewayLabel(),
+		}
+
+		replicas := int32(ctx.Flags.MeshEgressReplicas)
+		spec.Spec.Replicas = &replicas
+		spec.Spec.Template.Labels = egressGatewayLabel()
+		spec.Spec.Template.Spec.Containers = []v1.Container{}
+		return spec, nil
+	}
+}
+
+func deploymentContainerSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		container, err := installbase.AcceptContainerVisitor(installbase.EgressGatewayDeploymentName,
+			ctx.Flags.ImageRegistryURL+"/"+ctx.Flags.EasegressImage,
+			v1.PullIfNotPresent,
+			newVisitor(ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate container spec failed")
+		}
+
+		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, *container)
+		return spec, nil
+	}
+}
+
+func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		spec.Spec.Template.Spec.Volumes = []v1.Volume{
+			{
+				Name: installbase.EgressGatewayConfigMapName,
+				VolumeSource: v1.VolumeSource{
+					ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: installbase.EgressGatewayConfigMapName,
+						},
+					},
+				},
+			},
+		}
+		return spec, nil
+	}
+}
+
+func deploymentSecurityProfileSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		installbase.ApplySecurityProfile(ctx.Flags, &spec.Spec.Template.Spec,
+			installbase.EasegressHomeDir(ctx.Flags), installbase.TmpDir)
+		return spec, nil
+	}
+}
+
+type containerVisitor struct {
+	ctx *installbase.StageContext
+}
+
+func newVisitor(ctx *installbase.StageContext) installbase.ContainerVisitor {
+	return &containerVisitor{ctx}
+}
+
+func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []string, args []string) {
+	return []string{"/bin/sh"},
+		[]string{"-c", installbase.EgressGatewayDeploymentCmd}
+}
+
+func (v *containerVisitor) VisitorContainerPorts(c *v1.Container) ([]v1.ContainerPort, error) {
+	return installbase.ControlPlaneContainerPorts(v.ctx), nil
+}
+
+func (v *containerVisitor) VisitorEnvs(c *v1.Container) ([]v1.EnvVar, error) {
+	return []v1.EnvVar{
+		{
+			Name: "EG_NAME",
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name: "HOSTNAME",
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name: "APPLICATION_IP",
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{
+					FieldPath: "status.podIP",
+				},
+			},
+		},
+	}, nil
+}
+
+func (v *containerVisitor) VisitorEnvFrom(c *v1.Container) ([]v1.EnvFromSource, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.ResourceRequirements, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMount, error) {
+	return []v1.VolumeMount{
+		{
+			Name:      installbase.EgressGatewayConfigMapName,
+			MountPath: installbase.EgressGatewayConfigMapVolumeMountPath,
+			SubPath:   installbase.EgressGatewayConfigMapVolumeMountSubPath,
+		},
+	}, nil
+}
+
+func (v *containerVisitor) VisitorVolumeDevices(c *v1.Container) ([]v1.VolumeDevice, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorLivenessProbe(c *v1.Container) (*v1.Probe, error) {
+	/* FIXME: K8s probe report connection reset, but the port can be accessed via localhost/127.0.0.1
+	maybe the default admin API port should listen on all interface instead of loopback address.
+
+	return &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{
+				Host: "localhost",
+				Port: intstr.FromInt(v.ctx.Flags.EgAdminPort),
+				Path: "/apis/v1/healthz",
+			},
+		},
+		InitialDelaySeconds: 50,
+	}, nil
+	*/
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorReadinessProbe(c *v1.Container) (*v1.Probe, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorLifeCycle(c *v1.Container) (*v1.Lifecycle, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorSecurityContext(c *v1.Container) (*v1.SecurityContext, error) {
+	return installbase.ContainerSecurityContext(v.ctx.Flags), nil
+}
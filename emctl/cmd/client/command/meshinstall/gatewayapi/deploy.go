@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+
+	gatewayAPIClusterRole        = "mesh-operator-gateway-api-role"
+	gatewayAPIClusterRoleBinding = "mesh-operator-gateway-api-rolebinding"
+)
+
+// requiredCRDs are the Gateway API CRDs the operator watches.
+var requiredCRDs = []string{
+	"gateways." + gatewayAPIGroup,
+	"httproutes." + gatewayAPIGroup,
+}
+
+// Deploy deploys the resources which grant the operator access to Gateway API resources
+func Deploy(ctx *installbase.StageContext) error {
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		clusterRoleSpec(ctx),
+		clusterRoleBindingSpec(ctx),
+	})
+}
+
+// PreCheck checks whether the Gateway API CRDs have been installed in the cluster
+func PreCheck(ctx *installbase.StageContext) error {
+	for _, name := range requiredCRDs {
+		_, err := ctx.APIExtensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "Gateway API CRD %s not found, please install Gateway API CRDs first", name)
+		}
+	}
+	return nil
+}
+
+// Clear clears all k8s resources about the Gateway API ingress source
+func Clear(ctx *installbase.StageContext) error {
+	rbacV1Resources := [][]string{
+		{"clusterrolebindings", gatewayAPIClusterRoleBinding},
+		{"clusterroles", gatewayAPIClusterRole},
+	}
+	installbase.DeleteResources(ctx.Client, rbacV1Resources, ctx.Flags.MeshNamespace, installbase.DeleteRbacV1Resources)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of enabling the Gateway API ingress source
+func DescribePhase(ctx *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to enable Gateway API ingress source in the namespace: %s", ctx.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nGateway API ingress source enabled successfully, cluster role: %s\n"+
+			"HTTPRoutes attached to Gateways of class %q will be translated into mesh ingresses", gatewayAPIClusterRole, installbase.GatewayClassName)
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gatewayapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset, *extensionfake.Clientset) {
+	client := meshtesting.NewFakeClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client, extensionClient
+}
+
+func TestPreCheck(t *testing.T) {
+	ctx, _, extensionClient := prepareContext()
+	if PreCheck(ctx) == nil {
+		t.Fatalf("pre check should fail when Gateway API CRDs are absent")
+	}
+
+	for _, name := range requiredCRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		_, err := extensionClient.ApiextensionsV1().CustomResourceDefinitions().Create(context.TODO(), crd, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("create crd %s failed: %v", name, err)
+		}
+	}
+
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check should succeed, but got %v", err)
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	if err := Deploy(ctx); err != nil {
+		t.Fatalf("deploy Gateway API ingress source failed: %v", err)
+	}
+
+	_, err := client.RbacV1().ClusterRoles().Get(context.TODO(), gatewayAPIClusterRole, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("cluster role %s should be deployed: %v", gatewayAPIClusterRole, err)
+	}
+
+	Clear(ctx)
+	_, err = client.RbacV1().ClusterRoles().Get(context.TODO(), gatewayAPIClusterRole, metav1.GetOptions{})
+	if err == nil {
+		t.Fatalf("cluster role %s should be cleared", gatewayAPIClusterRole)
+	}
+}
+
+func TestDescribePhase(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	DescribePhase(ctx, installbase.ErrorPhase)
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Deploy deploy resources of GitOps controller
+func Deploy(ctx *installbase.StageContext) error {
+	err := installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		secretSpec(ctx),
+		serviceSpec(ctx),
+		deploymentSpec(ctx),
+	})
+	if err != nil {
+		return err
+	}
+
+	return checkGitOpsControllerStatus(ctx.Client, ctx.Flags)
+}
+
+// PreCheck check prerequisite for installing GitOps controller
+func PreCheck(context *installbase.StageContext) error {
+	if context.Flags.GitOpsRepo == "" {
+		return errors.Errorf("add-on gitops requires the Git repository specified by --gitops-repo")
+	}
+	if context.Flags.GitOpsInterval <= 0 {
+		return errors.Errorf("invalid --gitops-interval %s", context.Flags.GitOpsInterval)
+	}
+	return nil
+}
+
+// Clear will clear all installed resource about GitOps controller
+func Clear(context *installbase.StageContext) error {
+	appsV1Resources := [][]string{
+		{"deployments", installbase.GitOpsControllerDeploymentName},
+	}
+	coreV1Resources := [][]string{
+		{"services", installbase.GitOpsControllerServiceName},
+		{"secrets", installbase.GitOpsControllerSecretName},
+	}
+
+	installbase.DeleteResources(context.Client, appsV1Resources, context.Flags.MeshNamespace, installbase.DeleteAppsV1Resource)
+	installbase.DeleteResources(context.Client, coreV1Resources, context.Flags.MeshNamespace, installbase.DeleteCoreV1Resource)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of the GitOps controller
+func DescribePhase(context *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to install GitOps controller syncing %s in the namespace:%s",
+			context.Flags.GitOpsRepo, context.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nGitOps controller deployed successfully, deployment:%s\n"+
+			"Webhooks could be sent to http://%s.%s:%d/webhook, sync status is served at /status\n%s",
+			installbase.GitOpsControllerDeploymentName,
+			installbase.GitOpsControllerServiceName, context.Flags.MeshNamespace, context.Flags.GitOpsWebhookPort,
+			installbase.FormatPodStatus(context.Client, context.Flags.MeshNamespace,
+				installbase.AdaptListPodFunc(gitOpsControllerLabel())))
+	}
+	return ""
+}
+
+func checkGitOpsControllerStatus(client kubernetes.Interface, installFlags *flags.Install) error {
+	i := 0
+	for {
+		time.Sleep(time.Millisecond * 100)
+		i++
+		if i > 600 {
+			return errors.Errorf("easeMesh GitOps controller deploy failed, GitOps controller (deployment) not ready")
+		}
+		ready, err := installbase.CheckDeploymentResourceStatus(client, installFlags.MeshNamespace,
+			installbase.GitOpsControllerDeploymentName,
+			installbase.DeploymentReadyPredict)
+		if ready {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gitops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	appsV1 "k8s.io/api/apps/v1"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset, *extensionfake.Clientset) {
+	client := meshtesting.NewFakeClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	install.GitOpsRepo = "https://github.com/megaease/easemesh-gitops.git"
+	install.GitOpsWebhookSecret = "s3cret"
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client, extensionClient
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client, _ := prepareContext()
+
+	client.PrependReactor("get", "deployments", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+		var replicas int32 = 1
+		return true, &appsV1.Deployment{
+			Spec: appsV1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+			Status: appsV1.DeploymentStatus{
+				ReadyReplicas: replicas,
+			},
+		}, nil
+	})
+
+	if err := Deploy(ctx); err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+}
+
+func TestDescribePhase(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	DescribePhase(ctx, installbase.ErrorPhase)
+	PreCheck(ctx)
+}
+
+func TestPreCheck(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check failed: %v", err)
+	}
+
+	ctx.Flags.GitOpsRepo = ""
+	if err := PreCheck(ctx); err == nil {
+		t.Fatalf("pre check without repo should fail")
+	}
+}
+
+func TestClear(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	for _, f := range []func(*installbase.StageContext) installbase.InstallFunc{
+		secretSpec, serviceSpec, deploymentSpec,
+	} {
+		if err := f(ctx).Deploy(ctx); err != nil {
+			t.Fatalf("deploy failed: %v", err)
+		}
+	}
+
+	if err := Clear(ctx); err != nil {
+		t.Fatalf("clear failed: %v", err)
+	}
+
+	_, err := client.AppsV1().Deployments(ctx.Flags.MeshNamespace).Get(context.Background(),
+		installbase.GitOpsControllerDeploymentName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the deployment cleared, got %v", err)
+	}
+	_, err = client.CoreV1().Secrets(ctx.Flags.MeshNamespace).Get(context.Background(),
+		installbase.GitOpsControllerSecretName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the secret cleared, got %v", err)
+	}
+}
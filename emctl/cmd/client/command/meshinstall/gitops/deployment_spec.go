@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	gitOpsWorkDir       = "/var/lib/easemesh-gitops"
+	gitOpsWorkDirName   = "work-dir"
+	webhookSecretEnv    = "EASEMESH_GITOPS_WEBHOOK_SECRET"
+	gitOpsContainerName = "gitops-controller"
+)
+
+type deploymentSpecFunc func(*flags.Install) (*appsV1.Deployment, error)
+
+func gitOpsControllerLabel() map[string]string {
+	selector := map[string]string{}
+	selector["app"] = "easemesh-gitops-controller"
+	return selector
+}
+
+func deploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		deployment, err := deploymentContainerSpec(
+			deploymentBaseSpec(
+				deploymentInitialize(nil)))(ctx.Flags)
+		if err != nil {
+			return errors.Wrap(err, "build deployment spec failed")
+		}
+
+		err = installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "deployment operation %s failed", deployment.Name)
+		}
+		return err
+	}
+}
+
+func deploymentInitialize(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(installFlags *flags.Install) (*appsV1.Deployment, error) {
+		return &appsV1.Deployment{}, nil
+	}
+}
+
+func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(installFlags *flags.Install) (*appsV1.Deployment, error) {
+		spec, err := fn(installFlags)
+		if err != nil {
+			return nil, err
+		}
+		spec.Name = installbase.GitOpsControllerDeploymentName
+		spec.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: gitOpsControllerLabel(),
+		}
+
+		// NOTE: Only one controller syncs, or they would race to apply.
+		var replicas int32 = 1
+		spec.Spec.Replicas = &replicas
+		spec.Spec.Strategy.Type = appsV1.RecreateDeploymentStrategyType
+
+		spec.Spec.Template.Labels = gitOpsControllerLabel()
+		spec.Spec.Template.Spec.Containers = []v1.Container{}
+		spec.Spec.Template.Spec.Volumes = []v1.Volume{
+			{
+				Name: gitOpsWorkDirName,
+				VolumeSource: v1.VolumeSource{
+					EmptyDir: &v1.EmptyDirVolumeSource{},
+				},
+			},
+		}
+		return spec, nil
+	}
+}
+
+func deploymentContainerSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(installFlags *flags.Install) (*appsV1.Deployment, error) {
+		spec, err := fn(installFlags)
+		if err != nil {
+			return nil, err
+		}
+		container, err := installbase.AcceptContainerVisitor(gitOpsContainerName,
+			installFlags.ImageRegistryURL+"/"+installFlags.GitOpsControllerImage,
+			v1.PullIfNotPresent,
+			newVisitor(installFlags))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate container spec failed")
+		}
+
+		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, *container)
+		return spec, nil
+	}
+}
+
+type containerVisitor struct {
+	installFlags *flags.Install
+}
+
+func newVisitor(installFlags *flags.Install) installbase.ContainerVisitor {
+	return &containerVisitor{installFlags}
+}
+
+func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []string, installFlags []string) {
+	meshServer := fmt.Sprintf("%s.%s:%d", v.installFlags.EgServiceName, v.installFlags.MeshNamespace, v.installFlags.EgServiceAdminPort)
+	args := []string{
+		"gitops", "serve",
+		"--server", meshServer,
+		"--repo", v.installFlags.GitOpsRepo,
+		"--branch", v.installFlags.GitOpsBranch,
+		"--path", v.installFlags.GitOpsPath,
+		"--interval", v.installFlags.GitOpsInterval.String(),
+		"--work-dir", gitOpsWorkDir,
+		"--listen-address", fmt.Sprintf(":%d", v.installFlags.GitOpsWebhookPort),
+	}
+	// NOTE: The image entrypoint is emctl.
+	return nil, args
+}
+
+func (v *containerVisitor) VisitorContainerPorts(c *v1.Container) ([]v1.ContainerPort, error) {
+	return []v1.ContainerPort{
+		{
+			Name:          "webhook",
+			ContainerPort: v.installFlags.GitOpsWebhookPort,
+			Protocol:      v1.ProtocolTCP,
+		},
+	}, nil
+}
+
+func (v *containerVisitor) VisitorEnvs(c *v1.Container) ([]v1.EnvVar, error) {
+	optional := true
+	return []v1.EnvVar{
+		{
+			Name: webhookSecretEnv,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: installbase.GitOpsControllerSecretName},
+					Key:                  installbase.GitOpsControllerSecretKey,
+					Optional:             &optional,
+				},
+			},
+		},
+	}, nil
+}
+
+func (v *containerVisitor) VisitorEnvFrom(c *v1.Container) ([]v1.EnvFromSource, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.ResourceRequirements, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMount, error) {
+	return []v1.VolumeMount{
+		{
+			Name:      gitOpsWorkDirName,
+			MountPath: gitOpsWorkDir,
+		},
+	}, nil
+}
+
+func (v *containerVisitor) VisitorVolumeDevices(c *v1.Container) ([]v1.VolumeDevice, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorLivenessProbe(c *v1.Container) (*v1.Probe, error) {
+	return &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path:   "/healthz",
+				Port:   intstr.FromInt(int(v.installFlags.GitOpsWebhookPort)),
+				Scheme: "HTTP",
+			},
+		},
+		InitialDelaySeconds: 15,
+		PeriodSeconds:       20,
+	}, nil
+}
+
+func (v *containerVisitor) VisitorReadinessProbe(c *v1.Container) (*v1.Probe, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorLifeCycle(c *v1.Container) (*v1.Lifecycle, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorSecurityContext(c *v1.Container) (*v1.SecurityContext, error) {
+	return nil, nil
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+func secretSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	secret := &v1.Secret{}
+	secret.Name = installbase.GitOpsControllerSecretName
+	secret.Labels = gitOpsControllerLabel()
+	secret.StringData = map[string]string{
+		installbase.GitOpsControllerSecretKey: ctx.Flags.GitOpsWebhookSecret,
+	}
+
+	return func(ctx *installbase.StageContext) error {
+		// NOTE: The controller refers to the secret optionally,
+		// no secret means webhooks are not verified.
+		if ctx.Flags.GitOpsWebhookSecret == "" {
+			return nil
+		}
+
+		err := installbase.DeploySecret(secret, ctx.Client, ctx.Flags.MeshNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy secret %s failed", secret.Name)
+		}
+		return nil
+	}
+}
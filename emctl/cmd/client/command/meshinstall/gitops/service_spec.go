@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gitops
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	service := &v1.Service{}
+	service.Name = installbase.GitOpsControllerServiceName
+
+	service.Spec.Ports = []v1.ServicePort{
+		{
+			Name:       "webhook",
+			Port:       ctx.Flags.GitOpsWebhookPort,
+			Protocol:   v1.ProtocolTCP,
+			TargetPort: intstr.IntOrString{IntVal: ctx.Flags.GitOpsWebhookPort},
+		},
+	}
+	service.Spec.Selector = gitOpsControllerLabel()
+	// NOTE: Expose it by an ingress if webhooks come from outside of the cluster.
+	service.Spec.Type = v1.ServiceTypeClusterIP
+	return func(ctx *installbase.StageContext) error {
+		err := installbase.DeployService(service, ctx.Client, ctx.Flags.MeshNamespace)
+		return err
+	}
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type (
+	// componentImage is the image flag of an installed component.
+	componentImage struct {
+		component string
+		image     *string
+		// signed images are verified if --cosign-key is specified.
+		signed bool
+	}
+
+	// imageRecord records the digest of an installed image.
+	imageRecord struct {
+		Component string `yaml:"component"`
+		Image     string `yaml:"image"`
+		Digest    string `yaml:"digest,omitempty"`
+		Verified  bool   `yaml:"verified"`
+	}
+)
+
+// cosignCommand is the command verifying signatures of images.
+var cosignCommand = "cosign"
+
+// componentImages returns images of the components to be installed,
+// every image flag is returned once.
+func componentImages(installFlags *flags.Install) []componentImage {
+	images := []componentImage{}
+	if !installFlags.OnlyAddOn {
+		images = append(images,
+			componentImage{"easegress", &installFlags.EasegressImage, true},
+			componentImage{"operator", &installFlags.EaseMeshOperatorImage, true},
+		)
+		if installFlags.TrafficInterception != "" {
+			images = append(images, componentImage{"interception", &installFlags.InterceptionImage, false})
+		}
+	}
+
+	for _, addon := range installFlags.AddOns {
+		switch strings.ToLower(addon) {
+		case "shadowservice":
+			images = append(images, componentImage{"shadowservice", &installFlags.ShadowServiceControllerImage, false})
+		case "egressgateway":
+			images = append(images, componentImage{"easegress", &installFlags.EasegressImage, true})
+		case "gitops":
+			images = append(images, componentImage{"gitops", &installFlags.GitOpsControllerImage, false})
+		case "maintenance":
+			images = append(images, componentImage{"maintenance", &installFlags.MaintenanceImage, false})
+		}
+	}
+
+	result := []componentImage{}
+	added := map[*string]bool{}
+	for _, image := range images {
+		if added[image.image] {
+			continue
+		}
+		added[image.image] = true
+		result = append(result, image)
+	}
+	return result
+}
+
+// PreCheck checks cosign is available for verifying signatures.
+func PreCheck(context *installbase.StageContext) error {
+	if context.Flags.CosignKey == "" {
+		return nil
+	}
+
+	_, err := exec.LookPath(cosignCommand)
+	if err != nil {
+		return errors.Wrapf(err, "%s is required by --cosign-key", cosignCommand)
+	}
+
+	// NOTE: Keys of KMS like awskms:// or k8s:// are checked by cosign.
+	if !strings.Contains(context.Flags.CosignKey, "://") {
+		_, err = os.Stat(context.Flags.CosignKey)
+		if err != nil {
+			return errors.Wrap(err, "check --cosign-key")
+		}
+	}
+	return nil
+}
+
+// Deploy pins images of the components to be installed to their digests and
+// verifies their signatures, then records them. The image flags are changed
+// to the pinned ones, so stages following deploy images by digests.
+func Deploy(ctx *installbase.StageContext) error {
+	records := []*imageRecord{}
+	for _, c := range componentImages(ctx.Flags) {
+		record, err := pinImage(ctx.Stage(), ctx.Flags, c)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		configMapSpec(ctx, records),
+	})
+}
+
+func pinImage(ctx context.Context, installFlags *flags.Install, c componentImage) (*imageRecord, error) {
+	record := &imageRecord{
+		Component: c.component,
+		Image:     installFlags.ImageRegistryURL + "/" + *c.image,
+	}
+
+	pinned := *c.image
+	if installFlags.PinDigests {
+		digest, err := registry.resolveDigest(ctx, installFlags.ImageRegistryURL, *c.image)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolve digest of %s", record.Image)
+		}
+		repository, _ := splitImage(*c.image)
+		record.Digest, pinned = digest, repository+"@"+digest
+	}
+
+	if installFlags.CosignKey != "" && c.signed {
+		image := installFlags.ImageRegistryURL + "/" + pinned
+		cmd := exec.CommandContext(ctx, cosignCommand, "verify", "--key", installFlags.CosignKey, image)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, errors.Wrapf(err, "verify signature of %s: %s", image, strings.TrimSpace(string(output)))
+		}
+		record.Verified = true
+	}
+
+	if pinned != *c.image {
+		common.Infof("Pinned %s to %s", record.Image, record.Digest)
+		*c.image = pinned
+	}
+	return record, nil
+}
+
+func configMapSpec(ctx *installbase.StageContext, records []*imageRecord) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		buff, err := yaml.Marshal(records)
+		if err != nil {
+			return errors.Wrap(err, "marshal image digests")
+		}
+
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      installbase.ImageDigestsConfigMapName,
+				Namespace: ctx.Flags.MeshNamespace,
+			},
+			Data: map[string]string{
+				installbase.ImageDigestsConfigMapKey: string(buff),
+			},
+		}
+		return installbase.DeployConfigMap(configMap, ctx.Client, ctx.Flags.MeshNamespace)
+	}
+}
+
+// Clear will clear the record of image digests
+func Clear(context *installbase.StageContext) error {
+	coreV1Resources := [][]string{
+		{"configmaps", installbase.ImageDigestsConfigMapName},
+	}
+
+	installbase.DeleteResources(context.Client, coreV1Resources, context.Flags.MeshNamespace, installbase.DeleteCoreV1Resource)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of pinning images
+func DescribePhase(context *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return "Begin to pin digests and verify signatures of images"
+	case installbase.EndPhase:
+		images := []string{}
+		for _, c := range componentImages(context.Flags) {
+			images = append(images, context.Flags.ImageRegistryURL+"/"+*c.image)
+		}
+		return fmt.Sprintf("\nImages recorded in the configmap %s/%s:\n%s",
+			context.Flags.MeshNamespace, installbase.ImageDigestsConfigMapName, strings.Join(images, "\n"))
+	}
+	return ""
+}
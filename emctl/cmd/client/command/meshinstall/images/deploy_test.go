@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset) {
+	client := meshtesting.NewFakeClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client
+}
+
+// fakeRegistry serves manifests with the test digest, which requires tokens from its realm.
+func fakeRegistry(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:megaease/easegress:pull" {
+				t.Errorf("unexpected scope %s", r.URL.Query().Get("scope"))
+			}
+			w.Write([]byte(`{"token": "anonymous"}`))
+		case r.Header.Get("Authorization") != "Bearer anonymous":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:megaease/easegress:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodHead && strings.HasPrefix(r.URL.Path, "/v2/megaease/easegress/manifests/"):
+			w.Header().Set("Docker-Content-Digest", testDigest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestSplitImage(t *testing.T) {
+	for image, expected := range map[string][2]string{
+		"megaease/easegress:easemesh":         {"megaease/easegress", "easemesh"},
+		"megaease/easegress":                  {"megaease/easegress", "latest"},
+		"localhost:5000/easegress":            {"localhost:5000/easegress", "latest"},
+		"megaease/easegress@" + testDigest:    {"megaease/easegress", testDigest},
+		"localhost:5000/easegress:easemesh":   {"localhost:5000/easegress", "easemesh"},
+		"megaease/easemesh-operator:v1.3.0.1": {"megaease/easemesh-operator", "v1.3.0.1"},
+	} {
+		repository, reference := splitImage(image)
+		if repository != expected[0] || reference != expected[1] {
+			t.Errorf("split %s: want %v, got %s %s", image, expected, repository, reference)
+		}
+	}
+
+	host, repository := registryRepository("docker.io", "nginx")
+	if host != dockerHubRegistry || repository != "library/nginx" {
+		t.Errorf("want official image of docker hub, got %s %s", host, repository)
+	}
+	host, repository = registryRepository("registry.example.com/mirror", "megaease/easegress")
+	if host != "registry.example.com" || repository != "mirror/megaease/easegress" {
+		t.Errorf("want repository under the path of the registry, got %s %s", host, repository)
+	}
+}
+
+func TestResolveDigest(t *testing.T) {
+	server := fakeRegistry(t)
+	defer server.Close()
+
+	client := &registryClient{client: server.Client(), scheme: "http"}
+	registryURL := strings.TrimPrefix(server.URL, "http://")
+
+	digest, err := client.resolveDigest(context.Background(), registryURL, "megaease/easegress:easemesh")
+	if err != nil {
+		t.Fatalf("resolve digest failed: %v", err)
+	}
+	if digest != testDigest {
+		t.Fatalf("want digest %s, got %s", testDigest, digest)
+	}
+
+	_, err = client.resolveDigest(context.Background(), registryURL, "megaease/unknown:latest")
+	if err == nil {
+		t.Fatalf("expected unknown image fails")
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	server := fakeRegistry(t)
+	defer server.Close()
+	defaultRegistry := registry
+	registry = &registryClient{client: server.Client(), scheme: "http"}
+	defer func() { registry = defaultRegistry }()
+
+	ctx, client := prepareContext()
+	ctx.Flags.ImageRegistryURL = strings.TrimPrefix(server.URL, "http://")
+	ctx.Flags.EaseMeshOperatorImage = "megaease/easegress:operator"
+	ctx.Flags.PinDigests = true
+	ctx.Flags.AddOns = []string{"egressgateway"}
+
+	if images := componentImages(ctx.Flags); len(images) != 2 {
+		t.Fatalf("expected the image of Easegress is pinned once, got %d images", len(images))
+	}
+
+	err := Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+	if ctx.Flags.EasegressImage != "megaease/easegress@"+testDigest {
+		t.Fatalf("expected the image of Easegress is pinned, got %s", ctx.Flags.EasegressImage)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(ctx.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.ImageDigestsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get configmap failed: %v", err)
+	}
+	records := []*imageRecord{}
+	err = yaml.Unmarshal([]byte(configMap.Data[installbase.ImageDigestsConfigMapKey]), &records)
+	if err != nil {
+		t.Fatalf("unmarshal records failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Digest != testDigest || records[0].Verified {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	Clear(ctx)
+	_, err = client.CoreV1().ConfigMaps(ctx.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.ImageDigestsConfigMapName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the configmap is cleared, got %v", err)
+	}
+}
+
+func TestPreCheck(t *testing.T) {
+	ctx, _ := prepareContext()
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check without --cosign-key failed: %v", err)
+	}
+
+	cosignCommand = "cosign-not-installed"
+	defer func() { cosignCommand = "cosign" }()
+	ctx.Flags.CosignKey = "cosign.pub"
+	if err := PreCheck(ctx); err == nil {
+		t.Fatalf("expected missing cosign fails the pre check")
+	}
+}
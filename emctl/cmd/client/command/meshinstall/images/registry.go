@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+
+	manifestAcceptTypes = "application/vnd.docker.distribution.manifest.list.v2+json," +
+		"application/vnd.docker.distribution.manifest.v2+json," +
+		"application/vnd.oci.image.index.v1+json," +
+		"application/vnd.oci.image.manifest.v1+json"
+)
+
+var authParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// registryClient resolves tags of images to digests by the registry HTTP API V2,
+// with anonymous tokens if the registry requires them.
+type registryClient struct {
+	client *http.Client
+	scheme string
+}
+
+// registry is replaced by a client of a fake registry in tests.
+var registry = &registryClient{client: http.DefaultClient, scheme: "https"}
+
+// splitImage splits the image into its repository and its tag or digest.
+func splitImage(image string) (repository, reference string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
+
+// isDigest reports whether the reference of an image is a digest.
+func isDigest(reference string) bool {
+	return strings.HasPrefix(reference, "sha256:")
+}
+
+// registryRepository returns the host of the registry and the full repository
+// of the image, the registry URL could contain a path prefix of repositories.
+func registryRepository(registryURL, repository string) (host, fullRepository string) {
+	host = registryURL
+	if i := strings.Index(registryURL, "/"); i >= 0 {
+		host, repository = registryURL[:i], registryURL[i+1:]+"/"+repository
+	}
+
+	switch host {
+	case "docker.io", "index.docker.io":
+		host = dockerHubRegistry
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	}
+	return host, repository
+}
+
+// resolveDigest returns the digest of the manifest the tag of the image refers to.
+func (r *registryClient) resolveDigest(ctx context.Context, registryURL, image string) (string, error) {
+	repository, reference := splitImage(image)
+	if isDigest(reference) {
+		return reference, nil
+	}
+
+	host, repository := registryRepository(registryURL, repository)
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", r.scheme, host, repository, reference)
+
+	resp, err := r.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := r.token(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", errors.Wrapf(err, "get token of %s", manifestURL)
+		}
+		resp, err = r.headManifest(ctx, manifestURL, token)
+		if err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("head %s: %s", manifestURL, resp.Status)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !isDigest(digest) {
+		return "", errors.Errorf("head %s: no digest in the response", manifestURL)
+	}
+	return digest, nil
+}
+
+func (r *registryClient) headManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "new request of %s", manifestURL)
+	}
+	req.Header.Set("Accept", manifestAcceptTypes)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "head %s", manifestURL)
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// token gets an anonymous token from the realm of the Bearer challenge.
+func (r *registryClient) token(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", errors.Errorf("unsupported challenge %q, only anonymous pulling is supported", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range authParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", errors.Errorf("no realm in challenge %q", challenge)
+	}
+
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenURL := params["realm"] + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "new request of %s", tokenURL)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "get %s", tokenURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("get %s: %s", tokenURL, resp.Status)
+	}
+
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", errors.Wrapf(err, "decode token of %s", tokenURL)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
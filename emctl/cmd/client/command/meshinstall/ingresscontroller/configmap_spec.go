@@ -28,7 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+func configMapSpec(ctx *installbase.StageContext, inst *instance) installbase.InstallFunc {
 	config := installbase.EasegressConfig{
 		// Injected from env EG_NAME
 		// Name:                    "" ,
@@ -39,10 +39,8 @@ func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 			PrimaryListenPeerURLs: installbase.ControlPlanePeerURLs(ctx),
 		},
 		APIAddr: fmt.Sprintf("0.0.0.0:%d", ctx.Flags.EgAdminPort),
-		HomeDir: installbase.ControlPlaneHomeDir,
-		Labels: map[string]string{
-			"mesh-role": "ingress-controller",
-		},
+		HomeDir: installbase.EasegressHomeDir(ctx.Flags),
+		Labels:  inst.easegressLabels(),
 	}
 
 	yamlBuff, _ := yaml.Marshal(config)
@@ -52,8 +50,9 @@ func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 
 	configMap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      installbase.IngressControllerConfigMapName,
+			Name:      inst.configMapName(),
 			Namespace: ctx.Flags.MeshNamespace,
+			Labels:    inst.labels(),
 		},
 		Data: data,
 	}
@@ -19,10 +19,12 @@ package ingresscontroller
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
 
 	"github.com/pkg/errors"
 	"k8s.io/client-go/kubernetes"
@@ -30,31 +32,59 @@ import (
 
 // Deploy deploy resources of mesh ingress controller
 func Deploy(ctx *installbase.StageContext) error {
-	err := installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
-		configMapSpec(ctx),
-		serviceSpec(ctx),
-		deploymentSpec(ctx),
-	})
+	insts, err := instances(ctx)
 	if err != nil {
 		return err
 	}
 
-	return checkMeshIngressStatus(ctx.Client, ctx.Flags)
+	for _, inst := range insts {
+		err = installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+			configMapSpec(ctx, inst),
+			serviceSpec(ctx, inst),
+			deploymentSpec(ctx, inst),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, inst := range insts {
+		err = checkMeshIngressStatus(ctx.Client, ctx.Flags, inst.deploymentName())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // PreCheck check prerequisite for installing mesh ingress controller
 func PreCheck(context *installbase.StageContext) error {
-	return nil
+	_, err := instances(context)
+	if err != nil {
+		return err
+	}
+	_, err = parseL4Ports(context.Flags)
+	return err
 }
 
 // Clear will clear all installed resource about mesh ingress panel
 func Clear(context *installbase.StageContext) error {
-	appsV1Resources := [][]string{
-		{"deployments", installbase.IngressControllerDeploymentName},
+	insts := []*instance{defaultInstance(context)}
+	classes, err := installedClasses(context)
+	if err != nil {
+		common.OutputErrorf("%v", err)
+	}
+	for _, class := range classes {
+		insts = append(insts, &instance{class: class})
 	}
-	coreV1Resources := [][]string{
-		{"services", installbase.IngressControllerServiceName},
-		{"configmap", installbase.IngressControllerConfigMapName},
+
+	appsV1Resources := [][]string{}
+	coreV1Resources := [][]string{}
+	for _, inst := range insts {
+		appsV1Resources = append(appsV1Resources, []string{"deployments", inst.deploymentName()})
+		coreV1Resources = append(coreV1Resources,
+			[]string{"services", inst.serviceName()},
+			[]string{"configmaps", inst.configMapName()})
 	}
 
 	installbase.DeleteResources(context.Client, appsV1Resources, context.Flags.MeshNamespace, installbase.DeleteAppsV1Resource)
@@ -69,14 +99,20 @@ func DescribePhase(context *installbase.StageContext, phase installbase.InstallP
 	case installbase.BeginPhase:
 		return fmt.Sprintf("Begin to install mesh ingress controller in the namespace:%s", context.Flags.MeshNamespace)
 	case installbase.EndPhase:
-		return fmt.Sprintf("\nMesh ingress controller deployed successfully, deployment:%s\n%s", installbase.IngressControllerDeploymentName,
-			installbase.FormatPodStatus(context.Client, context.Flags.MeshNamespace,
-				installbase.AdaptListPodFunc(meshIngressLabel())))
+		insts, _ := instances(context)
+		names := []string{}
+		status := ""
+		for _, inst := range insts {
+			names = append(names, inst.deploymentName())
+			status += installbase.FormatPodStatus(context.Client, context.Flags.MeshNamespace,
+				installbase.AdaptListPodFunc(meshIngressLabel(inst)))
+		}
+		return fmt.Sprintf("\nMesh ingress controller deployed successfully, deployment:%s\n%s", strings.Join(names, ","), status)
 	}
 	return ""
 }
 
-func checkMeshIngressStatus(client kubernetes.Interface, installFlags *flags.Install) error {
+func checkMeshIngressStatus(client kubernetes.Interface, installFlags *flags.Install, name string) error {
 	i := 0
 	for {
 		time.Sleep(time.Millisecond * 100)
@@ -85,8 +121,7 @@ func checkMeshIngressStatus(client kubernetes.Interface, installFlags *flags.Ins
 			return errors.Errorf("easeMesh meshingress controller deploy failed, mesh ingress controller (EG deployment) not ready")
 		}
 		ready, err := installbase.CheckDeploymentResourceStatus(client, installFlags.MeshNamespace,
-			installbase.IngressControllerDeploymentName,
-			installbase.DeploymentReadyPredict)
+			name, installbase.DeploymentReadyPredict)
 		if ready {
 			return nil
 		}
@@ -18,6 +18,9 @@
 package ingresscontroller
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
@@ -28,13 +31,14 @@ import (
 	appsV1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
 )
 
-func prepareContext() (*installbase.StageContext, *fake.Clientset, *extensionfake.Clientset) {
-	client := fake.NewSimpleClientset()
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset, *extensionfake.Clientset) {
+	client := meshtesting.NewFakeClientset()
 	extensionClient := extensionfake.NewSimpleClientset()
 
 	install := &flags.Install{}
@@ -53,10 +57,10 @@ func TestDeploy(t *testing.T) {
 		return true, nil, nil
 	})
 
-	for _, f := range []func(*installbase.StageContext) installbase.InstallFunc{
+	for _, f := range []func(*installbase.StageContext, *instance) installbase.InstallFunc{
 		configMapSpec, serviceSpec, deploymentSpec,
 	} {
-		f(ctx).Deploy(ctx)
+		f(ctx, defaultInstance(ctx)).Deploy(ctx)
 	}
 
 	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
@@ -86,4 +90,161 @@ func TestDescribePhase(t *testing.T) {
 	PreCheck(ctx)
 }
 
+func TestParseInstance(t *testing.T) {
+	ctx, _, _ := prepareContext()
+
+	inst, err := parseInstance(ctx, "name=team-a,tenant=a,replicas=2,port=8080,node-port=30080,cpu=500m,memory=256Mi")
+	if err != nil {
+		t.Fatalf("parse instance failed: %v", err)
+	}
+	if inst.class != "team-a" || inst.tenant != "a" || inst.replicas != 2 ||
+		inst.servicePort != 8080 || inst.nodePort != 30080 {
+		t.Fatalf("unexpected instance %+v", inst)
+	}
+	if inst.resources.Limits.Memory().String() != "256Mi" || inst.resources.Requests.Cpu().String() != "500m" {
+		t.Fatalf("unexpected resources %+v", inst.resources)
+	}
+
+	inst, err = parseInstance(ctx, "name=team-b")
+	if err != nil {
+		t.Fatalf("parse instance failed: %v", err)
+	}
+	if inst.replicas != ctx.Flags.MeshIngressReplicas || inst.servicePort != ctx.Flags.MeshIngressServicePort || inst.resources != nil {
+		t.Fatalf("instance %+v should default to the default instance", inst)
+	}
+
+	for _, spec := range []string{
+		"tenant=a",
+		"name=Team_A",
+		"name=team-a,replicas=0",
+		"name=team-a,port=70000",
+		"name=team-a,cpu=lots",
+		"name=team-a,zone=dc1",
+		"name=team-a,tenant",
+	} {
+		if _, err = parseInstance(ctx, spec); err == nil {
+			t.Errorf("parse instance %q should fail", spec)
+		}
+	}
+
+	ctx.Flags.MeshIngressClasses = []string{"name=team-a", "name=team-a,replicas=2"}
+	if _, err = instances(ctx); err == nil {
+		t.Errorf("duplicated ingress classes should fail")
+	}
+}
+
+func TestDeployIngressClass(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	ctx.Flags.MeshNamespace = "easemesh"
+	ctx.Flags.MeshIngressClasses = []string{"name=team-a,tenant=a,replicas=2,port=8080,memory=256Mi"}
+
+	insts, err := instances(ctx)
+	if err != nil {
+		t.Fatalf("instances failed: %v", err)
+	}
+	if len(insts) != 2 {
+		t.Fatalf("expected the default instance and team-a, got %d instances", len(insts))
+	}
+	for _, f := range []func(*installbase.StageContext, *instance) installbase.InstallFunc{
+		configMapSpec, serviceSpec, deploymentSpec,
+	} {
+		if err = f(ctx, insts[1]).Deploy(ctx); err != nil {
+			t.Fatalf("deploy failed: %v", err)
+		}
+	}
+
+	deployment, err := client.AppsV1().Deployments("easemesh").Get(context.Background(),
+		"easemesh-ingress-controller-team-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment failed: %v", err)
+	}
+	if *deployment.Spec.Replicas != 2 || deployment.Labels[ingressClassLabel] != "team-a" {
+		t.Errorf("unexpected deployment %+v", deployment.ObjectMeta)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Resources.Limits.Memory().String() != "256Mi" {
+		t.Errorf("unexpected resources %+v", container.Resources)
+	}
+
+	service, err := client.CoreV1().Services("easemesh").Get(context.Background(),
+		"easemesh-ingress-controller-service-team-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get service failed: %v", err)
+	}
+	port := service.Spec.Ports[0]
+	if port.Port != 8080 || port.TargetPort.IntVal != ctx.Flags.MeshIngressServicePort ||
+		service.Spec.Selector["app"] != "easemesh-ingress-controller-team-a" {
+		t.Errorf("unexpected service %+v", service.Spec)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps("easemesh").Get(context.Background(),
+		"easemesh-ingress-controller-config-team-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get configmap failed: %v", err)
+	}
+	config := configMap.Data[installbase.ControlPlaneConfigMapKey]
+	if !strings.Contains(config, "mesh-ingress-class: team-a") || !strings.Contains(config, "mesh-tenant: a") {
+		t.Errorf("unexpected config %s", config)
+	}
+
+	classes, err := installedClasses(ctx)
+	if err != nil {
+		t.Fatalf("installed classes failed: %v", err)
+	}
+	if len(classes) != 1 || classes[0] != "team-a" {
+		t.Errorf("expected installed class team-a, got %v", classes)
+	}
+
+	Clear(ctx)
+	_, err = client.AppsV1().Deployments("easemesh").Get(context.Background(),
+		"easemesh-ingress-controller-team-a", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the deployment is cleared, got %v", err)
+	}
+	_, err = client.CoreV1().ConfigMaps("easemesh").Get(context.Background(),
+		"easemesh-ingress-controller-config-team-a", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the configmap is cleared, got %v", err)
+	}
+}
+
+func TestL4Ports(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	ctx.Flags.MeshNamespace = "easemesh"
+	ctx.Flags.MeshIngressL4Ports = []string{"5432", "53/UDP", "53/tcp"}
+
+	ports, err := parseL4Ports(ctx.Flags)
+	if err != nil {
+		t.Fatalf("parse l4 ports failed: %v", err)
+	}
+	if len(ports) != 3 || ports[1].name() != "udp-53" || ports[2].protocol != v1.ProtocolTCP {
+		t.Fatalf("unexpected l4 ports %+v", ports)
+	}
+
+	if err = serviceSpec(ctx, defaultInstance(ctx)).Deploy(ctx); err != nil {
+		t.Fatalf("deploy service failed: %v", err)
+	}
+	service, err := client.CoreV1().Services("easemesh").Get(context.Background(),
+		installbase.IngressControllerServiceName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get service failed: %v", err)
+	}
+	if len(service.Spec.Ports) != 4 || service.Spec.Ports[2].Protocol != v1.ProtocolUDP || service.Spec.Ports[1].Port != 5432 {
+		t.Errorf("unexpected service ports %+v", service.Spec.Ports)
+	}
+
+	for _, invalid := range [][]string{
+		{"0"},
+		{"5432/sctp"},
+		{"postgres"},
+		{"5432", "5432/tcp"},
+		{strconv.Itoa(int(ctx.Flags.MeshIngressServicePort))},
+	} {
+		ctx.Flags.MeshIngressL4Ports = invalid
+		if err = PreCheck(ctx); err == nil {
+			t.Errorf("l4 ports %v should be invalid", invalid)
+		}
+	}
+}
+
 var helloWorld = "aGVsbG8gd29ybGQK"
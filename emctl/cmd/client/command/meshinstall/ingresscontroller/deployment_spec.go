@@ -18,7 +18,6 @@
 package ingresscontroller
 
 import (
-	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 
 	"github.com/pkg/errors"
@@ -27,22 +26,26 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-type deploymentSpecFunc func(*installbase.StageContext) *appsV1.Deployment
+type deploymentSpecFunc func(*installbase.StageContext, *instance) (*appsV1.Deployment, error)
 
-func meshIngressLabel() map[string]string {
+func meshIngressLabel(inst *instance) map[string]string {
 	selector := map[string]string{}
-	selector["app"] = installbase.IngressControllerDeploymentName
+	selector["app"] = inst.deploymentName()
 	return selector
 }
 
-func deploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
-	deployment := deploymentConfigVolumeSpec(
-		deploymentContainerSpec(
-			deploymentBaseSpec(
-				deploymentInitialize(nil))))(ctx)
-
+func deploymentSpec(ctx *installbase.StageContext, inst *instance) installbase.InstallFunc {
 	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
+		deployment, err := deploymentSecurityProfileSpec(
+			deploymentConfigVolumeSpec(
+				deploymentContainerSpec(
+					deploymentBaseSpec(
+						deploymentInitialize(nil)))))(ctx, inst)
+		if err != nil {
+			return errors.Wrap(err, "build deployment spec failed")
+		}
+
+		err = installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
 		if err != nil {
 			return errors.Wrapf(err, "deploy %s failed", deployment.Name)
 		}
@@ -51,65 +54,91 @@ func deploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 }
 
 func deploymentInitialize(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		return &appsV1.Deployment{}
+	return func(ctx *installbase.StageContext, inst *instance) (*appsV1.Deployment, error) {
+		return &appsV1.Deployment{}, nil
 	}
 }
 
 func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
-		spec.Name = installbase.IngressControllerDeploymentName
+	return func(ctx *installbase.StageContext, inst *instance) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx, inst)
+		if err != nil {
+			return nil, err
+		}
+		spec.Name = inst.deploymentName()
+		spec.Labels = inst.labels()
 		spec.Spec.Selector = &metav1.LabelSelector{
-			MatchLabels: meshIngressLabel(),
+			MatchLabels: meshIngressLabel(inst),
 		}
 
-		replicas := int32(ctx.Flags.MeshIngressReplicas)
+		replicas := int32(inst.replicas)
 		spec.Spec.Replicas = &replicas
-		spec.Spec.Template.Labels = meshIngressLabel()
+		spec.Spec.Template.Labels = meshIngressLabel(inst)
 		spec.Spec.Template.Spec.Containers = []v1.Container{}
-		return spec
+		return spec, nil
 	}
 }
 
 func deploymentContainerSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
-		container, _ := installbase.AcceptContainerVisitor(installbase.IngressControllerDeploymentName,
+	return func(ctx *installbase.StageContext, inst *instance) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx, inst)
+		if err != nil {
+			return nil, err
+		}
+		container, err := installbase.AcceptContainerVisitor(inst.deploymentName(),
 			ctx.Flags.ImageRegistryURL+"/"+ctx.Flags.EasegressImage,
 			v1.PullIfNotPresent,
-			newVisitor(ctx))
+			newVisitor(ctx, inst))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate container spec failed")
+		}
 
 		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, *container)
-		return spec
+		return spec, nil
 	}
 }
 
 func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext, inst *instance) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx, inst)
+		if err != nil {
+			return nil, err
+		}
 		spec.Spec.Template.Spec.Volumes = []v1.Volume{
 			{
 				Name: installbase.IngressControllerConfigMapName,
 				VolumeSource: v1.VolumeSource{
 					ConfigMap: &v1.ConfigMapVolumeSource{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: installbase.IngressControllerConfigMapName,
+							Name: inst.configMapName(),
 						},
 					},
 				},
 			},
 		}
-		return spec
+		return spec, nil
+	}
+}
+
+func deploymentSecurityProfileSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext, inst *instance) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx, inst)
+		if err != nil {
+			return nil, err
+		}
+		installbase.ApplySecurityProfile(ctx.Flags, &spec.Spec.Template.Spec,
+			installbase.EasegressHomeDir(ctx.Flags), installbase.TmpDir)
+		return spec, nil
 	}
 }
 
 type containerVisitor struct {
-	ctx *installbase.StageContext
+	ctx  *installbase.StageContext
+	inst *instance
 }
 
-func newVisitor(ctx *installbase.StageContext) installbase.ContainerVisitor {
-	return &containerVisitor{ctx}
+func newVisitor(ctx *installbase.StageContext, inst *instance) installbase.ContainerVisitor {
+	return &containerVisitor{ctx, inst}
 }
 
 func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []string, args []string) {
@@ -118,20 +147,15 @@ func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []str
 }
 
 func (v *containerVisitor) VisitorContainerPorts(c *v1.Container) ([]v1.ContainerPort, error) {
-	return []v1.ContainerPort{
-		{
-			Name:          installbase.ControlPlaneStatefulSetAdminPortName,
-			ContainerPort: flags.DefaultMeshAdminPort,
-		},
-		{
-			Name:          installbase.ControlPlaneStatefulSetClientPortName,
-			ContainerPort: flags.DefaultMeshClientPort,
-		},
-		{
-			Name:          installbase.ControlPlaneStatefulSetPeerPortName,
-			ContainerPort: flags.DefaultMeshPeerPort,
-		},
-	}, nil
+	ports := installbase.ControlPlaneContainerPorts(v.ctx)
+	l4Ports, err := parseL4Ports(v.ctx.Flags)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range l4Ports {
+		ports = append(ports, p.containerPort())
+	}
+	return ports, nil
 }
 
 func (v *containerVisitor) VisitorEnvs(c *v1.Container) ([]v1.EnvVar, error) {
@@ -168,7 +192,7 @@ func (v *containerVisitor) VisitorEnvFrom(c *v1.Container) ([]v1.EnvFromSource,
 }
 
 func (v *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.ResourceRequirements, error) {
-	return nil, nil
+	return v.inst.resources, nil
 }
 
 func (v *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMount, error) {
@@ -193,7 +217,7 @@ func (v *containerVisitor) VisitorLivenessProbe(c *v1.Container) (*v1.Probe, err
 		Handler: v1.Handler{
 			HTTPGet: &v1.HTTPGetAction{
 				Host: "localhost",
-				Port: intstr.FromInt(installbase.DefaultMeshAdminPort),
+				Port: intstr.FromInt(v.ctx.Flags.EgAdminPort),
 				Path: "/apis/v1/healthz",
 			},
 		},
@@ -212,5 +236,5 @@ func (v *containerVisitor) VisitorLifeCycle(c *v1.Container) (*v1.Lifecycle, err
 }
 
 func (v *containerVisitor) VisitorSecurityContext(c *v1.Container) (*v1.SecurityContext, error) {
-	return nil, nil
+	return installbase.ContainerSecurityContext(v.ctx.Flags), nil
 }
@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingresscontroller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ingressClassLabel labels resources and the Easegress of an ingress controller
+	// instance with its ingress class, the default instance has none.
+	ingressClassLabel = "mesh-ingress-class"
+	// ingressTenantLabel labels the Easegress of an ingress controller instance with its tenant.
+	ingressTenantLabel = "mesh-tenant"
+)
+
+// instance is an ingress controller deployed with its own ConfigMap, Service and Deployment,
+// the instance with an ingress class only serves mesh ingresses annotated with the class.
+type instance struct {
+	class       string
+	tenant      string
+	replicas    int
+	servicePort int32
+	nodePort    int32
+	resources   *v1.ResourceRequirements
+}
+
+// defaultInstance returns the instance serving mesh ingresses without ingress classes.
+func defaultInstance(ctx *installbase.StageContext) *instance {
+	return &instance{
+		replicas:    ctx.Flags.MeshIngressReplicas,
+		servicePort: ctx.Flags.MeshIngressServicePort,
+		nodePort:    ctx.Flags.MeshIngressNodePort,
+	}
+}
+
+// instances returns the default instance followed by instances of --ingress-class.
+func instances(ctx *installbase.StageContext) ([]*instance, error) {
+	result := []*instance{defaultInstance(ctx)}
+	classes := map[string]bool{}
+	for _, spec := range ctx.Flags.MeshIngressClasses {
+		inst, err := parseInstance(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		if classes[inst.class] {
+			return nil, errors.Errorf("duplicated ingress class %s", inst.class)
+		}
+		classes[inst.class] = true
+		result = append(result, inst)
+	}
+	return result, nil
+}
+
+// parseInstance parses the instance in the form
+// name=<class>,tenant=<tenant>,replicas=<n>,port=<port>,node-port=<port>,cpu=<quantity>,memory=<quantity>,
+// only name is required and the others default to the default instance.
+func parseInstance(ctx *installbase.StageContext, spec string) (*instance, error) {
+	inst := defaultInstance(ctx)
+	inst.nodePort = 0
+
+	requests := v1.ResourceList{}
+	for _, kv := range strings.Split(spec, ",") {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid ingress class %q, %q must be in the form key=value", spec, kv)
+		}
+		key, value := kv[:i], kv[i+1:]
+
+		var err error
+		switch key {
+		case "name":
+			inst.class = value
+		case "tenant":
+			inst.tenant = value
+		case "replicas":
+			inst.replicas, err = strconv.Atoi(value)
+		case "port":
+			inst.servicePort, err = parsePort(value)
+		case "node-port":
+			inst.nodePort, err = parsePort(value)
+		case "cpu":
+			requests[v1.ResourceCPU], err = resource.ParseQuantity(value)
+		case "memory":
+			requests[v1.ResourceMemory], err = resource.ParseQuantity(value)
+		default:
+			return nil, errors.Errorf("invalid ingress class %q, unknown key %s", spec, key)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ingress class %q, %s", spec, key)
+		}
+	}
+
+	if inst.class == "" {
+		return nil, errors.Errorf("invalid ingress class %q, name is required", spec)
+	}
+	if msgs := validation.IsDNS1123Label(inst.class); len(msgs) != 0 {
+		return nil, errors.Errorf("invalid ingress class %q, name %s", spec, strings.Join(msgs, ", "))
+	}
+	if inst.replicas < 1 {
+		return nil, errors.Errorf("invalid ingress class %q, replicas must be positive", spec)
+	}
+	if len(requests) != 0 {
+		inst.resources = &v1.ResourceRequirements{
+			Requests: requests,
+			Limits:   requests.DeepCopy(),
+		}
+	}
+
+	return inst, nil
+}
+
+func parsePort(value string) (int32, error) {
+	port, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if port < 0 || port > 65535 {
+		return 0, errors.Errorf("port %d out of range", port)
+	}
+	return int32(port), nil
+}
+
+func (inst *instance) nameOf(name string) string {
+	if inst.class == "" {
+		return name
+	}
+	return name + "-" + inst.class
+}
+
+func (inst *instance) deploymentName() string {
+	return inst.nameOf(installbase.IngressControllerDeploymentName)
+}
+
+func (inst *instance) serviceName() string {
+	return inst.nameOf(installbase.IngressControllerServiceName)
+}
+
+func (inst *instance) configMapName() string {
+	return inst.nameOf(installbase.IngressControllerConfigMapName)
+}
+
+// labels returns labels of resources of the instance.
+func (inst *instance) labels() map[string]string {
+	if inst.class == "" {
+		return nil
+	}
+	return map[string]string{ingressClassLabel: inst.class}
+}
+
+// easegressLabels returns labels of the Easegress, by which the control plane
+// selects mesh ingresses served by the instance.
+func (inst *instance) easegressLabels() map[string]string {
+	labels := map[string]string{
+		"mesh-role": "ingress-controller",
+	}
+	if inst.class != "" {
+		labels[ingressClassLabel] = inst.class
+	}
+	if inst.tenant != "" {
+		labels[ingressTenantLabel] = inst.tenant
+	}
+	return labels
+}
+
+// installedClasses returns ingress classes of instances installed in the mesh namespace,
+// the --ingress-class flags of the installation are unknown when resetting.
+func installedClasses(ctx *installbase.StageContext) ([]string, error) {
+	deployments, err := ctx.Client.AppsV1().Deployments(ctx.Flags.MeshNamespace).List(context.Background(),
+		metav1.ListOptions{LabelSelector: ingressClassLabel})
+	if err != nil {
+		return nil, errors.Wrap(err, "list ingress controller instances")
+	}
+
+	classes := []string{}
+	for _, deployment := range deployments.Items {
+		classes = append(classes, deployment.Labels[ingressClassLabel])
+	}
+	return classes, nil
+}
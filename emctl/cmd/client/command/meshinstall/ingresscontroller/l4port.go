@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ingresscontroller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const meshIngressHTTPPortName = "http"
+
+// l4Port is a TCP/UDP port exposed by the ingress controller, the traffic
+// is forwarded to mesh services by IngressPort resources.
+type l4Port struct {
+	port     int32
+	protocol v1.Protocol
+}
+
+// parseL4Ports parses ports of --mesh-ingress-l4-ports in the form <port>[/tcp|/udp].
+func parseL4Ports(installFlags *flags.Install) ([]*l4Port, error) {
+	reserved := map[int]string{
+		installFlags.EgAdminPort:                 "--mesh-control-plane-admin-port",
+		installFlags.EgClientPort:                "--mesh-control-plane-client-port",
+		installFlags.EgPeerPort:                  "--mesh-control-plane-peer-port",
+		int(installFlags.MeshIngressServicePort): "--mesh-ingress-service-port",
+	}
+
+	ports := []*l4Port{}
+	seen := map[string]bool{}
+	for _, value := range installFlags.MeshIngressL4Ports {
+		number, protocol := value, "tcp"
+		if i := strings.Index(value, "/"); i >= 0 {
+			number, protocol = value[:i], strings.ToLower(value[i+1:])
+		}
+
+		port, err := strconv.Atoi(number)
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, errors.Errorf("invalid l4 port %q, port must be in 1-65535", value)
+		}
+		if flag, exists := reserved[port]; exists {
+			return nil, errors.Errorf("l4 port %q conflicts with %s", value, flag)
+		}
+
+		var p *l4Port
+		switch protocol {
+		case "tcp":
+			p = &l4Port{port: int32(port), protocol: v1.ProtocolTCP}
+		case "udp":
+			p = &l4Port{port: int32(port), protocol: v1.ProtocolUDP}
+		default:
+			return nil, errors.Errorf("invalid l4 port %q, protocol must be tcp or udp", value)
+		}
+
+		if seen[p.name()] {
+			return nil, errors.Errorf("duplicated l4 port %q", value)
+		}
+		seen[p.name()] = true
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+func (p *l4Port) name() string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(p.protocol)), p.port)
+}
+
+func (p *l4Port) servicePort() v1.ServicePort {
+	return v1.ServicePort{
+		Name:       p.name(),
+		Port:       p.port,
+		Protocol:   p.protocol,
+		TargetPort: intstr.FromInt(int(p.port)),
+	}
+}
+
+func (p *l4Port) containerPort() v1.ContainerPort {
+	return v1.ContainerPort{
+		Name:          p.name(),
+		ContainerPort: p.port,
+		Protocol:      p.protocol,
+	}
+}
@@ -24,21 +24,33 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+func serviceSpec(ctx *installbase.StageContext, inst *instance) installbase.InstallFunc {
 	service := &v1.Service{}
-	service.Name = installbase.IngressControllerServiceName
+	service.Name = inst.serviceName()
+	service.Labels = inst.labels()
 
 	service.Spec.Ports = []v1.ServicePort{
 		{
-			Port:       ctx.Flags.MeshIngressServicePort,
-			Protocol:   v1.ProtocolTCP,
+			Name:     meshIngressHTTPPortName,
+			Port:     inst.servicePort,
+			Protocol: v1.ProtocolTCP,
+			// All instances listen on the ingress port of the mesh controller.
 			TargetPort: intstr.IntOrString{IntVal: ctx.Flags.MeshIngressServicePort},
+			NodePort:   inst.nodePort,
 		},
 	}
-	service.Spec.Selector = meshIngressLabel()
+	service.Spec.Selector = meshIngressLabel(inst)
 	service.Spec.Type = v1.ServiceTypeNodePort
 	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployService(service, ctx.Client, ctx.Flags.MeshNamespace)
+		l4Ports, err := parseL4Ports(ctx.Flags)
+		if err != nil {
+			return err
+		}
+		for _, p := range l4Ports {
+			service.Spec.Ports = append(service.Spec.Ports, p.servicePort())
+		}
+
+		err = installbase.DeployService(service, ctx.Client, ctx.Flags.MeshNamespace)
 		return err
 	}
 }
@@ -18,7 +18,7 @@
 package installation
 
 import (
-	"fmt"
+	stdcontext "context"
 
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 	"github.com/megaease/easemeshctl/cmd/common"
@@ -82,12 +82,13 @@ type PreCheckFunc HookFunc
 // DescribeFunc is the type of function describing what's the situation of the installation
 type DescribeFunc func(*installbase.StageContext, installbase.InstallPhase) string
 
-// Wrap creates new InstallStage via wraping functions
-func Wrap(preCheckFunc HookFunc, installFunc InstallFunc, clearFunc HookFunc, description DescribeFunc) InstallStage {
-	return &baseInstallStage{preCheck: PreCheckFunc(preCheckFunc), installFunc: installFunc, clearFunc: ClearFunc(clearFunc), description: description}
+// Wrap creates new InstallStage named name via wraping functions
+func Wrap(name string, preCheckFunc HookFunc, installFunc InstallFunc, clearFunc HookFunc, description DescribeFunc) InstallStage {
+	return &baseInstallStage{name: name, preCheck: PreCheckFunc(preCheckFunc), installFunc: installFunc, clearFunc: ClearFunc(clearFunc), description: description}
 }
 
 type baseInstallStage struct {
+	name        string
 	preCheck    PreCheckFunc
 	installFunc InstallFunc
 	clearFunc   ClearFunc
@@ -97,22 +98,46 @@ type baseInstallStage struct {
 var _ InstallStage = &baseInstallStage{}
 
 func (b *baseInstallStage) Do(context *installbase.StageContext, install Installation) error {
-	fmt.Printf("%s\n", b.description(context, installbase.BeginPhase))
+	context.Progress.Started(b.name)
+	common.Infof("%s", b.description(context, installbase.BeginPhase))
+
+	end := context.BeginStage()
+	context.Snapshot.BeginStage(b.name)
 	if b.preCheck != nil {
 		if err := b.preCheck(context); err != nil {
-			return errors.Wrap(err, "pre check installation condition failed")
+			err = b.interrupted(context, errors.Wrap(err, "pre check installation condition failed"))
+			end()
+			context.Progress.Failed(b.name, err)
+			return common.WithCode(err, common.ExitCodeValidation)
 		}
 	}
 	err := b.installFunc(context)
 	context.ClearFuncs = append(context.ClearFuncs, b.clearFunc)
 	if err != nil {
-		return errors.Wrap(err, "invoke install func")
+		err = b.interrupted(context, errors.Wrap(err, "invoke install func"))
+		end()
+		context.Progress.Failed(b.name, err)
+		return err
 	}
+	end()
 
-	fmt.Printf("Install successfully end, following resource are deployed successfully: %s\n", b.description(context, installbase.EndPhase))
+	context.Progress.Ready(b.name)
+	common.Infof("Install successfully end, following resource are deployed successfully: %s", b.description(context, installbase.EndPhase))
 	return install.DoInstallStage(context)
 }
 
+// interrupted reports which stage is interrupted or timed out, if the
+// stage failed for its context.
+func (b *baseInstallStage) interrupted(context *installbase.StageContext, err error) error {
+	switch context.Stage().Err() {
+	case stdcontext.Canceled:
+		return common.CodeErrorf(common.ExitCodeInterrupted, "stage %s interrupted: %v", b.name, err)
+	case stdcontext.DeadlineExceeded:
+		return common.CodeErrorf(common.ExitCodeTimeout, "stage %s timed out after %s: %v", b.name, context.Flags.StageTimeout, err)
+	}
+	return err
+}
+
 func (b *baseInstallStage) Clear(context *installbase.StageContext) error {
 	if b.clearFunc != nil {
 		return b.clearFunc(context)
@@ -17,9 +17,18 @@
 package installation
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
 )
 
 func stepOneDescribe(*installbase.StageContext, installbase.InstallPhase) string {
@@ -56,8 +65,8 @@ func stepTwoPreCheck(s *installbase.StageContext) error {
 
 func TestInstallation(t *testing.T) {
 	installStages := []InstallStage{
-		Wrap(stepOnePreCheck, stepOneDeploy, stepOneClear, stepOneDescribe),
-		Wrap(stepTwoPreCheck, stepTwoDeploy, stepTwoClear, stepTwoDescribe),
+		Wrap("one", stepOnePreCheck, stepOneDeploy, stepOneClear, stepOneDescribe),
+		Wrap("two", stepTwoPreCheck, stepTwoDeploy, stepTwoClear, stepTwoDescribe),
 	}
 
 	installations := New(installStages...)
@@ -78,3 +87,105 @@ func TestInstallation(t *testing.T) {
 
 	installStages[0].Clear(&installContext)
 }
+
+func stepTwoFailedDeploy(s *installbase.StageContext) error {
+	return errors.New("generate container spec failed")
+}
+
+func TestInstallationFailed(t *testing.T) {
+	installations := New(
+		Wrap("one", stepOnePreCheck, stepOneDeploy, stepOneClear, stepOneDescribe),
+		Wrap("two", stepTwoPreCheck, stepTwoFailedDeploy, stepTwoClear, stepTwoDescribe),
+	)
+
+	ctx := &installbase.StageContext{}
+	err := installations.DoInstallStage(ctx)
+	if err == nil {
+		t.Fatalf("installation should fail when install func returns error")
+	}
+
+	// Both the completed and the failed stage must be rolled back.
+	if len(ctx.ClearFuncs) != 2 {
+		t.Fatalf("expected 2 clear funcs, got %d", len(ctx.ClearFuncs))
+	}
+	installations.ClearResource(ctx)
+}
+
+func TestInstallationProgress(t *testing.T) {
+	installations := New(
+		Wrap("one", stepOnePreCheck, stepOneDeploy, stepOneClear, stepOneDescribe),
+		Wrap("two", stepTwoPreCheck, stepTwoFailedDeploy, stepTwoClear, stepTwoDescribe),
+	)
+
+	buff := &bytes.Buffer{}
+	progress := installbase.NewProgressReporter(buff)
+	err := installations.DoInstallStage(&installbase.StageContext{Progress: progress})
+	if err == nil {
+		t.Fatalf("installation should fail when install func returns error")
+	}
+
+	expected := []string{"one started", "one ready", "two started", "two failed"}
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %s", len(expected), len(lines), buff.String())
+	}
+	for i, line := range lines {
+		event := installbase.ProgressEvent{}
+		err := json.Unmarshal([]byte(line), &event)
+		if err != nil {
+			t.Fatalf("event %s isn't json: %v", line, err)
+		}
+		if got := event.Stage + " " + event.Event; got != expected[i] {
+			t.Fatalf("expected event %s, got %s", expected[i], got)
+		}
+	}
+
+	failed := installbase.ProgressEvent{}
+	json.Unmarshal([]byte(lines[3]), &failed)
+	if !strings.Contains(failed.Reason, "generate container spec failed") {
+		t.Fatalf("expected reason of the failure, got %q", failed.Reason)
+	}
+}
+
+func waitStageDone(s *installbase.StageContext) error {
+	<-s.Stage().Done()
+	return s.Stage().Err()
+}
+
+func TestInstallationInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, c := range []struct {
+		stageContext *installbase.StageContext
+		code         int
+		message      string
+	}{
+		{
+			stageContext: &installbase.StageContext{Flags: &flags.Install{StageTimeout: 10 * time.Millisecond}},
+			code:         common.ExitCodeTimeout,
+			message:      "stage two timed out after 10ms",
+		},
+		{
+			stageContext: &installbase.StageContext{Context: ctx, Flags: &flags.Install{}},
+			code:         common.ExitCodeInterrupted,
+			message:      "stage two interrupted",
+		},
+	} {
+		installations := New(
+			Wrap("one", stepOnePreCheck, stepOneDeploy, stepOneClear, stepOneDescribe),
+			Wrap("two", stepTwoPreCheck, waitStageDone, stepTwoClear, stepTwoDescribe),
+		)
+
+		err := installations.DoInstallStage(c.stageContext)
+		if err == nil {
+			t.Fatalf("installation should fail when the stage is %s", c.message)
+		}
+		if !strings.Contains(err.Error(), c.message) {
+			t.Fatalf("expected error %q, got %q", c.message, err)
+		}
+		if code := common.ExitCode(err); code != c.code {
+			t.Fatalf("expected exit code %d, got %d", c.code, code)
+		}
+	}
+}
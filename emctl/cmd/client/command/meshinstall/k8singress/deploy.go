@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8singress
+
+import (
+	"fmt"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+)
+
+const (
+	k8sIngressClusterRole        = "mesh-operator-k8s-ingress-role"
+	k8sIngressClusterRoleBinding = "mesh-operator-k8s-ingress-rolebinding"
+)
+
+// Deploy deploys the IngressClass of the EaseMesh and grants the operator
+// access to Kubernetes Ingress resources
+func Deploy(ctx *installbase.StageContext) error {
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		ingressClassSpec(ctx),
+		clusterRoleSpec(ctx),
+		clusterRoleBindingSpec(ctx),
+	})
+}
+
+// PreCheck check prerequisite for translating Kubernetes Ingress
+func PreCheck(ctx *installbase.StageContext) error {
+	return nil
+}
+
+// Clear clears all k8s resources about the Kubernetes Ingress source
+func Clear(ctx *installbase.StageContext) error {
+	rbacV1Resources := [][]string{
+		{"clusterrolebindings", k8sIngressClusterRoleBinding},
+		{"clusterroles", k8sIngressClusterRole},
+	}
+	networkingV1Resources := [][]string{
+		{"ingressclasses", installbase.IngressClassName},
+	}
+
+	installbase.DeleteResources(ctx.Client, rbacV1Resources, ctx.Flags.MeshNamespace, installbase.DeleteRbacV1Resources)
+	installbase.DeleteResources(ctx.Client, networkingV1Resources, ctx.Flags.MeshNamespace, installbase.DeleteIngressClassResource)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of enabling the Kubernetes Ingress source
+func DescribePhase(ctx *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to enable Kubernetes Ingress source in the namespace: %s", ctx.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nKubernetes Ingress source enabled successfully, ingress class: %s\n"+
+			"Ingresses with ingressClassName %q will be translated into mesh ingresses", installbase.IngressClassName, installbase.IngressClassName)
+	}
+	return ""
+}
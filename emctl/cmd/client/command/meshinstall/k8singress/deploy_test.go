@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8singress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset, *extensionfake.Clientset) {
+	client := meshtesting.NewFakeClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client, extensionClient
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check failed: %v", err)
+	}
+
+	// Deploy twice to cover updating.
+	for i := 0; i < 2; i++ {
+		if err := Deploy(ctx); err != nil {
+			t.Fatalf("deploy Kubernetes Ingress source failed: %v", err)
+		}
+	}
+
+	ingressClass, err := client.NetworkingV1().IngressClasses().Get(context.TODO(), installbase.IngressClassName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("ingress class %s should be deployed: %v", installbase.IngressClassName, err)
+	}
+	if ingressClass.Spec.Controller != installbase.IngressClassController {
+		t.Fatalf("unexpected ingress class controller %s", ingressClass.Spec.Controller)
+	}
+
+	Clear(ctx)
+	_, err = client.NetworkingV1().IngressClasses().Get(context.TODO(), installbase.IngressClassName, metav1.GetOptions{})
+	if err == nil {
+		t.Fatalf("ingress class %s should be cleared", installbase.IngressClassName)
+	}
+}
+
+func TestDescribePhase(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	DescribePhase(ctx, installbase.ErrorPhase)
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package k8singress
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func ingressClassSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	ingressClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: installbase.IngressClassName,
+		},
+		Spec: networkingv1.IngressClassSpec{
+			Controller: installbase.IngressClassController,
+		},
+	}
+
+	return func(ctx *installbase.StageContext) error {
+		err := installbase.DeployIngressClass(ingressClass, ctx.Client)
+		if err != nil {
+			return errors.Wrapf(err, "create IngressClass %s", ingressClass.Name)
+		}
+		return nil
+	}
+}
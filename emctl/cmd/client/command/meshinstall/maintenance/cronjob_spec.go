@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const maintenanceContainerName = "maintenance"
+
+func maintenanceLabel() map[string]string {
+	selector := map[string]string{}
+	selector["app"] = installbase.MaintenanceCronJobName
+	return selector
+}
+
+func cronJobSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		cronJob, err := cronJobBaseSpec(ctx.Flags)
+		if err != nil {
+			return errors.Wrap(err, "build cronjob spec failed")
+		}
+
+		err = installbase.DeployCronJob(cronJob, ctx.Client, ctx.Flags.MeshNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy cronjob %s failed", cronJob.Name)
+		}
+		return nil
+	}
+}
+
+func cronJobBaseSpec(installFlags *flags.Install) (*batchv1.CronJob, error) {
+	container, err := installbase.AcceptContainerVisitor(maintenanceContainerName,
+		installFlags.ImageRegistryURL+"/"+installFlags.MaintenanceImage,
+		v1.PullIfNotPresent,
+		newVisitor(installFlags))
+	if err != nil {
+		return nil, errors.Wrap(err, "generate container spec failed")
+	}
+
+	// NOTE: Only one maintenance runs at a time, a failed one is
+	// not retried until the next schedule.
+	var backoffLimit int32
+	cronJob := &batchv1.CronJob{}
+	cronJob.Name = installbase.MaintenanceCronJobName
+	cronJob.Labels = maintenanceLabel()
+	cronJob.Spec.Schedule = installFlags.MaintenanceSchedule
+	cronJob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronJob.Spec.JobTemplate.Spec.BackoffLimit = &backoffLimit
+	cronJob.Spec.JobTemplate.Spec.Template.Labels = maintenanceLabel()
+	cronJob.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyNever
+	cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers = []v1.Container{*container}
+	return cronJob, nil
+}
+
+type containerVisitor struct {
+	installFlags *flags.Install
+}
+
+func newVisitor(installFlags *flags.Install) installbase.ContainerVisitor {
+	return &containerVisitor{installFlags}
+}
+
+func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []string, args []string) {
+	endpoint := fmt.Sprintf("http://%s.%s:%d", v.installFlags.EgServiceName, v.installFlags.MeshNamespace, v.installFlags.EgClientPort)
+	// NOTE: The image entrypoint is emctl.
+	return nil, []string{
+		"maintenance", "run",
+		"--endpoint", endpoint,
+		"--retain-revisions", strconv.FormatInt(v.installFlags.MaintenanceRetainRevisions, 10),
+	}
+}
+
+func (v *containerVisitor) VisitorContainerPorts(c *v1.Container) ([]v1.ContainerPort, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorEnvs(c *v1.Container) ([]v1.EnvVar, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorEnvFrom(c *v1.Container) ([]v1.EnvFromSource, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.ResourceRequirements, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMount, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorVolumeDevices(c *v1.Container) ([]v1.VolumeDevice, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorLivenessProbe(c *v1.Container) (*v1.Probe, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorReadinessProbe(c *v1.Container) (*v1.Probe, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorLifeCycle(c *v1.Container) (*v1.Lifecycle, error) {
+	return nil, nil
+}
+
+func (v *containerVisitor) VisitorSecurityContext(c *v1.Container) (*v1.SecurityContext, error) {
+	return nil, nil
+}
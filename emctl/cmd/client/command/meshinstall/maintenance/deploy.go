@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+)
+
+// Deploy deploy the cronjob maintaining the control plane storage
+func Deploy(ctx *installbase.StageContext) error {
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		cronJobSpec(ctx),
+	})
+}
+
+// PreCheck check prerequisite for installing the control plane maintenance
+func PreCheck(context *installbase.StageContext) error {
+	fields := strings.Fields(context.Flags.MaintenanceSchedule)
+	descriptor := len(fields) == 1 && strings.HasPrefix(fields[0], "@")
+	if len(fields) != 5 && !descriptor {
+		return errors.Errorf("invalid --maintenance-schedule %q, a cron schedule of 5 fields or a descriptor like @weekly is required",
+			context.Flags.MaintenanceSchedule)
+	}
+	if context.Flags.MaintenanceRetainRevisions < 0 {
+		return errors.Errorf("--maintenance-retain-revisions must not be negative, got %d",
+			context.Flags.MaintenanceRetainRevisions)
+	}
+	return nil
+}
+
+// Clear will clear all installed resource about the control plane maintenance
+func Clear(context *installbase.StageContext) error {
+	batchV1Resources := [][]string{
+		{"cronjobs", installbase.MaintenanceCronJobName},
+	}
+
+	installbase.DeleteResources(context.Client, batchV1Resources, context.Flags.MeshNamespace, installbase.DeleteCronJobResource)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of the control plane maintenance
+func DescribePhase(context *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to install control plane maintenance in the namespace:%s", context.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nControl plane maintenance deployed successfully, cronjob:%s, schedule:%s\n"+
+			"Run it on demand by: kubectl -n %s create job --from=cronjob/%s %s-manual",
+			installbase.MaintenanceCronJobName, context.Flags.MaintenanceSchedule,
+			context.Flags.MeshNamespace, installbase.MaintenanceCronJobName, installbase.MaintenanceCronJobName)
+	}
+	return ""
+}
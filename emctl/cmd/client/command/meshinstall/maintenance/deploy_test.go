@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package maintenance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func prepareContext() (*installbase.StageContext, *fake.Clientset, *extensionfake.Clientset) {
+	client := fake.NewSimpleClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client, extensionClient
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client, _ := prepareContext()
+
+	err := Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	cronJob, err := client.BatchV1().CronJobs(ctx.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.MaintenanceCronJobName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get cronjob failed: %v", err)
+	}
+	if cronJob.Spec.Schedule != flags.DefaultMaintenanceSchedule {
+		t.Errorf("schedule: want %s, got %s", flags.DefaultMaintenanceSchedule, cronJob.Spec.Schedule)
+	}
+	args := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args
+	if len(args) < 2 || args[0] != "maintenance" || args[1] != "run" {
+		t.Errorf("args should run emctl maintenance run, got %v", args)
+	}
+
+	Clear(ctx)
+}
+
+func TestDescribePhase(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	DescribePhase(ctx, installbase.ErrorPhase)
+}
+
+func TestPreCheck(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check failed: %v", err)
+	}
+
+	ctx.Flags.MaintenanceSchedule = "@weekly 3"
+	if err := PreCheck(ctx); err == nil {
+		t.Fatalf("pre check with invalid schedule should fail")
+	}
+}
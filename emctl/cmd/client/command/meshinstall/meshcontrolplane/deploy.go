@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshcontrolplane
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var meshControlPlaneResource = schema.GroupVersionResource{
+	Group:    "mesh.megaease.com",
+	Version:  "v1",
+	Resource: "meshcontrolplanes",
+}
+
+// Deploy creates the MeshControlPlane describing the installation, the operator
+// applies changes of it to components afterwards. Installing add-ons only
+// appends them to the existing MeshControlPlane.
+func Deploy(ctx *installbase.StageContext) error {
+	client := resourceClient(ctx)
+	old, err := client.Get(ctx.Stage(), installbase.MeshControlPlaneName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "get MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+
+	if apierrors.IsNotFound(err) {
+		if ctx.Flags.OnlyAddOn {
+			common.Warnf("MeshControlPlane %s not found, add-ons aren't recorded", installbase.MeshControlPlaneName)
+			return nil
+		}
+		_, err = client.Create(ctx.Stage(), meshControlPlaneObject(ctx.Flags, nil), metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "create MeshControlPlane %s", installbase.MeshControlPlaneName)
+		}
+		return nil
+	}
+
+	oldAddOns, _, _ := unstructured.NestedStringSlice(old.Object, "spec", "addOns")
+	obj := old
+	if ctx.Flags.OnlyAddOn {
+		err = unstructured.SetNestedStringSlice(obj.Object, mergeAddOns(oldAddOns, ctx.Flags.AddOns), "spec", "addOns")
+	} else {
+		err = unstructured.SetNestedField(obj.Object, meshControlPlaneSpec(ctx.Flags, oldAddOns), "spec")
+	}
+	if err != nil {
+		return errors.Wrapf(err, "set spec of MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+
+	_, err = client.Update(ctx.Stage(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "update MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+	return nil
+}
+
+// PreCheck checks prerequisite for creating the MeshControlPlane.
+func PreCheck(ctx *installbase.StageContext) error {
+	return nil
+}
+
+// Clear deletes the MeshControlPlane, so the operator stops applying it.
+func Clear(ctx *installbase.StageContext) error {
+	err := resourceClient(ctx).Delete(ctx.Stage(), installbase.MeshControlPlaneName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "delete MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+	return nil
+}
+
+// RemoveAddOns removes add-ons reset by emctl reset --only-add-on from the MeshControlPlane.
+func RemoveAddOns(ctx *installbase.StageContext, addOns []string) error {
+	client := resourceClient(ctx)
+	obj, err := client.Get(ctx.Stage(), installbase.MeshControlPlaneName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "get MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+
+	removed := map[string]bool{}
+	for _, addOn := range addOns {
+		removed[strings.ToLower(addOn)] = true
+	}
+	oldAddOns, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "addOns")
+	kept := []string{}
+	for _, addOn := range oldAddOns {
+		if !removed[addOn] {
+			kept = append(kept, addOn)
+		}
+	}
+
+	err = unstructured.SetNestedStringSlice(obj.Object, kept, "spec", "addOns")
+	if err != nil {
+		return errors.Wrapf(err, "set add-ons of MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+	_, err = client.Update(ctx.Stage(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "update MeshControlPlane %s", installbase.MeshControlPlaneName)
+	}
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of creating the MeshControlPlane
+func DescribePhase(ctx *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to record the installation in MeshControlPlane %s/%s",
+			ctx.Flags.MeshNamespace, installbase.MeshControlPlaneName)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nMeshControlPlane %s/%s recorded successfully, edit it to change the installation",
+			ctx.Flags.MeshNamespace, installbase.MeshControlPlaneName)
+	}
+	return ""
+}
+
+func resourceClient(ctx *installbase.StageContext) dynamic.ResourceInterface {
+	return ctx.DynamicClient.Resource(meshControlPlaneResource).Namespace(ctx.Flags.MeshNamespace)
+}
+
+func meshControlPlaneObject(installFlags *flags.Install, oldAddOns []string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": meshControlPlaneResource.GroupVersion().String(),
+			"kind":       "MeshControlPlane",
+			"metadata": map[string]interface{}{
+				"name":      installbase.MeshControlPlaneName,
+				"namespace": installFlags.MeshNamespace,
+			},
+			"spec": meshControlPlaneSpec(installFlags, oldAddOns),
+		},
+	}
+}
+
+func meshControlPlaneSpec(installFlags *flags.Install, oldAddOns []string) map[string]interface{} {
+	component := func(replicas int, image string) map[string]interface{} {
+		return map[string]interface{}{
+			"replicas": int64(replicas),
+			"image":    image,
+		}
+	}
+
+	spec := map[string]interface{}{
+		"imageRegistryURL":  installFlags.ImageRegistryURL,
+		"controlPlane":      component(installFlags.EasegressControlPlaneReplicas, installFlags.EasegressImage),
+		"ingressController": component(installFlags.MeshIngressReplicas, installFlags.EasegressImage),
+		"operator":          component(installFlags.EaseMeshOperatorReplicas, installFlags.EaseMeshOperatorImage),
+	}
+
+	addOns := mergeAddOns(oldAddOns, installFlags.AddOns)
+	if len(addOns) != 0 {
+		values := make([]interface{}, 0, len(addOns))
+		for _, addOn := range addOns {
+			values = append(values, addOn)
+		}
+		spec["addOns"] = values
+	}
+	return spec
+}
+
+// mergeAddOns appends new add-ons absent in old ones in lower case.
+func mergeAddOns(old, added []string) []string {
+	result := []string{}
+	exists := map[string]bool{}
+	for _, addOns := range [][]string{old, added} {
+		for _, addOn := range addOns {
+			addOn = strings.ToLower(addOn)
+			if exists[addOn] {
+				continue
+			}
+			exists[addOn] = true
+			result = append(result, addOn)
+		}
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshcontrolplane
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func prepareContext() *installbase.StageContext {
+	return &installbase.StageContext{
+		DynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		Flags: &flags.Install{
+			OperationGlobal:               &flags.OperationGlobal{MeshNamespace: "easemesh"},
+			ImageRegistryURL:              "docker.io",
+			EasegressImage:                "megaease/easegress:easemesh",
+			EasegressControlPlaneReplicas: 3,
+			MeshIngressReplicas:           1,
+			EaseMeshOperatorImage:         "megaease/easemesh-operator:latest",
+			EaseMeshOperatorReplicas:      1,
+			AddOns:                        []string{"ShadowService"},
+		},
+	}
+}
+
+func getAddOns(t *testing.T, ctx *installbase.StageContext) []string {
+	obj, err := resourceClient(ctx).Get(ctx.Stage(), installbase.MeshControlPlaneName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get MeshControlPlane failed: %v", err)
+	}
+	addOns, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "addOns")
+	return addOns
+}
+
+func TestDeploy(t *testing.T) {
+	ctx := prepareContext()
+	err := Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+
+	obj, err := resourceClient(ctx).Get(ctx.Stage(), installbase.MeshControlPlaneName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get MeshControlPlane failed: %v", err)
+	}
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "controlPlane", "replicas")
+	if replicas != 3 {
+		t.Errorf("expected 3 replicas of control plane, got %d", replicas)
+	}
+	image, _, _ := unstructured.NestedString(obj.Object, "spec", "operator", "image")
+	if image != "megaease/easemesh-operator:latest" {
+		t.Errorf("unexpected image of operator %s", image)
+	}
+
+	ctx.Flags.OnlyAddOn = true
+	ctx.Flags.AddOns = []string{"gitops", "shadowservice"}
+	err = Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy add-ons failed: %v", err)
+	}
+	if addOns := getAddOns(t, ctx); !reflect.DeepEqual(addOns, []string{"shadowservice", "gitops"}) {
+		t.Errorf("unexpected add-ons %v", addOns)
+	}
+
+	err = RemoveAddOns(ctx, []string{"ShadowService"})
+	if err != nil {
+		t.Fatalf("remove add-ons failed: %v", err)
+	}
+	if addOns := getAddOns(t, ctx); !reflect.DeepEqual(addOns, []string{"gitops"}) {
+		t.Errorf("unexpected add-ons %v", addOns)
+	}
+
+	err = Clear(ctx)
+	if err != nil {
+		t.Fatalf("clear failed: %v", err)
+	}
+	err = Clear(ctx)
+	if err != nil {
+		t.Fatalf("clear twice failed: %v", err)
+	}
+}
+
+func TestDeployOnlyAddOnWithoutMeshControlPlane(t *testing.T) {
+	ctx := prepareContext()
+	ctx.Flags.OnlyAddOn = true
+	err := Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+	err = RemoveAddOns(ctx, []string{"shadowservice"})
+	if err != nil {
+		t.Fatalf("remove add-ons failed: %v", err)
+	}
+}
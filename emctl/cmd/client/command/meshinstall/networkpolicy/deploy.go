@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package networkpolicy
+
+import (
+	"fmt"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+)
+
+// Deploy deploys network policies of the control plane and the operator
+func Deploy(ctx *installbase.StageContext) error {
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		controlPlanePolicySpec(ctx),
+		operatorPolicySpec(ctx),
+	})
+}
+
+// PreCheck check prerequisite for deploying network policies
+func PreCheck(ctx *installbase.StageContext) error {
+	return nil
+}
+
+// Clear clears network policies of the control plane and the operator
+func Clear(ctx *installbase.StageContext) error {
+	networkingV1Resources := [][]string{
+		{"networkpolicies", installbase.ControlPlaneNetworkPolicyName},
+		{"networkpolicies", installbase.OperatorNetworkPolicyName},
+	}
+
+	installbase.DeleteResources(ctx.Client, networkingV1Resources, ctx.Flags.MeshNamespace, installbase.DeleteNetworkPolicyResource)
+	return nil
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of deploying network policies
+func DescribePhase(ctx *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to deploy network policies in the namespace: %s", ctx.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nNetwork policies deployed successfully: %s, %s\n"+
+			"Pods injected before the operator labels them with %s must be restarted to connect the control plane",
+			installbase.ControlPlaneNetworkPolicyName, installbase.OperatorNetworkPolicyName, installbase.SidecarInjectedLabelKey)
+	}
+	return ""
+}
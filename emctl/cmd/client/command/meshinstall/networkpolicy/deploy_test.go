@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func prepareContext() (*installbase.StageContext, *fake.Clientset, *extensionfake.Clientset) {
+	client := fake.NewSimpleClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	return meshtesting.PrepareInstallContext(cmd, client, extensionClient, install), client, extensionClient
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	ctx.Flags.OperatorMetricsScrape = true
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check failed: %v", err)
+	}
+
+	// Deploy twice to cover updating.
+	for i := 0; i < 2; i++ {
+		if err := Deploy(ctx); err != nil {
+			t.Fatalf("deploy network policies failed: %v", err)
+		}
+	}
+
+	policies := client.NetworkingV1().NetworkPolicies(ctx.Flags.MeshNamespace)
+	controlPlane, err := policies.Get(context.TODO(), installbase.ControlPlaneNetworkPolicyName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("network policy %s should be deployed: %v", installbase.ControlPlaneNetworkPolicyName, err)
+	}
+	if len(controlPlane.Spec.Ingress) != 3 {
+		t.Fatalf("expect 3 ingress rules of the control plane, got %d", len(controlPlane.Spec.Ingress))
+	}
+	sidecarRule := controlPlane.Spec.Ingress[1]
+	if sidecarRule.From[0].PodSelector.MatchLabels[installbase.SidecarInjectedLabelKey] != "true" {
+		t.Fatalf("sidecars should be selected by %s", installbase.SidecarInjectedLabelKey)
+	}
+	clientPortAllowed := false
+	for _, port := range sidecarRule.Ports {
+		if port.Port.IntValue() == ctx.Flags.EgClientPort {
+			clientPortAllowed = true
+		}
+	}
+	if !clientPortAllowed {
+		t.Fatalf("client port %d should be allowed for sidecars", ctx.Flags.EgClientPort)
+	}
+
+	adminRule := controlPlane.Spec.Ingress[2]
+	if len(adminRule.From) != 0 || len(adminRule.Ports) != 1 || adminRule.Ports[0].Port.IntValue() != ctx.Flags.EgAdminPort {
+		t.Fatalf("only admin port %d should be allowed from anywhere", ctx.Flags.EgAdminPort)
+	}
+
+	operator, err := policies.Get(context.TODO(), installbase.OperatorNetworkPolicyName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("network policy %s should be deployed: %v", installbase.OperatorNetworkPolicyName, err)
+	}
+	if len(operator.Spec.Ingress[0].Ports) != 4 {
+		t.Fatalf("expect 4 ports of the operator with metrics, got %d", len(operator.Spec.Ingress[0].Ports))
+	}
+
+	Clear(ctx)
+	_, err = policies.Get(context.TODO(), installbase.ControlPlaneNetworkPolicyName, metav1.GetOptions{})
+	if err == nil {
+		t.Fatalf("network policy %s should be cleared", installbase.ControlPlaneNetworkPolicyName)
+	}
+}
+
+func TestDescribePhase(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	DescribePhase(ctx, installbase.BeginPhase)
+	DescribePhase(ctx, installbase.EndPhase)
+	DescribePhase(ctx, installbase.ErrorPhase)
+}
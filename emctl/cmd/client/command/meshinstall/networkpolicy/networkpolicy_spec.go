@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package networkpolicy
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func policyPorts(ports ...int) []networkingv1.NetworkPolicyPort {
+	result := []networkingv1.NetworkPolicyPort{}
+	for _, port := range ports {
+		protocol, port := v1.ProtocolTCP, intstr.FromInt(port)
+		result = append(result, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+	return result
+}
+
+// controlPlanePolicySpec only allows the members of the control plane, other
+// components in the mesh namespace and sidecars to connect the cluster ports
+// of the control plane. The admin port is open to emctl out of the cluster.
+func controlPlanePolicySpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	clusterPorts := policyPorts(ctx.Flags.EgPeerPort, ctx.Flags.EgClientPort, ctx.Flags.EgAdminPort)
+
+	networkPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.ControlPlaneNetworkPolicyName,
+			Namespace: ctx.Flags.MeshNamespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": installbase.ControlPlaneStatefulSetName},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					// NOTE: The empty pod selector selects all pods in the mesh namespace.
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+					Ports: clusterPorts,
+				},
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{},
+							PodSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{installbase.SidecarInjectedLabelKey: "true"},
+							},
+						},
+					},
+					Ports: clusterPorts,
+				},
+				{
+					Ports: policyPorts(ctx.Flags.EgAdminPort),
+				},
+			},
+		},
+	}
+
+	return func(ctx *installbase.StageContext) error {
+		return installbase.DeployNetworkPolicy(networkPolicy, ctx.Client, ctx.Flags.MeshNamespace)
+	}
+}
+
+// operatorPolicySpec only allows connections to the webhook, the metrics and
+// the health probes of the operator. The API server calling the webhook isn't
+// selectable by pod selectors, so they're open to all sources.
+func operatorPolicySpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	ports := []int{installbase.OperatorMutatingWebhookPort, installbase.OperatorRBACProxyPort, installbase.OperatorProbePort}
+	if ctx.Flags.OperatorMetricsScrape {
+		ports = append(ports, installbase.OperatorMetricsPort)
+	}
+	if ctx.Flags.EnableAPIAggregation {
+		ports = append(ports, installbase.OperatorAPIAggregationPort)
+	}
+
+	networkPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.OperatorNetworkPolicyName,
+			Namespace: ctx.Flags.MeshNamespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": installbase.OperatorDeploymentName},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{Ports: policyPorts(ports...)},
+			},
+		},
+	}
+
+	return func(ctx *installbase.StageContext) error {
+		return installbase.DeployNetworkPolicy(networkPolicy, ctx.Client, ctx.Flags.MeshNamespace)
+	}
+}
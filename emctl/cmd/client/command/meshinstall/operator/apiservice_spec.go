@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator
+
+import (
+	"encoding/base64"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// NOTE: The Role and the ClusterRole are built-in ones, which let
+	// aggregated API servers authenticate and authorize requests.
+	authReaderRole             = "extension-apiserver-authentication-reader"
+	authReaderRoleBinding      = "mesh-operator-auth-reader-rolebinding"
+	authDelegatorClusterRole   = "system:auth-delegator"
+	authDelegatorRoleBinding   = "mesh-operator-auth-delegator-rolebinding"
+	authConfigMapNamespace     = "kube-system"
+	authConfigMapName          = "extension-apiserver-authentication"
+	authConfigMapClientCAKey   = "requestheader-client-ca-file"
+	aggregatedAPIGroup         = "admin.mesh.megaease.com"
+	aggregatedAPIVersion       = "v1alpha1"
+	aggregatedAPIGroupPriority = 1000
+)
+
+var apiServiceResource = schema.GroupVersionResource{
+	Group:    "apiregistration.k8s.io",
+	Version:  "v1",
+	Resource: "apiservices",
+}
+
+// checkAPIAggregation checks the API server enables the aggregation layer,
+// which proxies requests to the operator with the authenticated user.
+func checkAPIAggregation(ctx *installbase.StageContext) error {
+	if !ctx.Flags.EnableAPIAggregation {
+		return nil
+	}
+
+	configMap, err := ctx.Client.CoreV1().ConfigMaps(authConfigMapNamespace).Get(ctx.Stage(), authConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get configmap %s/%s", authConfigMapNamespace, authConfigMapName)
+	}
+	if configMap.Data[authConfigMapClientCAKey] == "" {
+		return errors.Errorf("%s not found in configmap %s/%s, the aggregation layer of the API server isn't enabled",
+			authConfigMapClientCAKey, authConfigMapNamespace, authConfigMapName)
+	}
+	return nil
+}
+
+// authDelegationSpec lets the operator read how the API server proxies
+// requests and review accesses of the proxied users.
+func authDelegationSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	subjects := []rbacv1.Subject{
+		{
+			Kind:      "ServiceAccount",
+			Name:      "default",
+			Namespace: ctx.Flags.MeshNamespace,
+		},
+	}
+	readerRoleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      authReaderRoleBinding,
+			Namespace: authConfigMapNamespace,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     authReaderRole,
+		},
+		Subjects: subjects,
+	}
+	delegatorClusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: authDelegatorRoleBinding,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     authDelegatorClusterRole,
+		},
+		Subjects: subjects,
+	}
+
+	return func(ctx *installbase.StageContext) error {
+		if !ctx.Flags.EnableAPIAggregation {
+			return nil
+		}
+
+		err := installbase.DeployRoleBinding(readerRoleBinding, ctx.Client, authConfigMapNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "create roleBinding %s", readerRoleBinding.Name)
+		}
+		err = installbase.DeployClusterRoleBinding(delegatorClusterRoleBinding, ctx.Client)
+		if err != nil {
+			return errors.Wrapf(err, "create clusterRoleBinding %s", delegatorClusterRoleBinding.Name)
+		}
+		return nil
+	}
+}
+
+// apiServiceSpec registers the aggregated API served by the operator. It's
+// registered after the operator is ready, otherwise discovery of kubectl
+// fails on the unavailable APIService.
+func apiServiceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		if !ctx.Flags.EnableAPIAggregation {
+			return nil
+		}
+
+		secret, err := ctx.Client.CoreV1().Secrets(ctx.Flags.MeshNamespace).Get(ctx.Stage(), installbase.OperatorSecretName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		caBundle, exists := secret.Data[installbase.OperatorSecretCertFileName]
+		if !exists {
+			return errors.Errorf("key %v in secret %s not found",
+				installbase.OperatorSecretCertFileName,
+				installbase.OperatorSecretName)
+		}
+
+		client := ctx.DynamicClient.Resource(apiServiceResource)
+		obj := apiServiceObject(ctx.Flags.MeshNamespace, caBundle)
+		old, err := client.Get(ctx.Stage(), installbase.OperatorAPIServiceName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = client.Create(ctx.Stage(), obj, metav1.CreateOptions{})
+			return errors.Wrapf(err, "create APIService %s", installbase.OperatorAPIServiceName)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "get APIService %s", installbase.OperatorAPIServiceName)
+		}
+
+		obj.SetResourceVersion(old.GetResourceVersion())
+		_, err = client.Update(ctx.Stage(), obj, metav1.UpdateOptions{})
+		return errors.Wrapf(err, "update APIService %s", installbase.OperatorAPIServiceName)
+	}
+}
+
+func apiServiceObject(namespace string, caBundle []byte) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiServiceResource.GroupVersion().String(),
+			"kind":       "APIService",
+			"metadata": map[string]interface{}{
+				"name": installbase.OperatorAPIServiceName,
+			},
+			"spec": map[string]interface{}{
+				"group":   aggregatedAPIGroup,
+				"version": aggregatedAPIVersion,
+				"service": map[string]interface{}{
+					"name":      installbase.OperatorServiceName,
+					"namespace": namespace,
+					"port":      int64(installbase.OperatorAPIAggregationPort),
+				},
+				"caBundle":             base64.StdEncoding.EncodeToString(caBundle),
+				"groupPriorityMinimum": int64(aggregatedAPIGroupPriority),
+				"versionPriority":      int64(15),
+			},
+		},
+	}
+}
+
+// clearAPIAggregation deletes the APIService and the bindings of the auth delegation.
+func clearAPIAggregation(ctx *installbase.StageContext) {
+	if ctx.DynamicClient != nil {
+		err := ctx.DynamicClient.Resource(apiServiceResource).Delete(ctx.Stage(), installbase.OperatorAPIServiceName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			common.Warnf("delete APIService %s failed: %v", installbase.OperatorAPIServiceName, err)
+		}
+	}
+
+	installbase.DeleteResources(ctx.Client, [][]string{{"rolebindings", authReaderRoleBinding}},
+		authConfigMapNamespace, installbase.DeleteRbacV1Resources)
+	installbase.DeleteResources(ctx.Client, [][]string{{"clusterrolebindings", authDelegatorRoleBinding}},
+		ctx.Flags.MeshNamespace, installbase.DeleteRbacV1Resources)
+}
@@ -19,9 +19,9 @@ package operator
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 
-	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 
 	"github.com/pkg/errors"
@@ -31,13 +31,21 @@ import (
 )
 
 func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	// NOTE: The metrics are only reachable via kube-rbac-proxy,
+	// unless they are exposed for scraping.
+	metricsAddr := "127.0.0.1:" + strconv.Itoa(installbase.OperatorMetricsPort)
+	if ctx.Flags.OperatorMetricsScrape {
+		metricsAddr = ":" + strconv.Itoa(installbase.OperatorMetricsPort)
+	}
+
 	cfg := installbase.MeshOperatorConfig{
 		ImageRegistryURL:          ctx.Flags.ImageRegistryURL,
 		ClusterName:               installbase.ControlPlaneStatefulSetName,
-		ClusterJoinURLs:           []string{"http://" + flags.DefaultMeshControlPlaneHeadfulServiceName + "." + ctx.Flags.MeshNamespace + ":" + strconv.Itoa(ctx.Flags.EgPeerPort)},
-		MetricsAddr:               "127.0.0.1:8080",
-		EnableLeaderElection:      false,
-		ProbeAddr:                 ":8081",
+		ClusterJoinURLs:           []string{"http://" + ctx.Flags.EgServiceName + "." + ctx.Flags.MeshNamespace + ":" + strconv.Itoa(ctx.Flags.EgServicePeerPort)},
+		APIAddr:                   ctx.Flags.EgServiceName + "." + ctx.Flags.MeshNamespace + ":" + strconv.Itoa(ctx.Flags.EgServiceAdminPort),
+		MetricsAddr:               metricsAddr,
+		EnableLeaderElection:      true,
+		ProbeAddr:                 ":" + strconv.Itoa(installbase.OperatorProbePort),
 		WebhookPort:               installbase.OperatorMutatingWebhookPort,
 		CertDir:                   installbase.OperatorSecretVolumeMountPath,
 		CertName:                  installbase.OperatorSecretCertFileName,
@@ -45,6 +53,31 @@ func configMapSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		SidecarImageName:          installbase.SidecarImageName,
 		AgentInitializerImageName: installbase.AgentInitializerImageName,
 		Log4jConfigName:           installbase.AgentLog4jConfigName,
+		EnableGatewayAPI:          ctx.Flags.EnableGatewayAPI,
+		EnableK8sIngress:          ctx.Flags.EnableK8sIngress,
+		EnableMeshControlPlane:    true,
+		EnableSelfHeal:            ctx.Flags.SelfHeal,
+		EnableAPIAggregation:      ctx.Flags.EnableAPIAggregation,
+		APIAggregationPort:        installbase.OperatorAPIAggregationPort,
+		SidecarDNSCapture:         ctx.Flags.SidecarDNSCapture,
+		SidecarDNSUpstream:        ctx.Flags.SidecarDNSUpstream,
+		ClusterDomain:             ctx.Flags.ClusterDomain,
+		SpringCloudConfigURI:      ctx.Flags.SpringCloudConfigURI,
+		EnablePprof:               ctx.Flags.OperatorEnablePprof,
+		SidecarInjectionTemplate:  path.Join(installbase.SidecarInjectionTemplateVolumeMountPath, installbase.SidecarInjectionTemplateConfigMapKey),
+
+		HoldApplicationUntilSidecarReady: ctx.Flags.HoldApplicationUntilSidecarReady,
+		NativeSidecar:                    ctx.Flags.NativeSidecar,
+		EnableServiceAutoRegistration:    ctx.Flags.ServiceAutoRegistration,
+		EnableTrafficPolicyAnnotations:   ctx.Flags.TrafficPolicyAnnotations,
+	}
+	if ctx.Flags.SidecarDrainDuration > 0 {
+		cfg.SidecarDrainDuration = ctx.Flags.SidecarDrainDuration.String()
+	}
+	if ctx.Flags.TrafficInterception != "" {
+		cfg.TrafficInterception = ctx.Flags.TrafficInterception
+		cfg.InterceptionImageName = ctx.Flags.InterceptionImage
+		cfg.InterceptionPorts = ctx.Flags.InterceptionPorts
 	}
 
 	configMap := &v1.ConfigMap{
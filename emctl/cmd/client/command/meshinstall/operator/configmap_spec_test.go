@@ -18,9 +18,15 @@
 package operator
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	testclient "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -33,3 +39,80 @@ func TestDeployOperatorConfigMap(t *testing.T) {
 		t.Fatalf("deployment operator configmap err %s", err)
 	}
 }
+
+func TestDeployOperatorConfigMapMetricsScrape(t *testing.T) {
+	client := testclient.NewSimpleClientset()
+	stageContext := fake.NewStageContextForApply(client, nil)
+	stageContext.Flags.OperatorMetricsScrape = true
+	stageContext.Flags.OperatorEnablePprof = true
+
+	err := configMapSpec(stageContext).Deploy(stageContext)
+	if err != nil {
+		t.Fatalf("deployment operator configmap err %s", err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(stageContext.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.OperatorConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get operator configmap err %s", err)
+	}
+	config := configMap.Data[installbase.OperatorConfigMapKey]
+	for _, want := range []string{"metrics-bind-address: :8080", "enable-pprof: true"} {
+		if !strings.Contains(config, want) {
+			t.Errorf("operator config should contain %q, got:\n%s", want, config)
+		}
+	}
+}
+
+func TestDeployOperatorConfigMapSidecarLifecycle(t *testing.T) {
+	client := testclient.NewSimpleClientset()
+	stageContext := fake.NewStageContextForApply(client, nil)
+	stageContext.Flags.SidecarDrainDuration = 20 * time.Second
+	stageContext.Flags.HoldApplicationUntilSidecarReady = true
+
+	err := configMapSpec(stageContext).Deploy(stageContext)
+	if err != nil {
+		t.Fatalf("deployment operator configmap err %s", err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(stageContext.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.OperatorConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get operator configmap err %s", err)
+	}
+	config := configMap.Data[installbase.OperatorConfigMapKey]
+	for _, want := range []string{"sidecar-drain-duration: 20s", "hold-application-until-sidecar-ready: true"} {
+		if !strings.Contains(config, want) {
+			t.Errorf("operator config should contain %q, got:\n%s", want, config)
+		}
+	}
+}
+
+func TestDeployOperatorConfigMapTrafficInterception(t *testing.T) {
+	client := testclient.NewSimpleClientset()
+	stageContext := fake.NewStageContextForApply(client, nil)
+	stageContext.Flags.TrafficInterception = installbase.TrafficInterceptionIptables
+	stageContext.Flags.InterceptionImage = "registry.example.com/debian-iptables:buster-v1.6.7"
+	stageContext.Flags.InterceptionPorts = []int{80, 8080}
+
+	err := configMapSpec(stageContext).Deploy(stageContext)
+	if err != nil {
+		t.Fatalf("deployment operator configmap err %s", err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(stageContext.Flags.MeshNamespace).
+		Get(context.TODO(), installbase.OperatorConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get operator configmap err %s", err)
+	}
+	config := configMap.Data[installbase.OperatorConfigMapKey]
+	for _, want := range []string{
+		"traffic-interception: iptables",
+		"interception-image-name: registry.example.com/debian-iptables:buster-v1.6.7",
+		"interception-ports:\n- 80\n- 8080\n",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("operator config should contain %q, got:\n%s", want, config)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator
+
+import (
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// conversionWebhookSpec makes the API server convert MeshDeployments between
+// versions through the operator. It's set here instead of in the CRD, since
+// the certificate of the operator is issued while deploying the operator.
+func conversionWebhookSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		secret, err := ctx.Client.CoreV1().Secrets(ctx.Flags.MeshNamespace).Get(ctx.Stage(), installbase.OperatorSecretName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		caBundle, exists := secret.Data[installbase.OperatorSecretCertFileName]
+		if !exists {
+			return errors.Errorf("key %v in secret %s not found",
+				installbase.OperatorSecretCertFileName,
+				installbase.OperatorSecretName)
+		}
+
+		crd, err := ctx.APIExtensionsClient.ApiextensionsV1().CustomResourceDefinitions().
+			Get(ctx.Stage(), installbase.MeshDeploymentCRDName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "get CRD %s", installbase.MeshDeploymentCRDName)
+		}
+
+		crd.Spec.Conversion = conversionSpec(ctx.Flags.MeshNamespace, caBundle)
+		err = installbase.DeployCustomResourceDefinition(crd, ctx.APIExtensionsClient)
+		if err != nil {
+			return errors.Wrapf(err, "set conversion webhook of CRD %s", crd.Name)
+		}
+		return nil
+	}
+}
+
+func conversionSpec(namespace string, caBundle []byte) *apiextensionsv1.CustomResourceConversion {
+	path := installbase.OperatorConversionWebhookPath
+	port := int32(installbase.OperatorMutatingWebhookPort)
+
+	return &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Name:      installbase.OperatorServiceName,
+					Namespace: namespace,
+					Path:      &path,
+					Port:      &port,
+				},
+				CABundle: caBundle,
+			},
+			// NOTE: The operator serves ConversionReview of v1beta1 only.
+			ConversionReviewVersions: []string{"v1beta1"},
+		},
+	}
+}
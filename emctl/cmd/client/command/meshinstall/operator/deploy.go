@@ -40,6 +40,9 @@ const (
 
 	proxyClusterRole        = "mesh-operator-proxy-role"
 	proxyClusterRoleBinding = "mesh-operator-proxy-rolebinding"
+
+	// maxInterceptionPorts is the limit of ports of the iptables multiport match.
+	maxInterceptionPorts = 15
 )
 
 // Deploy deploy resources of operator
@@ -48,26 +51,72 @@ func Deploy(ctx *installbase.StageContext) error {
 		[]installbase.InstallFunc{
 			secretSpec(ctx),
 			configMapSpec(ctx),
+			injectionTemplateSpec(ctx),
 			roleSpec(ctx),
 			clusterRoleSpec(ctx),
 			roleBindingSpec(ctx),
 			clusterRoleBindingSpec(ctx),
+			authDelegationSpec(ctx),
 
 			operatorDeploymentSpec(ctx),
 
 			serviceSpec(ctx),
 			mutatingWebhookSpec(ctx),
+			conversionWebhookSpec(ctx),
 		})
 	if err != nil {
 		return err
 	}
 
-	return checkOperatorStatus(ctx.Client, ctx.Flags)
+	err = checkOperatorStatus(ctx.Client, ctx.Flags)
+	if err != nil {
+		return err
+	}
+
+	return installbase.BatchDeployResources(ctx, []installbase.InstallFunc{apiServiceSpec(ctx)})
 }
 
 // PreCheck check prerequisite for installing mesh operator
 func PreCheck(context *installbase.StageContext) error {
-	// Do nothing
+	if context.Flags.EaseMeshOperatorReplicas < 1 {
+		return errors.Errorf("--operator-replicas must be positive, got %d", context.Flags.EaseMeshOperatorReplicas)
+	}
+	if context.Flags.SidecarInjectionTemplate != "" {
+		_, err := readInjectionTemplate(context.Flags.SidecarInjectionTemplate)
+		if err != nil {
+			return err
+		}
+	}
+	err := checkTrafficInterception(context.Flags)
+	if err != nil {
+		return err
+	}
+	return checkAPIAggregation(context)
+}
+
+// checkTrafficInterception checks the mode and the ports of the traffic interception.
+func checkTrafficInterception(installFlags *flags.Install) error {
+	switch installFlags.TrafficInterception {
+	case "":
+		return nil
+	case installbase.TrafficInterceptionIptables:
+	default:
+		return errors.Errorf("unknown traffic interception %s (support %s)",
+			installFlags.TrafficInterception, installbase.TrafficInterceptionIptables)
+	}
+
+	ports := installFlags.InterceptionPorts
+	if len(ports) == 0 || len(ports) > maxInterceptionPorts {
+		return errors.Errorf("--interception-ports requires 1 to %d ports, got %d", maxInterceptionPorts, len(ports))
+	}
+	for _, port := range ports {
+		if port < 1 || port > 65535 {
+			return errors.Errorf("invalid port %d of --interception-ports", port)
+		}
+		if port == installbase.SidecarIngressPort || port == installbase.SidecarEgressPort {
+			return errors.Errorf("port %d of --interception-ports conflicts with ports of sidecars", port)
+		}
+	}
 	return nil
 }
 
@@ -84,6 +133,7 @@ func Clear(context *installbase.StageContext) error {
 	coreV1Resources := [][]string{
 		{"services", installbase.OperatorServiceName},
 		{"configmaps", installbase.OperatorConfigMapName},
+		{"configmaps", installbase.SidecarInjectionTemplateConfigMapName},
 		{"secrets", installbase.OperatorSecretName},
 	}
 
@@ -102,6 +152,8 @@ func Clear(context *installbase.StageContext) error {
 		{"mutatingwebhookconfigurations", installbase.OperatorMutatingWebhookName},
 	}
 
+	clearAPIAggregation(context)
+
 	installbase.DeleteResources(context.Client, certificateV1BetaResources,
 		context.Flags.MeshNamespace, installbase.DeleteCertificateV1Beta1Resources)
 	installbase.DeleteResources(context.Client, appsV1Resources,
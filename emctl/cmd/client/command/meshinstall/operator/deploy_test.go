@@ -28,17 +28,19 @@ import (
 	appsV1 "k8s.io/api/apps/v1"
 	certv1 "k8s.io/api/certificates/v1"
 	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/kubernetes/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	k8stesting "k8s.io/client-go/testing"
 )
 
-func prepareContext() (*installbase.StageContext, *fake.Clientset, *extensionfake.Clientset) {
-	client := fake.NewSimpleClientset()
+func prepareContext() (*installbase.StageContext, *meshtesting.FakeClientset, *extensionfake.Clientset) {
+	client := meshtesting.NewFakeClientset()
 	extensionClient := extensionfake.NewSimpleClientset()
 
 	install := &flags.Install{}
@@ -59,7 +61,7 @@ func TestDeploy(t *testing.T) {
 	})
 
 	for _, f := range []func(*installbase.StageContext) installbase.InstallFunc{
-		secretSpec, configMapSpec, roleSpec, clusterRoleSpec, roleBindingSpec, clusterRoleBindingSpec,
+		secretSpec, configMapSpec, injectionTemplateSpec, roleSpec, clusterRoleSpec, roleBindingSpec, clusterRoleBindingSpec,
 		operatorDeploymentSpec, serviceSpec, mutatingWebhookSpec,
 	} {
 		f(ctx).Deploy(ctx)
@@ -139,6 +141,80 @@ func TestDeploy(t *testing.T) {
 	checkOperatorStatus(ctx.Client, ctx.Flags)
 }
 
+func TestConversionWebhookSpec(t *testing.T) {
+	ctx, client, extensionClient := prepareContext()
+
+	client.CoreV1().Secrets(ctx.Flags.MeshNamespace).Create(ctx.Stage(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: installbase.OperatorSecretName},
+		Data:       map[string][]byte{installbase.OperatorSecretCertFileName: []byte(helloWorld)},
+	}, metav1.CreateOptions{})
+	extensionClient.ApiextensionsV1().CustomResourceDefinitions().Create(ctx.Stage(), &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: installbase.MeshDeploymentCRDName},
+	}, metav1.CreateOptions{})
+
+	err := conversionWebhookSpec(ctx).Deploy(ctx)
+	if err != nil {
+		t.Fatalf("set conversion webhook failed: %v", err)
+	}
+
+	crd, _ := extensionClient.ApiextensionsV1().CustomResourceDefinitions().
+		Get(ctx.Stage(), installbase.MeshDeploymentCRDName, metav1.GetOptions{})
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter {
+		t.Fatalf("conversion strategy should be webhook, but got %+v", conversion)
+	}
+	if *conversion.Webhook.ClientConfig.Service.Path != installbase.OperatorConversionWebhookPath ||
+		string(conversion.Webhook.ClientConfig.CABundle) != helloWorld {
+		t.Fatalf("unexpected client config of the conversion webhook %+v", conversion.Webhook.ClientConfig)
+	}
+}
+
+func TestAPIAggregation(t *testing.T) {
+	ctx, client, _ := prepareContext()
+	ctx.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	ctx.Flags.EnableAPIAggregation = true
+
+	if err := checkAPIAggregation(ctx); err == nil {
+		t.Fatalf("check should fail without the aggregation layer")
+	}
+	client.CoreV1().ConfigMaps(authConfigMapNamespace).Create(ctx.Stage(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: authConfigMapName},
+		Data:       map[string]string{authConfigMapClientCAKey: helloWorld},
+	}, metav1.CreateOptions{})
+	if err := checkAPIAggregation(ctx); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+
+	client.CoreV1().Secrets(ctx.Flags.MeshNamespace).Create(ctx.Stage(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: installbase.OperatorSecretName},
+		Data:       map[string][]byte{installbase.OperatorSecretCertFileName: []byte(helloWorld)},
+	}, metav1.CreateOptions{})
+	for _, f := range []func(*installbase.StageContext) installbase.InstallFunc{authDelegationSpec, apiServiceSpec, apiServiceSpec} {
+		if err := f(ctx).Deploy(ctx); err != nil {
+			t.Fatalf("deploy api aggregation failed: %v", err)
+		}
+	}
+
+	if _, err := client.RbacV1().RoleBindings(authConfigMapNamespace).Get(ctx.Stage(), authReaderRoleBinding, metav1.GetOptions{}); err != nil {
+		t.Errorf("get rolebinding %s failed: %v", authReaderRoleBinding, err)
+	}
+	apiService, err := ctx.DynamicClient.Resource(apiServiceResource).Get(ctx.Stage(), installbase.OperatorAPIServiceName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get APIService failed: %v", err)
+	}
+	if group, _, _ := unstructured.NestedString(apiService.Object, "spec", "group"); group != aggregatedAPIGroup {
+		t.Errorf("unexpected group %s of APIService", group)
+	}
+
+	clearAPIAggregation(ctx)
+	if _, err := ctx.DynamicClient.Resource(apiServiceResource).Get(ctx.Stage(), installbase.OperatorAPIServiceName, metav1.GetOptions{}); !k8serr.IsNotFound(err) {
+		t.Errorf("APIService should be deleted, got %v", err)
+	}
+	if _, err := client.RbacV1().RoleBindings(authConfigMapNamespace).Get(ctx.Stage(), authReaderRoleBinding, metav1.GetOptions{}); !k8serr.IsNotFound(err) {
+		t.Errorf("rolebinding %s should be deleted, got %v", authReaderRoleBinding, err)
+	}
+}
+
 func TestDescribePhase(t *testing.T) {
 	ctx, _, _ := prepareContext()
 	DescribePhase(ctx, installbase.BeginPhase)
@@ -147,4 +223,44 @@ func TestDescribePhase(t *testing.T) {
 	PreCheck(ctx)
 }
 
+func TestPreCheck(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	ctx.Flags.EaseMeshOperatorReplicas = 3
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("precheck of 3 replicas should succeed, got %v", err)
+	}
+
+	ctx.Flags.EaseMeshOperatorReplicas = 0
+	if err := PreCheck(ctx); err == nil {
+		t.Fatalf("precheck of 0 replicas should fail")
+	}
+}
+
 var helloWorld = "aGVsbG8gd29ybGQK"
+
+func TestCheckTrafficInterception(t *testing.T) {
+	ctx, _, _ := prepareContext()
+	if err := checkTrafficInterception(ctx.Flags); err != nil {
+		t.Fatalf("check without traffic interception failed: %v", err)
+	}
+
+	ctx.Flags.TrafficInterception = installbase.TrafficInterceptionIptables
+	if err := checkTrafficInterception(ctx.Flags); err != nil {
+		t.Fatalf("check default interception ports failed: %v", err)
+	}
+
+	for _, c := range []struct {
+		mode  string
+		ports []int
+	}{
+		{"ebpf", []int{80}},
+		{installbase.TrafficInterceptionIptables, nil},
+		{installbase.TrafficInterceptionIptables, []int{80, 65536}},
+		{installbase.TrafficInterceptionIptables, []int{installbase.SidecarEgressPort}},
+	} {
+		ctx.Flags.TrafficInterception, ctx.Flags.InterceptionPorts = c.mode, c.ports
+		if err := checkTrafficInterception(ctx.Flags); err == nil {
+			t.Errorf("expected interception %s of ports %v fails the check", c.mode, c.ports)
+		}
+	}
+}
@@ -18,6 +18,8 @@
 package operator
 
 import (
+	"strconv"
+
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
 
 	"github.com/pkg/errors"
@@ -28,16 +30,20 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-type deploymentSpecFunc func(ctx *installbase.StageContext) *appsV1.Deployment
+type deploymentSpecFunc func(ctx *installbase.StageContext) (*appsV1.Deployment, error)
 
 func operatorDeploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
-	deployment := deploymentConfigVolumeSpec(
-		deploymentManagerContainerSpec(
-			deploymentRBACContainerSpec(
-				deploymentBaseSpec(deploymentInitialize(nil)))))(ctx)
-
 	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
+		deployment, err := deploymentSecurityProfileSpec(
+			deploymentConfigVolumeSpec(
+				deploymentManagerContainerSpec(
+					deploymentRBACContainerSpec(
+						deploymentBaseSpec(deploymentInitialize(nil))))))(ctx)
+		if err != nil {
+			return errors.Wrap(err, "build deployment spec failed")
+		}
+
+		err = installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
 		if err != nil {
 			return errors.Wrapf(err, "deployment operation %s failed", deployment.Name)
 		}
@@ -52,14 +58,17 @@ func meshOperatorLabels() map[string]string {
 }
 
 func deploymentInitialize(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		return &appsV1.Deployment{}
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		return &appsV1.Deployment{}, nil
 	}
 }
 
 func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
 
 		labels := meshOperatorLabels()
 		spec.Name = installbase.OperatorDeploymentName
@@ -70,42 +79,85 @@ func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 		replicas := int32(ctx.Flags.EaseMeshOperatorReplicas)
 		spec.Spec.Replicas = &replicas
 		spec.Spec.Template.Labels = labels
+		// NOTE: Spread replicas across nodes, so a node failure doesn't stop
+		// sidecar injection, and the standby takes over the leadership.
+		spec.Spec.Template.Spec.Affinity = &v1.Affinity{
+			PodAntiAffinity: &v1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+					{
+						Weight: 100,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: labels,
+							},
+							TopologyKey: "kubernetes.io/hostname",
+						},
+					},
+				},
+			},
+		}
+		if ctx.Flags.OperatorMetricsScrape {
+			spec.Spec.Template.Annotations = map[string]string{
+				"prometheus.io/scrape": "true",
+				"prometheus.io/port":   strconv.Itoa(installbase.OperatorMetricsPort),
+				"prometheus.io/path":   "/metrics",
+			}
+		}
 		spec.Spec.Template.Spec.Containers = []v1.Container{}
 
 		var v int64 = 65532 //?
 		spec.Spec.Template.Spec.SecurityContext = &v1.PodSecurityContext{
 			RunAsUser: &v,
 		}
-		return spec
+		return spec, nil
 	}
 }
 
 func deploymentRBACContainerSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
 		rbacContainer := v1.Container{}
 		rbacContainer.Name = "kube-rbac-proxy"
 		rbacContainer.Image = "gcr.io/kubebuilder/kube-rbac-proxy:v0.5.0"
 		rbacContainer.Ports = []v1.ContainerPort{
 			{
 				Name:          "https",
-				ContainerPort: 8443,
+				ContainerPort: installbase.OperatorRBACProxyPort,
 			},
 		}
 		rbacContainer.Args = []string{
-			"--secure-listen-address=0.0.0.0:8443",
+			"--secure-listen-address=0.0.0.0:" + strconv.Itoa(installbase.OperatorRBACProxyPort),
 			"--upstream=http://127.0.0.1:8080/",
 			"--logtostderr=true",
 			"--v=10",
 		}
 		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, rbacContainer)
-		return spec
+		return spec, nil
+	}
+}
+
+func deploymentSecurityProfileSpec(fn deploymentSpecFunc) deploymentSpecFunc {
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		installbase.ApplySecurityProfile(ctx.Flags, &spec.Spec.Template.Spec, installbase.TmpDir)
+		return spec, nil
 	}
 }
 
 func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// NOTE: The operator injects sidecars without patches if the template is deleted.
+		optional := true
 		spec.Spec.Template.Spec.Volumes = []v1.Volume{
 			{
 				Name: installbase.OperatorConfigMapName,
@@ -125,21 +177,38 @@ func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 					},
 				},
 			},
+			{
+				Name: installbase.SidecarInjectionTemplateConfigMapName,
+				VolumeSource: v1.VolumeSource{
+					ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: installbase.SidecarInjectionTemplateConfigMapName,
+						},
+						Optional: &optional,
+					},
+				},
+			},
 		}
-		return spec
+		return spec, nil
 	}
 }
 
 func deploymentManagerContainerSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(ctx *installbase.StageContext) *appsV1.Deployment {
-		spec := fn(ctx)
-		container, _ := installbase.AcceptContainerVisitor("operator-manager",
+	return func(ctx *installbase.StageContext) (*appsV1.Deployment, error) {
+		spec, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		container, err := installbase.AcceptContainerVisitor("operator-manager",
 			ctx.Flags.ImageRegistryURL+"/"+ctx.Flags.EaseMeshOperatorImage,
 			v1.PullIfNotPresent,
 			newVisitor(ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate container spec failed")
+		}
 
 		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, *container)
-		return spec
+		return spec, nil
 	}
 }
 
@@ -156,12 +225,25 @@ func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []str
 }
 
 func (v *containerVisitor) VisitorContainerPorts(c *v1.Container) ([]v1.ContainerPort, error) {
-	return []v1.ContainerPort{
+	ports := []v1.ContainerPort{
 		{
 			Name:          installbase.OperatorMutatingWebhookPortName,
 			ContainerPort: installbase.OperatorMutatingWebhookPort,
 		},
-	}, nil
+	}
+	if v.ctx.Flags.OperatorMetricsScrape {
+		ports = append(ports, v1.ContainerPort{
+			Name:          installbase.OperatorMetricsPortName,
+			ContainerPort: installbase.OperatorMetricsPort,
+		})
+	}
+	if v.ctx.Flags.EnableAPIAggregation {
+		ports = append(ports, v1.ContainerPort{
+			Name:          installbase.OperatorAPIAggregationPortName,
+			ContainerPort: installbase.OperatorAPIAggregationPort,
+		})
+	}
+	return ports, nil
 }
 
 func (v *containerVisitor) VisitorEnvs(c *v1.Container) ([]v1.EnvVar, error) {
@@ -177,7 +259,11 @@ func (v *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.Res
 	if err != nil {
 		return nil, err
 	}
-	memoryRequest, err := resource.ParseQuantity("1Gi")
+	memory := "1Gi"
+	if v.ctx.Flags.LowResourceRequests {
+		memory = "128Mi"
+	}
+	memoryRequest, err := resource.ParseQuantity(memory)
 	if err != nil {
 		return nil, err
 	}
@@ -214,6 +300,11 @@ func (v *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMoun
 			Name:      installbase.OperatorSecretName,
 			MountPath: installbase.OperatorSecretVolumeMountPath,
 		},
+		{
+			Name:      installbase.SidecarInjectionTemplateConfigMapName,
+			MountPath: installbase.SidecarInjectionTemplateVolumeMountPath,
+			ReadOnly:  true,
+		},
 	}, nil
 }
 
@@ -226,7 +317,7 @@ func (v *containerVisitor) VisitorLivenessProbe(c *v1.Container) (*v1.Probe, err
 		Handler: v1.Handler{
 			HTTPGet: &v1.HTTPGetAction{
 				Path:   "/healthz",
-				Port:   intstr.FromInt(8081),
+				Port:   intstr.FromInt(installbase.OperatorProbePort),
 				Scheme: "HTTP",
 			},
 		},
@@ -240,7 +331,7 @@ func (v *containerVisitor) VisitorReadinessProbe(c *v1.Container) (*v1.Probe, er
 		Handler: v1.Handler{
 			HTTPGet: &v1.HTTPGetAction{
 				Path:   "/readyz",
-				Port:   intstr.FromInt(8081),
+				Port:   intstr.FromInt(installbase.OperatorProbePort),
 				Scheme: "HTTP",
 			},
 		},
@@ -254,5 +345,5 @@ func (v *containerVisitor) VisitorLifeCycle(c *v1.Container) (*v1.Lifecycle, err
 }
 
 func (v *containerVisitor) VisitorSecurityContext(c *v1.Container) (*v1.SecurityContext, error) {
-	return nil, nil
+	return installbase.ContainerSecurityContext(v.ctx.Flags), nil
 }
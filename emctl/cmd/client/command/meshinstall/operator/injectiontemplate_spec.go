@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator
+
+import (
+	"io/ioutil"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultInjectionTemplate is deployed if there is no template, it's edited
+// by kubectl edit configmap, or replaced by --sidecar-injection-template.
+const defaultInjectionTemplate = `# Patches of pod specs applied to pods injected with sidecars, e.g.
+# strategicMergePatches:
+# - containers:
+#   - name: log-shipper
+#     image: fluent/fluent-bit:1.8
+# jsonPatches:
+# - op: add
+#   path: /containers/0/env/-
+#   value: {name: REGION, value: beijing}
+strategicMergePatches: []
+jsonPatches: []
+`
+
+// injectionTemplate is the template of patches applied by the operator after
+// injecting sidecars, strategic merge patches are applied before JSON patches.
+type injectionTemplate struct {
+	StrategicMergePatches []map[string]interface{} `yaml:"strategicMergePatches"`
+	JSONPatches           []map[string]interface{} `yaml:"jsonPatches"`
+}
+
+// readInjectionTemplate reads and validates the template in the file.
+func readInjectionTemplate(file string) (string, error) {
+	buff, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "read sidecar injection template %s", file)
+	}
+
+	template := &injectionTemplate{}
+	err = yaml.UnmarshalStrict(buff, template)
+	if err != nil {
+		return "", errors.Wrapf(err, "unmarshal sidecar injection template %s", file)
+	}
+	for i, patch := range template.JSONPatches {
+		if _, ok := patch["op"].(string); !ok {
+			return "", errors.Errorf("json patch %d of sidecar injection template %s has no op", i, file)
+		}
+		if _, ok := patch["path"].(string); !ok {
+			return "", errors.Errorf("json patch %d of sidecar injection template %s has no path", i, file)
+		}
+	}
+
+	return string(buff), nil
+}
+
+func injectionTemplateSpec(ctx *installbase.StageContext) installbase.InstallFunc {
+	return func(ctx *installbase.StageContext) error {
+		template := defaultInjectionTemplate
+		if ctx.Flags.SidecarInjectionTemplate != "" {
+			var err error
+			template, err = readInjectionTemplate(ctx.Flags.SidecarInjectionTemplate)
+			if err != nil {
+				return err
+			}
+		} else {
+			// NOTE: Keep the template edited in the cluster.
+			_, err := ctx.Client.CoreV1().ConfigMaps(ctx.Flags.MeshNamespace).
+				Get(ctx.Stage(), installbase.SidecarInjectionTemplateConfigMapName, metav1.GetOptions{})
+			if err == nil {
+				return nil
+			}
+			if !k8serrors.IsNotFound(err) {
+				return errors.Wrapf(err, "get configmap %s", installbase.SidecarInjectionTemplateConfigMapName)
+			}
+		}
+
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      installbase.SidecarInjectionTemplateConfigMapName,
+				Namespace: ctx.Flags.MeshNamespace,
+			},
+			Data: map[string]string{
+				installbase.SidecarInjectionTemplateConfigMapKey: template,
+			},
+		}
+		err := installbase.DeployConfigMap(configMap, ctx.Client, ctx.Flags.MeshNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy configmap %s", installbase.SidecarInjectionTemplateConfigMapName)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operator
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeployInjectionTemplate(t *testing.T) {
+	client := testclient.NewSimpleClientset()
+	stageContext := fake.NewStageContextForApply(client, nil)
+
+	getTemplate := func() string {
+		configMap, err := client.CoreV1().ConfigMaps(stageContext.Flags.MeshNamespace).
+			Get(context.TODO(), installbase.SidecarInjectionTemplateConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get injection template configmap err %s", err)
+		}
+		return configMap.Data[installbase.SidecarInjectionTemplateConfigMapKey]
+	}
+
+	err := injectionTemplateSpec(stageContext).Deploy(stageContext)
+	if err != nil {
+		t.Fatalf("deploy injection template err %s", err)
+	}
+	if getTemplate() != defaultInjectionTemplate {
+		t.Fatalf("the default injection template should be deployed")
+	}
+
+	const template = `strategicMergePatches:
+- containers:
+  - name: log-shipper
+    image: fluent/fluent-bit:1.8
+`
+	file := path.Join(t.TempDir(), "template.yaml")
+	if err := ioutil.WriteFile(file, []byte(template), 0644); err != nil {
+		t.Fatalf("write template err %s", err)
+	}
+	stageContext.Flags.SidecarInjectionTemplate = file
+	if err := injectionTemplateSpec(stageContext).Deploy(stageContext); err != nil {
+		t.Fatalf("deploy injection template err %s", err)
+	}
+	if getTemplate() != template {
+		t.Fatalf("the injection template should be replaced, got:\n%s", getTemplate())
+	}
+
+	// NOTE: The template in the cluster is kept without the flag.
+	stageContext.Flags.SidecarInjectionTemplate = ""
+	if err := injectionTemplateSpec(stageContext).Deploy(stageContext); err != nil {
+		t.Fatalf("deploy injection template err %s", err)
+	}
+	if getTemplate() != template {
+		t.Fatalf("the injection template should be kept, got:\n%s", getTemplate())
+	}
+}
+
+func TestReadInjectionTemplate(t *testing.T) {
+	for _, invalid := range []string{
+		"patches: []\n",
+		"jsonPatches:\n- path: /volumes/-\n",
+	} {
+		file := path.Join(t.TempDir(), "template.yaml")
+		if err := ioutil.WriteFile(file, []byte(invalid), 0644); err != nil {
+			t.Fatalf("write template err %s", err)
+		}
+		if _, err := readInjectionTemplate(file); err == nil {
+			t.Errorf("expected error of invalid template:\n%s", invalid)
+		}
+	}
+}
@@ -44,11 +44,16 @@ func roleSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{""},
-				Resources: []string{"configmaps", "leases"},
+				Resources: []string{"configmaps"},
 				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch, roleVerbCreate, roleVerbUpdate, roleVerbPatch, roleVerbDelete},
 			},
 			{
-				APIGroups: []string{"", "coordination.k8s.io"},
+				APIGroups: []string{"coordination.k8s.io"},
+				Resources: []string{"leases"},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch, roleVerbCreate, roleVerbUpdate, roleVerbPatch, roleVerbDelete},
+			},
+			{
+				APIGroups: []string{""},
 				Resources: []string{"events"},
 				Verbs:     []string{roleVerbCreate, roleVerbPatch},
 			},
@@ -71,10 +76,20 @@ func clusterRoleSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 				Resources: []string{"deployments"},
 				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch, roleVerbCreate, roleVerbUpdate, roleVerbPatch, roleVerbDelete},
 			},
+			{
+				APIGroups: []string{"apps"},
+				Resources: []string{"statefulsets"},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch, roleVerbUpdate, roleVerbPatch},
+			},
 			{
 				APIGroups: []string{""},
 				Resources: []string{"pods"},
-				Verbs:     []string{roleVerbGet, roleVerbList},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"events"},
+				Verbs:     []string{roleVerbCreate, roleVerbPatch},
 			},
 			{
 				APIGroups: []string{"mesh.megaease.com"},
@@ -91,6 +106,16 @@ func clusterRoleSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 				Resources: []string{"meshdeployments/status"},
 				Verbs:     []string{roleVerbGet, roleVerbPatch, roleVerbUpdate},
 			},
+			{
+				APIGroups: []string{"mesh.megaease.com"},
+				Resources: []string{"meshcontrolplanes"},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch},
+			},
+			{
+				APIGroups: []string{"mesh.megaease.com"},
+				Resources: []string{"meshcontrolplanes/status"},
+				Verbs:     []string{roleVerbGet, roleVerbPatch, roleVerbUpdate},
+			},
 		},
 	}
 
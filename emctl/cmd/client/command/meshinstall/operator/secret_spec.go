@@ -28,6 +28,7 @@ import (
 	"fmt"
 
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -47,7 +48,7 @@ func secretSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 		_, err := ctx.Client.CoreV1().Secrets(ctx.Flags.MeshNamespace).Get(context.TODO(),
 			secret.Name, metav1.GetOptions{})
 		if err == nil {
-			fmt.Printf("\nsecret %s existed, won't create it again\n\n", secret.Name)
+			common.Infof("secret %s existed, won't create it again", secret.Name)
 			return nil
 		} else if !errors.IsNotFound(err) {
 			return fmt.Errorf("deploy secret %s/%s failed: %v",
@@ -38,7 +38,7 @@ func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 	service.Spec.Ports = []v1.ServicePort{
 		{
 			Name:       "https",
-			Port:       8443,
+			Port:       installbase.OperatorRBACProxyPort,
 			TargetPort: intstr.IntOrString{StrVal: "https"},
 		},
 		{
@@ -47,6 +47,20 @@ func serviceSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 			TargetPort: intstr.IntOrString{StrVal: "mutate-webhook"},
 		},
 	}
+	if ctx.Flags.OperatorMetricsScrape {
+		service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{
+			Name:       installbase.OperatorMetricsPortName,
+			Port:       installbase.OperatorMetricsPort,
+			TargetPort: intstr.IntOrString{StrVal: installbase.OperatorMetricsPortName},
+		})
+	}
+	if ctx.Flags.EnableAPIAggregation {
+		service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{
+			Name:       installbase.OperatorAPIAggregationPortName,
+			Port:       installbase.OperatorAPIAggregationPort,
+			TargetPort: intstr.IntOrString{StrVal: installbase.OperatorAPIAggregationPortName},
+		})
+	}
 	service.Spec.Selector = labels
 	return func(ctx *installbase.StageContext) error {
 		err := installbase.DeployService(service, ctx.Client, ctx.Flags.MeshNamespace)
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selfheal
+
+import (
+	"fmt"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	selfHealClusterRole        = "mesh-operator-self-heal-role"
+	selfHealClusterRoleBinding = "mesh-operator-self-heal-rolebinding"
+)
+
+// Deploy grants the operator access to objects of the installation, and
+// saves the snapshot of them. Installing add-ons only merges their objects
+// into the existing snapshot.
+func Deploy(ctx *installbase.StageContext) error {
+	err := installbase.BatchDeployResources(ctx, []installbase.InstallFunc{
+		clusterRoleSpec(ctx),
+		clusterRoleBindingSpec(ctx),
+	})
+	if err != nil {
+		return err
+	}
+
+	objects := ctx.Snapshot.Objects()
+	if ctx.Flags.OnlyAddOn {
+		old, err := loadSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		objects = installbase.MergeSnapshotObjects(old, objects)
+	}
+	return saveSnapshot(ctx, objects)
+}
+
+// PreCheck checks the snapshot is taken by the installation.
+func PreCheck(ctx *installbase.StageContext) error {
+	if ctx.Snapshot == nil {
+		return errors.Errorf("no snapshot of the installation is taken")
+	}
+	return nil
+}
+
+// Clear deletes the snapshot ahead of other resources, so the operator
+// stops healing them, then the access granted to the operator.
+func Clear(ctx *installbase.StageContext) error {
+	installbase.DeleteResources(ctx.Client, [][]string{
+		{"secrets", installbase.InstallSnapshotSecretName},
+	}, ctx.Flags.MeshNamespace, installbase.DeleteCoreV1Resource)
+
+	installbase.DeleteResources(ctx.Client, [][]string{
+		{"clusterrolebindings", selfHealClusterRoleBinding},
+		{"clusterroles", selfHealClusterRole},
+	}, ctx.Flags.MeshNamespace, installbase.DeleteRbacV1Resources)
+	return nil
+}
+
+// RemoveStages removes objects of the stages from the snapshot, so the
+// operator doesn't recreate them once they are reset.
+func RemoveStages(ctx *installbase.StageContext, stages []string) error {
+	old, err := loadSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	return saveSnapshot(ctx, installbase.RemoveSnapshotStages(old, stages))
+}
+
+// DescribePhase leverage human-readable text to describe different phase
+// in the process of enabling self-healing
+func DescribePhase(ctx *installbase.StageContext, phase installbase.InstallPhase) string {
+	switch phase {
+	case installbase.BeginPhase:
+		return fmt.Sprintf("Begin to save the snapshot of the installation in the namespace: %s", ctx.Flags.MeshNamespace)
+	case installbase.EndPhase:
+		return fmt.Sprintf("\nSnapshot %s saved successfully, "+
+			"the operator reverts manual edits and deletions of objects in it", installbase.InstallSnapshotSecretName)
+	}
+	return ""
+}
+
+func loadSnapshot(ctx *installbase.StageContext) ([]*installbase.SnapshotObject, error) {
+	secret, err := ctx.Client.CoreV1().Secrets(ctx.Flags.MeshNamespace).
+		Get(ctx.Stage(), installbase.InstallSnapshotSecretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "get secret %s", installbase.InstallSnapshotSecretName)
+	}
+	return installbase.UnmarshalSnapshotObjects(secret.Data[installbase.InstallSnapshotSecretKey])
+}
+
+func saveSnapshot(ctx *installbase.StageContext, objects []*installbase.SnapshotObject) error {
+	buff, err := installbase.MarshalSnapshotObjects(objects)
+	if err != nil {
+		return err
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.InstallSnapshotSecretName,
+			Namespace: ctx.Flags.MeshNamespace,
+		},
+		Data: map[string][]byte{
+			installbase.InstallSnapshotSecretKey: buff,
+		},
+	}
+	err = installbase.DeploySecret(secret, ctx.Client, ctx.Flags.MeshNamespace)
+	if err != nil {
+		return errors.Wrapf(err, "deploy secret %s", secret.Name)
+	}
+	return nil
+}
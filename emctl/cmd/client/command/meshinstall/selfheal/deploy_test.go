@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selfheal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	meshtesting "github.com/megaease/easemeshctl/cmd/client/testing"
+
+	"github.com/spf13/cobra"
+	extensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func prepareContext() (*installbase.StageContext, *fake.Clientset) {
+	client := fake.NewSimpleClientset()
+	extensionClient := extensionfake.NewSimpleClientset()
+
+	install := &flags.Install{}
+	cmd := &cobra.Command{}
+	install.AttachCmd(cmd)
+	ctx := meshtesting.PrepareInstallContext(cmd, client, extensionClient, install)
+	ctx.Snapshot = installbase.NewInstallSnapshot()
+	return ctx, client
+}
+
+func snapshotNames(t *testing.T, ctx *installbase.StageContext) []string {
+	objects, err := loadSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("load snapshot failed: %v", err)
+	}
+	names := []string{}
+	for _, o := range objects {
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+func TestDeploy(t *testing.T) {
+	ctx, client := prepareContext()
+	if err := PreCheck(ctx); err != nil {
+		t.Fatalf("pre check failed: %v", err)
+	}
+
+	err := saveSnapshot(ctx, []*installbase.SnapshotObject{
+		{Group: "apps", Version: "v1", Resource: "deployments", Namespace: "easemesh", Name: "easemesh-operator", Stage: "operator"},
+		{Version: "v1", Resource: "services", Namespace: "easemesh", Name: "easemesh-shadowservice-controller", Stage: "shadowservice"},
+	})
+	if err != nil {
+		t.Fatalf("save snapshot failed: %v", err)
+	}
+
+	ctx.Flags.OnlyAddOn = true
+	err = Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+	_, err = client.RbacV1().ClusterRoles().Get(context.TODO(), selfHealClusterRole, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("cluster role %s should be deployed: %v", selfHealClusterRole, err)
+	}
+	if names := snapshotNames(t, ctx); len(names) != 2 {
+		t.Fatalf("installing add-ons should keep the snapshot, got %v", names)
+	}
+
+	err = RemoveStages(ctx, []string{"ShadowService"})
+	if err != nil {
+		t.Fatalf("remove stages failed: %v", err)
+	}
+	if names := snapshotNames(t, ctx); len(names) != 1 || names[0] != "easemesh-operator" {
+		t.Fatalf("unexpected snapshot %v", names)
+	}
+
+	ctx.Flags.OnlyAddOn = false
+	err = Deploy(ctx)
+	if err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+	if names := snapshotNames(t, ctx); len(names) != 0 {
+		t.Fatalf("installing should replace the snapshot, got %v", names)
+	}
+}
@@ -29,7 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-type deploymentSpecFunc func(*flags.Install) *appsV1.Deployment
+type deploymentSpecFunc func(*flags.Install) (*appsV1.Deployment, error)
 
 func shadowServiceLabel() map[string]string {
 	selector := map[string]string{}
@@ -38,12 +38,15 @@ func shadowServiceLabel() map[string]string {
 }
 
 func deploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
-	deployment := deploymentContainerSpec(
-		deploymentBaseSpec(
-			deploymentInitialize(nil)))(ctx.Flags)
-
 	return func(ctx *installbase.StageContext) error {
-		err := installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
+		deployment, err := deploymentContainerSpec(
+			deploymentBaseSpec(
+				deploymentInitialize(nil)))(ctx.Flags)
+		if err != nil {
+			return errors.Wrap(err, "build deployment spec failed")
+		}
+
+		err = installbase.DeployDeployment(deployment, ctx.Client, ctx.Flags.MeshNamespace)
 		if err != nil {
 			return errors.Wrapf(err, "deployment operation %s failed", deployment.Name)
 		}
@@ -52,14 +55,17 @@ func deploymentSpec(ctx *installbase.StageContext) installbase.InstallFunc {
 }
 
 func deploymentInitialize(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(installFlags *flags.Install) *appsV1.Deployment {
-		return &appsV1.Deployment{}
+	return func(installFlags *flags.Install) (*appsV1.Deployment, error) {
+		return &appsV1.Deployment{}, nil
 	}
 }
 
 func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(installFlags *flags.Install) *appsV1.Deployment {
-		spec := fn(installFlags)
+	return func(installFlags *flags.Install) (*appsV1.Deployment, error) {
+		spec, err := fn(installFlags)
+		if err != nil {
+			return nil, err
+		}
 		spec.Name = installbase.IngressControllerShadowServiceName
 		spec.Spec.Selector = &metav1.LabelSelector{
 			MatchLabels: shadowServiceLabel(),
@@ -67,20 +73,26 @@ func deploymentBaseSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 
 		spec.Spec.Template.Labels = shadowServiceLabel()
 		spec.Spec.Template.Spec.Containers = []v1.Container{}
-		return spec
+		return spec, nil
 	}
 }
 
 func deploymentContainerSpec(fn deploymentSpecFunc) deploymentSpecFunc {
-	return func(installFlags *flags.Install) *appsV1.Deployment {
-		spec := fn(installFlags)
-		container, _ := installbase.AcceptContainerVisitor("shadowservice-controller",
+	return func(installFlags *flags.Install) (*appsV1.Deployment, error) {
+		spec, err := fn(installFlags)
+		if err != nil {
+			return nil, err
+		}
+		container, err := installbase.AcceptContainerVisitor("shadowservice-controller",
 			installFlags.ImageRegistryURL+"/"+installFlags.ShadowServiceControllerImage,
 			v1.PullIfNotPresent,
 			newVisitor(installFlags))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate container spec failed")
+		}
 
 		spec.Spec.Template.Spec.Containers = append(spec.Spec.Template.Spec.Containers, *container)
-		return spec
+		return spec, nil
 	}
 }
 
@@ -94,7 +106,7 @@ func newVisitor(installFlags *flags.Install) installbase.ContainerVisitor {
 
 func (v *containerVisitor) VisitorCommandAndArgs(c *v1.Container) (command []string, installFlags []string) {
 	cmds := []string{"/bin/sh"}
-	meshServer := fmt.Sprintf("%s.%s:%d", v.installFlags.EgServiceName, v.installFlags.MeshNamespace, v.installFlags.EgAdminPort)
+	meshServer := fmt.Sprintf("%s.%s:%d", v.installFlags.EgServiceName, v.installFlags.MeshNamespace, v.installFlags.EgServiceAdminPort)
 	args := []string{
 		"-c",
 		"/opt/easemesh-shadowservice/bin/easemesh-shadowservice-controller -mesh-server " + meshServer,
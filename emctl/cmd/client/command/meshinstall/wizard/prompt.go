@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wizard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// prompter asks questions line by line, an empty answer takes the default.
+	prompter struct {
+		in  *bufio.Reader
+		out io.Writer
+	}
+
+	// option is an option of a choice.
+	option struct {
+		value       string
+		description string
+	}
+)
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask asks the question until the answer is valid.
+func (p *prompter) ask(question, def string, validate func(answer string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		line, err := p.in.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if err != nil && (err != io.EOF || answer == "") {
+			fmt.Fprintln(p.out)
+			return "", errors.Wrapf(err, "read answer of %q", question)
+		}
+		if answer == "" {
+			answer = def
+		}
+
+		if validate == nil {
+			return answer, nil
+		}
+		verr := validate(answer)
+		if verr == nil {
+			return answer, nil
+		}
+		fmt.Fprintf(p.out, "  %v\n", verr)
+		if err == io.EOF {
+			return "", verr
+		}
+	}
+}
+
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	defAnswer := "y/N"
+	if def {
+		defAnswer = "Y/n"
+	}
+
+	answer, err := p.ask(question, defAnswer, func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "y/n", "y", "yes", "n", "no":
+			return nil
+		}
+		return errors.Errorf("answer y or n")
+	})
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return def, nil
+}
+
+// choose lists numbered options, the answer is either the number or the value.
+func (p *prompter) choose(question string, options []*option, def string) (string, error) {
+	fmt.Fprintln(p.out, question)
+	for i, o := range options {
+		if o.description != "" {
+			fmt.Fprintf(p.out, "  %d) %s - %s\n", i+1, o.value, o.description)
+		} else {
+			fmt.Fprintf(p.out, "  %d) %s\n", i+1, o.value)
+		}
+	}
+
+	var chosen string
+	_, err := p.ask("Choose", def, func(answer string) error {
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			chosen = options[n-1].value
+			return nil
+		}
+		for _, o := range options {
+			if o.value == answer {
+				chosen = o.value
+				return nil
+			}
+		}
+		return errors.Errorf("choose a number from 1 to %d", len(options))
+	})
+	return chosen, err
+}
+
+// askPositive asks a positive integer.
+func (p *prompter) askPositive(question string, def int) (int, error) {
+	answer, err := p.ask(question, strconv.Itoa(def), func(answer string) error {
+		n, err := strconv.Atoi(answer)
+		if err != nil || n <= 0 {
+			return errors.Errorf("answer a positive integer")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(answer)
+}
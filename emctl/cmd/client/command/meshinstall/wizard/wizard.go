@@ -0,0 +1,351 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wizard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ephemeralStorage is the storage option storing data of the control plane in emptyDir volumes.
+	ephemeralStorage = "ephemeral"
+
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	noProvisioner                 = "kubernetes.io/no-provisioner"
+)
+
+type (
+	// wizard sets answers to flags of the install command,
+	// as if they were specified in the command line.
+	wizard struct {
+		cmd    *cobra.Command
+		flags  *flags.Install
+		client kubernetes.Interface
+		p      *prompter
+	}
+
+	// cluster is what the wizard detects from the cluster.
+	cluster struct {
+		version string
+		nodes   int
+		// local is a kind, minikube or Docker Desktop cluster.
+		local bool
+	}
+)
+
+// addOns are the add-ons the wizard offers.
+var addOns = []*option{
+	{value: "shadowservice", description: "shadow services for testing with production traffic"},
+	{value: "egressgateway", description: "egress gateway for external services"},
+	{value: "gitops", description: "GitOps controller syncing mesh resources from a Git repository"},
+	{value: "maintenance", description: "scheduled compaction and defragmentation of the control plane storage"},
+	{value: "dashboards", description: "Grafana dashboards of the operator"},
+}
+
+var safeArgPattern = regexp.MustCompile(`^[A-Za-z0-9._:/@=,+-]+$`)
+
+// Run walks users through the installation by prompts, answers are set to
+// flags of the command. It returns false if users decline to install at last.
+func Run(cmd *cobra.Command, installFlags *flags.Install, client kubernetes.Interface, in io.Reader, out io.Writer) (bool, error) {
+	w := &wizard{
+		cmd:    cmd,
+		flags:  installFlags,
+		client: client,
+		p:      newPrompter(in, out),
+	}
+
+	err := w.run()
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(out, "\nThe equivalent command is:\n\n  %s\n\n", EquivalentCommand(cmd))
+	return w.p.confirm("Install now", true)
+}
+
+func (w *wizard) run() error {
+	c, err := detectCluster(w.client)
+	if err != nil {
+		return errors.Wrap(err, "detect the cluster")
+	}
+	fmt.Fprintf(w.p.out, "Detected Kubernetes %s with %d node(s)\n\n", c.version, c.nodes)
+
+	preset := false
+	if c.local && c.nodes == 1 && !w.changed("kind-preset") {
+		preset, err = w.p.confirm("It looks like a local cluster, apply the preset for kind and minikube", true)
+		if err != nil {
+			return err
+		}
+		if preset {
+			err = w.set("kind-preset", "true")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// NOTE: The preset decides the storage and replicas.
+	if !preset && !w.flags.KindPreset {
+		err = w.askStorage()
+		if err != nil {
+			return err
+		}
+		err = w.askReplicas(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = w.askRegistryType()
+	if err != nil {
+		return err
+	}
+	return w.askAddOns()
+}
+
+func detectCluster(client kubernetes.Interface) (*cluster, error) {
+	c := &cluster{version: "unknown"}
+	if info, err := client.Discovery().ServerVersion(); err == nil && info.GitVersion != "" {
+		c.version = info.GitVersion
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	c.nodes = len(nodes.Items)
+	for i := range nodes.Items {
+		if isLocalNode(&nodes.Items[i]) {
+			c.local = true
+		}
+	}
+	return c, nil
+}
+
+func isLocalNode(node *v1.Node) bool {
+	if strings.HasPrefix(node.Spec.ProviderID, "kind://") {
+		return true
+	}
+	if _, ok := node.Labels["minikube.k8s.io/name"]; ok {
+		return true
+	}
+	return node.Name == "docker-desktop"
+}
+
+func (w *wizard) askStorage() error {
+	if w.changed("storage-class") || w.changed("ephemeral-storage") {
+		return nil
+	}
+
+	storageClasses, err := w.client.StorageV1().StorageClasses().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "list storage classes")
+	}
+
+	options := []*option{}
+	def := ephemeralStorage
+	provisioners := map[string]string{}
+	for _, sc := range storageClasses.Items {
+		description := "provisioner " + sc.Provisioner
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			description += ", default"
+			def = sc.Name
+		}
+		options = append(options, &option{value: sc.Name, description: description})
+		provisioners[sc.Name] = sc.Provisioner
+	}
+	options = append(options, &option{value: ephemeralStorage, description: "emptyDir volumes, data is lost once pods restart"})
+
+	answer, err := w.p.choose("Storage of the control plane:", options, def)
+	if err != nil {
+		return err
+	}
+	if answer == ephemeralStorage {
+		return w.set("ephemeral-storage", "true")
+	}
+	if provisioners[answer] == noProvisioner {
+		fmt.Fprintf(w.p.out, "  NOTE: %s has no provisioner, create PersistentVolumes of it before installing\n", answer)
+	}
+	return w.set("storage-class", answer)
+}
+
+func (w *wizard) askReplicas(c *cluster) error {
+	if !w.changed("easemesh-control-plane-replicas") {
+		def := flags.DefaultMeshControlPlaneReplicas
+		if c.nodes > 0 && c.nodes < def {
+			def = 1
+		}
+		replicas, err := w.p.askPositive("Replicas of the control plane, an odd number keeps the quorum", def)
+		if err != nil {
+			return err
+		}
+		if replicas%2 == 0 {
+			fmt.Fprintf(w.p.out, "  NOTE: %d members tolerate the same failures as %d\n", replicas, replicas-1)
+		}
+		err = w.set("easemesh-control-plane-replicas", strconv.Itoa(replicas))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, r := range []struct {
+		name     string
+		question string
+		def      int
+	}{
+		{"easemesh-ingress-replicas", "Replicas of the mesh ingress", w.flags.MeshIngressReplicas},
+		{"operator-replicas", "Replicas of the operator", w.flags.EaseMeshOperatorReplicas},
+	} {
+		if w.changed(r.name) {
+			continue
+		}
+		replicas, err := w.p.askPositive(r.question, r.def)
+		if err != nil {
+			return err
+		}
+		err = w.set(r.name, strconv.Itoa(replicas))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wizard) askRegistryType() error {
+	if w.changed("registry-type") {
+		return nil
+	}
+
+	answer, err := w.p.choose("Registry type of applications:", []*option{
+		{value: "eureka"},
+		{value: "consul"},
+		{value: "nacos"},
+	}, w.flags.EaseMeshRegistryType)
+	if err != nil {
+		return err
+	}
+	return w.set("registry-type", answer)
+}
+
+func (w *wizard) askAddOns() error {
+	if w.changed("add-ons") {
+		return nil
+	}
+
+	for _, addOn := range addOns {
+		install, err := w.p.confirm(fmt.Sprintf("Install the add-on %s (%s)", addOn.value, addOn.description), false)
+		if err != nil {
+			return err
+		}
+		if !install {
+			continue
+		}
+		err = w.set("add-ons", addOn.value)
+		if err != nil {
+			return err
+		}
+
+		if addOn.value == "gitops" && !w.changed("gitops-repo") {
+			repo, err := w.p.ask("URL of the Git repository holding mesh resources", "", func(answer string) error {
+				if answer == "" {
+					return errors.New("the repository is required by the add-on gitops")
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			err = w.set("gitops-repo", repo)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *wizard) changed(name string) bool {
+	return w.cmd.Flags().Changed(name)
+}
+
+func (w *wizard) set(name, value string) error {
+	err := w.cmd.Flags().Set(name, value)
+	if err != nil {
+		return errors.Wrapf(err, "set flag %s", name)
+	}
+	return nil
+}
+
+// EquivalentCommand returns the non-interactive install command
+// with the flags changed in the command line or by the wizard.
+func EquivalentCommand(cmd *cobra.Command) string {
+	args := []string{"emctl", "install"}
+
+	var names []string
+	values := map[string][]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "interactive" {
+			return
+		}
+		names = append(names, f.Name)
+
+		switch {
+		case strings.HasSuffix(f.Value.Type(), "Array"), strings.HasSuffix(f.Value.Type(), "Slice"):
+			list := strings.TrimSuffix(strings.TrimPrefix(f.Value.String(), "["), "]")
+			if list != "" {
+				values[f.Name] = strings.Split(list, ",")
+			}
+		default:
+			values[f.Name] = []string{f.Value.String()}
+		}
+	})
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range values[name] {
+			if value == "true" {
+				args = append(args, "--"+name)
+				continue
+			}
+			args = append(args, "--"+name+"="+shellQuote(value))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+func shellQuote(s string) string {
+	if safeArgPattern.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
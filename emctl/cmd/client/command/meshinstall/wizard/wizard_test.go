@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wizard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func prepareCommand() (*cobra.Command, *flags.Install) {
+	cmd := &cobra.Command{Use: "install"}
+	install := &flags.Install{}
+	install.AttachCmd(cmd)
+	return cmd, install
+}
+
+func node(name, providerID string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func TestWizard(t *testing.T) {
+	cmd, install := prepareCommand()
+	client := fake.NewSimpleClientset(
+		node("node-0", ""), node("node-1", ""), node("node-2", ""),
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "standard", Annotations: map[string]string{defaultStorageClassAnnotation: "true"}},
+			Provisioner: "rancher.io/local-path",
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "local"},
+			Provisioner: noProvisioner,
+		},
+	)
+
+	answers := strings.Join([]string{
+		// storage, replicas of the control plane, invalid and valid replicas of the ingress, the operator
+		"local", "", "0", "2", "",
+		// registry type
+		"2",
+		// add-ons with the repository of gitops
+		"n", "", "y", "", "https://github.com/megaease/mesh-config.git", "no", "",
+		// install now
+		"",
+	}, "\n") + "\n"
+	out := &bytes.Buffer{}
+	proceed, err := Run(cmd, install, client, strings.NewReader(answers), out)
+	if err != nil {
+		t.Fatalf("run wizard failed: %v\n%s", err, out.String())
+	}
+	if !proceed {
+		t.Fatalf("expected to install")
+	}
+
+	if install.MeshControlPlaneStorageClassName != "local" || install.EasegressControlPlaneReplicas != 3 ||
+		install.MeshIngressReplicas != 2 || install.EaseMeshRegistryType != "consul" ||
+		len(install.AddOns) != 1 || install.AddOns[0] != "gitops" ||
+		install.GitOpsRepo != "https://github.com/megaease/mesh-config.git" {
+		t.Fatalf("unexpected flags %+v", install)
+	}
+	if !strings.Contains(out.String(), "create PersistentVolumes of it") {
+		t.Fatalf("expected the note of storage class without provisioner:\n%s", out.String())
+	}
+
+	command := EquivalentCommand(cmd)
+	for _, want := range []string{
+		"emctl install --add-ons=gitops",
+		"--easemesh-control-plane-replicas=3",
+		"--easemesh-ingress-replicas=2",
+		"--gitops-repo=https://github.com/megaease/mesh-config.git",
+		"--registry-type=consul",
+		"--storage-class=local",
+	} {
+		if !strings.Contains(command, want) {
+			t.Fatalf("expected %q in the equivalent command %s", want, command)
+		}
+	}
+}
+
+func TestWizardLocalCluster(t *testing.T) {
+	cmd, install := prepareCommand()
+	client := fake.NewSimpleClientset(node("kind-control-plane", "kind://docker/kind/kind-control-plane"))
+
+	answers := "\n\n\n\n\n\n\nn\n"
+	proceed, err := Run(cmd, install, client, strings.NewReader(answers), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("run wizard failed: %v", err)
+	}
+	if proceed {
+		t.Fatalf("expected to decline installing")
+	}
+	if !install.KindPreset || cmd.Flags().Changed("easemesh-control-plane-replicas") {
+		t.Fatalf("expected the kind preset deciding replicas")
+	}
+	if command := EquivalentCommand(cmd); command != "emctl install --kind-preset --registry-type=eureka" {
+		t.Fatalf("unexpected equivalent command %s", command)
+	}
+
+	cmd, install = prepareCommand()
+	_, err = Run(cmd, install, client, strings.NewReader("y\n"), &bytes.Buffer{})
+	if err == nil {
+		t.Fatalf("expected error without answers")
+	}
+}
+
+func TestEquivalentCommandQuote(t *testing.T) {
+	cmd, _ := prepareCommand()
+	cmd.Flags().Set("storage-class", "")
+	cmd.Flags().Set("gitops-webhook-secret", "it's")
+	cmd.Flags().Set("interactive", "true")
+
+	command := EquivalentCommand(cmd)
+	if command != `emctl install --gitops-webhook-secret='it'\''s' --storage-class=''` {
+		t.Fatalf("unexpected equivalent command %s", command)
+	}
+}
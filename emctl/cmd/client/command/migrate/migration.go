@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Converter converts the spec of a resource to the schema of the target version
+	// in place, it returns paths of the fields which can't be converted, e.g. removed
+	// without replacements in the target version, they're dropped from the spec.
+	Converter func(spec map[string]interface{}) (unconvertible []string, err error)
+
+	// Migration converts resources from a schema version of EaseMesh to another with
+	// converters of kinds, resources of other kinds are the same in both versions.
+	Migration struct {
+		From       string
+		To         string
+		Converters map[string]Converter
+	}
+)
+
+// migrations are registered once schemas of kinds change between versions of EaseMesh,
+// the converters of them are usually built with RenameField and RemoveField.
+var migrations []*Migration
+
+// Register registers a migration between versions.
+func Register(m *Migration) {
+	migrations = append(migrations, m)
+}
+
+func lookup(from, to string) (*Migration, error) {
+	available := []string{}
+	for _, m := range migrations {
+		if m.From == from && m.To == to {
+			return m, nil
+		}
+		available = append(available, fmt.Sprintf("%s to %s", m.From, m.To))
+	}
+
+	if len(available) == 0 {
+		available = append(available, "none")
+	}
+	return nil, errors.Errorf("no migration from %s to %s (available: %s)", from, to, strings.Join(available, ", "))
+}
+
+// kinds returns kinds converted by the migration in order.
+func (m *Migration) kinds() []string {
+	kinds := make([]string, 0, len(m.Converters))
+	for kind := range m.Converters {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// RenameField returns a Converter moving the field at the path to another path,
+// paths are separated by dots, e.g. sidecar.ingressPort.
+func RenameField(from, to string) Converter {
+	return func(spec map[string]interface{}) ([]string, error) {
+		value, ok := removePath(spec, from)
+		if !ok {
+			return nil, nil
+		}
+		err := setPath(spec, to, value)
+		if err != nil {
+			// NOTE: Put the value back to keep the spec, setting the path just removed never fails.
+			setPath(spec, from, value)
+			return nil, errors.Wrapf(err, "rename %s to %s", from, to)
+		}
+		return nil, nil
+	}
+}
+
+// RemoveField returns a Converter removing the field at the path, which has no
+// replacement in the target version, so it's reported if it's set.
+func RemoveField(path string) Converter {
+	return func(spec map[string]interface{}) ([]string, error) {
+		_, ok := removePath(spec, path)
+		if !ok {
+			return nil, nil
+		}
+		return []string{path}, nil
+	}
+}
+
+// Chain returns a Converter converting with converters in order.
+func Chain(converters ...Converter) Converter {
+	return func(spec map[string]interface{}) ([]string, error) {
+		var unconvertible []string
+		for _, converter := range converters {
+			fields, err := converter(spec)
+			if err != nil {
+				return nil, err
+			}
+			unconvertible = append(unconvertible, fields...)
+		}
+		return unconvertible, nil
+	}
+}
+
+func removePath(spec map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	m := spec
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = child
+	}
+
+	last := keys[len(keys)-1]
+	value, ok := m[last]
+	if ok {
+		delete(m, last)
+	}
+	return value, ok
+}
+
+func setPath(spec map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	m := spec
+	for i, key := range keys[:len(keys)-1] {
+		child, ok := m[key]
+		if !ok {
+			child = map[string]interface{}{}
+			m[key] = child
+		}
+		childMap, ok := child.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%s is not an object", strings.Join(keys[:i+1], "."))
+		}
+		m = childMap
+	}
+
+	m[keys[len(keys)-1]] = value
+	return nil
+}
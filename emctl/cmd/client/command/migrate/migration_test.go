@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestConverters(t *testing.T) {
+	converter := Chain(
+		RenameField("sidecar.port", "sidecar.ingressPort"),
+		RenameField("tenant", "registerTenant"),
+		RemoveField("sidecar.legacyMode"),
+		RemoveField("absent"),
+	)
+
+	spec := map[string]interface{}{
+		"tenant": "pet",
+		"sidecar": map[string]interface{}{
+			"port":       13001,
+			"legacyMode": true,
+		},
+	}
+	unconvertible, err := converter(spec)
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"registerTenant": "pet",
+		"sidecar": map[string]interface{}{
+			"ingressPort": 13001,
+		},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Fatalf("expected %v, but got %v", expected, spec)
+	}
+	if !reflect.DeepEqual(unconvertible, []string{"sidecar.legacyMode"}) {
+		t.Fatalf("sidecar.legacyMode should be unconvertible, but got %v", unconvertible)
+	}
+
+	_, err = RenameField("sidecar.ingressPort", "registerTenant.port")(spec)
+	if err == nil {
+		t.Fatalf("renaming to a field of a string should fail")
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Fatalf("failed renaming should keep the spec %v, but got %v", expected, spec)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	defer func(old []*Migration) { migrations = old }(migrations)
+	migrations = nil
+
+	_, err := lookup("v1", "v2")
+	if err == nil {
+		t.Fatalf("lookup should fail without migrations")
+	}
+
+	Register(&Migration{From: "v1", To: "v2"})
+	m, err := lookup("v1", "v2")
+	if err != nil || m.To != "v2" {
+		t.Fatalf("lookup v1 to v2 failed: %v", err)
+	}
+}
+
+func TestMigrateResource(t *testing.T) {
+	converter := Chain(RenameField("desc", "description"), RemoveField("owner"))
+
+	r := migrateResource(resource.KindTenant, map[string]interface{}{
+		"name":     "pet",
+		"desc":     "pet store",
+		"services": []interface{}{"order"},
+		"owner":    "alice",
+	}, converter)
+	if r.Err != nil || r.Result != resultConverted {
+		t.Fatalf("tenant should be converted, but got %s: %v", r.Result, r.Err)
+	}
+	tenant := r.object.(*resource.Tenant)
+	if tenant.Name() != "pet" || tenant.Spec.Description != "pet store" {
+		t.Fatalf("unexpected converted tenant %+v", tenant.Spec)
+	}
+	if !reflect.DeepEqual(r.Unconvertible, []string{"owner"}) {
+		t.Fatalf("owner should be unconvertible, but got %v", r.Unconvertible)
+	}
+
+	r = migrateResource(resource.KindTenant, map[string]interface{}{
+		"name": "pet",
+		"zone": "beijing",
+	}, converter)
+	if r.Result != resultFailed || !reflect.DeepEqual(r.Unconvertible, []string{"zone"}) {
+		t.Fatalf("the unknown field zone should fail the conversion, but got %s %v", r.Result, r.Unconvertible)
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	resultConverted = "converted"
+	resultRewritten = "rewritten"
+	resultFailed    = "failed"
+)
+
+// listURLs are paths listing resources of kinds in the form stored by the control plane,
+// resources are fetched raw since fields of the source version are unknown to emctl.
+// Policies of services, e.g. LoadBalance, are converted as fields of the Service.
+var listURLs = map[string]string{
+	resource.KindTenant:          meshclient.MeshTenantsURL,
+	resource.KindService:         meshclient.MeshServicesURL,
+	resource.KindIngress:         meshclient.MeshIngressesURL,
+	resource.KindServiceCanary:   meshclient.MeshServiceCanariesURL,
+	resource.KindExternalService: meshclient.MeshExternalServicesURL,
+	resource.KindTenantPolicy:    meshclient.MeshTenantPoliciesURL,
+	resource.KindSLO:             meshclient.MeshSLOsURL,
+	resource.KindAlertRule:       meshclient.MeshAlertRulesURL,
+	resource.KindMessagingPolicy: meshclient.MeshMessagingPoliciesURL,
+	resource.KindIngressPort:     meshclient.MeshIngressPortsURL,
+	resource.KindWAFPolicy:       meshclient.MeshWAFPoliciesURL,
+	resource.KindMaintenanceMode: meshclient.MeshMaintenanceModesURL,
+}
+
+// result is the result of migrating a resource.
+type result struct {
+	Kind          string
+	Name          string
+	Result        string
+	Unconvertible []string
+	Err           error
+
+	object meta.MeshObject
+}
+
+// RunResources is the entrypoint of the emctl migrate resources sub command
+func RunResources(cmd *cobra.Command, flag *flags.MigrateResources) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	if flag.From == "" || flag.To == "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "--from and --to are required")
+	}
+
+	migration, err := lookup(flag.From, flag.To)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	meshClient := meshclient.New(flag.Server)
+
+	var results []*result
+	for _, kind := range migration.kinds() {
+		objects, err := Fetch(flag.Server, kind, flag.Timeout)
+		if err != nil {
+			common.ExitWithErrorf("fetch %s failed: %w", kind, err)
+		}
+		for _, object := range objects {
+			r := migrateResource(kind, object, migration.Converters[kind])
+			if r.Err == nil {
+				err := apply.Validate(meshClient, r.object, flag.Timeout)
+				if err != nil {
+					r.Result, r.Err = resultFailed, err
+				}
+			}
+			results = append(results, r)
+		}
+	}
+
+	// NOTE: Nothing is rewritten unless all resources are converted,
+	// so the control plane never has resources of both versions.
+	if failed(results) || flag.DryRun {
+		printResults(os.Stdout, results)
+		if failed(results) {
+			common.ExitWithCodef(common.ExitCodeValidation, "some resources can't be migrated from %s to %s, nothing rewritten",
+				flag.From, flag.To)
+		}
+		return
+	}
+
+	for _, r := range results {
+		err := apply.WrapApplierByMeshObject(r.object, meshClient, flag.Timeout).Apply()
+		if err != nil {
+			r.Result, r.Err = resultFailed, err
+			continue
+		}
+		r.Result = resultRewritten
+	}
+
+	printResults(os.Stdout, results)
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	if len(errs) != 0 {
+		common.ExitWithCodef(common.ExitCodeOf(errs...), "%d resources are not rewritten from %s to %s",
+			len(errs), flag.From, flag.To)
+	}
+}
+
+// Kinds returns kinds of the resources stored by the control plane in order.
+func Kinds() []string {
+	kinds := make([]string, 0, len(listURLs))
+	for kind := range listURLs {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Fetch fetches raw objects of the kind stored by the control plane.
+func Fetch(server, kind string, timeout time.Duration) ([]map[string]interface{}, error) {
+	path, ok := listURLs[kind]
+	if !ok {
+		return nil, errors.Errorf("%s can't be migrated", kind)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	url := "http://" + strings.TrimPrefix(server, "http://") + path
+	objects, err := client.NewHTTPJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			if statusCode == http.StatusNotFound {
+				return []map[string]interface{}{}, nil
+			}
+			if statusCode >= 300 || statusCode < 200 {
+				return nil, errors.Errorf("call GET %s failed, return statuscode %d text %s", url, statusCode, string(b))
+			}
+
+			objects := []map[string]interface{}{}
+			err := json.Unmarshal(b, &objects)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal %s result", kind)
+			}
+			return objects, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return objects.([]map[string]interface{}), nil
+}
+
+// Decode decodes an object stored by the control plane in the schema of emctl,
+// it returns the object and fields unknown to the schema, e.g. removed ones.
+func Decode(kind string, object map[string]interface{}) (meta.MeshObject, []string, error) {
+	r := migrateResource(kind, object, func(map[string]interface{}) ([]string, error) { return nil, nil })
+	return r.object, r.Unconvertible, r.Err
+}
+
+// migrateResource converts an object stored by the control plane, and decodes
+// it in the schema of the target version, which is the one of emctl itself.
+func migrateResource(kind string, object map[string]interface{}, converter Converter) *result {
+	name, _ := object["name"].(string)
+	r := &result{Kind: kind, Name: name, Result: resultFailed}
+
+	spec := map[string]interface{}{}
+	for k, v := range object {
+		if k != "name" {
+			spec[k] = v
+		}
+	}
+
+	unconvertible, err := converter(spec)
+	if err != nil {
+		r.Err = errors.Wrap(err, "convert")
+		return r
+	}
+	r.Unconvertible = unconvertible
+
+	buff, err := json.Marshal(map[string]interface{}{
+		"apiVersion": resource.DefaultAPIVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       spec,
+	})
+	if err != nil {
+		r.Err = errors.Wrap(err, "marshal converted object")
+		return r
+	}
+
+	r.object, _, err = util.NewDecoder(true).Decode(buff)
+	if err != nil {
+		// NOTE: Fields left by converters are unknown to the target version.
+		var unknown *util.UnknownFieldError
+		if errors.As(err, &unknown) {
+			r.Unconvertible = append(r.Unconvertible, unknown.Field)
+			r.Err = errors.Errorf("field %s is unknown in the target version", unknown.Field)
+			return r
+		}
+		r.Err = err
+		return r
+	}
+
+	r.Result = resultConverted
+	return r
+}
+
+func failed(results []*result) bool {
+	for _, r := range results {
+		if r.Result == resultFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func printResults(w io.Writer, results []*result) {
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Kind", "Name", "Result", "Unconvertible Fields", "Message"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Result]++
+
+		message := ""
+		if r.Err != nil {
+			message = r.Err.Error()
+		}
+		table.Append([]string{r.Kind, r.Name, r.Result, strings.Join(r.Unconvertible, ","), message})
+	}
+
+	table.Render()
+
+	fmt.Fprintf(w, "\n%d converted, %d rewritten, %d failed\n",
+		counts[resultConverted], counts[resultRewritten], counts[resultFailed])
+}
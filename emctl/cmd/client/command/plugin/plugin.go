@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package plugin makes emctl run as the kubectl plugin kubectl mesh,
+// and generates the krew manifest distributing the plugin.
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/pkg/version"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// BinaryName is the name kubectl finds the plugin kubectl mesh by.
+	BinaryName = "kubectl-mesh"
+
+	krewAPIVersion = "krew.googlecontainertools.github.com/v1alpha2"
+	homepage       = "https://github.com/megaease/easemesh"
+	releaseURL     = homepage + "/releases/download/%s"
+)
+
+type (
+	// KrewManifest is the manifest of a plugin in the krew index.
+	KrewManifest struct {
+		APIVersion string       `yaml:"apiVersion"`
+		Kind       string       `yaml:"kind"`
+		Metadata   KrewMetadata `yaml:"metadata"`
+		Spec       KrewSpec     `yaml:"spec"`
+	}
+
+	// KrewMetadata is the metadata of the plugin.
+	KrewMetadata struct {
+		Name string `yaml:"name"`
+	}
+
+	// KrewSpec is the spec of the plugin.
+	KrewSpec struct {
+		Version          string          `yaml:"version"`
+		Homepage         string          `yaml:"homepage"`
+		ShortDescription string          `yaml:"shortDescription"`
+		Description      string          `yaml:"description"`
+		Platforms        []*KrewPlatform `yaml:"platforms"`
+	}
+
+	// KrewPlatform is the archive of the plugin for a platform.
+	KrewPlatform struct {
+		Selector KrewSelector `yaml:"selector"`
+		URI      string       `yaml:"uri"`
+		SHA256   string       `yaml:"sha256"`
+		Bin      string       `yaml:"bin"`
+	}
+
+	// KrewSelector selects the platform by os and arch.
+	KrewSelector struct {
+		MatchLabels map[string]string `yaml:"matchLabels"`
+	}
+)
+
+// IsPlugin reports whether emctl runs as the kubectl plugin.
+func IsPlugin(arg0 string) bool {
+	return strings.TrimSuffix(filepath.Base(arg0), ".exe") == BinaryName
+}
+
+// AdaptCommand makes usages and examples of the commands follow kubectl mesh.
+//
+// NOTE: The root command is named mesh, and kubectl is prefixed in usages,
+// since cobra takes the first word of Use as the name of the command.
+func AdaptCommand(root *cobra.Command) {
+	root.Use = "mesh"
+	root.SetUsageTemplate(strings.NewReplacer(
+		"{{.UseLine}}", "kubectl {{.UseLine}}",
+		"{{.CommandPath}}", "kubectl {{.CommandPath}}",
+	).Replace(root.UsageTemplate()))
+
+	var adapt func(cmd *cobra.Command)
+	adapt = func(cmd *cobra.Command) {
+		cmd.Example = strings.ReplaceAll(cmd.Example, "emctl ", "kubectl mesh ")
+		for _, child := range cmd.Commands() {
+			adapt(child)
+		}
+	}
+	adapt(root)
+}
+
+// RunKrewManifest is the entrypoint of the emctl plugin krew-manifest sub command
+func RunKrewManifest(cmd *cobra.Command, flag *flags.PluginKrewManifest) {
+	if flag.Version == "" {
+		flag.Version = version.RELEASE
+	}
+	if !strings.HasPrefix(flag.Version, "v") {
+		flag.Version = "v" + flag.Version
+	}
+	if flag.BaseURL == "" {
+		flag.BaseURL = fmt.Sprintf(releaseURL, flag.Version)
+	}
+
+	manifest, err := buildKrewManifest(flag, fileSHA256)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	buff, err := yaml.Marshal(manifest)
+	if err != nil {
+		common.ExitWithErrorf("marshal krew manifest failed: %w", err)
+	}
+	fmt.Print(string(buff))
+}
+
+// ArchiveName returns the name of the archive of the plugin for the platform.
+func ArchiveName(goos, arch string) string {
+	return fmt.Sprintf("%s_%s_%s.tar.gz", BinaryName, goos, arch)
+}
+
+func buildKrewManifest(flag *flags.PluginKrewManifest, checksum func(path string) (string, error)) (*KrewManifest, error) {
+	if len(flag.Platforms) == 0 {
+		return nil, errors.New("--platforms is empty")
+	}
+
+	manifest := &KrewManifest{
+		APIVersion: krewAPIVersion,
+		Kind:       "Plugin",
+		Metadata:   KrewMetadata{Name: "mesh"},
+		Spec: KrewSpec{
+			Version:          flag.Version,
+			Homepage:         homepage,
+			ShortDescription: "Manage and operate the EaseMesh",
+			Description: "Install the EaseMesh into the cluster, and manage mesh resources such as services,\n" +
+				"canaries and ingresses with the same commands as emctl, e.g. kubectl mesh get service.\n",
+		},
+	}
+
+	for _, platform := range flag.Platforms {
+		parts := strings.Split(platform, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid platform %s, expecting <os>/<arch>", platform)
+		}
+		goos, arch := parts[0], parts[1]
+
+		archive := ArchiveName(goos, arch)
+		sum, err := checksum(filepath.Join(flag.ArchiveDir, archive))
+		if err != nil {
+			return nil, err
+		}
+
+		bin := BinaryName
+		if goos == "windows" {
+			bin += ".exe"
+		}
+		manifest.Spec.Platforms = append(manifest.Spec.Platforms, &KrewPlatform{
+			Selector: KrewSelector{MatchLabels: map[string]string{"os": goos, "arch": arch}},
+			URI:      strings.TrimSuffix(flag.BaseURL, "/") + "/" + archive,
+			SHA256:   sum,
+			Bin:      bin,
+		})
+	}
+
+	return manifest, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "open archive %s", path)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", errors.Wrapf(err, "read archive %s", path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+func TestIsPlugin(t *testing.T) {
+	for arg0, want := range map[string]bool{
+		"/usr/local/bin/kubectl-mesh": true,
+		"kubectl-mesh.exe":            true,
+		"./bin/emctl":                 false,
+		"kubectl-mesh-dev":            false,
+	} {
+		if got := IsPlugin(arg0); got != want {
+			t.Errorf("IsPlugin(%s): want %v, got %v", arg0, want, got)
+		}
+	}
+}
+
+func TestAdaptCommand(t *testing.T) {
+	root := &cobra.Command{Use: "emctl"}
+	get := &cobra.Command{Use: "get", Example: "emctl get service\nemctl get service -o yaml", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(get)
+
+	AdaptCommand(root)
+	if get.Example != "kubectl mesh get service\nkubectl mesh get service -o yaml" {
+		t.Errorf("unexpected example %s", get.Example)
+	}
+	if usage := get.UsageString(); !strings.Contains(usage, "kubectl mesh get") {
+		t.Errorf("usage should contain kubectl mesh get, got:\n%s", usage)
+	}
+}
+
+func TestBuildKrewManifest(t *testing.T) {
+	flag := &flags.PluginKrewManifest{
+		Version:    "v1.3.0",
+		BaseURL:    "https://example.com/releases/v1.3.0/",
+		ArchiveDir: "dist",
+		Platforms:  []string{"linux/amd64", "windows/amd64"},
+	}
+	checksum := func(path string) (string, error) {
+		return "sum-of-" + filepath.Base(path), nil
+	}
+
+	manifest, err := buildKrewManifest(flag, checksum)
+	if err != nil {
+		t.Fatalf("build krew manifest failed: %v", err)
+	}
+	if manifest.Metadata.Name != "mesh" || manifest.Spec.Version != "v1.3.0" || len(manifest.Spec.Platforms) != 2 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	linux, windows := manifest.Spec.Platforms[0], manifest.Spec.Platforms[1]
+	if linux.URI != "https://example.com/releases/v1.3.0/kubectl-mesh_linux_amd64.tar.gz" ||
+		linux.SHA256 != "sum-of-kubectl-mesh_linux_amd64.tar.gz" || linux.Bin != "kubectl-mesh" ||
+		linux.Selector.MatchLabels["os"] != "linux" || linux.Selector.MatchLabels["arch"] != "amd64" {
+		t.Errorf("unexpected linux platform %+v", linux)
+	}
+	if windows.Bin != "kubectl-mesh.exe" {
+		t.Errorf("unexpected bin %s of windows", windows.Bin)
+	}
+
+	flag.Platforms = []string{"linux"}
+	if _, err := buildKrewManifest(flag, checksum); err == nil {
+		t.Errorf("invalid platform should fail")
+	}
+}
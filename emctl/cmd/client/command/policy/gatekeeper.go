@@ -0,0 +1,237 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	templateAPIVersion   = "templates.gatekeeper.sh/v1"
+	constraintAPIVersion = "constraints.gatekeeper.sh/v1beta1"
+
+	serviceTenantKind = "EasemeshServiceTenant"
+	sidecarImageKind  = "EasemeshSidecarImage"
+)
+
+type (
+	object struct {
+		APIVersion string                 `json:"apiVersion"`
+		Kind       string                 `json:"kind"`
+		Metadata   objectMetadata         `json:"metadata"`
+		Spec       map[string]interface{} `json:"spec"`
+	}
+
+	objectMetadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+
+	kindMatcher struct {
+		APIGroups []string `json:"apiGroups"`
+		Kinds     []string `json:"kinds"`
+	}
+)
+
+var (
+	deploymentMatcher     = kindMatcher{APIGroups: []string{"apps"}, Kinds: []string{"Deployment"}}
+	meshDeploymentMatcher = kindMatcher{APIGroups: []string{"mesh.megaease.com"}, Kinds: []string{"MeshDeployment"}}
+	podMatcher            = kindMatcher{APIGroups: []string{""}, Kinds: []string{"Pod"}}
+)
+
+// serviceTenantRego denies mesh services, which are workloads annotated
+// with the service name and MeshDeployments, without a tenant declared
+// by the label or the annotation of the key in parameters.
+const serviceTenantRego = `package easemeshservicetenant
+
+service_name(obj) = name {
+  name := obj.metadata.annotations["mesh.megaease.com/service-name"]
+} else = name {
+  obj.kind == "MeshDeployment"
+  name := obj.spec.service.name
+}
+
+declared(obj, key) {
+  obj.metadata.labels[key] != ""
+}
+
+declared(obj, key) {
+  obj.metadata.annotations[key] != ""
+}
+
+violation[{"msg": msg}] {
+  obj := input.review.object
+  name := service_name(obj)
+  not declared(obj, input.parameters.key)
+  msg := sprintf("mesh service %v of %v %v must declare its tenant by the label or annotation %v", [name, obj.kind, obj.metadata.name, input.parameters.key])
+}
+`
+
+// sidecarImageRego denies sidecar and initializer images injected by the
+// operator, or overridden by annotations of workloads, which aren't from
+// the approved registries in parameters.
+const sidecarImageRego = `package easemeshsidecarimage
+
+mesh_containers := {"easemesh-sidecar", "initializer"}
+
+image_annotations := {"mesh.megaease.com/sidecar-image", "mesh.megaease.com/init-container-image"}
+
+images[image] {
+  input.review.object.kind == "Pod"
+  container := input.review.object.spec.containers[_]
+  mesh_containers[container.name]
+  image := container.image
+}
+
+images[image] {
+  input.review.object.kind == "Pod"
+  container := input.review.object.spec.initContainers[_]
+  mesh_containers[container.name]
+  image := container.image
+}
+
+images[image] {
+  image := input.review.object.metadata.annotations[key]
+  image_annotations[key]
+}
+
+approved(image) {
+  startswith(image, input.parameters.registries[_])
+}
+
+violation[{"msg": msg}] {
+  image := images[_]
+  not approved(image)
+  obj := input.review.object
+  msg := sprintf("sidecar image %v of %v %v isn't from approved registries %v", [image, obj.kind, obj.metadata.name, input.parameters.registries])
+}
+`
+
+// RunExportGatekeeper is the entrypoint of the emctl policy export-gatekeeper sub command
+func RunExportGatekeeper(cmd *cobra.Command, flag *flags.PolicyExportGatekeeper) {
+	err := validateExportGatekeeper(flag)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	docs := []string{}
+	for _, obj := range gatekeeperObjects(flag) {
+		buff, err := yaml.Marshal(obj)
+		if err != nil {
+			common.ExitWithErrorf("marshal %s %s failed: %w", obj.Kind, obj.Metadata.Name, err)
+		}
+		docs = append(docs, string(buff))
+	}
+	fmt.Print(strings.Join(docs, "---\n"))
+}
+
+func validateExportGatekeeper(flag *flags.PolicyExportGatekeeper) error {
+	switch flag.EnforcementAction {
+	case "deny", "dryrun", "warn":
+	default:
+		return errors.Errorf("unsupported enforcement action %q (support deny, dryrun, warn)", flag.EnforcementAction)
+	}
+	if flag.TenantKey == "" {
+		return errors.Errorf("--tenant-key is required")
+	}
+	if len(flag.ApprovedRegistries) == 0 {
+		return errors.Errorf("--approved-registries is required")
+	}
+	return nil
+}
+
+// gatekeeperObjects returns ConstraintTemplates and their Constraints of
+// rules of mesh governance. The Constraints match workloads before the
+// operator injects sidecars, and Pods after it, because Gatekeeper validates
+// objects after all mutating webhooks.
+func gatekeeperObjects(flag *flags.PolicyExportGatekeeper) []*object {
+	return []*object{
+		constraintTemplate(serviceTenantKind, serviceTenantRego, map[string]interface{}{
+			"key": map[string]interface{}{"type": "string"},
+		}),
+		constraintTemplate(sidecarImageKind, sidecarImageRego, map[string]interface{}{
+			"registries": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+		}),
+		constraint(flag, serviceTenantKind, "easemesh-service-tenant",
+			[]kindMatcher{deploymentMatcher, meshDeploymentMatcher},
+			map[string]interface{}{"key": flag.TenantKey}),
+		constraint(flag, sidecarImageKind, "easemesh-sidecar-image",
+			[]kindMatcher{deploymentMatcher, meshDeploymentMatcher, podMatcher},
+			map[string]interface{}{"registries": flag.ApprovedRegistries}),
+	}
+}
+
+func constraintTemplate(kind, rego string, parameters map[string]interface{}) *object {
+	return &object{
+		APIVersion: templateAPIVersion,
+		Kind:       "ConstraintTemplate",
+		Metadata:   objectMetadata{Name: strings.ToLower(kind)},
+		Spec: map[string]interface{}{
+			"crd": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"names": map[string]interface{}{"kind": kind},
+					"validation": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{
+							"type":       "object",
+							"properties": parameters,
+						},
+					},
+				},
+			},
+			"targets": []interface{}{
+				map[string]interface{}{
+					"target": "admission.k8s.gatekeeper.sh",
+					"rego":   rego,
+				},
+			},
+		},
+	}
+}
+
+func constraint(flag *flags.PolicyExportGatekeeper, kind, name string,
+	kinds []kindMatcher, parameters map[string]interface{}) *object {
+	match := map[string]interface{}{"kinds": kinds}
+	if len(flag.ExcludedNamespaces) != 0 {
+		match["excludedNamespaces"] = flag.ExcludedNamespaces
+	}
+
+	return &object{
+		APIVersion: constraintAPIVersion,
+		Kind:       kind,
+		Metadata: objectMetadata{
+			Name:   name,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "emctl"},
+		},
+		Spec: map[string]interface{}{
+			"enforcementAction": flag.EnforcementAction,
+			"match":             match,
+			"parameters":        parameters,
+		},
+	}
+}
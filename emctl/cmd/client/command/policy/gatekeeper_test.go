@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGatekeeperObjects(t *testing.T) {
+	flag := &flags.PolicyExportGatekeeper{}
+	flag.AttachCmd(&cobra.Command{})
+	if err := validateExportGatekeeper(flag); err != nil {
+		t.Fatalf("default flags should be valid: %v", err)
+	}
+
+	objects := gatekeeperObjects(flag)
+	if len(objects) != 4 {
+		t.Fatalf("expected 4 objects, got %d", len(objects))
+	}
+
+	templates := map[string]*object{}
+	for _, obj := range objects {
+		if obj.Kind == "ConstraintTemplate" {
+			templates[obj.Metadata.Name] = obj
+		}
+	}
+	for _, obj := range objects {
+		if obj.Kind == "ConstraintTemplate" {
+			continue
+		}
+		template := templates[strings.ToLower(obj.Kind)]
+		if template == nil {
+			t.Fatalf("constraint %s has no template of kind %s", obj.Metadata.Name, obj.Kind)
+		}
+		if obj.Spec["enforcementAction"] != "deny" {
+			t.Fatalf("unexpected enforcement action %v", obj.Spec["enforcementAction"])
+		}
+		match := obj.Spec["match"].(map[string]interface{})
+		if !reflect.DeepEqual(match["excludedNamespaces"], []string{"kube-system", "easemesh"}) {
+			t.Fatalf("unexpected excluded namespaces %v", match["excludedNamespaces"])
+		}
+	}
+
+	parameters := objects[3].Spec["parameters"].(map[string]interface{})
+	if !reflect.DeepEqual(parameters["registries"], []string{"docker.io/megaease/"}) {
+		t.Fatalf("unexpected approved registries %v", parameters["registries"])
+	}
+	parameters = objects[2].Spec["parameters"].(map[string]interface{})
+	if parameters["key"] != "mesh.megaease.com/tenant" {
+		t.Fatalf("unexpected tenant key %v", parameters["key"])
+	}
+}
+
+func TestValidateExportGatekeeper(t *testing.T) {
+	for _, flag := range []*flags.PolicyExportGatekeeper{
+		{TenantKey: "tenant", ApprovedRegistries: []string{"docker.io/"}, EnforcementAction: "block"},
+		{ApprovedRegistries: []string{"docker.io/"}, EnforcementAction: "deny"},
+		{TenantKey: "tenant", EnforcementAction: "warn"},
+	} {
+		if err := validateExportGatekeeper(flag); err == nil {
+			t.Fatalf("flags %+v should be invalid", flag)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/fatih/color"
+	yamljsontool "github.com/ghodss/yaml"
+	"gopkg.in/yaml.v2"
+)
+
+// noneValue is the value of a custom column absent in the object.
+const noneValue = "<none>"
+
+type customColumn struct {
+	header string
+	path   []string
+}
+
+// statusColors are colors of values in columns about status, values
+// absent in them are kept as they are.
+var statusColors = map[string]map[string]color.Attribute{
+	"Status": {
+		"UP":             color.FgGreen,
+		"DOWN":           color.FgRed,
+		"OUT_OF_SERVICE": color.FgRed,
+		"STARTING":       color.FgYellow,
+	},
+	"Severity": {
+		"critical": color.FgRed,
+		"warning":  color.FgYellow,
+	},
+}
+
+// colorize colors the value of the column by its meaning, it's disabled
+// if the output isn't a terminal or the env NO_COLOR is set.
+func colorize(column, value string) string {
+	attribute, ok := statusColors[column][value]
+	if !ok {
+		return value
+	}
+	return color.New(attribute).Sprint(value)
+}
+
+// parseCustomColumns parses the spec of custom columns in the form of
+// <header>:<path>[,<header>:<path>...], e.g. NAME:.metadata.name,PORTS:.spec.ports[0].
+func parseCustomColumns(spec string) ([]*customColumn, error) {
+	var columns []*customColumn
+	for _, field := range strings.Split(spec, ",") {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], ".") {
+			return nil, common.CodeErrorf(common.ExitCodeValidation,
+				"invalid custom column %q, must be in the form <header>:<path>, e.g. NAME:.metadata.name", field)
+		}
+
+		var path []string
+		for _, segment := range strings.Split(strings.TrimPrefix(parts[1], "."), ".") {
+			// NOTE: Indexes of lists are segments too, e.g. .spec.rules[0].host.
+			for segment != "" {
+				i := strings.Index(segment, "[")
+				if i < 0 {
+					path = append(path, segment)
+					break
+				}
+				j := strings.Index(segment, "]")
+				if j < i {
+					return nil, common.CodeErrorf(common.ExitCodeValidation, "invalid path %q of custom column %s", parts[1], parts[0])
+				}
+				if i > 0 {
+					path = append(path, segment[:i])
+				}
+				path = append(path, segment[i:j+1])
+				segment = segment[j+1:]
+			}
+		}
+		columns = append(columns, &customColumn{header: parts[0], path: path})
+	}
+
+	return columns, nil
+}
+
+func (p *printer) printCustomColumns(objects []meta.MeshObject) {
+	columns, err := parseCustomColumns(strings.TrimPrefix(p.outputFormat, customColumnsPrefix))
+	if err != nil {
+		common.ExitWithError(err)
+	}
+
+	var header []string
+	for _, column := range columns {
+		header = append(header, column.header)
+	}
+
+	var rows [][]string
+	for _, object := range objects {
+		value, err := toJSONValue(object)
+		if err != nil {
+			common.ExitWithErrorf("convert %s/%s failed: %w", object.Kind(), object.Name(), err)
+		}
+
+		var row []string
+		for _, column := range columns {
+			row = append(row, formatValue(lookup(value, column.path)))
+		}
+		rows = append(rows, row)
+	}
+
+	renderTable(header, rows)
+}
+
+// toJSONValue converts the object to the value decoded from its JSON form.
+func toJSONValue(object meta.MeshObject) (interface{}, error) {
+	yamlBuff, err := yaml.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	jsonBuff, err := yamljsontool.YAMLToJSON(yamlBuff)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	err = json.Unmarshal(jsonBuff, &value)
+	return value, err
+}
+
+// lookup returns the value at the path, or nil if it's absent.
+// Keys of objects are matched case-insensitively if there's no exact one.
+func lookup(value interface{}, path []string) interface{} {
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				for k, kv := range v {
+					if strings.EqualFold(k, segment) {
+						next = kv
+						break
+					}
+				}
+			}
+			value = next
+		case []interface{}:
+			if !strings.HasPrefix(segment, "[") {
+				return nil
+			}
+			i, err := strconv.Atoi(strings.Trim(segment, "[]"))
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return noneValue
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		buff, err := json.Marshal(v)
+		if err != nil {
+			return noneValue
+		}
+		return string(buff)
+	}
+}
@@ -20,12 +20,15 @@ package printer
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 	"github.com/megaease/easemeshctl/cmd/common"
 	"github.com/olekukonko/tablewriter"
 
+	"github.com/fatih/color"
+
 	jsoniter "github.com/json-iterator/go"
 	"gopkg.in/yaml.v2"
 )
@@ -36,14 +39,53 @@ type (
 		PrintObjects(objects []meta.MeshObject)
 	}
 
+	// ColumnsFunc returns extra columns of the object in the wide table.
+	ColumnsFunc func(object meta.MeshObject) []*meta.TableColumn
+
+	// Option is the option of the printer.
+	Option func(p *printer)
+
 	printer struct {
 		outputFormat string
+		wideColumns  []ColumnsFunc
 	}
 )
 
+// customColumnsPrefix is the prefix of the custom columns output format,
+// e.g. custom-columns=NAME:.metadata.name,TENANT:.spec.registerTenant.
+const customColumnsPrefix = "custom-columns="
+
 // New creates a Printer
-func New(outputFormat string) Printer {
-	return &printer{outputFormat: outputFormat}
+func New(outputFormat string, options ...Option) Printer {
+	p := &printer{outputFormat: outputFormat}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// WithWideColumns appends columns returned by fn to the wide table.
+func WithWideColumns(fn ColumnsFunc) Option {
+	return func(p *printer) {
+		p.wideColumns = append(p.wideColumns, fn)
+	}
+}
+
+// ValidateOutputFormat validates the output format, which is one of table, wide,
+// yaml, json and custom-columns=<header>:<path>[,<header>:<path>...].
+func ValidateOutputFormat(outputFormat string) error {
+	switch outputFormat {
+	case "table", "wide", "yaml", "json":
+		return nil
+	}
+
+	if strings.HasPrefix(outputFormat, customColumnsPrefix) {
+		_, err := parseCustomColumns(strings.TrimPrefix(outputFormat, customColumnsPrefix))
+		return err
+	}
+
+	return common.CodeErrorf(common.ExitCodeValidation,
+		"unsupported output format %s (support table, wide, yaml, json, custom-columns=<header>:<path>,...)", outputFormat)
 }
 
 func (p *printer) PrintObjects(objects []meta.MeshObject) {
@@ -51,72 +93,108 @@ func (p *printer) PrintObjects(objects []meta.MeshObject) {
 		fmt.Println("No resource")
 		return
 	}
-	switch p.outputFormat {
-	case "table":
-		p.printTable(objects)
-	case "json":
+	switch {
+	case p.outputFormat == "table":
+		p.printTable(objects, false)
+	case p.outputFormat == "wide":
+		p.printTable(objects, true)
+	case p.outputFormat == "json":
 		p.printJSON(objects)
-	case "yaml":
+	case p.outputFormat == "yaml":
 		p.printYAML(objects)
+	case strings.HasPrefix(p.outputFormat, customColumnsPrefix):
+		p.printCustomColumns(objects)
 	default:
-		common.ExitWithErrorf("unsupported output format: %s", p.outputFormat)
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format: %s", p.outputFormat)
 	}
 }
 
-func (p *printer) printTable(objects []meta.MeshObject) {
-	table := tablewriter.NewWriter(os.Stdout)
+// columnsOf returns columns of the object besides its kind, name and labels.
+func (p *printer) columnsOf(object meta.MeshObject, wide bool) []*meta.TableColumn {
+	var columns []*meta.TableColumn
+	if tableObject, ok := object.(meta.TableObject); ok {
+		columns = append(columns, tableObject.Columns()...)
+	}
+	if !wide {
+		return columns
+	}
 
+	if wideTableObject, ok := object.(meta.WideTableObject); ok {
+		columns = append(columns, wideTableObject.WideColumns()...)
+	}
+	for _, fn := range p.wideColumns {
+		columns = append(columns, fn(object)...)
+	}
+	return columns
+}
+
+func (p *printer) printTable(objects []meta.MeshObject, wide bool) {
 	header := []string{"Kind", "Name", "Labels"}
 
 	var headerColumns []*meta.TableColumn
 	for _, object := range objects {
-		if tableObject, ok := object.(meta.TableObject); ok {
-			headerColumns = tableObject.Columns()
+		if _, ok := object.(meta.TableObject); ok {
+			headerColumns = p.columnsOf(object, wide)
 			break
 		}
 	}
+	if headerColumns == nil {
+		headerColumns = p.columnsOf(objects[0], wide)
+	}
 
 	for _, column := range headerColumns {
 		header = append(header, column.Name)
 	}
 
-	table.SetHeader(header)
-	table.SetBorder(false)
-	table.SetRowLine(false)
-	table.SetColumnSeparator("")
-	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-	table.SetHeaderLine(false)
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-
+	var rows [][]string
 	for _, object := range objects {
 		var labels []string
 		for k, v := range object.Labels() {
 			labels = append(labels, k+"="+v)
 		}
+		sort.Strings(labels)
 
 		row := []string{
 			object.Kind(),
 			object.Name(),
 			strings.Join(labels, ","),
 		}
-
-		tableObject, ok := object.(meta.TableObject)
-		if ok {
-			for _, column := range tableObject.Columns() {
-				row = append(row, column.Value)
-			}
+		for _, column := range p.columnsOf(object, wide) {
+			row = append(row, colorize(column.Name, column.Value))
 		}
 
-		table.Append(row)
+		rows = append(rows, row)
+	}
+
+	renderTable(header, rows)
+}
+
+func renderTable(header []string, rows [][]string) {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	if !color.NoColor {
+		colors := make([]tablewriter.Colors, len(header))
+		for i := range colors {
+			colors[i] = tablewriter.Colors{tablewriter.Bold}
+		}
+		table.SetHeaderColor(colors...)
 	}
 
+	table.AppendBulk(rows)
 	table.Render()
 }
 
 func (p *printer) printYAML(objects []meta.MeshObject) {
 	yamlBuff, err := yaml.Marshal(objects)
 	if err != nil {
-		common.ExitWithErrorf("marshal %#v to yaml failed: %v", objects, err)
+		common.ExitWithErrorf("marshal %#v to yaml failed: %w", objects, err)
 	}
 
 	fmt.Printf("%s", yamlBuff)
@@ -125,18 +203,18 @@ func (p *printer) printYAML(objects []meta.MeshObject) {
 func (p *printer) printJSON(objects []meta.MeshObject) {
 	yamlBuff, err := yaml.Marshal(objects)
 	if err != nil {
-		common.ExitWithErrorf("marshal %#v to yaml failed: %v", objects, err)
+		common.ExitWithErrorf("marshal %#v to yaml failed: %w", objects, err)
 	}
 
 	var m interface{}
 	err = yaml.Unmarshal(yamlBuff, &m)
 	if err != nil {
-		common.ExitWithErrorf("unmarshal %#v to yaml failed: %v", objects, err)
+		common.ExitWithErrorf("unmarshal %#v to yaml failed: %w", objects, err)
 	}
 
 	prettyJSONBuff, err := jsoniter.MarshalIndent(m, "", "  ")
 	if err != nil {
-		common.ExitWithErrorf("marshal %#v to json failed: %v", m, err)
+		common.ExitWithErrorf("marshal %#v to json failed: %w", m, err)
 	}
 
 	fmt.Printf("%s\n", prettyJSONBuff)
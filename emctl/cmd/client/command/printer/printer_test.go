@@ -19,6 +19,7 @@ package printer
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
@@ -29,6 +30,8 @@ func TestPrinter(t *testing.T) {
 	yamlPrinter := New("yaml")
 	jsonPrinter := New("json")
 	tablePrinter := New("table")
+	widePrinter := New("wide")
+	customColumnsPrinter := New("custom-columns=KIND:.kind,NAME:.metadata.name")
 
 	for _, rtk := range meshtesting.GetAllResourceKinds() {
 		fmt.Printf("%+v", rtk)
@@ -37,6 +40,53 @@ func TestPrinter(t *testing.T) {
 		yamlPrinter.PrintObjects([]meta.MeshObject{obj})
 		jsonPrinter.PrintObjects([]meta.MeshObject{obj})
 		tablePrinter.PrintObjects([]meta.MeshObject{obj})
+		widePrinter.PrintObjects([]meta.MeshObject{obj})
+		customColumnsPrinter.PrintObjects([]meta.MeshObject{obj})
 
 	}
 }
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{"table", "wide", "yaml", "json", "custom-columns=NAME:.metadata.name,HOST:.spec.rules[0].host"} {
+		if err := ValidateOutputFormat(format); err != nil {
+			t.Fatalf("expected %s valid, got %v", format, err)
+		}
+	}
+	for _, format := range []string{"xml", "custom-columns=NAME", "custom-columns=NAME:metadata.name", "custom-columns=HOST:.spec.rules]0[.host"} {
+		if err := ValidateOutputFormat(format); err == nil {
+			t.Fatalf("expected %s invalid", format)
+		}
+	}
+}
+
+func TestCustomColumns(t *testing.T) {
+	columns, err := parseCustomColumns("NAME:.metadata.name,HOST:.spec.rules[0].host,BACKENDS:.spec.rules[0].paths")
+	if err != nil {
+		t.Fatalf("parse custom columns failed: %v", err)
+	}
+	if !reflect.DeepEqual(columns[1].path, []string{"spec", "rules", "[0]", "host"}) {
+		t.Fatalf("unexpected path: %v", columns[1].path)
+	}
+
+	value := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "pet-ingress"},
+		"spec": map[string]interface{}{
+			"Rules": []interface{}{map[string]interface{}{
+				"host":  "pet.example.com",
+				"paths": []interface{}{map[string]interface{}{"path": "/", "backend": "vets-service"}},
+			}},
+		},
+	}
+	var row []string
+	for _, column := range columns {
+		row = append(row, formatValue(lookup(value, column.path)))
+	}
+	expected := []string{"pet-ingress", "pet.example.com", `[{"backend":"vets-service","path":"/"}]`}
+	if !reflect.DeepEqual(row, expected) {
+		t.Fatalf("expected %v, got %v", expected, row)
+	}
+
+	if got := formatValue(lookup(value, []string{"spec", "rules", "[1]", "host"})); got != noneValue {
+		t.Fatalf("expected %s, got %s", noneValue, got)
+	}
+}
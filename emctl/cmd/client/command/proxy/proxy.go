@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+)
+
+// healthzPath is the path of the health check of the control plane admin API.
+const healthzPath = "/apis/v1/healthz"
+
+// Proxy exposes the control plane admin API locally through the service
+// proxy of the Kubernetes API server, authenticated by the kubeconfig.
+// Every request is sent over connections maintained by the Kubernetes
+// client, which are re-established once they're broken, e.g. the API
+// server restarts, so the proxy keeps working across disconnections.
+type Proxy struct {
+	flag      *flags.Proxy
+	target    *url.URL
+	transport http.RoundTripper
+
+	mutex     sync.Mutex
+	connected *bool
+}
+
+// Run is the entrypoint of the emctl proxy subcommand
+func Run(cmd *cobra.Command, flag *flags.Proxy) {
+	config, err := installbase.KubernetesConfig()
+	if err != nil {
+		common.ExitWithError(common.WithCode(errors.Wrap(err, "load kubeconfig"), common.ExitCodeUnreachable))
+	}
+
+	p, err := New(config, flag)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	address := net.JoinHostPort(flag.Address, strconv.Itoa(flag.Port))
+	if ip := net.ParseIP(flag.Address); ip == nil || !ip.IsLoopback() {
+		common.Warnf("the admin API is exposed on %s without authentication, anyone reaching it manages the mesh", address)
+	}
+	common.Logger().With("address", address, "namespace", flag.MeshNamespace, "service", flag.EgServiceName).
+		Infof("proxying the control plane admin API on %s, run emctl with --server %s", address, address)
+
+	err = p.Run(ctx, address)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+}
+
+// New creates a Proxy to the admin port of the control plane service.
+func New(config *rest.Config, flag *flags.Proxy) (*Proxy, error) {
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "create transport of Kubernetes")
+	}
+
+	target, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse host %s of Kubernetes", config.Host)
+	}
+	if target.Scheme == "" {
+		target, err = url.Parse("https://" + config.Host)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse host %s of Kubernetes", config.Host)
+		}
+	}
+	target.Path = path.Join(target.Path, fmt.Sprintf("/api/v1/namespaces/%s/services/%s:%s/proxy",
+		flag.MeshNamespace, flag.EgServiceName, installbase.ControlPlaneStatefulSetAdminPortName))
+
+	return &Proxy{flag: flag, target: target, transport: transport}, nil
+}
+
+// Handler returns the handler forwarding requests to the admin API.
+func (p *Proxy) Handler() http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(p.target)
+	proxy.Transport = p.transport
+
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = p.target.Host
+	}
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		// NOTE: The API server responds 503 if no endpoint of the service is ready.
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			p.setConnected(true, nil)
+		}
+		return nil
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		p.setConnected(false, err)
+		http.Error(w, fmt.Sprintf("control plane unreachable: %v", err), http.StatusBadGateway)
+	}
+
+	return proxy
+}
+
+// Run serves the proxy on the address and checks the health of the
+// control plane at every interval, until the context is done.
+func (p *Proxy) Run(ctx context.Context, address string) error {
+	server := &http.Server{
+		Addr:    address,
+		Handler: p.Handler(),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	defer server.Close()
+
+	ticker := time.NewTicker(p.flag.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		p.checkHealth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-serverErr:
+			return errors.Wrapf(err, "serve on %s", address)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Proxy) checkHealth(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.flag.HealthInterval)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.target.String()+healthzPath, nil)
+	if err != nil {
+		p.setConnected(false, err)
+		return
+	}
+
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		p.setConnected(false, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		p.setConnected(false, errors.Errorf("health check returns status code %d", resp.StatusCode))
+		return
+	}
+	p.setConnected(true, nil)
+}
+
+// setConnected logs transitions of the connection to the control plane.
+func (p *Proxy) setConnected(connected bool, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.connected != nil && *p.connected == connected {
+		return
+	}
+	p.connected = &connected
+
+	if connected {
+		common.Infof("connected to the control plane")
+		return
+	}
+	common.Warnf("lost the control plane, reconnecting: %v", err)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestProxy(t *testing.T) {
+	var requested string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Write([]byte("[]"))
+	}))
+	defer apiServer.Close()
+
+	flag := &flags.Proxy{OperationGlobal: &flags.OperationGlobal{
+		MeshNamespace: "easemesh",
+		EgServiceName: "easemesh-control-plane-service",
+	}}
+	p, err := New(&rest.Config{Host: apiServer.URL}, flag)
+	if err != nil {
+		t.Fatalf("create proxy failed: %v", err)
+	}
+
+	local := httptest.NewServer(p.Handler())
+	defer local.Close()
+
+	resp, err := http.Get(local.URL + "/apis/v1/mesh/tenants")
+	if err != nil {
+		t.Fatalf("request through the proxy failed: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	expected := "/api/v1/namespaces/easemesh/services/easemesh-control-plane-service:admin-port/proxy/apis/v1/mesh/tenants"
+	if requested != expected || string(body) != "[]" {
+		t.Fatalf("expected request to %s, got %s with body %s", expected, requested, body)
+	}
+
+	apiServer.Close()
+	resp, err = http.Get(local.URL + "/apis/v1/mesh/tenants")
+	if err != nil {
+		t.Fatalf("request through the proxy failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected status code %d once the API server is down, got %d", http.StatusBadGateway, resp.StatusCode)
+	}
+}
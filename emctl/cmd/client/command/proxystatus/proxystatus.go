@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxystatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Status is the status of a sidecar in sync with the control plane.
+type Status struct {
+	Service  string `yaml:"service" json:"service"`
+	Instance string `yaml:"instance" json:"instance"`
+	IP       string `yaml:"ip" json:"ip"`
+	// Sync is one of SYNCED, STALE and DISCONNECTED.
+	Sync          string `yaml:"sync" json:"sync"`
+	AckedVersion  int64  `yaml:"ackedVersion" json:"ackedVersion"`
+	LatestVersion int64  `yaml:"latestVersion" json:"latestVersion"`
+	// Lag is the number of versions the sidecar lags behind.
+	Lag               int64  `yaml:"lag" json:"lag"`
+	LastHeartbeatTime string `yaml:"lastHeartbeatTime" json:"lastHeartbeatTime"`
+	// HeartbeatAge is the duration since the last heartbeat, empty if it's unknown.
+	HeartbeatAge string `yaml:"heartbeatAge" json:"heartbeatAge"`
+}
+
+// Run is the entrypoint of the emctl proxy-status command
+func Run(cmd *cobra.Command, flag *flags.ProxyStatus) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+
+	client := meshclient.New(flag.Server)
+	proxyStatuses, err := client.V1Alpha1().ProxyStatus().List(ctx)
+	if err != nil {
+		common.ExitWithErrorf("list proxy statuses failed: %w", err)
+	}
+
+	statuses := syncStatuses(proxyStatuses, instanceExpiry(ctx, client), time.Now(), flag.Service)
+
+	switch flag.OutputFormat {
+	case "table":
+		printStatuses(os.Stdout, statuses)
+	case "yaml":
+		buff, err := yaml.Marshal(statuses)
+		if err != nil {
+			common.ExitWithErrorf("marshal proxy statuses failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal proxy statuses failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
+
+// instanceExpiry returns the duration without heartbeats after which sidecars
+// are disconnected, it's the instance expiry of the mesh controller.
+func instanceExpiry(ctx context.Context, client meshclient.MeshClient) time.Duration {
+	expiry := flags.DefaultInstanceExpiry * time.Second
+
+	meshController, err := client.V1Alpha1().MeshController().Get(ctx, flags.DefaultMeshControllerName)
+	if err != nil {
+		common.Warnf("get mesh controller failed, sidecars without heartbeats for %s are disconnected: %v", expiry, err)
+		return expiry
+	}
+	if meshController.InstanceExpiry == "" {
+		return expiry
+	}
+
+	configured, err := time.ParseDuration(meshController.InstanceExpiry)
+	if err != nil {
+		common.Warnf("invalid instance expiry %s of the mesh controller: %v", meshController.InstanceExpiry, err)
+		return expiry
+	}
+	return configured
+}
+
+// syncStatuses compares the versions acked by sidecars with the latest ones, sidecars
+// without heartbeats for the expiry are disconnected no matter what versions they acked.
+// Statuses are sorted by services and instances, empty service means all services.
+func syncStatuses(proxyStatuses []*resource.ProxyStatus, expiry time.Duration, now time.Time, service string) []*Status {
+	statuses := []*Status{}
+	for _, proxyStatus := range proxyStatuses {
+		if service != "" && proxyStatus.ServiceName != service {
+			continue
+		}
+
+		status := &Status{
+			Service:           proxyStatus.ServiceName,
+			Instance:          proxyStatus.InstanceID,
+			IP:                proxyStatus.IP,
+			Sync:              resource.ProxySynced,
+			AckedVersion:      proxyStatus.AckedVersion,
+			LatestVersion:     proxyStatus.LatestVersion,
+			LastHeartbeatTime: proxyStatus.LastHeartbeatTime,
+		}
+		if proxyStatus.LatestVersion > proxyStatus.AckedVersion {
+			status.Sync = resource.ProxyStale
+			status.Lag = proxyStatus.LatestVersion - proxyStatus.AckedVersion
+		}
+
+		heartbeat, err := time.Parse(time.RFC3339, proxyStatus.LastHeartbeatTime)
+		if err != nil {
+			status.Sync = resource.ProxyDisconnected
+		} else {
+			age := now.Sub(heartbeat)
+			if age < 0 {
+				age = 0
+			}
+			status.HeartbeatAge = age.Truncate(time.Second).String()
+			if age > expiry {
+				status.Sync = resource.ProxyDisconnected
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Service != statuses[j].Service {
+			return statuses[i].Service < statuses[j].Service
+		}
+		return statuses[i].Instance < statuses[j].Instance
+	})
+	return statuses
+}
+
+func printStatuses(w io.Writer, statuses []*Status) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No sidecar connected to the control plane found")
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Service", "Instance", "IP", "Sync", "Acked", "Latest", "Lag", "Last Heartbeat"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	unsynced := 0
+	for _, status := range statuses {
+		if status.Sync != resource.ProxySynced {
+			unsynced++
+		}
+		heartbeat := "unknown"
+		if status.HeartbeatAge != "" {
+			heartbeat = status.HeartbeatAge + " ago"
+		}
+		table.Append([]string{status.Service, status.Instance, status.IP, status.Sync,
+			strconv.FormatInt(status.AckedVersion, 10), strconv.FormatInt(status.LatestVersion, 10),
+			strconv.FormatInt(status.Lag, 10), heartbeat})
+	}
+	table.Render()
+
+	if unsynced != 0 {
+		fmt.Fprintf(w, "\n%d of %d sidecars are stale or disconnected\n", unsynced, len(statuses))
+	}
+}
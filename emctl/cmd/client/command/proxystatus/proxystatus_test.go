@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxystatus
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestSyncStatuses(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	heartbeat := func(ago time.Duration) string {
+		return now.Add(-ago).Format(time.RFC3339)
+	}
+
+	proxyStatuses := []*resource.ProxyStatus{
+		{ServiceName: "order-mesh", InstanceID: "order-2", AckedVersion: 7, LatestVersion: 9, LastHeartbeatTime: heartbeat(3 * time.Second)},
+		{ServiceName: "order-mesh", InstanceID: "order-1", AckedVersion: 9, LatestVersion: 9, LastHeartbeatTime: heartbeat(5 * time.Second)},
+		{ServiceName: "delivery-mesh", InstanceID: "delivery-1", AckedVersion: 4, LatestVersion: 4, LastHeartbeatTime: heartbeat(time.Minute)},
+		{ServiceName: "restaurant-mesh", InstanceID: "restaurant-1", AckedVersion: 2, LatestVersion: 2},
+	}
+
+	statuses := syncStatuses(proxyStatuses, 15*time.Second, now, "")
+	want := []struct {
+		instance string
+		sync     string
+		lag      int64
+		age      string
+	}{
+		{"delivery-1", resource.ProxyDisconnected, 0, "1m0s"},
+		{"order-1", resource.ProxySynced, 0, "5s"},
+		{"order-2", resource.ProxyStale, 2, "3s"},
+		{"restaurant-1", resource.ProxyDisconnected, 0, ""},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("want %d statuses, got %d", len(want), len(statuses))
+	}
+	for i, w := range want {
+		s := statuses[i]
+		if s.Instance != w.instance || s.Sync != w.sync || s.Lag != w.lag || s.HeartbeatAge != w.age {
+			t.Errorf("status %d: want %+v, got %+v", i, w, s)
+		}
+	}
+
+	statuses = syncStatuses(proxyStatuses, 15*time.Second, now, "order-mesh")
+	if len(statuses) != 2 {
+		t.Fatalf("want 2 statuses of order-mesh, got %d", len(statuses))
+	}
+
+	buff := &bytes.Buffer{}
+	printStatuses(buff, statuses)
+	if !strings.Contains(buff.String(), "1 of 2 sidecars are stale or disconnected") {
+		t.Errorf("unexpected output:\n%s", buff.String())
+	}
+}
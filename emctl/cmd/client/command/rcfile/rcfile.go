@@ -31,9 +31,23 @@ type (
 	// RCFile contains information of rc file of emctl.
 	RCFile struct {
 		Server string `yaml:"server"`
+		// User is the identity recorded in the audit log of the control plane.
+		User string `yaml:"user,omitempty"`
+		// Token is the access token authorizing requests to the control plane.
+		Token string `yaml:"token,omitempty"`
+		// Telemetry is the opt-in reporting of anonymous usage of emctl.
+		Telemetry *Telemetry `yaml:"telemetry,omitempty"`
 
 		path string
 	}
+
+	// Telemetry contains the settings of reporting anonymous usage of emctl.
+	Telemetry struct {
+		Enabled  bool   `yaml:"enabled"`
+		Endpoint string `yaml:"endpoint,omitempty"`
+		// ClientID is random, it only tells reports from the same emctl apart.
+		ClientID string `yaml:"clientID,omitempty"`
+	}
 )
 
 const (
@@ -66,7 +80,8 @@ func (r *RCFile) Marshal() error {
 		return errors.Wrapf(err, "marshal %+v to yaml failed", r)
 	}
 
-	err = ioutil.WriteFile(r.path, buff, 0o644)
+	// NOTE: The rc file may contain the access token, so it's only readable by the owner.
+	err = ioutil.WriteFile(r.path, buff, 0o600)
 	if err != nil {
 		return errors.Wrapf(err, "write file %s failed", r.path)
 	}
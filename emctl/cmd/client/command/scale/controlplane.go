@@ -0,0 +1,357 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scale
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var pollInterval = 2 * time.Second
+
+type controlPlaneScaler struct {
+	flag    *flags.ScaleControlPlane
+	client  kubernetes.Interface
+	members memberClient
+}
+
+// RunControlPlane is the entrypoint of the emctl scale control-plane sub command
+func RunControlPlane(cmd *cobra.Command, flag *flags.ScaleControlPlane) {
+	if flag.Replicas < 1 {
+		common.ExitWithCodef(common.ExitCodeValidation, "--replicas must be positive, got %d", flag.Replicas)
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	members, err := newControlPlaneMemberClient(client, flag.MeshNamespace)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	s := &controlPlaneScaler{flag: flag, client: client, members: members}
+	err = s.scale()
+	if err != nil {
+		common.ExitWithErrorf("scale control plane failed: %w", err)
+	}
+}
+
+func newControlPlaneMemberClient(client kubernetes.Interface, namespace string) (memberClient, error) {
+	adminURLs, err := installbase.GetMeshControlPlaneEndpoints(client, namespace,
+		installbase.ControlPlanePlubicServiceName, installbase.ControlPlaneStatefulSetAdminPortName)
+	if err != nil {
+		return nil, errors.Wrap(err, "get admin endpoints of control plane")
+	}
+	clientURLs, err := installbase.GetMeshControlPlaneEndpoints(client, namespace,
+		installbase.ControlPlanePlubicServiceName, installbase.ControlPlaneStatefulSetClientPortName)
+	if err != nil {
+		return nil, errors.Wrap(err, "get client endpoints of control plane")
+	}
+	if len(adminURLs) == 0 || len(clientURLs) == 0 {
+		return nil, errors.Errorf("no endpoint of control plane found")
+	}
+
+	return newMemberClient(adminURLs[0], clientURLs[0]), nil
+}
+
+// scale changes members one by one, so the etcd cluster keeps the quorum
+// during scaling:
+//
+// - scale up: add the member to the etcd cluster, then start it.
+// - scale down: stop the member with the largest ordinal, then purge it.
+//
+// Members are restarted one by one at last to pick up the new initial cluster.
+func (s *controlPlaneScaler) scale() error {
+	sts, err := s.getStatefulSet()
+	if err != nil {
+		return err
+	}
+	current := int(*sts.Spec.Replicas)
+	target := s.flag.Replicas
+
+	if target == current {
+		common.Infof("control plane has %d members already", current)
+		return nil
+	}
+	if target%2 == 0 {
+		common.Warnf("an even number of members tolerates no more failures than %d members", target-1)
+	}
+	if zones := controlPlaneZones(sts); len(zones) != 0 && target != len(zones) {
+		common.Warnf("control plane is spread across %d zones by --zones, %d members don't keep one member per zone, "+
+			"a zone outage may lose the quorum", len(zones), target)
+	}
+
+	err = s.checkMembers(current)
+	if err != nil {
+		return err
+	}
+
+	container, err := controlPlaneContainer(sts)
+	if err != nil {
+		return err
+	}
+	value, exists := argValue(container.Args, initialClusterArg)
+	if !exists {
+		return errors.Errorf("flag %s not found in container %s", initialClusterArg, container.Name)
+	}
+	cluster, err := parseInitialCluster(value)
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < target; i++ {
+		err = s.addMember(cluster, i)
+		if err != nil {
+			return err
+		}
+	}
+	for i := current - 1; i >= target; i-- {
+		err = s.removeMember(i)
+		if err != nil {
+			return err
+		}
+	}
+
+	urls, err := peerURLs(cluster, target)
+	if err != nil {
+		return err
+	}
+	common.Infof("restart members to update initial cluster")
+	err = s.updateStatefulSet(func(sts *appsV1.StatefulSet) error {
+		return updateSpec(sts, urls, target, 0)
+	})
+	if err != nil {
+		return err
+	}
+	err = s.waitRolledOut(target)
+	if err != nil {
+		return err
+	}
+
+	common.Infof("scale control plane from %d to %d members successfully", current, target)
+	return nil
+}
+
+// checkMembers checks all members are reporting, scaling an unhealthy
+// cluster may lose the quorum.
+func (s *controlPlaneScaler) checkMembers(replicas int) error {
+	names, err := s.members.list()
+	if err != nil {
+		return common.WithCode(err, common.ExitCodeUnreachable)
+	}
+	if len(names) != replicas {
+		return common.CodeErrorf(common.ExitCodeConflict,
+			"control plane has %d replicas but %d members %v reporting, fix it before scaling", replicas, len(names), names)
+	}
+	return nil
+}
+
+func (s *controlPlaneScaler) addMember(cluster map[string]string, index int) error {
+	name := installbase.ControlPlanePodName(index)
+	urls, err := peerURLs(cluster, index+1)
+	if err != nil {
+		return err
+	}
+
+	common.Infof("add member %s", name)
+
+	// NOTE: The claim left by a former member holds data of
+	// the member removed from the cluster, which can't rejoin.
+	err = s.deletePVC(name)
+	if err != nil {
+		return err
+	}
+
+	err = s.members.add(urls[name])
+	if err != nil {
+		return errors.Wrapf(err, "add member %s", name)
+	}
+
+	err = s.updateStatefulSet(func(sts *appsV1.StatefulSet) error {
+		return updateSpec(sts, urls, index+1, index)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.poll(func() error {
+		err := s.checkPodReady(name)
+		if err != nil {
+			return err
+		}
+		return s.checkMemberReporting(name, true)
+	})
+}
+
+func (s *controlPlaneScaler) removeMember(index int) error {
+	name := installbase.ControlPlanePodName(index)
+	common.Infof("remove member %s", name)
+
+	err := s.updateStatefulSet(func(sts *appsV1.StatefulSet) error {
+		r := int32(index)
+		sts.Spec.Replicas = &r
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.poll(func() error {
+		_, err := s.client.CoreV1().Pods(s.flag.MeshNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return errors.Errorf("pod %s is still running", name)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.members.purge(name)
+	if err != nil {
+		return errors.Wrapf(err, "purge member %s", name)
+	}
+	err = s.poll(func() error {
+		return s.checkMemberReporting(name, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.deletePVC(name)
+}
+
+func (s *controlPlaneScaler) getStatefulSet() (*appsV1.StatefulSet, error) {
+	sts, err := s.client.AppsV1().StatefulSets(s.flag.MeshNamespace).
+		Get(context.TODO(), installbase.ControlPlaneStatefulSetName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, common.CodeErrorf(common.ExitCodeNotFound, "statefulset %s not found in namespace %s",
+				installbase.ControlPlaneStatefulSetName, s.flag.MeshNamespace)
+		}
+		return nil, errors.Wrapf(err, "get statefulset %s", installbase.ControlPlaneStatefulSetName)
+	}
+	return sts, nil
+}
+
+func (s *controlPlaneScaler) updateStatefulSet(mutate func(sts *appsV1.StatefulSet) error) error {
+	sts, err := s.getStatefulSet()
+	if err != nil {
+		return err
+	}
+	err = mutate(sts)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.AppsV1().StatefulSets(s.flag.MeshNamespace).Update(context.TODO(), sts, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "update statefulset %s", sts.Name)
+	}
+	return nil
+}
+
+func (s *controlPlaneScaler) deletePVC(podName string) error {
+	name := pvcName(podName)
+	err := s.client.CoreV1().PersistentVolumeClaims(s.flag.MeshNamespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "delete persistent volume claim %s", name)
+	}
+	return nil
+}
+
+func (s *controlPlaneScaler) checkPodReady(name string) error {
+	pod, err := s.client.CoreV1().Pods(s.flag.MeshNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady && c.Status == v1.ConditionTrue {
+			return nil
+		}
+	}
+	return errors.Errorf("pod %s is not ready", name)
+}
+
+func (s *controlPlaneScaler) checkMemberReporting(name string, reporting bool) error {
+	names, err := s.members.list()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, n := range names {
+		if n == name {
+			found = true
+			break
+		}
+	}
+	if found != reporting {
+		if reporting {
+			return errors.Errorf("member %s is not reporting", name)
+		}
+		return errors.Errorf("member %s is still reporting", name)
+	}
+	return nil
+}
+
+func (s *controlPlaneScaler) waitRolledOut(replicas int) error {
+	return s.poll(func() error {
+		sts, err := s.getStatefulSet()
+		if err != nil {
+			return err
+		}
+		status := sts.Status
+		if status.ObservedGeneration < sts.Generation ||
+			int(status.UpdatedReplicas) != replicas || int(status.ReadyReplicas) != replicas {
+			return errors.Errorf("statefulset %s is rolling out, %d updated, %d ready of %d",
+				sts.Name, status.UpdatedReplicas, status.ReadyReplicas, replicas)
+		}
+		return s.checkMembers(replicas)
+	})
+}
+
+func (s *controlPlaneScaler) poll(fn func() error) error {
+	deadline := time.Now().Add(s.flag.WaitTimeout)
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "timeout after %s", s.flag.WaitTimeout)
+		}
+		common.Debugf("%v, retry in %s", err, pollInterval)
+		time.Sleep(pollInterval)
+	}
+}
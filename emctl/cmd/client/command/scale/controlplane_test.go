@@ -0,0 +1,255 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scale
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "easemesh"
+
+type fakeMemberClient struct {
+	names  []string
+	added  []string
+	purged []string
+}
+
+func (c *fakeMemberClient) list() ([]string, error) {
+	return append([]string{}, c.names...), nil
+}
+
+func (c *fakeMemberClient) add(peerURL string) error {
+	c.added = append(c.added, peerURL)
+	u, err := url.Parse(peerURL)
+	if err != nil {
+		return err
+	}
+	c.names = append(c.names, strings.Split(u.Hostname(), ".")[0])
+	return nil
+}
+
+func (c *fakeMemberClient) purge(name string) error {
+	c.purged = append(c.purged, name)
+	for i, n := range c.names {
+		if n == name {
+			c.names = append(c.names[:i], c.names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func testPeerURL(index int) string {
+	name := installbase.ControlPlanePodName(index)
+	return "http://" + name + "." + installbase.ControlPlaneHeadlessServiceName + "." + testNamespace + ":2380"
+}
+
+func testStatefulSet(replicas, readyReplicas int) *appsV1.StatefulSet {
+	cluster := map[string]string{}
+	for i := 0; i < replicas; i++ {
+		cluster[installbase.ControlPlanePodName(i)] = testPeerURL(i)
+	}
+
+	r := int32(replicas)
+	return &appsV1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.ControlPlaneStatefulSetName,
+			Namespace: testNamespace,
+		},
+		Spec: appsV1.StatefulSetSpec{
+			Replicas: &r,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: controlPlaneContainerName,
+							Args: []string{
+								"-f", installbase.ControlPlaneConfigMapVolumeMountPath,
+								"--initial-cluster", initialClusterStr(cluster),
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: appsV1.StatefulSetStatus{
+			ReadyReplicas:   int32(readyReplicas),
+			UpdatedReplicas: int32(readyReplicas),
+		},
+	}
+}
+
+func readyPod(index int) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      installbase.ControlPlanePodName(index),
+			Namespace: testNamespace,
+		},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+		},
+	}
+}
+
+func memberNames(n int) []string {
+	names := []string{}
+	for i := 0; i < n; i++ {
+		names = append(names, installbase.ControlPlanePodName(i))
+	}
+	return names
+}
+
+func newTestScaler(replicas int, members *fakeMemberClient, objects ...runtime.Object) *controlPlaneScaler {
+	pollInterval = time.Millisecond
+	return &controlPlaneScaler{
+		flag: &flags.ScaleControlPlane{
+			OperationGlobal: &flags.OperationGlobal{MeshNamespace: testNamespace},
+			Replicas:        replicas,
+			WaitTimeout:     100 * time.Millisecond,
+		},
+		client:  fake.NewSimpleClientset(objects...),
+		members: members,
+	}
+}
+
+func TestScaleUp(t *testing.T) {
+	members := &fakeMemberClient{names: memberNames(3)}
+	stalePVC := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name: pvcName(installbase.ControlPlanePodName(4)), Namespace: testNamespace,
+	}}
+	s := newTestScaler(5, members, testStatefulSet(3, 5), readyPod(3), readyPod(4), stalePVC)
+
+	err := s.scale()
+	if err != nil {
+		t.Fatalf("scale up failed: %v", err)
+	}
+
+	if want := []string{testPeerURL(3), testPeerURL(4)}; !reflect.DeepEqual(members.added, want) {
+		t.Errorf("added members: want %v, got %v", want, members.added)
+	}
+
+	sts, _ := s.getStatefulSet()
+	if *sts.Spec.Replicas != 5 {
+		t.Errorf("replicas: want 5, got %d", *sts.Spec.Replicas)
+	}
+	if p := *sts.Spec.UpdateStrategy.RollingUpdate.Partition; p != 0 {
+		t.Errorf("partition: want 0, got %d", p)
+	}
+	args := sts.Spec.Template.Spec.Containers[0].Args
+	value, _ := argValue(args, initialClusterArg)
+	if got := len(strings.Split(value, ",")); got != 5 {
+		t.Errorf("initial cluster should have 5 members, got %s", value)
+	}
+	if value, _ := argValue(args, stateFlagArg); value != stateFlagExisting {
+		t.Errorf("state flag: want %s, got %s", stateFlagExisting, value)
+	}
+
+	_, err = s.client.CoreV1().PersistentVolumeClaims(testNamespace).Get(context.TODO(), stalePVC.Name, metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("stale persistent volume claim %s should be deleted", stalePVC.Name)
+	}
+}
+
+func TestScaleDown(t *testing.T) {
+	members := &fakeMemberClient{names: memberNames(5)}
+	s := newTestScaler(3, members, testStatefulSet(5, 3))
+
+	err := s.scale()
+	if err != nil {
+		t.Fatalf("scale down failed: %v", err)
+	}
+
+	want := []string{installbase.ControlPlanePodName(4), installbase.ControlPlanePodName(3)}
+	if !reflect.DeepEqual(members.purged, want) {
+		t.Errorf("purged members: want %v, got %v", want, members.purged)
+	}
+
+	sts, _ := s.getStatefulSet()
+	if *sts.Spec.Replicas != 3 {
+		t.Errorf("replicas: want 3, got %d", *sts.Spec.Replicas)
+	}
+	value, _ := argValue(sts.Spec.Template.Spec.Containers[0].Args, initialClusterArg)
+	if strings.Contains(value, installbase.ControlPlanePodName(3)) {
+		t.Errorf("initial cluster should not contain removed members, got %s", value)
+	}
+}
+
+func TestScaleUnhealthyCluster(t *testing.T) {
+	members := &fakeMemberClient{names: memberNames(2)}
+	s := newTestScaler(5, members, testStatefulSet(3, 3))
+
+	err := s.scale()
+	if err == nil {
+		t.Fatalf("scaling a cluster missing members should fail")
+	}
+	if len(members.added) != 0 {
+		t.Errorf("no member should be added, got %v", members.added)
+	}
+}
+
+func TestSetArg(t *testing.T) {
+	args := []string{"-f", "config.yaml", "--initial-cluster", "a=1"}
+
+	got := setArg(args, "--initial-cluster", "a=1,b=2")
+	if want := []string{"-f", "config.yaml", "--initial-cluster", "a=1,b=2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	got = setArg(args, "--state-flag", "existing")
+	if want := append(args, "--state-flag", "existing"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if args[3] != "a=1" {
+		t.Errorf("args should not be changed, got %v", args)
+	}
+}
+
+func TestPeerURLs(t *testing.T) {
+	cluster, err := parseInitialCluster(initialClusterStr(map[string]string{
+		installbase.ControlPlanePodName(0): testPeerURL(0),
+	}))
+	if err != nil {
+		t.Fatalf("parse initial cluster failed: %v", err)
+	}
+
+	urls, err := peerURLs(cluster, 2)
+	if err != nil {
+		t.Fatalf("peer urls failed: %v", err)
+	}
+	if got := urls[installbase.ControlPlanePodName(1)]; got != testPeerURL(1) {
+		t.Errorf("want %s, got %s", testPeerURL(1), got)
+	}
+
+	_, err = parseInitialCluster("invalid")
+	if err == nil {
+		t.Errorf("parse invalid initial cluster should fail")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// NOTE: The Easegress admin API has no endpoint to add a member,
+	// so members are added via the gRPC gateway of the embedded etcd,
+	// which serves on the client port.
+	etcdMemberListPath = "/v3/cluster/member/list"
+	etcdMemberAddPath  = "/v3/cluster/member/add"
+
+	memberRequestTimeout = 10 * time.Second
+)
+
+type (
+	// memberClient manages members of the etcd cluster formed by the control plane.
+	memberClient interface {
+		// list returns names of members reporting to the control plane.
+		list() ([]string, error)
+		// add adds a member of the peer URL to the etcd cluster,
+		// it must be called before the member starts.
+		add(peerURL string) error
+		// purge removes a stopped member from the control plane.
+		purge(name string) error
+	}
+
+	httpMemberClient struct {
+		// adminURL is the address of the Easegress admin API.
+		adminURL string
+		// clientURL is the address of the etcd client port.
+		clientURL string
+	}
+
+	etcdMember struct {
+		ID       string   `json:"ID"`
+		Name     string   `json:"name"`
+		PeerURLs []string `json:"peerURLs"`
+	}
+
+	etcdMemberList struct {
+		Members []etcdMember `json:"members"`
+	}
+)
+
+func newMemberClient(adminURL, clientURL string) memberClient {
+	return &httpMemberClient{adminURL: adminURL, clientURL: clientURL}
+}
+
+func (c *httpMemberClient) list() ([]string, error) {
+	result, err := client.NewHTTPJSON().
+		Get(c.adminURL+installbase.MemberList, nil, memberRequestTimeout, nil).
+		HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+			if statusCode != http.StatusOK {
+				return nil, errors.Errorf("list members of control plane failed, status code: %d, body: %s", statusCode, body)
+			}
+			return parseMemberNames(body)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]string), nil
+}
+
+func (c *httpMemberClient) add(peerURL string) error {
+	members, err := c.etcdMembers()
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		for _, u := range m.PeerURLs {
+			if u == peerURL {
+				// NOTE: Added by a former interrupted scaling.
+				return nil
+			}
+		}
+	}
+
+	_, err = client.NewHTTPJSON().
+		Post(c.clientURL+etcdMemberAddPath, map[string]interface{}{"peerURLs": []string{peerURL}}, memberRequestTimeout, nil).
+		HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+			if statusCode != http.StatusOK {
+				return nil, errors.Errorf("add member %s failed, status code: %d, body: %s", peerURL, statusCode, body)
+			}
+			return nil, nil
+		})
+	return err
+}
+
+func (c *httpMemberClient) purge(name string) error {
+	_, err := client.NewHTTPJSON().
+		Delete(fmt.Sprintf("%s%s/%s", c.adminURL, installbase.MemberList, name), nil, memberRequestTimeout, nil).
+		HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+			if statusCode != http.StatusOK && statusCode != http.StatusNotFound {
+				return nil, errors.Errorf("purge member %s failed, status code: %d, body: %s", name, statusCode, body)
+			}
+			return nil, nil
+		})
+	return err
+}
+
+func (c *httpMemberClient) etcdMembers() ([]etcdMember, error) {
+	result, err := client.NewHTTPJSON().
+		Post(c.clientURL+etcdMemberListPath, map[string]interface{}{}, memberRequestTimeout, nil).
+		HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+			if statusCode != http.StatusOK {
+				return nil, errors.Errorf("list etcd members failed, status code: %d, body: %s", statusCode, body)
+			}
+			list := &etcdMemberList{}
+			err := json.Unmarshal(body, list)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal etcd members")
+			}
+			return list.Members, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]etcdMember), nil
+}
+
+// parseMemberNames parses names of members from the status of members
+// reported by the Easegress admin API.
+func parseMemberNames(body []byte) ([]string, error) {
+	var members []struct {
+		Options struct {
+			Name string `yaml:"name"`
+		} `yaml:"options"`
+	}
+	err := yaml.Unmarshal(body, &members)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal members of control plane")
+	}
+
+	names := make([]string, 0, len(members))
+	for _, m := range members {
+		names = append(names, m.Options.Name)
+	}
+	return names, nil
+}
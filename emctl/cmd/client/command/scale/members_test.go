@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scale
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+)
+
+func TestHTTPMemberClient(t *testing.T) {
+	var addedPeerURLs []string
+	purged := ""
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(installbase.MemberList, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"options": {"name": "easemesh-control-plane-0"}}, {"options": {"name": "easemesh-control-plane-1"}}]`))
+	})
+	mux.HandleFunc(installbase.MemberList+"/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		purged = r.URL.Path[len(installbase.MemberList+"/"):]
+	})
+	mux.HandleFunc(etcdMemberListPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"members": [{"ID": "1", "name": "easemesh-control-plane-0", "peerURLs": ["http://p0:2380"]}]}`))
+	})
+	mux.HandleFunc(etcdMemberAddPath, func(w http.ResponseWriter, r *http.Request) {
+		req := map[string][]string{}
+		json.NewDecoder(r.Body).Decode(&req)
+		addedPeerURLs = append(addedPeerURLs, req["peerURLs"]...)
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := newMemberClient(server.URL, server.URL)
+
+	names, err := c.list()
+	if err != nil {
+		t.Fatalf("list members failed: %v", err)
+	}
+	if want := []string{"easemesh-control-plane-0", "easemesh-control-plane-1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("members: want %v, got %v", want, names)
+	}
+
+	// NOTE: Adding an existing member is a no-op.
+	err = c.add("http://p0:2380")
+	if err != nil {
+		t.Fatalf("add existing member failed: %v", err)
+	}
+	err = c.add("http://p1:2380")
+	if err != nil {
+		t.Fatalf("add member failed: %v", err)
+	}
+	if want := []string{"http://p1:2380"}; !reflect.DeepEqual(addedPeerURLs, want) {
+		t.Errorf("added members: want %v, got %v", want, addedPeerURLs)
+	}
+
+	err = c.purge("easemesh-control-plane-1")
+	if err != nil {
+		t.Fatalf("purge member failed: %v", err)
+	}
+	if purged != "easemesh-control-plane-1" {
+		t.Errorf("purged member: want easemesh-control-plane-1, got %s", purged)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scale
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	controlPlaneContainerName = "easegress"
+
+	initialClusterArg = "--initial-cluster"
+	stateFlagArg      = "--state-flag"
+	stateFlagExisting = "existing"
+)
+
+// controlPlaneContainer returns the Easegress container of the control plane StatefulSet.
+func controlPlaneContainer(sts *appsV1.StatefulSet) (*v1.Container, error) {
+	containers := sts.Spec.Template.Spec.Containers
+	for i := range containers {
+		if containers[i].Name == controlPlaneContainerName {
+			return &containers[i], nil
+		}
+	}
+	return nil, errors.Errorf("container %s not found in statefulset %s", controlPlaneContainerName, sts.Name)
+}
+
+// controlPlaneZones returns zones the control plane is spread across by
+// emctl install --zones, empty if it isn't zone aware.
+func controlPlaneZones(sts *appsV1.StatefulSet) []string {
+	affinity := sts.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == v1.LabelTopologyZone && expr.Operator == v1.NodeSelectorOpIn {
+				return expr.Values
+			}
+		}
+	}
+	return nil
+}
+
+// argValue returns the value following the flag in args.
+func argValue(args []string, flag string) (string, bool) {
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"="), true
+		}
+	}
+	return "", false
+}
+
+// setArg sets the value of the flag in args, the flag is appended if absent.
+func setArg(args []string, flag, value string) []string {
+	result := make([]string, len(args))
+	copy(result, args)
+
+	for i, arg := range result {
+		if arg == flag && i+1 < len(result) {
+			result[i+1] = value
+			return result
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			result[i] = flag + "=" + value
+			return result
+		}
+	}
+	return append(result, flag, value)
+}
+
+// parseInitialCluster parses the initial cluster in format name1=url1,name2=url2.
+func parseInitialCluster(value string) (map[string]string, error) {
+	cluster := map[string]string{}
+	for _, member := range strings.Split(value, ",") {
+		if member == "" {
+			continue
+		}
+		kv := strings.SplitN(member, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid member %q of initial cluster", member)
+		}
+		cluster[kv[0]] = kv[1]
+	}
+	if len(cluster) == 0 {
+		return nil, errors.Errorf("empty initial cluster")
+	}
+	return cluster, nil
+}
+
+// peerURLs returns the peer URLs of the first replicas members, URLs of
+// members absent in the initial cluster follow the one of the first member.
+func peerURLs(cluster map[string]string, replicas int) (map[string]string, error) {
+	firstName := installbase.ControlPlanePodName(0)
+	firstURL, exists := cluster[firstName]
+	if !exists {
+		return nil, errors.Errorf("member %s not found in initial cluster", firstName)
+	}
+
+	urls := map[string]string{}
+	for i := 0; i < replicas; i++ {
+		name := installbase.ControlPlanePodName(i)
+		if url, exists := cluster[name]; exists {
+			urls[name] = url
+			continue
+		}
+		urls[name] = strings.Replace(firstURL, "//"+firstName+".", "//"+name+".", 1)
+	}
+	return urls, nil
+}
+
+func initialClusterStr(urls map[string]string) string {
+	members := []string{}
+	for name, url := range urls {
+		members = append(members, fmt.Sprintf("%s=%s", name, url))
+	}
+	sort.Strings(members)
+	return strings.Join(members, ",")
+}
+
+// updateSpec updates the StatefulSet to run the first replicas members, the
+// ones with ordinals less than partition are left untouched. Members started
+// by the updated spec join the existing cluster.
+func updateSpec(sts *appsV1.StatefulSet, urls map[string]string, replicas, partition int) error {
+	container, err := controlPlaneContainer(sts)
+	if err != nil {
+		return err
+	}
+
+	container.Args = setArg(container.Args, initialClusterArg, initialClusterStr(urls))
+	container.Args = setArg(container.Args, stateFlagArg, stateFlagExisting)
+
+	r := int32(replicas)
+	sts.Spec.Replicas = &r
+
+	p := int32(partition)
+	sts.Spec.UpdateStrategy = appsV1.StatefulSetUpdateStrategy{
+		Type: appsV1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsV1.RollingUpdateStatefulSetStrategy{
+			Partition: &p,
+		},
+	}
+	return nil
+}
+
+// pvcName returns the name of the PersistentVolumeClaim of the member.
+func pvcName(podName string) string {
+	return installbase.ControlPlanePVCName + "-" + podName
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// sidecarObjectsPath lists the Easegress objects of the sidecar, which carry
+	// the routes, the backends and the policies received from the control plane.
+	sidecarObjectsPath = "/apis/v1/objects"
+	// sidecarStatusPath lists the runtime status of the objects.
+	sidecarStatusPath = "/apis/v1/status/objects"
+)
+
+var (
+	// kubectlCommand is the command forwarding the admin port of the sidecar.
+	kubectlCommand = "kubectl"
+
+	// forwardPort forwards a local port to the port of the pod, it's replaced in tests.
+	forwardPort = kubectlPortForward
+
+	forwardingRegexp = regexp.MustCompile(`Forwarding from 127\.0\.0\.1:(\d+) ->`)
+)
+
+// ConfigDump is the effective configuration of a sidecar.
+type ConfigDump struct {
+	// Pod is in the form of namespace/name.
+	Pod     string        `json:"pod"`
+	Image   string        `json:"image"`
+	Objects []interface{} `json:"objects"`
+	Status  interface{}   `json:"status,omitempty"`
+}
+
+// RunConfigDump is the entrypoint of the emctl sidecar config-dump sub command
+func RunConfigDump(cmd *cobra.Command, flag *flags.SidecarConfigDump, pod string) {
+	switch flag.OutputFormat {
+	case "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support yaml, json)",
+			flag.OutputFormat)
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancel()
+
+	dump, err := configDump(ctx, client, flag, pod)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	buff, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		common.ExitWithErrorf("marshal config dump failed: %w", err)
+	}
+	if flag.OutputFormat == "yaml" {
+		buff, err = yaml.JSONToYAML(buff)
+		if err != nil {
+			common.ExitWithErrorf("marshal config dump failed: %w", err)
+		}
+		fmt.Print(string(buff))
+		return
+	}
+	fmt.Println(string(buff))
+}
+
+// configDump fetches the configuration from the admin API of the sidecar in the pod.
+// The admin API only listens on localhost of the pod, it's reached by port-forward
+// authenticated by the kubeconfig, and the local port is only bound to localhost.
+func configDump(ctx context.Context, client kubernetes.Interface, flag *flags.SidecarConfigDump, name string) (*ConfigDump, error) {
+	pod, err := client.CoreV1().Pods(flag.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, common.CodeErrorf(common.ExitCodeNotFound, "pod %s/%s not found", flag.Namespace, name)
+		}
+		return nil, common.WithCode(errors.Wrapf(err, "get pod %s/%s", flag.Namespace, name), common.ExitCodeUnreachable)
+	}
+	image, ok := sidecarImage(pod)
+	if !ok {
+		return nil, common.CodeErrorf(common.ExitCodeNotFound, "pod %s/%s isn't injected with sidecar", flag.Namespace, name)
+	}
+
+	address, stop, err := forwardPort(ctx, flag.Namespace, name, flag.AdminPort)
+	if err != nil {
+		return nil, common.WithCode(errors.Wrap(err, "forward admin port of the sidecar"), common.ExitCodeUnreachable)
+	}
+	defer stop()
+
+	dump := &ConfigDump{
+		Pod:   flag.Namespace + "/" + name,
+		Image: image,
+	}
+
+	objects, err := getAdminAPI(ctx, address, sidecarObjectsPath)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := objects.([]interface{}); ok {
+		dump.Objects = list
+	} else if objects != nil {
+		dump.Objects = []interface{}{objects}
+	}
+
+	// NOTE: The status is a supplement, sidecars of old versions may not serve it.
+	dump.Status, err = getAdminAPI(ctx, address, sidecarStatusPath)
+	if err != nil {
+		common.Warnf("get status of the sidecar: %v", err)
+	}
+
+	return dump, nil
+}
+
+// getAdminAPI gets the path of the admin API, which responds YAML or JSON.
+func getAdminAPI(ctx context.Context, address, path string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+path, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create request of %s", path)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, common.WithCode(errors.Wrapf(err, "get %s", path), common.ExitCodeUnreachable)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read response of %s", path)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, errors.Errorf("get %s returns status code %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result interface{}
+	err = yaml.Unmarshal(body, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal response of %s", path)
+	}
+	return result, nil
+}
+
+// kubectlPortForward runs kubectl port-forward on a random local port of localhost,
+// and returns the address once the forwarding is ready.
+func kubectlPortForward(ctx context.Context, namespace, pod string, port int) (string, func(), error) {
+	_, err := exec.LookPath(kubectlCommand)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "%s is required to forward ports", kubectlCommand)
+	}
+
+	cmd := exec.Command(kubectlCommand, "port-forward", "--namespace", namespace, "--address", "127.0.0.1",
+		"pod/"+pod, ":"+strconv.Itoa(port))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "pipe output of port-forward")
+	}
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+
+	err = cmd.Start()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "start port-forward")
+	}
+	stop := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+
+	ready := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if match := forwardingRegexp.FindStringSubmatch(scanner.Text()); match != nil {
+				ready <- "127.0.0.1:" + match[1]
+				break
+			}
+		}
+		close(ready)
+		// NOTE: Drain the output to keep kubectl from blocking.
+		for scanner.Scan() {
+		}
+	}()
+
+	select {
+	case address, ok := <-ready:
+		if !ok {
+			stop()
+			return "", nil, errors.Errorf("port-forward exited: %s", strings.TrimSpace(stderr.String()))
+		}
+		return address, stop, nil
+	case <-ctx.Done():
+		stop()
+		return "", nil, errors.Wrapf(ctx.Err(), "wait for port-forward: %s", strings.TrimSpace(stderr.String()))
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case sidecarObjectsPath:
+			w.Write([]byte("- name: sidecar-ingress\n  kind: HTTPServer\n  rules:\n  - paths:\n    - pathPrefix: /\n"))
+		case sidecarStatusPath:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	forwarded := false
+	forwardPort = func(ctx context.Context, namespace, pod string, port int) (string, func(), error) {
+		if namespace != "shop" || pod != "orders-0" || port != flags.DefaultSidecarAdminPort {
+			t.Errorf("unexpected port-forward of %s/%s:%d", namespace, pod, port)
+		}
+		return strings.TrimPrefix(server.URL, "http://"), func() { forwarded = true }, nil
+	}
+	defer func() { forwardPort = kubectlPortForward }()
+
+	legacy := injectedPod("shop", "legacy-0", "", nil)
+	legacy.Spec.Containers = legacy.Spec.Containers[:1]
+	client := fake.NewSimpleClientset(injectedPod("shop", "orders-0", "megaease/easegress:easemesh", nil), legacy)
+	flag := &flags.SidecarConfigDump{Namespace: "shop", AdminPort: flags.DefaultSidecarAdminPort, Timeout: time.Second}
+
+	dump, err := configDump(context.TODO(), client, flag, "orders-0")
+	if err != nil {
+		t.Fatalf("config dump failed: %v", err)
+	}
+	if !forwarded {
+		t.Errorf("port-forward should be stopped")
+	}
+	if dump.Pod != "shop/orders-0" || dump.Image != "megaease/easegress:easemesh" || dump.Status != nil {
+		t.Errorf("unexpected config dump %+v", dump)
+	}
+	if len(dump.Objects) != 1 || dump.Objects[0].(map[string]interface{})["kind"] != "HTTPServer" {
+		t.Errorf("unexpected objects %+v", dump.Objects)
+	}
+
+	for _, pod := range []string{"payments-0", "legacy-0"} {
+		_, err = configDump(context.TODO(), client, flag, pod)
+		if common.ExitCode(err) != common.ExitCodeNotFound {
+			t.Errorf("config dump of %s should exit with %d, got %v", pod, common.ExitCodeNotFound, err)
+		}
+	}
+}
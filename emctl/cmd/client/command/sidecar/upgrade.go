@@ -0,0 +1,333 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	kindDeployment  = "Deployment"
+	kindStatefulSet = "StatefulSet"
+	kindDaemonSet   = "DaemonSet"
+	kindReplicaSet  = "ReplicaSet"
+
+	// restartedAtAnnotation is the annotation set by kubectl rollout restart,
+	// changing it in the pod template rolls out new pods.
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+var pollInterval = 2 * time.Second
+
+type (
+	// workload is a workload with pods running stale sidecars.
+	workload struct {
+		Kind      string
+		Namespace string
+		Name      string
+		StalePods int
+	}
+
+	upgrader struct {
+		flag   *flags.SidecarUpgrade
+		client kubernetes.Interface
+		image  string
+	}
+)
+
+// RunUpgrade is the entrypoint of the emctl sidecar upgrade sub command
+func RunUpgrade(cmd *cobra.Command, flag *flags.SidecarUpgrade) {
+	if flag.Namespace == "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "--namespace is required")
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	image, err := injectedImage(client, flag.MeshNamespace)
+	if err != nil {
+		common.ExitWithError(err)
+	}
+
+	u := &upgrader{flag: flag, client: client, image: image}
+	workloads, err := u.staleWorkloads()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	if len(workloads) == 0 {
+		common.Infof("sidecars of all workloads in namespace %s are %s", flag.Namespace, image)
+		return
+	}
+
+	batches, err := splitBatches(workloads, flag.MaxUnavailable)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	if flag.DryRun {
+		printBatches(os.Stdout, batches)
+		return
+	}
+
+	err = u.upgrade(batches)
+	if err != nil {
+		common.ExitWithErrorf("upgrade sidecars failed: %w", err)
+	}
+	common.Infof("upgrade sidecars of %d workloads in namespace %s to %s successfully", len(workloads), flag.Namespace, image)
+}
+
+func (w *workload) String() string {
+	return w.Kind + "/" + w.Name
+}
+
+// staleWorkloads returns workloads with pods running sidecars other than the injected one.
+func (u *upgrader) staleWorkloads() ([]*workload, error) {
+	pods, err := listInjectedPods(u.client, u.flag.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*workload{}
+	for i := range pods {
+		pod := &pods[i]
+		image, ok := sidecarImage(pod)
+		if !ok || image == u.image {
+			continue
+		}
+
+		kind, name, err := u.controllerOf(pod)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "" {
+			common.Warnf("pod %s runs sidecar %s but isn't managed by a Deployment, StatefulSet or DaemonSet, restart it manually",
+				pod.Name, image)
+			continue
+		}
+
+		key := kind + "/" + name
+		w, exists := byKey[key]
+		if !exists {
+			w = &workload{Kind: kind, Namespace: pod.Namespace, Name: name}
+			byKey[key] = w
+		}
+		w.StalePods++
+	}
+
+	workloads := make([]*workload, 0, len(byKey))
+	for _, w := range byKey {
+		workloads = append(workloads, w)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		return workloads[i].String() < workloads[j].String()
+	})
+	return workloads, nil
+}
+
+// controllerOf returns the workload managing the pod, empty kind means none.
+func (u *upgrader) controllerOf(pod metav1.Object) (string, string, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", "", nil
+	}
+
+	switch owner.Kind {
+	case kindStatefulSet, kindDaemonSet:
+		return owner.Kind, owner.Name, nil
+	case kindReplicaSet:
+		rs, err := u.client.AppsV1().ReplicaSets(pod.GetNamespace()).Get(context.TODO(), owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", errors.Wrapf(err, "get replicaset %s", owner.Name)
+		}
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.Kind == kindDeployment {
+			return owner.Kind, owner.Name, nil
+		}
+	}
+	return "", "", nil
+}
+
+// splitBatches splits workloads into batches, each of which has at most
+// maxUnavailable workloads, a percentage of workloads is rounded up.
+func splitBatches(workloads []*workload, maxUnavailable string) ([][]*workload, error) {
+	value := intstr.Parse(maxUnavailable)
+	size, err := intstr.GetScaledValueFromIntOrPercent(&value, len(workloads), true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid --max-unavailable %s", maxUnavailable)
+	}
+	if size < 1 {
+		return nil, errors.Errorf("--max-unavailable %s must be positive", maxUnavailable)
+	}
+
+	var batches [][]*workload
+	for len(workloads) > size {
+		batches = append(batches, workloads[:size])
+		workloads = workloads[size:]
+	}
+	return append(batches, workloads), nil
+}
+
+// upgrade restarts workloads batch by batch, the next batch is restarted
+// after all workloads of the previous one are rolled out.
+func (u *upgrader) upgrade(batches [][]*workload) error {
+	for i, batch := range batches {
+		names := make([]string, 0, len(batch))
+		for _, w := range batch {
+			names = append(names, w.String())
+		}
+		common.Infof("batch %d/%d: restart %s", i+1, len(batches), strings.Join(names, ", "))
+
+		for _, w := range batch {
+			err := u.restart(w)
+			if err != nil {
+				return err
+			}
+		}
+		for _, w := range batch {
+			err := u.poll(func() error { return u.checkRolledOut(w) })
+			if err != nil {
+				return errors.Wrapf(err, "roll out %s", w)
+			}
+		}
+	}
+	return nil
+}
+
+// restart rolls out new pods of the workload, which are injected with the new sidecar.
+func (u *upgrader) restart(w *workload) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339)))
+
+	var err error
+	ctx := context.TODO()
+	switch w.Kind {
+	case kindDeployment:
+		_, err = u.client.AppsV1().Deployments(w.Namespace).Patch(ctx, w.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case kindStatefulSet:
+		_, err = u.client.AppsV1().StatefulSets(w.Namespace).Patch(ctx, w.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case kindDaemonSet:
+		_, err = u.client.AppsV1().DaemonSets(w.Namespace).Patch(ctx, w.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return errors.Errorf("unsupported workload %s", w)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "restart %s", w)
+	}
+	return nil
+}
+
+func (u *upgrader) checkRolledOut(w *workload) error {
+	ctx := context.TODO()
+	switch w.Kind {
+	case kindDeployment:
+		deploy, err := u.client.AppsV1().Deployments(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas, status := int32(1), deploy.Status
+		if deploy.Spec.Replicas != nil {
+			replicas = *deploy.Spec.Replicas
+		}
+		if status.ObservedGeneration < deploy.Generation || status.UpdatedReplicas != replicas ||
+			status.Replicas != replicas || status.AvailableReplicas != replicas {
+			return errors.Errorf("%s is rolling out, %d updated, %d available of %d",
+				w, status.UpdatedReplicas, status.AvailableReplicas, replicas)
+		}
+	case kindStatefulSet:
+		sts, err := u.client.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas, status := int32(1), sts.Status
+		if sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		}
+		if status.ObservedGeneration < sts.Generation || status.UpdatedReplicas != replicas ||
+			status.ReadyReplicas != replicas || status.UpdateRevision != status.CurrentRevision {
+			return errors.Errorf("%s is rolling out, %d updated, %d ready of %d",
+				w, status.UpdatedReplicas, status.ReadyReplicas, replicas)
+		}
+	case kindDaemonSet:
+		ds, err := u.client.AppsV1().DaemonSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		status := ds.Status
+		if status.ObservedGeneration < ds.Generation || status.UpdatedNumberScheduled != status.DesiredNumberScheduled ||
+			status.NumberAvailable != status.DesiredNumberScheduled {
+			return errors.Errorf("%s is rolling out, %d updated, %d available of %d",
+				w, status.UpdatedNumberScheduled, status.NumberAvailable, status.DesiredNumberScheduled)
+		}
+	}
+	return nil
+}
+
+func (u *upgrader) poll(fn func() error) error {
+	deadline := time.Now().Add(u.flag.WaitTimeout)
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return common.WithCode(errors.Wrapf(err, "timeout after %s", u.flag.WaitTimeout), common.ExitCodeTimeout)
+		}
+		common.Debugf("%v, retry in %s", err, pollInterval)
+		time.Sleep(pollInterval)
+	}
+}
+
+func printBatches(w io.Writer, batches [][]*workload) {
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Batch", "Kind", "Name", "Stale Pods"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for i, batch := range batches {
+		for _, wl := range batch {
+			table.Append([]string{strconv.Itoa(i + 1), wl.Kind, wl.Name, strconv.Itoa(wl.StalePods)})
+		}
+	}
+
+	table.Render()
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	appsV1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testImage = "docker.io/megaease/easegress:v2.0.0"
+
+func testDeployment(name string, replicas int32) *appsV1.Deployment {
+	return &appsV1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec:       appsV1.DeploymentSpec{Replicas: &replicas},
+		Status: appsV1.DeploymentStatus{
+			Replicas:          replicas,
+			UpdatedReplicas:   replicas,
+			AvailableReplicas: replicas,
+		},
+	}
+}
+
+func testReplicaSet(name, deployment string) *appsV1.ReplicaSet {
+	controller := true
+	return &appsV1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "shop",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: kindDeployment, Name: deployment, Controller: &controller},
+			},
+		},
+	}
+}
+
+func newTestUpgrader(maxUnavailable string) *upgrader {
+	pollInterval = time.Millisecond
+	return &upgrader{
+		flag: &flags.SidecarUpgrade{
+			OperationGlobal: &flags.OperationGlobal{MeshNamespace: flags.DefaultMeshNamespace},
+			Namespace:       "shop",
+			MaxUnavailable:  maxUnavailable,
+			WaitTimeout:     100 * time.Millisecond,
+		},
+		client: fake.NewSimpleClientset(
+			testDeployment("order", 2), testReplicaSet("order-7d9f", "order"),
+			testDeployment("delivery", 1), testReplicaSet("delivery-5c8b", "delivery"),
+			testDeployment("payment", 1), testReplicaSet("payment-6b7c", "payment"),
+			&appsV1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "stock", Namespace: "shop"},
+				Status:     appsV1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 1},
+			},
+			injectedPod("shop", "order-7d9f-a", "docker.io/megaease/easegress:v1.3.0",
+				&metav1.OwnerReference{Kind: kindReplicaSet, Name: "order-7d9f"}),
+			injectedPod("shop", "order-7d9f-b", "docker.io/megaease/easegress:v1.3.0",
+				&metav1.OwnerReference{Kind: kindReplicaSet, Name: "order-7d9f"}),
+			injectedPod("shop", "delivery-5c8b-a", "docker.io/megaease/easegress:v1.3.0",
+				&metav1.OwnerReference{Kind: kindReplicaSet, Name: "delivery-5c8b"}),
+			injectedPod("shop", "payment-6b7c-a", testImage,
+				&metav1.OwnerReference{Kind: kindReplicaSet, Name: "payment-6b7c"}),
+			injectedPod("shop", "stock-0", "docker.io/megaease/easegress:v1.3.0",
+				&metav1.OwnerReference{Kind: kindStatefulSet, Name: "stock"}),
+			injectedPod("shop", "debug", "docker.io/megaease/easegress:v1.3.0", nil),
+		),
+		image: testImage,
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	workloads := make([]*workload, 5)
+	for maxUnavailable, sizes := range map[string][]int{
+		"20%":  {1, 1, 1, 1, 1},
+		"50%":  {3, 2},
+		"2":    {2, 2, 1},
+		"100%": {5},
+	} {
+		batches, err := splitBatches(workloads, maxUnavailable)
+		if err != nil {
+			t.Fatalf("split by %s failed: %v", maxUnavailable, err)
+		}
+		if len(batches) != len(sizes) {
+			t.Fatalf("split by %s: want %d batches, got %d", maxUnavailable, len(sizes), len(batches))
+		}
+		for i, batch := range batches {
+			if len(batch) != sizes[i] {
+				t.Fatalf("split by %s: want sizes %v, got %d in batch %d", maxUnavailable, sizes, len(batch), i)
+			}
+		}
+	}
+
+	for _, maxUnavailable := range []string{"0", "0%", "-1", "half"} {
+		if _, err := splitBatches(workloads, maxUnavailable); err == nil {
+			t.Errorf("expected error of --max-unavailable %s", maxUnavailable)
+		}
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	u := newTestUpgrader("50%")
+
+	workloads, err := u.staleWorkloads()
+	if err != nil {
+		t.Fatalf("list stale workloads failed: %v", err)
+	}
+	names := []string{}
+	for _, w := range workloads {
+		names = append(names, w.String())
+	}
+	if len(names) != 3 || names[0] != "Deployment/delivery" || names[1] != "Deployment/order" || names[2] != "StatefulSet/stock" {
+		t.Fatalf("unexpected stale workloads %v", names)
+	}
+	if workloads[1].StalePods != 2 {
+		t.Fatalf("order should have 2 stale pods, got %d", workloads[1].StalePods)
+	}
+
+	batches, err := splitBatches(workloads, u.flag.MaxUnavailable)
+	if err != nil {
+		t.Fatalf("split batches failed: %v", err)
+	}
+	err = u.upgrade(batches)
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+
+	for _, name := range []string{"delivery", "order"} {
+		deploy, _ := u.client.AppsV1().Deployments("shop").Get(context.TODO(), name, metav1.GetOptions{})
+		if deploy.Spec.Template.Annotations[restartedAtAnnotation] == "" {
+			t.Errorf("deployment %s should be restarted", name)
+		}
+	}
+	payment, _ := u.client.AppsV1().Deployments("shop").Get(context.TODO(), "payment", metav1.GetOptions{})
+	if payment.Spec.Template.Annotations[restartedAtAnnotation] != "" {
+		t.Errorf("deployment payment runs the injected sidecar, it shouldn't be restarted")
+	}
+}
+
+func TestUpgradeTimeout(t *testing.T) {
+	u := newTestUpgrader("1")
+
+	stuck := testDeployment("order", 2)
+	stuck.Status.AvailableReplicas = 1
+	_, err := u.client.AppsV1().Deployments("shop").UpdateStatus(context.TODO(), stuck, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("update status failed: %v", err)
+	}
+
+	workloads, _ := u.staleWorkloads()
+	batches, _ := splitBatches(workloads, u.flag.MaxUnavailable)
+	err = u.upgrade(batches)
+	if err == nil {
+		t.Fatalf("expected timeout of rolling out order")
+	}
+
+	// NOTE: The batch after the stuck one is never restarted.
+	sts, _ := u.client.AppsV1().StatefulSets("shop").Get(context.TODO(), "stock", metav1.GetOptions{})
+	if sts.Spec.Template.Annotations[restartedAtAnnotation] != "" {
+		t.Errorf("statefulset stock shouldn't be restarted after the stuck batch")
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const sidecarContainerName = "easemesh-sidecar"
+
+// Version is the pods running a sidecar image.
+type Version struct {
+	Version string `json:"version"`
+	Image   string `json:"image"`
+	// Injected reports whether the image is the one injected by the
+	// operator now, pods of other images are upgraded by restarting.
+	Injected bool `json:"injected"`
+	// Pods are in the form of namespace/name.
+	Pods []string `json:"pods"`
+}
+
+// RunVersions is the entrypoint of the emctl sidecar versions sub command
+func RunVersions(cmd *cobra.Command, flag *flags.SidecarVersions) {
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	image, err := injectedImage(client, flag.MeshNamespace)
+	if err != nil {
+		common.ExitWithError(err)
+	}
+
+	pods, err := listInjectedPods(client, flag.Namespace)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	versions := groupVersions(pods, image)
+
+	switch flag.OutputFormat {
+	case "table":
+		printVersions(os.Stdout, versions, image)
+	case "yaml":
+		buff, err := yaml.Marshal(versions)
+		if err != nil {
+			common.ExitWithErrorf("marshal versions failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal versions failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
+
+// injectedImage returns the sidecar image injected by the operator in the mesh namespace.
+func injectedImage(client kubernetes.Interface, meshNamespace string) (string, error) {
+	config, err := installbase.GetMeshOperatorConfig(client, meshNamespace)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", common.CodeErrorf(common.ExitCodeNotFound, "EaseMesh isn't installed in namespace %s", meshNamespace)
+		}
+		return "", common.WithCode(errors.Wrap(err, "get config of the operator"), common.ExitCodeUnreachable)
+	}
+	return config.InjectedSidecarImage(), nil
+}
+
+// listInjectedPods lists pods injected with sidecars in the namespace, empty means all namespaces.
+func listInjectedPods(client kubernetes.Interface, namespace string) ([]v1.Pod, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: installbase.SidecarInjectedLabelKey + "=true",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list injected pods")
+	}
+	return pods.Items, nil
+}
+
+// sidecarImage returns the image of the sidecar container of the pod.
+func sidecarImage(pod *v1.Pod) (string, bool) {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == sidecarContainerName {
+			return container.Image, true
+		}
+	}
+	return "", false
+}
+
+// imageVersion returns the tag of the image, or the short digest of it.
+func imageVersion(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		digest := strings.TrimPrefix(image[i+1:], "sha256:")
+		if len(digest) > 12 {
+			digest = digest[:12]
+		}
+		return digest
+	}
+
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
+
+// groupVersions groups pods by their sidecar images, the injected one goes first.
+func groupVersions(pods []v1.Pod, injected string) []*Version {
+	byImage := map[string]*Version{}
+	for i := range pods {
+		image, ok := sidecarImage(&pods[i])
+		if !ok {
+			continue
+		}
+		version, exists := byImage[image]
+		if !exists {
+			version = &Version{
+				Version:  imageVersion(image),
+				Image:    image,
+				Injected: image == injected,
+				Pods:     []string{},
+			}
+			byImage[image] = version
+		}
+		version.Pods = append(version.Pods, pods[i].Namespace+"/"+pods[i].Name)
+	}
+
+	versions := make([]*Version, 0, len(byImage))
+	for _, version := range byImage {
+		sort.Strings(version.Pods)
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Injected != versions[j].Injected {
+			return versions[i].Injected
+		}
+		return versions[i].Image < versions[j].Image
+	})
+	return versions
+}
+
+func printVersions(w io.Writer, versions []*Version, injected string) {
+	if len(versions) == 0 {
+		fmt.Fprintln(w, "No pod injected with sidecar found")
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Version", "Image", "Injected", "Pods"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	total, skewed := 0, 0
+	for _, version := range versions {
+		total += len(version.Pods)
+		injectedStr := "no"
+		if version.Injected {
+			injectedStr = "yes"
+		} else {
+			skewed += len(version.Pods)
+		}
+		table.Append([]string{version.Version, version.Image, injectedStr, strconv.Itoa(len(version.Pods))})
+	}
+
+	table.Render()
+
+	fmt.Fprintf(w, "\n%d of %d pods run sidecars other than %s\n", skewed, total, injected)
+}
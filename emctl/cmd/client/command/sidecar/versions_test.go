@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func injectedPod(namespace, name, image string, owner *metav1.OwnerReference) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{installbase.SidecarInjectedLabelKey: "true"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "app", Image: "shop/app:1.0"},
+				{Name: sidecarContainerName, Image: image},
+			},
+		},
+	}
+	if owner != nil {
+		controller := true
+		owner.Controller = &controller
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func TestImageVersion(t *testing.T) {
+	for image, version := range map[string]string{
+		"docker.io/megaease/easegress:easemesh":          "easemesh",
+		"localhost:5000/megaease/easegress":              "latest",
+		"megaease/easegress@sha256:0123456789abcdef0123": "0123456789ab",
+	} {
+		if got := imageVersion(image); got != version {
+			t.Errorf("version of %s: want %s, got %s", image, version, got)
+		}
+	}
+}
+
+func TestGroupVersions(t *testing.T) {
+	const injected = "docker.io/megaease/easegress:v2.0.0"
+	pods := []v1.Pod{
+		*injectedPod("shop", "order-1", "docker.io/megaease/easegress:v1.3.0", nil),
+		*injectedPod("shop", "order-0", "docker.io/megaease/easegress:v1.3.0", nil),
+		*injectedPod("pet", "pet-0", injected, nil),
+	}
+
+	versions := groupVersions(pods, injected)
+	if len(versions) != 2 || !versions[0].Injected || versions[1].Version != "v1.3.0" {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+	if !reflect.DeepEqual(versions[1].Pods, []string{"shop/order-0", "shop/order-1"}) {
+		t.Fatalf("unexpected pods %v", versions[1].Pods)
+	}
+
+	buff := &bytes.Buffer{}
+	printVersions(buff, versions, injected)
+	if !strings.Contains(buff.String(), "2 of 3 pods run sidecars other than "+injected) {
+		t.Fatalf("unexpected output:\n%s", buff.String())
+	}
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ObjectiveAvailability is the objective of successful requests.
+	ObjectiveAvailability = "availability"
+	// ObjectiveLatency is the objective of requests faster than the threshold.
+	ObjectiveLatency = "latency"
+)
+
+type (
+	// BurnRate is the rate consuming the error budget in a window of
+	// statistics, 1 means the budget is exhausted right at the end of
+	// the window of the SLO.
+	BurnRate struct {
+		Window string  `json:"window"`
+		Rate   float64 `json:"rate"`
+	}
+
+	// Status is the status of an objective of an SLO.
+	Status struct {
+		Name      string  `json:"name"`
+		Service   string  `json:"service"`
+		Route     string  `json:"route,omitempty"`
+		Objective string  `json:"objective"`
+		Target    float64 `json:"target"`
+		// Requests is the count of requests since sidecars started.
+		Requests float64 `json:"requests"`
+		// Current is the percentage of good requests since sidecars started.
+		Current   float64    `json:"current"`
+		BurnRates []BurnRate `json:"burnRates"`
+		// BudgetRemaining is the ratio of the error budget remaining,
+		// it's negative if the budget is overspent.
+		BudgetRemaining float64 `json:"budgetRemaining"`
+		// ExhaustedIn is the time left until the budget is exhausted at
+		// the burn rate of the longest window, empty means never.
+		ExhaustedIn string `json:"exhaustedIn,omitempty"`
+	}
+)
+
+// RunStatus is the entrypoint of the emctl slo status sub command
+func RunStatus(cmd *cobra.Command, flag *flags.SLOStatus) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	slos, err := listSLOs(meshclient.New(flag.Server), flag.Timeout, cmd.Flags().Args())
+	if err != nil {
+		if meshclient.IsNotFoundError(err) {
+			common.ExitWithError(common.WithCode(err, common.ExitCodeNotFound))
+		}
+		common.ExitWithErrorf("list SLOs failed: %w", err)
+	}
+	if len(slos) == 0 {
+		common.Infof("no SLO found, create SLOs by emctl apply")
+		return
+	}
+
+	objects, err := telemetry.New(flag.Server, flag.Timeout).Objects()
+	if err != nil {
+		common.ExitWithErrorf("get telemetry of sidecars failed: %w", err)
+	}
+
+	statuses := []*Status{}
+	for _, slo := range slos {
+		statuses = append(statuses, evaluate(slo, telemetry.IngressStats(objects, slo.Spec.Service, slo.Spec.Route))...)
+	}
+
+	switch flag.OutputFormat {
+	case "table":
+		printStatuses(os.Stdout, statuses)
+	case "yaml":
+		buff, err := yaml.Marshal(statuses)
+		if err != nil {
+			common.ExitWithErrorf("marshal statuses failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal statuses failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+}
+
+// listSLOs returns SLOs of the names, empty names means all.
+func listSLOs(meshClient meshclient.MeshClient, timeout time.Duration, names []string) ([]*resource.SLO, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	if len(names) == 0 {
+		slos, err := meshClient.V1Alpha1().SLO().List(ctx)
+		if meshclient.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return slos, err
+	}
+
+	slos := []*resource.SLO{}
+	for _, name := range names {
+		slo, err := meshClient.V1Alpha1().SLO().Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		slos = append(slos, slo)
+	}
+	return slos, nil
+}
+
+// evaluate returns statuses of objectives of the SLO against the
+// statistics reported by sidecars.
+func evaluate(slo *resource.SLO, stats []*telemetry.Stat) []*Status {
+	total := &telemetry.Stat{}
+	for _, s := range stats {
+		total.Add(s)
+	}
+
+	statuses := []*Status{}
+	newStatus := func(objective string, target float64) *Status {
+		return &Status{
+			Name:      slo.Name(),
+			Service:   slo.Spec.Service,
+			Route:     slo.Spec.Route,
+			Objective: objective,
+			Target:    target,
+			Requests:  total.Count,
+			Current:   100,
+			BurnRates: []BurnRate{},
+		}
+	}
+
+	if slo.Spec.Availability != nil {
+		status := newStatus(ObjectiveAvailability, slo.Spec.Availability.Target)
+		if total.Count > 0 {
+			status.Current = 100 * (1 - total.ErrCount/total.Count)
+		}
+		for _, w := range []struct {
+			window        string
+			rate, errRate float64
+		}{
+			{"1m", total.M1, total.M1Err},
+			{"5m", total.M5, total.M5Err},
+			{"15m", total.M15, total.M15Err},
+		} {
+			rate := 0.0
+			if w.rate > 0 {
+				rate = w.errRate / w.rate
+			}
+			status.BurnRates = append(status.BurnRates, BurnRate{Window: w.window, Rate: burnRate(rate, status.Target)})
+		}
+		status.settle(burnRate(1-status.Current/100, status.Target), slo.Spec.WindowDuration())
+		statuses = append(statuses, status)
+	}
+
+	if slo.Spec.Latency != nil {
+		status := newStatus(ObjectiveLatency, slo.Spec.Latency.Target)
+		threshold, _ := slo.Spec.Latency.ThresholdDuration()
+		if total.Count > 0 {
+			fast := 0.0
+			for _, s := range stats {
+				fast += s.Count * s.FastRatio(threshold)
+			}
+			status.Current = 100 * fast / total.Count
+		}
+		// NOTE: Sidecars only report percentiles of recent requests.
+		status.BurnRates = append(status.BurnRates, BurnRate{Window: "recent", Rate: burnRate(1-status.Current/100, status.Target)})
+		status.settle(burnRate(1-status.Current/100, status.Target), slo.Spec.WindowDuration())
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// burnRate returns the ratio of bad requests against the ratio allowed
+// by the target percentage.
+func burnRate(bad, target float64) float64 {
+	return bad / (1 - target/100)
+}
+
+// settle calculates the remaining budget by the overall burn rate, and
+// the time left at the burn rate of the longest window.
+func (s *Status) settle(overall float64, window time.Duration) {
+	s.BudgetRemaining = 1 - overall
+	if s.Requests == 0 {
+		return
+	}
+
+	rate := s.BurnRates[len(s.BurnRates)-1].Rate
+	switch {
+	case s.BudgetRemaining <= 0:
+		s.ExhaustedIn = "0s"
+	case rate > 0:
+		s.ExhaustedIn = humanDuration(time.Duration(s.BudgetRemaining / rate * float64(window)))
+	}
+}
+
+func humanDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return d.String()
+	}
+}
+
+func printStatuses(w io.Writer, statuses []*Status) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Name", "Service", "Route", "Objective", "Target", "Current", "Burn Rate", "Budget Remaining", "Exhausted In"})
+	table.SetBorder(false)
+	for _, s := range statuses {
+		route, current, exhaustedIn := s.Route, "-", s.ExhaustedIn
+		if route == "" {
+			route = "*"
+		}
+		if s.Requests > 0 {
+			current = fmt.Sprintf("%.3f%%", s.Current)
+		}
+		if exhaustedIn == "" {
+			exhaustedIn = "-"
+		}
+		rates := []string{}
+		for _, r := range s.BurnRates {
+			rates = append(rates, fmt.Sprintf("%s=%.2f", r.Window, r.Rate))
+		}
+		table.Append([]string{
+			s.Name,
+			s.Service,
+			route,
+			s.Objective,
+			fmt.Sprintf("%g%%", s.Target),
+			current,
+			strings.Join(rates, " "),
+			fmt.Sprintf("%.1f%%", 100*s.BudgetRemaining),
+			exhaustedIn,
+		})
+	}
+	table.Render()
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package slo
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/telemetry"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"sigs.k8s.io/yaml"
+)
+
+const testObjects = `
+easemesh-controller:
+  order-service-6b7d9:
+    sidecar-ingress-order-service:
+      health: ready
+      count: 10000
+      errCount: 5
+      m1: 10
+      m1Err: 0.1
+      m5: 10
+      m5Err: 0.02
+      m15: 10
+      m15Err: 0.005
+      min: 2
+      p25: 10
+      p50: 20
+      p75: 50
+      p95: 200
+      p98: 300
+      p99: 400
+      p999: 900
+      max: 1200
+      topN:
+      - path: /api/orders/1
+        count: 600
+        errCount: 6
+      - path: /api/orders/2
+        count: 400
+        errCount: 0
+      - path: /health
+        count: 9000
+        errCount: 0
+  order-service-8c4f2:
+    sidecar-ingress-order-service:
+      count: 10000
+      errCount: 5
+      m1: 10
+      m1Err: 0.1
+      m5: 10
+      m5Err: 0.02
+      m15: 10
+      m15Err: 0.005
+  pet-service-5f8c4:
+    sidecar-ingress-pet-service:
+      count: 100
+`
+
+func testStats(t *testing.T, service, route string) []*telemetry.Stat {
+	objects := map[string]map[string]interface{}{}
+	err := yaml.Unmarshal([]byte(testObjects), &objects)
+	if err != nil {
+		t.Fatalf("unmarshal objects failed: %v", err)
+	}
+	return telemetry.IngressStats(objects, service, route)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestEvaluate(t *testing.T) {
+	slo := &resource.SLO{
+		MeshResource: resource.NewSLOResource(resource.DefaultAPIVersion, "order"),
+		Spec: &resource.SLOSpec{
+			Service:      "order-service",
+			Window:       "720h",
+			Availability: &resource.SLOAvailability{Target: 99.9},
+		},
+	}
+
+	statuses := evaluate(slo, testStats(t, "order-service", ""))
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 status, got %d", len(statuses))
+	}
+	s := statuses[0]
+	if !near(s.Current, 99.95) || !near(s.BudgetRemaining, 0.5) {
+		t.Fatalf("unexpected current %f or budget remaining %f", s.Current, s.BudgetRemaining)
+	}
+	if len(s.BurnRates) != 3 || !near(s.BurnRates[0].Rate, 10) || !near(s.BurnRates[2].Rate, 0.5) {
+		t.Fatalf("unexpected burn rates %+v", s.BurnRates)
+	}
+	if s.ExhaustedIn != "30d" {
+		t.Fatalf("expected budget exhausted in 30d, got %s", s.ExhaustedIn)
+	}
+
+	slo.Spec.Route = "/api/orders"
+	slo.Spec.Latency = &resource.SLOLatency{Threshold: "300ms", Target: 99}
+	statuses = evaluate(slo, testStats(t, "order-service", slo.Spec.Route))
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(statuses))
+	}
+	if !near(statuses[0].Current, 99.4) || statuses[0].BudgetRemaining >= 0 || statuses[0].ExhaustedIn != "0s" {
+		t.Fatalf("expected budget of route overspent, got %+v", statuses[0])
+	}
+
+	slo.Spec.Service = "unknown-service"
+	statuses = evaluate(slo, testStats(t, slo.Spec.Service, ""))
+	if statuses[0].Requests != 0 || statuses[0].BudgetRemaining != 1 || statuses[0].ExhaustedIn != "" {
+		t.Fatalf("expected full budget without requests, got %+v", statuses[0])
+	}
+
+	buff := &bytes.Buffer{}
+	printStatuses(buff, statuses)
+	if !strings.Contains(buff.String(), "unknown-service") {
+		t.Fatalf("expected unknown-service in table:\n%s", buff.String())
+	}
+}
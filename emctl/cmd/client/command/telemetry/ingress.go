@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"strings"
+	"time"
+)
+
+// ingressServerPrefix prefixes the ingress HTTP servers of sidecars, whose
+// statistics are of requests to the service, and of the top paths.
+const ingressServerPrefix = "sidecar-ingress-"
+
+type (
+	// Stat is the statistics of requests reported by a sidecar.
+	Stat struct {
+		Count    float64
+		ErrCount float64
+		// Rates of requests and failed requests per second, which are
+		// moving averages of 1, 5 and 15 minutes.
+		M1, M1Err   float64
+		M5, M5Err   float64
+		M15, M15Err float64
+
+		// latencies are in milliseconds at percentiles in ascending order.
+		latencies []percentile
+	}
+
+	percentile struct {
+		percent float64
+		latency float64
+	}
+)
+
+// IngressStats returns statistics of requests to the service reported by
+// every sidecar, or statistics of the paths with the prefix of the route.
+func IngressStats(objects map[string]map[string]interface{}, service, route string) []*Stat {
+	name := ingressServerPrefix + service
+	stats := []*Stat{}
+
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for k, v := range value {
+				if server, ok := v.(map[string]interface{}); ok && k == name {
+					stats = append(stats, serverStats(server, route)...)
+					continue
+				}
+				walk(v)
+			}
+		case []interface{}:
+			for _, v := range value {
+				walk(v)
+			}
+		}
+	}
+	for _, members := range objects {
+		for _, status := range members {
+			walk(status)
+		}
+	}
+
+	return stats
+}
+
+// serverStats returns statistics of the HTTP server, sidecars only report
+// statistics of the top paths, so the statistics of a route may be partial.
+func serverStats(server map[string]interface{}, route string) []*Stat {
+	if route == "" {
+		return []*Stat{toStat(server)}
+	}
+
+	stats := []*Stat{}
+	items, _ := server["topN"].([]interface{})
+	for _, item := range items {
+		item, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if path, _ := item["path"].(string); strings.HasPrefix(path, route) {
+			stats = append(stats, toStat(item))
+		}
+	}
+	return stats
+}
+
+func toStat(m map[string]interface{}) *Stat {
+	number := func(key string) float64 {
+		n, _ := m[key].(float64)
+		return n
+	}
+
+	s := &Stat{
+		Count:    number("count"),
+		ErrCount: number("errCount"),
+		M1:       number("m1"),
+		M1Err:    number("m1Err"),
+		M5:       number("m5"),
+		M5Err:    number("m5Err"),
+		M15:      number("m15"),
+		M15Err:   number("m15Err"),
+	}
+	for _, p := range []struct {
+		key     string
+		percent float64
+	}{
+		{"min", 0}, {"p25", 25}, {"p50", 50}, {"p75", 75}, {"p95", 95},
+		{"p98", 98}, {"p99", 99}, {"p999", 99.9}, {"max", 100},
+	} {
+		if _, ok := m[p.key]; ok {
+			s.latencies = append(s.latencies, percentile{percent: p.percent, latency: number(p.key)})
+		}
+	}
+	return s
+}
+
+// Add accumulates the counts and rates of other statistics, latencies
+// could not be accumulated and are left as is.
+func (s *Stat) Add(other *Stat) {
+	s.Count += other.Count
+	s.ErrCount += other.ErrCount
+	s.M1 += other.M1
+	s.M1Err += other.M1Err
+	s.M5 += other.M5
+	s.M5Err += other.M5Err
+	s.M15 += other.M15
+	s.M15Err += other.M15Err
+}
+
+// FastRatio estimates the ratio of requests faster than the threshold,
+// by interpolating between the reported percentiles.
+func (s *Stat) FastRatio(threshold time.Duration) float64 {
+	if len(s.latencies) == 0 {
+		return 1
+	}
+
+	ms := float64(threshold) / float64(time.Millisecond)
+	if ms < s.latencies[0].latency {
+		return s.latencies[0].percent / 100
+	}
+	for i := 1; i < len(s.latencies); i++ {
+		prev, next := s.latencies[i-1], s.latencies[i]
+		if ms < next.latency {
+			percent := prev.percent + (next.percent-prev.percent)*(ms-prev.latency)/(next.latency-prev.latency)
+			return percent / 100
+		}
+	}
+	return s.latencies[len(s.latencies)-1].percent / 100
+}
+
+// Latency returns the latency in milliseconds at the percentile, false
+// if the sidecar doesn't report it.
+func (s *Stat) Latency(percent float64) (float64, bool) {
+	for _, p := range s.latencies {
+		if p.percent == percent {
+			return p.latency, true
+		}
+	}
+	return 0, false
+}
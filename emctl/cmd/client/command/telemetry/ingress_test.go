@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const testObjects = `
+easemesh-controller:
+  order-service-6b7d9:
+    sidecar-ingress-order-service:
+      health: ready
+      count: 10000
+      errCount: 5
+      m1: 10
+      m1Err: 0.1
+      m5: 10
+      m5Err: 0.02
+      m15: 10
+      m15Err: 0.005
+      min: 2
+      p25: 10
+      p50: 20
+      p75: 50
+      p95: 200
+      p98: 300
+      p99: 400
+      p999: 900
+      max: 1200
+      topN:
+      - path: /api/orders/1
+        count: 600
+        errCount: 6
+      - path: /api/orders/2
+        count: 400
+        errCount: 0
+      - path: /health
+        count: 9000
+        errCount: 0
+  order-service-8c4f2:
+    sidecar-ingress-order-service:
+      count: 10000
+      errCount: 5
+      m1: 10
+      m1Err: 0.1
+      m5: 10
+      m5Err: 0.02
+      m15: 10
+      m15Err: 0.005
+  pet-service-5f8c4:
+    sidecar-ingress-pet-service:
+      count: 100
+`
+
+func testIngressStats(t *testing.T, service, route string) []*Stat {
+	objects := map[string]map[string]interface{}{}
+	err := yaml.Unmarshal([]byte(testObjects), &objects)
+	if err != nil {
+		t.Fatalf("unmarshal objects failed: %v", err)
+	}
+	return IngressStats(objects, service, route)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestIngressStats(t *testing.T) {
+	stats := testIngressStats(t, "order-service", "")
+	if len(stats) != 2 || stats[0].Count+stats[1].Count != 20000 {
+		t.Fatalf("expected statistics of 2 sidecars, got %+v", stats)
+	}
+
+	stats = testIngressStats(t, "order-service", "/api/orders")
+	if len(stats) != 2 {
+		t.Fatalf("expected statistics of 2 paths, got %d", len(stats))
+	}
+
+	stats = testIngressStats(t, "order-service", "")
+	for _, s := range stats {
+		if len(s.latencies) == 0 {
+			continue
+		}
+		if !near(s.FastRatio(300*time.Millisecond), 0.98) {
+			t.Fatalf("expected 98%% faster than 300ms, got %f", s.FastRatio(300*time.Millisecond))
+		}
+		if !near(s.FastRatio(350*time.Millisecond), 0.985) {
+			t.Fatalf("expected 98.5%% faster than 350ms, got %f", s.FastRatio(350*time.Millisecond))
+		}
+		if s.FastRatio(time.Millisecond) != 0 || s.FastRatio(2*time.Second) != 1 {
+			t.Fatalf("unexpected ratio out of range of latencies")
+		}
+		if latency, ok := s.Latency(99); !ok || latency != 400 {
+			t.Fatalf("expected p99 latency 400ms, got %f", latency)
+		}
+		if _, ok := s.Latency(90); ok {
+			t.Fatalf("expected no p90 latency")
+		}
+	}
+}
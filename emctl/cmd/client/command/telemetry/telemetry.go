@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// statusObjectsPath is the path of the Easegress admin API returning
+// statuses of objects keyed by object name and member name, sidecars
+// report theirs to the control plane as members of the cluster.
+// NOTE: The response is YAML or JSON depending on the version of Easegress.
+const statusObjectsPath = "/apis/v1/status/objects"
+
+type (
+	// Client gets the statistics reported by sidecars from the control plane.
+	Client interface {
+		// Objects returns statuses of objects keyed by object name and member name.
+		Objects() (map[string]map[string]interface{}, error)
+	}
+
+	httpClient struct {
+		url     string
+		timeout time.Duration
+	}
+)
+
+// New returns a Client requesting the admin API of the control plane.
+func New(server string, timeout time.Duration) Client {
+	return &httpClient{
+		url:     "http://" + strings.TrimPrefix(server, "http://") + statusObjectsPath,
+		timeout: timeout,
+	}
+}
+
+func (c *httpClient) Objects() (map[string]map[string]interface{}, error) {
+	result, err := client.NewHTTPJSON().
+		Get(c.url, nil, c.timeout, nil).
+		HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
+			if statusCode != http.StatusOK {
+				return nil, errors.Errorf("get status of objects failed, status code: %d, body: %s", statusCode, body)
+			}
+			objects := map[string]map[string]interface{}{}
+			err := yaml.Unmarshal(body, &objects)
+			if err != nil {
+				return nil, errors.Wrap(err, "unmarshal status of objects")
+			}
+			return objects, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]map[string]interface{}), nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RunPolicySet is the entrypoint of the emctl tenant policy set sub command
+func RunPolicySet(cmd *cobra.Command, flag *flags.TenantPolicySet) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	tenantName := tenantNameFromArgs(cmd)
+	if flag.YamlFile == "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "no policy file specified")
+	}
+
+	buff, err := ioutil.ReadFile(flag.YamlFile)
+	if err != nil {
+		common.ExitWithErrorf("read policy file %s failed: %w", flag.YamlFile, err)
+	}
+
+	spec := &resource.TenantPolicySpec{}
+	err = yamljsontool.Unmarshal(buff, spec)
+	if err != nil {
+		common.ExitWithCodef(common.ExitCodeValidation, "unmarshal policy file %s failed: %w", flag.YamlFile, err)
+	}
+
+	client := meshclient.New(flag.Server)
+	err = SetPolicy(client, tenantName, spec, flag.Timeout)
+	if err != nil {
+		common.ExitWithErrorf("set policy of tenant %s failed: %w", tenantName, err)
+	}
+
+	common.Logger().With("kind", resource.KindTenantPolicy, "name", tenantName).
+		Infof("%s/%s applied successfully", resource.KindTenantPolicy, tenantName)
+
+	err = printPrecedence(client, tenantName, spec, flag)
+	if err != nil {
+		common.OutputErrorf("describe policy precedence failed: %v", err)
+	}
+}
+
+// RunPolicyGet is the entrypoint of the emctl tenant policy get sub command
+func RunPolicyGet(cmd *cobra.Command, flag *flags.TenantPolicyGet) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	switch flag.OutputFormat {
+	case "table", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support table, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	tenantName := tenantNameFromArgs(cmd)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	policy, err := meshclient.New(flag.Server).V1Alpha1().TenantPolicy().Get(ctx, tenantName)
+	if err != nil {
+		common.ExitWithErrorf("get policy of tenant %s failed: %w", tenantName, err)
+	}
+
+	printer.New(flag.OutputFormat).PrintObjects([]meta.MeshObject{policy})
+}
+
+// SetPolicy validates the tenant and applies its default policies.
+func SetPolicy(client meshclient.MeshClient, tenantName string, spec *resource.TenantPolicySpec, timeout time.Duration) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	_, err := client.V1Alpha1().Tenant().Get(ctx, tenantName)
+	if err != nil {
+		return errors.Wrapf(err, "get tenant %s", tenantName)
+	}
+
+	policy := &resource.TenantPolicy{
+		MeshResource: resource.NewTenantPolicyResource(resource.DefaultAPIVersion, tenantName),
+		Spec:         spec,
+	}
+
+	return apply.WrapApplierByMeshObject(policy, client, timeout).Apply()
+}
+
+// printPrecedence prints which policies every service of the tenant inherits,
+// and which ones are overridden by the service itself.
+func printPrecedence(client meshclient.MeshClient, tenantName string,
+	spec *resource.TenantPolicySpec, flag *flags.TenantPolicySet) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+
+	tenant, err := client.V1Alpha1().Tenant().Get(ctx, tenantName)
+	if err != nil {
+		return errors.Wrapf(err, "get tenant %s", tenantName)
+	}
+
+	policyNames := spec.PolicyNames()
+	for _, serviceName := range tenant.Spec.Services {
+		service, err := client.V1Alpha1().Service().Get(ctx, serviceName)
+		if err != nil {
+			return errors.Wrapf(err, "get service %s", serviceName)
+		}
+
+		inherited := spec.Inherit(service.Spec)
+		overridden := []string{}
+		for _, name := range policyNames {
+			if !contains(inherited, name) {
+				overridden = append(overridden, name)
+			}
+		}
+
+		fmt.Printf("  service %s inherits [%s], overrides [%s]\n", serviceName,
+			strings.Join(inherited, ","), strings.Join(overridden, ","))
+	}
+
+	return nil
+}
+
+func tenantNameFromArgs(cmd *cobra.Command) string {
+	args := cmd.Flags().Args()
+	if len(args) != 1 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <tenant name>")
+	}
+	return args[0]
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
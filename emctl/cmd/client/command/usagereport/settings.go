@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usagereport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+// reportedFields are shown to users before they opt in.
+const reportedFields = "command name, names of set flags, duration, success or failure, exit code, emctl version, os and arch"
+
+// RunOn opts in reporting usage to the endpoint.
+func RunOn(cmd *cobra.Command, flag *flags.TelemetryOn) {
+	err := validateEndpoint(flag.Endpoint)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	rc := loadRCFile()
+	if rc.Telemetry == nil {
+		rc.Telemetry = &rcfile.Telemetry{}
+	}
+	rc.Telemetry.Enabled = true
+	rc.Telemetry.Endpoint = flag.Endpoint
+	if rc.Telemetry.ClientID == "" {
+		rc.Telemetry.ClientID, err = newClientID()
+		if err != nil {
+			common.ExitWithErrorf("generate client id failed: %v", err)
+		}
+	}
+
+	err = rc.Marshal()
+	if err != nil {
+		common.ExitWithErrorf("save telemetry settings failed: %v", err)
+	}
+
+	fmt.Printf("telemetry is on, reporting %s to %s\n", reportedFields, flag.Endpoint)
+	if env := DisabledByEnv(); env != "" {
+		common.Warnf("telemetry is still disabled by the env %s", env)
+	}
+}
+
+// RunOff opts out reporting usage, the endpoint and the client id are kept.
+func RunOff(cmd *cobra.Command) {
+	rc := loadRCFile()
+	if rc.Telemetry == nil || !rc.Telemetry.Enabled {
+		fmt.Println("telemetry is off")
+		return
+	}
+
+	rc.Telemetry.Enabled = false
+	err := rc.Marshal()
+	if err != nil {
+		common.ExitWithErrorf("save telemetry settings failed: %v", err)
+	}
+	fmt.Println("telemetry is off")
+}
+
+// RunStatus outputs whether usage is reported, and where it's reported to.
+func RunStatus(cmd *cobra.Command) {
+	rc := loadRCFile()
+	settings := rc.Telemetry
+	if settings == nil {
+		settings = &rcfile.Telemetry{}
+	}
+
+	status := "off"
+	if settings.Enabled {
+		status = "on"
+	}
+	if env := DisabledByEnv(); env != "" {
+		status = fmt.Sprintf("off (disabled by the env %s)", env)
+	}
+
+	fmt.Printf("Status:    %s\n", status)
+	if settings.Endpoint != "" {
+		fmt.Printf("Endpoint:  %s\n", settings.Endpoint)
+	}
+	if settings.ClientID != "" {
+		fmt.Printf("Client ID: %s\n", settings.ClientID)
+	}
+	fmt.Printf("Reported:  %s\n", reportedFields)
+}
+
+// loadRCFile loads the rc file, which may not exist.
+func loadRCFile() *rcfile.RCFile {
+	rc, err := rcfile.New()
+	if err != nil {
+		common.ExitWithErrorf("new rcfile failed: %v", err)
+	}
+	_ = rc.Unmarshal()
+	return rc
+}
+
+func validateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return fmt.Errorf("--endpoint is required")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("--endpoint must be an HTTP(S) URL, got %q", endpoint)
+	}
+	return nil
+}
+
+func newClientID() (string, error) {
+	buff := make([]byte, 16)
+	_, err := rand.Read(buff)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buff), nil
+}
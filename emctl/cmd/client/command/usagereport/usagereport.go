@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package usagereport reports anonymous usage of emctl to a collector once
+// the user opts in, so maintainers and platform teams know which commands
+// are used and how they work out.
+package usagereport
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/pkg/version"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// EnvKillSwitch disables reporting whatever the rc file says, once it's
+	// off, false or 0.
+	EnvKillSwitch = "EMCTL_TELEMETRY"
+	// envDoNotTrack is the convention of console tools to disable tracking.
+	envDoNotTrack = "DO_NOT_TRACK"
+
+	telemetryCommand = "telemetry"
+	sendTimeout      = 2 * time.Second
+)
+
+// Event is the usage of a command reported to the collector. It's redacted
+// strictly: it never contains arguments, flag values, error messages, or
+// anything identifying the user, the host or the cluster.
+type Event struct {
+	ClientID string `json:"clientID"`
+	// Command is the path of the sub command without the root, e.g. get.
+	Command string `json:"command"`
+	// Flags are names of flags set in the command line, without values.
+	Flags      []string `json:"flags,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+	Success    bool     `json:"success"`
+	ExitCode   int      `json:"exitCode"`
+	Version    string   `json:"version"`
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+}
+
+// DisabledByEnv returns the env disabling reporting, or empty if none does.
+func DisabledByEnv() string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvKillSwitch))) {
+	case "off", "false", "0":
+		return EnvKillSwitch
+	}
+	if value := strings.TrimSpace(os.Getenv(envDoNotTrack)); value != "" && value != "0" {
+		return envDoNotTrack
+	}
+	return ""
+}
+
+// Start reports the usage of the command when emctl exits, if the user opts in
+// and reporting is not disabled by envs. Reporting never fails the command.
+func Start(cmd *cobra.Command) {
+	if DisabledByEnv() != "" || isTelemetryCommand(cmd) {
+		return
+	}
+
+	rc, err := rcfile.New()
+	if err != nil || rc.Unmarshal() != nil {
+		return
+	}
+	settings := rc.Telemetry
+	if settings == nil || !settings.Enabled || settings.Endpoint == "" {
+		return
+	}
+
+	start := time.Now()
+	event := NewEvent(cmd, settings.ClientID)
+	common.OnExit(func(code int) {
+		event.DurationMS = time.Since(start).Milliseconds()
+		event.Success = code == common.ExitCodeOK
+		event.ExitCode = code
+
+		err := Send(settings.Endpoint, event)
+		if err != nil {
+			common.Debugf("ignored: report usage failed: %v", err)
+		}
+	})
+}
+
+// NewEvent creates the event of the command, only names defined by emctl
+// itself are taken from the command line.
+func NewEvent(cmd *cobra.Command, clientID string) *Event {
+	return &Event{
+		ClientID: clientID,
+		Command:  commandPath(cmd),
+		Flags:    changedFlags(cmd),
+		Version:  version.RELEASE,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+	}
+}
+
+// Send posts the event to the endpoint. It uses its own HTTP client, so the
+// access token of the control plane is never sent along.
+func Send(endpoint string, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal usage event failed")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "new request to %s failed", endpoint)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "emctl/"+version.RELEASE)
+
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "post usage event to %s failed", endpoint)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("post usage event to %s failed: status code %d", endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// commandPath returns names of the command and its parents except the root,
+// which is emctl or mesh of kubectl mesh.
+func commandPath(cmd *cobra.Command) string {
+	names := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		names = append([]string{c.Name()}, names...)
+	}
+	return strings.Join(names, " ")
+}
+
+func changedFlags(cmd *cobra.Command) []string {
+	names := []string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		names = append(names, f.Name)
+	})
+	sort.Strings(names)
+	return names
+}
+
+func isTelemetryCommand(cmd *cobra.Command) bool {
+	path := commandPath(cmd)
+	return path == telemetryCommand || strings.HasPrefix(path, telemetryCommand+" ")
+}
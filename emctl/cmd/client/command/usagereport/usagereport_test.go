@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usagereport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNewEvent(t *testing.T) {
+	var event *Event
+	root := &cobra.Command{Use: "emctl"}
+	get := &cobra.Command{
+		Use: "get",
+		Run: func(cmd *cobra.Command, args []string) {
+			event = NewEvent(cmd, "client-001")
+		},
+	}
+	get.Flags().StringP("output", "o", "table", "")
+	get.Flags().StringP("server", "s", "", "")
+	get.Flags().StringP("selector", "l", "", "")
+	root.AddCommand(get)
+
+	root.SetArgs([]string{"get", "service", "secret-service", "-o", "yaml", "--server", "10.0.0.1:2381"})
+	err := root.Execute()
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	if event.Command != "get" || event.ClientID != "client-001" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if !reflect.DeepEqual(event.Flags, []string{"output", "server"}) {
+		t.Fatalf("expect flags [output server], got %v", event.Flags)
+	}
+
+	buff, _ := json.Marshal(event)
+	for _, secret := range []string{"service", "secret-service", "yaml", "10.0.0.1"} {
+		if strings.Contains(string(buff), secret) {
+			t.Fatalf("event %s leaks %q", buff, secret)
+		}
+	}
+}
+
+func TestDisabledByEnv(t *testing.T) {
+	defer os.Unsetenv(EnvKillSwitch)
+	defer os.Unsetenv(envDoNotTrack)
+
+	for _, c := range []struct {
+		killSwitch string
+		doNotTrack string
+		expect     string
+	}{
+		{"", "", ""},
+		{"on", "", ""},
+		{"OFF", "", EnvKillSwitch},
+		{"0", "", EnvKillSwitch},
+		{"", "1", envDoNotTrack},
+		{"", "0", ""},
+	} {
+		os.Setenv(EnvKillSwitch, c.killSwitch)
+		os.Setenv(envDoNotTrack, c.doNotTrack)
+		if got := DisabledByEnv(); got != c.expect {
+			t.Fatalf("%s=%q %s=%q: expect %q, got %q",
+				EnvKillSwitch, c.killSwitch, envDoNotTrack, c.doNotTrack, c.expect, got)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	received := &Event{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected authorization header")
+		}
+		json.NewDecoder(r.Body).Decode(received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	event := &Event{ClientID: "client-001", Command: "apply", Success: true, DurationMS: 120}
+	err := Send(server.URL, event)
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if !reflect.DeepEqual(received, event) {
+		t.Fatalf("expect %+v, got %+v", event, received)
+	}
+
+	err = Send(server.URL+"/%zz", event)
+	if err == nil {
+		t.Fatalf("send to an invalid endpoint should fail")
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	for endpoint, valid := range map[string]bool{
+		"https://telemetry.example.com/emctl": true,
+		"http://10.0.0.1:8080":                true,
+		"":                                    false,
+		"telemetry.example.com":               false,
+		"ftp://telemetry.example.com":         false,
+	} {
+		if err := validateEndpoint(endpoint); (err == nil) != valid {
+			t.Fatalf("endpoint %q: expect valid %v, got error %v", endpoint, valid, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verify
+
+import (
+	"bytes"
+	_ "embed" // for embedding the mesh resources of sample apps
+	"fmt"
+	"strconv"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+
+	appsV1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// annotationPrefix is the prefix of annotations instructing
+	// the mesh operator to inject sidecars.
+	annotationPrefix = "mesh.megaease.com/"
+
+	namespaceLabelKey = annotationPrefix + "mesh-service"
+
+	annotationServiceNameKey     = annotationPrefix + "service-name"
+	annotationApplicationPortKey = annotationPrefix + "application-port"
+	annotationAliveProbeURLKey   = annotationPrefix + "alive-probe-url"
+
+	sidecarContainerName = "easemesh-sidecar"
+
+	sampleAppLabelKey = "app"
+	sampleAppPort     = 80
+
+	// sampleIngressPathPrefix is the path prefix routed to sample apps
+	// by the mesh ingress, see sample/mesh.yaml.
+	sampleIngressPathPrefix = "/emctl-verify/"
+)
+
+// sampleApps are names of the sample app pair, which are the names
+// of their mesh services as well.
+var sampleApps = []string{"emctl-verify-front", "emctl-verify-back"}
+
+//go:embed sample/mesh.yaml
+var sampleMeshResources []byte
+
+// sampleMeshObjects returns the mesh resources of sample apps in the order
+// of creating, the resources which others depend on come first.
+func sampleMeshObjects() ([]meta.MeshObject, error) {
+	var objects []meta.MeshObject
+	err := util.NewReaderVisitor(bytes.NewReader(sampleMeshResources), "sample/mesh.yaml").
+		Visit(func(object meta.MeshObject, err error) error {
+			if err != nil {
+				return err
+			}
+			objects = append(objects, object)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// sampleIngressPath returns the path routed to the app by the mesh ingress.
+func sampleIngressPath(app string) string {
+	return sampleIngressPathPrefix + app[len("emctl-verify-"):]
+}
+
+func sampleNamespace(name string) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				namespaceLabelKey: "true",
+			},
+		},
+	}
+}
+
+func sampleDeployment(app, namespace, image string) *appsV1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{sampleAppLabelKey: app}
+
+	return &appsV1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      app,
+			Namespace: namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				annotationServiceNameKey:     app,
+				annotationApplicationPortKey: strconv.Itoa(sampleAppPort),
+				annotationAliveProbeURLKey:   fmt.Sprintf("http://localhost:%d/", sampleAppPort),
+			},
+		},
+		Spec: appsV1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  app,
+							Image: image,
+							Env: []v1.EnvVar{
+								{
+									Name:  "PORT",
+									Value: strconv.Itoa(sampleAppPort),
+								},
+							},
+							Ports: []v1.ContainerPort{
+								{
+									ContainerPort: sampleAppPort,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,430 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/delete"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// pollInterval is the interval of polling the status of sample apps.
+	pollInterval = 2 * time.Second
+
+	// traceIDHeader is the B3 header carrying the trace id, sidecars
+	// propagate it to apps when tracing is enabled.
+	traceIDHeader = "x-b3-traceid"
+
+	// statusObjectsPath is the path of the Easegress admin API
+	// reporting statistics of all objects.
+	statusObjectsPath = "/apis/v1/status/objects"
+)
+
+func (v *verifier) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), v.flag.Timeout)
+}
+
+func (v *verifier) createNamespace() error {
+	ctx, cancelFunc := v.requestContext()
+	defer cancelFunc()
+
+	_, err := v.client.CoreV1().Namespaces().Create(ctx, sampleNamespace(v.flag.Namespace), metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		return common.CodeErrorf(common.ExitCodeConflict,
+			"namespace %s already exists, delete it or specify another one by --namespace", v.flag.Namespace)
+	}
+	return err
+}
+
+func (v *verifier) applyMeshResources() error {
+	objects, err := sampleMeshObjects()
+	if err != nil {
+		return errors.Wrap(err, "decode mesh resources of sample apps")
+	}
+
+	for _, object := range objects {
+		err := apply.WrapApplierByMeshObject(object, v.meshClient, v.flag.Timeout).Apply()
+		if err != nil {
+			return errors.Wrapf(err, "apply %s/%s", object.Kind(), object.Name())
+		}
+	}
+	return nil
+}
+
+func (v *verifier) deploySampleApps() error {
+	for _, app := range sampleApps {
+		deployment := sampleDeployment(app, v.flag.Namespace, v.flag.Image)
+		err := installbase.DeployDeployment(deployment, v.client, v.flag.Namespace)
+		if err != nil {
+			return errors.Wrapf(err, "deploy %s", app)
+		}
+	}
+	return nil
+}
+
+// waitSidecarsInjected waits for pods of all sample apps are ready,
+// with sidecars injected by the mesh operator.
+func (v *verifier) waitSidecarsInjected() error {
+	return poll(v.flag.WaitTimeout, func() error {
+		for _, app := range sampleApps {
+			err := v.checkSidecarInjected(app)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (v *verifier) checkSidecarInjected(app string) error {
+	ctx, cancelFunc := v.requestContext()
+	defer cancelFunc()
+
+	pods, err := v.client.CoreV1().Pods(v.flag.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", sampleAppLabelKey, app),
+	})
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		return errors.Errorf("no pod of %s", app)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !hasContainer(pod, sidecarContainerName) {
+			return errors.Errorf("no sidecar injected into pod %s, is the mesh operator running?", pod.Name)
+		}
+		if !podReady(pod) {
+			return errors.Errorf("pod %s is not ready", pod.Name)
+		}
+	}
+	return nil
+}
+
+func hasContainer(pod *v1.Pod, name string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// waitServiceInstances waits for sidecars of all sample apps
+// registering their service instances as UP.
+func (v *verifier) waitServiceInstances() error {
+	return poll(v.flag.WaitTimeout, func() error {
+		ctx, cancelFunc := v.requestContext()
+		defer cancelFunc()
+
+		instances, err := v.meshClient.V1Alpha1().ServiceInstance().List(ctx)
+		if err != nil {
+			return err
+		}
+
+		up := map[string]bool{}
+		for _, instance := range instances {
+			if instance.Spec != nil && instance.Spec.Status == "UP" {
+				up[instance.Spec.ServiceName] = true
+			}
+		}
+
+		for _, app := range sampleApps {
+			if !up[app] {
+				return errors.Errorf("no service instance of %s is UP", app)
+			}
+		}
+		return nil
+	})
+}
+
+// requestThroughIngress requests every sample app through the mesh ingress,
+// until all of them respond successfully.
+func (v *verifier) requestThroughIngress() error {
+	address := v.flag.IngressAddress
+	if address == "" {
+		var err error
+		address, err = v.ingressAddress()
+		if err != nil {
+			return errors.Wrap(err, "discover address of the mesh ingress, specify it by --ingress-address")
+		}
+	}
+
+	client := &http.Client{Timeout: v.flag.Timeout}
+	return poll(v.flag.WaitTimeout, func() error {
+		for _, app := range sampleApps {
+			if v.responses[app] != nil {
+				continue
+			}
+
+			url := strings.TrimSuffix(address, "/") + sampleIngressPath(app)
+			body, err := get(client, url)
+			if err != nil {
+				return err
+			}
+			v.responses[app] = body
+		}
+		return nil
+	})
+}
+
+// ingressAddress returns the address of the NodePort of the mesh ingress service.
+func (v *verifier) ingressAddress() (string, error) {
+	ctx, cancelFunc := v.requestContext()
+	defer cancelFunc()
+
+	service, err := v.client.CoreV1().Services(v.flag.MeshNamespace).Get(ctx,
+		installbase.IngressControllerServiceName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(service.Spec.Ports) == 0 || service.Spec.Ports[0].NodePort == 0 {
+		return "", errors.Errorf("no NodePort of service %s", service.Name)
+	}
+	nodePort := service.Spec.Ports[0].NodePort
+
+	// NOTICE: For situation where the node address is not reachable.
+	if addr := os.Getenv("EMCTL_NODE_ADDRESS"); addr != "" {
+		return fmt.Sprintf("http://%s:%d", addr, nodePort), nil
+	}
+
+	nodes, err := v.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes.Items {
+		for _, address := range n.Status.Addresses {
+			if address.Type == v1.NodeInternalIP {
+				return fmt.Sprintf("http://%s:%d", address.Address, nodePort), nil
+			}
+		}
+	}
+
+	return "", errors.Errorf("no internal IP of nodes")
+}
+
+func get(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read response of %s", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("request %s: status code %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// checkTracing checks the trace id is propagated to every sample app,
+// which echoes request headers in the response.
+func (v *verifier) checkTracing() error {
+	for _, app := range sampleApps {
+		headers, err := echoedHeaders(v.responses[app])
+		if err != nil {
+			return errors.Wrapf(err, "parse response of %s", app)
+		}
+		if headers[traceIDHeader] == "" {
+			return errors.Errorf("no trace id header %s propagated to %s", traceIDHeader, app)
+		}
+	}
+	return nil
+}
+
+// echoedHeaders returns the request headers echoed by a sample app,
+// with lower-cased names.
+func echoedHeaders(body []byte) (map[string]string, error) {
+	echo := struct {
+		Request struct {
+			Headers map[string]interface{} `json:"headers"`
+		} `json:"request"`
+	}{}
+
+	err := json.Unmarshal(body, &echo)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+	for k, v := range echo.Request.Headers {
+		headers[strings.ToLower(k)] = fmt.Sprint(v)
+	}
+	return headers, nil
+}
+
+// waitMetrics waits for the mesh ingress reporting statistics of
+// requests to every sample app.
+func (v *verifier) waitMetrics() error {
+	return poll(v.flag.WaitTimeout, func() error {
+		status, err := v.ingressStatus()
+		if err != nil {
+			return err
+		}
+
+		for _, app := range sampleApps {
+			if requestCount(status, app) == 0 {
+				return errors.Errorf("no metrics of requests to %s", app)
+			}
+		}
+		return nil
+	})
+}
+
+// ingressStatus returns statistics of objects in all mesh ingress pods,
+// which are requested through the proxy of the Kubernetes API server.
+func (v *verifier) ingressStatus() ([]interface{}, error) {
+	ctx, cancelFunc := v.requestContext()
+	defer cancelFunc()
+
+	pods, err := v.client.CoreV1().Pods(v.flag.MeshNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s", installbase.IngressControllerDeploymentName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, errors.Errorf("no pod of %s", installbase.IngressControllerDeploymentName)
+	}
+
+	var status []interface{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		port := installbase.PodContainerPort(pod, installbase.ControlPlaneStatefulSetAdminPortName, flags.DefaultMeshAdminPort)
+		buff, err := v.client.CoreV1().Pods(v.flag.MeshNamespace).
+			ProxyGet("http", pod.Name, strconv.Itoa(port), statusObjectsPath, nil).
+			DoRaw(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get status of pod %s", pod.Name)
+		}
+
+		var podStatus interface{}
+		err = json.Unmarshal(buff, &podStatus)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshal status of pod %s", pod.Name)
+		}
+		status = append(status, podStatus)
+	}
+
+	return status, nil
+}
+
+// requestCount sums the counts of requests in the statistics of objects
+// whose names contain the service name.
+func requestCount(status interface{}, serviceName string) float64 {
+	var walk func(value interface{}, matched bool) float64
+	walk = func(value interface{}, matched bool) float64 {
+		count := 0.0
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for k, v := range value {
+				if n, ok := v.(float64); ok && k == "count" && matched {
+					count += n
+					continue
+				}
+				count += walk(v, matched || strings.Contains(k, serviceName))
+			}
+		case []interface{}:
+			for _, v := range value {
+				count += walk(v, matched)
+			}
+		}
+		return count
+	}
+
+	return walk(status, false)
+}
+
+// tearDown deletes the mesh resources in the reverse order of creating,
+// and the namespace of sample apps.
+func (v *verifier) tearDown() error {
+	var errs []string
+
+	objects, err := sampleMeshObjects()
+	if err != nil {
+		return errors.Wrap(err, "decode mesh resources of sample apps")
+	}
+	for i := len(objects) - 1; i >= 0; i-- {
+		object := objects[i]
+		err := delete.WrapDeleterByMeshObject(object, v.meshClient, v.flag.Timeout).Delete()
+		if err != nil && !meshclient.IsNotFoundError(err) {
+			errs = append(errs, fmt.Sprintf("delete %s/%s: %v", object.Kind(), object.Name(), err))
+		}
+	}
+
+	ctx, cancelFunc := v.requestContext()
+	defer cancelFunc()
+	err = v.client.CoreV1().Namespaces().Delete(ctx, v.flag.Namespace, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		errs = append(errs, fmt.Sprintf("delete namespace %s: %v", v.flag.Namespace, err))
+	}
+
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// poll calls fn until it succeeds, it returns the last error of fn
+// if the timeout is exceeded.
+func poll(timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "timeout after %s", timeout)
+		}
+		common.Debugf("%v, retry in %s", err, pollInterval)
+		time.Sleep(pollInterval)
+	}
+}
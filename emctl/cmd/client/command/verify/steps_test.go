@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/testing/meshserver"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestVerifier(t *testing.T) (*verifier, *meshserver.Server) {
+	server := meshserver.New()
+	flag := &flags.VerifyInstall{
+		AdminGlobal:     &flags.AdminGlobal{Timeout: 5 * time.Second},
+		OperationGlobal: &flags.OperationGlobal{MeshNamespace: flags.DefaultMeshNamespace},
+		Namespace:       flags.DefaultVerifyInstallNamespace,
+		Image:           flags.DefaultVerifyInstallImage,
+		WaitTimeout:     time.Millisecond,
+	}
+	return newVerifier(flag, fake.NewSimpleClientset(), server.Client()), server
+}
+
+func TestSampleAppsLifecycle(t *testing.T) {
+	v, server := newTestVerifier(t)
+	defer server.Close()
+
+	for _, fn := range []func() error{v.createNamespace, v.applyMeshResources, v.deploySampleApps} {
+		if err := fn(); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	if err := v.createNamespace(); err == nil {
+		t.Fatalf("expected conflict of the existing namespace")
+	}
+
+	ctx := context.Background()
+	for _, app := range sampleApps {
+		if _, err := v.meshClient.V1Alpha1().Service().Get(ctx, app); err != nil {
+			t.Fatalf("get service %s failed: %v", app, err)
+		}
+		if _, err := v.client.AppsV1().Deployments(v.flag.Namespace).Get(ctx, app, metav1.GetOptions{}); err != nil {
+			t.Fatalf("get deployment %s failed: %v", app, err)
+		}
+	}
+
+	// NOTE: No sidecar is injected without the mesh operator.
+	if err := v.waitSidecarsInjected(); err == nil {
+		t.Fatalf("expected no pod of sample apps")
+	}
+
+	if err := v.tearDown(); err != nil {
+		t.Fatalf("tear down failed: %v", err)
+	}
+	if _, err := v.meshClient.V1Alpha1().Tenant().Get(ctx, "emctl-verify"); err == nil {
+		t.Fatalf("expected tenant deleted")
+	}
+	if _, err := v.client.CoreV1().Namespaces().Get(ctx, v.flag.Namespace, metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected namespace deleted")
+	}
+}
+
+func TestCheckSidecarInjected(t *testing.T) {
+	v, server := newTestVerifier(t)
+	defer server.Close()
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "emctl-verify-front-0",
+			Namespace: v.flag.Namespace,
+			Labels:    map[string]string{sampleAppLabelKey: "emctl-verify-front"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "emctl-verify-front"}},
+		},
+	}
+	pods := v.client.CoreV1().Pods(v.flag.Namespace)
+	if _, err := pods.Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := v.checkSidecarInjected("emctl-verify-front"); err == nil {
+		t.Fatalf("expected no sidecar injected")
+	}
+
+	pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: sidecarContainerName})
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	if _, err := pods.Update(context.Background(), pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := v.checkSidecarInjected("emctl-verify-front"); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestCheckTracing(t *testing.T) {
+	v, server := newTestVerifier(t)
+	defer server.Close()
+
+	v.responses["emctl-verify-front"] = []byte(`{"request":{"headers":{"X-B3-TraceId":"80f198ee56343ba8"}}}`)
+	v.responses["emctl-verify-back"] = []byte(`{"request":{"headers":{"host":"emctl-verify-back"}}}`)
+	if err := v.checkTracing(); err == nil {
+		t.Fatalf("expected no trace id propagated to emctl-verify-back")
+	}
+
+	v.responses["emctl-verify-back"] = []byte(`{"request":{"headers":{"x-b3-traceid":"80f198ee56343ba9"}}}`)
+	if err := v.checkTracing(); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestRequestCount(t *testing.T) {
+	status := []interface{}{}
+	err := json.Unmarshal([]byte(`[{
+		"pipeline-emctl-verify-front": {"ingress-0": {"count": 3, "m1": 0.1}},
+		"pipeline-other": {"ingress-0": {"count": 5}},
+		"http-server": {"ingress-0": {"status": {"emctl-verify-back": {"count": 2}}}}
+	}]`), &status)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if count := requestCount(status, "emctl-verify-front"); count != 3 {
+		t.Fatalf("expected 3 requests to emctl-verify-front, got %v", count)
+	}
+	if count := requestCount(status, "emctl-verify-back"); count != 2 {
+		t.Fatalf("expected 2 requests to emctl-verify-back, got %v", count)
+	}
+	if count := requestCount(status, "emctl-verify-none"); count != 0 {
+		t.Fatalf("expected no request, got %v", count)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	resultPass    = "PASS"
+	resultFail    = "FAIL"
+	resultSkipped = "SKIPPED"
+)
+
+type (
+	// step is a step of the verification, steps run in order
+	// and the ones after a failed step are skipped.
+	step struct {
+		name string
+		run  func() error
+	}
+
+	// stepResult is the result of a step shown in the summary.
+	stepResult struct {
+		Name     string
+		Result   string
+		Duration time.Duration
+		Message  string
+	}
+
+	verifier struct {
+		flag       *flags.VerifyInstall
+		client     kubernetes.Interface
+		meshClient meshclient.MeshClient
+
+		// responses are the bodies of sample apps responding
+		// requests through the mesh ingress, keyed by app.
+		responses map[string][]byte
+	}
+)
+
+// Run is the entrypoint of the emctl verify-install sub command
+func Run(cmd *cobra.Command, flag *flags.VerifyInstall) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	v := newVerifier(flag, client, meshclient.New(flag.Server))
+	results := v.verify()
+
+	printSummary(os.Stdout, results)
+	if !passed(results) {
+		common.ExitWithErrorf("verify installation of the EaseMesh failed")
+	}
+
+	common.Infof("verify installation of the EaseMesh successfully")
+}
+
+func newVerifier(flag *flags.VerifyInstall, client kubernetes.Interface, meshClient meshclient.MeshClient) *verifier {
+	return &verifier{
+		flag:       flag,
+		client:     client,
+		meshClient: meshClient,
+		responses:  map[string][]byte{},
+	}
+}
+
+func (v *verifier) steps() []step {
+	return []step{
+		{name: "create namespace", run: v.createNamespace},
+		{name: "apply mesh resources", run: v.applyMeshResources},
+		{name: "deploy sample apps", run: v.deploySampleApps},
+		{name: "inject sidecars", run: v.waitSidecarsInjected},
+		{name: "register service instances", run: v.waitServiceInstances},
+		{name: "request through ingress", run: v.requestThroughIngress},
+		{name: "propagate tracing", run: v.checkTracing},
+		{name: "report metrics", run: v.waitMetrics},
+	}
+}
+
+// verify runs all steps and tears down the sample apps at last,
+// it returns results of all steps including the teardown.
+func (v *verifier) verify() []*stepResult {
+	results := runSteps(v.steps())
+
+	if v.flag.KeepResources {
+		common.Warnf("keep sample apps in namespace %s, delete them by: kubectl delete namespace %s",
+			v.flag.Namespace, v.flag.Namespace)
+		return results
+	}
+
+	return append(results, runSteps([]step{{name: "tear down", run: v.tearDown}})...)
+}
+
+func runSteps(steps []step) []*stepResult {
+	results := make([]*stepResult, 0, len(steps))
+
+	failed := false
+	for _, s := range steps {
+		result := &stepResult{Name: s.name}
+		results = append(results, result)
+
+		if failed {
+			result.Result = resultSkipped
+			continue
+		}
+
+		common.Infof("%s", s.name)
+		startTime := time.Now()
+		err := s.run()
+		result.Duration = time.Since(startTime).Round(time.Millisecond)
+
+		if err != nil {
+			failed = true
+			result.Result, result.Message = resultFail, err.Error()
+			common.Warnf("%s failed: %v", s.name, err)
+			continue
+		}
+		result.Result = resultPass
+	}
+
+	return results
+}
+
+func passed(results []*stepResult) bool {
+	for _, result := range results {
+		if result.Result != resultPass {
+			return false
+		}
+	}
+	return true
+}
+
+func printSummary(w io.Writer, results []*stepResult) {
+	table := tablewriter.NewWriter(w)
+
+	table.SetHeader([]string{"Step", "Result", "Duration", "Message"})
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, result := range results {
+		duration := ""
+		if result.Result != resultSkipped {
+			duration = result.Duration.String()
+		}
+		table.Append([]string{result.Name, result.Result, duration, result.Message})
+	}
+
+	table.Render()
+
+	passedCount := 0
+	for _, result := range results {
+		if result.Result == resultPass {
+			passedCount++
+		}
+	}
+	fmt.Fprintf(w, "\n%d/%d steps passed\n", passedCount, len(results))
+}
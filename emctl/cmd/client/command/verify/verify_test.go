@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package verify
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRunSteps(t *testing.T) {
+	called := []string{}
+	steps := []step{
+		{name: "first", run: func() error { called = append(called, "first"); return nil }},
+		{name: "second", run: func() error { called = append(called, "second"); return errors.New("boom") }},
+		{name: "third", run: func() error { called = append(called, "third"); return nil }},
+	}
+
+	results := runSteps(steps)
+	if strings.Join(called, ",") != "first,second" {
+		t.Fatalf("steps after the failed one should be skipped, called: %v", called)
+	}
+
+	expected := []string{resultPass, resultFail, resultSkipped}
+	for i, result := range results {
+		if result.Result != expected[i] {
+			t.Fatalf("expected result of %s is %s, got %s", result.Name, expected[i], result.Result)
+		}
+	}
+	if results[1].Message != "boom" {
+		t.Fatalf("expected message boom, got %s", results[1].Message)
+	}
+	if passed(results) {
+		t.Fatalf("expected verification failed")
+	}
+
+	buff := &bytes.Buffer{}
+	printSummary(buff, results)
+	if !strings.Contains(buff.String(), "1/3 steps passed") {
+		t.Fatalf("unexpected summary:\n%s", buff.String())
+	}
+
+	if !passed(runSteps(steps[:1])) {
+		t.Fatalf("expected verification passed")
+	}
+}
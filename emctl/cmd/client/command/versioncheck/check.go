@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package versioncheck
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/pkg/version"
+
+	"github.com/spf13/cobra"
+)
+
+// checkTimeout limits the time of getting a version, so a command isn't
+// slowed down by an unreachable component, which it reports by itself.
+const checkTimeout = 2 * time.Second
+
+type (
+	// versionCache caches versions of components in files, so they are got
+	// once in the ttl instead of by every command, see SetCache.
+	versionCache struct {
+		mutex sync.Mutex
+		dir   string
+		ttl   time.Duration
+	}
+
+	cachedComponent struct {
+		CheckedAt time.Time  `json:"checkedAt"`
+		Component *Component `json:"component"`
+	}
+)
+
+var (
+	cache = &versionCache{}
+
+	// exemptCommands bridge versions or don't reach the server-side
+	// components, so they are never blocked by skews.
+	exemptCommands = map[string]bool{
+		"version":    true,
+		"install":    true,
+		"reset":      true,
+		"check":      true,
+		"completion": true,
+		"help":       true,
+		"plugin":     true,
+		"telemetry":  true,
+	}
+)
+
+// SetCache makes checks cache versions of components in the directory for
+// the ttl, empty dir disables the cache.
+func SetCache(dir string, ttl time.Duration) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.dir, cache.ttl = dir, ttl
+}
+
+func (c *versionCache) path(key string) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.dir == "" || c.ttl <= 0 {
+		return ""
+	}
+	// NOTE: Every component has its own cache, e.g. 127.0.0.1:2381 is cached in 127.0.0.1_2381.
+	return filepath.Join(c.dir, strings.NewReplacer(":", "_", "/", "_").Replace(key), "version.json")
+}
+
+func (c *versionCache) load(key string) *Component {
+	path := c.path(key)
+	if path == "" {
+		return nil
+	}
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	cached := &cachedComponent{}
+	err = json.Unmarshal(buff, cached)
+	if err != nil || cached.Component == nil {
+		common.Debugf("ignore broken cache of the version: %v", err)
+		return nil
+	}
+
+	c.mutex.Lock()
+	ttl := c.ttl
+	c.mutex.Unlock()
+	if time.Since(cached.CheckedAt) > ttl {
+		return nil
+	}
+	return cached.Component
+}
+
+func (c *versionCache) store(key string, component *Component) {
+	path := c.path(key)
+	if path == "" {
+		return
+	}
+
+	buff, err := json.Marshal(&cachedComponent{CheckedAt: time.Now(), Component: component})
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = ioutil.WriteFile(path, buff, 0o644)
+		}
+	}
+	if err != nil {
+		common.Debugf("cache the version failed: %v", err)
+	}
+}
+
+// cached returns the cached component of the key, or gets it by fn and
+// caches it. A component missing its version isn't cached, e.g. it's
+// not installed yet, neither is the one failed to be got.
+func cached(key string, fn func(ctx context.Context) (*Component, error)) *Component {
+	if component := cache.load(key); component != nil {
+		return component
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	component, err := fn(ctx)
+	if err != nil {
+		common.Debugf("ignored: check the version failed: %v", err)
+		return nil
+	}
+	if component.Version != "" {
+		cache.store(key, component)
+	}
+	return component
+}
+
+// Check checks skews of components the command works with: the control plane
+// for commands with --server, the operator for commands with --mesh-namespace.
+// Warnings are logged, and errors block the command unless skip is true.
+func Check(cmd *cobra.Command, skip bool) {
+	if skip || exempt(cmd) {
+		return
+	}
+
+	components := []*Component{}
+	if server := serverOf(cmd); server != "" {
+		component := cached(server, func(ctx context.Context) (*Component, error) {
+			return controlPlaneComponent(ctx, server)
+		})
+		if component != nil {
+			components = append(components, component)
+		}
+	}
+	if f := cmd.Flags().Lookup("mesh-namespace"); f != nil {
+		if component := operatorOf(f.Value.String()); component != nil {
+			components = append(components, component)
+		}
+	}
+
+	for _, component := range components {
+		skew := CheckSkew(version.RELEASE, component)
+		if skew == nil {
+			continue
+		}
+		if skew.Severity == SeverityError {
+			common.ExitWithCodef(common.ExitCodeValidation, "%s, run with --skip-version-check to ignore it", skew.Message)
+		}
+		common.Warnf("%s", skew.Message)
+	}
+}
+
+func exempt(cmd *cobra.Command) bool {
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	return !cmd.HasParent() || exemptCommands[top.Name()]
+}
+
+// serverOf returns the control plane the command works with, which is the
+// --server flag, or the server of rc file.
+func serverOf(cmd *cobra.Command) string {
+	f := cmd.Flags().Lookup("server")
+	if f == nil {
+		return ""
+	}
+	if f.Value.String() != "" {
+		return strings.TrimPrefix(f.Value.String(), "http://")
+	}
+
+	// NOTE: flags.GetServerAddress complains about the missing rc file, the command does it by itself.
+	rc, err := rcfile.New()
+	if err != nil || rc.Unmarshal() != nil {
+		return ""
+	}
+	return strings.TrimPrefix(rc.Server, "http://")
+}
+
+func operatorOf(namespace string) *Component {
+	config, err := installbase.KubernetesConfig()
+	if err != nil {
+		common.Debugf("ignored: check the version of the operator failed: %v", err)
+		return nil
+	}
+
+	// NOTE: Operators of different clusters are cached separately.
+	return cached(config.Host+"/"+namespace+"/operator", func(ctx context.Context) (*Component, error) {
+		client, err := installbase.NewKubernetesClient()
+		if err != nil {
+			return nil, err
+		}
+		return operatorComponent(ctx, client, namespace)
+	})
+}
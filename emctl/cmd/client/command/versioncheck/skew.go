@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package versioncheck checks version skews between emctl and the server-side
+// components of EaseMesh, which are the control plane and the operator.
+package versioncheck
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaxMinorSkew is the max difference of minor versions between emctl and
+	// a server-side component, larger skews are warned.
+	MaxMinorSkew = 1
+
+	// ComponentControlPlane is the name of the control plane component.
+	ComponentControlPlane = "ControlPlane"
+	// ComponentOperator is the name of the operator component.
+	ComponentOperator = "Operator"
+
+	// SeverityWarning means emctl may not work with the component as expected.
+	SeverityWarning = "WARNING"
+	// SeverityError means emctl doesn't work with the component, commands are blocked.
+	SeverityError = "ERROR"
+
+	operatorContainerName = "operator-manager"
+)
+
+type (
+	// Component is the version of a server-side component.
+	Component struct {
+		Name    string `yaml:"name" json:"name"`
+		Version string `yaml:"version,omitempty" json:"version,omitempty"`
+		// APIVersions are API versions of mesh resources served by the component.
+		APIVersions []string `yaml:"apiVersions,omitempty" json:"apiVersions,omitempty"`
+		// Error is why the version is unknown.
+		Error string `yaml:"error,omitempty" json:"error,omitempty"`
+		Skew  *Skew  `yaml:"skew,omitempty" json:"skew,omitempty"`
+	}
+
+	// Skew is the incompatibility of a component with emctl.
+	Skew struct {
+		Severity string `yaml:"severity" json:"severity"`
+		Message  string `yaml:"message" json:"message"`
+	}
+
+	semver struct {
+		major int
+		minor int
+	}
+)
+
+// parseVersion parses the major and minor versions of versions like v1.3.0,
+// 1.3 or 1.3.0-rc.1. Versions like latest or UNKNOWN are not parsed.
+func parseVersion(version string) (semver, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return semver{}, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return semver{}, false
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return semver{}, false
+	}
+	return semver{major: major, minor: minor}, true
+}
+
+// CheckSkew returns the skew of the component with emctl of the client
+// version, nil means they are compatible or the versions are unknown.
+func CheckSkew(client string, c *Component) *Skew {
+	if len(c.APIVersions) != 0 && !contains(c.APIVersions, resource.DefaultAPIVersion) {
+		return &Skew{
+			Severity: SeverityError,
+			Message: fmt.Sprintf("%s serves API versions %s, but emctl %s requires %s",
+				c.Name, strings.Join(c.APIVersions, ", "), client, resource.DefaultAPIVersion),
+		}
+	}
+
+	clientVersion, ok := parseVersion(client)
+	if !ok {
+		return nil
+	}
+	serverVersion, ok := parseVersion(c.Version)
+	if !ok {
+		return nil
+	}
+
+	if clientVersion.major != serverVersion.major {
+		return &Skew{
+			Severity: SeverityError,
+			Message: fmt.Sprintf("%s %s is incompatible with emctl %s of another major version",
+				c.Name, c.Version, client),
+		}
+	}
+
+	skew := clientVersion.minor - serverVersion.minor
+	if skew > MaxMinorSkew || -skew > MaxMinorSkew {
+		return &Skew{
+			Severity: SeverityWarning,
+			Message: fmt.Sprintf("%s %s is more than %d minor version away from emctl %s, upgrade the older one",
+				c.Name, c.Version, MaxMinorSkew, client),
+		}
+	}
+	return nil
+}
+
+// controlPlaneComponent gets the version of the control plane, the error
+// means the control plane can't be reached.
+func controlPlaneComponent(ctx context.Context, server string) (*Component, error) {
+	c := &Component{Name: ComponentControlPlane}
+	version, err := meshclient.New(server).V1Alpha1().Version().Get(ctx)
+	if err != nil {
+		if meshclient.IsNotFoundError(err) || meshclient.IsUnsupportedError(err) {
+			c.Error = "the control plane doesn't serve its version, it may be older than emctl"
+			return c, nil
+		}
+		return nil, err
+	}
+
+	c.Version, c.APIVersions = version.Version, version.APIVersions
+	return c, nil
+}
+
+// operatorComponent gets the version of the operator by the image tag of its
+// Deployment, the error means Kubernetes can't be reached.
+func operatorComponent(ctx context.Context, client kubernetes.Interface, namespace string) (*Component, error) {
+	c := &Component{Name: ComponentOperator}
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, installbase.OperatorDeploymentName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			c.Error = fmt.Sprintf("deployment %s/%s not found", namespace, installbase.OperatorDeploymentName)
+			return c, nil
+		}
+		return nil, errors.Wrapf(err, "get deployment %s/%s", namespace, installbase.OperatorDeploymentName)
+	}
+
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == operatorContainerName {
+			c.Version = imageTag(container.Image)
+		}
+	}
+	if c.Version == "" {
+		c.Error = fmt.Sprintf("no tag in the image of the container %s", operatorContainerName)
+	}
+	return c, nil
+}
+
+// imageTag returns the tag of the image, e.g. v1.3.0 of megaease/easemesh-operator:v1.3.0.
+func imageTag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package versioncheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/pkg/version"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Versions are versions of emctl and the server-side components.
+	Versions struct {
+		Client  ClientVersion `yaml:"client" json:"client"`
+		Servers []*Component  `yaml:"servers,omitempty" json:"servers,omitempty"`
+	}
+
+	// ClientVersion is the version of emctl.
+	ClientVersion struct {
+		Version    string `yaml:"version" json:"version"`
+		Commit     string `yaml:"commit" json:"commit"`
+		Repo       string `yaml:"repo" json:"repo"`
+		APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	}
+)
+
+// RunVersion is the entrypoint of the emctl version command
+func RunVersion(cmd *cobra.Command, flag *flags.Version) {
+	switch flag.OutputFormat {
+	case "text", "yaml", "json":
+	default:
+		common.ExitWithCodef(common.ExitCodeValidation, "unsupported output format %s (support text, yaml, json)",
+			flag.OutputFormat)
+	}
+
+	versions := &Versions{
+		Client: ClientVersion{
+			Version:    version.RELEASE,
+			Commit:     version.COMMIT,
+			Repo:       version.REPO,
+			APIVersion: resource.DefaultAPIVersion,
+		},
+	}
+	if !flag.Client {
+		versions.Servers = serverVersions(flag)
+	}
+
+	switch flag.OutputFormat {
+	case "text":
+		printVersions(os.Stdout, versions)
+	case "yaml":
+		buff, err := yaml.Marshal(versions)
+		if err != nil {
+			common.ExitWithErrorf("marshal versions failed: %w", err)
+		}
+		fmt.Print(string(buff))
+	case "json":
+		buff, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			common.ExitWithErrorf("marshal versions failed: %w", err)
+		}
+		fmt.Println(string(buff))
+	}
+
+	for _, server := range versions.Servers {
+		if server.Skew != nil && server.Skew.Severity == SeverityError {
+			common.ExitWithCodef(common.ExitCodeValidation, "%s", server.Skew.Message)
+		}
+	}
+}
+
+// serverVersions gets versions of server-side components without the cache,
+// a component failed to be got is reported with the error.
+func serverVersions(flag *flags.Version) []*Component {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancel()
+
+	controlPlane, err := controlPlaneComponent(ctx, flag.Server)
+	if err != nil {
+		controlPlane = &Component{Name: ComponentControlPlane, Error: err.Error()}
+	}
+
+	var operator *Component
+	client, err := installbase.NewKubernetesClient()
+	if err == nil {
+		operator, err = operatorComponent(ctx, client, flag.MeshNamespace)
+	}
+	if err != nil {
+		operator = &Component{Name: ComponentOperator, Error: err.Error()}
+	}
+
+	components := []*Component{controlPlane, operator}
+	for _, component := range components {
+		component.Skew = CheckSkew(version.RELEASE, component)
+	}
+	return components
+}
+
+func printVersions(w io.Writer, versions *Versions) {
+	fmt.Fprintf(w, "Client Version: %s (commit: %s, API version: %s)\n",
+		versions.Client.Version, versions.Client.Commit, versions.Client.APIVersion)
+
+	for _, server := range versions.Servers {
+		v := server.Version
+		if v == "" {
+			v = "unknown (" + server.Error + ")"
+		}
+		fmt.Fprintf(w, "%s Version: %s\n", server.Name, v)
+	}
+
+	for _, server := range versions.Servers {
+		if server.Skew != nil {
+			fmt.Fprintf(w, "%s: %s\n", server.Skew.Severity, server.Skew.Message)
+		}
+	}
+}
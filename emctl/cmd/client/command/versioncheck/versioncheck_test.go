@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package versioncheck
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckSkew(t *testing.T) {
+	for _, c := range []struct {
+		client      string
+		server      string
+		apiVersions []string
+		severity    string
+	}{
+		{"1.3.0", "1.3.2", nil, ""},
+		{"v1.3.0", "v1.2.0", nil, ""},
+		{"1.3.0", "1.4.0-rc.1", nil, ""},
+		{"1.3.0", "1.1.0", nil, SeverityWarning},
+		{"1.1.0", "1.3.0", nil, SeverityWarning},
+		{"1.3.0", "2.0.0", nil, SeverityError},
+		{"1.3.0", "1.3.0", []string{"mesh.megaease.com/v2"}, SeverityError},
+		{"1.3.0", "1.3.0", []string{"mesh.megaease.com/v2", resource.DefaultAPIVersion}, ""},
+		{"UNKNOWN", "1.3.0", nil, ""},
+		{"1.3.0", "latest", nil, ""},
+		{"1.3.0", "", nil, ""},
+	} {
+		skew := CheckSkew(c.client, &Component{Name: ComponentControlPlane, Version: c.server, APIVersions: c.apiVersions})
+		severity := ""
+		if skew != nil {
+			severity = skew.Severity
+		}
+		if severity != c.severity {
+			t.Fatalf("emctl %s with %s %v: expect severity %q, got %+v", c.client, c.server, c.apiVersions, c.severity, skew)
+		}
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	for image, tag := range map[string]string{
+		"megaease/easemesh-operator:v1.3.0":                 "v1.3.0",
+		"registry.local:5000/megaease/easemesh-operator":    "",
+		"registry.local:5000/easemesh-operator:1.3.0":       "1.3.0",
+		"megaease/easemesh-operator:v1.3.0@sha256:0123abcd": "v1.3.0",
+		"megaease/easemesh-operator@sha256:0123abcd":        "",
+	} {
+		if got := imageTag(image); got != tag {
+			t.Fatalf("image %s: expect tag %q, got %q", image, tag, got)
+		}
+	}
+}
+
+func TestOperatorComponent(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	component, err := operatorComponent(context.Background(), client, "easemesh")
+	if err != nil || component.Version != "" || component.Error == "" {
+		t.Fatalf("expect the missing operator reported, got %+v, %v", component, err)
+	}
+
+	client = fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: installbase.OperatorDeploymentName, Namespace: "easemesh"},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.5.0"},
+						{Name: operatorContainerName, Image: "megaease/easemesh-operator:v1.3.0"},
+					},
+				},
+			},
+		},
+	})
+	component, err = operatorComponent(context.Background(), client, "easemesh")
+	if err != nil || component.Version != "v1.3.0" {
+		t.Fatalf("expect the operator v1.3.0, got %+v, %v", component, err)
+	}
+}
+
+func TestCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versioncheck")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	SetCache(dir, time.Minute)
+	defer SetCache("", 0)
+
+	calls := 0
+	fn := func(ctx context.Context) (*Component, error) {
+		calls++
+		return &Component{Name: ComponentControlPlane, Version: "1.3.0"}, nil
+	}
+	for i := 0; i < 3; i++ {
+		component := cached("127.0.0.1:2381", fn)
+		if component == nil || component.Version != "1.3.0" {
+			t.Fatalf("unexpected component %+v", component)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expect the version got once, got %d times", calls)
+	}
+
+	noVersion := func(ctx context.Context) (*Component, error) {
+		calls++
+		return &Component{Name: ComponentOperator, Error: "not found"}, nil
+	}
+	cached("https://10.0.0.1:6443/easemesh/operator", noVersion)
+	cached("https://10.0.0.1:6443/easemesh/operator", noVersion)
+	if calls != 3 {
+		t.Fatalf("expect components without versions not cached, got %d calls", calls)
+	}
+}
@@ -0,0 +1,308 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	sidecarConfigFileName = "sidecar-config.yaml"
+	composeFileName       = "docker-compose.yaml"
+	sidecarUnitFileName   = "easemesh-sidecar.service"
+	agentEnvFileName      = "easeagent.env"
+
+	sidecarAPIAddr  = "localhost:2381"
+	sidecarBinary   = "/opt/easegress/bin/easegress-server"
+	sidecarHomeDir  = "/var/lib/easemesh-sidecar"
+	sidecarConfDir  = "/etc/easemesh"
+	agentInstallDir = "/opt/easeagent"
+
+	// The paths and the volume below are the same as the injected sidecar in Kubernetes.
+	sidecarVolumeMountPath = "/sidecar-volume"
+	agentVolumeMountPath   = "/agent-volume"
+	agentVolumeName        = "easeagent"
+	agentInitializerCmd    = "cp -r /easeagent-volume/* " + agentVolumeMountPath
+
+	applicationIPEnvName = "APPLICATION_IP"
+	javaToolOptionsEnv   = "JAVA_TOOL_OPTIONS"
+)
+
+type (
+	// generatedFile is a file generated for the VM workload.
+	generatedFile struct {
+		Name    string
+		Content []byte
+		// Mode is the mode of the file in the tarball, 0644 if zero.
+		Mode int64
+	}
+
+	composeSpec struct {
+		Version  string                    `yaml:"version"`
+		Services map[string]composeService `yaml:"services"`
+		Volumes  map[string]struct{}       `yaml:"volumes"`
+	}
+
+	composeService struct {
+		Image       string            `yaml:"image"`
+		Command     []string          `yaml:"command,omitempty"`
+		NetworkMode string            `yaml:"network_mode,omitempty"`
+		Environment map[string]string `yaml:"environment,omitempty"`
+		Volumes     []string          `yaml:"volumes,omitempty"`
+		Restart     string            `yaml:"restart,omitempty"`
+	}
+)
+
+// RunGenerate is the entrypoint of the emctl vm generate sub command
+func RunGenerate(cmd *cobra.Command, flag *flags.VMGenerate) {
+	err := validate(flag)
+	if err != nil {
+		common.ExitWithError(err)
+	}
+
+	if len(flag.ControlPlanePeerURLs) == 0 {
+		flag.ControlPlanePeerURLs, err = discoverPeerURLs(flag.MeshNamespace)
+		if err != nil {
+			common.ExitWithErrorf("discover peer URLs of control plane failed: %w, specify them by --control-plane-peer-urls", err)
+		}
+	}
+
+	files, err := generate(flag)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	err = os.MkdirAll(flag.OutputDir, 0o755)
+	if err != nil {
+		common.ExitWithErrorf("create directory %s failed: %w", flag.OutputDir, err)
+	}
+	for _, f := range files {
+		path := filepath.Join(flag.OutputDir, f.Name)
+		err = ioutil.WriteFile(path, f.Content, 0o644)
+		if err != nil {
+			common.ExitWithErrorf("write %s failed: %w", path, err)
+		}
+		common.Infof("%s generated", path)
+	}
+
+	fmt.Print(nextSteps(flag))
+}
+
+func validate(flag *flags.VMGenerate) error {
+	if flag.ServiceName == "" {
+		return common.CodeErrorf(common.ExitCodeValidation, "--service-name is required")
+	}
+	if net.ParseIP(flag.ApplicationIP) == nil {
+		return common.CodeErrorf(common.ExitCodeValidation, "--application-ip must be an IP reachable from other instances, got %q", flag.ApplicationIP)
+	}
+	if flag.ApplicationPort <= 0 || flag.ApplicationPort > 65535 {
+		return common.CodeErrorf(common.ExitCodeValidation, "--application-port must be a valid port, got %d", flag.ApplicationPort)
+	}
+	if flag.Format != flags.VMFormatCompose && flag.Format != flags.VMFormatSystemd {
+		return common.CodeErrorf(common.ExitCodeValidation, "unknown format %s, must be %s or %s",
+			flag.Format, flags.VMFormatCompose, flags.VMFormatSystemd)
+	}
+	if flag.InstanceName == "" {
+		replacer := strings.NewReplacer(".", "-", ":", "-")
+		flag.InstanceName = flag.ServiceName + "-" + replacer.Replace(flag.ApplicationIP)
+	}
+	return nil
+}
+
+func discoverPeerURLs(namespace string) ([]string, error) {
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	urls, err := installbase.GetMeshControlPlaneEndpoints(client, namespace,
+		installbase.ControlPlanePlubicServiceName, installbase.ControlPlaneStatefulSetPeerPortName)
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, errors.Errorf("no endpoint of control plane found")
+	}
+	return urls, nil
+}
+
+func generate(flag *flags.VMGenerate) ([]generatedFile, error) {
+	homeDir := installbase.SidecarHomeDir
+	if flag.Format == flags.VMFormatSystemd {
+		homeDir = sidecarHomeDir
+	}
+	config, err := sidecarConfig(flag, homeDir)
+	if err != nil {
+		return nil, err
+	}
+	files := []generatedFile{{Name: sidecarConfigFileName, Content: config}}
+
+	switch flag.Format {
+	case flags.VMFormatCompose:
+		compose, err := composeConfig(flag)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, generatedFile{Name: composeFileName, Content: compose})
+	case flags.VMFormatSystemd:
+		files = append(files,
+			generatedFile{Name: sidecarUnitFileName, Content: []byte(sidecarUnit(flag))},
+			generatedFile{Name: agentEnvFileName, Content: []byte(javaToolOptionsEnv + "=" + strconv.Quote(javaToolOptions(agentInstallDir)) + "\n")},
+		)
+	}
+	return files, nil
+}
+
+// sidecarConfig is the config of the sidecar joining the control plane as a
+// secondary member, its labels are the same as the injected sidecar in Kubernetes.
+func sidecarConfig(flag *flags.VMGenerate, homeDir string) ([]byte, error) {
+	config := installbase.EasegressConfig{
+		Name:        flag.InstanceName,
+		ClusterName: installbase.ControlPlaneStatefulSetName,
+		ClusterRole: installbase.EasegressSecondaryClusterRole,
+		Cluster: installbase.ClusterOptions{
+			PrimaryListenPeerURLs: flag.ControlPlanePeerURLs,
+		},
+		APIAddr: sidecarAPIAddr,
+		HomeDir: homeDir,
+		Labels: map[string]string{
+			"alive-probe":         flag.AliveProbeURL,
+			"application-port":    strconv.Itoa(flag.ApplicationPort),
+			"mesh-service-labels": marshalLabels(flag.Labels),
+			"mesh-servicename":    flag.ServiceName,
+		},
+	}
+
+	buff, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sidecar config")
+	}
+	return buff, nil
+}
+
+func composeConfig(flag *flags.VMGenerate) ([]byte, error) {
+	compose := composeSpec{
+		Version: "3",
+		Services: map[string]composeService{
+			"easeagent-initializer": {
+				Image:   flag.AgentInitializerImage,
+				Command: []string{"sh", "-c", agentInitializerCmd},
+				Volumes: []string{agentVolumeName + ":" + agentVolumeMountPath},
+			},
+			"easemesh-sidecar": {
+				Image:       flag.SidecarImage,
+				Command:     []string{sidecarBinary, "-f", sidecarVolumeMountPath + "/" + sidecarConfigFileName},
+				NetworkMode: "host",
+				Environment: map[string]string{applicationIPEnvName: flag.ApplicationIP},
+				Volumes: []string{
+					"./" + sidecarConfigFileName + ":" + sidecarVolumeMountPath + "/" + sidecarConfigFileName + ":ro",
+				},
+				Restart: "unless-stopped",
+			},
+		},
+		Volumes: map[string]struct{}{agentVolumeName: {}},
+	}
+
+	buff, err := yaml.Marshal(compose)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal docker-compose file")
+	}
+	return buff, nil
+}
+
+func sidecarUnit(flag *flags.VMGenerate) string {
+	return fmt.Sprintf(`[Unit]
+Description=EaseMesh sidecar of service %s
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Environment=%s=%s
+ExecStart=%s -f %s/%s
+WorkingDirectory=%s
+StateDirectory=easemesh-sidecar
+Restart=always
+RestartSec=5
+
+[Install]
+WantedBy=multi-user.target
+`, flag.ServiceName, applicationIPEnvName, flag.ApplicationIP,
+		sidecarBinary, sidecarConfDir, sidecarConfigFileName, sidecarHomeDir)
+}
+
+// javaToolOptions attaches EaseAgent installed in the directory to Java applications.
+func javaToolOptions(dir string) string {
+	return fmt.Sprintf(" -javaagent:%s/easeagent.jar -Deaseagent.log.conf=%s/%s ",
+		dir, dir, installbase.AgentLog4jConfigName)
+}
+
+func marshalLabels(labels map[string]string) string {
+	pairs := []string{}
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func nextSteps(flag *flags.VMGenerate) string {
+	switch flag.Format {
+	case flags.VMFormatCompose:
+		return fmt.Sprintf(`
+Add the following to the service of the application in %s, then run 'docker-compose up -d':
+
+    network_mode: host
+    environment:
+      %s: "%s"
+    volumes:
+    - %s:%s
+    depends_on:
+    - easeagent-initializer
+    - easemesh-sidecar
+`, composeFileName, javaToolOptionsEnv, javaToolOptions(agentVolumeMountPath), agentVolumeName, agentVolumeMountPath)
+	default:
+		return fmt.Sprintf(`
+Install the sidecar on the VM:
+
+    install -D -m 0644 %s %s/%s
+    install -D -m 0644 %s %s/%s
+    install -D -m 0644 %s /etc/systemd/system/%s
+    systemctl daemon-reload && systemctl enable --now easemesh-sidecar
+
+Copy %s of the sidecar image to the same path, and EaseAgent to %s,
+then add 'EnvironmentFile=%s/%s' to the systemd unit of the application.
+`, sidecarConfigFileName, sidecarConfDir, sidecarConfigFileName,
+			agentEnvFileName, sidecarConfDir, agentEnvFileName,
+			sidecarUnitFileName, sidecarUnitFileName,
+			sidecarBinary, agentInstallDir, sidecarConfDir, agentEnvFileName)
+	}
+}
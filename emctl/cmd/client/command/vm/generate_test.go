@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"gopkg.in/yaml.v2"
+)
+
+func testFlag(format string) *flags.VMGenerate {
+	return &flags.VMGenerate{
+		OperationGlobal:       &flags.OperationGlobal{MeshNamespace: "easemesh"},
+		ServiceName:           "order-service",
+		ApplicationIP:         "10.0.0.8",
+		ApplicationPort:       8080,
+		Labels:                map[string]string{"zone": "dc1", "version": "v2"},
+		ControlPlanePeerURLs:  []string{"http://192.168.0.10:32380"},
+		Format:                format,
+		SidecarImage:          "docker.io/megaease/easegress:easemesh",
+		AgentInitializerImage: "docker.io/megaease/easeagent-initializer:latest",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	flag := testFlag(flags.VMFormatCompose)
+	err := validate(flag)
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if flag.InstanceName != "order-service-10-0-0-8" {
+		t.Fatalf("unexpected instance name %s", flag.InstanceName)
+	}
+
+	for _, mutate := range []func(f *flags.VMGenerate){
+		func(f *flags.VMGenerate) { f.ServiceName = "" },
+		func(f *flags.VMGenerate) { f.ApplicationIP = "vm-1" },
+		func(f *flags.VMGenerate) { f.ApplicationPort = 0 },
+		func(f *flags.VMGenerate) { f.Format = "helm" },
+	} {
+		flag := testFlag(flags.VMFormatCompose)
+		mutate(flag)
+		if validate(flag) == nil {
+			t.Fatalf("expected invalid flags %+v", flag)
+		}
+	}
+}
+
+func findFile(t *testing.T, files []generatedFile, name string) []byte {
+	for _, f := range files {
+		if f.Name == name {
+			return f.Content
+		}
+	}
+	t.Fatalf("file %s not generated", name)
+	return nil
+}
+
+func TestGenerateCompose(t *testing.T) {
+	flag := testFlag(flags.VMFormatCompose)
+	validate(flag)
+
+	files, err := generate(flag)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	config := installbase.EasegressConfig{}
+	err = yaml.Unmarshal(findFile(t, files, sidecarConfigFileName), &config)
+	if err != nil {
+		t.Fatalf("unmarshal sidecar config failed: %v", err)
+	}
+	if config.Name != flag.InstanceName || config.ClusterRole != installbase.EasegressSecondaryClusterRole ||
+		len(config.Cluster.PrimaryListenPeerURLs) != 1 {
+		t.Fatalf("unexpected sidecar config %+v", config)
+	}
+	if config.Labels["mesh-servicename"] != "order-service" || config.Labels["application-port"] != "8080" ||
+		config.Labels["mesh-service-labels"] != "version=v2,zone=dc1" {
+		t.Fatalf("unexpected labels of sidecar config %v", config.Labels)
+	}
+
+	compose := composeSpec{}
+	err = yaml.Unmarshal(findFile(t, files, composeFileName), &compose)
+	if err != nil {
+		t.Fatalf("unmarshal docker-compose file failed: %v", err)
+	}
+	sidecar, ok := compose.Services["easemesh-sidecar"]
+	if !ok {
+		t.Fatalf("sidecar service not found in %+v", compose)
+	}
+	if sidecar.Environment[applicationIPEnvName] != "10.0.0.8" || sidecar.NetworkMode != "host" {
+		t.Fatalf("unexpected sidecar service %+v", sidecar)
+	}
+	if _, ok := compose.Volumes[agentVolumeName]; !ok {
+		t.Fatalf("volume %s not found", agentVolumeName)
+	}
+}
+
+func TestGenerateSystemd(t *testing.T) {
+	flag := testFlag(flags.VMFormatSystemd)
+	validate(flag)
+
+	files, err := generate(flag)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+
+	unit := string(findFile(t, files, sidecarUnitFileName))
+	if !strings.Contains(unit, "ExecStart="+sidecarBinary+" -f "+sidecarConfDir+"/"+sidecarConfigFileName) ||
+		!strings.Contains(unit, "Environment=APPLICATION_IP=10.0.0.8") {
+		t.Fatalf("unexpected systemd unit:\n%s", unit)
+	}
+
+	env := string(findFile(t, files, agentEnvFileName))
+	if !strings.HasPrefix(env, "JAVA_TOOL_OPTIONS=") || !strings.Contains(env, agentInstallDir+"/easeagent.jar") {
+		t.Fatalf("unexpected agent env file: %s", env)
+	}
+}
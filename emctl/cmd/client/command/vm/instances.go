@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// statusPending means the sidecar of the VM hasn't registered the instance yet.
+	statusPending = "PENDING"
+	// statusUnknown means the instances can't be listed from the control plane.
+	statusUnknown = "UNKNOWN"
+)
+
+// vmStatus is a row of emctl vm list.
+type vmStatus struct {
+	*vmRecord
+	InstanceStatus string
+	TokenValid     bool
+	TokenExpires   time.Time
+}
+
+// RunList is the entrypoint of the emctl vm list sub command
+func RunList(cmd *cobra.Command, flag *flags.VMList) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	records, err := listVMRecords(client, flag.MeshNamespace)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	instances, err := meshclient.New(flag.Server).V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil {
+		common.Warnf("list service instances failed: %v", err)
+		instances = nil
+	}
+
+	statuses, err := vmStatuses(client, records, instances, err == nil)
+	if err != nil {
+		common.ExitWithErrorf("%s failed: %w", cmd.Short, err)
+	}
+	printVMs(os.Stdout, statuses)
+}
+
+// RunDeregister is the entrypoint of the emctl vm deregister sub command
+func RunDeregister(cmd *cobra.Command, flag *flags.VMDeregister) {
+	name := instanceNameFromArgs(cmd)
+
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+
+	client, err := installbase.NewKubernetesClient()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancelFunc()
+	err = deregister(ctx, client, meshclient.New(flag.Server), flag.MeshNamespace, name)
+	if err != nil {
+		common.ExitWithErrorf("deregister vm %s failed: %w", name, err)
+	}
+	common.Infof("vm %s deregistered", name)
+}
+
+func instanceNameFromArgs(cmd *cobra.Command) string {
+	args := cmd.Flags().Args()
+	if len(args) != 1 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid command args: support <instance name>")
+	}
+	return args[0]
+}
+
+// deregister revokes the bootstrap token of the VM, deletes its service
+// instances from the registry, and deletes its record at last. The sidecar
+// on the VM must be stopped, or it registers the instance again.
+func deregister(ctx context.Context, client kubernetes.Interface, meshClient meshclient.MeshClient, namespace, name string) error {
+	records, err := listVMRecords(client, namespace)
+	if err != nil {
+		return err
+	}
+	var record *vmRecord
+	for _, r := range records {
+		if r.InstanceName == name {
+			record = r
+			break
+		}
+	}
+	if record == nil {
+		return common.CodeErrorf(common.ExitCodeNotFound, "vm %s not found in namespace %s", name, namespace)
+	}
+
+	err = client.CoreV1().Secrets(bootstrapTokenNamespace).Delete(context.TODO(),
+		bootstrapTokenSecretPrefix+record.TokenID, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "delete bootstrap token %s", record.TokenID)
+	}
+
+	instances, err := meshClient.V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list service instances")
+	}
+	for _, instance := range instances {
+		if !matchInstance(record, instance) {
+			continue
+		}
+		err = meshClient.V1Alpha1().ServiceInstance().Delete(ctx, instance.Spec.ServiceName, instance.Spec.InstanceID)
+		if err != nil && !meshclient.IsNotFoundError(err) {
+			return errors.Wrapf(err, "delete service instance %s", instance.Name())
+		}
+	}
+
+	return deleteVMRecord(client, namespace, name)
+}
+
+func deleteVMRecord(client kubernetes.Interface, namespace, name string) error {
+	err := client.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), vmRecordName(name), metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "delete configmap %s", vmRecordName(name))
+	}
+	return nil
+}
+
+func matchInstance(r *vmRecord, instance *resource.ServiceInstance) bool {
+	return instance.Spec != nil &&
+		instance.Spec.ServiceName == r.ServiceName &&
+		instance.Spec.Ip == r.ApplicationIP &&
+		fmt.Sprintf("%d", instance.Spec.Port) == fmt.Sprintf("%d", r.ApplicationPort)
+}
+
+func vmStatuses(client kubernetes.Interface, records []*vmRecord, instances []*resource.ServiceInstance, listed bool) ([]*vmStatus, error) {
+	statuses := []*vmStatus{}
+	for _, r := range records {
+		s := &vmStatus{vmRecord: r, InstanceStatus: statusPending}
+		if !listed {
+			s.InstanceStatus = statusUnknown
+		}
+		for _, instance := range instances {
+			if matchInstance(r, instance) {
+				s.InstanceStatus = instance.Spec.Status
+				break
+			}
+		}
+
+		expiration, found, err := tokenExpiration(client, r.TokenID)
+		if err != nil {
+			return nil, err
+		}
+		s.TokenExpires = expiration
+		s.TokenValid = found && expiration.After(time.Now())
+
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func printVMs(w io.Writer, statuses []*vmStatus) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Instance", "Service", "Address", "Status", "Token Expires", "Registered At"})
+	table.SetBorder(false)
+	for _, s := range statuses {
+		token := "expired"
+		if s.TokenValid {
+			token = s.TokenExpires.Format(time.RFC3339)
+		}
+		table.Append([]string{
+			s.InstanceName,
+			s.ServiceName,
+			fmt.Sprintf("%s:%d", s.ApplicationIP, s.ApplicationPort),
+			s.InstanceStatus,
+			token,
+			s.RegisteredAt.Format(time.RFC3339),
+		})
+	}
+	table.Render()
+}
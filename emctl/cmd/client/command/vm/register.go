@@ -0,0 +1,230 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	bootstrapFileName     = "bootstrap.yaml"
+	caCertFileName        = "ca.crt"
+	sidecarBinaryFileName = "easegress-server"
+)
+
+// bootstrapConfig is the information of the cluster packed into the
+// tarball, the token authenticates the VM to read endpoints of the control
+// plane from the API server trusted by the CA certificate.
+type bootstrapConfig struct {
+	APIServer             string `yaml:"apiServer"`
+	Namespace             string `yaml:"namespace"`
+	ControlPlaneService   string `yaml:"controlPlaneService"`
+	ServiceName           string `yaml:"serviceName"`
+	InstanceName          string `yaml:"instanceName"`
+	Token                 string `yaml:"token"`
+	TokenExpiration       string `yaml:"tokenExpiration"`
+	CACertFile            string `yaml:"caCertFile,omitempty"`
+	InsecureSkipTLSVerify bool   `yaml:"insecureSkipTLSVerify,omitempty"`
+}
+
+// RunRegister is the entrypoint of the emctl vm register sub command
+func RunRegister(cmd *cobra.Command, flag *flags.VMRegister) {
+	err := validate(flag.VMGenerate)
+	if err != nil {
+		common.ExitWithError(err)
+	}
+	if flag.TokenTTL <= 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "--token-ttl must be positive, got %s", flag.TokenTTL)
+	}
+
+	config, err := installbase.KubernetesConfig()
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeUnreachable))
+	}
+
+	if len(flag.ControlPlanePeerURLs) == 0 {
+		flag.ControlPlanePeerURLs, err = discoverPeerURLs(flag.MeshNamespace)
+		if err != nil {
+			common.ExitWithErrorf("discover peer URLs of control plane failed: %w, specify them by --control-plane-peer-urls", err)
+		}
+	}
+
+	tarball, err := register(client, config, flag)
+	if err != nil {
+		common.ExitWithErrorf("register vm %s failed: %w", flag.InstanceName, err)
+	}
+
+	path := filepath.Join(flag.OutputDir, flag.InstanceName+".tar.gz")
+	err = os.MkdirAll(flag.OutputDir, 0o755)
+	if err == nil {
+		err = ioutil.WriteFile(path, tarball, 0o600)
+	}
+	if err != nil {
+		common.ExitWithErrorf("write %s failed: %w", path, err)
+	}
+
+	common.Infof("vm %s registered into service %s, copy %s to the vm and extract it", flag.InstanceName, flag.ServiceName, path)
+	fmt.Print(nextSteps(flag.VMGenerate))
+}
+
+// register records the VM, creates its bootstrap token, and returns the
+// tarball holding everything the VM needs to join the mesh.
+func register(client kubernetes.Interface, config *rest.Config, flag *flags.VMRegister) ([]byte, error) {
+	files, err := generate(flag.VMGenerate)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newBootstrapToken(flag.TokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrap := bootstrapConfig{
+		APIServer:           config.Host,
+		Namespace:           flag.MeshNamespace,
+		ControlPlaneService: installbase.ControlPlaneHeadlessServiceName,
+		ServiceName:         flag.ServiceName,
+		InstanceName:        flag.InstanceName,
+		Token:               token.String(),
+		TokenExpiration:     token.Expiration.Format(time.RFC3339),
+	}
+
+	caCert, err := caCertificate(config)
+	if err != nil {
+		return nil, err
+	}
+	if caCert != nil {
+		bootstrap.CACertFile = caCertFileName
+		files = append(files, generatedFile{Name: caCertFileName, Content: caCert})
+	} else {
+		common.Warnf("no CA certificate found in kubeconfig, the vm can't verify the API server")
+		bootstrap.InsecureSkipTLSVerify = config.Insecure
+	}
+
+	buff, err := yaml.Marshal(bootstrap)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal bootstrap config")
+	}
+	files = append(files, generatedFile{Name: bootstrapFileName, Content: buff, Mode: 0o600})
+
+	if flag.SidecarBinary != "" {
+		binary, err := ioutil.ReadFile(flag.SidecarBinary)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read sidecar binary %s", flag.SidecarBinary)
+		}
+		files = append(files, generatedFile{Name: sidecarBinaryFileName, Content: binary, Mode: 0o755})
+	}
+
+	tarball, err := packTarball(flag.InstanceName, files)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &vmRecord{
+		InstanceName:    flag.InstanceName,
+		ServiceName:     flag.ServiceName,
+		ApplicationIP:   flag.ApplicationIP,
+		ApplicationPort: flag.ApplicationPort,
+		TokenID:         token.ID,
+		RegisteredAt:    time.Now().UTC(),
+	}
+	err = createVMRecord(client, flag.MeshNamespace, record)
+	if err != nil {
+		return nil, err
+	}
+	err = createBootstrapToken(client, flag.MeshNamespace, record, token)
+	if err != nil {
+		deleteVMRecord(client, flag.MeshNamespace, record.InstanceName)
+		return nil, err
+	}
+
+	return tarball, nil
+}
+
+func caCertificate(config *rest.Config) ([]byte, error) {
+	if len(config.CAData) != 0 {
+		return config.CAData, nil
+	}
+	if config.CAFile != "" {
+		buff, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read CA certificate %s", config.CAFile)
+		}
+		return buff, nil
+	}
+	return nil, nil
+}
+
+// packTarball packs files into a gzipped tarball under the directory.
+func packTarball(dir string, files []generatedFile) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	gw := gzip.NewWriter(buff)
+	tw := tar.NewWriter(gw)
+
+	for _, f := range files {
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		err := tw.WriteHeader(&tar.Header{
+			Name:    dir + "/" + f.Name,
+			Mode:    mode,
+			Size:    int64(len(f.Content)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "write header of %s", f.Name)
+		}
+		_, err = tw.Write(f.Content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "write %s", f.Name)
+		}
+	}
+
+	err := tw.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "close tarball")
+	}
+	err = gw.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "close tarball")
+	}
+	return buff.Bytes(), nil
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func untar(t *testing.T, tarball []byte) map[string][]byte {
+	gr, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		t.Fatalf("read gzip failed: %v", err)
+	}
+	tr := tar.NewReader(gr)
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tarball failed: %v", err)
+		}
+		buff, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("read %s failed: %v", header.Name, err)
+		}
+		files[header.Name] = buff
+	}
+	return files
+}
+
+func TestRegister(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	config := &rest.Config{
+		Host:            "https://10.0.0.1:6443",
+		TLSClientConfig: rest.TLSClientConfig{CAData: []byte("fake ca")},
+	}
+	flag := &flags.VMRegister{VMGenerate: testFlag(flags.VMFormatSystemd), TokenTTL: time.Hour}
+	validate(flag.VMGenerate)
+
+	tarball, err := register(client, config, flag)
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	files := untar(t, tarball)
+	dir := flag.InstanceName + "/"
+	for _, name := range []string{sidecarConfigFileName, sidecarUnitFileName, agentEnvFileName, caCertFileName, bootstrapFileName} {
+		if _, ok := files[dir+name]; !ok {
+			t.Fatalf("%s not found in tarball", name)
+		}
+	}
+	bootstrap := bootstrapConfig{}
+	err = yaml.Unmarshal(files[dir+bootstrapFileName], &bootstrap)
+	if err != nil {
+		t.Fatalf("unmarshal bootstrap config failed: %v", err)
+	}
+	if bootstrap.APIServer != config.Host || bootstrap.CACertFile != caCertFileName || len(bootstrap.Token) != 23 {
+		t.Fatalf("unexpected bootstrap config %+v", bootstrap)
+	}
+
+	records, err := listVMRecords(client, flag.MeshNamespace)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected 1 vm record, got %v: %v", records, err)
+	}
+	if records[0].InstanceName != flag.InstanceName || records[0].ApplicationPort != 8080 {
+		t.Fatalf("unexpected vm record %+v", records[0])
+	}
+
+	secret, err := client.CoreV1().Secrets(bootstrapTokenNamespace).Get(context.TODO(),
+		bootstrapTokenSecretPrefix+records[0].TokenID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get bootstrap token failed: %v", err)
+	}
+	if secret.Type != bootstrapTokenSecretType || secret.StringData["auth-extra-groups"] != bootstrapTokenGroup {
+		t.Fatalf("unexpected bootstrap token %+v", secret)
+	}
+	_, err = client.RbacV1().RoleBindings(flag.MeshNamespace).Get(context.TODO(), bootstrapRoleName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get role binding failed: %v", err)
+	}
+
+	_, err = register(client, config, flag)
+	if err == nil {
+		t.Fatalf("expected registering the same vm twice fails")
+	}
+}
+
+func TestVMStatuses(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	config := &rest.Config{Host: "https://10.0.0.1:6443"}
+	flag := &flags.VMRegister{VMGenerate: testFlag(flags.VMFormatCompose), TokenTTL: time.Hour}
+	validate(flag.VMGenerate)
+	_, err := register(client, config, flag)
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	records, _ := listVMRecords(client, flag.MeshNamespace)
+
+	statuses, err := vmStatuses(client, records, nil, true)
+	if err != nil {
+		t.Fatalf("get statuses failed: %v", err)
+	}
+	if statuses[0].InstanceStatus != statusPending || !statuses[0].TokenValid {
+		t.Fatalf("unexpected status %+v", statuses[0])
+	}
+
+	instance := resource.ToServiceInstance(&v1alpha1.ServiceInstance{
+		ServiceName: "order-service",
+		InstanceID:  "order-service-10-0-0-8",
+		Ip:          "10.0.0.8",
+		Port:        8080,
+		Status:      "UP",
+	})
+	statuses, err = vmStatuses(client, records, []*resource.ServiceInstance{instance}, true)
+	if err != nil {
+		t.Fatalf("get statuses failed: %v", err)
+	}
+	if statuses[0].InstanceStatus != "UP" {
+		t.Fatalf("expected status UP, got %s", statuses[0].InstanceStatus)
+	}
+
+	err = deleteVMRecord(client, flag.MeshNamespace, flag.InstanceName)
+	if err != nil {
+		t.Fatalf("delete vm record failed: %v", err)
+	}
+	records, _ = listVMRecords(client, flag.MeshNamespace)
+	if len(records) != 0 {
+		t.Fatalf("expected no vm record, got %d", len(records))
+	}
+}
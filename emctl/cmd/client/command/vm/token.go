@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vm
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strconv"
+	"time"
+
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Bootstrap tokens are authenticated by the API server, see
+	// https://kubernetes.io/docs/reference/access-authn-authz/bootstrap-tokens/
+	bootstrapTokenNamespace    = "kube-system"
+	bootstrapTokenSecretPrefix = "bootstrap-token-"
+	bootstrapTokenSecretType   = v1.SecretType("bootstrap.kubernetes.io/token")
+	bootstrapTokenGroup        = "system:bootstrappers:easemesh-vm"
+	bootstrapTokenChars        = "abcdefghijklmnopqrstuvwxyz0123456789"
+	bootstrapTokenIDLen        = 6
+	bootstrapTokenSecretLen    = 16
+
+	// bootstrapRoleName grants bootstrap tokens of VMs to read endpoints
+	// of the control plane, so VMs could follow its membership.
+	bootstrapRoleName = "easemesh-vm-bootstrap"
+
+	// A VM is recorded in a ConfigMap of the mesh namespace, which outlives
+	// its bootstrap token deleted by the API server after expiration.
+	vmRecordPrefix       = "easemesh-vm-"
+	vmAppLabelValue      = "easemesh-vm"
+	vmInstanceLabel      = "mesh.megaease.com/vm-instance"
+	vmServiceLabel       = "mesh.megaease.com/vm-service"
+	vmRecordIPKey        = "application-ip"
+	vmRecordPortKey      = "application-port"
+	vmRecordTokenIDKey   = "token-id"
+	vmRecordRegisteredAt = "registered-at"
+)
+
+type (
+	// vmRecord is a VM registered into the mesh.
+	vmRecord struct {
+		InstanceName    string
+		ServiceName     string
+		ApplicationIP   string
+		ApplicationPort int
+		TokenID         string
+		RegisteredAt    time.Time
+	}
+
+	// bootstrapToken is a token in the form of id.secret.
+	bootstrapToken struct {
+		ID         string
+		Secret     string
+		Expiration time.Time
+	}
+)
+
+func (t *bootstrapToken) String() string {
+	return t.ID + "." + t.Secret
+}
+
+func newBootstrapToken(ttl time.Duration) (*bootstrapToken, error) {
+	id, err := randomString(bootstrapTokenIDLen)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomString(bootstrapTokenSecretLen)
+	if err != nil {
+		return nil, err
+	}
+	return &bootstrapToken{ID: id, Secret: secret, Expiration: time.Now().Add(ttl).UTC()}, nil
+}
+
+func randomString(n int) (string, error) {
+	buff := make([]byte, n)
+	max := big.NewInt(int64(len(bootstrapTokenChars)))
+	for i := range buff {
+		c, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", errors.Wrap(err, "generate random token")
+		}
+		buff[i] = bootstrapTokenChars[c.Int64()]
+	}
+	return string(buff), nil
+}
+
+func vmRecordName(instanceName string) string {
+	return vmRecordPrefix + instanceName
+}
+
+func vmLabels(r *vmRecord) map[string]string {
+	return map[string]string{
+		"app":           vmAppLabelValue,
+		vmInstanceLabel: r.InstanceName,
+		vmServiceLabel:  r.ServiceName,
+	}
+}
+
+// createBootstrapToken creates the bootstrap token of the VM, and grants
+// tokens of VMs to read endpoints of the control plane.
+func createBootstrapToken(client kubernetes.Interface, namespace string, r *vmRecord, token *bootstrapToken) error {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrapTokenSecretPrefix + token.ID,
+			Namespace: bootstrapTokenNamespace,
+			Labels:    vmLabels(r),
+		},
+		Type: bootstrapTokenSecretType,
+		StringData: map[string]string{
+			"description":                    "EaseMesh VM " + r.InstanceName + " of service " + r.ServiceName,
+			"token-id":                       token.ID,
+			"token-secret":                   token.Secret,
+			"expiration":                     token.Expiration.Format(time.RFC3339),
+			"usage-bootstrap-authentication": "true",
+			"auth-extra-groups":              bootstrapTokenGroup,
+		},
+	}
+	_, err := client.CoreV1().Secrets(bootstrapTokenNamespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "create bootstrap token secret %s", secret.Name)
+	}
+
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: bootstrapRoleName, Namespace: namespace},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"endpoints", "services"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+		},
+	}
+	err = installbase.DeployRole(role, client, namespace)
+	if err != nil {
+		return errors.Wrapf(err, "deploy role %s", bootstrapRoleName)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: bootstrapRoleName, Namespace: namespace},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     bootstrapRoleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.GroupKind,
+				Name:     bootstrapTokenGroup,
+			},
+		},
+	}
+	err = installbase.DeployRoleBinding(roleBinding, client, namespace)
+	if err != nil {
+		return errors.Wrapf(err, "deploy role binding %s", bootstrapRoleName)
+	}
+	return nil
+}
+
+func createVMRecord(client kubernetes.Interface, namespace string, r *vmRecord) error {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vmRecordName(r.InstanceName),
+			Namespace: namespace,
+			Labels:    vmLabels(r),
+		},
+		Data: map[string]string{
+			vmRecordIPKey:        r.ApplicationIP,
+			vmRecordPortKey:      strconv.Itoa(r.ApplicationPort),
+			vmRecordTokenIDKey:   r.TokenID,
+			vmRecordRegisteredAt: r.RegisteredAt.Format(time.RFC3339),
+		},
+	}
+	_, err := client.CoreV1().ConfigMaps(namespace).Create(context.TODO(), configMap, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		return errors.Errorf("vm %s is registered already, deregister it first", r.InstanceName)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "create configmap %s", configMap.Name)
+	}
+	return nil
+}
+
+func listVMRecords(client kubernetes.Interface, namespace string) ([]*vmRecord, error) {
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "app=" + vmAppLabelValue})
+	if err != nil {
+		return nil, errors.Wrap(err, "list configmaps of vms")
+	}
+
+	records := []*vmRecord{}
+	for _, cm := range configMaps.Items {
+		port, _ := strconv.Atoi(cm.Data[vmRecordPortKey])
+		registeredAt, _ := time.Parse(time.RFC3339, cm.Data[vmRecordRegisteredAt])
+		records = append(records, &vmRecord{
+			InstanceName:    cm.Labels[vmInstanceLabel],
+			ServiceName:     cm.Labels[vmServiceLabel],
+			ApplicationIP:   cm.Data[vmRecordIPKey],
+			ApplicationPort: port,
+			TokenID:         cm.Data[vmRecordTokenIDKey],
+			RegisteredAt:    registeredAt,
+		})
+	}
+	return records, nil
+}
+
+// tokenExpiration returns the expiration of the bootstrap token, the token
+// is deleted by the API server after expiration.
+func tokenExpiration(client kubernetes.Interface, tokenID string) (time.Time, bool, error) {
+	secret, err := client.CoreV1().Secrets(bootstrapTokenNamespace).Get(context.TODO(),
+		bootstrapTokenSecretPrefix+tokenID, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "get bootstrap token %s", tokenID)
+	}
+
+	value := string(secret.Data["expiration"])
+	if value == "" {
+		value = secret.StringData["expiration"]
+	}
+	expiration, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, errors.Wrapf(err, "parse expiration of bootstrap token %s", tokenID)
+	}
+	return expiration, true, nil
+}
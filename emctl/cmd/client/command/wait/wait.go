@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package wait waits for conditions of mesh resources reported by the
+// control plane, so deployment pipelines could gate on the state of the mesh.
+package wait
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type (
+	// Condition is what to wait for, either a condition of the status or the deletion.
+	Condition struct {
+		Delete bool
+		Type   string
+		Status string
+	}
+
+	// Target is a resource to wait for.
+	Target struct {
+		Kind string
+		Name string
+	}
+
+	// statusFunc gets the status of the resource with its latest revision.
+	statusFunc func(ctx context.Context, target *Target) (*resource.ResourceStatus, string, error)
+)
+
+// ParseCondition parses the condition in the form of condition=<type>[=<status>] or delete,
+// the status is True by default.
+func ParseCondition(s string) (*Condition, error) {
+	if strings.EqualFold(s, "delete") {
+		return &Condition{Delete: true}, nil
+	}
+
+	parts := strings.SplitN(s, "=", 3)
+	if len(parts) < 2 || !strings.EqualFold(parts[0], "condition") || parts[1] == "" {
+		return nil, errors.Errorf("invalid --for %q, want condition=<type>[=<status>] or delete", s)
+	}
+
+	c := &Condition{Type: parts[1], Status: resource.ConditionTrue}
+	if len(parts) == 3 {
+		switch {
+		case strings.EqualFold(parts[2], resource.ConditionTrue):
+			c.Status = resource.ConditionTrue
+		case strings.EqualFold(parts[2], resource.ConditionFalse):
+			c.Status = resource.ConditionFalse
+		case strings.EqualFold(parts[2], resource.ConditionUnknown):
+			c.Status = resource.ConditionUnknown
+		default:
+			return nil, errors.Errorf("invalid status %q of --for (support %s, %s, %s)", parts[2],
+				resource.ConditionTrue, resource.ConditionFalse, resource.ConditionUnknown)
+		}
+	}
+	return c, nil
+}
+
+func (c *Condition) String() string {
+	if c.Delete {
+		return "delete"
+	}
+	return fmt.Sprintf("condition=%s=%s", c.Type, c.Status)
+}
+
+// observe reports whether the condition is met by the status got by the latest
+// revision of the resource, with the description of the observation.
+func (c *Condition) observe(status *resource.ResourceStatus, latestRevision string, err error) (bool, string) {
+	if c.Delete {
+		switch {
+		case meshclient.IsNotFoundError(err):
+			return true, "deleted"
+		case err != nil:
+			return false, err.Error()
+		}
+		return false, "still exists"
+	}
+
+	switch {
+	case meshclient.IsNotFoundError(err):
+		return false, "not found"
+	case err != nil:
+		return false, err.Error()
+	}
+
+	// NOTE: Conditions of a stale revision don't tell about the resource just applied.
+	observed := strconv.FormatInt(status.ObservedRevision, 10)
+	if status.ObservedRevision != 0 && latestRevision != "" && observed != latestRevision {
+		return false, fmt.Sprintf("status is observed for revision %s, the latest is %s", observed, latestRevision)
+	}
+
+	condition := status.Condition(c.Type)
+	if condition == nil {
+		return false, fmt.Sprintf("condition %s is not reported yet", c.Type)
+	}
+	if strings.EqualFold(condition.Status, c.Status) {
+		return true, "condition met"
+	}
+
+	observation := fmt.Sprintf("condition %s is %s", condition.Type, condition.Status)
+	if condition.Reason != "" {
+		observation += ", reason: " + condition.Reason
+	}
+	if condition.Message != "" {
+		observation += ", message: " + condition.Message
+	}
+	return false, observation
+}
+
+// ParseTargets parses resources in the form of <kind>/<name>..., or <kind> <name>.
+func ParseTargets(client meshclient.MeshClient, args []string, timeout time.Duration) ([]*Target, error) {
+	if len(args) == 2 && !strings.Contains(args[0], "/") && !strings.Contains(args[1], "/") {
+		args = []string{args[0] + "/" + args[1]}
+	}
+	if len(args) == 0 {
+		return nil, errors.New("no resource specified, support <kind>/<name>... or <kind> <name>")
+	}
+
+	targets := []*Target{}
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid resource %q, support <kind>/<name>... or <kind> <name>", arg)
+		}
+		kind, err := util.ResolveCommandKind(client, parts[0], timeout)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, &Target{Kind: kind, Name: parts[1]})
+	}
+	return targets, nil
+}
+
+// Wait polls the status of the target every interval until the condition is met,
+// it fails with the last observation if the context is done before.
+func Wait(ctx context.Context, get statusFunc, target *Target, condition *Condition, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, revision, err := get(ctx, target)
+		met, observation := condition.observe(status, revision, err)
+		if met {
+			return nil
+		}
+		common.Debugf("waiting for %s on %s/%s: %s", condition, target.Kind, target.Name, observation)
+
+		select {
+		case <-ctx.Done():
+			return common.WithCode(errors.Errorf("timed out waiting for %s on %s/%s: %s",
+				condition, target.Kind, target.Name, observation), common.ExitCodeTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusOf gets statuses by the client, the latest revision is only got for conditions.
+func statusOf(client meshclient.MeshClient, condition *Condition) statusFunc {
+	return func(ctx context.Context, target *Target) (*resource.ResourceStatus, string, error) {
+		status, err := client.V1Alpha1().Status().Get(ctx, target.Kind, target.Name)
+		if err != nil || condition.Delete {
+			return status, "", err
+		}
+		revision, err := meshclient.ResourceVersion(ctx, client, target.Kind, target.Name)
+		return status, revision, err
+	}
+}
+
+// Run is the entrypoint of the emctl wait command
+func Run(cmd *cobra.Command, flag *flags.Wait) {
+	if flag.Server == "" {
+		flag.Server = flags.GetServerAddress()
+	}
+	if flag.For == "" {
+		common.ExitWithCodef(common.ExitCodeValidation, "--for is required, e.g. --for=condition=Ready or --for=delete")
+	}
+	if flag.Interval <= 0 {
+		common.ExitWithCodef(common.ExitCodeValidation, "invalid --interval %s, want a positive duration", flag.Interval)
+	}
+
+	condition, err := ParseCondition(flag.For)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	client := meshclient.New(flag.Server)
+	targets, err := ParseTargets(client, cmd.Flags().Args(), flag.Timeout)
+	if err != nil {
+		common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+	}
+
+	// NOTE: All resources share the timeout like kubectl wait.
+	ctx, cancel := context.WithTimeout(context.Background(), flag.Timeout)
+	defer cancel()
+
+	get := statusOf(client, condition)
+	for _, target := range targets {
+		err := Wait(ctx, get, target, condition, flag.Interval)
+		if err != nil {
+			common.ExitWithError(err)
+		}
+		if condition.Delete {
+			fmt.Printf("%s/%s deleted\n", target.Kind, target.Name)
+		} else {
+			fmt.Printf("%s/%s condition met\n", target.Kind, target.Name)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wait
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseCondition(t *testing.T) {
+	cases := map[string]*Condition{
+		"delete":                  {Delete: true},
+		"condition=Active":        {Type: "Active", Status: resource.ConditionTrue},
+		"condition=Ready=false":   {Type: "Ready", Status: resource.ConditionFalse},
+		"Condition=Ready=Unknown": {Type: "Ready", Status: resource.ConditionUnknown},
+	}
+	for s, expected := range cases {
+		c, err := ParseCondition(s)
+		if err != nil || *c != *expected {
+			t.Errorf("parse %q: expected %+v, got %+v, %v", s, expected, c, err)
+		}
+	}
+
+	for _, s := range []string{"Active", "condition", "condition=", "condition=Ready=yes", "phase=Ready"} {
+		if _, err := ParseCondition(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	active := &Condition{Type: resource.ConditionActive, Status: resource.ConditionTrue}
+	status := &resource.ResourceStatus{
+		ObservedRevision: 3,
+		Conditions: []*resource.Condition{
+			{Type: "Active", Status: resource.ConditionFalse, Reason: "NoInstances", Message: "no instances labeled"},
+		},
+	}
+
+	cases := []struct {
+		condition   *Condition
+		status      *resource.ResourceStatus
+		revision    string
+		err         error
+		met         bool
+		observation string
+	}{
+		{active, nil, "", errors.Wrap(meshclient.NotFoundError, "get"), false, "not found"},
+		{active, nil, "", errors.New("connection refused"), false, "connection refused"},
+		{active, status, "4", nil, false, "status is observed for revision 3, the latest is 4"},
+		{active, status, "3", nil, false, "condition Active is False, reason: NoInstances, message: no instances labeled"},
+		{&Condition{Type: "active", Status: resource.ConditionFalse}, status, "3", nil, true, "condition met"},
+		{&Condition{Type: resource.ConditionReady, Status: resource.ConditionTrue}, status, "", nil, false, "condition Ready is not reported yet"},
+		{&Condition{Delete: true}, status, "", nil, false, "still exists"},
+		{&Condition{Delete: true}, nil, "", errors.Wrap(meshclient.NotFoundError, "get"), true, "deleted"},
+	}
+	for i, c := range cases {
+		met, observation := c.condition.observe(c.status, c.revision, c.err)
+		if met != c.met || observation != c.observation {
+			t.Errorf("case %d: expected %v %q, got %v %q", i, c.met, c.observation, met, observation)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	calls := 0
+	get := func(ctx context.Context, target *Target) (*resource.ResourceStatus, string, error) {
+		calls++
+		status := resource.ConditionFalse
+		if calls >= 3 {
+			status = resource.ConditionTrue
+		}
+		return &resource.ResourceStatus{
+			Conditions: []*resource.Condition{{Type: resource.ConditionActive, Status: status}},
+		}, "", nil
+	}
+
+	target := &Target{Kind: resource.KindServiceCanary, Name: "pet-beta"}
+	active := &Condition{Type: resource.ConditionActive, Status: resource.ConditionTrue}
+	err := Wait(context.Background(), get, target, active, time.Millisecond)
+	if err != nil || calls != 3 {
+		t.Fatalf("expected condition met at the 3rd poll, got %d polls, %v", calls, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = Wait(ctx, get, target, &Condition{Delete: true}, time.Millisecond)
+	if err == nil || common.ExitCodeOf(err) != common.ExitCodeTimeout ||
+		!strings.Contains(err.Error(), "timed out waiting for delete on ServiceCanary/pet-beta: still exists") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
@@ -21,7 +21,14 @@ import (
 	"os"
 
 	"github.com/megaease/easemeshctl/cmd/client/command"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/client/command/plugin"
+	"github.com/megaease/easemeshctl/cmd/client/command/usagereport"
+	"github.com/megaease/easemeshctl/cmd/client/command/versioncheck"
 	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
 
 	"github.com/spf13/cobra"
 )
@@ -32,7 +39,68 @@ func init() {
 
 var exampleUsage = ` # EaseMesh command line tool for management and operation
 # Install EaseMesh Components
-emctl install --clean-when-failed
+emctl install
+
+# Verify the installation end to end with sample apps
+emctl verify-install
+
+# Install the takeaway demo to see the mesh working, and uninstall it
+emctl demo install
+emctl demo uninstall
+
+# Report changes required before upgrading the EaseMesh
+emctl check upgrade-compat
+
+# Report resources referencing missing tenants, services or ingresses
+emctl check refs
+
+# List pods grouped by sidecar versions, and upgrade sidecars of a namespace in batches
+emctl sidecar versions
+emctl sidecar upgrade --namespace shop --max-unavailable 20%
+
+# Output the effective configuration of the sidecar in a pod
+emctl sidecar config-dump orders-7d9c5b6f4-kx2lp --namespace shop
+
+# Show sidecars out of sync with the control plane
+emctl proxy-status
+
+# Expose the admin API of the control plane on 127.0.0.1:2381
+emctl proxy
+
+# Scale the control plane to 5 members
+emctl scale control-plane --replicas 5
+
+# Compact and defragment the storage of the control plane
+emctl maintenance run
+
+# Make sidecars and ingresses return 503 for a service during an incident
+emctl maintenance enable --service payments --response 503 --retry-after 120
+
+# Expand the persistent volumes of the control plane
+emctl control-plane resize-storage --capacity 20Gi
+
+# Generate the sidecar configuration of a VM workload
+emctl vm generate --service-name order-service --application-ip 10.0.0.8 --application-port 8080
+
+# Register a VM workload with a bootstrap token, list and deregister VMs
+emctl vm register --service-name order-service --application-ip 10.0.0.8 --application-port 8080
+emctl vm list
+emctl vm deregister order-service-10-0-0-8
+
+# Output the call graph of mesh services
+emctl graph | dot -Tsvg > mesh.svg
+
+# Show burn rates and remaining error budgets of SLOs
+emctl slo status
+
+# Export alert rules as a PrometheusRule
+emctl alert export | kubectl apply -f -
+
+# Export Gatekeeper constraints enforcing tenants and approved sidecar images
+emctl policy export-gatekeeper | kubectl apply -f -
+
+# Dry-run a sample request against the match expression of a ServiceCanary
+emctl canary test-match delivery-mesh-beijing --header X-Location=Beijing --cookie uid=1024
 
 # Apply Tenant (kind is case-insensitive in command line)
 emctl apply -f tenant-001.yaml
@@ -46,6 +114,25 @@ emctl apply -f loadbalance.yaml
 # Apply Ingress
 emctl apply -f ingress.yaml
 
+# Change heartbeat interval of the mesh controller with live reload
+emctl mesh-config patch -p '{"heartbeatInterval": "10s"}'
+
+# Set default policies of tenant inherited by its services
+emctl tenant policy set tenant-001 -f tenant-policy.yaml
+
+# Wait for a service canary to take effect on traffic
+emctl wait servicecanary/pet-beta --for=condition=Active --timeout=5m
+
+# Check resources in files without any cluster, e.g. in CI
+emctl lint -f ./mesh -o sarif
+
+# Show versions of emctl, the control plane and the operator
+emctl version -o json
+
+# Report anonymous usage of emctl to a collector, opt in and out
+emctl telemetry on --endpoint https://telemetry.example.com/emctl
+emctl telemetry off
+
 # Get service.
 emctl get service
 emctl get service -o yaml
@@ -55,11 +142,37 @@ emctl get service service-001 -o json
 emctl get loadbalance
 emctl get loadbalance service-001 -o yaml
 
+# Show details of service with its instances, canaries, errors and ingresses
+emctl describe service service-001
+
+# Scaffold a Service with defaults, or create it directly
+emctl create service orders --tenant shop --port 8080 --protocol http --dry-run -o yaml
+emctl create servicecanary orders-canary --service orders
+
+# Edit ServiceCanary with the editor, retried on conflicts of modifications
+emctl edit servicecanary canary-001
+
+# Show revisions of LoadBalance and rollback to the previous one
+emctl history loadbalance service-001
+emctl rollback loadbalance service-001
+
+# Create an access token for the team of tenant shop
+emctl auth create-token --tenant shop --role editor --ttl 720h
+
+# List audit records of mutations in the last 24 hours
+emctl audit list --since 24h
+
+# Output canary events of the last hour, and watch new events
+emctl events --since 1h --type canary
+emctl events -w
 
 # Delete service
 emctl delete service service-001
 emctl delete service -f service-001.yaml
 
+# Delete tenant with services, canaries and ingress rules referencing it
+emctl delete tenant shop --cascade
+
 # Delete LoadBalance
 emctl delete loadbalance service-001
 
@@ -70,13 +183,37 @@ emctl delete loadbalance service-001
 # - ObservabilityMetrics, ObservabilityTracings, ObservabilityOutputServer`
 
 func main() {
+	logging := &flags.Logging{}
+	cache := &flags.Cache{}
+	kubernetes := &flags.Kubernetes{}
+	versionCheck := &flags.VersionCheck{}
 	rootCmd := &cobra.Command{
 		Use:        "emctl",
 		Short:      "A command line tool for EaseMesh management and operation",
 		Example:    exampleUsage,
 		SuggestFor: []string{"emctl"},
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			err := logging.Setup()
+			if err != nil {
+				common.ExitWithError(common.WithCode(err, common.ExitCodeValidation))
+			}
+			client.SetDefaultHeader(meshclient.AuditIdentityHeader, flags.GetIdentity())
+			if token := flags.GetToken(); token != "" {
+				client.SetDefaultHeader(meshclient.AuthorizationHeader, "Bearer "+token)
+			}
+			meshclient.SetDiscoveryCache(cache.Dir, cache.TTL)
+			installbase.SetKubernetesConfig(kubernetes.Kubeconfig, kubernetes.Context)
+			usagereport.Start(cmd)
+			versioncheck.SetCache(cache.Dir, cache.TTL)
+			versioncheck.Check(cmd, versionCheck.Skip)
+		},
 	}
 
+	logging.AttachCmd(rootCmd)
+	cache.AttachCmd(rootCmd)
+	kubernetes.AttachCmd(rootCmd)
+	versionCheck.AttachCmd(rootCmd)
+
 	completionCmd := &cobra.Command{
 		Use:   "completion bash|zsh",
 		Short: "Output shell completion code for the specified shell (bash or zsh)",
@@ -87,7 +224,7 @@ func main() {
 			case "zsh":
 				rootCmd.GenZshCompletion(os.Stdout)
 			default:
-				common.ExitWithErrorf("unsupported shell %s, expecting bash or zsh", args[0])
+				common.ExitWithCodef(common.ExitCodeValidation, "unsupported shell %s, expecting bash or zsh", args[0])
 			}
 		},
 		Args: cobra.ExactArgs(1),
@@ -99,11 +236,47 @@ func main() {
 		command.ApplyCmd(),
 		command.DeleteCmd(),
 		command.GetCmd(),
+		command.DescribeCmd(),
+		command.EditCmd(),
+		command.CreateCmd(),
+		command.TenantCmd(),
+		command.HistoryCmd(),
+		command.RollbackCmd(),
+		command.AuditCmd(),
+		command.AuthCmd(),
+		command.EventsCmd(),
+		command.MigrateCmd(),
+		command.GitOpsCmd(),
+		command.VerifyInstallCmd(),
+		command.DemoCmd(),
+		command.CheckCmd(),
+		command.SidecarCmd(),
+		command.MeshConfigCmd(),
+		command.ScaleCmd(),
+		command.MaintenanceCmd(),
+		command.ControlPlaneCmd(),
+		command.VMCmd(),
+		command.GraphCmd(),
+		command.SLOCmd(),
+		command.AlertCmd(),
+		command.CanaryCmd(),
+		command.PolicyCmd(),
+		command.ProxyCmd(),
+		command.ProxyStatusCmd(),
+		command.IngressCmd(),
+		command.PluginCmd(),
+		command.TelemetryCmd(),
+		command.VersionCmd(),
+		command.LintCmd(),
+		command.WaitCmd(),
 		completionCmd,
 	)
 
-	err := rootCmd.Execute()
-	if err != nil {
-		common.ExitWithError(err)
+	// NOTE: kubectl runs the binary kubectl-mesh as the plugin kubectl mesh.
+	if plugin.IsPlugin(os.Args[0]) {
+		plugin.AdaptCommand(rootCmd)
 	}
+
+	// NOTE: Exit by ExitWithError even if succeeded, so exit hooks run.
+	common.ExitWithError(rootCmd.Execute())
 }
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenRoleViewer reads all resources of the mesh.
+	TokenRoleViewer = "viewer"
+	// TokenRoleEditor also writes resources of its tenant.
+	TokenRoleEditor = "editor"
+	// TokenRoleAdmin also manages access tokens of its tenant.
+	TokenRoleAdmin = "admin"
+)
+
+type (
+	// AccessTokenRequest is the request creating an access token.
+	AccessTokenRequest struct {
+		// Tenant scopes the token, empty means the whole mesh.
+		Tenant string `yaml:"tenant,omitempty" json:"tenant,omitempty"`
+		Role   string `yaml:"role" json:"role"`
+		// TTL is the lifetime of the token like 720h, empty means never expiring.
+		TTL         string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+		Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	}
+
+	// AccessToken is an access token of the admin API of the control plane,
+	// the secret Token is only returned by the creation.
+	AccessToken struct {
+		ID          string `yaml:"id" json:"id"`
+		Token       string `yaml:"token,omitempty" json:"token,omitempty"`
+		Tenant      string `yaml:"tenant,omitempty" json:"tenant,omitempty"`
+		Role        string `yaml:"role" json:"role"`
+		Description string `yaml:"description,omitempty" json:"description,omitempty"`
+		CreatedBy   string `yaml:"createdBy,omitempty" json:"createdBy,omitempty"`
+		// CreatedAt and ExpiresAt are in RFC3339, empty ExpiresAt means never expiring.
+		CreatedAt string `yaml:"createdAt" json:"createdAt"`
+		ExpiresAt string `yaml:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	}
+)
+
+// Validate validates the request before it's sent.
+func (r *AccessTokenRequest) Validate() error {
+	switch r.Role {
+	case TokenRoleViewer, TokenRoleEditor, TokenRoleAdmin:
+	default:
+		return errors.Errorf("unsupported role %q (support %s, %s, %s)",
+			r.Role, TokenRoleViewer, TokenRoleEditor, TokenRoleAdmin)
+	}
+
+	if r.TTL != "" {
+		ttl, err := time.ParseDuration(r.TTL)
+		if err != nil {
+			return errors.Wrapf(err, "invalid ttl %s", r.TTL)
+		}
+		if ttl <= 0 {
+			return errors.Errorf("ttl must be positive, got %s", r.TTL)
+		}
+	}
+
+	return nil
+}
+
+// Expired reports whether the token has expired at now.
+func (t *AccessToken) Expired(now time.Time) bool {
+	if t.ExpiresAt == "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, t.ExpiresAt)
+	return err != nil || !now.Before(expiresAt)
+}
+
+// CanWrite reports whether the token could write a resource of the tenant,
+// the tenant is empty for mesh-wide resources like ingresses, which are
+// only written by mesh-wide tokens.
+func (t *AccessToken) CanWrite(tenant string) bool {
+	if t.Role != TokenRoleEditor && t.Role != TokenRoleAdmin {
+		return false
+	}
+	return t.Tenant == "" || t.Tenant == tenant
+}
+
+// CanGrant reports whether the token could create the token of the request,
+// which is never broader than itself.
+func (t *AccessToken) CanGrant(r *AccessTokenRequest) bool {
+	if t.Role != TokenRoleAdmin {
+		return false
+	}
+	return t.Tenant == "" || t.Tenant == r.Tenant
+}
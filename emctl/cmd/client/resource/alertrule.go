@@ -0,0 +1,288 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AlertMetricLatency alerts on the latency of requests at a percentile.
+	AlertMetricLatency = "latency"
+	// AlertMetricErrorRate alerts on the percentage of failed requests.
+	AlertMetricErrorRate = "errorRate"
+	// AlertMetricInstanceCount alerts on the count of instances in service.
+	AlertMetricInstanceCount = "instanceCount"
+
+	// AlertSeverityInfo is the info severity of alerts.
+	AlertSeverityInfo = "info"
+	// AlertSeverityWarning is the warning severity of alerts, which is the default.
+	AlertSeverityWarning = "warning"
+	// AlertSeverityCritical is the critical severity of alerts.
+	AlertSeverityCritical = "critical"
+
+	// DefaultAlertPercentile is the default percentile of latency alerts.
+	DefaultAlertPercentile = 99
+)
+
+// AlertPercentiles are the percentiles of latencies reported by sidecars.
+var AlertPercentiles = []float64{50, 75, 95, 98, 99, 99.9}
+
+type (
+	// AlertRule describes a condition of metrics of a service, or of every
+	// service of a tenant, which fires an alert when it holds.
+	AlertRule struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *AlertRuleSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// AlertRuleSpec describes the scope and the condition of an alert rule,
+	// exactly one of latency, errorRate and instanceCount is required.
+	AlertRuleSpec struct {
+		// Service or Tenant is the scope of the rule, a rule of the tenant
+		// applies to every service of the tenant.
+		Service string `yaml:"service,omitempty" json:"service,omitempty" jsonschema:"omitempty"`
+		Tenant  string `yaml:"tenant,omitempty" json:"tenant,omitempty" jsonschema:"omitempty"`
+
+		Latency       *AlertLatency       `yaml:"latency,omitempty" json:"latency,omitempty" jsonschema:"omitempty"`
+		ErrorRate     *AlertErrorRate     `yaml:"errorRate,omitempty" json:"errorRate,omitempty" jsonschema:"omitempty"`
+		InstanceCount *AlertInstanceCount `yaml:"instanceCount,omitempty" json:"instanceCount,omitempty" jsonschema:"omitempty"`
+
+		// For is how long the condition holds before the alert fires, zero means at once.
+		For string `yaml:"for,omitempty" json:"for,omitempty" jsonschema:"omitempty,format=duration"`
+		// Severity is one of info, warning and critical, default is warning.
+		Severity string `yaml:"severity,omitempty" json:"severity,omitempty" jsonschema:"omitempty,enum=info,enum=warning,enum=critical"`
+		// Webhook is the URL of the receiver which alerts are pushed to.
+		Webhook string `yaml:"webhook,omitempty" json:"webhook,omitempty" jsonschema:"omitempty,format=uri"`
+	}
+
+	// AlertLatency fires when the latency at the percentile exceeds the threshold.
+	AlertLatency struct {
+		// Percentile is one of 50, 75, 95, 98, 99 and 99.9, default is 99.
+		Percentile float64 `yaml:"percentile,omitempty" json:"percentile,omitempty" jsonschema:"omitempty"`
+		Threshold  string  `yaml:"threshold" json:"threshold" jsonschema:"required,format=duration"`
+	}
+
+	// AlertErrorRate fires when the percentage of failed requests exceeds the threshold.
+	AlertErrorRate struct {
+		// Threshold is the percentage, e.g. 5
+		Threshold float64 `yaml:"threshold" json:"threshold" jsonschema:"required,exclusiveMinimum=0,maximum=100"`
+	}
+
+	// AlertInstanceCount fires when instances in service are fewer than the minimum.
+	AlertInstanceCount struct {
+		Min int `yaml:"min" json:"min" jsonschema:"required,minimum=1"`
+	}
+
+	// AlertRuleObject is the alert rule object stored in the control plane of the EaseMesh
+	AlertRuleObject struct {
+		Name string `json:"name"`
+		*AlertRuleSpec
+	}
+)
+
+var _ meta.TableObject = &AlertRule{}
+
+// Columns returns the columns of AlertRule.
+func (a *AlertRule) Columns() []*meta.TableColumn {
+	if a.Spec == nil {
+		return nil
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Scope",
+			Value: a.Spec.Scope(),
+		},
+		{
+			Name:  "Condition",
+			Value: a.Spec.Condition(),
+		},
+		{
+			Name:  "For",
+			Value: a.Spec.ForDuration().String(),
+		},
+		{
+			Name:  "Severity",
+			Value: a.Spec.SeverityOrDefault(),
+		},
+	}
+}
+
+// Validate validates the AlertRule before it's applied.
+func (a *AlertRule) Validate() error {
+	if a.Spec == nil {
+		return nil
+	}
+	if (a.Spec.Service == "") == (a.Spec.Tenant == "") {
+		return errors.New("exactly one of service and tenant is required")
+	}
+
+	conditions := 0
+	if a.Spec.Latency != nil {
+		conditions++
+		if _, err := a.Spec.Latency.ThresholdDuration(); err != nil {
+			return errors.Wrap(err, "latency")
+		}
+		if !validAlertPercentile(a.Spec.Latency.PercentileOrDefault()) {
+			return errors.Errorf("latency: percentile %g must be one of %v", a.Spec.Latency.Percentile, AlertPercentiles)
+		}
+	}
+	if a.Spec.ErrorRate != nil {
+		conditions++
+		if a.Spec.ErrorRate.Threshold <= 0 || a.Spec.ErrorRate.Threshold > 100 {
+			return errors.Errorf("errorRate: threshold %g must be a percentage in (0, 100]", a.Spec.ErrorRate.Threshold)
+		}
+	}
+	if a.Spec.InstanceCount != nil {
+		conditions++
+		if a.Spec.InstanceCount.Min < 1 {
+			return errors.Errorf("instanceCount: min %d must be positive", a.Spec.InstanceCount.Min)
+		}
+	}
+	if conditions != 1 {
+		return errors.New("exactly one of latency, errorRate and instanceCount is required")
+	}
+
+	if a.Spec.For != "" {
+		d, err := time.ParseDuration(a.Spec.For)
+		if err != nil || d < 0 {
+			return errors.Errorf("invalid for %q, it must be a duration like 5m", a.Spec.For)
+		}
+	}
+	switch a.Spec.Severity {
+	case "", AlertSeverityInfo, AlertSeverityWarning, AlertSeverityCritical:
+	default:
+		return errors.Errorf("unknown severity %s (support %s, %s, %s)", a.Spec.Severity,
+			AlertSeverityInfo, AlertSeverityWarning, AlertSeverityCritical)
+	}
+	if a.Spec.Webhook != "" {
+		u, err := url.Parse(a.Spec.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("invalid webhook %q, it must be an http or https URL", a.Spec.Webhook)
+		}
+	}
+
+	return nil
+}
+
+func validAlertPercentile(percentile float64) bool {
+	for _, p := range AlertPercentiles {
+		if p == percentile {
+			return true
+		}
+	}
+	return false
+}
+
+// Metric returns the metric which the rule alerts on.
+func (s *AlertRuleSpec) Metric() string {
+	switch {
+	case s.Latency != nil:
+		return AlertMetricLatency
+	case s.ErrorRate != nil:
+		return AlertMetricErrorRate
+	case s.InstanceCount != nil:
+		return AlertMetricInstanceCount
+	default:
+		return ""
+	}
+}
+
+// Scope returns the service or the tenant of the rule.
+func (s *AlertRuleSpec) Scope() string {
+	if s.Tenant != "" {
+		return "tenant " + s.Tenant
+	}
+	return "service " + s.Service
+}
+
+// Condition returns the readable condition of the rule.
+func (s *AlertRuleSpec) Condition() string {
+	switch {
+	case s.Latency != nil:
+		return fmt.Sprintf("p%g latency > %s", s.Latency.PercentileOrDefault(), s.Latency.Threshold)
+	case s.ErrorRate != nil:
+		return fmt.Sprintf("error rate > %g%%", s.ErrorRate.Threshold)
+	case s.InstanceCount != nil:
+		return fmt.Sprintf("instances < %d", s.InstanceCount.Min)
+	default:
+		return ""
+	}
+}
+
+// ForDuration returns how long the condition holds before the alert fires.
+func (s *AlertRuleSpec) ForDuration() time.Duration {
+	d, err := time.ParseDuration(s.For)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// SeverityOrDefault returns the severity of alerts, default is warning.
+func (s *AlertRuleSpec) SeverityOrDefault() string {
+	if s.Severity == "" {
+		return AlertSeverityWarning
+	}
+	return s.Severity
+}
+
+// PercentileOrDefault returns the percentile of the latency, default is 99.
+func (l *AlertLatency) PercentileOrDefault() float64 {
+	if l.Percentile == 0 {
+		return DefaultAlertPercentile
+	}
+	return l.Percentile
+}
+
+// ThresholdDuration returns the latency threshold.
+func (l *AlertLatency) ThresholdDuration() (time.Duration, error) {
+	threshold, err := time.ParseDuration(l.Threshold)
+	if err != nil || threshold <= 0 {
+		return 0, errors.Errorf("invalid threshold %q, it must be a positive duration like 500ms", l.Threshold)
+	}
+	return threshold, nil
+}
+
+// ToObject converts an AlertRule resource to the object of the control plane
+func (a *AlertRule) ToObject() *AlertRuleObject {
+	result := &AlertRuleObject{
+		Name:          a.Name(),
+		AlertRuleSpec: &AlertRuleSpec{},
+	}
+	if a.Spec != nil {
+		result.AlertRuleSpec = a.Spec
+	}
+	return result
+}
+
+// ToAlertRule converts an object of the control plane to an AlertRule resource
+func ToAlertRule(object *AlertRuleObject) *AlertRule {
+	result := &AlertRule{
+		Spec: object.AlertRuleSpec,
+	}
+	result.MeshResource = NewAlertRuleResource(DefaultAPIVersion, object.Name)
+	return result
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// KindApplySet is the kind of the record of resources applied with a label selector,
+// it's maintained by `emctl apply --prune` rather than users.
+const KindApplySet = "ApplySet"
+
+type (
+	// ApplySet records resources applied with the same label selector, so that
+	// resources no longer in the input could be pruned in the next apply.
+	ApplySet struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *ApplySetSpec `yaml:"spec"`
+	}
+
+	// ApplySetSpec is the spec of ApplySet
+	ApplySetSpec struct {
+		Selector  string           `yaml:"selector" json:"selector"`
+		Resources []*ApplySetEntry `yaml:"resources" json:"resources"`
+	}
+
+	// ApplySetEntry identifies one applied resource
+	ApplySetEntry struct {
+		Kind string `yaml:"kind" json:"kind"`
+		Name string `yaml:"name" json:"name"`
+	}
+
+	// ApplySetObject is the ApplySet object stored in the control plane of the EaseMesh
+	ApplySetObject struct {
+		Name string `json:"name"`
+		*ApplySetSpec
+	}
+)
+
+// ToObject converts an ApplySet resource to the object of the control plane
+func (a *ApplySet) ToObject() *ApplySetObject {
+	result := &ApplySetObject{
+		Name:         a.Name(),
+		ApplySetSpec: &ApplySetSpec{},
+	}
+	if a.Spec != nil {
+		result.ApplySetSpec = a.Spec
+	}
+	return result
+}
+
+// ToApplySet converts an object of the control plane to an ApplySet resource
+func ToApplySet(object *ApplySetObject) *ApplySet {
+	spec := object.ApplySetSpec
+	if spec == nil {
+		spec = &ApplySetSpec{}
+	}
+	return &ApplySet{
+		MeshResource: NewMeshResource(DefaultAPIVersion, KindApplySet, object.Name),
+		Spec:         spec,
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// KindAuditRecord is the kind of the audit records of mutations to EaseMesh resources,
+// audit records are read-only which could not be applied or deleted.
+const KindAuditRecord = "AuditRecord"
+
+type (
+	// AuditRecord is a record of a mutation to a mesh resource, stored in the control plane
+	AuditRecord struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *AuditRecordObject `yaml:"spec"`
+	}
+
+	// AuditRecordObject is the audit record object stored in the control plane of the EaseMesh
+	AuditRecordObject struct {
+		ID string `yaml:"id" json:"id"`
+		// Timestamp is the time of the mutation in RFC3339.
+		Timestamp string `yaml:"timestamp" json:"timestamp"`
+		// User is the identity who made the mutation.
+		User string `yaml:"user" json:"user"`
+		// Operation is one of create, update, delete and rollback.
+		Operation string `yaml:"operation" json:"operation"`
+
+		Kind string `yaml:"kind" json:"kind"`
+		Name string `yaml:"name" json:"name"`
+
+		// Diff is the unified diff of the resource in YAML.
+		Diff string `yaml:"diff,omitempty" json:"diff,omitempty"`
+	}
+)
+
+var _ meta.TableObject = &AuditRecord{}
+
+// Columns returns the columns of AuditRecord.
+func (a *AuditRecord) Columns() []*meta.TableColumn {
+	if a.Spec == nil {
+		return nil
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Timestamp",
+			Value: a.Spec.Timestamp,
+		},
+		{
+			Name:  "User",
+			Value: a.Spec.User,
+		},
+		{
+			Name:  "Operation",
+			Value: a.Spec.Operation,
+		},
+		{
+			Name:  "Resource",
+			Value: a.Spec.Kind + "/" + a.Spec.Name,
+		},
+	}
+}
+
+// ToAuditRecord converts an object of the control plane to an AuditRecord resource
+func ToAuditRecord(object *AuditRecordObject) *AuditRecord {
+	return &AuditRecord{
+		MeshResource: NewMeshResource(DefaultAPIVersion, KindAuditRecord, object.ID),
+		Spec:         object,
+	}
+}
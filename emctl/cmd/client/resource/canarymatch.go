@@ -0,0 +1,519 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package resource
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// CanaryBuckets is the count of percentage buckets of canary match expressions.
+const CanaryBuckets = 100
+
+type (
+	// CanaryMatch is a compiled boolean expression over headers, cookies and query
+	// params of requests, which is used by A/B testing keyed on user identities.
+	//
+	// The syntax is:
+	//   header("X-User") == "alice" || cookie("uid") =~ "^1[0-9]+$"
+	//   !exists(query("debug")) && (header("X-Region") != "eu")
+	//   bucket(cookie("uid")) < 20
+	// bucket hashes the value into a stable percentage bucket in [0, 100),
+	// so the same user always falls into the same bucket.
+	CanaryMatch struct {
+		expr canaryExpr
+	}
+
+	// CanaryPredicateResult is the result of a predicate of the expression for a request.
+	CanaryPredicateResult struct {
+		Predicate string `json:"predicate"`
+		Value     string `json:"value"`
+		Matched   bool   `json:"matched"`
+	}
+
+	canaryExpr interface {
+		eval(r *http.Request) bool
+		predicates() []*canaryPredicate
+		String() string
+	}
+
+	canaryNot struct {
+		expr canaryExpr
+	}
+
+	canaryBinary struct {
+		op          string
+		left, right canaryExpr
+	}
+
+	canarySource struct {
+		kind, name string
+	}
+
+	canaryPredicate struct {
+		source canarySource
+		// exists tests presence of the source, op and values are unused.
+		exists bool
+		bucket bool
+		op     string
+		str    string
+		num    float64
+		re     *regexp.Regexp
+	}
+)
+
+// ParseCanaryMatch parses a canary match expression.
+func ParseCanaryMatch(expression string) (*CanaryMatch, error) {
+	tokens, err := tokenizeCanaryMatch(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &canaryParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, errors.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+
+	return &CanaryMatch{expr: expr}, nil
+}
+
+// ValidateCanarySource validates a source of canary match expressions, e.g. cookie("uid").
+func ValidateCanarySource(source string) error {
+	tokens, err := tokenizeCanaryMatch(source)
+	if err != nil {
+		return err
+	}
+
+	p := &canaryParser{tokens: tokens}
+	_, err = p.parseSource()
+	if err != nil {
+		return err
+	}
+	if p.pos != len(p.tokens) {
+		return errors.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+	return nil
+}
+
+// Match reports whether the request matches the expression.
+func (m *CanaryMatch) Match(r *http.Request) bool {
+	return m.expr.eval(r)
+}
+
+// Explain returns the results of all predicates of the expression for the request.
+func (m *CanaryMatch) Explain(r *http.Request) []*CanaryPredicateResult {
+	results := []*CanaryPredicateResult{}
+	for _, p := range m.expr.predicates() {
+		value, _ := p.value(r)
+		results = append(results, &CanaryPredicateResult{
+			Predicate: p.String(),
+			Value:     value,
+			Matched:   p.eval(r),
+		})
+	}
+	return results
+}
+
+// String returns the normalized expression.
+func (m *CanaryMatch) String() string {
+	return m.expr.String()
+}
+
+// CanaryBucket returns the stable percentage bucket of the value.
+func CanaryBucket(value string) int {
+	h := fnv.New32a()
+	h.Write([]byte(value))
+	return int(h.Sum32() % CanaryBuckets)
+}
+
+func (n *canaryNot) eval(r *http.Request) bool      { return !n.expr.eval(r) }
+func (n *canaryNot) predicates() []*canaryPredicate { return n.expr.predicates() }
+func (n *canaryNot) String() string                 { return "!" + n.expr.String() }
+
+func (b *canaryBinary) eval(r *http.Request) bool {
+	if b.op == "&&" {
+		return b.left.eval(r) && b.right.eval(r)
+	}
+	return b.left.eval(r) || b.right.eval(r)
+}
+
+func (b *canaryBinary) predicates() []*canaryPredicate {
+	return append(b.left.predicates(), b.right.predicates()...)
+}
+
+func (b *canaryBinary) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.left, b.op, b.right)
+}
+
+func (s canarySource) value(r *http.Request) (string, bool) {
+	switch s.kind {
+	case "header":
+		values, exists := r.Header[http.CanonicalHeaderKey(s.name)]
+		if !exists || len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	case "cookie":
+		cookie, err := r.Cookie(s.name)
+		if err != nil {
+			return "", false
+		}
+		return cookie.Value, true
+	default:
+		values, exists := r.URL.Query()[s.name]
+		if !exists || len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	}
+}
+
+func (s canarySource) String() string {
+	return fmt.Sprintf("%s(%q)", s.kind, s.name)
+}
+
+func (p *canaryPredicate) value(r *http.Request) (string, bool) {
+	value, exists := p.source.value(r)
+	if exists && p.bucket {
+		return strconv.Itoa(CanaryBucket(value)), true
+	}
+	return value, exists
+}
+
+func (p *canaryPredicate) eval(r *http.Request) bool {
+	value, exists := p.source.value(r)
+	if p.exists {
+		return exists
+	}
+
+	if p.bucket {
+		// NOTE: Requests without the identity fall into no bucket.
+		if !exists {
+			return false
+		}
+		bucket := float64(CanaryBucket(value))
+		switch p.op {
+		case "==":
+			return bucket == p.num
+		case "!=":
+			return bucket != p.num
+		case "<":
+			return bucket < p.num
+		case "<=":
+			return bucket <= p.num
+		case ">":
+			return bucket > p.num
+		default:
+			return bucket >= p.num
+		}
+	}
+
+	switch p.op {
+	case "==":
+		return exists && value == p.str
+	case "!=":
+		return !exists || value != p.str
+	case "=~":
+		return exists && p.re.MatchString(value)
+	default:
+		return !exists || !p.re.MatchString(value)
+	}
+}
+
+func (p *canaryPredicate) predicates() []*canaryPredicate {
+	return []*canaryPredicate{p}
+}
+
+func (p *canaryPredicate) String() string {
+	switch {
+	case p.exists:
+		return fmt.Sprintf("exists(%s)", p.source)
+	case p.bucket:
+		return fmt.Sprintf("bucket(%s) %s %g", p.source, p.op, p.num)
+	default:
+		return fmt.Sprintf("%s %s %q", p.source, p.op, p.str)
+	}
+}
+
+type (
+	canaryToken struct {
+		// kind is one of ident, string, number, op.
+		kind string
+		text string
+		pos  int
+	}
+
+	canaryParser struct {
+		tokens []*canaryToken
+		pos    int
+	}
+)
+
+var canaryOperators = []string{"&&", "||", "==", "!=", "=~", "!~", "<=", ">=", "<", ">", "!", "(", ")", ","}
+
+func tokenizeCanaryMatch(expression string) ([]*canaryToken, error) {
+	tokens := []*canaryToken{}
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(expression) && expression[end] != '"'; end++ {
+				if expression[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expression) {
+				return nil, errors.Errorf("unterminated string at position %d", i)
+			}
+			text, err := strconv.Unquote(expression[i : end+1])
+			if err != nil {
+				return nil, errors.Errorf("invalid string at position %d", i)
+			}
+			tokens = append(tokens, &canaryToken{kind: "string", text: text, pos: i})
+			i = end + 1
+		case unicode.IsDigit(c) || c == '.':
+			end := i
+			for end < len(expression) && (unicode.IsDigit(rune(expression[end])) || expression[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, &canaryToken{kind: "number", text: expression[i:end], pos: i})
+			i = end
+		case unicode.IsLetter(c):
+			end := i
+			for end < len(expression) && (unicode.IsLetter(rune(expression[end])) || unicode.IsDigit(rune(expression[end]))) {
+				end++
+			}
+			tokens = append(tokens, &canaryToken{kind: "ident", text: expression[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range canaryOperators {
+				if strings.HasPrefix(expression[i:], op) {
+					tokens = append(tokens, &canaryToken{kind: "op", text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, errors.Errorf("unexpected %q at position %d", c, i)
+			}
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+
+	return tokens, nil
+}
+
+func (p *canaryParser) peek() *canaryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *canaryParser) peekOp(op string) bool {
+	t := p.peek()
+	return t != nil && t.kind == "op" && t.text == op
+}
+
+func (p *canaryParser) next(kind string) (*canaryToken, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, errors.Errorf("unexpected end of expression, expecting %s", kind)
+	}
+	if t.kind != kind && !(t.kind == "op" && t.text == kind) {
+		return nil, errors.Errorf("unexpected %q at position %d, expecting %s", t.text, t.pos, kind)
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *canaryParser) parseOr() (canaryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &canaryBinary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *canaryParser) parseAnd() (canaryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &canaryBinary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *canaryParser) parseUnary() (canaryExpr, error) {
+	switch {
+	case p.peekOp("!"):
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &canaryNot{expr: expr}, nil
+	case p.peekOp("("):
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.next(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return p.parsePredicate()
+	}
+}
+
+func (p *canaryParser) parsePredicate() (canaryExpr, error) {
+	ident, err := p.next("ident")
+	if err != nil {
+		return nil, err
+	}
+
+	predicate := &canaryPredicate{}
+	switch ident.text {
+	case "exists", "bucket":
+		if _, err := p.next("("); err != nil {
+			return nil, err
+		}
+		if predicate.source, err = p.parseSource(); err != nil {
+			return nil, err
+		}
+		if _, err := p.next(")"); err != nil {
+			return nil, err
+		}
+		if ident.text == "exists" {
+			predicate.exists = true
+			return predicate, nil
+		}
+		predicate.bucket = true
+	default:
+		p.pos--
+		if predicate.source, err = p.parseSource(); err != nil {
+			return nil, err
+		}
+	}
+
+	op, err := p.next("op")
+	if err != nil {
+		return nil, err
+	}
+	predicate.op = op.text
+
+	if predicate.bucket {
+		switch op.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+		default:
+			return nil, errors.Errorf("unsupported operator %s of bucket at position %d", op.text, op.pos)
+		}
+		num, err := p.next("number")
+		if err != nil {
+			return nil, err
+		}
+		predicate.num, err = strconv.ParseFloat(num.text, 64)
+		if err != nil || predicate.num < 0 || predicate.num > CanaryBuckets {
+			return nil, errors.Errorf("invalid bucket %s at position %d, it must be in [0, %d]", num.text, num.pos, CanaryBuckets)
+		}
+		return predicate, nil
+	}
+
+	switch op.text {
+	case "==", "!=", "=~", "!~":
+	default:
+		return nil, errors.Errorf("unsupported operator %s at position %d", op.text, op.pos)
+	}
+	str, err := p.next("string")
+	if err != nil {
+		return nil, err
+	}
+	predicate.str = str.text
+	if op.text == "=~" || op.text == "!~" {
+		predicate.re, err = regexp.Compile(str.text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regex at position %d", str.pos)
+		}
+	}
+
+	return predicate, nil
+}
+
+func (p *canaryParser) parseSource() (canarySource, error) {
+	ident, err := p.next("ident")
+	if err != nil {
+		return canarySource{}, err
+	}
+	switch ident.text {
+	case "header", "cookie", "query":
+	default:
+		return canarySource{}, errors.Errorf("unknown %q at position %d (support header, cookie, query, exists, bucket)",
+			ident.text, ident.pos)
+	}
+
+	if _, err := p.next("("); err != nil {
+		return canarySource{}, err
+	}
+	name, err := p.next("string")
+	if err != nil {
+		return canarySource{}, err
+	}
+	if name.text == "" {
+		return canarySource{}, errors.Errorf("empty name at position %d", name.pos)
+	}
+	if _, err := p.next(")"); err != nil {
+		return canarySource{}, err
+	}
+
+	return canarySource{kind: ident.text, name: name.text}, nil
+}
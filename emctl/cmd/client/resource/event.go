@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// KindMeshEvent is the kind of the events of the control plane, events are
+// read-only which could not be applied or deleted.
+const KindMeshEvent = "MeshEvent"
+
+const (
+	// EventTypeService is the type of events of registering and deregistering services.
+	EventTypeService = "service"
+	// EventTypeCanary is the type of events of activating and deactivating canaries.
+	EventTypeCanary = "canary"
+	// EventTypeConfig is the type of events of configurations accepted or rejected by the control plane.
+	EventTypeConfig = "config"
+	// EventTypeSidecar is the type of events of sidecars connecting to and disconnecting from the control plane.
+	EventTypeSidecar = "sidecar"
+)
+
+// EventTypes are types of the events of the control plane.
+var EventTypes = []string{EventTypeService, EventTypeCanary, EventTypeConfig, EventTypeSidecar}
+
+type (
+	// MeshEvent is an event of the control plane, e.g. a service is registered
+	MeshEvent struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *MeshEventObject `yaml:"spec"`
+	}
+
+	// MeshEventObject is the event object streamed by the control plane of the EaseMesh
+	MeshEventObject struct {
+		ID string `yaml:"id" json:"id"`
+		// Timestamp is the time of the event in RFC3339.
+		Timestamp string `yaml:"timestamp" json:"timestamp"`
+		// Type is one of service, canary, config and sidecar.
+		Type string `yaml:"type" json:"type"`
+		// Reason is what happened, e.g. Registered, Deregistered, Activated, Rejected and Disconnected.
+		Reason string `yaml:"reason" json:"reason"`
+
+		// Kind and Name are the resource involved in the event.
+		Kind string `yaml:"kind" json:"kind"`
+		Name string `yaml:"name" json:"name"`
+
+		Message string `yaml:"message,omitempty" json:"message,omitempty"`
+	}
+)
+
+var _ meta.TableObject = &MeshEvent{}
+
+// Columns returns the columns of MeshEvent.
+func (e *MeshEvent) Columns() []*meta.TableColumn {
+	if e.Spec == nil {
+		return nil
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Timestamp",
+			Value: e.Spec.Timestamp,
+		},
+		{
+			Name:  "Type",
+			Value: e.Spec.Type,
+		},
+		{
+			Name:  "Reason",
+			Value: e.Spec.Reason,
+		},
+		{
+			Name:  "Object",
+			Value: e.Spec.Kind + "/" + e.Spec.Name,
+		},
+		{
+			Name:  "Message",
+			Value: e.Spec.Message,
+		},
+	}
+}
+
+// ToMeshEvent converts an event object of the control plane to a MeshEvent resource
+func ToMeshEvent(object *MeshEventObject) *MeshEvent {
+	return &MeshEvent{
+		MeshResource: NewMeshResource(DefaultAPIVersion, KindMeshEvent, object.ID),
+		Spec:         object,
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExternalServiceTLSModeDisable sends plain traffic to the external service.
+	ExternalServiceTLSModeDisable = "disable"
+	// ExternalServiceTLSModeOriginate lets the mesh originate TLS to the external service,
+	// so that applications could send plain traffic.
+	ExternalServiceTLSModeOriginate = "originate"
+	// ExternalServiceTLSModePassthrough passes TLS traffic of applications through.
+	ExternalServiceTLSModePassthrough = "passthrough"
+)
+
+type (
+	// ExternalService describes an off-mesh endpoint (database, SaaS API, etc.)
+	// which is accessed by mesh services
+	ExternalService struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *ExternalServiceSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// ExternalServiceSpec describes how to reach an external service
+	ExternalServiceSpec struct {
+		// Hosts are DNS names of the external service, e.g. api.stripe.com
+		Hosts []string `yaml:"hosts" json:"hosts" jsonschema:"required"`
+		// Endpoints are static addresses (host:port) of the external service,
+		// hosts are resolved by DNS if it's empty.
+		Endpoints []string               `yaml:"endpoints,omitempty" json:"endpoints,omitempty" jsonschema:"omitempty"`
+		Ports     []*ExternalServicePort `yaml:"ports" json:"ports" jsonschema:"required"`
+
+		TLS     *ExternalServiceTLS     `yaml:"tls,omitempty" json:"tls,omitempty" jsonschema:"omitempty"`
+		Retries *ExternalServiceRetries `yaml:"retries,omitempty" json:"retries,omitempty" jsonschema:"omitempty"`
+		Timeout string                  `yaml:"timeout,omitempty" json:"timeout,omitempty" jsonschema:"omitempty,format=duration"`
+
+		// ViaEgressGateway routes the outbound traffic through the egress gateway
+		// instead of leaving from sidecars directly.
+		ViaEgressGateway bool `yaml:"viaEgressGateway,omitempty" json:"viaEgressGateway,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ExternalServicePort is a port of the external service
+	ExternalServicePort struct {
+		Name     string `yaml:"name,omitempty" json:"name,omitempty" jsonschema:"omitempty"`
+		Number   int    `yaml:"number" json:"number" jsonschema:"required,minimum=1,maximum=65535"`
+		Protocol string `yaml:"protocol" json:"protocol" jsonschema:"required,enum=http,enum=https,enum=tcp,enum=tls"`
+	}
+
+	// ExternalServiceTLS describes TLS settings towards the external service
+	ExternalServiceTLS struct {
+		Mode string `yaml:"mode" json:"mode" jsonschema:"required,enum=disable,enum=originate,enum=passthrough"`
+		SNI  string `yaml:"sni,omitempty" json:"sni,omitempty" jsonschema:"omitempty"`
+		// CACertBase64 verifies the certificate of the external service, the system pool is used if it's empty.
+		CACertBase64 string `yaml:"caCertBase64,omitempty" json:"caCertBase64,omitempty" jsonschema:"omitempty,format=base64"`
+		// CertBase64 and KeyBase64 are used for mutual TLS.
+		CertBase64 string `yaml:"certBase64,omitempty" json:"certBase64,omitempty" jsonschema:"omitempty,format=base64"`
+		KeyBase64  string `yaml:"keyBase64,omitempty" json:"keyBase64,omitempty" jsonschema:"omitempty,format=base64"`
+		// CredentialSecretRef references the secret with the keys tls.crt, tls.key and ca.crt
+		// instead of inlining them above, all of the keys are optional.
+		CredentialSecretRef *SecretRef `yaml:"credentialSecretRef,omitempty" json:"credentialSecretRef,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ExternalServiceRetries describes the retry policy of requests to the external service
+	ExternalServiceRetries struct {
+		MaxAttempts   int    `yaml:"maxAttempts" json:"maxAttempts" jsonschema:"required,minimum=1"`
+		PerTryTimeout string `yaml:"perTryTimeout,omitempty" json:"perTryTimeout,omitempty" jsonschema:"omitempty,format=duration"`
+		// RetryOn is the list of status codes to retry, e.g. 502, 503
+		RetryOn []int `yaml:"retryOn,omitempty" json:"retryOn,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ExternalServiceObject is the ExternalService object stored in the control plane of the EaseMesh
+	ExternalServiceObject struct {
+		Name string `json:"name"`
+		*ExternalServiceSpec
+	}
+)
+
+var _ meta.TableObject = &ExternalService{}
+
+// Columns returns the columns of ExternalService.
+func (e *ExternalService) Columns() []*meta.TableColumn {
+	if e.Spec == nil {
+		return nil
+	}
+
+	ports := []string{}
+	for _, port := range e.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d/%s", port.Number, port.Protocol))
+	}
+
+	tlsMode := ExternalServiceTLSModeDisable
+	if e.Spec.TLS != nil {
+		tlsMode = e.Spec.TLS.Mode
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Hosts",
+			Value: strings.Join(e.Spec.Hosts, ","),
+		},
+		{
+			Name:  "Ports",
+			Value: strings.Join(ports, ","),
+		},
+		{
+			Name:  "TLS",
+			Value: tlsMode,
+		},
+		{
+			Name:  "EgressGateway",
+			Value: fmt.Sprintf("%t", e.Spec.ViaEgressGateway),
+		},
+	}
+}
+
+// Validate validates the ExternalService before it's applied.
+func (e *ExternalService) Validate() error {
+	if e.Spec == nil || e.Spec.TLS == nil {
+		return nil
+	}
+	return e.Spec.TLS.Validate()
+}
+
+// Validate validates the TLS settings, credentials are either inlined
+// or referenced by the secret.
+func (t *ExternalServiceTLS) Validate() error {
+	switch t.Mode {
+	case ExternalServiceTLSModeDisable, ExternalServiceTLSModeOriginate, ExternalServiceTLSModePassthrough:
+	default:
+		return errors.Errorf("tls: unsupported mode %q", t.Mode)
+	}
+	if (t.CertBase64 == "") != (t.KeyBase64 == "") {
+		return errors.New("tls: certBase64 and keyBase64 must be set together")
+	}
+
+	if t.CredentialSecretRef == nil {
+		return nil
+	}
+	if t.CACertBase64 != "" || t.CertBase64 != "" {
+		return errors.New("tls: credentialSecretRef can't be set with caCertBase64, certBase64 and keyBase64")
+	}
+	if t.Mode != ExternalServiceTLSModeOriginate {
+		return errors.Errorf("tls: credentialSecretRef is only used by the mode %s", ExternalServiceTLSModeOriginate)
+	}
+	err := t.CredentialSecretRef.Validate()
+	if err != nil {
+		return errors.Wrap(err, "tls: credentialSecretRef")
+	}
+	return nil
+}
+
+// ToObject converts an ExternalService resource to the object of the control plane
+func (e *ExternalService) ToObject() *ExternalServiceObject {
+	result := &ExternalServiceObject{
+		Name:                e.Name(),
+		ExternalServiceSpec: &ExternalServiceSpec{},
+	}
+	if e.Spec != nil {
+		result.ExternalServiceSpec = e.Spec
+	}
+	return result
+}
+
+// ToExternalService converts an object of the control plane to an ExternalService resource
+func ToExternalService(object *ExternalServiceObject) *ExternalService {
+	result := &ExternalService{
+		Spec: object.ExternalServiceSpec,
+	}
+	result.MeshResource = NewExternalServiceResource(DefaultAPIVersion, object.Name)
+	return result
+}
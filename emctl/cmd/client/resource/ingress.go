@@ -18,10 +18,28 @@
 package resource
 
 import (
+	"net/url"
+	"strings"
+
 	"github.com/megaease/easemesh-api/v1alpha1"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ACMEChallengeHTTP01 proves the control of hosts by HTTP requests to the ingress controller.
+	ACMEChallengeHTTP01 = "http-01"
+	// ACMEChallengeDNS01 proves the control of hosts by TXT records of the DNS provider.
+	ACMEChallengeDNS01 = "dns-01"
 )
 
+// corsMethods are the methods allowed by CORS policies.
+var corsMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "OPTIONS": true, "CONNECT": true, "TRACE": true,
+}
+
 type (
 	// Ingress describes ingress resource of the EaseMesh
 	Ingress struct {
@@ -32,6 +50,73 @@ type (
 	// IngressSpec wraps all route rules
 	IngressSpec struct {
 		Rules []*v1alpha1.IngressRule `yaml:"rules" jsonschema:"required"`
+		// CORS is the CORS policy of all paths of the ingress, nil means
+		// requests are passed to backends as they are.
+		CORS *IngressCORS `yaml:"cors,omitempty" json:"cors,omitempty" jsonschema:"omitempty"`
+		// PathCORS overrides the CORS policy of paths of the rules.
+		PathCORS []*IngressPathCORS `yaml:"pathCORS,omitempty" json:"pathCORS,omitempty" jsonschema:"omitempty"`
+		// Limits protect backends from oversized requests and slow clients.
+		Limits *IngressLimits `yaml:"limits,omitempty" json:"limits,omitempty" jsonschema:"omitempty"`
+		// PathLimits override the limits of paths of the rules.
+		PathLimits []*IngressPathLimits `yaml:"pathLimits,omitempty" json:"pathLimits,omitempty" jsonschema:"omitempty"`
+		// PathFilters are the compression and cache filters of paths of the rules.
+		PathFilters []*IngressPathFilters `yaml:"pathFilters,omitempty" json:"pathFilters,omitempty" jsonschema:"omitempty"`
+		// HostPolicies are the HTTPS policies of hosts of the rules.
+		HostPolicies []*IngressHostPolicy `yaml:"hostPolicies,omitempty" json:"hostPolicies,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressHostPolicy is the HTTPS policy of a host of the rules
+	IngressHostPolicy struct {
+		Host string `yaml:"host" json:"host" jsonschema:"required"`
+		// SSLRedirect redirects HTTP requests of the host to HTTPS with 301.
+		SSLRedirect bool         `yaml:"sslRedirect,omitempty" json:"sslRedirect,omitempty" jsonschema:"omitempty"`
+		HSTS        *IngressHSTS `yaml:"hsts,omitempty" json:"hsts,omitempty" jsonschema:"omitempty"`
+		// ACMEChallenge obtains the certificate of the host by the ACME client of the
+		// ingress controller with the challenge, empty means it's not managed by ACME.
+		ACMEChallenge string `yaml:"acmeChallenge,omitempty" json:"acmeChallenge,omitempty" jsonschema:"omitempty,enum=http-01,enum=dns-01"`
+	}
+
+	// IngressHSTS is the Strict-Transport-Security header added to HTTPS responses of a host
+	IngressHSTS struct {
+		// MaxAge is the seconds browsers only access the host by HTTPS.
+		MaxAge            int  `yaml:"maxAge" json:"maxAge" jsonschema:"required,minimum=1"`
+		IncludeSubDomains bool `yaml:"includeSubDomains,omitempty" json:"includeSubDomains,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressCORS is a CORS policy, fields are the same as the ones of
+	// the CORSAdaptor filter of Easegress, which the ingress controller
+	// puts in front of backends in the pipeline.
+	IngressCORS struct {
+		// AllowedOrigins are origins like https://www.megaease.com,
+		// https://*.megaease.com matches all subdomains, * matches all origins.
+		AllowedOrigins   []string `yaml:"allowedOrigins" json:"allowedOrigins" jsonschema:"required"`
+		AllowedMethods   []string `yaml:"allowedMethods,omitempty" json:"allowedMethods,omitempty" jsonschema:"omitempty"`
+		AllowedHeaders   []string `yaml:"allowedHeaders,omitempty" json:"allowedHeaders,omitempty" jsonschema:"omitempty"`
+		ExposedHeaders   []string `yaml:"exposedHeaders,omitempty" json:"exposedHeaders,omitempty" jsonschema:"omitempty"`
+		AllowCredentials bool     `yaml:"allowCredentials,omitempty" json:"allowCredentials,omitempty" jsonschema:"omitempty"`
+		// MaxAge is the seconds preflight results are cached by browsers, 0 means not cached.
+		MaxAge int `yaml:"maxAge,omitempty" json:"maxAge,omitempty" jsonschema:"omitempty,minimum=0"`
+	}
+
+	// IngressPathCORS is the CORS policy of a path of the rules
+	IngressPathCORS struct {
+		// Host and Path must be the same as the ones of a path of the rules.
+		Host string `yaml:"host,omitempty" json:"host,omitempty" jsonschema:"omitempty"`
+		Path string `yaml:"path" json:"path" jsonschema:"required"`
+		// Disabled turns off the CORS policy of the ingress for the path.
+		Disabled bool         `yaml:"disabled,omitempty" json:"disabled,omitempty" jsonschema:"omitempty"`
+		CORS     *IngressCORS `yaml:"cors,omitempty" json:"cors,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressObject is the Ingress object stored in the control plane of the EaseMesh
+	IngressObject struct {
+		*v1alpha1.Ingress
+		CORS         *IngressCORS          `json:"cors,omitempty"`
+		PathCORS     []*IngressPathCORS    `json:"pathCORS,omitempty"`
+		Limits       *IngressLimits        `json:"limits,omitempty"`
+		PathLimits   []*IngressPathLimits  `json:"pathLimits,omitempty"`
+		PathFilters  []*IngressPathFilters `json:"pathFilters,omitempty"`
+		HostPolicies []*IngressHostPolicy  `json:"hostPolicies,omitempty"`
 	}
 )
 
@@ -54,3 +139,162 @@ func ToIngress(ingress *v1alpha1.Ingress) *Ingress {
 	result.Spec.Rules = ingress.Rules
 	return result
 }
+
+// ToObject converts an Ingress resource to the object of the control plane
+func (ing *Ingress) ToObject() *IngressObject {
+	result := &IngressObject{Ingress: ing.ToV1Alpha1()}
+	if ing.Spec != nil {
+		result.CORS = ing.Spec.CORS
+		result.PathCORS = ing.Spec.PathCORS
+		result.Limits = ing.Spec.Limits
+		result.PathLimits = ing.Spec.PathLimits
+		result.PathFilters = ing.Spec.PathFilters
+		result.HostPolicies = ing.Spec.HostPolicies
+	}
+	return result
+}
+
+// IngressFromObject converts an object of the control plane to an Ingress resource
+func IngressFromObject(object *IngressObject) *Ingress {
+	ingress := object.Ingress
+	if ingress == nil {
+		ingress = &v1alpha1.Ingress{}
+	}
+	result := ToIngress(ingress)
+	result.Spec.CORS = object.CORS
+	result.Spec.PathCORS = object.PathCORS
+	result.Spec.Limits = object.Limits
+	result.Spec.PathLimits = object.PathLimits
+	result.Spec.PathFilters = object.PathFilters
+	result.Spec.HostPolicies = object.HostPolicies
+	return result
+}
+
+// Validate validates CORS, limits, path filters and host policies of the Ingress before it's applied.
+func (ing *Ingress) Validate() error {
+	if ing.Spec == nil {
+		return nil
+	}
+
+	if ing.Spec.CORS != nil {
+		err := ing.Spec.CORS.validate()
+		if err != nil {
+			return errors.Wrap(err, "cors")
+		}
+	}
+
+	paths, hosts := map[string]bool{}, map[string]bool{}
+	for _, rule := range ing.Spec.Rules {
+		hosts[rule.Host] = true
+		for _, path := range rule.Paths {
+			paths[rule.Host+path.Path] = true
+		}
+	}
+	for _, pathCORS := range ing.Spec.PathCORS {
+		if !paths[pathCORS.Host+pathCORS.Path] {
+			return errors.Errorf("pathCORS: no path %s of host %q in rules", pathCORS.Path, pathCORS.Host)
+		}
+		if pathCORS.Disabled != (pathCORS.CORS == nil) {
+			return errors.Errorf("pathCORS: path %s must have either cors or disabled", pathCORS.Path)
+		}
+		if pathCORS.CORS != nil {
+			err := pathCORS.CORS.validate()
+			if err != nil {
+				return errors.Wrapf(err, "pathCORS: path %s", pathCORS.Path)
+			}
+		}
+	}
+
+	if ing.Spec.Limits != nil {
+		err := ing.Spec.Limits.validate()
+		if err != nil {
+			return errors.Wrap(err, "limits")
+		}
+	}
+	err := validatePathLimits(ing.Spec.PathLimits, paths)
+	if err != nil {
+		return errors.Wrap(err, "pathLimits")
+	}
+
+	err = validatePathFilters(ing.Spec.PathFilters, paths)
+	if err != nil {
+		return errors.Wrap(err, "pathFilters")
+	}
+
+	policies := map[string]bool{}
+	for _, policy := range ing.Spec.HostPolicies {
+		if policy.Host == "" || !hosts[policy.Host] {
+			return errors.Errorf("hostPolicies: no host %q in rules", policy.Host)
+		}
+		if policies[policy.Host] {
+			return errors.Errorf("hostPolicies: host %s is duplicated", policy.Host)
+		}
+		policies[policy.Host] = true
+		if policy.HSTS != nil && policy.HSTS.MaxAge <= 0 {
+			return errors.Errorf("hostPolicies: hsts maxAge %d of host %s must be positive", policy.HSTS.MaxAge, policy.Host)
+		}
+		switch policy.ACMEChallenge {
+		case "", ACMEChallengeHTTP01, ACMEChallengeDNS01:
+		default:
+			return errors.Errorf("hostPolicies: unsupported acmeChallenge %s of host %s (support %s, %s)",
+				policy.ACMEChallenge, policy.Host, ACMEChallengeHTTP01, ACMEChallengeDNS01)
+		}
+		if policy.ACMEChallenge == ACMEChallengeHTTP01 && strings.HasPrefix(policy.Host, "*.") {
+			return errors.Errorf("hostPolicies: wildcard host %s requires acmeChallenge %s", policy.Host, ACMEChallengeDNS01)
+		}
+	}
+
+	return nil
+}
+
+func (c *IngressCORS) validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return errors.New("allowedOrigins is required")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return errors.New("allowedOrigins * can't be used with allowCredentials")
+			}
+			continue
+		}
+		// Only the leftmost label of the host could be the wildcard.
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			strings.Contains(u.Host, "*") || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return errors.Errorf("invalid origin %q, it must be like https://www.megaease.com or https://*.megaease.com", origin)
+		}
+	}
+
+	for _, method := range c.AllowedMethods {
+		if !corsMethods[strings.ToUpper(method)] {
+			return errors.Errorf("invalid method %q", method)
+		}
+	}
+	for _, header := range append(append([]string{}, c.AllowedHeaders...), c.ExposedHeaders...) {
+		if header != "*" && !isHeaderName(header) {
+			return errors.Errorf("invalid header %q", header)
+		}
+	}
+	if c.MaxAge < 0 {
+		return errors.Errorf("maxAge %d must not be negative", c.MaxAge)
+	}
+
+	return nil
+}
+
+// isHeaderName reports whether the name consists of token characters of RFC 7230.
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
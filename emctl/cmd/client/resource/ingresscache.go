@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IngressCachePurge purges responses cached by the cache of path filters in
+// all ingress controller instances, it's an action rather than a mesh resource.
+type IngressCachePurge struct {
+	Host string `yaml:"host" json:"host"`
+	// Path is an exact path like /index.html, or a prefix ending with * like
+	// /assets/*, only /* purges all responses of the host.
+	Path string `yaml:"path" json:"path"`
+	// Purged is the number of responses purged, returned by the control plane.
+	Purged int `yaml:"purged" json:"purged"`
+}
+
+// Validate validates the host and the path of the purge.
+func (p *IngressCachePurge) Validate() error {
+	if p.Host == "" {
+		return errors.Errorf("host is required")
+	}
+	if strings.ContainsAny(p.Host, "/*") {
+		return errors.Errorf("invalid host %q, it must be a host name like app.example.com", p.Host)
+	}
+	if !strings.HasPrefix(p.Path, "/") {
+		return errors.Errorf("invalid path %q, it must start with /", p.Path)
+	}
+	if i := strings.Index(p.Path, "*"); i >= 0 && i != len(p.Path)-1 {
+		return errors.Errorf("invalid path %q, * is only allowed at the end", p.Path)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+const (
+	// IngressCertificatePending means the certificate is being obtained from the ACME server.
+	IngressCertificatePending = "PENDING"
+	// IngressCertificateReady means the certificate is obtained and in use.
+	IngressCertificateReady = "READY"
+	// IngressCertificateFailed means the last attempt to obtain or renew the certificate failed.
+	IngressCertificateFailed = "FAILED"
+)
+
+// IngressCertificate is the status of the certificate of an ingress host managed
+// by the ACME client of the ingress controller, they are read-only which could
+// not be applied or deleted.
+type IngressCertificate struct {
+	Host    string `yaml:"host" json:"host"`
+	Ingress string `yaml:"ingress" json:"ingress"`
+	// Challenge is http-01 or dns-01.
+	Challenge string `yaml:"challenge" json:"challenge"`
+	// Status is one of PENDING, READY and FAILED.
+	Status string `yaml:"status" json:"status"`
+	// NotAfter is the expiry time in RFC3339 of the certificate in use, empty if there is none.
+	NotAfter string `yaml:"notAfter,omitempty" json:"notAfter,omitempty"`
+	// Message is the error of the last failed attempt.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+}
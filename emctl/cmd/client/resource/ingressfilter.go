@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// IngressEncodingGzip is the gzip content encoding.
+	IngressEncodingGzip = "gzip"
+	// IngressEncodingBrotli is the brotli content encoding.
+	IngressEncodingBrotli = "br"
+
+	// DefaultIngressCompressionMinLength is the default min bytes of responses to be compressed.
+	DefaultIngressCompressionMinLength = 1024
+	// DefaultIngressCacheTTL is the default duration responses are cached.
+	DefaultIngressCacheTTL = time.Minute
+	// DefaultIngressStaticCacheTTL is the default duration responses are cached in the static mode.
+	DefaultIngressStaticCacheTTL = time.Hour
+	// DefaultIngressCacheMaxEntries is the default max number of responses cached for a path.
+	DefaultIngressCacheMaxEntries = 1000
+	// DefaultIngressCacheMaxEntrySize is the default max bytes of a cached response.
+	DefaultIngressCacheMaxEntrySize = 1 << 20
+
+	// IngressCacheModeStatic caches responses as static assets for the TTL,
+	// regardless of the freshness told by the backend.
+	IngressCacheModeStatic = "static"
+)
+
+type (
+	// IngressPathFilters are the filters of a path of the rules, they're put
+	// in front of the backend in the pipeline of the path by the ingress controller.
+	IngressPathFilters struct {
+		// Host and Path must be the same as the ones of a path of the rules.
+		Host        string              `yaml:"host,omitempty" json:"host,omitempty" jsonschema:"omitempty"`
+		Path        string              `yaml:"path" json:"path" jsonschema:"required"`
+		Compression *IngressCompression `yaml:"compression,omitempty" json:"compression,omitempty" jsonschema:"omitempty"`
+		Cache       *IngressCache       `yaml:"cache,omitempty" json:"cache,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressCompression compresses responses by the encodings accepted by clients.
+	IngressCompression struct {
+		// Encodings are gzip and br in the order of preference, empty means both, br first.
+		Encodings []string `yaml:"encodings,omitempty" json:"encodings,omitempty" jsonschema:"omitempty"`
+		// MinLength is the min bytes of responses to be compressed, 0 means 1024.
+		MinLength int `yaml:"minLength,omitempty" json:"minLength,omitempty" jsonschema:"omitempty,minimum=0"`
+		// ContentTypes are media types of responses to be compressed like text/*,
+		// empty means text, JSON, JavaScript, XML and SVG.
+		ContentTypes []string `yaml:"contentTypes,omitempty" json:"contentTypes,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressCache caches responses of the backend in memory of ingress controllers,
+	// responses with Cache-Control no-store or private, or Set-Cookie are never cached.
+	IngressCache struct {
+		// TTL is the duration responses are cached, empty means 60s.
+		TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty" jsonschema:"omitempty,format=duration"`
+		// Methods are GET and HEAD, empty means both.
+		Methods []string `yaml:"methods,omitempty" json:"methods,omitempty" jsonschema:"omitempty"`
+		// KeyHeaders are request headers in the cache key besides the method, host and path.
+		KeyHeaders []string `yaml:"keyHeaders,omitempty" json:"keyHeaders,omitempty" jsonschema:"omitempty"`
+		// IgnoreQuery excludes the query from the cache key.
+		IgnoreQuery bool `yaml:"ignoreQuery,omitempty" json:"ignoreQuery,omitempty" jsonschema:"omitempty"`
+		// MaxEntries is the max number of responses cached, the least recently used
+		// ones are evicted, 0 means 1000.
+		MaxEntries int `yaml:"maxEntries,omitempty" json:"maxEntries,omitempty" jsonschema:"omitempty,minimum=0"`
+		// MaxEntrySize is the max bytes of a cached response, 0 means 1MiB.
+		MaxEntrySize int `yaml:"maxEntrySize,omitempty" json:"maxEntrySize,omitempty" jsonschema:"omitempty,minimum=0"`
+
+		// Mode is empty or static. Empty caches responses no longer than the
+		// Cache-Control max-age or Expires of the backend. Static caches them
+		// for the TTL, which is 1h by default, ignoring Cache-Control no-cache,
+		// max-age and Expires, and drops Set-Cookie of them instead of skipping them.
+		Mode string `yaml:"mode,omitempty" json:"mode,omitempty" jsonschema:"omitempty,enum=static"`
+		// ETag adds strong ETags of the bodies to cached responses without one,
+		// and answers requests with matching If-None-Match by 304 Not Modified.
+		ETag bool `yaml:"etag,omitempty" json:"etag,omitempty" jsonschema:"omitempty"`
+		// ClientTTL overwrites Cache-Control of cached responses to clients with
+		// public and its max-age, empty keeps the one of the backend.
+		ClientTTL string `yaml:"clientTTL,omitempty" json:"clientTTL,omitempty" jsonschema:"omitempty,format=duration"`
+	}
+)
+
+// validatePathFilters validates filters of paths, paths are the host and
+// path pairs of the rules.
+func validatePathFilters(pathFilters []*IngressPathFilters, paths map[string]bool) error {
+	filtered := map[string]bool{}
+	for _, filters := range pathFilters {
+		key := filters.Host + filters.Path
+		if !paths[key] {
+			return errors.Errorf("no path %s of host %q in rules", filters.Path, filters.Host)
+		}
+		if filtered[key] {
+			return errors.Errorf("path %s of host %q is duplicated", filters.Path, filters.Host)
+		}
+		filtered[key] = true
+
+		if filters.Compression == nil && filters.Cache == nil {
+			return errors.Errorf("path %s must have compression or cache", filters.Path)
+		}
+		if filters.Compression != nil {
+			err := filters.Compression.validate()
+			if err != nil {
+				return errors.Wrapf(err, "path %s: compression", filters.Path)
+			}
+		}
+		if filters.Cache != nil {
+			err := filters.Cache.validate()
+			if err != nil {
+				return errors.Wrapf(err, "path %s: cache", filters.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *IngressCompression) validate() error {
+	encodings := map[string]bool{}
+	for _, encoding := range c.Encodings {
+		if encoding != IngressEncodingGzip && encoding != IngressEncodingBrotli {
+			return errors.Errorf("unsupported encoding %s (support %s, %s)", encoding, IngressEncodingBrotli, IngressEncodingGzip)
+		}
+		if encodings[encoding] {
+			return errors.Errorf("encoding %s is duplicated", encoding)
+		}
+		encodings[encoding] = true
+	}
+	if c.MinLength < 0 {
+		return errors.Errorf("minLength %d must not be negative", c.MinLength)
+	}
+	for _, contentType := range c.ContentTypes {
+		parts := strings.Split(contentType, "/")
+		if len(parts) != 2 || !isHeaderName(parts[0]) || !isHeaderName(parts[1]) {
+			return errors.Errorf("invalid content type %q, it must be like text/html or text/*", contentType)
+		}
+	}
+	return nil
+}
+
+func (c *IngressCache) validate() error {
+	if c.TTL != "" {
+		ttl, err := time.ParseDuration(c.TTL)
+		if err != nil || ttl <= 0 {
+			return errors.Errorf("invalid ttl %q, it must be a positive duration like 30s", c.TTL)
+		}
+	}
+	for _, method := range c.Methods {
+		switch strings.ToUpper(method) {
+		case "GET", "HEAD":
+		default:
+			return errors.Errorf("unsupported method %s, only GET and HEAD responses could be cached", method)
+		}
+	}
+	for _, header := range c.KeyHeaders {
+		if !isHeaderName(header) || header == "*" {
+			return errors.Errorf("invalid key header %q", header)
+		}
+	}
+	if c.MaxEntries < 0 {
+		return errors.Errorf("maxEntries %d must not be negative", c.MaxEntries)
+	}
+	if c.MaxEntrySize < 0 {
+		return errors.Errorf("maxEntrySize %d must not be negative", c.MaxEntrySize)
+	}
+	if c.Mode != "" && c.Mode != IngressCacheModeStatic {
+		return errors.Errorf("unsupported mode %s (support %s)", c.Mode, IngressCacheModeStatic)
+	}
+	if c.ClientTTL != "" {
+		ttl, err := time.ParseDuration(c.ClientTTL)
+		if err != nil || ttl < 0 || ttl%time.Second != 0 {
+			return errors.Errorf("invalid clientTTL %q, it must be a non-negative duration of whole seconds like 24h", c.ClientTTL)
+		}
+	}
+	return nil
+}
+
+// EncodingsOrDefault returns the encodings in the order of preference, default is br and gzip.
+func (c *IngressCompression) EncodingsOrDefault() []string {
+	if len(c.Encodings) == 0 {
+		return []string{IngressEncodingBrotli, IngressEncodingGzip}
+	}
+	return c.Encodings
+}
+
+// MinLengthOrDefault returns the min bytes of responses to be compressed, default is 1024.
+func (c *IngressCompression) MinLengthOrDefault() int {
+	if c.MinLength == 0 {
+		return DefaultIngressCompressionMinLength
+	}
+	return c.MinLength
+}
+
+// TTLDuration returns the duration responses are cached, default is 60s,
+// or 1h in the static mode.
+func (c *IngressCache) TTLDuration() time.Duration {
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil || ttl <= 0 {
+		if c.Mode == IngressCacheModeStatic {
+			return DefaultIngressStaticCacheTTL
+		}
+		return DefaultIngressCacheTTL
+	}
+	return ttl
+}
+
+// MaxEntriesOrDefault returns the max number of responses cached, default is 1000.
+func (c *IngressCache) MaxEntriesOrDefault() int {
+	if c.MaxEntries == 0 {
+		return DefaultIngressCacheMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// MaxEntrySizeOrDefault returns the max bytes of a cached response, default is 1MiB.
+func (c *IngressCache) MaxEntrySizeOrDefault() int {
+	if c.MaxEntrySize == 0 {
+		return DefaultIngressCacheMaxEntrySize
+	}
+	return c.MaxEntrySize
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// IngressLimits protect backends from oversized requests and slow clients,
+	// zero values mean no limits.
+	IngressLimits struct {
+		// MaxBodySize is the max bytes of request bodies, larger requests are rejected with 413.
+		MaxBodySize int64 `yaml:"maxBodySize,omitempty" json:"maxBodySize,omitempty" jsonschema:"omitempty,minimum=0"`
+		// MaxHeaderCount is the max number of request headers, more are rejected with 431.
+		MaxHeaderCount int `yaml:"maxHeaderCount,omitempty" json:"maxHeaderCount,omitempty" jsonschema:"omitempty,minimum=0"`
+		// MaxHeaderSize is the max bytes of all request headers, larger ones are rejected with 431.
+		MaxHeaderSize int `yaml:"maxHeaderSize,omitempty" json:"maxHeaderSize,omitempty" jsonschema:"omitempty,minimum=0"`
+		// ReadHeaderTimeout is the duration to read request headers.
+		ReadHeaderTimeout string `yaml:"readHeaderTimeout,omitempty" json:"readHeaderTimeout,omitempty" jsonschema:"omitempty,format=duration"`
+		// ReadTimeout is the duration to read whole requests including bodies.
+		ReadTimeout string `yaml:"readTimeout,omitempty" json:"readTimeout,omitempty" jsonschema:"omitempty,format=duration"`
+		// WriteTimeout is the duration to write responses to clients.
+		WriteTimeout string `yaml:"writeTimeout,omitempty" json:"writeTimeout,omitempty" jsonschema:"omitempty,format=duration"`
+	}
+
+	// IngressPathLimits override limits of the ingress for a path of the rules.
+	// Header limits could not be overridden, since headers are read before
+	// requests are routed to paths.
+	IngressPathLimits struct {
+		// Host and Path must be the same as the ones of a path of the rules.
+		Host         string `yaml:"host,omitempty" json:"host,omitempty" jsonschema:"omitempty"`
+		Path         string `yaml:"path" json:"path" jsonschema:"required"`
+		MaxBodySize  int64  `yaml:"maxBodySize,omitempty" json:"maxBodySize,omitempty" jsonschema:"omitempty,minimum=0"`
+		ReadTimeout  string `yaml:"readTimeout,omitempty" json:"readTimeout,omitempty" jsonschema:"omitempty,format=duration"`
+		WriteTimeout string `yaml:"writeTimeout,omitempty" json:"writeTimeout,omitempty" jsonschema:"omitempty,format=duration"`
+	}
+)
+
+func (l *IngressLimits) validate() error {
+	if l.MaxBodySize < 0 || l.MaxHeaderCount < 0 || l.MaxHeaderSize < 0 {
+		return errors.New("maxBodySize, maxHeaderCount and maxHeaderSize must not be negative")
+	}
+
+	readHeaderTimeout, err := limitTimeout("readHeaderTimeout", l.ReadHeaderTimeout)
+	if err != nil {
+		return err
+	}
+	readTimeout, err := limitTimeout("readTimeout", l.ReadTimeout)
+	if err != nil {
+		return err
+	}
+	if readHeaderTimeout != 0 && readTimeout != 0 && readHeaderTimeout > readTimeout {
+		return errors.Errorf("readHeaderTimeout %s must not be greater than readTimeout %s", l.ReadHeaderTimeout, l.ReadTimeout)
+	}
+	_, err = limitTimeout("writeTimeout", l.WriteTimeout)
+	return err
+}
+
+// validatePathLimits validates limits of paths, paths are the host and
+// path pairs of the rules.
+func validatePathLimits(pathLimits []*IngressPathLimits, paths map[string]bool) error {
+	limited := map[string]bool{}
+	for _, limits := range pathLimits {
+		key := limits.Host + limits.Path
+		if !paths[key] {
+			return errors.Errorf("no path %s of host %q in rules", limits.Path, limits.Host)
+		}
+		if limited[key] {
+			return errors.Errorf("path %s of host %q is duplicated", limits.Path, limits.Host)
+		}
+		limited[key] = true
+
+		if limits.MaxBodySize < 0 {
+			return errors.Errorf("path %s: maxBodySize %d must not be negative", limits.Path, limits.MaxBodySize)
+		}
+		for _, timeout := range []struct{ name, value string }{
+			{"readTimeout", limits.ReadTimeout},
+			{"writeTimeout", limits.WriteTimeout},
+		} {
+			_, err := limitTimeout(timeout.name, timeout.value)
+			if err != nil {
+				return errors.Wrapf(err, "path %s", limits.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// limitTimeout parses the timeout of limits, empty means no timeout.
+func limitTimeout(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, errors.Errorf("invalid %s %q, it must be a positive duration like 30s", name, value)
+	}
+	return timeout, nil
+}
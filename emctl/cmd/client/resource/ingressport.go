@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// IngressPortProtocolTCP forwards TCP connections to the backend.
+	IngressPortProtocolTCP = "tcp"
+	// IngressPortProtocolUDP forwards UDP datagrams to the backend.
+	IngressPortProtocolUDP = "udp"
+
+	// IngressPortTLSModePassthrough passes TLS connections through to the backend,
+	// which terminates TLS itself.
+	IngressPortTLSModePassthrough = "passthrough"
+	// IngressPortTLSModeTerminate terminates TLS in the ingress controller,
+	// and forwards plain connections to the backend.
+	IngressPortTLSModeTerminate = "terminate"
+)
+
+type (
+	// IngressPort describes a L4 port of the ingress controller forwarding
+	// TCP/UDP traffic to a mesh service, e.g. exposing 5432 to a database.
+	IngressPort struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *IngressPortSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// IngressPortSpec describes the port of the ingress controller and its backend
+	IngressPortSpec struct {
+		// Port is the port of the ingress controller, it must be exposed
+		// by emctl install --mesh-ingress-l4-ports.
+		Port     int    `yaml:"port" json:"port" jsonschema:"required,minimum=1,maximum=65535"`
+		Protocol string `yaml:"protocol" json:"protocol" jsonschema:"required,enum=tcp,enum=udp"`
+		// Backend is the mesh service receiving the traffic.
+		Backend string `yaml:"backend" json:"backend" jsonschema:"required"`
+		// BackendPort is the port of instances of the backend, it's Port by default.
+		BackendPort int `yaml:"backendPort,omitempty" json:"backendPort,omitempty" jsonschema:"omitempty,minimum=1,maximum=65535"`
+
+		TLS *IngressPortTLS `yaml:"tls,omitempty" json:"tls,omitempty" jsonschema:"omitempty"`
+	}
+
+	// IngressPortTLS describes TLS of TCP connections of the port
+	IngressPortTLS struct {
+		Mode string `yaml:"mode" json:"mode" jsonschema:"required,enum=passthrough,enum=terminate"`
+		// Hosts are SNI names routed to the backend, several IngressPorts
+		// could share a port by distinct hosts. Empty means all names.
+		Hosts []string `yaml:"hosts,omitempty" json:"hosts,omitempty" jsonschema:"omitempty"`
+		// CertBase64 and KeyBase64 are required by the terminate mode.
+		CertBase64 string `yaml:"certBase64,omitempty" json:"certBase64,omitempty" jsonschema:"omitempty,format=base64"`
+		KeyBase64  string `yaml:"keyBase64,omitempty" json:"keyBase64,omitempty" jsonschema:"omitempty,format=base64"`
+	}
+
+	// IngressPortObject is the IngressPort object stored in the control plane of the EaseMesh
+	IngressPortObject struct {
+		Name string `json:"name"`
+		*IngressPortSpec
+	}
+)
+
+var _ meta.TableObject = &IngressPort{}
+
+// Columns returns the columns of IngressPort.
+func (i *IngressPort) Columns() []*meta.TableColumn {
+	if i.Spec == nil {
+		return nil
+	}
+
+	tlsMode := "none"
+	hosts := "*"
+	if i.Spec.TLS != nil {
+		tlsMode = i.Spec.TLS.Mode
+		if len(i.Spec.TLS.Hosts) != 0 {
+			hosts = strings.Join(i.Spec.TLS.Hosts, ",")
+		}
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Port",
+			Value: strconv.Itoa(i.Spec.Port) + "/" + i.Spec.Protocol,
+		},
+		{
+			Name:  "Backend",
+			Value: i.Spec.Backend + ":" + strconv.Itoa(i.Spec.backendPort()),
+		},
+		{
+			Name:  "TLS",
+			Value: tlsMode,
+		},
+		{
+			Name:  "Hosts",
+			Value: hosts,
+		},
+	}
+}
+
+func (s *IngressPortSpec) backendPort() int {
+	if s.BackendPort != 0 {
+		return s.BackendPort
+	}
+	return s.Port
+}
+
+// Validate validates the IngressPort before it's applied.
+func (i *IngressPort) Validate() error {
+	if i.Spec == nil {
+		return nil
+	}
+
+	if i.Spec.Port < 1 || i.Spec.Port > 65535 {
+		return errors.Errorf("port %d must be in [1, 65535]", i.Spec.Port)
+	}
+	if i.Spec.BackendPort < 0 || i.Spec.BackendPort > 65535 {
+		return errors.Errorf("backendPort %d must be in [1, 65535]", i.Spec.BackendPort)
+	}
+	switch i.Spec.Protocol {
+	case IngressPortProtocolTCP, IngressPortProtocolUDP:
+	default:
+		return errors.Errorf("unsupported protocol %q (support %s, %s)",
+			i.Spec.Protocol, IngressPortProtocolTCP, IngressPortProtocolUDP)
+	}
+	if i.Spec.Backend == "" {
+		return errors.New("backend is required")
+	}
+
+	tls := i.Spec.TLS
+	if tls == nil {
+		return nil
+	}
+	if i.Spec.Protocol == IngressPortProtocolUDP {
+		return errors.New("tls: only supported by the tcp protocol")
+	}
+	switch tls.Mode {
+	case IngressPortTLSModePassthrough:
+		if tls.CertBase64 != "" || tls.KeyBase64 != "" {
+			return errors.New("tls: certBase64 and keyBase64 must be empty in the passthrough mode")
+		}
+	case IngressPortTLSModeTerminate:
+		if tls.CertBase64 == "" || tls.KeyBase64 == "" {
+			return errors.New("tls: certBase64 and keyBase64 are required in the terminate mode")
+		}
+	default:
+		return errors.Errorf("tls: unsupported mode %q (support %s, %s)",
+			tls.Mode, IngressPortTLSModePassthrough, IngressPortTLSModeTerminate)
+	}
+
+	return nil
+}
+
+// ToObject converts an IngressPort resource to the object of the control plane
+func (i *IngressPort) ToObject() *IngressPortObject {
+	result := &IngressPortObject{
+		Name:            i.Name(),
+		IngressPortSpec: &IngressPortSpec{},
+	}
+	if i.Spec != nil {
+		result.IngressPortSpec = i.Spec
+	}
+	return result
+}
+
+// ToIngressPort converts an object of the control plane to an IngressPort resource
+func ToIngressPort(object *IngressPortObject) *IngressPort {
+	result := &IngressPort{
+		Spec: object.IngressPortSpec,
+	}
+	result.MeshResource = NewIngressPortResource(DefaultAPIVersion, object.Name)
+	return result
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaintenanceModeMeshWide is the name of the maintenance mode of the whole mesh.
+	MaintenanceModeMeshWide = "mesh"
+
+	// DefaultMaintenanceStatusCode is the default status code of the static response.
+	DefaultMaintenanceStatusCode = http.StatusServiceUnavailable
+)
+
+// headerNamePattern is the legal name of HTTP headers.
+var headerNamePattern = cookieNamePattern
+
+type (
+	// MaintenanceMode describes a kill switch which makes sidecars and ingresses
+	// return a static response instead of forwarding requests, for a service or
+	// the whole mesh. It's used during incident response and migrations.
+	MaintenanceMode struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *MaintenanceModeSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// MaintenanceModeSpec describes the scope and the static response.
+	MaintenanceModeSpec struct {
+		// Service is the mesh service in maintenance, empty means the whole mesh.
+		Service string `yaml:"service,omitempty" json:"service,omitempty" jsonschema:"omitempty"`
+		// StatusCode is 503 by default.
+		StatusCode int               `yaml:"statusCode,omitempty" json:"statusCode,omitempty" jsonschema:"omitempty,minimum=200,maximum=599"`
+		Headers    map[string]string `yaml:"headers,omitempty" json:"headers,omitempty" jsonschema:"omitempty"`
+		Body       string            `yaml:"body,omitempty" json:"body,omitempty" jsonschema:"omitempty"`
+		// RetryAfter is the seconds in the Retry-After header, zero means no header.
+		RetryAfter int `yaml:"retryAfter,omitempty" json:"retryAfter,omitempty" jsonschema:"omitempty,minimum=0"`
+		// Reason is shown in emctl and logs of sidecars, not in responses.
+		Reason string `yaml:"reason,omitempty" json:"reason,omitempty" jsonschema:"omitempty"`
+	}
+
+	// MaintenanceModeObject is the MaintenanceMode object stored in the control plane of the EaseMesh
+	MaintenanceModeObject struct {
+		Name string `json:"name"`
+		*MaintenanceModeSpec
+	}
+)
+
+var _ meta.TableObject = &MaintenanceMode{}
+
+// Columns returns the columns of MaintenanceMode.
+func (m *MaintenanceMode) Columns() []*meta.TableColumn {
+	if m.Spec == nil {
+		return nil
+	}
+
+	service := m.Spec.Service
+	if service == "" {
+		service = "*"
+	}
+
+	retryAfter := "-"
+	if m.Spec.RetryAfter != 0 {
+		retryAfter = strconv.Itoa(m.Spec.RetryAfter) + "s"
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Service",
+			Value: service,
+		},
+		{
+			Name:  "StatusCode",
+			Value: strconv.Itoa(m.Spec.StatusCodeOrDefault()),
+		},
+		{
+			Name:  "RetryAfter",
+			Value: retryAfter,
+		},
+		{
+			Name:  "Reason",
+			Value: m.Spec.Reason,
+		},
+	}
+}
+
+// StatusCodeOrDefault returns the status code of the static response.
+func (s *MaintenanceModeSpec) StatusCodeOrDefault() int {
+	if s.StatusCode == 0 {
+		return DefaultMaintenanceStatusCode
+	}
+	return s.StatusCode
+}
+
+// Validate validates the MaintenanceMode before it's applied.
+func (m *MaintenanceMode) Validate() error {
+	if m.Spec == nil {
+		return errors.New("spec is required")
+	}
+
+	// NOTE: The name tells the scope for emctl maintenance, an empty service
+	// means the whole mesh, which must not be applied by mistake.
+	switch {
+	case m.Spec.Service == "" && m.Name() != MaintenanceModeMeshWide:
+		return errors.Errorf("name %s must be the service, or %s for the whole mesh without the service",
+			m.Name(), MaintenanceModeMeshWide)
+	case m.Spec.Service == MaintenanceModeMeshWide:
+		return errors.Errorf("service name %s is reserved for the whole mesh", MaintenanceModeMeshWide)
+	case m.Spec.Service != "" && m.Name() != m.Spec.Service:
+		return errors.Errorf("name %s must be the same as the service %s", m.Name(), m.Spec.Service)
+	}
+
+	if m.Spec.StatusCode != 0 && (m.Spec.StatusCode < 200 || m.Spec.StatusCode > 599) {
+		return errors.Errorf("status code %d out of range [200, 599]", m.Spec.StatusCode)
+	}
+	if m.Spec.RetryAfter < 0 {
+		return errors.Errorf("negative retry after %d", m.Spec.RetryAfter)
+	}
+
+	names := []string{}
+	for name := range m.Spec.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !headerNamePattern.MatchString(name) {
+			return errors.Errorf("invalid header name %q", name)
+		}
+		if strings.EqualFold(name, "Retry-After") {
+			return errors.New("header Retry-After is set by retryAfter")
+		}
+		if strings.ContainsAny(m.Spec.Headers[name], "\r\n") {
+			return errors.Errorf("invalid value of header %s", name)
+		}
+	}
+
+	return nil
+}
+
+// ToObject converts a MaintenanceMode resource to the object of the control plane
+func (m *MaintenanceMode) ToObject() *MaintenanceModeObject {
+	result := &MaintenanceModeObject{
+		Name:                m.Name(),
+		MaintenanceModeSpec: &MaintenanceModeSpec{},
+	}
+	if m.Spec != nil {
+		result.MaintenanceModeSpec = m.Spec
+	}
+	return result
+}
+
+// ToMaintenanceMode converts an object of the control plane to a MaintenanceMode resource
+func ToMaintenanceMode(object *MaintenanceModeObject) *MaintenanceMode {
+	result := &MaintenanceMode{
+		Spec: object.MaintenanceModeSpec,
+	}
+	result.MeshResource = NewMaintenanceModeResource(DefaultAPIVersion, object.Name)
+	return result
+}
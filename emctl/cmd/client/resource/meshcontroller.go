@@ -52,12 +52,27 @@ type (
 		// IngressPort is the port for http server in mesh ingress
 		IngressPort int `yaml:"ingressPort" jsonschema:"required"`
 
+		// RevisionHistoryLimit is the number of revisions kept for every mesh resource.
+		RevisionHistoryLimit int `yaml:"revisionHistoryLimit,omitempty" jsonschema:"omitempty"`
+
+		// InstanceExpiry is the duration without heartbeats after which a service instance is marked OUT_OF_SERVICE.
+		InstanceExpiry string `yaml:"instanceExpiry,omitempty" jsonschema:"omitempty,format=duration"`
+
+		// DeregistrationGracePeriod is the duration an expired service instance is kept before it's deregistered.
+		DeregistrationGracePeriod string `yaml:"deregistrationGracePeriod,omitempty" jsonschema:"omitempty,format=duration"`
+
 		// ExternalServiceRegistry is the external service registry name.
 		ExternalServiceRegistry string `yaml:"externalServiceRegistry" jsonschema:"omitempty"`
 		CleanExternalRegistry   bool   `yaml:"cleanExternalRegistry"`
 
 		Security *Security `yaml:"security" jsonschema:"omitempty"`
 
+		// SecretStore resolves secret references of mesh resources besides Kubernetes Secrets.
+		SecretStore *SecretStore `yaml:"secretStore,omitempty" jsonschema:"omitempty"`
+
+		// IngressACME obtains certificates of ingress hosts by ACME.
+		IngressACME *IngressACMEAccount `yaml:"ingressACME,omitempty" jsonschema:"omitempty"`
+
 		// Sidecar injection relevant config.
 		ImageRegistryURL          string `yaml:"imageRegistryURL" jsonschema:"omitempty"`
 		ImagePullPolicy           string `yaml:"imagePullPolicy" jsonschema:"omitempty"`
@@ -77,6 +92,32 @@ type (
 		AppCertTTL  string `yaml:"appCertTTL" jsonschema:"required,format=duration"`
 	}
 
+	// SecretStore is the spec of stores resolving secret references.
+	SecretStore struct {
+		Vault *VaultSecretStore `yaml:"vault,omitempty" jsonschema:"omitempty"`
+	}
+
+	// VaultSecretStore is the spec of Vault logged in with the Kubernetes auth method.
+	VaultSecretStore struct {
+		Address  string `yaml:"address" jsonschema:"required"`
+		AuthPath string `yaml:"authPath" jsonschema:"required"`
+		Role     string `yaml:"role" jsonschema:"required"`
+	}
+
+	// IngressACMEAccount is the spec of the ACME client of ingress controllers.
+	IngressACMEAccount struct {
+		Email        string           `yaml:"email" jsonschema:"required"`
+		DirectoryURL string           `yaml:"directoryURL" jsonschema:"required"`
+		DNSProvider  *ACMEDNSProvider `yaml:"dnsProvider,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ACMEDNSProvider is the spec of the DNS provider solving dns-01 challenges.
+	ACMEDNSProvider struct {
+		Name string `yaml:"name" jsonschema:"required"`
+		// SecretName is the Secret in the mesh namespace holding options of the provider.
+		SecretName string `yaml:"secretName" jsonschema:"required"`
+	}
+
 	// MonitorMTLS is the spec of mTLS specification of monitor.
 	MonitorMTLS struct {
 		Enabled  bool   `yaml:"enabled" jsonschema:"required"`
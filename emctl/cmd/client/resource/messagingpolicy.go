@@ -0,0 +1,226 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package resource
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MessagingProtocolKafka is the Kafka protocol.
+	MessagingProtocolKafka = "kafka"
+	// MessagingProtocolMQTT is the MQTT protocol.
+	MessagingProtocolMQTT = "mqtt"
+)
+
+// kafkaTopicPattern is the legal name of Kafka topics.
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+type (
+	// MessagingPolicy describes how the sidecar of a service passes the
+	// messaging (Kafka, MQTT) traffic through to the brokers, the application
+	// connects to the local port of the sidecar instead of the brokers.
+	MessagingPolicy struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *MessagingPolicySpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// MessagingPolicySpec describes the brokers, the topics to observe and the TLS
+	// settings of the messaging traffic of a service.
+	MessagingPolicySpec struct {
+		// Service is the mesh service whose sidecar proxies the messaging traffic.
+		Service  string `yaml:"service" json:"service" jsonschema:"required"`
+		Protocol string `yaml:"protocol" json:"protocol" jsonschema:"required,enum=kafka,enum=mqtt"`
+		// Brokers are addresses (host:port) of the brokers.
+		Brokers []string `yaml:"brokers" json:"brokers" jsonschema:"required"`
+		// ListenPort is the port of the sidecar which the application connects to.
+		ListenPort int `yaml:"listenPort" json:"listenPort" jsonschema:"required,minimum=1,maximum=65535"`
+		// Topics are the topics having per-topic metrics, metrics of other topics
+		// are aggregated. MQTT topic filters with wildcards (+, #) are supported.
+		Topics []string `yaml:"topics,omitempty" json:"topics,omitempty" jsonschema:"omitempty"`
+
+		Metrics *MessagingMetrics   `yaml:"metrics,omitempty" json:"metrics,omitempty" jsonschema:"omitempty"`
+		TLS     *ExternalServiceTLS `yaml:"tls,omitempty" json:"tls,omitempty" jsonschema:"omitempty"`
+	}
+
+	// MessagingMetrics describes the reporting of connection metrics, e.g.
+	// connections, bytes and messages produced/consumed of every topic.
+	MessagingMetrics struct {
+		Enabled bool `yaml:"enabled" json:"enabled" jsonschema:"required"`
+		// Interval is 30s by default.
+		Interval string `yaml:"interval,omitempty" json:"interval,omitempty" jsonschema:"omitempty,format=duration"`
+	}
+
+	// MessagingPolicyObject is the MessagingPolicy object stored in the control plane of the EaseMesh
+	MessagingPolicyObject struct {
+		Name string `json:"name"`
+		*MessagingPolicySpec
+	}
+)
+
+var _ meta.TableObject = &MessagingPolicy{}
+
+// Columns returns the columns of MessagingPolicy.
+func (m *MessagingPolicy) Columns() []*meta.TableColumn {
+	if m.Spec == nil {
+		return nil
+	}
+
+	topics := "*"
+	if len(m.Spec.Topics) != 0 {
+		topics = strings.Join(m.Spec.Topics, ",")
+	}
+
+	tlsMode := ExternalServiceTLSModeDisable
+	if m.Spec.TLS != nil {
+		tlsMode = m.Spec.TLS.Mode
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Service",
+			Value: m.Spec.Service,
+		},
+		{
+			Name:  "Protocol",
+			Value: m.Spec.Protocol,
+		},
+		{
+			Name:  "Brokers",
+			Value: strings.Join(m.Spec.Brokers, ","),
+		},
+		{
+			Name:  "Port",
+			Value: strconv.Itoa(m.Spec.ListenPort),
+		},
+		{
+			Name:  "Topics",
+			Value: topics,
+		},
+		{
+			Name:  "TLS",
+			Value: tlsMode,
+		},
+	}
+}
+
+// Validate validates the MessagingPolicy before it's applied.
+func (m *MessagingPolicy) Validate() error {
+	if m.Spec == nil {
+		return nil
+	}
+	if m.Spec.Service == "" {
+		return errors.New("service is required")
+	}
+
+	switch m.Spec.Protocol {
+	case MessagingProtocolKafka, MessagingProtocolMQTT:
+	default:
+		return errors.Errorf("unsupported protocol %q (support %s, %s)",
+			m.Spec.Protocol, MessagingProtocolKafka, MessagingProtocolMQTT)
+	}
+
+	if len(m.Spec.Brokers) == 0 {
+		return errors.New("brokers are required")
+	}
+	for _, broker := range m.Spec.Brokers {
+		_, port, err := net.SplitHostPort(broker)
+		if err != nil {
+			return errors.Errorf("invalid broker %q, it must be host:port", broker)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return errors.Errorf("invalid port of broker %q", broker)
+		}
+	}
+
+	if m.Spec.ListenPort < 1 || m.Spec.ListenPort > 65535 {
+		return errors.Errorf("listenPort %d must be in [1, 65535]", m.Spec.ListenPort)
+	}
+
+	for _, topic := range m.Spec.Topics {
+		err := validateMessagingTopic(m.Spec.Protocol, topic)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Spec.Metrics != nil && m.Spec.Metrics.Interval != "" {
+		interval, err := time.ParseDuration(m.Spec.Metrics.Interval)
+		if err != nil || interval <= 0 {
+			return errors.Errorf("metrics: invalid interval %q, it must be a positive duration like 30s", m.Spec.Metrics.Interval)
+		}
+	}
+
+	if m.Spec.TLS != nil {
+		return m.Spec.TLS.Validate()
+	}
+
+	return nil
+}
+
+// validateMessagingTopic validates a topic name of Kafka, or a topic filter of MQTT.
+func validateMessagingTopic(protocol, topic string) error {
+	if protocol == MessagingProtocolKafka {
+		if !kafkaTopicPattern.MatchString(topic) {
+			return errors.Errorf("invalid kafka topic %q", topic)
+		}
+		return nil
+	}
+
+	if topic == "" {
+		return errors.New("empty mqtt topic")
+	}
+	levels := strings.Split(topic, "/")
+	for i, level := range levels {
+		switch {
+		case level == "#" && i != len(levels)-1:
+			return errors.Errorf("invalid mqtt topic %q, # must be the last level", topic)
+		case level != "#" && level != "+" && strings.ContainsAny(level, "#+"):
+			return errors.Errorf("invalid mqtt topic %q, wildcards must occupy a whole level", topic)
+		}
+	}
+	return nil
+}
+
+// ToObject converts a MessagingPolicy resource to the object of the control plane
+func (m *MessagingPolicy) ToObject() *MessagingPolicyObject {
+	result := &MessagingPolicyObject{
+		Name:                m.Name(),
+		MessagingPolicySpec: &MessagingPolicySpec{},
+	}
+	if m.Spec != nil {
+		result.MessagingPolicySpec = m.Spec
+	}
+	return result
+}
+
+// ToMessagingPolicy converts an object of the control plane to a MessagingPolicy resource
+func ToMessagingPolicy(object *MessagingPolicyObject) *MessagingPolicy {
+	result := &MessagingPolicy{
+		Spec: object.MessagingPolicySpec,
+	}
+	result.MeshResource = NewMessagingPolicyResource(DefaultAPIVersion, object.Name)
+	return result
+}
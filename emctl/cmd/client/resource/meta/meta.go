@@ -27,8 +27,12 @@ type (
 
 	// MetaData is meta data for resources of the EaseMesh
 	MetaData struct {
-		Name   string            `yaml:"name" yaml:"name" jsonschema:"required"`
-		Labels map[string]string `yaml:"labels,omitempty" yaml:"labels,omitempty" jsonschema:"omitempty"`
+		Name        string            `yaml:"name" yaml:"name" jsonschema:"required"`
+		Labels      map[string]string `yaml:"labels,omitempty" yaml:"labels,omitempty" jsonschema:"omitempty"`
+		Annotations map[string]string `yaml:"annotations,omitempty" yaml:"annotations,omitempty" jsonschema:"omitempty"`
+		// ResourceVersion is the version of the resource got from the control plane,
+		// updating the resource with a stale one fails.
+		ResourceVersion string `yaml:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty" jsonschema:"omitempty"`
 	}
 
 	// MeshResource holds common information for a resource of the EaseMesh
@@ -43,6 +47,15 @@ type (
 		Kind() string
 		APIVersion() string
 		Labels() map[string]string
+		Annotations() map[string]string
+		ResourceVersion() string
+	}
+
+	// MetaDataSetter sets the meta data of an EaseMesh object
+	MetaDataSetter interface {
+		SetLabels(labels map[string]string)
+		SetAnnotations(annotations map[string]string)
+		SetResourceVersion(version string)
 	}
 	// TableColumn is the user-defined table column.
 	TableColumn struct {
@@ -55,6 +68,12 @@ type (
 	TableObject interface {
 		Columns() []*TableColumn
 	}
+
+	// WideTableObject is the object which has extra
+	// columns in the wide table, following its columns.
+	WideTableObject interface {
+		WideColumns() []*TableColumn
+	}
 )
 
 // Name returns name of the EaseMesh resource
@@ -76,3 +95,28 @@ func (m *MeshResource) APIVersion() string {
 func (m *MeshResource) Labels() map[string]string {
 	return m.MetaData.Labels
 }
+
+// Annotations returns annotations of the EaseMesh resource
+func (m *MeshResource) Annotations() map[string]string {
+	return m.MetaData.Annotations
+}
+
+// SetLabels sets labels of the EaseMesh resource
+func (m *MeshResource) SetLabels(labels map[string]string) {
+	m.MetaData.Labels = labels
+}
+
+// SetAnnotations sets annotations of the EaseMesh resource
+func (m *MeshResource) SetAnnotations(annotations map[string]string) {
+	m.MetaData.Annotations = annotations
+}
+
+// ResourceVersion returns the resource version of the EaseMesh resource
+func (m *MeshResource) ResourceVersion() string {
+	return m.MetaData.ResourceVersion
+}
+
+// SetResourceVersion sets the resource version of the EaseMesh resource
+func (m *MeshResource) SetResourceVersion(version string) {
+	m.MetaData.ResourceVersion = version
+}
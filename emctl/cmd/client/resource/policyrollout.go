@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// PolicyRollout describes a policy of a service being propagated to a
+	// percentage of its sidecars only. The control plane serves the staged
+	// policy to the sidecars selected by the percentage, and the current
+	// policy to the others, until the rollout is removed.
+	PolicyRollout struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *PolicyRolloutSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// PolicyRolloutSpec describes the staged policy and its progress.
+	PolicyRolloutSpec struct {
+		Service    string `yaml:"service" json:"service" jsonschema:"required"`
+		TargetKind string `yaml:"targetKind" json:"targetKind" jsonschema:"required"`
+		TargetName string `yaml:"targetName" json:"targetName" jsonschema:"required"`
+		// Policy is the staged policy in the form stored in the control plane.
+		Policy map[string]interface{} `yaml:"policy" json:"policy" jsonschema:"required"`
+		// Stages are the ascending percentages of sidecars, ending with 100.
+		Stages []int `yaml:"stages,omitempty" json:"stages,omitempty" jsonschema:"omitempty"`
+		// Percentage is the percentage of sidecars of the current stage.
+		Percentage int `yaml:"percentage" json:"percentage" jsonschema:"required,minimum=1,maximum=100"`
+	}
+
+	// PolicyRolloutObject is the PolicyRollout object stored in the control plane of the EaseMesh
+	PolicyRolloutObject struct {
+		Name string `json:"name"`
+		*PolicyRolloutSpec
+	}
+)
+
+var _ meta.TableObject = &PolicyRollout{}
+
+// PolicyRolloutName returns the name of the rollout of the resource,
+// there is at most one rollout of a resource at a time.
+func PolicyRolloutName(kind, name string) string {
+	return strings.ToLower(kind) + "-" + name
+}
+
+// Columns returns the columns of PolicyRollout.
+func (p *PolicyRollout) Columns() []*meta.TableColumn {
+	if p.Spec == nil {
+		return nil
+	}
+
+	stages := []string{}
+	for _, stage := range p.Spec.Stages {
+		stages = append(stages, strconv.Itoa(stage)+"%")
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Service",
+			Value: p.Spec.Service,
+		},
+		{
+			Name:  "Target",
+			Value: p.Spec.TargetKind + "/" + p.Spec.TargetName,
+		},
+		{
+			Name:  "Percentage",
+			Value: strconv.Itoa(p.Spec.Percentage) + "%",
+		},
+		{
+			Name:  "Stages",
+			Value: strings.Join(stages, ","),
+		},
+	}
+}
+
+// Selects reports whether the sidecar of the instance is served the staged
+// policy, the selection is stable as the percentage grows.
+func (s *PolicyRolloutSpec) Selects(instanceID string) bool {
+	return CanaryBucket(instanceID) < s.Percentage
+}
+
+// Validate validates the PolicyRollout before it's applied.
+func (p *PolicyRollout) Validate() error {
+	if p.Spec == nil {
+		return nil
+	}
+
+	switch {
+	case p.Spec.Service == "":
+		return errors.New("service is required")
+	case p.Spec.TargetKind == "" || p.Spec.TargetName == "":
+		return errors.New("target kind and name are required")
+	case p.Spec.Policy == nil:
+		return errors.New("policy is required")
+	case p.Spec.Percentage <= 0 || p.Spec.Percentage > 100:
+		return errors.Errorf("percentage %d out of range (0, 100]", p.Spec.Percentage)
+	}
+
+	err := ValidateRolloutStages(p.Spec.Stages)
+	if err != nil {
+		return err
+	}
+	if len(p.Spec.Stages) == 0 {
+		return nil
+	}
+	for _, stage := range p.Spec.Stages {
+		if stage == p.Spec.Percentage {
+			return nil
+		}
+	}
+	return errors.Errorf("percentage %d is not one of the stages", p.Spec.Percentage)
+}
+
+// ValidateRolloutStages validates the percentages of stages, which must be
+// ascending in (0, 100] and end with 100.
+func ValidateRolloutStages(stages []int) error {
+	for i, stage := range stages {
+		if stage <= 0 || stage > 100 {
+			return errors.Errorf("stage %d%% out of range (0%%, 100%%]", stage)
+		}
+		if i > 0 && stage <= stages[i-1] {
+			return errors.Errorf("stage %d%% is not greater than the previous one", stage)
+		}
+	}
+	if len(stages) > 0 && stages[len(stages)-1] != 100 {
+		return errors.New("the last stage must be 100%")
+	}
+	return nil
+}
+
+// ToObject converts a PolicyRollout resource to the object of the control plane
+func (p *PolicyRollout) ToObject() *PolicyRolloutObject {
+	result := &PolicyRolloutObject{
+		Name:              p.Name(),
+		PolicyRolloutSpec: &PolicyRolloutSpec{},
+	}
+	if p.Spec != nil {
+		result.PolicyRolloutSpec = p.Spec
+	}
+	return result
+}
+
+// ToPolicyRollout converts an object of the control plane to a PolicyRollout resource
+func ToPolicyRollout(object *PolicyRolloutObject) *PolicyRollout {
+	result := &PolicyRollout{
+		Spec: object.PolicyRolloutSpec,
+	}
+	result.MeshResource = NewPolicyRolloutResource(DefaultAPIVersion, object.Name)
+	return result
+}
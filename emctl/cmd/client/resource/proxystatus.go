@@ -0,0 +1,41 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+const (
+	// ProxySynced means the sidecar has applied the latest configuration.
+	ProxySynced = "SYNCED"
+	// ProxyStale means the sidecar lags behind the latest configuration.
+	ProxyStale = "STALE"
+	// ProxyDisconnected means the sidecar hasn't reported heartbeats for the instance expiry.
+	ProxyDisconnected = "DISCONNECTED"
+)
+
+// ProxyStatus is the configuration status reported by a sidecar connected to the
+// control plane, proxy statuses are read-only which could not be applied or deleted.
+type ProxyStatus struct {
+	ServiceName string `yaml:"serviceName" json:"serviceName"`
+	InstanceID  string `yaml:"instanceID" json:"instanceID"`
+	IP          string `yaml:"ip" json:"ip"`
+	// AckedVersion is the configuration version the sidecar has applied.
+	AckedVersion int64 `yaml:"ackedVersion" json:"ackedVersion"`
+	// LatestVersion is the latest configuration version of the sidecar in the control plane.
+	LatestVersion int64 `yaml:"latestVersion" json:"latestVersion"`
+	// LastHeartbeatTime is in RFC3339.
+	LastHeartbeatTime string `yaml:"lastHeartbeatTime" json:"lastHeartbeatTime"`
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// serviceNamedKinds are kinds of policies named by their services.
+var serviceNamedKinds = map[string]bool{
+	KindLoadBalance:               true,
+	KindResilience:                true,
+	KindCanary:                    true,
+	KindMock:                      true,
+	KindObservabilityMetrics:      true,
+	KindObservabilityTracings:     true,
+	KindObservabilityOutputServer: true,
+}
+
+// Reference is a resource referenced by another one.
+type Reference struct {
+	Kind string
+	Name string
+	// HTTPOnly names the HTTP-only policies applied to the referenced
+	// service, which are invalid if the service is a tcp one.
+	HTTPOnly string
+}
+
+// String returns the reference in the form of <kind>/<name>.
+func (r *Reference) String() string {
+	return r.Kind + "/" + r.Name
+}
+
+// References returns resources referenced by the object, e.g. the tenant of
+// a service, services selected by a service canary and backends of an ingress.
+func References(mo meta.MeshObject) []*Reference {
+	service := func(name, httpOnly string) *Reference {
+		return &Reference{Kind: KindService, Name: name, HTTPOnly: httpOnly}
+	}
+	tenant := func(name string) *Reference {
+		return &Reference{Kind: KindTenant, Name: name}
+	}
+
+	refs := []*Reference{}
+	if serviceNamedKinds[mo.Kind()] {
+		httpOnly := ""
+		switch o := mo.(type) {
+		case *Canary:
+			httpOnly = "canary"
+		case *Mock:
+			httpOnly = "mock"
+		case *Resilience:
+			httpOnly = strings.Join(o.HTTPOnlyPolicies(), ", ")
+		}
+		refs = append(refs, service(mo.Name(), httpOnly))
+	}
+
+	switch o := mo.(type) {
+	case *Service:
+		if o.Spec != nil && o.Spec.RegisterTenant != "" {
+			refs = append(refs, tenant(o.Spec.RegisterTenant))
+		}
+	case *Tenant:
+		if o.Spec != nil {
+			for _, name := range o.Spec.Services {
+				refs = append(refs, service(name, ""))
+			}
+		}
+	case *TenantPolicy:
+		refs = append(refs, tenant(o.Name()))
+	case *ServiceCanary:
+		if o.Spec != nil && o.Spec.Selector != nil {
+			for _, name := range o.Spec.Selector.MatchServices {
+				refs = append(refs, service(name, "serviceCanary"))
+			}
+		}
+	case *Ingress:
+		if o.Spec != nil {
+			for _, rule := range o.Spec.Rules {
+				for _, path := range rule.Paths {
+					refs = append(refs, service(path.Backend, "ingress path "+path.Path))
+				}
+			}
+		}
+	case *IngressPort:
+		if o.Spec != nil {
+			refs = append(refs, service(o.Spec.Backend, ""))
+		}
+	case *WAFPolicy:
+		if o.Spec != nil {
+			for _, target := range o.Spec.Targets {
+				refs = append(refs, &Reference{Kind: KindIngress, Name: target.Ingress})
+			}
+		}
+	case *SLO:
+		if o.Spec != nil {
+			refs = append(refs, service(o.Spec.Service, ""))
+		}
+	case *AlertRule:
+		if o.Spec != nil && o.Spec.Service != "" {
+			refs = append(refs, service(o.Spec.Service, ""))
+		}
+		if o.Spec != nil && o.Spec.Tenant != "" {
+			refs = append(refs, tenant(o.Spec.Tenant))
+		}
+	case *MaintenanceMode:
+		if o.Spec != nil && o.Spec.Service != "" {
+			refs = append(refs, service(o.Spec.Service, ""))
+		}
+	case *MessagingPolicy:
+		if o.Spec != nil {
+			refs = append(refs, service(o.Spec.Service, ""))
+		}
+	case *PolicyRollout:
+		if o.Spec != nil {
+			refs = append(refs, service(o.Spec.Service, ""))
+		}
+	}
+	return refs
+}
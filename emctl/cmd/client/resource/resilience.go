@@ -18,27 +18,217 @@
 package resource
 
 import (
+	"net/http"
+	"regexp"
+	"time"
+
 	"github.com/megaease/easemesh-api/v1alpha1"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultHedgingMaxAttempts is the default max attempts of hedging including the original request.
+	DefaultHedgingMaxAttempts = 2
+	// MaxHedgingMaxAttempts is the upper limit of max attempts of hedging.
+	MaxHedgingMaxAttempts = 5
+	// DefaultHedgingBudgetPercent is the default percentage of hedged requests over all requests.
+	DefaultHedgingBudgetPercent = 10
 )
 
+// hedgingMethods are read-only methods which are safe to be sent more than once.
+var hedgingMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
 type (
 	// Resilience describes resilience resource of the EaseMesh
 	Resilience struct {
 		meta.MeshResource `yaml:",inline"`
-		Spec              *v1alpha1.Resilience `yaml:"spec" jsonschema:"required"`
+		Spec              *ResilienceSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// ResilienceSpec is the v1alpha1.Resilience with hedging, which is kept
+	// by the control plane along with the resilience of the service.
+	ResilienceSpec struct {
+		v1alpha1.Resilience `yaml:",inline"`
+		Hedging             *Hedging `yaml:"hedging,omitempty" json:"hedging,omitempty" jsonschema:"omitempty"`
+	}
+
+	// Hedging sends another request to another instance if the response of the
+	// request doesn't arrive after the delay, and takes the first response.
+	// It's only for latency-sensitive read-only routes.
+	Hedging struct {
+		Policies []*HedgingPolicy  `yaml:"policies" json:"policies" jsonschema:"required"`
+		URLs     []*HedgingURLRule `yaml:"urls" json:"urls" jsonschema:"required"`
+	}
+
+	// HedgingPolicy is a hedging policy referred by URL rules.
+	HedgingPolicy struct {
+		Name string `yaml:"name" json:"name" jsonschema:"required"`
+		// Delay is the duration after which a hedged request is sent.
+		Delay string `yaml:"delay" json:"delay" jsonschema:"required,format=duration"`
+		// MaxAttempts includes the original request, 2 by default.
+		MaxAttempts int `yaml:"maxAttempts,omitempty" json:"maxAttempts,omitempty" jsonschema:"omitempty,minimum=2,maximum=5"`
+		// BudgetPercent limits hedged requests to the percentage of all requests, 10 by default.
+		BudgetPercent int `yaml:"budgetPercent,omitempty" json:"budgetPercent,omitempty" jsonschema:"omitempty,minimum=1,maximum=100"`
+		// MinBudgetPerSecond is the number of hedged requests per second allowed
+		// regardless of the percentage, so hedging works for low traffic.
+		MinBudgetPerSecond int `yaml:"minBudgetPerSecond,omitempty" json:"minBudgetPerSecond,omitempty" jsonschema:"omitempty,minimum=0"`
+	}
+
+	// HedgingURLRule applies the hedging policy to requests of the methods and the URL.
+	HedgingURLRule struct {
+		Methods   []string    `yaml:"methods" json:"methods" jsonschema:"required"`
+		URL       *HedgingURL `yaml:"url" json:"url" jsonschema:"required"`
+		PolicyRef string      `yaml:"policyRef" json:"policyRef" jsonschema:"required"`
+	}
+
+	// HedgingURL matches the path of requests, exactly one of them is required.
+	HedgingURL struct {
+		Exact  string `yaml:"exact,omitempty" json:"exact,omitempty" jsonschema:"omitempty"`
+		Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty" jsonschema:"omitempty"`
+		Regex  string `yaml:"regex,omitempty" json:"regex,omitempty" jsonschema:"omitempty"`
 	}
 )
 
-// ToV1Alpha1 converts a Resilience resource to v1alpha1.Resilience
+// Validate validates the Resilience before it's applied.
+func (r *Resilience) Validate() error {
+	if r.Spec == nil || r.Spec.Hedging == nil {
+		return nil
+	}
+
+	err := r.Spec.Hedging.validate()
+	if err != nil {
+		return errors.Wrap(err, "hedging")
+	}
+	return nil
+}
+
+func (h *Hedging) validate() error {
+	policies := map[string]bool{}
+	for _, p := range h.Policies {
+		if p.Name == "" {
+			return errors.New("policy name is required")
+		}
+		if policies[p.Name] {
+			return errors.Errorf("duplicated policy %s", p.Name)
+		}
+		policies[p.Name] = true
+
+		delay, err := time.ParseDuration(p.Delay)
+		if err != nil || delay <= 0 {
+			return errors.Errorf("policy %s: invalid delay %q, want a positive duration", p.Name, p.Delay)
+		}
+		if p.MaxAttempts != 0 && (p.MaxAttempts < 2 || p.MaxAttempts > MaxHedgingMaxAttempts) {
+			return errors.Errorf("policy %s: max attempts %d out of range [2, %d]", p.Name, p.MaxAttempts, MaxHedgingMaxAttempts)
+		}
+		if p.BudgetPercent < 0 || p.BudgetPercent > 100 {
+			return errors.Errorf("policy %s: budget percent %d out of range [1, 100]", p.Name, p.BudgetPercent)
+		}
+		if p.MinBudgetPerSecond < 0 {
+			return errors.Errorf("policy %s: negative min budget per second %d", p.Name, p.MinBudgetPerSecond)
+		}
+	}
+
+	if len(h.URLs) == 0 {
+		return errors.New("urls are required")
+	}
+	for i, u := range h.URLs {
+		if !policies[u.PolicyRef] {
+			return errors.Errorf("url %d: policy %q not found", i, u.PolicyRef)
+		}
+		if len(u.Methods) == 0 {
+			return errors.Errorf("url %d: methods are required", i)
+		}
+		for _, method := range u.Methods {
+			if !hedgingMethods[method] {
+				return errors.Errorf("url %d: method %s is not read-only (support GET, HEAD, OPTIONS)", i, method)
+			}
+		}
+
+		if u.URL == nil {
+			return errors.Errorf("url %d: url is required", i)
+		}
+		matchers := 0
+		for _, m := range []string{u.URL.Exact, u.URL.Prefix, u.URL.Regex} {
+			if m != "" {
+				matchers++
+			}
+		}
+		if matchers != 1 {
+			return errors.Errorf("url %d: exactly one of exact, prefix and regex is required", i)
+		}
+		if u.URL.Regex != "" {
+			_, err := regexp.Compile(u.URL.Regex)
+			if err != nil {
+				return errors.Wrapf(err, "url %d: invalid regex", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MaxAttemptsOrDefault returns the max attempts of the policy.
+func (p *HedgingPolicy) MaxAttemptsOrDefault() int {
+	if p.MaxAttempts == 0 {
+		return DefaultHedgingMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// BudgetPercentOrDefault returns the budget percent of the policy.
+func (p *HedgingPolicy) BudgetPercentOrDefault() int {
+	if p.BudgetPercent == 0 {
+		return DefaultHedgingBudgetPercent
+	}
+	return p.BudgetPercent
+}
+
+// HTTPOnlyPolicies returns names of the policies which work on HTTP requests.
+func (r *Resilience) HTTPOnlyPolicies() []string {
+	if r.Spec == nil {
+		return nil
+	}
+
+	policies := HTTPOnlyResiliencePolicies(&r.Spec.Resilience)
+	if r.Spec.Hedging != nil {
+		policies = append(policies, "hedging")
+	}
+	return policies
+}
+
+// ToV1Alpha1 converts a Resilience resource to v1alpha1.Resilience, hedging is dropped.
 func (r *Resilience) ToV1Alpha1() *v1alpha1.Resilience {
+	if r.Spec == nil {
+		return nil
+	}
+	return &r.Spec.Resilience
+}
+
+// ToObject converts a Resilience resource to the object of the control plane.
+func (r *Resilience) ToObject() *ResilienceSpec {
+	if r.Spec == nil {
+		return &ResilienceSpec{}
+	}
 	return r.Spec
 }
 
+// ToResilienceFromObject converts an object of the control plane to a Resilience resource.
+func ToResilienceFromObject(name string, object *ResilienceSpec) *Resilience {
+	result := ToResilience(name, &object.Resilience)
+	result.Spec.Hedging = object.Hedging
+	return result
+}
+
 // ToResilience converts a v1alpha1.Resilience resource to a Resilience resource
 func ToResilience(name string, resilience *v1alpha1.Resilience) *Resilience {
 	result := &Resilience{
-		Spec: &v1alpha1.Resilience{},
+		Spec: &ResilienceSpec{},
 	}
 	result.MeshResource = NewResilienceResource(DefaultAPIVersion, name)
 	result.Spec.RateLimiter = resilience.RateLimiter
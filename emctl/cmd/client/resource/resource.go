@@ -92,6 +92,33 @@ const (
 
 	// KindServiceCanary is service canary kind of the EaseMesh resource.
 	KindServiceCanary = "ServiceCanary"
+
+	// KindTenantPolicy is tenant policy kind of the EaseMesh resource.
+	KindTenantPolicy = "TenantPolicy"
+
+	// KindExternalService is external service kind of the EaseMesh resource.
+	KindExternalService = "ExternalService"
+
+	// KindSLO is service level objective kind of the EaseMesh resource.
+	KindSLO = "SLO"
+
+	// KindAlertRule is alert rule kind of the EaseMesh resource.
+	KindAlertRule = "AlertRule"
+
+	// KindMessagingPolicy is messaging policy kind of the EaseMesh resource.
+	KindMessagingPolicy = "MessagingPolicy"
+
+	// KindIngressPort is ingress port kind of the EaseMesh resource.
+	KindIngressPort = "IngressPort"
+
+	// KindWAFPolicy is WAF policy kind of the EaseMesh resource.
+	KindWAFPolicy = "WAFPolicy"
+
+	// KindMaintenanceMode is maintenance mode kind of the EaseMesh resource.
+	KindMaintenanceMode = "MaintenanceMode"
+
+	// KindPolicyRollout is policy rollout kind of the EaseMesh resource.
+	KindPolicyRollout = "PolicyRollout"
 )
 
 type (
@@ -184,6 +211,42 @@ func (oc *objectCreator) new(kind meta.VersionKind, metaData meta.MetaData) (met
 		return &ServiceCanary{
 			MeshResource: NewServiceCanaryResource(apiVersion, metaData.Name),
 		}, nil
+	case KindExternalService:
+		return &ExternalService{
+			MeshResource: NewExternalServiceResource(apiVersion, metaData.Name),
+		}, nil
+	case KindTenantPolicy:
+		return &TenantPolicy{
+			MeshResource: NewTenantPolicyResource(apiVersion, metaData.Name),
+		}, nil
+	case KindSLO:
+		return &SLO{
+			MeshResource: NewSLOResource(apiVersion, metaData.Name),
+		}, nil
+	case KindAlertRule:
+		return &AlertRule{
+			MeshResource: NewAlertRuleResource(apiVersion, metaData.Name),
+		}, nil
+	case KindMessagingPolicy:
+		return &MessagingPolicy{
+			MeshResource: NewMessagingPolicyResource(apiVersion, metaData.Name),
+		}, nil
+	case KindIngressPort:
+		return &IngressPort{
+			MeshResource: NewIngressPortResource(apiVersion, metaData.Name),
+		}, nil
+	case KindWAFPolicy:
+		return &WAFPolicy{
+			MeshResource: NewWAFPolicyResource(apiVersion, metaData.Name),
+		}, nil
+	case KindMaintenanceMode:
+		return &MaintenanceMode{
+			MeshResource: NewMaintenanceModeResource(apiVersion, metaData.Name),
+		}, nil
+	case KindPolicyRollout:
+		return &PolicyRollout{
+			MeshResource: NewPolicyRolloutResource(apiVersion, metaData.Name),
+		}, nil
 	case KindCustomResourceKind:
 		return &CustomResourceKind{
 			MeshResource: NewCustomResourceKindResource(apiVersion, metaData.Name),
@@ -275,6 +338,51 @@ func NewServiceCanaryResource(apiVersion, name string) meta.MeshResource {
 	return NewMeshResource(apiVersion, KindServiceCanary, name)
 }
 
+// NewExternalServiceResource returns a MeshResource with the external service kind.
+func NewExternalServiceResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindExternalService, name)
+}
+
+// NewTenantPolicyResource returns a MeshResource with the tenant policy kind.
+func NewTenantPolicyResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindTenantPolicy, name)
+}
+
+// NewSLOResource returns a MeshResource with the SLO kind.
+func NewSLOResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindSLO, name)
+}
+
+// NewAlertRuleResource returns a MeshResource with the AlertRule kind.
+func NewAlertRuleResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindAlertRule, name)
+}
+
+// NewMessagingPolicyResource returns a MeshResource with the MessagingPolicy kind.
+func NewMessagingPolicyResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindMessagingPolicy, name)
+}
+
+// NewIngressPortResource returns a MeshResource with the IngressPort kind.
+func NewIngressPortResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindIngressPort, name)
+}
+
+// NewWAFPolicyResource returns a MeshResource with the WAFPolicy kind.
+func NewWAFPolicyResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindWAFPolicy, name)
+}
+
+// NewMaintenanceModeResource returns a MeshResource with the MaintenanceMode kind.
+func NewMaintenanceModeResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindMaintenanceMode, name)
+}
+
+// NewPolicyRolloutResource returns a MeshResource with the PolicyRollout kind.
+func NewPolicyRolloutResource(apiVersion, name string) meta.MeshResource {
+	return NewMeshResource(apiVersion, KindPolicyRollout, name)
+}
+
 // NewMeshResource returns a generic MeshResource
 func NewMeshResource(api, kind, name string) meta.MeshResource {
 	return meta.MeshResource{
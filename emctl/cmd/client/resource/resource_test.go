@@ -17,7 +17,13 @@
 package resource
 
 import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/megaease/easemesh-api/v1alpha1"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
@@ -28,7 +34,8 @@ func TestObjectCreator(t *testing.T) {
 	kinds := []string{
 		KindCanary, KindCustomResourceKind, KindIngress, KindLoadBalance,
 		KindMeshController, KindObservabilityMetrics, KindObservabilityOutputServer, KindObservabilityTracings,
-		KindResilience, KindService, KindServiceInstance, KindTenant, "CustomResource",
+		KindResilience, KindService, KindServiceInstance, KindTenant, KindExternalService, KindTenantPolicy,
+		KindSLO, KindAlertRule, KindMessagingPolicy, KindIngressPort, KindWAFPolicy, KindMaintenanceMode, KindPolicyRollout, "CustomResource",
 	}
 
 	NewObjectCreator().NewFromResource(meta.MeshResource{
@@ -53,8 +60,9 @@ func TestObjectCreator(t *testing.T) {
 		case *MeshController:
 			ToMeshController(r.ToV1Alpha1()).Columns()
 		case *Ingress:
-			r.Spec = &IngressSpec{}
+			r.Spec = &IngressSpec{CORS: &IngressCORS{AllowedOrigins: []string{"*"}}}
 			ToIngress(r.ToV1Alpha1())
+			IngressFromObject(r.ToObject())
 		case *HTTPRouteGroup:
 			r.Spec = &HTTPRouteGroupSpec{}
 			ToHTTPRouteGroup(r.ToV1Alpha1())
@@ -73,8 +81,9 @@ func TestObjectCreator(t *testing.T) {
 		case *ObservabilityTracings:
 			ToObservabilityTracings("new", r.ToV1Alpha1())
 		case *Resilience:
-			r.Spec = &v1alpha1.Resilience{}
+			r.Spec = &ResilienceSpec{}
 			ToResilience("new", r.ToV1Alpha1())
+			ToResilienceFromObject("new", r.ToObject())
 		case *Mock:
 			r.Spec = &v1alpha1.Mock{}
 			ToMock("new", r.ToV1Alpha1())
@@ -102,6 +111,45 @@ func TestObjectCreator(t *testing.T) {
 			t := ToTenant(r.ToV1Alpha1())
 			t.Spec = nil
 			t.Columns()
+		case *ExternalService:
+			r.Columns()
+			r.Spec = &ExternalServiceSpec{
+				Ports: []*ExternalServicePort{{Number: 443, Protocol: "https"}},
+				TLS:   &ExternalServiceTLS{Mode: ExternalServiceTLSModeOriginate},
+			}
+			ToExternalService(r.ToObject()).Columns()
+		case *TenantPolicy:
+			r.Columns()
+			r.Spec = &TenantPolicySpec{LoadBalance: &v1alpha1.LoadBalance{}}
+			ToTenantPolicy(r.ToObject()).Columns()
+		case *SLO:
+			r.Columns()
+			r.Spec = &SLOSpec{Service: "order", Latency: &SLOLatency{Threshold: "300ms", Target: 99}}
+			ToSLO(r.ToObject()).Columns()
+		case *AlertRule:
+			r.Columns()
+			r.Spec = &AlertRuleSpec{Tenant: "pet", ErrorRate: &AlertErrorRate{Threshold: 5}}
+			ToAlertRule(r.ToObject()).Columns()
+		case *MessagingPolicy:
+			r.Columns()
+			r.Spec = &MessagingPolicySpec{Service: "order", Protocol: MessagingProtocolMQTT, TLS: &ExternalServiceTLS{Mode: ExternalServiceTLSModeOriginate}}
+			ToMessagingPolicy(r.ToObject()).Columns()
+		case *IngressPort:
+			r.Columns()
+			r.Spec = &IngressPortSpec{Port: 5432, Protocol: IngressPortProtocolTCP, Backend: "db", TLS: &IngressPortTLS{Mode: IngressPortTLSModePassthrough}}
+			ToIngressPort(r.ToObject()).Columns()
+		case *WAFPolicy:
+			r.Columns()
+			r.Spec = &WAFPolicySpec{Mode: WAFModeDetect, RuleSets: []string{"sqli"}, Targets: []*WAFTarget{{Ingress: "pet"}}}
+			ToWAFPolicy(r.ToObject()).Columns()
+		case *MaintenanceMode:
+			r.Columns()
+			r.Spec = &MaintenanceModeSpec{RetryAfter: 120}
+			ToMaintenanceMode(r.ToObject()).Columns()
+		case *PolicyRollout:
+			r.Columns()
+			r.Spec = &PolicyRolloutSpec{Service: "order", TargetKind: KindResilience, TargetName: "order", Stages: []int{10, 100}, Percentage: 10}
+			ToPolicyRollout(r.ToObject()).Columns()
 		case *CustomResource:
 			ToCustomResource(map[string]interface{}{
 				"name": "name",
@@ -136,3 +184,976 @@ func TestDynamicObject(t *testing.T) {
 		t.Errorf("the type of 'field1' should be 'map[string]interface{}'")
 	}
 }
+
+func TestServiceProtocol(t *testing.T) {
+	service := &Service{
+		MeshResource: NewServiceResource(DefaultAPIVersion, "redis"),
+		Spec: &ServiceSpec{
+			RegisterTenant: "pet",
+			Protocol:       ServiceProtocolTCP,
+			Sidecar:        &v1alpha1.Sidecar{IngressProtocol: DefaultSideIngressProtocol, EgressProtocol: DefaultSideEgressProtocol},
+			Resilience:     &v1alpha1.Resilience{CircuitBreaker: &v1alpha1.CircuitBreaker{}},
+		},
+	}
+	if err := service.Validate(); err != nil {
+		t.Fatalf("validate tcp service failed: %v", err)
+	}
+
+	got := ToService(service.ToV1Alpha1())
+	if got.Spec.Sidecar.IngressProtocol != ServiceProtocolTCP || got.Spec.Protocol != ServiceProtocolTCP {
+		t.Fatalf("tcp service should be converted by protocols of the sidecar, but got %+v", got.Spec)
+	}
+
+	service.Spec.Resilience.Retryer = &v1alpha1.Retryer{}
+	service.Spec.Canary = &v1alpha1.Canary{}
+	if err := service.Validate(); err == nil {
+		t.Fatalf("validate tcp service with retryer and canary should fail")
+	}
+
+	service.Spec.Protocol = ServiceProtocolHTTP
+	service.Spec.Sidecar.IngressProtocol = DefaultSideIngressProtocol
+	if err := service.Validate(); err != nil {
+		t.Fatalf("validate http service failed: %v", err)
+	}
+
+	service.Spec.Protocol = "udp"
+	if err := service.Validate(); err == nil {
+		t.Fatalf("validate service with unknown protocol should fail")
+	}
+}
+
+func TestServiceRoutes(t *testing.T) {
+	service := &Service{
+		MeshResource: NewServiceResource(DefaultAPIVersion, "pet-service"),
+		Spec: &ServiceSpec{
+			RegisterTenant: "pet",
+			Routes: []*ServiceRoute{
+				{
+					Name:  "get-pet",
+					Match: &ServiceRouteMatch{Methods: []string{"GET"}, Regex: `^/pets/\d+$`},
+					Retry: &ServiceRouteRetry{MaxAttempts: 3, WaitDuration: "500ms", FailureStatusCodes: []int{503}},
+				},
+				{
+					Name:   "create-pet",
+					Match:  &ServiceRouteMatch{Methods: []string{"POST"}, Prefix: "/pets"},
+					Canary: &ServiceRouteCanary{InstanceLabels: map[string]string{"version": "beta"}, Headers: map[string]string{"X-Canary": "beta"}},
+				},
+			},
+		},
+	}
+	if err := service.Validate(); err != nil {
+		t.Fatalf("validate service with routes failed: %v", err)
+	}
+
+	result := service.ToV1Alpha1()
+	resilience, _ := json.Marshal(result.Resilience)
+	for _, want := range []string{`"policyRef":"route-get-pet"`, `"maxAttempts":3`} {
+		if !strings.Contains(string(resilience), want) {
+			t.Fatalf("resilience %s should contain %s", resilience, want)
+		}
+	}
+	canary, _ := json.Marshal(result.Canary)
+	for _, want := range []string{`"prefix":"/pets"`, `"exact":"beta"`, `"version":"beta"`} {
+		if !strings.Contains(string(canary), want) {
+			t.Fatalf("canary %s should contain %s", canary, want)
+		}
+	}
+
+	// NOTE: Routes are read back from the translated rules, and applying them again changes nothing.
+	got := ToService(result)
+	if got.Spec.Resilience != nil || got.Spec.Canary != nil || len(got.Spec.Routes) != 2 {
+		t.Fatalf("routes should be read back, but got %+v", got.Spec)
+	}
+	gotRoutes, _ := json.Marshal(got.Spec.Routes)
+	wantRoutes, _ := json.Marshal(service.Spec.Routes)
+	if string(gotRoutes) != string(wantRoutes) {
+		t.Fatalf("want routes %s, but got %s", wantRoutes, gotRoutes)
+	}
+	again, err := (&Service{MeshResource: service.MeshResource, Spec: &ServiceSpec{
+		Routes: service.Spec.Routes, Resilience: result.Resilience, Canary: result.Canary,
+	}}).Translate()
+	if err != nil {
+		t.Fatalf("translate routes again failed: %v", err)
+	}
+	if !reflect.DeepEqual(again.Resilience, result.Resilience) || !reflect.DeepEqual(again.Canary, result.Canary) {
+		t.Fatalf("translating routes again should change nothing")
+	}
+
+	content := map[string]interface{}{}
+	buff, _ := json.Marshal(result)
+	_ = json.Unmarshal(buff, &content)
+	revision := ToRevision(&RevisionObject{Kind: KindService, Name: "pet-service", Content: content})
+	if _, exists := revision.Spec.Content["routes"]; !exists {
+		t.Fatalf("revision %+v should show routes", revision.Spec.Content)
+	}
+
+	shadowed := &v1alpha1.Resilience{}
+	_ = json.Unmarshal([]byte(`{"retryer":{"policies":[{"name":"default","maxAttempts":2}],"urls":[{"url":{"exact":"/pets"},"policyRef":"default"}]}}`), shadowed)
+	shadowing := &Service{MeshResource: service.MeshResource, Spec: &ServiceSpec{
+		Routes:     []*ServiceRoute{{Name: "a", Match: &ServiceRouteMatch{Exact: "/pets"}, Retry: &ServiceRouteRetry{MaxAttempts: 1}}},
+		Resilience: shadowed,
+	}}
+	if err := shadowing.Validate(); err == nil {
+		t.Fatalf("validate routes shadowing rules of the service should fail")
+	}
+
+	for _, routes := range [][]*ServiceRoute{
+		{{Name: "a", Match: &ServiceRouteMatch{Prefix: "/pets"}}},
+		{{Name: "a", Match: &ServiceRouteMatch{Prefix: "pets"}, Retry: &ServiceRouteRetry{MaxAttempts: 1}}},
+		{{Name: "a", Match: &ServiceRouteMatch{Prefix: "/pets", Exact: "/pets"}, Retry: &ServiceRouteRetry{MaxAttempts: 1}}},
+		{{Name: "a", Match: &ServiceRouteMatch{Regex: "(", Methods: []string{"GET"}}, Retry: &ServiceRouteRetry{MaxAttempts: 1}}},
+		{{Name: "a", Match: &ServiceRouteMatch{Exact: "/pets", Methods: []string{"FETCH"}}, Retry: &ServiceRouteRetry{MaxAttempts: 1}}},
+		{{Name: "a", Match: &ServiceRouteMatch{Exact: "/pets"}, Retry: &ServiceRouteRetry{}}},
+		{
+			{Name: "a", Match: &ServiceRouteMatch{Exact: "/pets"}, Retry: &ServiceRouteRetry{MaxAttempts: 1}},
+			{Name: "a", Match: &ServiceRouteMatch{Exact: "/owners"}, Retry: &ServiceRouteRetry{MaxAttempts: 1}},
+		},
+		{
+			{Name: "a", Match: &ServiceRouteMatch{Exact: "/pets", Methods: []string{"GET", "POST"}}, Retry: &ServiceRouteRetry{MaxAttempts: 1}},
+			{Name: "b", Match: &ServiceRouteMatch{Exact: "/pets", Methods: []string{"POST"}}, Retry: &ServiceRouteRetry{MaxAttempts: 2}},
+		},
+		{
+			{Name: "a", Match: &ServiceRouteMatch{Prefix: "/pets"}, Retry: &ServiceRouteRetry{MaxAttempts: 1}},
+			{Name: "b", Match: &ServiceRouteMatch{Prefix: "/pets/owners", Methods: []string{"GET"}}, Retry: &ServiceRouteRetry{MaxAttempts: 2}},
+		},
+	} {
+		invalid := &Service{MeshResource: service.MeshResource, Spec: &ServiceSpec{Routes: routes}}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid routes %+v should fail", routes)
+		}
+	}
+}
+
+func TestTenantPolicy(t *testing.T) {
+	policy := &TenantPolicy{
+		MeshResource: NewTenantPolicyResource(DefaultAPIVersion, "pet"),
+		Spec: &TenantPolicySpec{
+			Resilience:  &v1alpha1.Resilience{},
+			LoadBalance: &v1alpha1.LoadBalance{Policy: LoadBalanceRoundRobinPolicy},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("validate tenant policy failed: %v", err)
+	}
+
+	serviceLoadBalance := &v1alpha1.LoadBalance{Policy: "random"}
+	service := &ServiceSpec{LoadBalance: serviceLoadBalance}
+	inherited := policy.Spec.Inherit(service)
+	if len(inherited) != 1 || inherited[0] != "resilience" {
+		t.Fatalf("service should only inherit resilience, but got %v", inherited)
+	}
+	if service.LoadBalance != serviceLoadBalance {
+		t.Fatalf("load balance of service should take precedence over the tenant")
+	}
+	if service.Resilience != policy.Spec.Resilience {
+		t.Fatalf("resilience of service should be inherited from the tenant")
+	}
+
+	policy.Spec.LoadBalance.Policy = "unknown"
+	if err := policy.Validate(); err == nil {
+		t.Fatalf("validate tenant policy with unknown load balance policy should fail")
+	}
+}
+
+func TestSLO(t *testing.T) {
+	slo := &SLO{
+		MeshResource: NewSLOResource(DefaultAPIVersion, "order-availability"),
+		Spec: &SLOSpec{
+			Service:      "order-service",
+			Route:        "/api/orders",
+			Availability: &SLOAvailability{Target: 99.9},
+			Latency:      &SLOLatency{Threshold: "300ms", Target: 99},
+		},
+	}
+	if err := slo.Validate(); err != nil {
+		t.Fatalf("validate slo failed: %v", err)
+	}
+	if slo.Spec.WindowDuration() != DefaultSLOWindow {
+		t.Fatalf("window should be %s by default, but got %s", DefaultSLOWindow, slo.Spec.WindowDuration())
+	}
+
+	for _, modify := range []func(s *SLOSpec){
+		func(s *SLOSpec) { s.Service = "" },
+		func(s *SLOSpec) { s.Availability, s.Latency = nil, nil },
+		func(s *SLOSpec) { s.Route = "api" },
+		func(s *SLOSpec) { s.Window = "30d" },
+		func(s *SLOSpec) { s.Availability.Target = 100 },
+		func(s *SLOSpec) { s.Latency.Threshold = "0s" },
+	} {
+		spec := *slo.Spec
+		availability, latency := *slo.Spec.Availability, *slo.Spec.Latency
+		spec.Availability, spec.Latency = &availability, &latency
+		modify(&spec)
+		invalid := &SLO{MeshResource: slo.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid slo %+v should fail", spec)
+		}
+	}
+}
+
+func TestAlertRule(t *testing.T) {
+	rule := &AlertRule{
+		MeshResource: NewAlertRuleResource(DefaultAPIVersion, "order-latency"),
+		Spec: &AlertRuleSpec{
+			Service: "order-service",
+			Latency: &AlertLatency{Threshold: "500ms"},
+			For:     "5m",
+			Webhook: "http://alertmanager:9093/api/v1/alerts",
+		},
+	}
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("validate alert rule failed: %v", err)
+	}
+	if rule.Spec.Metric() != AlertMetricLatency || rule.Spec.SeverityOrDefault() != AlertSeverityWarning {
+		t.Fatalf("unexpected metric %s or severity %s", rule.Spec.Metric(), rule.Spec.SeverityOrDefault())
+	}
+	if condition := rule.Spec.Condition(); condition != "p99 latency > 500ms" {
+		t.Fatalf("unexpected condition %s", condition)
+	}
+
+	for _, modify := range []func(s *AlertRuleSpec){
+		func(s *AlertRuleSpec) { s.Service = "" },
+		func(s *AlertRuleSpec) { s.Tenant = "pet" },
+		func(s *AlertRuleSpec) { s.Latency = nil },
+		func(s *AlertRuleSpec) { s.ErrorRate = &AlertErrorRate{Threshold: 5} },
+		func(s *AlertRuleSpec) { s.Latency.Percentile = 90 },
+		func(s *AlertRuleSpec) { s.Latency.Threshold = "500" },
+		func(s *AlertRuleSpec) { s.Latency, s.InstanceCount = nil, &AlertInstanceCount{} },
+		func(s *AlertRuleSpec) { s.For = "5 minutes" },
+		func(s *AlertRuleSpec) { s.Severity = "fatal" },
+		func(s *AlertRuleSpec) { s.Webhook = "alertmanager:9093" },
+	} {
+		spec := *rule.Spec
+		latency := *rule.Spec.Latency
+		spec.Latency = &latency
+		modify(&spec)
+		invalid := &AlertRule{MeshResource: rule.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid alert rule %+v should fail", spec)
+		}
+	}
+}
+
+func TestMessagingPolicy(t *testing.T) {
+	policy := &MessagingPolicy{
+		MeshResource: NewMessagingPolicyResource(DefaultAPIVersion, "order-kafka"),
+		Spec: &MessagingPolicySpec{
+			Service:    "order-service",
+			Protocol:   MessagingProtocolKafka,
+			Brokers:    []string{"kafka-0:9093", "kafka-1:9093"},
+			ListenPort: 19093,
+			Topics:     []string{"orders", "payments.v1"},
+			Metrics:    &MessagingMetrics{Enabled: true, Interval: "10s"},
+			TLS:        &ExternalServiceTLS{Mode: ExternalServiceTLSModeOriginate},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("validate messaging policy failed: %v", err)
+	}
+
+	mqtt := &MessagingPolicy{
+		MeshResource: NewMessagingPolicyResource(DefaultAPIVersion, "sensor-mqtt"),
+		Spec: &MessagingPolicySpec{
+			Service:    "sensor-service",
+			Protocol:   MessagingProtocolMQTT,
+			Brokers:    []string{"emqx:1883"},
+			ListenPort: 11883,
+			Topics:     []string{"sensors/+/temperature", "alerts/#"},
+		},
+	}
+	if err := mqtt.Validate(); err != nil {
+		t.Fatalf("validate mqtt messaging policy failed: %v", err)
+	}
+	mqtt.Spec.Topics = []string{"alerts/#/critical"}
+	if err := mqtt.Validate(); err == nil {
+		t.Fatalf("validate mqtt topic with # in the middle should fail")
+	}
+
+	for _, modify := range []func(s *MessagingPolicySpec){
+		func(s *MessagingPolicySpec) { s.Service = "" },
+		func(s *MessagingPolicySpec) { s.Protocol = "amqp" },
+		func(s *MessagingPolicySpec) { s.Brokers = nil },
+		func(s *MessagingPolicySpec) { s.Brokers = []string{"kafka-0"} },
+		func(s *MessagingPolicySpec) { s.ListenPort = 0 },
+		func(s *MessagingPolicySpec) { s.Topics = []string{"orders/#"} },
+		func(s *MessagingPolicySpec) { s.Metrics = &MessagingMetrics{Interval: "10"} },
+		func(s *MessagingPolicySpec) { s.TLS = &ExternalServiceTLS{Mode: "strict"} },
+		func(s *MessagingPolicySpec) {
+			s.TLS = &ExternalServiceTLS{Mode: ExternalServiceTLSModeOriginate, CertBase64: "Y2VydA=="}
+		},
+	} {
+		spec := *policy.Spec
+		modify(&spec)
+		invalid := &MessagingPolicy{MeshResource: policy.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid messaging policy %+v should fail", spec)
+		}
+	}
+}
+
+func TestExternalServiceSecretRef(t *testing.T) {
+	for _, ref := range []*SecretRef{
+		{Kubernetes: "payments/stripe-client"},
+		{Kubernetes: "stripe-client"},
+		{Vault: "secret/data/payments/stripe"},
+	} {
+		tls := &ExternalServiceTLS{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: ref}
+		if err := tls.Validate(); err != nil {
+			t.Fatalf("validate secret ref %+v failed: %v", ref, err)
+		}
+	}
+
+	for _, tls := range []*ExternalServiceTLS{
+		{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: &SecretRef{}},
+		{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: &SecretRef{Kubernetes: "a/b/c"}},
+		{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: &SecretRef{Kubernetes: "Stripe"}},
+		{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: &SecretRef{Vault: "/secret/stripe"}},
+		{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: &SecretRef{Vault: "secret//stripe"}},
+		{Mode: ExternalServiceTLSModeOriginate, CredentialSecretRef: &SecretRef{Kubernetes: "stripe", Vault: "secret/stripe"}},
+		{Mode: ExternalServiceTLSModePassthrough, CredentialSecretRef: &SecretRef{Kubernetes: "stripe"}},
+		{Mode: ExternalServiceTLSModeOriginate, CACertBase64: "Y2E=", CredentialSecretRef: &SecretRef{Kubernetes: "stripe"}},
+	} {
+		if err := tls.Validate(); err == nil {
+			t.Fatalf("validate invalid tls %+v should fail", tls)
+		}
+	}
+}
+
+func TestCanaryMatch(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "http://pet-service/pets?debug=1", nil)
+	r.Header.Set("X-User", "alice")
+	r.Header.Set("X-Region", "eu-west")
+	r.AddCookie(&http.Cookie{Name: "uid", Value: "1024"})
+
+	for expression, want := range map[string]bool{
+		`header("x-user") == "alice"`:                               true,
+		`header("X-User") != "alice" || cookie("uid") =~ "^10"`:     true,
+		`!exists(query("debug"))`:                                   false,
+		`exists(header("X-User")) && !(header("X-Region") !~ "eu")`: true,
+		`query("debug") == "1" && cookie("missing") != "x"`:         true,
+		`bucket(cookie("uid")) >= 0 && bucket(cookie("uid")) < 100`: true,
+	} {
+		m, err := ParseCanaryMatch(expression)
+		if err != nil {
+			t.Fatalf("parse %s failed: %v", expression, err)
+		}
+		if got := m.Match(r); got != want {
+			t.Fatalf("match %s: want %v, got %v", expression, want, got)
+		}
+	}
+
+	if CanaryBucket("1024") != CanaryBucket("1024") {
+		t.Fatalf("bucket of the same value should be stable")
+	}
+
+	m, _ := ParseCanaryMatch(`header("X-User") == "alice" && cookie("uid") == "1"`)
+	results := m.Explain(r)
+	if len(results) != 2 || !results[0].Matched || results[1].Matched || results[1].Value != "1024" {
+		t.Fatalf("unexpected explanation %+v", results)
+	}
+
+	for _, expression := range []string{
+		``,
+		`header("X-User")`,
+		`header("X-User") == alice`,
+		`header("X-User") =~ "("`,
+		`bucket(cookie("uid")) < 101`,
+		`bucket(cookie("uid")) =~ "1"`,
+		`(header("X-User") == "alice"`,
+		`body("x") == "1"`,
+	} {
+		if _, err := ParseCanaryMatch(expression); err == nil {
+			t.Fatalf("parse invalid expression %q should fail", expression)
+		}
+	}
+
+	sc := &ServiceCanary{
+		MeshResource: NewServiceCanaryResource(DefaultAPIVersion, "pet-beta"),
+		Spec:         &ServiceCanarySpec{Match: `cookie("uid") ==`},
+	}
+	if err := sc.Validate(); err == nil {
+		t.Fatalf("validate service canary with invalid match should fail")
+	}
+}
+
+func TestServiceCanarySticky(t *testing.T) {
+	sc := &ServiceCanary{
+		MeshResource: NewServiceCanaryResource(DefaultAPIVersion, "pet-beta"),
+		Spec: &ServiceCanarySpec{
+			Sticky: &ServiceCanarySticky{Mode: ServiceCanaryStickyCookie, MaxAge: "2h"},
+		},
+	}
+	if err := sc.Validate(); err != nil {
+		t.Fatalf("validate sticky cookie failed: %v", err)
+	}
+	if got := sc.Spec.Sticky.Expression(sc.Name()); got != `cookie("X-Mesh-Service-Canary") == "pet-beta"` {
+		t.Fatalf("unexpected expression %s", got)
+	}
+
+	sc.Spec.Sticky = &ServiceCanarySticky{Mode: ServiceCanaryStickyHash, Key: `header("X-User-Id")`, Percentage: 10}
+	if err := sc.Validate(); err != nil {
+		t.Fatalf("validate sticky hash failed: %v", err)
+	}
+	object := sc.ToObject()
+	if object.Sticky == nil || ToServiceCanaryFromObject(object).Spec.Sticky.Percentage != 10 {
+		t.Fatalf("sticky should be kept in the object")
+	}
+
+	for _, sticky := range []*ServiceCanarySticky{
+		{Mode: "session"},
+		{Mode: ServiceCanaryStickyCookie, CookieName: "bad cookie"},
+		{Mode: ServiceCanaryStickyCookie, MaxAge: "-1h"},
+		{Mode: ServiceCanaryStickyCookie, Key: `cookie("uid")`},
+		{Mode: ServiceCanaryStickyHash},
+		{Mode: ServiceCanaryStickyHash, Key: `cookie("uid")) < 1 || exists(cookie("a")`},
+		{Mode: ServiceCanaryStickyHash, Key: `cookie("uid")`, Percentage: 101},
+		{Mode: ServiceCanaryStickyHash, Key: `cookie("uid")`, MaxAge: "1h"},
+	} {
+		sc.Spec.Sticky = sticky
+		if err := sc.Validate(); err == nil {
+			t.Fatalf("validate invalid sticky %+v should fail", sticky)
+		}
+	}
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	mode := &MaintenanceMode{
+		MeshResource: NewMaintenanceModeResource(DefaultAPIVersion, "payments"),
+		Spec: &MaintenanceModeSpec{
+			Service:    "payments",
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"message": "under maintenance"}`,
+			RetryAfter: 120,
+		},
+	}
+	if err := mode.Validate(); err != nil {
+		t.Fatalf("validate maintenance mode failed: %v", err)
+	}
+	if mode.Spec.StatusCodeOrDefault() != DefaultMaintenanceStatusCode {
+		t.Fatalf("default status code should be %d", DefaultMaintenanceStatusCode)
+	}
+
+	for _, modify := range []func(s *MaintenanceModeSpec){
+		func(s *MaintenanceModeSpec) { s.StatusCode = 600 },
+		func(s *MaintenanceModeSpec) { s.RetryAfter = -1 },
+		func(s *MaintenanceModeSpec) { s.Headers = map[string]string{"Bad Header": "x"} },
+		func(s *MaintenanceModeSpec) { s.Headers = map[string]string{"retry-after": "10"} },
+		func(s *MaintenanceModeSpec) { s.Headers = map[string]string{"X-Reason": "a\r\nb"} },
+		func(s *MaintenanceModeSpec) { s.Service = "" },
+		func(s *MaintenanceModeSpec) { s.Service = "orders" },
+	} {
+		spec := *mode.Spec
+		modify(&spec)
+		invalid := &MaintenanceMode{MeshResource: mode.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid maintenance mode %+v should fail", spec)
+		}
+	}
+
+	if err := (&MaintenanceMode{MeshResource: mode.MeshResource}).Validate(); err == nil {
+		t.Fatalf("validate maintenance mode without spec should fail")
+	}
+
+	meshWide := &MaintenanceMode{
+		MeshResource: NewMaintenanceModeResource(DefaultAPIVersion, MaintenanceModeMeshWide),
+		Spec:         &MaintenanceModeSpec{},
+	}
+	if err := meshWide.Validate(); err != nil {
+		t.Fatalf("validate mesh-wide maintenance mode failed: %v", err)
+	}
+	meshWide.Spec.Service = MaintenanceModeMeshWide
+	if err := meshWide.Validate(); err == nil {
+		t.Fatalf("validate maintenance mode of the reserved service should fail")
+	}
+}
+
+func TestPolicyRollout(t *testing.T) {
+	rollout := &PolicyRollout{
+		MeshResource: NewPolicyRolloutResource(DefaultAPIVersion, PolicyRolloutName(KindResilience, "payments")),
+		Spec: &PolicyRolloutSpec{
+			Service:    "payments",
+			TargetKind: KindResilience,
+			TargetName: "payments",
+			Policy:     map[string]interface{}{"timeLimiter": map[string]interface{}{"defaultTimeoutDuration": "3s"}},
+			Stages:     []int{10, 50, 100},
+			Percentage: 10,
+		},
+	}
+	if err := rollout.Validate(); err != nil {
+		t.Fatalf("validate policy rollout failed: %v", err)
+	}
+	if rollout.Name() != "resilience-payments" {
+		t.Fatalf("unexpected name of policy rollout %s", rollout.Name())
+	}
+
+	selected := 0
+	for i := 0; i < 1000; i++ {
+		id := "payments-" + strconv.Itoa(i)
+		if rollout.Spec.Selects(id) {
+			selected++
+			spec := *rollout.Spec
+			spec.Percentage = 50
+			if !spec.Selects(id) {
+				t.Fatalf("sidecar %s selected at 10%% should be selected at 50%%", id)
+			}
+		}
+	}
+	if selected < 50 || selected > 150 {
+		t.Fatalf("about 10%% of sidecars should be selected, but got %d/1000", selected)
+	}
+
+	for _, modify := range []func(s *PolicyRolloutSpec){
+		func(s *PolicyRolloutSpec) { s.Service = "" },
+		func(s *PolicyRolloutSpec) { s.TargetKind = "" },
+		func(s *PolicyRolloutSpec) { s.Policy = nil },
+		func(s *PolicyRolloutSpec) { s.Percentage = 0 },
+		func(s *PolicyRolloutSpec) { s.Percentage = 20 },
+		func(s *PolicyRolloutSpec) { s.Stages = []int{50, 10, 100} },
+		func(s *PolicyRolloutSpec) { s.Stages = []int{10, 50} },
+	} {
+		spec := *rollout.Spec
+		modify(&spec)
+		invalid := &PolicyRollout{MeshResource: rollout.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid policy rollout %+v should fail", spec)
+		}
+	}
+}
+
+func TestResilienceHedging(t *testing.T) {
+	resilience := &Resilience{
+		MeshResource: NewResilienceResource(DefaultAPIVersion, "pet-service"),
+	}
+	err := json.Unmarshal([]byte(`{
+		"circuitBreaker": {"policies": [{"name": "cb"}]},
+		"hedging": {
+			"policies": [{"name": "fast-read", "delay": "50ms", "maxAttempts": 3, "budgetPercent": 5}],
+			"urls": [{"methods": ["GET"], "url": {"prefix": "/pets"}, "policyRef": "fast-read"}]
+		}
+	}`), &resilience.Spec)
+	if err != nil {
+		t.Fatalf("unmarshal resilience failed: %v", err)
+	}
+	if err := resilience.Validate(); err != nil {
+		t.Fatalf("validate resilience with hedging failed: %v", err)
+	}
+	if resilience.Spec.CircuitBreaker == nil {
+		t.Fatalf("circuit breaker should be decoded along with hedging")
+	}
+	if policies := resilience.HTTPOnlyPolicies(); len(policies) != 1 || policies[0] != "hedging" {
+		t.Fatalf("unexpected HTTP-only policies %v", policies)
+	}
+
+	buff, _ := json.Marshal(resilience.ToObject())
+	object := &ResilienceSpec{}
+	if err := json.Unmarshal(buff, object); err != nil {
+		t.Fatalf("unmarshal object failed: %v", err)
+	}
+	roundTrip := ToResilienceFromObject("pet-service", object)
+	if roundTrip.Spec.Hedging == nil || roundTrip.Spec.Hedging.Policies[0].MaxAttemptsOrDefault() != 3 ||
+		roundTrip.Spec.Hedging.Policies[0].BudgetPercentOrDefault() != 5 {
+		t.Fatalf("hedging should be round-tripped: %s", buff)
+	}
+
+	for _, hedging := range []*Hedging{
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "0s"}}, URLs: []*HedgingURLRule{{Methods: []string{"GET"}, URL: &HedgingURL{Exact: "/"}, PolicyRef: "p"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms", MaxAttempts: 6}}, URLs: []*HedgingURLRule{{Methods: []string{"GET"}, URL: &HedgingURL{Exact: "/"}, PolicyRef: "p"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms", BudgetPercent: 101}}, URLs: []*HedgingURLRule{{Methods: []string{"GET"}, URL: &HedgingURL{Exact: "/"}, PolicyRef: "p"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms"}}, URLs: []*HedgingURLRule{{Methods: []string{"POST"}, URL: &HedgingURL{Exact: "/"}, PolicyRef: "p"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms"}}, URLs: []*HedgingURLRule{{Methods: []string{"GET"}, URL: &HedgingURL{Exact: "/"}, PolicyRef: "q"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms"}}, URLs: []*HedgingURLRule{{Methods: []string{"GET"}, URL: &HedgingURL{Exact: "/", Prefix: "/"}, PolicyRef: "p"}}},
+		{Policies: []*HedgingPolicy{{Name: "p", Delay: "50ms"}}, URLs: []*HedgingURLRule{{Methods: []string{"GET"}, URL: &HedgingURL{Regex: "("}, PolicyRef: "p"}}},
+	} {
+		invalid := &Resilience{MeshResource: resilience.MeshResource, Spec: &ResilienceSpec{Hedging: hedging}}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid hedging %+v should fail", hedging)
+		}
+	}
+}
+
+func TestResourceMeta(t *testing.T) {
+	tenant := &Tenant{MeshResource: NewTenantResource(DefaultAPIVersion, "payments")}
+	tenant.MetaData.Labels = map[string]string{"team": "payments"}
+	tenant.MetaData.Annotations = map[string]string{"owner": "payments@megaease.com"}
+
+	resourceMeta := ToResourceMeta(NewResourceMeta(tenant).ToObject())
+	if resourceMeta.Name() != "tenant-payments" || resourceMeta.Spec.Kind != KindTenant || resourceMeta.Spec.Name != "payments" {
+		t.Fatalf("unexpected resource meta %+v", resourceMeta.Spec)
+	}
+
+	got := &Tenant{MeshResource: NewTenantResource(DefaultAPIVersion, "payments")}
+	resourceMeta.ApplyTo(got)
+	if got.Labels()["team"] != "payments" || got.Annotations()["owner"] != "payments@megaease.com" {
+		t.Fatalf("labels and annotations are not applied: %+v", got.MetaData)
+	}
+
+	buff, err := yaml.Marshal(got)
+	if err != nil {
+		t.Fatalf("marshal tenant failed: %v", err)
+	}
+	if !strings.Contains(string(buff), "annotations:") {
+		t.Fatalf("annotations should be marshaled into metadata, got %s", buff)
+	}
+
+	for _, kind := range []string{KindServiceInstance, KindPolicyRollout, KindApplySet, KindResourceMeta} {
+		if HasResourceMeta(kind) {
+			t.Fatalf("%s should not keep resource meta", kind)
+		}
+	}
+	if !HasResourceMeta(KindService) || !HasResourceMeta("ShadowService") {
+		t.Fatalf("services and custom resources should keep resource meta")
+	}
+}
+
+func TestIngressPort(t *testing.T) {
+	port := &IngressPort{
+		MeshResource: NewIngressPortResource(DefaultAPIVersion, "order-db"),
+		Spec: &IngressPortSpec{
+			Port:     5432,
+			Protocol: IngressPortProtocolTCP,
+			Backend:  "order-db",
+			TLS: &IngressPortTLS{
+				Mode:  IngressPortTLSModePassthrough,
+				Hosts: []string{"order-db.megaease.com"},
+			},
+		},
+	}
+	if err := port.Validate(); err != nil {
+		t.Fatalf("validate ingress port failed: %v", err)
+	}
+	if backend := port.Columns()[1].Value; backend != "order-db:5432" {
+		t.Fatalf("backend port should default to the port, got %s", backend)
+	}
+
+	for _, modify := range []func(s *IngressPortSpec){
+		func(s *IngressPortSpec) { s.Port = 0 },
+		func(s *IngressPortSpec) { s.BackendPort = 70000 },
+		func(s *IngressPortSpec) { s.Protocol = "sctp" },
+		func(s *IngressPortSpec) { s.Backend = "" },
+		func(s *IngressPortSpec) { s.Protocol = IngressPortProtocolUDP },
+		func(s *IngressPortSpec) { s.TLS = &IngressPortTLS{Mode: "strict"} },
+		func(s *IngressPortSpec) {
+			s.TLS = &IngressPortTLS{Mode: IngressPortTLSModePassthrough, CertBase64: "Y2VydA==", KeyBase64: "a2V5"}
+		},
+		func(s *IngressPortSpec) {
+			s.TLS = &IngressPortTLS{Mode: IngressPortTLSModeTerminate, CertBase64: "Y2VydA=="}
+		},
+	} {
+		spec := *port.Spec
+		modify(&spec)
+		invalid := &IngressPort{MeshResource: port.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid ingress port %+v should fail", spec)
+		}
+	}
+}
+
+func TestIngressCORS(t *testing.T) {
+	ingress := &Ingress{
+		MeshResource: NewIngressResource(DefaultAPIVersion, "ingress-sample"),
+		Spec: &IngressSpec{
+			Rules: []*v1alpha1.IngressRule{{
+				Host: "www.megaease.com",
+				Paths: []*v1alpha1.IngressPath{
+					{Path: "/api", Backend: "order-service"},
+					{Path: "/static", Backend: "static-service"},
+				},
+			}},
+			CORS: &IngressCORS{
+				AllowedOrigins: []string{"https://*.megaease.com"},
+				AllowedMethods: []string{"GET", "post"},
+				AllowedHeaders: []string{"X-Requested-With"},
+				MaxAge:         600,
+			},
+			PathCORS: []*IngressPathCORS{
+				{Host: "www.megaease.com", Path: "/static", Disabled: true},
+			},
+			HostPolicies: []*IngressHostPolicy{
+				{Host: "www.megaease.com", SSLRedirect: true, HSTS: &IngressHSTS{MaxAge: 31536000, IncludeSubDomains: true}, ACMEChallenge: ACMEChallengeHTTP01},
+			},
+		},
+	}
+	if err := ingress.Validate(); err != nil {
+		t.Fatalf("validate ingress failed: %v", err)
+	}
+	got := IngressFromObject(ingress.ToObject())
+	if got.Spec.CORS != ingress.Spec.CORS || len(got.Spec.PathCORS) != 1 || len(got.Spec.HostPolicies) != 1 {
+		t.Fatalf("CORS and host policies should be kept in the object, got %+v", got.Spec)
+	}
+
+	for _, modify := range []func(s *IngressSpec){
+		func(s *IngressSpec) { s.CORS = &IngressCORS{} },
+		func(s *IngressSpec) { s.CORS = &IngressCORS{AllowedOrigins: []string{"www.megaease.com"}} },
+		func(s *IngressSpec) { s.CORS = &IngressCORS{AllowedOrigins: []string{"https://www.*.com"}} },
+		func(s *IngressSpec) { s.CORS = &IngressCORS{AllowedOrigins: []string{"*"}, AllowCredentials: true} },
+		func(s *IngressSpec) {
+			s.CORS = &IngressCORS{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"FETCH"}}
+		},
+		func(s *IngressSpec) {
+			s.CORS = &IngressCORS{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X Id"}}
+		},
+		func(s *IngressSpec) { s.CORS = &IngressCORS{AllowedOrigins: []string{"*"}, MaxAge: -1} },
+		func(s *IngressSpec) {
+			s.PathCORS = []*IngressPathCORS{{Host: "www.megaease.com", Path: "/admin", Disabled: true}}
+		},
+		func(s *IngressSpec) { s.PathCORS = []*IngressPathCORS{{Host: "www.megaease.com", Path: "/api"}} },
+		func(s *IngressSpec) {
+			s.PathCORS = []*IngressPathCORS{{Host: "www.megaease.com", Path: "/api", Disabled: true, CORS: s.CORS}}
+		},
+		func(s *IngressSpec) {
+			s.HostPolicies = []*IngressHostPolicy{{Host: "api.megaease.com", SSLRedirect: true}}
+		},
+		func(s *IngressSpec) {
+			s.HostPolicies = []*IngressHostPolicy{{Host: "www.megaease.com"}, {Host: "www.megaease.com", SSLRedirect: true}}
+		},
+		func(s *IngressSpec) {
+			s.HostPolicies = []*IngressHostPolicy{{Host: "www.megaease.com", HSTS: &IngressHSTS{}}}
+		},
+		func(s *IngressSpec) {
+			s.HostPolicies = []*IngressHostPolicy{{Host: "www.megaease.com", ACMEChallenge: "tls-alpn-01"}}
+		},
+	} {
+		spec := *ingress.Spec
+		modify(&spec)
+		invalid := &Ingress{MeshResource: ingress.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid ingress %+v should fail", spec)
+		}
+	}
+}
+
+func TestIngressPathFilters(t *testing.T) {
+	ingress := &Ingress{
+		MeshResource: NewIngressResource(DefaultAPIVersion, "ingress-sample"),
+		Spec: &IngressSpec{
+			Rules: []*v1alpha1.IngressRule{{
+				Paths: []*v1alpha1.IngressPath{
+					{Path: "/api", Backend: "order-service"},
+					{Path: "/static", Backend: "static-service"},
+				},
+			}},
+			PathFilters: []*IngressPathFilters{
+				{Path: "/api", Compression: &IngressCompression{Encodings: []string{IngressEncodingGzip}}},
+				{
+					Path:        "/static",
+					Compression: &IngressCompression{ContentTypes: []string{"text/*", "image/svg+xml"}},
+					Cache:       &IngressCache{TTL: "10m", KeyHeaders: []string{"Accept-Language"}},
+				},
+			},
+		},
+	}
+	if err := ingress.Validate(); err != nil {
+		t.Fatalf("validate ingress failed: %v", err)
+	}
+
+	compression, cache := ingress.Spec.PathFilters[1].Compression, ingress.Spec.PathFilters[1].Cache
+	if encodings := compression.EncodingsOrDefault(); len(encodings) != 2 || encodings[0] != IngressEncodingBrotli {
+		t.Fatalf("default encodings should be br and gzip, got %v", encodings)
+	}
+	if compression.MinLengthOrDefault() != DefaultIngressCompressionMinLength ||
+		cache.TTLDuration() != 10*time.Minute || cache.MaxEntriesOrDefault() != DefaultIngressCacheMaxEntries ||
+		cache.MaxEntrySizeOrDefault() != DefaultIngressCacheMaxEntrySize {
+		t.Fatalf("unexpected defaults of %+v %+v", compression, cache)
+	}
+	if ttl := (&IngressCache{}).TTLDuration(); ttl != DefaultIngressCacheTTL {
+		t.Fatalf("default ttl should be %s, got %s", DefaultIngressCacheTTL, ttl)
+	}
+	if ttl := (&IngressCache{Mode: IngressCacheModeStatic}).TTLDuration(); ttl != DefaultIngressStaticCacheTTL {
+		t.Fatalf("default ttl of the static mode should be %s, got %s", DefaultIngressStaticCacheTTL, ttl)
+	}
+
+	spec := *ingress.Spec
+	spec.PathFilters = []*IngressPathFilters{
+		{Path: "/static", Cache: &IngressCache{Mode: IngressCacheModeStatic, ETag: true, ClientTTL: "24h"}},
+	}
+	if err := (&Ingress{MeshResource: ingress.MeshResource, Spec: &spec}).Validate(); err != nil {
+		t.Fatalf("validate static cache failed: %v", err)
+	}
+
+	for _, filters := range []*IngressPathFilters{
+		{Path: "/admin", Cache: &IngressCache{}},
+		{Path: "/api"},
+		{Path: "/api", Compression: &IngressCompression{Encodings: []string{"deflate"}}},
+		{Path: "/api", Compression: &IngressCompression{Encodings: []string{"br", "br"}}},
+		{Path: "/api", Compression: &IngressCompression{MinLength: -1}},
+		{Path: "/api", Compression: &IngressCompression{ContentTypes: []string{"json"}}},
+		{Path: "/api", Cache: &IngressCache{TTL: "0s"}},
+		{Path: "/api", Cache: &IngressCache{Methods: []string{"POST"}}},
+		{Path: "/api", Cache: &IngressCache{KeyHeaders: []string{"X User"}}},
+		{Path: "/api", Cache: &IngressCache{MaxEntries: -1}},
+		{Path: "/api", Cache: &IngressCache{Mode: "lazy"}},
+		{Path: "/api", Cache: &IngressCache{ClientTTL: "-1h"}},
+		{Path: "/api", Cache: &IngressCache{ClientTTL: "1.5s"}},
+		{Path: "/static", Cache: &IngressCache{}},
+	} {
+		spec := *ingress.Spec
+		spec.PathFilters = []*IngressPathFilters{ingress.Spec.PathFilters[1], filters}
+		invalid := &Ingress{MeshResource: ingress.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid path filters %+v should fail", filters)
+		}
+	}
+}
+
+func TestIngressCachePurge(t *testing.T) {
+	for _, purge := range []*IngressCachePurge{
+		{Host: "app.example.com", Path: "/assets/*"},
+		{Host: "app.example.com", Path: "/index.html"},
+		{Host: "app.example.com", Path: "/*"},
+	} {
+		if err := purge.Validate(); err != nil {
+			t.Fatalf("validate purge %+v failed: %v", purge, err)
+		}
+	}
+
+	for _, purge := range []*IngressCachePurge{
+		{Path: "/assets/*"},
+		{Host: "app.example.com/assets", Path: "/*"},
+		{Host: "app.example.com", Path: "assets/*"},
+		{Host: "app.example.com", Path: "/assets/*.js"},
+	} {
+		if err := purge.Validate(); err == nil {
+			t.Fatalf("validate invalid purge %+v should fail", purge)
+		}
+	}
+}
+
+func TestIngressLimits(t *testing.T) {
+	ingress := &Ingress{
+		MeshResource: NewIngressResource(DefaultAPIVersion, "ingress-sample"),
+		Spec: &IngressSpec{
+			Rules: []*v1alpha1.IngressRule{{
+				Host:  "www.megaease.com",
+				Paths: []*v1alpha1.IngressPath{{Path: "/api", Backend: "order-service"}, {Path: "/upload", Backend: "file-service"}},
+			}},
+			Limits: &IngressLimits{
+				MaxBodySize:       1 << 20,
+				MaxHeaderCount:    100,
+				MaxHeaderSize:     16 << 10,
+				ReadHeaderTimeout: "5s",
+				ReadTimeout:       "30s",
+				WriteTimeout:      "30s",
+			},
+			PathLimits: []*IngressPathLimits{
+				{Host: "www.megaease.com", Path: "/upload", MaxBodySize: 100 << 20, ReadTimeout: "5m"},
+			},
+		},
+	}
+	if err := ingress.Validate(); err != nil {
+		t.Fatalf("validate ingress failed: %v", err)
+	}
+	if got := IngressFromObject(ingress.ToObject()); got.Spec.Limits == nil || len(got.Spec.PathLimits) != 1 {
+		t.Fatalf("limits should be kept in the object, got %+v", got.Spec)
+	}
+
+	for _, modify := range []func(s *IngressSpec){
+		func(s *IngressSpec) { s.Limits = &IngressLimits{MaxBodySize: -1} },
+		func(s *IngressSpec) { s.Limits = &IngressLimits{ReadTimeout: "30"} },
+		func(s *IngressSpec) { s.Limits = &IngressLimits{ReadHeaderTimeout: "1m", ReadTimeout: "30s"} },
+		func(s *IngressSpec) { s.Limits = &IngressLimits{WriteTimeout: "-1s"} },
+		func(s *IngressSpec) { s.PathLimits = []*IngressPathLimits{{Host: "www.megaease.com", Path: "/admin"}} },
+		func(s *IngressSpec) {
+			s.PathLimits = []*IngressPathLimits{{Host: "www.megaease.com", Path: "/api", WriteTimeout: "0s"}}
+		},
+		func(s *IngressSpec) {
+			s.PathLimits = []*IngressPathLimits{{Host: "www.megaease.com", Path: "/api"}, {Host: "www.megaease.com", Path: "/api"}}
+		},
+	} {
+		spec := *ingress.Spec
+		modify(&spec)
+		invalid := &Ingress{MeshResource: ingress.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid ingress %+v should fail", spec)
+		}
+	}
+}
+
+func TestWAFPolicy(t *testing.T) {
+	policy := &WAFPolicy{
+		MeshResource: NewWAFPolicyResource(DefaultAPIVersion, "pet-waf"),
+		Spec: &WAFPolicySpec{
+			Mode:     WAFModeBlock,
+			RuleSets: []string{"sqli", "xss"},
+			Rules: []*WAFRule{
+				{ID: "no-admin", Target: WAFTargetURI, Pattern: "^/admin"},
+				{ID: "bad-bot", Target: WAFTargetHeader, Header: "User-Agent", Pattern: "(?i)sqlmap|nikto"},
+			},
+			Targets: []*WAFTarget{{Ingress: "pet-ingress", Path: "/api"}},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("validate WAF policy failed: %v", err)
+	}
+	if code := policy.Spec.BlockStatusCodeOrDefault(); code != DefaultWAFBlockStatusCode {
+		t.Fatalf("block status code should default to %d, got %d", DefaultWAFBlockStatusCode, code)
+	}
+	if columns := policy.Columns(); columns[1].Value != "sqli,xss" || columns[2].Value != "2" {
+		t.Fatalf("unexpected columns %+v %+v", columns[1], columns[2])
+	}
+
+	for _, modify := range []func(s *WAFPolicySpec){
+		func(s *WAFPolicySpec) { s.Mode = "log" },
+		func(s *WAFPolicySpec) { s.BlockStatusCode = 302 },
+		func(s *WAFPolicySpec) { s.RuleSets, s.Rules = nil, nil },
+		func(s *WAFPolicySpec) { s.RuleSets = []string{"ssrf"} },
+		func(s *WAFPolicySpec) { s.Rules = []*WAFRule{{Target: WAFTargetURI, Pattern: "x"}} },
+		func(s *WAFPolicySpec) { s.Rules = []*WAFRule{s.Rules[0], s.Rules[0]} },
+		func(s *WAFPolicySpec) { s.Rules = []*WAFRule{{ID: "r", Target: "cookie", Pattern: "x"}} },
+		func(s *WAFPolicySpec) { s.Rules = []*WAFRule{{ID: "r", Target: WAFTargetHeader, Pattern: "x"}} },
+		func(s *WAFPolicySpec) {
+			s.Rules = []*WAFRule{{ID: "r", Target: WAFTargetBody, Header: "Host", Pattern: "x"}}
+		},
+		func(s *WAFPolicySpec) { s.Rules = []*WAFRule{{ID: "r", Target: WAFTargetQuery, Pattern: "(?<=id)"}} },
+		func(s *WAFPolicySpec) { s.Targets = nil },
+		func(s *WAFPolicySpec) { s.Targets = []*WAFTarget{{Path: "/api"}} },
+	} {
+		spec := *policy.Spec
+		modify(&spec)
+		invalid := &WAFPolicy{MeshResource: policy.MeshResource, Spec: &spec}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("validate invalid WAF policy %+v should fail", spec)
+		}
+	}
+}
+
+func TestResourceStatus(t *testing.T) {
+	condition := func(conditionType, status, message string) *Condition {
+		return &Condition{Type: conditionType, Status: status, Message: message}
+	}
+
+	cases := []struct {
+		status    *ResourceStatus
+		phase     string
+		unhealthy string
+	}{
+		{&ResourceStatus{}, "Pending", ""},
+		{&ResourceStatus{
+			Conditions: []*Condition{condition(ConditionAccepted, ConditionFalse, "unknown service vets")},
+		}, "Rejected", "rejected: unknown service vets"},
+		{&ResourceStatus{
+			SyncedSidecars: 2, TotalSidecars: 5,
+			Conditions: []*Condition{condition(ConditionAccepted, ConditionTrue, "")},
+		}, "Propagating", ""},
+		{&ResourceStatus{
+			LastError:  "sidecar vets-0 rejected the config",
+			Conditions: []*Condition{condition(ConditionAccepted, ConditionTrue, ""), condition("ready", ConditionFalse, "")},
+		}, "Failed", "sidecar vets-0 rejected the config"},
+		{&ResourceStatus{
+			LastError:  "sidecar vets-0 rejected the config",
+			Conditions: []*Condition{condition(ConditionAccepted, ConditionTrue, ""), condition(ConditionReady, ConditionTrue, "")},
+		}, "Ready", ""},
+	}
+	for i, c := range cases {
+		if phase := c.status.Phase(); phase != c.phase {
+			t.Errorf("case %d: expected phase %s, got %s", i, c.phase, phase)
+		}
+		if unhealthy := c.status.Unhealthy(); unhealthy != c.unhealthy {
+			t.Errorf("case %d: expected unhealthy %q, got %q", i, c.unhealthy, unhealthy)
+		}
+	}
+
+	if synced := cases[2].status.Synced(); synced != "2/5" {
+		t.Errorf("expected synced 2/5, got %s", synced)
+	}
+}
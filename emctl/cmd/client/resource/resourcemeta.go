@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package resource
+
+import (
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// KindResourceMeta is the kind of the labels and annotations of a resource,
+// it's maintained by emctl rather than users.
+const KindResourceMeta = "ResourceMeta"
+
+type (
+	// ResourceMeta keeps labels and annotations of a resource, since objects
+	// stored in the control plane carry no metadata but their names.
+	ResourceMeta struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *ResourceMetaSpec `yaml:"spec"`
+	}
+
+	// ResourceMetaSpec is the spec of ResourceMeta
+	ResourceMetaSpec struct {
+		Kind        string            `yaml:"kind" json:"kind"`
+		Name        string            `yaml:"name" json:"name"`
+		Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+		Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	}
+
+	// ResourceMetaObject is the ResourceMeta object stored in the control plane of the EaseMesh
+	ResourceMetaObject struct {
+		Name string `json:"name"`
+		*ResourceMetaSpec
+	}
+)
+
+// ResourceMetaName returns the name of the ResourceMeta of a resource
+func ResourceMetaName(kind, name string) string {
+	return strings.ToLower(kind) + "-" + name
+}
+
+// HasResourceMeta reports whether labels and annotations of the kind are kept
+// in ResourceMeta. Service instances carry their own labels, and the kinds
+// maintained by emctl itself need none.
+func HasResourceMeta(kind string) bool {
+	switch kind {
+	case KindServiceInstance, KindPolicyRollout, KindApplySet, KindResourceMeta:
+		return false
+	}
+	return true
+}
+
+// NewResourceMeta returns the ResourceMeta keeping labels and annotations of the object
+func NewResourceMeta(object meta.MeshObject) *ResourceMeta {
+	return &ResourceMeta{
+		MeshResource: NewMeshResource(DefaultAPIVersion, KindResourceMeta,
+			ResourceMetaName(object.Kind(), object.Name())),
+		Spec: &ResourceMetaSpec{
+			Kind:        object.Kind(),
+			Name:        object.Name(),
+			Labels:      object.Labels(),
+			Annotations: object.Annotations(),
+		},
+	}
+}
+
+// ApplyTo sets labels and annotations kept in the ResourceMeta to the object
+func (r *ResourceMeta) ApplyTo(object meta.MeshObject) {
+	if r.Spec == nil {
+		return
+	}
+	setter, ok := object.(meta.MetaDataSetter)
+	if !ok {
+		return
+	}
+	setter.SetLabels(r.Spec.Labels)
+	setter.SetAnnotations(r.Spec.Annotations)
+}
+
+// ToObject converts a ResourceMeta resource to the object of the control plane
+func (r *ResourceMeta) ToObject() *ResourceMetaObject {
+	result := &ResourceMetaObject{
+		Name:             r.Name(),
+		ResourceMetaSpec: &ResourceMetaSpec{},
+	}
+	if r.Spec != nil {
+		result.ResourceMetaSpec = r.Spec
+	}
+	return result
+}
+
+// ToResourceMeta converts an object of the control plane to a ResourceMeta resource
+func ToResourceMeta(object *ResourceMetaObject) *ResourceMeta {
+	spec := object.ResourceMetaSpec
+	if spec == nil {
+		spec = &ResourceMetaSpec{}
+	}
+	return &ResourceMeta{
+		MeshResource: NewMeshResource(DefaultAPIVersion, KindResourceMeta, object.Name),
+		Spec:         spec,
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// KindRevision is the kind of the revision of EaseMesh resources,
+// revisions are read-only which could not be applied or deleted.
+const KindRevision = "Revision"
+
+type (
+	// Revision is a historical revision of a mesh resource stored in the control plane
+	Revision struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *RevisionObject `yaml:"spec"`
+	}
+
+	// RevisionObject is the revision object stored in the control plane of the EaseMesh
+	RevisionObject struct {
+		// Kind and Name identify the revised resource.
+		Kind string `yaml:"kind" json:"kind"`
+		Name string `yaml:"name" json:"name"`
+
+		// Revision increases monotonically for every change of the resource.
+		Revision int64 `yaml:"revision" json:"revision"`
+		// CreatedAt is the time of the change in RFC3339.
+		CreatedAt string `yaml:"createdAt" json:"createdAt"`
+		// Operator is who changed the resource.
+		Operator string `yaml:"operator,omitempty" json:"operator,omitempty"`
+		// ChangeCause describes the change, e.g. rollback to revision 3.
+		ChangeCause string `yaml:"changeCause,omitempty" json:"changeCause,omitempty"`
+
+		// Content is the whole resource of this revision.
+		Content map[string]interface{} `yaml:"content,omitempty" json:"content,omitempty"`
+	}
+)
+
+var _ meta.TableObject = &Revision{}
+
+// Columns returns the columns of Revision.
+func (r *Revision) Columns() []*meta.TableColumn {
+	if r.Spec == nil {
+		return nil
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Revision",
+			Value: strconv.FormatInt(r.Spec.Revision, 10),
+		},
+		{
+			Name:  "CreatedAt",
+			Value: r.Spec.CreatedAt,
+		},
+		{
+			Name:  "Operator",
+			Value: r.Spec.Operator,
+		},
+		{
+			Name:  "ChangeCause",
+			Value: r.Spec.ChangeCause,
+		},
+	}
+}
+
+// ToRevision converts an object of the control plane to a Revision resource
+func ToRevision(object *RevisionObject) *Revision {
+	name := fmt.Sprintf("%s/%s", object.Kind, object.Name)
+	if object.Kind == KindService && object.Content != nil {
+		// NOTE: The content is shown as it is if routes can't be recognized.
+		_ = showServiceRoutes(object.Content)
+	}
+	return &Revision{
+		MeshResource: NewMeshResource(DefaultAPIVersion, KindRevision, name),
+		Spec:         object,
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SecretKeyTLSCert is the key of the certificate in the referenced secret.
+	SecretKeyTLSCert = "tls.crt"
+	// SecretKeyTLSKey is the key of the private key in the referenced secret.
+	SecretKeyTLSKey = "tls.key"
+	// SecretKeyCACert is the key of the CA certificate in the referenced secret.
+	SecretKeyCACert = "ca.crt"
+)
+
+var secretNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+type (
+	// SecretRef references credentials kept out of mesh resources, so specs
+	// never carry them in plaintext. The control plane resolves them and
+	// distributes them only to sidecars which need them.
+	SecretRef struct {
+		// Kubernetes is a Secret in the form of namespace/name or name,
+		// the namespace is the mesh namespace by default.
+		Kubernetes string `yaml:"kubernetes,omitempty" json:"kubernetes,omitempty" jsonschema:"omitempty"`
+		// Vault is the path of a secret in Vault like secret/data/payments/stripe,
+		// which is read by the secret store of the MeshController.
+		Vault string `yaml:"vault,omitempty" json:"vault,omitempty" jsonschema:"omitempty"`
+	}
+)
+
+// Validate validates the reference, exactly one of the stores must be set.
+func (r *SecretRef) Validate() error {
+	switch {
+	case r.Kubernetes != "" && r.Vault != "":
+		return errors.New("only one of kubernetes and vault could be set")
+	case r.Kubernetes != "":
+		parts := strings.Split(r.Kubernetes, "/")
+		if len(parts) > 2 {
+			return errors.Errorf("invalid kubernetes secret %q, it must be namespace/name or name", r.Kubernetes)
+		}
+		for _, part := range parts {
+			if !secretNamePattern.MatchString(part) {
+				return errors.Errorf("invalid kubernetes secret %q, it must be namespace/name or name", r.Kubernetes)
+			}
+		}
+	case r.Vault != "":
+		if strings.HasPrefix(r.Vault, "/") || strings.HasSuffix(r.Vault, "/") || strings.Contains(r.Vault, "//") {
+			return errors.Errorf("invalid vault path %q", r.Vault)
+		}
+	default:
+		return errors.New("one of kubernetes and vault must be set")
+	}
+	return nil
+}
@@ -18,10 +18,24 @@
 package resource
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
 	"github.com/megaease/easemesh-api/v1alpha1"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 )
 
+const (
+	// ServiceProtocolHTTP is the default protocol of services, the sidecar proxies HTTP requests.
+	ServiceProtocolHTTP = "http"
+	// ServiceProtocolTCP makes the sidecar and the ingress proxy raw TCP connections,
+	// without parsing HTTP, so only connection-level metrics are reported.
+	ServiceProtocolTCP = "tcp"
+)
+
 type (
 	// Service describes service resource of the EaseMesh
 	Service struct {
@@ -32,6 +46,8 @@ type (
 	// ServiceSpec describes details of the service resource
 	ServiceSpec struct {
 		RegisterTenant string `yaml:"registerTenant" jsonschema:"required"`
+		// Protocol is http by default, tcp services can't have HTTP-only policies.
+		Protocol string `yaml:"protocol,omitempty" jsonschema:"omitempty,enum=http,enum=tcp"`
 
 		Sidecar       *v1alpha1.Sidecar       `yaml:"sidecar" jsonschema:"required"`
 		Mock          *v1alpha1.Mock          `yaml:"mock" jsonschema:"omitempty"`
@@ -39,10 +55,17 @@ type (
 		Canary        *v1alpha1.Canary        `yaml:"canary" jsonschema:"omitempty"`
 		LoadBalance   *v1alpha1.LoadBalance   `yaml:"loadBalance" jsonschema:"omitempty"`
 		Observability *v1alpha1.Observability `yaml:"observability" jsonschema:"omitempty"`
+
+		// Routes carry policies of requests matching them, which are translated
+		// into URL rules of the resilience and the canary ahead of service-wide rules.
+		Routes []*ServiceRoute `yaml:"routes,omitempty" json:"routes,omitempty" jsonschema:"omitempty"`
 	}
 )
 
-var _ meta.TableObject = &Service{}
+var (
+	_ meta.TableObject     = &Service{}
+	_ meta.WideTableObject = &Service{}
+)
 
 // Columns returns the columns of Service.
 func (s *Service) Columns() []*meta.TableColumn {
@@ -55,11 +78,169 @@ func (s *Service) Columns() []*meta.TableColumn {
 			Name:  "Tenant",
 			Value: s.Spec.RegisterTenant,
 		},
+		{
+			Name:  "Protocol",
+			Value: s.Spec.ProtocolOrDefault(),
+		},
 	}
 }
 
+// WideColumns returns the extra columns of Service in the wide table.
+func (s *Service) WideColumns() []*meta.TableColumn {
+	if s.Spec == nil {
+		return nil
+	}
+
+	sidecar := ""
+	if s.Spec.Sidecar != nil {
+		sidecar = fmt.Sprintf("%d/%s,%d/%s", s.Spec.Sidecar.IngressPort, s.Spec.Sidecar.IngressProtocol,
+			s.Spec.Sidecar.EgressPort, s.Spec.Sidecar.EgressProtocol)
+	}
+
+	var policies []string
+	for _, policy := range []struct {
+		name string
+		set  bool
+	}{
+		{"resilience", s.Spec.Resilience != nil},
+		{"canary", s.Spec.Canary != nil},
+		{"loadBalance", s.Spec.LoadBalance != nil},
+		{"mock", s.Spec.Mock != nil},
+		{"observability", s.Spec.Observability != nil},
+	} {
+		if policy.set {
+			policies = append(policies, policy.name)
+		}
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Sidecar",
+			Value: sidecar,
+		},
+		{
+			Name:  "Policies",
+			Value: strings.Join(policies, ","),
+		},
+		{
+			Name:  "Routes",
+			Value: strconv.Itoa(len(s.Spec.Routes)),
+		},
+	}
+}
+
+// ProtocolOrDefault returns the protocol of the service, which is also
+// recognized from the ingress protocol of the sidecar.
+func (s *ServiceSpec) ProtocolOrDefault() string {
+	if s.Protocol != "" {
+		return s.Protocol
+	}
+	if s.Sidecar != nil && s.Sidecar.IngressProtocol == ServiceProtocolTCP {
+		return ServiceProtocolTCP
+	}
+	return ServiceProtocolHTTP
+}
+
+// IsTCP reports whether the service is proxied at L4.
+func (s *ServiceSpec) IsTCP() bool {
+	return s.ProtocolOrDefault() == ServiceProtocolTCP
+}
+
+// Validate validates the Service before it's applied.
+func (s *Service) Validate() error {
+	if s.Spec == nil {
+		return nil
+	}
+
+	switch s.Spec.Protocol {
+	case "", ServiceProtocolHTTP, ServiceProtocolTCP:
+	default:
+		return errors.Errorf("unsupported protocol %q (support %s, %s)",
+			s.Spec.Protocol, ServiceProtocolHTTP, ServiceProtocolTCP)
+	}
+
+	if len(s.Spec.Routes) != 0 {
+		err := validateServiceRoutes(s.Spec.Routes)
+		if err != nil {
+			return err
+		}
+		_, err = s.Translate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !s.Spec.IsTCP() {
+		return nil
+	}
+
+	policies := HTTPOnlyResiliencePolicies(s.Spec.Resilience)
+	if s.Spec.Canary != nil {
+		policies = append(policies, "canary")
+	}
+	if s.Spec.Mock != nil {
+		policies = append(policies, "mock")
+	}
+	if len(s.Spec.Routes) != 0 {
+		policies = append(policies, "routes")
+	}
+	if s.Spec.LoadBalance != nil && s.Spec.LoadBalance.Policy == "headerHash" {
+		policies = append(policies, "loadBalance headerHash")
+	}
+	if len(policies) != 0 {
+		return errors.Errorf("tcp service can't have HTTP-only policies: %s", strings.Join(policies, ", "))
+	}
+
+	return nil
+}
+
+// HTTPOnlyResiliencePolicies returns names of the resilience policies which
+// work on HTTP requests, so they are unavailable for tcp services.
+func HTTPOnlyResiliencePolicies(resilience *v1alpha1.Resilience) []string {
+	policies := []string{}
+	if resilience == nil {
+		return policies
+	}
+
+	if resilience.Retryer != nil {
+		policies = append(policies, "retryer")
+	}
+	if resilience.RateLimiter != nil {
+		policies = append(policies, "rateLimiter")
+	}
+	if resilience.TimeLimiter != nil {
+		policies = append(policies, "timeLimiter")
+	}
+
+	return policies
+}
+
 // ToV1Alpha1 converts an Ingress resource to v1alpha1.Ingress
+// NOTE: It's called by the client generated for every kind, which can't return errors,
+// routes failed to translate are left out, Validate and Translate report the error.
 func (s *Service) ToV1Alpha1() *v1alpha1.Service {
+	result, err := s.Translate()
+	if err != nil {
+		result = s.toV1Alpha1()
+	}
+	return result
+}
+
+// Translate converts the Service to v1alpha1.Service, whose resilience and canary
+// carry the URL rules translated from its routes.
+func (s *Service) Translate() (*v1alpha1.Service, error) {
+	result := s.toV1Alpha1()
+	if s.Spec != nil && len(s.Spec.Routes) != 0 {
+		resilience, canary, err := translateServiceRoutes(s.Spec.Routes, s.Spec.Resilience, s.Spec.Canary)
+		if err != nil {
+			return nil, errors.Wrap(err, "translate routes")
+		}
+		result.Resilience, result.Canary = resilience, canary
+	}
+	return result, nil
+}
+
+func (s *Service) toV1Alpha1() *v1alpha1.Service {
 	result := &v1alpha1.Service{}
 	result.Name = s.Name()
 	if s.Spec != nil {
@@ -70,6 +251,12 @@ func (s *Service) ToV1Alpha1() *v1alpha1.Service {
 		result.Mock = s.Spec.Mock
 		result.Sidecar = s.Spec.Sidecar
 		result.Observability = s.Spec.Observability
+
+		// NOTE: The control plane recognizes tcp services by protocols of the sidecar.
+		if s.Spec.Protocol == ServiceProtocolTCP && s.Spec.Sidecar != nil {
+			s.Spec.Sidecar.IngressProtocol = ServiceProtocolTCP
+			s.Spec.Sidecar.EgressProtocol = ServiceProtocolTCP
+		}
 	}
 	return result
 }
@@ -87,5 +274,14 @@ func ToService(service *v1alpha1.Service) *Service {
 	result.Spec.Mock = service.Mock
 	result.Spec.LoadBalance = service.LoadBalance
 	result.Spec.Observability = service.Observability
+	// NOTE: Rules translated from routes are shown as the routes, so applying
+	// the service read back translates them again instead of duplicating them.
+	resilience, canary, routes, err := splitServiceRoutes(service.Resilience, service.Canary)
+	if err == nil && len(routes) != 0 {
+		result.Spec.Resilience, result.Spec.Canary, result.Spec.Routes = resilience, canary, routes
+	}
+	if result.Spec.IsTCP() {
+		result.Spec.Protocol = ServiceProtocolTCP
+	}
 	return result
 }
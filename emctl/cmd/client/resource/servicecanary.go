@@ -18,14 +18,31 @@
 package resource
 
 import (
+	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/megaease/easemesh-api/v1alpha1"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 )
 
+const (
+	// ServiceCanaryStickyCookie keeps users in the canary by a cookie set by the sidecar.
+	ServiceCanaryStickyCookie = "cookie"
+	// ServiceCanaryStickyHash decides the canary by consistent hashing on the user identity.
+	ServiceCanaryStickyHash = "hash"
+
+	// DefaultServiceCanaryStickyCookie is the default name of the sticky cookie.
+	DefaultServiceCanaryStickyCookie = "X-Mesh-Service-Canary"
+)
+
+var cookieNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
 type (
 	// ServiceCanary describes canary resource of the EaseMesh.
 	ServiceCanary struct {
@@ -38,6 +55,41 @@ type (
 		Priority     int32
 		Selector     *v1alpha1.ServiceSelector `yaml:"selector" jsonschema:"required"`
 		TrafficRules *v1alpha1.TrafficRules    `yaml:"trafficRules" jsonschema:"required"`
+		// Match is an optional canary match expression, requests are routed to
+		// the canary only if they match both the traffic rules and it.
+		Match string `yaml:"match,omitempty" json:"match,omitempty" jsonschema:"omitempty"`
+		// Sticky keeps users in the canary for the session once they are routed to it.
+		Sticky *ServiceCanarySticky `yaml:"sticky,omitempty" json:"sticky,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ServiceCanarySticky is the session affinity of the service canary.
+	//
+	// In the cookie mode, the sidecar sets the cookie with the canary name in
+	// responses of requests routed to the canary, and routes requests with the
+	// cookie to the canary before evaluating traffic rules and the match.
+	//
+	// In the hash mode, requests matching traffic rules and the match are routed
+	// to the canary only if the bucket of the key is less than the percentage,
+	// so the same user is always routed to the same version.
+	ServiceCanarySticky struct {
+		Mode string `yaml:"mode" json:"mode" jsonschema:"required,enum=cookie,enum=hash"`
+
+		// CookieName is the name of the cookie in the cookie mode.
+		CookieName string `yaml:"cookieName,omitempty" json:"cookieName,omitempty" jsonschema:"omitempty"`
+		// MaxAge is the max age of the cookie, empty means a session cookie.
+		MaxAge string `yaml:"maxAge,omitempty" json:"maxAge,omitempty" jsonschema:"omitempty,format=duration"`
+
+		// Key is the user identity in the hash mode, e.g. cookie("uid").
+		Key string `yaml:"key,omitempty" json:"key,omitempty" jsonschema:"omitempty"`
+		// Percentage is the percentage of users routed to the canary in the hash mode.
+		Percentage int `yaml:"percentage,omitempty" json:"percentage,omitempty" jsonschema:"omitempty,minimum=0,maximum=100"`
+	}
+
+	// ServiceCanaryObject is the ServiceCanary object stored in the control plane of the EaseMesh
+	ServiceCanaryObject struct {
+		*v1alpha1.ServiceCanary
+		Match  string               `json:"match,omitempty"`
+		Sticky *ServiceCanarySticky `json:"sticky,omitempty"`
 	}
 )
 
@@ -57,6 +109,11 @@ func (sc *ServiceCanary) Columns() []*meta.TableColumn {
 	}
 	sort.Strings(labels)
 
+	sticky := ""
+	if sc.Spec.Sticky != nil {
+		sticky = sc.Spec.Sticky.Mode
+	}
+
 	return []*meta.TableColumn{
 		{
 			Name:  "Services",
@@ -70,9 +127,111 @@ func (sc *ServiceCanary) Columns() []*meta.TableColumn {
 			Name:  "Priority",
 			Value: strconv.Itoa(int(sc.Spec.Priority)),
 		},
+		{
+			Name:  "Sticky",
+			Value: sticky,
+		},
 	}
 }
 
+// Validate validates the ServiceCanary before it's applied.
+func (sc *ServiceCanary) Validate() error {
+	if sc.Spec == nil {
+		return nil
+	}
+
+	if sc.Spec.Match != "" {
+		_, err := ParseCanaryMatch(sc.Spec.Match)
+		if err != nil {
+			return errors.Wrapf(err, "invalid match %q", sc.Spec.Match)
+		}
+	}
+
+	if sc.Spec.Sticky != nil {
+		err := sc.Spec.Sticky.validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid sticky")
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceCanarySticky) validate() error {
+	switch s.Mode {
+	case ServiceCanaryStickyCookie:
+		if s.Key != "" || s.Percentage != 0 {
+			return errors.Errorf("key and percentage are only for the %s mode", ServiceCanaryStickyHash)
+		}
+		if s.CookieName != "" && !cookieNamePattern.MatchString(s.CookieName) {
+			return errors.Errorf("invalid cookie name %q", s.CookieName)
+		}
+		if s.MaxAge != "" {
+			maxAge, err := time.ParseDuration(s.MaxAge)
+			if err != nil || maxAge <= 0 {
+				return errors.Errorf("invalid max age %q, want a positive duration", s.MaxAge)
+			}
+		}
+	case ServiceCanaryStickyHash:
+		if s.CookieName != "" || s.MaxAge != "" {
+			return errors.Errorf("cookieName and maxAge are only for the %s mode", ServiceCanaryStickyCookie)
+		}
+		if s.Key == "" {
+			return errors.Errorf("key is required in the %s mode", ServiceCanaryStickyHash)
+		}
+		err := ValidateCanarySource(s.Key)
+		if err != nil {
+			return errors.Wrapf(err, "invalid key %q", s.Key)
+		}
+		if s.Percentage < 0 || s.Percentage > CanaryBuckets {
+			return errors.Errorf("percentage %d out of range [0, %d]", s.Percentage, CanaryBuckets)
+		}
+	default:
+		return errors.Errorf("unsupported mode %q (support %s, %s)", s.Mode, ServiceCanaryStickyCookie, ServiceCanaryStickyHash)
+	}
+
+	return nil
+}
+
+// Expression returns the match expression equivalent to the sticky of the
+// service canary of the name. In the cookie mode, requests matching it are
+// routed to the canary regardless of traffic rules and the match. In the hash
+// mode, requests must match it besides traffic rules and the match.
+func (s *ServiceCanarySticky) Expression(name string) string {
+	if s.Mode == ServiceCanaryStickyHash {
+		return fmt.Sprintf("bucket(%s) < %d", s.Key, s.Percentage)
+	}
+
+	cookieName := s.CookieName
+	if cookieName == "" {
+		cookieName = DefaultServiceCanaryStickyCookie
+	}
+	return fmt.Sprintf("cookie(%q) == %q", cookieName, name)
+}
+
+// ToObject converts a ServiceCanary resource to the object of the control plane.
+func (sc *ServiceCanary) ToObject() *ServiceCanaryObject {
+	result := &ServiceCanaryObject{
+		ServiceCanary: sc.ToV1Alpha1(),
+	}
+	if sc.Spec != nil {
+		result.Match = sc.Spec.Match
+		result.Sticky = sc.Spec.Sticky
+	}
+	return result
+}
+
+// ToServiceCanaryFromObject converts an object of the control plane to a ServiceCanary resource.
+func ToServiceCanaryFromObject(object *ServiceCanaryObject) *ServiceCanary {
+	if object.ServiceCanary == nil {
+		object.ServiceCanary = &v1alpha1.ServiceCanary{}
+	}
+	result := ToServiceCanary(object.ServiceCanary)
+	result.Spec.Match = object.Match
+	result.Spec.Sticky = object.Sticky
+	return result
+}
+
 // ToV1Alpha1 converts a ServiceCanary resource to v1alpha1.ServiceCanary.
 func (sc *ServiceCanary) ToV1Alpha1() *v1alpha1.ServiceCanary {
 	result := &v1alpha1.ServiceCanary{}
@@ -0,0 +1,703 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+
+	"github.com/pkg/errors"
+)
+
+// serviceRoutePolicyPrefix prefixes names of resilience policies translated from routes.
+const serviceRoutePolicyPrefix = "route-"
+
+var serviceRouteMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+type (
+	// ServiceRoute carries policies of the requests to the service matching it,
+	// which take precedence over the policies of the whole service. Routes have
+	// no timeouts, since the time limiter of the control plane only has the
+	// default timeout of the whole service, its URL rules carry no durations.
+	ServiceRoute struct {
+		Name  string             `yaml:"name" json:"name" jsonschema:"required"`
+		Match *ServiceRouteMatch `yaml:"match" json:"match" jsonschema:"required"`
+
+		Retry  *ServiceRouteRetry  `yaml:"retry,omitempty" json:"retry,omitempty" jsonschema:"omitempty"`
+		Canary *ServiceRouteCanary `yaml:"canary,omitempty" json:"canary,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ServiceRouteMatch matches requests by methods and path,
+	// exactly one of exact, prefix and regex is required.
+	ServiceRouteMatch struct {
+		// Methods are all methods if it's empty.
+		Methods []string `yaml:"methods,omitempty" json:"methods,omitempty" jsonschema:"omitempty"`
+		Exact   string   `yaml:"exact,omitempty" json:"exact,omitempty" jsonschema:"omitempty"`
+		Prefix  string   `yaml:"prefix,omitempty" json:"prefix,omitempty" jsonschema:"omitempty"`
+		Regex   string   `yaml:"regex,omitempty" json:"regex,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ServiceRouteRetry retries failed requests of the route.
+	ServiceRouteRetry struct {
+		MaxAttempts  int    `yaml:"maxAttempts" json:"maxAttempts" jsonschema:"required,minimum=1"`
+		WaitDuration string `yaml:"waitDuration,omitempty" json:"waitDuration,omitempty" jsonschema:"omitempty,format=duration"`
+		// FailureStatusCodes are the status codes to retry, e.g. 502, 503
+		FailureStatusCodes []int `yaml:"failureStatusCodes,omitempty" json:"failureStatusCodes,omitempty" jsonschema:"omitempty"`
+	}
+
+	// ServiceRouteCanary routes requests of the route carrying the headers
+	// to the instances with the labels.
+	ServiceRouteCanary struct {
+		InstanceLabels map[string]string `yaml:"instanceLabels" json:"instanceLabels" jsonschema:"required"`
+		Headers        map[string]string `yaml:"headers,omitempty" json:"headers,omitempty" jsonschema:"omitempty"`
+	}
+)
+
+// String returns the brief of the match, e.g. GET,POST prefix:/pets/.
+func (m *ServiceRouteMatch) String() string {
+	methods := "*"
+	if len(m.Methods) != 0 {
+		methods = strings.Join(m.Methods, ",")
+	}
+
+	switch {
+	case m.Exact != "":
+		return methods + " exact:" + m.Exact
+	case m.Prefix != "":
+		return methods + " prefix:" + m.Prefix
+	default:
+		return methods + " regex:" + m.Regex
+	}
+}
+
+func (m *ServiceRouteMatch) validate() error {
+	matchers := 0
+	for _, path := range []string{m.Exact, m.Prefix} {
+		if path == "" {
+			continue
+		}
+		matchers++
+		if !strings.HasPrefix(path, "/") {
+			return errors.Errorf("path %q must start with /", path)
+		}
+	}
+	if m.Regex != "" {
+		matchers++
+		if _, err := regexp.Compile(m.Regex); err != nil {
+			return errors.Wrapf(err, "invalid regex %q", m.Regex)
+		}
+	}
+	if matchers != 1 {
+		return errors.New("exactly one of exact, prefix and regex is required")
+	}
+
+	for _, method := range m.Methods {
+		if !containsString(serviceRouteMethods, method) {
+			return errors.Errorf("unsupported method %q", method)
+		}
+	}
+
+	return nil
+}
+
+// covers reports whether all requests matching other match m too.
+func (m *ServiceRouteMatch) covers(other *ServiceRouteMatch) bool {
+	if len(m.Methods) != 0 {
+		if len(other.Methods) == 0 {
+			return false
+		}
+		for _, method := range other.Methods {
+			if !containsString(m.Methods, method) {
+				return false
+			}
+		}
+	}
+
+	switch {
+	case m.Exact != "":
+		return other.Exact == m.Exact
+	case m.Prefix != "":
+		return (other.Exact != "" && strings.HasPrefix(other.Exact, m.Prefix)) ||
+			(other.Prefix != "" && strings.HasPrefix(other.Prefix, m.Prefix))
+	default:
+		return other.Regex == m.Regex
+	}
+}
+
+// overlaps reports whether some requests match both m and other with the same path matcher.
+func (m *ServiceRouteMatch) overlaps(other *ServiceRouteMatch) bool {
+	if m.Exact != other.Exact || m.Prefix != other.Prefix || m.Regex != other.Regex {
+		return false
+	}
+	if len(m.Methods) == 0 || len(other.Methods) == 0 {
+		return true
+	}
+	for _, method := range other.Methods {
+		if containsString(m.Methods, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// toObject converts the match to the URL rule of the control plane.
+func (m *ServiceRouteMatch) toObject() map[string]interface{} {
+	url := map[string]interface{}{}
+	switch {
+	case m.Exact != "":
+		url["exact"] = m.Exact
+	case m.Prefix != "":
+		url["prefix"] = m.Prefix
+	default:
+		url["regex"] = m.Regex
+	}
+
+	methods := m.Methods
+	if len(methods) == 0 {
+		methods = serviceRouteMethods
+	}
+
+	return map[string]interface{}{
+		"methods": methods,
+		"url":     url,
+	}
+}
+
+func (r *ServiceRoute) validate() error {
+	if r.Match == nil {
+		return errors.New("match is required")
+	}
+	err := r.Match.validate()
+	if err != nil {
+		return errors.Wrap(err, "match")
+	}
+
+	if r.Retry == nil && r.Canary == nil {
+		return errors.New("at least one of retry and canary is required")
+	}
+	if r.Retry != nil {
+		if r.Retry.MaxAttempts < 1 {
+			return errors.Errorf("retry: maxAttempts %d must be positive", r.Retry.MaxAttempts)
+		}
+		if r.Retry.WaitDuration != "" {
+			if _, err := time.ParseDuration(r.Retry.WaitDuration); err != nil {
+				return errors.Errorf("retry: invalid waitDuration %q", r.Retry.WaitDuration)
+			}
+		}
+	}
+	if r.Canary != nil && len(r.Canary.InstanceLabels) == 0 {
+		return errors.New("canary: instanceLabels is required")
+	}
+
+	return nil
+}
+
+// validateServiceRoutes validates routes and detects conflicts among them,
+// the first matched route takes effect, so a route covered by a former one is a conflict.
+func validateServiceRoutes(routes []*ServiceRoute) error {
+	names := map[string]bool{}
+	for i, route := range routes {
+		if route.Name == "" {
+			return errors.Errorf("route %d: name is required", i)
+		}
+		if names[route.Name] {
+			return errors.Errorf("route %s: duplicated name", route.Name)
+		}
+		names[route.Name] = true
+
+		err := route.validate()
+		if err != nil {
+			return errors.Wrapf(err, "route %s", route.Name)
+		}
+
+		for _, former := range routes[:i] {
+			if former.Match.overlaps(route.Match) {
+				return errors.Errorf("route %s conflicts with route %s: both match %s",
+					route.Name, former.Name, route.Match)
+			}
+			if former.Match.covers(route.Match) {
+				return errors.Errorf("route %s is shadowed by route %s: %s covers %s",
+					route.Name, former.Name, former.Match, route.Match)
+			}
+		}
+	}
+
+	return nil
+}
+
+// translateServiceRoutes translates routes into the URL rules of the resilience
+// and the canary of the service, rules of routes go ahead of the service-wide ones.
+// Rules translated from routes before are replaced, so translating again changes nothing.
+func translateServiceRoutes(routes []*ServiceRoute, resilience *v1alpha1.Resilience,
+	canary *v1alpha1.Canary) (*v1alpha1.Resilience, *v1alpha1.Canary, error) {
+	resilienceObject, err := toJSONObject(resilience)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "resilience")
+	}
+	canaryObject, err := toJSONObject(canary)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "canary")
+	}
+
+	extractServiceRoutes(resilienceObject, canaryObject)
+	err = checkServiceRouteConflicts(routes, resilienceObject, canaryObject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	retryer := jsonObjectField(resilienceObject, "retryer")
+	retryerPolicies, retryerURLs, canaryRules := []interface{}{}, []interface{}{}, []interface{}{}
+
+	for _, route := range routes {
+		if route.Retry != nil {
+			policy := map[string]interface{}{
+				"name":        serviceRoutePolicyPrefix + route.Name,
+				"maxAttempts": route.Retry.MaxAttempts,
+			}
+			if route.Retry.WaitDuration != "" {
+				policy["waitDuration"] = route.Retry.WaitDuration
+			}
+			if len(route.Retry.FailureStatusCodes) != 0 {
+				policy["failureStatusCodes"] = route.Retry.FailureStatusCodes
+			}
+			retryerPolicies = append(retryerPolicies, policy)
+			retryerURLs = append(retryerURLs, route.toURLRule())
+		}
+
+		if route.Canary != nil {
+			headers := map[string]interface{}{}
+			for k, v := range route.Canary.Headers {
+				headers[k] = map[string]interface{}{"exact": v}
+			}
+			canaryRules = append(canaryRules, map[string]interface{}{
+				"serviceInstanceLabels": route.Canary.InstanceLabels,
+				"headers":               headers,
+				"urls":                  []interface{}{route.toURLRule()},
+			})
+		}
+	}
+
+	prependJSONArray(retryer, "policies", retryerPolicies)
+	prependJSONArray(retryer, "urls", retryerURLs)
+	prependJSONArray(canaryObject, "canaryRules", canaryRules)
+
+	if len(retryerURLs) != 0 {
+		resilienceObject["retryer"] = retryer
+	}
+
+	return fromServicePolicyObjects(resilienceObject, canaryObject)
+}
+
+// fromServicePolicyObjects converts the JSON objects back to the resilience and the canary,
+// which are nil if they're empty.
+func fromServicePolicyObjects(resilienceObject, canaryObject map[string]interface{}) (
+	*v1alpha1.Resilience, *v1alpha1.Canary, error) {
+	var resilience *v1alpha1.Resilience
+	if len(resilienceObject) != 0 {
+		resilience = &v1alpha1.Resilience{}
+		if err := fromJSONObject(resilienceObject, resilience); err != nil {
+			return nil, nil, errors.Wrap(err, "resilience")
+		}
+	}
+
+	var canary *v1alpha1.Canary
+	if len(canaryObject) != 0 {
+		canary = &v1alpha1.Canary{}
+		if err := fromJSONObject(canaryObject, canary); err != nil {
+			return nil, nil, errors.Wrap(err, "canary")
+		}
+	}
+
+	return resilience, canary, nil
+}
+
+// splitServiceRoutes splits the URL rules translated from routes out of the resilience
+// and the canary of the service, and returns the routes of them.
+func splitServiceRoutes(resilience *v1alpha1.Resilience, canary *v1alpha1.Canary) (
+	*v1alpha1.Resilience, *v1alpha1.Canary, []*ServiceRoute, error) {
+	resilienceObject, err := toJSONObject(resilience)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "resilience")
+	}
+	canaryObject, err := toJSONObject(canary)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "canary")
+	}
+
+	routes := extractServiceRoutes(resilienceObject, canaryObject)
+
+	resilience, canary, err = fromServicePolicyObjects(resilienceObject, canaryObject)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return resilience, canary, routes, nil
+}
+
+// showServiceRoutes replaces the URL rules translated from routes in the service
+// object of the control plane with the routes, as services are read by Get.
+func showServiceRoutes(service map[string]interface{}) error {
+	resilienceObject, err := toJSONObject(service["resilience"])
+	if err != nil {
+		return errors.Wrap(err, "resilience")
+	}
+	canaryObject, err := toJSONObject(service["canary"])
+	if err != nil {
+		return errors.Wrap(err, "canary")
+	}
+
+	routes := extractServiceRoutes(resilienceObject, canaryObject)
+	if len(routes) == 0 {
+		return nil
+	}
+	routesObject := []interface{}{}
+	buff, err := json.Marshal(routes)
+	if err != nil {
+		return errors.Wrap(err, "routes")
+	}
+	err = json.Unmarshal(buff, &routesObject)
+	if err != nil {
+		return errors.Wrap(err, "routes")
+	}
+
+	service["resilience"], service["canary"], service["routes"] = resilienceObject, canaryObject, routesObject
+	compactJSONObject(service, "resilience", "canary")
+	return nil
+}
+
+// toURLRule converts the route to the URL rule of the control plane,
+// whose policyRef marks it as translated from the route.
+func (r *ServiceRoute) toURLRule() map[string]interface{} {
+	url := r.Match.toObject()
+	url["policyRef"] = serviceRoutePolicyPrefix + r.Name
+	return url
+}
+
+// serviceRouteNameOf returns the name of the route which the URL rule is translated from.
+func serviceRouteNameOf(url interface{}) (string, bool) {
+	object, ok := url.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	ref, _ := object["policyRef"].(string)
+	if !strings.HasPrefix(ref, serviceRoutePolicyPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ref, serviceRoutePolicyPrefix), true
+}
+
+// serviceRouteMatchOf converts the URL rule of the control plane to the match of routes.
+func serviceRouteMatchOf(url interface{}) *ServiceRouteMatch {
+	object := asJSONObject(url)
+	match := &ServiceRouteMatch{Methods: jsonStrings(object["methods"])}
+	if len(match.Methods) == 0 || len(match.Methods) == len(serviceRouteMethods) {
+		// NOTE: Routes match all methods by leaving them empty.
+		match.Methods = nil
+	}
+
+	path := jsonObjectField(object, "url")
+	match.Exact, _ = path["exact"].(string)
+	match.Prefix, _ = path["prefix"].(string)
+	match.Regex, _ = path["regex"].(string)
+
+	return match
+}
+
+// checkServiceRouteConflicts detects service-wide URL rules shadowed by routes,
+// since rules of routes go ahead of them.
+func checkServiceRouteConflicts(routes []*ServiceRoute, resilienceObject, canaryObject map[string]interface{}) error {
+	retryerURLs, _ := jsonObjectField(resilienceObject, "retryer")["urls"].([]interface{})
+	canaryURLs := []interface{}{}
+	canaryRules, _ := canaryObject["canaryRules"].([]interface{})
+	for _, rule := range canaryRules {
+		urls, _ := asJSONObject(rule)["urls"].([]interface{})
+		canaryURLs = append(canaryURLs, urls...)
+	}
+
+	for _, route := range routes {
+		for _, policy := range []struct {
+			name    string
+			enabled bool
+			urls    []interface{}
+		}{
+			{"retryer", route.Retry != nil, retryerURLs},
+			{"canary", route.Canary != nil, canaryURLs},
+		} {
+			if !policy.enabled {
+				continue
+			}
+			for _, url := range policy.urls {
+				match := serviceRouteMatchOf(url)
+				if route.Match.overlaps(match) || route.Match.covers(match) {
+					return errors.Errorf("route %s shadows the %s rule of the service: %s covers %s",
+						route.Name, policy.name, route.Match, match)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// extractServiceRoutes removes URL rules and retryer policies translated from routes
+// out of the resilience and the canary of the service, and returns the routes of them.
+func extractServiceRoutes(resilienceObject, canaryObject map[string]interface{}) []*ServiceRoute {
+	routes := map[string]*ServiceRoute{}
+	routeOf := func(name string, url interface{}) *ServiceRoute {
+		route, exists := routes[name]
+		if !exists {
+			route = &ServiceRoute{Name: name, Match: serviceRouteMatchOf(url)}
+			routes[name] = route
+		}
+		return route
+	}
+
+	// NOTE: Each list keeps the order of routes, which are merged back in the end.
+	orders := [][]string{}
+
+	if retryer, exists := resilienceObject["retryer"].(map[string]interface{}); exists {
+		policies := map[string]map[string]interface{}{}
+		retryer["policies"] = filterJSONArray(retryer["policies"], func(elem interface{}) bool {
+			policy := asJSONObject(elem)
+			name, _ := policy["name"].(string)
+			if strings.HasPrefix(name, serviceRoutePolicyPrefix) {
+				policies[strings.TrimPrefix(name, serviceRoutePolicyPrefix)] = policy
+				return false
+			}
+			return true
+		})
+
+		order := []string{}
+		retryer["urls"] = filterJSONArray(retryer["urls"], func(url interface{}) bool {
+			name, ok := serviceRouteNameOf(url)
+			if !ok {
+				return true
+			}
+			route := routeOf(name, url)
+			route.Retry = serviceRouteRetryOf(policies[name])
+			order = append(order, name)
+			return false
+		})
+		orders = append(orders, order)
+
+		compactJSONObject(retryer, "policies", "urls")
+		compactJSONObject(resilienceObject, "retryer")
+	}
+
+	order := []string{}
+	canaryObject["canaryRules"] = filterJSONArray(canaryObject["canaryRules"], func(elem interface{}) bool {
+		rule := asJSONObject(elem)
+		urls, _ := rule["urls"].([]interface{})
+		if len(urls) != 1 {
+			return true
+		}
+		name, ok := serviceRouteNameOf(urls[0])
+		if !ok {
+			return true
+		}
+		route := routeOf(name, urls[0])
+		route.Canary = &ServiceRouteCanary{
+			InstanceLabels: map[string]string{},
+			Headers:        map[string]string{},
+		}
+		for k, v := range jsonObjectField(rule, "serviceInstanceLabels") {
+			route.Canary.InstanceLabels[k], _ = v.(string)
+		}
+		for k, v := range jsonObjectField(rule, "headers") {
+			route.Canary.Headers[k], _ = asJSONObject(v)["exact"].(string)
+		}
+		if len(route.Canary.Headers) == 0 {
+			route.Canary.Headers = nil
+		}
+		order = append(order, name)
+		return false
+	})
+	orders = append(orders, order)
+	compactJSONObject(canaryObject, "canaryRules")
+
+	result := []*ServiceRoute{}
+	for _, name := range mergeServiceRouteOrders(routes, orders) {
+		result = append(result, routes[name])
+	}
+	return result
+}
+
+// serviceRouteRetryOf converts the retryer policy of the control plane to the retry of routes.
+func serviceRouteRetryOf(policy map[string]interface{}) *ServiceRouteRetry {
+	retry := &ServiceRouteRetry{}
+	if maxAttempts, ok := policy["maxAttempts"].(float64); ok {
+		retry.MaxAttempts = int(maxAttempts)
+	}
+	retry.WaitDuration, _ = policy["waitDuration"].(string)
+	codes, _ := policy["failureStatusCodes"].([]interface{})
+	for _, code := range codes {
+		if code, ok := code.(float64); ok {
+			retry.FailureStatusCodes = append(retry.FailureStatusCodes, int(code))
+		}
+	}
+	return retry
+}
+
+// mergeServiceRouteOrders merges orders of routes in lists of URL rules into one order
+// which keeps all of them. Among routes in different lists, the ones covering others go
+// later, so the merged routes are valid as they're validated before translating.
+func mergeServiceRouteOrders(routes map[string]*ServiceRoute, orders [][]string) []string {
+	result := []string{}
+	pending := func(name string) bool {
+		for _, order := range orders {
+			for i, other := range order {
+				if other == name && i != 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for {
+		candidates := []string{}
+		for _, order := range orders {
+			if len(order) != 0 && !pending(order[0]) && !containsString(candidates, order[0]) {
+				candidates = append(candidates, order[0])
+			}
+		}
+		if len(candidates) == 0 {
+			return result
+		}
+
+		next := candidates[0]
+	nextCandidate:
+		for _, candidate := range candidates {
+			for _, other := range candidates {
+				if other != candidate && routes[candidate].Match.covers(routes[other].Match) {
+					continue nextCandidate
+				}
+			}
+			next = candidate
+			break
+		}
+
+		result = append(result, next)
+		for i, order := range orders {
+			if len(order) != 0 && order[0] == next {
+				orders[i] = order[1:]
+			}
+		}
+	}
+}
+
+func toJSONObject(v interface{}) (map[string]interface{}, error) {
+	object := map[string]interface{}{}
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: A nil pointer is marshalled to null, which leaves the object empty.
+	err = json.Unmarshal(buff, &object)
+	if err != nil {
+		return nil, err
+	}
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+	return object, nil
+}
+
+func fromJSONObject(object map[string]interface{}, v interface{}) error {
+	buff, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buff, v)
+}
+
+func jsonObjectField(object map[string]interface{}, key string) map[string]interface{} {
+	return asJSONObject(object[key])
+}
+
+func asJSONObject(v interface{}) map[string]interface{} {
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+	return object
+}
+
+func prependJSONArray(object map[string]interface{}, key string, elems []interface{}) {
+	if len(elems) == 0 {
+		return
+	}
+	existed, _ := object[key].([]interface{})
+	object[key] = append(elems, existed...)
+}
+
+func filterJSONArray(array interface{}, keep func(elem interface{}) bool) []interface{} {
+	elems, _ := array.([]interface{})
+	result := []interface{}{}
+	for _, elem := range elems {
+		if keep(elem) {
+			result = append(result, elem)
+		}
+	}
+	return result
+}
+
+// compactJSONObject deletes the fields of empty arrays and objects.
+func compactJSONObject(object map[string]interface{}, keys ...string) {
+	for _, key := range keys {
+		switch field := object[key].(type) {
+		case []interface{}:
+			if len(field) == 0 {
+				delete(object, key)
+			}
+		case map[string]interface{}:
+			if len(field) == 0 {
+				delete(object, key)
+			}
+		case nil:
+			delete(object, key)
+		}
+	}
+}
+
+func jsonStrings(array interface{}) []string {
+	elems, _ := array.([]interface{})
+	result := []string{}
+	for _, elem := range elems {
+		if s, ok := elem.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultSLOWindow is the default rolling window of the error budget of SLOs.
+const DefaultSLOWindow = 30 * 24 * time.Hour
+
+type (
+	// SLO describes the service level objectives of a service or a route of
+	// the service. Requests failing the objectives consume the error budget,
+	// which is the ratio of bad requests allowed by the target in the window.
+	SLO struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *SLOSpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// SLOSpec describes the objectives of an SLO
+	SLOSpec struct {
+		Service string `yaml:"service" json:"service" jsonschema:"required"`
+		// Route is the path of requests, empty means all requests of the service.
+		Route string `yaml:"route,omitempty" json:"route,omitempty" jsonschema:"omitempty"`
+		// Window is the rolling window of the error budget, default is 720h (30 days).
+		Window string `yaml:"window,omitempty" json:"window,omitempty" jsonschema:"omitempty,format=duration"`
+
+		Availability *SLOAvailability `yaml:"availability,omitempty" json:"availability,omitempty" jsonschema:"omitempty"`
+		Latency      *SLOLatency      `yaml:"latency,omitempty" json:"latency,omitempty" jsonschema:"omitempty"`
+	}
+
+	// SLOAvailability is the objective of the percentage of successful requests
+	SLOAvailability struct {
+		// Target is the percentage, e.g. 99.9
+		Target float64 `yaml:"target" json:"target" jsonschema:"required,exclusiveMinimum=0,exclusiveMaximum=100"`
+	}
+
+	// SLOLatency is the objective of the percentage of requests faster than the threshold
+	SLOLatency struct {
+		Threshold string `yaml:"threshold" json:"threshold" jsonschema:"required,format=duration"`
+		// Target is the percentage, e.g. 99
+		Target float64 `yaml:"target" json:"target" jsonschema:"required,exclusiveMinimum=0,exclusiveMaximum=100"`
+	}
+
+	// SLOObject is the SLO object stored in the control plane of the EaseMesh
+	SLOObject struct {
+		Name string `json:"name"`
+		*SLOSpec
+	}
+)
+
+var _ meta.TableObject = &SLO{}
+
+// Columns returns the columns of SLO.
+func (s *SLO) Columns() []*meta.TableColumn {
+	if s.Spec == nil {
+		return nil
+	}
+
+	route := s.Spec.Route
+	if route == "" {
+		route = "*"
+	}
+	objectives := []string{}
+	if s.Spec.Availability != nil {
+		objectives = append(objectives, fmt.Sprintf("availability %g%%", s.Spec.Availability.Target))
+	}
+	if s.Spec.Latency != nil {
+		objectives = append(objectives, fmt.Sprintf("latency %g%% < %s", s.Spec.Latency.Target, s.Spec.Latency.Threshold))
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Service",
+			Value: s.Spec.Service,
+		},
+		{
+			Name:  "Route",
+			Value: route,
+		},
+		{
+			Name:  "Objectives",
+			Value: strings.Join(objectives, ","),
+		},
+		{
+			Name:  "Window",
+			Value: s.Spec.WindowDuration().String(),
+		},
+	}
+}
+
+// Validate validates the SLO before it's applied.
+func (s *SLO) Validate() error {
+	if s.Spec == nil {
+		return nil
+	}
+	if s.Spec.Service == "" {
+		return errors.New("service is required")
+	}
+	if s.Spec.Availability == nil && s.Spec.Latency == nil {
+		return errors.New("at least one of availability and latency is required")
+	}
+	if s.Spec.Route != "" && !strings.HasPrefix(s.Spec.Route, "/") {
+		return errors.Errorf("route %s must start with /", s.Spec.Route)
+	}
+	if s.Spec.Window != "" {
+		window, err := time.ParseDuration(s.Spec.Window)
+		if err != nil || window <= 0 {
+			return errors.Errorf("invalid window %q, it must be a positive duration like 720h", s.Spec.Window)
+		}
+	}
+
+	if s.Spec.Availability != nil {
+		err := validateSLOTarget(s.Spec.Availability.Target)
+		if err != nil {
+			return errors.Wrap(err, "availability")
+		}
+	}
+	if s.Spec.Latency != nil {
+		err := validateSLOTarget(s.Spec.Latency.Target)
+		if err != nil {
+			return errors.Wrap(err, "latency")
+		}
+		if _, err := s.Spec.Latency.ThresholdDuration(); err != nil {
+			return errors.Wrap(err, "latency")
+		}
+	}
+
+	return nil
+}
+
+func validateSLOTarget(target float64) error {
+	if target <= 0 || target >= 100 {
+		return errors.Errorf("target %g must be a percentage in (0, 100)", target)
+	}
+	return nil
+}
+
+// WindowDuration returns the rolling window of the error budget.
+func (s *SLOSpec) WindowDuration() time.Duration {
+	window, err := time.ParseDuration(s.Window)
+	if err != nil || window <= 0 {
+		return DefaultSLOWindow
+	}
+	return window
+}
+
+// ThresholdDuration returns the latency threshold.
+func (l *SLOLatency) ThresholdDuration() (time.Duration, error) {
+	threshold, err := time.ParseDuration(l.Threshold)
+	if err != nil || threshold <= 0 {
+		return 0, errors.Errorf("invalid threshold %q, it must be a positive duration like 300ms", l.Threshold)
+	}
+	return threshold, nil
+}
+
+// ToObject converts an SLO resource to the object of the control plane
+func (s *SLO) ToObject() *SLOObject {
+	result := &SLOObject{
+		Name:    s.Name(),
+		SLOSpec: &SLOSpec{},
+	}
+	if s.Spec != nil {
+		result.SLOSpec = s.Spec
+	}
+	return result
+}
+
+// ToSLO converts an object of the control plane to an SLO resource
+func ToSLO(object *SLOObject) *SLO {
+	result := &SLO{
+		Spec: object.SLOSpec,
+	}
+	result.MeshResource = NewSLOResource(DefaultAPIVersion, object.Name)
+	return result
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ConditionTrue means the condition holds.
+	ConditionTrue = "True"
+	// ConditionFalse means the condition doesn't hold.
+	ConditionFalse = "False"
+	// ConditionUnknown means the control plane can't tell whether the condition holds.
+	ConditionUnknown = "Unknown"
+
+	// ConditionAccepted means the resource passes the validation of the control
+	// plane and is stored, it's False with the error otherwise.
+	ConditionAccepted = "Accepted"
+	// ConditionReady means the resource is accepted by the control plane and
+	// pushed to all sidecars and ingresses it works on.
+	ConditionReady = "Ready"
+	// ConditionActive means the resource takes effect on traffic, e.g. a
+	// canary routes requests to its instances.
+	ConditionActive = "Active"
+)
+
+type (
+	// ResourceStatus is the observed state of a mesh resource populated by the
+	// control plane, it's read-only which could not be applied or deleted.
+	ResourceStatus struct {
+		Kind string `yaml:"kind" json:"kind"`
+		Name string `yaml:"name" json:"name"`
+		// ObservedRevision is the revision of the resource the conditions are observed for.
+		ObservedRevision int64 `yaml:"observedRevision,omitempty" json:"observedRevision,omitempty"`
+
+		// SyncedSidecars are the number of sidecars and ingresses running the
+		// observed revision, out of TotalSidecars the resource works on.
+		SyncedSidecars int `yaml:"syncedSidecars" json:"syncedSidecars"`
+		TotalSidecars  int `yaml:"totalSidecars" json:"totalSidecars"`
+
+		// LastError is the last error of accepting or propagating the resource,
+		// it's cleared once the resource is ready.
+		LastError string `yaml:"lastError,omitempty" json:"lastError,omitempty"`
+		// LastErrorTime is the time in RFC3339 of the last error.
+		LastErrorTime string `yaml:"lastErrorTime,omitempty" json:"lastErrorTime,omitempty"`
+
+		Conditions []*Condition `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+	}
+
+	// Condition is an aspect of the observed state of a mesh resource.
+	Condition struct {
+		// Type is e.g. Ready or Active, kinds may report their own types.
+		Type string `yaml:"type" json:"type"`
+		// Status is one of True, False and Unknown.
+		Status string `yaml:"status" json:"status"`
+		// Reason is a CamelCase word of the cause of the status.
+		Reason  string `yaml:"reason,omitempty" json:"reason,omitempty"`
+		Message string `yaml:"message,omitempty" json:"message,omitempty"`
+		// LastTransitionTime is the time in RFC3339 the status changed.
+		LastTransitionTime string `yaml:"lastTransitionTime,omitempty" json:"lastTransitionTime,omitempty"`
+	}
+)
+
+// Condition returns the condition of the type matched case-insensitively,
+// nil if it's not reported.
+func (s *ResourceStatus) Condition(conditionType string) *Condition {
+	for _, c := range s.Conditions {
+		if strings.EqualFold(c.Type, conditionType) {
+			return c
+		}
+	}
+	return nil
+}
+
+// isTrue reports whether the condition of the type is reported and True.
+func (s *ResourceStatus) isTrue(conditionType string) bool {
+	c := s.Condition(conditionType)
+	return c != nil && strings.EqualFold(c.Status, ConditionTrue)
+}
+
+// isFalse reports whether the condition of the type is reported and False.
+func (s *ResourceStatus) isFalse(conditionType string) bool {
+	c := s.Condition(conditionType)
+	return c != nil && strings.EqualFold(c.Status, ConditionFalse)
+}
+
+// Phase summarizes the status in a word: Rejected, Ready, Failed, Propagating or Pending.
+func (s *ResourceStatus) Phase() string {
+	switch {
+	case s.isFalse(ConditionAccepted):
+		return "Rejected"
+	case s.isTrue(ConditionReady):
+		return "Ready"
+	case s.LastError != "":
+		return "Failed"
+	case s.isTrue(ConditionAccepted):
+		return "Propagating"
+	}
+	return "Pending"
+}
+
+// Synced returns the propagation in the form of <synced>/<total>.
+func (s *ResourceStatus) Synced() string {
+	return fmt.Sprintf("%d/%d", s.SyncedSidecars, s.TotalSidecars)
+}
+
+// Unhealthy returns why the resource is unhealthy, empty if it's healthy or still
+// propagating. A rejected resource or a failed propagation is unhealthy.
+func (s *ResourceStatus) Unhealthy() string {
+	if s.isFalse(ConditionAccepted) {
+		c := s.Condition(ConditionAccepted)
+		if c.Message != "" {
+			return "rejected: " + c.Message
+		}
+		return "rejected"
+	}
+	if s.LastError != "" && !s.isTrue(ConditionReady) {
+		return s.LastError
+	}
+	return ""
+}
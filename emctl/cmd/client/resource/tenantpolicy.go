@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+// tenantPolicyLoadBalancePolicies is the load balance policies supported by the sidecar.
+var tenantPolicyLoadBalancePolicies = []string{
+	LoadBalanceRoundRobinPolicy, "random", "weightedRandom", "ipHash", "headerHash",
+}
+
+type (
+	// TenantPolicy describes the default policies of a tenant, the name of
+	// a TenantPolicy must be the same as its tenant.
+	//
+	// Services registered in the tenant inherit these policies unless they
+	// have their own, which means the precedence is:
+	//   service spec > tenant policy > mesh defaults
+	TenantPolicy struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *TenantPolicySpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// TenantPolicySpec describes the default policies of a tenant
+	TenantPolicySpec struct {
+		Resilience    *v1alpha1.Resilience    `yaml:"resilience,omitempty" json:"resilience,omitempty" jsonschema:"omitempty"`
+		LoadBalance   *v1alpha1.LoadBalance   `yaml:"loadBalance,omitempty" json:"loadBalance,omitempty" jsonschema:"omitempty"`
+		Observability *v1alpha1.Observability `yaml:"observability,omitempty" json:"observability,omitempty" jsonschema:"omitempty"`
+	}
+
+	// TenantPolicyObject is the TenantPolicy object stored in the control plane of the EaseMesh
+	TenantPolicyObject struct {
+		Name string `json:"name"`
+		*TenantPolicySpec
+	}
+)
+
+var _ meta.TableObject = &TenantPolicy{}
+
+// Columns returns the columns of TenantPolicy.
+func (t *TenantPolicy) Columns() []*meta.TableColumn {
+	if t.Spec == nil {
+		return nil
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Policies",
+			Value: strings.Join(t.Spec.PolicyNames(), ","),
+		},
+	}
+}
+
+// PolicyNames returns the names of the policies set in the spec.
+func (s *TenantPolicySpec) PolicyNames() []string {
+	names := []string{}
+	if s.Resilience != nil {
+		names = append(names, "resilience")
+	}
+	if s.LoadBalance != nil {
+		names = append(names, "loadBalance")
+	}
+	if s.Observability != nil {
+		names = append(names, "observability")
+	}
+	return names
+}
+
+// Validate validates the TenantPolicy before it's applied.
+func (t *TenantPolicy) Validate() error {
+	if t.Spec == nil || t.Spec.LoadBalance == nil {
+		return nil
+	}
+
+	policy := t.Spec.LoadBalance.Policy
+	for _, p := range tenantPolicyLoadBalancePolicies {
+		if p == policy {
+			return nil
+		}
+	}
+
+	return errors.Errorf("unsupported load balance policy %q (support %s)",
+		policy, strings.Join(tenantPolicyLoadBalancePolicies, ", "))
+}
+
+// Inherit fills the policies of the service which are absent with the ones of the tenant.
+// The service spec always takes precedence, it returns names of the inherited policies.
+func (s *TenantPolicySpec) Inherit(service *ServiceSpec) []string {
+	inherited := []string{}
+	if s == nil || service == nil {
+		return inherited
+	}
+
+	if service.Resilience == nil && s.Resilience != nil {
+		service.Resilience = s.Resilience
+		inherited = append(inherited, "resilience")
+	}
+	if service.LoadBalance == nil && s.LoadBalance != nil {
+		service.LoadBalance = s.LoadBalance
+		inherited = append(inherited, "loadBalance")
+	}
+	if service.Observability == nil && s.Observability != nil {
+		service.Observability = s.Observability
+		inherited = append(inherited, "observability")
+	}
+
+	return inherited
+}
+
+// ToObject converts a TenantPolicy resource to the object of the control plane
+func (t *TenantPolicy) ToObject() *TenantPolicyObject {
+	result := &TenantPolicyObject{
+		Name:             t.Name(),
+		TenantPolicySpec: &TenantPolicySpec{},
+	}
+	if t.Spec != nil {
+		result.TenantPolicySpec = t.Spec
+	}
+	return result
+}
+
+// ToTenantPolicy converts an object of the control plane to a TenantPolicy resource
+func ToTenantPolicy(object *TenantPolicyObject) *TenantPolicy {
+	result := &TenantPolicy{
+		Spec: object.TenantPolicySpec,
+	}
+	result.MeshResource = NewTenantPolicyResource(DefaultAPIVersion, object.Name)
+	return result
+}
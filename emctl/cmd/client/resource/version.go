@@ -0,0 +1,26 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+// ControlPlaneVersion is the version of the control plane with the API versions
+// of mesh resources it serves, it's read-only which could not be applied or deleted.
+type ControlPlaneVersion struct {
+	Version string `yaml:"version" json:"version"`
+	// APIVersions are API versions of mesh resources, e.g. mesh.megaease.com/v1alpha1.
+	APIVersions []string `yaml:"apiVersions,omitempty" json:"apiVersions,omitempty"`
+}
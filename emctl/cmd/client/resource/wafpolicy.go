@@ -0,0 +1,247 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// WAFModeBlock rejects requests matching rules.
+	WAFModeBlock = "block"
+	// WAFModeDetect only records hits of rules, requests are passed to backends.
+	WAFModeDetect = "detect"
+
+	// WAFTargetURI matches rules against the request URI with the query.
+	WAFTargetURI = "uri"
+	// WAFTargetQuery matches rules against the decoded query.
+	WAFTargetQuery = "query"
+	// WAFTargetHeader matches rules against the value of the header.
+	WAFTargetHeader = "header"
+	// WAFTargetBody matches rules against the request body.
+	WAFTargetBody = "body"
+
+	// DefaultWAFBlockStatusCode is the default status code of blocked requests.
+	DefaultWAFBlockStatusCode = http.StatusForbidden
+)
+
+// wafRuleSets are the subsets of the OWASP Core Rule Set built in the ingress controller.
+var wafRuleSets = map[string]bool{
+	"sqli":     true,
+	"xss":      true,
+	"rce":      true,
+	"lfi":      true,
+	"rfi":      true,
+	"php":      true,
+	"java":     true,
+	"scanner":  true,
+	"protocol": true,
+}
+
+type (
+	// WAFPolicy attaches web application firewall rules to paths of ingresses
+	WAFPolicy struct {
+		meta.MeshResource `yaml:",inline"`
+		Spec              *WAFPolicySpec `yaml:"spec" jsonschema:"required"`
+	}
+
+	// WAFPolicySpec describes the rules of the policy and the paths they protect
+	WAFPolicySpec struct {
+		Mode string `yaml:"mode" json:"mode" jsonschema:"required,enum=block,enum=detect"`
+		// RuleSets are subsets of the OWASP Core Rule Set like sqli and xss.
+		RuleSets []string `yaml:"ruleSets,omitempty" json:"ruleSets,omitempty" jsonschema:"omitempty"`
+		// Rules are custom rules besides the rule sets.
+		Rules []*WAFRule `yaml:"rules,omitempty" json:"rules,omitempty" jsonschema:"omitempty"`
+		// BlockStatusCode is the status code of blocked requests, 0 means 403.
+		BlockStatusCode int `yaml:"blockStatusCode,omitempty" json:"blockStatusCode,omitempty" jsonschema:"omitempty,minimum=400,maximum=599"`
+		// Targets are the paths of ingresses protected by the policy.
+		Targets []*WAFTarget `yaml:"targets" json:"targets" jsonschema:"required"`
+	}
+
+	// WAFRule is a custom rule matching a part of requests by a regular expression
+	WAFRule struct {
+		// ID identifies hits of the rule in metrics.
+		ID          string `yaml:"id" json:"id" jsonschema:"required"`
+		Description string `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"omitempty"`
+		Target      string `yaml:"target" json:"target" jsonschema:"required,enum=uri,enum=query,enum=header,enum=body"`
+		// Header is the name of the header matched by the header target.
+		Header string `yaml:"header,omitempty" json:"header,omitempty" jsonschema:"omitempty"`
+		// Pattern is a regular expression in the RE2 syntax.
+		Pattern string `yaml:"pattern" json:"pattern" jsonschema:"required"`
+	}
+
+	// WAFTarget is a path of an ingress, empty host and path mean all paths of the ingress
+	WAFTarget struct {
+		Ingress string `yaml:"ingress" json:"ingress" jsonschema:"required"`
+		Host    string `yaml:"host,omitempty" json:"host,omitempty" jsonschema:"omitempty"`
+		Path    string `yaml:"path,omitempty" json:"path,omitempty" jsonschema:"omitempty"`
+	}
+
+	// WAFPolicyObject is the WAFPolicy object stored in the control plane of the EaseMesh
+	WAFPolicyObject struct {
+		Name string `json:"name"`
+		*WAFPolicySpec
+	}
+)
+
+var _ meta.TableObject = &WAFPolicy{}
+
+// Columns returns the columns of WAFPolicy.
+func (w *WAFPolicy) Columns() []*meta.TableColumn {
+	if w.Spec == nil {
+		return nil
+	}
+
+	ruleSets := "-"
+	if len(w.Spec.RuleSets) != 0 {
+		ruleSets = strings.Join(w.Spec.RuleSets, ",")
+	}
+	ingresses := []string{}
+	for _, target := range w.Spec.Targets {
+		ingresses = append(ingresses, target.Ingress)
+	}
+
+	return []*meta.TableColumn{
+		{
+			Name:  "Mode",
+			Value: w.Spec.Mode,
+		},
+		{
+			Name:  "RuleSets",
+			Value: ruleSets,
+		},
+		{
+			Name:  "Rules",
+			Value: strconv.Itoa(len(w.Spec.Rules)),
+		},
+		{
+			Name:  "Ingresses",
+			Value: strings.Join(ingresses, ","),
+		},
+	}
+}
+
+// BlockStatusCodeOrDefault returns the status code of blocked requests, default is 403.
+func (s *WAFPolicySpec) BlockStatusCodeOrDefault() int {
+	if s.BlockStatusCode == 0 {
+		return DefaultWAFBlockStatusCode
+	}
+	return s.BlockStatusCode
+}
+
+// Validate validates the WAFPolicy before it's applied.
+func (w *WAFPolicy) Validate() error {
+	if w.Spec == nil {
+		return nil
+	}
+
+	switch w.Spec.Mode {
+	case WAFModeBlock, WAFModeDetect:
+	default:
+		return errors.Errorf("unsupported mode %q (support %s, %s)", w.Spec.Mode, WAFModeBlock, WAFModeDetect)
+	}
+	if w.Spec.BlockStatusCode != 0 && (w.Spec.BlockStatusCode < 400 || w.Spec.BlockStatusCode > 599) {
+		return errors.Errorf("blockStatusCode %d must be in [400, 599]", w.Spec.BlockStatusCode)
+	}
+
+	if len(w.Spec.RuleSets) == 0 && len(w.Spec.Rules) == 0 {
+		return errors.New("ruleSets or rules is required")
+	}
+	for _, ruleSet := range w.Spec.RuleSets {
+		if !wafRuleSets[ruleSet] {
+			ruleSets := []string{}
+			for name := range wafRuleSets {
+				ruleSets = append(ruleSets, name)
+			}
+			sort.Strings(ruleSets)
+			return errors.Errorf("unsupported rule set %q (support %s)", ruleSet, strings.Join(ruleSets, ", "))
+		}
+	}
+
+	ids := map[string]bool{}
+	for _, rule := range w.Spec.Rules {
+		if rule.ID == "" {
+			return errors.New("rules: id is required")
+		}
+		if ids[rule.ID] {
+			return errors.Errorf("rules: id %s is duplicated", rule.ID)
+		}
+		ids[rule.ID] = true
+
+		switch rule.Target {
+		case WAFTargetURI, WAFTargetQuery, WAFTargetBody:
+			if rule.Header != "" {
+				return errors.Errorf("rules: header of rule %s is only for the %s target", rule.ID, WAFTargetHeader)
+			}
+		case WAFTargetHeader:
+			if !isHeaderName(rule.Header) || rule.Header == "*" {
+				return errors.Errorf("rules: invalid header %q of rule %s", rule.Header, rule.ID)
+			}
+		default:
+			return errors.Errorf("rules: unsupported target %q of rule %s (support %s, %s, %s, %s)",
+				rule.Target, rule.ID, WAFTargetURI, WAFTargetQuery, WAFTargetHeader, WAFTargetBody)
+		}
+		if rule.Pattern == "" {
+			return errors.Errorf("rules: pattern of rule %s is required", rule.ID)
+		}
+		_, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "rules: invalid pattern of rule %s", rule.ID)
+		}
+	}
+
+	if len(w.Spec.Targets) == 0 {
+		return errors.New("targets is required")
+	}
+	for _, target := range w.Spec.Targets {
+		if target.Ingress == "" {
+			return errors.New("targets: ingress is required")
+		}
+	}
+
+	return nil
+}
+
+// ToObject converts a WAFPolicy resource to the object of the control plane
+func (w *WAFPolicy) ToObject() *WAFPolicyObject {
+	result := &WAFPolicyObject{
+		Name:          w.Name(),
+		WAFPolicySpec: &WAFPolicySpec{},
+	}
+	if w.Spec != nil {
+		result.WAFPolicySpec = w.Spec
+	}
+	return result
+}
+
+// ToWAFPolicy converts an object of the control plane to a WAFPolicy resource
+func ToWAFPolicy(object *WAFPolicyObject) *WAFPolicy {
+	result := &WAFPolicy{
+		Spec: object.WAFPolicySpec,
+	}
+	result.MeshResource = NewWAFPolicyResource(DefaultAPIVersion, object.Name)
+	return result
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	rbacv1 "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/rest"
+	restfake "k8s.io/client-go/rest/fake"
+)
+
+type (
+	// FakeClientset is the fake clientset whose REST clients of groups core,
+	// apps and rbac delete objects, which the ones of the fake clientset of
+	// client-go can't, so resources cleared by DeleteResources can be tested.
+	FakeClientset struct {
+		*fake.Clientset
+	}
+
+	fakeCoreV1 struct {
+		corev1.CoreV1Interface
+		restClient rest.Interface
+	}
+
+	fakeAppsV1 struct {
+		appsv1.AppsV1Interface
+		restClient rest.Interface
+	}
+
+	fakeRbacV1 struct {
+		rbacv1.RbacV1Interface
+		restClient rest.Interface
+	}
+)
+
+// NewFakeClientset returns the fake clientset holding the objects.
+func NewFakeClientset(objects ...runtime.Object) *FakeClientset {
+	return &FakeClientset{Clientset: fake.NewSimpleClientset(objects...)}
+}
+
+// CoreV1 retrieves the CoreV1Client.
+func (c *FakeClientset) CoreV1() corev1.CoreV1Interface {
+	return &fakeCoreV1{CoreV1Interface: c.Clientset.CoreV1(), restClient: c.restClient("", "v1")}
+}
+
+// AppsV1 retrieves the AppsV1Client.
+func (c *FakeClientset) AppsV1() appsv1.AppsV1Interface {
+	return &fakeAppsV1{AppsV1Interface: c.Clientset.AppsV1(), restClient: c.restClient("apps", "v1")}
+}
+
+// RbacV1 retrieves the RbacV1Client.
+func (c *FakeClientset) RbacV1() rbacv1.RbacV1Interface {
+	return &fakeRbacV1{RbacV1Interface: c.Clientset.RbacV1(), restClient: c.restClient("rbac.authorization.k8s.io", "v1")}
+}
+
+func (c *fakeCoreV1) RESTClient() rest.Interface { return c.restClient }
+
+func (c *fakeAppsV1) RESTClient() rest.Interface { return c.restClient }
+
+func (c *fakeRbacV1) RESTClient() rest.Interface { return c.restClient }
+
+// restClient returns the REST client deleting objects of the group from the tracker,
+// requests of other verbs are rejected.
+func (c *FakeClientset) restClient(group, version string) rest.Interface {
+	return &restfake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: group, Version: version},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodDelete {
+				return statusResponse(apierrors.NewMethodNotSupported(schema.GroupResource{Group: group}, req.Method))
+			}
+
+			// NOTE: Paths are /namespaces/{namespace}/{resource}/{name} or /{resource}/{name}.
+			segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+			namespace := ""
+			if len(segments) == 4 && segments[0] == "namespaces" {
+				namespace, segments = segments[1], segments[2:]
+			}
+			if len(segments) != 2 {
+				return statusResponse(apierrors.NewBadRequest("unexpected path " + req.URL.Path))
+			}
+
+			gvr := schema.GroupVersionResource{Group: group, Version: version, Resource: segments[0]}
+			err := c.Tracker().Delete(gvr, namespace, segments[1])
+			if apierrors.IsNotFound(err) && namespace != "" {
+				// NOTE: Cluster-scoped resources are deleted with the namespace as well.
+				err = c.Tracker().Delete(gvr, "", segments[1])
+			}
+			if err != nil {
+				return statusResponse(err)
+			}
+			return statusResponse(nil)
+		}),
+	}
+}
+
+func statusResponse(err error) (*http.Response, error) {
+	status := metav1.Status{Status: metav1.StatusSuccess, Code: http.StatusOK}
+	if statusErr, ok := err.(apierrors.APIStatus); ok {
+		status = statusErr.Status()
+	} else if err != nil {
+		status = apierrors.NewInternalError(err).Status()
+	}
+	status.Kind, status.APIVersion = "Status", "v1"
+
+	body, err := json.Marshal(&status)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: int(status.Code),
+		Header:     http.Header{"Content-Type": []string{runtime.ContentTypeJSON}},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
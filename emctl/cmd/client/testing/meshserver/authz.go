@@ -0,0 +1,282 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshserver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+const accessTokenKey = "accesstokens"
+
+const (
+	// accessTokenIDBytes and accessTokenSecretBytes are random bytes of the id and the secret,
+	// so ids are unguessable and never collide in practice, which is checked anyway.
+	accessTokenIDBytes     = 16
+	accessTokenSecretBytes = 32
+)
+
+// accessToken is an access token with its secret, which is never listed.
+type accessToken struct {
+	resource.AccessToken
+	secret string
+}
+
+// authorize authenticates the bearer token of the request and authorizes
+// writes by the tenant and the role of the token, which only this server
+// does, to test tokens sent by emctl. It reports whether the request is
+// allowed. Authorization is disabled until the first token is created.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, segments []string) bool {
+	if len(s.tokens) == 0 {
+		return true
+	}
+
+	token := s.authenticate(r)
+	if token == nil {
+		writeError(w, http.StatusUnauthorized, "missing, invalid or expired access token")
+		return false
+	}
+
+	switch {
+	case len(segments) >= 2 && segments[0] == "mesh" && segments[1] == accessTokenKey:
+		if token.Role != resource.TokenRoleAdmin {
+			writeError(w, http.StatusForbidden, "access token %s with role %s can't manage access tokens", token.ID, token.Role)
+			return false
+		}
+		return true
+	case r.Method == http.MethodGet:
+		return true
+	}
+
+	var body []byte
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "read body failed: %v", err)
+			return false
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	for _, tenant := range s.tenantsOf(segments, body) {
+		if !token.CanWrite(tenant) {
+			scope := "the whole mesh"
+			if tenant != "" {
+				scope = "tenant " + tenant
+			}
+			writeError(w, http.StatusForbidden, "access token %s of tenant %q with role %s can't write resources of %s",
+				token.ID, token.Tenant, token.Role, scope)
+			return false
+		}
+	}
+	return true
+}
+
+// authenticate returns the unexpired token of the request, or nil.
+func (s *Server) authenticate(r *http.Request) *accessToken {
+	value := strings.TrimPrefix(r.Header.Get(meshclient.AuthorizationHeader), "Bearer ")
+	fields := strings.SplitN(value, ".", 2)
+	if len(fields) != 2 {
+		return nil
+	}
+
+	token := s.tokens[fields[0]]
+	if token == nil || token.Expired(time.Now()) {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(token.secret), []byte(fields[1])) != 1 {
+		return nil
+	}
+	return token
+}
+
+// tenantsOf returns tenants of the resource written by the request, both
+// the old and the new ones for updates, empty tenants are mesh-wide.
+func (s *Server) tenantsOf(segments []string, body []byte) []string {
+	if len(segments) < 2 || segments[0] != "mesh" {
+		return []string{""}
+	}
+
+	key, rest := segments[1], segments[2:]
+	name := ""
+	switch {
+	case key == "revisions" && len(rest) >= 2:
+		key, name, body = s.revisionKeys[revisionKey(rest[0], rest[1])], rest[1], nil
+	case key == serviceKey && len(rest) == 2:
+		key, name, body = serviceKey+"/"+rest[1], rest[0], nil
+	case key == serviceInstanceKey && len(rest) == 2:
+		name = rest[0] + "/" + rest[1]
+	case len(rest) == 1:
+		name = rest[0]
+	}
+	if name == "" && body != nil {
+		name, _ = objectName(key, body)
+	}
+
+	tenants := []string{}
+	switch {
+	case key == "tenants" || key == "tenantpolicies":
+		tenants = append(tenants, name)
+	case key == serviceKey || strings.HasPrefix(key, serviceKey+"/"):
+		tenants = append(tenants, s.serviceTenant(name))
+		if body != nil {
+			service := struct {
+				RegisterTenant string `json:"registerTenant"`
+			}{}
+			_ = json.Unmarshal(body, &service)
+			tenants = append(tenants, service.RegisterTenant)
+		}
+	case key == serviceInstanceKey:
+		tenants = append(tenants, s.serviceTenant(strings.SplitN(name, "/", 2)[0]))
+	case key == "servicecanaries":
+		raws := [][]byte{body}
+		if raw, ok := s.store.get(key, name); ok {
+			raws = append(raws, raw)
+		}
+		for _, raw := range raws {
+			canary := struct {
+				Selector struct {
+					MatchServices []string `json:"matchServices"`
+				} `json:"selector"`
+			}{}
+			_ = json.Unmarshal(raw, &canary)
+			for _, service := range canary.Selector.MatchServices {
+				tenants = append(tenants, s.serviceTenant(service))
+			}
+		}
+	}
+
+	// NOTE: Resources without tenants, e.g. services being created, are mesh-wide.
+	result := []string{}
+	for _, tenant := range tenants {
+		if tenant != "" {
+			result = append(result, tenant)
+		}
+	}
+	if len(result) == 0 {
+		return []string{""}
+	}
+	return result
+}
+
+// serviceTenant returns the tenant of the stored service, or empty.
+func (s *Server) serviceTenant(name string) string {
+	service, ok, _ := s.store.service(name)
+	if !ok {
+		return ""
+	}
+	return service.RegisterTenant
+}
+
+func (s *Server) serveAccessTokens(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		request := &resource.AccessTokenRequest{}
+		err := json.NewDecoder(r.Body).Decode(request)
+		if err == nil {
+			err = request.Validate()
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid access token request: %v", err)
+			return
+		}
+
+		if len(s.tokens) == 0 {
+			if request.Tenant != "" || request.Role != resource.TokenRoleAdmin {
+				writeError(w, http.StatusBadRequest, "the first access token must be an admin token of the whole mesh")
+				return
+			}
+		} else if creator := s.authenticate(r); !creator.CanGrant(request) {
+			writeError(w, http.StatusForbidden, "access token %s of tenant %q can't create tokens of tenant %q",
+				creator.ID, creator.Tenant, request.Tenant)
+			return
+		}
+
+		token, err := newAccessToken(request, r.Header.Get(meshclient.AuditIdentityHeader))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		if _, exists := s.tokens[token.ID]; exists {
+			writeError(w, http.StatusInternalServerError, "access token id %s collided, create it again", token.ID)
+			return
+		}
+		s.tokens[token.ID] = token
+		s.audit(r, "create", "AccessToken", token.ID)
+
+		result := token.AccessToken
+		result.Token = token.ID + "." + token.secret
+		writeJSON(w, http.StatusCreated, result)
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		token := s.tokens[rest[0]]
+		if token == nil {
+			writeError(w, http.StatusNotFound, "access token %s not found", rest[0])
+			return
+		}
+		revoker := s.authenticate(r)
+		if !revoker.CanGrant(&resource.AccessTokenRequest{Tenant: token.Tenant, Role: token.Role}) {
+			writeError(w, http.StatusForbidden, "access token %s of tenant %q can't revoke tokens of tenant %q",
+				revoker.ID, revoker.Tenant, token.Tenant)
+			return
+		}
+		delete(s.tokens, rest[0])
+		s.audit(r, "delete", "AccessToken", rest[0])
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+}
+
+func newAccessToken(request *resource.AccessTokenRequest, creator string) (*accessToken, error) {
+	id, secret := make([]byte, accessTokenIDBytes), make([]byte, accessTokenSecretBytes)
+	for _, buff := range [][]byte{id, secret} {
+		if _, err := rand.Read(buff); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	token := &accessToken{
+		AccessToken: resource.AccessToken{
+			ID:          hex.EncodeToString(id),
+			Tenant:      request.Tenant,
+			Role:        request.Role,
+			Description: request.Description,
+			CreatedBy:   creator,
+			CreatedAt:   now.Format(time.RFC3339),
+		},
+		secret: hex.EncodeToString(secret),
+	}
+	if request.TTL != "" {
+		// NOTE: The TTL has been validated.
+		ttl, _ := time.ParseDuration(request.TTL)
+		token.ExpiresAt = now.Add(ttl).Format(time.RFC3339)
+	}
+	return token, nil
+}
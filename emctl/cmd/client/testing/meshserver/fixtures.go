@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshserver
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+
+	"github.com/pkg/errors"
+)
+
+// KindCustomResource stands for the custom resource in fixtures,
+// the fixture is a ShadowService defined by the CustomResourceKind fixture.
+const KindCustomResource = "CustomResource"
+
+//go:embed fixtures/*.yaml
+var fixtures embed.FS
+
+// fixtureKinds lists kinds of fixtures in the order of loading,
+// the resources which others depend on come first.
+var fixtureKinds = []string{
+	resource.KindMeshController,
+	resource.KindTenant,
+	resource.KindTenantPolicy,
+	resource.KindService,
+	resource.KindServiceInstance,
+	resource.KindCanary,
+	resource.KindLoadBalance,
+	resource.KindResilience,
+	resource.KindMock,
+	resource.KindObservabilityOutputServer,
+	resource.KindObservabilityTracings,
+	resource.KindObservabilityMetrics,
+	resource.KindIngress,
+	resource.KindHTTPRouteGroup,
+	resource.KindTrafficTarget,
+	resource.KindServiceCanary,
+	resource.KindExternalService,
+	resource.KindSLO,
+	resource.KindAlertRule,
+	resource.KindMessagingPolicy,
+	resource.KindIngressPort,
+	resource.KindWAFPolicy,
+	resource.KindMaintenanceMode,
+	resource.KindPolicyRollout,
+	resource.KindCustomResourceKind,
+	KindCustomResource,
+}
+
+// FixtureKinds returns kinds which have fixtures, in the order of loading.
+func FixtureKinds() []string {
+	return append([]string{}, fixtureKinds...)
+}
+
+// Fixture returns the fixture of the kind in the YAML accepted by emctl apply.
+func Fixture(kind string) ([]byte, error) {
+	buff, err := fixtures.ReadFile(path.Join("fixtures", strings.ToLower(kind)+".yaml"))
+	if err != nil {
+		return nil, errors.Errorf("no fixture for kind %s", kind)
+	}
+	return buff, nil
+}
+
+// LoadFixtures stores fixtures of all kinds into the server.
+func (s *Server) LoadFixtures() error {
+	for _, kind := range fixtureKinds {
+		err := s.LoadFixture(kind)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFixture stores the fixture of the kind into the server,
+// the fixtures it depends on should be loaded before.
+func (s *Server) LoadFixture(kind string) error {
+	buff, err := Fixture(kind)
+	if err != nil {
+		return err
+	}
+
+	client := s.Client()
+	return util.NewReaderVisitor(bytes.NewReader(buff), kind).Visit(func(object meta.MeshObject, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// NOTE: Service instances are registered by sidecars instead of being applied.
+		if instance, ok := object.(*resource.ServiceInstance); ok {
+			return s.registerServiceInstance(instance)
+		}
+		return apply.WrapApplierByMeshObject(object, client, 10*time.Second).Apply()
+	})
+}
+
+func (s *Server) registerServiceInstance(instance *resource.ServiceInstance) error {
+	serviceName, instanceID, err := instance.ParseName()
+	if err != nil {
+		return err
+	}
+
+	instance.Spec.ServiceName, instance.Spec.InstanceID = serviceName, instanceID
+	raw, err := json.Marshal(instance.Spec)
+	if err != nil {
+		return errors.Wrapf(err, "marshal service instance %s failed", instance.Name())
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.store.put(serviceInstanceKey, serviceName+"/"+instanceID, raw)
+	return nil
+}
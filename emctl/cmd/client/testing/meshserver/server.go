@@ -0,0 +1,584 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package meshserver provides an in-memory implementation of the admin API
+// of the EaseMesh control plane, which emctl talks to. It lets tests run
+// apply, get and delete flows without a Kubernetes cluster.
+package meshserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"sigs.k8s.io/yaml"
+)
+
+const apiPrefix = "/apis/v1"
+
+// Server is an in-memory mesh API server, every write to it is recorded
+// as a revision and an audit record just like the control plane does.
+type Server struct {
+	*httptest.Server
+
+	mutex     sync.Mutex
+	store     *store
+	revisions map[string][]*resource.RevisionObject
+	// revisionKeys maps a revised resource to its collection for rollback.
+	revisionKeys map[string]string
+	audits       []*resource.AuditRecordObject
+	// tokens are access tokens by their ids.
+	tokens map[string]*accessToken
+	// unsupported are APIs of EaseMesh extensions not served, see Unsupport.
+	unsupported map[string]bool
+}
+
+// New creates and starts a Server, callers should Close it after use.
+func New() *Server {
+	s := &Server{
+		store:        newStore(),
+		revisions:    map[string][]*resource.RevisionObject{},
+		revisionKeys: map[string]string{},
+		tokens:       map[string]*accessToken{},
+		unsupported:  map[string]bool{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Address returns the address for the --server flag of emctl.
+func (s *Server) Address() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// Client returns a mesh client talking to the server.
+func (s *Server) Client() meshclient.MeshClient {
+	return meshclient.New(s.Address())
+}
+
+// Unsupport stops serving the APIs of EaseMesh extensions like an older
+// control plane, no APIs means all of them like Easegress.
+func (s *Server) Unsupport(apis ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(apis) == 0 {
+		apis = meshclient.Features()
+	}
+	for _, api := range apis {
+		s.unsupported[api] = true
+	}
+}
+
+// Reset removes all objects, revisions, audit records and access tokens.
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.store = newStore()
+	s.revisions = map[string][]*resource.RevisionObject{}
+	s.revisionKeys = map[string]string{}
+	s.audits = nil
+	s.tokens = map[string]*accessToken{}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments, ok := pathSegments(r.URL.EscapedPath())
+	if !ok {
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.authorize(w, r, segments) {
+		return
+	}
+
+	switch {
+	case len(segments) >= 1 && segments[0] == meshControllerKey:
+		s.serveCollection(w, r, meshControllerKey, segments[1:])
+	case len(segments) >= 2 && segments[0] == "mesh":
+		key, rest := segments[1], segments[2:]
+		switch {
+		case s.unsupported[key]:
+			writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		case key == "features" && len(rest) == 0:
+			s.serveFeatures(w, r)
+		case key == "audits" && len(rest) == 0:
+			s.serveAudits(w, r)
+		case key == accessTokenKey:
+			s.serveAccessTokens(w, r, rest)
+		case key == "revisions":
+			s.serveRevisions(w, r, rest)
+		case key == customResourceKey:
+			s.serveCustomResources(w, r, rest)
+		case key == serviceKey && len(rest) == 2:
+			s.serveSubResource(w, r, rest[0], rest[1])
+		case key == serviceInstanceKey && len(rest) == 2:
+			s.serveCollection(w, r, key, []string{rest[0] + "/" + rest[1]})
+		case collectionKinds[key] != "":
+			s.serveCollection(w, r, key, rest)
+		default:
+			writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+	}
+}
+
+// serveFeatures lists the served APIs of EaseMesh extensions,
+// the path isn't served once all of them are unsupported.
+func (s *Server) serveFeatures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+
+	features := []string{}
+	for _, api := range meshclient.Features() {
+		if !s.unsupported[api] {
+			features = append(features, api)
+		}
+	}
+	if len(features) == 0 {
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		return
+	}
+	writeJSON(w, http.StatusOK, features)
+}
+
+// pathSegments splits the escaped path under the API prefix,
+// names escaped by the client such as a/b stay in one segment.
+func pathSegments(escapedPath string) ([]string, bool) {
+	if !strings.HasPrefix(escapedPath, apiPrefix+"/") {
+		return nil, false
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(escapedPath, apiPrefix), "/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+		segments[i] = unescaped
+	}
+	return segments, true
+}
+
+func (s *Server) serveCollection(w http.ResponseWriter, r *http.Request, key string, rest []string) {
+	kind := collectionKinds[key]
+	if len(rest) > 1 {
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		return
+	}
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, s.store.list(key))
+		case http.MethodPost, http.MethodPut:
+			raw, ok := readBody(w, r, key)
+			if !ok {
+				return
+			}
+			name, err := objectName(key, raw)
+			if err != nil || name == "" {
+				writeError(w, http.StatusBadRequest, "no name in %s", kind)
+				return
+			}
+			s.write(w, r, key, kind, name, raw)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		}
+		return
+	}
+
+	name := rest[0]
+	switch r.Method {
+	case http.MethodGet:
+		raw, ok := s.store.get(key, name)
+		if !ok {
+			writeError(w, http.StatusNotFound, "%s %s not found", kind, name)
+			return
+		}
+		writeRaw(w, http.StatusOK, raw)
+	case http.MethodPut:
+		raw, ok := readBody(w, r, key)
+		if !ok {
+			return
+		}
+		s.write(w, r, key, kind, name, raw)
+	case http.MethodDelete:
+		if !s.store.remove(key, name) {
+			writeError(w, http.StatusNotFound, "%s %s not found", kind, name)
+			return
+		}
+		s.audit(r, "delete", kind, name)
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+}
+
+// write creates the object for POST and updates it for PUT.
+func (s *Server) write(w http.ResponseWriter, r *http.Request, key, kind, name string, raw []byte) {
+	_, exists := s.store.get(key, name)
+	switch {
+	case r.Method == http.MethodPost && exists:
+		writeError(w, http.StatusConflict, "%s %s existed", kind, name)
+		return
+	case r.Method == http.MethodPut && !exists:
+		writeError(w, http.StatusNotFound, "%s %s not found", kind, name)
+		return
+	case r.Method == http.MethodPut && s.stale(w, r, kind, name):
+		return
+	}
+
+	s.store.put(key, name, raw)
+	s.revise(r, key, kind, name, raw, "")
+	if r.Method == http.MethodPost {
+		s.audit(r, "create", kind, name)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	s.audit(r, "update", kind, name)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) serveSubResource(w http.ResponseWriter, r *http.Request, serviceName, subName string) {
+	sub, ok := subResources[subName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		return
+	}
+
+	_, serviceExists := s.store.get(serviceKey, serviceName)
+	raw, exists, err := s.store.getSubResource(serviceName, sub)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			writeError(w, http.StatusNotFound, "%s %s not found", sub.kind, serviceName)
+			return
+		}
+		writeRaw(w, http.StatusOK, raw)
+	case http.MethodPost, http.MethodPut:
+		switch {
+		case !serviceExists:
+			writeError(w, http.StatusNotFound, "service %s not found", serviceName)
+			return
+		case r.Method == http.MethodPost && exists:
+			writeError(w, http.StatusConflict, "%s %s existed", sub.kind, serviceName)
+			return
+		case r.Method == http.MethodPut && !exists:
+			writeError(w, http.StatusNotFound, "%s %s not found", sub.kind, serviceName)
+			return
+		case r.Method == http.MethodPut && s.stale(w, r, sub.kind, serviceName):
+			return
+		}
+
+		body, ok := readBody(w, r, serviceKey)
+		if !ok {
+			return
+		}
+		err = s.store.putSubResource(serviceName, sub, body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "%v", err)
+			return
+		}
+		s.revise(r, serviceKey+"/"+subName, sub.kind, serviceName, body, "")
+
+		status, operation := http.StatusOK, "update"
+		if r.Method == http.MethodPost {
+			status, operation = http.StatusCreated, "create"
+		}
+		s.audit(r, operation, sub.kind, serviceName)
+		w.WriteHeader(status)
+	case http.MethodDelete:
+		if !exists {
+			writeError(w, http.StatusNotFound, "%s %s not found", sub.kind, serviceName)
+			return
+		}
+		err = s.store.putSubResource(serviceName, sub, nil)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		s.audit(r, "delete", sub.kind, serviceName)
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+}
+
+// serveCustomResources serves custom resources, which are stored in
+// collections per kind but created and updated through one path.
+func (s *Server) serveCustomResources(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch len(rest) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			writeList(w, s.store.list(customResourceKey))
+		case http.MethodPost, http.MethodPut:
+			raw, ok := readBody(w, r, customResourceKey)
+			if !ok {
+				return
+			}
+			object := struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			}{}
+			if json.Unmarshal(raw, &object) != nil || object.Kind == "" || object.Name == "" {
+				writeError(w, http.StatusBadRequest, "no kind or name in custom resource")
+				return
+			}
+			s.write(w, r, customResourceKey+"/"+object.Kind, object.Kind, object.Name, raw)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		}
+	case 1:
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+			return
+		}
+		writeList(w, s.store.list(customResourceKey+"/"+rest[0]))
+	case 2:
+		key, kind, name := customResourceKey+"/"+rest[0], rest[0], rest[1]
+		switch r.Method {
+		case http.MethodGet:
+			raw, ok := s.store.get(key, name)
+			if !ok {
+				writeError(w, http.StatusNotFound, "%s %s not found", kind, name)
+				return
+			}
+			writeRaw(w, http.StatusOK, raw)
+		case http.MethodDelete:
+			if !s.store.remove(key, name) {
+				writeError(w, http.StatusNotFound, "%s %s not found", kind, name)
+				return
+			}
+			s.audit(r, "delete", kind, name)
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+	}
+}
+
+func (s *Server) serveRevisions(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) < 2 || len(rest) > 4 || (len(rest) == 4 && rest[3] != "rollback") {
+		writeError(w, http.StatusNotFound, "path %s not found", r.URL.Path)
+		return
+	}
+
+	kind, name := rest[0], rest[1]
+	revisions := s.revisions[revisionKey(kind, name)]
+	if len(rest) == 2 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+			return
+		}
+		if len(revisions) == 0 {
+			writeError(w, http.StatusNotFound, "%s %s not found", kind, name)
+			return
+		}
+		writeJSON(w, http.StatusOK, revisions)
+		return
+	}
+
+	number, err := strconv.ParseInt(rest[2], 10, 64)
+	if err != nil || number < 1 || number > int64(len(revisions)) {
+		writeError(w, http.StatusNotFound, "revision %s of %s %s not found", rest[2], kind, name)
+		return
+	}
+	revision := revisions[number-1]
+
+	if len(rest) == 3 {
+		writeJSON(w, http.StatusOK, revision)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+
+	raw, err := json.Marshal(revision.Content)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	key := s.revisionKeys[revisionKey(kind, name)]
+	if strings.HasPrefix(key, serviceKey+"/") {
+		err = s.store.putSubResource(name, subResources[strings.TrimPrefix(key, serviceKey+"/")], raw)
+		if err != nil {
+			writeError(w, http.StatusConflict, "%v", err)
+			return
+		}
+	} else {
+		s.store.put(key, name, raw)
+	}
+	s.revise(r, key, kind, name, raw, fmt.Sprintf("rollback to revision %d", number))
+	s.audit(r, "rollback", kind, name)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) serveAudits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+
+	query := r.URL.Query()
+	var since time.Time
+	if query.Get("since") != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, query.Get("since"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: %v", err)
+			return
+		}
+	}
+
+	result := []*resource.AuditRecordObject{}
+	for _, record := range s.audits {
+		timestamp, _ := time.Parse(time.RFC3339, record.Timestamp)
+		switch {
+		case !since.IsZero() && timestamp.Before(since):
+		case query.Get("kind") != "" && !strings.EqualFold(query.Get("kind"), record.Kind):
+		case query.Get("name") != "" && query.Get("name") != record.Name:
+		case query.Get("user") != "" && query.Get("user") != record.User:
+		default:
+			result = append(result, record)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) revise(r *http.Request, key, kind, name string, raw []byte, changeCause string) {
+	content := map[string]interface{}{}
+	// NOTE: The content is informative, keep the revision even if it's not an object.
+	_ = json.Unmarshal(raw, &content)
+
+	id := revisionKey(kind, name)
+	s.revisionKeys[id] = key
+	s.revisions[id] = append(s.revisions[id], &resource.RevisionObject{
+		Kind:        kind,
+		Name:        name,
+		Revision:    int64(len(s.revisions[id]) + 1),
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+		Operator:    r.Header.Get(meshclient.AuditIdentityHeader),
+		ChangeCause: changeCause,
+		Content:     content,
+	})
+}
+
+func (s *Server) audit(r *http.Request, operation, kind, name string) {
+	s.audits = append(s.audits, &resource.AuditRecordObject{
+		ID:        strconv.Itoa(len(s.audits) + 1),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		User:      r.Header.Get(meshclient.AuditIdentityHeader),
+		Operation: operation,
+		Kind:      kind,
+		Name:      name,
+	})
+}
+
+// stale rejects the update with 409 if the resource version in the request
+// is not the latest revision of the resource, it reports whether rejected.
+func (s *Server) stale(w http.ResponseWriter, r *http.Request, kind, name string) bool {
+	version := r.Header.Get(meshclient.ResourceVersionHeader)
+	if version == "" {
+		return false
+	}
+
+	latest := strconv.Itoa(len(s.revisions[revisionKey(kind, name)]))
+	if version == latest {
+		return false
+	}
+	writeError(w, http.StatusConflict, "%s %s has been modified, resource version %s is stale (latest %s)",
+		kind, name, version, latest)
+	return true
+}
+
+func revisionKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// readBody reads the request body, the mesh controller is sent in YAML.
+func readBody(w http.ResponseWriter, r *http.Request, key string) ([]byte, bool) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil && key == meshControllerKey {
+		body, err = yaml.YAMLToJSON(body)
+	}
+	if err != nil || !json.Valid(body) {
+		writeError(w, http.StatusBadRequest, "invalid body: %s", body)
+		return nil, false
+	}
+	return body, true
+}
+
+func writeList(w http.ResponseWriter, items [][]byte) {
+	buff := []byte("[")
+	for i, item := range items {
+		if i != 0 {
+			buff = append(buff, ',')
+		}
+		buff = append(buff, item...)
+	}
+	writeRaw(w, http.StatusOK, append(buff, ']'))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeRaw(w, status, buff)
+}
+
+func writeRaw(w http.ResponseWriter, status int, raw []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(raw)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, format, args...)
+}
@@ -0,0 +1,315 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/apply"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+	commonclient "github.com/megaease/easemeshctl/cmd/common/client"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	err := server.LoadFixtures()
+	if err != nil {
+		t.Fatalf("load fixtures failed: %v", err)
+	}
+
+	ctx := context.Background()
+	client := server.Client().V1Alpha1()
+
+	if _, err := client.MeshController().Get(ctx, "easemesh-controller"); err != nil {
+		t.Fatalf("get mesh controller failed: %v", err)
+	}
+	if _, err := client.Tenant().Get(ctx, "pet"); err != nil {
+		t.Fatalf("get tenant failed: %v", err)
+	}
+	if _, err := client.Service().Get(ctx, "vets-service"); err != nil {
+		t.Fatalf("get service failed: %v", err)
+	}
+	if _, err := client.LoadBalance().Get(ctx, "vets-service"); err != nil {
+		t.Fatalf("get load balance failed: %v", err)
+	}
+	if _, err := client.ObservabilityMetrics().Get(ctx, "vets-service"); err != nil {
+		t.Fatalf("get observability metrics failed: %v", err)
+	}
+	if _, err := client.ServiceInstance().Get(ctx, "vets-service", "vets-service-0"); err != nil {
+		t.Fatalf("get service instance failed: %v", err)
+	}
+	if _, err := client.CustomResource().Get(ctx, "ShadowService", "vets-shadow"); err != nil {
+		t.Fatalf("get custom resource failed: %v", err)
+	}
+
+	canaries, err := client.Canary().List(ctx)
+	if err != nil || len(canaries) != 1 {
+		t.Fatalf("expected 1 canary, got %d: %v", len(canaries), err)
+	}
+
+	for _, kind := range FixtureKinds() {
+		if _, err := Fixture(kind); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+}
+
+func TestCreateGetPatchDelete(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client().V1Alpha1()
+	tenant := resource.ToTenant(&v1alpha1.Tenant{Name: "pet", Description: "v1"})
+
+	if err := client.Tenant().Create(ctx, tenant); err != nil {
+		t.Fatalf("create tenant failed: %v", err)
+	}
+	if err := client.Tenant().Create(ctx, tenant); !meshclient.IsConflictError(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+
+	tenant.Spec.Description = "v2"
+	if err := client.Tenant().Patch(ctx, tenant); err != nil {
+		t.Fatalf("patch tenant failed: %v", err)
+	}
+	got, err := client.Tenant().Get(ctx, "pet")
+	if err != nil || got.Spec.Description != "v2" {
+		t.Fatalf("expected patched tenant, got %+v: %v", got, err)
+	}
+
+	if err := client.Tenant().Delete(ctx, "pet"); err != nil {
+		t.Fatalf("delete tenant failed: %v", err)
+	}
+	if _, err := client.Tenant().Get(ctx, "pet"); !meshclient.IsNotFoundError(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if err := client.Tenant().Delete(ctx, "pet"); !meshclient.IsNotFoundError(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestSubResourceOfMissingService(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	err := server.LoadFixture(resource.KindLoadBalance)
+	if err == nil {
+		t.Fatalf("load balance of a missing service should fail")
+	}
+}
+
+func TestRevisionsAndAudits(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client().V1Alpha1()
+	tenant := resource.ToTenant(&v1alpha1.Tenant{Name: "pet", Description: "v1"})
+	if err := client.Tenant().Create(ctx, tenant); err != nil {
+		t.Fatalf("create tenant failed: %v", err)
+	}
+	tenant.Spec.Description = "v2"
+	if err := client.Tenant().Patch(ctx, tenant); err != nil {
+		t.Fatalf("patch tenant failed: %v", err)
+	}
+
+	if err := client.Revision().Rollback(ctx, resource.KindTenant, "pet", 1); err != nil {
+		t.Fatalf("rollback tenant failed: %v", err)
+	}
+	got, err := client.Tenant().Get(ctx, "pet")
+	if err != nil || got.Spec.Description != "v1" {
+		t.Fatalf("expected rolled back tenant, got %+v: %v", got, err)
+	}
+
+	revisions, err := client.Revision().List(ctx, resource.KindTenant, "pet")
+	if err != nil || len(revisions) != 3 {
+		t.Fatalf("expected 3 revisions, got %d: %v", len(revisions), err)
+	}
+
+	audits, err := client.Audit().List(ctx, &meshclient.AuditListOptions{Kind: resource.KindTenant})
+	if err != nil || len(audits) != 3 {
+		t.Fatalf("expected 3 audit records, got %d: %v", len(audits), err)
+	}
+	if audits[2].Spec.Operation != "rollback" {
+		t.Fatalf("expected rollback operation, got %s", audits[2].Spec.Operation)
+	}
+
+	server.Reset()
+	if _, err := client.Tenant().Get(ctx, "pet"); !meshclient.IsNotFoundError(err) {
+		t.Fatalf("expected not found error after reset, got %v", err)
+	}
+}
+
+func TestResourceVersion(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client()
+	tenant := resource.ToTenant(&v1alpha1.Tenant{Name: "pet", Description: "v1"})
+	if err := client.V1Alpha1().Tenant().Create(ctx, tenant); err != nil {
+		t.Fatalf("create tenant failed: %v", err)
+	}
+
+	version, err := meshclient.ResourceVersion(ctx, client, resource.KindTenant, "pet")
+	if err != nil || version != "1" {
+		t.Fatalf("expected resource version 1, got %q: %v", version, err)
+	}
+
+	tenant.Spec.Description = "v2"
+	if err := client.V1Alpha1().Tenant().Patch(meshclient.WithResourceVersion(ctx, version), tenant); err != nil {
+		t.Fatalf("patch tenant at the latest version failed: %v", err)
+	}
+
+	tenant.Spec.Description = "v3"
+	err = client.V1Alpha1().Tenant().Patch(meshclient.WithResourceVersion(ctx, version), tenant)
+	if !meshclient.IsStaleError(err) {
+		t.Fatalf("expected stale error, got %v", err)
+	}
+
+	if err := client.V1Alpha1().Tenant().Patch(ctx, tenant); err != nil {
+		t.Fatalf("patch tenant without resource version failed: %v", err)
+	}
+}
+
+func TestUnsupportedAPIs(t *testing.T) {
+	server := New()
+	defer server.Close()
+	server.Unsupport(meshclient.APITenantPolicies)
+
+	ctx := context.Background()
+	client := server.Client()
+	_, err := client.V1Alpha1().TenantPolicy().List(ctx)
+	if !meshclient.IsUnsupportedError(err) || common.ExitCode(err) != common.ExitCodeUnsupported {
+		t.Fatalf("expected unsupported error of tenant policies, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "tenantpolicies API of "+server.Address()) {
+		t.Fatalf("expected the API and the server in the error, got %v", err)
+	}
+	if _, err := client.V1Alpha1().SLO().List(ctx); err != nil {
+		t.Fatalf("list SLOs failed: %v", err)
+	}
+
+	// NOTE: Easegress serves neither the features API nor any extensions.
+	easegress := New()
+	defer easegress.Close()
+	easegress.Unsupport()
+
+	client = easegress.Client()
+	tenant := resource.ToTenant(&v1alpha1.Tenant{Name: "pet"})
+	if err := apply.WrapApplierByMeshObject(tenant, client, time.Second).Apply(); err != nil {
+		t.Fatalf("apply tenant without labels failed: %v", err)
+	}
+	tenant.SetLabels(map[string]string{"team": "pet"})
+	err = apply.WrapApplierByMeshObject(tenant, client, time.Second).Apply()
+	if !meshclient.IsUnsupportedError(err) {
+		t.Fatalf("expected unsupported error keeping labels, got %v", err)
+	}
+	_, err = client.V1Alpha1().Version().Get(ctx)
+	if !meshclient.IsUnsupportedError(err) {
+		t.Errorf("expected unsupported error of version, got %v", err)
+	}
+	_, err = client.V1Alpha1().Audit().List(ctx, &meshclient.AuditListOptions{})
+	if !meshclient.IsUnsupportedError(err) {
+		t.Errorf("expected unsupported error of audits, got %v", err)
+	}
+	_, err = client.V1Alpha1().Status().Get(ctx, resource.KindTenant, "pet")
+	if !meshclient.IsUnsupportedError(err) {
+		t.Errorf("expected unsupported error of statuses, got %v", err)
+	}
+
+	version, err := meshclient.ResourceVersion(ctx, client, resource.KindTenant, "pet")
+	if err != nil || version != "" {
+		t.Fatalf("expected no resource version, got %q: %v", version, err)
+	}
+}
+
+func TestAccessTokens(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	if err := server.LoadFixtures(); err != nil {
+		t.Fatalf("load fixtures failed: %v", err)
+	}
+
+	client := server.Client().V1Alpha1()
+	withToken := func(token string) context.Context {
+		return commonclient.WithHeader(context.Background(), meshclient.AuthorizationHeader, "Bearer "+token)
+	}
+
+	_, err := client.AccessToken().Create(context.Background(), &resource.AccessTokenRequest{Tenant: "pet", Role: resource.TokenRoleEditor})
+	if err == nil {
+		t.Fatalf("the first token should be a mesh-wide admin token")
+	}
+	admin, err := client.AccessToken().Create(context.Background(), &resource.AccessTokenRequest{Role: resource.TokenRoleAdmin})
+	if err != nil {
+		t.Fatalf("create admin token failed: %v", err)
+	}
+	if len(admin.ID) != 2*accessTokenIDBytes {
+		t.Fatalf("token id %s should be hex of %d bytes", admin.ID, accessTokenIDBytes)
+	}
+
+	if _, err := client.Tenant().Get(context.Background(), "pet"); common.ExitCode(err) != common.ExitCodeForbidden {
+		t.Fatalf("expected unauthenticated error without token, got %v", err)
+	}
+	if _, err := client.Tenant().Get(withToken(admin.ID+".wrong"), "pet"); common.ExitCode(err) != common.ExitCodeForbidden {
+		t.Fatalf("expected unauthenticated error with wrong secret, got %v", err)
+	}
+
+	editor, err := client.AccessToken().Create(withToken(admin.Token), &resource.AccessTokenRequest{Tenant: "pet", Role: resource.TokenRoleEditor})
+	if err != nil {
+		t.Fatalf("create editor token failed: %v", err)
+	}
+
+	ctx := withToken(editor.Token)
+	loadBalance, err := client.LoadBalance().Get(ctx, "vets-service")
+	if err != nil {
+		t.Fatalf("get load balance failed: %v", err)
+	}
+	if err := client.LoadBalance().Patch(ctx, loadBalance); err != nil {
+		t.Fatalf("editor should patch load balance of its tenant: %v", err)
+	}
+
+	forbidden := []error{
+		client.Tenant().Create(ctx, resource.ToTenant(&v1alpha1.Tenant{Name: "shop"})),
+		client.Ingress().Delete(ctx, "pet-ingress"),
+	}
+	_, err = client.AccessToken().Create(ctx, &resource.AccessTokenRequest{Tenant: "pet", Role: resource.TokenRoleViewer})
+	forbidden = append(forbidden, err)
+	for _, err := range forbidden {
+		if common.ExitCode(err) != common.ExitCodeForbidden {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+	}
+
+	if err := client.AccessToken().Delete(withToken(admin.Token), editor.ID); err != nil {
+		t.Fatalf("revoke editor token failed: %v", err)
+	}
+	if _, err := client.LoadBalance().Get(ctx, "vets-service"); common.ExitCode(err) != common.ExitCodeForbidden {
+		t.Fatalf("expected unauthenticated error with revoked token, got %v", err)
+	}
+}
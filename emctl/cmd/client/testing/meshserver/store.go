@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshserver
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	meshControllerKey  = "objects"
+	serviceKey         = "services"
+	serviceInstanceKey = "serviceinstances"
+	customResourceKey  = "customresources"
+)
+
+// collectionKinds maps collections of the admin API to kinds of mesh resources.
+var collectionKinds = map[string]string{
+	meshControllerKey:     resource.KindMeshController,
+	"tenants":             resource.KindTenant,
+	"tenantpolicies":      resource.KindTenantPolicy,
+	serviceKey:            resource.KindService,
+	serviceInstanceKey:    resource.KindServiceInstance,
+	"ingresses":           resource.KindIngress,
+	"httproutegroups":     resource.KindHTTPRouteGroup,
+	"traffictargets":      resource.KindTrafficTarget,
+	"servicecanaries":     resource.KindServiceCanary,
+	"externalservices":    resource.KindExternalService,
+	"slos":                resource.KindSLO,
+	"alertrules":          resource.KindAlertRule,
+	"messagingpolicies":   resource.KindMessagingPolicy,
+	"ingressports":        resource.KindIngressPort,
+	"wafpolicies":         resource.KindWAFPolicy,
+	"policyrollouts":      resource.KindPolicyRollout,
+	"maintenancemodes":    resource.KindMaintenanceMode,
+	"customresourcekinds": resource.KindCustomResourceKind,
+	"applysets":           resource.KindApplySet,
+	"resourcemetas":       resource.KindResourceMeta,
+}
+
+// subResource is a part of a service, which is accessed by
+// /mesh/services/{name}/{subresource} but stored inside the service.
+type subResource struct {
+	kind  string
+	field []string
+}
+
+var subResources = map[string]subResource{
+	"canary":       {resource.KindCanary, []string{"Canary"}},
+	"resilience":   {resource.KindResilience, []string{"Resilience"}},
+	"loadbalance":  {resource.KindLoadBalance, []string{"LoadBalance"}},
+	"mock":         {resource.KindMock, []string{"Mock"}},
+	"outputserver": {resource.KindObservabilityOutputServer, []string{"Observability", "OutputServer"}},
+	"tracings":     {resource.KindObservabilityTracings, []string{"Observability", "Tracings"}},
+	"metrics":      {resource.KindObservabilityMetrics, []string{"Observability", "Metrics"}},
+}
+
+// store keeps objects in JSON, the key of a collection is its path
+// under /mesh, e.g. tenants or customresources/ShadowService.
+type store struct {
+	collections map[string]map[string][]byte
+}
+
+func newStore() *store {
+	return &store{collections: map[string]map[string][]byte{}}
+}
+
+func (s *store) get(key, name string) ([]byte, bool) {
+	raw, ok := s.collections[key][name]
+	return raw, ok
+}
+
+func (s *store) put(key, name string, raw []byte) {
+	collection := s.collections[key]
+	if collection == nil {
+		collection = map[string][]byte{}
+		s.collections[key] = collection
+	}
+	collection[name] = raw
+}
+
+func (s *store) remove(key, name string) bool {
+	if _, ok := s.collections[key][name]; !ok {
+		return false
+	}
+	delete(s.collections[key], name)
+	return true
+}
+
+// list returns objects of the collections with the key prefix, ordered by key and name.
+func (s *store) list(prefix string) [][]byte {
+	keys := []string{}
+	for key := range s.collections {
+		if key == prefix || strings.HasPrefix(key, prefix+"/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := [][]byte{}
+	for _, key := range keys {
+		names := []string{}
+		for name := range s.collections[key] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result = append(result, s.collections[key][name])
+		}
+	}
+	return result
+}
+
+func (s *store) getSubResource(serviceName string, sub subResource) ([]byte, bool, error) {
+	service, ok, err := s.service(serviceName)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	field, ok := subResourceField(service, sub, false)
+	if !ok || field.IsNil() {
+		return nil, false, nil
+	}
+	raw, err := json.Marshal(field.Interface())
+	return raw, true, err
+}
+
+// putSubResource sets the sub resource of the service, nil raw removes it.
+func (s *store) putSubResource(serviceName string, sub subResource, raw []byte) error {
+	service, ok, err := s.service(serviceName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("service %s not found", serviceName)
+	}
+
+	field, ok := subResourceField(service, sub, raw != nil)
+	if ok {
+		if raw == nil {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			value := reflect.New(field.Type().Elem())
+			err = json.Unmarshal(raw, value.Interface())
+			if err != nil {
+				return errors.Wrapf(err, "unmarshal %s failed", sub.kind)
+			}
+			field.Set(value)
+		}
+	}
+
+	buff, err := json.Marshal(service)
+	if err != nil {
+		return errors.Wrapf(err, "marshal service %s failed", serviceName)
+	}
+	s.put(serviceKey, serviceName, buff)
+	return nil
+}
+
+func (s *store) service(name string) (*v1alpha1.Service, bool, error) {
+	raw, ok := s.get(serviceKey, name)
+	if !ok {
+		return nil, false, nil
+	}
+
+	service := &v1alpha1.Service{}
+	err := json.Unmarshal(raw, service)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "unmarshal service %s failed", name)
+	}
+	return service, true, nil
+}
+
+// subResourceField walks to the field of the sub resource,
+// creating the intermediate structs if create is true.
+func subResourceField(service *v1alpha1.Service, sub subResource, create bool) (reflect.Value, bool) {
+	value := reflect.ValueOf(service).Elem()
+	for i, name := range sub.field {
+		field := value.FieldByName(name)
+		if i == len(sub.field)-1 {
+			return field, true
+		}
+		if field.IsNil() {
+			if !create {
+				return reflect.Value{}, false
+			}
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		value = field.Elem()
+	}
+	return reflect.Value{}, false
+}
+
+// objectName returns the name of an object of the collection.
+func objectName(key string, raw []byte) (string, error) {
+	switch key {
+	case meshControllerKey:
+		object := &resource.MeshControllerV1Alpha1{}
+		err := yaml.Unmarshal(raw, object)
+		return object.Name, err
+	case serviceInstanceKey:
+		object := &v1alpha1.ServiceInstance{}
+		err := json.Unmarshal(raw, object)
+		return object.ServiceName + "/" + object.InstanceID, err
+	default:
+		object := struct {
+			Name string `json:"name"`
+		}{}
+		err := json.Unmarshal(raw, &object)
+		return object.Name, err
+	}
+}
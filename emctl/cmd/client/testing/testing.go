@@ -58,6 +58,15 @@ func GetAllResourceKinds() []ResourceTypeKind {
 		{Type: reflect.TypeOf(resource.Service{}), Kind: resource.KindService},
 		{Type: reflect.TypeOf(resource.Resilience{}), Kind: resource.KindResilience},
 		{Type: reflect.TypeOf(resource.Mock{}), Kind: resource.KindMock},
+		{Type: reflect.TypeOf(resource.ExternalService{}), Kind: resource.KindExternalService},
+		{Type: reflect.TypeOf(resource.TenantPolicy{}), Kind: resource.KindTenantPolicy},
+		{Type: reflect.TypeOf(resource.SLO{}), Kind: resource.KindSLO},
+		{Type: reflect.TypeOf(resource.AlertRule{}), Kind: resource.KindAlertRule},
+		{Type: reflect.TypeOf(resource.MessagingPolicy{}), Kind: resource.KindMessagingPolicy},
+		{Type: reflect.TypeOf(resource.IngressPort{}), Kind: resource.KindIngressPort},
+		{Type: reflect.TypeOf(resource.WAFPolicy{}), Kind: resource.KindWAFPolicy},
+		{Type: reflect.TypeOf(resource.MaintenanceMode{}), Kind: resource.KindMaintenanceMode},
+		{Type: reflect.TypeOf(resource.PolicyRollout{}), Kind: resource.KindPolicyRollout},
 	}
 }
 
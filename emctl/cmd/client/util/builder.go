@@ -33,6 +33,8 @@ type (
 	// VisitorBuilder is a builder that build a visitor to visit func
 	VisitorBuilder interface {
 		HTTPAttemptCount(httpGetAttempts int) VisitorBuilder
+		Strict(strict bool) VisitorBuilder
+		Template(template *Template) VisitorBuilder
 		FilenameParam(filenameOptions *FilenameOptions) VisitorBuilder
 		CommandParam(commandOptions *CommandOptions) VisitorBuilder
 		Command() VisitorBuilder
@@ -50,6 +52,7 @@ type (
 		commandOptions    *CommandOptions
 		filenameOptions   *FilenameOptions
 		stdinInUse        bool
+		template          *Template
 	}
 
 	// CommandOptions holds command option
@@ -77,6 +80,18 @@ func (b *visitorBuilder) HTTPAttemptCount(httpGetAttempts int) VisitorBuilder {
 	return b
 }
 
+// Strict sets whether unknown fields of objects are rejected, true by default.
+func (b *visitorBuilder) Strict(strict bool) VisitorBuilder {
+	b.decoder = newDecoder(strict)
+	return b
+}
+
+// Template sets the template rendering variables of files, nil means no rendering.
+func (b *visitorBuilder) Template(template *Template) VisitorBuilder {
+	b.template = template
+	return b
+}
+
 func (b *visitorBuilder) FilenameParam(filenameOptions *FilenameOptions) VisitorBuilder {
 	b.filenameOptions = filenameOptions
 	return b
@@ -108,6 +123,14 @@ func (b *visitorBuilder) Do() ([]Visitor, error) {
 		return nil, fmt.Errorf("%+v", b.errs)
 	}
 
+	if b.template != nil {
+		for _, v := range b.visitors {
+			if s, ok := v.(interface{ setTemplate(*Template) }); ok {
+				s.setTemplate(b.template)
+			}
+		}
+	}
+
 	return b.visitors, nil
 }
 
@@ -50,7 +50,7 @@ apiVersion: mesh.megaease.com/v1alpha1
 metadata:
   name: tenant_{id}
 spec:
-  service: []
+  services: []
 `
 
 	aService = `kind: Service
@@ -18,11 +18,16 @@
 package util
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
 	"github.com/megaease/easemeshctl/cmd/client/valid"
+	"github.com/megaease/easemeshctl/cmd/common"
 
 	"github.com/pkg/errors"
 )
@@ -32,25 +37,53 @@ type Decoder interface {
 	Decode(data []byte) (meta.MeshObject, *meta.VersionKind, error)
 }
 
-type decoder struct {
-	oc resource.ObjectCreator
+type (
+	decoder struct {
+		oc resource.ObjectCreator
+		// strict rejects unknown fields of objects, e.g. typos of fields.
+		strict bool
+	}
+
+	// UnknownFieldError is the error of decoding an unknown field in strict mode.
+	UnknownFieldError struct {
+		Field string
+	}
+)
+
+const unknownFieldErrorPrefix = "json: unknown field "
+
+// Error implements error
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+// ExitCode implements common.ExitCoder
+func (e *UnknownFieldError) ExitCode() int {
+	return common.ExitCodeValidation
 }
 
 func (d *decoder) Decode(jsonBuff []byte) (meta.MeshObject, *meta.VersionKind, error) {
 	vk := &meta.VersionKind{}
 	err := json.Unmarshal(jsonBuff, vk)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "unmarshal data to resource.VersionKind failed")
+		return nil, nil, common.WithCode(errors.Wrap(err, "unmarshal data to resource.VersionKind failed"), common.ExitCodeValidation)
 	}
 
 	meshObject, err := d.oc.NewFromKind(*vk)
 	if err != nil {
-		return nil, vk, err
+		return nil, vk, common.WithCode(err, common.ExitCodeValidation)
 	}
 
-	err = json.Unmarshal(jsonBuff, meshObject)
+	jsonDecoder := json.NewDecoder(bytes.NewReader(jsonBuff))
+	if d.strict {
+		jsonDecoder.DisallowUnknownFields()
+	}
+	err = jsonDecoder.Decode(meshObject)
 	if err != nil {
-		return nil, vk, errors.Wrap(err, "unmarshal data to MeshObject")
+		if field, ok := unknownField(err); ok {
+			return nil, vk, &UnknownFieldError{Field: field}
+		}
+		return nil, vk, common.WithCode(errors.Wrap(err, "unmarshal data to MeshObject"), common.ExitCodeValidation)
 	}
 
 	vr := valid.Validate(meshObject)
@@ -61,6 +94,31 @@ func (d *decoder) Decode(jsonBuff []byte) (meta.MeshObject, *meta.VersionKind, e
 	return meshObject, vk, nil
 }
 
+// unknownField returns the field of the unknown field error of the json package,
+// which reports unknown fields only in messages.
+func unknownField(err error) (string, bool) {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, unknownFieldErrorPrefix) {
+		return "", false
+	}
+	field, err := strconv.Unquote(strings.TrimPrefix(msg, unknownFieldErrorPrefix))
+	if err != nil {
+		return "", false
+	}
+	return field, true
+}
+
+// NewDecoder returns a Decoder of mesh objects in JSON, the strict one
+// rejects unknown fields with UnknownFieldError.
+func NewDecoder(strict bool) Decoder {
+	return newDecoder(strict)
+}
+
+// newDefaultDecoder returns a decoder rejecting unknown fields.
 func newDefaultDecoder() Decoder {
-	return &decoder{oc: resource.NewObjectCreator()}
+	return newDecoder(true)
+}
+
+func newDecoder(strict bool) Decoder {
+	return &decoder{oc: resource.NewObjectCreator(), strict: strict}
 }
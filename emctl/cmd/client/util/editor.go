@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultEditor = "vi"
+
+// RunEditor opens the file in the editor, and waits for it exiting.
+var RunEditor = func(path string) error {
+	args := append(strings.Fields(editorCommand()), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// editorCommand returns the editor from the env EMCTL_EDITOR or EDITOR,
+// the default one is vi.
+func editorCommand() string {
+	for _, env := range []string{"EMCTL_EDITOR", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return defaultEditor
+}
+
+// Edit opens the content in a temporary file named by the pattern with
+// the editor, and returns the content after editing.
+func Edit(content []byte, pattern string) ([]byte, error) {
+	file, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "create temporary file")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(content)
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "write temporary file %s", file.Name())
+	}
+	err = file.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "close temporary file %s", file.Name())
+	}
+
+	err = RunEditor(file.Name())
+	if err != nil {
+		return nil, errors.Wrapf(err, "run editor %s", editorCommand())
+	}
+
+	return ioutil.ReadFile(file.Name())
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestEdit(t *testing.T) {
+	defer func(fn func(string) error) { RunEditor = fn }(RunEditor)
+
+	RunEditor = func(path string) error {
+		buff, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, bytes.Replace(buff, []byte("5s"), []byte("30s"), 1), 0o600)
+	}
+
+	edited, err := Edit([]byte("heartbeatInterval: 5s\n"), "emctl-test-*.yaml")
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if string(edited) != "heartbeatInterval: 30s\n" {
+		t.Fatalf("unexpected edited content: %s", edited)
+	}
+}
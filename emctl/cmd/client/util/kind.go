@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+// ResolveCommandKind converts the kind in command line to the kind of the
+// EaseMesh resource. Kinds not built in emctl are discovered from the custom
+// resource kinds registered in the control plane, which are matched by
+// their case-insensitive names or plurals, e.g. shadowservices.
+func ResolveCommandKind(client meshclient.MeshClient, kind string, timeout time.Duration) (string, error) {
+	adapted := AdaptCommandKind(kind)
+	object, err := resource.NewObjectCreator().NewFromKind(meta.VersionKind{
+		APIVersion: resource.DefaultAPIVersion,
+		Kind:       adapted,
+	})
+	if err == nil {
+		if _, ok := object.(*resource.CustomResource); !ok {
+			return adapted, nil
+		}
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+	kinds, err := client.V1Alpha1().CustomResourceKind().List(ctx)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return "", errors.Wrap(err, "list custom resource kinds")
+	}
+
+	for _, k := range kinds {
+		if matchCommandKind(k.Name(), kind) {
+			return k.Name(), nil
+		}
+	}
+
+	return "", common.WithCode(errors.Errorf("unknown kind %s, it's neither built in nor a registered custom resource kind", kind),
+		common.ExitCodeValidation)
+}
+
+func matchCommandKind(kind, arg string) bool {
+	kind, arg = strings.ToLower(kind), strings.ToLower(arg)
+	switch {
+	case arg == kind, arg == kind+"s":
+		return true
+	case strings.HasSuffix(kind, "s"), strings.HasSuffix(kind, "x"), strings.HasSuffix(kind, "ch"), strings.HasSuffix(kind, "sh"):
+		return arg == kind+"es"
+	case strings.HasSuffix(kind, "y"):
+		return arg == strings.TrimSuffix(kind, "y")+"ies"
+	}
+	return false
+}
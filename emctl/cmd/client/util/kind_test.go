@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+func TestResolveCommandKind(t *testing.T) {
+	reactorType := "__resolve_kind_reactor"
+	fake.NewResourceReactorBuilder(reactorType).
+		AddReactor("list", resource.KindCustomResourceKind, "*", func(fake.Action) (bool, []meta.MeshObject, error) {
+			return true, []meta.MeshObject{
+				&resource.CustomResourceKind{MeshResource: resource.NewCustomResourceKindResource(resource.DefaultAPIVersion, "ShadowService")},
+				&resource.CustomResourceKind{MeshResource: resource.NewCustomResourceKindResource(resource.DefaultAPIVersion, "RetryPolicy")},
+			}, nil
+		}).
+		Added()
+	client := meshclient.NewFakeClient(reactorType)
+
+	for arg, expected := range map[string]string{
+		"service":        resource.KindService,
+		"Tenant":         resource.KindTenant,
+		"shadowservice":  "ShadowService",
+		"ShadowServices": "ShadowService",
+		"retrypolicies":  "RetryPolicy",
+	} {
+		kind, err := ResolveCommandKind(client, arg, time.Second)
+		if err != nil || kind != expected {
+			t.Fatalf("%s should be resolved to %s, but got %s %v", arg, expected, kind, err)
+		}
+	}
+
+	if _, err := ResolveCommandKind(client, "widget", time.Second); err == nil {
+		t.Fatalf("unregistered kind should not be resolved")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import "reflect"
+
+// MergePatch applies the JSON merge patch (RFC 7386) to the target.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for k, v := range patchObject {
+		if v == nil {
+			delete(targetObject, k)
+			continue
+		}
+		targetObject[k] = MergePatch(targetObject[k], v)
+	}
+
+	return targetObject
+}
+
+// CreateMergePatch returns the JSON merge patch (RFC 7386) which turns
+// the original object into the modified one, lists are replaced as a whole.
+func CreateMergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k, v := range modified {
+		o, exists := original[k]
+		switch {
+		case !exists:
+			patch[k] = v
+		case reflect.DeepEqual(o, v):
+		default:
+			oObject, oOK := o.(map[string]interface{})
+			vObject, vOK := v.(map[string]interface{})
+			if oOK && vOK {
+				patch[k] = CreateMergePatch(oObject, vObject)
+			} else {
+				patch[k] = v
+			}
+		}
+	}
+	for k := range original {
+		if _, exists := modified[k]; !exists {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
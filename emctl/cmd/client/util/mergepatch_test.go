@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	target := map[string]interface{}{
+		"a": "b",
+		"c": map[string]interface{}{"d": "e", "f": "g"},
+	}
+	patch := map[string]interface{}{
+		"a": "z",
+		"c": map[string]interface{}{"f": nil},
+		"h": []interface{}{"i"},
+	}
+
+	expected := map[string]interface{}{
+		"a": "z",
+		"c": map[string]interface{}{"d": "e"},
+		"h": []interface{}{"i"},
+	}
+	if result := MergePatch(target, patch); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	original := map[string]interface{}{
+		"a": "b",
+		"c": map[string]interface{}{"d": "e", "f": "g"},
+		"h": []interface{}{"i"},
+	}
+	modified := map[string]interface{}{
+		"a": "b",
+		"c": map[string]interface{}{"d": "x"},
+		"h": []interface{}{"i", "j"},
+		"k": "l",
+	}
+
+	expected := map[string]interface{}{
+		"c": map[string]interface{}{"d": "x", "f": nil},
+		"h": []interface{}{"i", "j"},
+		"k": "l",
+	}
+	patch := CreateMergePatch(original, modified)
+	if !reflect.DeepEqual(patch, expected) {
+		t.Fatalf("expected %v, got %v", expected, patch)
+	}
+	if result := MergePatch(original, patch); !reflect.DeepEqual(result, modified) {
+		t.Fatalf("expected %v after patching, got %v", modified, result)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// templateVariable matches ${name}, ${name:-default}, and the escaped $${.
+	templateVariable = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_.-]*)(:-([^}]*))?\}`)
+	templateName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+)
+
+// Template renders variables in resource files, so one file serves multiple
+// environments. A variable ${name} is resolved by --set, --values files, and
+// environment variables if enabled in order, ${name:-default} falls back to
+// the default, and $${ is rendered as the literal ${.
+type Template struct {
+	values map[string]string
+	env    bool
+}
+
+// NewTemplate creates a Template by values files, values in the form of
+// <name>=<value> overriding them, and whether environment variables are
+// resolved. It returns nil if none of them is given, so files are not rendered.
+func NewTemplate(valuesFiles, sets []string, env bool) (*Template, error) {
+	if len(valuesFiles) == 0 && len(sets) == 0 && !env {
+		return nil, nil
+	}
+
+	t := &Template{values: map[string]string{}, env: env}
+	for _, file := range valuesFiles {
+		buff, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read values file %s", file)
+		}
+		values := map[string]interface{}{}
+		err = yaml.Unmarshal(buff, &values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshal values file %s", file)
+		}
+		err = flattenValues("", values, t.values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "values file %s", file)
+		}
+	}
+
+	for _, set := range sets {
+		parts := strings.SplitN(set, "=", 2)
+		if len(parts) != 2 || !templateName.MatchString(parts[0]) {
+			return nil, errors.Errorf("invalid value %q, want <name>=<value>", set)
+		}
+		t.values[parts[0]] = parts[1]
+	}
+
+	return t, nil
+}
+
+// flattenValues flattens nested maps of values to names joined by dots, e.g. db.host.
+func flattenValues(prefix string, values map[string]interface{}, result map[string]string) error {
+	for key, value := range values {
+		name := prefix + key
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			nested := map[string]interface{}{}
+			for k, vv := range v {
+				nested[fmt.Sprint(k)] = vv
+			}
+			err := flattenValues(name+".", nested, result)
+			if err != nil {
+				return err
+			}
+		case []interface{}:
+			return errors.Errorf("value %s is a list, only scalars and maps are supported", name)
+		case nil:
+			result[name] = ""
+		default:
+			result[name] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+func (t *Template) lookup(name string) (string, bool) {
+	if value, ok := t.values[name]; ok {
+		return value, true
+	}
+	if t.env {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// Render renders variables in the content of the source, all undefined
+// variables without defaults are reported with their lines.
+func (t *Template) Render(buff []byte, source string) ([]byte, error) {
+	var undefined []string
+	result := templateVariable.ReplaceAllFunc(buff, func(match []byte) []byte {
+		if bytes.Equal(match, []byte("$${")) {
+			return []byte("${")
+		}
+
+		groups := templateVariable.FindSubmatch(match)
+		name := string(groups[1])
+		if value, ok := t.lookup(name); ok {
+			return []byte(value)
+		}
+		if len(groups[2]) != 0 {
+			return groups[3]
+		}
+
+		undefined = append(undefined, name)
+		return match
+	})
+
+	if len(undefined) != 0 {
+		lines := map[string]int{}
+		for _, loc := range templateVariable.FindAllSubmatchIndex(buff, -1) {
+			if loc[2] < 0 {
+				continue
+			}
+			name := string(buff[loc[2]:loc[3]])
+			if _, ok := lines[name]; !ok {
+				lines[name] = bytes.Count(buff[:loc[0]], []byte("\n")) + 1
+			}
+		}
+
+		names := []string{}
+		for _, name := range undefined {
+			names = append(names, fmt.Sprintf("%s (line %d)", name, lines[name]))
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("undefined variables in %s: %s, please set them by --set or --values",
+			source, strings.Join(uniqueStrings(names), ", "))
+	}
+
+	return result, nil
+}
+
+func uniqueStrings(sorted []string) []string {
+	result := []string{}
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
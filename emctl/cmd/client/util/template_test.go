@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+)
+
+func TestNewTemplate(t *testing.T) {
+	template, err := NewTemplate(nil, nil, false)
+	if template != nil || err != nil {
+		t.Fatalf("expected no template without values, got %v, %v", template, err)
+	}
+
+	dir, err := ioutil.TempDir("", "emctl-template")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	base, prod := filepath.Join(dir, "base.yaml"), filepath.Join(dir, "prod.yaml")
+	ioutil.WriteFile(base, []byte("tenant: dev\nreplicas: 1\ndb:\n  host: localhost\n"), 0o644)
+	ioutil.WriteFile(prod, []byte("tenant: prod\ndb:\n  port: 3306\n"), 0o644)
+
+	template, err = NewTemplate([]string{base, prod}, []string{"tenant=shop", "db.host=db.shop"}, false)
+	if err != nil {
+		t.Fatalf("new template failed: %v", err)
+	}
+	expected := map[string]string{"tenant": "shop", "replicas": "1", "db.host": "db.shop", "db.port": "3306"}
+	for name, value := range expected {
+		if template.values[name] != value {
+			t.Errorf("expected %s=%s, got %q", name, value, template.values[name])
+		}
+	}
+
+	for _, set := range []string{"tenant", "=shop", "1tenant=shop"} {
+		if _, err := NewTemplate(nil, []string{set}, false); err == nil {
+			t.Errorf("expected error for --set %q", set)
+		}
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	os.Setenv("EMCTL_TEST_REGION", "us-east-1")
+	defer os.Unsetenv("EMCTL_TEST_REGION")
+
+	template := &Template{values: map[string]string{"tenant": "shop"}, env: true}
+	buff, err := template.Render([]byte(`registerTenant: ${tenant}
+region: ${EMCTL_TEST_REGION}
+protocol: ${protocol:-http}
+body: "$${tenant}"
+`), "svc.yaml")
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	expected := `registerTenant: shop
+region: us-east-1
+protocol: http
+body: "${tenant}"
+`
+	if string(buff) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buff)
+	}
+
+	template.env = false
+	_, err = template.Render([]byte("tenant: ${tenant}\nregion: ${EMCTL_TEST_REGION}\nzone: ${zone}\n"), "svc.yaml")
+	if err == nil || !strings.Contains(err.Error(), "svc.yaml: EMCTL_TEST_REGION (line 2), zone (line 3)") {
+		t.Fatalf("expected undefined variables with lines, got %v", err)
+	}
+}
+
+func TestVisitorTemplate(t *testing.T) {
+	source := `kind: Tenant
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: ${tenant}
+spec:
+  description: ${description:-tenant}
+`
+	v := newStreamVisitor(strings.NewReader(source), newDefaultDecoder(), "tenant.yaml")
+	v.setTemplate(&Template{values: map[string]string{"tenant": "shop"}})
+
+	var names []string
+	err := v.Visit(func(mo meta.MeshObject, e error) error {
+		if e != nil {
+			return e
+		}
+		names = append(names, mo.Name())
+		return nil
+	})
+	if err != nil || len(names) != 1 || names[0] != "shop" {
+		t.Fatalf("expected tenant shop, got %v, %v", names, err)
+	}
+}
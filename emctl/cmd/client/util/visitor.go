@@ -21,15 +21,18 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
 	"github.com/megaease/easemeshctl/cmd/client/resource/meta"
+	"github.com/megaease/easemeshctl/cmd/common"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/pkg/errors"
@@ -43,6 +46,7 @@ import (
 
 const (
 	constSTDINstr = "STDIN"
+	yamlSeparator = "---"
 )
 
 // Visitor is visitor to visit all MeshObjects via VisitorFunc
@@ -53,6 +57,28 @@ type Visitor interface {
 // VisitorFunc executes visition logic
 type VisitorFunc func(meta.MeshObject, error) error
 
+// LocatedVisitor is a Visitor telling where every MeshObject is decoded from,
+// e.g. to report problems of objects with their positions.
+type LocatedVisitor interface {
+	Visitor
+	VisitLocated(LocatedVisitorFunc) error
+}
+
+// LocatedVisitorFunc executes visition logic with the location of the MeshObject
+type LocatedVisitorFunc func(meta.MeshObject, Location, error) error
+
+// Location is where a MeshObject is decoded from, Line is the first line of its YAML document.
+type Location struct {
+	Source string
+	Line   int
+}
+
+func ignoreLocation(fn VisitorFunc) LocatedVisitorFunc {
+	return func(mo meta.MeshObject, _ Location, err error) error {
+		return fn(mo, err)
+	}
+}
+
 // RawExtension is a raw struct that holds raw information of the spec
 type RawExtension struct {
 	Raw []byte `json:"-" protobuf:"bytes,1,opt,name=raw"`
@@ -95,6 +121,12 @@ func FileVisitorForSTDIN(decoder Decoder) Visitor {
 	}
 }
 
+// NewReaderVisitor returns a Visitor decoding mesh objects from the reader,
+// the source names the reader in error messages.
+func NewReaderVisitor(r io.Reader, source string) Visitor {
+	return newStreamVisitor(r, newDefaultDecoder(), source)
+}
+
 func expandPathsToFileVisitors(decoder Decoder, paths string, recursive bool, extensions []string) ([]Visitor, error) {
 	var visitors []Visitor
 	err := filepath.Walk(paths, func(path string, fi os.FileInfo, err error) error {
@@ -133,10 +165,15 @@ type fileVisitor struct {
 	*streamVisitor
 }
 
-var _ Visitor = &fileVisitor{}
+var _ LocatedVisitor = &fileVisitor{}
 
 // Visit in a FileVisitor is just taking care of opening/closing files
 func (v *fileVisitor) Visit(fn VisitorFunc) error {
+	return v.VisitLocated(ignoreLocation(fn))
+}
+
+// VisitLocated is Visit with locations of objects
+func (v *fileVisitor) VisitLocated(fn LocatedVisitorFunc) error {
 	var f *os.File
 	if v.Path == constSTDINstr {
 		f = os.Stdin
@@ -152,7 +189,7 @@ func (v *fileVisitor) Visit(fn VisitorFunc) error {
 	utf16bom := unicode.BOMOverride(unicode.UTF8.NewDecoder())
 	v.streamVisitor.Reader = transform.NewReader(f, utf16bom)
 
-	return v.streamVisitor.Visit(fn)
+	return v.streamVisitor.VisitLocated(fn)
 }
 
 type streamVisitor struct {
@@ -160,9 +197,11 @@ type streamVisitor struct {
 
 	Decoder Decoder
 	Source  string
+	// template renders variables of the stream before it's decoded, nil means no rendering.
+	template *Template
 }
 
-var _ Visitor = &streamVisitor{}
+var _ LocatedVisitor = &streamVisitor{}
 
 // newStreamVisitor is a helper function that is useful when we want to change the fields of the struct but keep calls the same.
 func newStreamVisitor(r io.Reader, decoder Decoder, source string) *streamVisitor {
@@ -173,32 +212,58 @@ func newStreamVisitor(r io.Reader, decoder Decoder, source string) *streamVisito
 	}
 }
 
+func (v *streamVisitor) setTemplate(t *Template) {
+	v.template = t
+}
+
 // Visit implements Visitor over a stream. StreamVisitor is able to distinct multiple resources in one stream.
 func (v *streamVisitor) Visit(fn VisitorFunc) error {
-	var errs []error
-	d := yaml.NewYAMLOrJSONDecoder(v.Reader, 4096)
-	for {
-		ext := RawExtension{}
-		if err := d.Decode(&ext); err != nil {
-			if err != io.EOF {
-				errs = append(errs, errors.Errorf("error parsing %s: %v", v.Source, err))
-			}
-			break
-		}
-		jsonBuff, err := ext.MarshalJSON()
+	return v.VisitLocated(ignoreLocation(fn))
+}
+
+// VisitLocated is Visit with locations of objects
+func (v *streamVisitor) VisitLocated(fn LocatedVisitorFunc) error {
+	buff, err := ioutil.ReadAll(v.Reader)
+	if err != nil {
+		return errors.Wrapf(err, "read %s", v.Source)
+	}
+	if v.template != nil {
+		buff, err = v.template.Render(buff, v.Source)
 		if err != nil {
-			return err
+			return common.WithCode(err, common.ExitCodeValidation)
 		}
+	}
 
-		ext.Raw = bytes.TrimSpace(jsonBuff)
-		if len(ext.Raw) == 0 || bytes.Equal(ext.Raw, []byte("null")) {
-			continue
-		}
-		info, err := v.decodeMeshObject(jsonBuff, v.Source)
+	var errs []error
+	for _, doc := range splitDocuments(buff) {
+		d := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(doc.raw), 4096)
+		for {
+			ext := RawExtension{}
+			if err := d.Decode(&ext); err != nil {
+				if err != io.EOF {
+					errs = append(errs, common.CodeErrorf(common.ExitCodeValidation, "error parsing %s: %v", v.Source, err))
+				}
+				break
+			}
+			jsonBuff, err := ext.MarshalJSON()
+			if err != nil {
+				return err
+			}
+
+			ext.Raw = bytes.TrimSpace(jsonBuff)
+			if len(ext.Raw) == 0 || bytes.Equal(ext.Raw, []byte("null")) {
+				continue
+			}
+			info, err := v.decodeMeshObject(jsonBuff, v.Source)
+			var unknownFieldErr *UnknownFieldError
+			if errors.As(err, &unknownFieldErr) {
+				err = v.unknownFieldError(doc, unknownFieldErr)
+			}
 
-		err1 := fn(info, err)
-		if err1 != nil {
-			errs = append(errs, err1)
+			err1 := fn(info, Location{Source: v.Source, Line: doc.line}, err)
+			if err1 != nil {
+				errs = append(errs, err1)
+			}
 		}
 	}
 
@@ -215,7 +280,54 @@ func (v *streamVisitor) Visit(fn VisitorFunc) error {
 		}
 	}
 
-	return finalErr
+	// NOTE: Keep the exit code since messages are joined.
+	return common.WithCode(finalErr, common.ExitCodeOf(errs...))
+}
+
+// unknownFieldError reports the unknown field with its position in the source.
+func (v *streamVisitor) unknownFieldError(doc document, err *UnknownFieldError) error {
+	line, column, ok := locateField(doc.raw, err.Field)
+	if !ok {
+		return common.CodeErrorf(common.ExitCodeValidation, "error parsing %s: %v", v.Source, err)
+	}
+	return common.CodeErrorf(common.ExitCodeValidation, "error parsing %s: line %d, column %d: %v",
+		v.Source, doc.line+line-1, column, err)
+}
+
+// document is a YAML document in a stream, line is the number of its first line.
+type document struct {
+	raw  []byte
+	line int
+}
+
+// splitDocuments splits the stream into YAML documents by lines of the separator ---,
+// the same as the YAML reader of Kubernetes.
+func splitDocuments(buff []byte) []document {
+	var docs []document
+	start, line := 0, 1
+	lines := bytes.SplitAfter(buff, []byte("\n"))
+	offset := 0
+	for i, l := range lines {
+		if bytes.HasPrefix(l, []byte(yamlSeparator)) && len(bytes.TrimSpace(l[len(yamlSeparator):])) == 0 {
+			docs = append(docs, document{raw: buff[start:offset], line: line})
+			start, line = offset+len(l), i+2
+		}
+		offset += len(l)
+	}
+	return append(docs, document{raw: buff[start:], line: line})
+}
+
+// locateField returns the line and the column of the first key named
+// the field in the document, both of them start from 1.
+func locateField(doc []byte, field string) (int, int, bool) {
+	re := regexp.MustCompile(`(^|[\s{,-])"?` + regexp.QuoteMeta(field) + `"?\s*:`)
+	for i, line := range bytes.Split(doc, []byte("\n")) {
+		loc := re.FindSubmatchIndex(line)
+		if loc != nil {
+			return i + 1, loc[3] + 1, true
+		}
+	}
+	return 0, 0, false
 }
 
 func (v *streamVisitor) decodeMeshObject(data []byte, source string) (meta.MeshObject, error) {
@@ -232,14 +344,21 @@ type urlVisitor struct {
 	HTTPAttemptCount int
 }
 
+var _ LocatedVisitor = &urlVisitor{}
+
 func (v *urlVisitor) Visit(fn VisitorFunc) error {
+	return v.VisitLocated(ignoreLocation(fn))
+}
+
+// VisitLocated is Visit with locations of objects
+func (v *urlVisitor) VisitLocated(fn LocatedVisitorFunc) error {
 	body, err := readHTTPWithRetries(resty.New(), 5*time.Second, v.URL.String(), v.HTTPAttemptCount)
 	if err != nil {
 		return err
 	}
 	defer body.Close()
 	v.streamVisitor.Reader = body
-	return v.streamVisitor.Visit(fn)
+	return v.streamVisitor.VisitLocated(fn)
 }
 
 // readHTTPWithRetries tries to http.Get the v.URL retries times before giving up.
@@ -285,13 +404,14 @@ var _ Visitor = &commandVisitor{}
 
 func newCommandVisitor(kind, name string) *commandVisitor {
 	return &commandVisitor{
-		Kind: adaptCommandKind(kind),
+		Kind: AdaptCommandKind(kind),
 		Name: name,
 		oc:   resource.NewObjectCreator(),
 	}
 }
 
-func adaptCommandKind(kind string) string {
+// AdaptCommandKind converts the case-insensitive kind in command line to the kind of the EaseMesh resource.
+func AdaptCommandKind(kind string) string {
 	low := strings.ToLower
 	switch low(kind) {
 	case low(resource.KindMeshController):
@@ -326,6 +446,24 @@ func adaptCommandKind(kind string) string {
 		return resource.KindServiceCanary
 	case low(resource.KindCustomResourceKind):
 		return resource.KindCustomResourceKind
+	case low(resource.KindTenantPolicy):
+		return resource.KindTenantPolicy
+	case low(resource.KindExternalService):
+		return resource.KindExternalService
+	case low(resource.KindSLO):
+		return resource.KindSLO
+	case low(resource.KindAlertRule):
+		return resource.KindAlertRule
+	case low(resource.KindMessagingPolicy):
+		return resource.KindMessagingPolicy
+	case low(resource.KindIngressPort):
+		return resource.KindIngressPort
+	case low(resource.KindWAFPolicy):
+		return resource.KindWAFPolicy
+	case low(resource.KindMaintenanceMode):
+		return resource.KindMaintenanceMode
+	case low(resource.KindPolicyRollout):
+		return resource.KindPolicyRollout
 	default:
 		return kind
 	}
@@ -17,6 +17,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
@@ -40,3 +41,63 @@ func TestFileVisitor(t *testing.T) {
 func TestVisitorForSTDIN(t *testing.T) {
 	FileVisitorForSTDIN(newDefaultDecoder()).Visit(func(mo meta.MeshObject, e error) error { return nil })
 }
+
+func TestStrictVisitor(t *testing.T) {
+	source := `kind: Tenant
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: tenant-001
+spec:
+  description: tenant
+---
+kind: Tenant
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: tenant-002
+spec:
+  descripton: tenant
+`
+	err := NewReaderVisitor(strings.NewReader(source), "tenant.yaml").Visit(func(mo meta.MeshObject, e error) error { return e })
+	if err == nil || !strings.Contains(err.Error(), `error parsing tenant.yaml: line 13, column 3: unknown field "descripton"`) {
+		t.Fatalf("expected unknown field at line 13, column 3, got %v", err)
+	}
+
+	var objects []meta.MeshObject
+	err = newStreamVisitor(strings.NewReader(source), newDecoder(false), "tenant.yaml").Visit(func(mo meta.MeshObject, e error) error {
+		objects = append(objects, mo)
+		return e
+	})
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("expected 2 objects decoded laxly, got %d objects, %v", len(objects), err)
+	}
+}
+
+func TestLocatedVisitor(t *testing.T) {
+	source := `kind: Tenant
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: tenant-001
+spec:
+  description: tenant
+---
+# The second tenant
+kind: Tenant
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: tenant-002
+spec:
+  description: tenant
+`
+	lines := map[string]int{}
+	err := newStreamVisitor(strings.NewReader(source), newDefaultDecoder(), "tenant.yaml").VisitLocated(
+		func(mo meta.MeshObject, location Location, e error) error {
+			if location.Source != "tenant.yaml" {
+				t.Errorf("expected source tenant.yaml, got %s", location.Source)
+			}
+			lines[mo.Name()] = location.Line
+			return e
+		})
+	if err != nil || lines["tenant-001"] != 1 || lines["tenant-002"] != 8 {
+		t.Fatalf("expected tenants at line 1 and 8, got %v, %v", lines, err)
+	}
+}
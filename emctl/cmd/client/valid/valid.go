@@ -133,6 +133,27 @@ func Validate(v interface{}) *ValidateRecorder {
 	return vr
 }
 
+// ValidateBySchema validates the dynamic value by the json schema, e.g. a
+// custom resource by the schema of its kind registered in the control plane.
+func ValidateBySchema(schema, v map[string]interface{}) *ValidateRecorder {
+	vr := &ValidateRecorder{}
+
+	s, err := loadjs.NewSchema(loadjs.NewGoLoader(schema))
+	if err != nil {
+		vr.recordSystem(fmt.Errorf("new schema from %v failed: %v", schema, err))
+		return vr
+	}
+
+	result, err := s.Validate(loadjs.NewGoLoader(v))
+	if err != nil {
+		vr.recordSystem(fmt.Errorf("validate %v failed: %v", v, err))
+		return vr
+	}
+	vr.recordJSONSchema(result)
+
+	return vr
+}
+
 func getSchemaMeta(t reflect.Type) (*schemaMeta, error) {
 	schemaMetasMutex.Lock()
 	defer schemaMetasMutex.Unlock()
@@ -19,7 +19,6 @@ package valid
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 	"runtime/debug"
 	"strings"
@@ -174,10 +173,15 @@ func (vr *ValidateRecorder) Error() string {
 	return vr.String()
 }
 
+// ExitCode makes emctl exit with common.ExitCodeValidation for invalid resources.
+func (vr *ValidateRecorder) ExitCode() int {
+	return common.ExitCodeValidation
+}
+
 func (vr *ValidateRecorder) String() string {
 	buff, err := yaml.Marshal(vr)
 	if err != nil {
-		log.Printf("BUG: marshal %#v to yaml failed: %v", vr, err)
+		common.OutputErrorf("BUG: marshal %#v to yaml failed: %v", vr, err)
 	}
 	return string(buff)
 }
@@ -19,8 +19,14 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/megaease/easemeshctl/cmd/common"
+
 	"github.com/go-resty/resty/v2"
 )
 
@@ -49,6 +55,33 @@ type HTTPJSONClient interface {
 // Option is option function
 type Option func(*resty.Client)
 
+// defaultHeaders are sent with requests of all clients,
+// e.g. the identity of the operator for the audit log of the control plane.
+var defaultHeaders = map[string]string{}
+
+// SetDefaultHeader sets a header sent with requests of all clients
+func SetDefaultHeader(key, value string) {
+	defaultHeaders[key] = value
+}
+
+type headersKey struct{}
+
+// WithHeader returns a context whose requests are sent with the header,
+// e.g. the precondition of updating a resource.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	headers := map[string]string{}
+	for k, v := range headersOf(ctx) {
+		headers[k] = v
+	}
+	headers[key] = value
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+func headersOf(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
+
 type httpJSONClient struct {
 	options []Option
 }
@@ -81,7 +114,7 @@ func WrapRetryOptions(retryCount int, retryWaitTime time.Duration, conditionFunc
 	}
 }
 
-func (h *httpJSONClient) setupClient(timeout *time.Duration, extraHeaders map[string]string) *resty.Client {
+func (h *httpJSONClient) setupClient(ctx context.Context, timeout *time.Duration, extraHeaders map[string]string) *resty.Client {
 	client := resty.New()
 	client.
 		SetHeader("Content-Type", "application/json").
@@ -91,6 +124,10 @@ func (h *httpJSONClient) setupClient(timeout *time.Duration, extraHeaders map[st
 		client.SetTimeout(*timeout)
 	}
 
+	for k, v := range defaultHeaders {
+		client.SetHeader(k, v)
+	}
+
 	for _, o := range h.options {
 		o(client)
 	}
@@ -100,9 +137,67 @@ func (h *httpJSONClient) setupClient(timeout *time.Duration, extraHeaders map[st
 			client.SetHeader(k, v)
 		}
 	}
+
+	for k, v := range headersOf(ctx) {
+		client.SetHeader(k, v)
+	}
+
+	if common.Verbosity() > 0 {
+		client.OnAfterResponse(logResponse)
+	}
 	return client
 }
 
+// logResponse logs requests to the control plane for debugging,
+// bodies are logged only if the verbosity is greater than 1.
+func logResponse(c *resty.Client, r *resty.Response) error {
+	fields := []interface{}{
+		"method", r.Request.Method,
+		"url", r.Request.URL,
+		"status", r.StatusCode(),
+		"latency", r.Time().String(),
+	}
+
+	if common.Verbosity() > 1 {
+		switch body := r.Request.Body.(type) {
+		case nil:
+		case []byte:
+			fields = append(fields, "requestBody", redact(body))
+		case string:
+			fields = append(fields, "requestBody", redact([]byte(body)))
+		default:
+			reqBody, err := json.Marshal(body)
+			if err == nil {
+				fields = append(fields, "requestBody", redact(reqBody))
+			}
+		}
+		fields = append(fields, "responseBody", redact(r.Body()))
+	}
+
+	common.Logger().Debugw("request to the control plane", fields...)
+	return nil
+}
+
+// handleResponse rejects responses of unauthenticated or unauthorized
+// requests before fn, so all commands exit with ExitCodeForbidden for them.
+func handleResponse(r *resty.Response, err error, fn UnmarshalFunc) (interface{}, error) {
+	defer closeRawBody(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.StatusCode() {
+	case http.StatusUnauthorized:
+		return nil, common.CodeErrorf(common.ExitCodeForbidden, "unauthenticated by %s, check the token: %s",
+			r.Request.URL, strings.TrimSpace(string(r.Body())))
+	case http.StatusForbidden:
+		return nil, common.CodeErrorf(common.ExitCodeForbidden, "forbidden by %s: %s",
+			r.Request.URL, strings.TrimSpace(string(r.Body())))
+	}
+	return fn(r.Body(), r.StatusCode())
+}
+
 func closeRawBody(r *resty.Response) {
 	if r != nil && r.RawBody() != nil {
 		defer r.RawBody().Close()
@@ -110,131 +205,93 @@ func closeRawBody(r *resty.Response) {
 }
 
 func (h *httpJSONClient) Post(url string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(&timeout, extraHeaders)
+	client := h.setupClient(context.Background(), &timeout, extraHeaders)
 	r, err := client.R().SetBody(reqBody).Post(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) PostByContext(ctx context.Context, url string, reqBody interface{}, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(nil, extraHeaders)
+	client := h.setupClient(ctx, nil, extraHeaders)
 	r, err := client.R().SetContext(ctx).SetBody(reqBody).Post(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) Delete(url string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(&timeout, extraHeaders)
+	client := h.setupClient(context.Background(), &timeout, extraHeaders)
 	r, err := client.R().SetBody(reqBody).Delete(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) DeleteByContext(ctx context.Context, url string, reqBody interface{}, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(nil, extraHeaders)
+	client := h.setupClient(ctx, nil, extraHeaders)
 	r, err := client.R().SetContext(ctx).SetBody(reqBody).Delete(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) Patch(url string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(&timeout, extraHeaders)
+	client := h.setupClient(context.Background(), &timeout, extraHeaders)
 	r, err := client.R().SetBody(reqBody).Patch(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) PatchByContext(ctx context.Context, url string, reqBody interface{}, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(nil, extraHeaders)
+	client := h.setupClient(ctx, nil, extraHeaders)
 	r, err := client.R().SetContext(ctx).SetBody(reqBody).Patch(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) Put(url string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(&timeout, extraHeaders)
+	client := h.setupClient(context.Background(), &timeout, extraHeaders)
 	r, err := client.R().SetBody(reqBody).Put(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) PutByContext(ctx context.Context, url string, reqBody interface{}, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(nil, extraHeaders)
+	client := h.setupClient(ctx, nil, extraHeaders)
 	r, err := client.R().SetContext(ctx).SetBody(reqBody).Put(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) Get(url string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(&timeout, extraHeaders)
+	client := h.setupClient(context.Background(), &timeout, extraHeaders)
 	r, err := client.R().Get(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
 
 func (h *httpJSONClient) GetByContext(ctx context.Context, url string, reqBody interface{}, extraHeaders map[string]string) HTTPJSONResponseHandler {
-	client := h.setupClient(nil, extraHeaders)
+	client := h.setupClient(ctx, nil, extraHeaders)
 	r, err := client.R().SetContext(ctx).Get(url)
 	return (httpJSONResponseFunc)(func(fn UnmarshalFunc) (interface{}, error) {
-		defer closeRawBody(r)
-
-		if err != nil {
-			return nil, err
-		}
-		return fn(r.Body(), r.StatusCode())
+		return handleResponse(r, err, fn)
 	})
 }
+
+// StreamByContext sends a GET request whose response is a stream, e.g. events
+// in newline-delimited JSON, the body is read until the context is done.
+// The caller must close the body if the error is nil.
+func StreamByContext(ctx context.Context, url string, extraHeaders map[string]string) (io.ReadCloser, int, error) {
+	client := (&httpJSONClient{}).setupClient(ctx, nil, extraHeaders)
+	r, err := client.R().SetContext(ctx).SetDoNotParseResponse(true).Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.RawBody(), r.StatusCode(), nil
+}
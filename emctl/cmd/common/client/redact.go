@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveKeys are suffixes of keys whose values are never logged,
+// e.g. the token of an access token, or the password of a registry.
+var sensitiveKeys = []string{"token", "secret", "password", "authorization"}
+
+// redact replaces values of sensitive fields in the JSON or YAML body,
+// which is logged in JSON then. Other bodies are logged as they are.
+func redact(body []byte) string {
+	jsonBody, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		return string(body)
+	}
+
+	var value interface{}
+	err = json.Unmarshal(jsonBody, &value)
+	if err != nil {
+		return string(body)
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return string(body)
+	}
+
+	buff, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(buff)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return value
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.HasSuffix(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	for _, c := range []struct {
+		body, expected string
+	}{
+		{`{"id":"9f2c","token":"9f2c.s3cret","role":"admin"}`, `{"id":"9f2c","role":"admin","token":"REDACTED"}`},
+		{`[{"name":"git","webhookSecret":"s3cret"}]`, `[{"name":"git","webhookSecret":"REDACTED"}]`},
+		{"kind: MeshController\nregistry:\n  password: s3cret\n", `{"kind":"MeshController","registry":{"password":"REDACTED"}}`},
+		{"tenant pet not found", "tenant pet not found"},
+		{"", ""},
+	} {
+		if got := redact([]byte(c.body)); got != c.expected {
+			t.Errorf("expected %q redacted to %q, got %q", c.body, c.expected, got)
+		}
+	}
+}
@@ -20,18 +20,28 @@ package common
 import (
 	"fmt"
 	"os"
-
-	"github.com/fatih/color"
 )
 
-// ExitWithError exits with self-defined message not the one of cobra(such as usage).
+var exitHooks []func(code int)
+
+// OnExit registers the hook called with the exit code before emctl exits by ExitWithError.
+func OnExit(hook func(code int)) {
+	exitHooks = append(exitHooks, hook)
+}
+
+// ExitWithError exits with self-defined message not the one of cobra(such as usage),
+// the exit code is decided by the error, see ExitCode.
 func ExitWithError(err error) {
+	code := ExitCodeOK
 	if err != nil {
-		color.New(color.FgRed).Fprint(os.Stderr, "Error: ")
-		fmt.Fprintf(os.Stderr, "%s\n", err)
-		os.Exit(1)
+		Logger().Errorf("%s", err)
+		code = ExitCode(err)
 	}
-	os.Exit(0)
+
+	for _, hook := range exitHooks {
+		hook(code)
+	}
+	os.Exit(code)
 }
 
 // ExitWithErrorf wraps ExitWithError with format.
@@ -39,16 +49,19 @@ func ExitWithErrorf(format string, a ...interface{}) {
 	ExitWithError(fmt.Errorf(format, a...))
 }
 
+// ExitWithCodef exits with the message and the exit code.
+func ExitWithCodef(code int, format string, a ...interface{}) {
+	ExitWithError(CodeErrorf(code, format, a...))
+}
+
 // OutputErrorf outputs an error information
 func OutputErrorf(format string, a ...interface{}) {
-	color.New(color.FgRed).Fprintf(os.Stderr, "Error: ")
-	fmt.Fprintf(os.Stderr, format+"\n", a...)
+	Logger().Errorf(format, a...)
 }
 
 // OutputError outputs an error information
 func OutputError(err error) {
 	if err != nil {
-		color.New(color.FgRed).Fprint(os.Stderr, "Error: ")
-		fmt.Fprintf(os.Stderr, "%s\n", err)
+		Logger().Errorf("%s", err)
 	}
 }
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes of emctl, scripts could branch on them instead of error messages.
+const (
+	// ExitCodeOK means the command succeeded.
+	ExitCodeOK = 0
+	// ExitCodeGeneral means the command failed for other reasons.
+	ExitCodeGeneral = 1
+	// ExitCodeValidation means invalid arguments, flags or resources.
+	ExitCodeValidation = 3
+	// ExitCodeNotFound means the resource does not exist.
+	ExitCodeNotFound = 4
+	// ExitCodeConflict means the resource already exists or was changed concurrently.
+	ExitCodeConflict = 5
+	// ExitCodeUnreachable means the control plane or Kubernetes can't be reached.
+	ExitCodeUnreachable = 6
+	// ExitCodeTimeout means the operation didn't finish in time.
+	ExitCodeTimeout = 7
+	// ExitCodeForbidden means the request isn't authenticated or authorized.
+	ExitCodeForbidden = 8
+	// ExitCodeUnsupported means the control plane doesn't serve the API the command needs.
+	ExitCodeUnsupported = 9
+	// ExitCodeInterrupted means the command was interrupted by SIGINT or SIGTERM.
+	ExitCodeInterrupted = 130
+)
+
+// ExitCoder is an error carrying its exit code.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+type codeError struct {
+	code int
+	err  error
+}
+
+func (e *codeError) Error() string { return e.err.Error() }
+func (e *codeError) Unwrap() error { return e.err }
+func (e *codeError) ExitCode() int { return e.code }
+
+// CodeErrorf creates an error with the exit code.
+func CodeErrorf(code int, format string, a ...interface{}) error {
+	return &codeError{code: code, err: fmt.Errorf(format, a...)}
+}
+
+// WithCode attaches the exit code to the error, unless it carries
+// a more specific one already, e.g. a validation failure wrapped by
+// a general install failure keeps exiting with ExitCodeValidation.
+func WithCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	if ExitCode(err) != ExitCodeGeneral {
+		return err
+	}
+	return &codeError{code: code, err: err}
+}
+
+// ExitCode returns the exit code of the error. Besides errors carrying
+// codes, errors of the Kubernetes API and the network are recognized.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		return ExitCodeNotFound
+	case apierrors.IsAlreadyExists(err), apierrors.IsConflict(err):
+		return ExitCodeConflict
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return ExitCodeValidation
+	case apierrors.IsUnauthorized(err), apierrors.IsForbidden(err):
+		return ExitCodeForbidden
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ExitCodeUnreachable
+	}
+
+	return ExitCodeGeneral
+}
+
+// ExitCodeOf returns the exit code shared by all errors,
+// or ExitCodeGeneral if they differ.
+func ExitCodeOf(errs ...error) int {
+	code := ExitCodeOK
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		c := ExitCode(err)
+		switch code {
+		case ExitCodeOK:
+			code = c
+		case c:
+		default:
+			return ExitCodeGeneral
+		}
+	}
+	return code
+}
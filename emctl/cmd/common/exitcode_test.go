@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package common
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExitCode(t *testing.T) {
+	notFound := CodeErrorf(ExitCodeNotFound, "resource not found")
+	resource := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	for _, c := range []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitCodeOK},
+		{fmt.Errorf("oops"), ExitCodeGeneral},
+		{notFound, ExitCodeNotFound},
+		{errors.Wrap(notFound, "get tenant pet"), ExitCodeNotFound},
+		{fmt.Errorf("get tenant: %w", notFound), ExitCodeNotFound},
+		{WithCode(notFound, ExitCodeValidation), ExitCodeNotFound},
+		{WithCode(fmt.Errorf("bad flag"), ExitCodeValidation), ExitCodeValidation},
+		{apierrors.NewNotFound(resource, "easemesh-operator"), ExitCodeNotFound},
+		{apierrors.NewAlreadyExists(resource, "easemesh-operator"), ExitCodeConflict},
+		{apierrors.NewForbidden(resource, "easemesh-operator", fmt.Errorf("denied")), ExitCodeForbidden},
+		{errors.Wrap(&net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}, "get tenant"), ExitCodeUnreachable},
+	} {
+		if code := ExitCode(c.err); code != c.code {
+			t.Fatalf("error %v: expect exit code %d but got %d", c.err, c.code, code)
+		}
+	}
+
+	if code := ExitCodeOf(notFound, nil, errors.Wrap(notFound, "get")); code != ExitCodeNotFound {
+		t.Fatalf("expect shared exit code %d but got %d", ExitCodeNotFound, code)
+	}
+	if code := ExitCodeOf(notFound, fmt.Errorf("oops")); code != ExitCodeGeneral {
+		t.Fatalf("expect general exit code for different errors but got %d", code)
+	}
+}
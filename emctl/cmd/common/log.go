@@ -0,0 +1,220 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package common
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// LogFormatText is the human-readable log format.
+	LogFormatText = "text"
+	// LogFormatJSON logs one JSON object per line for machines, e.g. CI pipelines.
+	LogFormatJSON = "json"
+)
+
+// logLevels are levels of logs supported by --log-level.
+var logLevels = []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel}
+
+var logger = struct {
+	sync.Mutex
+	level     zap.AtomicLevel
+	format    string
+	verbosity int
+	stdout    io.Writer
+	stderr    io.Writer
+	// sugared is built by the settings above, and rebuilt once they change.
+	sugared *zap.SugaredLogger
+}{
+	level:  zap.NewAtomicLevelAt(zapcore.InfoLevel),
+	format: LogFormatText,
+	stdout: os.Stdout,
+	stderr: os.Stderr,
+}
+
+func init() {
+	// NOTE: Colors are disabled by the env NO_COLOR with any value, see https://no-color.org.
+	if os.Getenv("NO_COLOR") != "" {
+		color.NoColor = true
+	}
+}
+
+// SetLogLevel sets the minimal level of logs, one of debug, info, warn, error.
+func SetLogLevel(level string) error {
+	for _, l := range logLevels {
+		if strings.EqualFold(level, l.String()) {
+			logger.level.SetLevel(l)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %s (support debug, info, warn, error)", level)
+}
+
+// SetLogFormat sets the format of logs, text or json.
+func SetLogFormat(format string) error {
+	switch strings.ToLower(format) {
+	case LogFormatText, LogFormatJSON:
+		logger.Lock()
+		defer logger.Unlock()
+		logger.format = strings.ToLower(format)
+		logger.sugared = nil
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %s (support text, json)", format)
+	}
+}
+
+// RedirectInfoToStderr makes info logs in the text format go to stderr,
+// so that stdout is kept for machine-readable outputs of commands.
+func RedirectInfoToStderr() {
+	logger.Lock()
+	defer logger.Unlock()
+	logger.stdout = logger.stderr
+	logger.sugared = nil
+}
+
+// SetLogOutput sets writers of info logs in the text format and the others,
+// e.g. buffers of tests checking logs.
+func SetLogOutput(stdout, stderr io.Writer) {
+	logger.Lock()
+	defer logger.Unlock()
+	logger.stdout, logger.stderr = stdout, stderr
+	logger.sugared = nil
+}
+
+// SetVerbosity sets the verbosity, any positive verbosity enables debug logs.
+// Verbosity 1 logs requests to the control plane, 2 logs their bodies too.
+func SetVerbosity(verbosity int) {
+	logger.Lock()
+	defer logger.Unlock()
+	logger.verbosity = verbosity
+	if verbosity > 0 {
+		logger.level.SetLevel(zapcore.DebugLevel)
+	}
+}
+
+// Verbosity returns the verbosity.
+func Verbosity() int {
+	logger.Lock()
+	defer logger.Unlock()
+	return logger.verbosity
+}
+
+// Logger returns the logger of emctl. In the text format, fields added by
+// With are appended to messages, except info messages which are the
+// progress output of commands.
+func Logger() *zap.SugaredLogger {
+	logger.Lock()
+	defer logger.Unlock()
+
+	if logger.sugared == nil {
+		logger.sugared = newLogger().Sugar()
+	}
+	return logger.sugared
+}
+
+// Debugf logs at the debug level.
+func Debugf(format string, a ...interface{}) {
+	Logger().Debugf(format, a...)
+}
+
+// Infof logs at the info level.
+func Infof(format string, a ...interface{}) {
+	Logger().Infof(format, a...)
+}
+
+// Warnf logs at the warn level.
+func Warnf(format string, a ...interface{}) {
+	Logger().Warnf(format, a...)
+}
+
+func newLogger() *zap.Logger {
+	level := logger.level
+	stderr := zapcore.Lock(zapcore.AddSync(logger.stderr))
+
+	if logger.format == LogFormatJSON {
+		config := zap.NewProductionEncoderConfig()
+		config.TimeKey = "time"
+		config.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		// NOTE: Logs never go to stdout in json format, which is kept for outputs of commands.
+		return zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(config), stderr, level))
+	}
+
+	config := zapcore.EncoderConfig{
+		LevelKey:         "level",
+		MessageKey:       "msg",
+		EncodeLevel:      textLevelEncoder,
+		ConsoleSeparator: " ",
+	}
+	return zap.New(zapcore.NewTee(
+		&messageCore{
+			LevelEnabler: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return l == zapcore.InfoLevel && level.Enabled(l)
+			}),
+			out: zapcore.Lock(zapcore.AddSync(logger.stdout)),
+		},
+		zapcore.NewCore(zapcore.NewConsoleEncoder(config), stderr, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l != zapcore.InfoLevel && level.Enabled(l)
+		})),
+	))
+}
+
+func textLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch level {
+	case zapcore.DebugLevel:
+		enc.AppendString(color.New(color.Faint).Sprint("Debug:"))
+	case zapcore.WarnLevel:
+		enc.AppendString(color.New(color.FgYellow).Sprint("Warning:"))
+	default:
+		enc.AppendString(color.New(color.FgRed).Sprint("Error:"))
+	}
+}
+
+// messageCore writes messages only without fields, which is
+// for info logs in the text format.
+type messageCore struct {
+	zapcore.LevelEnabler
+	out zapcore.WriteSyncer
+}
+
+func (c *messageCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *messageCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *messageCore) Write(entry zapcore.Entry, _ []zapcore.Field) error {
+	_, err := fmt.Fprintln(c.out, entry.Message)
+	return err
+}
+
+func (c *messageCore) Sync() error {
+	return c.out.Sync()
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func captureLog(t *testing.T) (*bytes.Buffer, *bytes.Buffer) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	origin := logger.stdout
+	originErr := logger.stderr
+	logger.stdout, logger.stderr, logger.sugared = stdout, stderr, nil
+	t.Cleanup(func() {
+		logger.stdout, logger.stderr, logger.sugared = origin, originErr, nil
+		logger.format, logger.verbosity = LogFormatText, 0
+		logger.level.SetLevel(zapcore.InfoLevel)
+	})
+	return stdout, stderr
+}
+
+func TestLogText(t *testing.T) {
+	stdout, stderr := captureLog(t)
+
+	Debugf("hidden")
+	Logger().With("kind", "Tenant").Infof("%s/%s applied successfully", "Tenant", "pet")
+	Logger().With("kind", "Tenant").Warnf("careful")
+	if stdout.String() != "Tenant/pet applied successfully\n" {
+		t.Fatalf("info should go to stdout as it is, but got %q", stdout.String())
+	}
+	if strings.Contains(stderr.String(), "hidden") || !strings.Contains(stderr.String(), `careful {"kind": "Tenant"}`) {
+		t.Fatalf("expect warn without debug in stderr, but got %q", stderr.String())
+	}
+
+	SetVerbosity(1)
+	Debugf("shown")
+	if !strings.Contains(stderr.String(), "shown") || Verbosity() != 1 {
+		t.Fatalf("verbosity should enable debug logs, but got %q", stderr.String())
+	}
+}
+
+func TestLogJSON(t *testing.T) {
+	stdout, stderr := captureLog(t)
+
+	if err := SetLogFormat("JSON"); err != nil {
+		t.Fatalf("set log format failed: %v", err)
+	}
+	if err := SetLogLevel("warn"); err != nil {
+		t.Fatalf("set log level failed: %v", err)
+	}
+
+	Infof("hidden")
+	Logger().With("kind", "Tenant", "name", "pet").Errorf("apply failed")
+	if stdout.Len() != 0 {
+		t.Fatalf("json logs should not go to stdout, but got %q", stdout.String())
+	}
+
+	entry := map[string]interface{}{}
+	err := json.Unmarshal(stderr.Bytes(), &entry)
+	if err != nil {
+		t.Fatalf("expect one json entry but got %q: %v", stderr.String(), err)
+	}
+	if entry["level"] != "error" || entry["msg"] != "apply failed" || entry["kind"] != "Tenant" || entry["time"] == nil {
+		t.Fatalf("unexpected entry %v", entry)
+	}
+
+	if err := SetLogFormat("xml"); err == nil {
+		t.Fatalf("unknown format should fail")
+	}
+	if err := SetLogLevel("trace"); err == nil {
+		t.Fatalf("unknown level should fail")
+	}
+}
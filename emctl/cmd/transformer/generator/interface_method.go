@@ -336,6 +336,13 @@ func buildPutByContextStatement(info *buildInfo) func(string) (jen.Code, error)
 						jen.Id("args1").Dot("Name").Call(),
 					)))
 
+				conflictStmt := jen.If(jen.Id("statusCode").Op("==").Qual("net/http", "StatusConflict")).Block(
+					jen.Return(jen.Nil(), jen.Qual(errorsPkg, "Wrapf").Call(
+						jen.Id("StaleError"),
+						jen.Lit("patch "+resourceName+" %s"),
+						jen.Id("args1").Dot("Name").Call(),
+					)))
+
 				stmt2 := jen.If(jen.Id("statusCode").Op("<").Lit(300)).Op("&&").Id("statusCode").Op(">=").Lit(200).Block(
 					jen.Return(jen.Nil(), jen.Nil()),
 				)
@@ -346,6 +353,7 @@ func buildPutByContextStatement(info *buildInfo) func(string) (jen.Code, error)
 					),
 				)
 				g1.Add(stmt1)
+				g1.Add(conflictStmt)
 				g1.Add(stmt2)
 				g1.Add(returnStmt)
 			}),
@@ -17,7 +17,9 @@ require (
 	github.com/onsi/gomega v1.14.0
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.1.1
+	github.com/spf13/pflag v1.0.5
 	github.com/xeipuuv/gojsonschema v1.2.0
+	go.uber.org/zap v1.19.0
 	golang.org/x/text v0.3.7
 	google.golang.org/appengine v1.6.6 // indirect
 	google.golang.org/protobuf v1.27.1
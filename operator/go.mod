@@ -3,6 +3,7 @@ module github.com/megaease/easemesh/mesh-operator
 go 1.16
 
 require (
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/go-logr/logr v0.3.0
 	github.com/go-test/deep v1.0.7
 	github.com/iancoleman/strcase v0.1.3
@@ -12,6 +13,7 @@ require (
 	github.com/onsi/ginkgo v1.14.1
 	github.com/onsi/gomega v1.10.2
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.7.1
 	github.com/spf13/pflag v1.0.5
 	go.uber.org/zap v1.19.0
 	gopkg.in/check.v1 v1.0.0-20200902074654-038fdea0a05b // indirect
@@ -18,7 +18,10 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/pprof"
 	"os"
 	"time"
 
@@ -27,22 +30,33 @@ import (
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/yaml.v2"
 
+	"github.com/megaease/easemesh/mesh-operator/pkg/aggregator"
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
 	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
 	"github.com/megaease/easemesh/mesh-operator/pkg/base"
 	"github.com/megaease/easemesh/mesh-operator/pkg/controllers"
 	"github.com/megaease/easemesh/mesh-operator/pkg/hook"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshingress"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshservice"
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecarinjector"
+	"github.com/megaease/easemesh/mesh-operator/pkg/trafficpolicy"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,6 +75,9 @@ const (
 
 	// DefaultLog4jConfigName is the default log4j config file name.
 	DefaultLog4jConfigName = "easeagent-log4j2.xml"
+
+	// DefaultInterceptionImageName is the default image name of init containers setting up the traffic interception.
+	DefaultInterceptionImageName = "registry.k8s.io/build-image/debian-iptables:buster-v1.6.7"
 )
 
 var scheme = runtime.NewScheme()
@@ -69,6 +86,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(meshv1beta1.AddToScheme(scheme))
+	utilruntime.Must(meshv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -89,6 +107,36 @@ type ConfigSpec struct {
 
 	AgentInitializerImageName string `yaml:"agent-initializer-image-name" jsonschema:"required"`
 	SidecarImageName          string `yaml:"sidecar-image-name" jsonschema:"required"`
+
+	EnableGatewayAPI bool `yaml:"enable-gateway-api" jsonschema:"omitempty"`
+	EnableK8sIngress bool `yaml:"enable-k8s-ingress" jsonschema:"omitempty"`
+
+	EnableMeshControlPlane bool `yaml:"enable-mesh-control-plane" jsonschema:"omitempty"`
+	EnableSelfHeal         bool `yaml:"enable-self-heal" jsonschema:"omitempty"`
+
+	EnableServiceAutoRegistration  bool `yaml:"enable-service-auto-registration" jsonschema:"omitempty"`
+	EnableTrafficPolicyAnnotations bool `yaml:"enable-traffic-policy-annotations" jsonschema:"omitempty"`
+
+	EnableAPIAggregation bool   `yaml:"enable-api-aggregation" jsonschema:"omitempty"`
+	APIAggregationPort   uint16 `yaml:"api-aggregation-port" jsonschema:"omitempty"`
+
+	SidecarDNSCapture  bool   `yaml:"sidecar-dns-capture" jsonschema:"omitempty"`
+	SidecarDNSUpstream string `yaml:"sidecar-dns-upstream" jsonschema:"omitempty"`
+	ClusterDomain      string `yaml:"cluster-domain" jsonschema:"omitempty"`
+
+	SpringCloudConfigURI string `yaml:"spring-cloud-config-uri" jsonschema:"omitempty"`
+
+	EnablePprof bool `yaml:"enable-pprof" jsonschema:"omitempty"`
+
+	SidecarInjectionTemplate string `yaml:"sidecar-injection-template" jsonschema:"omitempty"`
+
+	SidecarDrainDuration             string `yaml:"sidecar-drain-duration" jsonschema:"omitempty"`
+	HoldApplicationUntilSidecarReady bool   `yaml:"hold-application-until-sidecar-ready" jsonschema:"omitempty"`
+	NativeSidecar                    bool   `yaml:"native-sidecar" jsonschema:"omitempty"`
+
+	TrafficInterception   string `yaml:"traffic-interception" jsonschema:"omitempty"`
+	InterceptionImageName string `yaml:"interception-image-name" jsonschema:"omitempty"`
+	InterceptionPorts     []int  `yaml:"interception-ports" jsonschema:"omitempty"`
 }
 
 func main() {
@@ -110,6 +158,26 @@ func main() {
 		certName             string
 		keyName              string
 		log4jConfigName      string
+		enableGatewayAPI     bool
+		enableK8sIngress     bool
+		enableControlPlane   bool
+		enableSelfHeal       bool
+		enableRegistration   bool
+		enableTrafficPolicy  bool
+		enableAggregation    bool
+		aggregationPort      uint16
+		sidecarDNSCapture    bool
+		sidecarDNSUpstream   string
+		clusterDomain        string
+		springCloudConfigURI string
+		enablePprof          bool
+		injectionTemplate    string
+		sidecarDrainDuration time.Duration
+		holdApplication      bool
+		nativeSidecar        bool
+		interception         string
+		interceptionImage    string
+		interceptionPorts    []int
 		//
 		agentInitializerImageName string
 	)
@@ -131,6 +199,28 @@ func main() {
 	pflag.StringVar(&certName, "cert-file", "cert.pem", "The TLS cert file name.")
 	pflag.StringVar(&keyName, "key-file", "key.pem", "The TLS key file name.")
 	pflag.Uint16Var(&webhookPort, "webhook-port", 9090, "Webhook port listening on.")
+	pflag.BoolVar(&enableGatewayAPI, "enable-gateway-api", false, "Translate Gateway API HTTPRoutes into mesh ingresses.")
+	pflag.BoolVar(&enableK8sIngress, "enable-k8s-ingress", false, "Translate Kubernetes Ingresses with ingressClassName easemesh into mesh ingresses.")
+	pflag.BoolVar(&enableControlPlane, "enable-mesh-control-plane", false, "Apply MeshControlPlanes to components of the EaseMesh installation.")
+	pflag.BoolVar(&enableAggregation, "enable-api-aggregation", false, "Serve mesh resources as an aggregated API of the Kubernetes API server.")
+	pflag.Uint16Var(&aggregationPort, "api-aggregation-port", 9443, "Port of the aggregated API listening on.")
+	pflag.BoolVar(&enableSelfHeal, "enable-self-heal", false, "Revert manual edits and deletions of objects applied by emctl install.")
+	pflag.BoolVar(&enableRegistration, "enable-service-auto-registration", false,
+		"Register Deployments labeled with mesh.megaease.com/enable=true as mesh services under the tenant named after their namespaces.")
+	pflag.BoolVar(&enableTrafficPolicy, "enable-traffic-policy-annotations", false,
+		"Convert timeout, retries and canary weight annotations of Deployments into mesh resources.")
+	pflag.BoolVar(&sidecarDNSCapture, "sidecar-dns-capture", false, "Make sidecars serve DNS for mesh services and external services.")
+	pflag.StringVar(&sidecarDNSUpstream, "sidecar-dns-upstream", "", "The nameserver sidecars forward unknown names to, default is the nameserver of the operator.")
+	pflag.StringVar(&clusterDomain, "cluster-domain", "cluster.local", "The DNS domain of the Kubernetes cluster.")
+	pflag.StringVar(&springCloudConfigURI, "spring-cloud-config-uri", "", "The Spring Cloud Config server which injected applications fetch config from.")
+	pflag.BoolVar(&enablePprof, "enable-pprof", false, "Serve the pprof endpoints under /debug/pprof/ on the metrics address.")
+	pflag.StringVar(&injectionTemplate, "sidecar-injection-template", "", "The yaml file of patches applied to pods injected with sidecars.")
+	pflag.DurationVar(&sidecarDrainDuration, "sidecar-drain-duration", 0, "How long applications keep running to finish in-flight requests on termination before they and then sidecars are terminated, zero disables the drain.")
+	pflag.BoolVar(&holdApplication, "hold-application-until-sidecar-ready", false, "Start application containers after sidecars are ready.")
+	pflag.BoolVar(&nativeSidecar, "native-sidecar", false, "Inject sidecars as native sidecars of Kubernetes 1.28+, which start before and stop after applications.")
+	pflag.StringVar(&interception, "traffic-interception", "", "Experimental, intercept outbound traffic of injected pods to sidecars (support iptables), empty leaves it to EaseAgent and DNS.")
+	pflag.StringVar(&interceptionImage, "interception-image-name", DefaultInterceptionImageName, "The image of init containers redirecting outbound traffic by iptables, which needs the shell and iptables.")
+	pflag.IntSliceVar(&interceptionPorts, "interception-ports", []int{80}, "Destination ports of outbound TCP traffic redirected to sidecars.")
 
 	pflag.Parse()
 
@@ -158,6 +248,41 @@ func main() {
 			agentInitializerImageName = spec.AgentInitializerImageName
 			sidecarImageName = spec.SidecarImageName
 			log4jConfigName = spec.Log4jConfigName
+			enableGatewayAPI = spec.EnableGatewayAPI
+			enableK8sIngress = spec.EnableK8sIngress
+			enableControlPlane = spec.EnableMeshControlPlane
+			enableSelfHeal = spec.EnableSelfHeal
+			enableRegistration = spec.EnableServiceAutoRegistration
+			enableTrafficPolicy = spec.EnableTrafficPolicyAnnotations
+			enableAggregation = spec.EnableAPIAggregation
+			if spec.APIAggregationPort != 0 {
+				aggregationPort = spec.APIAggregationPort
+			}
+			sidecarDNSCapture = spec.SidecarDNSCapture
+			sidecarDNSUpstream = spec.SidecarDNSUpstream
+			if spec.ClusterDomain != "" {
+				clusterDomain = spec.ClusterDomain
+			}
+			springCloudConfigURI = spec.SpringCloudConfigURI
+			enablePprof = spec.EnablePprof
+			injectionTemplate = spec.SidecarInjectionTemplate
+			if spec.SidecarDrainDuration != "" {
+				duration, err := time.ParseDuration(spec.SidecarDrainDuration)
+				if err != nil {
+					setupLog.Error(err, "invalid sidecar drain duration")
+					os.Exit(1)
+				}
+				sidecarDrainDuration = duration
+			}
+			holdApplication = spec.HoldApplicationUntilSidecarReady
+			nativeSidecar = spec.NativeSidecar
+			interception = spec.TrafficInterception
+			if spec.InterceptionImageName != "" {
+				interceptionImage = spec.InterceptionImageName
+			}
+			if len(spec.InterceptionPorts) != 0 {
+				interceptionPorts = spec.InterceptionPorts
+			}
 		})
 	}
 
@@ -168,12 +293,39 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "870093a3.megaease.com",
+		// NOTE: Only the leader runs controllers, while the webhook server
+		// runs in every replica, so all of them inject sidecars.
+		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if nativeSidecar {
+		supported, err := nativeSidecarSupported(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to check native sidecar support")
+			os.Exit(1)
+		}
+		if !supported {
+			setupLog.Info("native sidecars need Kubernetes 1.28+, inject sidecars as containers instead")
+			nativeSidecar = false
+		}
+	}
+
+	if interception != "" && interception != sidecarinjector.TrafficInterceptionIptables {
+		setupLog.Error(fmt.Errorf("unknown traffic interception %s", interception), "invalid traffic interception")
+		os.Exit(1)
+	}
+
+	if enablePprof {
+		if err := addPprofHandlers(mgr); err != nil {
+			setupLog.Error(err, "unable to set up pprof handlers")
+			os.Exit(1)
+		}
+	}
+
 	baseRuntime := base.Runtime{
 		Client:           mgr.GetClient(),
 		Scheme:           mgr.GetScheme(),
@@ -188,12 +340,33 @@ func main() {
 		APIAddr:         apiAddr,
 		ClusterJoinURLs: clusterJoinURLs,
 		ClusterName:     clusterName,
+
+		SidecarDNSCapture:  sidecarDNSCapture,
+		SidecarDNSUpstream: sidecarDNSUpstream,
+		ClusterDomain:      clusterDomain,
+
+		SpringCloudConfigURI: springCloudConfigURI,
+
+		SidecarInjectionTemplate: injectionTemplate,
+		SidecarDrainDuration:     sidecarDrainDuration,
+
+		HoldApplicationUntilSidecarReady: holdApplication,
+		NativeSidecar:                    nativeSidecar,
+
+		TrafficInterception:   interception,
+		InterceptionImageName: interceptionImage,
+		InterceptionPorts:     interceptionPorts,
+
+		ServiceAutoRegistration: enableRegistration,
 	}
 
 	// Create MeshDeploymentReconciler.
 	meshDeploymentRuntime := baseRuntime
 	meshDeploymentRuntime.Name = "MeshDeployment"
 	meshDeploymentRuntime.Log = ctrl.Log.WithName("controllers").WithName("MeshDeployment")
+	// NOTE: The controller updates Deployments in the Kubernetes API it built with,
+	// which can't mark init containers as native sidecars.
+	meshDeploymentRuntime.NativeSidecar = false
 	meshDeploymentReconciler := &controllers.MeshDeploymentReconciler{Runtime: &meshDeploymentRuntime}
 	err = meshDeploymentReconciler.SetupWithManager(mgr)
 	if err != nil {
@@ -201,10 +374,120 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Create HTTPRouteReconciler.
+	if enableGatewayAPI {
+		httpRouteRuntime := baseRuntime
+		httpRouteRuntime.Name = "HTTPRoute"
+		httpRouteRuntime.Log = ctrl.Log.WithName("controllers").WithName("HTTPRoute")
+		httpRouteRuntime.Recorder = mgr.GetEventRecorderFor("controller.HTTPRoute")
+		httpRouteReconciler := &controllers.HTTPRouteReconciler{
+			Runtime:       &httpRouteRuntime,
+			IngressClient: meshingress.NewClient(apiAddr),
+		}
+		err = httpRouteReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "create controller of HTTPRoute failed")
+			os.Exit(1)
+		}
+	}
+
+	// Create IngressReconciler.
+	if enableK8sIngress {
+		ingressRuntime := baseRuntime
+		ingressRuntime.Name = "Ingress"
+		ingressRuntime.Log = ctrl.Log.WithName("controllers").WithName("Ingress")
+		ingressRuntime.Recorder = mgr.GetEventRecorderFor("controller.Ingress")
+		ingressReconciler := &controllers.IngressReconciler{
+			Runtime:       &ingressRuntime,
+			IngressClient: meshingress.NewClient(apiAddr),
+		}
+		err = ingressReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "create controller of Ingress failed")
+			os.Exit(1)
+		}
+	}
+
+	// Create MeshControlPlaneReconciler.
+	if enableControlPlane {
+		controlPlaneRuntime := baseRuntime
+		controlPlaneRuntime.Name = "MeshControlPlane"
+		controlPlaneRuntime.Log = ctrl.Log.WithName("controllers").WithName("MeshControlPlane")
+		controlPlaneRuntime.Recorder = mgr.GetEventRecorderFor("controller.MeshControlPlane")
+		controlPlaneReconciler := &controllers.MeshControlPlaneReconciler{Runtime: &controlPlaneRuntime}
+		err = controlPlaneReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "create controller of MeshControlPlane failed")
+			os.Exit(1)
+		}
+	}
+
+	// Create SelfHealReconciler.
+	if enableSelfHeal {
+		selfHealRuntime := baseRuntime
+		selfHealRuntime.Name = "SelfHeal"
+		selfHealRuntime.Log = ctrl.Log.WithName("controllers").WithName("SelfHeal")
+		selfHealRuntime.Recorder = mgr.GetEventRecorderFor("controller.SelfHeal")
+		selfHealReconciler := &controllers.SelfHealReconciler{Runtime: &selfHealRuntime}
+		err = selfHealReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "create controller of SelfHeal failed")
+			os.Exit(1)
+		}
+	}
+
+	// Create ServiceRegistrationReconciler.
+	if enableRegistration {
+		registrationRuntime := baseRuntime
+		registrationRuntime.Name = "ServiceRegistration"
+		registrationRuntime.Log = ctrl.Log.WithName("controllers").WithName("ServiceRegistration")
+		registrationRuntime.Recorder = mgr.GetEventRecorderFor("controller.ServiceRegistration")
+		registrationReconciler := &controllers.ServiceRegistrationReconciler{
+			Runtime:       &registrationRuntime,
+			ServiceClient: meshservice.NewClient(apiAddr),
+		}
+		err = registrationReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "create controller of ServiceRegistration failed")
+			os.Exit(1)
+		}
+	}
+
+	// Create TrafficPolicyReconciler.
+	if enableTrafficPolicy {
+		trafficPolicyRuntime := baseRuntime
+		trafficPolicyRuntime.Name = "TrafficPolicy"
+		trafficPolicyRuntime.Log = ctrl.Log.WithName("controllers").WithName("TrafficPolicy")
+		trafficPolicyRuntime.Recorder = mgr.GetEventRecorderFor("controller.TrafficPolicy")
+		trafficPolicyReconciler := &controllers.TrafficPolicyReconciler{
+			Runtime:      &trafficPolicyRuntime,
+			PolicyClient: trafficpolicy.NewClient(apiAddr),
+		}
+		err = trafficPolicyReconciler.SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "create controller of TrafficPolicy failed")
+			os.Exit(1)
+		}
+	}
+
+	// Create InjectionEventReconciler.
+	// NOTE: It records events of pods as the webhook, which annotates decisions on them.
+	injectionEventRuntime := baseRuntime
+	injectionEventRuntime.Name = "InjectionEvent"
+	injectionEventRuntime.Log = ctrl.Log.WithName("controllers").WithName("InjectionEvent")
+	injectionEventRuntime.Recorder = mgr.GetEventRecorderFor("webhook.SidecarInjector")
+	injectionEventReconciler := &controllers.InjectionEventReconciler{Runtime: &injectionEventRuntime}
+	err = injectionEventReconciler.SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "create controller of InjectionEvent failed")
+		os.Exit(1)
+	}
+
 	// Create a webhook server.
 	webhookRuntime := baseRuntime
 	webhookRuntime.Name = "Webhook"
 	webhookRuntime.Log = ctrl.Log.WithName("webhook").WithName("mutate")
+	webhookRuntime.Recorder = mgr.GetEventRecorderFor("webhook.SidecarInjector")
 	webhookMutate := hook.NewMutateHook(&webhookRuntime)
 	webhookServer := &webhook.Server{
 		Port:     int(webhookPort),
@@ -214,12 +497,33 @@ func main() {
 	}
 
 	webhookServer.Register("/mutate", webhookMutate.Admission)
+	// NOTE: The API server converts MeshDeployments between versions
+	// through it, so old clients keep working after upgrades.
+	webhookServer.Register("/convert", &conversion.Webhook{})
 
 	if err := mgr.Add(webhookServer); err != nil {
 		setupLog.Error(err, "unable to set up webhook server")
 		os.Exit(1)
 	}
 
+	// Create the aggregated API server.
+	if enableAggregation {
+		aggregationServer := &aggregator.Server{
+			Port:     int(aggregationPort),
+			CertDir:  certDir,
+			CertName: certName,
+			KeyName:  keyName,
+			APIAddr:  apiAddr,
+			Reader:   mgr.GetAPIReader(),
+			Client:   mgr.GetClient(),
+			Log:      ctrl.Log.WithName("aggregator"),
+		}
+		if err := mgr.Add(aggregationServer); err != nil {
+			setupLog.Error(err, "unable to set up aggregated API server")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
@@ -238,6 +542,46 @@ func main() {
 	}
 }
 
+// nativeSidecarSupported reports whether the Kubernetes API server supports native sidecars,
+// which are alpha behind the SidecarContainers feature gate in 1.28 and enabled by default since 1.29.
+func nativeSidecarSupported(config *rest.Config) (bool, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := client.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, err
+	}
+
+	return serverVersion.AtLeast(version.MustParseGeneric("1.28")), nil
+}
+
+// addPprofHandlers serves the pprof endpoints alongside the metrics endpoint,
+// so profiling needs no extra port to be exposed.
+func addPprofHandlers(mgr ctrl.Manager) error {
+	handlers := map[string]http.HandlerFunc{
+		"/debug/pprof/":        pprof.Index,
+		"/debug/pprof/cmdline": pprof.Cmdline,
+		"/debug/pprof/profile": pprof.Profile,
+		"/debug/pprof/symbol":  pprof.Symbol,
+		"/debug/pprof/trace":   pprof.Trace,
+	}
+	for path, handler := range handlers {
+		if err := mgr.AddMetricsExtraHandler(path, handler); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func loggerEncoderConfig() zapcore.EncoderConfig {
 	const RFC3339Milli = "2006-01-02T15:04:05.999Z07:00"
 
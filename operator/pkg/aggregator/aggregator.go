@@ -0,0 +1,339 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package aggregator serves mesh resources of the EaseMesh control plane
+// as an aggregated API of the Kubernetes API server, so they're accessible
+// by kubectl, e.g. kubectl get meshservices.
+package aggregator
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// GroupName is the group of the aggregated API, it differs from the group
+	// of the CRDs since a group version can't be served by both.
+	GroupName = "admin.mesh.megaease.com"
+	// Version is the version of the aggregated API.
+	Version = "v1alpha1"
+
+	groupVersion = GroupName + "/" + Version
+	apiPrefix    = "/apis/" + groupVersion
+
+	adminRequestTimeout = 10 * time.Second
+)
+
+type (
+	// resource is a kind of mesh resources served read-only.
+	resource struct {
+		name         string
+		singularName string
+		kind         string
+		// adminPath is the path of the resources in the admin API of the control plane.
+		adminPath string
+	}
+
+	// Server is the aggregated API server, it authenticates requests proxied
+	// by the Kubernetes API server and delegates authorization to it.
+	Server struct {
+		Port     int
+		CertDir  string
+		CertName string
+		KeyName  string
+
+		// APIAddr is the admin API address of the control plane.
+		APIAddr string
+		// Reader reads the request header config without the cache.
+		Reader client.Reader
+		// Client creates SubjectAccessReviews.
+		Client client.Client
+		Log    logr.Logger
+	}
+
+	handler struct {
+		apiAddr      string
+		client       *http.Client
+		log          logr.Logger
+		authenticate func(r *http.Request) (*userInfo, error)
+		authorize    func(ctx context.Context, user *userInfo, verb string, res *resource, name string) (bool, string, error)
+	}
+)
+
+var resources = []*resource{
+	{name: "meshservices", singularName: "meshservice", kind: "MeshService", adminPath: "/apis/v1/mesh/services"},
+	{name: "meshtenants", singularName: "meshtenant", kind: "MeshTenant", adminPath: "/apis/v1/mesh/tenants"},
+	{name: "meshingresses", singularName: "meshingress", kind: "MeshIngress", adminPath: "/apis/v1/mesh/ingresses"},
+	{name: "meshexternalservices", singularName: "meshexternalservice", kind: "MeshExternalService", adminPath: "/apis/v1/mesh/externalservices"},
+}
+
+// Start serves the aggregated API until the context is done.
+func (s *Server) Start(ctx context.Context) error {
+	config, err := loadRequestHeaderConfig(ctx, s.Reader)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(filepath.Join(s.CertDir, s.CertName), filepath.Join(s.CertDir, s.KeyName))
+	if err != nil {
+		return errors.Wrap(err, "load serving certificate")
+	}
+
+	h := &handler{
+		apiAddr:      s.APIAddr,
+		client:       &http.Client{Timeout: adminRequestTimeout},
+		log:          s.Log,
+		authenticate: config.authenticate,
+		authorize:    s.authorize,
+	}
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.Port),
+		Handler: h,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			// NOTE: Client certificates are verified against the request header CA
+			// while authenticating, since only proxied requests carry them.
+			ClientAuth: tls.RequestClientCert,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	s.Log.Info("serving aggregated API", "port", s.Port, "groupVersion", groupVersion)
+	err = server.ListenAndServeTLS("", "")
+	if err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "serve aggregated API")
+	}
+	return nil
+}
+
+// NeedLeaderElection makes every replica of the operator serve the API.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authenticate(r)
+	if err != nil {
+		h.log.Info("unauthenticated request", "path", r.URL.Path, "reason", err.Error())
+		writeStatus(w, apierrors.NewUnauthorized(err.Error()))
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == apiPrefix {
+		writeJSON(w, http.StatusOK, discovery())
+		return
+	}
+	if !strings.HasPrefix(path, apiPrefix+"/") {
+		writeStatus(w, apierrors.NewNotFound(schema.GroupResource{}, path))
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, apiPrefix+"/"), "/")
+	res := findResource(parts[0])
+	if res == nil || len(parts) > 2 {
+		writeStatus(w, apierrors.NewNotFound(schema.GroupResource{Group: GroupName, Resource: parts[0]}, ""))
+		return
+	}
+	gr := schema.GroupResource{Group: GroupName, Resource: res.name}
+
+	verb, name := "list", ""
+	if len(parts) == 2 {
+		verb, name = "get", parts[1]
+	}
+	watch := r.URL.Query().Get("watch")
+	if r.Method != http.MethodGet || watch == "true" || watch == "1" {
+		writeStatus(w, apierrors.NewMethodNotSupported(gr, strings.ToLower(r.Method)))
+		return
+	}
+
+	allowed, reason, err := h.authorize(r.Context(), user, verb, res, name)
+	if err != nil {
+		h.log.Error(err, "authorize request", "user", user.name, "verb", verb, "resource", res.name)
+		writeStatus(w, apierrors.NewInternalError(err))
+		return
+	}
+	if !allowed {
+		writeStatus(w, apierrors.NewForbidden(gr, name,
+			errors.Errorf("user %q cannot %s resource %q in API group %q: %s", user.name, verb, res.name, GroupName, reason)))
+		return
+	}
+
+	if name == "" {
+		h.list(w, r, res)
+	} else {
+		h.get(w, r, res, name)
+	}
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request, res *resource) {
+	items := []map[string]interface{}{}
+	statusCode, err := h.callAdmin(r.Context(), res.adminPath, &items)
+	if err != nil && statusCode != http.StatusNotFound {
+		h.log.Error(err, "list mesh resources", "resource", res.name)
+		writeStatus(w, apierrors.NewInternalError(err))
+		return
+	}
+
+	objects := []interface{}{}
+	for _, item := range items {
+		obj, err := toObject(res, item)
+		if err != nil {
+			h.log.Error(err, "convert mesh resource", "resource", res.name)
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"apiVersion": groupVersion,
+		"kind":       res.kind + "List",
+		"metadata":   map[string]interface{}{},
+		"items":      objects,
+	})
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request, res *resource, name string) {
+	gr := schema.GroupResource{Group: GroupName, Resource: res.name}
+
+	item := map[string]interface{}{}
+	statusCode, err := h.callAdmin(r.Context(), res.adminPath+"/"+url.PathEscape(name), &item)
+	if statusCode == http.StatusNotFound {
+		writeStatus(w, apierrors.NewNotFound(gr, name))
+		return
+	}
+	if err != nil {
+		h.log.Error(err, "get mesh resource", "resource", res.name, "name", name)
+		writeStatus(w, apierrors.NewInternalError(err))
+		return
+	}
+
+	obj, err := toObject(res, item)
+	if err != nil {
+		writeStatus(w, apierrors.NewInternalError(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, obj)
+}
+
+// callAdmin gets the path of the admin API, the status code is returned even if it failed.
+func (h *handler) callAdmin(ctx context.Context, path string, result interface{}) (int, error) {
+	u := fmt.Sprintf("http://%s%s", h.apiAddr, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "new request GET %s", u)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "call GET %s", u)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrapf(err, "read body of GET %s", u)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.Errorf("call GET %s failed, return statuscode %d text %s", u, resp.StatusCode, body)
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return resp.StatusCode, errors.Wrapf(err, "unmarshal body of GET %s", u)
+	}
+	return resp.StatusCode, nil
+}
+
+// toObject wraps a mesh resource as a Kubernetes object, the mesh resource is its spec.
+func toObject(res *resource, item map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := item["name"].(string)
+	if name == "" {
+		return nil, errors.Errorf("%s without name", res.singularName)
+	}
+
+	return map[string]interface{}{
+		"apiVersion": groupVersion,
+		"kind":       res.kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       item,
+	}, nil
+}
+
+func discovery() *metav1.APIResourceList {
+	list := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: groupVersion,
+	}
+	for _, res := range resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:         res.name,
+			SingularName: res.singularName,
+			Namespaced:   false,
+			Kind:         res.kind,
+			Verbs:        metav1.Verbs{"get", "list"},
+			Categories:   []string{"easemesh"},
+		})
+	}
+	return list
+}
+
+func findResource(name string) *resource {
+	for _, res := range resources {
+		if res.name == name || res.singularName == name {
+			return res
+		}
+	}
+	return nil
+}
+
+func writeStatus(w http.ResponseWriter, err *apierrors.StatusError) {
+	status := err.ErrStatus
+	status.Kind, status.APIVersion = "Status", "v1"
+	writeJSON(w, int(status.Code), &status)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
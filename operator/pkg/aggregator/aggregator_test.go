@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func newTestHandler(t *testing.T, allowed bool) *handler {
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/v1/mesh/services":
+			w.Write([]byte(`[{"name": "order", "registerTenant": "shop"}, {"name": "delivery"}]`))
+		case "/apis/v1/mesh/services/order":
+			w.Write([]byte(`{"name": "order", "registerTenant": "shop"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(controlPlane.Close)
+
+	return &handler{
+		apiAddr: strings.TrimPrefix(controlPlane.URL, "http://"),
+		client:  controlPlane.Client(),
+		log:     zap.New(),
+		authenticate: func(r *http.Request) (*userInfo, error) {
+			return &userInfo{name: "alice"}, nil
+		},
+		authorize: func(ctx context.Context, user *userInfo, verb string, res *resource, name string) (bool, string, error) {
+			return allowed, "denied by test", nil
+		},
+	}
+}
+
+func serve(h http.Handler, method, path string) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+	body := map[string]interface{}{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body
+}
+
+func TestHandler(t *testing.T) {
+	h := newTestHandler(t, true)
+
+	code, body := serve(h, http.MethodGet, apiPrefix)
+	if code != http.StatusOK || body["kind"] != "APIResourceList" || len(body["resources"].([]interface{})) != len(resources) {
+		t.Fatalf("unexpected discovery %d %v", code, body)
+	}
+
+	code, body = serve(h, http.MethodGet, apiPrefix+"/meshservices")
+	if code != http.StatusOK || body["kind"] != "MeshServiceList" || len(body["items"].([]interface{})) != 2 {
+		t.Fatalf("unexpected list %d %v", code, body)
+	}
+
+	code, body = serve(h, http.MethodGet, apiPrefix+"/meshservices/order")
+	if code != http.StatusOK || body["kind"] != "MeshService" {
+		t.Fatalf("unexpected get %d %v", code, body)
+	}
+	if name := body["metadata"].(map[string]interface{})["name"]; name != "order" {
+		t.Errorf("unexpected name %v", name)
+	}
+	if tenant := body["spec"].(map[string]interface{})["registerTenant"]; tenant != "shop" {
+		t.Errorf("unexpected spec %v", body["spec"])
+	}
+
+	code, body = serve(h, http.MethodGet, apiPrefix+"/meshservices/payment")
+	if code != http.StatusNotFound || body["reason"] != "NotFound" {
+		t.Errorf("unexpected get of missing service %d %v", code, body)
+	}
+
+	code, _ = serve(h, http.MethodGet, apiPrefix+"/meshtenants")
+	if code != http.StatusOK {
+		t.Errorf("list without resources should be empty, got %d", code)
+	}
+
+	code, _ = serve(h, http.MethodGet, apiPrefix+"/deployments")
+	if code != http.StatusNotFound {
+		t.Errorf("unknown resource should be not found, got %d", code)
+	}
+
+	for _, c := range [][2]string{
+		{http.MethodDelete, apiPrefix + "/meshservices/order"},
+		{http.MethodGet, apiPrefix + "/meshservices?watch=true"},
+	} {
+		code, body = serve(h, c[0], c[1])
+		if code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s should not be supported, got %d %v", c[0], c[1], code, body)
+		}
+	}
+
+	code, body = serve(newTestHandler(t, false), http.MethodGet, apiPrefix+"/meshservices")
+	if code != http.StatusForbidden || body["reason"] != "Forbidden" {
+		t.Errorf("unexpected forbidden list %d %v", code, body)
+	}
+}
+
+func newCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate failed: %v", err)
+	}
+	return cert, key
+}
+
+func TestAuthenticate(t *testing.T) {
+	ca, caKey := newCert(t, "front-proxy-ca", nil, nil)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})
+
+	config, err := parseRequestHeaderConfig(map[string]string{
+		requestHeaderClientCAKey:            string(caPEM),
+		requestHeaderAllowedNamesKey:        `["front-proxy-client"]`,
+		requestHeaderUsernameHeadersKey:     `["X-Remote-User"]`,
+		requestHeaderGroupHeadersKey:        `["X-Remote-Group"]`,
+		requestHeaderExtraHeaderPrefixesKey: `["X-Remote-Extra-"]`,
+	})
+	if err != nil {
+		t.Fatalf("parse request header config failed: %v", err)
+	}
+
+	newRequest := func(certs ...*x509.Certificate) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, apiPrefix, nil)
+		r.TLS = &tls.ConnectionState{PeerCertificates: certs}
+		r.Header.Set("X-Remote-User", "alice")
+		r.Header.Add("X-Remote-Group", "system:authenticated")
+		r.Header.Add("X-Remote-Group", "dev")
+		r.Header.Set("X-Remote-Extra-Scopes", "view")
+		return r
+	}
+
+	proxy, _ := newCert(t, "front-proxy-client", ca, caKey)
+	user, err := config.authenticate(newRequest(proxy))
+	if err != nil {
+		t.Fatalf("authenticate failed: %v", err)
+	}
+	if user.name != "alice" || len(user.groups) != 2 || user.extra["scopes"][0] != "view" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	other, _ := newCert(t, "other-client", ca, caKey)
+	if _, err := config.authenticate(newRequest(other)); err == nil {
+		t.Errorf("expected client certificate not allowed")
+	}
+
+	selfSigned, _ := newCert(t, "front-proxy-client", nil, nil)
+	if _, err := config.authenticate(newRequest(selfSigned)); err == nil {
+		t.Errorf("expected client certificate not signed by the CA rejected")
+	}
+
+	if _, err := config.authenticate(newRequest()); err == nil {
+		t.Errorf("expected request without client certificate rejected")
+	}
+
+	if _, err := parseRequestHeaderConfig(map[string]string{}); err == nil {
+		t.Errorf("expected error without request header CA")
+	}
+}
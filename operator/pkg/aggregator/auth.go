@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NOTE: The API server publishes how it proxies requests to aggregated APIs
+// in the ConfigMap, which is readable by the Role extension-apiserver-authentication-reader.
+const (
+	authenticationConfigMapNamespace = "kube-system"
+	authenticationConfigMapName      = "extension-apiserver-authentication"
+
+	requestHeaderClientCAKey            = "requestheader-client-ca-file"
+	requestHeaderAllowedNamesKey        = "requestheader-allowed-names"
+	requestHeaderUsernameHeadersKey     = "requestheader-username-headers"
+	requestHeaderGroupHeadersKey        = "requestheader-group-headers"
+	requestHeaderExtraHeaderPrefixesKey = "requestheader-extra-headers-prefix"
+)
+
+type (
+	// requestHeaderConfig is the config of the API server proxying requests
+	// with the authenticated user in headers.
+	requestHeaderConfig struct {
+		clientCA            *x509.CertPool
+		allowedNames        []string
+		usernameHeaders     []string
+		groupHeaders        []string
+		extraHeaderPrefixes []string
+	}
+
+	// userInfo is the user authenticated by the API server.
+	userInfo struct {
+		name   string
+		groups []string
+		extra  map[string][]string
+	}
+)
+
+func loadRequestHeaderConfig(ctx context.Context, reader client.Reader) (*requestHeaderConfig, error) {
+	configMap := &v1.ConfigMap{}
+	key := types.NamespacedName{Namespace: authenticationConfigMapNamespace, Name: authenticationConfigMapName}
+	err := reader.Get(ctx, key, configMap)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get configmap %s", key)
+	}
+
+	return parseRequestHeaderConfig(configMap.Data)
+}
+
+func parseRequestHeaderConfig(data map[string]string) (*requestHeaderConfig, error) {
+	clientCA := x509.NewCertPool()
+	if !clientCA.AppendCertsFromPEM([]byte(data[requestHeaderClientCAKey])) {
+		return nil, errors.Errorf("no certificate found in %s, the API server doesn't enable the aggregation layer",
+			requestHeaderClientCAKey)
+	}
+
+	config := &requestHeaderConfig{clientCA: clientCA}
+	for key, value := range map[string]*[]string{
+		requestHeaderAllowedNamesKey:        &config.allowedNames,
+		requestHeaderUsernameHeadersKey:     &config.usernameHeaders,
+		requestHeaderGroupHeadersKey:        &config.groupHeaders,
+		requestHeaderExtraHeaderPrefixesKey: &config.extraHeaderPrefixes,
+	} {
+		if data[key] == "" {
+			continue
+		}
+		err := json.Unmarshal([]byte(data[key]), value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unmarshal %s", key)
+		}
+	}
+	if len(config.usernameHeaders) == 0 {
+		return nil, errors.Errorf("%s is empty", requestHeaderUsernameHeadersKey)
+	}
+
+	return config, nil
+}
+
+// authenticate trusts the user in headers only if the request is proxied
+// by the API server, whose client certificate is signed by the request header CA.
+func (c *requestHeaderConfig) authenticate(r *http.Request) (*userInfo, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, errors.New("no client certificate")
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range r.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         c.clientCA,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "verify client certificate")
+	}
+	if len(c.allowedNames) != 0 && !contains(c.allowedNames, cert.Subject.CommonName) {
+		return nil, errors.Errorf("client certificate %s is not allowed", cert.Subject.CommonName)
+	}
+
+	user := &userInfo{extra: map[string][]string{}}
+	for _, header := range c.usernameHeaders {
+		if user.name = r.Header.Get(header); user.name != "" {
+			break
+		}
+	}
+	if user.name == "" {
+		return nil, errors.New("no user in request headers")
+	}
+	for _, header := range c.groupHeaders {
+		user.groups = append(user.groups, r.Header.Values(header)...)
+	}
+	for header, values := range r.Header {
+		for _, prefix := range c.extraHeaderPrefixes {
+			if strings.HasPrefix(strings.ToLower(header), strings.ToLower(prefix)) {
+				key := strings.ToLower(header[len(prefix):])
+				user.extra[key] = append(user.extra[key], values...)
+			}
+		}
+	}
+
+	return user, nil
+}
+
+// authorize delegates the authorization to the API server by SubjectAccessReview.
+func (s *Server) authorize(ctx context.Context, user *userInfo, verb string, res *resource, name string) (bool, string, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, values := range user.extra {
+		extra[key] = values
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.name,
+			Groups: user.groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     verb,
+				Group:    GroupName,
+				Version:  Version,
+				Resource: res.name,
+				Name:     name,
+			},
+		},
+	}
+	err := s.Client.Create(ctx, review)
+	if err != nil {
+		return false, "", errors.Wrap(err, "create SubjectAccessReview")
+	}
+
+	return review.Status.Allowed, review.Status.Reason, nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
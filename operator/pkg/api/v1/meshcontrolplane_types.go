@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MeshControlPlanePhase is the phase of the EaseMesh installation.
+type MeshControlPlanePhase string
+
+const (
+	// MeshControlPlanePhaseProgressing means components are rolling out the spec.
+	MeshControlPlanePhaseProgressing MeshControlPlanePhase = "Progressing"
+	// MeshControlPlanePhaseReady means all components run the spec.
+	MeshControlPlanePhaseReady MeshControlPlanePhase = "Ready"
+	// MeshControlPlanePhaseFailed means the spec can't be applied.
+	MeshControlPlanePhaseFailed MeshControlPlanePhase = "Failed"
+)
+
+// ComponentSpec is the desired state of a component of the EaseMesh.
+type ComponentSpec struct {
+	// Replicas is the number of desired pods, empty means unchanged.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Image is the image of the component without the registry,
+	// e.g. megaease/easegress:easemesh, empty means unchanged.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+}
+
+// MeshControlPlaneSpec defines the desired state of MeshControlPlane
+type MeshControlPlaneSpec struct {
+	// ImageRegistryURL is the registry of images of all components.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="docker.io"
+	ImageRegistryURL string `json:"imageRegistryURL,omitempty"`
+
+	// ControlPlane is the Easegress cluster of the control plane.
+	// +kubebuilder:validation:Optional
+	ControlPlane ComponentSpec `json:"controlPlane,omitempty"`
+
+	// IngressController is the mesh ingress controller.
+	// +kubebuilder:validation:Optional
+	IngressController ComponentSpec `json:"ingressController,omitempty"`
+
+	// Operator is the mesh operator itself.
+	// +kubebuilder:validation:Optional
+	Operator ComponentSpec `json:"operator,omitempty"`
+
+	// AddOns are the add-ons installed by emctl install.
+	// +kubebuilder:validation:Optional
+	AddOns []string `json:"addOns,omitempty"`
+}
+
+// ComponentStatus is the observed state of a component of the EaseMesh.
+type ComponentStatus struct {
+	// Name is the name of the workload of the component.
+	Name string `json:"name"`
+	// Image is the running image of the component.
+	Image string `json:"image,omitempty"`
+	// Replicas is the number of desired pods.
+	Replicas int32 `json:"replicas"`
+	// ReadyReplicas is the number of ready pods.
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// Message tells why the component doesn't run the spec.
+	Message string `json:"message,omitempty"`
+}
+
+// MeshControlPlaneStatus defines the observed state of MeshControlPlane
+type MeshControlPlaneStatus struct {
+	// ObservedGeneration is the generation of the spec applied lastly.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is the phase of the installation.
+	Phase MeshControlPlanePhase `json:"phase,omitempty"`
+	// Components are states of components of the installation.
+	Components []ComponentStatus `json:"components,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=meshcontrolplanes,scope=Namespaced,shortName=mcp
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MeshControlPlane is the Schema for the meshcontrolplanes API, it
+// describes the whole EaseMesh installation in its namespace.
+type MeshControlPlane struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MeshControlPlaneSpec   `json:"spec,omitempty"`
+	Status MeshControlPlaneStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MeshControlPlaneList contains a list of MeshControlPlane
+type MeshControlPlaneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeshControlPlane `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MeshControlPlane{}, &MeshControlPlaneList{})
+}
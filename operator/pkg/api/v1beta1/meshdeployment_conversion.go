@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"math"
+
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = &MeshDeployment{}
+
+// ConvertTo converts the MeshDeployment to the hub version v1.
+func (m *MeshDeployment) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*meshv1.MeshDeployment)
+	if !ok {
+		return errors.Errorf("can't convert MeshDeployment to %T", hub)
+	}
+
+	dst.ObjectMeta = m.ObjectMeta
+	dst.Spec.Service = meshv1.ServiceSpec{
+		Name:             m.Spec.Service.Name,
+		AppContainerName: m.Spec.Service.AppContainerName,
+		Labels:           m.Spec.Service.Labels,
+		AliveProbeURL:    m.Spec.Service.AliveProbeURL,
+		ApplicationPort:  int32(m.Spec.Service.ApplicationPort),
+	}
+	dst.Spec.Deploy.DeploymentSpec = m.Spec.Deploy.DeploymentSpec
+
+	return nil
+}
+
+// ConvertFrom converts the hub version v1 to the MeshDeployment.
+func (m *MeshDeployment) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*meshv1.MeshDeployment)
+	if !ok {
+		return errors.Errorf("can't convert %T to MeshDeployment", hub)
+	}
+
+	port := src.Spec.Service.ApplicationPort
+	if port < 0 || port > math.MaxUint16 {
+		return errors.Errorf("applicationPort %d is out of range", port)
+	}
+
+	m.ObjectMeta = src.ObjectMeta
+	m.Spec.Service = ServiceSpec{
+		Name:             src.Spec.Service.Name,
+		AppContainerName: src.Spec.Service.AppContainerName,
+		Labels:           src.Spec.Service.Labels,
+		AliveProbeURL:    src.Spec.Service.AliveProbeURL,
+		ApplicationPort:  uint16(port),
+	}
+	m.Spec.Deploy.DeploymentSpec = src.Spec.Deploy.DeploymentSpec
+
+	return nil
+}
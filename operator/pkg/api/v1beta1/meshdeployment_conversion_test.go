@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConversion(t *testing.T) {
+	src := &MeshDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "order", Namespace: "pet"},
+		Spec: MeshDeploymentSpec{
+			Service: ServiceSpec{
+				Name:            "order",
+				Labels:          map[string]string{"version": "canary"},
+				AliveProbeURL:   "http://localhost:9900/health",
+				ApplicationPort: 8080,
+			},
+		},
+	}
+
+	hub := &meshv1.MeshDeployment{}
+	err := src.ConvertTo(hub)
+	if err != nil {
+		t.Fatalf("convert to v1 failed: %v", err)
+	}
+	if hub.Name != "order" || hub.Spec.Service.ApplicationPort != 8080 {
+		t.Fatalf("unexpected v1 MeshDeployment %+v", hub)
+	}
+
+	dst := &MeshDeployment{}
+	err = dst.ConvertFrom(hub)
+	if err != nil {
+		t.Fatalf("convert from v1 failed: %v", err)
+	}
+	if !reflect.DeepEqual(src, dst) {
+		t.Fatalf("round trip should keep the MeshDeployment, expected %+v, got %+v", src, dst)
+	}
+
+	hub.Spec.Service.ApplicationPort = 70000
+	err = dst.ConvertFrom(hub)
+	if err == nil {
+		t.Fatalf("out of range applicationPort should fail the conversion")
+	}
+}
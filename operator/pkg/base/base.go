@@ -18,6 +18,8 @@
 package base
 
 import (
+	"time"
+
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -43,5 +45,40 @@ type (
 		APIAddr         string
 		ClusterJoinURLs []string
 		ClusterName     string
+
+		// SidecarDNSCapture makes sidecars serve DNS of the injected pods.
+		SidecarDNSCapture bool
+		// SidecarDNSUpstream is the nameserver which sidecars forward unknown names to.
+		SidecarDNSUpstream string
+		// ClusterDomain is the DNS domain of the Kubernetes cluster.
+		ClusterDomain string
+
+		// SpringCloudConfigURI is the Spring Cloud Config server which injected applications fetch config from.
+		SpringCloudConfigURI string
+
+		// SidecarInjectionTemplate is the file of patches applied to injected pods.
+		SidecarInjectionTemplate string
+
+		// SidecarDrainDuration is how long applications keep running to finish
+		// in-flight requests on termination, sidecars outlive them, zero disables the drain.
+		SidecarDrainDuration time.Duration
+
+		// HoldApplicationUntilSidecarReady makes applications start after sidecars are ready.
+		HoldApplicationUntilSidecarReady bool
+
+		// NativeSidecar makes sidecars native sidecars of Kubernetes, which are init containers
+		// started before applications and terminated after them.
+		NativeSidecar bool
+
+		// TrafficInterception intercepts outbound traffic of injected pods, empty leaves it to EaseAgent and DNS.
+		TrafficInterception string
+		// InterceptionImageName is the image of init containers setting up iptables rules of the interception.
+		InterceptionImageName string
+		// InterceptionPorts are destination ports of outbound traffic redirected to sidecars.
+		InterceptionPorts []int
+
+		// ServiceAutoRegistration makes Deployments labeled with mesh.megaease.com/enable=true
+		// injected and registered as mesh services named after them.
+		ServiceAutoRegistration bool
 	}
 )
@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
 	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
 
 	. "github.com/onsi/ginkgo"
@@ -67,6 +68,8 @@ var _ = BeforeSuite(func() {
 
 	err = meshv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
+	err = meshv1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
 
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshingress"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// gatewayResyncPeriod is the period to re-translate HTTPRoutes, since changes
+// of Gateways (e.g. switching gateway class) don't trigger HTTPRoute reconciling.
+const gatewayResyncPeriod = 5 * time.Minute
+
+// HTTPRouteReconciler translates Gateway API HTTPRoutes attached to
+// EaseMesh Gateways into mesh ingresses.
+type HTTPRouteReconciler struct {
+	*base.Runtime
+	IngressClient meshingress.Client
+}
+
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways;httproutes;gatewayclasses,verbs=get;list;watch
+
+// Reconcile reconciles HTTPRoute.
+func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ingressName := meshingress.HTTPRouteIngressName(req.Namespace, req.Name)
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(meshingress.HTTPRouteGVK)
+	err := r.Client.Get(ctx, req.NamespacedName, u)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("HTTPRoute not found, delete its mesh ingress", "id", req.NamespacedName, "ingress", ingressName)
+			return reconcile.Result{}, r.IngressClient.Delete(ctx, ingressName)
+		}
+		r.Log.Error(err, "get HTTPRoute", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	route := &meshingress.HTTPRoute{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, route)
+	if err != nil {
+		r.Log.Error(err, "convert HTTPRoute", "id", req.NamespacedName)
+		r.Recorder.Eventf(u, v1.EventTypeWarning, reasonValidationFailed, "invalid HTTPRoute: %v", err)
+		return reconcile.Result{}, nil
+	}
+
+	attached, err := r.attachedToMeshGateway(ctx, req.Namespace, route)
+	if err != nil {
+		r.Recorder.Eventf(u, v1.EventTypeWarning, reasonReconcileFailed, "%v", err)
+		return reconcile.Result{}, err
+	}
+
+	if !attached {
+		// NOTE: The HTTPRoute may be detached from EaseMesh Gateway.
+		return reconcile.Result{RequeueAfter: gatewayResyncPeriod}, r.IngressClient.Delete(ctx, ingressName)
+	}
+
+	r.Log.Info("syncing HTTPRoute", "id", req.NamespacedName, "ingress", ingressName)
+	ingress := meshingress.FromHTTPRoute(ingressName, route)
+	err = r.IngressClient.Apply(ctx, ingress)
+	if err != nil {
+		r.Log.Error(err, "apply mesh ingress", "id", req.NamespacedName, "ingress", ingressName)
+		metrics.RecordError(r.Name, metrics.OperationApplyMeshIngress)
+		r.Recorder.Eventf(u, v1.EventTypeWarning, reasonReconcileFailed, "apply mesh ingress %s: %v", ingressName, err)
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: gatewayResyncPeriod}, nil
+}
+
+func (r *HTTPRouteReconciler) attachedToMeshGateway(ctx context.Context, namespace string, route *meshingress.HTTPRoute) (bool, error) {
+	for _, ref := range route.GatewayRefs(namespace) {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(meshingress.GatewayGVK)
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: ref[0], Name: ref[1]}, u)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "get Gateway %s/%s", ref[0], ref[1])
+		}
+
+		gateway := &meshingress.Gateway{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, gateway)
+		if err != nil {
+			return false, errors.Wrapf(err, "convert Gateway %s/%s", ref[0], ref[1])
+		}
+
+		if gateway.Spec.GatewayClassName == meshingress.GatewayClassName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HTTPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(meshingress.HTTPRouteGVK)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(route).
+		Complete(r)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshingress"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// IngressReconciler translates Kubernetes Ingresses of the EaseMesh
+// ingress class into mesh ingresses.
+type IngressReconciler struct {
+	*base.Runtime
+	IngressClient meshingress.Client
+}
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;ingressclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile reconciles Ingress.
+func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ingressName := meshingress.K8sIngressName(req.Namespace, req.Name)
+
+	ingress := &networkingv1.Ingress{}
+	err := r.Client.Get(ctx, req.NamespacedName, ingress)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("Ingress not found, delete its mesh ingress", "id", req.NamespacedName, "ingress", ingressName)
+			return reconcile.Result{}, r.IngressClient.Delete(ctx, ingressName)
+		}
+		r.Log.Error(err, "get Ingress", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	if !meshingress.IsMeshIngressClass(ingress) {
+		// NOTE: The ingress class may be changed from EaseMesh to others.
+		return reconcile.Result{}, r.IngressClient.Delete(ctx, ingressName)
+	}
+
+	secrets := map[string]*v1.Secret{}
+	for _, name := range meshingress.TLSSecretNames(ingress) {
+		secret := &v1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: name}, secret)
+		if err != nil {
+			r.Log.Error(err, "get TLS secret", "id", req.NamespacedName, "secret", name)
+			r.Recorder.Eventf(ingress, v1.EventTypeWarning, reasonReconcileFailed, "get TLS secret %s: %v", name, err)
+			return reconcile.Result{}, err
+		}
+		secrets[name] = secret
+	}
+
+	r.Log.Info("syncing Ingress", "id", req.NamespacedName, "ingress", ingressName)
+	err = r.IngressClient.Apply(ctx, meshingress.FromK8sIngress(ingressName, ingress, secrets))
+	if err != nil {
+		r.Log.Error(err, "apply mesh ingress", "id", req.NamespacedName, "ingress", ingressName)
+		metrics.RecordError(r.Name, metrics.OperationApplyMeshIngress)
+		r.Recorder.Eventf(ingress, v1.EventTypeWarning, reasonReconcileFailed, "apply mesh ingress %s: %v", ingressName, err)
+	}
+
+	return reconcile.Result{}, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&networkingv1.Ingress{}).
+		Complete(r)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecarinjector"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// injectionEventMaxAge is the max age of pods whose injection events are recorded,
+// so the events of existing pods aren't recorded again after the operator restarts.
+const injectionEventMaxAge = 10 * time.Minute
+
+// InjectionEventReconciler records the injection decisions annotated on pods
+// by the webhook as events of the pods, which the webhook can't record since
+// pods have no UIDs until they're created.
+type InjectionEventReconciler struct {
+	*base.Runtime
+}
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile reconciles Pod.
+func (r *InjectionEventReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pod := &metav1.PartialObjectMetadata{}
+	pod.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Pod"))
+	err := r.Client.Get(ctx, req.NamespacedName, pod)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		r.Log.Error(err, "get Pod", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	eventType, reason, message, ok := sidecarinjector.InjectionEvent(pod)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	r.Recorder.Event(&v1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		UID:        pod.UID,
+	}, eventType, reason, message)
+
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *InjectionEventReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// NOTE: Only the metadata of pods is cached, which carries the annotations.
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Pod{}, builder.OnlyMetadata).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return time.Since(e.Object.GetCreationTimestamp().Time) < injectionEventMaxAge
+			},
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		Complete(r)
+}
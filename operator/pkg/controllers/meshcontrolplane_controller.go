@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/controlplane"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// controlPlaneResyncPeriod is the period refreshing the status until components are ready,
+// since workloads of components aren't owned by the MeshControlPlane.
+const controlPlaneResyncPeriod = 10 * time.Second
+
+// MeshControlPlaneReconciler applies MeshControlPlanes to the components
+// of the EaseMesh installation in their namespaces.
+type MeshControlPlaneReconciler struct {
+	*base.Runtime
+}
+
+// +kubebuilder:rbac:groups=mesh.megaease.com,resources=meshcontrolplanes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mesh.megaease.com,resources=meshcontrolplanes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update;patch
+
+// Reconcile reconciles MeshControlPlane.
+func (r *MeshControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	mcp := &meshv1.MeshControlPlane{}
+	err := r.Client.Get(ctx, req.NamespacedName, mcp)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		r.Log.Error(err, "get MeshControlPlane", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	r.Log.Info("syncing MeshControlPlane", "id", req.NamespacedName)
+
+	spec := &mcp.Spec
+	var syncErr error
+	components := []meshv1.ComponentStatus{}
+	record := func(name string, status meshv1.ComponentStatus, err error) {
+		if err != nil {
+			r.Log.Error(err, "sync component", "id", req.NamespacedName, "component", name)
+			metrics.RecordError(r.Name, metrics.OperationSyncControlPlane)
+			r.Recorder.Eventf(mcp, corev1.EventTypeWarning, reasonReconcileFailed, "sync component %s: %v", name, err)
+			status = meshv1.ComponentStatus{Name: name, Message: err.Error()}
+			syncErr = err
+		}
+		components = append(components, status)
+	}
+
+	status, err := r.syncControlPlane(ctx, req.Namespace, spec)
+	record(controlplane.ControlPlaneStatefulSetName, status, err)
+
+	status, err = r.syncDeployment(ctx, req.Namespace, controlplane.IngressControllerDeploymentName,
+		controlplane.IngressControllerContainerName, spec.ImageRegistryURL, &spec.IngressController)
+	record(controlplane.IngressControllerDeploymentName, status, err)
+
+	status, err = r.syncDeployment(ctx, req.Namespace, controlplane.OperatorDeploymentName,
+		controlplane.OperatorContainerName, spec.ImageRegistryURL, &spec.Operator)
+	record(controlplane.OperatorDeploymentName, status, err)
+
+	mcp.Status.ObservedGeneration = mcp.Generation
+	mcp.Status.Components = components
+	mcp.Status.Phase = controlplane.Phase(components)
+	if syncErr != nil {
+		mcp.Status.Phase = meshv1.MeshControlPlanePhaseFailed
+	}
+
+	err = r.Client.Status().Update(ctx, mcp)
+	if err != nil {
+		r.Log.Error(err, "update status of MeshControlPlane", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	if syncErr != nil {
+		return reconcile.Result{}, syncErr
+	}
+	if mcp.Status.Phase != meshv1.MeshControlPlanePhaseReady {
+		return reconcile.Result{RequeueAfter: controlPlaneResyncPeriod}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// syncControlPlane updates the image of the control plane only, changing
+// replicas must add or remove members of the Easegress cluster one by one,
+// which is done by emctl scale control-plane.
+func (r *MeshControlPlaneReconciler) syncControlPlane(ctx context.Context, namespace string,
+	spec *meshv1.MeshControlPlaneSpec) (meshv1.ComponentStatus, error) {
+	name := controlplane.ControlPlaneStatefulSetName
+	sts := &appsv1.StatefulSet{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts)
+	if err != nil {
+		return meshv1.ComponentStatus{}, errors.Wrapf(err, "get statefulset %s", name)
+	}
+
+	image := ""
+	if spec.ControlPlane.Image != "" {
+		image = controlplane.ImageName(spec.ImageRegistryURL, spec.ControlPlane.Image)
+	}
+	changed, err := controlplane.SyncImage(&sts.Spec.Template, controlplane.ControlPlaneContainerName, image)
+	if err != nil {
+		return meshv1.ComponentStatus{}, errors.Wrapf(err, "statefulset %s", name)
+	}
+	if changed {
+		r.Log.Info("update image of control plane", "statefulset", name, "image", image)
+		err = r.Client.Update(ctx, sts)
+		if err != nil {
+			return meshv1.ComponentStatus{}, errors.Wrapf(err, "update statefulset %s", name)
+		}
+	}
+
+	status := controlplane.StatefulSetStatus(sts, controlplane.ControlPlaneContainerName)
+	if desired := spec.ControlPlane.Replicas; desired != nil && *desired != status.Replicas {
+		status.Message = fmt.Sprintf("control plane has %d members, run emctl scale control-plane --replicas %d to change them",
+			status.Replicas, *desired)
+	}
+	return status, nil
+}
+
+func (r *MeshControlPlaneReconciler) syncDeployment(ctx context.Context, namespace, name, containerName,
+	registry string, spec *meshv1.ComponentSpec) (meshv1.ComponentStatus, error) {
+	deploy := &appsv1.Deployment{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deploy)
+	if err != nil {
+		return meshv1.ComponentStatus{}, errors.Wrapf(err, "get deployment %s", name)
+	}
+
+	image := ""
+	if spec.Image != "" {
+		image = controlplane.ImageName(registry, spec.Image)
+	}
+	imageChanged, err := controlplane.SyncImage(&deploy.Spec.Template, containerName, image)
+	if err != nil {
+		return meshv1.ComponentStatus{}, errors.Wrapf(err, "deployment %s", name)
+	}
+	replicasChanged := controlplane.SyncReplicas(&deploy.Spec.Replicas, spec.Replicas)
+	if imageChanged || replicasChanged {
+		r.Log.Info("update deployment", "deployment", name, "image", image, "replicas", spec.Replicas)
+		err = r.Client.Update(ctx, deploy)
+		if err != nil {
+			return meshv1.ComponentStatus{}, errors.Wrapf(err, "update deployment %s", name)
+		}
+	}
+
+	return controlplane.DeploymentStatus(deploy, containerName), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MeshControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&meshv1.MeshControlPlane{}).
+		Complete(r)
+}
@@ -20,14 +20,16 @@ package controllers
 import (
 	"context"
 
-	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
 	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
 	"github.com/megaease/easemesh/mesh-operator/pkg/sidecarinjector"
 	"github.com/megaease/easemesh/mesh-operator/pkg/syncer"
 
 	"github.com/imdario/mergo"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -49,7 +51,7 @@ type MeshDeploymentReconciler struct {
 func (r *MeshDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.Log.WithValues("MeshDeploymentID", req.NamespacedName)
 
-	meshDeploy := &meshv1beta1.MeshDeployment{}
+	meshDeploy := &meshv1.MeshDeployment{}
 	err := r.Client.Get(ctx, req.NamespacedName, meshDeploy)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -60,6 +62,14 @@ func (r *MeshDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return reconcile.Result{}, err
 	}
 
+	err = validateMeshDeployment(meshDeploy)
+	if err != nil {
+		// NOTE: Retrying doesn't help until the MeshDeployment is fixed.
+		r.Log.Error(err, "validate MeshDeployment", "id", req.NamespacedName)
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeWarning, reasonValidationFailed, "invalid MeshDeployment: %v", err)
+		return reconcile.Result{}, nil
+	}
+
 	deploy := &v1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      meshDeploy.Name,
@@ -83,14 +93,15 @@ func (r *MeshDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		//   https://github.com/kubernetes/klog/issues/253
 		//   https://github.com/kubernetes/klog/pull/242
 		//   https://github.com/kubernetes-sigs/controller-runtime/issues/1538
-		deploy.Spec.Template.ObjectMeta.Labels = sourceDeploySpec.Selector.MatchLabels
+		deploy.Spec.Template.ObjectMeta.Labels = sidecarinjector.InjectedLabels(sourceDeploySpec.Selector.MatchLabels)
 
 		service := &sidecarinjector.MeshService{
 			Name:             meshDeploy.Name,
+			Namespace:        meshDeploy.Namespace,
 			Labels:           meshDeploy.Spec.Service.Labels,
 			AppContainerName: meshDeploy.Spec.Service.AppContainerName,
 			AliveProbeURL:    meshDeploy.Spec.Service.AliveProbeURL,
-			ApplicationPort:  meshDeploy.Spec.Service.ApplicationPort,
+			ApplicationPort:  uint16(meshDeploy.Spec.Service.ApplicationPort),
 		}
 		injector := sidecarinjector.New(r.Runtime, service, &deploy.Spec.Template.Spec)
 
@@ -101,15 +112,41 @@ func (r *MeshDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	err = syncer.Sync(context.TODO(), meshDeploymentSyncer, r.Recorder)
 	if err != nil {
 		r.Log.V(1).Error(err, "sync MeshDeployment")
+		metrics.RecordError(r.Name, metrics.OperationSyncDeployment)
 	}
 
 	return ctrl.Result{}, err
 }
 
+// validateMeshDeployment checks what the schema of the CRD doesn't,
+// which would fail the injection or the Deployment otherwise.
+func validateMeshDeployment(meshDeploy *meshv1.MeshDeployment) error {
+	spec := &meshDeploy.Spec.Deploy.DeploymentSpec
+	if spec.Selector == nil || len(spec.Selector.MatchLabels) == 0 {
+		return errors.New("deploy.selector.matchLabels is required")
+	}
+
+	containers := spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return errors.New("deploy.template.spec.containers is required")
+	}
+
+	name := meshDeploy.Spec.Service.AppContainerName
+	if name == "" {
+		return nil
+	}
+	for _, container := range containers {
+		if container.Name == name {
+			return nil
+		}
+	}
+	return errors.Errorf("app container %s not found in deploy.template.spec.containers", name)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *MeshDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&meshv1beta1.MeshDeployment{}).
+		For(&meshv1.MeshDeployment{}).
 		Owns(&v1.Deployment{}).
 		Complete(r)
 }
@@ -21,7 +21,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
-	"github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
 	"github.com/megaease/easemesh/mesh-operator/pkg/base"
 
 	v1 "k8s.io/api/apps/v1"
@@ -47,7 +47,7 @@ var (
 )
 
 var _ = Describe("meshdeployment controller", func() {
-	var meshDeployment v1beta1.MeshDeployment
+	var meshDeployment meshv1.MeshDeployment
 	var log logr.Logger
 	BeforeEach(func() {
 		log = ctrl.Log.WithName("controllers").WithName("MeshDeployment")
@@ -58,19 +58,19 @@ var _ = Describe("meshdeployment controller", func() {
 			Recorder: &mockRecorder{},
 			Log:      log,
 		}
-		meshDeployment = v1beta1.MeshDeployment{
+		meshDeployment = meshv1.MeshDeployment{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      meshDeploymentName,
 				Namespace: namespace,
 			},
-			Spec: v1beta1.MeshDeploymentSpec{
-				Service: v1beta1.ServiceSpec{
+			Spec: meshv1.MeshDeploymentSpec{
+				Service: meshv1.ServiceSpec{
 					Name: "test-server",
 					Labels: map[string]string{
 						"canary": "internal",
 					},
 				},
-				Deploy: v1beta1.DeploySpec{
+				Deploy: meshv1.DeploySpec{
 					DeploymentSpec: v1.DeploymentSpec{
 						Replicas: fromInt32(2),
 						Selector: &metav1.LabelSelector{
@@ -131,6 +131,40 @@ var _ = Describe("meshdeployment controller", func() {
 
 })
 
+var _ = Describe("validateMeshDeployment", func() {
+	meshDeployment := func(appContainerName string, selector *metav1.LabelSelector) *meshv1.MeshDeployment {
+		return &meshv1.MeshDeployment{
+			Spec: meshv1.MeshDeploymentSpec{
+				Service: meshv1.ServiceSpec{Name: "test-server", AppContainerName: appContainerName},
+				Deploy: meshv1.DeploySpec{
+					DeploymentSpec: v1.DeploymentSpec{
+						Selector: selector,
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "test-server"}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test-server"}}
+
+	It("accepts valid MeshDeployments", func() {
+		Expect(validateMeshDeployment(meshDeployment("", selector))).To(Succeed())
+		Expect(validateMeshDeployment(meshDeployment("test-server", selector))).To(Succeed())
+	})
+
+	It("rejects MeshDeployments without selectors", func() {
+		Expect(validateMeshDeployment(meshDeployment("", nil))).To(MatchError(ContainSubstring("deploy.selector.matchLabels")))
+	})
+
+	It("rejects MeshDeployments with unknown app containers", func() {
+		Expect(validateMeshDeployment(meshDeployment("app", selector))).To(MatchError(ContainSubstring("app container app not found")))
+	})
+})
+
 func fromInt32(i int32) *int32 {
 	return &i
 }
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
+	"github.com/megaease/easemesh/mesh-operator/pkg/selfheal"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// selfHealPeriod is the period comparing objects with the snapshot,
+// since the objects aren't owned by the snapshot Secret.
+const selfHealPeriod = 30 * time.Second
+
+// SelfHealReconciler reverts manual edits and deletions of the objects
+// in the snapshot saved by emctl install --self-heal.
+//
+// NOTE: The permissions are granted by emctl install --self-heal,
+// so there are no rbac markers here.
+type SelfHealReconciler struct {
+	*base.Runtime
+}
+
+// Reconcile reconciles the snapshot Secret.
+func (r *SelfHealReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	secret := &v1.Secret{}
+	err := r.Client.Get(ctx, req.NamespacedName, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		r.Log.Error(err, "get snapshot", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	objects, err := selfheal.Decode(secret.Data[selfheal.SnapshotSecretKey])
+	if err != nil {
+		// NOTE: Retrying doesn't help until the snapshot is saved again.
+		r.Log.Error(err, "decode snapshot", "id", req.NamespacedName)
+		metrics.RecordError(r.Name, metrics.OperationSelfHeal)
+		return reconcile.Result{}, nil
+	}
+
+	for _, desired := range objects {
+		err := r.heal(ctx, desired)
+		if err != nil {
+			r.Log.Error(err, "heal object", "kind", desired.GetKind(),
+				"namespace", desired.GetNamespace(), "name", desired.GetName())
+			metrics.RecordError(r.Name, metrics.OperationSelfHeal)
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: selfHealPeriod}, nil
+}
+
+func (r *SelfHealReconciler) heal(ctx context.Context, desired *unstructured.Unstructured) error {
+	kind, name := desired.GetKind(), desired.GetName()
+	key := types.NamespacedName{Namespace: desired.GetNamespace(), Name: name}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(desired.GroupVersionKind())
+	err := r.Client.Get(ctx, key, live)
+	if apierrors.IsNotFound(err) {
+		obj := desired.DeepCopy()
+		err = r.Client.Create(ctx, obj)
+		if err != nil {
+			return errors.Wrapf(err, "recreate %s %s", kind, key)
+		}
+
+		r.Log.Info("recreated deleted object", "kind", kind, "id", key)
+		r.Recorder.Eventf(obj, v1.EventTypeWarning, "Recreated",
+			"%s %s was deleted and is recreated from the install snapshot", kind, name)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "get %s %s", kind, key)
+	}
+
+	if !selfheal.Heal(desired, live) {
+		return nil
+	}
+
+	err = r.Client.Update(ctx, live)
+	if err != nil {
+		return errors.Wrapf(err, "update %s %s", kind, key)
+	}
+
+	r.Log.Info("corrected drifted object", "kind", kind, "id", key)
+	r.Recorder.Eventf(live, v1.EventTypeWarning, "DriftCorrected",
+		"manual edits of %s %s are reverted to the install snapshot", kind, name)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SelfHealReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Secret{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == selfheal.SnapshotSecretName
+		})).
+		Complete(r)
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshservice"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ServiceRegistrationReconciler registers Deployments labeled with
+// mesh.megaease.com/enable=true as mesh services, under the default
+// tenant named after their namespaces.
+type ServiceRegistrationReconciler struct {
+	*base.Runtime
+	ServiceClient meshservice.Client
+}
+
+// Reconcile reconciles Deployment.
+func (r *ServiceRegistrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	deploy := &v1.Deployment{}
+	err := r.Client.Get(ctx, req.NamespacedName, deploy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		r.Log.Error(err, "get Deployment", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	registered := deploy.Annotations[meshservice.RegisteredServiceAnnotationKey]
+	if !deploy.DeletionTimestamp.IsZero() || !meshservice.Enabled(deploy) {
+		return reconcile.Result{}, r.deregister(ctx, deploy, registered)
+	}
+
+	tenant := meshservice.DefaultTenant(deploy.Namespace)
+	_, err = r.ServiceClient.CreateTenant(ctx, tenant)
+	if err != nil {
+		return reconcile.Result{}, r.failed(deploy, err, "create tenant %s", tenant.Name)
+	}
+
+	name := meshservice.ServiceName(deploy)
+	if registered != "" && registered != name {
+		// NOTE: The service is renamed by the annotation.
+		err = r.ServiceClient.DeleteService(ctx, registered)
+		if err != nil {
+			return reconcile.Result{}, r.failed(deploy, err, "delete service %s", registered)
+		}
+		registered = ""
+	}
+
+	created, err := r.ServiceClient.CreateService(ctx, meshservice.DefaultService(name, tenant.Name))
+	if err != nil {
+		return reconcile.Result{}, r.failed(deploy, err, "create service %s", name)
+	}
+	if registered != "" {
+		return reconcile.Result{}, nil
+	}
+	if !created {
+		// NOTE: The service is authored by users, which isn't taken over.
+		r.Log.Info("service exists, skip registration", "id", req.NamespacedName, "service", name)
+		return reconcile.Result{}, r.forget(ctx, deploy)
+	}
+
+	if deploy.Annotations == nil {
+		deploy.Annotations = map[string]string{}
+	}
+	deploy.Annotations[meshservice.RegisteredServiceAnnotationKey] = name
+	controllerutil.AddFinalizer(deploy, meshservice.RegistrationFinalizer)
+	err = r.Client.Update(ctx, deploy)
+	if err != nil {
+		r.Log.Error(err, "update Deployment", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	r.Log.Info("registered service", "id", req.NamespacedName, "service", name, "tenant", tenant.Name)
+	r.Recorder.Eventf(deploy, corev1.EventTypeNormal, "ServiceRegistered",
+		"registered as mesh service %s of tenant %s", name, tenant.Name)
+	return reconcile.Result{}, nil
+}
+
+// deregister deletes the service registered for the Deployment, which is
+// unlabeled or being deleted.
+func (r *ServiceRegistrationReconciler) deregister(ctx context.Context, deploy *v1.Deployment, registered string) error {
+	if registered != "" {
+		err := r.ServiceClient.DeleteService(ctx, registered)
+		if err != nil {
+			return r.failed(deploy, err, "delete service %s", registered)
+		}
+
+		r.Log.Info("deregistered service", "id", client.ObjectKeyFromObject(deploy), "service", registered)
+		r.Recorder.Eventf(deploy, corev1.EventTypeNormal, "ServiceDeregistered",
+			"deregistered mesh service %s", registered)
+	}
+
+	return r.forget(ctx, deploy)
+}
+
+// forget removes the registration records from the Deployment.
+func (r *ServiceRegistrationReconciler) forget(ctx context.Context, deploy *v1.Deployment) error {
+	_, annotated := deploy.Annotations[meshservice.RegisteredServiceAnnotationKey]
+	if !annotated && !meshservice.Registered(deploy) {
+		return nil
+	}
+
+	delete(deploy.Annotations, meshservice.RegisteredServiceAnnotationKey)
+	controllerutil.RemoveFinalizer(deploy, meshservice.RegistrationFinalizer)
+	err := r.Client.Update(ctx, deploy)
+	if err != nil && !apierrors.IsNotFound(err) {
+		r.Log.Error(err, "update Deployment", "id", client.ObjectKeyFromObject(deploy))
+		return err
+	}
+	return nil
+}
+
+func (r *ServiceRegistrationReconciler) failed(deploy *v1.Deployment, err error, format string, args ...interface{}) error {
+	r.Log.Error(err, "register service", "id", client.ObjectKeyFromObject(deploy))
+	metrics.RecordError(r.Name, metrics.OperationRegisterService)
+	r.Recorder.Eventf(deploy, corev1.EventTypeWarning, reasonReconcileFailed, format+": %v", append(args, err)...)
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceRegistrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// NOTE: Registered Deployments are watched after they're unlabeled to deregister them.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("serviceregistration").
+		For(&v1.Deployment{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return meshservice.Enabled(obj) || meshservice.Registered(obj)
+		})).
+		Complete(r)
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshservice"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecarinjector"
+	"github.com/megaease/easemesh/mesh-operator/pkg/trafficpolicy"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reasonTrafficPolicyConflict means annotations are left unapplied by the conflict rules.
+const reasonTrafficPolicyConflict = "TrafficPolicyConflict"
+
+// TrafficPolicyReconciler converts traffic policy annotations of Deployments,
+// i.e. timeout, retries and canary weight, into mesh resources.
+type TrafficPolicyReconciler struct {
+	*base.Runtime
+	PolicyClient trafficpolicy.Client
+}
+
+// Reconcile reconciles Deployment.
+func (r *TrafficPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	deploy := &v1.Deployment{}
+	err := r.Client.Get(ctx, req.NamespacedName, deploy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		r.Log.Error(err, "get Deployment", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	if deploy.DeletionTimestamp.IsZero() {
+		_, err := trafficpolicy.Parse(deploy.Annotations)
+		if err != nil {
+			// NOTE: Retrying doesn't help until the annotations are fixed.
+			r.Log.Error(err, "parse traffic policy", "id", req.NamespacedName)
+			r.Recorder.Eventf(deploy, corev1.EventTypeWarning, reasonValidationFailed, "invalid traffic policy: %v", err)
+			return reconcile.Result{}, nil
+		}
+	}
+
+	service := r.serviceOf(deploy)
+	if service == "" {
+		r.Recorder.Eventf(deploy, corev1.EventTypeWarning, reasonValidationFailed,
+			"traffic policy needs the mesh service of the Deployment, annotate it with %s", sidecarinjector.ServiceNameAnnotationKey)
+		return reconcile.Result{}, nil
+	}
+
+	self := r.deploymentOf(deploy)
+	peers, err := r.peersOf(ctx, deploy, service)
+	if err != nil {
+		r.Log.Error(err, "list Deployments", "id", req.NamespacedName)
+		return reconcile.Result{}, err
+	}
+
+	live, err := r.PolicyClient.GetResilience(ctx, service)
+	if err != nil {
+		return reconcile.Result{}, r.policyFailed(deploy, err, "get resilience of service %s", service)
+	}
+
+	resolution := trafficpolicy.ResolveResilience(service, live, self, peers)
+	for _, conflict := range resolution.Conflicts {
+		r.Recorder.Event(deploy, corev1.EventTypeWarning, reasonTrafficPolicyConflict, conflict)
+	}
+	if resolution.Resilience != nil {
+		err = r.PolicyClient.ApplyResilience(ctx, service, resolution.Resilience)
+		if err != nil {
+			return reconcile.Result{}, r.policyFailed(deploy, err, "apply resilience of service %s", service)
+		}
+		r.Log.Info("applied resilience", "id", req.NamespacedName, "service", service)
+	}
+
+	serviceCanary, err := r.syncServiceCanary(ctx, deploy, service, self)
+	if err != nil {
+		return reconcile.Result{}, r.policyFailed(deploy, err, "sync service canary of service %s", service)
+	}
+
+	applied := &trafficpolicy.Applied{
+		Service:       service,
+		TimeLimiter:   resolution.TimeLimiter,
+		Retryer:       resolution.Retryer,
+		ServiceCanary: serviceCanary,
+	}
+	return reconcile.Result{}, r.record(ctx, deploy, applied)
+}
+
+// syncServiceCanary applies the service canary of the canary weight,
+// it returns the name of the one written by the operator.
+func (r *TrafficPolicyReconciler) syncServiceCanary(ctx context.Context, deploy *v1.Deployment,
+	service string, self *trafficpolicy.Deployment) (string, error) {
+	name := trafficpolicy.ServiceCanaryName(deploy.Namespace, deploy.Name)
+	recorded := self.Applied != nil && self.Applied.ServiceCanary == name
+
+	if self.Policy == nil || self.Policy.CanaryWeight == nil {
+		if recorded {
+			return "", r.PolicyClient.DeleteServiceCanary(ctx, name)
+		}
+		return "", nil
+	}
+
+	if !recorded {
+		live, err := r.PolicyClient.GetServiceCanary(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if live != nil {
+			r.Recorder.Eventf(deploy, corev1.EventTypeWarning, reasonTrafficPolicyConflict,
+				"service canary %s isn't written by annotations, delete it to manage the canary weight by annotations", name)
+			return "", nil
+		}
+	}
+
+	err := r.PolicyClient.ApplyServiceCanary(ctx, trafficpolicy.ServiceCanary(name, service, self.Policy))
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// record saves the policies written by the operator in the Deployment, the
+// finalizer removes them before the Deployment is deleted.
+func (r *TrafficPolicyReconciler) record(ctx context.Context, deploy *v1.Deployment, applied *trafficpolicy.Applied) error {
+	value := ""
+	if applied.TimeLimiter != nil || applied.Retryer != nil || applied.ServiceCanary != "" {
+		buff, err := json.Marshal(applied)
+		if err != nil {
+			return err
+		}
+		value = string(buff)
+	}
+
+	if value == deploy.Annotations[trafficpolicy.AppliedAnnotationKey] &&
+		(value != "") == controllerutil.ContainsFinalizer(deploy, trafficpolicy.Finalizer) {
+		return nil
+	}
+
+	if value == "" {
+		delete(deploy.Annotations, trafficpolicy.AppliedAnnotationKey)
+		controllerutil.RemoveFinalizer(deploy, trafficpolicy.Finalizer)
+	} else {
+		if deploy.Annotations == nil {
+			deploy.Annotations = map[string]string{}
+		}
+		deploy.Annotations[trafficpolicy.AppliedAnnotationKey] = value
+		controllerutil.AddFinalizer(deploy, trafficpolicy.Finalizer)
+	}
+
+	err := r.Client.Update(ctx, deploy)
+	if err != nil && !apierrors.IsNotFound(err) {
+		r.Log.Error(err, "update Deployment", "id", client.ObjectKeyFromObject(deploy))
+		return err
+	}
+	return nil
+}
+
+// deploymentOf returns the Deployment for resolving, whose policy is nil if
+// it's being deleted or its annotations are invalid.
+func (r *TrafficPolicyReconciler) deploymentOf(deploy *v1.Deployment) *trafficpolicy.Deployment {
+	result := &trafficpolicy.Deployment{
+		ID: fmt.Sprintf("%s/%s", deploy.Namespace, deploy.Name),
+	}
+
+	if value := deploy.Annotations[trafficpolicy.AppliedAnnotationKey]; value != "" {
+		applied := &trafficpolicy.Applied{}
+		if err := json.Unmarshal([]byte(value), applied); err == nil {
+			result.Applied = applied
+		}
+	}
+
+	if deploy.DeletionTimestamp.IsZero() {
+		result.Policy, _ = trafficpolicy.Parse(deploy.Annotations)
+	}
+
+	return result
+}
+
+// serviceOf returns the mesh service of the Deployment,
+// or the one recorded if the Deployment has left the mesh.
+func (r *TrafficPolicyReconciler) serviceOf(deploy *v1.Deployment) string {
+	if name := deploy.Annotations[sidecarinjector.ServiceNameAnnotationKey]; name != "" {
+		return name
+	}
+	if r.ServiceAutoRegistration && meshservice.Enabled(deploy) {
+		return meshservice.ServiceName(deploy)
+	}
+	if d := r.deploymentOf(deploy); d.Applied != nil {
+		return d.Applied.Service
+	}
+	return ""
+}
+
+// peersOf returns other annotated Deployments of the service.
+func (r *TrafficPolicyReconciler) peersOf(ctx context.Context, deploy *v1.Deployment, service string) ([]*trafficpolicy.Deployment, error) {
+	list := &v1.DeploymentList{}
+	err := r.Client.List(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := []*trafficpolicy.Deployment{}
+	for i := range list.Items {
+		peer := &list.Items[i]
+		if peer.Namespace == deploy.Namespace && peer.Name == deploy.Name {
+			continue
+		}
+		if !trafficpolicy.Annotated(peer) && !controllerutil.ContainsFinalizer(peer, trafficpolicy.Finalizer) {
+			continue
+		}
+		if r.serviceOf(peer) != service {
+			continue
+		}
+		peers = append(peers, r.deploymentOf(peer))
+	}
+
+	return peers, nil
+}
+
+func (r *TrafficPolicyReconciler) policyFailed(deploy *v1.Deployment, err error, format string, args ...interface{}) error {
+	r.Log.Error(err, "sync traffic policy", "id", client.ObjectKeyFromObject(deploy))
+	metrics.RecordError(r.Name, metrics.OperationSyncTrafficPolicy)
+	r.Recorder.Eventf(deploy, corev1.EventTypeWarning, reasonReconcileFailed, format+": %v", append(args, err)...)
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TrafficPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// NOTE: Deployments with the finalizer are watched after they're unannotated to remove policies.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("trafficpolicy").
+		For(&v1.Deployment{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return trafficpolicy.Annotated(obj) || controllerutil.ContainsFinalizer(obj, trafficpolicy.Finalizer)
+		})).
+		Complete(r)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlplane
+
+import (
+	"fmt"
+
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// NOTE: The names must be consistent with the ones deployed by emctl install.
+const (
+	// ControlPlaneStatefulSetName is the name of the StatefulSet of the control plane.
+	ControlPlaneStatefulSetName = "easemesh-control-plane"
+	// ControlPlaneContainerName is the name of the Easegress container of the control plane.
+	ControlPlaneContainerName = "easegress"
+
+	// IngressControllerDeploymentName is the name of the Deployment of the ingress controller.
+	IngressControllerDeploymentName = "easemesh-ingress-controller"
+	// IngressControllerContainerName is the name of the container of the ingress controller.
+	IngressControllerContainerName = "easemesh-ingress-controller"
+
+	// OperatorDeploymentName is the name of the Deployment of the operator.
+	OperatorDeploymentName = "easemesh-operator"
+	// OperatorContainerName is the name of the container of the operator.
+	OperatorContainerName = "operator-manager"
+)
+
+// ImageName returns the name of the image in the registry.
+func ImageName(registry, image string) string {
+	if registry == "" {
+		return image
+	}
+	return registry + "/" + image
+}
+
+// SyncReplicas sets replicas to the desired one, nil desired means unchanged.
+// It reports whether replicas are changed.
+func SyncReplicas(replicas **int32, desired *int32) bool {
+	if desired == nil {
+		return false
+	}
+	if *replicas != nil && **replicas == *desired {
+		return false
+	}
+	r := *desired
+	*replicas = &r
+	return true
+}
+
+// SyncImage sets the image of the container in the pod template, empty image
+// means unchanged. It reports whether the template is changed.
+func SyncImage(template *v1.PodTemplateSpec, containerName, image string) (bool, error) {
+	container := findContainer(template, containerName)
+	if container == nil {
+		return false, errors.Errorf("container %s not found", containerName)
+	}
+	if image == "" || container.Image == image {
+		return false, nil
+	}
+	container.Image = image
+	return true, nil
+}
+
+func findContainer(template *v1.PodTemplateSpec, name string) *v1.Container {
+	containers := template.Spec.Containers
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func componentStatus(name string, template *v1.PodTemplateSpec, containerName string,
+	rolledOut bool, replicas, updatedReplicas, readyReplicas int32) meshv1.ComponentStatus {
+	status := meshv1.ComponentStatus{
+		Name:          name,
+		Replicas:      replicas,
+		ReadyReplicas: readyReplicas,
+	}
+	if container := findContainer(template, containerName); container != nil {
+		status.Image = container.Image
+	}
+	if !rolledOut || updatedReplicas != replicas || readyReplicas != replicas {
+		status.Message = fmt.Sprintf("rolling out, %d updated, %d ready of %d", updatedReplicas, readyReplicas, replicas)
+	}
+	return status
+}
+
+// StatefulSetStatus returns the status of the component running by the StatefulSet.
+func StatefulSetStatus(sts *appsv1.StatefulSet, containerName string) meshv1.ComponentStatus {
+	var replicas int32 = 1
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return componentStatus(sts.Name, &sts.Spec.Template, containerName,
+		sts.Status.ObservedGeneration >= sts.Generation,
+		replicas, sts.Status.UpdatedReplicas, sts.Status.ReadyReplicas)
+}
+
+// DeploymentStatus returns the status of the component running by the Deployment.
+func DeploymentStatus(deploy *appsv1.Deployment, containerName string) meshv1.ComponentStatus {
+	var replicas int32 = 1
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	return componentStatus(deploy.Name, &deploy.Spec.Template, containerName,
+		deploy.Status.ObservedGeneration >= deploy.Generation,
+		replicas, deploy.Status.UpdatedReplicas, deploy.Status.ReadyReplicas)
+}
+
+// Phase returns the phase of the installation by statuses of its components.
+func Phase(components []meshv1.ComponentStatus) meshv1.MeshControlPlanePhase {
+	for _, c := range components {
+		if c.Message != "" {
+			return meshv1.MeshControlPlanePhaseProgressing
+		}
+	}
+	return meshv1.MeshControlPlanePhaseReady
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlplane
+
+import (
+	"testing"
+
+	meshv1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestImageName(t *testing.T) {
+	if got := ImageName("docker.io", "megaease/easegress:easemesh"); got != "docker.io/megaease/easegress:easemesh" {
+		t.Errorf("unexpected image %s", got)
+	}
+	if got := ImageName("", "megaease/easegress:easemesh"); got != "megaease/easegress:easemesh" {
+		t.Errorf("unexpected image %s", got)
+	}
+}
+
+func TestSyncReplicas(t *testing.T) {
+	var replicas *int32
+	if SyncReplicas(&replicas, nil) || replicas != nil {
+		t.Errorf("nil desired replicas should change nothing")
+	}
+	if !SyncReplicas(&replicas, int32Ptr(2)) || *replicas != 2 {
+		t.Errorf("expected replicas changed to 2")
+	}
+	if SyncReplicas(&replicas, int32Ptr(2)) {
+		t.Errorf("expected replicas unchanged")
+	}
+}
+
+func TestSyncImage(t *testing.T) {
+	template := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "kube-rbac-proxy", Image: "kube-rbac-proxy:v0.5.0"},
+				{Name: OperatorContainerName, Image: "docker.io/megaease/easemesh-operator:v1"},
+			},
+		},
+	}
+
+	changed, err := SyncImage(template, OperatorContainerName, "")
+	if err != nil || changed {
+		t.Errorf("empty image should change nothing, changed %v, err %v", changed, err)
+	}
+
+	changed, err = SyncImage(template, OperatorContainerName, "docker.io/megaease/easemesh-operator:v2")
+	if err != nil || !changed {
+		t.Fatalf("expected image changed, err %v", err)
+	}
+	if template.Spec.Containers[1].Image != "docker.io/megaease/easemesh-operator:v2" ||
+		template.Spec.Containers[0].Image != "kube-rbac-proxy:v0.5.0" {
+		t.Errorf("unexpected containers %+v", template.Spec.Containers)
+	}
+
+	_, err = SyncImage(template, "not-found", "image")
+	if err == nil {
+		t.Errorf("expected error of container not found")
+	}
+}
+
+func TestDeploymentStatus(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: IngressControllerDeploymentName, Generation: 2},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(2),
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: IngressControllerContainerName, Image: "easegress"}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+	}
+
+	status := DeploymentStatus(deploy, IngressControllerContainerName)
+	if status.Image != "easegress" || status.Replicas != 2 || status.ReadyReplicas != 2 || status.Message != "" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if phase := Phase([]meshv1.ComponentStatus{status}); phase != meshv1.MeshControlPlanePhaseReady {
+		t.Errorf("expected phase %s, got %s", meshv1.MeshControlPlanePhaseReady, phase)
+	}
+
+	deploy.Generation = 3
+	status = DeploymentStatus(deploy, IngressControllerContainerName)
+	if status.Message == "" {
+		t.Errorf("expected rolling out message")
+	}
+	if phase := Phase([]meshv1.ComponentStatus{status}); phase != meshv1.MeshControlPlanePhaseProgressing {
+		t.Errorf("expected phase %s, got %s", meshv1.MeshControlPlanePhaseProgressing, phase)
+	}
+}
@@ -20,8 +20,10 @@ package hook
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/megaease/easemesh/mesh-operator/pkg/base"
+	"github.com/megaease/easemesh/mesh-operator/pkg/metrics"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -50,17 +52,34 @@ func NewMutateHook(baseRuntime *base.Runtime) *MutateHook {
 }
 
 func (h *MutateHook) mutateHandler(cxt context.Context, req admission.Request) admission.Response {
-	if !h.needInject(&req) {
-		return ignoreResp(&req)
+	startTime := time.Now()
+	inject, reason := h.needInject(&req)
+	if !inject {
+		metrics.ObserveInjection(req.Kind.Kind, metrics.InjectionResultSkipped, startTime)
+		if reason == "" || req.Kind.Kind != "Pod" || req.Operation != admissionv1.Create {
+			return ignoreResp(&req)
+		}
+
+		// NOTE: The operator reports the annotation as an event of the pod after it's created.
+		currentRaw, err := h.annotateSkippedPod(&req, reason)
+		if err != nil {
+			h.Log.Error(err, "")
+			return ignoreResp(&req)
+		}
+		return admission.PatchResponseFromRaw(req.Object.Raw, currentRaw)
 	}
 
 	h.Log.Info("mutate", "id", fmt.Sprintf("%s %s/%s", req.Kind.Kind, req.Namespace, req.Name))
 	currentRaw, err := h.injectSidecar(&req)
 	if err != nil {
 		h.Log.Error(err, "")
+		metrics.ObserveInjection(req.Kind.Kind, metrics.InjectionResultFailed, startTime)
+		metrics.RecordError(h.Name, metrics.OperationInjectSidecar)
+		h.recordInjectionFailure(&req, err)
 		return errorResp(err)
 	}
 
+	metrics.ObserveInjection(req.Kind.Kind, metrics.InjectionResultInjected, startTime)
 	return admission.PatchResponseFromRaw(req.Object.Raw, currentRaw)
 }
 
@@ -20,7 +20,9 @@ package hook
 import (
 	"encoding/json"
 	"strconv"
+	"time"
 
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshservice"
 	"github.com/megaease/easemesh/mesh-operator/pkg/sidecarinjector"
 	"github.com/megaease/easemesh/mesh-operator/pkg/util/labelstool"
 	"github.com/pkg/errors"
@@ -29,18 +31,29 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 const (
 	annotationPrefix              = "mesh.megaease.com/"
-	annotationServiceNameKey      = annotationPrefix + "service-name"
+	annotationServiceNameKey      = sidecarinjector.ServiceNameAnnotationKey
 	annotationServiceLabels       = annotationPrefix + "service-labels"
 	annotationAppContainerNameKey = annotationPrefix + "app-container-name"
 	annotationApplicationPortKey  = annotationPrefix + "application-port"
 	annotationAliveProbeURLKey    = annotationPrefix + "alive-probe-url"
 	annotationInitContainerImage  = annotationPrefix + "init-container-image"
 	annotationSidecarImage        = annotationPrefix + "sidecar-image"
+	annotationDNSCapture          = annotationPrefix + "dns-capture"
+
+	annotationSpringCloudConfigURI     = annotationPrefix + "spring-cloud-config-uri"
+	annotationSpringCloudConfigLabel   = annotationPrefix + "spring-cloud-config-label"
+	annotationSpringCloudConfigProfile = annotationPrefix + "spring-cloud-config-profile"
+	annotationSpringCloudConfigPath    = annotationPrefix + "spring-cloud-config-path"
+
+	annotationDrainDuration   = annotationPrefix + "drain-duration"
+	annotationHoldApplication = annotationPrefix + "hold-application-until-sidecar-ready"
 
 	defaultAliveProbeURL = "http://localhost:9900/health"
 )
@@ -51,36 +64,55 @@ type (
 		metav1.TypeMeta   `json:",inline"`
 		metav1.ObjectMeta `json:"metadata"`
 	}
+
+	// annotationError is an invalid annotation of the object, which must be fixed by users.
+	annotationError struct {
+		error
+	}
 )
 
-func (h *MutateHook) needInject(req *admission.Request) bool {
+// needInject tells whether to inject the sidecar, or the reason not to
+// inject, which is empty if the object isn't concerned by the injection.
+func (h *MutateHook) needInject(req *admission.Request) (bool, string) {
 	switch req.Operation {
 	case admissionv1.Connect, admissionv1.Delete:
-		return false
+		return false, ""
 	}
 
 	switch req.Kind.Kind {
 	case "Pod", "ReplicaSet", "Deployment", "StatefulSet", "DaemonSet":
 	default:
-		return false
+		return false, ""
 	}
 
 	baseObject := &BaseObject{}
 	err := json.Unmarshal(req.Object.Raw, baseObject)
 	if err != nil {
 		h.Log.Error(err, "unmarshal json to base object", "raw", req.String())
-		return false
+		return false, ""
 	}
 
-	if baseObject.Annotations[annotationServiceNameKey] == "" {
-		return false
+	if h.serviceName(req.Kind.Kind, baseObject) == "" {
+		return false, "no annotation " + annotationServiceNameKey
 	}
 
-	return true
+	return true, ""
 }
 
-func (h *MutateHook) extractMeshService(baseObject *BaseObject) (*sidecarinjector.MeshService, error) {
-	name := baseObject.Annotations[annotationServiceNameKey]
+// serviceName returns the mesh service of the object, Deployments labeled to
+// enable the mesh are registered as services named after them by the operator.
+func (h *MutateHook) serviceName(kind string, baseObject *BaseObject) string {
+	if name := baseObject.Annotations[annotationServiceNameKey]; name != "" {
+		return name
+	}
+	if h.ServiceAutoRegistration && kind == "Deployment" && meshservice.Enabled(baseObject) {
+		return meshservice.ServiceName(baseObject)
+	}
+	return ""
+}
+
+func (h *MutateHook) extractMeshService(kind string, baseObject *BaseObject) (*sidecarinjector.MeshService, error) {
+	name := h.serviceName(kind, baseObject)
 	if name == "" {
 		return nil, errors.New("no service name")
 	}
@@ -105,14 +137,54 @@ func (h *MutateHook) extractMeshService(baseObject *BaseObject) (*sidecarinjecto
 		aliveProbeURL = defaultAliveProbeURL
 	}
 
+	var dnsCapture *bool
+	if dnsCaptureValue := baseObject.Annotations[annotationDNSCapture]; dnsCaptureValue != "" {
+		capture, err := strconv.ParseBool(dnsCaptureValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse dns capture %s", dnsCaptureValue)
+		}
+		dnsCapture = &capture
+	}
+
+	var drainDuration *time.Duration
+	if drainDurationValue := baseObject.Annotations[annotationDrainDuration]; drainDurationValue != "" {
+		duration, err := time.ParseDuration(drainDurationValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse drain duration %s", drainDurationValue)
+		}
+		if duration < 0 {
+			return nil, errors.Errorf("negative drain duration %s", drainDurationValue)
+		}
+		drainDuration = &duration
+	}
+
+	var holdApplication *bool
+	if holdApplicationValue := baseObject.Annotations[annotationHoldApplication]; holdApplicationValue != "" {
+		hold, err := strconv.ParseBool(holdApplicationValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse hold application %s", holdApplicationValue)
+		}
+		holdApplication = &hold
+	}
+
 	return &sidecarinjector.MeshService{
 		Name:               name,
+		Namespace:          baseObject.Namespace,
 		Labels:             labels,
 		AppContainerName:   baseObject.Annotations[annotationAppContainerNameKey],
 		AliveProbeURL:      aliveProbeURL,
 		ApplicationPort:    applicationPort,
 		InitContainerImage: baseObject.Annotations[annotationInitContainerImage],
 		SidecarImage:       baseObject.Annotations[annotationSidecarImage],
+		DNSCapture:         dnsCapture,
+
+		SpringCloudConfigURI:     baseObject.Annotations[annotationSpringCloudConfigURI],
+		SpringCloudConfigLabel:   baseObject.Annotations[annotationSpringCloudConfigLabel],
+		SpringCloudConfigProfile: baseObject.Annotations[annotationSpringCloudConfigProfile],
+		SpringCloudConfigPath:    baseObject.Annotations[annotationSpringCloudConfigPath],
+
+		DrainDuration:   drainDuration,
+		HoldApplication: holdApplication,
 	}, nil
 }
 
@@ -123,9 +195,13 @@ func (h *MutateHook) injectSidecar(req *admission.Request) ([]byte, error) {
 		return nil, errors.Wrapf(err, "unmarshal json %s to base object", req.String())
 	}
 
-	meshService, err := h.extractMeshService(baseObject)
+	meshService, err := h.extractMeshService(req.Kind.Kind, baseObject)
 	if err != nil {
-		return nil, err
+		return nil, &annotationError{err}
+	}
+	if meshService.Namespace == "" {
+		// NOTE: Pods created by controllers have no namespace in the object yet.
+		meshService.Namespace = req.Namespace
 	}
 
 	object := h.newObject(req.Kind.Kind)
@@ -142,12 +218,18 @@ func (h *MutateHook) injectSidecar(req *admission.Request) ([]byte, error) {
 		return nil, errors.Wrapf(err, "inject sidecar")
 	}
 
+	podMeta := h.getPodMeta(object)
+	podMeta.Labels = sidecarinjector.InjectedLabels(podMeta.Labels)
+	if req.Kind.Kind == "Pod" {
+		podMeta.Annotations = withInjectionStatus(podMeta.Annotations, sidecarinjector.InjectionStatusInjected)
+	}
+
 	currentRaw, err := json.Marshal(object)
 	if err != nil {
 		return nil, errors.Wrapf(err, "marshal %+v to json", object)
 	}
 
-	return currentRaw, nil
+	return restoreRestartPolicies(req.Kind.Kind, req.Object.Raw, currentRaw)
 }
 
 func (h *MutateHook) newObject(kind string) interface{} {
@@ -167,6 +249,23 @@ func (h *MutateHook) newObject(kind string) interface{} {
 	return nil
 }
 
+func (h *MutateHook) getPodMeta(object interface{}) *metav1.ObjectMeta {
+	switch obj := object.(type) {
+	case *corev1.Pod:
+		return &obj.ObjectMeta
+	case *v1.ReplicaSet:
+		return &obj.Spec.Template.ObjectMeta
+	case *v1.Deployment:
+		return &obj.Spec.Template.ObjectMeta
+	case *v1.StatefulSet:
+		return &obj.Spec.Template.ObjectMeta
+	case *v1.DaemonSet:
+		return &obj.Spec.Template.ObjectMeta
+	}
+
+	return nil
+}
+
 func (h *MutateHook) getPodSpec(object interface{}) *corev1.PodSpec {
 	switch obj := object.(type) {
 	case *corev1.Pod:
@@ -183,3 +282,127 @@ func (h *MutateHook) getPodSpec(object interface{}) *corev1.PodSpec {
 
 	return nil
 }
+
+// annotateSkippedPod records why the pod is skipped in its annotation. Pods
+// injected by the templates of their workloads are recorded as injected.
+func (h *MutateHook) annotateSkippedPod(req *admission.Request, reason string) ([]byte, error) {
+	pod := &corev1.Pod{}
+	err := json.Unmarshal(req.Object.Raw, pod)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal json %s", req.String())
+	}
+
+	status := sidecarinjector.InjectionSkipped(reason)
+	if pod.Labels[sidecarinjector.InjectedLabelKey] == "true" {
+		status = sidecarinjector.InjectionStatusInjected
+	}
+	pod.Annotations = withInjectionStatus(pod.Annotations, status)
+
+	currentRaw, err := json.Marshal(pod)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal %+v to json", pod)
+	}
+
+	return restoreRestartPolicies(req.Kind.Kind, req.Object.Raw, currentRaw)
+}
+
+// restoreRestartPolicies carries restartPolicy of init containers, i.e. native sidecars,
+// over the round trip of the object in the Kubernetes API the operator built with,
+// otherwise the patch of the response removes them.
+func restoreRestartPolicies(kind string, originalRaw, currentRaw []byte) ([]byte, error) {
+	original, current := map[string]interface{}{}, map[string]interface{}{}
+	err := json.Unmarshal(originalRaw, &original)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal original json")
+	}
+	err = json.Unmarshal(currentRaw, &current)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal current json")
+	}
+
+	fields := []string{"spec", "template", "spec"}
+	if kind == "Pod" {
+		fields = []string{"spec"}
+	}
+	originalSpec, _, _ := unstructured.NestedFieldNoCopy(original, fields...)
+	currentSpec, _, _ := unstructured.NestedFieldNoCopy(current, fields...)
+	originalPodSpec, _ := originalSpec.(map[string]interface{})
+	currentPodSpec, ok := currentSpec.(map[string]interface{})
+	if !ok || !sidecarinjector.RestoreRestartPolicies(originalPodSpec, currentPodSpec) {
+		return currentRaw, nil
+	}
+
+	restoredRaw, err := json.Marshal(current)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal restored json")
+	}
+
+	return restoredRaw, nil
+}
+
+// recordInjectionFailure records the failure as an event of the object, or of its
+// controller if the object is being created without a UID, e.g. the ReplicaSet of a pod.
+func (h *MutateHook) recordInjectionFailure(req *admission.Request, injectErr error) {
+	// NOTE: The webhook declares no side effects on dry runs.
+	if req.DryRun != nil && *req.DryRun {
+		return
+	}
+
+	baseObject := &BaseObject{}
+	err := json.Unmarshal(req.Object.Raw, baseObject)
+	if err != nil {
+		return
+	}
+
+	ref := involvedObject(req, baseObject)
+	if ref == nil {
+		return
+	}
+
+	reason := sidecarinjector.ReasonSidecarInjectionFailed
+	var annotationErr *annotationError
+	if errors.As(injectErr, &annotationErr) {
+		reason = sidecarinjector.ReasonValidationFailed
+	}
+
+	h.Recorder.Eventf(ref, corev1.EventTypeWarning, reason,
+		"sidecar not injected into %s: %v", req.Kind.Kind, injectErr)
+}
+
+func involvedObject(req *admission.Request, baseObject *BaseObject) *corev1.ObjectReference {
+	namespace := baseObject.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+
+	if baseObject.UID != "" {
+		return &corev1.ObjectReference{
+			APIVersion: schema.GroupVersion{Group: req.Kind.Group, Version: req.Kind.Version}.String(),
+			Kind:       req.Kind.Kind,
+			Namespace:  namespace,
+			Name:       baseObject.Name,
+			UID:        baseObject.UID,
+		}
+	}
+
+	owner := metav1.GetControllerOf(baseObject)
+	if owner == nil {
+		return nil
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: owner.APIVersion,
+		Kind:       owner.Kind,
+		Namespace:  namespace,
+		Name:       owner.Name,
+		UID:        owner.UID,
+	}
+}
+
+func withInjectionStatus(annotations map[string]string, status string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[sidecarinjector.InjectionStatusAnnotationKey] = status
+	return annotations
+}
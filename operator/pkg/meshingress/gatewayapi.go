@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshingress
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// GatewayClassName is the GatewayClass served by the EaseMesh ingress controller.
+	GatewayClassName = "easemesh"
+
+	gatewayAPIGroup   = "gateway.networking.k8s.io"
+	gatewayAPIVersion = "v1alpha2"
+
+	pathMatchExact             = "Exact"
+	pathMatchPathPrefix        = "PathPrefix"
+	pathMatchRegularExpression = "RegularExpression"
+
+	filterURLRewrite         = "URLRewrite"
+	pathModifierFullPath     = "ReplaceFullPath"
+	pathModifierPrefixMatch  = "ReplacePrefixMatch"
+	defaultGatewayAPIPath    = "/"
+	httpRouteIngressNameForm = "gateway-%s-%s"
+)
+
+var (
+	// GatewayGVK is the GroupVersionKind of Gateway API Gateway.
+	GatewayGVK = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: gatewayAPIVersion, Kind: "Gateway"}
+	// HTTPRouteGVK is the GroupVersionKind of Gateway API HTTPRoute.
+	HTTPRouteGVK = schema.GroupVersionKind{Group: gatewayAPIGroup, Version: gatewayAPIVersion, Kind: "HTTPRoute"}
+)
+
+// NOTE: We only declare the part of Gateway API which the translation cares of,
+// so that we don't need to depend on the whole sigs.k8s.io/gateway-api module.
+type (
+	// Gateway is the Gateway API Gateway.
+	Gateway struct {
+		Spec GatewaySpec `json:"spec"`
+	}
+
+	// GatewaySpec is the spec of Gateway.
+	GatewaySpec struct {
+		GatewayClassName string `json:"gatewayClassName"`
+	}
+
+	// HTTPRoute is the Gateway API HTTPRoute.
+	HTTPRoute struct {
+		Spec HTTPRouteSpec `json:"spec"`
+	}
+
+	// HTTPRouteSpec is the spec of HTTPRoute.
+	HTTPRouteSpec struct {
+		ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+		Hostnames  []string          `json:"hostnames,omitempty"`
+		Rules      []HTTPRouteRule   `json:"rules,omitempty"`
+	}
+
+	// ParentReference references the Gateway which the HTTPRoute attaches to.
+	ParentReference struct {
+		Kind      *string `json:"kind,omitempty"`
+		Namespace *string `json:"namespace,omitempty"`
+		Name      string  `json:"name"`
+	}
+
+	// HTTPRouteRule is the rule of HTTPRoute.
+	HTTPRouteRule struct {
+		Matches     []HTTPRouteMatch  `json:"matches,omitempty"`
+		Filters     []HTTPRouteFilter `json:"filters,omitempty"`
+		BackendRefs []HTTPBackendRef  `json:"backendRefs,omitempty"`
+	}
+
+	// HTTPRouteMatch is the match of HTTPRouteRule.
+	HTTPRouteMatch struct {
+		Path *HTTPPathMatch `json:"path,omitempty"`
+	}
+
+	// HTTPPathMatch is the path match of HTTPRouteMatch.
+	HTTPPathMatch struct {
+		Type  *string `json:"type,omitempty"`
+		Value *string `json:"value,omitempty"`
+	}
+
+	// HTTPRouteFilter is the filter of HTTPRouteRule.
+	HTTPRouteFilter struct {
+		Type       string          `json:"type"`
+		URLRewrite *HTTPURLRewrite `json:"urlRewrite,omitempty"`
+	}
+
+	// HTTPURLRewrite is the URL rewrite filter.
+	HTTPURLRewrite struct {
+		Path *HTTPPathModifier `json:"path,omitempty"`
+	}
+
+	// HTTPPathModifier is the path modifier of HTTPURLRewrite.
+	HTTPPathModifier struct {
+		Type               string  `json:"type"`
+		ReplaceFullPath    *string `json:"replaceFullPath,omitempty"`
+		ReplacePrefixMatch *string `json:"replacePrefixMatch,omitempty"`
+	}
+
+	// HTTPBackendRef references the backend mesh service.
+	HTTPBackendRef struct {
+		Name string `json:"name"`
+	}
+)
+
+// HTTPRouteIngressName returns the name of the mesh ingress translated from the HTTPRoute.
+func HTTPRouteIngressName(namespace, name string) string {
+	return fmt.Sprintf(httpRouteIngressNameForm, namespace, name)
+}
+
+// GatewayRefs returns the namespace/name of Gateways the HTTPRoute attaches to.
+func (r *HTTPRoute) GatewayRefs(routeNamespace string) [][2]string {
+	refs := [][2]string{}
+	for _, ref := range r.Spec.ParentRefs {
+		if ref.Kind != nil && *ref.Kind != "Gateway" {
+			continue
+		}
+		namespace := routeNamespace
+		if ref.Namespace != nil && *ref.Namespace != "" {
+			namespace = *ref.Namespace
+		}
+		refs = append(refs, [2]string{namespace, ref.Name})
+	}
+	return refs
+}
+
+// FromHTTPRoute translates the HTTPRoute into the mesh ingress.
+// Only the first backend of every rule is used, since the mesh ingress
+// routes every path to exactly one mesh service.
+func FromHTTPRoute(name string, route *HTTPRoute) *Ingress {
+	paths := []*IngressPath{}
+	for _, rule := range route.Spec.Rules {
+		if len(rule.BackendRefs) == 0 {
+			continue
+		}
+		backend := rule.BackendRefs[0].Name
+
+		matches := rule.Matches
+		if len(matches) == 0 {
+			matches = []HTTPRouteMatch{{}}
+		}
+
+		for _, match := range matches {
+			path, isPrefix := pathRegexp(match.Path)
+			paths = append(paths, &IngressPath{
+				Path:          path,
+				RewriteTarget: rewriteTarget(rule.Filters, isPrefix),
+				Backend:       backend,
+			})
+		}
+	}
+
+	ingress := &Ingress{Name: name}
+	hostnames := route.Spec.Hostnames
+	if len(hostnames) == 0 {
+		hostnames = []string{""}
+	}
+	for _, host := range hostnames {
+		ingress.Rules = append(ingress.Rules, &IngressRule{Host: host, Paths: paths})
+	}
+
+	return ingress
+}
+
+// pathRegexp returns the path regular expression of mesh ingress, and whether
+// the match is a prefix match. Prefixes match whole path elements, so /foo
+// matches /foo and /foo/bar but not /foobar, and the trailing slash of them is
+// ignored, which makes / match all paths. The rest of a prefix-matched path is
+// captured as ${1}, and as ${2} without the leading slash, for rewriting.
+func pathRegexp(match *HTTPPathMatch) (string, bool) {
+	matchType, value := pathMatchPathPrefix, defaultGatewayAPIPath
+	if match != nil {
+		if match.Type != nil {
+			matchType = *match.Type
+		}
+		if match.Value != nil {
+			value = *match.Value
+		}
+	}
+
+	switch matchType {
+	case pathMatchExact:
+		return "^" + regexp.QuoteMeta(value) + "$", false
+	case pathMatchRegularExpression:
+		return value, false
+	default:
+		return "^" + regexp.QuoteMeta(strings.TrimSuffix(value, "/")) + "(/(.*))?$", true
+	}
+}
+
+func rewriteTarget(filters []HTTPRouteFilter, isPrefix bool) string {
+	for _, filter := range filters {
+		if filter.Type != filterURLRewrite || filter.URLRewrite == nil || filter.URLRewrite.Path == nil {
+			continue
+		}
+
+		modifier := filter.URLRewrite.Path
+		switch modifier.Type {
+		case pathModifierFullPath:
+			if modifier.ReplaceFullPath != nil {
+				return *modifier.ReplaceFullPath
+			}
+		case pathModifierPrefixMatch:
+			if modifier.ReplacePrefixMatch != nil && isPrefix {
+				// NOTE: Joining the rest to / must not double the slash.
+				prefix := strings.TrimSuffix(*modifier.ReplacePrefixMatch, "/")
+				if prefix == "" {
+					return "/${2}"
+				}
+				return prefix + "${1}"
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshingress
+
+import (
+	"regexp"
+	"testing"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestFromHTTPRoute(t *testing.T) {
+	route := &HTTPRoute{
+		Spec: HTTPRouteSpec{
+			ParentRefs: []ParentReference{{Name: "mesh-gateway"}},
+			Hostnames:  []string{"orders.megaease.com"},
+			Rules: []HTTPRouteRule{
+				{
+					Matches: []HTTPRouteMatch{
+						{Path: &HTTPPathMatch{Type: strPtr(pathMatchPathPrefix), Value: strPtr("/api/")}},
+						{Path: &HTTPPathMatch{Type: strPtr(pathMatchExact), Value: strPtr("/healthz")}},
+					},
+					Filters: []HTTPRouteFilter{
+						{
+							Type: filterURLRewrite,
+							URLRewrite: &HTTPURLRewrite{
+								Path: &HTTPPathModifier{Type: pathModifierPrefixMatch, ReplacePrefixMatch: strPtr("/")},
+							},
+						},
+					},
+					BackendRefs: []HTTPBackendRef{{Name: "order-mesh"}, {Name: "ignored"}},
+				},
+				{
+					// No backend, ignored.
+					Matches: []HTTPRouteMatch{{}},
+				},
+			},
+		},
+	}
+
+	ingress := FromHTTPRoute(HTTPRouteIngressName("default", "orders"), route)
+	if ingress.Name != "gateway-default-orders" {
+		t.Fatalf("unexpected ingress name %s", ingress.Name)
+	}
+	if len(ingress.Rules) != 1 || ingress.Rules[0].Host != "orders.megaease.com" {
+		t.Fatalf("unexpected rules %+v", ingress.Rules)
+	}
+
+	paths := ingress.Rules[0].Paths
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(paths))
+	}
+
+	if paths[0].Path != "^/api(/(.*))?$" || paths[0].RewriteTarget != "/${2}" || paths[0].Backend != "order-mesh" {
+		t.Errorf("unexpected prefix path %+v", paths[0])
+	}
+	if paths[1].Path != "^/healthz$" || paths[1].RewriteTarget != "" {
+		t.Errorf("unexpected exact path %+v", paths[1])
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	for _, c := range []struct {
+		prefix        string
+		replacePrefix string
+		paths         map[string]string
+		unmatched     []string
+	}{
+		{
+			prefix:        "/foo",
+			replacePrefix: "/",
+			paths:         map[string]string{"/foo": "/", "/foo/": "/", "/foo/bar": "/bar"},
+			unmatched:     []string{"/foobar", "/fo", "/bar/foo"},
+		},
+		{
+			prefix:        "/foo/",
+			replacePrefix: "/v2/",
+			paths:         map[string]string{"/foo": "/v2", "/foo/": "/v2/", "/foo/bar": "/v2/bar"},
+			unmatched:     []string{"/foobar"},
+		},
+		{
+			prefix:        "/",
+			replacePrefix: "/v2",
+			paths:         map[string]string{"/bar": "/v2/bar", "/bar/baz": "/v2/bar/baz"},
+		},
+		{
+			prefix:        "/",
+			replacePrefix: "/",
+			paths:         map[string]string{"/": "/", "/bar": "/bar"},
+		},
+	} {
+		path, isPrefix := pathRegexp(&HTTPPathMatch{Type: strPtr(pathMatchPathPrefix), Value: strPtr(c.prefix)})
+		target := rewriteTarget([]HTTPRouteFilter{{
+			Type: filterURLRewrite,
+			URLRewrite: &HTTPURLRewrite{
+				Path: &HTTPPathModifier{Type: pathModifierPrefixMatch, ReplacePrefixMatch: strPtr(c.replacePrefix)},
+			},
+		}}, isPrefix)
+
+		re := regexp.MustCompile(path)
+		for requestPath, want := range c.paths {
+			if !re.MatchString(requestPath) {
+				t.Fatalf("prefix %s should match %s", c.prefix, requestPath)
+			}
+			if got := re.ReplaceAllString(requestPath, target); got != want {
+				t.Errorf("prefix %s replaced by %s should rewrite %s to %s, but got %s",
+					c.prefix, c.replacePrefix, requestPath, want, got)
+			}
+		}
+		for _, requestPath := range c.unmatched {
+			if re.MatchString(requestPath) {
+				t.Errorf("prefix %s should not match %s", c.prefix, requestPath)
+			}
+		}
+	}
+}
+
+func TestGatewayRefs(t *testing.T) {
+	route := &HTTPRoute{
+		Spec: HTTPRouteSpec{
+			ParentRefs: []ParentReference{
+				{Name: "local"},
+				{Name: "remote", Namespace: strPtr("gateways")},
+				{Name: "svc", Kind: strPtr("Service")},
+			},
+		},
+	}
+
+	refs := route.GatewayRefs("default")
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 gateway refs, got %v", refs)
+	}
+	if refs[0] != [2]string{"default", "local"} || refs[1] != [2]string{"gateways", "remote"} {
+		t.Errorf("unexpected gateway refs %v", refs)
+	}
+}
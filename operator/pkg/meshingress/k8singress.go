@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshingress
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	// IngressClassName is the IngressClass served by the EaseMesh ingress controller.
+	IngressClassName = "easemesh"
+
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+	k8sIngressNameForm     = "k8s-%s-%s"
+	catchAllPathRegexp     = "^/"
+
+	// SSLRedirectAnnotation set to "true" redirects HTTP requests of TLS hosts to HTTPS.
+	SSLRedirectAnnotation = "mesh.megaease.com/ssl-redirect"
+	// HSTSMaxAgeAnnotation is the max-age seconds of the Strict-Transport-Security
+	// header added to HTTPS responses of TLS hosts, unset means no header.
+	HSTSMaxAgeAnnotation = "mesh.megaease.com/hsts-max-age"
+	// HSTSIncludeSubDomainsAnnotation set to "true" adds includeSubDomains to the header.
+	HSTSIncludeSubDomainsAnnotation = "mesh.megaease.com/hsts-include-subdomains"
+	// ACMEChallengeAnnotation is http-01 or dns-01, TLS hosts without secrets get
+	// certificates by the ACME client of the ingress controller with the challenge.
+	ACMEChallengeAnnotation = "mesh.megaease.com/acme-challenge"
+)
+
+// K8sIngressName returns the name of the mesh ingress translated from the Kubernetes Ingress.
+func K8sIngressName(namespace, name string) string {
+	return fmt.Sprintf(k8sIngressNameForm, namespace, name)
+}
+
+// IsMeshIngressClass returns if the Kubernetes Ingress belongs to the EaseMesh ingress class.
+// The deprecated annotation kubernetes.io/ingress.class is also supported.
+func IsMeshIngressClass(ingress *networkingv1.Ingress) bool {
+	if ingress.Spec.IngressClassName != nil {
+		return *ingress.Spec.IngressClassName == IngressClassName
+	}
+	return ingress.Annotations[ingressClassAnnotation] == IngressClassName
+}
+
+// TLSSecretNames returns the names of secrets referenced by TLS sections.
+func TLSSecretNames(ingress *networkingv1.Ingress) []string {
+	names := []string{}
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" {
+			names = append(names, tls.SecretName)
+		}
+	}
+	return names
+}
+
+// FromK8sIngress translates the Kubernetes Ingress into the mesh ingress,
+// secrets are the TLS secrets indexed by name. Backends must be mesh services,
+// the port of service backends is ignored.
+func FromK8sIngress(name string, ingress *networkingv1.Ingress, secrets map[string]*v1.Secret) *Ingress {
+	result := &Ingress{Name: name}
+
+	var defaultPath *IngressPath
+	if ingress.Spec.DefaultBackend != nil && ingress.Spec.DefaultBackend.Service != nil {
+		defaultPath = &IngressPath{
+			Path:    catchAllPathRegexp,
+			Backend: ingress.Spec.DefaultBackend.Service.Name,
+		}
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		ingressRule := &IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service == nil {
+					continue
+				}
+				ingressRule.Paths = append(ingressRule.Paths, &IngressPath{
+					Path:    k8sPathRegexp(path.Path, path.PathType),
+					Backend: path.Backend.Service.Name,
+				})
+			}
+		}
+		if defaultPath != nil {
+			ingressRule.Paths = append(ingressRule.Paths, defaultPath)
+		}
+		result.Rules = append(result.Rules, ingressRule)
+	}
+
+	if len(result.Rules) == 0 && defaultPath != nil {
+		result.Rules = append(result.Rules, &IngressRule{Paths: []*IngressPath{defaultPath}})
+	}
+
+	for _, tls := range ingress.Spec.TLS {
+		secret := secrets[tls.SecretName]
+		if secret == nil {
+			continue
+		}
+		result.TLS = append(result.TLS, &IngressTLS{
+			Hosts:      tls.Hosts,
+			CertBase64: base64.StdEncoding.EncodeToString(secret.Data[v1.TLSCertKey]),
+			KeyBase64:  base64.StdEncoding.EncodeToString(secret.Data[v1.TLSPrivateKeyKey]),
+		})
+	}
+	result.HostPolicies = hostPolicies(ingress)
+
+	return result
+}
+
+// hostPolicies returns the HTTPS policies of TLS hosts by annotations of the
+// Kubernetes Ingress, invalid values are ignored like unset.
+func hostPolicies(ingress *networkingv1.Ingress) []*IngressHostPolicy {
+	sslRedirect := ingress.Annotations[SSLRedirectAnnotation] == "true"
+	var hsts *IngressHSTS
+	if maxAge, err := strconv.Atoi(ingress.Annotations[HSTSMaxAgeAnnotation]); err == nil && maxAge > 0 {
+		hsts = &IngressHSTS{
+			MaxAge:            maxAge,
+			IncludeSubDomains: ingress.Annotations[HSTSIncludeSubDomainsAnnotation] == "true",
+		}
+	}
+	acmeChallenge := ingress.Annotations[ACMEChallengeAnnotation]
+	if acmeChallenge != "http-01" && acmeChallenge != "dns-01" {
+		acmeChallenge = ""
+	}
+	if !sslRedirect && hsts == nil && acmeChallenge == "" {
+		return nil
+	}
+
+	policies := []*IngressHostPolicy{}
+	hosts := map[string]bool{}
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			if host == "" || hosts[host] {
+				continue
+			}
+			hosts[host] = true
+			policy := &IngressHostPolicy{Host: host, SSLRedirect: sslRedirect, HSTS: hsts}
+			if tls.SecretName == "" {
+				policy.ACMEChallenge = acmeChallenge
+			}
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// k8sPathRegexp converts path of Kubernetes Ingress into regular expression.
+// Prefix matches are element-wise, so /foo matches /foo and /foo/bar, but not /foobar.
+// ImplementationSpecific path is regarded as a regular expression as it is.
+func k8sPathRegexp(path string, pathType *networkingv1.PathType) string {
+	if path == "" {
+		path = "/"
+	}
+
+	matchType := networkingv1.PathTypePrefix
+	if pathType != nil {
+		matchType = *pathType
+	}
+
+	switch matchType {
+	case networkingv1.PathTypeExact:
+		return "^" + regexp.QuoteMeta(path) + "$"
+	case networkingv1.PathTypeImplementationSpecific:
+		return path
+	default:
+		return "^" + regexp.QuoteMeta(strings.TrimSuffix(path, "/")) + "(/.*)?$"
+	}
+}
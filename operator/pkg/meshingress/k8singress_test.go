@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshingress
+
+import (
+	"encoding/base64"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func serviceBackend(name string) networkingv1.IngressBackend {
+	return networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: name},
+	}
+}
+
+func TestIsMeshIngressClass(t *testing.T) {
+	className := IngressClassName
+	otherName := "nginx"
+
+	cases := []struct {
+		ingress  *networkingv1.Ingress
+		expected bool
+	}{
+		{&networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: &className}}, true},
+		{&networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: &otherName}}, false},
+		{&networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ingressClassAnnotation: IngressClassName}}}, true},
+		{&networkingv1.Ingress{}, false},
+	}
+
+	for i, c := range cases {
+		if got := IsMeshIngressClass(c.ingress); got != c.expected {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, got)
+		}
+	}
+}
+
+func TestFromK8sIngress(t *testing.T) {
+	exact := networkingv1.PathTypeExact
+	prefix := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "default-mesh"},
+			},
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"orders.megaease.com"}, SecretName: "orders-tls"},
+				{Hosts: []string{"missing.megaease.com"}, SecretName: "missing-tls"},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "orders.megaease.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{Path: "/order/", PathType: &prefix, Backend: serviceBackend("order-mesh")},
+								{Path: "/healthz", PathType: &exact, Backend: serviceBackend("order-mesh")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	secrets := map[string]*v1.Secret{
+		"orders-tls": {Data: map[string][]byte{v1.TLSCertKey: []byte("cert"), v1.TLSPrivateKeyKey: []byte("key")}},
+	}
+
+	result := FromK8sIngress(K8sIngressName("default", "orders"), ingress, secrets)
+	if result.Name != "k8s-default-orders" {
+		t.Fatalf("unexpected ingress name %s", result.Name)
+	}
+	if len(result.Rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(result.Rules))
+	}
+
+	paths := result.Rules[0].Paths
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 paths, got %d", len(paths))
+	}
+	if paths[0].Path != "^/order(/.*)?$" || paths[1].Path != "^/healthz$" || paths[2].Backend != "default-mesh" {
+		t.Errorf("unexpected paths %+v %+v %+v", paths[0], paths[1], paths[2])
+	}
+
+	if len(result.TLS) != 1 {
+		t.Fatalf("expected 1 tls, got %d", len(result.TLS))
+	}
+	if result.TLS[0].CertBase64 != base64.StdEncoding.EncodeToString([]byte("cert")) {
+		t.Errorf("unexpected cert %s", result.TLS[0].CertBase64)
+	}
+}
+
+func TestHostPolicies(t *testing.T) {
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			SSLRedirectAnnotation:           "true",
+			HSTSMaxAgeAnnotation:            "31536000",
+			HSTSIncludeSubDomainsAnnotation: "true",
+		}},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"orders.megaease.com", "pets.megaease.com"}, SecretName: "megaease-tls"},
+				{Hosts: []string{"orders.megaease.com"}, SecretName: "orders-tls"},
+			},
+		},
+	}
+
+	policies := hostPolicies(ingress)
+	if len(policies) != 2 || policies[0].Host != "orders.megaease.com" || policies[1].Host != "pets.megaease.com" {
+		t.Fatalf("expected policies of 2 TLS hosts, got %+v", policies)
+	}
+	if !policies[0].SSLRedirect || policies[0].HSTS == nil || policies[0].HSTS.MaxAge != 31536000 || !policies[0].HSTS.IncludeSubDomains {
+		t.Errorf("unexpected policy %+v", policies[0])
+	}
+
+	ingress.Annotations = map[string]string{ACMEChallengeAnnotation: "dns-01"}
+	ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1.IngressTLS{Hosts: []string{"*.megaease.com"}})
+	policies = hostPolicies(ingress)
+	if len(policies) != 3 || policies[0].ACMEChallenge != "" || policies[2].ACMEChallenge != "dns-01" {
+		t.Fatalf("expected the ACME challenge for the host without secret, got %+v", policies)
+	}
+
+	ingress.Annotations = map[string]string{HSTSMaxAgeAnnotation: "forever"}
+	if policies := hostPolicies(ingress); policies != nil {
+		t.Errorf("expected no policies, got %+v", policies)
+	}
+}
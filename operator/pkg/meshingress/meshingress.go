@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package meshingress translates ingress configurations from other sources
+// (e.g. Kubernetes Gateway API, Kubernetes Ingress) into EaseMesh ingresses.
+package meshingress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	meshIngressesURL = "http://%s/apis/v1/mesh/ingresses"
+	meshIngressURL   = "http://%s/apis/v1/mesh/ingresses/%s"
+)
+
+type (
+	// Ingress is the EaseMesh ingress resource.
+	Ingress struct {
+		Name  string         `json:"name"`
+		Rules []*IngressRule `json:"rules"`
+		TLS   []*IngressTLS  `json:"tls,omitempty"`
+		// HostPolicies are the HTTPS policies of hosts of the rules.
+		HostPolicies []*IngressHostPolicy `json:"hostPolicies,omitempty"`
+	}
+
+	// IngressHostPolicy is the HTTPS policy of a host.
+	IngressHostPolicy struct {
+		Host string `json:"host"`
+		// SSLRedirect redirects HTTP requests of the host to HTTPS with 301.
+		SSLRedirect bool         `json:"sslRedirect,omitempty"`
+		HSTS        *IngressHSTS `json:"hsts,omitempty"`
+		// ACMEChallenge obtains the certificate of the host by ACME with the challenge.
+		ACMEChallenge string `json:"acmeChallenge,omitempty"`
+	}
+
+	// IngressHSTS is the Strict-Transport-Security header added to HTTPS responses.
+	IngressHSTS struct {
+		MaxAge            int  `json:"maxAge"`
+		IncludeSubDomains bool `json:"includeSubDomains,omitempty"`
+	}
+
+	// IngressTLS is the certificate used by the hosts of the ingress.
+	IngressTLS struct {
+		Hosts []string `json:"hosts"`
+		// CertBase64 and KeyBase64 are base64 encoded PEM.
+		CertBase64 string `json:"certBase64"`
+		KeyBase64  string `json:"keyBase64"`
+	}
+
+	// IngressRule is the rule of an ingress for one host.
+	IngressRule struct {
+		Host  string         `json:"host,omitempty"`
+		Paths []*IngressPath `json:"paths"`
+	}
+
+	// IngressPath routes requests matching Path to the Backend mesh service.
+	IngressPath struct {
+		// Path is a regular expression.
+		Path          string `json:"path"`
+		RewriteTarget string `json:"rewriteTarget,omitempty"`
+		Backend       string `json:"backend"`
+	}
+
+	// Client operates ingresses of the EaseMesh control plane.
+	Client interface {
+		// Apply creates the ingress or updates it if it already exists.
+		Apply(ctx context.Context, ingress *Ingress) error
+		// Delete deletes the ingress, it's not an error if the ingress doesn't exist.
+		Delete(ctx context.Context, name string) error
+	}
+
+	httpClient struct {
+		apiAddr string
+		client  *http.Client
+	}
+)
+
+// NewClient creates a client talking to the EaseMesh control plane API address.
+func NewClient(apiAddr string) Client {
+	return &httpClient{
+		apiAddr: apiAddr,
+		client:  http.DefaultClient,
+	}
+}
+
+func (c *httpClient) Apply(ctx context.Context, ingress *Ingress) error {
+	body, err := json.Marshal(ingress)
+	if err != nil {
+		return errors.Wrapf(err, "marshal ingress %s", ingress.Name)
+	}
+
+	statusCode, err := c.do(ctx, http.MethodPost, fmt.Sprintf(meshIngressesURL, c.apiAddr), body)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusConflict {
+		return nil
+	}
+
+	_, err = c.do(ctx, http.MethodPut, fmt.Sprintf(meshIngressURL, c.apiAddr, ingress.Name), body)
+	return err
+}
+
+func (c *httpClient) Delete(ctx context.Context, name string) error {
+	statusCode, err := c.do(ctx, http.MethodDelete, fmt.Sprintf(meshIngressURL, c.apiAddr, name), nil)
+	if err != nil && statusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// do sends the request, the status code is returned even if the response is failed.
+func (c *httpClient) do(ctx context.Context, method, url string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrapf(err, "new request %s %s", method, url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "call %s %s", method, url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	// NOTE: Conflict is handled by the caller.
+	if resp.StatusCode == http.StatusConflict {
+		return resp.StatusCode, nil
+	}
+
+	text, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, errors.Errorf("call %s %s failed, return statuscode %d text %s",
+		method, url, resp.StatusCode, text)
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package meshservice registers Deployments labeled to enable the mesh as
+// services of the EaseMesh control plane, under the default tenant of their
+// namespaces, so simple services need no Service resources authored.
+package meshservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EnableLabelKey is the label of Deployments registered as mesh services.
+	EnableLabelKey = "mesh.megaease.com/enable"
+	// RegisteredServiceAnnotationKey is the annotation of Deployments recording
+	// the mesh service registered by the operator, services authored by users
+	// aren't recorded, so they're never deleted by the operator.
+	RegisteredServiceAnnotationKey = "mesh.megaease.com/registered-service"
+	// RegistrationFinalizer deregisters the mesh service before the Deployment is deleted.
+	RegistrationFinalizer = "mesh.megaease.com/service-registration"
+
+	serviceNameAnnotationKey = "mesh.megaease.com/service-name"
+
+	defaultLoadBalancePolicy = "roundRobin"
+	defaultDiscoveryType     = "eureka"
+	defaultSidecarAddress    = "127.0.0.1"
+	defaultSidecarProtocol   = "http"
+	defaultIngressPort       = 13001
+	defaultEgressPort        = 13002
+
+	meshTenantsURL  = "http://%s/apis/v1/mesh/tenants"
+	meshServicesURL = "http://%s/apis/v1/mesh/services"
+	meshServiceURL  = "http://%s/apis/v1/mesh/services/%s"
+)
+
+type (
+	// Tenant is the EaseMesh tenant resource.
+	Tenant struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Service is the EaseMesh service resource.
+	Service struct {
+		Name           string       `json:"name"`
+		RegisterTenant string       `json:"registerTenant"`
+		LoadBalance    *LoadBalance `json:"loadBalance"`
+		Sidecar        *Sidecar     `json:"sidecar"`
+	}
+
+	// LoadBalance is the load balance policy of a service.
+	LoadBalance struct {
+		Policy string `json:"policy"`
+	}
+
+	// Sidecar is the sidecar spec of a service.
+	Sidecar struct {
+		DiscoveryType   string `json:"discoveryType"`
+		Address         string `json:"address"`
+		IngressPort     int    `json:"ingressPort"`
+		IngressProtocol string `json:"ingressProtocol"`
+		EgressPort      int    `json:"egressPort"`
+		EgressProtocol  string `json:"egressProtocol"`
+	}
+
+	// Client registers services to the EaseMesh control plane.
+	Client interface {
+		// CreateTenant creates the tenant, created is false if it already exists.
+		CreateTenant(ctx context.Context, tenant *Tenant) (created bool, err error)
+		// CreateService creates the service, created is false if it already exists.
+		CreateService(ctx context.Context, service *Service) (created bool, err error)
+		// DeleteService deletes the service, it's not an error if the service doesn't exist.
+		DeleteService(ctx context.Context, name string) error
+	}
+
+	httpClient struct {
+		apiAddr string
+		client  *http.Client
+	}
+)
+
+// Enabled tells whether the object is labeled to be registered as a mesh service.
+func Enabled(obj metav1.Object) bool {
+	return obj.GetLabels()[EnableLabelKey] == "true"
+}
+
+// Registered tells whether the mesh service of the object is registered by the operator.
+func Registered(obj metav1.Object) bool {
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer == RegistrationFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceName returns the mesh service name of the object, which is the
+// annotation mesh.megaease.com/service-name or the name of the object.
+func ServiceName(obj metav1.Object) string {
+	if name := obj.GetAnnotations()[serviceNameAnnotationKey]; name != "" {
+		return name
+	}
+	return obj.GetName()
+}
+
+// DefaultTenant returns the default tenant of the namespace, which is named after it.
+func DefaultTenant(namespace string) *Tenant {
+	return &Tenant{
+		Name:        namespace,
+		Description: fmt.Sprintf("Default tenant of namespace %s, created by the EaseMesh operator", namespace),
+	}
+}
+
+// DefaultService returns the service registered for Deployments, with the
+// default sidecar spec of emctl.
+func DefaultService(name, tenant string) *Service {
+	return &Service{
+		Name:           name,
+		RegisterTenant: tenant,
+		LoadBalance:    &LoadBalance{Policy: defaultLoadBalancePolicy},
+		Sidecar: &Sidecar{
+			DiscoveryType:   defaultDiscoveryType,
+			Address:         defaultSidecarAddress,
+			IngressPort:     defaultIngressPort,
+			IngressProtocol: defaultSidecarProtocol,
+			EgressPort:      defaultEgressPort,
+			EgressProtocol:  defaultSidecarProtocol,
+		},
+	}
+}
+
+// NewClient creates a client talking to the EaseMesh control plane API address.
+func NewClient(apiAddr string) Client {
+	return &httpClient{
+		apiAddr: apiAddr,
+		client:  http.DefaultClient,
+	}
+}
+
+func (c *httpClient) CreateTenant(ctx context.Context, tenant *Tenant) (bool, error) {
+	body, err := json.Marshal(tenant)
+	if err != nil {
+		return false, errors.Wrapf(err, "marshal tenant %s", tenant.Name)
+	}
+
+	return c.create(ctx, fmt.Sprintf(meshTenantsURL, c.apiAddr), body)
+}
+
+func (c *httpClient) CreateService(ctx context.Context, service *Service) (bool, error) {
+	body, err := json.Marshal(service)
+	if err != nil {
+		return false, errors.Wrapf(err, "marshal service %s", service.Name)
+	}
+
+	return c.create(ctx, fmt.Sprintf(meshServicesURL, c.apiAddr), body)
+}
+
+func (c *httpClient) DeleteService(ctx context.Context, name string) error {
+	statusCode, err := c.do(ctx, http.MethodDelete, fmt.Sprintf(meshServiceURL, c.apiAddr, name), nil)
+	if err != nil && statusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// create posts the resource, existing ones are left as they are,
+// since they could be authored by users.
+func (c *httpClient) create(ctx context.Context, url string, body []byte) (bool, error) {
+	statusCode, err := c.do(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return false, err
+	}
+	return statusCode != http.StatusConflict, nil
+}
+
+// do sends the request, the status code is returned even if the response is failed.
+func (c *httpClient) do(ctx context.Context, method, url string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrapf(err, "new request %s %s", method, url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "call %s %s", method, url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	// NOTE: Conflict is handled by the caller.
+	if resp.StatusCode == http.StatusConflict {
+		return resp.StatusCode, nil
+	}
+
+	text, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, errors.Errorf("call %s %s failed, return statuscode %d text %s",
+		method, url, resp.StatusCode, text)
+}
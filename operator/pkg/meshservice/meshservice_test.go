@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceName(t *testing.T) {
+	cases := []struct {
+		obj      *metav1.ObjectMeta
+		expected string
+	}{
+		{&metav1.ObjectMeta{Name: "order"}, "order"},
+		{&metav1.ObjectMeta{Name: "order-v2", Annotations: map[string]string{serviceNameAnnotationKey: "order"}}, "order"},
+	}
+
+	for i, c := range cases {
+		if got := ServiceName(c.obj); got != c.expected {
+			t.Errorf("case %d: expected %s, got %s", i, c.expected, got)
+		}
+	}
+}
+
+func TestEnabledAndRegistered(t *testing.T) {
+	obj := &metav1.ObjectMeta{Labels: map[string]string{EnableLabelKey: "true"}}
+	if !Enabled(obj) || Registered(obj) {
+		t.Errorf("expected enabled and unregistered")
+	}
+
+	obj = &metav1.ObjectMeta{Labels: map[string]string{EnableLabelKey: "false"}, Finalizers: []string{RegistrationFinalizer}}
+	if Enabled(obj) || !Registered(obj) {
+		t.Errorf("expected disabled and registered")
+	}
+}
+
+func TestClient(t *testing.T) {
+	existing := map[string]bool{"/apis/v1/mesh/tenants": true}
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && existing[r.URL.Path]:
+			w.WriteHeader(http.StatusConflict)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(strings.TrimPrefix(server.URL, "http://"))
+	ctx := context.Background()
+
+	created, err := client.CreateTenant(ctx, DefaultTenant("shop"))
+	if err != nil || created {
+		t.Errorf("expected existing tenant, got created %v err %v", created, err)
+	}
+
+	created, err = client.CreateService(ctx, DefaultService("order", "shop"))
+	if err != nil || !created {
+		t.Errorf("expected created service, got created %v err %v", created, err)
+	}
+
+	if err := client.DeleteService(ctx, "missing"); err != nil {
+		t.Errorf("expected no error deleting missing service, got %v", err)
+	}
+	if err := client.DeleteService(ctx, "order"); err != nil {
+		t.Errorf("delete service: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/apis/v1/mesh/services/order" {
+		t.Errorf("expected order deleted, got %v", deleted)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// NOTE: Durations and errors of reconciling, and latencies of webhooks are
+// exported by controller-runtime already, as controller_runtime_reconcile_time_seconds,
+// controller_runtime_reconcile_errors_total and controller_runtime_webhook_latency_seconds.
+
+const (
+	namespace = "easemesh"
+	subsystem = "operator"
+
+	// InjectionResultInjected means the sidecar is injected.
+	InjectionResultInjected = "injected"
+	// InjectionResultSkipped means the object needs no injection.
+	InjectionResultSkipped = "skipped"
+	// InjectionResultFailed means the injection failed, the object is rejected.
+	InjectionResultFailed = "failed"
+
+	// OperationInjectSidecar is the operation injecting sidecars.
+	OperationInjectSidecar = "inject_sidecar"
+	// OperationSyncDeployment is the operation syncing MeshDeployments to Deployments.
+	OperationSyncDeployment = "sync_deployment"
+	// OperationApplyMeshIngress is the operation applying mesh ingresses to the control plane.
+	OperationApplyMeshIngress = "apply_mesh_ingress"
+	// OperationSyncControlPlane is the operation applying MeshControlPlanes to components of the installation.
+	OperationSyncControlPlane = "sync_control_plane"
+	// OperationSelfHeal is the operation reverting drift of objects applied by emctl install.
+	OperationSelfHeal = "self_heal"
+	// OperationRegisterService is the operation registering Deployments as mesh services.
+	OperationRegisterService = "register_service"
+	// OperationSyncTrafficPolicy is the operation converting traffic policy annotations into mesh resources.
+	OperationSyncTrafficPolicy = "sync_traffic_policy"
+)
+
+var (
+	// SidecarInjections counts admission requests handled by the mutating webhook.
+	SidecarInjections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "sidecar_injections_total",
+		Help:      "Total number of admission requests handled by the sidecar injector, by kind and result.",
+	}, []string{"kind", "result"})
+
+	// SidecarInjectionDuration observes durations of injecting sidecars.
+	SidecarInjectionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "sidecar_injection_duration_seconds",
+		Help:      "Duration of injecting sidecars into objects, by kind.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"kind"})
+
+	// Errors counts errors of operations by components.
+	Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "errors_total",
+		Help:      "Total number of errors of the operator, by component and operation.",
+	}, []string{"component", "operation"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(SidecarInjections, SidecarInjectionDuration, Errors)
+}
+
+// ObserveInjection records an admission request handled by the
+// sidecar injector, which started at startTime.
+func ObserveInjection(kind, result string, startTime time.Time) {
+	SidecarInjections.WithLabelValues(kind, result).Inc()
+	if result != InjectionResultSkipped {
+		SidecarInjectionDuration.WithLabelValues(kind).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// RecordError records an error of the operation in the component.
+func RecordError(component, operation string) {
+	Errors.WithLabelValues(component, operation).Inc()
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveInjection(t *testing.T) {
+	ObserveInjection("Deployment", InjectionResultInjected, time.Now())
+	ObserveInjection("Deployment", InjectionResultSkipped, time.Now())
+	ObserveInjection("Deployment", InjectionResultSkipped, time.Now())
+
+	if got := testutil.ToFloat64(SidecarInjections.WithLabelValues("Deployment", InjectionResultInjected)); got != 1 {
+		t.Errorf("injected count: want 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(SidecarInjections.WithLabelValues("Deployment", InjectionResultSkipped)); got != 2 {
+		t.Errorf("skipped count: want 2, got %v", got)
+	}
+	// NOTE: Skipped requests are not observed by the duration histogram.
+	if got := testutil.CollectAndCount(SidecarInjectionDuration); got != 1 {
+		t.Errorf("duration series: want 1, got %v", got)
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	RecordError("MeshDeployment", OperationSyncDeployment)
+
+	if got := testutil.ToFloat64(Errors.WithLabelValues("MeshDeployment", OperationSyncDeployment)); got != 1 {
+		t.Errorf("error count: want 1, got %v", got)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package selfheal compares objects applied by emctl install with
+// their live copies in the cluster and reverts manual edits.
+package selfheal
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// NOTE: The names must be consistent with the ones saved by emctl install --self-heal.
+const (
+	// SnapshotSecretName is the name of the Secret holding the snapshot of the installation.
+	SnapshotSecretName = "easemesh-install-snapshot"
+	// SnapshotSecretKey is the key of the objects in the snapshot Secret.
+	SnapshotSecretKey = "objects.json"
+)
+
+// ignoredFields are the fields changed legitimately after the installation,
+// e.g. by emctl scale, emctl upgrade or the MeshControlPlane reconciler.
+// The "*" element matches every item of a list.
+var ignoredFields = map[string][][]string{
+	"Deployment": {
+		{"spec", "replicas"},
+		{"spec", "template", "spec", "containers", "*", "image"},
+	},
+	"StatefulSet": {
+		{"spec", "replicas"},
+		{"spec", "updateStrategy"},
+		{"spec", "template", "spec", "containers", "*", "image"},
+		{"spec", "template", "spec", "containers", "*", "args"},
+	},
+}
+
+// Decode decodes the objects in the snapshot.
+func Decode(data []byte) ([]*unstructured.Unstructured, error) {
+	// NOTE: util/json decodes numbers into int64 like the unstructured client does.
+	items := []interface{}{}
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal snapshot")
+	}
+
+	objects := []*unstructured.Unstructured{}
+	for i, item := range items {
+		m, _ := item.(map[string]interface{})
+		object, _ := m["object"].(map[string]interface{})
+		if object == nil {
+			return nil, errors.Errorf("object %d of snapshot is empty", i)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: object})
+	}
+
+	return objects, nil
+}
+
+// Heal reverts the drift of live from desired in place,
+// it reports whether live is changed.
+func Heal(desired, live *unstructured.Unstructured) bool {
+	fields := desiredFields(desired)
+	if !drifted(fields, live.Object) {
+		return false
+	}
+
+	merge(fields, live.Object)
+	return true
+}
+
+// desiredFields returns the fields of desired kept by self-healing.
+func desiredFields(desired *unstructured.Unstructured) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for k, v := range desired.DeepCopy().Object {
+		switch k {
+		case "apiVersion", "kind", "status":
+		case "metadata":
+			metadata := map[string]interface{}{}
+			m, _ := v.(map[string]interface{})
+			for _, key := range []string{"labels", "annotations"} {
+				if m[key] != nil {
+					metadata[key] = m[key]
+				}
+			}
+			fields[k] = metadata
+		default:
+			fields[k] = v
+		}
+	}
+
+	for _, path := range ignoredFields[desired.GetKind()] {
+		removeField(fields, path)
+	}
+
+	return fields
+}
+
+func removeField(obj interface{}, path []string) {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(v, path[0])
+			return
+		}
+		removeField(v[path[0]], path[1:])
+	case []interface{}:
+		if path[0] != "*" {
+			return
+		}
+		for _, item := range v {
+			if len(path) > 1 {
+				removeField(item, path[1:])
+			}
+		}
+	}
+}
+
+// drifted reports whether live doesn't contain desired.
+func drifted(desired, live interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return false
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return len(d) != 0
+		}
+		for k, v := range d {
+			if drifted(v, l[k]) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return len(d) != 0
+		}
+		if len(d) != len(l) {
+			return true
+		}
+		for i := range d {
+			if drifted(d[i], l[i]) {
+				return true
+			}
+		}
+		return false
+	default:
+		return desired != live
+	}
+}
+
+// merge merges desired into live, it keeps fields of live
+// which are not in desired such as defaulted ones.
+func merge(desired, live interface{}) interface{} {
+	switch d := desired.(type) {
+	case nil:
+		return live
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return d
+		}
+		for k, v := range d {
+			l[k] = merge(v, l[k])
+		}
+		return l
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(d) != len(l) {
+			return d
+		}
+		for i := range d {
+			l[i] = merge(d[i], l[i])
+		}
+		return l
+	default:
+		return desired
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selfheal
+
+import (
+	"testing"
+)
+
+const snapshot = `[
+  {
+    "group": "apps", "version": "v1", "resource": "deployments",
+    "namespace": "easemesh", "name": "easemesh-operator", "stage": "operator",
+    "object": {
+      "apiVersion": "apps/v1",
+      "kind": "Deployment",
+      "metadata": {"name": "easemesh-operator", "namespace": "easemesh", "labels": {"app": "operator"}, "creationTimestamp": null},
+      "spec": {
+        "replicas": 1,
+        "template": {
+          "spec": {
+            "containers": [{"name": "operator-manager", "image": "megaease/easemesh-operator:v1", "args": ["--config=/opt/config.yaml"]}]
+          }
+        }
+      }
+    }
+  }
+]`
+
+func TestDecode(t *testing.T) {
+	objects, err := Decode([]byte(snapshot))
+	if err != nil {
+		t.Fatalf("decode snapshot failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].GetKind() != "Deployment" || objects[0].GetName() != "easemesh-operator" {
+		t.Fatalf("unexpected objects %v", objects)
+	}
+	if replicas := objects[0].Object["spec"].(map[string]interface{})["replicas"]; replicas != int64(1) {
+		t.Errorf("expected replicas decoded as int64, got %T", replicas)
+	}
+
+	_, err = Decode([]byte(`[{"name": "foo"}]`))
+	if err == nil {
+		t.Errorf("expected error of empty object")
+	}
+}
+
+func TestHeal(t *testing.T) {
+	objects, err := Decode([]byte(snapshot))
+	if err != nil {
+		t.Fatalf("decode snapshot failed: %v", err)
+	}
+	desired := objects[0]
+
+	// Defaulted fields, scaled replicas and upgraded images aren't drift.
+	live := desired.DeepCopy()
+	live.SetResourceVersion("10")
+	spec := live.Object["spec"].(map[string]interface{})
+	spec["replicas"] = int64(3)
+	spec["revisionHistoryLimit"] = int64(10)
+	container := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+	container["image"] = "megaease/easemesh-operator:v2"
+	container["imagePullPolicy"] = "IfNotPresent"
+	if Heal(desired, live) {
+		t.Fatalf("expected no drift")
+	}
+
+	// Manual edits are reverted.
+	container["args"] = []interface{}{"--config=/opt/config.yaml", "--leader-elect"}
+	live.SetLabels(map[string]string{"app": "foo", "team": "bar"})
+	if !Heal(desired, live) {
+		t.Fatalf("expected drift")
+	}
+	if args := container["args"].([]interface{}); len(args) != 1 {
+		t.Errorf("expected args reverted, got %v", args)
+	}
+	if labels := live.GetLabels(); labels["app"] != "operator" || labels["team"] != "bar" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	if spec["replicas"] != int64(3) || container["image"] != "megaease/easemesh-operator:v2" || container["imagePullPolicy"] != "IfNotPresent" {
+		t.Errorf("unexpected ignored fields changed: %v", spec)
+	}
+	if live.GetResourceVersion() != "10" {
+		t.Errorf("expected resourceVersion kept")
+	}
+	if Heal(desired, live) {
+		t.Errorf("expected no drift after healing")
+	}
+}
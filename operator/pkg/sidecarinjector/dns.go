@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecarinjector
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultClusterDomain = "cluster.local"
+	resolvConfPath       = "/etc/resolv.conf"
+	dnsLocalNameserver   = "127.0.0.1"
+	dnsDefaultPort       = "53"
+	dnsNdots             = "5"
+)
+
+var (
+	sidecarContainerDNSPortName          = "sidecar-dns"
+	sidecarContainerDNSPortContainerPort = int32(53)
+	sidecarContainerDNSPorts             = []corev1.ContainerPort{
+		{
+			Name:          sidecarContainerDNSPortName,
+			ContainerPort: sidecarContainerDNSPortContainerPort,
+			Protocol:      corev1.ProtocolUDP,
+		},
+	}
+
+	// NOTE: The sidecar needs to bind the privileged port 53 to serve DNS.
+	sidecarContainerDNSSecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"NET_BIND_SERVICE"},
+		},
+	}
+)
+
+// dnsCaptureEnabled reports whether the sidecar should serve DNS for the pod,
+// the annotation of the service overlaps the global switch of the operator.
+func (m *SidecarInjector) dnsCaptureEnabled() bool {
+	if m.meshService.DNSCapture != nil {
+		return *m.meshService.DNSCapture
+	}
+	return m.runtime.SidecarDNSCapture
+}
+
+// dnsUpstream returns the address which the sidecar forwards unknown names to.
+// It falls back to the first nameserver of the operator itself, which is the cluster DNS.
+func (m *SidecarInjector) dnsUpstream() string {
+	upstream := m.runtime.SidecarDNSUpstream
+	if upstream == "" {
+		upstream = resolvConfNameserver(resolvConfPath)
+	}
+	if upstream == "" {
+		return ""
+	}
+
+	if _, _, err := net.SplitHostPort(upstream); err != nil {
+		upstream = net.JoinHostPort(upstream, dnsDefaultPort)
+	}
+
+	return upstream
+}
+
+// injectDNSConfig points the resolver of the pod to the sidecar.
+// The upstream is kept as the second nameserver, so that names could still be
+// resolved when the sidecar is not ready, e.g. the sidecar resolving the control plane on startup.
+func (m *SidecarInjector) injectDNSConfig(upstream string) {
+	clusterDomain := m.runtime.ClusterDomain
+	if clusterDomain == "" {
+		clusterDomain = defaultClusterDomain
+	}
+
+	nameservers := []string{dnsLocalNameserver}
+	if host, _, err := net.SplitHostPort(upstream); err == nil && host != dnsLocalNameserver {
+		nameservers = append(nameservers, host)
+	}
+
+	searches := []string{}
+	if m.meshService.Namespace != "" {
+		searches = append(searches, fmt.Sprintf("%s.svc.%s", m.meshService.Namespace, clusterDomain))
+	}
+	searches = append(searches, "svc."+clusterDomain, clusterDomain)
+
+	ndots := dnsNdots
+	m.pod.DNSPolicy = corev1.DNSNone
+	m.pod.DNSConfig = &corev1.PodDNSConfig{
+		Nameservers: nameservers,
+		Searches:    searches,
+		Options: []corev1.PodDNSConfigOption{
+			{
+				Name:  "ndots",
+				Value: &ndots,
+			},
+		},
+	}
+}
+
+// resolvConfNameserver returns the first nameserver in the resolv.conf,
+// it returns empty string if there is none.
+func resolvConfNameserver(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return fields[1]
+		}
+	}
+
+	return ""
+}
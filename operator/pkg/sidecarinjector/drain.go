@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecarinjector
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// drainShutdownPeriod is the time left for the application to shut down
+// after the drain, it's the default termination grace period of Kubernetes.
+const drainShutdownPeriod = 30 * time.Second
+
+// appContainerDrainEnvName marks the preStop hook of the application injected by
+// the operator, it carries the seconds of the hook, so the hook is recognized
+// by the exact command and hooks of users are never overwritten.
+const appContainerDrainEnvName = "EASEMESH_DRAIN_SECONDS"
+
+// drainDuration returns how long the application keeps running after the pod starts
+// terminating, the annotation of the service overlaps the global one of the operator.
+func (m *SidecarInjector) drainDuration() time.Duration {
+	if m.meshService.DrainDuration != nil {
+		return *m.meshService.DrainDuration
+	}
+	return m.runtime.SidecarDrainDuration
+}
+
+// injectDrainHook makes the application finish the in-flight requests before it's
+// terminated. Its preStop hook keeps it running for the drain duration, then it's
+// terminated, while the sidecar outlives it by injectSidecarDrainHook, so the
+// requests proxied by the sidecar and the outbound calls of the application in the
+// drain and the shutdown of the application keep working.
+func (m *SidecarInjector) injectDrainHook(appContainer *corev1.Container) {
+	lifecycle := corev1.Lifecycle{}
+	if appContainer.Lifecycle != nil {
+		lifecycle = *appContainer.Lifecycle
+	}
+
+	// NOTE: The preStop hook of the application takes care of its own shutdown.
+	if lifecycle.PreStop != nil && !isDrainPreStopHook(lifecycle.PreStop, appContainer.Env) {
+		return
+	}
+
+	duration := m.drainDuration()
+	if duration <= 0 {
+		lifecycle.PreStop = nil
+		appContainer.Env = removeEnvVar(appContainer.Env, appContainerDrainEnvName)
+	} else {
+		seconds := strconv.FormatInt(durationSeconds(duration), 10)
+		lifecycle.PreStop = drainPreStopHook(seconds)
+		appContainer.Env = injectEnvVars(appContainer.Env, corev1.EnvVar{Name: appContainerDrainEnvName, Value: seconds})
+		m.extendTerminationGracePeriod(duration + drainShutdownPeriod)
+	}
+
+	appContainer.Lifecycle = &lifecycle
+	if lifecycle.PreStop == nil && lifecycle.PostStart == nil {
+		appContainer.Lifecycle = nil
+	}
+}
+
+// injectSidecarDrainHook keeps the sidecar running until the application stops
+// listening on its port, i.e. it's terminated after the drain and its shutdown,
+// or until the drain and the shutdown period are over.
+func (m *SidecarInjector) injectSidecarDrainHook() {
+	duration := m.drainDuration()
+	if duration <= 0 {
+		return
+	}
+
+	for i := range m.pod.Containers {
+		container := &m.pod.Containers[i]
+		if container.Name != sidecarContainerName {
+			continue
+		}
+		lifecycle := corev1.Lifecycle{}
+		if container.Lifecycle != nil {
+			lifecycle = *container.Lifecycle
+		}
+		lifecycle.PreStop = &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: sidecarDrainCommand(int32(m.meshService.ApplicationPort), duration+drainShutdownPeriod),
+			},
+		}
+		container.Lifecycle = &lifecycle
+	}
+}
+
+// extendTerminationGracePeriod makes sure the grace period covers the drain,
+// it never shortens the one of the pod.
+func (m *SidecarInjector) extendTerminationGracePeriod(period time.Duration) {
+	seconds := durationSeconds(period)
+	if m.pod.TerminationGracePeriodSeconds != nil && *m.pod.TerminationGracePeriodSeconds >= seconds {
+		return
+	}
+	m.pod.TerminationGracePeriodSeconds = &seconds
+}
+
+func durationSeconds(duration time.Duration) int64 {
+	return int64((duration + time.Second - 1) / time.Second)
+}
+
+func drainPreStopHook(seconds string) *corev1.Handler {
+	return &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"sleep", seconds},
+		},
+	}
+}
+
+// isDrainPreStopHook reports whether the hook is injected by the operator,
+// so it's updated in the injection again.
+func isDrainPreStopHook(hook *corev1.Handler, env []corev1.EnvVar) bool {
+	for _, e := range env {
+		if e.Name == appContainerDrainEnvName {
+			return reflect.DeepEqual(hook, drainPreStopHook(e.Value))
+		}
+	}
+	return false
+}
+
+// sidecarDrainCommand waits for the application to stop listening on the port,
+// no longer than the timeout. It reads /proc/net/tcp like sidecarReadyCommand.
+func sidecarDrainCommand(port int32, timeout time.Duration) []string {
+	const cmdTemplate = `for i in $(seq %d); do
+  %s || exit 0
+  sleep 1
+done`
+
+	return []string{"/bin/sh", "-c", fmt.Sprintf(cmdTemplate, durationSeconds(timeout), portListeningTest(port))}
+}
+
+func removeEnvVar(envVars []corev1.EnvVar, name string) []corev1.EnvVar {
+	result := []corev1.EnvVar{}
+	for _, envVar := range envVars {
+		if envVar.Name != name {
+			result = append(result, envVar)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
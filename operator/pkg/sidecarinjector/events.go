@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecarinjector
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ServiceNameAnnotationKey is the annotation of workloads and pods to inject.
+	ServiceNameAnnotationKey = "mesh.megaease.com/service-name"
+
+	// InjectionStatusAnnotationKey is the annotation of pods recording the decision
+	// of the webhook, since pods have no UIDs to record events on until they're created.
+	// The operator reports it as an event of the pod after the pod is created.
+	InjectionStatusAnnotationKey = "mesh.megaease.com/sidecar-injection-status"
+	// InjectionStatusInjected means the pod is injected by the webhook or by its template.
+	InjectionStatusInjected = "injected"
+
+	injectionStatusSkippedPrefix = "skipped: "
+
+	// meshServiceNamespaceLabelKey is the label of namespaces watched by the webhook.
+	meshServiceNamespaceLabelKey = "mesh.megaease.com/mesh-service"
+)
+
+// Reasons of events explaining the injection of sidecars.
+const (
+	ReasonSidecarInjected         = "SidecarInjected"
+	ReasonSidecarInjectionSkipped = "SidecarInjectionSkipped"
+	ReasonSidecarInjectionFailed  = "SidecarInjectionFailed"
+	ReasonSidecarWebhookNotCalled = "SidecarWebhookNotCalled"
+	// ReasonValidationFailed means the annotations or the spec of the object are invalid,
+	// which must be fixed by editing the object.
+	ReasonValidationFailed = "ValidationFailed"
+)
+
+// InjectionSkipped returns the injection status of the pod skipped for the reason.
+func InjectionSkipped(reason string) string {
+	return injectionStatusSkippedPrefix + reason
+}
+
+// InjectionEvent returns the event explaining the injection status of the pod,
+// ok is false if there is nothing to explain, e.g. the pod isn't in the mesh.
+func InjectionEvent(pod metav1.Object) (eventType, reason, message string, ok bool) {
+	annotations := pod.GetAnnotations()
+	status, annotated := annotations[InjectionStatusAnnotationKey]
+	switch {
+	case status == InjectionStatusInjected:
+		return corev1.EventTypeNormal, ReasonSidecarInjected,
+			"sidecar injected", true
+	case strings.HasPrefix(status, injectionStatusSkippedPrefix):
+		return corev1.EventTypeNormal, ReasonSidecarInjectionSkipped,
+			"sidecar not injected: " + strings.TrimPrefix(status, injectionStatusSkippedPrefix), true
+	case annotated:
+		return "", "", "", false
+	}
+
+	// NOTE: Pods of MeshDeployments are injected by the operator without the webhook.
+	if pod.GetLabels()[InjectedLabelKey] == "true" || annotations[ServiceNameAnnotationKey] == "" {
+		return "", "", "", false
+	}
+
+	return corev1.EventTypeWarning, ReasonSidecarWebhookNotCalled,
+		fmt.Sprintf("sidecar not injected: the injection webhook wasn't called, "+
+			"label namespace %s with %s to inject pods in it", pod.GetNamespace(), meshServiceNamespaceLabelKey), true
+}
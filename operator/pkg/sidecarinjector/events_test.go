@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecarinjector
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InjectionEvent", func() {
+	pod := func(labels, annotations map[string]string) *metav1.ObjectMeta {
+		return &metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "order-6b9f7c-x2kqv",
+			Labels:      labels,
+			Annotations: annotations,
+		}
+	}
+
+	It("reports injected pods", func() {
+		eventType, reason, _, ok := InjectionEvent(pod(nil, map[string]string{
+			InjectionStatusAnnotationKey: InjectionStatusInjected,
+		}))
+		Expect(ok).To(BeTrue())
+		Expect(eventType).To(Equal(corev1.EventTypeNormal))
+		Expect(reason).To(Equal(ReasonSidecarInjected))
+	})
+
+	It("reports why pods are skipped", func() {
+		eventType, reason, message, ok := InjectionEvent(pod(nil, map[string]string{
+			InjectionStatusAnnotationKey: InjectionSkipped("no annotation " + ServiceNameAnnotationKey),
+		}))
+		Expect(ok).To(BeTrue())
+		Expect(eventType).To(Equal(corev1.EventTypeNormal))
+		Expect(reason).To(Equal(ReasonSidecarInjectionSkipped))
+		Expect(message).To(Equal("sidecar not injected: no annotation " + ServiceNameAnnotationKey))
+	})
+
+	It("warns pods not seen by the webhook", func() {
+		eventType, reason, message, ok := InjectionEvent(pod(nil, map[string]string{
+			ServiceNameAnnotationKey: "order",
+		}))
+		Expect(ok).To(BeTrue())
+		Expect(eventType).To(Equal(corev1.EventTypeWarning))
+		Expect(reason).To(Equal(ReasonSidecarWebhookNotCalled))
+		Expect(message).To(ContainSubstring("label namespace default with " + meshServiceNamespaceLabelKey))
+	})
+
+	It("ignores pods out of the mesh", func() {
+		_, _, _, ok := InjectionEvent(pod(nil, nil))
+		Expect(ok).To(BeFalse())
+
+		_, _, _, ok = InjectionEvent(pod(InjectedLabels(nil), map[string]string{
+			ServiceNameAnnotationKey: "order",
+		}))
+		Expect(ok).To(BeFalse())
+	})
+})
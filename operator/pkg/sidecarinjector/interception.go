@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package sidecarinjector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TrafficInterceptionIptables intercepts outbound traffic of injected pods by iptables rules of an init container.
+	TrafficInterceptionIptables = "iptables"
+
+	interceptionInitContainerName = "easemesh-interception-init"
+	interceptionIptablesChain     = "EASEMESH_OUTPUT"
+
+	// sidecarContainerUID is the uid of the sidecar, whose connections are exempted
+	// from the interception, or those to the intercepted ports loop back to itself.
+	sidecarContainerUID = int64(1337)
+)
+
+var (
+	// NOTE: Redirecting outbound traffic needs root to change the netfilter rules of the pod.
+	interceptionInitContainerUID             = int64(0)
+	interceptionInitContainerSecurityContext = &corev1.SecurityContext{
+		RunAsUser: &interceptionInitContainerUID,
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"},
+		},
+	}
+)
+
+// interceptionEnabled reports whether outbound traffic of the pod is intercepted to the sidecar.
+func (m *SidecarInjector) interceptionEnabled() bool {
+	return m.runtime.TrafficInterception == TrafficInterceptionIptables
+}
+
+// injectInterceptionInitContainer injects the init container redirecting
+// outbound traffic of the pod to the sidecar by iptables.
+func (m *SidecarInjector) injectInterceptionInitContainer() {
+	initContainer := corev1.Container{
+		Name:            interceptionInitContainerName,
+		Image:           m.completeImageURL(m.runtime.InterceptionImageName),
+		ImagePullPolicy: corev1.PullPolicy(m.dynamicSpec.spec().ImagePullPolicy),
+		Command:         interceptionInitCommand(m.runtime.InterceptionPorts),
+		SecurityContext: interceptionInitContainerSecurityContext,
+	}
+
+	m.pod.InitContainers = injectContainers(m.pod.InitContainers, initContainer)
+}
+
+// interceptionSidecarSecurityContext runs the sidecar as the uid exempted from
+// the interception, keeping the rest of the security context.
+func interceptionSidecarSecurityContext(securityContext *corev1.SecurityContext) *corev1.SecurityContext {
+	result := &corev1.SecurityContext{}
+	if securityContext != nil {
+		result = securityContext.DeepCopy()
+	}
+	uid := sidecarContainerUID
+	result.RunAsUser = &uid
+	return result
+}
+
+// interceptionInitCommand redirects outbound TCP connections to the ports to
+// the egress port of the sidecar by iptables. Connections of the sidecar,
+// recognized by its uid, and those to the loopback, e.g. of EaseAgent to the
+// sidecar, are left as they are. It's idempotent for restarted pods.
+func interceptionInitCommand(ports []int) []string {
+	const cmdTemplate = `set -e
+iptables -t nat -N %[1]s 2>/dev/null || iptables -t nat -F %[1]s
+iptables -t nat -A %[1]s -m owner --uid-owner %[4]d -j RETURN
+iptables -t nat -A %[1]s -d 127.0.0.0/8 -j RETURN
+iptables -t nat -A %[1]s -p tcp -m multiport --dports %[2]s -j REDIRECT --to-ports %[3]d
+iptables -t nat -C OUTPUT -p tcp -j %[1]s 2>/dev/null || iptables -t nat -A OUTPUT -p tcp -j %[1]s`
+
+	portValues := make([]string, 0, len(ports))
+	for _, port := range ports {
+		portValues = append(portValues, strconv.Itoa(port))
+	}
+
+	cmd := fmt.Sprintf(cmdTemplate,
+		interceptionIptablesChain,
+		strings.Join(portValues, ","),
+		sidecarContainerEgressPortContainerPort,
+		sidecarContainerUID)
+
+	return []string{"/bin/sh", "-c", cmd}
+}
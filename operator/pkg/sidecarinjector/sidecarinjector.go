@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/megaease/easemesh/mesh-operator/pkg/base"
 	"github.com/megaease/easemesh/mesh-operator/pkg/util/labelstool"
@@ -29,6 +30,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+// InjectedLabelKey is the label of pods injected with the sidecar.
+const InjectedLabelKey = "mesh.megaease.com/sidecar-injected"
+
 var (
 	// Volumes stuff.
 	volumes = []corev1.Volume{
@@ -160,7 +164,7 @@ var (
 	}
 )
 
-func initContainerCommand(service *MeshService) []string {
+func initContainerCommand(service *MeshService, dnsUpstream string) []string {
 	// TODO: Adjust for label names:
 	// alive-probe -> mesh-alive-probe-url
 	// application-port -> mesh-application-port
@@ -180,7 +184,13 @@ labels:
   application-port: %d
   mesh-service-labels: %s
   mesh-servicename: %s
-' > %s`
+%s' > %s`
+
+	dnsLabels := ""
+	if dnsUpstream != "" {
+		dnsLabels = fmt.Sprintf("  mesh-dns-listen-addr: %s:%d\n  mesh-dns-upstream: %s\n",
+			dnsLocalNameserver, sidecarContainerDNSPortContainerPort, dnsUpstream)
+	}
 
 	cmd := fmt.Sprintf(cmdTemplate,
 		initContainerAgentVolumeMountPath,
@@ -191,6 +201,7 @@ labels:
 		service.ApplicationPort,
 		labelstool.Marshal(service.Labels),
 		service.Name,
+		dnsLabels,
 
 		initContainerSidecarConfigPath)
 
@@ -211,6 +222,9 @@ type (
 		// Name is required.
 		Name string
 
+		// Namespace is optional, it's used to complete DNS search domains.
+		Namespace string
+
 		// Labels is optional.
 		Labels map[string]string
 
@@ -230,9 +244,47 @@ type (
 
 		// SidecarImage could overlap the default image of the sidecar
 		SidecarImage string
+
+		// DNSCapture could overlap the global DNS capture switch of the operator.
+		// If true, the sidecar serves DNS for mesh services and external services.
+		DNSCapture *bool
+
+		// SpringCloudConfigURI could overlap the global Spring Cloud Config server of the operator.
+		// SpringCloudConfigDisabled disables it for the service.
+		SpringCloudConfigURI string
+
+		// SpringCloudConfigLabel is optional, it's the label (git branch) of the config.
+		SpringCloudConfigLabel string
+
+		// SpringCloudConfigProfile is optional, it's the profile of the config.
+		SpringCloudConfigProfile string
+
+		// SpringCloudConfigPath is optional, it's set on the config server itself
+		// and registered as the configPath metadata of its instances.
+		SpringCloudConfigPath string
+
+		// DrainDuration could overlap the global drain duration of the operator,
+		// zero disables the drain of the service.
+		DrainDuration *time.Duration
+
+		// HoldApplication could overlap the global switch of the operator
+		// holding applications until sidecars are ready.
+		HoldApplication *bool
 	}
 )
 
+// InjectedLabels returns the labels of the pod injected with the sidecar, which
+// are allowed to connect the control plane by its network policies. The labels
+// are copied since they could be shared with selectors.
+func InjectedLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[InjectedLabelKey] = "true"
+	return result
+}
+
 // New creates a SidecarInjector.
 func New(baseRuntime *base.Runtime, meshService *MeshService, pod *corev1.PodSpec) *SidecarInjector {
 	return &SidecarInjector{
@@ -251,14 +303,45 @@ func (m *SidecarInjector) Inject() error {
 		return errors.Wrap(err, "set up mesh service")
 	}
 
+	dnsUpstream := ""
+	if m.dnsCaptureEnabled() {
+		dnsUpstream = m.dnsUpstream()
+		if dnsUpstream == "" {
+			return errors.Errorf("dns capture enabled but no upstream nameserver found")
+		}
+		m.injectDNSConfig(dnsUpstream)
+	}
+
+	m.injectSpringCloudConfigMetadata()
+
 	m.injectVolumes(volumes...)
-	m.injectInitContainer()
-	m.injectSidecarContainer()
+	m.injectInitContainer(dnsUpstream)
+	if m.interceptionEnabled() {
+		m.injectInterceptionInitContainer()
+	} else {
+		m.pod.InitContainers = removeContainer(m.pod.InitContainers, interceptionInitContainerName)
+	}
+	m.injectSidecarContainer(dnsUpstream != "")
 
 	err = m.adaptAppContainerSpec()
 	if err != nil {
 		return errors.Wrap(err, "complete app container spec")
 	}
+	m.injectSidecarDrainHook()
+
+	if m.nativeSidecarEnabled() {
+		m.runAsNativeSidecar()
+	} else {
+		m.pod.InitContainers = removeContainer(m.pod.InitContainers, sidecarContainerName)
+		if m.holdApplicationEnabled() {
+			m.holdApplicationUntilSidecarReady()
+		}
+	}
+
+	err = m.applyInjectionTemplate()
+	if err != nil {
+		return errors.Wrap(err, "apply injection template")
+	}
 
 	return nil
 }
@@ -323,12 +406,12 @@ func (m *SidecarInjector) injectVolumes(volumes ...corev1.Volume) {
 	}
 }
 
-func (m *SidecarInjector) injectInitContainer() {
+func (m *SidecarInjector) injectInitContainer(dnsUpstream string) {
 	initContainer := corev1.Container{
 		Name:            initContainerName,
 		Image:           m.completeImageURL(initContainerImageName(m.meshService.InitContainerImage, m.dynamicSpec.spec())),
 		ImagePullPolicy: corev1.PullPolicy(m.dynamicSpec.spec().ImagePullPolicy),
-		Command:         initContainerCommand(m.meshService),
+		Command:         initContainerCommand(m.meshService, dnsUpstream),
 		VolumeMounts:    initContainerVolumeMounts,
 	}
 
@@ -355,15 +438,18 @@ func (m *SidecarInjector) adaptAppContainerSpec() error {
 			Value: appContainerJavaEnvValue(m.dynamicSpec.spec()),
 		},
 	}
+	appContainerEnvs = append(appContainerEnvs, m.springCloudConfigEnvs()...)
 
 	appContainer.Env = injectEnvVars(appContainer.Env, appContainerEnvs...)
 
+	m.injectDrainHook(appContainer)
+
 	m.pod.Containers = injectContainers(m.pod.Containers, *appContainer)
 
 	return nil
 }
 
-func (m *SidecarInjector) injectSidecarContainer() {
+func (m *SidecarInjector) injectSidecarContainer(dnsCapture bool) {
 	sidecarContainer := corev1.Container{
 		Name:            sidecarContainerName,
 		Image:           m.completeImageURL(sidecarContainerImageName(m.meshService.SidecarImage, m.dynamicSpec.spec())),
@@ -374,6 +460,15 @@ func (m *SidecarInjector) injectSidecarContainer() {
 		Ports:           sidecarContainerPorts,
 	}
 
+	if dnsCapture {
+		sidecarContainer.Ports = injectContainerPorts(
+			append([]corev1.ContainerPort{}, sidecarContainerPorts...), sidecarContainerDNSPorts...)
+		sidecarContainer.SecurityContext = sidecarContainerDNSSecurityContext
+	}
+	if m.interceptionEnabled() {
+		sidecarContainer.SecurityContext = interceptionSidecarSecurityContext(sidecarContainer.SecurityContext)
+	}
+
 	m.pod.Containers = injectContainers(m.pod.Containers, sidecarContainer)
 }
 
@@ -412,6 +507,20 @@ func findContainer(containers []corev1.Container, name string) (*corev1.Containe
 	return nil, false
 }
 
+// removeContainer returns the containers without the named one.
+func removeContainer(containers []corev1.Container, name string) []corev1.Container {
+	if _, exists := findContainer(containers, name); !exists {
+		return containers
+	}
+	result := make([]corev1.Container, 0, len(containers))
+	for _, container := range containers {
+		if container.Name != name {
+			result = append(result, container)
+		}
+	}
+	return result
+}
+
 func injectVolumeMounts(volumeMounts []corev1.VolumeMount, elems ...corev1.VolumeMount) []corev1.VolumeMount {
 	for _, elem := range elems {
 		replaced := false
@@ -19,11 +19,18 @@ package sidecarinjector
 
 import (
 	_ "embed"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/megaease/easemesh/mesh-operator/pkg/base"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
 	. "github.com/onsi/ginkgo"
@@ -73,4 +80,475 @@ var _ = Describe("SidecarInjector", func() {
 
 		Expect(originalDeploy.Spec.Template.Spec).To(Equal(wantDeploy.Spec.Template.Spec))
 	})
+
+	It("labels injected pods without changing selectors", func() {
+		selector := map[string]string{"app": "vets-service"}
+		labels := InjectedLabels(selector)
+
+		Expect(labels).To(Equal(map[string]string{"app": "vets-service", InjectedLabelKey: "true"}))
+		Expect(selector).NotTo(HaveKey(InjectedLabelKey))
+	})
+
+	It("injects pod with dns capture", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:               "test-runtime-name",
+			ImagePullPolicy:    "IfNotPresent",
+			Log:                logr.Discard(),
+			SidecarDNSCapture:  true,
+			SidecarDNSUpstream: "10.96.0.10",
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			Namespace:        "spring-petclinic",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+			AliveProbeURL:    "http://localhost:9000/health",
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		Expect(podSpec.DNSPolicy).To(Equal(corev1.DNSNone))
+		Expect(podSpec.DNSConfig.Nameservers).To(Equal([]string{"127.0.0.1", "10.96.0.10"}))
+		Expect(podSpec.DNSConfig.Searches).To(Equal([]string{
+			"spring-petclinic.svc.cluster.local", "svc.cluster.local", "cluster.local"}))
+
+		sidecar, exists := findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(sidecar.Ports).To(ContainElement(sidecarContainerDNSPorts[0]))
+
+		initContainer, exists := findContainer(podSpec.InitContainers, initContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(initContainer.Command[2]).To(ContainSubstring("mesh-dns-upstream: 10.96.0.10:53"))
+	})
+
+	It("skips dns capture overlapped by the service", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:               "test-runtime-name",
+			Log:                logr.Discard(),
+			SidecarDNSCapture:  true,
+			SidecarDNSUpstream: "10.96.0.10",
+		}
+
+		dnsCapture := false
+		service := &MeshService{
+			Name:            "vets-service",
+			ApplicationPort: 9000,
+			DNSCapture:      &dnsCapture,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		Expect(podSpec.DNSConfig).To(BeNil())
+	})
+
+	It("injects spring cloud config", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                 "test-runtime-name",
+			Log:                  logr.Discard(),
+			SpringCloudConfigURI: "http://config-server.spring-petclinic:8888",
+		}
+
+		service := &MeshService{
+			Name:                     "vets-service",
+			AppContainerName:         "vets-service",
+			ApplicationPort:          9000,
+			SpringCloudConfigLabel:   "main",
+			SpringCloudConfigProfile: "prod",
+			SpringCloudConfigPath:    "/config",
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		app, exists := findContainer(podSpec.Containers, "vets-service")
+		Expect(exists).To(BeTrue())
+		Expect(app.Env).To(ContainElements(
+			corev1.EnvVar{Name: "SPRING_CLOUD_CONFIG_URI", Value: "http://config-server.spring-petclinic:8888"},
+			corev1.EnvVar{Name: "SPRING_CONFIG_IMPORT", Value: "optional:configserver:http://config-server.spring-petclinic:8888"},
+			corev1.EnvVar{Name: "SPRING_CLOUD_CONFIG_LABEL", Value: "main"},
+			corev1.EnvVar{Name: "SPRING_CLOUD_CONFIG_PROFILE", Value: "prod"},
+		))
+
+		initContainer, exists := findContainer(podSpec.InitContainers, initContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(initContainer.Command[2]).To(ContainSubstring("mesh-service-labels: configPath=/config"))
+	})
+
+	It("skips spring cloud config disabled by the service", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                 "test-runtime-name",
+			Log:                  logr.Discard(),
+			SpringCloudConfigURI: "http://config-server.spring-petclinic:8888",
+		}
+
+		service := &MeshService{
+			Name:                 "vets-service",
+			AppContainerName:     "vets-service",
+			ApplicationPort:      9000,
+			SpringCloudConfigURI: SpringCloudConfigDisabled,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		app, exists := findContainer(podSpec.Containers, "vets-service")
+		Expect(exists).To(BeTrue())
+		for _, env := range app.Env {
+			Expect(env.Name).NotTo(HavePrefix("SPRING_"))
+		}
+	})
+
+	It("applies patches of the injection template", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		dir, err := ioutil.TempDir("", "injection-template")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		templateFile := filepath.Join(dir, "template.yaml")
+		Expect(ioutil.WriteFile(templateFile, []byte(`
+strategicMergePatches:
+- containers:
+  - name: log-shipper
+    image: fluent/fluent-bit:1.8
+- containers:
+  - name: easemesh-sidecar
+    env:
+    - name: LOG_LEVEL
+      value: debug
+jsonPatches:
+- op: add
+  path: /volumes/-
+  value:
+    name: logs
+    emptyDir: {}
+`), 0644)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                     "test-runtime-name",
+			Log:                      logr.Discard(),
+			SidecarInjectionTemplate: templateFile,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		_, exists := findContainer(podSpec.Containers, "log-shipper")
+		Expect(exists).To(BeTrue())
+		sidecar, exists := findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(sidecar.Image).NotTo(BeEmpty())
+		Expect(sidecar.Env).To(ContainElements(
+			corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"},
+			corev1.EnvVar{Name: sidecarContainerAppIPEnvName, ValueFrom: sidecarContainerAppIPEnvValue},
+		))
+		Expect(podSpec.Volumes[len(podSpec.Volumes)-1].Name).To(Equal("logs"))
+
+		Expect(ioutil.WriteFile(templateFile, []byte("jsonPatches:\n- op: remove\n  path: /nothing\n"), 0644)).To(Succeed())
+		Expect(New(baseRuntime, service, podSpec).Inject()).NotTo(Succeed())
+	})
+
+	It("injects the drain hook", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                 "test-runtime-name",
+			Log:                  logr.Discard(),
+			SidecarDrainDuration: 15 * time.Second,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		app, exists := findContainer(podSpec.Containers, "vets-service")
+		Expect(exists).To(BeTrue())
+		Expect(app.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "15"}))
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(45)))
+		// NOTE: The sidecar outlives the application until it stops listening on 9000.
+		sidecar, exists := findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(sidecar.Lifecycle.PreStop.Exec.Command[2]).To(ContainSubstring("seq 45"))
+		Expect(sidecar.Lifecycle.PreStop.Exec.Command[2]).To(ContainSubstring(":2328 "))
+
+		drainDuration := 90 * time.Second
+		service.DrainDuration = &drainDuration
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		app, _ = findContainer(podSpec.Containers, "vets-service")
+		Expect(app.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "90"}))
+		Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(120)))
+
+		drainDuration = 0
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		app, _ = findContainer(podSpec.Containers, "vets-service")
+		Expect(app.Lifecycle).To(BeNil())
+		for _, env := range app.Env {
+			Expect(env.Name).NotTo(Equal(appContainerDrainEnvName))
+		}
+		sidecar, _ = findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(sidecar.Lifecycle).To(BeNil())
+	})
+
+	It("keeps the sleep preStop hook of users", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		preStop := &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"sleep", "5"}},
+		}
+		deploy.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{PreStop: preStop}
+
+		baseRuntime := &base.Runtime{
+			Name:                 "test-runtime-name",
+			Log:                  logr.Discard(),
+			SidecarDrainDuration: 15 * time.Second,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: deploy.Spec.Template.Spec.Containers[0].Name,
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		app, exists := findContainer(podSpec.Containers, service.AppContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(app.Lifecycle.PreStop).To(Equal(preStop))
+	})
+
+	It("keeps the preStop hook of the application", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		preStop := &corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/shutdown", Port: intstr.FromInt(9000)},
+		}
+		deploy.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{PreStop: preStop}
+
+		baseRuntime := &base.Runtime{
+			Name:                 "test-runtime-name",
+			Log:                  logr.Discard(),
+			SidecarDrainDuration: 15 * time.Second,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: deploy.Spec.Template.Spec.Containers[0].Name,
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		app, exists := findContainer(podSpec.Containers, service.AppContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(app.Lifecycle.PreStop).To(Equal(preStop))
+	})
+
+	It("holds the application until the sidecar is ready", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                             "test-runtime-name",
+			Log:                              logr.Discard(),
+			HoldApplicationUntilSidecarReady: true,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		Expect(podSpec.Containers).To(HaveLen(2))
+		Expect(podSpec.Containers[0].Name).To(Equal(sidecarContainerName))
+		Expect(podSpec.Containers[0].Lifecycle.PostStart.Exec.Command[2]).To(ContainSubstring(":32CA "))
+		Expect(podSpec.Containers[1].Name).To(Equal("vets-service"))
+
+		// NOTE: Injecting again keeps the order, and the service could overlap the switch.
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		Expect(podSpec.Containers).To(HaveLen(2))
+		Expect(podSpec.Containers[0].Name).To(Equal(sidecarContainerName))
+
+		hold := false
+		service.HoldApplication = &hold
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		sidecar, exists := findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(sidecar.Lifecycle).To(BeNil())
+	})
+
+	It("holds the application and keeps the drain hook of the sidecar", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                             "test-runtime-name",
+			Log:                              logr.Discard(),
+			SidecarDrainDuration:             15 * time.Second,
+			HoldApplicationUntilSidecarReady: true,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		Expect(podSpec.Containers[0].Name).To(Equal(sidecarContainerName))
+		Expect(podSpec.Containers[0].Lifecycle.PreStop.Exec.Command[2]).To(ContainSubstring("seq 45"))
+		// NOTE: The hook fails if the sidecar isn't ready in time, so kubelet restarts it.
+		Expect(podSpec.Containers[0].Lifecycle.PostStart.Exec.Command[2]).To(HaveSuffix("exit 1"))
+	})
+
+	It("injects the native sidecar", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                             "test-runtime-name",
+			Log:                              logr.Discard(),
+			SidecarDrainDuration:             15 * time.Second,
+			HoldApplicationUntilSidecarReady: true,
+			NativeSidecar:                    true,
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		// NOTE: Injecting again keeps a single sidecar.
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		_, exists := findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(exists).To(BeFalse())
+		Expect(podSpec.InitContainers).To(HaveLen(2))
+		sidecar := podSpec.InitContainers[1]
+		Expect(sidecar.Name).To(Equal(sidecarContainerName))
+		Expect(sidecar.Lifecycle).To(BeNil())
+		Expect(sidecar.StartupProbe.Exec.Command[2]).To(ContainSubstring(":32CA "))
+		app, _ := findContainer(podSpec.Containers, "vets-service")
+		Expect(app.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "15"}))
+
+		baseRuntime.NativeSidecar = false
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		Expect(podSpec.InitContainers).To(HaveLen(1))
+		Expect(podSpec.Containers[0].Name).To(Equal(sidecarContainerName))
+	})
+
+	It("injects the interception init container", func() {
+		deploy := &v1.Deployment{}
+		Expect(yaml.Unmarshal([]byte(originalDeployStr), deploy)).To(Succeed())
+
+		baseRuntime := &base.Runtime{
+			Name:                  "test-runtime-name",
+			Log:                   logr.Discard(),
+			SidecarDNSCapture:     true,
+			SidecarDNSUpstream:    "10.96.0.10",
+			TrafficInterception:   TrafficInterceptionIptables,
+			InterceptionImageName: "registry.k8s.io/build-image/debian-iptables:buster-v1.6.7",
+			InterceptionPorts:     []int{80, 8080},
+		}
+
+		service := &MeshService{
+			Name:             "vets-service",
+			AppContainerName: "vets-service",
+			ApplicationPort:  9000,
+		}
+
+		podSpec := &deploy.Spec.Template.Spec
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		// NOTE: Injecting again keeps a single interception init container.
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+
+		Expect(podSpec.InitContainers).To(HaveLen(2))
+		initContainer, exists := findContainer(podSpec.InitContainers, interceptionInitContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(initContainer.Image).To(Equal("registry.k8s.io/build-image/debian-iptables:buster-v1.6.7"))
+		Expect(initContainer.SecurityContext.Capabilities.Add).To(ContainElement(corev1.Capability("NET_ADMIN")))
+		Expect(*initContainer.SecurityContext.RunAsUser).To(BeZero())
+		rules := strings.Split(initContainer.Command[2], "\n")
+		Expect(rules).To(ContainElement(ContainSubstring("-m owner --uid-owner 1337 -j RETURN")))
+		Expect(rules).To(ContainElement(ContainSubstring("-d 127.0.0.0/8 -j RETURN")))
+		Expect(rules).To(ContainElement(ContainSubstring("--dports 80,8080 -j REDIRECT --to-ports 13002")))
+		// NOTE: Connections of the sidecar must return before they are redirected.
+		Expect(strings.Index(initContainer.Command[2], "--uid-owner")).To(BeNumerically("<", strings.Index(initContainer.Command[2], "REDIRECT")))
+
+		sidecar, exists := findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(exists).To(BeTrue())
+		Expect(*sidecar.SecurityContext.RunAsUser).To(Equal(sidecarContainerUID))
+		Expect(sidecar.SecurityContext.Capabilities.Add).To(ContainElement(corev1.Capability("NET_BIND_SERVICE")))
+		Expect(sidecarContainerDNSSecurityContext.RunAsUser).To(BeNil())
+
+		baseRuntime.TrafficInterception = ""
+		Expect(New(baseRuntime, service, podSpec).Inject()).To(Succeed())
+		Expect(podSpec.InitContainers).To(HaveLen(1))
+		_, exists = findContainer(podSpec.InitContainers, interceptionInitContainerName)
+		Expect(exists).To(BeFalse())
+		sidecar, _ = findContainer(podSpec.Containers, sidecarContainerName)
+		Expect(sidecar.SecurityContext.RunAsUser).To(BeNil())
+	})
+
+	It("restores restart policies of init containers", func() {
+		original := map[string]interface{}{
+			"initContainers": []interface{}{
+				map[string]interface{}{"name": "log-shipper", "restartPolicy": "Always"},
+			},
+		}
+		current := map[string]interface{}{
+			"initContainers": []interface{}{
+				map[string]interface{}{"name": "log-shipper"},
+				map[string]interface{}{"name": initContainerName},
+				map[string]interface{}{"name": sidecarContainerName},
+			},
+		}
+
+		Expect(RestoreRestartPolicies(original, current)).To(BeTrue())
+		containers := current["initContainers"].([]interface{})
+		Expect(containers[0]).To(HaveKeyWithValue("restartPolicy", "Always"))
+		Expect(containers[1]).NotTo(HaveKey("restartPolicy"))
+		Expect(containers[2]).To(HaveKeyWithValue("restartPolicy", nativeSidecarRestartPolicy))
+
+		Expect(RestoreRestartPolicies(original, current)).To(BeFalse())
+	})
 })
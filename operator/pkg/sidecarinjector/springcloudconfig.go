@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package sidecarinjector
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SpringCloudConfigDisabled in the annotation of the service disables
+	// the config server set by the operator.
+	SpringCloudConfigDisabled = "none"
+
+	// springCloudConfigPathLabel is the instance metadata which discovery-first
+	// Spring Cloud Config clients use to locate the config server.
+	springCloudConfigPathLabel = "configPath"
+
+	appContainerSpringCloudConfigURIEnvName     = "SPRING_CLOUD_CONFIG_URI"
+	appContainerSpringConfigImportEnvName       = "SPRING_CONFIG_IMPORT"
+	appContainerSpringCloudConfigLabelEnvName   = "SPRING_CLOUD_CONFIG_LABEL"
+	appContainerSpringCloudConfigProfileEnvName = "SPRING_CLOUD_CONFIG_PROFILE"
+)
+
+// springCloudConfigURI returns the config server which the application fetches its config from,
+// the annotation of the service overlaps the global config server of the operator.
+func (m *SidecarInjector) springCloudConfigURI() string {
+	uri := m.meshService.SpringCloudConfigURI
+	if uri == "" {
+		uri = m.runtime.SpringCloudConfigURI
+	}
+	if uri == SpringCloudConfigDisabled {
+		return ""
+	}
+
+	return uri
+}
+
+// springCloudConfigEnvs returns the environment variables which point the
+// Spring Cloud Config client of the application to the config server.
+// Both the legacy bootstrap and the spring.config.import of Spring Boot 2.4+ are covered.
+func (m *SidecarInjector) springCloudConfigEnvs() []corev1.EnvVar {
+	uri := m.springCloudConfigURI()
+	if uri == "" {
+		return nil
+	}
+
+	envs := []corev1.EnvVar{
+		{
+			Name:  appContainerSpringCloudConfigURIEnvName,
+			Value: uri,
+		},
+		{
+			Name:  appContainerSpringConfigImportEnvName,
+			Value: "optional:configserver:" + uri,
+		},
+	}
+
+	if m.meshService.SpringCloudConfigLabel != "" {
+		envs = append(envs, corev1.EnvVar{
+			Name:  appContainerSpringCloudConfigLabelEnvName,
+			Value: m.meshService.SpringCloudConfigLabel,
+		})
+	}
+
+	if m.meshService.SpringCloudConfigProfile != "" {
+		envs = append(envs, corev1.EnvVar{
+			Name:  appContainerSpringCloudConfigProfileEnvName,
+			Value: m.meshService.SpringCloudConfigProfile,
+		})
+	}
+
+	return envs
+}
+
+// injectSpringCloudConfigMetadata registers the config path of a config server
+// into the metadata of its instances.
+func (m *SidecarInjector) injectSpringCloudConfigMetadata() {
+	if m.meshService.SpringCloudConfigPath == "" {
+		return
+	}
+
+	labels := map[string]string{}
+	for k, v := range m.meshService.Labels {
+		labels[k] = v
+	}
+	labels[springCloudConfigPathLabel] = m.meshService.SpringCloudConfigPath
+	m.meshService.Labels = labels
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecarinjector
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sidecarReadyTimeout is the seconds the sidecar waits for itself to be ready
+// in the postStart hook. The hook fails after it if the sidecar isn't ready,
+// so kubelet kills and restarts the sidecar like one failing its startup probe.
+const sidecarReadyTimeout = 60
+
+// nativeSidecarRestartPolicy is the restartPolicy which makes an init container a native
+// sidecar of Kubernetes. The Kubernetes API which the operator built with has no such field,
+// so it's set on the JSON object by RestoreRestartPolicies.
+const nativeSidecarRestartPolicy = "Always"
+
+// nativeSidecarEnabled reports whether the sidecar runs as a native sidecar of Kubernetes.
+func (m *SidecarInjector) nativeSidecarEnabled() bool {
+	return m.runtime.NativeSidecar
+}
+
+// holdApplicationEnabled reports whether the application starts after the sidecar is ready,
+// the annotation of the service overlaps the global switch of the operator.
+func (m *SidecarInjector) holdApplicationEnabled() bool {
+	if m.meshService.HoldApplication != nil {
+		return *m.meshService.HoldApplication
+	}
+	return m.runtime.HoldApplicationUntilSidecarReady
+}
+
+// holdApplicationUntilSidecarReady moves the sidecar to be the first container and
+// blocks it in the postStart hook until the egress port is listening. Kubernetes
+// starts containers in order and waits for the postStart hook of each container,
+// so the application doesn't start before the sidecar could proxy its requests.
+func (m *SidecarInjector) holdApplicationUntilSidecarReady() {
+	containers := make([]corev1.Container, 0, len(m.pod.Containers))
+	for _, container := range m.pod.Containers {
+		if container.Name != sidecarContainerName {
+			continue
+		}
+		if container.Lifecycle == nil {
+			container.Lifecycle = &corev1.Lifecycle{}
+		}
+		container.Lifecycle.PostStart = &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: sidecarReadyCommand(sidecarContainerEgressPortContainerPort),
+			},
+		}
+		containers = append(containers, container)
+	}
+	for _, container := range m.pod.Containers {
+		if container.Name != sidecarContainerName {
+			containers = append(containers, container)
+		}
+	}
+
+	m.pod.Containers = containers
+}
+
+// runAsNativeSidecar moves the sidecar into init containers after the init container of the mesh.
+// Marked with restartPolicy Always, Kubernetes starts application containers after its startup
+// probe succeeds and terminates it after them, so neither the postStart hook holding applications
+// nor the preStop hook draining applications is needed.
+func (m *SidecarInjector) runAsNativeSidecar() {
+	containers := make([]corev1.Container, 0, len(m.pod.Containers))
+	var sidecar *corev1.Container
+	for i := range m.pod.Containers {
+		if m.pod.Containers[i].Name == sidecarContainerName {
+			sidecar = m.pod.Containers[i].DeepCopy()
+			continue
+		}
+		containers = append(containers, m.pod.Containers[i])
+	}
+	if sidecar == nil {
+		return
+	}
+
+	sidecar.Lifecycle = nil
+	sidecar.StartupProbe = &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", portListeningTest(sidecarContainerEgressPortContainerPort)},
+			},
+		},
+		PeriodSeconds:    1,
+		FailureThreshold: sidecarReadyTimeout,
+	}
+
+	m.pod.Containers = containers
+	m.pod.InitContainers = injectContainers(m.pod.InitContainers, *sidecar)
+}
+
+// RestoreRestartPolicies sets restartPolicy of init containers in the current pod spec
+// decoded from JSON, which is lost in the round trip of the Kubernetes API the operator
+// built with. Policies of the original pod spec are kept, and the sidecar in init containers
+// becomes a native sidecar. It reports whether the current pod spec is changed.
+func RestoreRestartPolicies(original, current map[string]interface{}) bool {
+	policies := map[string]interface{}{}
+	originalContainers, _ := original["initContainers"].([]interface{})
+	for _, c := range originalContainers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := container["name"].(string)
+		if policy, exists := container["restartPolicy"]; exists {
+			policies[name] = policy
+		}
+	}
+
+	changed := false
+	currentContainers, _ := current["initContainers"].([]interface{})
+	for _, c := range currentContainers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := container["name"].(string)
+		policy, exists := policies[name]
+		if name == sidecarContainerName {
+			policy, exists = nativeSidecarRestartPolicy, true
+		}
+		if exists && container["restartPolicy"] != policy {
+			container["restartPolicy"] = policy
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// sidecarReadyCommand waits for the port listening in the network namespace of the pod,
+// and exits non-zero on the timeout. It reads /proc/net/tcp instead of depending on
+// network tools in the sidecar image.
+func sidecarReadyCommand(port int32) []string {
+	const cmdTemplate = `for i in $(seq %d); do
+  %s && exit 0
+  sleep 1
+done
+echo 'sidecar is not ready in %d seconds' >&2
+exit 1`
+
+	return []string{"/bin/sh", "-c", fmt.Sprintf(cmdTemplate, sidecarReadyTimeout, portListeningTest(port), sidecarReadyTimeout)}
+}
+
+// portListeningTest is the shell test of the port listening in the network namespace of the pod.
+func portListeningTest(port int32) string {
+	return fmt.Sprintf("grep -qE ':%04X [0-9A-F]+:0000 0A' /proc/net/tcp /proc/net/tcp6 2>/dev/null", port)
+}
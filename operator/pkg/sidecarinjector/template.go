@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecarinjector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+// InjectionTemplate carries patches of the pod spec applied after the sidecar is injected,
+// so platform teams could add env vars, volumes or containers to every injected pod.
+// Strategic merge patches are applied in order, then JSON patches.
+type InjectionTemplate struct {
+	StrategicMergePatches []map[string]interface{} `json:"strategicMergePatches,omitempty"`
+	JSONPatches           []map[string]interface{} `json:"jsonPatches,omitempty"`
+}
+
+// LoadInjectionTemplate loads the template in yaml, it returns nil if the file doesn't exist.
+func LoadInjectionTemplate(path string) (*InjectionTemplate, error) {
+	buff, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s", path)
+	}
+
+	template := &InjectionTemplate{}
+	err = yaml.UnmarshalStrict(buff, template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s", path)
+	}
+	return template, nil
+}
+
+// Apply applies patches of the template to the pod spec.
+func (t *InjectionTemplate) Apply(pod *corev1.PodSpec) error {
+	current, err := json.Marshal(pod)
+	if err != nil {
+		return errors.Wrap(err, "marshal pod spec")
+	}
+
+	for i, p := range t.StrategicMergePatches {
+		patch, err := json.Marshal(p)
+		if err != nil {
+			return errors.Wrapf(err, "marshal strategic merge patch %d", i)
+		}
+		current, err = strategicpatch.StrategicMergePatch(current, patch, corev1.PodSpec{})
+		if err != nil {
+			return errors.Wrapf(err, "apply strategic merge patch %d", i)
+		}
+	}
+
+	if len(t.JSONPatches) != 0 {
+		buff, err := json.Marshal(t.JSONPatches)
+		if err != nil {
+			return errors.Wrap(err, "marshal json patches")
+		}
+		patch, err := jsonpatch.DecodePatch(buff)
+		if err != nil {
+			return errors.Wrap(err, "decode json patches")
+		}
+		current, err = patch.Apply(current)
+		if err != nil {
+			return errors.Wrap(err, "apply json patches")
+		}
+	}
+
+	result := corev1.PodSpec{}
+	err = json.Unmarshal(current, &result)
+	if err != nil {
+		return errors.Wrap(err, "unmarshal patched pod spec")
+	}
+	*pod = result
+
+	return nil
+}
+
+// applyInjectionTemplate applies the injection template of the operator, which
+// is reloaded in every injection since it's mounted from a ConfigMap.
+func (m *SidecarInjector) applyInjectionTemplate() error {
+	if m.runtime.SidecarInjectionTemplate == "" {
+		return nil
+	}
+
+	template, err := LoadInjectionTemplate(m.runtime.SidecarInjectionTemplate)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return nil
+	}
+	return template.Apply(m.pod)
+}
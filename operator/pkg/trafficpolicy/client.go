@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trafficpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	meshServiceResilienceURL   = "http://%s/apis/v1/mesh/services/%s/resilience"
+	meshServiceCanariesURL     = "http://%s/apis/v1/mesh/servicecanaries"
+	meshServiceCanaryObjectURL = "http://%s/apis/v1/mesh/servicecanaries/%s"
+)
+
+type (
+	// Client operates policies of the EaseMesh control plane. Policies are
+	// JSON objects, so fields unknown to the operator are kept as they are.
+	Client interface {
+		// GetResilience returns the resilience of the service, nil if it doesn't exist.
+		GetResilience(ctx context.Context, service string) (map[string]interface{}, error)
+		// ApplyResilience creates the resilience of the service or updates it if it already exists.
+		ApplyResilience(ctx context.Context, service string, resilience map[string]interface{}) error
+		// GetServiceCanary returns the service canary, nil if it doesn't exist.
+		GetServiceCanary(ctx context.Context, name string) (map[string]interface{}, error)
+		// ApplyServiceCanary creates the service canary or updates it if it already exists.
+		ApplyServiceCanary(ctx context.Context, serviceCanary map[string]interface{}) error
+		// DeleteServiceCanary deletes the service canary, it's not an error if it doesn't exist.
+		DeleteServiceCanary(ctx context.Context, name string) error
+	}
+
+	httpClient struct {
+		apiAddr string
+		client  *http.Client
+	}
+)
+
+// NewClient creates a client talking to the EaseMesh control plane API address.
+func NewClient(apiAddr string) Client {
+	return &httpClient{
+		apiAddr: apiAddr,
+		client:  http.DefaultClient,
+	}
+}
+
+func (c *httpClient) GetResilience(ctx context.Context, service string) (map[string]interface{}, error) {
+	return c.get(ctx, fmt.Sprintf(meshServiceResilienceURL, c.apiAddr, service))
+}
+
+func (c *httpClient) ApplyResilience(ctx context.Context, service string, resilience map[string]interface{}) error {
+	url := fmt.Sprintf(meshServiceResilienceURL, c.apiAddr, service)
+	return c.apply(ctx, url, url, resilience)
+}
+
+func (c *httpClient) GetServiceCanary(ctx context.Context, name string) (map[string]interface{}, error) {
+	return c.get(ctx, fmt.Sprintf(meshServiceCanaryObjectURL, c.apiAddr, name))
+}
+
+func (c *httpClient) ApplyServiceCanary(ctx context.Context, serviceCanary map[string]interface{}) error {
+	name, _ := serviceCanary["name"].(string)
+	return c.apply(ctx, fmt.Sprintf(meshServiceCanariesURL, c.apiAddr),
+		fmt.Sprintf(meshServiceCanaryObjectURL, c.apiAddr, name), serviceCanary)
+}
+
+func (c *httpClient) DeleteServiceCanary(ctx context.Context, name string) error {
+	statusCode, _, err := c.do(ctx, http.MethodDelete, fmt.Sprintf(meshServiceCanaryObjectURL, c.apiAddr, name), nil)
+	if err != nil && statusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (c *httpClient) get(ctx context.Context, url string) (map[string]interface{}, error) {
+	statusCode, body, err := c.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		if statusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	object := map[string]interface{}{}
+	err = json.Unmarshal(body, &object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s", url)
+	}
+	return object, nil
+}
+
+// apply posts the object to createURL, then puts it to updateURL if it already exists.
+func (c *httpClient) apply(ctx context.Context, createURL, updateURL string, object map[string]interface{}) error {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return errors.Wrapf(err, "marshal %s", updateURL)
+	}
+
+	statusCode, _, err := c.do(ctx, http.MethodPost, createURL, body)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusConflict {
+		return nil
+	}
+
+	_, _, err = c.do(ctx, http.MethodPut, updateURL, body)
+	return err
+}
+
+// do sends the request, the status code is returned even if the response is failed.
+func (c *httpClient) do(ctx context.Context, method, url string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "new request %s %s", method, url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "call %s %s", method, url)
+	}
+	defer resp.Body.Close()
+
+	text, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, text, nil
+	}
+
+	// NOTE: Conflict is handled by the caller.
+	if resp.StatusCode == http.StatusConflict {
+		return resp.StatusCode, text, nil
+	}
+
+	return resp.StatusCode, nil, errors.Errorf("call %s %s failed, return statuscode %d text %s",
+		method, url, resp.StatusCode, text)
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trafficpolicy
+
+import (
+	"fmt"
+)
+
+type (
+	// Deployment is an annotated Deployment of a service.
+	Deployment struct {
+		// ID is namespace/name of the Deployment.
+		ID string
+		// Policy is nil if the Deployment is being deleted.
+		Policy  *Policy
+		Applied *Applied
+	}
+
+	// Resolution is the resilience of the service resolved from its Deployments.
+	Resolution struct {
+		// Resilience is the resilience to apply, nil if it's unchanged.
+		Resilience map[string]interface{}
+		// TimeLimiter and Retryer are owned by the annotations after applying.
+		TimeLimiter map[string]interface{}
+		Retryer     map[string]interface{}
+		// Conflicts explain the policies left as they are.
+		Conflicts []string
+	}
+
+	resilienceField struct {
+		name     string
+		setting  string
+		generate func(*Policy) (map[string]interface{}, string)
+		applied  func(*Applied) map[string]interface{}
+		resolved *map[string]interface{}
+	}
+)
+
+// ResolveResilience resolves the timeout and retries of the service from the
+// annotations of all its Deployments, by the conflict rules of the package.
+func ResolveResilience(service string, live map[string]interface{}, self *Deployment, peers []*Deployment) *Resolution {
+	result := &Resolution{}
+	resilience := copyObject(live)
+	changed := false
+
+	fields := []*resilienceField{
+		{
+			name:    "timeLimiter",
+			setting: "timeout",
+			generate: func(p *Policy) (map[string]interface{}, string) {
+				return TimeLimiter(p.Timeout), p.Timeout
+			},
+			applied:  func(a *Applied) map[string]interface{} { return a.TimeLimiter },
+			resolved: &result.TimeLimiter,
+		},
+		{
+			name:    "retryer",
+			setting: "retries",
+			generate: func(p *Policy) (map[string]interface{}, string) {
+				return Retryer(p.Retries), fmt.Sprint(p.Retries)
+			},
+			applied:  func(a *Applied) map[string]interface{} { return a.Retryer },
+			resolved: &result.Retryer,
+		},
+	}
+
+	deployments := append([]*Deployment{self}, peers...)
+	for _, field := range fields {
+		var desired map[string]interface{}
+		var desiredValue, desiredBy string
+		conflicted := false
+		for _, d := range deployments {
+			if d.Policy == nil {
+				continue
+			}
+			policy, value := field.generate(d.Policy)
+			if policy == nil {
+				continue
+			}
+			if desired != nil && !Equal(desired, policy) {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("%s %s of Deployment %s conflicts with %s of Deployment %s, "+
+					"Deployments of service %s must agree on it", field.setting, value, d.ID, desiredValue, desiredBy, service))
+				conflicted = true
+				break
+			}
+			desired, desiredValue, desiredBy = policy, value, d.ID
+		}
+		if conflicted {
+			if self.Applied != nil {
+				*field.resolved = field.applied(self.Applied)
+			}
+			continue
+		}
+
+		records := []map[string]interface{}{}
+		for _, d := range deployments {
+			if d.Applied != nil {
+				records = append(records, field.applied(d.Applied))
+			}
+		}
+
+		current, _ := resilience[field.name].(map[string]interface{})
+		if !Owned(current, records) {
+			if desired != nil {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("resilience.%s of service %s isn't written by annotations, "+
+					"remove it from the resources to manage %s by annotations", field.name, service, field.setting))
+			}
+			continue
+		}
+
+		*field.resolved = desired
+		if Equal(current, desired) {
+			continue
+		}
+		if desired == nil {
+			delete(resilience, field.name)
+		} else {
+			resilience[field.name] = desired
+		}
+		changed = true
+	}
+
+	if changed {
+		result.Resilience = resilience
+	}
+	return result
+}
+
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trafficpolicy converts traffic policy annotations of Deployments into
+// mesh resources, for teams managing no mesh resources in YAML.
+//
+// The conflict rules are:
+//   - Resources win over annotations: the resilience policies and service
+//     canaries which aren't written by the operator are never overwritten.
+//   - Timeout and retries are service-wide: all annotated Deployments of the
+//     service must agree on them, otherwise they're left as they are.
+//   - The canary weight is per Deployment: it routes the percentage of the
+//     requests to the instances labeled by its service labels.
+package trafficpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/util/labelstool"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TimeoutAnnotationKey is the timeout of requests to the service, e.g. 2s.
+	TimeoutAnnotationKey = "mesh.megaease.com/timeout"
+	// RetriesAnnotationKey is the count of retries of failed requests to the service.
+	RetriesAnnotationKey = "mesh.megaease.com/retries"
+	// CanaryWeightAnnotationKey is the percentage of requests routed to the instances of the Deployment.
+	CanaryWeightAnnotationKey = "mesh.megaease.com/canary-weight"
+	// CanaryKeyAnnotationKey is the source which requests are bucketed by for the canary weight.
+	CanaryKeyAnnotationKey = "mesh.megaease.com/canary-key"
+
+	// AppliedAnnotationKey records the policies written by the operator for the Deployment,
+	// which are owned by the annotations.
+	AppliedAnnotationKey = "mesh.megaease.com/applied-traffic-policy"
+	// Finalizer removes the policies written by the operator before the Deployment is deleted.
+	Finalizer = "mesh.megaease.com/traffic-policy"
+
+	// DefaultCanaryKey is the default source which requests are bucketed by.
+	DefaultCanaryKey = `header("X-Request-Id")`
+
+	serviceLabelsAnnotationKey = "mesh.megaease.com/service-labels"
+
+	retryPolicyName   = "annotations"
+	retryWaitDuration = "500ms"
+	maxRetries        = 10
+)
+
+var (
+	allMethods = []interface{}{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+	}
+	retryStatusCodes = []interface{}{502, 503, 504}
+)
+
+type (
+	// Policy is the traffic policy in annotations of a Deployment.
+	Policy struct {
+		Timeout string
+		Retries int
+
+		// CanaryWeight is nil if there is no canary.
+		CanaryWeight *int
+		CanaryKey    string
+		CanaryLabels map[string]string
+	}
+
+	// Applied is the record of policies written by the operator for a Deployment.
+	Applied struct {
+		Service       string                 `json:"service"`
+		TimeLimiter   map[string]interface{} `json:"timeLimiter,omitempty"`
+		Retryer       map[string]interface{} `json:"retryer,omitempty"`
+		ServiceCanary string                 `json:"serviceCanary,omitempty"`
+	}
+)
+
+// Annotated tells whether the object carries traffic policy annotations.
+func Annotated(obj metav1.Object) bool {
+	annotations := obj.GetAnnotations()
+	for _, key := range []string{TimeoutAnnotationKey, RetriesAnnotationKey, CanaryWeightAnnotationKey} {
+		if annotations[key] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses the traffic policy annotations.
+func Parse(annotations map[string]string) (*Policy, error) {
+	policy := &Policy{}
+
+	if value := annotations[TimeoutAnnotationKey]; value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, errors.Errorf("%s: invalid timeout %q, want a positive duration", TimeoutAnnotationKey, value)
+		}
+		policy.Timeout = value
+	}
+
+	if value := annotations[RetriesAnnotationKey]; value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 || retries > maxRetries {
+			return nil, errors.Errorf("%s: invalid retries %q, want an integer in [0, %d]", RetriesAnnotationKey, value, maxRetries)
+		}
+		policy.Retries = retries
+	}
+
+	if value := annotations[CanaryWeightAnnotationKey]; value != "" {
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 || weight > 100 {
+			return nil, errors.Errorf("%s: invalid weight %q, want a percentage in [0, 100]", CanaryWeightAnnotationKey, value)
+		}
+		labels, err := labelstool.Unmarshal(annotations[serviceLabelsAnnotationKey])
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", serviceLabelsAnnotationKey)
+		}
+		if len(labels) == 0 {
+			return nil, errors.Errorf("%s requires %s to select the canary instances", CanaryWeightAnnotationKey, serviceLabelsAnnotationKey)
+		}
+		policy.CanaryWeight = &weight
+		policy.CanaryLabels = labels
+		policy.CanaryKey = annotations[CanaryKeyAnnotationKey]
+		if policy.CanaryKey == "" {
+			policy.CanaryKey = DefaultCanaryKey
+		}
+	} else if annotations[CanaryKeyAnnotationKey] != "" {
+		return nil, errors.Errorf("%s requires %s", CanaryKeyAnnotationKey, CanaryWeightAnnotationKey)
+	}
+
+	return policy, nil
+}
+
+// TimeLimiter returns resilience.timeLimiter of the timeout, nil if there is no timeout.
+func TimeLimiter(timeout string) map[string]interface{} {
+	if timeout == "" {
+		return nil
+	}
+	return map[string]interface{}{
+		"urls": []interface{}{
+			map[string]interface{}{
+				"methods":         allMethods,
+				"url":             map[string]interface{}{"prefix": "/"},
+				"timeoutDuration": timeout,
+			},
+		},
+	}
+}
+
+// Retryer returns resilience.retryer of the retries, nil if there are no retries.
+func Retryer(retries int) map[string]interface{} {
+	if retries == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"policies": []interface{}{
+			map[string]interface{}{
+				"name": retryPolicyName,
+				// NOTE: The first attempt isn't a retry.
+				"maxAttempts":        retries + 1,
+				"waitDuration":       retryWaitDuration,
+				"failureStatusCodes": retryStatusCodes,
+			},
+		},
+		"urls": []interface{}{
+			map[string]interface{}{
+				"methods":   allMethods,
+				"url":       map[string]interface{}{"prefix": "/"},
+				"policyRef": retryPolicyName,
+			},
+		},
+	}
+}
+
+// ServiceCanaryName returns the name of the service canary of the Deployment.
+func ServiceCanaryName(namespace, name string) string {
+	return fmt.Sprintf("%s-%s-weight", namespace, name)
+}
+
+// ServiceCanary returns the service canary routing the weight of requests
+// to the service to the canary instances.
+func ServiceCanary(name, service string, policy *Policy) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"selector": map[string]interface{}{
+			"matchServices":       []interface{}{service},
+			"matchInstanceLabels": policy.CanaryLabels,
+		},
+		"trafficRules": map[string]interface{}{
+			"headers": map[string]interface{}{},
+		},
+		"sticky": map[string]interface{}{
+			"mode":       "hash",
+			"key":        policy.CanaryKey,
+			"percentage": *policy.CanaryWeight,
+		},
+	}
+}
+
+// Owned tells whether the live policy is written by the operator, according to
+// the records of Deployments of the service, an absent policy is owned by anyone.
+func Owned(live map[string]interface{}, records []map[string]interface{}) bool {
+	if live == nil {
+		return true
+	}
+	for _, record := range records {
+		if record != nil && Equal(live, record) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal compares policies after normalizing them by JSON,
+// since numbers in the live ones are decoded as float64.
+func Equal(a, b map[string]interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(policy map[string]interface{}) interface{} {
+	if policy == nil {
+		return nil
+	}
+	buff, err := json.Marshal(policy)
+	if err != nil {
+		return policy
+	}
+	var result interface{}
+	if err := json.Unmarshal(buff, &result); err != nil {
+		return policy
+	}
+	return result
+}
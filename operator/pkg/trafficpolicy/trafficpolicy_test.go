@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trafficpolicy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	labels := "version=canary"
+	cases := []struct {
+		annotations map[string]string
+		valid       bool
+		weight      int
+	}{
+		{map[string]string{TimeoutAnnotationKey: "3s", RetriesAnnotationKey: "2"}, true, -1},
+		{map[string]string{TimeoutAnnotationKey: "3"}, false, -1},
+		{map[string]string{TimeoutAnnotationKey: "-3s"}, false, -1},
+		{map[string]string{RetriesAnnotationKey: "11"}, false, -1},
+		{map[string]string{CanaryWeightAnnotationKey: "20", serviceLabelsAnnotationKey: labels}, true, 20},
+		{map[string]string{CanaryWeightAnnotationKey: "20"}, false, -1},
+		{map[string]string{CanaryWeightAnnotationKey: "101", serviceLabelsAnnotationKey: labels}, false, -1},
+		{map[string]string{CanaryKeyAnnotationKey: `header("X-User")`}, false, -1},
+	}
+
+	for i, c := range cases {
+		policy, err := Parse(c.annotations)
+		if (err == nil) != c.valid {
+			t.Errorf("case %d: expected valid %v, got error %v", i, c.valid, err)
+			continue
+		}
+		if !c.valid {
+			continue
+		}
+		if c.weight < 0 && policy.CanaryWeight != nil {
+			t.Errorf("case %d: expected no canary weight", i)
+		}
+		if c.weight >= 0 && (policy.CanaryWeight == nil || *policy.CanaryWeight != c.weight || policy.CanaryKey != DefaultCanaryKey) {
+			t.Errorf("case %d: expected canary weight %d by the default key, got %+v", i, c.weight, policy)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	// NOTE: Numbers of policies read from the control plane are float64.
+	live := map[string]interface{}{}
+	buff, _ := json.Marshal(Retryer(2))
+	json.Unmarshal(buff, &live)
+
+	if !Equal(live, Retryer(2)) {
+		t.Errorf("expected decoded retryer equal to the generated one")
+	}
+	if Equal(live, Retryer(3)) {
+		t.Errorf("expected retryers of different retries unequal")
+	}
+}
+
+func TestResolveResilience(t *testing.T) {
+	deployment := func(id, timeout string, applied *Applied) *Deployment {
+		return &Deployment{ID: id, Policy: &Policy{Timeout: timeout}, Applied: applied}
+	}
+	applied := &Applied{Service: "order", TimeLimiter: TimeLimiter("3s")}
+	manual := map[string]interface{}{"timeLimiter": TimeLimiter("5s")}
+
+	cases := []struct {
+		name        string
+		live        map[string]interface{}
+		self        *Deployment
+		peers       []*Deployment
+		conflicts   int
+		changed     bool
+		timeLimiter map[string]interface{}
+	}{
+		{"apply", nil, deployment("default/order", "3s", nil), nil, 0, true, TimeLimiter("3s")},
+		{"unchanged", map[string]interface{}{"timeLimiter": TimeLimiter("3s")}, deployment("default/order", "3s", applied), nil, 0, false, TimeLimiter("3s")},
+		{"agreed", nil, deployment("default/order", "3s", nil), []*Deployment{deployment("default/order-v2", "3s", nil)}, 0, true, TimeLimiter("3s")},
+		{"disagreed", map[string]interface{}{"timeLimiter": TimeLimiter("3s")}, deployment("default/order", "3s", applied),
+			[]*Deployment{deployment("default/order-v2", "5s", nil)}, 1, false, TimeLimiter("3s")},
+		{"written by resources", manual, deployment("default/order", "3s", nil), nil, 1, false, nil},
+		{"left to resources", manual, deployment("default/order", "", nil), nil, 0, false, nil},
+		{"removed", map[string]interface{}{"timeLimiter": TimeLimiter("3s")}, &Deployment{ID: "default/order", Applied: applied}, nil, 0, true, nil},
+		{"kept by peers", map[string]interface{}{"timeLimiter": TimeLimiter("3s")}, &Deployment{ID: "default/order", Applied: applied},
+			[]*Deployment{deployment("default/order-v2", "3s", nil)}, 0, false, TimeLimiter("3s")},
+	}
+
+	for _, c := range cases {
+		resolution := ResolveResilience("order", c.live, c.self, c.peers)
+		if len(resolution.Conflicts) != c.conflicts {
+			t.Errorf("%s: expected %d conflicts, got %v", c.name, c.conflicts, resolution.Conflicts)
+		}
+		if (resolution.Resilience != nil) != c.changed {
+			t.Errorf("%s: expected changed %v, got %v", c.name, c.changed, resolution.Resilience)
+		}
+		if !Equal(resolution.TimeLimiter, c.timeLimiter) {
+			t.Errorf("%s: expected time limiter %v, got %v", c.name, c.timeLimiter, resolution.TimeLimiter)
+		}
+		if timeLimiter, _ := resolution.Resilience["timeLimiter"].(map[string]interface{}); c.changed && !Equal(timeLimiter, c.timeLimiter) {
+			t.Errorf("%s: expected resilience with time limiter %v, got %v", c.name, c.timeLimiter, resolution.Resilience)
+		}
+	}
+}